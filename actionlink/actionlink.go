@@ -0,0 +1,190 @@
+// Package actionlink は通知に含める、ログインせずにワンクリックでTodoを操作するリンクのトークンを提供する。
+// トークンは操作の種類、対象のTodo、操作するユーザーと有効期限を含み、秘密情報action_link_keyを鍵としたHMAC-SHA256で署名する。
+// 放置されたTodoの通知のリンクは状態を持たず、有効期限までは同じリンクを何度でも使える。
+// リマインダーなどのワンクリック操作のリンクはトークンごとのIDを含み、使用したIDをサーバーが記録して1回だけ使えるようにする。
+package actionlink
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/secrets"
+	"strings"
+	"time"
+)
+
+// 操作の種類
+const (
+	// ActionArchive はTodoのアーカイブ
+	ActionArchive = "archive"
+	// ActionSnooze は放置されたTodoの通知の延期
+	ActionSnooze = "snooze"
+	// ActionComplete はTodoの完了
+	ActionComplete = "complete"
+	// ActionSnoozeDay はリマインダーを1日後にもう一度通知すること
+	ActionSnoozeDay = "snooze_day"
+	// ActionDelete はTodoの削除
+	ActionDelete = "delete"
+)
+
+// QuickActions は1回だけ使えるワンクリック操作のリンクで行える操作。通知にはこの順に並べる
+var QuickActions = []string{ActionComplete, ActionSnoozeDay, ActionDelete}
+
+// ErrInvalidToken はトークンの形式や署名が不正か、期限切れか、別の操作のものであることを表すエラー
+var ErrInvalidToken = errors.New("リンクが無効か、有効期限が切れています")
+
+// Claims はトークンに含める内容を表す構造体
+type Claims struct {
+	Action string `json:"act"`
+	TodoID int64  `json:"todo"`
+	// UserID は操作するユーザーのID。所有者のいないTodoでは0
+	UserID    int64 `json:"user,omitempty"`
+	ExpiresAt int64 `json:"exp"`
+	// ID は1回だけ使えるリンクのトークンを識別する値。放置されたTodoの通知のリンクでは空
+	ID string `json:"jti,omitempty"`
+	// Channel はリンクを送った通知チャネル。1日後にもう一度通知する場合に同じチャネルを使う
+	Channel string `json:"ch,omitempty"`
+}
+
+// PathPrefix はリンクのパスの接頭辞。リンクは<PathPrefix><操作の種類>/<トークン>の形式になる
+const PathPrefix = "/review/"
+
+// QuickActionPathPrefix は1回だけ使えるワンクリック操作のリンクのパスの接頭辞。リンクは<QuickActionPathPrefix><トークン>の形式になる
+const QuickActionPathPrefix = "/actions/"
+
+// tokenIDBytes は1回だけ使えるリンクのトークンのIDのバイト数
+const tokenIDBytes = 16
+
+// Signer はリンクのトークンを発行し、検証する
+type Signer struct {
+	secrets *secrets.Manager
+	baseURL string
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// NewSigner はSignerの新しいインスタンスを生成する。
+// リンクは利用者がサーバーに接続するURLのbaseURLから組み立て、発行したトークンはttlの間だけ有効。
+func NewSigner(secrets *secrets.Manager, baseURL string, ttl time.Duration) *Signer {
+	return &Signer{
+		secrets: secrets,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Link はユーザーuserIDがTodo todoIDにactionを実行するリンクを返す
+func (s *Signer) Link(ctx context.Context, action string, todoID, userID int64) (string, error) {
+	token, _, err := s.Sign(ctx, action, todoID, userID)
+	if err != nil {
+		return "", err
+	}
+	return s.baseURL + PathPrefix + action + "/" + token, nil
+}
+
+// QuickActionLink はユーザーuserIDがTodo todoIDにactionを1回だけ実行できるリンクを返す。
+// channelはリンクを送る通知チャネルで、1日後にもう一度通知する場合に使う。
+func (s *Signer) QuickActionLink(ctx context.Context, action string, todoID, userID int64, channel string) (string, error) {
+	id := make([]byte, tokenIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	token, _, err := s.sign(ctx, Claims{
+		Action:  action,
+		TodoID:  todoID,
+		UserID:  userID,
+		ID:      base64.RawURLEncoding.EncodeToString(id),
+		Channel: channel,
+	})
+	if err != nil {
+		return "", err
+	}
+	return s.baseURL + QuickActionPathPrefix + token, nil
+}
+
+// key は署名の鍵を読み込む
+func (s *Signer) key(ctx context.Context) ([]byte, error) {
+	key, ok, err := s.secrets.Lookup(ctx, secrets.ActionLinkKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || key == "" {
+		return nil, fmt.Errorf("秘密情報 %s が設定されていません", secrets.ActionLinkKey)
+	}
+	return []byte(key), nil
+}
+
+// Sign はユーザーuserIDがTodo todoIDにactionを実行するためのトークンを発行し、トークンと有効期限を返す
+func (s *Signer) Sign(ctx context.Context, action string, todoID, userID int64) (string, time.Time, error) {
+	return s.sign(ctx, Claims{Action: action, TodoID: todoID, UserID: userID})
+}
+
+// sign はclaimsに有効期限を設定したトークンを発行し、トークンと有効期限を返す
+func (s *Signer) sign(ctx context.Context, claims Claims) (string, time.Time, error) {
+	key, err := s.key(ctx)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := s.now().Add(s.ttl).UTC().Truncate(time.Second)
+	claims.ExpiresAt = expiresAt.Unix()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sign(key, encoded)), expiresAt, nil
+}
+
+// Verify はトークンの署名と有効期限を検証し、actionのトークンであればその内容を返す
+func (s *Signer) Verify(ctx context.Context, action, token string) (Claims, error) {
+	claims, err := s.Parse(ctx, token)
+	if err != nil {
+		return Claims{}, err
+	}
+	// 別の操作のリンクに付け替えて使えないよう、操作の種類も照合する
+	if claims.Action != action {
+		return Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// Parse はトークンの署名と有効期限を検証し、その内容を返す。操作の種類は照合しない。
+func (s *Signer) Parse(ctx context.Context, token string) (Claims, error) {
+	key, err := s.key(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Claims{}, ErrInvalidToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, sign(key, encoded)) {
+		return Claims{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	if !s.now().Before(time.Unix(claims.ExpiresAt, 0)) {
+		return Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// sign はトークンの内容のHMAC-SHA256を求める
+func sign(key []byte, encoded string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,173 @@
+// Package activity はTodoへの変更を誰が・いつ・どのように行ったかをアクティビティログに記録する機能を提供する。
+// 記録は変更と同じトランザクション内で行い、変更と履歴の不整合が起きないようにする。
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/db"
+	"reflect"
+	"time"
+)
+
+// 変更の種類
+const (
+	// ActionCreate はTodoの作成
+	ActionCreate = "create"
+	// ActionUpdate はTodoの更新
+	ActionUpdate = "update"
+	// ActionDelete はTodoの削除
+	ActionDelete = "delete"
+	// ActionToggle はTodoの完了状態の切り替え
+	ActionToggle = "toggle"
+	// ActionMove はTodoの別のリスト（プロジェクト）や並び順への移動
+	ActionMove = "move"
+	// ActionEscalate は期限切れによる優先度の自動の引き上げ
+	ActionEscalate = "escalate"
+	// ActionArchive はTodoのアーカイブ
+	ActionArchive = "archive"
+	// ActionUnarchive はTodoのアーカイブの解除
+	ActionUnarchive = "unarchive"
+	// ActionTag はTodoに付けたタグの変更。タグの名前の変更、統合、削除によるものも含む
+	ActionTag = "tag"
+)
+
+// SystemActor はリクエストによらない変更（スケジューラーなど）の実行者
+const SystemActor = "system"
+
+// actorKey は実行者をcontextに格納するためのキー
+type actorKey struct{}
+
+// WithActor は実行者を格納したcontextを返す
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom はcontextに格納された実行者を返す。格納されていない場合はSystemActorを返す。
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
+
+// Change はフィールドの変更前後の値を表す構造体
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// nullValue はsql.Null*型の値をJSONで表現できる値に変換する
+func nullValue[T any](v T, valid bool) any {
+	if !valid {
+		return nil
+	}
+	return v
+}
+
+// snapshot は履歴に残すTodoのフィールドを取り出す。件数や日時など自動で更新される値は含めない。
+func snapshot(t *db.Todo) map[string]any {
+	if t == nil {
+		return map[string]any{}
+	}
+	var dueAt any
+	if t.DueAt.Valid {
+		dueAt = t.DueAt.Time.UTC().Format(time.RFC3339)
+	}
+	var metadata any
+	if t.Metadata.Valid {
+		metadata = json.RawMessage(t.Metadata.String)
+	}
+	return map[string]any{
+		"title":       t.Title,
+		"description": nullValue(t.Description.String, t.Description.Valid),
+		"completed":   t.Completed == 1,
+		"latitude":    nullValue(t.Latitude.Float64, t.Latitude.Valid),
+		"longitude":   nullValue(t.Longitude.Float64, t.Longitude.Valid),
+		"place_name":  nullValue(t.PlaceName.String, t.PlaceName.Valid),
+		"project_id":  nullValue(t.ProjectID.Int64, t.ProjectID.Valid),
+		"position":    t.Position,
+		"due_at":      dueAt,
+		"recurrence":  nullValue(t.Recurrence.String, t.Recurrence.Valid),
+		"assignee":    nullValue(t.Assignee.String, t.Assignee.Valid),
+		"metadata":    metadata,
+		"priority":    t.Priority,
+		"archived":    t.ArchivedAt.Valid,
+	}
+}
+
+// Diff は変更前後のTodoを比較し、値が変わったフィールドを返す。
+// 作成時はbeforeに、削除時はafterにnilを指定する。
+func Diff(before, after *db.Todo) map[string]Change {
+	from, to := snapshot(before), snapshot(after)
+	diff := make(map[string]Change)
+	for _, m := range []map[string]any{from, to} {
+		for field := range m {
+			if _, ok := diff[field]; ok {
+				continue
+			}
+			if !reflect.DeepEqual(from[field], to[field]) {
+				diff[field] = Change{From: from[field], To: to[field]}
+			}
+		}
+	}
+	return diff
+}
+
+// Record はTodoの変更をアクティビティログに記録する。qには変更と同じトランザクションを渡す。
+func Record(ctx context.Context, q *db.Queries, action string, before, after *db.Todo) error {
+	var todoID int64
+	switch {
+	case after != nil:
+		todoID = after.ID
+	case before != nil:
+		todoID = before.ID
+	default:
+		return errors.New("変更前後のTodoがどちらも指定されていません")
+	}
+
+	diff, err := json.Marshal(Diff(before, after))
+	if err != nil {
+		return fmt.Errorf("変更内容のエンコードに失敗: %w", err)
+	}
+
+	if err := q.CreateActivity(ctx, db.CreateActivityParams{
+		TodoID: todoID,
+		Actor:  ActorFrom(ctx),
+		Action: action,
+		Diff:   string(diff),
+	}); err != nil {
+		return fmt.Errorf("アクティビティの記録に失敗: %w", err)
+	}
+	return nil
+}
+
+// RecordTags はTodoに付けたタグの変更をアクティビティログに記録する。before、afterには変更前後のタグの名前を渡す。
+func RecordTags(ctx context.Context, q *db.Queries, todoID int64, before, after []string) error {
+	diff, err := json.Marshal(map[string]Change{
+		"tags": {From: nonNil(before), To: nonNil(after)},
+	})
+	if err != nil {
+		return fmt.Errorf("変更内容のエンコードに失敗: %w", err)
+	}
+
+	if err := q.CreateActivity(ctx, db.CreateActivityParams{
+		TodoID: todoID,
+		Actor:  ActorFrom(ctx),
+		Action: ActionTag,
+		Diff:   string(diff),
+	}); err != nil {
+		return fmt.Errorf("アクティビティの記録に失敗: %w", err)
+	}
+	return nil
+}
+
+// nonNil はタグがない場合もnullではなく空の配列として記録するため、nilを空のスライスにする
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
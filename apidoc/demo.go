@@ -0,0 +1,157 @@
+package apidoc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// DemoAccount はデモ用のデータを投入した後に、/docsで操作を試すために使う資格情報
+type DemoAccount struct {
+	Email    string
+	Password string
+	// APIKey はX-API-Keyヘッダーで送るAPIキー。アクセストークンと違い期限がない
+	APIKey string
+}
+
+// demoStep はデモ用のデータを投入するために呼び出す操作
+type demoStep struct {
+	operationID string
+	// body はリクエストボディ。nilの場合は操作の例を送る
+	body any
+}
+
+// demoSteps はデモ用のデータを投入する手順。操作の例が参照するIDが順に振られるよう、並びを変えない
+var demoSteps = []demoStep{
+	{operationID: "signup", body: map[string]any{"email": demoEmail, "name": demoName, "password": demoPassword}},
+	{operationID: "signup", body: map[string]any{"email": demoMemberEmail, "name": demoMemberName, "password": demoPassword}},
+	{operationID: "login"},
+	{operationID: "create-project"},
+	{operationID: "create-todo"},
+	{operationID: "create-subtask"},
+	{operationID: "create-comment"},
+	{operationID: "create-reminder"},
+	{operationID: "share-todo"},
+	{operationID: "create-filter"},
+	{operationID: "create-api-key"},
+	{operationID: "create-webhook"},
+}
+
+// SeedDemo は空のデータベースに、Examplesが作る操作の例が参照するデモ用のデータを投入する。
+// 操作の例をそのままhに送るため、検証、認可、アクティビティの記録などはAPIを呼び出した場合と同じになる。
+// 例が登録済みのOpenAPIを渡し、すべての操作を登録した後、リクエストを受け付ける前に呼び出す。
+func SeedDemo(ctx context.Context, h http.Handler, oapi *huma.OpenAPI) (DemoAccount, error) {
+	ops := make(map[string]*huma.Operation)
+	for _, op := range operations(oapi) {
+		ops[op.OperationID] = op
+	}
+
+	account := DemoAccount{Email: demoEmail, Password: demoPassword}
+	var token string
+	for _, step := range demoSteps {
+		op, ok := ops[step.operationID]
+		if !ok {
+			return DemoAccount{}, fmt.Errorf("操作 %s が登録されていません", step.operationID)
+		}
+		body := step.body
+		if body == nil && op.RequestBody != nil {
+			if mt, ok := op.RequestBody.Content["application/json"]; ok {
+				body = mt.Example
+			}
+		}
+		res, err := sendDemoRequest(ctx, h, op, body, token)
+		if err != nil {
+			return DemoAccount{}, fmt.Errorf("%s: %w", step.operationID, err)
+		}
+
+		switch step.operationID {
+		case "login":
+			var out struct {
+				AccessToken string `json:"access_token"`
+			}
+			if err := json.Unmarshal(res, &out); err != nil {
+				return DemoAccount{}, fmt.Errorf("%s: レスポンスを解析できません: %w", step.operationID, err)
+			}
+			token = out.AccessToken
+		case "create-api-key":
+			var out struct {
+				Key string `json:"key"`
+			}
+			if err := json.Unmarshal(res, &out); err != nil {
+				return DemoAccount{}, fmt.Errorf("%s: レスポンスを解析できません: %w", step.operationID, err)
+			}
+			account.APIKey = out.Key
+		}
+	}
+	return account, nil
+}
+
+// sendDemoRequest はパスパラメータを例の値で置き換えて操作を呼び出し、成功した場合はレスポンスボディを返す
+func sendDemoRequest(ctx context.Context, h http.Handler, op *huma.Operation, body any, token string) ([]byte, error) {
+	path := op.Path
+	for _, p := range op.Parameters {
+		if p.In == "path" {
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", fmt.Sprint(p.Example))
+		}
+	}
+
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, op.Method, path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.RemoteAddr = "127.0.0.1:0"
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := &demoRecorder{header: http.Header{}}
+	h.ServeHTTP(rec, req)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if rec.status >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("%s %sが%dを返しました: %s", op.Method, path, rec.status, rec.body.String())
+	}
+	return rec.body.Bytes(), nil
+}
+
+// demoRecorder はデモ用のデータを投入するリクエストのレスポンスを記録するResponseWriter
+type demoRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *demoRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *demoRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *demoRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
@@ -0,0 +1,411 @@
+package apidoc
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// 変更の種類
+const (
+	ChangeOperationAdded      = "operation_added"
+	ChangeOperationRemoved    = "operation_removed"
+	ChangeOperationDeprecated = "operation_deprecated"
+	ChangeParameterAdded      = "parameter_added"
+	ChangeParameterRemoved    = "parameter_removed"
+	ChangeParameterRequired   = "parameter_required"
+	ChangeParameterOptional   = "parameter_optional"
+	ChangeBodyRequired        = "request_body_required"
+	ChangeResponseAdded       = "response_added"
+	ChangeResponseRemoved     = "response_removed"
+	ChangePropertyAdded       = "property_added"
+	ChangePropertyRemoved     = "property_removed"
+	ChangePropertyRequired    = "property_required"
+	ChangePropertyOptional    = "property_optional"
+	ChangePropertyDeprecated  = "property_deprecated"
+	ChangeTypeChanged         = "type_changed"
+	ChangeEnumAdded           = "enum_value_added"
+	ChangeEnumRemoved         = "enum_value_removed"
+)
+
+// 差分の判定
+const (
+	DiffOK       = "ok"
+	DiffBreaking = "breaking"
+)
+
+// Change はOpenAPIのドキュメントの変更1件を表す構造体
+type Change struct {
+	Kind      string `json:"kind"`
+	Operation string `json:"operation"`
+	Location  string `json:"location,omitempty"`
+	Message   string `json:"message"`
+	// WasDeprecated は削除や変更の対象が基準のドキュメントで非推奨になっていたか
+	WasDeprecated bool `json:"was_deprecated,omitempty"`
+}
+
+// DiffReport は基準のドキュメントと現在のドキュメントの差分を表す構造体
+type DiffReport struct {
+	// Status は互換性のない変更があり、メジャーバージョンが上がっていない場合にbreakingになる
+	Status          string `json:"status"`
+	BaselineVersion string `json:"baseline_version"`
+	CurrentVersion  string `json:"current_version"`
+	// MajorBump はinfo.versionのメジャーバージョンが上がっているか
+	MajorBump   bool     `json:"major_bump"`
+	Breaking    []Change `json:"breaking"`
+	NonBreaking []Change `json:"non_breaking"`
+}
+
+// specDocument は差分の比較に使うOpenAPIのドキュメントの部分。
+// huma.OpenAPIはJSONからの読み込みに対応していないため、必要な項目だけを読み込む
+type specDocument struct {
+	Info struct {
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths      map[string]specPathItem `json:"paths"`
+	Components struct {
+		Schemas map[string]*specSchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// specPathItem はパス1つの操作を表す構造体
+type specPathItem struct {
+	Get     *specOperation `json:"get"`
+	Put     *specOperation `json:"put"`
+	Post    *specOperation `json:"post"`
+	Delete  *specOperation `json:"delete"`
+	Options *specOperation `json:"options"`
+	Head    *specOperation `json:"head"`
+	Patch   *specOperation `json:"patch"`
+	Trace   *specOperation `json:"trace"`
+}
+
+// operations はメソッドごとの操作を返す
+func (p specPathItem) operations() map[string]*specOperation {
+	ops := map[string]*specOperation{
+		"GET": p.Get, "PUT": p.Put, "POST": p.Post, "DELETE": p.Delete,
+		"OPTIONS": p.Options, "HEAD": p.Head, "PATCH": p.Patch, "TRACE": p.Trace,
+	}
+	for method, op := range ops {
+		if op == nil {
+			delete(ops, method)
+		}
+	}
+	return ops
+}
+
+type specOperation struct {
+	OperationID string          `json:"operationId"`
+	Deprecated  bool            `json:"deprecated"`
+	Parameters  []specParameter `json:"parameters"`
+	RequestBody *struct {
+		Required bool                     `json:"required"`
+		Content  map[string]specMediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]specMediaType `json:"content"`
+	} `json:"responses"`
+}
+
+type specParameter struct {
+	Name       string      `json:"name"`
+	In         string      `json:"in"`
+	Required   bool        `json:"required"`
+	Deprecated bool        `json:"deprecated"`
+	Schema     *specSchema `json:"schema"`
+}
+
+type specMediaType struct {
+	Schema *specSchema `json:"schema"`
+}
+
+type specSchema struct {
+	Ref        string                 `json:"$ref"`
+	Type       json.RawMessage        `json:"type"`
+	Format     string                 `json:"format"`
+	Items      *specSchema            `json:"items"`
+	Properties map[string]*specSchema `json:"properties"`
+	Required   []string               `json:"required"`
+	Enum       []any                  `json:"enum"`
+	Deprecated bool                   `json:"deprecated"`
+}
+
+// typeName は型を比較できる文字列にする。OpenAPI 3.1では型を配列で指定できるため、並べ替えてつなげる
+func (s *specSchema) typeName() string {
+	if len(s.Type) == 0 {
+		return ""
+	}
+	var name string
+	if json.Unmarshal(s.Type, &name) == nil {
+		return name
+	}
+	var names []string
+	if json.Unmarshal(s.Type, &names) == nil {
+		slices.Sort(names)
+		return strings.Join(names, "|")
+	}
+	return string(s.Type)
+}
+
+// differ は2つのドキュメントを比較して変更を集める
+type differ struct {
+	baseline, current *specDocument
+	report            *DiffReport
+	// visited は循環する参照を比較し続けないよう、比較済みの参照の組を記録する
+	visited map[string]bool
+}
+
+// Diff は基準のドキュメントと現在のドキュメントをJSONで受け取り、変更を互換性のない変更とそれ以外に分けて返す。
+// 操作はメソッドとパスで対応付け、パラメータ、リクエストボディ、成功のレスポンスのスキーマを比較する。
+// クライアントが送る値は受け付ける範囲が狭まる変更を、受け取る値は返す内容が変わる変更を互換性のない変更とする。
+// 基準で非推奨にしていた操作やフィールドの削除も互換性のない変更だが、WasDeprecatedで区別する。
+func Diff(baseline, current []byte) (*DiffReport, error) {
+	var base, cur specDocument
+	if err := json.Unmarshal(baseline, &base); err != nil {
+		return nil, fmt.Errorf("基準のドキュメントを読み込めません: %w", err)
+	}
+	if err := json.Unmarshal(current, &cur); err != nil {
+		return nil, fmt.Errorf("現在のドキュメントを読み込めません: %w", err)
+	}
+
+	report := &DiffReport{
+		BaselineVersion: base.Info.Version,
+		CurrentVersion:  cur.Info.Version,
+		MajorBump:       majorVersion(cur.Info.Version) > majorVersion(base.Info.Version),
+		Breaking:        []Change{},
+		NonBreaking:     []Change{},
+	}
+	d := &differ{baseline: &base, current: &cur, report: report, visited: map[string]bool{}}
+	d.compareOperations()
+
+	sortChanges := func(a, b Change) int {
+		return cmp.Or(cmp.Compare(a.Operation, b.Operation), cmp.Compare(a.Location, b.Location), cmp.Compare(a.Kind, b.Kind))
+	}
+	slices.SortFunc(report.Breaking, sortChanges)
+	slices.SortFunc(report.NonBreaking, sortChanges)
+
+	report.Status = DiffOK
+	if len(report.Breaking) > 0 && !report.MajorBump {
+		report.Status = DiffBreaking
+	}
+	return report, nil
+}
+
+// majorVersion はinfo.versionのメジャーバージョンを返す。数値で始まらない場合は0とする
+func majorVersion(version string) int {
+	var major int
+	_, _ = fmt.Sscanf(strings.TrimPrefix(version, "v"), "%d", &major)
+	return major
+}
+
+func (d *differ) add(breaking bool, c Change) {
+	if breaking {
+		d.report.Breaking = append(d.report.Breaking, c)
+	} else {
+		d.report.NonBreaking = append(d.report.NonBreaking, c)
+	}
+}
+
+// operationName は変更の対象の操作を、メソッドとパスと操作IDで表す
+func operationName(method, path string, op *specOperation) string {
+	if op.OperationID == "" {
+		return method + " " + path
+	}
+	return method + " " + path + " (" + op.OperationID + ")"
+}
+
+func (d *differ) compareOperations() {
+	for path, baseItem := range d.baseline.Paths {
+		curOps := d.current.Paths[path].operations()
+		for method, baseOp := range baseItem.operations() {
+			name := operationName(method, path, baseOp)
+			curOp, ok := curOps[method]
+			if !ok {
+				d.add(true, Change{Kind: ChangeOperationRemoved, Operation: name, Message: "操作が削除されました", WasDeprecated: baseOp.Deprecated})
+				continue
+			}
+			if curOp.Deprecated && !baseOp.Deprecated {
+				d.add(false, Change{Kind: ChangeOperationDeprecated, Operation: name, Message: "操作が非推奨になりました"})
+			}
+			d.compareOperation(name, baseOp, curOp)
+		}
+	}
+	for path, curItem := range d.current.Paths {
+		baseOps := d.baseline.Paths[path].operations()
+		for method, curOp := range curItem.operations() {
+			if _, ok := baseOps[method]; !ok {
+				d.add(false, Change{Kind: ChangeOperationAdded, Operation: operationName(method, path, curOp), Message: "操作が追加されました"})
+			}
+		}
+	}
+}
+
+func (d *differ) compareOperation(name string, base, cur *specOperation) {
+	key := func(p specParameter) string { return p.In + ":" + p.Name }
+	curParams := make(map[string]specParameter, len(cur.Parameters))
+	for _, p := range cur.Parameters {
+		curParams[key(p)] = p
+	}
+	baseParams := make(map[string]specParameter, len(base.Parameters))
+	for _, p := range base.Parameters {
+		baseParams[key(p)] = p
+		location := "parameters." + p.In + "." + p.Name
+		c, ok := curParams[key(p)]
+		switch {
+		case !ok:
+			d.add(true, Change{Kind: ChangeParameterRemoved, Operation: name, Location: location, Message: "パラメータが削除されました", WasDeprecated: p.Deprecated})
+			continue
+		case c.Required && !p.Required:
+			d.add(true, Change{Kind: ChangeParameterRequired, Operation: name, Location: location, Message: "パラメータが必須になりました"})
+		case !c.Required && p.Required:
+			d.add(false, Change{Kind: ChangeParameterOptional, Operation: name, Location: location, Message: "パラメータが任意になりました"})
+		}
+		d.compareSchema(name, location, p.Schema, c.Schema, true)
+	}
+	for _, p := range cur.Parameters {
+		if _, ok := baseParams[key(p)]; !ok {
+			location := "parameters." + p.In + "." + p.Name
+			if p.Required {
+				d.add(true, Change{Kind: ChangeParameterAdded, Operation: name, Location: location, Message: "必須のパラメータが追加されました"})
+			} else {
+				d.add(false, Change{Kind: ChangeParameterAdded, Operation: name, Location: location, Message: "任意のパラメータが追加されました"})
+			}
+		}
+	}
+
+	if cur.RequestBody != nil {
+		if cur.RequestBody.Required && (base.RequestBody == nil || !base.RequestBody.Required) {
+			d.add(true, Change{Kind: ChangeBodyRequired, Operation: name, Location: "request", Message: "リクエストボディが必須になりました"})
+		}
+		if base.RequestBody != nil {
+			for contentType, media := range base.RequestBody.Content {
+				if c, ok := cur.RequestBody.Content[contentType]; ok {
+					d.compareSchema(name, "request."+contentType, media.Schema, c.Schema, true)
+				}
+			}
+		}
+	}
+
+	for status, baseResp := range base.Responses {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+		curResp, ok := cur.Responses[status]
+		if !ok {
+			d.add(true, Change{Kind: ChangeResponseRemoved, Operation: name, Location: "responses." + status, Message: "成功のレスポンスが削除されました"})
+			continue
+		}
+		for contentType, media := range baseResp.Content {
+			if c, ok := curResp.Content[contentType]; ok {
+				d.compareSchema(name, "responses."+status+"."+contentType, media.Schema, c.Schema, false)
+			}
+		}
+	}
+	for status := range cur.Responses {
+		if _, ok := base.Responses[status]; !ok && strings.HasPrefix(status, "2") {
+			d.add(false, Change{Kind: ChangeResponseAdded, Operation: name, Location: "responses." + status, Message: "成功のレスポンスが追加されました"})
+		}
+	}
+}
+
+// resolve はスキーマの参照をcomponents.schemasから解決する
+func resolve(doc *specDocument, s *specSchema) *specSchema {
+	for i := 0; s != nil && s.Ref != "" && i < 32; i++ {
+		s = doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// compareSchema はスキーマを再帰的に比較する。requestはクライアントが送る値のスキーマか
+func (d *differ) compareSchema(name, location string, base, cur *specSchema, request bool) {
+	if base == nil || cur == nil {
+		return
+	}
+	if base.Ref != "" && cur.Ref != "" {
+		pair := base.Ref + "\x00" + cur.Ref
+		if d.visited[pair] {
+			return
+		}
+		d.visited[pair] = true
+		defer delete(d.visited, pair)
+	}
+	base, cur = resolve(d.baseline, base), resolve(d.current, cur)
+	if base == nil || cur == nil {
+		return
+	}
+
+	if bt, ct := base.typeName(), cur.typeName(); bt != ct || base.Format != cur.Format {
+		d.add(true, Change{Kind: ChangeTypeChanged, Operation: name, Location: location,
+			Message: fmt.Sprintf("型が変わりました: %s → %s", typeLabel(bt, base.Format), typeLabel(ct, cur.Format))})
+		return
+	}
+
+	if len(base.Enum) > 0 || len(cur.Enum) > 0 {
+		key := func(v any) string { b, _ := json.Marshal(v); return string(b) }
+		baseEnum := make(map[string]bool, len(base.Enum))
+		for _, v := range base.Enum {
+			baseEnum[key(v)] = true
+		}
+		curEnum := make(map[string]bool, len(cur.Enum))
+		for _, v := range cur.Enum {
+			curEnum[key(v)] = true
+		}
+		// 列挙がなくなった場合はすべての値を受け付ける
+		for v := range baseEnum {
+			if len(curEnum) > 0 && !curEnum[v] {
+				d.add(request, Change{Kind: ChangeEnumRemoved, Operation: name, Location: location, Message: "列挙値が削除されました: " + v})
+			}
+		}
+		for v := range curEnum {
+			if !baseEnum[v] {
+				d.add(!request, Change{Kind: ChangeEnumAdded, Operation: name, Location: location, Message: "列挙値が追加されました: " + v})
+			}
+		}
+	}
+
+	d.compareSchema(name, location+"[]", base.Items, cur.Items, request)
+
+	for prop, baseProp := range base.Properties {
+		propLocation := location + "." + prop
+		curProp, ok := cur.Properties[prop]
+		if !ok {
+			// Humaは定義にないプロパティを受け付けないため、リクエストでも互換性のない変更になる
+			d.add(true, Change{Kind: ChangePropertyRemoved, Operation: name, Location: propLocation, Message: "プロパティが削除されました", WasDeprecated: resolve(d.baseline, baseProp).Deprecated})
+			continue
+		}
+		baseRequired, curRequired := slices.Contains(base.Required, prop), slices.Contains(cur.Required, prop)
+		switch {
+		case curRequired && !baseRequired:
+			d.add(request, Change{Kind: ChangePropertyRequired, Operation: name, Location: propLocation, Message: "プロパティが必須になりました"})
+		case !curRequired && baseRequired:
+			d.add(!request, Change{Kind: ChangePropertyOptional, Operation: name, Location: propLocation, Message: "プロパティが任意になりました"})
+		}
+		if curProp.Deprecated && !baseProp.Deprecated {
+			d.add(false, Change{Kind: ChangePropertyDeprecated, Operation: name, Location: propLocation, Message: "プロパティが非推奨になりました"})
+		}
+		d.compareSchema(name, propLocation, baseProp, curProp, request)
+	}
+	for prop := range cur.Properties {
+		if _, ok := base.Properties[prop]; !ok {
+			required := slices.Contains(cur.Required, prop)
+			if request && required {
+				d.add(true, Change{Kind: ChangePropertyAdded, Operation: name, Location: location + "." + prop, Message: "必須のプロパティが追加されました"})
+			} else {
+				d.add(false, Change{Kind: ChangePropertyAdded, Operation: name, Location: location + "." + prop, Message: "プロパティが追加されました"})
+			}
+		}
+	}
+}
+
+// typeLabel は型と形式を表示用の文字列にする
+func typeLabel(t, format string) string {
+	if t == "" {
+		t = "any"
+	}
+	if format != "" {
+		return t + "(" + format + ")"
+	}
+	return t
+}
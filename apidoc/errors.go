@@ -0,0 +1,44 @@
+package apidoc
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// validationDescription はバリデーションエラーのレスポンスの説明
+const validationDescription = "バリデーションエラー。ボディ、クエリ、パス、ヘッダーの不正な値をすべてerrorsにまとめ、それぞれの箇所をJSON Pointerのlocationで示す"
+
+// DocumentValidationErrors はパラメータかリクエストボディのある操作に、バリデーションエラーの422のレスポンスを追加する。
+// huma.OpenAPIのOnAddOperationに登録し、操作の登録時に呼び出す。レスポンスの形式はhuma.NewErrorが返すエラーの型のスキーマにする。
+func DocumentValidationErrors(oapi *huma.OpenAPI, op *huma.Operation) {
+	if len(op.Parameters) == 0 && op.RequestBody == nil {
+		return
+	}
+	status := strconv.Itoa(http.StatusUnprocessableEntity)
+	if _, ok := op.Responses[status]; ok {
+		return
+	}
+
+	exampleErr := huma.NewError(0, "")
+	contentType := "application/json"
+	if ctf, ok := exampleErr.(huma.ContentTypeFilter); ok {
+		contentType = ctf.ContentType(contentType)
+	}
+	errType := reflect.TypeOf(exampleErr)
+	if errType.Kind() == reflect.Pointer {
+		errType = errType.Elem()
+	}
+
+	if op.Responses == nil {
+		op.Responses = map[string]*huma.Response{}
+	}
+	op.Responses[status] = &huma.Response{
+		Description: validationDescription,
+		Content: map[string]*huma.MediaType{
+			contentType: {Schema: oapi.Components.Schemas.Schema(errType, true, errType.Name())},
+		},
+	}
+}
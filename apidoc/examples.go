@@ -0,0 +1,281 @@
+package apidoc
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// デモ用のデータのID。SeedDemoで空のデータベースに投入したときに振られるIDで、操作の例はこれらを参照する
+const (
+	demoMemberID   = 2 // 2番目に登録するユーザー。最初のユーザーは管理者になる
+	demoProjectID  = 1
+	demoTodoID     = 1
+	demoSubtaskID  = 1
+	demoCommentID  = 1
+	demoReminderID = 1
+	demoShareID    = 1
+	demoFilterID   = 1
+	demoAPIKeyID   = 1
+	demoWebhookID  = 1
+)
+
+// demoTodoETag はデモ用のTodoのETag。作成で版が1になり、サブタスクの追加で2になる
+const demoTodoETag = `"2"`
+
+// デモ用のユーザーの資格情報
+const (
+	demoEmail       = "alice@example.com"
+	demoName        = "Alice"
+	demoPassword    = "demo-password"
+	demoMemberEmail = "bob@example.com"
+	demoMemberName  = "Bob"
+	// signupの例は、投入したユーザーと重複せずに登録できるメールアドレスにする
+	demoSignupEmail = "carol@example.com"
+	demoSignupName  = "Carol"
+)
+
+// maxExampleDepth は例を組み立てるときにたどる入れ子のオブジェクトの深さの上限
+const maxExampleDepth = 5
+
+// Examples は/docsで試せる操作の例を1か所で組み立てるファクトリー。
+// フィールドごとのexampleタグではなく、パスから操作の対象のリソースを判断し、リソースごとの値とデモ用のデータのIDで
+// パラメータとリクエストボディの例を作る。日時は生成した日を基準にするため、期限や通知日時が過去にならない。
+type Examples struct {
+	// values はリソース（パスのコレクション名）ごとの、プロパティ名に対応する値。空文字のキーはリソースによらない値
+	values map[string]map[string]any
+	// ids はパスの{id}の直前のコレクション名ごとの、デモ用のデータのID
+	ids map[string]int64
+	// params は{id}以外のパスパラメータの名前ごとの値
+	params map[string]any
+}
+
+// NewExamples はnowの日付を基準にした例を作るExamplesを生成する。日時の例はnowのタイムゾーンで書く
+func NewExamples(now time.Time) *Examples {
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	dueAt := tomorrow.Add(18 * time.Hour).Format(time.RFC3339)
+	remindAt := tomorrow.Add(9 * time.Hour).Format(time.RFC3339)
+	date := tomorrow.Format(time.DateOnly)
+
+	return &Examples{
+		values: map[string]map[string]any{
+			"": {
+				"todo_id":    demoTodoID,
+				"todo_ids":   []any{demoTodoID},
+				"project_id": demoProjectID,
+				"user_id":    demoMemberID,
+				"due_at":     dueAt,
+				"remind_at":  remindAt,
+				"date":       date,
+				"timezone":   now.Location().String(),
+			},
+			"signup": {"email": demoSignupEmail, "name": demoSignupName, "password": demoPassword},
+			"login":  {"email": demoEmail, "password": demoPassword},
+			"users":  {"role": "editor"},
+			// 招待の例は、投入したユーザーとsignupの例のどちらとも重複しないメールアドレスにする
+			"invitations": {"email": "dave@example.com", "role": "viewer"},
+			"projects": {
+				"name":        "買い物",
+				"description": "週末にまとめて買うもの",
+				"wip_limit":   10,
+			},
+			"todos": {
+				"title":       "牛乳を買う",
+				"description": "低脂肪のものを2本",
+				"project_id":  demoProjectID,
+				"due_at":      dueAt,
+				"priority":    2,
+			},
+			"subtasks":  {"title": "スーパーのチラシを確認する"},
+			"comments":  {"author": demoName, "body": "帰りに寄って買います"},
+			"reminders": {"remind_at": remindAt, "channel": "log"},
+			"shares":    {"email": demoMemberEmail, "permission": "write"},
+			"filters":   {"name": "未完了のTodo", "completed": false},
+			"api-keys":  {"name": "デモ用のスクリプト"},
+			"webhooks": {
+				"url":    "https://example.com/hooks/todo",
+				"events": []any{"todo.created", "todo.completed"},
+			},
+			"move":          {"project_id": demoProjectID, "position": 1},
+			"ops":           {"base_revision": 0, "ops": []any{map[string]any{"insert": "近所のスーパーで"}}},
+			"chat-channels": {"webhook_url": "https://discord.com/api/webhooks/123456789/demo-token"},
+		},
+		ids: map[string]int64{
+			"todos":    demoTodoID,
+			"projects": demoProjectID,
+			"users":    demoMemberID,
+			"filters":  demoFilterID,
+			"api-keys": demoAPIKeyID,
+			"webhooks": demoWebhookID,
+		},
+		params: map[string]any{
+			"subtask_id":  demoSubtaskID,
+			"comment_id":  demoCommentID,
+			"reminder_id": demoReminderID,
+			"share_id":    demoShareID,
+		},
+	}
+}
+
+// Document は操作のパスパラメータ、必須のクエリパラメータ、TodoのIf-Matchヘッダー、JSONのリクエストボディに例を設定する。
+// huma.OpenAPIのOnAddOperationに登録し、操作の登録時に呼び出す。
+func (e *Examples) Document(oapi *huma.OpenAPI, op *huma.Operation) {
+	resource := e.resourceOf(op.Path)
+	registry := oapi.Components.Schemas
+	for _, p := range op.Parameters {
+		if p.In != "path" && !p.Required && (p.In != "header" || p.Name != "If-Match") {
+			continue
+		}
+		if v, ok := e.param(op.Path, p, resource, registry); ok {
+			p.Example = v
+		}
+	}
+
+	if op.RequestBody == nil {
+		return
+	}
+	for contentType, mt := range op.RequestBody.Content {
+		if mt.Schema == nil || !isJSON(contentType) {
+			continue
+		}
+		if v, ok := e.value(registry, mt.Schema, resource, "", 0); ok {
+			mt.Example = v
+		}
+	}
+}
+
+// param はパラメータの例を返す。{id}はその直前のコレクションのデモ用のデータのIDにする
+func (e *Examples) param(path string, p *huma.Param, resource string, registry huma.Registry) (any, bool) {
+	if p.In == "path" {
+		if p.Name == "id" {
+			if id, ok := e.ids[collectionOf(path, "{id}")]; ok {
+				return id, true
+			}
+		}
+		if v, ok := e.params[p.Name]; ok {
+			return v, true
+		}
+	}
+	if p.In == "header" && p.Name == "If-Match" {
+		if collectionOf(path, "{id}") != "todos" {
+			return nil, false
+		}
+		return demoTodoETag, true
+	}
+	if p.Schema == nil {
+		return nil, false
+	}
+	return e.value(registry, p.Schema, resource, p.Name, 0)
+}
+
+// value はスキーマの値の例を返す。nameはオブジェクトのプロパティかパラメータの名前で、
+// リソースごとの値、リソースによらない値、スキーマのexample、既定値、列挙の先頭、型に応じた値の順に使う
+func (e *Examples) value(registry huma.Registry, s *huma.Schema, resource, name string, depth int) (any, bool) {
+	if s.Ref != "" {
+		if s = registry.SchemaFromRef(s.Ref); s == nil {
+			return nil, false
+		}
+	}
+	if name != "" {
+		if v, ok := e.values[resource][name]; ok {
+			return v, true
+		}
+		if v, ok := e.values[""][name]; ok {
+			return v, true
+		}
+	}
+	switch {
+	case len(s.Examples) > 0:
+		return s.Examples[0], true
+	case s.Default != nil:
+		return s.Default, true
+	case len(s.Enum) > 0:
+		return s.Enum[0], true
+	}
+
+	switch s.Type {
+	case huma.TypeObject:
+		if depth >= maxExampleDepth {
+			return nil, false
+		}
+		return e.object(registry, s, resource, depth+1), true
+	case huma.TypeArray:
+		if s.Items == nil {
+			return []any{}, true
+		}
+		item, ok := e.value(registry, s.Items, resource, "", depth+1)
+		if !ok {
+			return []any{}, true
+		}
+		return []any{item}, true
+	case huma.TypeString:
+		switch s.Format {
+		case "date-time":
+			return e.values[""]["due_at"], true
+		case "date":
+			return e.values[""]["date"], true
+		case "email":
+			return demoEmail, true
+		case "uri":
+			return "https://example.com", true
+		}
+		return "example", true
+	case huma.TypeInteger, huma.TypeNumber:
+		if s.Minimum != nil {
+			return *s.Minimum, true
+		}
+		return 1, true
+	case huma.TypeBoolean:
+		return false, true
+	}
+	return nil, false
+}
+
+// object はオブジェクトの例を返す。必須のプロパティと、リソースごとの値があるプロパティだけを含め、
+// 任意のプロパティに根拠のない値を入れて操作が失敗しないようにする
+func (e *Examples) object(registry huma.Registry, s *huma.Schema, resource string, depth int) map[string]any {
+	obj := make(map[string]any)
+	for name, prop := range s.Properties {
+		if prop.ReadOnly {
+			continue
+		}
+		_, known := e.values[resource][name]
+		if !known && !slices.Contains(s.Required, name) {
+			continue
+		}
+		if v, ok := e.value(registry, prop, resource, name, depth); ok {
+			obj[name] = v
+		}
+	}
+	return obj
+}
+
+// resourceOf はパスの操作の対象のリソースを、パラメータを除いた末尾から順に、例の値があるコレクション名で返す。
+// /todos/{id}/subtasks/{subtask_id}はsubtasks、/todos/{id}/toggleはtodosになる
+func (e *Examples) resourceOf(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if _, ok := e.values[segments[i]]; ok && segments[i] != "" {
+			return segments[i]
+		}
+	}
+	return ""
+}
+
+// collectionOf はパスでplaceholderの直前にあるセグメントを返す
+func collectionOf(path, placeholder string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 1; i < len(segments); i++ {
+		if segments[i] == placeholder {
+			return segments[i-1]
+		}
+	}
+	return ""
+}
+
+// isJSON はメディアタイプがJSONかを返す
+func isJSON(contentType string) bool {
+	return contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
@@ -0,0 +1,187 @@
+// Package apidoc はOpenAPIのドキュメントの見せ方を設定ファイルで変更する機能を提供する。
+// コードで登録した操作のタグを置き換え、タグの説明と外部ドキュメント、並び順、
+// タグをまとめたグループ（Redocなどが解釈するx-tagGroups）を設定できる。
+package apidoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// OtherGroup はグループに含まれないタグをまとめる末尾のグループの名前
+const OtherGroup = "Other"
+
+// ExternalDocs はタグの外部ドキュメントへのリンクを表す構造体
+type ExternalDocs struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag はタグの説明と外部ドキュメントを表す構造体
+type Tag struct {
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	ExternalDocs *ExternalDocs `json:"external_docs,omitempty"`
+}
+
+// Group はドキュメントで見出しとしてまとめて表示するタグのグループを表す構造体
+type Group struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// TagConfig はOpenAPIのタグの設定を表す構造体
+type TagConfig struct {
+	// Tags はタグの説明。ドキュメントではこの順に並べ、記載のないタグは名前順に後ろへ並べる。
+	Tags []Tag `json:"tags,omitempty"`
+	// Operations は操作IDごとに、コードで指定したタグの代わりに付けるタグ
+	Operations map[string][]string `json:"operations,omitempty"`
+	// Groups はタグのグループ。この順に並べ、どのグループにも含まれないタグはOtherGroupにまとめる。
+	Groups []Group `json:"groups,omitempty"`
+}
+
+// LoadTagConfig はJSONの設定ファイルを読み込む。綴りの誤りに気付けるよう、未知のフィールドはエラーにする。
+func LoadTagConfig(path string) (*TagConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("タグの設定ファイルを読み込めません: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var c TagConfig
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("タグの設定ファイルが不正です: %w", err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// validate は名前の重複や空の名前がないことを検証する
+func (c *TagConfig) validate() error {
+	var errs []error
+	seen := make(map[string]bool)
+	for _, t := range c.Tags {
+		switch {
+		case t.Name == "":
+			errs = append(errs, errors.New("tagsに名前のないタグがあります"))
+		case seen[t.Name]:
+			errs = append(errs, fmt.Errorf("tagsでタグが重複しています: %s", t.Name))
+		}
+		if t.ExternalDocs != nil && t.ExternalDocs.URL == "" {
+			errs = append(errs, fmt.Errorf("タグ%sのexternal_docsにはurlが必要です", t.Name))
+		}
+		seen[t.Name] = true
+	}
+	grouped := make(map[string]string)
+	for _, g := range c.Groups {
+		if g.Name == "" {
+			errs = append(errs, errors.New("groupsに名前のないグループがあります"))
+		}
+		for _, tag := range g.Tags {
+			if other, ok := grouped[tag]; ok {
+				errs = append(errs, fmt.Errorf("タグ%sがグループ%sと%sの両方に含まれています", tag, other, g.Name))
+			}
+			grouped[tag] = g.Name
+		}
+	}
+	for id, tags := range c.Operations {
+		if len(tags) == 0 {
+			errs = append(errs, fmt.Errorf("操作%sには1つ以上のタグを指定してください", id))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// operations はOpenAPIのすべての操作を返す
+func operations(oapi *huma.OpenAPI) []*huma.Operation {
+	var ops []*huma.Operation
+	for _, item := range oapi.Paths {
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch, item.Trace} {
+			if op != nil {
+				ops = append(ops, op)
+			}
+		}
+	}
+	return ops
+}
+
+// Apply は登録済みの操作のタグを置き換え、タグの一覧とグループをOpenAPIに設定する。
+// すべての操作を登録した後、リクエストを受け付ける前に呼び出す。
+// 存在しない操作IDや、どの操作にも付いていないタグをグループに指定した場合はエラーにする。
+func (c *TagConfig) Apply(oapi *huma.OpenAPI) error {
+	var errs []error
+
+	used := make(map[string]bool)
+	found := make(map[string]bool)
+	for _, op := range operations(oapi) {
+		if tags, ok := c.Operations[op.OperationID]; ok {
+			op.Tags = slices.Clone(tags)
+			found[op.OperationID] = true
+		}
+		for _, tag := range op.Tags {
+			used[tag] = true
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(c.Operations)) {
+		if !found[id] {
+			errs = append(errs, fmt.Errorf("operationsの操作が存在しません: %s", id))
+		}
+	}
+
+	// 説明のあるタグを設定の順に並べ、説明のないタグは名前順に後ろへ並べる
+	var tags []*huma.Tag
+	described := make(map[string]bool)
+	for _, t := range c.Tags {
+		tag := &huma.Tag{Name: t.Name, Description: t.Description}
+		if t.ExternalDocs != nil {
+			tag.ExternalDocs = &huma.ExternalDocs{URL: t.ExternalDocs.URL, Description: t.ExternalDocs.Description}
+		}
+		tags = append(tags, tag)
+		described[t.Name] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(used)) {
+		if !described[name] {
+			tags = append(tags, &huma.Tag{Name: name})
+		}
+	}
+	oapi.Tags = tags
+
+	if len(c.Groups) > 0 {
+		grouped := make(map[string]bool)
+		var groups []map[string]any
+		for _, g := range c.Groups {
+			for _, tag := range g.Tags {
+				if !used[tag] && !described[tag] {
+					errs = append(errs, fmt.Errorf("グループ%sのタグが存在しません: %s", g.Name, tag))
+				}
+				grouped[tag] = true
+			}
+			groups = append(groups, map[string]any{"name": g.Name, "tags": g.Tags})
+		}
+		// グループに含まれないタグはドキュメントに表示されなくなるため、末尾のグループにまとめる
+		var rest []string
+		for _, tag := range tags {
+			if !grouped[tag.Name] {
+				rest = append(rest, tag.Name)
+			}
+		}
+		if len(rest) > 0 {
+			groups = append(groups, map[string]any{"name": OtherGroup, "tags": rest})
+		}
+		if oapi.Extensions == nil {
+			oapi.Extensions = make(map[string]any)
+		}
+		oapi.Extensions["x-tagGroups"] = groups
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,147 @@
+// Package audit は認証に関するイベントを記録し、不審なサインインを検知する機能を提供する。
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/notify"
+	"log/slog"
+	"time"
+)
+
+// 認証イベントの種類
+const (
+	// EventLoginSucceeded はサインインの成功
+	EventLoginSucceeded = "login.succeeded"
+	// EventLoginFailed はサインインまたは認証の失敗
+	EventLoginFailed = "login.failed"
+	// EventTokenIssued はトークンの発行
+	EventTokenIssued = "token.issued"
+	// EventKeyUsed はAPIキーやトークンを用いたリクエスト
+	EventKeyUsed = "key.used"
+)
+
+// signInEvents は新しい場所からのサインインの判定対象とするイベントの種類
+var signInEvents = []string{EventLoginSucceeded, EventKeyUsed}
+
+// anonymousSubject は資格情報のないリクエストの認証主体
+const anonymousSubject = "anonymous"
+
+// Subject はAuthorizationヘッダーの値から認証主体を識別する文字列を返す。
+// 資格情報そのものを保存しないよう、SHA-256ハッシュの先頭16文字を用いる。
+func Subject(authorization string) string {
+	if authorization == "" {
+		return anonymousSubject
+	}
+	sum := sha256.Sum256([]byte(authorization))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Attempt は記録する認証の試行を表す構造体
+type Attempt struct {
+	Subject   string
+	EventType string
+	IP        string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+// Client はリクエスト元の端末を表す構造体
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// WithClient はリクエスト元の端末を格納したcontextを返す
+func WithClient(ctx context.Context, c Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+// ClientFrom はcontextに格納されたリクエスト元の端末を返す
+func ClientFrom(ctx context.Context) Client {
+	c, _ := ctx.Value(clientKey{}).(Client)
+	return c
+}
+
+// Recorder は認証イベントを記録し、新しいIPアドレスからのサインインを通知するレコーダー
+type Recorder struct {
+	queries  *db.Queries
+	notifier notify.Notifier
+	channel  string
+	interval time.Duration
+}
+
+// NewRecorder はRecorderの新しいインスタンスを生成する。
+// 同じ認証主体・IPアドレス・端末からの成功したイベントは、interval以内であれば重複して記録しない。
+func NewRecorder(queries *db.Queries, notifier notify.Notifier, channel string, interval time.Duration) *Recorder {
+	return &Recorder{
+		queries:  queries,
+		notifier: notifier,
+		channel:  channel,
+		interval: interval,
+	}
+}
+
+// Record は認証の試行を記録する。
+// 過去に別のIPアドレスからのサインインがある認証主体が新しいIPアドレスからサインインした場合は警告を通知する。
+func (r *Recorder) Record(ctx context.Context, a Attempt) error {
+	now := time.Now().UTC()
+	succeeded := a.EventType != EventLoginFailed
+
+	if succeeded && r.interval > 0 {
+		recent, err := r.queries.HasRecentAuthEvent(ctx, db.HasRecentAuthEventParams{
+			Subject:   a.Subject,
+			EventType: a.EventType,
+			Ip:        a.IP,
+			UserAgent: a.UserAgent,
+			Since:     now.Add(-r.interval),
+		})
+		if err != nil {
+			return fmt.Errorf("認証イベントの確認に失敗: %w", err)
+		}
+		if recent == 1 {
+			return nil
+		}
+	}
+
+	var stats db.GetAuthLocationStatsRow
+	if succeeded && a.Subject != anonymousSubject {
+		var err error
+		stats, err = r.queries.GetAuthLocationStats(ctx, db.GetAuthLocationStatsParams{
+			Ip:         a.IP,
+			Subject:    a.Subject,
+			EventTypes: signInEvents,
+		})
+		if err != nil {
+			return fmt.Errorf("認証履歴の取得に失敗: %w", err)
+		}
+	}
+
+	if _, err := r.queries.CreateAuthEvent(ctx, db.CreateAuthEventParams{
+		Subject:   a.Subject,
+		EventType: a.EventType,
+		Ip:        a.IP,
+		UserAgent: a.UserAgent,
+		CreatedAt: now,
+	}); err != nil {
+		return fmt.Errorf("認証イベントの記録に失敗: %w", err)
+	}
+
+	if stats.Total > 0 && stats.FromIp == 0 {
+		slog.Warn("新しいIPアドレスからのサインインを検知", "subject", a.Subject, "ip", a.IP)
+		if err := r.notifier.Notify(ctx, notify.Notification{
+			Kind:    notify.KindSecurityAlert,
+			Title:   "新しい場所からのサインイン",
+			Message: fmt.Sprintf("認証主体 %s が新しいIPアドレス %s（%s）からサインインしました。", a.Subject, a.IP, a.UserAgent),
+			Channel: r.channel,
+		}); err != nil {
+			return fmt.Errorf("セキュリティ警告の通知に失敗: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-huma-test/db"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// APIKeyHeader はAPIキーを指定するリクエストヘッダー
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyPrefix は発行するAPIキーの接頭辞。ログや設定ファイルに紛れたキーを見分けやすくする。
+const apiKeyPrefix = "tk_"
+
+// apiKeyTouchInterval はAPIキーの最終使用日時を更新する最短の間隔。
+// リクエストのたびに書き込みが発生しないよう、この間隔より古い場合のみ更新する。
+const apiKeyTouchInterval = time.Minute
+
+// apiKeyDisplayLength は一覧で表示するAPIキーの先頭部分の文字数
+const apiKeyDisplayLength = len(apiKeyPrefix) + 8
+
+// NewAPIKey はランダムな256ビットの値から新しいAPIキーを生成する
+func NewAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashAPIKey は保存と照合に用いるAPIキーのSHA-256ハッシュを返す。
+// キーは十分な長さのランダム値のため、ソルトやストレッチングは行わない。
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyDisplayPrefix はキーを識別するために表示する先頭部分を返す
+func APIKeyDisplayPrefix(key string) string {
+	if len(key) <= apiKeyDisplayLength {
+		return key
+	}
+	return key[:apiKeyDisplayLength]
+}
+
+// APIKeySubject はAPIキーで認証したリクエストの認証主体を返す
+func APIKeySubject(id int64) string {
+	return "apikey:" + strconv.FormatInt(id, 10)
+}
+
+// APIKeyAuthenticator はX-API-KeyヘッダーのAPIキーを照合するAuthenticator。
+// 失効していないキーと一致した場合はキーの最終使用日時を記録する。
+type APIKeyAuthenticator struct {
+	queries *db.Queries
+}
+
+// NewAPIKeyAuthenticator はAPIKeyAuthenticatorの新しいインスタンスを生成する
+func NewAPIKeyAuthenticator(queries *db.Queries) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{queries: queries}
+}
+
+// Authenticate はAPIキーの認証主体を返す。ユーザーが作成したキーの場合は、そのユーザーとして認証する。
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, cred Credentials) (*Claims, error) {
+	key := cred.Header(APIKeyHeader)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	k, err := a.queries.GetActiveAPIKeyByHash(ctx, HashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &Unauthorized{
+				Challenge: "APIKey",
+				Message:   "Invalid API key",
+				Err:       fmt.Errorf("APIキーが一致しません: %s", APIKeyDisplayPrefix(key)),
+			}
+		}
+		return nil, fmt.Errorf("APIキーの取得に失敗: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if err := a.queries.TouchAPIKey(ctx, db.TouchAPIKeyParams{
+		UsedAt:      now,
+		ID:          k.ID,
+		StaleBefore: now.Add(-apiKeyTouchInterval),
+	}); err != nil {
+		// 最終使用日時の記録に失敗してもリクエストは処理する
+		slog.Warn("APIキーの最終使用日時の更新に失敗", "id", k.ID, "err", err)
+	}
+
+	if _, ok := ParseUserSubject(k.CreatedBy); ok {
+		return &Claims{Subject: k.CreatedBy}, nil
+	}
+	return &Claims{Subject: APIKeySubject(k.ID)}, nil
+}
+
+// Challenge はAPIキーによる認証を返す
+func (a *APIKeyAuthenticator) Challenge() string {
+	return "APIKey"
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// auth-providersで指定できる認証プロバイダー
+const (
+	// ProviderStatic は秘密情報static_tokensに設定した固定のBearerトークン
+	ProviderStatic = "static"
+	// ProviderJWT はこのサーバーかJWKSの鍵で署名されたBearerトークンのJWT
+	ProviderJWT = "jwt"
+	// ProviderAPIKey は/api-keysで発行したX-API-KeyヘッダーのAPIキー
+	ProviderAPIKey = "api-key"
+	// ProviderOIDC は外部のOIDCプロバイダーが発行したBearerトークンのIDトークン
+	ProviderOIDC = "oidc"
+)
+
+// bearerPrefix はAuthorizationヘッダーでBearerトークンを示す接頭辞
+const bearerPrefix = "bearer "
+
+// ErrNoCredentials はリクエストにAuthenticatorが扱う資格情報がないことを表すエラー。Stackでは次のAuthenticatorで認証を試みる。
+var ErrNoCredentials = errors.New("資格情報がありません")
+
+// Credentials はAuthenticatorが資格情報を読み取るリクエストのヘッダー。huma.Contextが満たす。
+type Credentials interface {
+	Header(name string) string
+}
+
+// Unauthorized は資格情報が不正なため認証に失敗したことを表すエラー。401で応答する。
+type Unauthorized struct {
+	// Challenge はWWW-Authenticateヘッダーの値
+	Challenge string
+	// Message はレスポンスのメッセージ
+	Message string
+	Err     error
+}
+
+// Error はエラーの内容を返す
+func (e *Unauthorized) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap は失敗の原因のエラーを返す
+func (e *Unauthorized) Unwrap() error {
+	return e.Err
+}
+
+// Authenticator はリクエストの資格情報を検証し、認証主体のクレームを返す。
+// 扱う資格情報がない場合はErrNoCredentialsを、資格情報が不正な場合は*Unauthorizedを返す。
+// それ以外のエラーは鍵の取得の失敗などで検証できなかったことを表す。
+type Authenticator interface {
+	Authenticate(ctx context.Context, cred Credentials) (*Claims, error)
+	// Challenge は資格情報がない場合にWWW-Authenticateヘッダーで示す認証方式
+	Challenge() string
+}
+
+// Stack は複数のAuthenticatorを順に試すAuthenticator。
+// 最初にErrNoCredentials以外を返したAuthenticatorの結果を使うため、同じヘッダーを扱うものは対象を絞れるものを先に並べる。
+type Stack []Authenticator
+
+// Authenticate は資格情報を扱うAuthenticatorが見つかるまで順に認証を試みる
+func (s Stack) Authenticate(ctx context.Context, cred Credentials) (*Claims, error) {
+	for _, a := range s {
+		claims, err := a.Authenticate(ctx, cred)
+		if !errors.Is(err, ErrNoCredentials) {
+			return claims, err
+		}
+	}
+	return nil, ErrNoCredentials
+}
+
+// Challenge はすべてのAuthenticatorの認証方式を重複を除いて返す
+func (s Stack) Challenge() string {
+	var challenges []string
+	for _, a := range s {
+		if c := a.Challenge(); !slices.Contains(challenges, c) {
+			challenges = append(challenges, c)
+		}
+	}
+	return strings.Join(challenges, ", ")
+}
+
+// ParseProviders はカンマ区切りの認証プロバイダーの名前を検証し、指定の順に返す
+func ParseProviders(s string) ([]string, error) {
+	var providers []string
+	for name := range strings.SplitSeq(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		switch name {
+		case ProviderStatic, ProviderJWT, ProviderAPIKey, ProviderOIDC:
+		default:
+			return nil, fmt.Errorf("認証プロバイダーにはstatic、jwt、api-key、oidcを指定してください: %s", name)
+		}
+		if slices.Contains(providers, name) {
+			return nil, fmt.Errorf("認証プロバイダーが重複しています: %s", name)
+		}
+		providers = append(providers, name)
+	}
+	if len(providers) == 0 {
+		return nil, errors.New("認証プロバイダーを1つ以上指定してください")
+	}
+	return providers, nil
+}
+
+// bearerToken はAuthorizationヘッダーのBearerトークンを返す。
+// ヘッダーがない場合はErrNoCredentialsを、Bearerトークンでない場合は*Unauthorizedを返す。
+func bearerToken(cred Credentials) (string, error) {
+	authorization := cred.Header("Authorization")
+	if authorization == "" {
+		return "", ErrNoCredentials
+	}
+	if len(authorization) <= len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return "", &Unauthorized{Challenge: `Bearer error="invalid_request"`, Message: "Bearer token required"}
+	}
+	return strings.TrimSpace(authorization[len(bearerPrefix):]), nil
+}
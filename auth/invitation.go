@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// 招待と初期管理者の作成に使うトークンの接頭辞。ログやメッセージに紛れたトークンを見分けやすくする。
+const (
+	invitationTokenPrefix = "inv_"
+	bootstrapTokenPrefix  = "boot_"
+)
+
+// newToken はランダムな256ビットの値から接頭辞付きのトークンを生成する
+func newToken(prefix string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewInvitationToken は招待したユーザーが登録時に指定するトークンを生成する。
+// 保存と照合にはAPIキーと同じくHashAPIKeyのハッシュを用いる。
+func NewInvitationToken() (string, error) {
+	return newToken(invitationTokenPrefix)
+}
+
+// NewBootstrapToken はユーザーが1人もいない状態で、初期管理者を1回だけ作成できるトークンを生成する
+func NewBootstrapToken() (string, error) {
+	return newToken(bootstrapTokenPrefix)
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minJWKSRefetchInterval は未知のkidを受け取った際に、JWKSを取り直す最短の間隔。
+// 不正なkidを大量に送られてもJWKSの提供元へ問い合わせが集中しないようにする。
+const minJWKSRefetchInterval = time.Minute
+
+// maxJWKSBytes は読み込むJWKSの最大サイズ
+const maxJWKSBytes = 1024 * 1024
+
+// jwk はJWKSに含まれる鍵1件を表す構造体
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS はJWKSのURLから取得したRSA公開鍵をkidごとにキャッシュする。
+// キャッシュはrefreshごとに取り直し、鍵のローテーションに追従する。
+type JWKS struct {
+	url     string
+	refresh time.Duration
+	client  *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// NewJWKS はJWKSの新しいインスタンスを生成する
+func NewJWKS(url string, refresh time.Duration) *JWKS {
+	return &JWKS{
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Key はkidに対応する公開鍵を返す。kidが空の場合はJWKSに鍵が1つだけのときその鍵を返す。
+// キャッシュが古い場合や、キャッシュにないkidの場合はJWKSを取り直す。
+func (j *JWKS) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stale := time.Since(j.fetchedAt) >= j.refresh
+	key, found := j.lookup(kid)
+	if stale || (!found && time.Since(j.fetchedAt) >= minJWKSRefetchInterval) {
+		keys, err := j.fetch(ctx)
+		switch {
+		case err == nil:
+			j.keys = keys
+			j.fetchedAt = time.Now()
+			key, found = j.lookup(kid)
+		case j.keys != nil:
+			// 取得に失敗しても、以前に取得した鍵で検証を続ける
+			slog.Warn("JWKSの再取得に失敗したため以前の鍵を使用", "url", j.url, "err", err)
+		default:
+			return nil, err
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: kidに対応する鍵がありません: %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// lookup はキャッシュからkidに対応する鍵を探す
+func (j *JWKS) lookup(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, k := range j.keys {
+			return k, true
+		}
+	}
+	k, ok := j.keys[kid]
+	return k, ok
+}
+
+// Fetch はJWKSを取得し、署名の検証に使えるRSA公開鍵の数を返す。キャッシュは更新しない。
+func (j *JWKS) Fetch(ctx context.Context) (int, error) {
+	keys, err := j.fetch(ctx)
+	return len(keys), err
+}
+
+// fetch はJWKSを取得し、RS256の検証に使えるRSA公開鍵をkidごとに返す
+func (j *JWKS) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("JWKSのリクエスト作成に失敗: %w", err)
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("JWKSの取得に失敗: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKSの取得に失敗: HTTP %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSBytes)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("JWKSの読み込みに失敗: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") || (k.Alg != "" && k.Alg != AlgRS256) {
+			continue
+		}
+		pub, err := k.rsaPublicKey()
+		if err != nil {
+			slog.Warn("JWKSの鍵を読み込めません", "kid", k.Kid, "err", err)
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKSにRS256の公開鍵がありません")
+	}
+	return keys, nil
+}
+
+// rsaPublicKey はJWKのモジュラスと公開指数からRSA公開鍵を組み立てる
+func (k jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeSegment(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("nをデコードできません: %w", err)
+	}
+	e, err := decodeSegment(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("eをデコードできません: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("公開指数が不正です")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
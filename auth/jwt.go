@@ -0,0 +1,318 @@
+// Package auth はBearerトークンとして送られたJWTを検証し、クレームをcontextで受け渡す機能を提供する。
+// HS256は秘密情報プロバイダーから読み込んだ共通鍵で、RS256はJWKSから取得した公開鍵で署名を検証する。
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/secrets"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 対応する署名アルゴリズム
+const (
+	// AlgHS256 はHMAC SHA-256による署名
+	AlgHS256 = "HS256"
+	// AlgRS256 はRSASSA-PKCS1-v1_5 SHA-256による署名
+	AlgRS256 = "RS256"
+)
+
+// ErrInvalidToken はトークンの形式、署名、有効期限のいずれかが不正であることを表すエラー
+var ErrInvalidToken = errors.New("トークンが不正です")
+
+// header はJWTのヘッダーを表す構造体
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// NumericDate はJWTの日時（UNIX時間の秒数）を表す型
+type NumericDate struct {
+	time.Time
+}
+
+// UnmarshalJSON は小数を含む秒数を日時として読み込む
+func (d *NumericDate) UnmarshalJSON(b []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	sec := int64(f)
+	d.Time = time.Unix(sec, int64((f-float64(sec))*1e9)).UTC()
+	return nil
+}
+
+// Audience はJWTのaudクレームを表す型。文字列と文字列の配列のどちらも受け付ける。
+type Audience []string
+
+// UnmarshalJSON は文字列1つの場合も配列として読み込む
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// Claims は検証済みのJWTのクレームを表す構造体
+type Claims struct {
+	Subject   string       `json:"sub"`
+	Issuer    string       `json:"iss"`
+	Audience  Audience     `json:"aud"`
+	ExpiresAt *NumericDate `json:"exp"`
+	NotBefore *NumericDate `json:"nbf"`
+	IssuedAt  *NumericDate `json:"iat"`
+	Scope     string       `json:"scope"`
+	// Extra は登録済みクレーム以外も含むすべてのクレーム
+	Extra map[string]any `json:"-"`
+	// Algorithm はトークンの署名を検証したアルゴリズム
+	Algorithm string `json:"-"`
+}
+
+// externalSubjectPrefix は外部の発行者のJWTで認証したリクエストの認証主体の接頭辞
+const externalSubjectPrefix = "external:"
+
+// ExternalSubject は外部の発行者のJWTで認証したリクエストの認証主体を返す。
+// 発行者ごとにsubの意味が異なるため発行者を含め、このサーバーのユーザー（user:<id>）とは区別する。
+func ExternalSubject(issuer, subject string) string {
+	return externalSubjectPrefix + issuer + "#" + subject
+}
+
+type claimsKey struct{}
+
+// WithClaims は検証済みのクレームを格納したcontextを返す
+func WithClaims(ctx context.Context, c *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, c)
+}
+
+// ClaimsFrom はcontextに格納されたクレームを返す。認証されていない場合はokにfalseを返す。
+func ClaimsFrom(ctx context.Context) (c *Claims, ok bool) {
+	c, ok = ctx.Value(claimsKey{}).(*Claims)
+	return c, ok
+}
+
+// Config はVerifierの設定を表す構造体
+type Config struct {
+	// Secrets はHS256の署名鍵（secrets.JWTSigningKey）を読み込むマネージャー
+	Secrets *secrets.Manager
+	// JWKS はRS256の公開鍵の取得元。nilの場合はRS256のトークンを受け付けない。
+	JWKS *JWKS
+	// Issuer が空でない場合はissクレームが一致することを要求する
+	Issuer string
+	// Audience が空でない場合はaudクレームに含まれることを要求する
+	Audience string
+	// Leeway は有効期限の判定で許容する時計のずれ
+	Leeway time.Duration
+}
+
+// Verifier はJWTの署名とクレームを検証する
+type Verifier struct {
+	secrets  *secrets.Manager
+	jwks     *JWKS
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// NewVerifier はVerifierの新しいインスタンスを生成する
+func NewVerifier(cfg Config) *Verifier {
+	return &Verifier{
+		secrets:  cfg.Secrets,
+		jwks:     cfg.JWKS,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		leeway:   cfg.Leeway,
+		now:      time.Now,
+	}
+}
+
+// decodeSegment はbase64url（パディングなし）でエンコードされたJWTの要素をデコードする
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// Verify はトークンの署名と有効期限、発行者、対象者を検証し、クレームを返す。
+// 検証に失敗した場合はErrInvalidTokenをラップしたエラーを返す。
+// 署名鍵の読み込みに失敗した場合はErrInvalidTokenではないエラーを返す。
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: JWTの形式ではありません", ErrInvalidToken)
+	}
+
+	rawHeader, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: ヘッダーをデコードできません", ErrInvalidToken)
+	}
+	var h header
+	if err := json.Unmarshal(rawHeader, &h); err != nil {
+		return nil, fmt.Errorf("%w: ヘッダーを読み込めません", ErrInvalidToken)
+	}
+	signature, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: 署名をデコードできません", ErrInvalidToken)
+	}
+
+	if err := v.verifySignature(ctx, h, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	payload, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: ペイロードをデコードできません", ErrInvalidToken)
+	}
+	var c Claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, fmt.Errorf("%w: クレームを読み込めません", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(payload, &c.Extra); err != nil {
+		return nil, fmt.Errorf("%w: クレームを読み込めません", ErrInvalidToken)
+	}
+
+	if err := v.validateClaims(&c); err != nil {
+		return nil, err
+	}
+	c.Algorithm = h.Alg
+	return &c, nil
+}
+
+// verifySignature はヘッダーのアルゴリズムに応じた鍵で署名を検証する。
+// HS256の鍵とRS256の鍵は取得元が異なるため、アルゴリズムを偽装しても別の鍵で検証されることはない。
+func (v *Verifier) verifySignature(ctx context.Context, h header, signingInput string, signature []byte) error {
+	switch h.Alg {
+	case AlgHS256:
+		if v.secrets == nil {
+			return fmt.Errorf("%w: HS256は受け付けていません", ErrInvalidToken)
+		}
+		key, _, err := v.secrets.Lookup(ctx, secrets.JWTSigningKey)
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			return fmt.Errorf("%w: HS256の署名鍵が設定されていません", ErrInvalidToken)
+		}
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("%w: 署名が一致しません", ErrInvalidToken)
+		}
+		return nil
+	case AlgRS256:
+		if v.jwks == nil {
+			return fmt.Errorf("%w: RS256は受け付けていません", ErrInvalidToken)
+		}
+		key, err := v.jwks.Key(ctx, h.Kid)
+		if err != nil {
+			return err
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("%w: 署名が一致しません", ErrInvalidToken)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: 未対応の署名アルゴリズムです: %q", ErrInvalidToken, h.Alg)
+	}
+}
+
+// validateClaims は有効期限、発行者、対象者を検証する
+func (v *Verifier) validateClaims(c *Claims) error {
+	now := v.now()
+	if c.ExpiresAt == nil {
+		return fmt.Errorf("%w: expクレームがありません", ErrInvalidToken)
+	}
+	if !now.Before(c.ExpiresAt.Add(v.leeway)) {
+		return fmt.Errorf("%w: 有効期限が切れています", ErrInvalidToken)
+	}
+	if c.NotBefore != nil && now.Add(v.leeway).Before(c.NotBefore.Time) {
+		return fmt.Errorf("%w: まだ有効になっていません", ErrInvalidToken)
+	}
+	if v.issuer != "" && c.Issuer != v.issuer {
+		return fmt.Errorf("%w: 発行者が一致しません: %q", ErrInvalidToken, c.Issuer)
+	}
+	if v.audience != "" && !slices.Contains(c.Audience, v.audience) {
+		return fmt.Errorf("%w: 対象者が一致しません", ErrInvalidToken)
+	}
+	return nil
+}
+
+// issuedHere はトークンがこのサーバーのSignerで発行したものかを返す。
+// Signerは署名鍵を共有するHS256で、Verifierと同じ発行者を設定して発行する。JWKSで検証したRS256のトークンは外部の発行者のものとする。
+func (v *Verifier) issuedHere(c *Claims) bool {
+	return c.Algorithm == AlgHS256 && c.Issuer == v.issuer
+}
+
+// unverifiedIssuer は署名を検証せずにトークンのissクレームを返す。
+// 複数の発行者のトークンを受け付ける場合に検証に用いるAuthenticatorを選ぶためだけに使い、認証には用いない。
+func unverifiedIssuer(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := decodeSegment(parts[1])
+	if err != nil {
+		return ""
+	}
+	var c struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return ""
+	}
+	return c.Issuer
+}
+
+// JWTAuthenticator はAuthorizationヘッダーのBearerトークンをJWTとして検証するAuthenticator
+type JWTAuthenticator struct {
+	verifier *Verifier
+}
+
+// NewJWTAuthenticator はJWTAuthenticatorの新しいインスタンスを生成する
+func NewJWTAuthenticator(verifier *Verifier) *JWTAuthenticator {
+	return &JWTAuthenticator{verifier: verifier}
+}
+
+// Authenticate はBearerトークンの署名とクレームを検証する。
+// このサーバーが発行したトークン以外は、subがuser:<id>でもユーザーとして扱わないよう、認証主体をExternalSubjectにする。
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, cred Credentials) (*Claims, error) {
+	token, err := bearerToken(cred)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := a.verifier.Verify(ctx, token)
+	if errors.Is(err, ErrInvalidToken) {
+		return nil, &Unauthorized{Challenge: `Bearer error="invalid_token"`, Message: "Invalid token", Err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !a.verifier.issuedHere(claims) {
+		claims.Subject = ExternalSubject(claims.Issuer, claims.Subject)
+	}
+	return claims, nil
+}
+
+// Challenge はBearer認証を返す
+func (a *JWTAuthenticator) Challenge() string {
+	return "Bearer"
+}
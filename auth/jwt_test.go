@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"go-huma-test/secrets"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testSigningKey = "test-signing-key"
+
+// mapProvider はテスト用にmapから秘密情報を返すProvider
+type mapProvider map[string]string
+
+func (p mapProvider) Get(_ context.Context, name string) (string, error) {
+	v, ok := p[name]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+	return v, nil
+}
+
+// headers はテスト用のCredentials
+type headers map[string]string
+
+func (h headers) Header(name string) string {
+	return h[name]
+}
+
+func testSecrets() *secrets.Manager {
+	return secrets.NewManager(mapProvider{secrets.JWTSigningKey: testSigningKey}, time.Minute)
+}
+
+// signRS256 はRS256で署名したトークンを返す
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	rawHeader, _ := json.Marshal(map[string]string{"alg": AlgRS256, "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(rawHeader) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// jwksServer はkeyの公開鍵をkidで返すJWKSのサーバーを起動し、取得された回数を数える
+func jwksServer(t *testing.T, key *rsa.PrivateKey, kid string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{"kty": "EC", "kid": "ignored"},
+			{
+				"kty": "RSA", "kid": kid, "use": "sig", "alg": AlgRS256,
+				"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			},
+		}})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &fetches
+}
+
+func TestSignerTokenVerifies(t *testing.T) {
+	ctx := context.Background()
+	signer := NewSigner(SignerConfig{Secrets: testSecrets(), Issuer: "https://todo.example", Audience: "todo", TTL: time.Hour})
+	token, expiresAt, err := signer.Sign(ctx, UserSubject(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(expiresAt); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("expiresAt = %v", expiresAt)
+	}
+
+	verifier := NewVerifier(Config{Secrets: testSecrets(), Issuer: "https://todo.example", Audience: "todo"})
+	claims, err := verifier.Verify(ctx, token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != "user:1" || claims.Algorithm != AlgHS256 || claims.Extra["sub"] != "user:1" {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	// 署名を改ざんしたトークン
+	tampered := token[:len(token)-2] + "AA"
+	if _, err := verifier.Verify(ctx, tampered); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("tampered token: err = %v", err)
+	}
+	// 別の鍵で署名したトークン
+	other := NewVerifier(Config{Secrets: secrets.NewManager(mapProvider{secrets.JWTSigningKey: "other"}, time.Minute)})
+	if _, err := other.Verify(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("other key: err = %v", err)
+	}
+}
+
+func TestVerifierValidatesClaims(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sign := func(issuer, audience string, ttl time.Duration) string {
+		s := NewSigner(SignerConfig{Secrets: testSecrets(), Issuer: issuer, Audience: audience, TTL: ttl})
+		s.now = func() time.Time { return now }
+		token, _, err := s.Sign(ctx, "user:1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		at    time.Time
+		ok    bool
+	}{
+		{"有効", sign("iss", "aud", time.Hour), now.Add(time.Minute), true},
+		{"期限切れ", sign("iss", "aud", time.Hour), now.Add(time.Hour + time.Minute), false},
+		{"許容するずれの範囲内", sign("iss", "aud", time.Hour), now.Add(time.Hour + 20*time.Second), true},
+		{"発行者が異なる", sign("other", "aud", time.Hour), now, false},
+		{"対象者が異なる", sign("iss", "other", time.Hour), now, false},
+		{"JWTでない", "abc.def", now, false},
+		{"alg none", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + ".e30.", now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier(Config{Secrets: testSecrets(), Issuer: "iss", Audience: "aud", Leeway: 30 * time.Second})
+			v.now = func() time.Time { return tt.at }
+			_, err := v.Verify(ctx, tt.token)
+			if tt.ok && err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify: err = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifierRS256WithJWKS(t *testing.T) {
+	ctx := context.Background()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, fetches := jwksServer(t, key, "k1")
+	verifier := NewVerifier(Config{JWKS: NewJWKS(srv.URL, time.Hour)})
+
+	claims := map[string]any{"sub": "alice", "iss": "https://idp.example", "exp": time.Now().Add(time.Hour).Unix()}
+	for range 2 {
+		got, err := verifier.Verify(ctx, signRS256(t, key, "k1", claims))
+		if err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+		if got.Subject != "alice" || got.Algorithm != AlgRS256 {
+			t.Errorf("claims = %+v", got)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("JWKS fetched %d times, want 1 (cached)", n)
+	}
+
+	// 未知のkidは直前に取得したばかりのため取り直さずに拒否する
+	if _, err := verifier.Verify(ctx, signRS256(t, key, "unknown", claims)); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("unknown kid: err = %v", err)
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("JWKS fetched %d times after unknown kid, want 1", n)
+	}
+
+	// JWKSの鍵と異なる鍵で署名したトークン
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(ctx, signRS256(t, otherKey, "k1", claims)); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("other key: err = %v", err)
+	}
+
+	// HS256の鍵がないVerifierはHS256を受け付けない
+	token, _, err := NewSigner(SignerConfig{Secrets: testSecrets(), TTL: time.Hour}).Sign(ctx, "user:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("HS256 without secrets: err = %v", err)
+	}
+}
+
+func TestJWTAuthenticatorMapsOnlyLocalUsers(t *testing.T) {
+	ctx := context.Background()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, _ := jwksServer(t, key, "k1")
+	authenticator := NewJWTAuthenticator(NewVerifier(Config{Secrets: testSecrets(), JWKS: NewJWKS(srv.URL, time.Hour)}))
+
+	local, _, err := NewSigner(SignerConfig{Secrets: testSecrets(), TTL: time.Hour}).Sign(ctx, "user:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	external := signRS256(t, key, "k1", map[string]any{"sub": "user:1", "iss": "https://idp.example", "exp": time.Now().Add(time.Hour).Unix()})
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"このサーバーが発行したトークン", local, "user:1"},
+		{"外部の発行者のトークン", external, "external:https://idp.example#user:1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := authenticator.Authenticate(ctx, headers{"Authorization": "Bearer " + tt.token})
+			if err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+			if claims.Subject != tt.want {
+				t.Errorf("Subject = %q, want %q", claims.Subject, tt.want)
+			}
+			_, isUser := ParseUserSubject(claims.Subject)
+			if isUser != strings.HasPrefix(tt.want, "user:") {
+				t.Errorf("ParseUserSubject(%q) = %v", claims.Subject, isUser)
+			}
+		})
+	}
+
+	var unauthorized *Unauthorized
+	if _, err := authenticator.Authenticate(ctx, headers{"Authorization": "Bearer abc"}); !errors.As(err, &unauthorized) {
+		t.Errorf("invalid token: err = %v, want *Unauthorized", err)
+	}
+	if _, err := authenticator.Authenticate(ctx, headers{}); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("no credentials: err = %v", err)
+	}
+}
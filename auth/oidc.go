@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/secrets"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxOIDCResponseBytes は読み込むディスカバリーとトークンの応答の最大サイズ
+const maxOIDCResponseBytes = 1024 * 1024
+
+// ErrOIDCProvider はOIDCプロバイダーとの通信に失敗したか、プロバイダーが不正な応答を返したことを表すエラー
+var ErrOIDCProvider = errors.New("OIDCプロバイダーとの通信に失敗しました")
+
+// OIDCConfig はOIDCの設定を表す構造体
+type OIDCConfig struct {
+	// Issuer はOIDCプロバイダーの発行者。/.well-known/openid-configurationからエンドポイントを取得する。
+	Issuer string
+	// ClientID はプロバイダーに登録したクライアントID。IDトークンのaudクレームとして要求する。
+	ClientID string
+	// RedirectURL はプロバイダーに登録したコールバックのURL
+	RedirectURL string
+	// Scopes は要求するスコープ。openidは常に含める。
+	Scopes []string
+	// Secrets はクライアントシークレット（secrets.OIDCClientSecret）を読み込むマネージャー
+	Secrets *secrets.Manager
+	// JWKSRefresh はプロバイダーの公開鍵をキャッシュする時間
+	JWKSRefresh time.Duration
+	// Leeway はIDトークンの有効期限の判定で許容する時計のずれ
+	Leeway time.Duration
+}
+
+// oidcDiscovery はOIDCプロバイダーのディスカバリー文書のうち、ログインに用いる項目を表す構造体
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDC は認可コードフロー（PKCE付き）で外部のOIDCプロバイダーにログインし、IDトークンを検証する。
+// エンドポイントと公開鍵は初回の利用時にディスカバリー文書から取得する。
+type OIDC struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	verifier  *Verifier
+}
+
+// NewOIDC はOIDCの新しいインスタンスを生成する
+func NewOIDC(cfg OIDCConfig) *OIDC {
+	return &OIDC{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// OpenIDConfigurationURL は発行者のディスカバリー文書のURLを返す
+func OpenIDConfigurationURL(issuer string) string {
+	return strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+}
+
+// NewOIDCState はstate、nonce、PKCEのコード検証子に用いるランダムな文字列を生成する
+func NewOIDCState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// codeChallenge はPKCEのコード検証子からS256のコードチャレンジを求める
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// discover はディスカバリー文書を取得し、IDトークンの検証に用いるVerifierを準備する。
+// 取得に成功した結果はプロセスの終了まで使い続ける。
+func (o *OIDC) discover(ctx context.Context) (*oidcDiscovery, *Verifier, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.discovery != nil {
+		return o.discovery, o.verifier, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, OpenIDConfigurationURL(o.cfg.Issuer), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: ディスカバリーのリクエスト作成に失敗: %v", ErrOIDCProvider, err)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: ディスカバリー文書の取得に失敗: %v", ErrOIDCProvider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%w: ディスカバリー文書の取得に失敗: HTTP %d", ErrOIDCProvider, resp.StatusCode)
+	}
+
+	var d oidcDiscovery
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOIDCResponseBytes)).Decode(&d); err != nil {
+		return nil, nil, fmt.Errorf("%w: ディスカバリー文書の読み込みに失敗: %v", ErrOIDCProvider, err)
+	}
+	// 発行者が一致しない文書は別のプロバイダーのものの可能性があるため使わない
+	if d.Issuer != o.cfg.Issuer {
+		return nil, nil, fmt.Errorf("%w: ディスカバリー文書の発行者が一致しません: %q", ErrOIDCProvider, d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("%w: ディスカバリー文書に必要なエンドポイントがありません", ErrOIDCProvider)
+	}
+
+	o.discovery = &d
+	o.verifier = NewVerifier(Config{
+		JWKS:     NewJWKS(d.JWKSURI, o.cfg.JWKSRefresh),
+		Issuer:   d.Issuer,
+		Audience: o.cfg.ClientID,
+		Leeway:   o.cfg.Leeway,
+	})
+	return o.discovery, o.verifier, nil
+}
+
+// Discover はディスカバリー文書を取得できることを確認し、認可エンドポイントを返す
+func (o *OIDC) Discover(ctx context.Context) (string, error) {
+	d, _, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return d.AuthorizationEndpoint, nil
+}
+
+// AuthCodeURL はプロバイダーのログイン画面へリダイレクトするURLを返す。
+// stateとnonceはコールバックで照合し、codeVerifierはExchangeに渡す。
+func (o *OIDC) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	d, _, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := []string{"openid"}
+	for _, s := range o.cfg.Scopes {
+		s = strings.TrimSpace(s)
+		if s != "" && s != "openid" {
+			scopes = append(scopes, s)
+		}
+	}
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {o.cfg.ClientID},
+		"redirect_uri":          {o.cfg.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange は認可コードをトークンエンドポイントでIDトークンと交換し、検証したクレームを返す。
+// IDトークンの署名、発行者、対象者、有効期限に加えて、nonceがログイン開始時のものと一致することを検証する。
+// IDトークンが不正な場合はErrInvalidTokenを、プロバイダーとの通信に失敗した場合はErrOIDCProviderをラップしたエラーを返す。
+func (o *OIDC) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	d, verifier, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clientSecret, _, err := o.cfg.Secrets.Lookup(ctx, secrets.OIDCClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {o.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	// シークレットがない場合は公開クライアントとしてclient_idのみを送る
+	if clientSecret == "" {
+		form.Set("client_id", o.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: トークンのリクエスト作成に失敗: %v", ErrOIDCProvider, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientSecret != "" {
+		// client_secret_basicではクライアントIDとシークレットをURLエンコードしてから送る
+		req.SetBasicAuth(url.QueryEscape(o.cfg.ClientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: トークンの取得に失敗: %v", ErrOIDCProvider, err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOIDCResponseBytes)).Decode(&token); err != nil {
+		return nil, fmt.Errorf("%w: トークンの応答を読み込めません: HTTP %d", ErrOIDCProvider, resp.StatusCode)
+	}
+	if token.Error != "" {
+		// 期限切れや使用済みの認可コードはプロバイダーではなく利用者側の問題として扱う
+		if token.Error == "invalid_grant" {
+			return nil, fmt.Errorf("%w: 認可コードが不正です: %s", ErrInvalidToken, token.ErrorDescription)
+		}
+		return nil, fmt.Errorf("%w: トークンの取得に失敗: %s %s", ErrOIDCProvider, token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || token.IDToken == "" {
+		return nil, fmt.Errorf("%w: IDトークンが返されませんでした: HTTP %d", ErrOIDCProvider, resp.StatusCode)
+	}
+
+	claims, err := verifier.Verify(ctx, token.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := claims.Extra["nonce"].(string); n != nonce {
+		return nil, fmt.Errorf("%w: nonceが一致しません", ErrInvalidToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: subクレームがありません", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// OIDCAuthenticator は外部のOIDCプロバイダーが発行したIDトークンをBearerトークンとして検証するAuthenticator。
+// /auth/oidc/loginでログインしてユーザーに紐づいたアカウントのみを受け付け、そのユーザーとして認証する。
+// 発行者がプロバイダーと異なるトークンはErrNoCredentialsとするため、JWTなど同じBearerトークンを扱うものより前に並べる。
+type OIDCAuthenticator struct {
+	provider *OIDC
+	queries  *db.Queries
+}
+
+// NewOIDCAuthenticator はOIDCAuthenticatorの新しいインスタンスを生成する
+func NewOIDCAuthenticator(provider *OIDC, queries *db.Queries) *OIDCAuthenticator {
+	return &OIDCAuthenticator{provider: provider, queries: queries}
+}
+
+// Authenticate はIDトークンを検証し、アカウントに紐づいたユーザーのクレームを返す
+func (a *OIDCAuthenticator) Authenticate(ctx context.Context, cred Credentials) (*Claims, error) {
+	token, err := bearerToken(cred)
+	if err != nil || unverifiedIssuer(token) != a.provider.cfg.Issuer {
+		return nil, ErrNoCredentials
+	}
+	_, verifier, err := a.provider.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := verifier.Verify(ctx, token)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			return nil, &Unauthorized{Challenge: `Bearer error="invalid_token"`, Message: "Invalid token", Err: err}
+		}
+		return nil, err
+	}
+
+	user, err := a.queries.GetUserByIdentity(ctx, db.GetUserByIdentityParams{Issuer: a.provider.cfg.Issuer, Subject: claims.Subject})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &Unauthorized{
+				Challenge: `Bearer error="invalid_token"`,
+				Message:   "OIDC account is not registered. Log in via /auth/oidc/login first",
+				Err:       fmt.Errorf("ユーザーに紐づいていないOIDCアカウントです: %s", claims.Subject),
+			}
+		}
+		return nil, fmt.Errorf("ユーザーの取得に失敗: %w", err)
+	}
+	claims.Subject = UserSubject(user.ID)
+	return claims, nil
+}
+
+// Challenge はBearer認証を返す
+func (a *OIDCAuthenticator) Challenge() string {
+	return "Bearer"
+}
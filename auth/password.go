@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// passwordScheme はパスワードハッシュの形式を表す接頭辞
+const passwordScheme = "pbkdf2-sha256"
+
+// passwordIterations はPBKDF2の反復回数
+const passwordIterations = 600000
+
+// passwordKeyLength はPBKDF2で導出する鍵のバイト数
+const passwordKeyLength = 32
+
+// ErrPasswordMismatch はパスワードがハッシュと一致しないことを表すエラー
+var ErrPasswordMismatch = errors.New("パスワードが一致しません")
+
+// HashPassword はランダムなソルトを用いてパスワードをPBKDF2-SHA256でハッシュ化する。
+// 戻り値は "pbkdf2-sha256$反復回数$ソルト$ハッシュ" の形式で、反復回数を変えても以前のハッシュを検証できる。
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeyLength)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{
+		passwordScheme,
+		strconv.Itoa(passwordIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+// VerifyPassword はパスワードがHashPasswordで生成したハッシュと一致するか検証する。
+// 一致しない場合はErrPasswordMismatchを返す。
+func VerifyPassword(encoded, password string) error {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != passwordScheme {
+		return errors.New("パスワードハッシュの形式が不正です")
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return fmt.Errorf("パスワードハッシュの反復回数が不正です: %q", parts[1])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("パスワードハッシュのソルトをデコードできません: %w", err)
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return fmt.Errorf("パスワードハッシュをデコードできません: %w", err)
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iter, len(want))
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(got, want) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package auth
+
+import "context"
+
+// Role はユーザーに許可された操作の範囲を表す型
+type Role string
+
+// ロールの種類。後ろのものほど多くの操作を許可し、前のロールの権限をすべて含む。
+const (
+	// RoleViewer は読み取りのみ
+	RoleViewer Role = "viewer"
+	// RoleEditor はTodoやプロジェクトの変更
+	RoleEditor Role = "editor"
+	// RoleAdmin はAPIキーやユーザーのロールなど、認証に関わる管理
+	RoleAdmin Role = "admin"
+)
+
+// roleRank はロールの強さを表す値
+var roleRank = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// Valid は定義済みのロールかを返す
+func (r Role) Valid() bool {
+	_, ok := roleRank[r]
+	return ok
+}
+
+// Allows はロールがrequiredで許可された操作を行えるかを返す。未定義のロールは何も許可しない。
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRank[r]
+	return ok && rank >= roleRank[required]
+}
+
+type roleKey struct{}
+
+// WithRole は認可に用いるロールを格納したcontextを返す
+func WithRole(ctx context.Context, r Role) context.Context {
+	return context.WithValue(ctx, roleKey{}, r)
+}
+
+// RoleFrom はcontextに格納されたロールを返す。格納されていない場合はokにfalseを返す。
+func RoleFrom(ctx context.Context) (r Role, ok bool) {
+	r, ok = ctx.Value(roleKey{}).(Role)
+	return r, ok
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"go-huma-test/secrets"
+	"strings"
+)
+
+// StaticTokenAuthenticator は秘密情報static_tokensに設定した固定のBearerトークンを照合するAuthenticator。
+// 値は"<認証主体>=<トークン>"をカンマ区切りで並べ、ログインできないCIやバッチに認証主体を割り当てる。
+// 一致しないトークンはErrNoCredentialsとするため、JWTなど同じBearerトークンを扱うものより前に並べる。
+type StaticTokenAuthenticator struct {
+	secrets *secrets.Manager
+}
+
+// NewStaticTokenAuthenticator はStaticTokenAuthenticatorの新しいインスタンスを生成する
+func NewStaticTokenAuthenticator(secretManager *secrets.Manager) *StaticTokenAuthenticator {
+	return &StaticTokenAuthenticator{secrets: secretManager}
+}
+
+// Authenticate はBearerトークンに一致する固定のトークンの認証主体を返す
+func (a *StaticTokenAuthenticator) Authenticate(ctx context.Context, cred Credentials) (*Claims, error) {
+	token, err := bearerToken(cred)
+	if err != nil {
+		// Bearerトークンでない資格情報は後に並べたAuthenticatorに任せる
+		return nil, ErrNoCredentials
+	}
+	value, _, err := a.secrets.Lookup(ctx, secrets.StaticTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	// 長さの違いから推測されないよう、ハッシュどうしを一定時間で比較する
+	sum := sha256.Sum256([]byte(token))
+	var subject string
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		sub, t, ok := strings.Cut(entry, "=")
+		if !ok || sub == "" || t == "" {
+			return nil, errors.New("static_tokensは<認証主体>=<トークン>をカンマ区切りで指定してください")
+		}
+		want := sha256.Sum256([]byte(t))
+		if subtle.ConstantTimeCompare(sum[:], want[:]) == 1 && subject == "" {
+			subject = sub
+		}
+	}
+	if subject == "" {
+		return nil, ErrNoCredentials
+	}
+	return &Claims{Subject: subject}, nil
+}
+
+// Challenge はBearer認証を返す
+func (a *StaticTokenAuthenticator) Challenge() string {
+	return "Bearer"
+}
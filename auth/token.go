@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"go-huma-test/secrets"
+	"time"
+)
+
+// SignerConfig はSignerの設定を表す構造体
+type SignerConfig struct {
+	// Secrets はHS256の署名鍵（secrets.JWTSigningKey）を読み込むマネージャー
+	Secrets *secrets.Manager
+	// Issuer が空でない場合はissクレームに設定する
+	Issuer string
+	// Audience が空でない場合はaudクレームに設定する
+	Audience string
+	// TTL は発行するトークンの有効期間
+	TTL time.Duration
+}
+
+// Signer はログインしたユーザーにHS256で署名したJWTを発行する。
+// Verifierと同じ署名鍵、発行者、対象者を用いるため、発行したトークンはそのままBearer認証に使える。
+type Signer struct {
+	secrets  *secrets.Manager
+	issuer   string
+	audience string
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewSigner はSignerの新しいインスタンスを生成する
+func NewSigner(cfg SignerConfig) *Signer {
+	return &Signer{
+		secrets:  cfg.Secrets,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		ttl:      cfg.TTL,
+		now:      time.Now,
+	}
+}
+
+// Sign は認証主体をsubクレームに持つトークンを発行し、トークンと有効期限を返す
+func (s *Signer) Sign(ctx context.Context, subject string) (string, time.Time, error) {
+	key, _, err := s.secrets.Lookup(ctx, secrets.JWTSigningKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if key == "" {
+		return "", time.Time{}, errors.New("HS256の署名鍵が設定されていません")
+	}
+
+	now := s.now().UTC()
+	expiresAt := now.Add(s.ttl)
+	claims := map[string]any{
+		"sub": subject,
+		"iat": now.Unix(),
+		"exp": expiresAt.Unix(),
+	}
+	if s.issuer != "" {
+		claims["iss"] = s.issuer
+	}
+	if s.audience != "" {
+		claims["aud"] = s.audience
+	}
+
+	rawHeader, err := json.Marshal(map[string]string{"alg": AlgHS256, "typ": "JWT"})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(rawHeader) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), expiresAt, nil
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// userSubjectPrefix はユーザーとして認証したリクエストの認証主体の接頭辞
+const userSubjectPrefix = "user:"
+
+// UserSubject はユーザーIDに対応する認証主体を返す
+func UserSubject(id int64) string {
+	return userSubjectPrefix + strconv.FormatInt(id, 10)
+}
+
+// ParseUserSubject は認証主体がユーザーを表す場合にそのIDを返す
+func ParseUserSubject(sub string) (int64, bool) {
+	rest, ok := strings.CutPrefix(sub, userSubjectPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(rest, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+type userIDKey struct{}
+
+// WithUserID は認証したユーザーのIDを格納したcontextを返す
+func WithUserID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, userIDKey{}, id)
+}
+
+// UserIDFrom はcontextに格納されたユーザーIDを返す。ユーザーとして認証されていない場合はokにfalseを返す。
+func UserIDFrom(ctx context.Context) (id int64, ok bool) {
+	id, ok = ctx.Value(userIDKey{}).(int64)
+	return id, ok
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"go-huma-test/backup"
+	"go-huma-test/migrate"
+	"go-huma-test/model"
+	"os"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+)
+
+// backupResult はbackupサブコマンドが出力するバックアップ1件を表す構造体
+type backupResult struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// newBackupResult はバックアップを出力する形に変換する
+func newBackupResult(b backup.Info) backupResult {
+	return backupResult{Name: b.Name, Path: b.Path, Size: b.Size, CreatedAt: b.CreatedAt}
+}
+
+// newBackupCommand はデータベースのバックアップを作成、復元するbackupサブコマンドを生成する。
+// 結果はJSONで標準出力に書き出し、失敗した場合は終了コード1で終了する。
+func newBackupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up the database or restore it from a backup",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "now",
+		Short: "Write a backup to backup-dir and delete backups beyond backup-retention",
+		Args:  cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, o *model.Options) {
+			sqlDB := openBackupTarget(o.DatabaseURL)
+			info, err := backup.NewManager(sqlDB, o.BackupDir, o.BackupRetention).Create(cmd.Context())
+			_ = sqlDB.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			writeJSON(map[string]any{"backup": newBackupResult(info)})
+		}),
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "restore <file>",
+		Short: "Replace the database with a backup, even while the server is running",
+		Long: "Checks the backup with integrity_check, writes a backup of the current database to backup-dir, " +
+			"then copies the backup over the database with the SQLite online backup API. " +
+			"A running server reads the restored data from its next query, though responses it has cached are served until they expire. " +
+			"Migrations missing from the backup are applied afterwards when auto-migrate is set.",
+		Args: cobra.ExactArgs(1),
+		Run: humacli.WithOptions(func(cmd *cobra.Command, args []string, o *model.Options) {
+			ctx := cmd.Context()
+
+			if _, err := os.Stat(args[0]); err != nil {
+				fmt.Fprintln(os.Stderr, "バックアップを開けません:", err)
+				os.Exit(1)
+			}
+			src, err := openDB(args[0], true)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			defer func() {
+				_ = src.Close()
+			}()
+			if err := backup.Verify(ctx, src); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			// 新しいバージョンで作成したバックアップは、このバージョンでは移行を戻せないため復元しない
+			srcMigrator, err := newMigrator(src)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			statuses, err := srcMigrator.Status(ctx)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			for _, s := range statuses {
+				if s.Unknown {
+					fmt.Fprintf(os.Stderr, "バックアップにはこのバージョンの知らない移行が適用されています: %d_%s\n", s.Version, s.Name)
+					os.Exit(1)
+				}
+			}
+
+			dst := openBackupTarget(o.DatabaseURL)
+			defer func() {
+				_ = dst.Close()
+			}()
+
+			// 誤って復元した場合に戻せるよう、置き換える前の内容をバックアップする
+			current, err := backup.NewManager(dst, o.BackupDir, o.BackupRetention).Create(ctx)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			if err := backup.Restore(ctx, dst, src); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				fmt.Fprintln(os.Stderr, "復元前のバックアップ:", current.Path)
+				os.Exit(1)
+			}
+
+			// 古いバージョンで作成したバックアップにない移行を適用し、実行中のサーバーのクエリと合わせる
+			applied := []migrationResult{}
+			var migrateErr error
+			if o.AutoMigrate {
+				migrator, err := newMigrator(dst)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+				var migrations []migrate.Migration
+				migrations, migrateErr = migrator.Up(ctx)
+				for _, m := range migrations {
+					applied = append(applied, migrationResult{Version: m.Version, Name: m.Name})
+				}
+			}
+			writeJSON(map[string]any{
+				"restored": args[0],
+				"previous": newBackupResult(current),
+				"applied":  applied,
+			})
+			if migrateErr != nil {
+				fmt.Fprintln(os.Stderr, migrateErr)
+				os.Exit(1)
+			}
+		}),
+	})
+	return cmd
+}
+
+// openBackupTarget はdatabase-urlのデータベースを開く。
+// インメモリのデータベースは別のプロセスから読み書きできないため、失敗した場合と同様に終了コード1で終了する。
+func openBackupTarget(databaseURL string) *sql.DB {
+	dbPath, err := sqlitePath(databaseURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if dbPath == memoryDatabase {
+		fmt.Fprintln(os.Stderr, "インメモリのデータベースはサーバーのプロセスの外からバックアップや復元ができません。POST /admin/backupsを使ってください")
+		os.Exit(1)
+	}
+	sqlDB, err := openDB(dbPath, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return sqlDB
+}
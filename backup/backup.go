@@ -0,0 +1,274 @@
+// Package backup はSQLiteのデータベースのバックアップの作成、一覧、削除と、バックアップからの復元を提供する。
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// バックアップのファイル名は作成日時の順に並ぶよう、接頭辞とUTCの作成日時と拡張子で構成する
+const (
+	filePrefix = "todos-"
+	fileSuffix = ".db"
+	timeLayout = "20060102T150405.000Z"
+)
+
+// ErrNotFound は指定された名前のバックアップが存在しないことを表すエラー
+var ErrNotFound = errors.New("バックアップが見つかりません")
+
+// Info はバックアップ1件を表す構造体
+type Info struct {
+	Name      string
+	Path      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Manager はバックアップ用のディレクトリにバックアップを作成し、保持する件数を超えた古いものを削除する
+type Manager struct {
+	db     *sql.DB
+	dir    string
+	retain int
+
+	// 同時に作成や削除を行わないよう直列にする
+	mu sync.Mutex
+}
+
+// NewManager はManagerの新しいインスタンスを生成する。retainが0の場合は古いバックアップを削除しない。
+func NewManager(db *sql.DB, dir string, retain int) *Manager {
+	return &Manager{
+		db:     db,
+		dir:    dir,
+		retain: retain,
+	}
+}
+
+// Dir はバックアップを保存するディレクトリを返す
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// Create はデータベースのバックアップを作成し、保持する件数を超えた古いバックアップを削除する。
+// VACUUM INTOは読み取りのトランザクションで一貫した時点の内容を書き出すため、書き込みを止めずに実行できる。
+func (m *Manager) Create(ctx context.Context) (Info, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return Info{}, fmt.Errorf("バックアップ用のディレクトリの作成に失敗: %w", err)
+	}
+
+	createdAt := time.Now().UTC().Truncate(time.Millisecond)
+	name := filePrefix + createdAt.Format(timeLayout) + fileSuffix
+	path := filepath.Join(m.dir, name)
+
+	// 書き出し途中のファイルを一覧に含めないよう、一時ファイルに書き出してから名前を変える。
+	// VACUUM INTOは既存のファイルには書き出せないため、一時ファイルは名前だけを決めてSQLiteに作成させる。
+	tmp := filepath.Join(m.dir, ".backup-"+createdAt.Format(timeLayout)+".tmp")
+	if _, err := m.db.ExecContext(ctx, "VACUUM INTO ?", tmp); err != nil {
+		_ = os.Remove(tmp)
+		return Info{}, fmt.Errorf("バックアップの書き出しに失敗: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return Info{}, fmt.Errorf("バックアップの保存に失敗: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("バックアップの確認に失敗: %w", err)
+	}
+
+	if err := m.prune(); err != nil {
+		return Info{}, err
+	}
+	return Info{Name: name, Path: path, Size: info.Size(), CreatedAt: createdAt}, nil
+}
+
+// List はバックアップを新しい順に返す。ディレクトリがない場合は空を返す。
+func (m *Manager) List() ([]Info, error) {
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("バックアップの一覧の取得に失敗: %w", err)
+	}
+
+	var backups []Info
+	for _, e := range entries {
+		createdAt, ok := parseName(e.Name())
+		if !ok || !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			// 一覧の取得後に削除されたものは含めない
+			continue
+		}
+		backups = append(backups, Info{
+			Name:      e.Name(),
+			Path:      filepath.Join(m.dir, e.Name()),
+			Size:      info.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+	slices.SortFunc(backups, func(a, b Info) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return backups, nil
+}
+
+// Open は指定された名前のバックアップを読み出す。存在しない場合はErrNotFoundを返す。
+func (m *Manager) Open(name string) (*os.File, Info, error) {
+	createdAt, ok := parseName(name)
+	if !ok {
+		return nil, Info{}, ErrNotFound
+	}
+	path := filepath.Join(m.dir, name)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, Info{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, Info{}, fmt.Errorf("バックアップの読み込みに失敗: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, Info{}, fmt.Errorf("バックアップの読み込みに失敗: %w", err)
+	}
+	return f, Info{Name: name, Path: path, Size: info.Size(), CreatedAt: createdAt}, nil
+}
+
+// prune は新しい順にretain件を超えたバックアップを削除する
+func (m *Manager) prune() error {
+	if m.retain <= 0 {
+		return nil
+	}
+	backups, err := m.List()
+	if err != nil {
+		return err
+	}
+	for _, b := range backups[min(m.retain, len(backups)):] {
+		if err := os.Remove(b.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("古いバックアップの削除に失敗: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseName はバックアップのファイル名から作成日時を返す。バックアップのファイル名でない場合はfalseを返す。
+func parseName(name string) (time.Time, bool) {
+	ts, ok := strings.CutPrefix(name, filePrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	ts, ok = strings.CutSuffix(ts, fileSuffix)
+	if !ok {
+		return time.Time{}, false
+	}
+	createdAt, err := time.Parse(timeLayout, ts)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
+
+// Verify はバックアップのファイルが壊れていないことをintegrity_checkで確認する
+func Verify(ctx context.Context, src *sql.DB) error {
+	rows, err := src.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("バックアップの検査に失敗: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return fmt.Errorf("バックアップの検査に失敗: %w", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("バックアップの検査に失敗: %w", err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("バックアップが壊れています: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Restore はsrcの内容でdstのデータベースを置き換える。
+// SQLiteのオンラインバックアップAPIでページを書き写すため、サーバーがdstを開いたままでも実行でき、
+// 他のコネクションは書き写しが終わった時点で復元した内容を読む。
+func Restore(ctx context.Context, dst, src *sql.DB) error {
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("復元先のデータベースに接続できません: %w", err)
+	}
+	defer func() {
+		_ = dstConn.Close()
+	}()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("バックアップに接続できません: %w", err)
+	}
+	defer func() {
+		_ = srcConn.Close()
+	}()
+
+	return dstConn.Raw(func(dc any) error {
+		return srcConn.Raw(func(sc any) error {
+			d, err := sqliteConn(dc)
+			if err != nil {
+				return err
+			}
+			s, err := sqliteConn(sc)
+			if err != nil {
+				return err
+			}
+			b, err := d.Backup("main", s, "main")
+			if err != nil {
+				return fmt.Errorf("復元の開始に失敗: %w", err)
+			}
+			// すべてのページを一度に書き写し、途中の状態を他のコネクションに見せない
+			if _, err := b.Step(-1); err != nil {
+				_ = b.Finish()
+				return fmt.Errorf("復元に失敗: %w", err)
+			}
+			if err := b.Finish(); err != nil {
+				return fmt.Errorf("復元の完了に失敗: %w", err)
+			}
+			return nil
+		})
+	})
+}
+
+// sqliteConn はトレースなどで包んだ接続からSQLiteのドライバーの接続を取り出す
+func sqliteConn(dc any) (*sqlite3.SQLiteConn, error) {
+	if u, ok := dc.(interface{ Unwrap() driver.Conn }); ok {
+		dc = u.Unwrap()
+	}
+	c, ok := dc.(*sqlite3.SQLiteConn)
+	if !ok {
+		return nil, fmt.Errorf("SQLiteの接続ではありません: %T", dc)
+	}
+	return c, nil
+}
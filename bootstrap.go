@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"go-huma-test/auth"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"io"
+	"net/mail"
+	"os"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+)
+
+// minPasswordLength はサインアップと同じく、パスワードに求める最低の文字数
+const minPasswordLength = 8
+
+// newBootstrapCommand はユーザーが1人もいないデータベースに初期管理者を作成するbootstrapサブコマンドを生成する。
+// パスワードはシェルの履歴に残らないよう、標準入力の1行目から読む。作成したユーザーはJSONで標準出力に書き出す。
+func newBootstrapCommand() *cobra.Command {
+	var email, name string
+
+	cmd := &cobra.Command{
+		Use:   "bootstrap",
+		Short: "Create the first admin user, reading the password from the first line of stdin",
+		Long: "Creates an admin user while the database has no users, so that a server started with invite-only " +
+			"can be administered without exposing signup. Fails once any user exists.",
+		Args: cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, o *model.Options) {
+			password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				fmt.Fprintln(os.Stderr, "パスワードを読み込めません:", err)
+				os.Exit(1)
+			}
+			password = strings.TrimRight(password, "\r\n")
+			if len([]rune(password)) < minPasswordLength {
+				fmt.Fprintf(os.Stderr, "パスワードは%d文字以上を標準入力から指定してください\n", minPasswordLength)
+				os.Exit(1)
+			}
+
+			email = strings.TrimSpace(email)
+			if _, err := mail.ParseAddress(email); err != nil {
+				fmt.Fprintln(os.Stderr, "--emailにはメールアドレスを指定してください:", err)
+				os.Exit(1)
+			}
+
+			user, err := runBootstrap(cmd.Context(), o, email, name, password)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			writeJSON(map[string]any{"user": map[string]any{
+				"id":    user.ID,
+				"email": user.Email,
+				"name":  user.Name,
+				"role":  user.Role,
+			}})
+		}),
+	}
+
+	cmd.Flags().StringVar(&email, "email", "", "Email of the admin user")
+	cmd.Flags().StringVar(&name, "name", "Admin", "Display name of the admin user")
+	_ = cmd.MarkFlagRequired("email")
+	return cmd
+}
+
+// runBootstrap はデータベースを開き、ユーザーが1人もいない場合にadminロールのユーザーを作成する
+func runBootstrap(ctx context.Context, o *model.Options, email, name, password string) (db.User, error) {
+	sqlDB, err := initDB(o.DatabaseURL, false, o.AutoMigrate)
+	if err != nil {
+		return db.User{}, err
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+
+	passwordHash, err := auth.HashPassword(password)
+	if err != nil {
+		return db.User{}, fmt.Errorf("パスワードのハッシュ化に失敗: %w", err)
+	}
+
+	tx, err := sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return db.User{}, fmt.Errorf("トランザクション開始に失敗: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	qtx := db.New(tx)
+
+	count, err := qtx.CountUsers(ctx)
+	if err != nil {
+		return db.User{}, fmt.Errorf("ユーザー数の取得に失敗: %w", err)
+	}
+	if count > 0 {
+		return db.User{}, fmt.Errorf("ユーザーが登録済みのため、初期管理者は作成できません: %d人", count)
+	}
+	user, err := qtx.CreateUser(ctx, db.CreateUserParams{
+		Email:        email,
+		Name:         name,
+		PasswordHash: passwordHash,
+		Role:         string(auth.RoleAdmin),
+	})
+	if err != nil {
+		return db.User{}, fmt.Errorf("初期管理者の登録に失敗: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return db.User{}, fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+	}
+	return user, nil
+}
@@ -0,0 +1,776 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/apidoc"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
+	"go-huma-test/event"
+	"go-huma-test/locale"
+	"go-huma-test/middleware"
+	"go-huma-test/migrate"
+	"go-huma-test/model"
+	"go-huma-test/notify"
+	"go-huma-test/replication"
+	"go-huma-test/scheduler"
+	"go-huma-test/secrets"
+	"go-huma-test/slo"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+)
+
+// checkTimeout は外部への疎通確認1件あたりのタイムアウト
+const checkTimeout = 5 * time.Second
+
+// チェック結果の状態
+const (
+	checkOK   = "ok"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+// checkResult は起動前チェック1項目の結果を表す構造体
+type checkResult struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Detail   string   `json:"detail,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// checkReport は起動前チェック全体の結果を表す構造体
+type checkReport struct {
+	Status string        `json:"status"`
+	Checks []checkResult `json:"checks"`
+}
+
+// newCheckCommand は設定、データベース、外部連携を検証するcheckサブコマンドを生成する。
+// 結果はJSONで標準出力に書き出し、失敗した項目があれば終了コード1で終了する。
+func newCheckCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check",
+		Short: "Validate configuration, database and integrations before deploying",
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, o *model.Options) {
+			report := runChecks(cmd.Context(), o)
+
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			if report.Status != checkOK {
+				os.Exit(1)
+			}
+		}),
+	}
+}
+
+// runChecks はすべてのチェックを順に実行してレポートにまとめる
+func runChecks(ctx context.Context, o *model.Options) checkReport {
+	report := checkReport{
+		Status: checkOK,
+		Checks: []checkResult{
+			checkConfig(o),
+			checkAttachmentDir(o.AttachmentDir),
+		},
+	}
+	if dbPath, err := sqlitePath(o.DatabaseURL); err == nil {
+		report.Checks = append(report.Checks, checkDatabase(ctx, dbPath)...)
+	} else {
+		report.Checks = append(report.Checks, newCheckResult("database", []string{err.Error()}), checkResult{Name: "schema", Status: checkSkip})
+	}
+	report.Checks = append(report.Checks, checkIntegrations(ctx, o)...)
+
+	for _, c := range report.Checks {
+		if c.Status == checkFail {
+			report.Status = checkFail
+		}
+	}
+	return report
+}
+
+// newCheckResult は問題の有無から結果を組み立てる
+func newCheckResult(name string, problems []string) checkResult {
+	if len(problems) > 0 {
+		return checkResult{Name: name, Status: checkFail, Problems: problems}
+	}
+	return checkResult{Name: name, Status: checkOK}
+}
+
+// checkChannel は通知チャネルの指定が妥当で、チャネルの送信先が設定されていることを検証する。nameはオプション名。
+func checkChannel(o *model.Options, name, channel string) []string {
+	switch channel {
+	case notify.ChannelLog:
+	case notify.ChannelWebhook:
+		if o.WebhookURL == "" {
+			return []string{fmt.Sprintf("%sにwebhookを指定する場合はwebhook-urlが必要です", name)}
+		}
+	case notify.ChannelEmail:
+		if o.SMTPAddr == "" || o.SMTPTo == "" {
+			return []string{fmt.Sprintf("%sにemailを指定する場合はsmtp-addrとsmtp-toが必要です", name)}
+		}
+	default:
+		return []string{fmt.Sprintf("未対応の%sです: %s", name, channel)}
+	}
+	return nil
+}
+
+// checkTodoChannel はTodoに関する通知のチャネルを検証する。
+// プロジェクトごとのチャット（discord、slack、teams）はTodoが属するプロジェクトに投稿するため、Todoに関する通知でのみ指定できる。
+func checkTodoChannel(o *model.Options, name, channel string) []string {
+	if channel == notify.ChannelDiscord || channel == notify.ChannelSlack || channel == notify.ChannelTeams {
+		return nil
+	}
+	return checkChannel(o, name, channel)
+}
+
+// checkConfig は起動オプションの値と組み合わせが妥当であることを検証する
+func checkConfig(o *model.Options) checkResult {
+	var problems []string
+
+	if o.Port < 1 || o.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("portは1から65535の範囲で指定してください: %d", o.Port))
+	}
+	for name, d := range map[string]time.Duration{
+		"recurrence-interval":        o.RecurrenceInterval,
+		"reminder-interval":          o.ReminderInterval,
+		"escalation-interval":        o.EscalationInterval,
+		"confirmation-ttl":           o.ConfirmationTTL,
+		"secret-ttl":                 o.SecretTTL,
+		"jwks-refresh":               o.JWKSRefresh,
+		"replica-max-lag":            o.ReplicaMaxLag,
+		"panic-alert-interval":       o.PanicAlertInterval,
+		"usage-interval":             o.UsageInterval,
+		"readiness-timeout":          o.ReadinessTimeout,
+		"query-overrun-threshold":    o.QueryOverrunThreshold,
+		"shutdown-timeout":           o.ShutdownTimeout,
+		"restart-timeout":            o.RestartTimeout,
+		"replication-interval":       o.ReplicationInterval,
+		"presence-ttl":               o.PresenceTTL,
+		"lock-ttl":                   o.LockTTL,
+		"lock-max-ttl":               o.LockMaxTTL,
+		"invitation-ttl":             o.InvitationTTL,
+		"stale-digest-interval":      o.StaleDigestInterval,
+		"stale-snooze":               o.StaleSnooze,
+		"action-link-ttl":            o.ActionLinkTTL,
+		"webhook-delivery-interval":  o.WebhookInterval,
+		"webhook-backoff":            o.WebhookBackoff,
+		"webhook-delivery-timeout":   o.WebhookTimeout,
+		"webhook-delivery-retention": o.WebhookRetention,
+		"event-sink-timeout":         o.EventSinkTimeout,
+		"outbox-relay-interval":      o.OutboxInterval,
+		"outbox-retention":           o.OutboxRetention,
+		"disk-check-interval":        o.DiskCheckInterval,
+		"slo-latency-threshold":      o.SLOLatencyThreshold,
+		"slo-window":                 o.SLOWindow,
+		"mirror-timeout":             o.MirrorTimeout,
+	} {
+		if d <= 0 {
+			problems = append(problems, fmt.Sprintf("%sには正の時間を指定してください: %s", name, d))
+		}
+	}
+	if _, err := slo.ParseTarget(o.SLOAvailability); err != nil {
+		problems = append(problems, fmt.Sprintf("slo-availabilityの指定が不正です: %v", err))
+	}
+	if _, err := slo.ParseTarget(o.SLOLatency); err != nil {
+		problems = append(problems, fmt.Sprintf("slo-latencyの指定が不正です: %v", err))
+	}
+	if o.StaleAfter < 0 {
+		problems = append(problems, fmt.Sprintf("stale-afterに負の時間は指定できません: %s", o.StaleAfter))
+	}
+	if o.StaleAfter > 0 && o.PublicURL == "" {
+		problems = append(problems, "stale-afterを指定する場合は、通知のリンクに使うpublic-urlを指定してください")
+	}
+	if o.PublicURL != "" {
+		if u, err := url.Parse(o.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("public-urlはhttpまたはhttpsのURLで指定してください: %s", o.PublicURL))
+		}
+	}
+	if o.QueryTimeout < 0 {
+		problems = append(problems, fmt.Sprintf("query-timeoutに負の時間は指定できません: %s", o.QueryTimeout))
+	}
+	if o.QueryTimeout >= serverWriteTimeout {
+		problems = append(problems, fmt.Sprintf("query-timeoutはレスポンスの書き込みのタイムアウト（%s）より短くしてください: %s", serverWriteTimeout, o.QueryTimeout))
+	}
+	if o.AuthEventInterval < 0 {
+		problems = append(problems, fmt.Sprintf("auth-event-intervalに負の時間は指定できません: %s", o.AuthEventInterval))
+	}
+	if o.DBReadConns < 0 {
+		problems = append(problems, fmt.Sprintf("db-read-connsに負の値は指定できません: %d", o.DBReadConns))
+	}
+	for name, d := range map[string]time.Duration{
+		"db-checkpoint-interval": o.DBCheckpointInterval,
+		"db-analyze-interval":    o.DBAnalyzeInterval,
+		"db-vacuum-interval":     o.DBVacuumInterval,
+	} {
+		if d < 0 {
+			problems = append(problems, fmt.Sprintf("%sに負の時間は指定できません: %s", name, d))
+		}
+	}
+	if o.DBBusyRetries < 0 {
+		problems = append(problems, fmt.Sprintf("db-busy-retriesに負の値は指定できません: %d", o.DBBusyRetries))
+	}
+	if o.DBBusyBackoff < 0 {
+		problems = append(problems, fmt.Sprintf("db-busy-backoffに負の時間は指定できません: %s", o.DBBusyBackoff))
+	}
+	if o.DBVacuumPages < 0 {
+		problems = append(problems, fmt.Sprintf("db-vacuum-pagesに負の値は指定できません: %d", o.DBVacuumPages))
+	}
+	if o.DiskMinFree < 0 {
+		problems = append(problems, fmt.Sprintf("disk-min-freeに負の値は指定できません: %d", o.DiskMinFree))
+	}
+	if o.BackupInterval < 0 {
+		problems = append(problems, fmt.Sprintf("backup-intervalに負の時間は指定できません: %s", o.BackupInterval))
+	}
+	if o.BackupRetention < 0 {
+		problems = append(problems, fmt.Sprintf("backup-retentionに負の値は指定できません: %d", o.BackupRetention))
+	}
+	if o.ReplicationURL != "" {
+		if u, err := url.Parse(o.ReplicationURL); err != nil || !(u.Scheme == replication.SchemeS3 && u.Host != "" || u.Scheme == replication.SchemeFile && u.Path != "") {
+			problems = append(problems, fmt.Sprintf("replication-urlはs3://bucket/prefixまたはfile:///dirの形式で指定してください: %s", o.ReplicationURL))
+		}
+	}
+	if o.ReplicationEndpoint != "" {
+		if u, err := url.Parse(o.ReplicationEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("replication-endpointはhttpまたはhttpsのURLで指定してください: %s", o.ReplicationEndpoint))
+		}
+	}
+	if o.ReplicationRetention < 0 {
+		problems = append(problems, fmt.Sprintf("replication-retentionに負の値は指定できません: %d", o.ReplicationRetention))
+	}
+	if o.RateLimit < 0 {
+		problems = append(problems, fmt.Sprintf("rate-limitに負の値は指定できません: %d", o.RateLimit))
+	}
+	if o.RateLimit > 0 && o.RateLimitBurst <= 0 {
+		problems = append(problems, fmt.Sprintf("rate-limit-burstには正の値を指定してください: %d", o.RateLimitBurst))
+	}
+	if o.WebhookMaxAttempts < 1 {
+		problems = append(problems, fmt.Sprintf("webhook-max-attemptsには正の値を指定してください: %d", o.WebhookMaxAttempts))
+	}
+	if o.MaxAttachmentSize <= 0 {
+		problems = append(problems, fmt.Sprintf("max-attachment-sizeには正の値を指定してください: %d", o.MaxAttachmentSize))
+	}
+
+	if o.WebhookURL != "" {
+		if u, err := url.Parse(o.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("webhook-urlはhttpまたはhttpsのURLで指定してください: %s", o.WebhookURL))
+		}
+	}
+	if o.OTelEndpoint != "" {
+		if u, err := url.Parse(o.OTelEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("otel-endpointはhttpまたはhttpsのURLで指定してください: %s", o.OTelEndpoint))
+		}
+	}
+	if o.DebugAddr != "" {
+		if err := checkDebugAddr(o.DebugAddr); err != nil {
+			problems = append(problems, fmt.Sprintf("debug-addrが不正です: %s", err))
+		}
+	}
+	if o.OpenAPITags != "" {
+		if _, err := apidoc.LoadTagConfig(o.OpenAPITags); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if o.Demo && o.ReadOnly {
+		problems = append(problems, "read-onlyのレプリカにはdemoでデータを投入できません")
+	}
+	if o.Demo && o.InviteOnly {
+		problems = append(problems, "invite-onlyではdemoのユーザーを登録できません")
+	}
+	if o.EmailTemplate != "" {
+		if _, err := notify.LoadHTMLTemplate(o.EmailTemplate); err != nil {
+			problems = append(problems, fmt.Sprintf("email-templateが不正です: %s", err))
+		}
+	}
+	if err := checkTLS(o); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if o.OTelSamplePercent < 0 || o.OTelSamplePercent > 100 {
+		problems = append(problems, fmt.Sprintf("otel-sample-percentは0から100の範囲で指定してください: %d", o.OTelSamplePercent))
+	}
+	if o.MirrorURL != "" {
+		if u, err := url.Parse(o.MirrorURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" {
+			problems = append(problems, fmt.Sprintf("mirror-urlはクエリを含まないhttpまたはhttpsのURLで指定してください: %s", o.MirrorURL))
+		}
+	}
+	if o.MirrorPercent < 0 || o.MirrorPercent > 100 {
+		problems = append(problems, fmt.Sprintf("mirror-percentは0から100の範囲で指定してください: %d", o.MirrorPercent))
+	}
+	if o.MirrorMaxBody < 0 {
+		problems = append(problems, fmt.Sprintf("mirror-max-bodyには0以上の値を指定してください: %d", o.MirrorMaxBody))
+	}
+	if o.MirrorConcurrency <= 0 {
+		problems = append(problems, fmt.Sprintf("mirror-concurrencyには正の値を指定してください: %d", o.MirrorConcurrency))
+	}
+	for _, raw := range splitList(o.EventSinks) {
+		if _, err := event.OpenSink(raw); err != nil {
+			problems = append(problems, fmt.Sprintf("event-sinksの指定が不正です: %v", err))
+		}
+	}
+	if o.WebhookTemplate != "" {
+		if _, err := notify.ParsePayloadTemplate(o.WebhookTemplate); err != nil {
+			problems = append(problems, fmt.Sprintf("webhook-templateが不正です: %s", err))
+		}
+	}
+	if o.JWKSURL != "" {
+		if u, err := url.Parse(o.JWKSURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("jwks-urlはhttpまたはhttpsのURLで指定してください: %s", o.JWKSURL))
+		}
+	}
+	for _, origin := range splitList(o.CORSAllowedOrigins) {
+		if origin == "*" {
+			if o.CORSAllowCredentials {
+				problems = append(problems, "cors-allow-credentialsを指定する場合はcors-allowed-originsに*を含めず、オリジンを個別に指定してください")
+			}
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			problems = append(problems, fmt.Sprintf("cors-allowed-originsはhttps://app.example.comのようなオリジンで指定してください: %s", origin))
+		}
+	}
+	if o.ReplicaPrimaryURL != "" {
+		if u, err := url.Parse(o.ReplicaPrimaryURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("replica-primary-urlはhttpまたはhttpsのURLで指定してください: %s", o.ReplicaPrimaryURL))
+		}
+	}
+	if o.CORSMaxAge < 0 {
+		problems = append(problems, fmt.Sprintf("cors-max-ageに負の時間は指定できません: %s", o.CORSMaxAge))
+	}
+	if providers, err := auth.ParseProviders(o.AuthProviders); err != nil {
+		problems = append(problems, fmt.Sprintf("auth-providersの指定が不正です: %s", err))
+	} else if slices.Contains(providers, auth.ProviderOIDC) && o.OIDCIssuer == "" {
+		problems = append(problems, "認証プロバイダーoidcにはoidc-issuerが必要です")
+	}
+	if o.OIDCIssuer != "" {
+		if u, err := url.Parse(o.OIDCIssuer); err != nil || u.Scheme != "https" || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("oidc-issuerはhttpsのURLで指定してください: %s", o.OIDCIssuer))
+		}
+		if o.OIDCClientID == "" {
+			problems = append(problems, "OIDCログインにはoidc-client-idが必要です")
+		}
+		if u, err := url.Parse(o.OIDCRedirectURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("oidc-redirect-urlはhttpまたはhttpsのURLで指定してください: %s", o.OIDCRedirectURL))
+		}
+	}
+	if !auth.Role(o.DefaultRole).Valid() {
+		problems = append(problems, fmt.Sprintf("default-roleにはviewer、editor、adminのいずれかを指定してください: %s", o.DefaultRole))
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(o.LogLevel)); err != nil {
+		problems = append(problems, fmt.Sprintf("log-levelにはdebug、info、warn、errorのいずれかを指定してください: %s", o.LogLevel))
+	}
+	if _, err := middleware.ParseSampling(o.AccessLogSampling); err != nil {
+		problems = append(problems, fmt.Sprintf("access-log-samplingの指定が不正です: %v", err))
+	}
+	if !locale.Supported(o.Locale) {
+		problems = append(problems, fmt.Sprintf("localeにはjaかenを指定してください: %s", o.Locale))
+	}
+	if _, err := time.LoadLocation(o.Timezone); err != nil {
+		problems = append(problems, fmt.Sprintf("timezoneにはIANAのタイムゾーン名を指定してください: %s", o.Timezone))
+	}
+	if _, err := clock.Parse(o.FreezeTime); err != nil {
+		problems = append(problems, fmt.Sprintf("freeze-timeの指定が不正です: %v", err))
+	}
+	if o.JWTLeeway < 0 {
+		problems = append(problems, fmt.Sprintf("jwt-leewayに負の時間は指定できません: %s", o.JWTLeeway))
+	}
+	if (o.SMTPAddr == "") != (o.SMTPTo == "") {
+		problems = append(problems, "メール通知にはsmtp-addrとsmtp-toの両方が必要です")
+	}
+	if o.SMTPAddr != "" {
+		if _, _, err := net.SplitHostPort(o.SMTPAddr); err != nil {
+			problems = append(problems, fmt.Sprintf("smtp-addrはhost:port形式で指定してください: %s", o.SMTPAddr))
+		}
+	}
+
+	if _, err := scheduler.ParseEscalationThresholds(o.EscalationThresholds); err != nil {
+		problems = append(problems, fmt.Sprintf("escalation-thresholdsの指定が不正です: %v", err))
+	}
+	if _, err := middleware.ParseCacheTTLs(o.CacheTTLs); err != nil {
+		problems = append(problems, fmt.Sprintf("cache-ttlsの指定が不正です: %v", err))
+	}
+	problems = append(problems, checkChannel(o, "security-alert-channel", o.SecurityAlertChannel)...)
+	problems = append(problems, checkChannel(o, "panic-alert-channel", o.PanicAlertChannel)...)
+	problems = append(problems, checkChannel(o, "disk-alert-channel", o.DiskAlertChannel)...)
+	problems = append(problems, checkTodoChannel(o, "escalation-channel", o.EscalationChannel)...)
+	problems = append(problems, checkChannel(o, "watch-channel", o.WatchChannel)...)
+	if o.DueDigestTime != "" {
+		if _, err := scheduler.ParseDigestTime(o.DueDigestTime); err != nil {
+			problems = append(problems, fmt.Sprintf("due-digest-timeの指定が不正です: %v", err))
+		}
+		problems = append(problems, checkChannel(o, "due-digest-channel", o.DueDigestChannel)...)
+	}
+
+	if _, err := secrets.ParseProviders(o.SecretProviders, secrets.VaultConfig{
+		Addr:  o.VaultAddr,
+		Token: os.Getenv("VAULT_TOKEN"),
+		Path:  o.VaultPath,
+	}); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	// mapの走査順に依存せず、同じ設定なら同じレポートになるようにする
+	slices.Sort(problems)
+	return newCheckResult("config", problems)
+}
+
+// checkAttachmentDir は添付ファイルの保存先に書き込めることを確認する
+func checkAttachmentDir(dir string) checkResult {
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkResult{Name: "attachment_dir", Status: checkOK, Detail: "起動時に作成されます: " + dir}
+	}
+	if err != nil {
+		return newCheckResult("attachment_dir", []string{err.Error()})
+	}
+	if !info.IsDir() {
+		return newCheckResult("attachment_dir", []string{"ディレクトリではありません: " + dir})
+	}
+
+	f, err := os.CreateTemp(dir, ".check-*")
+	if err != nil {
+		return newCheckResult("attachment_dir", []string{"書き込みできません: " + err.Error()})
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+
+	return checkResult{Name: "attachment_dir", Status: checkOK, Detail: dir}
+}
+
+// checkDatabase はデータベースに接続できること、移行の適用状況と、既存のテーブルに移行後の列が揃っていることを確認する。
+func checkDatabase(ctx context.Context, path string) []checkResult {
+	if path == memoryDatabase {
+		return []checkResult{
+			{Name: "database", Status: checkOK, Detail: "インメモリ（停止すると内容は失われます）"},
+			{Name: "schema", Status: checkOK, Detail: "起動時に作成されます"},
+		}
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return []checkResult{
+			{Name: "database", Status: checkOK, Detail: "起動時に作成されます: " + path},
+			{Name: "schema", Status: checkOK, Detail: "起動時に作成されます"},
+		}
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return []checkResult{newCheckResult("database", []string{err.Error()}), {Name: "schema", Status: checkSkip}}
+	}
+	sqlDB, err := sql.Open(sqliteDriverName, "file:"+abs+"?mode=ro")
+	if err != nil {
+		return []checkResult{newCheckResult("database", []string{err.Error()}), {Name: "schema", Status: checkSkip}}
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var integrity string
+	if err := sqlDB.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&integrity); err != nil {
+		return []checkResult{newCheckResult("database", []string{err.Error()}), {Name: "schema", Status: checkSkip}}
+	}
+	if integrity != "ok" {
+		return []checkResult{newCheckResult("database", []string{"整合性チェックに失敗: " + integrity}), {Name: "schema", Status: checkSkip}}
+	}
+	dbResult := checkResult{Name: "database", Status: checkOK, Detail: path}
+
+	return []checkResult{dbResult, checkSchema(ctx, sqlDB)}
+}
+
+// checkSchema は移行の適用状況を確認し、すべて適用済みの場合はすべての移行を一時的なデータベースに適用した結果と実際のデータベースの列を比較する。
+// 未適用の移行は起動時に適用されるため問題にせず、このバージョンにない移行が適用されている場合は古いバージョンでの起動として問題にする。
+func checkSchema(ctx context.Context, sqlDB *sql.DB) checkResult {
+	migrations, err := migrate.Load(migrationFiles, migrationsDir)
+	if err != nil {
+		return newCheckResult("schema", []string{err.Error()})
+	}
+	statuses, err := migrate.New(sqlDB, migrations).Status(ctx)
+	if err != nil {
+		return newCheckResult("schema", []string{err.Error()})
+	}
+	var problems, pending []string
+	applied := 0
+	for _, st := range statuses {
+		switch {
+		case st.Unknown:
+			problems = append(problems, fmt.Sprintf("このバージョンにない移行が適用されています: %d_%s", st.Version, st.Name))
+		case st.AppliedAt.IsZero():
+			pending = append(pending, fmt.Sprintf("%d_%s", st.Version, st.Name))
+		default:
+			applied++
+		}
+	}
+	detail := fmt.Sprintf("適用済みの移行: %d/%d", applied, len(migrations))
+	if len(pending) > 0 {
+		result := newCheckResult("schema", problems)
+		result.Detail = detail + "; 起動時に適用される移行: " + strings.Join(pending, ", ")
+		return result
+	}
+
+	expectedDB, err := sql.Open(sqliteDriverName, ":memory:")
+	if err != nil {
+		return newCheckResult("schema", []string{err.Error()})
+	}
+	defer func() {
+		_ = expectedDB.Close()
+	}()
+	expectedDB.SetMaxOpenConns(1)
+	if _, err := migrate.New(expectedDB, migrations).Up(ctx); err != nil {
+		return newCheckResult("schema", []string{"移行の適用に失敗: " + err.Error()})
+	}
+
+	expected, err := tableColumns(ctx, expectedDB)
+	if err != nil {
+		return newCheckResult("schema", []string{err.Error()})
+	}
+	actual, err := tableColumns(ctx, sqlDB)
+	if err != nil {
+		return newCheckResult("schema", []string{err.Error()})
+	}
+
+	for _, table := range slices.Sorted(maps.Keys(expected)) {
+		columns, ok := actual[table]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("テーブル%sがありません", table))
+			continue
+		}
+		for _, c := range expected[table] {
+			if !slices.Contains(columns, c) {
+				problems = append(problems, fmt.Sprintf("列%s.%sがありません。移行を追加してください", table, c))
+			}
+		}
+	}
+
+	result := newCheckResult("schema", problems)
+	result.Detail = detail
+	return result
+}
+
+// tableColumns はデータベース内の各テーブルの列名を返す
+func tableColumns(ctx context.Context, sqlDB *sql.DB) (map[string][]string, error) {
+	rows, err := sqlDB.QueryContext(ctx, "SELECT m.name, p.name FROM sqlite_master AS m JOIN pragma_table_info(m.name) AS p WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return nil, fmt.Errorf("テーブル定義の取得に失敗: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	tables := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, fmt.Errorf("テーブル定義の取得に失敗: %w", err)
+		}
+		tables[table] = append(tables[table], column)
+	}
+	return tables, rows.Err()
+}
+
+// checkIntegrations は設定されている外部連携先に到達できることを確認する
+func checkIntegrations(ctx context.Context, o *model.Options) []checkResult {
+	results := make([]checkResult, 0, 7)
+
+	webhook := checkResult{Name: "webhook", Status: checkSkip, Detail: "webhook-urlが未設定"}
+	if u, err := url.Parse(o.WebhookURL); o.WebhookURL != "" && err == nil && u.Host != "" {
+		port := u.Port()
+		if port == "" {
+			port = "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+		}
+		webhook = checkDial(ctx, "webhook", net.JoinHostPort(u.Hostname(), port))
+	}
+	results = append(results, webhook)
+
+	smtpResult := checkResult{Name: "smtp", Status: checkSkip, Detail: "smtp-addrが未設定"}
+	if o.SMTPAddr != "" {
+		smtpResult = checkDial(ctx, "smtp", o.SMTPAddr)
+	}
+	results = append(results, smtpResult)
+
+	vault := checkResult{Name: "vault", Status: checkSkip, Detail: "vaultプロバイダーは使用されていません"}
+	if usesVault(o.SecretProviders) && o.VaultAddr != "" {
+		vault = checkVault(ctx, o.VaultAddr, os.Getenv("VAULT_TOKEN"))
+	}
+	results = append(results, vault)
+
+	results = append(results, checkJWTKeys(ctx, o))
+
+	oidc := checkResult{Name: "oidc", Status: checkSkip, Detail: "oidc-issuerが未設定"}
+	if o.OIDCIssuer != "" {
+		oidc = checkOIDC(ctx, o.OIDCIssuer)
+	}
+	results = append(results, oidc)
+
+	replicationResult := checkResult{Name: "replication", Status: checkSkip, Detail: "replication-urlが未設定"}
+	if o.ReplicationURL != "" {
+		replicationResult = checkReplication(ctx, o)
+	}
+	results = append(results, replicationResult)
+
+	sinks := splitList(o.EventSinks)
+	if len(sinks) == 0 {
+		results = append(results, checkResult{Name: "event-sink", Status: checkSkip, Detail: "event-sinksが未設定"})
+	}
+	for _, raw := range sinks {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" || (u.Scheme != event.SchemeNATS && u.Scheme != event.SchemeKafka) {
+			// URLの誤りはconfigの確認で報告する
+			continue
+		}
+		port := u.Port()
+		if port == "" {
+			port = "4222"
+			if u.Scheme == event.SchemeKafka {
+				port = "9092"
+			}
+		}
+		results = append(results, checkDial(ctx, "event-sink", net.JoinHostPort(u.Hostname(), port)))
+	}
+
+	return results
+}
+
+// checkReplication はスナップショットの複製先を一覧できることを確認する。
+// 複製先のディレクトリがない場合は起動時に作成されるため、作成せずに問題なしとする。
+func checkReplication(ctx context.Context, o *model.Options) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	if u, err := url.Parse(o.ReplicationURL); err == nil && u.Scheme == replication.SchemeFile {
+		if _, err := os.Stat(u.Path); errors.Is(err, os.ErrNotExist) {
+			return checkResult{Name: "replication", Status: checkOK, Detail: "起動時に作成されます: " + u.Path}
+		}
+	}
+	provider, err := secrets.ParseProviders(o.SecretProviders, secrets.VaultConfig{
+		Addr:  o.VaultAddr,
+		Token: os.Getenv("VAULT_TOKEN"),
+		Path:  o.VaultPath,
+	})
+	if err != nil {
+		return checkResult{Name: "replication", Status: checkSkip, Detail: "秘密情報プロバイダーの設定が不正"}
+	}
+	target, err := replication.OpenTarget(o.ReplicationURL, replication.Config{
+		Endpoint: o.ReplicationEndpoint,
+		Region:   o.ReplicationRegion,
+		Secrets:  secrets.NewManager(provider, o.SecretTTL),
+	})
+	if err != nil {
+		return newCheckResult("replication", []string{err.Error()})
+	}
+	snapshots, err := replication.List(ctx, target)
+	if err != nil {
+		return newCheckResult("replication", []string{err.Error()})
+	}
+	return checkResult{Name: "replication", Status: checkOK, Detail: fmt.Sprintf("%s (スナップショット %d件)", o.ReplicationURL, len(snapshots))}
+}
+
+// checkJWTKeys はアクセストークンを検証する鍵を取得できることを確認する。
+// jwks-urlが設定されている場合はJWKSを取得し、ない場合はHS256の署名鍵を秘密情報から読み込む。
+func checkJWTKeys(ctx context.Context, o *model.Options) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	if o.JWKSURL != "" {
+		n, err := auth.NewJWKS(o.JWKSURL, o.JWKSRefresh).Fetch(ctx)
+		if err != nil {
+			return newCheckResult("jwt", []string{err.Error()})
+		}
+		return checkResult{Name: "jwt", Status: checkOK, Detail: fmt.Sprintf("%s (RS256の公開鍵 %d件)", o.JWKSURL, n)}
+	}
+
+	provider, err := secrets.ParseProviders(o.SecretProviders, secrets.VaultConfig{
+		Addr:  o.VaultAddr,
+		Token: os.Getenv("VAULT_TOKEN"),
+		Path:  o.VaultPath,
+	})
+	if err != nil {
+		return checkResult{Name: "jwt", Status: checkSkip, Detail: "秘密情報プロバイダーの設定が不正"}
+	}
+	key, _, err := secrets.NewManager(provider, o.SecretTTL).Lookup(ctx, secrets.JWTSigningKey)
+	switch {
+	case err != nil:
+		return newCheckResult("jwt", []string{err.Error()})
+	case key == "":
+		return newCheckResult("jwt", []string{"jwks-urlか秘密情報" + secrets.JWTSigningKey + "を設定してください"})
+	}
+	return checkResult{Name: "jwt", Status: checkOK, Detail: "HS256の署名鍵"}
+}
+
+// checkOIDC はOIDCプロバイダーのディスカバリー文書を取得できることを確認する
+func checkOIDC(ctx context.Context, issuer string) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	endpoint, err := auth.NewOIDC(auth.OIDCConfig{Issuer: issuer}).Discover(ctx)
+	if err != nil {
+		return newCheckResult("oidc", []string{err.Error()})
+	}
+	return checkResult{Name: "oidc", Status: checkOK, Detail: fmt.Sprintf("%s (認可エンドポイント %s)", issuer, endpoint)}
+}
+
+// usesVault はプロバイダー指定にvaultが含まれているかを返す
+func usesVault(spec string) bool {
+	for _, s := range strings.Split(spec, ",") {
+		if strings.TrimSpace(s) == "vault" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDial は指定したアドレスにTCP接続できることを確認する
+func checkDial(ctx context.Context, name, addr string) checkResult {
+	d := net.Dialer{Timeout: checkTimeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return newCheckResult(name, []string{err.Error()})
+	}
+	_ = conn.Close()
+	return checkResult{Name: name, Status: checkOK, Detail: addr}
+}
+
+// checkVault はVaultのヘルスチェックAPIに到達でき、トークンが設定されていることを確認する
+func checkVault(ctx context.Context, addr, token string) checkResult {
+	var problems []string
+	if token == "" {
+		problems = append(problems, "VAULT_TOKENが設定されていません")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	// sys/healthはsealedやstandbyでも応答するため、接続できてステータスが返れば到達可能とみなす
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(addr, "/")+"/v1/sys/health", nil)
+	if err != nil {
+		return newCheckResult("vault", append(problems, err.Error()))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return newCheckResult("vault", append(problems, err.Error()))
+	}
+	_ = resp.Body.Close()
+
+	result := newCheckResult("vault", problems)
+	result.Detail = fmt.Sprintf("%s (HTTP %d)", addr, resp.StatusCode)
+	return result
+}
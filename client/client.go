@@ -0,0 +1,381 @@
+// Package client はTodo APIをGoのサービスから呼び出すクライアントを提供する。
+// 操作ごとのメソッドはサーバーのルーティングからgen.goで生成し、入力と出力にはサーバーと同じmodelパッケージの型を用いる。
+// 入力の構造体のpath、query、header、cookieのタグとBodyのフィールドからリクエストを組み立て、出力の構造体のheaderのタグとBodyのフィールドにレスポンスを読み込む。
+package client
+
+//go:generate go run gen.go
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/auth"
+	"go-huma-test/middleware"
+	"go-huma-test/model"
+	"io"
+	mathrand "math/rand/v2"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 既定のリトライの設定
+const (
+	DefaultMaxRetries   = 3
+	DefaultRetryWait    = 500 * time.Millisecond
+	DefaultMaxRetryWait = 30 * time.Second
+)
+
+// userAgent はリクエストのUser-Agentヘッダー
+const userAgent = "go-huma-test-client"
+
+// maxErrorBody はProblem Detailsとして読めないエラーのレスポンスから、detailに含めるボディの上限
+const maxErrorBody = 4 << 10
+
+// Config はクライアントの設定を表す構造体
+type Config struct {
+	// BaseURL はAPIのURL。パスを含む場合は操作のパスをその下に続ける
+	BaseURL string
+	// Token はAuthorizationヘッダーにBearerとして付けるアクセストークン
+	Token string
+	// APIKey はX-API-Keyヘッダーに付けるAPIキー。Tokenと両方を指定した場合は両方を送る
+	APIKey string
+	// HTTPClient はリクエストに使うクライアント。省略した場合はリダイレクトをたどらないクライアントを使う
+	HTTPClient *http.Client
+	// MaxRetries は失敗したリクエストを再送する回数。0の場合はDefaultMaxRetries、負の値の場合は再送しない
+	MaxRetries int
+	// RetryWait は1回目の再送までの待ち時間。再送のたびに倍にし、0の場合はDefaultRetryWait
+	RetryWait time.Duration
+	// MaxRetryWait は再送までの待ち時間の上限。Retry-Afterがこれより長い場合は再送せずにエラーを返す。0の場合はDefaultMaxRetryWait
+	MaxRetryWait time.Duration
+}
+
+// Client はTodo APIのクライアント。複数のゴルーチンから同時に使える
+type Client struct {
+	cfg     Config
+	baseURL *url.URL
+	http    *http.Client
+}
+
+// New はcfgの設定でAPIを呼び出すClientを生成する
+func New(cfg Config) (*Client, error) {
+	u, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("BaseURLが不正です: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("BaseURLはhttpかhttpsのURLを指定してください: %s", cfg.BaseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	switch {
+	case cfg.MaxRetries == 0:
+		cfg.MaxRetries = DefaultMaxRetries
+	case cfg.MaxRetries < 0:
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryWait <= 0 {
+		cfg.RetryWait = DefaultRetryWait
+	}
+	if cfg.MaxRetryWait <= 0 {
+		cfg.MaxRetryWait = DefaultMaxRetryWait
+	}
+	hc := cfg.HTTPClient
+	if hc == nil {
+		// OIDCのログインなどのリダイレクトを返す操作で、Locationヘッダーを出力に読み込めるようにする
+		hc = &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	}
+	return &Client{cfg: cfg, baseURL: u, http: hc}, nil
+}
+
+// WithToken はアクセストークンだけを差し替えたClientを返す。ログインで得たトークンで呼び出す場合に使う
+func (c *Client) WithToken(token string) *Client {
+	cp := *c
+	cp.cfg.Token = token
+	return &cp
+}
+
+// Error はAPIが返したエラーのレスポンスを表す。ボディはProblem Details（RFC 9457）として読み込む
+type Error struct {
+	StatusCode int
+	Header     http.Header
+	Problem    model.ErrorModel
+}
+
+// Error はステータスとエラーの説明を返す
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Problem.Detail != "" {
+		msg += ": " + e.Problem.Detail
+	}
+	for _, d := range e.Problem.Errors {
+		msg += "; " + d.Error()
+	}
+	return msg
+}
+
+// IsStatus はerrがstatusのステータスのErrorかどうかを返す
+func IsStatus(err error, status int) bool {
+	var e *Error
+	return errors.As(err, &e) && e.StatusCode == status
+}
+
+// FormFile はmultipart/form-dataで送るファイル
+type FormFile struct {
+	// Field はファイルを入れるフォームのフィールド名
+	Field       string
+	Filename    string
+	ContentType string
+	Content     io.Reader
+}
+
+// Form はファイルをアップロードする操作に送るmultipart/form-dataのフォーム
+type Form struct {
+	Fields map[string]string
+	Files  []FormFile
+}
+
+// encode はフォームを再送できるようにバイト列にし、Content-Typeとともに返す
+func (f *Form) encode() ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for name, value := range f.Fields {
+		if err := w.WriteField(name, value); err != nil {
+			return nil, "", err
+		}
+	}
+	for _, file := range f.Files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(file.Field), quoteEscaper.Replace(file.Filename)))
+		h.Set("Content-Type", cmp.Or(file.ContentType, "application/octet-stream"))
+		part, err := w.CreatePart(h)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := io.Copy(part, file.Content); err != nil {
+			return nil, "", fmt.Errorf("%sの読み込みに失敗: %w", file.Filename, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// quoteEscaper はContent-Dispositionの引用符で囲む値をエスケープする
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// idempotencyKeyContextKey はWithIdempotencyKeyで指定した冪等キーを保持するコンテキストのキー
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey は冪等キーに対応する操作で、自動で生成する代わりにkeyを送るコンテキストを返す。
+// 呼び出し元でリクエストを再送する場合に、同じキーを指定して二重に処理されないようにする。
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// operation は生成したメソッドが呼び出すAPIの操作
+type operation struct {
+	id     string
+	method string
+	// path は{name}の形のパスパラメータを含むパス
+	path string
+	// idempotencyKey はIdempotency-Keyヘッダーに対応する操作かどうか。対応する操作はPOSTでも再送する
+	idempotencyKey bool
+	// accept はAcceptヘッダー。空の場合はapplication/json
+	accept string
+}
+
+// call はopを呼び出し、レスポンスをOの出力に読み込んで返す。formはファイルをアップロードする操作でのみ指定する
+func call[O any](ctx context.Context, c *Client, op operation, input any, form *Form) (*O, error) {
+	resp, err := c.do(ctx, op, input, form)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	out := new(O)
+	if err := decodeOutput(resp, out); err != nil {
+		return nil, fmt.Errorf("%sのレスポンスの読み込みに失敗: %w", op.id, err)
+	}
+	return out, nil
+}
+
+// stream はopを呼び出し、成功したレスポンスを読み込まずに返す。ボディは呼び出し元が閉じる
+func stream(ctx context.Context, c *Client, op operation, input any) (*http.Response, error) {
+	return c.do(ctx, op, input, nil)
+}
+
+// do はリクエストを送り、成功したレスポンスを返す。
+// 接続の失敗と429、502、503、504は、冪等な操作か冪等キーを付けたリクエストに限り、指数関数的に延ばした待ち時間の後に再送する。
+// 429は処理されていないため、冪等でない操作でも再送する。
+func (c *Client) do(ctx context.Context, op operation, input any, form *Form) (*http.Response, error) {
+	req, err := c.encodeInput(op, input)
+	if err != nil {
+		return nil, fmt.Errorf("%sのリクエストの組み立てに失敗: %w", op.id, err)
+	}
+	if form != nil {
+		req.body, req.header["Content-Type"], err = form.encode()
+		if err != nil {
+			return nil, fmt.Errorf("%sのフォームの組み立てに失敗: %w", op.id, err)
+		}
+	}
+
+	retryable := op.method == http.MethodGet || op.method == http.MethodHead || op.method == http.MethodPut ||
+		op.method == http.MethodDelete || op.method == http.MethodOptions
+	if op.idempotencyKey {
+		key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+		if key == "" {
+			if key, err = newIdempotencyKey(); err != nil {
+				return nil, err
+			}
+		}
+		req.header[middleware.IdempotencyKeyHeader] = key
+		retryable = true
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, op, req)
+		last := attempt >= c.cfg.MaxRetries
+		if err != nil {
+			if ctx.Err() != nil || !retryable || last {
+				return nil, fmt.Errorf("%sの呼び出しに失敗: %w", op.id, err)
+			}
+			if err := c.wait(ctx, attempt, 0); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if resp.StatusCode < http.StatusBadRequest {
+			return resp, nil
+		}
+
+		apiErr := decodeError(resp)
+		if last || !isRetryableStatus(resp.StatusCode, retryable) {
+			return nil, apiErr
+		}
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		if retryAfter > c.cfg.MaxRetryWait {
+			return nil, apiErr
+		}
+		if err := c.wait(ctx, attempt, retryAfter); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isRetryableStatus はstatusのレスポンスを再送するかどうかを返す
+func isRetryableStatus(status int, retryable bool) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return retryable
+	}
+	return false
+}
+
+// wait はattempt回目の再送までの待ち時間だけ待つ。retryAfterが長い場合はそちらを待つ
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	d := c.cfg.RetryWait << min(attempt, 20)
+	if d <= 0 || d > c.cfg.MaxRetryWait {
+		d = c.cfg.MaxRetryWait
+	}
+	// 複数のクライアントが同時に再送しないよう、待ち時間の後半をランダムにする
+	d = d/2 + mathrand.N(d/2+1)
+	d = max(d, retryAfter)
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// parseRetryAfter はRetry-Afterヘッダーの秒数か日時から待ち時間を返す。指定がない場合は0を返す
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(v); err == nil && sec > 0 {
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+// newIdempotencyKey はリクエストごとの冪等キーを生成する
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// send はリクエストを1回送る
+func (c *Client) send(ctx context.Context, op operation, r *request) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += r.path
+	u.RawPath = c.baseURL.EscapedPath() + r.rawPath
+	u.RawQuery = r.query.Encode()
+
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, op.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", cmp.Or(op.accept, "application/json"))
+	req.Header.Set("User-Agent", userAgent)
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+	if c.cfg.APIKey != "" {
+		req.Header.Set(auth.APIKeyHeader, c.cfg.APIKey)
+	}
+	for name, value := range r.header {
+		req.Header.Set(name, value)
+	}
+	for _, cookie := range r.cookies {
+		req.AddCookie(cookie)
+	}
+	return c.http.Do(req)
+}
+
+// decodeError はエラーのレスポンスを読み込んでボディを閉じ、Errorを返す
+func decodeError(resp *http.Response) *Error {
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	e := &Error{StatusCode: resp.StatusCode, Header: resp.Header}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil || json.Unmarshal(b, &e.Problem) != nil {
+		if len(b) > maxErrorBody {
+			b = b[:maxErrorBody]
+		}
+		e.Problem.Detail = strings.TrimSpace(string(b))
+	}
+	if e.Problem.Status == 0 {
+		e.Problem.Status = resp.StatusCode
+	}
+	return e
+}
@@ -0,0 +1,320 @@
+package client
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// request は入力から組み立てたリクエストの内容。再送しても変わらないよう、ボディはバイト列で持つ
+type request struct {
+	// path はパスパラメータを埋めたパス、rawPathはそれをエスケープしたもの
+	path    string
+	rawPath string
+	query   url.Values
+	header  map[string]string
+	cookies []*http.Cookie
+	body    []byte
+}
+
+// tagName はHumaの構造体タグから名前を返す。カンマの後のオプションは除く
+func tagName(f reflect.StructField, key string) string {
+	name, _, _ := strings.Cut(f.Tag.Get(key), ",")
+	return name
+}
+
+// isEmbedded はfがタグを持たない埋め込みの構造体で、フィールドを展開して扱うかどうかを返す
+func isEmbedded(f reflect.StructField) bool {
+	return f.Anonymous && f.Type.Kind() == reflect.Struct && f.Type != timeType && f.Tag == ""
+}
+
+// encodeInput はopのパスと入力の構造体のタグから、リクエストを組み立てる
+func (c *Client) encodeInput(op operation, input any) (*request, error) {
+	r := &request{query: url.Values{}, header: map[string]string{}}
+	params := map[string]string{}
+	if input != nil {
+		v := reflect.ValueOf(input)
+		if v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		if v.IsValid() && v.Kind() == reflect.Struct {
+			if err := r.encodeFields(v, params); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var path, raw strings.Builder
+	rest := op.path
+	for {
+		start := strings.IndexByte(rest, '{')
+		end := strings.IndexByte(rest, '}')
+		if start < 0 || end < start {
+			path.WriteString(rest)
+			raw.WriteString(rest)
+			break
+		}
+		name := rest[start+1 : end]
+		value, ok := params[name]
+		if !ok || value == "" {
+			return nil, fmt.Errorf("パスパラメータ%sが指定されていません", name)
+		}
+		path.WriteString(rest[:start] + value)
+		raw.WriteString(rest[:start] + url.PathEscape(value))
+		rest = rest[end+1:]
+	}
+	r.path, r.rawPath = path.String(), raw.String()
+	return r, nil
+}
+
+// encodeFields は構造体のフィールドをタグに従ってパスパラメータ、クエリ、ヘッダー、Cookie、ボディに振り分ける。
+// クエリとヘッダーはゼロ値の場合は送らずにサーバーの既定値に任せ、既定値がtrueのboolだけはfalseを送る。
+func (r *request) encodeFields(v reflect.Value, params map[string]string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+		if isEmbedded(f) {
+			if err := r.encodeFields(fv, params); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		switch {
+		case tagName(f, "path") != "":
+			s, err := formatValue(fv, false)
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.Name, err)
+			}
+			params[tagName(f, "path")] = s
+		case tagName(f, "query") != "":
+			if fv.IsZero() {
+				if fv.Kind() == reflect.Bool && f.Tag.Get("default") == "true" {
+					r.query.Set(tagName(f, "query"), "false")
+				}
+				continue
+			}
+			s, err := formatValue(fv, false)
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.Name, err)
+			}
+			r.query.Set(tagName(f, "query"), s)
+		case tagName(f, "header") != "":
+			if fv.IsZero() {
+				continue
+			}
+			s, err := formatValue(fv, true)
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.Name, err)
+			}
+			r.header[tagName(f, "header")] = s
+		case tagName(f, "cookie") != "":
+			if fv.IsZero() {
+				continue
+			}
+			s, err := formatValue(fv, false)
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.Name, err)
+			}
+			r.cookies = append(r.cookies, &http.Cookie{Name: tagName(f, "cookie"), Value: s})
+		case f.Name == "Body":
+			if (fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface) && fv.IsNil() {
+				continue
+			}
+			if b, ok := fv.Interface().([]byte); ok {
+				r.body = b
+				r.header["Content-Type"] = "application/octet-stream"
+				continue
+			}
+			b, err := json.Marshal(fv.Interface())
+			if err != nil {
+				return fmt.Errorf("ボディをJSONにできません: %w", err)
+			}
+			r.body = b
+			r.header["Content-Type"] = "application/json"
+		}
+	}
+	return nil
+}
+
+// formatValue はパラメータの値を文字列にする。スライスはカンマで区切り、日時はヘッダーではHTTPの日付、それ以外ではRFC 3339の形式にする
+func formatValue(v reflect.Value, header bool) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if header {
+			return t.UTC().Format(http.TimeFormat), nil
+		}
+		return t.Format(time.RFC3339Nano), nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Slice, reflect.Array:
+		sep := ","
+		if header {
+			sep = ", "
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			s, err := formatValue(v.Index(i), header)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, sep), nil
+	}
+	return "", fmt.Errorf("%sの値はパラメータにできません", v.Type())
+}
+
+// decodeOutput は成功したレスポンスを出力の構造体に読み込む。
+// headerのタグのフィールドにヘッダーを、Statusのフィールドにステータスを、Bodyのフィールドにボディを読み込む。
+// Bodyが[]byteの場合はボディをそのまま、それ以外はJSONとして読み込む。
+func decodeOutput(resp *http.Response, out any) error {
+	v := reflect.ValueOf(out).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	return decodeFields(resp, v)
+}
+
+// decodeFields は構造体のフィールドにレスポンスを読み込む
+func decodeFields(resp *http.Response, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+		if isEmbedded(f) {
+			if err := decodeFields(resp, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		switch {
+		case tagName(f, "header") != "":
+			values := resp.Header.Values(tagName(f, "header"))
+			if len(values) == 0 {
+				continue
+			}
+			if err := parseValue(fv, values); err != nil {
+				return fmt.Errorf("%sヘッダー: %w", tagName(f, "header"), err)
+			}
+		case f.Name == "Status" && fv.Kind() == reflect.Int:
+			fv.SetInt(int64(resp.StatusCode))
+		case f.Name == "Body":
+			if fv.Type() == reflect.TypeFor[[]byte]() {
+				b, err := io.ReadAll(resp.Body)
+				if err != nil {
+					return err
+				}
+				fv.SetBytes(b)
+				continue
+			}
+			if err := json.NewDecoder(resp.Body).Decode(fv.Addr().Interface()); err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseValue はヘッダーの値をフィールドの型に変換して設定する。スライス以外は最初の値を使う
+func parseValue(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	s := values[0]
+	if v.Type() == timeType {
+		t, err := http.ParseTime(s)
+		if err != nil {
+			t, err = time.Parse(time.RFC3339Nano, s)
+		}
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("%sの値はヘッダーから読み込めません", v.Type())
+		}
+		v.Set(reflect.ValueOf(values).Convert(v.Type()))
+	default:
+		return fmt.Errorf("%sの値はヘッダーから読み込めません", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,390 @@
+//go:build ignore
+
+// gen.go はmain.goで登録した操作から、操作ごとのClientのメソッドをoperations.goに生成する。
+// huma.Registerとsse.Registerの呼び出しからOperationID、メソッド、パスを、登録したハンドラーのメソッドの引数と戻り値から入力と出力の型を読み取る。
+// 操作を追加、変更した場合はclientのディレクトリでgo generateを実行する。
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// root はモジュールのルートのディレクトリ
+const root = ".."
+
+// initialisms は操作のIDからメソッド名を作るときに、すべて大文字にする単語
+var initialisms = map[string]string{
+	"api":  "API",
+	"db":   "DB",
+	"id":   "ID",
+	"oidc": "OIDC",
+	"slo":  "SLO",
+	"url":  "URL",
+}
+
+// method はハンドラーのメソッドの入力と出力の型
+type method struct {
+	input  string
+	output string
+}
+
+// op は生成する操作
+type op struct {
+	id, path, summary string
+	// method はnet/httpのメソッドの定数の名前
+	method         string
+	input, output  string
+	idempotencyKey bool
+	sse            bool
+	multipart      bool
+}
+
+func main() {
+	fset := token.NewFileSet()
+	handlerFiles := parseDir(fset, "handler")
+	modelFiles := parseDir(fset, "model")
+	mainFile, err := parser.ParseFile(fset, filepath.Join(root, "main.go"), nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	constructors, methods := handlerDecls(handlerFiles)
+	multipartInputs := multipartTypes(modelFiles)
+	consts := map[string]map[string]string{}
+
+	// ハンドラーの変数から型を引けるようにする
+	vars := map[string]string{}
+	ast.Inspect(mainFile, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, rhs := range n.Rhs {
+				call, ok := rhs.(*ast.CallExpr)
+				if !ok || i >= len(n.Lhs) {
+					continue
+				}
+				if pkg, name, ok := selector(call.Fun); ok && pkg == "handler" {
+					if typ, ok := constructors[name]; ok {
+						if id, ok := n.Lhs[i].(*ast.Ident); ok {
+							vars[id.Name] = typ
+						}
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			if star, ok := n.Type.(*ast.StarExpr); ok {
+				if pkg, name, ok := selector(star.X); ok && pkg == "handler" {
+					for _, id := range n.Names {
+						vars[id.Name] = name
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	var ops []op
+	seen := map[string]bool{}
+	ast.Inspect(mainFile, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		pkg, name, ok := selector(call.Fun)
+		if !ok || name != "Register" || (pkg != "huma" && pkg != "sse") {
+			return true
+		}
+		lit, ok := call.Args[1].(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+
+		o := op{sse: pkg == "sse"}
+		for _, elt := range lit.Elts {
+			kv := elt.(*ast.KeyValueExpr)
+			switch kv.Key.(*ast.Ident).Name {
+			case "OperationID":
+				o.id = stringValue(fset, kv.Value, consts)
+			case "Method":
+				_, m, _ := selector(kv.Value)
+				o.method = m
+			case "Path":
+				o.path = stringValue(fset, kv.Value, consts)
+			case "Summary":
+				o.summary = stringValue(fset, kv.Value, consts)
+			case "Parameters":
+				ast.Inspect(kv.Value, func(n ast.Node) bool {
+					if c, ok := n.(*ast.CallExpr); ok {
+						if _, fn, ok := selector(c.Fun); ok && fn == "IdempotencyKeyParam" {
+							o.idempotencyKey = true
+						}
+					}
+					return true
+				})
+			}
+		}
+		if seen[o.id] {
+			return true
+		}
+		seen[o.id] = true
+
+		h, ok := call.Args[len(call.Args)-1].(*ast.SelectorExpr)
+		if !ok {
+			log.Fatalf("%s: ハンドラーのメソッドを特定できません", o.id)
+		}
+		typ, ok := vars[h.X.(*ast.Ident).Name]
+		if !ok {
+			log.Fatalf("%s: %sの型を特定できません", o.id, h.X.(*ast.Ident).Name)
+		}
+		m, ok := methods[typ+"."+h.Sel.Name]
+		if !ok {
+			log.Fatalf("%s: %s.%sが見つかりません", o.id, typ, h.Sel.Name)
+		}
+		o.input, o.output = m.input, m.output
+		o.multipart = multipartInputs[o.input]
+		ops = append(ops, o)
+		return true
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go; DO NOT EDIT.\n\npackage client\n\n")
+	buf.WriteString("import (\n\t\"context\"\n\t\"go-huma-test/model\"\n\t\"net/http\"\n)\n")
+	for _, o := range ops {
+		writeOp(&buf, o)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("生成したコードを整形できません: %v\n%s", err, buf.Bytes())
+	}
+	if err := os.WriteFile("operations.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d件の操作を生成しました\n", len(ops))
+}
+
+// writeOp は操作を呼び出すメソッドを書き出す
+func writeOp(buf *bytes.Buffer, o op) {
+	name := methodName(o.id)
+	lit := fmt.Sprintf("operation{id: %q, method: http.%s, path: %q", o.id, o.method, o.path)
+	if o.idempotencyKey {
+		lit += ", idempotencyKey: true"
+	}
+	if o.sse {
+		lit += `, accept: "text/event-stream"`
+	}
+	lit += "}"
+
+	params := "ctx context.Context"
+	input := "nil"
+	if o.input != "struct{}" {
+		params += ", input *" + o.input
+		input = "input"
+	}
+
+	fmt.Fprintf(buf, "\n// %s は%s（%s %s）を呼び出す", name, o.summary, strings.ToUpper(strings.TrimPrefix(o.method, "Method")), o.path)
+	switch {
+	case o.sse:
+		buf.WriteString("。イベントはServer-Sent Eventsで届くため、レスポンスのボディから読み、読み終えたら閉じる\n")
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) (*http.Response, error) {\n\treturn stream(ctx, c, %s, %s)\n}\n", name, params, lit, input)
+	case o.output == "huma.StreamResponse":
+		buf.WriteString("。レスポンスのボディを読み終えたら閉じる\n")
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) (*http.Response, error) {\n\treturn stream(ctx, c, %s, %s)\n}\n", name, params, lit, input)
+	case o.multipart:
+		buf.WriteString("。ファイルはformで送る\n")
+		fmt.Fprintf(buf, "func (c *Client) %s(%s, form *Form) (*%s, error) {\n\treturn call[%s](ctx, c, %s, %s, form)\n}\n", name, params, o.output, o.output, lit, input)
+	default:
+		buf.WriteString("\n")
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) (*%s, error) {\n\treturn call[%s](ctx, c, %s, %s, nil)\n}\n", name, params, o.output, o.output, lit, input)
+	}
+}
+
+// methodName はケバブケースの操作のIDをメソッド名にする
+func methodName(id string) string {
+	var b strings.Builder
+	for _, w := range strings.Split(id, "-") {
+		if s, ok := initialisms[w]; ok {
+			b.WriteString(s)
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+// parseDir はモジュールのdirのパッケージのファイルを解析する
+func parseDir(fset *token.FileSet, dir string) []*ast.File {
+	paths, err := filepath.Glob(filepath.Join(root, dir, "*.go"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var files []*ast.File
+	for _, p := range paths {
+		if strings.HasSuffix(p, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, p, nil, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// handlerDecls はハンドラーのコンストラクタが返す型と、ハンドラーのメソッドの入力と出力の型を返す
+func handlerDecls(files []*ast.File) (map[string]string, map[string]method) {
+	constructors := map[string]string{}
+	methods := map[string]method{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !fn.Name.IsExported() {
+				continue
+			}
+			results := fn.Type.Results
+			if fn.Recv == nil {
+				if strings.HasPrefix(fn.Name.Name, "New") && results != nil && len(results.List) > 0 {
+					if star, ok := results.List[0].Type.(*ast.StarExpr); ok {
+						if id, ok := star.X.(*ast.Ident); ok {
+							constructors[fn.Name.Name] = id.Name
+						}
+					}
+				}
+				continue
+			}
+
+			star, ok := fn.Recv.List[0].Type.(*ast.StarExpr)
+			if !ok {
+				continue
+			}
+			recv := star.X.(*ast.Ident).Name
+			params := fn.Type.Params.List
+			if len(params) < 2 {
+				continue
+			}
+			m := method{input: typeName(params[1].Type)}
+			if results != nil && len(results.List) == 2 {
+				m.output = typeName(results.List[0].Type)
+			}
+			methods[recv+"."+fn.Name.Name] = m
+		}
+	}
+	return constructors, methods
+}
+
+// multipartTypes はRawBodyでmultipart/form-dataのフォームを受け取る入力の型を返す
+func multipartTypes(files []*ast.File) map[string]bool {
+	types := map[string]bool{}
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			for _, field := range st.Fields.List {
+				for _, name := range field.Names {
+					if name.Name == "RawBody" {
+						types["model."+spec.Name.Name] = true
+					}
+				}
+			}
+			return false
+		})
+	}
+	return types
+}
+
+// typeName はポインタを除いた型の名前を返す
+func typeName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.X.(*ast.Ident).Name + "." + e.Sel.Name
+	case *ast.StructType:
+		if len(e.Fields.List) == 0 {
+			return "struct{}"
+		}
+	}
+	return ""
+}
+
+// selector はpkg.Nameの形の式のパッケージ名と名前を返す
+func selector(expr ast.Expr) (string, string, bool) {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return "", "", false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+	return id.Name, sel.Sel.Name, true
+}
+
+// stringValue は文字列のリテラルと、他のパッケージの文字列の定数を+で連結した式の値を返す
+func stringValue(fset *token.FileSet, expr ast.Expr, consts map[string]map[string]string) string {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		s, err := strconv.Unquote(e.Value)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return s
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			return stringValue(fset, e.X, consts) + stringValue(fset, e.Y, consts)
+		}
+	case *ast.SelectorExpr:
+		pkg, name, _ := selector(e)
+		if _, ok := consts[pkg]; !ok {
+			consts[pkg] = packageConsts(fset, pkg)
+		}
+		if s, ok := consts[pkg][name]; ok {
+			return s
+		}
+	}
+	log.Fatalf("%s: 文字列の値を求められません", fset.Position(expr.Pos()))
+	return ""
+}
+
+// packageConsts はモジュールのpkgのパッケージの、文字列のリテラルで定義した定数を返す
+func packageConsts(fset *token.FileSet, pkg string) map[string]string {
+	consts := map[string]string{}
+	for _, f := range parseDir(fset, pkg) {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if i >= len(vs.Values) {
+						continue
+					}
+					if lit, ok := vs.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						consts[name.Name], _ = strconv.Unquote(lit.Value)
+					}
+				}
+			}
+		}
+	}
+	return consts
+}
@@ -0,0 +1,51 @@
+// Package clock は現在時刻を得る手段を提供する。
+// ハンドラーやスケジューラー、保持期間を過ぎた記録の削除はtime.Nowを直接呼ばずにClockから現在時刻を得るため、
+// 期限、繰り返し、まとめの通知のように日時で結果が変わる処理を、固定した時刻で再現できる。
+// 実行の間隔、タイムアウト、所要時間の計測は実際の経過時間が必要なため、Clockを使わない。
+package clock
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock は現在時刻を返す
+type Clock interface {
+	Now() time.Time
+}
+
+// System はOSの時計の現在時刻を返すClock
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Frozen は常に同じ時刻を返すClock。デモや検証で、日時に依存する処理の結果を毎回同じにするために使う
+type Frozen struct {
+	at time.Time
+}
+
+// Freeze は常にatを返すFrozenを生成する
+func Freeze(at time.Time) *Frozen {
+	return &Frozen{at: at}
+}
+
+// Now は固定した時刻を返す
+func (f *Frozen) Now() time.Time {
+	return f.at
+}
+
+// Parse は"2026-01-15T09:00:00+09:00"のようなRFC 3339の日時で固定したClockを返す。sが空の場合はSystemを返す
+func Parse(s string) (Clock, error) {
+	if s == "" {
+		return System, nil
+	}
+	at, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("日時はRFC 3339の形式で指定してください: %s", s)
+	}
+	return Freeze(at), nil
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"go-huma-test/model"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/casing"
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+)
+
+// config exportの出力形式
+const (
+	configFormatJSON = "json"
+	configFormatEnv  = "env"
+)
+
+// configEnvPrefix はhumacliが起動オプションを読み取る環境変数の接頭辞
+const configEnvPrefix = "SERVICE_"
+
+// configOption は起動オプション1つのフラグ名とフィールドを表す構造体
+type configOption struct {
+	name  string
+	field reflect.StructField
+	index int
+}
+
+// configDifference はファイルとこの環境で値が異なる起動オプションを表す構造体
+type configDifference struct {
+	Name      string `json:"name"`
+	File      any    `json:"file"`
+	Effective any    `json:"effective"`
+}
+
+// configValidation はconfig validateの結果を表す構造体
+type configValidation struct {
+	Status      string             `json:"status"`
+	Problems    []string           `json:"problems,omitempty"`
+	Unknown     []string           `json:"unknown,omitempty"`
+	Missing     []string           `json:"missing,omitempty"`
+	Redacted    []string           `json:"redacted,omitempty"`
+	Differences []configDifference `json:"differences,omitempty"`
+}
+
+// newConfigCommand は有効な起動オプションを書き出し、書き出したファイルを検証するconfigサブコマンドを生成する。
+// 書き出す内容は/debug/configと同じ形式のため、実行中のインスタンスの/debug/configもconfig validateで検証できる。
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Export the effective configuration or validate an exported one",
+	}
+
+	var format string
+	export := &cobra.Command{
+		Use:   "export",
+		Short: "Print every option with the value in effect after flags, environment variables and defaults, with secrets masked",
+		Args:  cobra.NoArgs,
+		Run: humacli.WithOptions(func(_ *cobra.Command, _ []string, o *model.Options) {
+			config := debugConfig(o)
+			switch format {
+			case configFormatJSON:
+				writeJSON(config)
+			case configFormatEnv:
+				fmt.Print(configEnv(config))
+			default:
+				fmt.Fprintf(os.Stderr, "--formatにはjsonかenvを指定してください: %s\n", format)
+				os.Exit(1)
+			}
+		}),
+	}
+	export.Flags().StringVar(&format, "format", configFormatJSON, "Output format: json, or env for SERVICE_* environment variable assignments")
+	cmd.AddCommand(export)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "validate <file>",
+		Short: "Check an exported configuration and list where it differs from this environment",
+		Long: "Reads a JSON file written by config export or fetched from /debug/config, checks the values the same way as the check subcommand, " +
+			"and lists options whose values differ from the effective configuration of this environment. " +
+			"Masked secrets are checked with this environment's values. Exits with status 1 when the file has problems; differences alone do not fail.",
+		Args: cobra.ExactArgs(1),
+		Run: humacli.WithOptions(func(_ *cobra.Command, args []string, o *model.Options) {
+			b, err := os.ReadFile(args[0])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			result := validateConfig(b, o)
+			writeJSON(result)
+			if result.Status != checkOK {
+				os.Exit(1)
+			}
+		}),
+	})
+	return cmd
+}
+
+// configOptions は起動オプションをフィールドの順に返す
+func configOptions() []configOption {
+	t := reflect.TypeFor[model.Options]()
+	options := make([]configOption, 0, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name := field.Tag.Get("name")
+		if name == "" {
+			name = casing.Kebab(field.Name)
+		}
+		options = append(options, configOption{name: name, field: field, index: i})
+	}
+	return options
+}
+
+// configEnv は起動オプションを環境変数の代入の形式にする。伏せた値は代入しないようコメントにする。
+func configEnv(config map[string]any) string {
+	var buf strings.Builder
+	for _, opt := range configOptions() {
+		env := configEnvPrefix + strings.ToUpper(strings.ReplaceAll(opt.name, "-", "_"))
+		value := fmt.Sprint(config[opt.name])
+		if value == redactedValue {
+			fmt.Fprintf(&buf, "# %s=%s\n", env, value)
+			continue
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", env, shellQuote(value))
+	}
+	return buf.String()
+}
+
+// shellQuote はシェルで読み込めるよう、英数字と一部の記号以外を含む値を単一引用符で囲む
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_./:,@+=-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// validateConfig は書き出した起動オプションを読み込んで検証し、effectiveとの違いを返す。
+// ファイルにないオプションは既定値、伏せられたオプションはeffectiveの値で検証する。
+func validateConfig(b []byte, effective *model.Options) configValidation {
+	result := configValidation{Status: checkOK}
+	fail := func(problem string) {
+		result.Status = checkFail
+		result.Problems = append(result.Problems, problem)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var values map[string]any
+	if err := dec.Decode(&values); err != nil {
+		fail(fmt.Sprintf("JSONのオブジェクトとして読み込めません: %s", err))
+		return result
+	}
+
+	var o model.Options
+	v := reflect.ValueOf(&o).Elem()
+	ev := reflect.ValueOf(effective).Elem()
+	known := make(map[string]bool)
+	for _, opt := range configOptions() {
+		known[opt.name] = true
+		raw, ok := values[opt.name]
+		switch {
+		case !ok:
+			result.Missing = append(result.Missing, opt.name)
+			raw = opt.field.Tag.Get("default")
+		case raw == redactedValue && opt.field.Tag.Get("redact") == "true":
+			result.Redacted = append(result.Redacted, opt.name)
+			v.Field(opt.index).Set(ev.Field(opt.index))
+			continue
+		}
+		if err := setConfigValue(v.Field(opt.index), raw); err != nil {
+			fail(fmt.Sprintf("%sの値が不正です: %s", opt.name, err))
+		}
+	}
+	for name := range values {
+		if !known[name] {
+			result.Unknown = append(result.Unknown, name)
+		}
+	}
+	slices.Sort(result.Unknown)
+	if result.Status != checkOK {
+		return result
+	}
+
+	if c := checkConfig(&o); c.Status != checkOK {
+		for _, p := range c.Problems {
+			fail(p)
+		}
+	}
+
+	fileConfig := debugConfig(&o)
+	effectiveConfig := debugConfig(effective)
+	for _, opt := range configOptions() {
+		if slices.Contains(result.Redacted, opt.name) {
+			continue
+		}
+		if !reflect.DeepEqual(fileConfig[opt.name], effectiveConfig[opt.name]) {
+			result.Differences = append(result.Differences, configDifference{
+				Name:      opt.name,
+				File:      fileConfig[opt.name],
+				Effective: effectiveConfig[opt.name],
+			})
+		}
+	}
+	return result
+}
+
+// setConfigValue はJSONの値または既定値の文字列を起動オプションのフィールドに設定する。
+// 時間は/debug/configと同じく1m30sのような文字列で受け付ける。
+func setConfigValue(field reflect.Value, raw any) error {
+	if field.Type() == reflect.TypeFor[time.Duration]() {
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("1m30sのような時間の文字列を指定してください: %v", raw)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("文字列を指定してください: %v", raw)
+		}
+		field.SetString(s)
+	case reflect.Int, reflect.Int64:
+		var n int64
+		var err error
+		switch r := raw.(type) {
+		case json.Number:
+			n, err = r.Int64()
+		case string:
+			// 既定値はタグの文字列から読み込み、既定値のない整数は0になる
+			n, err = strconv.ParseInt(cmp.Or(r, "0"), 10, 64)
+		default:
+			err = fmt.Errorf("整数を指定してください: %v", raw)
+		}
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Bool:
+		switch r := raw.(type) {
+		case bool:
+			field.SetBool(r)
+		case string:
+			// 既定値のない真偽値は空文字列になる
+			b, err := strconv.ParseBool(cmp.Or(r, "false"))
+			if err != nil {
+				return err
+			}
+			field.SetBool(b)
+		default:
+			return fmt.Errorf("trueかfalseを指定してください: %v", raw)
+		}
+	default:
+		return fmt.Errorf("未対応の型です: %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package confirm は取り消せない管理操作を2段階で実行するための確認トークンを提供する。
+// 1回目の呼び出しでは操作を実行せず、影響範囲とともに確認トークンを発行する。
+// 2回目の呼び出しで同じ実行者が確認トークンを送った場合のみ操作を実行する。
+// 確認後に影響範囲が変わった場合は、利用者が確認していない変更を防ぐため実行しない。
+package confirm
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/db"
+	"log/slog"
+	"time"
+)
+
+// TokenHeader は確認トークンを送るリクエストヘッダー
+const TokenHeader = "X-Confirmation-Token"
+
+var (
+	// ErrInvalidToken は確認トークンが存在しないか、期限切れか、別の操作や実行者のものであることを表すエラー
+	ErrInvalidToken = errors.New("確認トークンが無効です")
+	// ErrImpactChanged は確認トークンの発行後に操作の影響範囲が変わったことを表すエラー
+	ErrImpactChanged = errors.New("確認後に操作の影響範囲が変わりました")
+)
+
+// Impact は操作で削除や変更の対象になるリソースの件数を種類ごとに表す
+type Impact map[string]int64
+
+// Confirmation は発行した確認トークンを表す構造体
+type Confirmation struct {
+	Token     string
+	ExpiresAt time.Time
+	Impact    Impact
+}
+
+// Confirmer は確認トークンを発行し、照合する
+type Confirmer struct {
+	queries *db.Queries
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// NewConfirmer はConfirmerの新しいインスタンスを生成する。確認トークンはttlの間だけ有効。
+func NewConfirmer(queries *db.Queries, ttl time.Duration) *Confirmer {
+	return &Confirmer{
+		queries: queries,
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// hashToken は確認トークンを保存用のハッシュにする
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue はcontextの実行者がoperationをtargetに対して実行するための確認トークンを発行する。
+// impactは利用者に提示する影響範囲で、Consumeで実行直前の影響範囲と照合する。
+func (c *Confirmer) Issue(ctx context.Context, operation, target string, impact Impact) (*Confirmation, error) {
+	now := c.now().UTC()
+	// 使われずに期限が切れたトークンは発行のついでに削除する
+	if _, err := c.queries.DeleteExpiredConfirmationTokens(ctx, now); err != nil {
+		slog.Warn("期限切れの確認トークンの削除に失敗", "err", err)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("確認トークンの生成に失敗: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	encoded, err := json.Marshal(impact)
+	if err != nil {
+		return nil, fmt.Errorf("影響範囲のエンコードに失敗: %w", err)
+	}
+
+	expiresAt := now.Add(c.ttl)
+	if err := c.queries.CreateConfirmationToken(ctx, db.CreateConfirmationTokenParams{
+		TokenHash: hashToken(token),
+		Operation: operation,
+		Target:    target,
+		Actor:     activity.ActorFrom(ctx),
+		Impact:    string(encoded),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, fmt.Errorf("確認トークンの保存に失敗: %w", err)
+	}
+
+	return &Confirmation{Token: token, ExpiresAt: expiresAt, Impact: impact}, nil
+}
+
+// Consume は確認トークンを照合して使用済みにする。
+// qには操作と同じトランザクションを渡し、操作が失敗した場合は確認トークンも使用済みにならないようにする。
+// 確認トークンが無効な場合はErrInvalidTokenを、impactが発行時と異なる場合はErrImpactChangedを返す。
+func (c *Confirmer) Consume(ctx context.Context, q *db.Queries, token, operation, target string, impact Impact) error {
+	t, err := q.ConsumeConfirmationToken(ctx, db.ConsumeConfirmationTokenParams{
+		TokenHash: hashToken(token),
+		Now:       c.now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return fmt.Errorf("確認トークンの取得に失敗: %w", err)
+	}
+	if t.Operation != operation || t.Target != target || t.Actor != activity.ActorFrom(ctx) {
+		return ErrInvalidToken
+	}
+
+	encoded, err := json.Marshal(impact)
+	if err != nil {
+		return fmt.Errorf("影響範囲のエンコードに失敗: %w", err)
+	}
+	// mapのキーは並べ替えてエンコードされるため、文字列のまま比較できる
+	if t.Impact != string(encoded) {
+		return ErrImpactChanged
+	}
+	return nil
+}
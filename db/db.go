@@ -24,65 +24,1537 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.acceptInvitationStmt, err = db.PrepareContext(ctx, acceptInvitation); err != nil {
+		return nil, fmt.Errorf("error preparing query AcceptInvitation: %w", err)
+	}
+	if q.acquireResourceLockStmt, err = db.PrepareContext(ctx, acquireResourceLock); err != nil {
+		return nil, fmt.Errorf("error preparing query AcquireResourceLock: %w", err)
+	}
+	if q.addOperationUsageStmt, err = db.PrepareContext(ctx, addOperationUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query AddOperationUsage: %w", err)
+	}
+	if q.addTodoTagStmt, err = db.PrepareContext(ctx, addTodoTag); err != nil {
+		return nil, fmt.Errorf("error preparing query AddTodoTag: %w", err)
+	}
+	if q.addUsageAPICallsStmt, err = db.PrepareContext(ctx, addUsageAPICalls); err != nil {
+		return nil, fmt.Errorf("error preparing query AddUsageAPICalls: %w", err)
+	}
+	if q.archiveProjectStmt, err = db.PrepareContext(ctx, archiveProject); err != nil {
+		return nil, fmt.Errorf("error preparing query ArchiveProject: %w", err)
+	}
+	if q.archiveTodoStmt, err = db.PrepareContext(ctx, archiveTodo); err != nil {
+		return nil, fmt.Errorf("error preparing query ArchiveTodo: %w", err)
+	}
+	if q.completeIdempotencyKeyStmt, err = db.PrepareContext(ctx, completeIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query CompleteIdempotencyKey: %w", err)
+	}
+	if q.consumeConfirmationTokenStmt, err = db.PrepareContext(ctx, consumeConfirmationToken); err != nil {
+		return nil, fmt.Errorf("error preparing query ConsumeConfirmationToken: %w", err)
+	}
+	if q.countActiveAPIKeysByCreatorStmt, err = db.PrepareContext(ctx, countActiveAPIKeysByCreator); err != nil {
+		return nil, fmt.Errorf("error preparing query CountActiveAPIKeysByCreator: %w", err)
+	}
+	if q.countCommentsByTodoIDsStmt, err = db.PrepareContext(ctx, countCommentsByTodoIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query CountCommentsByTodoIDs: %w", err)
+	}
+	if q.countUsersStmt, err = db.PrepareContext(ctx, countUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query CountUsers: %w", err)
+	}
+	if q.countUsersByRoleStmt, err = db.PrepareContext(ctx, countUsersByRole); err != nil {
+		return nil, fmt.Errorf("error preparing query CountUsersByRole: %w", err)
+	}
+	if q.createAPIKeyStmt, err = db.PrepareContext(ctx, createAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAPIKey: %w", err)
+	}
+	if q.createActivityStmt, err = db.PrepareContext(ctx, createActivity); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateActivity: %w", err)
+	}
+	if q.createAttachmentStmt, err = db.PrepareContext(ctx, createAttachment); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAttachment: %w", err)
+	}
+	if q.createAuthEventStmt, err = db.PrepareContext(ctx, createAuthEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAuthEvent: %w", err)
+	}
+	if q.createCommentStmt, err = db.PrepareContext(ctx, createComment); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateComment: %w", err)
+	}
+	if q.createConfirmationTokenStmt, err = db.PrepareContext(ctx, createConfirmationToken); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateConfirmationToken: %w", err)
+	}
+	if q.createDescriptionOpStmt, err = db.PrepareContext(ctx, createDescriptionOp); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateDescriptionOp: %w", err)
+	}
+	if q.createDueDigestStmt, err = db.PrepareContext(ctx, createDueDigest); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateDueDigest: %w", err)
+	}
+	if q.createIdempotencyKeyStmt, err = db.PrepareContext(ctx, createIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateIdempotencyKey: %w", err)
+	}
+	if q.createImportJobStmt, err = db.PrepareContext(ctx, createImportJob); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateImportJob: %w", err)
+	}
+	if q.createInvitationStmt, err = db.PrepareContext(ctx, createInvitation); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateInvitation: %w", err)
+	}
+	if q.createOutboxEventStmt, err = db.PrepareContext(ctx, createOutboxEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateOutboxEvent: %w", err)
+	}
+	if q.createProjectStmt, err = db.PrepareContext(ctx, createProject); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateProject: %w", err)
+	}
+	if q.createReminderStmt, err = db.PrepareContext(ctx, createReminder); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateReminder: %w", err)
+	}
+	if q.createSavedFilterStmt, err = db.PrepareContext(ctx, createSavedFilter); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateSavedFilter: %w", err)
+	}
+	if q.createStaleDigestStmt, err = db.PrepareContext(ctx, createStaleDigest); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateStaleDigest: %w", err)
+	}
+	if q.createSubtaskStmt, err = db.PrepareContext(ctx, createSubtask); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateSubtask: %w", err)
+	}
+	if q.createTagStmt, err = db.PrepareContext(ctx, createTag); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTag: %w", err)
+	}
 	if q.createTodoStmt, err = db.PrepareContext(ctx, createTodo); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateTodo: %w", err)
 	}
+	if q.createUserStmt, err = db.PrepareContext(ctx, createUser); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateUser: %w", err)
+	}
+	if q.createUserIdentityStmt, err = db.PrepareContext(ctx, createUserIdentity); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateUserIdentity: %w", err)
+	}
+	if q.createWebhookStmt, err = db.PrepareContext(ctx, createWebhook); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWebhook: %w", err)
+	}
+	if q.createWebhookDeliveryStmt, err = db.PrepareContext(ctx, createWebhookDelivery); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWebhookDelivery: %w", err)
+	}
+	if q.deleteCommentStmt, err = db.PrepareContext(ctx, deleteComment); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteComment: %w", err)
+	}
+	if q.deleteCompletedTodosStmt, err = db.PrepareContext(ctx, deleteCompletedTodos); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCompletedTodos: %w", err)
+	}
+	if q.deleteExpiredConfirmationTokensStmt, err = db.PrepareContext(ctx, deleteExpiredConfirmationTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredConfirmationTokens: %w", err)
+	}
+	if q.deleteExpiredIdempotencyKeysStmt, err = db.PrepareContext(ctx, deleteExpiredIdempotencyKeys); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredIdempotencyKeys: %w", err)
+	}
+	if q.deleteIdempotencyKeyStmt, err = db.PrepareContext(ctx, deleteIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteIdempotencyKey: %w", err)
+	}
+	if q.deleteInvitationStmt, err = db.PrepareContext(ctx, deleteInvitation); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteInvitation: %w", err)
+	}
+	if q.deleteProjectStmt, err = db.PrepareContext(ctx, deleteProject); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteProject: %w", err)
+	}
+	if q.deleteProjectChatChannelStmt, err = db.PrepareContext(ctx, deleteProjectChatChannel); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteProjectChatChannel: %w", err)
+	}
+	if q.deleteProjectShareStmt, err = db.PrepareContext(ctx, deleteProjectShare); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteProjectShare: %w", err)
+	}
+	if q.deleteReminderStmt, err = db.PrepareContext(ctx, deleteReminder); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteReminder: %w", err)
+	}
+	if q.deleteResourceLockStmt, err = db.PrepareContext(ctx, deleteResourceLock); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteResourceLock: %w", err)
+	}
+	if q.deleteSavedFilterStmt, err = db.PrepareContext(ctx, deleteSavedFilter); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSavedFilter: %w", err)
+	}
+	if q.deleteSubtaskStmt, err = db.PrepareContext(ctx, deleteSubtask); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSubtask: %w", err)
+	}
+	if q.deleteTagStmt, err = db.PrepareContext(ctx, deleteTag); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteTag: %w", err)
+	}
 	if q.deleteTodoStmt, err = db.PrepareContext(ctx, deleteTodo); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteTodo: %w", err)
 	}
+	if q.deleteTodoDefaultsStmt, err = db.PrepareContext(ctx, deleteTodoDefaults); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteTodoDefaults: %w", err)
+	}
+	if q.deleteTodoShareStmt, err = db.PrepareContext(ctx, deleteTodoShare); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteTodoShare: %w", err)
+	}
+	if q.deleteTodoTagsStmt, err = db.PrepareContext(ctx, deleteTodoTags); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteTodoTags: %w", err)
+	}
+	if q.deleteTodosByIDsStmt, err = db.PrepareContext(ctx, deleteTodosByIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteTodosByIDs: %w", err)
+	}
+	if q.deleteUserStmt, err = db.PrepareContext(ctx, deleteUser); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUser: %w", err)
+	}
+	if q.deleteWebhookStmt, err = db.PrepareContext(ctx, deleteWebhook); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteWebhook: %w", err)
+	}
+	if q.endRecurrenceStmt, err = db.PrepareContext(ctx, endRecurrence); err != nil {
+		return nil, fmt.Errorf("error preparing query EndRecurrence: %w", err)
+	}
+	if q.escalateTodoStmt, err = db.PrepareContext(ctx, escalateTodo); err != nil {
+		return nil, fmt.Errorf("error preparing query EscalateTodo: %w", err)
+	}
+	if q.failInterruptedImportJobsStmt, err = db.PrepareContext(ctx, failInterruptedImportJobs); err != nil {
+		return nil, fmt.Errorf("error preparing query FailInterruptedImportJobs: %w", err)
+	}
+	if q.finishImportJobStmt, err = db.PrepareContext(ctx, finishImportJob); err != nil {
+		return nil, fmt.Errorf("error preparing query FinishImportJob: %w", err)
+	}
+	if q.finishWebhookDeliveryStmt, err = db.PrepareContext(ctx, finishWebhookDelivery); err != nil {
+		return nil, fmt.Errorf("error preparing query FinishWebhookDelivery: %w", err)
+	}
+	if q.getAPIKeyStmt, err = db.PrepareContext(ctx, getAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAPIKey: %w", err)
+	}
+	if q.getActiveAPIKeyByHashStmt, err = db.PrepareContext(ctx, getActiveAPIKeyByHash); err != nil {
+		return nil, fmt.Errorf("error preparing query GetActiveAPIKeyByHash: %w", err)
+	}
+	if q.getAttachmentStmt, err = db.PrepareContext(ctx, getAttachment); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAttachment: %w", err)
+	}
+	if q.getAuthLocationStatsStmt, err = db.PrepareContext(ctx, getAuthLocationStats); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAuthLocationStats: %w", err)
+	}
+	if q.getBadgeCountsStmt, err = db.PrepareContext(ctx, getBadgeCounts); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBadgeCounts: %w", err)
+	}
+	if q.getChatChannelForTodoStmt, err = db.PrepareContext(ctx, getChatChannelForTodo); err != nil {
+		return nil, fmt.Errorf("error preparing query GetChatChannelForTodo: %w", err)
+	}
+	if q.getCommentStmt, err = db.PrepareContext(ctx, getComment); err != nil {
+		return nil, fmt.Errorf("error preparing query GetComment: %w", err)
+	}
+	if q.getIdempotencyKeyStmt, err = db.PrepareContext(ctx, getIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetIdempotencyKey: %w", err)
+	}
+	if q.getImportJobStmt, err = db.PrepareContext(ctx, getImportJob); err != nil {
+		return nil, fmt.Errorf("error preparing query GetImportJob: %w", err)
+	}
+	if q.getInboxSummaryStmt, err = db.PrepareContext(ctx, getInboxSummary); err != nil {
+		return nil, fmt.Errorf("error preparing query GetInboxSummary: %w", err)
+	}
+	if q.getInvitationByTokenHashStmt, err = db.PrepareContext(ctx, getInvitationByTokenHash); err != nil {
+		return nil, fmt.Errorf("error preparing query GetInvitationByTokenHash: %w", err)
+	}
+	if q.getLatestActivityStmt, err = db.PrepareContext(ctx, getLatestActivity); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLatestActivity: %w", err)
+	}
+	if q.getLatestDescriptionOpStmt, err = db.PrepareContext(ctx, getLatestDescriptionOp); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLatestDescriptionOp: %w", err)
+	}
+	if q.getLatestOutboxEventIDStmt, err = db.PrepareContext(ctx, getLatestOutboxEventID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLatestOutboxEventID: %w", err)
+	}
+	if q.getLatestStaleDigestStmt, err = db.PrepareContext(ctx, getLatestStaleDigest); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLatestStaleDigest: %w", err)
+	}
+	if q.getNextActivityStmt, err = db.PrepareContext(ctx, getNextActivity); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNextActivity: %w", err)
+	}
+	if q.getOldestDescriptionRevisionStmt, err = db.PrepareContext(ctx, getOldestDescriptionRevision); err != nil {
+		return nil, fmt.Errorf("error preparing query GetOldestDescriptionRevision: %w", err)
+	}
+	if q.getPendingInvitationByEmailStmt, err = db.PrepareContext(ctx, getPendingInvitationByEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPendingInvitationByEmail: %w", err)
+	}
+	if q.getProjectStmt, err = db.PrepareContext(ctx, getProject); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProject: %w", err)
+	}
+	if q.getProjectChatChannelStmt, err = db.PrepareContext(ctx, getProjectChatChannel); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProjectChatChannel: %w", err)
+	}
+	if q.getResourceLockStmt, err = db.PrepareContext(ctx, getResourceLock); err != nil {
+		return nil, fmt.Errorf("error preparing query GetResourceLock: %w", err)
+	}
+	if q.getSavedFilterStmt, err = db.PrepareContext(ctx, getSavedFilter); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSavedFilter: %w", err)
+	}
+	if q.getSubtaskStmt, err = db.PrepareContext(ctx, getSubtask); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSubtask: %w", err)
+	}
+	if q.getTagStmt, err = db.PrepareContext(ctx, getTag); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTag: %w", err)
+	}
+	if q.getTagByNameStmt, err = db.PrepareContext(ctx, getTagByName); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTagByName: %w", err)
+	}
 	if q.getTodoStmt, err = db.PrepareContext(ctx, getTodo); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTodo: %w", err)
 	}
+	if q.getTodoByIDStmt, err = db.PrepareContext(ctx, getTodoByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTodoByID: %w", err)
+	}
+	if q.getTodoDefaultsStmt, err = db.PrepareContext(ctx, getTodoDefaults); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTodoDefaults: %w", err)
+	}
+	if q.getUserStmt, err = db.PrepareContext(ctx, getUser); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUser: %w", err)
+	}
+	if q.getUserByEmailStmt, err = db.PrepareContext(ctx, getUserByEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserByEmail: %w", err)
+	}
+	if q.getUserByIdentityStmt, err = db.PrepareContext(ctx, getUserByIdentity); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserByIdentity: %w", err)
+	}
+	if q.getWebhookStmt, err = db.PrepareContext(ctx, getWebhook); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWebhook: %w", err)
+	}
+	if q.hasRecentAuthEventStmt, err = db.PrepareContext(ctx, hasRecentAuthEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query HasRecentAuthEvent: %w", err)
+	}
+	if q.listAPIKeysStmt, err = db.PrepareContext(ctx, listAPIKeys); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAPIKeys: %w", err)
+	}
+	if q.listActiveWebhooksForOwnerStmt, err = db.PrepareContext(ctx, listActiveWebhooksForOwner); err != nil {
+		return nil, fmt.Errorf("error preparing query ListActiveWebhooksForOwner: %w", err)
+	}
+	if q.listActivityStmt, err = db.PrepareContext(ctx, listActivity); err != nil {
+		return nil, fmt.Errorf("error preparing query ListActivity: %w", err)
+	}
+	if q.listAgendaRemindersStmt, err = db.PrepareContext(ctx, listAgendaReminders); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAgendaReminders: %w", err)
+	}
+	if q.listAgendaTodosStmt, err = db.PrepareContext(ctx, listAgendaTodos); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAgendaTodos: %w", err)
+	}
+	if q.listAttachmentsStmt, err = db.PrepareContext(ctx, listAttachments); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAttachments: %w", err)
+	}
+	if q.listAttachmentsByTodoIDsStmt, err = db.PrepareContext(ctx, listAttachmentsByTodoIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAttachmentsByTodoIDs: %w", err)
+	}
+	if q.listAuthEventsStmt, err = db.PrepareContext(ctx, listAuthEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAuthEvents: %w", err)
+	}
+	if q.listChatOverdueDeliveriesStmt, err = db.PrepareContext(ctx, listChatOverdueDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListChatOverdueDeliveries: %w", err)
+	}
+	if q.listCommentsStmt, err = db.PrepareContext(ctx, listComments); err != nil {
+		return nil, fmt.Errorf("error preparing query ListComments: %w", err)
+	}
+	if q.listCommentsByProjectStmt, err = db.PrepareContext(ctx, listCommentsByProject); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCommentsByProject: %w", err)
+	}
+	if q.listCommentsByTodoIDsStmt, err = db.PrepareContext(ctx, listCommentsByTodoIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCommentsByTodoIDs: %w", err)
+	}
+	if q.listCompletedTodoIDsStmt, err = db.PrepareContext(ctx, listCompletedTodoIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCompletedTodoIDs: %w", err)
+	}
+	if q.listDescriptionOpsStmt, err = db.PrepareContext(ctx, listDescriptionOps); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDescriptionOps: %w", err)
+	}
+	if q.listDueDigestTodosStmt, err = db.PrepareContext(ctx, listDueDigestTodos); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDueDigestTodos: %w", err)
+	}
+	if q.listDueRemindersStmt, err = db.PrepareContext(ctx, listDueReminders); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDueReminders: %w", err)
+	}
+	if q.listDueWebhookDeliveriesStmt, err = db.PrepareContext(ctx, listDueWebhookDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDueWebhookDeliveries: %w", err)
+	}
+	if q.listImportJobsStmt, err = db.PrepareContext(ctx, listImportJobs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListImportJobs: %w", err)
+	}
+	if q.listInvitationsStmt, err = db.PrepareContext(ctx, listInvitations); err != nil {
+		return nil, fmt.Errorf("error preparing query ListInvitations: %w", err)
+	}
+	if q.listOperationUsageStmt, err = db.PrepareContext(ctx, listOperationUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query ListOperationUsage: %w", err)
+	}
+	if q.listOverdueTodosStmt, err = db.PrepareContext(ctx, listOverdueTodos); err != nil {
+		return nil, fmt.Errorf("error preparing query ListOverdueTodos: %w", err)
+	}
+	if q.listOwnedTodoIDsStmt, err = db.PrepareContext(ctx, listOwnedTodoIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListOwnedTodoIDs: %w", err)
+	}
+	if q.listPendingOutboxEventsStmt, err = db.PrepareContext(ctx, listPendingOutboxEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPendingOutboxEvents: %w", err)
+	}
+	if q.listPendingRecurrencesStmt, err = db.PrepareContext(ctx, listPendingRecurrences); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPendingRecurrences: %w", err)
+	}
+	if q.listPendingRecurringTodosStmt, err = db.PrepareContext(ctx, listPendingRecurringTodos); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPendingRecurringTodos: %w", err)
+	}
+	if q.listProjectChatChannelsStmt, err = db.PrepareContext(ctx, listProjectChatChannels); err != nil {
+		return nil, fmt.Errorf("error preparing query ListProjectChatChannels: %w", err)
+	}
+	if q.listProjectSharesStmt, err = db.PrepareContext(ctx, listProjectShares); err != nil {
+		return nil, fmt.Errorf("error preparing query ListProjectShares: %w", err)
+	}
+	if q.listProjectsStmt, err = db.PrepareContext(ctx, listProjects); err != nil {
+		return nil, fmt.Errorf("error preparing query ListProjects: %w", err)
+	}
+	if q.listProjectsByIDsStmt, err = db.PrepareContext(ctx, listProjectsByIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListProjectsByIDs: %w", err)
+	}
+	if q.listRemindersStmt, err = db.PrepareContext(ctx, listReminders); err != nil {
+		return nil, fmt.Errorf("error preparing query ListReminders: %w", err)
+	}
+	if q.listRemindersByTodoIDsStmt, err = db.PrepareContext(ctx, listRemindersByTodoIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListRemindersByTodoIDs: %w", err)
+	}
+	if q.listSavedFiltersStmt, err = db.PrepareContext(ctx, listSavedFilters); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSavedFilters: %w", err)
+	}
+	if q.listStaleTodosStmt, err = db.PrepareContext(ctx, listStaleTodos); err != nil {
+		return nil, fmt.Errorf("error preparing query ListStaleTodos: %w", err)
+	}
+	if q.listSubtasksStmt, err = db.PrepareContext(ctx, listSubtasks); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSubtasks: %w", err)
+	}
+	if q.listSubtasksByProjectStmt, err = db.PrepareContext(ctx, listSubtasksByProject); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSubtasksByProject: %w", err)
+	}
+	if q.listSubtasksByTodoIDsStmt, err = db.PrepareContext(ctx, listSubtasksByTodoIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSubtasksByTodoIDs: %w", err)
+	}
+	if q.listTagTodoIDsStmt, err = db.PrepareContext(ctx, listTagTodoIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTagTodoIDs: %w", err)
+	}
+	if q.listTagsStmt, err = db.PrepareContext(ctx, listTags); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTags: %w", err)
+	}
+	if q.listTodoActivityStmt, err = db.PrepareContext(ctx, listTodoActivity); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodoActivity: %w", err)
+	}
+	if q.listTodoChatDeliveriesStmt, err = db.PrepareContext(ctx, listTodoChatDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodoChatDeliveries: %w", err)
+	}
+	if q.listTodoDefaultsForProjectStmt, err = db.PrepareContext(ctx, listTodoDefaultsForProject); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodoDefaultsForProject: %w", err)
+	}
+	if q.listTodoIDsInProjectStmt, err = db.PrepareContext(ctx, listTodoIDsInProject); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodoIDsInProject: %w", err)
+	}
+	if q.listTodoLocksStmt, err = db.PrepareContext(ctx, listTodoLocks); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodoLocks: %w", err)
+	}
+	if q.listTodoSharesStmt, err = db.PrepareContext(ctx, listTodoShares); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodoShares: %w", err)
+	}
+	if q.listTodoTagNamesStmt, err = db.PrepareContext(ctx, listTodoTagNames); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodoTagNames: %w", err)
+	}
+	if q.listTodoWatchersToNotifyStmt, err = db.PrepareContext(ctx, listTodoWatchersToNotify); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodoWatchersToNotify: %w", err)
+	}
 	if q.listTodosStmt, err = db.PrepareContext(ctx, listTodos); err != nil {
 		return nil, fmt.Errorf("error preparing query ListTodos: %w", err)
 	}
+	if q.listTodosByOwnerStmt, err = db.PrepareContext(ctx, listTodosByOwner); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodosByOwner: %w", err)
+	}
+	if q.listTodosByProjectStmt, err = db.PrepareContext(ctx, listTodosByProject); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodosByProject: %w", err)
+	}
 	if q.listTodosByStatusStmt, err = db.PrepareContext(ctx, listTodosByStatus); err != nil {
 		return nil, fmt.Errorf("error preparing query ListTodosByStatus: %w", err)
 	}
+	if q.listTodosForExportStmt, err = db.PrepareContext(ctx, listTodosForExport); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodosForExport: %w", err)
+	}
+	if q.listTodosNearStmt, err = db.PrepareContext(ctx, listTodosNear); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodosNear: %w", err)
+	}
+	if q.listUsageStmt, err = db.PrepareContext(ctx, listUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUsage: %w", err)
+	}
+	if q.listUsersStmt, err = db.PrepareContext(ctx, listUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUsers: %w", err)
+	}
+	if q.listVisibleTodosByProjectStmt, err = db.PrepareContext(ctx, listVisibleTodosByProject); err != nil {
+		return nil, fmt.Errorf("error preparing query ListVisibleTodosByProject: %w", err)
+	}
+	if q.listWatchersByTodoIDsStmt, err = db.PrepareContext(ctx, listWatchersByTodoIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListWatchersByTodoIDs: %w", err)
+	}
+	if q.listWebhookDeliveriesStmt, err = db.PrepareContext(ctx, listWebhookDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListWebhookDeliveries: %w", err)
+	}
+	if q.listWebhooksStmt, err = db.PrepareContext(ctx, listWebhooks); err != nil {
+		return nil, fmt.Errorf("error preparing query ListWebhooks: %w", err)
+	}
+	if q.markInboxReadStmt, err = db.PrepareContext(ctx, markInboxRead); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkInboxRead: %w", err)
+	}
+	if q.markOutboxEventPublishedStmt, err = db.PrepareContext(ctx, markOutboxEventPublished); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkOutboxEventPublished: %w", err)
+	}
+	if q.markReminderSentStmt, err = db.PrepareContext(ctx, markReminderSent); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkReminderSent: %w", err)
+	}
+	if q.moveTodoStmt, err = db.PrepareContext(ctx, moveTodo); err != nil {
+		return nil, fmt.Errorf("error preparing query MoveTodo: %w", err)
+	}
+	if q.pruneDescriptionOpsStmt, err = db.PrepareContext(ctx, pruneDescriptionOps); err != nil {
+		return nil, fmt.Errorf("error preparing query PruneDescriptionOps: %w", err)
+	}
+	if q.pruneDueDigestsStmt, err = db.PrepareContext(ctx, pruneDueDigests); err != nil {
+		return nil, fmt.Errorf("error preparing query PruneDueDigests: %w", err)
+	}
+	if q.pruneOutboxStmt, err = db.PrepareContext(ctx, pruneOutbox); err != nil {
+		return nil, fmt.Errorf("error preparing query PruneOutbox: %w", err)
+	}
+	if q.pruneResourceLocksStmt, err = db.PrepareContext(ctx, pruneResourceLocks); err != nil {
+		return nil, fmt.Errorf("error preparing query PruneResourceLocks: %w", err)
+	}
+	if q.pruneWebhookDeliveriesStmt, err = db.PrepareContext(ctx, pruneWebhookDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query PruneWebhookDeliveries: %w", err)
+	}
+	if q.reassignTodoTagsStmt, err = db.PrepareContext(ctx, reassignTodoTags); err != nil {
+		return nil, fmt.Errorf("error preparing query ReassignTodoTags: %w", err)
+	}
+	if q.recordChatOverduePostStmt, err = db.PrepareContext(ctx, recordChatOverduePost); err != nil {
+		return nil, fmt.Errorf("error preparing query RecordChatOverduePost: %w", err)
+	}
+	if q.recordOutboxFailureStmt, err = db.PrepareContext(ctx, recordOutboxFailure); err != nil {
+		return nil, fmt.Errorf("error preparing query RecordOutboxFailure: %w", err)
+	}
+	if q.renameTagStmt, err = db.PrepareContext(ctx, renameTag); err != nil {
+		return nil, fmt.Errorf("error preparing query RenameTag: %w", err)
+	}
+	if q.revokeAPIKeyStmt, err = db.PrepareContext(ctx, revokeAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeAPIKey: %w", err)
+	}
+	if q.revokeAPIKeysByCreatorStmt, err = db.PrepareContext(ctx, revokeAPIKeysByCreator); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeAPIKeysByCreator: %w", err)
+	}
+	if q.setNextTodoIDStmt, err = db.PrepareContext(ctx, setNextTodoID); err != nil {
+		return nil, fmt.Errorf("error preparing query SetNextTodoID: %w", err)
+	}
+	if q.setTodoPositionStmt, err = db.PrepareContext(ctx, setTodoPosition); err != nil {
+		return nil, fmt.Errorf("error preparing query SetTodoPosition: %w", err)
+	}
+	if q.shareProjectStmt, err = db.PrepareContext(ctx, shareProject); err != nil {
+		return nil, fmt.Errorf("error preparing query ShareProject: %w", err)
+	}
+	if q.shareTodoStmt, err = db.PrepareContext(ctx, shareTodo); err != nil {
+		return nil, fmt.Errorf("error preparing query ShareTodo: %w", err)
+	}
+	if q.snapshotUsageStmt, err = db.PrepareContext(ctx, snapshotUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query SnapshotUsage: %w", err)
+	}
+	if q.snoozeTodoStmt, err = db.PrepareContext(ctx, snoozeTodo); err != nil {
+		return nil, fmt.Errorf("error preparing query SnoozeTodo: %w", err)
+	}
 	if q.toggleTodoCompletedStmt, err = db.PrepareContext(ctx, toggleTodoCompleted); err != nil {
 		return nil, fmt.Errorf("error preparing query ToggleTodoCompleted: %w", err)
 	}
+	if q.touchAPIKeyStmt, err = db.PrepareContext(ctx, touchAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query TouchAPIKey: %w", err)
+	}
+	if q.touchHealthProbeStmt, err = db.PrepareContext(ctx, touchHealthProbe); err != nil {
+		return nil, fmt.Errorf("error preparing query TouchHealthProbe: %w", err)
+	}
+	if q.unarchiveProjectStmt, err = db.PrepareContext(ctx, unarchiveProject); err != nil {
+		return nil, fmt.Errorf("error preparing query UnarchiveProject: %w", err)
+	}
+	if q.unarchiveTodoStmt, err = db.PrepareContext(ctx, unarchiveTodo); err != nil {
+		return nil, fmt.Errorf("error preparing query UnarchiveTodo: %w", err)
+	}
+	if q.unwatchTodoStmt, err = db.PrepareContext(ctx, unwatchTodo); err != nil {
+		return nil, fmt.Errorf("error preparing query UnwatchTodo: %w", err)
+	}
+	if q.unwatchTodosStmt, err = db.PrepareContext(ctx, unwatchTodos); err != nil {
+		return nil, fmt.Errorf("error preparing query UnwatchTodos: %w", err)
+	}
+	if q.updateProjectStmt, err = db.PrepareContext(ctx, updateProject); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateProject: %w", err)
+	}
+	if q.updateSubtaskStmt, err = db.PrepareContext(ctx, updateSubtask); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateSubtask: %w", err)
+	}
 	if q.updateTodoStmt, err = db.PrepareContext(ctx, updateTodo); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateTodo: %w", err)
 	}
+	if q.updateTodoDescriptionStmt, err = db.PrepareContext(ctx, updateTodoDescription); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateTodoDescription: %w", err)
+	}
+	if q.updateUserRoleStmt, err = db.PrepareContext(ctx, updateUserRole); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserRole: %w", err)
+	}
+	if q.updateWebhookStmt, err = db.PrepareContext(ctx, updateWebhook); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateWebhook: %w", err)
+	}
+	if q.upsertProjectChatChannelStmt, err = db.PrepareContext(ctx, upsertProjectChatChannel); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertProjectChatChannel: %w", err)
+	}
+	if q.upsertTodoDefaultsStmt, err = db.PrepareContext(ctx, upsertTodoDefaults); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertTodoDefaults: %w", err)
+	}
+	if q.useActionLinkStmt, err = db.PrepareContext(ctx, useActionLink); err != nil {
+		return nil, fmt.Errorf("error preparing query UseActionLink: %w", err)
+	}
+	if q.watchTodoStmt, err = db.PrepareContext(ctx, watchTodo); err != nil {
+		return nil, fmt.Errorf("error preparing query WatchTodo: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
-	if q.createTodoStmt != nil {
-		if cerr := q.createTodoStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing createTodoStmt: %w", cerr)
+	if q.acceptInvitationStmt != nil {
+		if cerr := q.acceptInvitationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing acceptInvitationStmt: %w", cerr)
 		}
 	}
-	if q.deleteTodoStmt != nil {
-		if cerr := q.deleteTodoStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteTodoStmt: %w", cerr)
+	if q.acquireResourceLockStmt != nil {
+		if cerr := q.acquireResourceLockStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing acquireResourceLockStmt: %w", cerr)
 		}
 	}
-	if q.getTodoStmt != nil {
-		if cerr := q.getTodoStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getTodoStmt: %w", cerr)
+	if q.addOperationUsageStmt != nil {
+		if cerr := q.addOperationUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addOperationUsageStmt: %w", cerr)
 		}
 	}
-	if q.listTodosStmt != nil {
-		if cerr := q.listTodosStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing listTodosStmt: %w", cerr)
+	if q.addTodoTagStmt != nil {
+		if cerr := q.addTodoTagStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addTodoTagStmt: %w", cerr)
 		}
 	}
-	if q.listTodosByStatusStmt != nil {
-		if cerr := q.listTodosByStatusStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing listTodosByStatusStmt: %w", cerr)
+	if q.addUsageAPICallsStmt != nil {
+		if cerr := q.addUsageAPICallsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addUsageAPICallsStmt: %w", cerr)
 		}
 	}
-	if q.toggleTodoCompletedStmt != nil {
-		if cerr := q.toggleTodoCompletedStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing toggleTodoCompletedStmt: %w", cerr)
+	if q.archiveProjectStmt != nil {
+		if cerr := q.archiveProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing archiveProjectStmt: %w", cerr)
 		}
 	}
-	if q.updateTodoStmt != nil {
-		if cerr := q.updateTodoStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing updateTodoStmt: %w", cerr)
+	if q.archiveTodoStmt != nil {
+		if cerr := q.archiveTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing archiveTodoStmt: %w", cerr)
+		}
+	}
+	if q.completeIdempotencyKeyStmt != nil {
+		if cerr := q.completeIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing completeIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.consumeConfirmationTokenStmt != nil {
+		if cerr := q.consumeConfirmationTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing consumeConfirmationTokenStmt: %w", cerr)
+		}
+	}
+	if q.countActiveAPIKeysByCreatorStmt != nil {
+		if cerr := q.countActiveAPIKeysByCreatorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countActiveAPIKeysByCreatorStmt: %w", cerr)
+		}
+	}
+	if q.countCommentsByTodoIDsStmt != nil {
+		if cerr := q.countCommentsByTodoIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countCommentsByTodoIDsStmt: %w", cerr)
+		}
+	}
+	if q.countUsersStmt != nil {
+		if cerr := q.countUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countUsersStmt: %w", cerr)
+		}
+	}
+	if q.countUsersByRoleStmt != nil {
+		if cerr := q.countUsersByRoleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countUsersByRoleStmt: %w", cerr)
+		}
+	}
+	if q.createAPIKeyStmt != nil {
+		if cerr := q.createAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.createActivityStmt != nil {
+		if cerr := q.createActivityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createActivityStmt: %w", cerr)
+		}
+	}
+	if q.createAttachmentStmt != nil {
+		if cerr := q.createAttachmentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAttachmentStmt: %w", cerr)
+		}
+	}
+	if q.createAuthEventStmt != nil {
+		if cerr := q.createAuthEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAuthEventStmt: %w", cerr)
+		}
+	}
+	if q.createCommentStmt != nil {
+		if cerr := q.createCommentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCommentStmt: %w", cerr)
+		}
+	}
+	if q.createConfirmationTokenStmt != nil {
+		if cerr := q.createConfirmationTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createConfirmationTokenStmt: %w", cerr)
+		}
+	}
+	if q.createDescriptionOpStmt != nil {
+		if cerr := q.createDescriptionOpStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createDescriptionOpStmt: %w", cerr)
+		}
+	}
+	if q.createDueDigestStmt != nil {
+		if cerr := q.createDueDigestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createDueDigestStmt: %w", cerr)
+		}
+	}
+	if q.createIdempotencyKeyStmt != nil {
+		if cerr := q.createIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.createImportJobStmt != nil {
+		if cerr := q.createImportJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createImportJobStmt: %w", cerr)
+		}
+	}
+	if q.createInvitationStmt != nil {
+		if cerr := q.createInvitationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createInvitationStmt: %w", cerr)
+		}
+	}
+	if q.createOutboxEventStmt != nil {
+		if cerr := q.createOutboxEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createOutboxEventStmt: %w", cerr)
+		}
+	}
+	if q.createProjectStmt != nil {
+		if cerr := q.createProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createProjectStmt: %w", cerr)
+		}
+	}
+	if q.createReminderStmt != nil {
+		if cerr := q.createReminderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createReminderStmt: %w", cerr)
+		}
+	}
+	if q.createSavedFilterStmt != nil {
+		if cerr := q.createSavedFilterStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createSavedFilterStmt: %w", cerr)
+		}
+	}
+	if q.createStaleDigestStmt != nil {
+		if cerr := q.createStaleDigestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createStaleDigestStmt: %w", cerr)
+		}
+	}
+	if q.createSubtaskStmt != nil {
+		if cerr := q.createSubtaskStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createSubtaskStmt: %w", cerr)
+		}
+	}
+	if q.createTagStmt != nil {
+		if cerr := q.createTagStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTagStmt: %w", cerr)
+		}
+	}
+	if q.createTodoStmt != nil {
+		if cerr := q.createTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTodoStmt: %w", cerr)
+		}
+	}
+	if q.createUserStmt != nil {
+		if cerr := q.createUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createUserStmt: %w", cerr)
+		}
+	}
+	if q.createUserIdentityStmt != nil {
+		if cerr := q.createUserIdentityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createUserIdentityStmt: %w", cerr)
+		}
+	}
+	if q.createWebhookStmt != nil {
+		if cerr := q.createWebhookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWebhookStmt: %w", cerr)
+		}
+	}
+	if q.createWebhookDeliveryStmt != nil {
+		if cerr := q.createWebhookDeliveryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWebhookDeliveryStmt: %w", cerr)
+		}
+	}
+	if q.deleteCommentStmt != nil {
+		if cerr := q.deleteCommentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCommentStmt: %w", cerr)
+		}
+	}
+	if q.deleteCompletedTodosStmt != nil {
+		if cerr := q.deleteCompletedTodosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCompletedTodosStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredConfirmationTokensStmt != nil {
+		if cerr := q.deleteExpiredConfirmationTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredConfirmationTokensStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredIdempotencyKeysStmt != nil {
+		if cerr := q.deleteExpiredIdempotencyKeysStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredIdempotencyKeysStmt: %w", cerr)
+		}
+	}
+	if q.deleteIdempotencyKeyStmt != nil {
+		if cerr := q.deleteIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.deleteInvitationStmt != nil {
+		if cerr := q.deleteInvitationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteInvitationStmt: %w", cerr)
+		}
+	}
+	if q.deleteProjectStmt != nil {
+		if cerr := q.deleteProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteProjectStmt: %w", cerr)
+		}
+	}
+	if q.deleteProjectChatChannelStmt != nil {
+		if cerr := q.deleteProjectChatChannelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteProjectChatChannelStmt: %w", cerr)
+		}
+	}
+	if q.deleteProjectShareStmt != nil {
+		if cerr := q.deleteProjectShareStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteProjectShareStmt: %w", cerr)
+		}
+	}
+	if q.deleteReminderStmt != nil {
+		if cerr := q.deleteReminderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteReminderStmt: %w", cerr)
+		}
+	}
+	if q.deleteResourceLockStmt != nil {
+		if cerr := q.deleteResourceLockStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteResourceLockStmt: %w", cerr)
+		}
+	}
+	if q.deleteSavedFilterStmt != nil {
+		if cerr := q.deleteSavedFilterStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSavedFilterStmt: %w", cerr)
+		}
+	}
+	if q.deleteSubtaskStmt != nil {
+		if cerr := q.deleteSubtaskStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSubtaskStmt: %w", cerr)
+		}
+	}
+	if q.deleteTagStmt != nil {
+		if cerr := q.deleteTagStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTagStmt: %w", cerr)
+		}
+	}
+	if q.deleteTodoStmt != nil {
+		if cerr := q.deleteTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTodoStmt: %w", cerr)
+		}
+	}
+	if q.deleteTodoDefaultsStmt != nil {
+		if cerr := q.deleteTodoDefaultsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTodoDefaultsStmt: %w", cerr)
+		}
+	}
+	if q.deleteTodoShareStmt != nil {
+		if cerr := q.deleteTodoShareStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTodoShareStmt: %w", cerr)
+		}
+	}
+	if q.deleteTodoTagsStmt != nil {
+		if cerr := q.deleteTodoTagsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTodoTagsStmt: %w", cerr)
+		}
+	}
+	if q.deleteTodosByIDsStmt != nil {
+		if cerr := q.deleteTodosByIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTodosByIDsStmt: %w", cerr)
+		}
+	}
+	if q.deleteUserStmt != nil {
+		if cerr := q.deleteUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserStmt: %w", cerr)
+		}
+	}
+	if q.deleteWebhookStmt != nil {
+		if cerr := q.deleteWebhookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteWebhookStmt: %w", cerr)
+		}
+	}
+	if q.endRecurrenceStmt != nil {
+		if cerr := q.endRecurrenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing endRecurrenceStmt: %w", cerr)
+		}
+	}
+	if q.escalateTodoStmt != nil {
+		if cerr := q.escalateTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing escalateTodoStmt: %w", cerr)
+		}
+	}
+	if q.failInterruptedImportJobsStmt != nil {
+		if cerr := q.failInterruptedImportJobsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failInterruptedImportJobsStmt: %w", cerr)
+		}
+	}
+	if q.finishImportJobStmt != nil {
+		if cerr := q.finishImportJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing finishImportJobStmt: %w", cerr)
+		}
+	}
+	if q.finishWebhookDeliveryStmt != nil {
+		if cerr := q.finishWebhookDeliveryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing finishWebhookDeliveryStmt: %w", cerr)
+		}
+	}
+	if q.getAPIKeyStmt != nil {
+		if cerr := q.getAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.getActiveAPIKeyByHashStmt != nil {
+		if cerr := q.getActiveAPIKeyByHashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getActiveAPIKeyByHashStmt: %w", cerr)
+		}
+	}
+	if q.getAttachmentStmt != nil {
+		if cerr := q.getAttachmentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAttachmentStmt: %w", cerr)
+		}
+	}
+	if q.getAuthLocationStatsStmt != nil {
+		if cerr := q.getAuthLocationStatsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAuthLocationStatsStmt: %w", cerr)
+		}
+	}
+	if q.getBadgeCountsStmt != nil {
+		if cerr := q.getBadgeCountsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBadgeCountsStmt: %w", cerr)
+		}
+	}
+	if q.getChatChannelForTodoStmt != nil {
+		if cerr := q.getChatChannelForTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getChatChannelForTodoStmt: %w", cerr)
+		}
+	}
+	if q.getCommentStmt != nil {
+		if cerr := q.getCommentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCommentStmt: %w", cerr)
+		}
+	}
+	if q.getIdempotencyKeyStmt != nil {
+		if cerr := q.getIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.getImportJobStmt != nil {
+		if cerr := q.getImportJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getImportJobStmt: %w", cerr)
+		}
+	}
+	if q.getInboxSummaryStmt != nil {
+		if cerr := q.getInboxSummaryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getInboxSummaryStmt: %w", cerr)
+		}
+	}
+	if q.getInvitationByTokenHashStmt != nil {
+		if cerr := q.getInvitationByTokenHashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getInvitationByTokenHashStmt: %w", cerr)
+		}
+	}
+	if q.getLatestActivityStmt != nil {
+		if cerr := q.getLatestActivityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLatestActivityStmt: %w", cerr)
+		}
+	}
+	if q.getLatestDescriptionOpStmt != nil {
+		if cerr := q.getLatestDescriptionOpStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLatestDescriptionOpStmt: %w", cerr)
+		}
+	}
+	if q.getLatestOutboxEventIDStmt != nil {
+		if cerr := q.getLatestOutboxEventIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLatestOutboxEventIDStmt: %w", cerr)
+		}
+	}
+	if q.getLatestStaleDigestStmt != nil {
+		if cerr := q.getLatestStaleDigestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLatestStaleDigestStmt: %w", cerr)
+		}
+	}
+	if q.getNextActivityStmt != nil {
+		if cerr := q.getNextActivityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNextActivityStmt: %w", cerr)
+		}
+	}
+	if q.getOldestDescriptionRevisionStmt != nil {
+		if cerr := q.getOldestDescriptionRevisionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getOldestDescriptionRevisionStmt: %w", cerr)
+		}
+	}
+	if q.getPendingInvitationByEmailStmt != nil {
+		if cerr := q.getPendingInvitationByEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPendingInvitationByEmailStmt: %w", cerr)
+		}
+	}
+	if q.getProjectStmt != nil {
+		if cerr := q.getProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProjectStmt: %w", cerr)
+		}
+	}
+	if q.getProjectChatChannelStmt != nil {
+		if cerr := q.getProjectChatChannelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProjectChatChannelStmt: %w", cerr)
+		}
+	}
+	if q.getResourceLockStmt != nil {
+		if cerr := q.getResourceLockStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getResourceLockStmt: %w", cerr)
+		}
+	}
+	if q.getSavedFilterStmt != nil {
+		if cerr := q.getSavedFilterStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSavedFilterStmt: %w", cerr)
+		}
+	}
+	if q.getSubtaskStmt != nil {
+		if cerr := q.getSubtaskStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSubtaskStmt: %w", cerr)
+		}
+	}
+	if q.getTagStmt != nil {
+		if cerr := q.getTagStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTagStmt: %w", cerr)
+		}
+	}
+	if q.getTagByNameStmt != nil {
+		if cerr := q.getTagByNameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTagByNameStmt: %w", cerr)
+		}
+	}
+	if q.getTodoStmt != nil {
+		if cerr := q.getTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTodoStmt: %w", cerr)
+		}
+	}
+	if q.getTodoByIDStmt != nil {
+		if cerr := q.getTodoByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTodoByIDStmt: %w", cerr)
+		}
+	}
+	if q.getTodoDefaultsStmt != nil {
+		if cerr := q.getTodoDefaultsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTodoDefaultsStmt: %w", cerr)
+		}
+	}
+	if q.getUserStmt != nil {
+		if cerr := q.getUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserStmt: %w", cerr)
+		}
+	}
+	if q.getUserByEmailStmt != nil {
+		if cerr := q.getUserByEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserByEmailStmt: %w", cerr)
+		}
+	}
+	if q.getUserByIdentityStmt != nil {
+		if cerr := q.getUserByIdentityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserByIdentityStmt: %w", cerr)
+		}
+	}
+	if q.getWebhookStmt != nil {
+		if cerr := q.getWebhookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWebhookStmt: %w", cerr)
+		}
+	}
+	if q.hasRecentAuthEventStmt != nil {
+		if cerr := q.hasRecentAuthEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing hasRecentAuthEventStmt: %w", cerr)
+		}
+	}
+	if q.listAPIKeysStmt != nil {
+		if cerr := q.listAPIKeysStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAPIKeysStmt: %w", cerr)
+		}
+	}
+	if q.listActiveWebhooksForOwnerStmt != nil {
+		if cerr := q.listActiveWebhooksForOwnerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listActiveWebhooksForOwnerStmt: %w", cerr)
+		}
+	}
+	if q.listActivityStmt != nil {
+		if cerr := q.listActivityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listActivityStmt: %w", cerr)
+		}
+	}
+	if q.listAgendaRemindersStmt != nil {
+		if cerr := q.listAgendaRemindersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAgendaRemindersStmt: %w", cerr)
+		}
+	}
+	if q.listAgendaTodosStmt != nil {
+		if cerr := q.listAgendaTodosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAgendaTodosStmt: %w", cerr)
+		}
+	}
+	if q.listAttachmentsStmt != nil {
+		if cerr := q.listAttachmentsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAttachmentsStmt: %w", cerr)
+		}
+	}
+	if q.listAttachmentsByTodoIDsStmt != nil {
+		if cerr := q.listAttachmentsByTodoIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAttachmentsByTodoIDsStmt: %w", cerr)
+		}
+	}
+	if q.listAuthEventsStmt != nil {
+		if cerr := q.listAuthEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAuthEventsStmt: %w", cerr)
+		}
+	}
+	if q.listChatOverdueDeliveriesStmt != nil {
+		if cerr := q.listChatOverdueDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listChatOverdueDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.listCommentsStmt != nil {
+		if cerr := q.listCommentsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCommentsStmt: %w", cerr)
+		}
+	}
+	if q.listCommentsByProjectStmt != nil {
+		if cerr := q.listCommentsByProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCommentsByProjectStmt: %w", cerr)
+		}
+	}
+	if q.listCommentsByTodoIDsStmt != nil {
+		if cerr := q.listCommentsByTodoIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCommentsByTodoIDsStmt: %w", cerr)
+		}
+	}
+	if q.listCompletedTodoIDsStmt != nil {
+		if cerr := q.listCompletedTodoIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCompletedTodoIDsStmt: %w", cerr)
+		}
+	}
+	if q.listDescriptionOpsStmt != nil {
+		if cerr := q.listDescriptionOpsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDescriptionOpsStmt: %w", cerr)
+		}
+	}
+	if q.listDueDigestTodosStmt != nil {
+		if cerr := q.listDueDigestTodosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDueDigestTodosStmt: %w", cerr)
+		}
+	}
+	if q.listDueRemindersStmt != nil {
+		if cerr := q.listDueRemindersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDueRemindersStmt: %w", cerr)
+		}
+	}
+	if q.listDueWebhookDeliveriesStmt != nil {
+		if cerr := q.listDueWebhookDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDueWebhookDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.listImportJobsStmt != nil {
+		if cerr := q.listImportJobsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listImportJobsStmt: %w", cerr)
+		}
+	}
+	if q.listInvitationsStmt != nil {
+		if cerr := q.listInvitationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listInvitationsStmt: %w", cerr)
+		}
+	}
+	if q.listOperationUsageStmt != nil {
+		if cerr := q.listOperationUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listOperationUsageStmt: %w", cerr)
+		}
+	}
+	if q.listOverdueTodosStmt != nil {
+		if cerr := q.listOverdueTodosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listOverdueTodosStmt: %w", cerr)
+		}
+	}
+	if q.listOwnedTodoIDsStmt != nil {
+		if cerr := q.listOwnedTodoIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listOwnedTodoIDsStmt: %w", cerr)
+		}
+	}
+	if q.listPendingOutboxEventsStmt != nil {
+		if cerr := q.listPendingOutboxEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPendingOutboxEventsStmt: %w", cerr)
+		}
+	}
+	if q.listPendingRecurrencesStmt != nil {
+		if cerr := q.listPendingRecurrencesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPendingRecurrencesStmt: %w", cerr)
+		}
+	}
+	if q.listPendingRecurringTodosStmt != nil {
+		if cerr := q.listPendingRecurringTodosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPendingRecurringTodosStmt: %w", cerr)
+		}
+	}
+	if q.listProjectChatChannelsStmt != nil {
+		if cerr := q.listProjectChatChannelsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listProjectChatChannelsStmt: %w", cerr)
+		}
+	}
+	if q.listProjectSharesStmt != nil {
+		if cerr := q.listProjectSharesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listProjectSharesStmt: %w", cerr)
+		}
+	}
+	if q.listProjectsStmt != nil {
+		if cerr := q.listProjectsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listProjectsStmt: %w", cerr)
+		}
+	}
+	if q.listProjectsByIDsStmt != nil {
+		if cerr := q.listProjectsByIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listProjectsByIDsStmt: %w", cerr)
+		}
+	}
+	if q.listRemindersStmt != nil {
+		if cerr := q.listRemindersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listRemindersStmt: %w", cerr)
+		}
+	}
+	if q.listRemindersByTodoIDsStmt != nil {
+		if cerr := q.listRemindersByTodoIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listRemindersByTodoIDsStmt: %w", cerr)
+		}
+	}
+	if q.listSavedFiltersStmt != nil {
+		if cerr := q.listSavedFiltersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSavedFiltersStmt: %w", cerr)
+		}
+	}
+	if q.listStaleTodosStmt != nil {
+		if cerr := q.listStaleTodosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listStaleTodosStmt: %w", cerr)
+		}
+	}
+	if q.listSubtasksStmt != nil {
+		if cerr := q.listSubtasksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSubtasksStmt: %w", cerr)
+		}
+	}
+	if q.listSubtasksByProjectStmt != nil {
+		if cerr := q.listSubtasksByProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSubtasksByProjectStmt: %w", cerr)
+		}
+	}
+	if q.listSubtasksByTodoIDsStmt != nil {
+		if cerr := q.listSubtasksByTodoIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSubtasksByTodoIDsStmt: %w", cerr)
+		}
+	}
+	if q.listTagTodoIDsStmt != nil {
+		if cerr := q.listTagTodoIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTagTodoIDsStmt: %w", cerr)
+		}
+	}
+	if q.listTagsStmt != nil {
+		if cerr := q.listTagsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTagsStmt: %w", cerr)
+		}
+	}
+	if q.listTodoActivityStmt != nil {
+		if cerr := q.listTodoActivityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodoActivityStmt: %w", cerr)
+		}
+	}
+	if q.listTodoChatDeliveriesStmt != nil {
+		if cerr := q.listTodoChatDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodoChatDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.listTodoDefaultsForProjectStmt != nil {
+		if cerr := q.listTodoDefaultsForProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodoDefaultsForProjectStmt: %w", cerr)
+		}
+	}
+	if q.listTodoIDsInProjectStmt != nil {
+		if cerr := q.listTodoIDsInProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodoIDsInProjectStmt: %w", cerr)
+		}
+	}
+	if q.listTodoLocksStmt != nil {
+		if cerr := q.listTodoLocksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodoLocksStmt: %w", cerr)
+		}
+	}
+	if q.listTodoSharesStmt != nil {
+		if cerr := q.listTodoSharesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodoSharesStmt: %w", cerr)
+		}
+	}
+	if q.listTodoTagNamesStmt != nil {
+		if cerr := q.listTodoTagNamesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodoTagNamesStmt: %w", cerr)
+		}
+	}
+	if q.listTodoWatchersToNotifyStmt != nil {
+		if cerr := q.listTodoWatchersToNotifyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodoWatchersToNotifyStmt: %w", cerr)
+		}
+	}
+	if q.listTodosStmt != nil {
+		if cerr := q.listTodosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodosStmt: %w", cerr)
+		}
+	}
+	if q.listTodosByOwnerStmt != nil {
+		if cerr := q.listTodosByOwnerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodosByOwnerStmt: %w", cerr)
+		}
+	}
+	if q.listTodosByProjectStmt != nil {
+		if cerr := q.listTodosByProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodosByProjectStmt: %w", cerr)
+		}
+	}
+	if q.listTodosByStatusStmt != nil {
+		if cerr := q.listTodosByStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodosByStatusStmt: %w", cerr)
+		}
+	}
+	if q.listTodosForExportStmt != nil {
+		if cerr := q.listTodosForExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodosForExportStmt: %w", cerr)
+		}
+	}
+	if q.listTodosNearStmt != nil {
+		if cerr := q.listTodosNearStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodosNearStmt: %w", cerr)
+		}
+	}
+	if q.listUsageStmt != nil {
+		if cerr := q.listUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUsageStmt: %w", cerr)
+		}
+	}
+	if q.listUsersStmt != nil {
+		if cerr := q.listUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUsersStmt: %w", cerr)
+		}
+	}
+	if q.listVisibleTodosByProjectStmt != nil {
+		if cerr := q.listVisibleTodosByProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listVisibleTodosByProjectStmt: %w", cerr)
+		}
+	}
+	if q.listWatchersByTodoIDsStmt != nil {
+		if cerr := q.listWatchersByTodoIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listWatchersByTodoIDsStmt: %w", cerr)
+		}
+	}
+	if q.listWebhookDeliveriesStmt != nil {
+		if cerr := q.listWebhookDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listWebhookDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.listWebhooksStmt != nil {
+		if cerr := q.listWebhooksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listWebhooksStmt: %w", cerr)
+		}
+	}
+	if q.markInboxReadStmt != nil {
+		if cerr := q.markInboxReadStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markInboxReadStmt: %w", cerr)
+		}
+	}
+	if q.markOutboxEventPublishedStmt != nil {
+		if cerr := q.markOutboxEventPublishedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markOutboxEventPublishedStmt: %w", cerr)
+		}
+	}
+	if q.markReminderSentStmt != nil {
+		if cerr := q.markReminderSentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markReminderSentStmt: %w", cerr)
+		}
+	}
+	if q.moveTodoStmt != nil {
+		if cerr := q.moveTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing moveTodoStmt: %w", cerr)
+		}
+	}
+	if q.pruneDescriptionOpsStmt != nil {
+		if cerr := q.pruneDescriptionOpsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing pruneDescriptionOpsStmt: %w", cerr)
+		}
+	}
+	if q.pruneDueDigestsStmt != nil {
+		if cerr := q.pruneDueDigestsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing pruneDueDigestsStmt: %w", cerr)
+		}
+	}
+	if q.pruneOutboxStmt != nil {
+		if cerr := q.pruneOutboxStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing pruneOutboxStmt: %w", cerr)
+		}
+	}
+	if q.pruneResourceLocksStmt != nil {
+		if cerr := q.pruneResourceLocksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing pruneResourceLocksStmt: %w", cerr)
+		}
+	}
+	if q.pruneWebhookDeliveriesStmt != nil {
+		if cerr := q.pruneWebhookDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing pruneWebhookDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.reassignTodoTagsStmt != nil {
+		if cerr := q.reassignTodoTagsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing reassignTodoTagsStmt: %w", cerr)
+		}
+	}
+	if q.recordChatOverduePostStmt != nil {
+		if cerr := q.recordChatOverduePostStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing recordChatOverduePostStmt: %w", cerr)
+		}
+	}
+	if q.recordOutboxFailureStmt != nil {
+		if cerr := q.recordOutboxFailureStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing recordOutboxFailureStmt: %w", cerr)
+		}
+	}
+	if q.renameTagStmt != nil {
+		if cerr := q.renameTagStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing renameTagStmt: %w", cerr)
+		}
+	}
+	if q.revokeAPIKeyStmt != nil {
+		if cerr := q.revokeAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.revokeAPIKeysByCreatorStmt != nil {
+		if cerr := q.revokeAPIKeysByCreatorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeAPIKeysByCreatorStmt: %w", cerr)
+		}
+	}
+	if q.setNextTodoIDStmt != nil {
+		if cerr := q.setNextTodoIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setNextTodoIDStmt: %w", cerr)
+		}
+	}
+	if q.setTodoPositionStmt != nil {
+		if cerr := q.setTodoPositionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setTodoPositionStmt: %w", cerr)
+		}
+	}
+	if q.shareProjectStmt != nil {
+		if cerr := q.shareProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing shareProjectStmt: %w", cerr)
+		}
+	}
+	if q.shareTodoStmt != nil {
+		if cerr := q.shareTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing shareTodoStmt: %w", cerr)
+		}
+	}
+	if q.snapshotUsageStmt != nil {
+		if cerr := q.snapshotUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing snapshotUsageStmt: %w", cerr)
+		}
+	}
+	if q.snoozeTodoStmt != nil {
+		if cerr := q.snoozeTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing snoozeTodoStmt: %w", cerr)
+		}
+	}
+	if q.toggleTodoCompletedStmt != nil {
+		if cerr := q.toggleTodoCompletedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing toggleTodoCompletedStmt: %w", cerr)
+		}
+	}
+	if q.touchAPIKeyStmt != nil {
+		if cerr := q.touchAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing touchAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.touchHealthProbeStmt != nil {
+		if cerr := q.touchHealthProbeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing touchHealthProbeStmt: %w", cerr)
+		}
+	}
+	if q.unarchiveProjectStmt != nil {
+		if cerr := q.unarchiveProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing unarchiveProjectStmt: %w", cerr)
+		}
+	}
+	if q.unarchiveTodoStmt != nil {
+		if cerr := q.unarchiveTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing unarchiveTodoStmt: %w", cerr)
+		}
+	}
+	if q.unwatchTodoStmt != nil {
+		if cerr := q.unwatchTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing unwatchTodoStmt: %w", cerr)
+		}
+	}
+	if q.unwatchTodosStmt != nil {
+		if cerr := q.unwatchTodosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing unwatchTodosStmt: %w", cerr)
+		}
+	}
+	if q.updateProjectStmt != nil {
+		if cerr := q.updateProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateProjectStmt: %w", cerr)
+		}
+	}
+	if q.updateSubtaskStmt != nil {
+		if cerr := q.updateSubtaskStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateSubtaskStmt: %w", cerr)
+		}
+	}
+	if q.updateTodoStmt != nil {
+		if cerr := q.updateTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateTodoStmt: %w", cerr)
+		}
+	}
+	if q.updateTodoDescriptionStmt != nil {
+		if cerr := q.updateTodoDescriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateTodoDescriptionStmt: %w", cerr)
+		}
+	}
+	if q.updateUserRoleStmt != nil {
+		if cerr := q.updateUserRoleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserRoleStmt: %w", cerr)
+		}
+	}
+	if q.updateWebhookStmt != nil {
+		if cerr := q.updateWebhookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateWebhookStmt: %w", cerr)
+		}
+	}
+	if q.upsertProjectChatChannelStmt != nil {
+		if cerr := q.upsertProjectChatChannelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertProjectChatChannelStmt: %w", cerr)
+		}
+	}
+	if q.upsertTodoDefaultsStmt != nil {
+		if cerr := q.upsertTodoDefaultsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertTodoDefaultsStmt: %w", cerr)
+		}
+	}
+	if q.useActionLinkStmt != nil {
+		if cerr := q.useActionLinkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing useActionLinkStmt: %w", cerr)
+		}
+	}
+	if q.watchTodoStmt != nil {
+		if cerr := q.watchTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing watchTodoStmt: %w", cerr)
 		}
 	}
 	return err
@@ -122,27 +1594,395 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                      DBTX
-	tx                      *sql.Tx
-	createTodoStmt          *sql.Stmt
-	deleteTodoStmt          *sql.Stmt
-	getTodoStmt             *sql.Stmt
-	listTodosStmt           *sql.Stmt
-	listTodosByStatusStmt   *sql.Stmt
-	toggleTodoCompletedStmt *sql.Stmt
-	updateTodoStmt          *sql.Stmt
+	db                                  DBTX
+	tx                                  *sql.Tx
+	acceptInvitationStmt                *sql.Stmt
+	acquireResourceLockStmt             *sql.Stmt
+	addOperationUsageStmt               *sql.Stmt
+	addTodoTagStmt                      *sql.Stmt
+	addUsageAPICallsStmt                *sql.Stmt
+	archiveProjectStmt                  *sql.Stmt
+	archiveTodoStmt                     *sql.Stmt
+	completeIdempotencyKeyStmt          *sql.Stmt
+	consumeConfirmationTokenStmt        *sql.Stmt
+	countActiveAPIKeysByCreatorStmt     *sql.Stmt
+	countCommentsByTodoIDsStmt          *sql.Stmt
+	countUsersStmt                      *sql.Stmt
+	countUsersByRoleStmt                *sql.Stmt
+	createAPIKeyStmt                    *sql.Stmt
+	createActivityStmt                  *sql.Stmt
+	createAttachmentStmt                *sql.Stmt
+	createAuthEventStmt                 *sql.Stmt
+	createCommentStmt                   *sql.Stmt
+	createConfirmationTokenStmt         *sql.Stmt
+	createDescriptionOpStmt             *sql.Stmt
+	createDueDigestStmt                 *sql.Stmt
+	createIdempotencyKeyStmt            *sql.Stmt
+	createImportJobStmt                 *sql.Stmt
+	createInvitationStmt                *sql.Stmt
+	createOutboxEventStmt               *sql.Stmt
+	createProjectStmt                   *sql.Stmt
+	createReminderStmt                  *sql.Stmt
+	createSavedFilterStmt               *sql.Stmt
+	createStaleDigestStmt               *sql.Stmt
+	createSubtaskStmt                   *sql.Stmt
+	createTagStmt                       *sql.Stmt
+	createTodoStmt                      *sql.Stmt
+	createUserStmt                      *sql.Stmt
+	createUserIdentityStmt              *sql.Stmt
+	createWebhookStmt                   *sql.Stmt
+	createWebhookDeliveryStmt           *sql.Stmt
+	deleteCommentStmt                   *sql.Stmt
+	deleteCompletedTodosStmt            *sql.Stmt
+	deleteExpiredConfirmationTokensStmt *sql.Stmt
+	deleteExpiredIdempotencyKeysStmt    *sql.Stmt
+	deleteIdempotencyKeyStmt            *sql.Stmt
+	deleteInvitationStmt                *sql.Stmt
+	deleteProjectStmt                   *sql.Stmt
+	deleteProjectChatChannelStmt        *sql.Stmt
+	deleteProjectShareStmt              *sql.Stmt
+	deleteReminderStmt                  *sql.Stmt
+	deleteResourceLockStmt              *sql.Stmt
+	deleteSavedFilterStmt               *sql.Stmt
+	deleteSubtaskStmt                   *sql.Stmt
+	deleteTagStmt                       *sql.Stmt
+	deleteTodoStmt                      *sql.Stmt
+	deleteTodoDefaultsStmt              *sql.Stmt
+	deleteTodoShareStmt                 *sql.Stmt
+	deleteTodoTagsStmt                  *sql.Stmt
+	deleteTodosByIDsStmt                *sql.Stmt
+	deleteUserStmt                      *sql.Stmt
+	deleteWebhookStmt                   *sql.Stmt
+	endRecurrenceStmt                   *sql.Stmt
+	escalateTodoStmt                    *sql.Stmt
+	failInterruptedImportJobsStmt       *sql.Stmt
+	finishImportJobStmt                 *sql.Stmt
+	finishWebhookDeliveryStmt           *sql.Stmt
+	getAPIKeyStmt                       *sql.Stmt
+	getActiveAPIKeyByHashStmt           *sql.Stmt
+	getAttachmentStmt                   *sql.Stmt
+	getAuthLocationStatsStmt            *sql.Stmt
+	getBadgeCountsStmt                  *sql.Stmt
+	getChatChannelForTodoStmt           *sql.Stmt
+	getCommentStmt                      *sql.Stmt
+	getIdempotencyKeyStmt               *sql.Stmt
+	getImportJobStmt                    *sql.Stmt
+	getInboxSummaryStmt                 *sql.Stmt
+	getInvitationByTokenHashStmt        *sql.Stmt
+	getLatestActivityStmt               *sql.Stmt
+	getLatestDescriptionOpStmt          *sql.Stmt
+	getLatestOutboxEventIDStmt          *sql.Stmt
+	getLatestStaleDigestStmt            *sql.Stmt
+	getNextActivityStmt                 *sql.Stmt
+	getOldestDescriptionRevisionStmt    *sql.Stmt
+	getPendingInvitationByEmailStmt     *sql.Stmt
+	getProjectStmt                      *sql.Stmt
+	getProjectChatChannelStmt           *sql.Stmt
+	getResourceLockStmt                 *sql.Stmt
+	getSavedFilterStmt                  *sql.Stmt
+	getSubtaskStmt                      *sql.Stmt
+	getTagStmt                          *sql.Stmt
+	getTagByNameStmt                    *sql.Stmt
+	getTodoStmt                         *sql.Stmt
+	getTodoByIDStmt                     *sql.Stmt
+	getTodoDefaultsStmt                 *sql.Stmt
+	getUserStmt                         *sql.Stmt
+	getUserByEmailStmt                  *sql.Stmt
+	getUserByIdentityStmt               *sql.Stmt
+	getWebhookStmt                      *sql.Stmt
+	hasRecentAuthEventStmt              *sql.Stmt
+	listAPIKeysStmt                     *sql.Stmt
+	listActiveWebhooksForOwnerStmt      *sql.Stmt
+	listActivityStmt                    *sql.Stmt
+	listAgendaRemindersStmt             *sql.Stmt
+	listAgendaTodosStmt                 *sql.Stmt
+	listAttachmentsStmt                 *sql.Stmt
+	listAttachmentsByTodoIDsStmt        *sql.Stmt
+	listAuthEventsStmt                  *sql.Stmt
+	listChatOverdueDeliveriesStmt       *sql.Stmt
+	listCommentsStmt                    *sql.Stmt
+	listCommentsByProjectStmt           *sql.Stmt
+	listCommentsByTodoIDsStmt           *sql.Stmt
+	listCompletedTodoIDsStmt            *sql.Stmt
+	listDescriptionOpsStmt              *sql.Stmt
+	listDueDigestTodosStmt              *sql.Stmt
+	listDueRemindersStmt                *sql.Stmt
+	listDueWebhookDeliveriesStmt        *sql.Stmt
+	listImportJobsStmt                  *sql.Stmt
+	listInvitationsStmt                 *sql.Stmt
+	listOperationUsageStmt              *sql.Stmt
+	listOverdueTodosStmt                *sql.Stmt
+	listOwnedTodoIDsStmt                *sql.Stmt
+	listPendingOutboxEventsStmt         *sql.Stmt
+	listPendingRecurrencesStmt          *sql.Stmt
+	listPendingRecurringTodosStmt       *sql.Stmt
+	listProjectChatChannelsStmt         *sql.Stmt
+	listProjectSharesStmt               *sql.Stmt
+	listProjectsStmt                    *sql.Stmt
+	listProjectsByIDsStmt               *sql.Stmt
+	listRemindersStmt                   *sql.Stmt
+	listRemindersByTodoIDsStmt          *sql.Stmt
+	listSavedFiltersStmt                *sql.Stmt
+	listStaleTodosStmt                  *sql.Stmt
+	listSubtasksStmt                    *sql.Stmt
+	listSubtasksByProjectStmt           *sql.Stmt
+	listSubtasksByTodoIDsStmt           *sql.Stmt
+	listTagTodoIDsStmt                  *sql.Stmt
+	listTagsStmt                        *sql.Stmt
+	listTodoActivityStmt                *sql.Stmt
+	listTodoChatDeliveriesStmt          *sql.Stmt
+	listTodoDefaultsForProjectStmt      *sql.Stmt
+	listTodoIDsInProjectStmt            *sql.Stmt
+	listTodoLocksStmt                   *sql.Stmt
+	listTodoSharesStmt                  *sql.Stmt
+	listTodoTagNamesStmt                *sql.Stmt
+	listTodoWatchersToNotifyStmt        *sql.Stmt
+	listTodosStmt                       *sql.Stmt
+	listTodosByOwnerStmt                *sql.Stmt
+	listTodosByProjectStmt              *sql.Stmt
+	listTodosByStatusStmt               *sql.Stmt
+	listTodosForExportStmt              *sql.Stmt
+	listTodosNearStmt                   *sql.Stmt
+	listUsageStmt                       *sql.Stmt
+	listUsersStmt                       *sql.Stmt
+	listVisibleTodosByProjectStmt       *sql.Stmt
+	listWatchersByTodoIDsStmt           *sql.Stmt
+	listWebhookDeliveriesStmt           *sql.Stmt
+	listWebhooksStmt                    *sql.Stmt
+	markInboxReadStmt                   *sql.Stmt
+	markOutboxEventPublishedStmt        *sql.Stmt
+	markReminderSentStmt                *sql.Stmt
+	moveTodoStmt                        *sql.Stmt
+	pruneDescriptionOpsStmt             *sql.Stmt
+	pruneDueDigestsStmt                 *sql.Stmt
+	pruneOutboxStmt                     *sql.Stmt
+	pruneResourceLocksStmt              *sql.Stmt
+	pruneWebhookDeliveriesStmt          *sql.Stmt
+	reassignTodoTagsStmt                *sql.Stmt
+	recordChatOverduePostStmt           *sql.Stmt
+	recordOutboxFailureStmt             *sql.Stmt
+	renameTagStmt                       *sql.Stmt
+	revokeAPIKeyStmt                    *sql.Stmt
+	revokeAPIKeysByCreatorStmt          *sql.Stmt
+	setNextTodoIDStmt                   *sql.Stmt
+	setTodoPositionStmt                 *sql.Stmt
+	shareProjectStmt                    *sql.Stmt
+	shareTodoStmt                       *sql.Stmt
+	snapshotUsageStmt                   *sql.Stmt
+	snoozeTodoStmt                      *sql.Stmt
+	toggleTodoCompletedStmt             *sql.Stmt
+	touchAPIKeyStmt                     *sql.Stmt
+	touchHealthProbeStmt                *sql.Stmt
+	unarchiveProjectStmt                *sql.Stmt
+	unarchiveTodoStmt                   *sql.Stmt
+	unwatchTodoStmt                     *sql.Stmt
+	unwatchTodosStmt                    *sql.Stmt
+	updateProjectStmt                   *sql.Stmt
+	updateSubtaskStmt                   *sql.Stmt
+	updateTodoStmt                      *sql.Stmt
+	updateTodoDescriptionStmt           *sql.Stmt
+	updateUserRoleStmt                  *sql.Stmt
+	updateWebhookStmt                   *sql.Stmt
+	upsertProjectChatChannelStmt        *sql.Stmt
+	upsertTodoDefaultsStmt              *sql.Stmt
+	useActionLinkStmt                   *sql.Stmt
+	watchTodoStmt                       *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                      tx,
-		tx:                      tx,
-		createTodoStmt:          q.createTodoStmt,
-		deleteTodoStmt:          q.deleteTodoStmt,
-		getTodoStmt:             q.getTodoStmt,
-		listTodosStmt:           q.listTodosStmt,
-		listTodosByStatusStmt:   q.listTodosByStatusStmt,
-		toggleTodoCompletedStmt: q.toggleTodoCompletedStmt,
-		updateTodoStmt:          q.updateTodoStmt,
+		db:                                  tx,
+		tx:                                  tx,
+		acceptInvitationStmt:                q.acceptInvitationStmt,
+		acquireResourceLockStmt:             q.acquireResourceLockStmt,
+		addOperationUsageStmt:               q.addOperationUsageStmt,
+		addTodoTagStmt:                      q.addTodoTagStmt,
+		addUsageAPICallsStmt:                q.addUsageAPICallsStmt,
+		archiveProjectStmt:                  q.archiveProjectStmt,
+		archiveTodoStmt:                     q.archiveTodoStmt,
+		completeIdempotencyKeyStmt:          q.completeIdempotencyKeyStmt,
+		consumeConfirmationTokenStmt:        q.consumeConfirmationTokenStmt,
+		countActiveAPIKeysByCreatorStmt:     q.countActiveAPIKeysByCreatorStmt,
+		countCommentsByTodoIDsStmt:          q.countCommentsByTodoIDsStmt,
+		countUsersStmt:                      q.countUsersStmt,
+		countUsersByRoleStmt:                q.countUsersByRoleStmt,
+		createAPIKeyStmt:                    q.createAPIKeyStmt,
+		createActivityStmt:                  q.createActivityStmt,
+		createAttachmentStmt:                q.createAttachmentStmt,
+		createAuthEventStmt:                 q.createAuthEventStmt,
+		createCommentStmt:                   q.createCommentStmt,
+		createConfirmationTokenStmt:         q.createConfirmationTokenStmt,
+		createDescriptionOpStmt:             q.createDescriptionOpStmt,
+		createDueDigestStmt:                 q.createDueDigestStmt,
+		createIdempotencyKeyStmt:            q.createIdempotencyKeyStmt,
+		createImportJobStmt:                 q.createImportJobStmt,
+		createInvitationStmt:                q.createInvitationStmt,
+		createOutboxEventStmt:               q.createOutboxEventStmt,
+		createProjectStmt:                   q.createProjectStmt,
+		createReminderStmt:                  q.createReminderStmt,
+		createSavedFilterStmt:               q.createSavedFilterStmt,
+		createStaleDigestStmt:               q.createStaleDigestStmt,
+		createSubtaskStmt:                   q.createSubtaskStmt,
+		createTagStmt:                       q.createTagStmt,
+		createTodoStmt:                      q.createTodoStmt,
+		createUserStmt:                      q.createUserStmt,
+		createUserIdentityStmt:              q.createUserIdentityStmt,
+		createWebhookStmt:                   q.createWebhookStmt,
+		createWebhookDeliveryStmt:           q.createWebhookDeliveryStmt,
+		deleteCommentStmt:                   q.deleteCommentStmt,
+		deleteCompletedTodosStmt:            q.deleteCompletedTodosStmt,
+		deleteExpiredConfirmationTokensStmt: q.deleteExpiredConfirmationTokensStmt,
+		deleteExpiredIdempotencyKeysStmt:    q.deleteExpiredIdempotencyKeysStmt,
+		deleteIdempotencyKeyStmt:            q.deleteIdempotencyKeyStmt,
+		deleteInvitationStmt:                q.deleteInvitationStmt,
+		deleteProjectStmt:                   q.deleteProjectStmt,
+		deleteProjectChatChannelStmt:        q.deleteProjectChatChannelStmt,
+		deleteProjectShareStmt:              q.deleteProjectShareStmt,
+		deleteReminderStmt:                  q.deleteReminderStmt,
+		deleteResourceLockStmt:              q.deleteResourceLockStmt,
+		deleteSavedFilterStmt:               q.deleteSavedFilterStmt,
+		deleteSubtaskStmt:                   q.deleteSubtaskStmt,
+		deleteTagStmt:                       q.deleteTagStmt,
+		deleteTodoStmt:                      q.deleteTodoStmt,
+		deleteTodoDefaultsStmt:              q.deleteTodoDefaultsStmt,
+		deleteTodoShareStmt:                 q.deleteTodoShareStmt,
+		deleteTodoTagsStmt:                  q.deleteTodoTagsStmt,
+		deleteTodosByIDsStmt:                q.deleteTodosByIDsStmt,
+		deleteUserStmt:                      q.deleteUserStmt,
+		deleteWebhookStmt:                   q.deleteWebhookStmt,
+		endRecurrenceStmt:                   q.endRecurrenceStmt,
+		escalateTodoStmt:                    q.escalateTodoStmt,
+		failInterruptedImportJobsStmt:       q.failInterruptedImportJobsStmt,
+		finishImportJobStmt:                 q.finishImportJobStmt,
+		finishWebhookDeliveryStmt:           q.finishWebhookDeliveryStmt,
+		getAPIKeyStmt:                       q.getAPIKeyStmt,
+		getActiveAPIKeyByHashStmt:           q.getActiveAPIKeyByHashStmt,
+		getAttachmentStmt:                   q.getAttachmentStmt,
+		getAuthLocationStatsStmt:            q.getAuthLocationStatsStmt,
+		getBadgeCountsStmt:                  q.getBadgeCountsStmt,
+		getChatChannelForTodoStmt:           q.getChatChannelForTodoStmt,
+		getCommentStmt:                      q.getCommentStmt,
+		getIdempotencyKeyStmt:               q.getIdempotencyKeyStmt,
+		getImportJobStmt:                    q.getImportJobStmt,
+		getInboxSummaryStmt:                 q.getInboxSummaryStmt,
+		getInvitationByTokenHashStmt:        q.getInvitationByTokenHashStmt,
+		getLatestActivityStmt:               q.getLatestActivityStmt,
+		getLatestDescriptionOpStmt:          q.getLatestDescriptionOpStmt,
+		getLatestOutboxEventIDStmt:          q.getLatestOutboxEventIDStmt,
+		getLatestStaleDigestStmt:            q.getLatestStaleDigestStmt,
+		getNextActivityStmt:                 q.getNextActivityStmt,
+		getOldestDescriptionRevisionStmt:    q.getOldestDescriptionRevisionStmt,
+		getPendingInvitationByEmailStmt:     q.getPendingInvitationByEmailStmt,
+		getProjectStmt:                      q.getProjectStmt,
+		getProjectChatChannelStmt:           q.getProjectChatChannelStmt,
+		getResourceLockStmt:                 q.getResourceLockStmt,
+		getSavedFilterStmt:                  q.getSavedFilterStmt,
+		getSubtaskStmt:                      q.getSubtaskStmt,
+		getTagStmt:                          q.getTagStmt,
+		getTagByNameStmt:                    q.getTagByNameStmt,
+		getTodoStmt:                         q.getTodoStmt,
+		getTodoByIDStmt:                     q.getTodoByIDStmt,
+		getTodoDefaultsStmt:                 q.getTodoDefaultsStmt,
+		getUserStmt:                         q.getUserStmt,
+		getUserByEmailStmt:                  q.getUserByEmailStmt,
+		getUserByIdentityStmt:               q.getUserByIdentityStmt,
+		getWebhookStmt:                      q.getWebhookStmt,
+		hasRecentAuthEventStmt:              q.hasRecentAuthEventStmt,
+		listAPIKeysStmt:                     q.listAPIKeysStmt,
+		listActiveWebhooksForOwnerStmt:      q.listActiveWebhooksForOwnerStmt,
+		listActivityStmt:                    q.listActivityStmt,
+		listAgendaRemindersStmt:             q.listAgendaRemindersStmt,
+		listAgendaTodosStmt:                 q.listAgendaTodosStmt,
+		listAttachmentsStmt:                 q.listAttachmentsStmt,
+		listAttachmentsByTodoIDsStmt:        q.listAttachmentsByTodoIDsStmt,
+		listAuthEventsStmt:                  q.listAuthEventsStmt,
+		listChatOverdueDeliveriesStmt:       q.listChatOverdueDeliveriesStmt,
+		listCommentsStmt:                    q.listCommentsStmt,
+		listCommentsByProjectStmt:           q.listCommentsByProjectStmt,
+		listCommentsByTodoIDsStmt:           q.listCommentsByTodoIDsStmt,
+		listCompletedTodoIDsStmt:            q.listCompletedTodoIDsStmt,
+		listDescriptionOpsStmt:              q.listDescriptionOpsStmt,
+		listDueDigestTodosStmt:              q.listDueDigestTodosStmt,
+		listDueRemindersStmt:                q.listDueRemindersStmt,
+		listDueWebhookDeliveriesStmt:        q.listDueWebhookDeliveriesStmt,
+		listImportJobsStmt:                  q.listImportJobsStmt,
+		listInvitationsStmt:                 q.listInvitationsStmt,
+		listOperationUsageStmt:              q.listOperationUsageStmt,
+		listOverdueTodosStmt:                q.listOverdueTodosStmt,
+		listOwnedTodoIDsStmt:                q.listOwnedTodoIDsStmt,
+		listPendingOutboxEventsStmt:         q.listPendingOutboxEventsStmt,
+		listPendingRecurrencesStmt:          q.listPendingRecurrencesStmt,
+		listPendingRecurringTodosStmt:       q.listPendingRecurringTodosStmt,
+		listProjectChatChannelsStmt:         q.listProjectChatChannelsStmt,
+		listProjectSharesStmt:               q.listProjectSharesStmt,
+		listProjectsStmt:                    q.listProjectsStmt,
+		listProjectsByIDsStmt:               q.listProjectsByIDsStmt,
+		listRemindersStmt:                   q.listRemindersStmt,
+		listRemindersByTodoIDsStmt:          q.listRemindersByTodoIDsStmt,
+		listSavedFiltersStmt:                q.listSavedFiltersStmt,
+		listStaleTodosStmt:                  q.listStaleTodosStmt,
+		listSubtasksStmt:                    q.listSubtasksStmt,
+		listSubtasksByProjectStmt:           q.listSubtasksByProjectStmt,
+		listSubtasksByTodoIDsStmt:           q.listSubtasksByTodoIDsStmt,
+		listTagTodoIDsStmt:                  q.listTagTodoIDsStmt,
+		listTagsStmt:                        q.listTagsStmt,
+		listTodoActivityStmt:                q.listTodoActivityStmt,
+		listTodoChatDeliveriesStmt:          q.listTodoChatDeliveriesStmt,
+		listTodoDefaultsForProjectStmt:      q.listTodoDefaultsForProjectStmt,
+		listTodoIDsInProjectStmt:            q.listTodoIDsInProjectStmt,
+		listTodoLocksStmt:                   q.listTodoLocksStmt,
+		listTodoSharesStmt:                  q.listTodoSharesStmt,
+		listTodoTagNamesStmt:                q.listTodoTagNamesStmt,
+		listTodoWatchersToNotifyStmt:        q.listTodoWatchersToNotifyStmt,
+		listTodosStmt:                       q.listTodosStmt,
+		listTodosByOwnerStmt:                q.listTodosByOwnerStmt,
+		listTodosByProjectStmt:              q.listTodosByProjectStmt,
+		listTodosByStatusStmt:               q.listTodosByStatusStmt,
+		listTodosForExportStmt:              q.listTodosForExportStmt,
+		listTodosNearStmt:                   q.listTodosNearStmt,
+		listUsageStmt:                       q.listUsageStmt,
+		listUsersStmt:                       q.listUsersStmt,
+		listVisibleTodosByProjectStmt:       q.listVisibleTodosByProjectStmt,
+		listWatchersByTodoIDsStmt:           q.listWatchersByTodoIDsStmt,
+		listWebhookDeliveriesStmt:           q.listWebhookDeliveriesStmt,
+		listWebhooksStmt:                    q.listWebhooksStmt,
+		markInboxReadStmt:                   q.markInboxReadStmt,
+		markOutboxEventPublishedStmt:        q.markOutboxEventPublishedStmt,
+		markReminderSentStmt:                q.markReminderSentStmt,
+		moveTodoStmt:                        q.moveTodoStmt,
+		pruneDescriptionOpsStmt:             q.pruneDescriptionOpsStmt,
+		pruneDueDigestsStmt:                 q.pruneDueDigestsStmt,
+		pruneOutboxStmt:                     q.pruneOutboxStmt,
+		pruneResourceLocksStmt:              q.pruneResourceLocksStmt,
+		pruneWebhookDeliveriesStmt:          q.pruneWebhookDeliveriesStmt,
+		reassignTodoTagsStmt:                q.reassignTodoTagsStmt,
+		recordChatOverduePostStmt:           q.recordChatOverduePostStmt,
+		recordOutboxFailureStmt:             q.recordOutboxFailureStmt,
+		renameTagStmt:                       q.renameTagStmt,
+		revokeAPIKeyStmt:                    q.revokeAPIKeyStmt,
+		revokeAPIKeysByCreatorStmt:          q.revokeAPIKeysByCreatorStmt,
+		setNextTodoIDStmt:                   q.setNextTodoIDStmt,
+		setTodoPositionStmt:                 q.setTodoPositionStmt,
+		shareProjectStmt:                    q.shareProjectStmt,
+		shareTodoStmt:                       q.shareTodoStmt,
+		snapshotUsageStmt:                   q.snapshotUsageStmt,
+		snoozeTodoStmt:                      q.snoozeTodoStmt,
+		toggleTodoCompletedStmt:             q.toggleTodoCompletedStmt,
+		touchAPIKeyStmt:                     q.touchAPIKeyStmt,
+		touchHealthProbeStmt:                q.touchHealthProbeStmt,
+		unarchiveProjectStmt:                q.unarchiveProjectStmt,
+		unarchiveTodoStmt:                   q.unarchiveTodoStmt,
+		unwatchTodoStmt:                     q.unwatchTodoStmt,
+		unwatchTodosStmt:                    q.unwatchTodosStmt,
+		updateProjectStmt:                   q.updateProjectStmt,
+		updateSubtaskStmt:                   q.updateSubtaskStmt,
+		updateTodoStmt:                      q.updateTodoStmt,
+		updateTodoDescriptionStmt:           q.updateTodoDescriptionStmt,
+		updateUserRoleStmt:                  q.updateUserRoleStmt,
+		updateWebhookStmt:                   q.updateWebhookStmt,
+		upsertProjectChatChannelStmt:        q.upsertProjectChatChannelStmt,
+		upsertTodoDefaultsStmt:              q.upsertTodoDefaultsStmt,
+		useActionLinkStmt:                   q.useActionLinkStmt,
+		watchTodoStmt:                       q.watchTodoStmt,
 	}
 }
@@ -0,0 +1,31 @@
+package db
+
+// HotQuery は頻繁に実行され、実行計画を確認する対象とするクエリを表す構造体
+type HotQuery struct {
+	Name string
+	SQL  string
+}
+
+// HotQueries は一覧の取得や認証、定期ジョブなどでリクエストごとに実行されるクエリ。
+// sqlc.sliceを含むクエリはプレースホルダーが展開されるまで実行計画を得られないため含めない。
+var HotQueries = []HotQuery{
+	{"ListTodos", listTodos},
+	{"ListTodosByStatus", listTodosByStatus},
+	{"ListTodosNear", listTodosNear},
+	{"ListTodosByProject", listTodosByProject},
+	{"ListVisibleTodosByProject", listVisibleTodosByProject},
+	{"GetTodo", getTodo},
+	{"ListSubtasks", listSubtasks},
+	{"ListComments", listComments},
+	{"ListReminders", listReminders},
+	{"ListTodoActivity", listTodoActivity},
+	{"ListTodoShares", listTodoShares},
+	{"ListDueReminders", listDueReminders},
+	{"ListOverdueTodos", listOverdueTodos},
+	{"ListPendingRecurrences", listPendingRecurrences},
+	{"GetActiveAPIKeyByHash", getActiveAPIKeyByHash},
+	{"HasRecentAuthEvent", hasRecentAuthEvent},
+	{"GetIdempotencyKey", getIdempotencyKey},
+	{"GetInboxSummary", getInboxSummary},
+	{"GetBadgeCounts", getBadgeCounts},
+}
@@ -9,11 +9,346 @@ import (
 	"time"
 )
 
-type Todo struct {
+type ActionLinkUse struct {
+	TokenID   string        `json:"token_id"`
+	Action    string        `json:"action"`
+	TodoID    int64         `json:"todo_id"`
+	UserID    sql.NullInt64 `json:"user_id"`
+	Ip        string        `json:"ip"`
+	UserAgent string        `json:"user_agent"`
+	UsedAt    time.Time     `json:"used_at"`
+}
+
+type ActivityLog struct {
+	ID        int64     `json:"id"`
+	TodoID    int64     `json:"todo_id"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Diff      string    `json:"diff"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ApiKey struct {
+	ID         int64        `json:"id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    string       `json:"key_hash"`
+	CreatedBy  string       `json:"created_by"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type Attachment struct {
+	ID          int64     `json:"id"`
+	TodoID      int64     `json:"todo_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"storage_key"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type AuthEvent struct {
+	ID        int64     `json:"id"`
+	Subject   string    `json:"subject"`
+	EventType string    `json:"event_type"`
+	Ip        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ChatOverduePost struct {
+	TodoID   int64     `json:"todo_id"`
+	DueAt    time.Time `json:"due_at"`
+	PostedAt time.Time `json:"posted_at"`
+}
+
+type Comment struct {
+	ID        int64     `json:"id"`
+	TodoID    int64     `json:"todo_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ConfirmationToken struct {
+	TokenHash string    `json:"token_hash"`
+	Operation string    `json:"operation"`
+	Target    string    `json:"target"`
+	Actor     string    `json:"actor"`
+	Impact    string    `json:"impact"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DueDigest struct {
+	OwnerID    int64     `json:"owner_id"`
+	DigestDate string    `json:"digest_date"`
+	TodoCount  int64     `json:"todo_count"`
+	SentAt     time.Time `json:"sent_at"`
+}
+
+type HealthProbe struct {
+	ID        int64     `json:"id"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+type IdempotencyKey struct {
+	Subject        string    `json:"subject"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	RequestHash    string    `json:"request_hash"`
+	Status         int64     `json:"status"`
+	Headers        string    `json:"headers"`
+	Body           []byte    `json:"body"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+type ImportJob struct {
+	ID         int64          `json:"id"`
+	Source     string         `json:"source"`
+	Status     string         `json:"status"`
+	DryRun     int64          `json:"dry_run"`
+	Actor      string         `json:"actor"`
+	Result     sql.NullString `json:"result"`
+	Error      sql.NullString `json:"error"`
+	CreatedAt  time.Time      `json:"created_at"`
+	FinishedAt sql.NullTime   `json:"finished_at"`
+}
+
+type Invitation struct {
+	ID             int64         `json:"id"`
+	Email          string        `json:"email"`
+	Role           string        `json:"role"`
+	TokenHash      string        `json:"token_hash"`
+	InvitedBy      string        `json:"invited_by"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	AcceptedAt     sql.NullTime  `json:"accepted_at"`
+	AcceptedUserID sql.NullInt64 `json:"accepted_user_id"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type OperationUsage struct {
+	OperationID string    `json:"operation_id"`
+	Calls       int64     `json:"calls"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Outbox struct {
 	ID          int64          `json:"id"`
-	Title       string         `json:"title"`
-	Description sql.NullString `json:"description"`
+	Type        string         `json:"type"`
+	TodoID      int64          `json:"todo_id"`
+	OwnerID     sql.NullInt64  `json:"owner_id"`
 	Completed   int64          `json:"completed"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
+	OccurredAt  time.Time      `json:"occurred_at"`
+	Attempts    int64          `json:"attempts"`
+	LastError   sql.NullString `json:"last_error"`
+	PublishedAt sql.NullTime   `json:"published_at"`
+	CommentID   sql.NullInt64  `json:"comment_id"`
+}
+
+type Project struct {
+	ID             int64          `json:"id"`
+	Name           string         `json:"name"`
+	Description    sql.NullString `json:"description"`
+	ArchivedAt     sql.NullTime   `json:"archived_at"`
+	OpenCount      int64          `json:"open_count"`
+	CompletedCount int64          `json:"completed_count"`
+	MetadataSchema sql.NullString `json:"metadata_schema"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	WipLimit       sql.NullInt64  `json:"wip_limit"`
+}
+
+type ProjectChatChannel struct {
+	ProjectID  int64     `json:"project_id"`
+	Provider   string    `json:"provider"`
+	WebhookUrl string    `json:"webhook_url"`
+	Events     string    `json:"events"`
+	Templates  string    `json:"templates"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ReadMarker struct {
+	Subject        string    `json:"subject"`
+	LastActivityID int64     `json:"last_activity_id"`
+	LastCommentID  int64     `json:"last_comment_id"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type Reminder struct {
+	ID        int64        `json:"id"`
+	TodoID    int64        `json:"todo_id"`
+	RemindAt  time.Time    `json:"remind_at"`
+	Channel   string       `json:"channel"`
+	SentAt    sql.NullTime `json:"sent_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type ResourceLock struct {
+	ResourceType string    `json:"resource_type"`
+	ResourceID   int64     `json:"resource_id"`
+	HolderID     int64     `json:"holder_id"`
+	AcquiredAt   time.Time `json:"acquired_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type SavedFilter struct {
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Completed int64          `json:"completed"`
+	Near      sql.NullString `json:"near"`
+	Radius    float64        `json:"radius"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type StaleDigest struct {
+	ID        int64         `json:"id"`
+	OwnerID   sql.NullInt64 `json:"owner_id"`
+	TodoCount int64         `json:"todo_count"`
+	SentAt    time.Time     `json:"sent_at"`
+}
+
+type Subtask struct {
+	ID        int64     `json:"id"`
+	TodoID    int64     `json:"todo_id"`
+	Title     string    `json:"title"`
+	Completed int64     `json:"completed"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Tag struct {
+	ID        int64         `json:"id"`
+	OwnerID   sql.NullInt64 `json:"owner_id"`
+	Name      string        `json:"name"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type Todo struct {
+	ID                    int64           `json:"id"`
+	Title                 string          `json:"title"`
+	Description           sql.NullString  `json:"description"`
+	Completed             int64           `json:"completed"`
+	Latitude              sql.NullFloat64 `json:"latitude"`
+	Longitude             sql.NullFloat64 `json:"longitude"`
+	PlaceName             sql.NullString  `json:"place_name"`
+	SubtaskCount          int64           `json:"subtask_count"`
+	SubtaskCompletedCount int64           `json:"subtask_completed_count"`
+	ProjectID             sql.NullInt64   `json:"project_id"`
+	DueAt                 sql.NullTime    `json:"due_at"`
+	Recurrence            sql.NullString  `json:"recurrence"`
+	NextTodoID            sql.NullInt64   `json:"next_todo_id"`
+	Position              int64           `json:"position"`
+	Version               int64           `json:"version"`
+	Assignee              sql.NullString  `json:"assignee"`
+	OwnerID               sql.NullInt64   `json:"owner_id"`
+	Metadata              sql.NullString  `json:"metadata"`
+	Priority              int64           `json:"priority"`
+	EscalationLevel       int64           `json:"escalation_level"`
+	CreatedAt             time.Time       `json:"created_at"`
+	UpdatedAt             time.Time       `json:"updated_at"`
+	Translations          sql.NullString  `json:"translations"`
+	ArchivedAt            sql.NullTime    `json:"archived_at"`
+}
+
+type TodoDefault struct {
+	ID               int64          `json:"id"`
+	ProjectID        sql.NullInt64  `json:"project_id"`
+	Priority         sql.NullInt64  `json:"priority"`
+	DueOffsetSeconds sql.NullInt64  `json:"due_offset_seconds"`
+	Metadata         sql.NullString `json:"metadata"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	Tags             sql.NullString `json:"tags"`
+}
+
+type TodoDescriptionOp struct {
+	TodoID      int64     `json:"todo_id"`
+	Revision    int64     `json:"revision"`
+	Ops         string    `json:"ops"`
+	Description string    `json:"description"`
+	Actor       string    `json:"actor"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type TodoShare struct {
+	ID         int64         `json:"id"`
+	TodoID     sql.NullInt64 `json:"todo_id"`
+	ProjectID  sql.NullInt64 `json:"project_id"`
+	UserID     int64         `json:"user_id"`
+	SharedBy   int64         `json:"shared_by"`
+	Permission string        `json:"permission"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type TodoSnooze struct {
+	TodoID       int64     `json:"todo_id"`
+	SnoozedUntil time.Time `json:"snoozed_until"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type TodoTag struct {
+	TodoID int64 `json:"todo_id"`
+	TagID  int64 `json:"tag_id"`
+}
+
+type TodoWatcher struct {
+	TodoID    int64     `json:"todo_id"`
+	UserID    int64     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UsageDaily struct {
+	Day          string    `json:"day"`
+	UserID       int64     `json:"user_id"`
+	ApiCalls     int64     `json:"api_calls"`
+	StorageBytes int64     `json:"storage_bytes"`
+	ActiveTodos  int64     `json:"active_todos"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type User struct {
+	ID           int64     `json:"id"`
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	PasswordHash string    `json:"password_hash"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type UserIdentity struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Webhook struct {
+	ID        int64         `json:"id"`
+	OwnerID   sql.NullInt64 `json:"owner_id"`
+	Url       string        `json:"url"`
+	Events    string        `json:"events"`
+	Secret    string        `json:"secret"`
+	Active    int64         `json:"active"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64          `json:"id"`
+	WebhookID      int64          `json:"webhook_id"`
+	Event          string         `json:"event"`
+	TodoID         int64          `json:"todo_id"`
+	Payload        string         `json:"payload"`
+	Status         string         `json:"status"`
+	Attempts       int64          `json:"attempts"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at"`
+	ResponseStatus sql.NullInt64  `json:"response_status"`
+	LastError      sql.NullString `json:"last_error"`
+	CreatedAt      time.Time      `json:"created_at"`
+	DeliveredAt    sql.NullTime   `json:"delivered_at"`
 }
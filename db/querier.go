@@ -6,16 +6,217 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
+	AcceptInvitation(ctx context.Context, arg AcceptInvitationParams) (int64, error)
+	// 期限内のロックを他のユーザーが持っている場合は更新せず、行を返さない。同じユーザーが取得し直した場合は期限だけを延ばす
+	AcquireResourceLock(ctx context.Context, arg AcquireResourceLockParams) (ResourceLock, error)
+	AddOperationUsage(ctx context.Context, arg AddOperationUsageParams) error
+	AddTodoTag(ctx context.Context, arg AddTodoTagParams) error
+	// 削除されたユーザーの呼び出し回数は記録しない
+	AddUsageAPICalls(ctx context.Context, arg AddUsageAPICallsParams) error
+	ArchiveProject(ctx context.Context, id int64) (int64, error)
+	ArchiveTodo(ctx context.Context, id int64) (Todo, error)
+	CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error
+	ConsumeConfirmationToken(ctx context.Context, arg ConsumeConfirmationTokenParams) (ConfirmationToken, error)
+	CountActiveAPIKeysByCreator(ctx context.Context, createdBy string) (int64, error)
+	CountCommentsByTodoIDs(ctx context.Context, todoIds []int64) ([]CountCommentsByTodoIDsRow, error)
+	CountUsers(ctx context.Context) (int64, error)
+	CountUsersByRole(ctx context.Context, role string) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateActivity(ctx context.Context, arg CreateActivityParams) error
+	CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error)
+	CreateAuthEvent(ctx context.Context, arg CreateAuthEventParams) (AuthEvent, error)
+	CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error)
+	CreateConfirmationToken(ctx context.Context, arg CreateConfirmationTokenParams) error
+	CreateDescriptionOp(ctx context.Context, arg CreateDescriptionOpParams) error
+	CreateDueDigest(ctx context.Context, arg CreateDueDigestParams) error
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (int64, error)
+	CreateImportJob(ctx context.Context, arg CreateImportJobParams) (ImportJob, error)
+	CreateInvitation(ctx context.Context, arg CreateInvitationParams) (Invitation, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error
+	CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error)
+	CreateReminder(ctx context.Context, arg CreateReminderParams) (Reminder, error)
+	CreateSavedFilter(ctx context.Context, arg CreateSavedFilterParams) (SavedFilter, error)
+	CreateStaleDigest(ctx context.Context, arg CreateStaleDigestParams) error
+	CreateSubtask(ctx context.Context, arg CreateSubtaskParams) (Subtask, error)
+	CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error)
 	CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) error
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	DeleteComment(ctx context.Context, arg DeleteCommentParams) (int64, error)
+	DeleteCompletedTodos(ctx context.Context, ownerID sql.NullInt64) ([]Todo, error)
+	DeleteExpiredConfirmationTokens(ctx context.Context, expiresAt time.Time) (int64, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) (int64, error)
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteInvitation(ctx context.Context, id int64) (int64, error)
+	DeleteProject(ctx context.Context, id int64) (int64, error)
+	DeleteProjectChatChannel(ctx context.Context, arg DeleteProjectChatChannelParams) (int64, error)
+	DeleteProjectShare(ctx context.Context, arg DeleteProjectShareParams) (int64, error)
+	DeleteReminder(ctx context.Context, arg DeleteReminderParams) (int64, error)
+	DeleteResourceLock(ctx context.Context, arg DeleteResourceLockParams) error
+	DeleteSavedFilter(ctx context.Context, id int64) (int64, error)
+	DeleteSubtask(ctx context.Context, arg DeleteSubtaskParams) (int64, error)
+	DeleteTag(ctx context.Context, id int64) (int64, error)
 	DeleteTodo(ctx context.Context, id int64) error
-	GetTodo(ctx context.Context, id int64) (Todo, error)
-	ListTodos(ctx context.Context) ([]Todo, error)
-	ListTodosByStatus(ctx context.Context, completed int64) ([]Todo, error)
+	DeleteTodoDefaults(ctx context.Context, projectID sql.NullInt64) (int64, error)
+	DeleteTodoShare(ctx context.Context, arg DeleteTodoShareParams) (int64, error)
+	DeleteTodoTags(ctx context.Context, todoID int64) error
+	DeleteTodosByIDs(ctx context.Context, arg DeleteTodosByIDsParams) ([]Todo, error)
+	DeleteUser(ctx context.Context, id int64) (int64, error)
+	DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) (int64, error)
+	EndRecurrence(ctx context.Context, id int64) error
+	EscalateTodo(ctx context.Context, arg EscalateTodoParams) (Todo, error)
+	FailInterruptedImportJobs(ctx context.Context, error sql.NullString) (int64, error)
+	FinishImportJob(ctx context.Context, arg FinishImportJobParams) (ImportJob, error)
+	FinishWebhookDelivery(ctx context.Context, arg FinishWebhookDeliveryParams) error
+	GetAPIKey(ctx context.Context, id int64) (ApiKey, error)
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
+	GetAttachment(ctx context.Context, id int64) (Attachment, error)
+	GetAuthLocationStats(ctx context.Context, arg GetAuthLocationStatsParams) (GetAuthLocationStatsRow, error)
+	GetBadgeCounts(ctx context.Context, arg GetBadgeCountsParams) (GetBadgeCountsRow, error)
+	// Todoが属するプロジェクトに設定されたチャットの送信先を取得する
+	GetChatChannelForTodo(ctx context.Context, arg GetChatChannelForTodoParams) (ProjectChatChannel, error)
+	GetComment(ctx context.Context, arg GetCommentParams) (Comment, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetImportJob(ctx context.Context, id int64) (ImportJob, error)
+	GetInboxSummary(ctx context.Context, arg GetInboxSummaryParams) (GetInboxSummaryRow, error)
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (Invitation, error)
+	GetLatestActivity(ctx context.Context) (ActivityLog, error)
+	GetLatestDescriptionOp(ctx context.Context, todoID int64) (TodoDescriptionOp, error)
+	GetLatestOutboxEventID(ctx context.Context) (int64, error)
+	GetLatestStaleDigest(ctx context.Context, ownerID sql.NullInt64) (StaleDigest, error)
+	GetNextActivity(ctx context.Context, afterID int64) (ActivityLog, error)
+	GetOldestDescriptionRevision(ctx context.Context, todoID int64) (int64, error)
+	// OIDCで確認済みのメールアドレスを、トークンなしで招待と照合する
+	GetPendingInvitationByEmail(ctx context.Context, arg GetPendingInvitationByEmailParams) (Invitation, error)
+	GetProject(ctx context.Context, id int64) (Project, error)
+	GetProjectChatChannel(ctx context.Context, arg GetProjectChatChannelParams) (ProjectChatChannel, error)
+	GetResourceLock(ctx context.Context, arg GetResourceLockParams) (GetResourceLockRow, error)
+	GetSavedFilter(ctx context.Context, id int64) (SavedFilter, error)
+	GetSubtask(ctx context.Context, arg GetSubtaskParams) (Subtask, error)
+	GetTag(ctx context.Context, arg GetTagParams) (Tag, error)
+	GetTagByName(ctx context.Context, arg GetTagByNameParams) (Tag, error)
+	GetTodo(ctx context.Context, arg GetTodoParams) (Todo, error)
+	GetTodoByID(ctx context.Context, id int64) (Todo, error)
+	GetTodoDefaults(ctx context.Context, projectID sql.NullInt64) (TodoDefault, error)
+	GetUser(ctx context.Context, id int64) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByIdentity(ctx context.Context, arg GetUserByIdentityParams) (User, error)
+	GetWebhook(ctx context.Context, arg GetWebhookParams) (Webhook, error)
+	HasRecentAuthEvent(ctx context.Context, arg HasRecentAuthEventParams) (int64, error)
+	ListAPIKeys(ctx context.Context, includeRevoked int64) ([]ApiKey, error)
+	ListActiveWebhooksForOwner(ctx context.Context, ownerID sql.NullInt64) ([]Webhook, error)
+	ListActivity(ctx context.Context, arg ListActivityParams) ([]ActivityLog, error)
+	ListAgendaReminders(ctx context.Context, arg ListAgendaRemindersParams) ([]ListAgendaRemindersRow, error)
+	ListAgendaTodos(ctx context.Context, arg ListAgendaTodosParams) ([]Todo, error)
+	ListAttachments(ctx context.Context, todoID int64) ([]Attachment, error)
+	ListAttachmentsByTodoIDs(ctx context.Context, todoIds []int64) ([]Attachment, error)
+	ListAuthEvents(ctx context.Context, arg ListAuthEventsParams) ([]AuthEvent, error)
+	// sinceからnowまでに期限を過ぎた未完了のTodoのうち、その期限の期限切れをまだ投稿していないものと、
+	// 期限切れを購読しているチャットの送信先を取得する
+	ListChatOverdueDeliveries(ctx context.Context, arg ListChatOverdueDeliveriesParams) ([]ListChatOverdueDeliveriesRow, error)
+	ListComments(ctx context.Context, arg ListCommentsParams) ([]Comment, error)
+	ListCommentsByProject(ctx context.Context, projectID sql.NullInt64) ([]Comment, error)
+	ListCommentsByTodoIDs(ctx context.Context, todoIds []int64) ([]Comment, error)
+	// DeleteCompletedTodosで削除されるTodoのID
+	ListCompletedTodoIDs(ctx context.Context, ownerID sql.NullInt64) ([]int64, error)
+	ListDescriptionOps(ctx context.Context, arg ListDescriptionOpsParams) ([]TodoDescriptionOp, error)
+	// 期限が今日までの未完了のTodoを、その日のまとめをまだ送っていない所有者ごとに期限の順で返す
+	ListDueDigestTodos(ctx context.Context, arg ListDueDigestTodosParams) ([]Todo, error)
+	ListDueReminders(ctx context.Context, now time.Time) ([]ListDueRemindersRow, error)
+	ListDueWebhookDeliveries(ctx context.Context, arg ListDueWebhookDeliveriesParams) ([]ListDueWebhookDeliveriesRow, error)
+	ListImportJobs(ctx context.Context, limit int64) ([]ImportJob, error)
+	ListInvitations(ctx context.Context, limit int64) ([]Invitation, error)
+	ListOperationUsage(ctx context.Context) ([]OperationUsage, error)
+	ListOverdueTodos(ctx context.Context, arg ListOverdueTodosParams) ([]Todo, error)
+	// DeleteTodosByIDsで削除されるTodoのID
+	ListOwnedTodoIDs(ctx context.Context, arg ListOwnedTodoIDsParams) ([]int64, error)
+	ListPendingOutboxEvents(ctx context.Context, limit int64) ([]Outbox, error)
+	ListPendingRecurrences(ctx context.Context) ([]Todo, error)
+	ListPendingRecurringTodos(ctx context.Context, arg ListPendingRecurringTodosParams) ([]Todo, error)
+	ListProjectChatChannels(ctx context.Context, projectID int64) ([]ProjectChatChannel, error)
+	ListProjectShares(ctx context.Context, arg ListProjectSharesParams) ([]ListProjectSharesRow, error)
+	ListProjects(ctx context.Context, includeArchived int64) ([]Project, error)
+	ListProjectsByIDs(ctx context.Context, ids []int64) ([]Project, error)
+	ListReminders(ctx context.Context, todoID int64) ([]Reminder, error)
+	ListRemindersByTodoIDs(ctx context.Context, todoIds []int64) ([]Reminder, error)
+	ListSavedFilters(ctx context.Context) ([]SavedFilter, error)
+	ListStaleTodos(ctx context.Context, arg ListStaleTodosParams) ([]Todo, error)
+	ListSubtasks(ctx context.Context, todoID int64) ([]Subtask, error)
+	ListSubtasksByProject(ctx context.Context, projectID sql.NullInt64) ([]Subtask, error)
+	ListSubtasksByTodoIDs(ctx context.Context, todoIds []int64) ([]Subtask, error)
+	ListTagTodoIDs(ctx context.Context, tagID int64) ([]int64, error)
+	// 所有者のタグを、付けているTodoの件数とともに名前の順で返す
+	ListTags(ctx context.Context, ownerID sql.NullInt64) ([]ListTagsRow, error)
+	ListTodoActivity(ctx context.Context, arg ListTodoActivityParams) ([]ActivityLog, error)
+	// Todoが属するプロジェクトに設定されたチャットの送信先とTodoの内容を取得する
+	ListTodoChatDeliveries(ctx context.Context, id int64) ([]ListTodoChatDeliveriesRow, error)
+	ListTodoDefaultsForProject(ctx context.Context, projectID sql.NullInt64) ([]TodoDefault, error)
+	ListTodoIDsInProject(ctx context.Context, projectID sql.NullInt64) ([]int64, error)
+	ListTodoLocks(ctx context.Context, arg ListTodoLocksParams) ([]ListTodoLocksRow, error)
+	ListTodoShares(ctx context.Context, todoID sql.NullInt64) ([]ListTodoSharesRow, error)
+	ListTodoTagNames(ctx context.Context, ids []int64) ([]ListTodoTagNamesRow, error)
+	// 共有を取り消されるなどして、Todoを閲覧できなくなったユーザーには通知しない
+	ListTodoWatchersToNotify(ctx context.Context, todoID int64) ([]ListTodoWatchersToNotifyRow, error)
+	ListTodos(ctx context.Context, arg ListTodosParams) ([]Todo, error)
+	ListTodosByOwner(ctx context.Context, ownerID sql.NullInt64) ([]Todo, error)
+	ListTodosByProject(ctx context.Context, projectID sql.NullInt64) ([]Todo, error)
+	ListTodosByStatus(ctx context.Context, arg ListTodosByStatusParams) ([]Todo, error)
+	// エクスポートするTodoをIDの順にlimit件ずつ取得する。after_idに前回の最後のIDを渡して続きを取得する
+	ListTodosForExport(ctx context.Context, arg ListTodosForExportParams) ([]Todo, error)
+	ListTodosNear(ctx context.Context, arg ListTodosNearParams) ([]Todo, error)
+	ListUsage(ctx context.Context, arg ListUsageParams) ([]ListUsageRow, error)
+	ListUsers(ctx context.Context) ([]User, error)
+	ListVisibleTodosByProject(ctx context.Context, arg ListVisibleTodosByProjectParams) ([]Todo, error)
+	ListWatchersByTodoIDs(ctx context.Context, todoIds []int64) ([]ListWatchersByTodoIDsRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhooks(ctx context.Context, ownerID sql.NullInt64) ([]Webhook, error)
+	MarkInboxRead(ctx context.Context, subject string) error
+	MarkOutboxEventPublished(ctx context.Context, arg MarkOutboxEventPublishedParams) error
+	MarkReminderSent(ctx context.Context, id int64) (int64, error)
+	MoveTodo(ctx context.Context, arg MoveTodoParams) (Todo, error)
+	PruneDescriptionOps(ctx context.Context, arg PruneDescriptionOpsParams) error
+	PruneDueDigests(ctx context.Context, sentAt time.Time) (int64, error)
+	PruneOutbox(ctx context.Context, publishedAt sql.NullTime) (int64, error)
+	PruneResourceLocks(ctx context.Context, expiresAt time.Time) (int64, error)
+	PruneWebhookDeliveries(ctx context.Context, createdAt time.Time) (int64, error)
+	// from_tag_idを付けたTodoにto_tag_idを付ける。既に付けている場合はそのままにする
+	ReassignTodoTags(ctx context.Context, arg ReassignTodoTagsParams) error
+	RecordChatOverduePost(ctx context.Context, arg RecordChatOverduePostParams) error
+	RecordOutboxFailure(ctx context.Context, arg RecordOutboxFailureParams) error
+	RenameTag(ctx context.Context, arg RenameTagParams) (Tag, error)
+	RevokeAPIKey(ctx context.Context, id int64) (int64, error)
+	RevokeAPIKeysByCreator(ctx context.Context, createdBy string) (int64, error)
+	SetNextTodoID(ctx context.Context, arg SetNextTodoIDParams) (int64, error)
+	SetTodoPosition(ctx context.Context, arg SetTodoPositionParams) (int64, error)
+	ShareProject(ctx context.Context, arg ShareProjectParams) (TodoShare, error)
+	ShareTodo(ctx context.Context, arg ShareTodoParams) (TodoShare, error)
+	// すべてのユーザーの添付ファイルの合計サイズと未完了のTodoの件数を、指定した日の利用量として記録する
+	SnapshotUsage(ctx context.Context, day string) (int64, error)
+	SnoozeTodo(ctx context.Context, arg SnoozeTodoParams) error
 	ToggleTodoCompleted(ctx context.Context, id int64) (Todo, error)
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error
+	TouchHealthProbe(ctx context.Context) error
+	UnarchiveProject(ctx context.Context, id int64) (int64, error)
+	UnarchiveTodo(ctx context.Context, id int64) (Todo, error)
+	UnwatchTodo(ctx context.Context, arg UnwatchTodoParams) (int64, error)
+	UnwatchTodos(ctx context.Context, arg UnwatchTodosParams) (int64, error)
+	UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error)
+	UpdateSubtask(ctx context.Context, arg UpdateSubtaskParams) (Subtask, error)
 	UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, error)
+	UpdateTodoDescription(ctx context.Context, arg UpdateTodoDescriptionParams) (Todo, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error)
+	UpsertProjectChatChannel(ctx context.Context, arg UpsertProjectChatChannelParams) (ProjectChatChannel, error)
+	UpsertTodoDefaults(ctx context.Context, arg UpsertTodoDefaultsParams) (TodoDefault, error)
+	UseActionLink(ctx context.Context, arg UseActionLinkParams) (int64, error)
+	WatchTodo(ctx context.Context, arg WatchTodoParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -8,150 +8,5867 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 )
 
+const acceptInvitation = `-- name: AcceptInvitation :execrows
+UPDATE invitations
+SET accepted_at = ?1, accepted_user_id = ?2
+WHERE id = ?3 AND accepted_at IS NULL
+`
+
+type AcceptInvitationParams struct {
+	AcceptedAt sql.NullTime  `json:"accepted_at"`
+	UserID     sql.NullInt64 `json:"user_id"`
+	ID         int64         `json:"id"`
+}
+
+func (q *Queries) AcceptInvitation(ctx context.Context, arg AcceptInvitationParams) (int64, error) {
+	result, err := q.exec(ctx, q.acceptInvitationStmt, acceptInvitation, arg.AcceptedAt, arg.UserID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const acquireResourceLock = `-- name: AcquireResourceLock :one
+INSERT INTO resource_locks (resource_type, resource_id, holder_id, acquired_at, expires_at)
+VALUES (?1, ?2, ?3, ?4, ?5)
+ON CONFLICT (resource_type, resource_id) DO UPDATE SET
+    acquired_at = CASE
+        WHEN resource_locks.holder_id = excluded.holder_id AND resource_locks.expires_at > excluded.acquired_at THEN resource_locks.acquired_at
+        ELSE excluded.acquired_at
+    END,
+    holder_id = excluded.holder_id,
+    expires_at = excluded.expires_at
+WHERE resource_locks.holder_id = excluded.holder_id OR resource_locks.expires_at <= excluded.acquired_at
+RETURNING resource_type, resource_id, holder_id, acquired_at, expires_at
+`
+
+type AcquireResourceLockParams struct {
+	ResourceType string    `json:"resource_type"`
+	ResourceID   int64     `json:"resource_id"`
+	HolderID     int64     `json:"holder_id"`
+	Now          time.Time `json:"now"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// 期限内のロックを他のユーザーが持っている場合は更新せず、行を返さない。同じユーザーが取得し直した場合は期限だけを延ばす
+func (q *Queries) AcquireResourceLock(ctx context.Context, arg AcquireResourceLockParams) (ResourceLock, error) {
+	row := q.queryRow(ctx, q.acquireResourceLockStmt, acquireResourceLock,
+		arg.ResourceType,
+		arg.ResourceID,
+		arg.HolderID,
+		arg.Now,
+		arg.ExpiresAt,
+	)
+	var i ResourceLock
+	err := row.Scan(
+		&i.ResourceType,
+		&i.ResourceID,
+		&i.HolderID,
+		&i.AcquiredAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const addOperationUsage = `-- name: AddOperationUsage :exec
+INSERT INTO operation_usage (operation_id, calls, last_used_at)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (operation_id) DO UPDATE
+SET calls = operation_usage.calls + excluded.calls,
+    last_used_at = MAX(operation_usage.last_used_at, excluded.last_used_at),
+    updated_at = CURRENT_TIMESTAMP
+`
+
+type AddOperationUsageParams struct {
+	OperationID string    `json:"operation_id"`
+	Calls       int64     `json:"calls"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+}
+
+func (q *Queries) AddOperationUsage(ctx context.Context, arg AddOperationUsageParams) error {
+	_, err := q.exec(ctx, q.addOperationUsageStmt, addOperationUsage, arg.OperationID, arg.Calls, arg.LastUsedAt)
+	return err
+}
+
+const addTodoTag = `-- name: AddTodoTag :exec
+INSERT OR IGNORE INTO todo_tags (todo_id, tag_id)
+VALUES (?, ?)
+`
+
+type AddTodoTagParams struct {
+	TodoID int64 `json:"todo_id"`
+	TagID  int64 `json:"tag_id"`
+}
+
+func (q *Queries) AddTodoTag(ctx context.Context, arg AddTodoTagParams) error {
+	_, err := q.exec(ctx, q.addTodoTagStmt, addTodoTag, arg.TodoID, arg.TagID)
+	return err
+}
+
+const addUsageAPICalls = `-- name: AddUsageAPICalls :exec
+INSERT INTO usage_daily (day, user_id, api_calls)
+SELECT ?1, id, ?2
+FROM users
+WHERE id = ?3
+ON CONFLICT (day, user_id) DO UPDATE
+SET api_calls = usage_daily.api_calls + excluded.api_calls, updated_at = CURRENT_TIMESTAMP
+`
+
+type AddUsageAPICallsParams struct {
+	Day      string `json:"day"`
+	ApiCalls int64  `json:"api_calls"`
+	UserID   int64  `json:"user_id"`
+}
+
+// 削除されたユーザーの呼び出し回数は記録しない
+func (q *Queries) AddUsageAPICalls(ctx context.Context, arg AddUsageAPICallsParams) error {
+	_, err := q.exec(ctx, q.addUsageAPICallsStmt, addUsageAPICalls, arg.Day, arg.ApiCalls, arg.UserID)
+	return err
+}
+
+const archiveProject = `-- name: ArchiveProject :execrows
+UPDATE projects
+SET archived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND archived_at IS NULL
+`
+
+func (q *Queries) ArchiveProject(ctx context.Context, id int64) (int64, error) {
+	result, err := q.exec(ctx, q.archiveProjectStmt, archiveProject, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const archiveTodo = `-- name: ArchiveTodo :one
+UPDATE todos
+SET archived_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND archived_at IS NULL
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+`
+
+func (q *Queries) ArchiveTodo(ctx context.Context, id int64) (Todo, error) {
+	row := q.queryRow(ctx, q.archiveTodoStmt, archiveTodo, id)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Completed,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.SubtaskCount,
+		&i.SubtaskCompletedCount,
+		&i.ProjectID,
+		&i.DueAt,
+		&i.Recurrence,
+		&i.NextTodoID,
+		&i.Position,
+		&i.Version,
+		&i.Assignee,
+		&i.OwnerID,
+		&i.Metadata,
+		&i.Priority,
+		&i.EscalationLevel,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Translations,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status = ?, headers = ?, body = ?
+WHERE subject = ? AND idempotency_key = ?
+`
+
+type CompleteIdempotencyKeyParams struct {
+	Status         int64  `json:"status"`
+	Headers        string `json:"headers"`
+	Body           []byte `json:"body"`
+	Subject        string `json:"subject"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.exec(ctx, q.completeIdempotencyKeyStmt, completeIdempotencyKey,
+		arg.Status,
+		arg.Headers,
+		arg.Body,
+		arg.Subject,
+		arg.IdempotencyKey,
+	)
+	return err
+}
+
+const consumeConfirmationToken = `-- name: ConsumeConfirmationToken :one
+DELETE FROM confirmation_tokens
+WHERE token_hash = ?1 AND expires_at > ?2
+RETURNING token_hash, operation, target, actor, impact, expires_at, created_at
+`
+
+type ConsumeConfirmationTokenParams struct {
+	TokenHash string    `json:"token_hash"`
+	Now       time.Time `json:"now"`
+}
+
+func (q *Queries) ConsumeConfirmationToken(ctx context.Context, arg ConsumeConfirmationTokenParams) (ConfirmationToken, error) {
+	row := q.queryRow(ctx, q.consumeConfirmationTokenStmt, consumeConfirmationToken, arg.TokenHash, arg.Now)
+	var i ConfirmationToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.Operation,
+		&i.Target,
+		&i.Actor,
+		&i.Impact,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const countActiveAPIKeysByCreator = `-- name: CountActiveAPIKeysByCreator :one
+SELECT COUNT(*) FROM api_keys WHERE created_by = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) CountActiveAPIKeysByCreator(ctx context.Context, createdBy string) (int64, error) {
+	row := q.queryRow(ctx, q.countActiveAPIKeysByCreatorStmt, countActiveAPIKeysByCreator, createdBy)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countCommentsByTodoIDs = `-- name: CountCommentsByTodoIDs :many
+SELECT todo_id, COUNT(*) AS count
+FROM comments
+WHERE todo_id IN (/*SLICE:todo_ids*/?)
+GROUP BY todo_id
+`
+
+type CountCommentsByTodoIDsRow struct {
+	TodoID int64 `json:"todo_id"`
+	Count  int64 `json:"count"`
+}
+
+func (q *Queries) CountCommentsByTodoIDs(ctx context.Context, todoIds []int64) ([]CountCommentsByTodoIDsRow, error) {
+	query := countCommentsByTodoIDs
+	var queryParams []interface{}
+	if len(todoIds) > 0 {
+		for _, v := range todoIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", strings.Repeat(",?", len(todoIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountCommentsByTodoIDsRow
+	for rows.Next() {
+		var i CountCommentsByTodoIDsRow
+		if err := rows.Scan(&i.TodoID, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.countUsersStmt, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUsersByRole = `-- name: CountUsersByRole :one
+SELECT COUNT(*) FROM users WHERE role = ?
+`
+
+func (q *Queries) CountUsersByRole(ctx context.Context, role string) (int64, error) {
+	row := q.queryRow(ctx, q.countUsersByRoleStmt, countUsersByRole, role)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, prefix, key_hash, created_by)
+VALUES (?, ?, ?, ?)
+RETURNING id, name, prefix, key_hash, created_by, last_used_at, revoked_at, created_at
+`
+
+type CreateAPIKeyParams struct {
+	Name      string `json:"name"`
+	Prefix    string `json:"prefix"`
+	KeyHash   string `json:"key_hash"`
+	CreatedBy string `json:"created_by"`
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	row := q.queryRow(ctx, q.createAPIKeyStmt, createAPIKey,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.CreatedBy,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.CreatedBy,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createActivity = `-- name: CreateActivity :exec
+INSERT INTO activity_log (todo_id, actor, action, diff)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateActivityParams struct {
+	TodoID int64  `json:"todo_id"`
+	Actor  string `json:"actor"`
+	Action string `json:"action"`
+	Diff   string `json:"diff"`
+}
+
+func (q *Queries) CreateActivity(ctx context.Context, arg CreateActivityParams) error {
+	_, err := q.exec(ctx, q.createActivityStmt, createActivity,
+		arg.TodoID,
+		arg.Actor,
+		arg.Action,
+		arg.Diff,
+	)
+	return err
+}
+
+const createAttachment = `-- name: CreateAttachment :one
+INSERT INTO attachments (todo_id, filename, content_type, size, storage_key)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, todo_id, filename, content_type, size, storage_key, created_at
+`
+
+type CreateAttachmentParams struct {
+	TodoID      int64  `json:"todo_id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	StorageKey  string `json:"storage_key"`
+}
+
+func (q *Queries) CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error) {
+	row := q.queryRow(ctx, q.createAttachmentStmt, createAttachment,
+		arg.TodoID,
+		arg.Filename,
+		arg.ContentType,
+		arg.Size,
+		arg.StorageKey,
+	)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.Filename,
+		&i.ContentType,
+		&i.Size,
+		&i.StorageKey,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createAuthEvent = `-- name: CreateAuthEvent :one
+INSERT INTO auth_events (subject, event_type, ip, user_agent, created_at)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, subject, event_type, ip, user_agent, created_at
+`
+
+type CreateAuthEventParams struct {
+	Subject   string    `json:"subject"`
+	EventType string    `json:"event_type"`
+	Ip        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) CreateAuthEvent(ctx context.Context, arg CreateAuthEventParams) (AuthEvent, error) {
+	row := q.queryRow(ctx, q.createAuthEventStmt, createAuthEvent,
+		arg.Subject,
+		arg.EventType,
+		arg.Ip,
+		arg.UserAgent,
+		arg.CreatedAt,
+	)
+	var i AuthEvent
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.EventType,
+		&i.Ip,
+		&i.UserAgent,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createComment = `-- name: CreateComment :one
+INSERT INTO comments (todo_id, author, body, created_at)
+VALUES (?1, ?2, ?3, COALESCE(?4, CURRENT_TIMESTAMP))
+RETURNING id, todo_id, author, body, created_at
+`
+
+type CreateCommentParams struct {
+	TodoID    int64        `json:"todo_id"`
+	Author    string       `json:"author"`
+	Body      string       `json:"body"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error) {
+	row := q.queryRow(ctx, q.createCommentStmt, createComment,
+		arg.TodoID,
+		arg.Author,
+		arg.Body,
+		arg.CreatedAt,
+	)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.Author,
+		&i.Body,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createConfirmationToken = `-- name: CreateConfirmationToken :exec
+INSERT INTO confirmation_tokens (token_hash, operation, target, actor, impact, expires_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateConfirmationTokenParams struct {
+	TokenHash string    `json:"token_hash"`
+	Operation string    `json:"operation"`
+	Target    string    `json:"target"`
+	Actor     string    `json:"actor"`
+	Impact    string    `json:"impact"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateConfirmationToken(ctx context.Context, arg CreateConfirmationTokenParams) error {
+	_, err := q.exec(ctx, q.createConfirmationTokenStmt, createConfirmationToken,
+		arg.TokenHash,
+		arg.Operation,
+		arg.Target,
+		arg.Actor,
+		arg.Impact,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createDescriptionOp = `-- name: CreateDescriptionOp :exec
+INSERT INTO todo_description_ops (todo_id, revision, ops, description, actor)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateDescriptionOpParams struct {
+	TodoID      int64  `json:"todo_id"`
+	Revision    int64  `json:"revision"`
+	Ops         string `json:"ops"`
+	Description string `json:"description"`
+	Actor       string `json:"actor"`
+}
+
+func (q *Queries) CreateDescriptionOp(ctx context.Context, arg CreateDescriptionOpParams) error {
+	_, err := q.exec(ctx, q.createDescriptionOpStmt, createDescriptionOp,
+		arg.TodoID,
+		arg.Revision,
+		arg.Ops,
+		arg.Description,
+		arg.Actor,
+	)
+	return err
+}
+
+const createDueDigest = `-- name: CreateDueDigest :exec
+INSERT INTO due_digests (owner_id, digest_date, todo_count, sent_at)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateDueDigestParams struct {
+	OwnerID    int64     `json:"owner_id"`
+	DigestDate string    `json:"digest_date"`
+	TodoCount  int64     `json:"todo_count"`
+	SentAt     time.Time `json:"sent_at"`
+}
+
+func (q *Queries) CreateDueDigest(ctx context.Context, arg CreateDueDigestParams) error {
+	_, err := q.exec(ctx, q.createDueDigestStmt, createDueDigest,
+		arg.OwnerID,
+		arg.DigestDate,
+		arg.TodoCount,
+		arg.SentAt,
+	)
+	return err
+}
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (subject, idempotency_key, request_hash, expires_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (subject, idempotency_key) DO NOTHING
+`
+
+type CreateIdempotencyKeyParams struct {
+	Subject        string    `json:"subject"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	RequestHash    string    `json:"request_hash"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (int64, error) {
+	result, err := q.exec(ctx, q.createIdempotencyKeyStmt, createIdempotencyKey,
+		arg.Subject,
+		arg.IdempotencyKey,
+		arg.RequestHash,
+		arg.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createImportJob = `-- name: CreateImportJob :one
+INSERT INTO import_jobs (source, dry_run, actor)
+VALUES (?, ?, ?)
+RETURNING id, source, status, dry_run, actor, result, error, created_at, finished_at
+`
+
+type CreateImportJobParams struct {
+	Source string `json:"source"`
+	DryRun int64  `json:"dry_run"`
+	Actor  string `json:"actor"`
+}
+
+func (q *Queries) CreateImportJob(ctx context.Context, arg CreateImportJobParams) (ImportJob, error) {
+	row := q.queryRow(ctx, q.createImportJobStmt, createImportJob, arg.Source, arg.DryRun, arg.Actor)
+	var i ImportJob
+	err := row.Scan(
+		&i.ID,
+		&i.Source,
+		&i.Status,
+		&i.DryRun,
+		&i.Actor,
+		&i.Result,
+		&i.Error,
+		&i.CreatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const createInvitation = `-- name: CreateInvitation :one
+INSERT INTO invitations (email, role, token_hash, invited_by, expires_at)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, created_at
+`
+
+type CreateInvitationParams struct {
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	TokenHash string    `json:"token_hash"`
+	InvitedBy string    `json:"invited_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateInvitation(ctx context.Context, arg CreateInvitationParams) (Invitation, error) {
+	row := q.queryRow(ctx, q.createInvitationStmt, createInvitation,
+		arg.Email,
+		arg.Role,
+		arg.TokenHash,
+		arg.InvitedBy,
+		arg.ExpiresAt,
+	)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Role,
+		&i.TokenHash,
+		&i.InvitedBy,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedUserID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOutboxEvent = `-- name: CreateOutboxEvent :exec
+INSERT INTO outbox (type, todo_id, owner_id, completed, comment_id, occurred_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateOutboxEventParams struct {
+	Type       string        `json:"type"`
+	TodoID     int64         `json:"todo_id"`
+	OwnerID    sql.NullInt64 `json:"owner_id"`
+	Completed  int64         `json:"completed"`
+	CommentID  sql.NullInt64 `json:"comment_id"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+func (q *Queries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error {
+	_, err := q.exec(ctx, q.createOutboxEventStmt, createOutboxEvent,
+		arg.Type,
+		arg.TodoID,
+		arg.OwnerID,
+		arg.Completed,
+		arg.CommentID,
+		arg.OccurredAt,
+	)
+	return err
+}
+
+const createProject = `-- name: CreateProject :one
+INSERT INTO projects (name, description, metadata_schema, wip_limit)
+VALUES (?, ?, ?, ?)
+RETURNING id, name, description, archived_at, open_count, completed_count, metadata_schema, created_at, updated_at, wip_limit
+`
+
+type CreateProjectParams struct {
+	Name           string         `json:"name"`
+	Description    sql.NullString `json:"description"`
+	MetadataSchema sql.NullString `json:"metadata_schema"`
+	WipLimit       sql.NullInt64  `json:"wip_limit"`
+}
+
+func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error) {
+	row := q.queryRow(ctx, q.createProjectStmt, createProject,
+		arg.Name,
+		arg.Description,
+		arg.MetadataSchema,
+		arg.WipLimit,
+	)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.ArchivedAt,
+		&i.OpenCount,
+		&i.CompletedCount,
+		&i.MetadataSchema,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.WipLimit,
+	)
+	return i, err
+}
+
+const createReminder = `-- name: CreateReminder :one
+INSERT INTO reminders (todo_id, remind_at, channel)
+VALUES (?, ?, ?)
+RETURNING id, todo_id, remind_at, channel, sent_at, created_at
+`
+
+type CreateReminderParams struct {
+	TodoID   int64     `json:"todo_id"`
+	RemindAt time.Time `json:"remind_at"`
+	Channel  string    `json:"channel"`
+}
+
+func (q *Queries) CreateReminder(ctx context.Context, arg CreateReminderParams) (Reminder, error) {
+	row := q.queryRow(ctx, q.createReminderStmt, createReminder, arg.TodoID, arg.RemindAt, arg.Channel)
+	var i Reminder
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.RemindAt,
+		&i.Channel,
+		&i.SentAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createSavedFilter = `-- name: CreateSavedFilter :one
+INSERT INTO saved_filters (name, completed, near, radius)
+VALUES (?, ?, ?, ?)
+RETURNING id, name, completed, near, radius, created_at
+`
+
+type CreateSavedFilterParams struct {
+	Name      string         `json:"name"`
+	Completed int64          `json:"completed"`
+	Near      sql.NullString `json:"near"`
+	Radius    float64        `json:"radius"`
+}
+
+func (q *Queries) CreateSavedFilter(ctx context.Context, arg CreateSavedFilterParams) (SavedFilter, error) {
+	row := q.queryRow(ctx, q.createSavedFilterStmt, createSavedFilter,
+		arg.Name,
+		arg.Completed,
+		arg.Near,
+		arg.Radius,
+	)
+	var i SavedFilter
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Completed,
+		&i.Near,
+		&i.Radius,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createStaleDigest = `-- name: CreateStaleDigest :exec
+INSERT INTO stale_digests (owner_id, todo_count, sent_at)
+VALUES (?, ?, ?)
+`
+
+type CreateStaleDigestParams struct {
+	OwnerID   sql.NullInt64 `json:"owner_id"`
+	TodoCount int64         `json:"todo_count"`
+	SentAt    time.Time     `json:"sent_at"`
+}
+
+func (q *Queries) CreateStaleDigest(ctx context.Context, arg CreateStaleDigestParams) error {
+	_, err := q.exec(ctx, q.createStaleDigestStmt, createStaleDigest, arg.OwnerID, arg.TodoCount, arg.SentAt)
+	return err
+}
+
+const createSubtask = `-- name: CreateSubtask :one
+INSERT INTO subtasks (todo_id, title, completed)
+VALUES (?, ?, ?)
+RETURNING id, todo_id, title, completed, created_at, updated_at
+`
+
+type CreateSubtaskParams struct {
+	TodoID    int64  `json:"todo_id"`
+	Title     string `json:"title"`
+	Completed int64  `json:"completed"`
+}
+
+func (q *Queries) CreateSubtask(ctx context.Context, arg CreateSubtaskParams) (Subtask, error) {
+	row := q.queryRow(ctx, q.createSubtaskStmt, createSubtask, arg.TodoID, arg.Title, arg.Completed)
+	var i Subtask
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.Title,
+		&i.Completed,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createTag = `-- name: CreateTag :one
+INSERT INTO tags (owner_id, name)
+VALUES (?, ?)
+RETURNING id, owner_id, name, created_at
+`
+
+type CreateTagParams struct {
+	OwnerID sql.NullInt64 `json:"owner_id"`
+	Name    string        `json:"name"`
+}
+
+func (q *Queries) CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error) {
+	row := q.queryRow(ctx, q.createTagStmt, createTag, arg.OwnerID, arg.Name)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createTodo = `-- name: CreateTodo :one
-INSERT INTO todos (title, description, completed)
+INSERT INTO todos (title, description, completed, latitude, longitude, place_name, project_id, due_at, recurrence, assignee, owner_id, metadata, priority, translations, position)
+VALUES (
+    ?1, ?2, ?3, ?4, ?5,
+    ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13, ?14,
+    (SELECT COALESCE(MAX(position), 0) + 1 FROM todos WHERE project_id IS ?7)
+)
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+`
+
+type CreateTodoParams struct {
+	Title        string          `json:"title"`
+	Description  sql.NullString  `json:"description"`
+	Completed    int64           `json:"completed"`
+	Latitude     sql.NullFloat64 `json:"latitude"`
+	Longitude    sql.NullFloat64 `json:"longitude"`
+	PlaceName    sql.NullString  `json:"place_name"`
+	ProjectID    sql.NullInt64   `json:"project_id"`
+	DueAt        sql.NullTime    `json:"due_at"`
+	Recurrence   sql.NullString  `json:"recurrence"`
+	Assignee     sql.NullString  `json:"assignee"`
+	OwnerID      sql.NullInt64   `json:"owner_id"`
+	Metadata     sql.NullString  `json:"metadata"`
+	Priority     int64           `json:"priority"`
+	Translations sql.NullString  `json:"translations"`
+}
+
+func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error) {
+	row := q.queryRow(ctx, q.createTodoStmt, createTodo,
+		arg.Title,
+		arg.Description,
+		arg.Completed,
+		arg.Latitude,
+		arg.Longitude,
+		arg.PlaceName,
+		arg.ProjectID,
+		arg.DueAt,
+		arg.Recurrence,
+		arg.Assignee,
+		arg.OwnerID,
+		arg.Metadata,
+		arg.Priority,
+		arg.Translations,
+	)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Completed,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.SubtaskCount,
+		&i.SubtaskCompletedCount,
+		&i.ProjectID,
+		&i.DueAt,
+		&i.Recurrence,
+		&i.NextTodoID,
+		&i.Position,
+		&i.Version,
+		&i.Assignee,
+		&i.OwnerID,
+		&i.Metadata,
+		&i.Priority,
+		&i.EscalationLevel,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Translations,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (email, name, password_hash, role)
+VALUES (?, ?, ?, ?)
+RETURNING id, email, name, password_hash, role, created_at
+`
+
+type CreateUserParams struct {
+	Email        string `json:"email"`
+	Name         string `json:"name"`
+	PasswordHash string `json:"password_hash"`
+	Role         string `json:"role"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.queryRow(ctx, q.createUserStmt, createUser,
+		arg.Email,
+		arg.Name,
+		arg.PasswordHash,
+		arg.Role,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.PasswordHash,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createUserIdentity = `-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (user_id, issuer, subject)
+VALUES (?, ?, ?)
+`
+
+type CreateUserIdentityParams struct {
+	UserID  int64  `json:"user_id"`
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) error {
+	_, err := q.exec(ctx, q.createUserIdentityStmt, createUserIdentity, arg.UserID, arg.Issuer, arg.Subject)
+	return err
+}
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks (owner_id, url, events, secret)
+VALUES (?, ?, ?, ?)
+RETURNING id, owner_id, url, events, secret, active, created_at, updated_at
+`
+
+type CreateWebhookParams struct {
+	OwnerID sql.NullInt64 `json:"owner_id"`
+	Url     string        `json:"url"`
+	Events  string        `json:"events"`
+	Secret  string        `json:"secret"`
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row := q.queryRow(ctx, q.createWebhookStmt, createWebhook,
+		arg.OwnerID,
+		arg.Url,
+		arg.Events,
+		arg.Secret,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Url,
+		&i.Events,
+		&i.Secret,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (webhook_id, event, todo_id, payload, next_attempt_at)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateWebhookDeliveryParams struct {
+	WebhookID     int64     `json:"webhook_id"`
+	Event         string    `json:"event"`
+	TodoID        int64     `json:"todo_id"`
+	Payload       string    `json:"payload"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error {
+	_, err := q.exec(ctx, q.createWebhookDeliveryStmt, createWebhookDelivery,
+		arg.WebhookID,
+		arg.Event,
+		arg.TodoID,
+		arg.Payload,
+		arg.NextAttemptAt,
+	)
+	return err
+}
+
+const deleteComment = `-- name: DeleteComment :execrows
+DELETE FROM comments WHERE id = ? AND todo_id = ?
+`
+
+type DeleteCommentParams struct {
+	ID     int64 `json:"id"`
+	TodoID int64 `json:"todo_id"`
+}
+
+func (q *Queries) DeleteComment(ctx context.Context, arg DeleteCommentParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteCommentStmt, deleteComment, arg.ID, arg.TodoID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteCompletedTodos = `-- name: DeleteCompletedTodos :many
+DELETE FROM todos
+WHERE completed = 1 AND owner_id IS ?
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+`
+
+func (q *Queries) DeleteCompletedTodos(ctx context.Context, ownerID sql.NullInt64) ([]Todo, error) {
+	rows, err := q.query(ctx, q.deleteCompletedTodosStmt, deleteCompletedTodos, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteExpiredConfirmationTokens = `-- name: DeleteExpiredConfirmationTokens :execrows
+DELETE FROM confirmation_tokens
+WHERE expires_at <= ?
+`
+
+func (q *Queries) DeleteExpiredConfirmationTokens(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.exec(ctx, q.deleteExpiredConfirmationTokensStmt, deleteExpiredConfirmationTokens, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= ?
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.exec(ctx, q.deleteExpiredIdempotencyKeysStmt, deleteExpiredIdempotencyKeys, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE subject = ? AND idempotency_key = ?
+`
+
+type DeleteIdempotencyKeyParams struct {
+	Subject        string `json:"subject"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.exec(ctx, q.deleteIdempotencyKeyStmt, deleteIdempotencyKey, arg.Subject, arg.IdempotencyKey)
+	return err
+}
+
+const deleteInvitation = `-- name: DeleteInvitation :execrows
+DELETE FROM invitations WHERE id = ?
+`
+
+func (q *Queries) DeleteInvitation(ctx context.Context, id int64) (int64, error) {
+	result, err := q.exec(ctx, q.deleteInvitationStmt, deleteInvitation, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteProject = `-- name: DeleteProject :execrows
+DELETE FROM projects WHERE id = ?
+`
+
+func (q *Queries) DeleteProject(ctx context.Context, id int64) (int64, error) {
+	result, err := q.exec(ctx, q.deleteProjectStmt, deleteProject, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteProjectChatChannel = `-- name: DeleteProjectChatChannel :execrows
+DELETE FROM project_chat_channels
+WHERE project_id = ? AND provider = ?
+`
+
+type DeleteProjectChatChannelParams struct {
+	ProjectID int64  `json:"project_id"`
+	Provider  string `json:"provider"`
+}
+
+func (q *Queries) DeleteProjectChatChannel(ctx context.Context, arg DeleteProjectChatChannelParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteProjectChatChannelStmt, deleteProjectChatChannel, arg.ProjectID, arg.Provider)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteProjectShare = `-- name: DeleteProjectShare :execrows
+DELETE FROM todo_shares WHERE id = ? AND project_id = ? AND shared_by = ?
+`
+
+type DeleteProjectShareParams struct {
+	ID        int64         `json:"id"`
+	ProjectID sql.NullInt64 `json:"project_id"`
+	SharedBy  int64         `json:"shared_by"`
+}
+
+func (q *Queries) DeleteProjectShare(ctx context.Context, arg DeleteProjectShareParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteProjectShareStmt, deleteProjectShare, arg.ID, arg.ProjectID, arg.SharedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteReminder = `-- name: DeleteReminder :execrows
+DELETE FROM reminders WHERE id = ? AND todo_id = ?
+`
+
+type DeleteReminderParams struct {
+	ID     int64 `json:"id"`
+	TodoID int64 `json:"todo_id"`
+}
+
+func (q *Queries) DeleteReminder(ctx context.Context, arg DeleteReminderParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteReminderStmt, deleteReminder, arg.ID, arg.TodoID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteResourceLock = `-- name: DeleteResourceLock :exec
+DELETE FROM resource_locks WHERE resource_type = ? AND resource_id = ?
+`
+
+type DeleteResourceLockParams struct {
+	ResourceType string `json:"resource_type"`
+	ResourceID   int64  `json:"resource_id"`
+}
+
+func (q *Queries) DeleteResourceLock(ctx context.Context, arg DeleteResourceLockParams) error {
+	_, err := q.exec(ctx, q.deleteResourceLockStmt, deleteResourceLock, arg.ResourceType, arg.ResourceID)
+	return err
+}
+
+const deleteSavedFilter = `-- name: DeleteSavedFilter :execrows
+DELETE FROM saved_filters WHERE id = ?
+`
+
+func (q *Queries) DeleteSavedFilter(ctx context.Context, id int64) (int64, error) {
+	result, err := q.exec(ctx, q.deleteSavedFilterStmt, deleteSavedFilter, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteSubtask = `-- name: DeleteSubtask :execrows
+DELETE FROM subtasks WHERE id = ? AND todo_id = ?
+`
+
+type DeleteSubtaskParams struct {
+	ID     int64 `json:"id"`
+	TodoID int64 `json:"todo_id"`
+}
+
+func (q *Queries) DeleteSubtask(ctx context.Context, arg DeleteSubtaskParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteSubtaskStmt, deleteSubtask, arg.ID, arg.TodoID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTag = `-- name: DeleteTag :execrows
+DELETE FROM tags WHERE id = ?
+`
+
+func (q *Queries) DeleteTag(ctx context.Context, id int64) (int64, error) {
+	result, err := q.exec(ctx, q.deleteTagStmt, deleteTag, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTodo = `-- name: DeleteTodo :exec
+DELETE FROM todos WHERE id = ?
+`
+
+func (q *Queries) DeleteTodo(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.deleteTodoStmt, deleteTodo, id)
+	return err
+}
+
+const deleteTodoDefaults = `-- name: DeleteTodoDefaults :execrows
+DELETE FROM todo_defaults
+WHERE project_id IS ?1
+`
+
+func (q *Queries) DeleteTodoDefaults(ctx context.Context, projectID sql.NullInt64) (int64, error) {
+	result, err := q.exec(ctx, q.deleteTodoDefaultsStmt, deleteTodoDefaults, projectID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTodoShare = `-- name: DeleteTodoShare :execrows
+DELETE FROM todo_shares WHERE id = ? AND todo_id = ?
+`
+
+type DeleteTodoShareParams struct {
+	ID     int64         `json:"id"`
+	TodoID sql.NullInt64 `json:"todo_id"`
+}
+
+func (q *Queries) DeleteTodoShare(ctx context.Context, arg DeleteTodoShareParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteTodoShareStmt, deleteTodoShare, arg.ID, arg.TodoID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTodoTags = `-- name: DeleteTodoTags :exec
+DELETE FROM todo_tags WHERE todo_id = ?
+`
+
+func (q *Queries) DeleteTodoTags(ctx context.Context, todoID int64) error {
+	_, err := q.exec(ctx, q.deleteTodoTagsStmt, deleteTodoTags, todoID)
+	return err
+}
+
+const deleteTodosByIDs = `-- name: DeleteTodosByIDs :many
+DELETE FROM todos
+WHERE owner_id IS ? AND id IN (/*SLICE:ids*/?)
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+`
+
+type DeleteTodosByIDsParams struct {
+	OwnerID sql.NullInt64 `json:"owner_id"`
+	Ids     []int64       `json:"ids"`
+}
+
+func (q *Queries) DeleteTodosByIDs(ctx context.Context, arg DeleteTodosByIDsParams) ([]Todo, error) {
+	query := deleteTodosByIDs
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.OwnerID)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users WHERE id = ?
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id int64) (int64, error) {
+	result, err := q.exec(ctx, q.deleteUserStmt, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = ?1 AND owner_id IS ?2
+`
+
+type DeleteWebhookParams struct {
+	ID      int64         `json:"id"`
+	OwnerID sql.NullInt64 `json:"owner_id"`
+}
+
+func (q *Queries) DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteWebhookStmt, deleteWebhook, arg.ID, arg.OwnerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const endRecurrence = `-- name: EndRecurrence :exec
+UPDATE todos
+SET recurrence = NULL, version = version + 1
+WHERE id = ?
+`
+
+func (q *Queries) EndRecurrence(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.endRecurrenceStmt, endRecurrence, id)
+	return err
+}
+
+const escalateTodo = `-- name: EscalateTodo :one
+UPDATE todos
+SET priority = ?1, escalation_level = ?2, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?3 AND completed = 0 AND escalation_level < ?2
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+`
+
+type EscalateTodoParams struct {
+	Priority        int64 `json:"priority"`
+	EscalationLevel int64 `json:"escalation_level"`
+	ID              int64 `json:"id"`
+}
+
+func (q *Queries) EscalateTodo(ctx context.Context, arg EscalateTodoParams) (Todo, error) {
+	row := q.queryRow(ctx, q.escalateTodoStmt, escalateTodo, arg.Priority, arg.EscalationLevel, arg.ID)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Completed,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.SubtaskCount,
+		&i.SubtaskCompletedCount,
+		&i.ProjectID,
+		&i.DueAt,
+		&i.Recurrence,
+		&i.NextTodoID,
+		&i.Position,
+		&i.Version,
+		&i.Assignee,
+		&i.OwnerID,
+		&i.Metadata,
+		&i.Priority,
+		&i.EscalationLevel,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Translations,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const failInterruptedImportJobs = `-- name: FailInterruptedImportJobs :execrows
+UPDATE import_jobs
+SET status = 'failed', error = ?, finished_at = CURRENT_TIMESTAMP
+WHERE status = 'running'
+`
+
+func (q *Queries) FailInterruptedImportJobs(ctx context.Context, error sql.NullString) (int64, error) {
+	result, err := q.exec(ctx, q.failInterruptedImportJobsStmt, failInterruptedImportJobs, error)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const finishImportJob = `-- name: FinishImportJob :one
+UPDATE import_jobs
+SET status = ?, result = ?, error = ?, finished_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, source, status, dry_run, actor, result, error, created_at, finished_at
+`
+
+type FinishImportJobParams struct {
+	Status string         `json:"status"`
+	Result sql.NullString `json:"result"`
+	Error  sql.NullString `json:"error"`
+	ID     int64          `json:"id"`
+}
+
+func (q *Queries) FinishImportJob(ctx context.Context, arg FinishImportJobParams) (ImportJob, error) {
+	row := q.queryRow(ctx, q.finishImportJobStmt, finishImportJob,
+		arg.Status,
+		arg.Result,
+		arg.Error,
+		arg.ID,
+	)
+	var i ImportJob
+	err := row.Scan(
+		&i.ID,
+		&i.Source,
+		&i.Status,
+		&i.DryRun,
+		&i.Actor,
+		&i.Result,
+		&i.Error,
+		&i.CreatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const finishWebhookDelivery = `-- name: FinishWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = ?1, attempts = attempts + 1, next_attempt_at = ?2,
+    response_status = ?3, last_error = ?4, delivered_at = ?5
+WHERE id = ?6
+`
+
+type FinishWebhookDeliveryParams struct {
+	Status         string         `json:"status"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at"`
+	ResponseStatus sql.NullInt64  `json:"response_status"`
+	LastError      sql.NullString `json:"last_error"`
+	DeliveredAt    sql.NullTime   `json:"delivered_at"`
+	ID             int64          `json:"id"`
+}
+
+func (q *Queries) FinishWebhookDelivery(ctx context.Context, arg FinishWebhookDeliveryParams) error {
+	_, err := q.exec(ctx, q.finishWebhookDeliveryStmt, finishWebhookDelivery,
+		arg.Status,
+		arg.NextAttemptAt,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.DeliveredAt,
+		arg.ID,
+	)
+	return err
+}
+
+const getAPIKey = `-- name: GetAPIKey :one
+SELECT id, name, prefix, key_hash, created_by, last_used_at, revoked_at, created_at
+FROM api_keys
+WHERE id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetAPIKey(ctx context.Context, id int64) (ApiKey, error) {
+	row := q.queryRow(ctx, q.getAPIKeyStmt, getAPIKey, id)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.CreatedBy,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getActiveAPIKeyByHash = `-- name: GetActiveAPIKeyByHash :one
+SELECT id, name, prefix, key_hash, created_by, last_used_at, revoked_at, created_at
+FROM api_keys
+WHERE key_hash = ? AND revoked_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error) {
+	row := q.queryRow(ctx, q.getActiveAPIKeyByHashStmt, getActiveAPIKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.CreatedBy,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAttachment = `-- name: GetAttachment :one
+SELECT id, todo_id, filename, content_type, size, storage_key, created_at
+FROM attachments
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetAttachment(ctx context.Context, id int64) (Attachment, error) {
+	row := q.queryRow(ctx, q.getAttachmentStmt, getAttachment, id)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.Filename,
+		&i.ContentType,
+		&i.Size,
+		&i.StorageKey,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAuthLocationStats = `-- name: GetAuthLocationStats :one
+SELECT COUNT(*) AS total, COUNT(CASE WHEN ip = ?1 THEN 1 END) AS from_ip
+FROM auth_events
+WHERE subject = ?2 AND event_type IN (/*SLICE:event_types*/?)
+`
+
+type GetAuthLocationStatsParams struct {
+	Ip         string   `json:"ip"`
+	Subject    string   `json:"subject"`
+	EventTypes []string `json:"event_types"`
+}
+
+type GetAuthLocationStatsRow struct {
+	Total  int64 `json:"total"`
+	FromIp int64 `json:"from_ip"`
+}
+
+func (q *Queries) GetAuthLocationStats(ctx context.Context, arg GetAuthLocationStatsParams) (GetAuthLocationStatsRow, error) {
+	query := getAuthLocationStats
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.Ip)
+	queryParams = append(queryParams, arg.Subject)
+	if len(arg.EventTypes) > 0 {
+		for _, v := range arg.EventTypes {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:event_types*/?", strings.Repeat(",?", len(arg.EventTypes))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:event_types*/?", "NULL", 1)
+	}
+	row := q.queryRow(ctx, nil, query, queryParams...)
+	var i GetAuthLocationStatsRow
+	err := row.Scan(&i.Total, &i.FromIp)
+	return i, err
+}
+
+const getBadgeCounts = `-- name: GetBadgeCounts :one
+SELECT
+    (SELECT COUNT(*) FROM todos
+     WHERE owner_id IS ?1 AND completed = 0 AND archived_at IS NULL AND due_at < ?2
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS overdue_count,
+    (SELECT COUNT(*) FROM todos
+     WHERE owner_id IS ?1 AND completed = 0 AND archived_at IS NULL AND due_at >= ?2 AND due_at < ?3
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS due_today_count,
+    (SELECT COUNT(*) FROM todos
+     WHERE owner_id IS ?1 AND completed = 0 AND archived_at IS NULL AND assignee = CAST(?4 AS TEXT)
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS assigned_count,
+    (SELECT COUNT(*) FROM comments
+     WHERE id > COALESCE((SELECT last_comment_id FROM read_markers WHERE subject = ?4), 0)
+       AND author != ?4
+       AND todo_id IN (SELECT id FROM todos WHERE owner_id IS ?1)) AS unread_comment_count
+`
+
+type GetBadgeCountsParams struct {
+	OwnerID sql.NullInt64 `json:"owner_id"`
+	Now     sql.NullTime  `json:"now"`
+	DayEnd  sql.NullTime  `json:"day_end"`
+	Subject string        `json:"subject"`
+}
+
+type GetBadgeCountsRow struct {
+	OverdueCount       int64 `json:"overdue_count"`
+	DueTodayCount      int64 `json:"due_today_count"`
+	AssignedCount      int64 `json:"assigned_count"`
+	UnreadCommentCount int64 `json:"unread_comment_count"`
+}
+
+func (q *Queries) GetBadgeCounts(ctx context.Context, arg GetBadgeCountsParams) (GetBadgeCountsRow, error) {
+	row := q.queryRow(ctx, q.getBadgeCountsStmt, getBadgeCounts,
+		arg.OwnerID,
+		arg.Now,
+		arg.DayEnd,
+		arg.Subject,
+	)
+	var i GetBadgeCountsRow
+	err := row.Scan(
+		&i.OverdueCount,
+		&i.DueTodayCount,
+		&i.AssignedCount,
+		&i.UnreadCommentCount,
+	)
+	return i, err
+}
+
+const getChatChannelForTodo = `-- name: GetChatChannelForTodo :one
+SELECT c.project_id, c.provider, c.webhook_url, c.events, c.templates, c.created_at, c.updated_at
+FROM project_chat_channels c
+JOIN todos t ON t.project_id = c.project_id
+WHERE t.id = ?1 AND c.provider = ?2
+`
+
+type GetChatChannelForTodoParams struct {
+	TodoID   int64  `json:"todo_id"`
+	Provider string `json:"provider"`
+}
+
+// Todoが属するプロジェクトに設定されたチャットの送信先を取得する
+func (q *Queries) GetChatChannelForTodo(ctx context.Context, arg GetChatChannelForTodoParams) (ProjectChatChannel, error) {
+	row := q.queryRow(ctx, q.getChatChannelForTodoStmt, getChatChannelForTodo, arg.TodoID, arg.Provider)
+	var i ProjectChatChannel
+	err := row.Scan(
+		&i.ProjectID,
+		&i.Provider,
+		&i.WebhookUrl,
+		&i.Events,
+		&i.Templates,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getComment = `-- name: GetComment :one
+SELECT id, todo_id, author, body, created_at
+FROM comments
+WHERE id = ? AND todo_id = ?
+`
+
+type GetCommentParams struct {
+	ID     int64 `json:"id"`
+	TodoID int64 `json:"todo_id"`
+}
+
+func (q *Queries) GetComment(ctx context.Context, arg GetCommentParams) (Comment, error) {
+	row := q.queryRow(ctx, q.getCommentStmt, getComment, arg.ID, arg.TodoID)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.Author,
+		&i.Body,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT subject, idempotency_key, request_hash, status, headers, body, created_at, expires_at
+FROM idempotency_keys
+WHERE subject = ? AND idempotency_key = ? AND expires_at > ?
+LIMIT 1
+`
+
+type GetIdempotencyKeyParams struct {
+	Subject        string    `json:"subject"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.queryRow(ctx, q.getIdempotencyKeyStmt, getIdempotencyKey, arg.Subject, arg.IdempotencyKey, arg.ExpiresAt)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Subject,
+		&i.IdempotencyKey,
+		&i.RequestHash,
+		&i.Status,
+		&i.Headers,
+		&i.Body,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getImportJob = `-- name: GetImportJob :one
+SELECT id, source, status, dry_run, actor, result, error, created_at, finished_at
+FROM import_jobs
+WHERE id = ?
+`
+
+func (q *Queries) GetImportJob(ctx context.Context, id int64) (ImportJob, error) {
+	row := q.queryRow(ctx, q.getImportJobStmt, getImportJob, id)
+	var i ImportJob
+	err := row.Scan(
+		&i.ID,
+		&i.Source,
+		&i.Status,
+		&i.DryRun,
+		&i.Actor,
+		&i.Result,
+		&i.Error,
+		&i.CreatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const getInboxSummary = `-- name: GetInboxSummary :one
+SELECT
+    (SELECT COUNT(*) FROM todos
+     WHERE completed = 0 AND due_at < ?1 AND owner_id IS ?2 AND archived_at IS NULL
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS overdue_count,
+    (SELECT COUNT(*) FROM todos
+     WHERE completed = 0 AND due_at >= ?1 AND due_at < ?3 AND owner_id IS ?2 AND archived_at IS NULL
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS due_today_count,
+    (SELECT COUNT(*) FROM todos
+     WHERE completed = 0 AND assignee = CAST(?4 AS TEXT) AND owner_id IS ?2 AND archived_at IS NULL
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS assigned_count,
+    (SELECT COUNT(*) FROM comments
+     WHERE id > COALESCE((SELECT last_comment_id FROM read_markers WHERE subject = ?4), 0)
+       AND author != ?4
+       AND instr(body, '@' || ?4) > 0) AS mention_count,
+    (SELECT COUNT(*) FROM activity_log
+     WHERE id > COALESCE((SELECT last_activity_id FROM read_markers WHERE subject = ?4), 0)
+       AND actor != ?4) AS unread_activity_count
+`
+
+type GetInboxSummaryParams struct {
+	Now     sql.NullTime  `json:"now"`
+	OwnerID sql.NullInt64 `json:"owner_id"`
+	DayEnd  sql.NullTime  `json:"day_end"`
+	Subject string        `json:"subject"`
+}
+
+type GetInboxSummaryRow struct {
+	OverdueCount        int64 `json:"overdue_count"`
+	DueTodayCount       int64 `json:"due_today_count"`
+	AssignedCount       int64 `json:"assigned_count"`
+	MentionCount        int64 `json:"mention_count"`
+	UnreadActivityCount int64 `json:"unread_activity_count"`
+}
+
+func (q *Queries) GetInboxSummary(ctx context.Context, arg GetInboxSummaryParams) (GetInboxSummaryRow, error) {
+	row := q.queryRow(ctx, q.getInboxSummaryStmt, getInboxSummary,
+		arg.Now,
+		arg.OwnerID,
+		arg.DayEnd,
+		arg.Subject,
+	)
+	var i GetInboxSummaryRow
+	err := row.Scan(
+		&i.OverdueCount,
+		&i.DueTodayCount,
+		&i.AssignedCount,
+		&i.MentionCount,
+		&i.UnreadActivityCount,
+	)
+	return i, err
+}
+
+const getInvitationByTokenHash = `-- name: GetInvitationByTokenHash :one
+SELECT id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, created_at
+FROM invitations
+WHERE token_hash = ?
+`
+
+func (q *Queries) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (Invitation, error) {
+	row := q.queryRow(ctx, q.getInvitationByTokenHashStmt, getInvitationByTokenHash, tokenHash)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Role,
+		&i.TokenHash,
+		&i.InvitedBy,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedUserID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLatestActivity = `-- name: GetLatestActivity :one
+SELECT id, todo_id, actor, action, diff, created_at
+FROM activity_log
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestActivity(ctx context.Context) (ActivityLog, error) {
+	row := q.queryRow(ctx, q.getLatestActivityStmt, getLatestActivity)
+	var i ActivityLog
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.Actor,
+		&i.Action,
+		&i.Diff,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLatestDescriptionOp = `-- name: GetLatestDescriptionOp :one
+SELECT todo_id, revision, ops, description, actor, created_at
+FROM todo_description_ops
+WHERE todo_id = ?
+ORDER BY revision DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestDescriptionOp(ctx context.Context, todoID int64) (TodoDescriptionOp, error) {
+	row := q.queryRow(ctx, q.getLatestDescriptionOpStmt, getLatestDescriptionOp, todoID)
+	var i TodoDescriptionOp
+	err := row.Scan(
+		&i.TodoID,
+		&i.Revision,
+		&i.Ops,
+		&i.Description,
+		&i.Actor,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLatestOutboxEventID = `-- name: GetLatestOutboxEventID :one
+SELECT CAST(COALESCE(MAX(id), 0) AS INTEGER) AS id
+FROM outbox
+`
+
+func (q *Queries) GetLatestOutboxEventID(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.getLatestOutboxEventIDStmt, getLatestOutboxEventID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getLatestStaleDigest = `-- name: GetLatestStaleDigest :one
+SELECT id, owner_id, todo_count, sent_at
+FROM stale_digests
+WHERE owner_id IS ?1
+ORDER BY sent_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestStaleDigest(ctx context.Context, ownerID sql.NullInt64) (StaleDigest, error) {
+	row := q.queryRow(ctx, q.getLatestStaleDigestStmt, getLatestStaleDigest, ownerID)
+	var i StaleDigest
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.TodoCount,
+		&i.SentAt,
+	)
+	return i, err
+}
+
+const getNextActivity = `-- name: GetNextActivity :one
+SELECT id, todo_id, actor, action, diff, created_at
+FROM activity_log
+WHERE id > ?1
+ORDER BY id
+LIMIT 1
+`
+
+func (q *Queries) GetNextActivity(ctx context.Context, afterID int64) (ActivityLog, error) {
+	row := q.queryRow(ctx, q.getNextActivityStmt, getNextActivity, afterID)
+	var i ActivityLog
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.Actor,
+		&i.Action,
+		&i.Diff,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOldestDescriptionRevision = `-- name: GetOldestDescriptionRevision :one
+SELECT CAST(COALESCE(MIN(revision), 0) AS INTEGER) AS revision
+FROM todo_description_ops
+WHERE todo_id = ?
+`
+
+func (q *Queries) GetOldestDescriptionRevision(ctx context.Context, todoID int64) (int64, error) {
+	row := q.queryRow(ctx, q.getOldestDescriptionRevisionStmt, getOldestDescriptionRevision, todoID)
+	var revision int64
+	err := row.Scan(&revision)
+	return revision, err
+}
+
+const getPendingInvitationByEmail = `-- name: GetPendingInvitationByEmail :one
+SELECT id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, created_at
+FROM invitations
+WHERE email = ?1 AND accepted_at IS NULL AND expires_at > ?2
+ORDER BY id DESC
+LIMIT 1
+`
+
+type GetPendingInvitationByEmailParams struct {
+	Email string    `json:"email"`
+	Now   time.Time `json:"now"`
+}
+
+// OIDCで確認済みのメールアドレスを、トークンなしで招待と照合する
+func (q *Queries) GetPendingInvitationByEmail(ctx context.Context, arg GetPendingInvitationByEmailParams) (Invitation, error) {
+	row := q.queryRow(ctx, q.getPendingInvitationByEmailStmt, getPendingInvitationByEmail, arg.Email, arg.Now)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Role,
+		&i.TokenHash,
+		&i.InvitedBy,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedUserID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getProject = `-- name: GetProject :one
+SELECT id, name, description, archived_at, open_count, completed_count, metadata_schema, created_at, updated_at, wip_limit
+FROM projects
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetProject(ctx context.Context, id int64) (Project, error) {
+	row := q.queryRow(ctx, q.getProjectStmt, getProject, id)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.ArchivedAt,
+		&i.OpenCount,
+		&i.CompletedCount,
+		&i.MetadataSchema,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.WipLimit,
+	)
+	return i, err
+}
+
+const getProjectChatChannel = `-- name: GetProjectChatChannel :one
+SELECT project_id, provider, webhook_url, events, templates, created_at, updated_at
+FROM project_chat_channels
+WHERE project_id = ? AND provider = ?
+`
+
+type GetProjectChatChannelParams struct {
+	ProjectID int64  `json:"project_id"`
+	Provider  string `json:"provider"`
+}
+
+func (q *Queries) GetProjectChatChannel(ctx context.Context, arg GetProjectChatChannelParams) (ProjectChatChannel, error) {
+	row := q.queryRow(ctx, q.getProjectChatChannelStmt, getProjectChatChannel, arg.ProjectID, arg.Provider)
+	var i ProjectChatChannel
+	err := row.Scan(
+		&i.ProjectID,
+		&i.Provider,
+		&i.WebhookUrl,
+		&i.Events,
+		&i.Templates,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getResourceLock = `-- name: GetResourceLock :one
+SELECT l.resource_type, l.resource_id, l.holder_id, u.name AS holder_name, l.acquired_at, l.expires_at
+FROM resource_locks l
+JOIN users u ON u.id = l.holder_id
+WHERE l.resource_type = ?1 AND l.resource_id = ?2 AND l.expires_at > ?3
+`
+
+type GetResourceLockParams struct {
+	ResourceType string    `json:"resource_type"`
+	ResourceID   int64     `json:"resource_id"`
+	Now          time.Time `json:"now"`
+}
+
+type GetResourceLockRow struct {
+	ResourceType string    `json:"resource_type"`
+	ResourceID   int64     `json:"resource_id"`
+	HolderID     int64     `json:"holder_id"`
+	HolderName   string    `json:"holder_name"`
+	AcquiredAt   time.Time `json:"acquired_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (q *Queries) GetResourceLock(ctx context.Context, arg GetResourceLockParams) (GetResourceLockRow, error) {
+	row := q.queryRow(ctx, q.getResourceLockStmt, getResourceLock, arg.ResourceType, arg.ResourceID, arg.Now)
+	var i GetResourceLockRow
+	err := row.Scan(
+		&i.ResourceType,
+		&i.ResourceID,
+		&i.HolderID,
+		&i.HolderName,
+		&i.AcquiredAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getSavedFilter = `-- name: GetSavedFilter :one
+SELECT id, name, completed, near, radius, created_at
+FROM saved_filters
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetSavedFilter(ctx context.Context, id int64) (SavedFilter, error) {
+	row := q.queryRow(ctx, q.getSavedFilterStmt, getSavedFilter, id)
+	var i SavedFilter
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Completed,
+		&i.Near,
+		&i.Radius,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSubtask = `-- name: GetSubtask :one
+SELECT id, todo_id, title, completed, created_at, updated_at
+FROM subtasks
+WHERE id = ? AND todo_id = ? LIMIT 1
+`
+
+type GetSubtaskParams struct {
+	ID     int64 `json:"id"`
+	TodoID int64 `json:"todo_id"`
+}
+
+func (q *Queries) GetSubtask(ctx context.Context, arg GetSubtaskParams) (Subtask, error) {
+	row := q.queryRow(ctx, q.getSubtaskStmt, getSubtask, arg.ID, arg.TodoID)
+	var i Subtask
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.Title,
+		&i.Completed,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTag = `-- name: GetTag :one
+SELECT id, owner_id, name, created_at
+FROM tags
+WHERE id = ? AND owner_id IS ?
+`
+
+type GetTagParams struct {
+	ID      int64         `json:"id"`
+	OwnerID sql.NullInt64 `json:"owner_id"`
+}
+
+func (q *Queries) GetTag(ctx context.Context, arg GetTagParams) (Tag, error) {
+	row := q.queryRow(ctx, q.getTagStmt, getTag, arg.ID, arg.OwnerID)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTagByName = `-- name: GetTagByName :one
+SELECT id, owner_id, name, created_at
+FROM tags
+WHERE owner_id IS ? AND name = ?
+`
+
+type GetTagByNameParams struct {
+	OwnerID sql.NullInt64 `json:"owner_id"`
+	Name    string        `json:"name"`
+}
+
+func (q *Queries) GetTagByName(ctx context.Context, arg GetTagByNameParams) (Tag, error) {
+	row := q.queryRow(ctx, q.getTagByNameStmt, getTagByName, arg.OwnerID, arg.Name)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTodo = `-- name: GetTodo :one
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE id = ?1
+  AND (owner_id IS ?2 OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = ?2
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+          AND (CAST(?3 AS INTEGER) = 0 OR s.permission = 'write')
+    ))
+LIMIT 1
+`
+
+type GetTodoParams struct {
+	ID       int64         `json:"id"`
+	UserID   sql.NullInt64 `json:"user_id"`
+	ForWrite int64         `json:"for_write"`
+}
+
+func (q *Queries) GetTodo(ctx context.Context, arg GetTodoParams) (Todo, error) {
+	row := q.queryRow(ctx, q.getTodoStmt, getTodo, arg.ID, arg.UserID, arg.ForWrite)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Completed,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.SubtaskCount,
+		&i.SubtaskCompletedCount,
+		&i.ProjectID,
+		&i.DueAt,
+		&i.Recurrence,
+		&i.NextTodoID,
+		&i.Position,
+		&i.Version,
+		&i.Assignee,
+		&i.OwnerID,
+		&i.Metadata,
+		&i.Priority,
+		&i.EscalationLevel,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Translations,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const getTodoByID = `-- name: GetTodoByID :one
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE id = ?
+`
+
+func (q *Queries) GetTodoByID(ctx context.Context, id int64) (Todo, error) {
+	row := q.queryRow(ctx, q.getTodoByIDStmt, getTodoByID, id)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Completed,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.SubtaskCount,
+		&i.SubtaskCompletedCount,
+		&i.ProjectID,
+		&i.DueAt,
+		&i.Recurrence,
+		&i.NextTodoID,
+		&i.Position,
+		&i.Version,
+		&i.Assignee,
+		&i.OwnerID,
+		&i.Metadata,
+		&i.Priority,
+		&i.EscalationLevel,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Translations,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const getTodoDefaults = `-- name: GetTodoDefaults :one
+SELECT id, project_id, priority, due_offset_seconds, metadata, updated_at, tags
+FROM todo_defaults
+WHERE project_id IS ?1
+`
+
+func (q *Queries) GetTodoDefaults(ctx context.Context, projectID sql.NullInt64) (TodoDefault, error) {
+	row := q.queryRow(ctx, q.getTodoDefaultsStmt, getTodoDefaults, projectID)
+	var i TodoDefault
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Priority,
+		&i.DueOffsetSeconds,
+		&i.Metadata,
+		&i.UpdatedAt,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, email, name, password_hash, role, created_at
+FROM users
+WHERE id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetUser(ctx context.Context, id int64) (User, error) {
+	row := q.queryRow(ctx, q.getUserStmt, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.PasswordHash,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, email, name, password_hash, role, created_at
+FROM users
+WHERE email = ?
+LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.queryRow(ctx, q.getUserByEmailStmt, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.PasswordHash,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserByIdentity = `-- name: GetUserByIdentity :one
+SELECT users.id, users.email, users.name, users.password_hash, users.role, users.created_at
+FROM users
+JOIN user_identities ON user_identities.user_id = users.id
+WHERE user_identities.issuer = ? AND user_identities.subject = ?
+LIMIT 1
+`
+
+type GetUserByIdentityParams struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) GetUserByIdentity(ctx context.Context, arg GetUserByIdentityParams) (User, error) {
+	row := q.queryRow(ctx, q.getUserByIdentityStmt, getUserByIdentity, arg.Issuer, arg.Subject)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.PasswordHash,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWebhook = `-- name: GetWebhook :one
+SELECT id, owner_id, url, events, secret, active, created_at, updated_at
+FROM webhooks
+WHERE id = ?1 AND owner_id IS ?2
+`
+
+type GetWebhookParams struct {
+	ID      int64         `json:"id"`
+	OwnerID sql.NullInt64 `json:"owner_id"`
+}
+
+func (q *Queries) GetWebhook(ctx context.Context, arg GetWebhookParams) (Webhook, error) {
+	row := q.queryRow(ctx, q.getWebhookStmt, getWebhook, arg.ID, arg.OwnerID)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Url,
+		&i.Events,
+		&i.Secret,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const hasRecentAuthEvent = `-- name: HasRecentAuthEvent :one
+SELECT EXISTS (
+    SELECT 1 FROM auth_events
+    WHERE subject = ?1
+      AND event_type = ?2
+      AND ip = ?3
+      AND user_agent = ?4
+      AND created_at >= ?5
+)
+`
+
+type HasRecentAuthEventParams struct {
+	Subject   string    `json:"subject"`
+	EventType string    `json:"event_type"`
+	Ip        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Since     time.Time `json:"since"`
+}
+
+func (q *Queries) HasRecentAuthEvent(ctx context.Context, arg HasRecentAuthEventParams) (int64, error) {
+	row := q.queryRow(ctx, q.hasRecentAuthEventStmt, hasRecentAuthEvent,
+		arg.Subject,
+		arg.EventType,
+		arg.Ip,
+		arg.UserAgent,
+		arg.Since,
+	)
+	var exists int64
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listAPIKeys = `-- name: ListAPIKeys :many
+SELECT id, name, prefix, key_hash, created_by, last_used_at, revoked_at, created_at
+FROM api_keys
+WHERE revoked_at IS NULL OR CAST(?1 AS INTEGER) = 1
+ORDER BY id
+`
+
+func (q *Queries) ListAPIKeys(ctx context.Context, includeRevoked int64) ([]ApiKey, error) {
+	rows, err := q.query(ctx, q.listAPIKeysStmt, listAPIKeys, includeRevoked)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.CreatedBy,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActiveWebhooksForOwner = `-- name: ListActiveWebhooksForOwner :many
+SELECT id, owner_id, url, events, secret, active, created_at, updated_at
+FROM webhooks
+WHERE owner_id IS ?1 AND active = 1
+ORDER BY id
+`
+
+func (q *Queries) ListActiveWebhooksForOwner(ctx context.Context, ownerID sql.NullInt64) ([]Webhook, error) {
+	rows, err := q.query(ctx, q.listActiveWebhooksForOwnerStmt, listActiveWebhooksForOwner, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.Url,
+			&i.Events,
+			&i.Secret,
+			&i.Active,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActivity = `-- name: ListActivity :many
+SELECT id, todo_id, actor, action, diff, created_at
+FROM activity_log
+WHERE ?1 = 0 OR id < ?1
+ORDER BY id DESC
+LIMIT ?2
+`
+
+type ListActivityParams struct {
+	BeforeID int64 `json:"before_id"`
+	Limit    int64 `json:"limit"`
+}
+
+func (q *Queries) ListActivity(ctx context.Context, arg ListActivityParams) ([]ActivityLog, error) {
+	rows, err := q.query(ctx, q.listActivityStmt, listActivity, arg.BeforeID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ActivityLog
+	for rows.Next() {
+		var i ActivityLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.Actor,
+			&i.Action,
+			&i.Diff,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAgendaReminders = `-- name: ListAgendaReminders :many
+SELECT reminders.id, reminders.todo_id, reminders.remind_at, reminders.channel, reminders.sent_at, todos.title, todos.completed
+FROM reminders
+JOIN todos ON todos.id = reminders.todo_id
+WHERE todos.owner_id IS ?1 AND todos.archived_at IS NULL
+  AND reminders.remind_at >= ?2 AND reminders.remind_at < ?3
+ORDER BY reminders.remind_at, reminders.id
+`
+
+type ListAgendaRemindersParams struct {
+	OwnerID  sql.NullInt64 `json:"owner_id"`
+	DayStart time.Time     `json:"day_start"`
+	DayEnd   time.Time     `json:"day_end"`
+}
+
+type ListAgendaRemindersRow struct {
+	ID        int64        `json:"id"`
+	TodoID    int64        `json:"todo_id"`
+	RemindAt  time.Time    `json:"remind_at"`
+	Channel   string       `json:"channel"`
+	SentAt    sql.NullTime `json:"sent_at"`
+	Title     string       `json:"title"`
+	Completed int64        `json:"completed"`
+}
+
+func (q *Queries) ListAgendaReminders(ctx context.Context, arg ListAgendaRemindersParams) ([]ListAgendaRemindersRow, error) {
+	rows, err := q.query(ctx, q.listAgendaRemindersStmt, listAgendaReminders, arg.OwnerID, arg.DayStart, arg.DayEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAgendaRemindersRow
+	for rows.Next() {
+		var i ListAgendaRemindersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.RemindAt,
+			&i.Channel,
+			&i.SentAt,
+			&i.Title,
+			&i.Completed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAgendaTodos = `-- name: ListAgendaTodos :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE owner_id IS ?1 AND archived_at IS NULL AND due_at >= ?2 AND due_at < ?3
+  AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+ORDER BY due_at, id
+`
+
+type ListAgendaTodosParams struct {
+	OwnerID  sql.NullInt64 `json:"owner_id"`
+	DayStart sql.NullTime  `json:"day_start"`
+	DayEnd   sql.NullTime  `json:"day_end"`
+}
+
+func (q *Queries) ListAgendaTodos(ctx context.Context, arg ListAgendaTodosParams) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listAgendaTodosStmt, listAgendaTodos, arg.OwnerID, arg.DayStart, arg.DayEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAttachments = `-- name: ListAttachments :many
+SELECT id, todo_id, filename, content_type, size, storage_key, created_at
+FROM attachments
+WHERE todo_id = ?
+ORDER BY id
+`
+
+func (q *Queries) ListAttachments(ctx context.Context, todoID int64) ([]Attachment, error) {
+	rows, err := q.query(ctx, q.listAttachmentsStmt, listAttachments, todoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Attachment
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.Filename,
+			&i.ContentType,
+			&i.Size,
+			&i.StorageKey,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAttachmentsByTodoIDs = `-- name: ListAttachmentsByTodoIDs :many
+SELECT id, todo_id, filename, content_type, size, storage_key, created_at
+FROM attachments
+WHERE todo_id IN (/*SLICE:todo_ids*/?)
+ORDER BY todo_id, id
+`
+
+func (q *Queries) ListAttachmentsByTodoIDs(ctx context.Context, todoIds []int64) ([]Attachment, error) {
+	query := listAttachmentsByTodoIDs
+	var queryParams []interface{}
+	if len(todoIds) > 0 {
+		for _, v := range todoIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", strings.Repeat(",?", len(todoIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Attachment
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.Filename,
+			&i.ContentType,
+			&i.Size,
+			&i.StorageKey,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuthEvents = `-- name: ListAuthEvents :many
+SELECT id, subject, event_type, ip, user_agent, created_at
+FROM auth_events
+WHERE subject = ?
+ORDER BY id DESC
+LIMIT ?
+`
+
+type ListAuthEventsParams struct {
+	Subject string `json:"subject"`
+	Limit   int64  `json:"limit"`
+}
+
+func (q *Queries) ListAuthEvents(ctx context.Context, arg ListAuthEventsParams) ([]AuthEvent, error) {
+	rows, err := q.query(ctx, q.listAuthEventsStmt, listAuthEvents, arg.Subject, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuthEvent
+	for rows.Next() {
+		var i AuthEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Subject,
+			&i.EventType,
+			&i.Ip,
+			&i.UserAgent,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChatOverdueDeliveries = `-- name: ListChatOverdueDeliveries :many
+SELECT t.id AS todo_id, t.title, t.description, t.assignee, t.due_at, p.name AS project_name,
+       c.provider, c.webhook_url, c.templates
+FROM todos t
+JOIN project_chat_channels c ON c.project_id = t.project_id
+JOIN projects p ON p.id = t.project_id
+LEFT JOIN chat_overdue_posts o ON o.todo_id = t.id AND o.due_at = t.due_at
+WHERE t.completed = 0 AND t.archived_at IS NULL AND t.due_at IS NOT NULL
+  AND t.due_at > ?1 AND t.due_at <= ?2
+  AND o.todo_id IS NULL
+  AND EXISTS (SELECT 1 FROM json_each(c.events) WHERE json_each.value = 'todo.overdue')
+ORDER BY t.due_at, t.id, c.provider
+`
+
+type ListChatOverdueDeliveriesParams struct {
+	Since sql.NullTime `json:"since"`
+	Now   sql.NullTime `json:"now"`
+}
+
+type ListChatOverdueDeliveriesRow struct {
+	TodoID      int64          `json:"todo_id"`
+	Title       string         `json:"title"`
+	Description sql.NullString `json:"description"`
+	Assignee    sql.NullString `json:"assignee"`
+	DueAt       sql.NullTime   `json:"due_at"`
+	ProjectName string         `json:"project_name"`
+	Provider    string         `json:"provider"`
+	WebhookUrl  string         `json:"webhook_url"`
+	Templates   string         `json:"templates"`
+}
+
+// sinceからnowまでに期限を過ぎた未完了のTodoのうち、その期限の期限切れをまだ投稿していないものと、
+// 期限切れを購読しているチャットの送信先を取得する
+func (q *Queries) ListChatOverdueDeliveries(ctx context.Context, arg ListChatOverdueDeliveriesParams) ([]ListChatOverdueDeliveriesRow, error) {
+	rows, err := q.query(ctx, q.listChatOverdueDeliveriesStmt, listChatOverdueDeliveries, arg.Since, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChatOverdueDeliveriesRow
+	for rows.Next() {
+		var i ListChatOverdueDeliveriesRow
+		if err := rows.Scan(
+			&i.TodoID,
+			&i.Title,
+			&i.Description,
+			&i.Assignee,
+			&i.DueAt,
+			&i.ProjectName,
+			&i.Provider,
+			&i.WebhookUrl,
+			&i.Templates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listComments = `-- name: ListComments :many
+SELECT id, todo_id, author, body, created_at
+FROM comments
+WHERE todo_id = ?1 AND id > ?2
+ORDER BY id
+LIMIT ?3
+`
+
+type ListCommentsParams struct {
+	TodoID  int64 `json:"todo_id"`
+	AfterID int64 `json:"after_id"`
+	Limit   int64 `json:"limit"`
+}
+
+func (q *Queries) ListComments(ctx context.Context, arg ListCommentsParams) ([]Comment, error) {
+	rows, err := q.query(ctx, q.listCommentsStmt, listComments, arg.TodoID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Comment
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.Author,
+			&i.Body,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommentsByProject = `-- name: ListCommentsByProject :many
+SELECT comments.id, comments.todo_id, comments.author, comments.body, comments.created_at
+FROM comments
+JOIN todos ON todos.id = comments.todo_id
+WHERE todos.project_id = ?
+ORDER BY comments.id
+`
+
+func (q *Queries) ListCommentsByProject(ctx context.Context, projectID sql.NullInt64) ([]Comment, error) {
+	rows, err := q.query(ctx, q.listCommentsByProjectStmt, listCommentsByProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Comment
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.Author,
+			&i.Body,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommentsByTodoIDs = `-- name: ListCommentsByTodoIDs :many
+SELECT id, todo_id, author, body, created_at
+FROM comments
+WHERE todo_id IN (/*SLICE:todo_ids*/?)
+ORDER BY todo_id, id
+`
+
+func (q *Queries) ListCommentsByTodoIDs(ctx context.Context, todoIds []int64) ([]Comment, error) {
+	query := listCommentsByTodoIDs
+	var queryParams []interface{}
+	if len(todoIds) > 0 {
+		for _, v := range todoIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", strings.Repeat(",?", len(todoIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Comment
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.Author,
+			&i.Body,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompletedTodoIDs = `-- name: ListCompletedTodoIDs :many
+SELECT id FROM todos
+WHERE completed = 1 AND owner_id IS ?
+ORDER BY id
+`
+
+// DeleteCompletedTodosで削除されるTodoのID
+func (q *Queries) ListCompletedTodoIDs(ctx context.Context, ownerID sql.NullInt64) ([]int64, error) {
+	rows, err := q.query(ctx, q.listCompletedTodoIDsStmt, listCompletedTodoIDs, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDescriptionOps = `-- name: ListDescriptionOps :many
+SELECT todo_id, revision, ops, description, actor, created_at
+FROM todo_description_ops
+WHERE todo_id = ? AND revision > ?
+ORDER BY revision
+`
+
+type ListDescriptionOpsParams struct {
+	TodoID   int64 `json:"todo_id"`
+	Revision int64 `json:"revision"`
+}
+
+func (q *Queries) ListDescriptionOps(ctx context.Context, arg ListDescriptionOpsParams) ([]TodoDescriptionOp, error) {
+	rows, err := q.query(ctx, q.listDescriptionOpsStmt, listDescriptionOps, arg.TodoID, arg.Revision)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TodoDescriptionOp
+	for rows.Next() {
+		var i TodoDescriptionOp
+		if err := rows.Scan(
+			&i.TodoID,
+			&i.Revision,
+			&i.Ops,
+			&i.Description,
+			&i.Actor,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueDigestTodos = `-- name: ListDueDigestTodos :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE completed = 0 AND archived_at IS NULL AND owner_id IS NOT NULL AND due_at < ?1
+  AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND NOT EXISTS (SELECT 1 FROM due_digests d WHERE d.owner_id = todos.owner_id AND d.digest_date = ?2)
+ORDER BY owner_id, due_at, id
+`
+
+type ListDueDigestTodosParams struct {
+	DayEnd     sql.NullTime `json:"day_end"`
+	DigestDate string       `json:"digest_date"`
+}
+
+// 期限が今日までの未完了のTodoを、その日のまとめをまだ送っていない所有者ごとに期限の順で返す
+func (q *Queries) ListDueDigestTodos(ctx context.Context, arg ListDueDigestTodosParams) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listDueDigestTodosStmt, listDueDigestTodos, arg.DayEnd, arg.DigestDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueReminders = `-- name: ListDueReminders :many
+SELECT reminders.id, reminders.todo_id, reminders.remind_at, reminders.channel, todos.title, todos.owner_id, users.email AS owner_email
+FROM reminders
+JOIN todos ON todos.id = reminders.todo_id
+LEFT JOIN users ON users.id = todos.owner_id
+WHERE reminders.sent_at IS NULL AND reminders.remind_at <= ?1
+ORDER BY reminders.remind_at, reminders.id
+`
+
+type ListDueRemindersRow struct {
+	ID         int64          `json:"id"`
+	TodoID     int64          `json:"todo_id"`
+	RemindAt   time.Time      `json:"remind_at"`
+	Channel    string         `json:"channel"`
+	Title      string         `json:"title"`
+	OwnerID    sql.NullInt64  `json:"owner_id"`
+	OwnerEmail sql.NullString `json:"owner_email"`
+}
+
+func (q *Queries) ListDueReminders(ctx context.Context, now time.Time) ([]ListDueRemindersRow, error) {
+	rows, err := q.query(ctx, q.listDueRemindersStmt, listDueReminders, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDueRemindersRow
+	for rows.Next() {
+		var i ListDueRemindersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.RemindAt,
+			&i.Channel,
+			&i.Title,
+			&i.OwnerID,
+			&i.OwnerEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueWebhookDeliveries = `-- name: ListDueWebhookDeliveries :many
+SELECT d.id, d.webhook_id, d.event, d.todo_id, d.payload, d.attempts, w.url, w.secret
+FROM webhook_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+WHERE d.status = 'pending' AND d.next_attempt_at <= ?1
+ORDER BY d.next_attempt_at
+LIMIT ?2
+`
+
+type ListDueWebhookDeliveriesParams struct {
+	Now   time.Time `json:"now"`
+	Limit int64     `json:"limit"`
+}
+
+type ListDueWebhookDeliveriesRow struct {
+	ID        int64  `json:"id"`
+	WebhookID int64  `json:"webhook_id"`
+	Event     string `json:"event"`
+	TodoID    int64  `json:"todo_id"`
+	Payload   string `json:"payload"`
+	Attempts  int64  `json:"attempts"`
+	Url       string `json:"url"`
+	Secret    string `json:"secret"`
+}
+
+func (q *Queries) ListDueWebhookDeliveries(ctx context.Context, arg ListDueWebhookDeliveriesParams) ([]ListDueWebhookDeliveriesRow, error) {
+	rows, err := q.query(ctx, q.listDueWebhookDeliveriesStmt, listDueWebhookDeliveries, arg.Now, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDueWebhookDeliveriesRow
+	for rows.Next() {
+		var i ListDueWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.Event,
+			&i.TodoID,
+			&i.Payload,
+			&i.Attempts,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImportJobs = `-- name: ListImportJobs :many
+SELECT id, source, status, dry_run, actor, result, error, created_at, finished_at
+FROM import_jobs
+ORDER BY id DESC
+LIMIT ?
+`
+
+func (q *Queries) ListImportJobs(ctx context.Context, limit int64) ([]ImportJob, error) {
+	rows, err := q.query(ctx, q.listImportJobsStmt, listImportJobs, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ImportJob
+	for rows.Next() {
+		var i ImportJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Source,
+			&i.Status,
+			&i.DryRun,
+			&i.Actor,
+			&i.Result,
+			&i.Error,
+			&i.CreatedAt,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInvitations = `-- name: ListInvitations :many
+SELECT id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, created_at
+FROM invitations
+ORDER BY id DESC
+LIMIT ?
+`
+
+func (q *Queries) ListInvitations(ctx context.Context, limit int64) ([]Invitation, error) {
+	rows, err := q.query(ctx, q.listInvitationsStmt, listInvitations, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Invitation
+	for rows.Next() {
+		var i Invitation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Role,
+			&i.TokenHash,
+			&i.InvitedBy,
+			&i.ExpiresAt,
+			&i.AcceptedAt,
+			&i.AcceptedUserID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOperationUsage = `-- name: ListOperationUsage :many
+SELECT operation_id, calls, last_used_at, updated_at
+FROM operation_usage
+ORDER BY operation_id
+`
+
+func (q *Queries) ListOperationUsage(ctx context.Context) ([]OperationUsage, error) {
+	rows, err := q.query(ctx, q.listOperationUsageStmt, listOperationUsage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OperationUsage
+	for rows.Next() {
+		var i OperationUsage
+		if err := rows.Scan(
+			&i.OperationID,
+			&i.Calls,
+			&i.LastUsedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverdueTodos = `-- name: ListOverdueTodos :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE completed = 0 AND archived_at IS NULL AND due_at IS NOT NULL AND due_at < ?1 AND escalation_level < ?2
+ORDER BY due_at, id
+`
+
+type ListOverdueTodosParams struct {
+	Now      sql.NullTime `json:"now"`
+	MaxLevel int64        `json:"max_level"`
+}
+
+func (q *Queries) ListOverdueTodos(ctx context.Context, arg ListOverdueTodosParams) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listOverdueTodosStmt, listOverdueTodos, arg.Now, arg.MaxLevel)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOwnedTodoIDs = `-- name: ListOwnedTodoIDs :many
+SELECT id FROM todos
+WHERE owner_id IS ? AND id IN (/*SLICE:ids*/?)
+ORDER BY id
+`
+
+type ListOwnedTodoIDsParams struct {
+	OwnerID sql.NullInt64 `json:"owner_id"`
+	Ids     []int64       `json:"ids"`
+}
+
+// DeleteTodosByIDsで削除されるTodoのID
+func (q *Queries) ListOwnedTodoIDs(ctx context.Context, arg ListOwnedTodoIDsParams) ([]int64, error) {
+	query := listOwnedTodoIDs
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.OwnerID)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingOutboxEvents = `-- name: ListPendingOutboxEvents :many
+SELECT id, type, todo_id, owner_id, completed, occurred_at, attempts, last_error, published_at, comment_id
+FROM outbox
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT ?
+`
+
+func (q *Queries) ListPendingOutboxEvents(ctx context.Context, limit int64) ([]Outbox, error) {
+	rows, err := q.query(ctx, q.listPendingOutboxEventsStmt, listPendingOutboxEvents, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.TodoID,
+			&i.OwnerID,
+			&i.Completed,
+			&i.OccurredAt,
+			&i.Attempts,
+			&i.LastError,
+			&i.PublishedAt,
+			&i.CommentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingRecurrences = `-- name: ListPendingRecurrences :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE completed = 1 AND recurrence IS NOT NULL AND next_todo_id IS NULL
+ORDER BY id
+`
+
+func (q *Queries) ListPendingRecurrences(ctx context.Context) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listPendingRecurrencesStmt, listPendingRecurrences)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingRecurringTodos = `-- name: ListPendingRecurringTodos :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE owner_id IS ?1 AND completed = 0 AND archived_at IS NULL
+  AND recurrence IS NOT NULL AND recurrence != '' AND next_todo_id IS NULL AND due_at < ?2
+  AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+ORDER BY due_at, id
+`
+
+type ListPendingRecurringTodosParams struct {
+	OwnerID sql.NullInt64 `json:"owner_id"`
+	DayEnd  sql.NullTime  `json:"day_end"`
+}
+
+func (q *Queries) ListPendingRecurringTodos(ctx context.Context, arg ListPendingRecurringTodosParams) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listPendingRecurringTodosStmt, listPendingRecurringTodos, arg.OwnerID, arg.DayEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectChatChannels = `-- name: ListProjectChatChannels :many
+SELECT project_id, provider, webhook_url, events, templates, created_at, updated_at
+FROM project_chat_channels
+WHERE project_id = ?
+ORDER BY provider
+`
+
+func (q *Queries) ListProjectChatChannels(ctx context.Context, projectID int64) ([]ProjectChatChannel, error) {
+	rows, err := q.query(ctx, q.listProjectChatChannelsStmt, listProjectChatChannels, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProjectChatChannel
+	for rows.Next() {
+		var i ProjectChatChannel
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.Provider,
+			&i.WebhookUrl,
+			&i.Events,
+			&i.Templates,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectShares = `-- name: ListProjectShares :many
+SELECT todo_shares.id, todo_shares.user_id, users.email, todo_shares.permission, todo_shares.created_at
+FROM todo_shares
+JOIN users ON users.id = todo_shares.user_id
+WHERE todo_shares.project_id = ? AND todo_shares.shared_by = ?
+ORDER BY todo_shares.id
+`
+
+type ListProjectSharesParams struct {
+	ProjectID sql.NullInt64 `json:"project_id"`
+	SharedBy  int64         `json:"shared_by"`
+}
+
+type ListProjectSharesRow struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	Email      string    `json:"email"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListProjectShares(ctx context.Context, arg ListProjectSharesParams) ([]ListProjectSharesRow, error) {
+	rows, err := q.query(ctx, q.listProjectSharesStmt, listProjectShares, arg.ProjectID, arg.SharedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProjectSharesRow
+	for rows.Next() {
+		var i ListProjectSharesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Email,
+			&i.Permission,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjects = `-- name: ListProjects :many
+SELECT id, name, description, archived_at, open_count, completed_count, metadata_schema, created_at, updated_at, wip_limit
+FROM projects
+WHERE archived_at IS NULL OR CAST(?1 AS INTEGER) = 1
+ORDER BY id
+`
+
+func (q *Queries) ListProjects(ctx context.Context, includeArchived int64) ([]Project, error) {
+	rows, err := q.query(ctx, q.listProjectsStmt, listProjects, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.ArchivedAt,
+			&i.OpenCount,
+			&i.CompletedCount,
+			&i.MetadataSchema,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.WipLimit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectsByIDs = `-- name: ListProjectsByIDs :many
+SELECT id, name, description, archived_at, open_count, completed_count, metadata_schema, created_at, updated_at, wip_limit
+FROM projects
+WHERE id IN (/*SLICE:ids*/?)
+ORDER BY id
+`
+
+func (q *Queries) ListProjectsByIDs(ctx context.Context, ids []int64) ([]Project, error) {
+	query := listProjectsByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.ArchivedAt,
+			&i.OpenCount,
+			&i.CompletedCount,
+			&i.MetadataSchema,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.WipLimit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReminders = `-- name: ListReminders :many
+SELECT id, todo_id, remind_at, channel, sent_at, created_at
+FROM reminders
+WHERE todo_id = ?
+ORDER BY remind_at, id
+`
+
+func (q *Queries) ListReminders(ctx context.Context, todoID int64) ([]Reminder, error) {
+	rows, err := q.query(ctx, q.listRemindersStmt, listReminders, todoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Reminder
+	for rows.Next() {
+		var i Reminder
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.RemindAt,
+			&i.Channel,
+			&i.SentAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRemindersByTodoIDs = `-- name: ListRemindersByTodoIDs :many
+SELECT id, todo_id, remind_at, channel, sent_at, created_at
+FROM reminders
+WHERE todo_id IN (/*SLICE:todo_ids*/?)
+ORDER BY todo_id, remind_at, id
+`
+
+func (q *Queries) ListRemindersByTodoIDs(ctx context.Context, todoIds []int64) ([]Reminder, error) {
+	query := listRemindersByTodoIDs
+	var queryParams []interface{}
+	if len(todoIds) > 0 {
+		for _, v := range todoIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", strings.Repeat(",?", len(todoIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Reminder
+	for rows.Next() {
+		var i Reminder
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.RemindAt,
+			&i.Channel,
+			&i.SentAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSavedFilters = `-- name: ListSavedFilters :many
+SELECT id, name, completed, near, radius, created_at
+FROM saved_filters
+ORDER BY id
+`
+
+func (q *Queries) ListSavedFilters(ctx context.Context) ([]SavedFilter, error) {
+	rows, err := q.query(ctx, q.listSavedFiltersStmt, listSavedFilters)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SavedFilter
+	for rows.Next() {
+		var i SavedFilter
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Completed,
+			&i.Near,
+			&i.Radius,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStaleTodos = `-- name: ListStaleTodos :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE completed = 0 AND archived_at IS NULL AND updated_at < ?1
+  AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND NOT EXISTS (SELECT 1 FROM todo_snoozes z WHERE z.todo_id = todos.id AND z.snoozed_until > ?2)
+ORDER BY owner_id, project_id, updated_at, id
+`
+
+type ListStaleTodosParams struct {
+	Before time.Time `json:"before"`
+	Now    time.Time `json:"now"`
+}
+
+func (q *Queries) ListStaleTodos(ctx context.Context, arg ListStaleTodosParams) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listStaleTodosStmt, listStaleTodos, arg.Before, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubtasks = `-- name: ListSubtasks :many
+SELECT id, todo_id, title, completed, created_at, updated_at
+FROM subtasks
+WHERE todo_id = ?
+ORDER BY id
+`
+
+func (q *Queries) ListSubtasks(ctx context.Context, todoID int64) ([]Subtask, error) {
+	rows, err := q.query(ctx, q.listSubtasksStmt, listSubtasks, todoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subtask
+	for rows.Next() {
+		var i Subtask
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.Title,
+			&i.Completed,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubtasksByProject = `-- name: ListSubtasksByProject :many
+SELECT subtasks.id, subtasks.todo_id, subtasks.title, subtasks.completed, subtasks.created_at, subtasks.updated_at
+FROM subtasks
+JOIN todos ON todos.id = subtasks.todo_id
+WHERE todos.project_id = ?
+ORDER BY subtasks.id
+`
+
+func (q *Queries) ListSubtasksByProject(ctx context.Context, projectID sql.NullInt64) ([]Subtask, error) {
+	rows, err := q.query(ctx, q.listSubtasksByProjectStmt, listSubtasksByProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subtask
+	for rows.Next() {
+		var i Subtask
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.Title,
+			&i.Completed,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubtasksByTodoIDs = `-- name: ListSubtasksByTodoIDs :many
+SELECT id, todo_id, title, completed, created_at, updated_at
+FROM subtasks
+WHERE todo_id IN (/*SLICE:todo_ids*/?)
+ORDER BY todo_id, id
+`
+
+func (q *Queries) ListSubtasksByTodoIDs(ctx context.Context, todoIds []int64) ([]Subtask, error) {
+	query := listSubtasksByTodoIDs
+	var queryParams []interface{}
+	if len(todoIds) > 0 {
+		for _, v := range todoIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", strings.Repeat(",?", len(todoIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subtask
+	for rows.Next() {
+		var i Subtask
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.Title,
+			&i.Completed,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagTodoIDs = `-- name: ListTagTodoIDs :many
+SELECT todo_id FROM todo_tags
+WHERE tag_id = ?
+ORDER BY todo_id
+`
+
+func (q *Queries) ListTagTodoIDs(ctx context.Context, tagID int64) ([]int64, error) {
+	rows, err := q.query(ctx, q.listTagTodoIDsStmt, listTagTodoIDs, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var todo_id int64
+		if err := rows.Scan(&todo_id); err != nil {
+			return nil, err
+		}
+		items = append(items, todo_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTags = `-- name: ListTags :many
+SELECT t.id, t.owner_id, t.name, t.created_at, CAST(COUNT(tt.todo_id) AS INTEGER) AS todo_count
+FROM tags t
+LEFT JOIN todo_tags tt ON tt.tag_id = t.id
+WHERE t.owner_id IS ?
+GROUP BY t.id
+ORDER BY t.name
+`
+
+type ListTagsRow struct {
+	ID        int64         `json:"id"`
+	OwnerID   sql.NullInt64 `json:"owner_id"`
+	Name      string        `json:"name"`
+	CreatedAt time.Time     `json:"created_at"`
+	TodoCount int64         `json:"todo_count"`
+}
+
+// 所有者のタグを、付けているTodoの件数とともに名前の順で返す
+func (q *Queries) ListTags(ctx context.Context, ownerID sql.NullInt64) ([]ListTagsRow, error) {
+	rows, err := q.query(ctx, q.listTagsStmt, listTags, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTagsRow
+	for rows.Next() {
+		var i ListTagsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.TodoCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodoActivity = `-- name: ListTodoActivity :many
+SELECT id, todo_id, actor, action, diff, created_at
+FROM activity_log
+WHERE todo_id = ?1 AND (?2 = 0 OR id < ?2)
+ORDER BY id DESC
+LIMIT ?3
+`
+
+type ListTodoActivityParams struct {
+	TodoID   int64 `json:"todo_id"`
+	BeforeID int64 `json:"before_id"`
+	Limit    int64 `json:"limit"`
+}
+
+func (q *Queries) ListTodoActivity(ctx context.Context, arg ListTodoActivityParams) ([]ActivityLog, error) {
+	rows, err := q.query(ctx, q.listTodoActivityStmt, listTodoActivity, arg.TodoID, arg.BeforeID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ActivityLog
+	for rows.Next() {
+		var i ActivityLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.TodoID,
+			&i.Actor,
+			&i.Action,
+			&i.Diff,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodoChatDeliveries = `-- name: ListTodoChatDeliveries :many
+SELECT c.provider, c.webhook_url, c.events, c.templates, t.title, t.description, t.assignee, t.due_at, p.name AS project_name
+FROM project_chat_channels c
+JOIN todos t ON t.project_id = c.project_id
+JOIN projects p ON p.id = c.project_id
+WHERE t.id = ?
+ORDER BY c.provider
+`
+
+type ListTodoChatDeliveriesRow struct {
+	Provider    string         `json:"provider"`
+	WebhookUrl  string         `json:"webhook_url"`
+	Events      string         `json:"events"`
+	Templates   string         `json:"templates"`
+	Title       string         `json:"title"`
+	Description sql.NullString `json:"description"`
+	Assignee    sql.NullString `json:"assignee"`
+	DueAt       sql.NullTime   `json:"due_at"`
+	ProjectName string         `json:"project_name"`
+}
+
+// Todoが属するプロジェクトに設定されたチャットの送信先とTodoの内容を取得する
+func (q *Queries) ListTodoChatDeliveries(ctx context.Context, id int64) ([]ListTodoChatDeliveriesRow, error) {
+	rows, err := q.query(ctx, q.listTodoChatDeliveriesStmt, listTodoChatDeliveries, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTodoChatDeliveriesRow
+	for rows.Next() {
+		var i ListTodoChatDeliveriesRow
+		if err := rows.Scan(
+			&i.Provider,
+			&i.WebhookUrl,
+			&i.Events,
+			&i.Templates,
+			&i.Title,
+			&i.Description,
+			&i.Assignee,
+			&i.DueAt,
+			&i.ProjectName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodoDefaultsForProject = `-- name: ListTodoDefaultsForProject :many
+SELECT id, project_id, priority, due_offset_seconds, metadata, updated_at, tags
+FROM todo_defaults
+WHERE project_id IS NULL OR project_id IS ?1
+`
+
+func (q *Queries) ListTodoDefaultsForProject(ctx context.Context, projectID sql.NullInt64) ([]TodoDefault, error) {
+	rows, err := q.query(ctx, q.listTodoDefaultsForProjectStmt, listTodoDefaultsForProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TodoDefault
+	for rows.Next() {
+		var i TodoDefault
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Priority,
+			&i.DueOffsetSeconds,
+			&i.Metadata,
+			&i.UpdatedAt,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodoIDsInProject = `-- name: ListTodoIDsInProject :many
+SELECT id
+FROM todos
+WHERE project_id IS ?
+ORDER BY position, created_at DESC, id DESC
+`
+
+func (q *Queries) ListTodoIDsInProject(ctx context.Context, projectID sql.NullInt64) ([]int64, error) {
+	rows, err := q.query(ctx, q.listTodoIDsInProjectStmt, listTodoIDsInProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodoLocks = `-- name: ListTodoLocks :many
+SELECT l.resource_id, l.holder_id, u.name AS holder_name, l.expires_at
+FROM resource_locks l
+JOIN users u ON u.id = l.holder_id
+WHERE l.resource_type = 'todo' AND l.resource_id IN (/*SLICE:ids*/?) AND l.expires_at > ?
+ORDER BY l.resource_id
+`
+
+type ListTodoLocksParams struct {
+	Ids []int64   `json:"ids"`
+	Now time.Time `json:"now"`
+}
+
+type ListTodoLocksRow struct {
+	ResourceID int64     `json:"resource_id"`
+	HolderID   int64     `json:"holder_id"`
+	HolderName string    `json:"holder_name"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func (q *Queries) ListTodoLocks(ctx context.Context, arg ListTodoLocksParams) ([]ListTodoLocksRow, error) {
+	query := listTodoLocks
+	var queryParams []interface{}
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.Now)
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTodoLocksRow
+	for rows.Next() {
+		var i ListTodoLocksRow
+		if err := rows.Scan(
+			&i.ResourceID,
+			&i.HolderID,
+			&i.HolderName,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodoShares = `-- name: ListTodoShares :many
+SELECT todo_shares.id, todo_shares.user_id, users.email, todo_shares.permission, todo_shares.created_at
+FROM todo_shares
+JOIN users ON users.id = todo_shares.user_id
+WHERE todo_shares.todo_id = ?
+ORDER BY todo_shares.id
+`
+
+type ListTodoSharesRow struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	Email      string    `json:"email"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListTodoShares(ctx context.Context, todoID sql.NullInt64) ([]ListTodoSharesRow, error) {
+	rows, err := q.query(ctx, q.listTodoSharesStmt, listTodoShares, todoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTodoSharesRow
+	for rows.Next() {
+		var i ListTodoSharesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Email,
+			&i.Permission,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodoTagNames = `-- name: ListTodoTagNames :many
+SELECT tt.todo_id, t.name
+FROM todo_tags tt
+JOIN tags t ON t.id = tt.tag_id
+WHERE tt.todo_id IN (/*SLICE:ids*/?)
+ORDER BY tt.todo_id, t.name
+`
+
+type ListTodoTagNamesRow struct {
+	TodoID int64  `json:"todo_id"`
+	Name   string `json:"name"`
+}
+
+func (q *Queries) ListTodoTagNames(ctx context.Context, ids []int64) ([]ListTodoTagNamesRow, error) {
+	query := listTodoTagNames
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTodoTagNamesRow
+	for rows.Next() {
+		var i ListTodoTagNamesRow
+		if err := rows.Scan(&i.TodoID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodoWatchersToNotify = `-- name: ListTodoWatchersToNotify :many
+SELECT users.id, users.email
+FROM todo_watchers
+JOIN users ON users.id = todo_watchers.user_id
+JOIN todos ON todos.id = todo_watchers.todo_id
+WHERE todo_watchers.todo_id = ?
+  AND (todos.owner_id = todo_watchers.user_id OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = todo_watchers.user_id
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+ORDER BY users.id
+`
+
+type ListTodoWatchersToNotifyRow struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+}
+
+// 共有を取り消されるなどして、Todoを閲覧できなくなったユーザーには通知しない
+func (q *Queries) ListTodoWatchersToNotify(ctx context.Context, todoID int64) ([]ListTodoWatchersToNotifyRow, error) {
+	rows, err := q.query(ctx, q.listTodoWatchersToNotifyStmt, listTodoWatchersToNotify, todoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTodoWatchersToNotifyRow
+	for rows.Next() {
+		var i ListTodoWatchersToNotifyRow
+		if err := rows.Scan(&i.ID, &i.Email); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodos = `-- name: ListTodos :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE (owner_id IS ?1 OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = ?1
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+  AND (CAST(?2 AS INTEGER) = 1 OR project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND (CAST(?2 AS INTEGER) = 1 OR archived_at IS NULL)
+  AND (?3 IS NULL OR CAST(json_extract(metadata, ?3) AS TEXT) = ?4)
+ORDER BY created_at DESC
+`
+
+type ListTodosParams struct {
+	UserID               sql.NullInt64  `json:"user_id"`
+	IncludeArchivedLists int64          `json:"include_archived_lists"`
+	MetadataPath         sql.NullString `json:"metadata_path"`
+	MetadataValue        sql.NullString `json:"metadata_value"`
+}
+
+func (q *Queries) ListTodos(ctx context.Context, arg ListTodosParams) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listTodosStmt, listTodos,
+		arg.UserID,
+		arg.IncludeArchivedLists,
+		arg.MetadataPath,
+		arg.MetadataValue,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodosByOwner = `-- name: ListTodosByOwner :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE owner_id = ?
+ORDER BY id
+`
+
+func (q *Queries) ListTodosByOwner(ctx context.Context, ownerID sql.NullInt64) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listTodosByOwnerStmt, listTodosByOwner, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodosByProject = `-- name: ListTodosByProject :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE project_id = ?
+ORDER BY position, created_at DESC, id DESC
+`
+
+func (q *Queries) ListTodosByProject(ctx context.Context, projectID sql.NullInt64) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listTodosByProjectStmt, listTodosByProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodosByStatus = `-- name: ListTodosByStatus :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE completed = ?1
+  AND (owner_id IS ?2 OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = ?2
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+  AND (CAST(?3 AS INTEGER) = 1 OR project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND (CAST(?3 AS INTEGER) = 1 OR archived_at IS NULL)
+  AND (?4 IS NULL OR CAST(json_extract(metadata, ?4) AS TEXT) = ?5)
+ORDER BY created_at DESC
+`
+
+type ListTodosByStatusParams struct {
+	Completed            int64          `json:"completed"`
+	UserID               sql.NullInt64  `json:"user_id"`
+	IncludeArchivedLists int64          `json:"include_archived_lists"`
+	MetadataPath         sql.NullString `json:"metadata_path"`
+	MetadataValue        sql.NullString `json:"metadata_value"`
+}
+
+func (q *Queries) ListTodosByStatus(ctx context.Context, arg ListTodosByStatusParams) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listTodosByStatusStmt, listTodosByStatus,
+		arg.Completed,
+		arg.UserID,
+		arg.IncludeArchivedLists,
+		arg.MetadataPath,
+		arg.MetadataValue,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodosForExport = `-- name: ListTodosForExport :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE id > ?1
+  AND (owner_id IS ?2 OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = ?2
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+  AND (?3 IS NULL OR completed = ?3)
+  AND (?4 IS NULL OR project_id = ?4)
+  AND (CAST(?5 AS INTEGER) = 1 OR project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND (CAST(?5 AS INTEGER) = 1 OR archived_at IS NULL)
+  AND (?6 IS NULL OR CAST(json_extract(metadata, ?6) AS TEXT) = ?7)
+ORDER BY id
+LIMIT ?8
+`
+
+type ListTodosForExportParams struct {
+	AfterID              int64          `json:"after_id"`
+	UserID               sql.NullInt64  `json:"user_id"`
+	Completed            sql.NullInt64  `json:"completed"`
+	ProjectID            sql.NullInt64  `json:"project_id"`
+	IncludeArchivedLists int64          `json:"include_archived_lists"`
+	MetadataPath         sql.NullString `json:"metadata_path"`
+	MetadataValue        sql.NullString `json:"metadata_value"`
+	Limit                int64          `json:"limit"`
+}
+
+// エクスポートするTodoをIDの順にlimit件ずつ取得する。after_idに前回の最後のIDを渡して続きを取得する
+func (q *Queries) ListTodosForExport(ctx context.Context, arg ListTodosForExportParams) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listTodosForExportStmt, listTodosForExport,
+		arg.AfterID,
+		arg.UserID,
+		arg.Completed,
+		arg.ProjectID,
+		arg.IncludeArchivedLists,
+		arg.MetadataPath,
+		arg.MetadataValue,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodosNear = `-- name: ListTodosNear :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE latitude IS NOT NULL AND longitude IS NOT NULL
+  AND (owner_id IS ?1 OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = ?1
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+  AND (CAST(?2 AS INTEGER) IS NULL OR completed = ?2)
+  AND haversine(latitude, longitude, CAST(?3 AS REAL), CAST(?4 AS REAL)) <= CAST(?5 AS REAL)
+  AND (CAST(?6 AS INTEGER) = 1 OR project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND (CAST(?6 AS INTEGER) = 1 OR archived_at IS NULL)
+  AND (?7 IS NULL OR CAST(json_extract(metadata, ?7) AS TEXT) = ?8)
+ORDER BY haversine(latitude, longitude, CAST(?3 AS REAL), CAST(?4 AS REAL)) ASC
+`
+
+type ListTodosNearParams struct {
+	UserID               sql.NullInt64  `json:"user_id"`
+	Completed            sql.NullInt64  `json:"completed"`
+	Lat                  float64        `json:"lat"`
+	Lng                  float64        `json:"lng"`
+	Radius               float64        `json:"radius"`
+	IncludeArchivedLists int64          `json:"include_archived_lists"`
+	MetadataPath         sql.NullString `json:"metadata_path"`
+	MetadataValue        sql.NullString `json:"metadata_value"`
+}
+
+func (q *Queries) ListTodosNear(ctx context.Context, arg ListTodosNearParams) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listTodosNearStmt, listTodosNear,
+		arg.UserID,
+		arg.Completed,
+		arg.Lat,
+		arg.Lng,
+		arg.Radius,
+		arg.IncludeArchivedLists,
+		arg.MetadataPath,
+		arg.MetadataValue,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsage = `-- name: ListUsage :many
+SELECT d.day, d.user_id, u.email, d.api_calls, d.storage_bytes, d.active_todos
+FROM usage_daily d
+JOIN users u ON u.id = d.user_id
+WHERE d.day >= ?1 AND d.day <= ?2
+  AND (?3 IS NULL OR d.user_id = ?3)
+ORDER BY d.day, d.user_id
+`
+
+type ListUsageParams struct {
+	FromDay string        `json:"from_day"`
+	ToDay   string        `json:"to_day"`
+	UserID  sql.NullInt64 `json:"user_id"`
+}
+
+type ListUsageRow struct {
+	Day          string `json:"day"`
+	UserID       int64  `json:"user_id"`
+	Email        string `json:"email"`
+	ApiCalls     int64  `json:"api_calls"`
+	StorageBytes int64  `json:"storage_bytes"`
+	ActiveTodos  int64  `json:"active_todos"`
+}
+
+func (q *Queries) ListUsage(ctx context.Context, arg ListUsageParams) ([]ListUsageRow, error) {
+	rows, err := q.query(ctx, q.listUsageStmt, listUsage, arg.FromDay, arg.ToDay, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsageRow
+	for rows.Next() {
+		var i ListUsageRow
+		if err := rows.Scan(
+			&i.Day,
+			&i.UserID,
+			&i.Email,
+			&i.ApiCalls,
+			&i.StorageBytes,
+			&i.ActiveTodos,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, email, name, password_hash, role, created_at
+FROM users
+ORDER BY id
+`
+
+func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
+	rows, err := q.query(ctx, q.listUsersStmt, listUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Name,
+			&i.PasswordHash,
+			&i.Role,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVisibleTodosByProject = `-- name: ListVisibleTodosByProject :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE project_id = ?1
+  AND (owner_id IS ?2 OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = ?2
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+ORDER BY position, created_at DESC, id DESC
+`
+
+type ListVisibleTodosByProjectParams struct {
+	ProjectID sql.NullInt64 `json:"project_id"`
+	UserID    sql.NullInt64 `json:"user_id"`
+}
+
+func (q *Queries) ListVisibleTodosByProject(ctx context.Context, arg ListVisibleTodosByProjectParams) ([]Todo, error) {
+	rows, err := q.query(ctx, q.listVisibleTodosByProjectStmt, listVisibleTodosByProject, arg.ProjectID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Completed,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.SubtaskCount,
+			&i.SubtaskCompletedCount,
+			&i.ProjectID,
+			&i.DueAt,
+			&i.Recurrence,
+			&i.NextTodoID,
+			&i.Position,
+			&i.Version,
+			&i.Assignee,
+			&i.OwnerID,
+			&i.Metadata,
+			&i.Priority,
+			&i.EscalationLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Translations,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWatchersByTodoIDs = `-- name: ListWatchersByTodoIDs :many
+SELECT todo_watchers.todo_id, todo_watchers.user_id, users.name, todo_watchers.created_at
+FROM todo_watchers
+JOIN users ON users.id = todo_watchers.user_id
+WHERE todo_watchers.todo_id IN (/*SLICE:todo_ids*/?)
+ORDER BY todo_watchers.todo_id, todo_watchers.created_at, todo_watchers.user_id
+`
+
+type ListWatchersByTodoIDsRow struct {
+	TodoID    int64     `json:"todo_id"`
+	UserID    int64     `json:"user_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListWatchersByTodoIDs(ctx context.Context, todoIds []int64) ([]ListWatchersByTodoIDsRow, error) {
+	query := listWatchersByTodoIDs
+	var queryParams []interface{}
+	if len(todoIds) > 0 {
+		for _, v := range todoIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", strings.Repeat(",?", len(todoIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", "NULL", 1)
+	}
+	rows, err := q.query(ctx, nil, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWatchersByTodoIDsRow
+	for rows.Next() {
+		var i ListWatchersByTodoIDsRow
+		if err := rows.Scan(
+			&i.TodoID,
+			&i.UserID,
+			&i.Name,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, webhook_id, event, todo_id, payload, status, attempts, next_attempt_at, response_status, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE webhook_id = ?1 AND (CAST(?2 AS INTEGER) = 0 OR id < ?2)
+ORDER BY id DESC
+LIMIT ?3
+`
+
+type ListWebhookDeliveriesParams struct {
+	WebhookID int64 `json:"webhook_id"`
+	BeforeID  int64 `json:"before_id"`
+	Limit     int64 `json:"limit"`
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.query(ctx, q.listWebhookDeliveriesStmt, listWebhookDeliveries, arg.WebhookID, arg.BeforeID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.Event,
+			&i.TodoID,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhooks = `-- name: ListWebhooks :many
+SELECT id, owner_id, url, events, secret, active, created_at, updated_at
+FROM webhooks
+WHERE owner_id IS ?1
+ORDER BY id
+`
+
+func (q *Queries) ListWebhooks(ctx context.Context, ownerID sql.NullInt64) ([]Webhook, error) {
+	rows, err := q.query(ctx, q.listWebhooksStmt, listWebhooks, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.Url,
+			&i.Events,
+			&i.Secret,
+			&i.Active,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markInboxRead = `-- name: MarkInboxRead :exec
+INSERT INTO read_markers (subject, last_activity_id, last_comment_id)
+VALUES (
+    ?1,
+    (SELECT COALESCE(MAX(id), 0) FROM activity_log),
+    (SELECT COALESCE(MAX(id), 0) FROM comments)
+)
+ON CONFLICT (subject) DO UPDATE
+SET last_activity_id = excluded.last_activity_id,
+    last_comment_id = excluded.last_comment_id,
+    updated_at = CURRENT_TIMESTAMP
+`
+
+func (q *Queries) MarkInboxRead(ctx context.Context, subject string) error {
+	_, err := q.exec(ctx, q.markInboxReadStmt, markInboxRead, subject)
+	return err
+}
+
+const markOutboxEventPublished = `-- name: MarkOutboxEventPublished :exec
+UPDATE outbox SET published_at = ? WHERE id = ?
+`
+
+type MarkOutboxEventPublishedParams struct {
+	PublishedAt sql.NullTime `json:"published_at"`
+	ID          int64        `json:"id"`
+}
+
+func (q *Queries) MarkOutboxEventPublished(ctx context.Context, arg MarkOutboxEventPublishedParams) error {
+	_, err := q.exec(ctx, q.markOutboxEventPublishedStmt, markOutboxEventPublished, arg.PublishedAt, arg.ID)
+	return err
+}
+
+const markReminderSent = `-- name: MarkReminderSent :execrows
+UPDATE reminders SET sent_at = CURRENT_TIMESTAMP
+WHERE id = ? AND sent_at IS NULL
+`
+
+func (q *Queries) MarkReminderSent(ctx context.Context, id int64) (int64, error) {
+	result, err := q.exec(ctx, q.markReminderSentStmt, markReminderSent, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const moveTodo = `-- name: MoveTodo :one
+UPDATE todos
+SET project_id = ?, position = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+`
+
+type MoveTodoParams struct {
+	ProjectID sql.NullInt64 `json:"project_id"`
+	Position  int64         `json:"position"`
+	ID        int64         `json:"id"`
+}
+
+func (q *Queries) MoveTodo(ctx context.Context, arg MoveTodoParams) (Todo, error) {
+	row := q.queryRow(ctx, q.moveTodoStmt, moveTodo, arg.ProjectID, arg.Position, arg.ID)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Completed,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.SubtaskCount,
+		&i.SubtaskCompletedCount,
+		&i.ProjectID,
+		&i.DueAt,
+		&i.Recurrence,
+		&i.NextTodoID,
+		&i.Position,
+		&i.Version,
+		&i.Assignee,
+		&i.OwnerID,
+		&i.Metadata,
+		&i.Priority,
+		&i.EscalationLevel,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Translations,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const pruneDescriptionOps = `-- name: PruneDescriptionOps :exec
+DELETE FROM todo_description_ops
+WHERE todo_id = ? AND revision <= ?
+`
+
+type PruneDescriptionOpsParams struct {
+	TodoID   int64 `json:"todo_id"`
+	Revision int64 `json:"revision"`
+}
+
+func (q *Queries) PruneDescriptionOps(ctx context.Context, arg PruneDescriptionOpsParams) error {
+	_, err := q.exec(ctx, q.pruneDescriptionOpsStmt, pruneDescriptionOps, arg.TodoID, arg.Revision)
+	return err
+}
+
+const pruneDueDigests = `-- name: PruneDueDigests :execrows
+DELETE FROM due_digests
+WHERE sent_at < ?
+`
+
+func (q *Queries) PruneDueDigests(ctx context.Context, sentAt time.Time) (int64, error) {
+	result, err := q.exec(ctx, q.pruneDueDigestsStmt, pruneDueDigests, sentAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const pruneOutbox = `-- name: PruneOutbox :execrows
+DELETE FROM outbox
+WHERE published_at IS NOT NULL AND published_at < ?
+`
+
+func (q *Queries) PruneOutbox(ctx context.Context, publishedAt sql.NullTime) (int64, error) {
+	result, err := q.exec(ctx, q.pruneOutboxStmt, pruneOutbox, publishedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const pruneResourceLocks = `-- name: PruneResourceLocks :execrows
+DELETE FROM resource_locks WHERE expires_at <= ?
+`
+
+func (q *Queries) PruneResourceLocks(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.exec(ctx, q.pruneResourceLocksStmt, pruneResourceLocks, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const pruneWebhookDeliveries = `-- name: PruneWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE status != 'pending' AND created_at < ?
+`
+
+func (q *Queries) PruneWebhookDeliveries(ctx context.Context, createdAt time.Time) (int64, error) {
+	result, err := q.exec(ctx, q.pruneWebhookDeliveriesStmt, pruneWebhookDeliveries, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reassignTodoTags = `-- name: ReassignTodoTags :exec
+INSERT OR IGNORE INTO todo_tags (todo_id, tag_id)
+SELECT todo_id, ?1 FROM todo_tags
+WHERE tag_id = ?2
+`
+
+type ReassignTodoTagsParams struct {
+	ToTagID   int64 `json:"to_tag_id"`
+	FromTagID int64 `json:"from_tag_id"`
+}
+
+// from_tag_idを付けたTodoにto_tag_idを付ける。既に付けている場合はそのままにする
+func (q *Queries) ReassignTodoTags(ctx context.Context, arg ReassignTodoTagsParams) error {
+	_, err := q.exec(ctx, q.reassignTodoTagsStmt, reassignTodoTags, arg.ToTagID, arg.FromTagID)
+	return err
+}
+
+const recordChatOverduePost = `-- name: RecordChatOverduePost :exec
+INSERT INTO chat_overdue_posts (todo_id, due_at, posted_at)
 VALUES (?, ?, ?)
-RETURNING id, title, description, completed, created_at, updated_at
+ON CONFLICT (todo_id) DO UPDATE SET due_at = excluded.due_at, posted_at = excluded.posted_at
+`
+
+type RecordChatOverduePostParams struct {
+	TodoID   int64     `json:"todo_id"`
+	DueAt    time.Time `json:"due_at"`
+	PostedAt time.Time `json:"posted_at"`
+}
+
+func (q *Queries) RecordChatOverduePost(ctx context.Context, arg RecordChatOverduePostParams) error {
+	_, err := q.exec(ctx, q.recordChatOverduePostStmt, recordChatOverduePost, arg.TodoID, arg.DueAt, arg.PostedAt)
+	return err
+}
+
+const recordOutboxFailure = `-- name: RecordOutboxFailure :exec
+UPDATE outbox SET attempts = attempts + 1, last_error = ? WHERE id = ?
+`
+
+type RecordOutboxFailureParams struct {
+	LastError sql.NullString `json:"last_error"`
+	ID        int64          `json:"id"`
+}
+
+func (q *Queries) RecordOutboxFailure(ctx context.Context, arg RecordOutboxFailureParams) error {
+	_, err := q.exec(ctx, q.recordOutboxFailureStmt, recordOutboxFailure, arg.LastError, arg.ID)
+	return err
+}
+
+const renameTag = `-- name: RenameTag :one
+UPDATE tags SET name = ?
+WHERE id = ?
+RETURNING id, owner_id, name, created_at
+`
+
+type RenameTagParams struct {
+	Name string `json:"name"`
+	ID   int64  `json:"id"`
+}
+
+func (q *Queries) RenameTag(ctx context.Context, arg RenameTagParams) (Tag, error) {
+	row := q.queryRow(ctx, q.renameTagStmt, renameTag, arg.Name, arg.ID)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, id int64) (int64, error) {
+	result, err := q.exec(ctx, q.revokeAPIKeyStmt, revokeAPIKey, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const revokeAPIKeysByCreator = `-- name: RevokeAPIKeysByCreator :execrows
+UPDATE api_keys
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE created_by = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAPIKeysByCreator(ctx context.Context, createdBy string) (int64, error) {
+	result, err := q.exec(ctx, q.revokeAPIKeysByCreatorStmt, revokeAPIKeysByCreator, createdBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setNextTodoID = `-- name: SetNextTodoID :execrows
+UPDATE todos
+SET next_todo_id = ?, version = version + 1
+WHERE id = ? AND next_todo_id IS NULL
+`
+
+type SetNextTodoIDParams struct {
+	NextTodoID sql.NullInt64 `json:"next_todo_id"`
+	ID         int64         `json:"id"`
+}
+
+func (q *Queries) SetNextTodoID(ctx context.Context, arg SetNextTodoIDParams) (int64, error) {
+	result, err := q.exec(ctx, q.setNextTodoIDStmt, setNextTodoID, arg.NextTodoID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setTodoPosition = `-- name: SetTodoPosition :execrows
+UPDATE todos
+SET position = ?1, version = version + 1
+WHERE id = ?2 AND position != ?1
 `
 
-type CreateTodoParams struct {
-	Title       string         `json:"title"`
-	Description sql.NullString `json:"description"`
-	Completed   int64          `json:"completed"`
+type SetTodoPositionParams struct {
+	Position int64 `json:"position"`
+	ID       int64 `json:"id"`
 }
 
-func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error) {
-	row := q.queryRow(ctx, q.createTodoStmt, createTodo, arg.Title, arg.Description, arg.Completed)
+func (q *Queries) SetTodoPosition(ctx context.Context, arg SetTodoPositionParams) (int64, error) {
+	result, err := q.exec(ctx, q.setTodoPositionStmt, setTodoPosition, arg.Position, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const shareProject = `-- name: ShareProject :one
+INSERT INTO todo_shares (project_id, user_id, shared_by, permission)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (project_id, user_id, shared_by) DO UPDATE SET permission = excluded.permission
+RETURNING id, todo_id, project_id, user_id, shared_by, permission, created_at
+`
+
+type ShareProjectParams struct {
+	ProjectID  sql.NullInt64 `json:"project_id"`
+	UserID     int64         `json:"user_id"`
+	SharedBy   int64         `json:"shared_by"`
+	Permission string        `json:"permission"`
+}
+
+func (q *Queries) ShareProject(ctx context.Context, arg ShareProjectParams) (TodoShare, error) {
+	row := q.queryRow(ctx, q.shareProjectStmt, shareProject,
+		arg.ProjectID,
+		arg.UserID,
+		arg.SharedBy,
+		arg.Permission,
+	)
+	var i TodoShare
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.SharedBy,
+		&i.Permission,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const shareTodo = `-- name: ShareTodo :one
+INSERT INTO todo_shares (todo_id, user_id, shared_by, permission)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (todo_id, user_id) DO UPDATE SET permission = excluded.permission
+RETURNING id, todo_id, project_id, user_id, shared_by, permission, created_at
+`
+
+type ShareTodoParams struct {
+	TodoID     sql.NullInt64 `json:"todo_id"`
+	UserID     int64         `json:"user_id"`
+	SharedBy   int64         `json:"shared_by"`
+	Permission string        `json:"permission"`
+}
+
+func (q *Queries) ShareTodo(ctx context.Context, arg ShareTodoParams) (TodoShare, error) {
+	row := q.queryRow(ctx, q.shareTodoStmt, shareTodo,
+		arg.TodoID,
+		arg.UserID,
+		arg.SharedBy,
+		arg.Permission,
+	)
+	var i TodoShare
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.SharedBy,
+		&i.Permission,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const snapshotUsage = `-- name: SnapshotUsage :execrows
+INSERT INTO usage_daily (day, user_id, storage_bytes, active_todos)
+SELECT ?1, u.id,
+       (SELECT COALESCE(SUM(a.size), 0) FROM attachments a JOIN todos t ON t.id = a.todo_id WHERE t.owner_id = u.id),
+       (SELECT COUNT(*) FROM todos t WHERE t.owner_id = u.id AND t.completed = 0)
+FROM users u
+WHERE true
+ON CONFLICT (day, user_id) DO UPDATE
+SET storage_bytes = excluded.storage_bytes, active_todos = excluded.active_todos, updated_at = CURRENT_TIMESTAMP
+`
+
+// すべてのユーザーの添付ファイルの合計サイズと未完了のTodoの件数を、指定した日の利用量として記録する
+func (q *Queries) SnapshotUsage(ctx context.Context, day string) (int64, error) {
+	result, err := q.exec(ctx, q.snapshotUsageStmt, snapshotUsage, day)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const snoozeTodo = `-- name: SnoozeTodo :exec
+INSERT INTO todo_snoozes (todo_id, snoozed_until)
+VALUES (?1, ?2)
+ON CONFLICT (todo_id) DO UPDATE
+SET snoozed_until = excluded.snoozed_until, created_at = CURRENT_TIMESTAMP
+`
+
+type SnoozeTodoParams struct {
+	TodoID       int64     `json:"todo_id"`
+	SnoozedUntil time.Time `json:"snoozed_until"`
+}
+
+func (q *Queries) SnoozeTodo(ctx context.Context, arg SnoozeTodoParams) error {
+	_, err := q.exec(ctx, q.snoozeTodoStmt, snoozeTodo, arg.TodoID, arg.SnoozedUntil)
+	return err
+}
+
+const toggleTodoCompleted = `-- name: ToggleTodoCompleted :one
+UPDATE todos
+SET completed = CASE WHEN completed = 0 THEN 1 ELSE 0 END, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+`
+
+func (q *Queries) ToggleTodoCompleted(ctx context.Context, id int64) (Todo, error) {
+	row := q.queryRow(ctx, q.toggleTodoCompletedStmt, toggleTodoCompleted, id)
 	var i Todo
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
 		&i.Description,
 		&i.Completed,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.SubtaskCount,
+		&i.SubtaskCompletedCount,
+		&i.ProjectID,
+		&i.DueAt,
+		&i.Recurrence,
+		&i.NextTodoID,
+		&i.Position,
+		&i.Version,
+		&i.Assignee,
+		&i.OwnerID,
+		&i.Metadata,
+		&i.Priority,
+		&i.EscalationLevel,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Translations,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
 
-const deleteTodo = `-- name: DeleteTodo :exec
-DELETE FROM todos WHERE id = ?
+const touchAPIKey = `-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = ?1
+WHERE id = ?2 AND (last_used_at IS NULL OR last_used_at < ?3)
 `
 
-func (q *Queries) DeleteTodo(ctx context.Context, id int64) error {
-	_, err := q.exec(ctx, q.deleteTodoStmt, deleteTodo, id)
+type TouchAPIKeyParams struct {
+	UsedAt      time.Time `json:"used_at"`
+	ID          int64     `json:"id"`
+	StaleBefore time.Time `json:"stale_before"`
+}
+
+func (q *Queries) TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error {
+	_, err := q.exec(ctx, q.touchAPIKeyStmt, touchAPIKey, arg.UsedAt, arg.ID, arg.StaleBefore)
 	return err
 }
 
-const getTodo = `-- name: GetTodo :one
-SELECT id, title, description, completed, created_at, updated_at
-FROM todos
-WHERE id = ? LIMIT 1
+const touchHealthProbe = `-- name: TouchHealthProbe :exec
+INSERT INTO health_probe (id, checked_at)
+VALUES (1, CURRENT_TIMESTAMP)
+ON CONFLICT (id) DO UPDATE SET checked_at = excluded.checked_at
+`
+
+func (q *Queries) TouchHealthProbe(ctx context.Context) error {
+	_, err := q.exec(ctx, q.touchHealthProbeStmt, touchHealthProbe)
+	return err
+}
+
+const unarchiveProject = `-- name: UnarchiveProject :execrows
+UPDATE projects
+SET archived_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND archived_at IS NOT NULL
+`
+
+func (q *Queries) UnarchiveProject(ctx context.Context, id int64) (int64, error) {
+	result, err := q.exec(ctx, q.unarchiveProjectStmt, unarchiveProject, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unarchiveTodo = `-- name: UnarchiveTodo :one
+UPDATE todos
+SET archived_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND archived_at IS NOT NULL
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
 `
 
-func (q *Queries) GetTodo(ctx context.Context, id int64) (Todo, error) {
-	row := q.queryRow(ctx, q.getTodoStmt, getTodo, id)
+func (q *Queries) UnarchiveTodo(ctx context.Context, id int64) (Todo, error) {
+	row := q.queryRow(ctx, q.unarchiveTodoStmt, unarchiveTodo, id)
 	var i Todo
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
 		&i.Description,
 		&i.Completed,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.SubtaskCount,
+		&i.SubtaskCompletedCount,
+		&i.ProjectID,
+		&i.DueAt,
+		&i.Recurrence,
+		&i.NextTodoID,
+		&i.Position,
+		&i.Version,
+		&i.Assignee,
+		&i.OwnerID,
+		&i.Metadata,
+		&i.Priority,
+		&i.EscalationLevel,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Translations,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
 
-const listTodos = `-- name: ListTodos :many
-SELECT id, title, description, completed, created_at, updated_at
-FROM todos
-ORDER BY created_at DESC
+const unwatchTodo = `-- name: UnwatchTodo :execrows
+DELETE FROM todo_watchers WHERE todo_id = ? AND user_id = ?
 `
 
-func (q *Queries) ListTodos(ctx context.Context) ([]Todo, error) {
-	rows, err := q.query(ctx, q.listTodosStmt, listTodos)
+type UnwatchTodoParams struct {
+	TodoID int64 `json:"todo_id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) UnwatchTodo(ctx context.Context, arg UnwatchTodoParams) (int64, error) {
+	result, err := q.exec(ctx, q.unwatchTodoStmt, unwatchTodo, arg.TodoID, arg.UserID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Todo
-	for rows.Next() {
-		var i Todo
-		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.Description,
-			&i.Completed,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected()
 }
 
-const listTodosByStatus = `-- name: ListTodosByStatus :many
-SELECT id, title, description, completed, created_at, updated_at
-FROM todos
-WHERE completed = ?
-ORDER BY created_at DESC
+const unwatchTodos = `-- name: UnwatchTodos :execrows
+DELETE FROM todo_watchers
+WHERE user_id = ?1 AND todo_id IN (/*SLICE:todo_ids*/?)
 `
 
-func (q *Queries) ListTodosByStatus(ctx context.Context, completed int64) ([]Todo, error) {
-	rows, err := q.query(ctx, q.listTodosByStatusStmt, listTodosByStatus, completed)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Todo
-	for rows.Next() {
-		var i Todo
-		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.Description,
-			&i.Completed,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
+type UnwatchTodosParams struct {
+	UserID  int64   `json:"user_id"`
+	TodoIds []int64 `json:"todo_ids"`
+}
+
+func (q *Queries) UnwatchTodos(ctx context.Context, arg UnwatchTodosParams) (int64, error) {
+	query := unwatchTodos
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.UserID)
+	if len(arg.TodoIds) > 0 {
+		for _, v := range arg.TodoIds {
+			queryParams = append(queryParams, v)
 		}
-		items = append(items, i)
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", strings.Repeat(",?", len(arg.TodoIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:todo_ids*/?", "NULL", 1)
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	result, err := q.exec(ctx, nil, query, queryParams...)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected()
 }
 
-const toggleTodoCompleted = `-- name: ToggleTodoCompleted :one
-UPDATE todos
-SET completed = CASE WHEN completed = 0 THEN 1 ELSE 0 END, updated_at = CURRENT_TIMESTAMP
+const updateProject = `-- name: UpdateProject :one
+UPDATE projects
+SET name = ?, description = ?, metadata_schema = ?, wip_limit = ?, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, title, description, completed, created_at, updated_at
+RETURNING id, name, description, archived_at, open_count, completed_count, metadata_schema, created_at, updated_at, wip_limit
 `
 
-func (q *Queries) ToggleTodoCompleted(ctx context.Context, id int64) (Todo, error) {
-	row := q.queryRow(ctx, q.toggleTodoCompletedStmt, toggleTodoCompleted, id)
-	var i Todo
+type UpdateProjectParams struct {
+	Name           string         `json:"name"`
+	Description    sql.NullString `json:"description"`
+	MetadataSchema sql.NullString `json:"metadata_schema"`
+	WipLimit       sql.NullInt64  `json:"wip_limit"`
+	ID             int64          `json:"id"`
+}
+
+func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error) {
+	row := q.queryRow(ctx, q.updateProjectStmt, updateProject,
+		arg.Name,
+		arg.Description,
+		arg.MetadataSchema,
+		arg.WipLimit,
+		arg.ID,
+	)
+	var i Project
 	err := row.Scan(
 		&i.ID,
-		&i.Title,
+		&i.Name,
 		&i.Description,
+		&i.ArchivedAt,
+		&i.OpenCount,
+		&i.CompletedCount,
+		&i.MetadataSchema,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.WipLimit,
+	)
+	return i, err
+}
+
+const updateSubtask = `-- name: UpdateSubtask :one
+UPDATE subtasks
+SET title = ?, completed = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND todo_id = ?
+RETURNING id, todo_id, title, completed, created_at, updated_at
+`
+
+type UpdateSubtaskParams struct {
+	Title     string `json:"title"`
+	Completed int64  `json:"completed"`
+	ID        int64  `json:"id"`
+	TodoID    int64  `json:"todo_id"`
+}
+
+func (q *Queries) UpdateSubtask(ctx context.Context, arg UpdateSubtaskParams) (Subtask, error) {
+	row := q.queryRow(ctx, q.updateSubtaskStmt, updateSubtask,
+		arg.Title,
+		arg.Completed,
+		arg.ID,
+		arg.TodoID,
+	)
+	var i Subtask
+	err := row.Scan(
+		&i.ID,
+		&i.TodoID,
+		&i.Title,
 		&i.Completed,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -161,16 +5878,27 @@ func (q *Queries) ToggleTodoCompleted(ctx context.Context, id int64) (Todo, erro
 
 const updateTodo = `-- name: UpdateTodo :one
 UPDATE todos
-SET title = ?, description = ?, completed = ?, updated_at = CURRENT_TIMESTAMP
+SET title = ?, description = ?, completed = ?, latitude = ?, longitude = ?, place_name = ?, project_id = ?, due_at = ?, recurrence = ?, assignee = ?, metadata = ?, priority = ?, escalation_level = ?, translations = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, title, description, completed, created_at, updated_at
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
 `
 
 type UpdateTodoParams struct {
-	Title       string         `json:"title"`
-	Description sql.NullString `json:"description"`
-	Completed   int64          `json:"completed"`
-	ID          int64          `json:"id"`
+	Title           string          `json:"title"`
+	Description     sql.NullString  `json:"description"`
+	Completed       int64           `json:"completed"`
+	Latitude        sql.NullFloat64 `json:"latitude"`
+	Longitude       sql.NullFloat64 `json:"longitude"`
+	PlaceName       sql.NullString  `json:"place_name"`
+	ProjectID       sql.NullInt64   `json:"project_id"`
+	DueAt           sql.NullTime    `json:"due_at"`
+	Recurrence      sql.NullString  `json:"recurrence"`
+	Assignee        sql.NullString  `json:"assignee"`
+	Metadata        sql.NullString  `json:"metadata"`
+	Priority        int64           `json:"priority"`
+	EscalationLevel int64           `json:"escalation_level"`
+	Translations    sql.NullString  `json:"translations"`
+	ID              int64           `json:"id"`
 }
 
 func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, error) {
@@ -178,6 +5906,17 @@ func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, e
 		arg.Title,
 		arg.Description,
 		arg.Completed,
+		arg.Latitude,
+		arg.Longitude,
+		arg.PlaceName,
+		arg.ProjectID,
+		arg.DueAt,
+		arg.Recurrence,
+		arg.Assignee,
+		arg.Metadata,
+		arg.Priority,
+		arg.EscalationLevel,
+		arg.Translations,
 		arg.ID,
 	)
 	var i Todo
@@ -186,8 +5925,252 @@ func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, e
 		&i.Title,
 		&i.Description,
 		&i.Completed,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.SubtaskCount,
+		&i.SubtaskCompletedCount,
+		&i.ProjectID,
+		&i.DueAt,
+		&i.Recurrence,
+		&i.NextTodoID,
+		&i.Position,
+		&i.Version,
+		&i.Assignee,
+		&i.OwnerID,
+		&i.Metadata,
+		&i.Priority,
+		&i.EscalationLevel,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Translations,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const updateTodoDescription = `-- name: UpdateTodoDescription :one
+UPDATE todos
+SET description = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+`
+
+type UpdateTodoDescriptionParams struct {
+	Description sql.NullString `json:"description"`
+	ID          int64          `json:"id"`
+}
+
+func (q *Queries) UpdateTodoDescription(ctx context.Context, arg UpdateTodoDescriptionParams) (Todo, error) {
+	row := q.queryRow(ctx, q.updateTodoDescriptionStmt, updateTodoDescription, arg.Description, arg.ID)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Completed,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.SubtaskCount,
+		&i.SubtaskCompletedCount,
+		&i.ProjectID,
+		&i.DueAt,
+		&i.Recurrence,
+		&i.NextTodoID,
+		&i.Position,
+		&i.Version,
+		&i.Assignee,
+		&i.OwnerID,
+		&i.Metadata,
+		&i.Priority,
+		&i.EscalationLevel,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Translations,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users SET role = ?
+WHERE id = ?
+RETURNING id, email, name, password_hash, role, created_at
+`
+
+type UpdateUserRoleParams struct {
+	Role string `json:"role"`
+	ID   int64  `json:"id"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.queryRow(ctx, q.updateUserRoleStmt, updateUserRole, arg.Role, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.PasswordHash,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateWebhook = `-- name: UpdateWebhook :one
+UPDATE webhooks
+SET url = ?1, events = ?2, active = ?3, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?4 AND owner_id IS ?5
+RETURNING id, owner_id, url, events, secret, active, created_at, updated_at
+`
+
+type UpdateWebhookParams struct {
+	Url     string        `json:"url"`
+	Events  string        `json:"events"`
+	Active  int64         `json:"active"`
+	ID      int64         `json:"id"`
+	OwnerID sql.NullInt64 `json:"owner_id"`
+}
+
+func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error) {
+	row := q.queryRow(ctx, q.updateWebhookStmt, updateWebhook,
+		arg.Url,
+		arg.Events,
+		arg.Active,
+		arg.ID,
+		arg.OwnerID,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Url,
+		&i.Events,
+		&i.Secret,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertProjectChatChannel = `-- name: UpsertProjectChatChannel :one
+INSERT INTO project_chat_channels (project_id, provider, webhook_url, events, templates)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (project_id, provider) DO UPDATE
+SET webhook_url = excluded.webhook_url, events = excluded.events, templates = excluded.templates, updated_at = CURRENT_TIMESTAMP
+RETURNING project_id, provider, webhook_url, events, templates, created_at, updated_at
+`
+
+type UpsertProjectChatChannelParams struct {
+	ProjectID  int64  `json:"project_id"`
+	Provider   string `json:"provider"`
+	WebhookUrl string `json:"webhook_url"`
+	Events     string `json:"events"`
+	Templates  string `json:"templates"`
+}
+
+func (q *Queries) UpsertProjectChatChannel(ctx context.Context, arg UpsertProjectChatChannelParams) (ProjectChatChannel, error) {
+	row := q.queryRow(ctx, q.upsertProjectChatChannelStmt, upsertProjectChatChannel,
+		arg.ProjectID,
+		arg.Provider,
+		arg.WebhookUrl,
+		arg.Events,
+		arg.Templates,
+	)
+	var i ProjectChatChannel
+	err := row.Scan(
+		&i.ProjectID,
+		&i.Provider,
+		&i.WebhookUrl,
+		&i.Events,
+		&i.Templates,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
+
+const upsertTodoDefaults = `-- name: UpsertTodoDefaults :one
+INSERT INTO todo_defaults (project_id, priority, due_offset_seconds, metadata, tags)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (COALESCE(project_id, 0)) DO UPDATE
+SET priority = excluded.priority, due_offset_seconds = excluded.due_offset_seconds, metadata = excluded.metadata, tags = excluded.tags, updated_at = CURRENT_TIMESTAMP
+RETURNING id, project_id, priority, due_offset_seconds, metadata, updated_at, tags
+`
+
+type UpsertTodoDefaultsParams struct {
+	ProjectID        sql.NullInt64  `json:"project_id"`
+	Priority         sql.NullInt64  `json:"priority"`
+	DueOffsetSeconds sql.NullInt64  `json:"due_offset_seconds"`
+	Metadata         sql.NullString `json:"metadata"`
+	Tags             sql.NullString `json:"tags"`
+}
+
+func (q *Queries) UpsertTodoDefaults(ctx context.Context, arg UpsertTodoDefaultsParams) (TodoDefault, error) {
+	row := q.queryRow(ctx, q.upsertTodoDefaultsStmt, upsertTodoDefaults,
+		arg.ProjectID,
+		arg.Priority,
+		arg.DueOffsetSeconds,
+		arg.Metadata,
+		arg.Tags,
+	)
+	var i TodoDefault
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Priority,
+		&i.DueOffsetSeconds,
+		&i.Metadata,
+		&i.UpdatedAt,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const useActionLink = `-- name: UseActionLink :execrows
+INSERT INTO action_link_uses (token_id, action, todo_id, user_id, ip, user_agent)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (token_id) DO NOTHING
+`
+
+type UseActionLinkParams struct {
+	TokenID   string        `json:"token_id"`
+	Action    string        `json:"action"`
+	TodoID    int64         `json:"todo_id"`
+	UserID    sql.NullInt64 `json:"user_id"`
+	Ip        string        `json:"ip"`
+	UserAgent string        `json:"user_agent"`
+}
+
+func (q *Queries) UseActionLink(ctx context.Context, arg UseActionLinkParams) (int64, error) {
+	result, err := q.exec(ctx, q.useActionLinkStmt, useActionLink,
+		arg.TokenID,
+		arg.Action,
+		arg.TodoID,
+		arg.UserID,
+		arg.Ip,
+		arg.UserAgent,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const watchTodo = `-- name: WatchTodo :exec
+INSERT INTO todo_watchers (todo_id, user_id)
+VALUES (?, ?)
+ON CONFLICT (todo_id, user_id) DO NOTHING
+`
+
+type WatchTodoParams struct {
+	TodoID int64 `json:"todo_id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) WatchTodo(ctx context.Context, arg WatchTodoParams) error {
+	_, err := q.exec(ctx, q.watchTodoStmt, watchTodo, arg.TodoID, arg.UserID)
+	return err
+}
@@ -0,0 +1,142 @@
+// Package dbpool は書き込み用と読み取り用のコネクションプールにクエリを振り分ける機能を提供する。
+// SQLiteは書き込みを1つずつしか行えないため書き込み用のプールは1コネクションにするが、
+// WALモードでは読み取りを書き込みと並行して行えるため、読み取り専用のクエリを複数コネクションのプールで実行する。
+package dbpool
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+)
+
+// readonlyStmt はSQLiteのsqlite3_stmt_readonlyのように、文がデータベースを変更しないかを返すドライバーの文
+type readonlyStmt interface {
+	Readonly() bool
+}
+
+// Router は読み取り専用のクエリを読み取り用のプールで、それ以外を書き込み用のプールで実行するdb.DBTX。
+// クエリが読み取り専用かは初回の実行時にドライバーで判定し、振り分け先のプールで準備した文を使い回す。
+// トランザクションは書き込み用のプールで開始し、q.WithTxで中のクエリもすべて書き込み用のプールで実行する。
+type Router struct {
+	write *sql.DB
+	read  *sql.DB
+
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+// New はRouterの新しいインスタンスを生成する。readは書き込みを行えないよう読み取り専用で開く。
+func New(write, read *sql.DB) *Router {
+	return &Router{
+		write: write,
+		read:  read,
+		stmts: make(map[string]*sql.Stmt),
+	}
+}
+
+// readOnly はクエリがデータベースを変更しないかを返す。判定できないドライバーの場合は書き込みとみなす。
+func (r *Router) readOnly(ctx context.Context, query string) (bool, error) {
+	conn, err := r.read.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	var readOnly bool
+	err = conn.Raw(func(dc any) error {
+		// トレースなどで包んだ接続は元の接続で準備し、ドライバーの文を直接調べる
+		if u, ok := dc.(interface{ Unwrap() driver.Conn }); ok {
+			dc = u.Unwrap()
+		}
+		s, err := dc.(driver.Conn).Prepare(query)
+		if err != nil {
+			return err
+		}
+		if ro, ok := s.(readonlyStmt); ok {
+			readOnly = ro.Readonly()
+		}
+		return s.Close()
+	})
+	return readOnly, err
+}
+
+// pool はクエリを実行するプールを返す
+func (r *Router) pool(ctx context.Context, query string) (*sql.DB, error) {
+	readOnly, err := r.readOnly(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if readOnly {
+		return r.read, nil
+	}
+	return r.write, nil
+}
+
+// stmt はクエリを振り分け先のプールで準備した文を返す
+func (r *Router) stmt(ctx context.Context, query string) (*sql.Stmt, error) {
+	r.mu.Lock()
+	s, ok := r.stmts[query]
+	r.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+
+	pool, err := r.pool(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s, err = pool.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// 同時に準備した場合は先に登録した文を使う
+	if existing, ok := r.stmts[query]; ok {
+		_ = s.Close()
+		return existing, nil
+	}
+	r.stmts[query] = s
+	return s, nil
+}
+
+// ExecContext はクエリを振り分け先のプールで実行する
+func (r *Router) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	s, err := r.stmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return s.ExecContext(ctx, args...)
+}
+
+// PrepareContext はクエリを振り分け先のプールで準備する。返した文は呼び出し側で閉じる。
+func (r *Router) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	pool, err := r.pool(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return pool.PrepareContext(ctx, query)
+}
+
+// QueryContext はクエリを振り分け先のプールで実行する
+func (r *Router) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	s, err := r.stmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return s.QueryContext(ctx, args...)
+}
+
+// QueryRowContext はクエリを振り分け先のプールで実行する。準備に失敗した場合はエラーを書き込み用のプールでの実行結果として返す。
+func (r *Router) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	s, err := r.stmt(ctx, query)
+	if err != nil {
+		// sql.Rowはエラーだけを持たせて作れないため、書き込み用のプールで実行して同じエラーを得る
+		return r.write.QueryRowContext(ctx, query, args...)
+	}
+	return s.QueryRowContext(ctx, args...)
+}
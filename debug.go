@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"go-huma-test/model"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"reflect"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/casing"
+)
+
+// redactedValue は/debug/configとconfig exportで伏せた値の代わりに返す文字列
+const redactedValue = "[REDACTED]"
+
+// checkDebugAddr はデバッグ用のリスナーのアドレスがループバックアドレスであることを検証する。
+// プロファイルや設定を公開しないよう、外部から接続できるアドレスは指定できない。
+func checkDebugAddr(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("host:portの形式で指定してください: %s", addr)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("ループバックアドレスを指定してください: %s", addr)
+}
+
+// debugConfig は起動オプションをフラグ名と値の組にする。redact:"true"のオプションは値が設定されている場合に伏せる。
+func debugConfig(o *model.Options) map[string]any {
+	config := make(map[string]any)
+	v := reflect.ValueOf(o).Elem()
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name := field.Tag.Get("name")
+		if name == "" {
+			name = casing.Kebab(field.Name)
+		}
+		value := v.Field(i)
+		switch {
+		case field.Tag.Get("redact") == "true" && !value.IsZero():
+			config[name] = redactedValue
+		case field.Type == reflect.TypeFor[time.Duration]():
+			config[name] = value.Interface().(time.Duration).String()
+		default:
+			config[name] = value.Interface()
+		}
+	}
+	return config
+}
+
+// debugVars はexpvarに公開された変数をJSONで書き込む。
+// コマンドライン引数には伏せるべきオプションが含まれるため、cmdlineは除く。
+func debugVars(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := make(map[string]json.RawMessage)
+	expvar.Do(func(kv expvar.KeyValue) {
+		if kv.Key != "cmdline" {
+			vars[kv.Key] = json.RawMessage(kv.Value.String())
+		}
+	})
+	if err := json.NewEncoder(w).Encode(vars); err != nil {
+		slog.Warn("実行時の変数の書き込みに失敗", "err", err)
+	}
+}
+
+// newDebugHandler はpprof、expvar、起動オプションを返すデバッグ用のハンドラーを生成する。
+// コマンドライン引数を返すpprofのcmdlineは、/debug/varsと同じ理由で登録しない。
+func newDebugHandler(o *model.Options) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("GET /debug/vars", debugVars)
+	mux.HandleFunc("GET /debug/config", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(debugConfig(o)); err != nil {
+			slog.Warn("起動オプションの書き込みに失敗", "err", err)
+		}
+	})
+	return mux
+}
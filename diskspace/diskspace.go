@@ -0,0 +1,149 @@
+// Package diskspace はデータベースのファイルがあるディスクの空き容量を監視する機能を提供する。
+// 空き容量が下限を下回るとMonitorが書き込みの拒否を求める状態になり、通知を送る。
+// SQLiteはディスクが一杯になると書き込みの途中で失敗するため、余裕を残して変更を止める。
+package diskspace
+
+import (
+	"context"
+	"fmt"
+	"go-huma-test/locale"
+	"go-huma-test/notify"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Status はディスクの空き容量の確認結果を表す構造体。/readyzで返す
+type Status struct {
+	FreeBytes    uint64    `json:"free_bytes"`
+	TotalBytes   uint64    `json:"total_bytes"`
+	MinFreeBytes uint64    `json:"min_free_bytes"`
+	Low          bool      `json:"low"`
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+// Monitor はディレクトリがあるファイルシステムの空き容量を一定間隔で確認し、下限を下回ったかを保持する。
+// 下限を下回ったときと回復したときに1回ずつ通知する。
+type Monitor struct {
+	dir       string
+	minFree   uint64
+	interval  time.Duration
+	notifier  notify.Notifier
+	channel   string
+	formatter *locale.Formatter
+
+	mu     sync.RWMutex
+	status Status
+	// checked は一度でも空き容量を取得できたか
+	checked bool
+}
+
+// NewMonitor はMonitorの新しいインスタンスを生成する。dirのファイルシステムの空き容量がminFreeバイトを下回ると書き込みを拒否する。
+// 通知はchannelで送り、本文の容量はformatterの言語で書く。
+func NewMonitor(dir string, minFree uint64, interval time.Duration, notifier notify.Notifier, channel string, formatter *locale.Formatter) *Monitor {
+	return &Monitor{
+		dir:       dir,
+		minFree:   minFree,
+		interval:  interval,
+		notifier:  notifier,
+		channel:   channel,
+		formatter: formatter,
+	}
+}
+
+// Low は空き容量が下限を下回っているかを返す。まだ確認していない場合や取得に失敗した場合は書き込みを止めない
+func (m *Monitor) Low() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status.Low
+}
+
+// Status は最後の確認結果を返す。まだ空き容量を取得できていない場合はfalseを返す
+func (m *Monitor) Status() (Status, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status, m.checked
+}
+
+// Run はctxがキャンセルされるまで空き容量を確認する
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	slog.Info("ディスクの空き容量の監視を開始", "dir", m.dir, "min_free_bytes", m.minFree, "interval", m.interval)
+	m.Check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("ディスクの空き容量の監視を停止")
+			return
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
+
+// Check は空き容量を取得して状態を更新し、下限を下回ったときと回復したときに通知する。
+// 取得に失敗した場合は前回の状態を保つ。
+func (m *Monitor) Check(ctx context.Context) {
+	free, total, err := usage(m.dir)
+	if err != nil {
+		slog.Warn("ディスクの空き容量の取得に失敗", "dir", m.dir, "err", err)
+		return
+	}
+
+	m.mu.Lock()
+	wasLow := m.status.Low
+	m.status = Status{
+		FreeBytes:    free,
+		TotalBytes:   total,
+		MinFreeBytes: m.minFree,
+		Low:          free < m.minFree,
+		CheckedAt:    time.Now(),
+	}
+	m.checked = true
+	status := m.status
+	m.mu.Unlock()
+
+	if status.Low == wasLow {
+		return
+	}
+	f := m.formatter
+	var n notify.Notification
+	if status.Low {
+		slog.Warn("ディスクの空き容量が下限を下回ったため書き込みを拒否", "dir", m.dir, "free_bytes", free, "min_free_bytes", m.minFree)
+		n = notify.Notification{
+			Kind:  notify.KindDiskSpace,
+			Title: f.Label("ディスクの空き容量が不足しているため変更を受け付けていません", "Changes are rejected because disk space is low"),
+			Message: fmt.Sprintf(f.Label("%[1]sの空き容量が%[2]sで、下限の%[3]sを下回りました。空き容量が回復するまで、削除以外の変更は507を返します。",
+				"Free space on %[1]s is %[2]s, below the minimum of %[3]s. Changes other than deletions return 507 until space is freed."),
+				m.dir, formatBytes(free), formatBytes(m.minFree)),
+		}
+	} else {
+		slog.Info("ディスクの空き容量が回復したため書き込みを再開", "dir", m.dir, "free_bytes", free, "min_free_bytes", m.minFree)
+		n = notify.Notification{
+			Kind:  notify.KindDiskSpace,
+			Title: f.Label("ディスクの空き容量が回復し、変更の受け付けを再開しました", "Disk space recovered and changes are accepted again"),
+			Message: fmt.Sprintf(f.Label("%[1]sの空き容量が%[2]sに回復しました（下限%[3]s）。", "Free space on %[1]s recovered to %[2]s (minimum %[3]s)."),
+				m.dir, formatBytes(free), formatBytes(m.minFree)),
+		}
+	}
+	n.Channel = m.channel
+	if err := m.notifier.Notify(ctx, n); err != nil {
+		slog.Warn("ディスクの空き容量の通知に失敗", "channel", m.channel, "err", err)
+	}
+}
+
+// formatBytes はバイト数を1024を単位とした読みやすい値にする
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
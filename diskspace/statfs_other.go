@@ -0,0 +1,10 @@
+//go:build !unix
+
+package diskspace
+
+import "errors"
+
+// usage はこのOSでは対応していないため、常にエラーを返す
+func usage(string) (uint64, uint64, error) {
+	return 0, 0, errors.New("このOSではディスクの空き容量を取得できません")
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package diskspace
+
+import "syscall"
+
+// usage はdirがあるファイルシステムの、特権のないプロセスが使える空き容量と全体の容量をバイト数で返す
+func usage(dir string) (free, total uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), uint64(st.Blocks) * uint64(st.Bsize), nil
+}
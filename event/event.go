@@ -0,0 +1,123 @@
+// Package event はTodoの変更イベントを購読者へ配信するイベントバスを提供する。
+// ハンドラーはトランザクションのコミット後にイベントを発行し、
+// フィードなどの購読者はバスからイベントを受け取って処理する。
+// ハンドラーは同じイベントをトランザクションの中でoutboxにも記録し、プロセスの外への送信はoutboxの中継が行う。
+package event
+
+import (
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Type はイベントの種類を表す
+type Type string
+
+const (
+	// TodoCreated はTodoが作成されたことを表す
+	TodoCreated Type = "todo.created"
+	// TodoUpdated はTodoが更新されたことを表す
+	TodoUpdated Type = "todo.updated"
+	// TodoDeleted はTodoが削除されたことを表す
+	TodoDeleted Type = "todo.deleted"
+	// TodoMoved はTodoが別のリスト（プロジェクト）や並び順に移動したことを表す
+	TodoMoved Type = "todo.moved"
+	// TodoCommented はTodoにコメントが投稿されたことを表す
+	TodoCommented Type = "todo.commented"
+	// TodoCompleted はTodoが完了したことを表す。バスには発行せず、CompletedのTodoUpdatedを購読者が区別する場合に使う
+	TodoCompleted Type = "todo.completed"
+	// TodoAssigned はTodoに担当者が設定されたことを表す。バスには発行せず、AssignedのTodoCreatedとTodoUpdatedを購読者が区別する場合に使う
+	TodoAssigned Type = "todo.assigned"
+	// TodoOverdue は未完了のTodoが期限を過ぎたことを表す。バスには発行せず、期限を監視する購読者が使う
+	TodoOverdue Type = "todo.overdue"
+)
+
+// Event はTodoに対する1件の変更を表す構造体
+type Event struct {
+	Type   Type
+	TodoID int64
+	// OwnerID はTodoの所有者。削除されたTodoは後から所有者を取得できないため、TodoDeletedでは発行元が設定する
+	OwnerID sql.NullInt64
+	// Completed はTodoUpdatedの更新でTodoが未完了から完了になったか
+	Completed bool
+	// Assigned はTodoCreatedとTodoUpdatedで担当者が設定されたか、別の担当者に変わったか。
+	// チャットへの投稿などバスの購読者だけが使い、outboxには記録しない
+	Assigned bool
+	// CommentID はTodoCommentedの場合に投稿されたコメントのID
+	CommentID  int64
+	OccurredAt time.Time
+}
+
+// Bus はプロセス内でイベントを配信するイベントバス
+type Bus struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   map[int]chan Event
+	closed bool
+}
+
+// NewBus はBusの新しいインスタンスを生成する
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[int]chan Event),
+	}
+}
+
+// Publish はすべての購読者にイベントを配信する。
+// 購読者のバッファが一杯の場合、そのイベントは破棄される。
+func (b *Bus) Publish(e Event) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for id, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			slog.Warn("購読者のバッファが一杯のためイベントを破棄", "subscriber", id, "type", e.Type, "todo_id", e.TodoID)
+		}
+	}
+}
+
+// Subscribe はイベントを受け取るチャネルと購読解除関数を返す。
+// Closeの後に購読した場合は閉じたチャネルを返す。
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, buffer)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = ch
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		// Closeで閉じたチャネルは購読者から除かれている
+		if _, ok := b.subs[id]; ok {
+			delete(b.subs, id)
+			close(ch)
+		}
+	}
+}
+
+// Close はすべての購読者のチャネルを閉じ、以降のイベントを配信しない。
+// SSEの配信のように購読し続けるハンドラーを、サーバーの停止時に終了させるために使う。
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for id, ch := range b.subs {
+		delete(b.subs, id)
+		close(ch)
+	}
+}
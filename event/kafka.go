@@ -0,0 +1,110 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// KafkaSink はfranz-goのクライアントでイベントをKafkaに送る送信先。
+// メッセージのキーをTodoのIDにし、同じTodoのイベントを同じパーティションに送って順序を保つ。
+// ブローカーのすべての同期レプリカが書き込むまで待つ（acks=all）。TLSとSASLには対応しない。
+type KafkaSink struct {
+	bootstrap string
+	topic     string
+
+	// mu はクライアントの生成と破棄を直列にする
+	mu     sync.Mutex
+	client *kgo.Client
+}
+
+// NewKafkaSink はkafka://host:9092/topicのURLからKafkaSinkを生成する。
+// hostには最初に接続するブローカーを指定し、送信先のブローカーはメタデータから探す。
+func NewKafkaSink(u *url.URL) (*KafkaSink, error) {
+	topic := strings.Trim(u.Path, "/")
+	if topic == "" {
+		return nil, fmt.Errorf("Kafkaの送信先のURLにトピックがありません: %s", u.Redacted())
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "9092")
+	}
+	return &KafkaSink{
+		bootstrap: addr,
+		topic:     topic,
+	}, nil
+}
+
+// Name はログに出す送信先の名前を返す
+func (s *KafkaSink) Name() string {
+	return fmt.Sprintf("kafka://%s/%s", s.bootstrap, s.topic)
+}
+
+// Send はメッセージのキーで決めたパーティションに送り、ブローカーが書き込むまで待つ。
+// 接続やリーダーの変更への追従はクライアントが行い、ctxの期限まで再試行する。
+func (s *KafkaSink) Send(ctx context.Context, m Message) error {
+	record, err := kafkaRecord(m)
+	if err != nil {
+		return err
+	}
+	client, err := s.open()
+	if err != nil {
+		return err
+	}
+	if err := client.ProduceSync(ctx, record).FirstErr(); err != nil {
+		return fmt.Errorf("Kafkaへの送信に失敗: %w", err)
+	}
+	return nil
+}
+
+// Close はブローカーとの接続を閉じる
+func (s *KafkaSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+	return nil
+}
+
+// open はクライアントを返す。ブローカーへの接続は最初の送信で行う
+func (s *KafkaSink) open() (*kgo.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		return s.client, nil
+	}
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(s.bootstrap),
+		kgo.DefaultProduceTopic(s.topic),
+		kgo.ClientID(clientName),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		// 1件ずつ記録した順に送るため、キーだけでパーティションを決める
+		kgo.RecordPartitioner(kgo.StickyKeyPartitioner(nil)),
+		kgo.ProducerLinger(0),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Kafkaのクライアントの生成に失敗: %w", err)
+	}
+	s.client = client
+	return client, nil
+}
+
+// kafkaRecord はメッセージをKafkaのレコードにする。イベントの種類はtypeヘッダーにも入れ、本文を読まずに選べるようにする
+func kafkaRecord(m Message) (*kgo.Record, error) {
+	value, err := m.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return &kgo.Record{
+		Key:     []byte(m.Key()),
+		Value:   value,
+		Headers: []kgo.RecordHeader{{Key: "type", Value: []byte(m.Type)}},
+	}, nil
+}
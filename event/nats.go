@@ -0,0 +1,124 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsDefaultSubject はURLに件名がない場合に使う件名の接頭辞
+const natsDefaultSubject = "todos"
+
+// natsConnectTimeout はctxに期限がない場合にサーバーへの接続を待つ時間
+const natsConnectTimeout = 5 * time.Second
+
+// NATSSink はnats.goのクライアントでイベントをNATSに送る送信先。
+// 件名は<接頭辞>.<イベントの種類>（todos.todo.createdなど）にし、購読側がワイルドカードで種類を選べるようにする。
+// 送信ごとにフラッシュしてサーバーが受け取ったことを確かめる。
+type NATSSink struct {
+	addr    string
+	subject string
+	user    string
+	pass    string
+	token   string
+
+	// mu は接続の生成と破棄を直列にする
+	mu   sync.Mutex
+	conn *nats.Conn
+}
+
+// NewNATSSink はnats://[user:pass@]host:4222/subjectのURLからNATSSinkを生成する。
+// ユーザー名だけを指定した場合はトークンとして送る。
+func NewNATSSink(u *url.URL) *NATSSink {
+	s := &NATSSink{
+		addr:    u.Host,
+		subject: strings.Trim(u.Path, "/"),
+	}
+	if u.Port() == "" {
+		s.addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	if s.subject == "" {
+		s.subject = natsDefaultSubject
+	}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			s.user, s.pass = u.User.Username(), pass
+		} else {
+			s.token = u.User.Username()
+		}
+	}
+	return s
+}
+
+// Name はログに出す送信先の名前を返す
+func (s *NATSSink) Name() string {
+	return fmt.Sprintf("nats://%s/%s", s.addr, s.subject)
+}
+
+// Send はメッセージを送り、フラッシュのPONGを待つ。接続が閉じている場合はつなぎ直す
+func (s *NATSSink) Send(ctx context.Context, m Message) error {
+	payload, err := m.Encode()
+	if err != nil {
+		return err
+	}
+	conn, err := s.open(ctx)
+	if err != nil {
+		return err
+	}
+	if err := conn.Publish(s.subject+"."+string(m.Type), payload); err != nil {
+		return fmt.Errorf("NATSへの送信に失敗: %w", err)
+	}
+	if err := conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("NATSへの送信に失敗: %w", err)
+	}
+	return nil
+}
+
+// Close はサーバーとの接続を閉じる
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	return nil
+}
+
+// open はサーバーとの接続を返す。接続は最初の送信で行い、閉じた接続はつなぎ直す
+func (s *NATSSink) open(ctx context.Context) (*nats.Conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil && !s.conn.IsClosed() {
+		return s.conn, nil
+	}
+
+	timeout := natsConnectTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	opts := []nats.Option{
+		nats.Name(clientName),
+		nats.Timeout(timeout),
+		// 切断中の送信はバッファに溜めずに失敗させ、outboxの再送に任せる
+		nats.ReconnectBufSize(-1),
+	}
+	if s.user != "" {
+		opts = append(opts, nats.UserInfo(s.user, s.pass))
+	}
+	if s.token != "" {
+		opts = append(opts, nats.Token(s.token))
+	}
+	conn, err := nats.Connect("nats://"+s.addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("NATSへの接続に失敗: %w", err)
+	}
+	s.conn = conn
+	return conn, nil
+}
@@ -0,0 +1,91 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 送信先のURLのスキーム
+const (
+	SchemeNATS  = "nats"
+	SchemeKafka = "kafka"
+)
+
+// clientName はブローカーのログに出るクライアントの名前
+const clientName = "todo-api"
+
+// Message はプロセスの外へ送るイベントの表現。購読者が受け取るEventと異なり、
+// Completedの更新はTodoUpdatedとTodoCompletedの2件のメッセージにする。
+type Message struct {
+	// ID はoutboxでのイベントのID。送信は少なくとも1回のため、同じイベントは同じIDとtypeで再送されることがある
+	ID     int64 `json:"id"`
+	Type   Type  `json:"type"`
+	TodoID int64 `json:"todo_id"`
+	// OwnerID はTodoの所有者。TodoDeletedの場合のみ設定する
+	OwnerID *int64 `json:"owner_id,omitempty"`
+	// CommentID は投稿されたコメント。TodoCommentedの場合のみ設定する
+	CommentID  *int64    `json:"comment_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Key はメッセージの分割のキー。同じTodoのメッセージを同じ順に届けるため、TodoのIDにする
+func (m Message) Key() string {
+	return strconv.FormatInt(m.TodoID, 10)
+}
+
+// Encode はメッセージをJSONにする
+func (m Message) Encode() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// Messages はoutboxに記録したイベントを外へ送るメッセージにする
+func Messages(id int64, e Event) []Message {
+	m := Message{ID: id, Type: e.Type, TodoID: e.TodoID, OccurredAt: e.OccurredAt.UTC()}
+	if e.OwnerID.Valid {
+		m.OwnerID = &e.OwnerID.Int64
+	}
+	if e.CommentID != 0 {
+		m.CommentID = &e.CommentID
+	}
+	msgs := []Message{m}
+	if e.Completed {
+		m.Type = TodoCompleted
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+// Sink はoutboxに記録したイベントをNATSやKafkaなどのブローカーへ送る送信先のインターフェース。
+// Sendはoutboxの中継が1つのゴルーチンから記録した順に呼び出す。
+type Sink interface {
+	// Name はログに出す送信先の名前を返す。資格情報は含めない
+	Name() string
+	// Send はメッセージを送り、ブローカーが受け取ったことを確かめるまで待つ
+	Send(ctx context.Context, m Message) error
+	// Close はブローカーへの接続を閉じる
+	Close() error
+}
+
+// OpenSink はnats://host:4222/subjectまたはkafka://host:9092/topicの形式の送信先を開く。
+// 接続は最初の送信で行う。
+func OpenSink(rawURL string) (Sink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("イベントの送信先のURLが不正です: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("イベントの送信先のURLにホストがありません: %s", u.Redacted())
+	}
+	switch u.Scheme {
+	case SchemeNATS:
+		return NewNATSSink(u), nil
+	case SchemeKafka:
+		return NewKafkaSink(u)
+	default:
+		return nil, fmt.Errorf("イベントの送信先のURLはnats://host:4222/subjectまたはkafka://host:9092/topicの形式で指定してください: %s", u.Redacted())
+	}
+}
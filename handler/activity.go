@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"go-huma-test/activity"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// recordActivity はTodoの変更をアクティビティログに記録する。qtxには変更と同じトランザクションを渡す。
+func recordActivity(ctx context.Context, qtx *db.Queries, action string, before, after *db.Todo) error {
+	if err := activity.Record(ctx, qtx, action, before, after); err != nil {
+		slog.Warn("アクティビティの記録に失敗", "err", err)
+		return huma.Error500InternalServerError("アクティビティの記録に失敗", err)
+	}
+	return nil
+}
+
+// recordDeletions は削除されたTodoごとに削除のアクティビティと削除イベントを記録する
+func recordDeletions(ctx context.Context, qtx *db.Queries, todos []db.Todo) error {
+	for _, t := range todos {
+		if err := recordActivity(ctx, qtx, activity.ActionDelete, &t, nil); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, qtx, event.Event{Type: event.TodoDeleted, TodoID: t.ID, OwnerID: t.OwnerID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ActivityHandler はアクティビティログの参照を処理するハンドラー
+type ActivityHandler struct {
+	queries *db.Queries
+}
+
+// NewActivityHandler はActivityHandlerの新しいインスタンスを生成する
+func NewActivityHandler(queries *db.Queries) *ActivityHandler {
+	return &ActivityHandler{
+		queries: queries,
+	}
+}
+
+// toActivityPage はアクティビティログをページに変換する。logsにはlimitより1件多く取得したものを渡す。
+func toActivityPage(logs []db.ActivityLog, limit int64) model.ActivityPage {
+	var page model.ActivityPage
+	if int64(len(logs)) > limit {
+		logs = logs[:limit]
+		page.NextCursor = &logs[len(logs)-1].ID
+	}
+	page.Activities = make([]model.ActivityResponse, len(logs))
+	for i, l := range logs {
+		diff := map[string]model.ActivityChange{}
+		if err := json.Unmarshal([]byte(l.Diff), &diff); err != nil {
+			slog.Warn("アクティビティの変更内容の解析に失敗", "id", l.ID, "err", err)
+		}
+		page.Activities[i] = model.ActivityResponse{
+			ID:        l.ID,
+			TodoID:    l.TodoID,
+			Actor:     l.Actor,
+			Action:    l.Action,
+			Diff:      diff,
+			CreatedAt: l.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	return page
+}
+
+// ListTodoHistory は指定されたTodoの変更履歴を新しい順に取得する
+func (h *ActivityHandler) ListTodoHistory(ctx context.Context, input *model.ListTodoHistoryInput) (*model.ListTodoHistoryOutput, error) {
+	// 次のページの有無を判定するため1件多く取得する
+	logs, err := h.queries.ListTodoActivity(ctx, db.ListTodoActivityParams{
+		TodoID:   input.TodoID,
+		BeforeID: input.Cursor,
+		Limit:    input.Limit + 1,
+	})
+	if err != nil {
+		slog.Warn("Todoの変更履歴の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("Todoの変更履歴の取得に失敗", err)
+	}
+
+	return &model.ListTodoHistoryOutput{Body: toActivityPage(logs, input.Limit)}, nil
+}
+
+// ListActivity はすべてのTodoの変更履歴を新しい順に取得する
+func (h *ActivityHandler) ListActivity(ctx context.Context, input *model.ListActivityInput) (*model.ListActivityOutput, error) {
+	logs, err := h.queries.ListActivity(ctx, db.ListActivityParams{
+		BeforeID: input.Cursor,
+		Limit:    input.Limit + 1,
+	})
+	if err != nil {
+		slog.Warn("アクティビティ一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("アクティビティ一覧の取得に失敗", err)
+	}
+
+	return &model.ListActivityOutput{Body: toActivityPage(logs, input.Limit)}, nil
+}
@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"go-huma-test/tracing"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// tableRefPattern はSQLのFROM句やJOIN句からテーブル名と別名を取り出す
+var tableRefPattern = regexp.MustCompile(`(?i)\b(?:FROM|JOIN)\s+(\w+)(?:\s+(?:AS\s+)?(\w+))?`)
+
+// aliasStopWords はテーブル名の後に続いても別名ではない語
+var aliasStopWords = map[string]bool{
+	"WHERE": true, "ON": true, "JOIN": true, "LEFT": true, "INNER": true, "CROSS": true,
+	"ORDER": true, "GROUP": true, "LIMIT": true, "USING": true, "SET": true, "AND": true,
+	"OR": true, "UNION": true, "HAVING": true, "WINDOW": true, "RETURNING": true,
+}
+
+// AdvisorHandler はデータベースの実行計画を診断するハンドラー
+type AdvisorHandler struct {
+	db *sql.DB
+}
+
+// NewAdvisorHandler はAdvisorHandlerの新しいインスタンスを生成する
+func NewAdvisorHandler(db *sql.DB) *AdvisorHandler {
+	return &AdvisorHandler{
+		db: db,
+	}
+}
+
+// GetDBAdvisor は頻繁に実行されるクエリの実行計画を現在の統計情報で取得し、
+// テーブルの全件走査や一時B-treeによる並べ替え、インデックスのない外部キーを報告する
+func (h *AdvisorHandler) GetDBAdvisor(ctx context.Context, input *model.GetDBAdvisorInput) (*model.GetDBAdvisorOutput, error) {
+	if input.Analyze {
+		// ANALYZEはすべてのテーブルとインデックスを走査するため、query-timeoutの上限を適用しない
+		if _, err := h.db.ExecContext(tracing.WithQueryTimeout(ctx, 0), "ANALYZE"); err != nil {
+			slog.Warn("ANALYZEの実行に失敗", "err", err)
+			return nil, huma.Error500InternalServerError("ANALYZEの実行に失敗", err)
+		}
+	}
+
+	tables, rows, err := h.tableStats(ctx)
+	if err != nil {
+		slog.Warn("テーブルの行数の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("テーブルの行数の取得に失敗", err)
+	}
+
+	missing, err := h.missingIndexes(ctx, rows, input.MinRows)
+	if err != nil {
+		slog.Warn("外部キーのインデックスの確認に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("外部キーのインデックスの確認に失敗", err)
+	}
+
+	queries, err := h.explainHotQueries(ctx, rows, input.MinRows)
+	if err != nil {
+		slog.Warn("実行計画の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("実行計画の取得に失敗", err)
+	}
+
+	output := &model.GetDBAdvisorOutput{}
+	output.Body.Analyzed = input.Analyze
+	output.Body.Tables = tables
+	output.Body.Queries = queries
+	output.Body.MissingIndexes = missing
+	return output, nil
+}
+
+// explainHotQueries は頻繁に実行されるクエリごとに実行計画を取得して診断する。
+// 接続を1つに制限している場合があるため、取得中はほかのクエリを実行しない。
+func (h *AdvisorHandler) explainHotQueries(ctx context.Context, rows map[string]int64, minRows int64) ([]model.QueryPlanResponse, error) {
+	conn, err := h.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("データベース接続の取得に失敗: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	queries := make([]model.QueryPlanResponse, len(db.HotQueries))
+	for i, q := range db.HotQueries {
+		plan, err := explainQueryPlan(ctx, conn, q.SQL)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", q.Name, err)
+		}
+		queries[i] = model.QueryPlanResponse{
+			Name:     q.Name,
+			Plan:     plan,
+			Findings: planFindings(q.SQL, plan, rows, minRows),
+		}
+	}
+	return queries, nil
+}
+
+// tableStats はテーブルごとの行数を名前順に返す
+func (h *AdvisorHandler) tableStats(ctx context.Context) ([]model.TableStatsResponse, map[string]int64, error) {
+	names, err := h.tableNames(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tables := make([]model.TableStatsResponse, len(names))
+	rows := make(map[string]int64, len(names))
+	for i, name := range names {
+		var n int64
+		// テーブル名はsqlite_masterから取得したもので、利用者の入力を含まない
+		if err := h.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM "%s"`, name)).Scan(&n); err != nil {
+			return nil, nil, fmt.Errorf("%sの行数の取得に失敗: %w", name, err)
+		}
+		tables[i] = model.TableStatsResponse{Name: name, Rows: n}
+		rows[name] = n
+	}
+	return tables, rows, nil
+}
+
+// tableNames はSQLiteの内部テーブルを除くテーブル名を名前順に返す
+func (h *AdvisorHandler) tableNames(ctx context.Context) ([]string, error) {
+	rs, err := h.db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rs.Close()
+	}()
+
+	var names []string
+	for rs.Next() {
+		var name string
+		if err := rs.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rs.Err()
+}
+
+// explainQueryPlan はクエリの実行計画を取得する。
+// 実行計画はパラメータの値に依存しないため、すべてのパラメータにNULLを渡す。
+func explainQueryPlan(ctx context.Context, conn *sql.Conn, query string) ([]model.QueryPlanStep, error) {
+	stmt := "EXPLAIN QUERY PLAN " + query
+
+	// ?1のような番号付きのパラメータを含むため、パラメータの数はドライバーに数えさせる
+	var params int
+	if err := conn.Raw(func(dc any) error {
+		s, err := dc.(driver.Conn).Prepare(stmt)
+		if err != nil {
+			return err
+		}
+		params = s.NumInput()
+		return s.Close()
+	}); err != nil {
+		return nil, err
+	}
+
+	rs, err := conn.QueryContext(ctx, stmt, make([]any, params)...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rs.Close()
+	}()
+
+	var plan []model.QueryPlanStep
+	for rs.Next() {
+		var step model.QueryPlanStep
+		var notUsed int64
+		if err := rs.Scan(&step.ID, &step.Parent, &notUsed, &step.Detail); err != nil {
+			return nil, err
+		}
+		plan = append(plan, step)
+	}
+	return plan, rs.Err()
+}
+
+// tableAliases はクエリ内の別名から実際のテーブル名への対応を返す
+func tableAliases(query string) map[string]string {
+	aliases := make(map[string]string)
+	for _, m := range tableRefPattern.FindAllStringSubmatch(query, -1) {
+		aliases[m[1]] = m[1]
+		if m[2] != "" && !aliasStopWords[strings.ToUpper(m[2])] {
+			aliases[m[2]] = m[1]
+		}
+	}
+	return aliases
+}
+
+// planFindings は実行計画からインデックスを使わない全件走査と一時B-treeによる処理を探す。
+// 全件走査はテーブルの行数がminRows以上の場合にwarningとする。
+func planFindings(query string, plan []model.QueryPlanStep, rows map[string]int64, minRows int64) []model.AdvisorFinding {
+	aliases := tableAliases(query)
+	findings := []model.AdvisorFinding{}
+	for _, step := range plan {
+		switch {
+		case strings.HasPrefix(step.Detail, "SCAN "):
+			fields := strings.Fields(step.Detail)
+			// "SCAN t USING INDEX ..."はインデックス順の走査、"SCAN CONSTANT ROW"などはテーブルを読まない
+			if len(fields) != 2 {
+				continue
+			}
+			table, ok := aliases[fields[1]]
+			if !ok {
+				continue
+			}
+			if _, ok := rows[table]; !ok {
+				continue
+			}
+			severity := model.SeverityInfo
+			if rows[table] >= minRows {
+				severity = model.SeverityWarning
+			}
+			findings = append(findings, model.AdvisorFinding{
+				Severity: severity,
+				Table:    table,
+				Rows:     rows[table],
+				Detail:   step.Detail,
+				Message:  fmt.Sprintf("%sをインデックスを使わずに全件走査しています。検索条件の列にインデックスを追加してください", table),
+			})
+		case strings.HasPrefix(step.Detail, "USE TEMP B-TREE"):
+			findings = append(findings, model.AdvisorFinding{
+				Severity: model.SeverityInfo,
+				Detail:   step.Detail,
+				Message:  "一時B-treeで並べ替えやグループ化をしています。結果の件数が多い場合はORDER BYやGROUP BYの列を含むインデックスを検討してください",
+			})
+		}
+	}
+	return findings
+}
+
+// missingIndexes は先頭の列としてインデックスに含まれていない外部キーを探す。
+// 外部キーにインデックスがないと、参照先の削除や参照元の絞り込みのたびに全件走査になる。
+func (h *AdvisorHandler) missingIndexes(ctx context.Context, rows map[string]int64, minRows int64) ([]model.MissingIndexResponse, error) {
+	rs, err := h.db.QueryContext(ctx, `SELECT m.name, fk."from", fk."table"
+FROM sqlite_master AS m
+JOIN pragma_foreign_key_list(m.name) AS fk
+WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%' AND fk.seq = 0
+  AND NOT EXISTS (
+    SELECT 1 FROM pragma_index_list(m.name) AS il
+    JOIN pragma_index_info(il.name) AS ii
+    WHERE ii.seqno = 0 AND ii.name = fk."from"
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM pragma_table_info(m.name) AS ti
+    WHERE ti.name = fk."from" AND ti.pk = 1 AND upper(ti.type) = 'INTEGER'
+  )
+ORDER BY m.name, fk."from"`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rs.Close()
+	}()
+
+	missing := []model.MissingIndexResponse{}
+	for rs.Next() {
+		var m model.MissingIndexResponse
+		if err := rs.Scan(&m.Table, &m.Column, &m.References); err != nil {
+			return nil, err
+		}
+		m.Rows = rows[m.Table]
+		m.Severity = model.SeverityInfo
+		if m.Rows >= minRows {
+			m.Severity = model.SeverityWarning
+		}
+		m.Suggestion = fmt.Sprintf("CREATE INDEX idx_%s_%s ON %s(%s);", m.Table, m.Column, m.Table, m.Column)
+		missing = append(missing, m)
+	}
+	return missing, rs.Err()
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/locale"
+	"go-huma-test/model"
+	"go-huma-test/recurrence"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// agendaMaxOccurrences は繰り返しのTodoの次の回を探すときに進める回数の上限。
+// 期限がかなり前のDAILYのTodoでも探し終え、壊れたルールで止まらないようにする
+const agendaMaxOccurrences = 5000
+
+// agendaKindOrder は同じ時刻の項目を並べる順
+var agendaKindOrder = map[string]int{
+	model.AgendaDue:        0,
+	model.AgendaReminder:   1,
+	model.AgendaRecurrence: 2,
+}
+
+// agendaMarkdownEscaper はMarkdownで書式として解釈される文字をエスケープする
+var agendaMarkdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`,
+	"#", `\#`, "<", `\<`, ">", `\>`, "|", `\|`, "\n", " ", "\r", " ",
+)
+
+// AgendaHandler は1日の予定をまとめたアジェンダを処理するハンドラー
+type AgendaHandler struct {
+	queries *db.Queries
+	clock   clock.Clock
+}
+
+// NewAgendaHandler はAgendaHandlerの新しいインスタンスを生成する。日付を省略した場合はclockの今日のアジェンダを返す
+func NewAgendaHandler(queries *db.Queries, clock clock.Clock) *AgendaHandler {
+	return &AgendaHandler{
+		queries: queries,
+		clock:   clock,
+	}
+}
+
+// GetAgenda は指定した日が期限のTodo、その日に通知するリマインダー、その日に作成される繰り返しのTodoの次の回を
+// 時刻の順に並べたアジェンダを、JSONまたはMarkdownで返す。日付の範囲はタイムゾーンでの0時から翌日の0時まで。
+func (h *AgendaHandler) GetAgenda(ctx context.Context, input *model.AgendaInput) (*model.AgendaOutput, error) {
+	formatter := locale.FormatterFrom(ctx)
+	loc := formatter.Location()
+	if input.TZ != "" {
+		l, err := time.LoadLocation(input.TZ)
+		if err != nil {
+			return nil, huma.Error422UnprocessableEntity(fmt.Sprintf("タイムゾーンが不正です: %s", input.TZ), &huma.ErrorDetail{
+				Location: "query.tz",
+				Value:    input.TZ,
+			})
+		}
+		loc = l
+		formatter = locale.New(formatter.Language(), loc)
+	}
+
+	day := h.clock.Now().In(loc)
+	if input.Date != "" {
+		d, err := time.ParseInLocation(time.DateOnly, input.Date, loc)
+		if err != nil {
+			return nil, huma.Error422UnprocessableEntity(fmt.Sprintf("日付が不正です: %s", input.Date), &huma.ErrorDetail{
+				Location: "query.date",
+				Value:    input.Date,
+			})
+		}
+		day = d
+	}
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 0, 1)
+
+	items, err := h.agendaItems(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	agenda := model.AgendaResponse{
+		Date:     start.Format(time.DateOnly),
+		Timezone: loc.String(),
+		Items:    items,
+	}
+
+	output := &model.AgendaOutput{ContentLanguage: formatter.Language()}
+	if input.Format == model.AgendaFormatMarkdown {
+		output.ContentType = "text/markdown; charset=utf-8"
+		output.Body = []byte(agendaMarkdown(formatter, start, items))
+		return output, nil
+	}
+	body, err := json.Marshal(agenda)
+	if err != nil {
+		slog.Warn("アジェンダの生成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("アジェンダの生成に失敗", err)
+	}
+	output.ContentType = "application/json"
+	output.Body = body
+	return output, nil
+}
+
+// agendaItems は[start, end)の期限、リマインダー、繰り返しの次の回を時刻の順に返す。時刻はstartのタイムゾーンで表す
+func (h *AgendaHandler) agendaItems(ctx context.Context, start, end time.Time) ([]model.AgendaItem, error) {
+	owner := ownerID(ctx)
+	dayStart := sql.NullTime{Time: start.UTC(), Valid: true}
+	dayEnd := sql.NullTime{Time: end.UTC(), Valid: true}
+	loc := start.Location()
+	items := []model.AgendaItem{}
+
+	todos, err := h.queries.ListAgendaTodos(ctx, db.ListAgendaTodosParams{OwnerID: owner, DayStart: dayStart, DayEnd: dayEnd})
+	if err != nil {
+		slog.Warn("アジェンダのTodoの取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("アジェンダのTodoの取得に失敗", err)
+	}
+	for _, t := range todos {
+		items = append(items, agendaTodoItem(ctx, model.AgendaDue, t, t.DueAt.Time.In(loc)))
+	}
+
+	reminders, err := h.queries.ListAgendaReminders(ctx, db.ListAgendaRemindersParams{OwnerID: owner, DayStart: start.UTC(), DayEnd: end.UTC()})
+	if err != nil {
+		slog.Warn("アジェンダのリマインダーの取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("アジェンダのリマインダーの取得に失敗", err)
+	}
+	for _, r := range reminders {
+		at := r.RemindAt.In(loc)
+		sent := r.SentAt.Valid
+		items = append(items, model.AgendaItem{
+			Kind:       model.AgendaReminder,
+			At:         at.Format(time.RFC3339),
+			Time:       at.Format("15:04"),
+			TodoID:     r.TodoID,
+			Title:      r.Title,
+			Completed:  r.Completed == 1,
+			ReminderID: &r.ID,
+			Channel:    &r.Channel,
+			Sent:       &sent,
+		})
+	}
+
+	recurring, err := h.queries.ListPendingRecurringTodos(ctx, db.ListPendingRecurringTodosParams{OwnerID: owner, DayEnd: dayEnd})
+	if err != nil {
+		slog.Warn("アジェンダの繰り返しのTodoの取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("アジェンダの繰り返しのTodoの取得に失敗", err)
+	}
+	for _, t := range recurring {
+		for _, at := range nextOccurrences(t, start, end) {
+			items = append(items, agendaTodoItem(ctx, model.AgendaRecurrence, t, at.In(loc)))
+		}
+	}
+
+	// 夏時間の切り替わる日は時刻の文字列の順が前後するため、オフセット付きの日時で比べる
+	slices.SortStableFunc(items, func(a, b model.AgendaItem) int {
+		at, _ := time.Parse(time.RFC3339, a.At)
+		bt, _ := time.Parse(time.RFC3339, b.At)
+		return cmp.Or(
+			at.Compare(bt),
+			cmp.Compare(agendaKindOrder[a.Kind], agendaKindOrder[b.Kind]),
+			cmp.Compare(a.TodoID, b.TodoID),
+		)
+	})
+	return items, nil
+}
+
+// nextOccurrences は繰り返しのTodoを完了し続けた場合に作成される次の回のうち、期限が[start, end)に入るものを返す。
+// 次の回の期限はRecurrenceSchedulerと同じく、前の回の期限から繰り返しのルールで求める
+func nextOccurrences(t db.Todo, start, end time.Time) []time.Time {
+	rule, err := recurrence.Parse(t.Recurrence.String)
+	if err != nil || !t.DueAt.Valid {
+		return nil
+	}
+	var occurrences []time.Time
+	at := t.DueAt.Time
+	for range agendaMaxOccurrences {
+		next, rest, ok := rule.Next(at)
+		if !ok || !next.Before(end) {
+			break
+		}
+		if !next.Before(start) {
+			occurrences = append(occurrences, next)
+		}
+		at, rule = next, rest
+	}
+	return occurrences
+}
+
+// agendaTodoItem はTodoをアジェンダの項目にする。タイトルはAccept-Languageヘッダーに合う訳にする
+func agendaTodoItem(ctx context.Context, kind string, t db.Todo, at time.Time) model.AgendaItem {
+	resp := toTodoResponse(ctx, t)
+	return model.AgendaItem{
+		Kind:      kind,
+		At:        at.Format(time.RFC3339),
+		Time:      at.Format("15:04"),
+		TodoID:    t.ID,
+		Title:     resp.Title,
+		Completed: kind == model.AgendaDue && resp.Completed,
+		ProjectID: resp.ProjectID,
+		Priority:  t.Priority,
+	}
+}
+
+// agendaMarkdown はアジェンダを印刷向けのMarkdownのチェックリストにする
+func agendaMarkdown(f *locale.Formatter, day time.Time, items []model.AgendaItem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s %s\n\n", f.Label("アジェンダ", "Agenda"), f.Date(day))
+	if len(items) == 0 {
+		b.WriteString(f.Label("予定はありません。", "Nothing scheduled."))
+		b.WriteString("\n")
+		return b.String()
+	}
+	for _, item := range items {
+		title := agendaMarkdownEscaper.Replace(item.Title)
+		switch item.Kind {
+		case model.AgendaReminder:
+			fmt.Fprintf(&b, "- %s %s: %s\n", item.Time, f.Label("リマインダー", "Reminder"), title)
+		case model.AgendaRecurrence:
+			fmt.Fprintf(&b, "- [ ] %s %s (%s)\n", item.Time, title, f.Label("繰り返し", "recurring"))
+		default:
+			check := " "
+			if item.Completed {
+				check = "x"
+			}
+			fmt.Fprintf(&b, "- [%s] %s %s\n", check, item.Time, title)
+		}
+	}
+	return b.String()
+}
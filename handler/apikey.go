@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/auth"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// APIKeyHandler は自動化クライアント向けのAPIキーの発行と失効を処理するハンドラー
+type APIKeyHandler struct {
+	queries *db.Queries
+}
+
+// NewAPIKeyHandler はAPIKeyHandlerの新しいインスタンスを生成する
+func NewAPIKeyHandler(queries *db.Queries) *APIKeyHandler {
+	return &APIKeyHandler{
+		queries: queries,
+	}
+}
+
+// toAPIKeyResponse はdb.ApiKeyをmodel.APIKeyResponseに変換する
+func toAPIKeyResponse(k db.ApiKey) model.APIKeyResponse {
+	return model.APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		CreatedBy:  k.CreatedBy,
+		LastUsedAt: nullTimeToPtr(k.LastUsedAt),
+		RevokedAt:  nullTimeToPtr(k.RevokedAt),
+		CreatedAt:  k.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ListAPIKeys はAPIキーの一覧を取得する
+func (h *APIKeyHandler) ListAPIKeys(ctx context.Context, input *model.ListAPIKeysInput) (*model.ListAPIKeysOutput, error) {
+	var includeRevoked int64
+	if input.IncludeRevoked {
+		includeRevoked = 1
+	}
+
+	keys, err := h.queries.ListAPIKeys(ctx, includeRevoked)
+	if err != nil {
+		slog.Warn("APIキー一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("APIキー一覧の取得に失敗", err)
+	}
+
+	output := &model.ListAPIKeysOutput{}
+	output.Body.APIKeys = make([]model.APIKeyResponse, len(keys))
+	for i, k := range keys {
+		output.Body.APIKeys[i] = toAPIKeyResponse(k)
+	}
+
+	return output, nil
+}
+
+// CreateAPIKey は新しいAPIキーを発行する。キーはハッシュのみを保存し、平文はこの応答でのみ返す。
+func (h *APIKeyHandler) CreateAPIKey(ctx context.Context, input *model.CreateAPIKeyInput) (*model.CreateAPIKeyOutput, error) {
+	key, err := auth.NewAPIKey()
+	if err != nil {
+		slog.Warn("APIキーの生成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("APIキーの生成に失敗", err)
+	}
+
+	k, err := h.queries.CreateAPIKey(ctx, db.CreateAPIKeyParams{
+		Name:      input.Body.Name,
+		Prefix:    auth.APIKeyDisplayPrefix(key),
+		KeyHash:   auth.HashAPIKey(key),
+		CreatedBy: activity.ActorFrom(ctx),
+	})
+	if err != nil {
+		slog.Warn("APIキーの登録に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("APIキーの登録に失敗", err)
+	}
+
+	output := &model.CreateAPIKeyOutput{}
+	output.Body.APIKeyResponse = toAPIKeyResponse(k)
+	output.Body.Key = key
+	return output, nil
+}
+
+// RevokeAPIKey は指定されたIDのAPIキーを失効させる。既に失効している場合は何もしない。
+func (h *APIKeyHandler) RevokeAPIKey(ctx context.Context, input *model.RevokeAPIKeyInput) (*model.RevokeAPIKeyOutput, error) {
+	n, err := h.queries.RevokeAPIKey(ctx, input.ID)
+	if err != nil {
+		slog.Warn("APIキーの失効に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("APIキーの失効に失敗", err)
+	}
+	if n == 0 {
+		// 既に失効済みの場合は存在確認のみ行う
+		if _, err := h.queries.GetAPIKey(ctx, input.ID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				slog.Warn("APIキーIDが見つかりません", "id", input.ID, "err", err)
+				return nil, huma.Error404NotFound(fmt.Sprintf("APIキーIDが見つかりません: %d", input.ID))
+			}
+			slog.Warn("APIキーの取得に失敗", "err", err)
+			return nil, huma.Error500InternalServerError("APIキーの取得に失敗", err)
+		}
+	}
+
+	output := &model.RevokeAPIKeyOutput{}
+	output.Body.Message = "API key revoked successfully"
+	return output, nil
+}
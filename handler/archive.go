@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/conditional"
+)
+
+// errAlreadyArchived はTodoが既にアーカイブ済みか、アーカイブされていないことを表すエラー
+var errAlreadyArchived = errors.New("Todoのアーカイブの状態が変わりません")
+
+// setTodoArchived は指定されたIDのTodoをアーカイブするか、アーカイブを解除する。
+// 既に指定された状態の場合はerrAlreadyArchivedを返す。他のユーザーがnowの時点でロックしている場合は423を返す。
+func setTodoArchived(ctx context.Context, sqlDB *sql.DB, queries *db.Queries, bus *event.Bus, id int64, archived bool, params *conditional.Params, now time.Time) (db.Todo, error) {
+	var todo db.Todo
+	err := runInTx(ctx, sqlDB, queries, false, func(qtx *db.Queries) error {
+		before, err := getUnlockedTodo(ctx, qtx, id, now)
+		if err != nil {
+			return err
+		}
+		if params != nil {
+			if err := checkTodoPrecondition(params, before, false); err != nil {
+				return err
+			}
+		}
+
+		action := activity.ActionArchive
+		if archived {
+			todo, err = qtx.ArchiveTodo(ctx, id)
+		} else {
+			action = activity.ActionUnarchive
+			todo, err = qtx.UnarchiveTodo(ctx, id)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			todo = before
+			return errAlreadyArchived
+		}
+		if err != nil {
+			slog.Warn("Todoのアーカイブの更新に失敗", "id", id, "archived", archived, "err", err)
+			return huma.Error500InternalServerError("Todoのアーカイブの更新に失敗", err)
+		}
+		if err := recordActivity(ctx, qtx, action, &before, &todo); err != nil {
+			return err
+		}
+		return recordEvent(ctx, qtx, event.Event{Type: event.TodoUpdated, TodoID: todo.ID})
+	})
+	if err != nil {
+		return todo, err
+	}
+
+	bus.Publish(event.Event{Type: event.TodoUpdated, TodoID: todo.ID})
+	return todo, nil
+}
+
+// ArchiveTodo は指定されたIDのTodoをアーカイブし、一覧に含めないようにする
+func (h *TodoHandler) ArchiveTodo(ctx context.Context, input *model.ArchiveTodoInput) (*model.ArchiveTodoOutput, error) {
+	todo, err := setTodoArchived(ctx, h.db, h.queries, h.bus, input.ID, true, &input.Params, h.clock.Now())
+	if errors.Is(err, errAlreadyArchived) {
+		slog.Warn("Todoは既にアーカイブ済みです", "id", input.ID)
+		return nil, huma.Error409Conflict(fmt.Sprintf("Todoは既にアーカイブ済みです: %d", input.ID))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &model.ArchiveTodoOutput{ETag: todoETag(todo), Body: toTodoResponse(ctx, todo)}, nil
+}
+
+// UnarchiveTodo は指定されたIDのアーカイブ済みのTodoを元に戻す
+func (h *TodoHandler) UnarchiveTodo(ctx context.Context, input *model.ArchiveTodoInput) (*model.ArchiveTodoOutput, error) {
+	todo, err := setTodoArchived(ctx, h.db, h.queries, h.bus, input.ID, false, &input.Params, h.clock.Now())
+	if errors.Is(err, errAlreadyArchived) {
+		slog.Warn("Todoはアーカイブされていません", "id", input.ID)
+		return nil, huma.Error409Conflict(fmt.Sprintf("Todoはアーカイブされていません: %d", input.ID))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &model.ArchiveTodoOutput{ETag: todoETag(todo), Body: toTodoResponse(ctx, todo)}, nil
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"go-huma-test/storage"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// AttachmentHandler はTodoの添付ファイルの操作を処理するハンドラー
+type AttachmentHandler struct {
+	queries *db.Queries
+	store   storage.BlobStore
+	maxSize int64
+}
+
+// NewAttachmentHandler はAttachmentHandlerの新しいインスタンスを生成する
+func NewAttachmentHandler(queries *db.Queries, store storage.BlobStore, maxSize int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		queries: queries,
+		store:   store,
+		maxSize: maxSize,
+	}
+}
+
+// toAttachmentResponse はdb.Attachmentをmodel.AttachmentResponseに変換する
+func toAttachmentResponse(a db.Attachment) model.AttachmentResponse {
+	return model.AttachmentResponse{
+		ID:          a.ID,
+		TodoID:      a.TodoID,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		CreatedAt:   a.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// UploadAttachment は指定されたTodoにファイルを添付する
+func (h *AttachmentHandler) UploadAttachment(ctx context.Context, input *model.UploadAttachmentInput) (*model.UploadAttachmentOutput, error) {
+	if err := ensureTodoWritable(ctx, h.queries, input.TodoID); err != nil {
+		return nil, err
+	}
+
+	file := input.RawBody.Data().File
+	defer file.Close()
+
+	if file.Size > h.maxSize {
+		return nil, huma.NewError(http.StatusRequestEntityTooLarge, fmt.Sprintf("ファイルサイズが上限を超えています: %d > %d バイト", file.Size, h.maxSize))
+	}
+
+	key, err := storage.NewKey()
+	if err != nil {
+		slog.Warn("保存キーの生成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("保存キーの生成に失敗", err)
+	}
+	size, err := h.store.Put(ctx, key, file)
+	if err != nil {
+		slog.Warn("添付ファイルの保存に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("添付ファイルの保存に失敗", err)
+	}
+
+	filename := filepath.Base(file.Filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		filename = "file"
+	}
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	attachment, err := h.queries.CreateAttachment(ctx, db.CreateAttachmentParams{
+		TodoID:      input.TodoID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+	})
+	if err != nil {
+		slog.Warn("添付ファイルの登録に失敗", "err", err)
+		// メタデータのない孤立したファイルを残さない
+		if err := h.store.Delete(ctx, key); err != nil {
+			slog.Warn("添付ファイルの削除に失敗", "key", key, "err", err)
+		}
+		return nil, huma.Error500InternalServerError("添付ファイルの登録に失敗", err)
+	}
+
+	return &model.UploadAttachmentOutput{Body: toAttachmentResponse(attachment)}, nil
+}
+
+// ListAttachments は指定されたTodoの添付ファイル一覧を取得する
+func (h *AttachmentHandler) ListAttachments(ctx context.Context, input *model.ListAttachmentsInput) (*model.ListAttachmentsOutput, error) {
+	if err := ensureTodoExists(ctx, h.queries, input.TodoID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := h.queries.ListAttachments(ctx, input.TodoID)
+	if err != nil {
+		slog.Warn("添付ファイル一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("添付ファイル一覧の取得に失敗", err)
+	}
+
+	output := &model.ListAttachmentsOutput{}
+	output.Body.Attachments = make([]model.AttachmentResponse, len(attachments))
+	for i, a := range attachments {
+		output.Body.Attachments[i] = toAttachmentResponse(a)
+	}
+
+	return output, nil
+}
+
+// DownloadAttachment は指定されたIDの添付ファイルを登録時のContent-Typeで返す
+func (h *AttachmentHandler) DownloadAttachment(ctx context.Context, input *model.DownloadAttachmentInput) (*huma.StreamResponse, error) {
+	attachment, err := h.queries.GetAttachment(ctx, input.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, huma.Error404NotFound(fmt.Sprintf("添付ファイルIDが見つかりません: %d", input.ID))
+		}
+		slog.Warn("添付ファイルの取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("添付ファイルの取得に失敗", err)
+	}
+	// 閲覧できないTodoの添付ファイルは存在しないものとして扱う
+	if _, err := getTodo(ctx, h.queries, attachment.TodoID); err != nil {
+		var se huma.StatusError
+		if errors.As(err, &se) && se.GetStatus() == http.StatusNotFound {
+			return nil, huma.Error404NotFound(fmt.Sprintf("添付ファイルIDが見つかりません: %d", input.ID))
+		}
+		return nil, err
+	}
+
+	r, err := h.store.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			slog.Warn("添付ファイルの本体が見つかりません", "id", attachment.ID, "key", attachment.StorageKey)
+			return nil, huma.Error404NotFound(fmt.Sprintf("添付ファイルIDが見つかりません: %d", input.ID))
+		}
+		slog.Warn("添付ファイルの読み込みに失敗", "err", err)
+		return nil, huma.Error500InternalServerError("添付ファイルの読み込みに失敗", err)
+	}
+
+	return &huma.StreamResponse{
+		Body: func(hctx huma.Context) {
+			defer r.Close()
+
+			hctx.SetHeader("Content-Type", attachment.ContentType)
+			hctx.SetHeader("Content-Length", strconv.FormatInt(attachment.Size, 10))
+			hctx.SetHeader("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+			hctx.SetHeader("X-Content-Type-Options", "nosniff")
+
+			if _, err := io.Copy(hctx.BodyWriter(), r); err != nil {
+				slog.Warn("添付ファイルの送信に失敗", "id", attachment.ID, "err", err)
+			}
+		},
+	}, nil
+}
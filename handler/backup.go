@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-huma-test/backup"
+	"go-huma-test/model"
+	"go-huma-test/tracing"
+	"io"
+	"log/slog"
+	"mime"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// BackupHandler はデータベースのバックアップを作成、取得するハンドラー
+type BackupHandler struct {
+	manager *backup.Manager
+}
+
+// NewBackupHandler はBackupHandlerの新しいインスタンスを生成する
+func NewBackupHandler(manager *backup.Manager) *BackupHandler {
+	return &BackupHandler{
+		manager: manager,
+	}
+}
+
+// toBackupResponse はバックアップをレスポンスに変換する
+func toBackupResponse(b backup.Info) model.BackupResponse {
+	return model.BackupResponse{
+		Name:      b.Name,
+		Size:      b.Size,
+		CreatedAt: b.CreatedAt,
+	}
+}
+
+// CreateBackup はデータベースのバックアップを作成する。保持する件数を超えた古いバックアップは削除する。
+// VACUUM INTOはデータベース全体を書き出すため、query-timeoutの上限を適用しない。
+func (h *BackupHandler) CreateBackup(ctx context.Context, _ *struct{}) (*model.CreateBackupOutput, error) {
+	info, err := h.manager.Create(tracing.WithQueryTimeout(ctx, 0))
+	if err != nil {
+		slog.Warn("バックアップの作成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("バックアップの作成に失敗", err)
+	}
+	slog.Info("バックアップを作成", "name", info.Name, "size", info.Size)
+	return &model.CreateBackupOutput{Body: toBackupResponse(info)}, nil
+}
+
+// ListBackups はバックアップを新しい順に取得する
+func (h *BackupHandler) ListBackups(ctx context.Context, _ *struct{}) (*model.ListBackupsOutput, error) {
+	backups, err := h.manager.List()
+	if err != nil {
+		slog.Warn("バックアップの一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("バックアップの一覧の取得に失敗", err)
+	}
+
+	output := &model.ListBackupsOutput{}
+	output.Body.Backups = make([]model.BackupResponse, 0, len(backups))
+	for _, b := range backups {
+		output.Body.Backups = append(output.Body.Backups, toBackupResponse(b))
+	}
+	return output, nil
+}
+
+// DownloadBackup は指定された名前のバックアップをSQLiteのデータベースファイルとして返す
+func (h *BackupHandler) DownloadBackup(ctx context.Context, input *model.DownloadBackupInput) (*huma.StreamResponse, error) {
+	f, info, err := h.manager.Open(input.Name)
+	if err != nil {
+		if errors.Is(err, backup.ErrNotFound) {
+			return nil, huma.Error404NotFound(fmt.Sprintf("バックアップが見つかりません: %s", input.Name))
+		}
+		slog.Warn("バックアップの読み込みに失敗", "err", err)
+		return nil, huma.Error500InternalServerError("バックアップの読み込みに失敗", err)
+	}
+
+	return &huma.StreamResponse{
+		Body: func(hctx huma.Context) {
+			defer func() {
+				_ = f.Close()
+			}()
+
+			hctx.SetHeader("Content-Type", "application/vnd.sqlite3")
+			hctx.SetHeader("Content-Length", strconv.FormatInt(info.Size, 10))
+			hctx.SetHeader("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": info.Name}))
+
+			if _, err := io.Copy(hctx.BodyWriter(), f); err != nil {
+				slog.Warn("バックアップの送信に失敗", "name", info.Name, "err", err)
+			}
+		},
+	}, nil
+}
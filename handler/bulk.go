@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"log/slog"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// runInTx はfnをトランザクション内で実行する。
+// dryRunがtrueの場合はfnの結果にかかわらず常にロールバックし、変更を残さない。
+func runInTx(ctx context.Context, sqlDB *sql.DB, q *db.Queries, dryRun bool, fn func(qtx *db.Queries) error) error {
+	tx, err := sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Warn("トランザクション開始に失敗", "err", err)
+		return huma.Error500InternalServerError("トランザクション開始に失敗", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := fn(q.WithTx(tx)); err != nil {
+		return err
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Warn("トランザクションのコミットに失敗", "err", err)
+		return huma.Error500InternalServerError("トランザクションのコミットに失敗", err)
+	}
+	return nil
+}
+
+// toDeletionSummary は削除されたTodoから削除結果を生成する
+func toDeletionSummary(ctx context.Context, todos []db.Todo, dryRun bool) model.DeletionSummary {
+	summary := model.DeletionSummary{
+		DryRun: dryRun,
+		Count:  len(todos),
+		Todos:  make([]model.TodoResponse, len(todos)),
+	}
+	for i, t := range todos {
+		summary.Todos[i] = toTodoResponse(ctx, t)
+	}
+	return summary
+}
+
+// publishDeleted は削除されたTodoごとに削除イベントを発行する
+func (h *TodoHandler) publishDeleted(todos []db.Todo) {
+	for _, t := range todos {
+		h.bus.Publish(event.Event{Type: event.TodoDeleted, TodoID: t.ID, OwnerID: t.OwnerID})
+	}
+}
+
+// BulkDeleteTodos は指定されたIDのTodoをまとめて削除する。他のユーザーがロックしているTodoが含まれる場合は423を返し、何も削除しない
+func (h *TodoHandler) BulkDeleteTodos(ctx context.Context, input *model.BulkDeleteTodosInput) (*model.BulkDeleteTodosOutput, error) {
+	var deleted []db.Todo
+	err := runInTx(ctx, h.db, h.queries, input.DryRun, func(qtx *db.Queries) error {
+		// ロックは削除したTodoと一緒に消えるため、削除する前に確認する
+		ids, err := qtx.ListOwnedTodoIDs(ctx, db.ListOwnedTodoIDsParams{OwnerID: ownerID(ctx), Ids: input.Body.IDs})
+		if err != nil {
+			slog.Warn("削除するTodoの取得に失敗", "err", err)
+			return huma.Error500InternalServerError("削除するTodoの取得に失敗", err)
+		}
+		if err := checkTodoLocks(ctx, qtx, h.clock.Now(), ids...); err != nil {
+			return err
+		}
+		deleted, err = qtx.DeleteTodosByIDs(ctx, db.DeleteTodosByIDsParams{OwnerID: ownerID(ctx), Ids: input.Body.IDs})
+		if err != nil {
+			slog.Warn("Todo一括削除に失敗", "err", err)
+			return huma.Error500InternalServerError("Todo一括削除に失敗", err)
+		}
+		return recordDeletions(ctx, qtx, deleted)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !input.DryRun {
+		h.publishDeleted(deleted)
+	}
+
+	return &model.BulkDeleteTodosOutput{Body: toDeletionSummary(ctx, deleted, input.DryRun)}, nil
+}
+
+// ClearCompletedTodos は完了済みのTodoをすべて削除する。他のユーザーがロックしているTodoが含まれる場合は423を返し、何も削除しない
+func (h *TodoHandler) ClearCompletedTodos(ctx context.Context, input *model.ClearCompletedTodosInput) (*model.ClearCompletedTodosOutput, error) {
+	var deleted []db.Todo
+	err := runInTx(ctx, h.db, h.queries, input.DryRun, func(qtx *db.Queries) error {
+		// ロックは削除したTodoと一緒に消えるため、削除する前に確認する
+		ids, err := qtx.ListCompletedTodoIDs(ctx, ownerID(ctx))
+		if err != nil {
+			slog.Warn("削除するTodoの取得に失敗", "err", err)
+			return huma.Error500InternalServerError("削除するTodoの取得に失敗", err)
+		}
+		if err := checkTodoLocks(ctx, qtx, h.clock.Now(), ids...); err != nil {
+			return err
+		}
+		deleted, err = qtx.DeleteCompletedTodos(ctx, ownerID(ctx))
+		if err != nil {
+			slog.Warn("完了済みTodoの削除に失敗", "err", err)
+			return huma.Error500InternalServerError("完了済みTodoの削除に失敗", err)
+		}
+		return recordDeletions(ctx, qtx, deleted)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !input.DryRun {
+		h.publishDeleted(deleted)
+	}
+
+	return &model.ClearCompletedTodosOutput{Body: toDeletionSummary(ctx, deleted, input.DryRun)}, nil
+}
@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// boolToInt64 は真偽値をDBに保存する整数値に変換する
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// nullTimeToTimePtr はsql.NullTimeを*time.Timeに変換する
+func nullTimeToTimePtr(t sql.NullTime) *time.Time {
+	if t.Valid {
+		return &t.Time
+	}
+	return nil
+}
+
+// validateBundleRefs はバンドル内の参照番号が重複しておらず、next_refが存在するTodoを指していることを検証する
+func validateBundleRefs(todos []model.BundleTodo) error {
+	refs := make(map[int64]bool, len(todos))
+	for i, t := range todos {
+		if refs[t.Ref] {
+			return huma.Error422UnprocessableEntity("参照番号が重複しています", &huma.ErrorDetail{
+				Location: fmt.Sprintf("body.todos[%d].ref", i),
+				Value:    t.Ref,
+			})
+		}
+		refs[t.Ref] = true
+	}
+	for i, t := range todos {
+		if t.NextRef != nil && !refs[*t.NextRef] {
+			return huma.Error422UnprocessableEntity("next_refが参照するTodoがバンドルに含まれていません", &huma.ErrorDetail{
+				Location: fmt.Sprintf("body.todos[%d].next_ref", i),
+				Value:    *t.NextRef,
+			})
+		}
+	}
+	return nil
+}
+
+// ExportProject はプロジェクトと所属するTodo、タグ、サブタスク、コメントを1つのバンドルとしてエクスポートする
+func (h *ProjectHandler) ExportProject(ctx context.Context, input *model.ExportProjectInput) (*model.ExportProjectOutput, error) {
+	p, err := getProject(ctx, h.queries, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID := sql.NullInt64{Int64: p.ID, Valid: true}
+	todos, err := h.queries.ListVisibleTodosByProject(ctx, db.ListVisibleTodosByProjectParams{
+		ProjectID: projectID,
+		UserID:    ownerID(ctx),
+	})
+	if err != nil {
+		slog.Warn("プロジェクトのTodo一覧の取得に失敗", "id", p.ID, "err", err)
+		return nil, huma.Error500InternalServerError("プロジェクトのTodo一覧の取得に失敗", err)
+	}
+	subtasks, err := h.queries.ListSubtasksByProject(ctx, projectID)
+	if err != nil {
+		slog.Warn("プロジェクトのサブタスク一覧の取得に失敗", "id", p.ID, "err", err)
+		return nil, huma.Error500InternalServerError("プロジェクトのサブタスク一覧の取得に失敗", err)
+	}
+	comments, err := h.queries.ListCommentsByProject(ctx, projectID)
+	if err != nil {
+		slog.Warn("プロジェクトのコメント一覧の取得に失敗", "id", p.ID, "err", err)
+		return nil, huma.Error500InternalServerError("プロジェクトのコメント一覧の取得に失敗", err)
+	}
+
+	// 参照番号には元のTodoのIDをそのまま使う
+	index := make(map[int64]int, len(todos))
+	bundleTodos := make([]model.BundleTodo, len(todos))
+	for i, t := range todos {
+		index[t.ID] = i
+		bundleTodos[i] = model.BundleTodo{
+			Ref:         t.ID,
+			Title:       t.Title,
+			Description: nullStringToPtr(t.Description),
+			Completed:   t.Completed == 1,
+			Assignee:    nullStringToPtr(t.Assignee),
+			Metadata:    decodeJSONObject(t.Metadata),
+			Priority:    t.Priority,
+			Location: model.Location{
+				Latitude:  nullFloat64ToPtr(t.Latitude),
+				Longitude: nullFloat64ToPtr(t.Longitude),
+				PlaceName: nullStringToPtr(t.PlaceName),
+			},
+			Schedule: model.Schedule{
+				DueAt:      nullTimeToTimePtr(t.DueAt),
+				Recurrence: nullStringToPtr(t.Recurrence),
+			},
+		}
+	}
+	ids := make([]int64, len(todos))
+	for i, t := range todos {
+		ids[i] = t.ID
+	}
+	tags, err := todoTagNames(ctx, h.queries, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range todos {
+		bundleTodos[i].Tags = tags[t.ID]
+		// 次回のTodoが別のプロジェクトに移されている場合はバンドルに含めない
+		if _, ok := index[t.NextTodoID.Int64]; t.NextTodoID.Valid && ok {
+			bundleTodos[i].NextRef = &t.NextTodoID.Int64
+		}
+	}
+	// 閲覧できないTodoのサブタスクとコメントは含めない
+	for _, s := range subtasks {
+		i, ok := index[s.TodoID]
+		if !ok {
+			continue
+		}
+		bundleTodos[i].Subtasks = append(bundleTodos[i].Subtasks, model.BundleSubtask{
+			Title:     s.Title,
+			Completed: s.Completed == 1,
+		})
+	}
+	for _, c := range comments {
+		i, ok := index[c.TodoID]
+		if !ok {
+			continue
+		}
+		bundleTodos[i].Comments = append(bundleTodos[i].Comments, model.BundleComment{
+			Author:    c.Author,
+			Body:      c.Body,
+			CreatedAt: &c.CreatedAt,
+		})
+	}
+
+	output := &model.ExportProjectOutput{
+		ContentDisposition: fmt.Sprintf(`attachment; filename="project-%d.json"`, p.ID),
+	}
+	output.Body = model.ProjectBundle{
+		Version: model.BundleVersion,
+		Project: model.BundleProject{
+			Name:           p.Name,
+			Description:    nullStringToPtr(p.Description),
+			MetadataSchema: decodeJSONObject(p.MetadataSchema),
+			WIPLimit:       nullInt64ToPtr(p.WipLimit),
+		},
+		Todos: bundleTodos,
+		Meta: &model.BundleExportMeta{
+			ExportedAt: h.clock.Now().UTC().Format(time.RFC3339),
+			SourceID:   p.ID,
+		},
+	}
+
+	return output, nil
+}
+
+// ImportProject はバンドルから新しいプロジェクトを作成する。
+// Todoには新しいIDが振られ、next_refによる参照も新しいIDに付け替えられる。
+func (h *ProjectHandler) ImportProject(ctx context.Context, input *model.ImportProjectInput) (*model.ImportProjectOutput, error) {
+	project, todoIDs, err := h.ImportBundle(ctx, input.Body, input.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &model.ImportProjectOutput{}
+	output.Body.DryRun = input.DryRun
+	output.Body.Project = toProjectResponse(project)
+	output.Body.TodoIDs = make(map[string]int64, len(todoIDs))
+	for ref, id := range todoIDs {
+		output.Body.TodoIDs[strconv.FormatInt(ref, 10)] = id
+	}
+
+	return output, nil
+}
+
+// ImportBundle はバンドルから新しいプロジェクトを1つのトランザクションで作成し、
+// 作成したプロジェクトと、バンドル内の参照番号から新しいTodoのIDへの対応を返す。
+// 外部のサービスからのインポートやCLIからも呼び出すため、HTTPの入出力に依存しない。
+func (h *ProjectHandler) ImportBundle(ctx context.Context, bundle model.ProjectBundle, dryRun bool) (db.Project, map[int64]int64, error) {
+	if err := validateBundleRefs(bundle.Todos); err != nil {
+		return db.Project{}, nil, err
+	}
+
+	tags := make([][]string, len(bundle.Todos))
+	for i, t := range bundle.Todos {
+		names, err := normalizeTagNames(t.Tags, fmt.Sprintf("body.todos[%d].tags", i))
+		if err != nil {
+			return db.Project{}, nil, err
+		}
+		tags[i] = names
+	}
+
+	var project db.Project
+	todoIDs := make(map[int64]int64, len(bundle.Todos))
+	err := runInTx(ctx, h.db, h.queries, dryRun, func(qtx *db.Queries) error {
+		var err error
+		metadataSchema, err := encodeMetadataSchema(bundle.Project.MetadataSchema)
+		if err != nil {
+			return err
+		}
+		project, err = qtx.CreateProject(ctx, db.CreateProjectParams{
+			Name:           bundle.Project.Name,
+			Description:    ptrStringToNullString(bundle.Project.Description),
+			MetadataSchema: metadataSchema,
+			WipLimit:       ptrInt64ToNullInt64(bundle.Project.WIPLimit),
+		})
+		if err != nil {
+			slog.Warn("プロジェクト作成に失敗", "err", err)
+			return huma.Error500InternalServerError("プロジェクト作成に失敗", err)
+		}
+
+		projectID := sql.NullInt64{Int64: project.ID, Valid: true}
+		for i, t := range bundle.Todos {
+			metadata, err := encodeMetadata(t.Metadata)
+			if err != nil {
+				return err
+			}
+			if err := validateTodoMetadata(ctx, qtx, projectID, metadata); err != nil {
+				return err
+			}
+			created, err := qtx.CreateTodo(ctx, db.CreateTodoParams{
+				Title:       t.Title,
+				Description: ptrStringToNullString(t.Description),
+				Completed:   boolToInt64(t.Completed),
+				Latitude:    ptrFloat64ToNullFloat64(t.Latitude),
+				Longitude:   ptrFloat64ToNullFloat64(t.Longitude),
+				PlaceName:   ptrStringToNullString(t.PlaceName),
+				ProjectID:   projectID,
+				DueAt:       ptrTimeToNullTime(t.DueAt),
+				Recurrence:  ptrStringToNullString(t.Recurrence),
+				Assignee:    ptrStringToNullString(t.Assignee),
+				OwnerID:     ownerID(ctx),
+				Metadata:    metadata,
+				Priority:    t.Priority,
+			})
+			if err != nil {
+				slog.Warn("Todo作成に失敗", "ref", t.Ref, "err", err)
+				return huma.Error500InternalServerError("Todo作成に失敗", err)
+			}
+			todoIDs[t.Ref] = created.ID
+			if err := recordActivity(ctx, qtx, activity.ActionCreate, nil, &created); err != nil {
+				return err
+			}
+			if err := recordEvent(ctx, qtx, event.Event{Type: event.TodoCreated, TodoID: created.ID}); err != nil {
+				return err
+			}
+
+			if err := addTodoTags(ctx, qtx, created.ID, created.OwnerID, tags[i]); err != nil {
+				return err
+			}
+
+			for _, s := range t.Subtasks {
+				if _, err := qtx.CreateSubtask(ctx, db.CreateSubtaskParams{
+					TodoID:    created.ID,
+					Title:     s.Title,
+					Completed: boolToInt64(s.Completed),
+				}); err != nil {
+					slog.Warn("サブタスク作成に失敗", "ref", t.Ref, "err", err)
+					return huma.Error500InternalServerError("サブタスク作成に失敗", err)
+				}
+			}
+
+			for _, c := range t.Comments {
+				if _, err := qtx.CreateComment(ctx, db.CreateCommentParams{
+					TodoID:    created.ID,
+					Author:    c.Author,
+					Body:      c.Body,
+					CreatedAt: ptrTimeToNullTime(c.CreatedAt),
+				}); err != nil {
+					slog.Warn("コメント作成に失敗", "ref", t.Ref, "err", err)
+					return huma.Error500InternalServerError("コメント作成に失敗", err)
+				}
+			}
+		}
+
+		// 全Todoの作成後に、繰り返しの次回Todoへの参照を新しいIDで張り直す
+		for _, t := range bundle.Todos {
+			if t.NextRef == nil {
+				continue
+			}
+			if _, err := qtx.SetNextTodoID(ctx, db.SetNextTodoIDParams{
+				NextTodoID: sql.NullInt64{Int64: todoIDs[*t.NextRef], Valid: true},
+				ID:         todoIDs[t.Ref],
+			}); err != nil {
+				slog.Warn("次回のTodoの記録に失敗", "ref", t.Ref, "err", err)
+				return huma.Error500InternalServerError("次回のTodoの記録に失敗", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return db.Project{}, nil, err
+	}
+
+	if !dryRun {
+		for _, id := range todoIDs {
+			h.bus.Publish(event.Event{Type: event.TodoCreated, TodoID: id})
+		}
+	}
+
+	return project, todoIDs, nil
+}
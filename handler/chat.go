@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"go-huma-test/notify"
+	"log/slog"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// chatEvents はチャットに投稿できるTodoのイベント
+var chatEvents = []string{
+	string(event.TodoCreated),
+	string(event.TodoUpdated),
+	string(event.TodoMoved),
+	string(event.TodoCompleted),
+	string(event.TodoAssigned),
+	string(event.TodoOverdue),
+}
+
+// maxChatTemplateLength はチャットのメッセージのテンプレートの最大文字数
+const maxChatTemplateLength = 4000
+
+// discordHosts はDiscordのWebhookのホスト
+var discordHosts = map[string]bool{
+	"discord.com":        true,
+	"discordapp.com":     true,
+	"ptb.discord.com":    true,
+	"canary.discord.com": true,
+}
+
+// teamsHostSuffixes はTeamsの受信Webhook（webhook.office.com）と、
+// ワークフロー（Power AutomateのpowerplatformとLogic Appsのlogic.azure.com）のWebhookのホストの末尾
+var teamsHostSuffixes = []string{
+	".webhook.office.com",
+	".environment.api.powerplatform.com",
+	".logic.azure.com",
+}
+
+// isTeamsHost はホストがTeamsのWebhookのホストかを返す
+func isTeamsHost(host string) bool {
+	host = strings.ToLower(host)
+	return slices.ContainsFunc(teamsHostSuffixes, func(suffix string) bool {
+		return strings.HasSuffix(host, suffix)
+	})
+}
+
+// ChatChannelHandler はプロジェクトごとのチャットの送信先に関する操作を処理するハンドラー
+type ChatChannelHandler struct {
+	queries *db.Queries
+	chats   map[string]*notify.ChatNotifier
+}
+
+// NewChatChannelHandler はChatChannelHandlerの新しいインスタンスを生成する。chatsは試験投稿に使うチャット名ごとの投稿先
+func NewChatChannelHandler(queries *db.Queries, chats map[string]*notify.ChatNotifier) *ChatChannelHandler {
+	return &ChatChannelHandler{
+		queries: queries,
+		chats:   chats,
+	}
+}
+
+// maskWebhookURL はWebhook URLのトークンにあたる最後のパスとクエリを伏せる
+func maskWebhookURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "***"
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	dir, _ := path.Split(u.Path)
+	u.Path = dir
+	return u.String() + "***"
+}
+
+// toChatChannelResponse はdb.ProjectChatChannelをmodel.ChatChannelResponseに変換する
+func toChatChannelResponse(c db.ProjectChatChannel) model.ChatChannelResponse {
+	events := []string{}
+	if err := json.Unmarshal([]byte(c.Events), &events); err != nil {
+		slog.Warn("チャットの購読イベントを解析できません", "project_id", c.ProjectID, "provider", c.Provider, "err", err)
+	}
+	templates := map[string]string{}
+	if err := json.Unmarshal([]byte(c.Templates), &templates); err != nil {
+		slog.Warn("チャットのテンプレートを解析できません", "project_id", c.ProjectID, "provider", c.Provider, "err", err)
+	}
+	return model.ChatChannelResponse{
+		ProjectID:  c.ProjectID,
+		Provider:   c.Provider,
+		WebhookURL: maskWebhookURL(c.WebhookUrl),
+		Events:     events,
+		Templates:  templates,
+		CreatedAt:  c.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  c.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// validateChatWebhookURL はチャットの種類に合ったWebhook URLであることを検証する
+func validateChatWebhookURL(provider, raw string) error {
+	invalid := func(msg string) error {
+		return huma.Error422UnprocessableEntity(msg, &huma.ErrorDetail{
+			Location: "body.webhook_url",
+			Value:    maskWebhookURL(raw),
+		})
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return invalid("Webhook URLが不正です")
+	}
+	if u.Scheme != "https" {
+		return invalid("Webhook URLはhttpsで指定してください")
+	}
+	if provider == notify.ChannelDiscord && (!discordHosts[u.Hostname()] || !strings.HasPrefix(u.Path, "/api/webhooks/")) {
+		return invalid("DiscordのWebhook URL（https://discord.com/api/webhooks/...）を指定してください")
+	}
+	if provider == notify.ChannelSlack && (u.Hostname() != "hooks.slack.com" || !strings.HasPrefix(u.Path, "/services/")) {
+		return invalid("Slackの着信Webhook URL（https://hooks.slack.com/services/...）を指定してください")
+	}
+	if provider == notify.ChannelTeams && !isTeamsHost(u.Hostname()) {
+		return invalid("Teamsの受信Webhook（https://<テナント>.webhook.office.com/...）かワークフローのURL（https://...logic.azure.com/...または...environment.api.powerplatform.com/...）を指定してください")
+	}
+	return nil
+}
+
+// validateChatTemplates はテンプレートのイベントの種類と、各テンプレートを解析して実行できることを検証する
+func validateChatTemplates(templates map[string]string) error {
+	for event, src := range templates {
+		// ロケーションの区切りと紛らわしいため、イベントの種類は値として返す
+		detail := &huma.ErrorDetail{Location: "body.templates", Value: event}
+		if !slices.Contains(chatEvents, event) {
+			return huma.Error422UnprocessableEntity(fmt.Sprintf("テンプレートのイベントの種類が不正です: %s", event), detail)
+		}
+		if utf8.RuneCountInString(src) > maxChatTemplateLength {
+			return huma.Error422UnprocessableEntity(fmt.Sprintf("テンプレートは%d文字以内にしてください", maxChatTemplateLength), detail)
+		}
+		if _, err := notify.ParseChatTemplate(src); err != nil {
+			return huma.Error422UnprocessableEntity(err.Error(), detail)
+		}
+	}
+	return nil
+}
+
+// ListChatChannels はプロジェクトに設定されたチャットの送信先の一覧を取得する
+func (h *ChatChannelHandler) ListChatChannels(ctx context.Context, input *model.ListChatChannelsInput) (*model.ListChatChannelsOutput, error) {
+	if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+
+	channels, err := h.queries.ListProjectChatChannels(ctx, input.ID)
+	if err != nil {
+		slog.Warn("チャットの送信先一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("チャットの送信先一覧の取得に失敗", err)
+	}
+
+	output := &model.ListChatChannelsOutput{}
+	output.Body.Channels = make([]model.ChatChannelResponse, len(channels))
+	for i, c := range channels {
+		output.Body.Channels[i] = toChatChannelResponse(c)
+	}
+	return output, nil
+}
+
+// PutChatChannel はプロジェクトのチャットの送信先を設定する。設定済みの場合は置き換える。
+func (h *ChatChannelHandler) PutChatChannel(ctx context.Context, input *model.PutChatChannelInput) (*model.PutChatChannelOutput, error) {
+	if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+	if err := validateChatWebhookURL(input.Provider, input.Body.WebhookURL); err != nil {
+		slog.Warn("チャットのWebhook URLが不正です", "project_id", input.ID, "provider", input.Provider)
+		return nil, err
+	}
+
+	if err := validateChatTemplates(input.Body.Templates); err != nil {
+		slog.Warn("チャットのテンプレートが不正です", "project_id", input.ID, "provider", input.Provider, "err", err)
+		return nil, err
+	}
+
+	events := input.Body.Events
+	if events == nil {
+		events = chatEvents
+	}
+	encoded, err := json.Marshal(events)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("購読イベントのエンコードに失敗", err)
+	}
+	templates := input.Body.Templates
+	if templates == nil {
+		templates = map[string]string{}
+	}
+	encodedTemplates, err := json.Marshal(templates)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("テンプレートのエンコードに失敗", err)
+	}
+
+	c, err := h.queries.UpsertProjectChatChannel(ctx, db.UpsertProjectChatChannelParams{
+		ProjectID:  input.ID,
+		Provider:   input.Provider,
+		WebhookUrl: input.Body.WebhookURL,
+		Events:     string(encoded),
+		Templates:  string(encodedTemplates),
+	})
+	if err != nil {
+		slog.Warn("チャットの送信先の設定に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("チャットの送信先の設定に失敗", err)
+	}
+
+	return &model.PutChatChannelOutput{Body: toChatChannelResponse(c)}, nil
+}
+
+// TestChatChannel はプロジェクトのチャットの送信先に見本の通知を投稿する。
+// 購読イベントの設定によらず投稿し、指定されたイベントのテンプレートがあれば適用する。
+func (h *ChatChannelHandler) TestChatChannel(ctx context.Context, input *model.TestChatChannelInput) (*model.TestChatChannelOutput, error) {
+	project, err := getProject(ctx, h.queries, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := h.queries.GetProjectChatChannel(ctx, db.GetProjectChatChannelParams{
+		ProjectID: input.ID,
+		Provider:  input.Provider,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, huma.Error404NotFound(fmt.Sprintf("チャットの送信先が設定されていません: project=%d provider=%s", input.ID, input.Provider))
+	}
+	if err != nil {
+		slog.Warn("チャットの送信先の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("チャットの送信先の取得に失敗", err)
+	}
+	chat, ok := h.chats[input.Provider]
+	if !ok {
+		return nil, huma.Error500InternalServerError(fmt.Sprintf("チャットの投稿先がありません: %s", input.Provider))
+	}
+	tmpl, err := notify.LookupChatTemplate(c.Templates, input.Event)
+	if err != nil {
+		slog.Warn("チャットのテンプレートを使えません", "project_id", input.ID, "provider", input.Provider, "event", input.Event, "err", err)
+		return nil, huma.Error500InternalServerError("チャットのテンプレートを使えません", err)
+	}
+
+	n := chat.Sample(input.Event, project.Name, activity.ActorFrom(ctx))
+	if err := chat.Post(ctx, c.WebhookUrl, n, tmpl); err != nil {
+		slog.Warn("チャットへの試験投稿に失敗", "project_id", input.ID, "provider", input.Provider, "err", err)
+		return nil, huma.Error502BadGateway("チャットへの試験投稿に失敗", err)
+	}
+
+	output := &model.TestChatChannelOutput{}
+	output.Body.Message = "Test message posted successfully"
+	return output, nil
+}
+
+// DeleteChatChannel はプロジェクトのチャットの送信先を削除する
+func (h *ChatChannelHandler) DeleteChatChannel(ctx context.Context, input *model.DeleteChatChannelInput) (*model.DeleteChatChannelOutput, error) {
+	n, err := h.queries.DeleteProjectChatChannel(ctx, db.DeleteProjectChatChannelParams{
+		ProjectID: input.ID,
+		Provider:  input.Provider,
+	})
+	if err != nil {
+		slog.Warn("チャットの送信先の削除に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("チャットの送信先の削除に失敗", err)
+	}
+	if n == 0 {
+		return nil, huma.Error404NotFound(fmt.Sprintf("チャットの送信先が設定されていません: project=%d provider=%s", input.ID, input.Provider))
+	}
+
+	output := &model.DeleteChatChannelOutput{}
+	output.Body.Message = "Chat channel deleted successfully"
+	return output, nil
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// CommentHandler はTodoに紐づくコメントの操作を処理するハンドラー
+type CommentHandler struct {
+	queries *db.Queries
+	db      *sql.DB
+	bus     *event.Bus
+}
+
+// NewCommentHandler はCommentHandlerの新しいインスタンスを生成する
+func NewCommentHandler(queries *db.Queries, db *sql.DB, bus *event.Bus) *CommentHandler {
+	return &CommentHandler{
+		queries: queries,
+		db:      db,
+		bus:     bus,
+	}
+}
+
+// toCommentResponse はdb.Commentをmodel.CommentResponseに変換する
+func toCommentResponse(c db.Comment) model.CommentResponse {
+	return model.CommentResponse{
+		ID:        c.ID,
+		TodoID:    c.TodoID,
+		Author:    c.Author,
+		Body:      c.Body,
+		CreatedAt: c.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ListComments は指定されたTodoのコメントをカーソルによるページ単位で取得する
+func (h *CommentHandler) ListComments(ctx context.Context, input *model.ListCommentsInput) (*model.ListCommentsOutput, error) {
+	if err := ensureTodoExists(ctx, h.queries, input.TodoID); err != nil {
+		return nil, err
+	}
+
+	// 次のページの有無を判定するため1件多く取得する
+	comments, err := h.queries.ListComments(ctx, db.ListCommentsParams{
+		TodoID:  input.TodoID,
+		AfterID: input.Cursor,
+		Limit:   input.Limit + 1,
+	})
+	if err != nil {
+		slog.Warn("コメント一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("コメント一覧の取得に失敗", err)
+	}
+
+	output := &model.ListCommentsOutput{}
+	if int64(len(comments)) > input.Limit {
+		comments = comments[:input.Limit]
+		output.Body.NextCursor = &comments[len(comments)-1].ID
+	}
+	output.Body.Comments = make([]model.CommentResponse, len(comments))
+	for i, c := range comments {
+		output.Body.Comments[i] = toCommentResponse(c)
+	}
+
+	return output, nil
+}
+
+// CreateComment は指定されたTodoにコメントを投稿する。Todoをウォッチしているユーザーに通知するため、コメントのイベントを発行する
+func (h *CommentHandler) CreateComment(ctx context.Context, input *model.CreateCommentInput) (*model.CreateCommentOutput, error) {
+	var comment db.Comment
+	err := runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		if err := ensureTodoWritable(ctx, qtx, input.TodoID); err != nil {
+			return err
+		}
+
+		var err error
+		comment, err = qtx.CreateComment(ctx, db.CreateCommentParams{
+			TodoID: input.TodoID,
+			Author: input.Body.Author,
+			Body:   input.Body.Body,
+		})
+		if err != nil {
+			slog.Warn("コメント投稿に失敗", "err", err)
+			return huma.Error500InternalServerError("コメント投稿に失敗", err)
+		}
+		return recordEvent(ctx, qtx, event.Event{Type: event.TodoCommented, TodoID: input.TodoID, CommentID: comment.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.bus.Publish(event.Event{Type: event.TodoCommented, TodoID: input.TodoID, CommentID: comment.ID})
+
+	return &model.CreateCommentOutput{Body: toCommentResponse(comment)}, nil
+}
+
+// DeleteComment は指定されたIDのコメントを削除する
+func (h *CommentHandler) DeleteComment(ctx context.Context, input *model.DeleteCommentInput) (*model.DeleteCommentOutput, error) {
+	if err := ensureTodoWritable(ctx, h.queries, input.TodoID); err != nil {
+		return nil, err
+	}
+
+	n, err := h.queries.DeleteComment(ctx, db.DeleteCommentParams{
+		ID:     input.CommentID,
+		TodoID: input.TodoID,
+	})
+	if err != nil {
+		slog.Warn("コメント削除に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("コメント削除に失敗", err)
+	}
+	if n == 0 {
+		return nil, huma.Error404NotFound(fmt.Sprintf("コメントIDが見つかりません: todo=%d comment=%d", input.TodoID, input.CommentID))
+	}
+
+	output := &model.DeleteCommentOutput{}
+	output.Body.Message = "Comment deleted successfully"
+	return output, nil
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"go-huma-test/confirm"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// issueConfirmation は操作を実行せずに確認トークンを発行し、レスポンスに含める影響範囲と確認トークンを返す
+func issueConfirmation(ctx context.Context, c *confirm.Confirmer, operation, target string, impact confirm.Impact) (*model.ConfirmationResponse, error) {
+	confirmation, err := c.Issue(ctx, operation, target, impact)
+	if err != nil {
+		slog.Warn("確認トークンの発行に失敗", "operation", operation, "err", err)
+		return nil, huma.Error500InternalServerError("確認トークンの発行に失敗", err)
+	}
+	return &model.ConfirmationResponse{
+		Token:     confirmation.Token,
+		ExpiresAt: confirmation.ExpiresAt.Format(time.RFC3339),
+		Impact:    confirmation.Impact,
+	}, nil
+}
+
+// consumeConfirmation は確認トークンを照合して使用済みにする。qtxには操作と同じトランザクションを渡す。
+// 確認トークンが無効な場合は422を、発行後に影響範囲が変わった場合は409を返す。
+func consumeConfirmation(ctx context.Context, c *confirm.Confirmer, qtx *db.Queries, token, operation, target string, impact confirm.Impact) error {
+	err := c.Consume(ctx, qtx, token, operation, target, impact)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, confirm.ErrInvalidToken):
+		slog.Warn("確認トークンが無効です", "operation", operation, "target", target)
+		return huma.Error422UnprocessableEntity("確認トークンが無効か期限切れです。確認トークンを省略して呼び出し、新しい確認トークンを取得してください", &huma.ErrorDetail{
+			Location: "header." + confirm.TokenHeader,
+		})
+	case errors.Is(err, confirm.ErrImpactChanged):
+		slog.Warn("確認後に影響範囲が変わりました", "operation", operation, "target", target, "impact", impact)
+		return huma.Error409Conflict("確認後に影響範囲が変わりました。確認トークンを省略して呼び出し、影響範囲を確認し直してください")
+	default:
+		slog.Warn("確認トークンの照合に失敗", "err", err)
+		return huma.Error500InternalServerError("確認トークンの照合に失敗", err)
+	}
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// TodoDefaultsHandler はTodoの作成時に使う既定値に関する操作を処理するハンドラー
+type TodoDefaultsHandler struct {
+	queries *db.Queries
+}
+
+// NewTodoDefaultsHandler はTodoDefaultsHandlerの新しいインスタンスを生成する
+func NewTodoDefaultsHandler(queries *db.Queries) *TodoDefaultsHandler {
+	return &TodoDefaultsHandler{
+		queries: queries,
+	}
+}
+
+// resolveTodoDefaults はプロジェクトに所属するTodoの作成時に使う既定値を返す。
+// プロジェクトの既定値で指定していない項目は全体の既定値にする。projectIDが無効な場合は全体の既定値を返す。
+func resolveTodoDefaults(ctx context.Context, q *db.Queries, projectID sql.NullInt64) (db.TodoDefault, error) {
+	rows, err := q.ListTodoDefaultsForProject(ctx, projectID)
+	if err != nil {
+		slog.Warn("既定値の取得に失敗", "project_id", projectID.Int64, "err", err)
+		return db.TodoDefault{}, huma.Error500InternalServerError("既定値の取得に失敗", err)
+	}
+	var global, project db.TodoDefault
+	for _, r := range rows {
+		if r.ProjectID.Valid {
+			project = r
+		} else {
+			global = r
+		}
+	}
+	if !project.Priority.Valid {
+		project.Priority = global.Priority
+	}
+	if !project.DueOffsetSeconds.Valid {
+		project.DueOffsetSeconds = global.DueOffsetSeconds
+	}
+	if !project.Metadata.Valid {
+		project.Metadata = global.Metadata
+	}
+	if !project.Tags.Valid {
+		project.Tags = global.Tags
+	}
+	return project, nil
+}
+
+// encodeDefaultTags は既定のタグの名前を正規化してDBに保存するJSON配列にする。nilの場合は既定値なしにする
+func encodeDefaultTags(names []string) (sql.NullString, error) {
+	if names == nil {
+		return sql.NullString{}, nil
+	}
+	names, err := normalizeTagNames(names, "body.tags")
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	b, err := json.Marshal(names)
+	if err != nil {
+		return sql.NullString{}, huma.Error500InternalServerError("既定のタグのエンコードに失敗", err)
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+// decodeDefaultTags はDBに保存した既定のタグの名前を返す。既定値がない場合はnilを返す
+func decodeDefaultTags(s sql.NullString) []string {
+	if !s.Valid {
+		return nil
+	}
+	names := []string{}
+	if err := json.Unmarshal([]byte(s.String), &names); err != nil {
+		slog.Warn("保存済みのJSONを読み込めません", "err", err)
+		return nil
+	}
+	return names
+}
+
+// toTodoDefaults はdb.TodoDefaultをmodel.TodoDefaultsに変換する
+func toTodoDefaults(d db.TodoDefault) model.TodoDefaults {
+	defaults := model.TodoDefaults{
+		Priority: nullInt64ToPtr(d.Priority),
+		Metadata: decodeJSONObject(d.Metadata),
+		Tags:     decodeDefaultTags(d.Tags),
+	}
+	if d.DueOffsetSeconds.Valid {
+		offset := (time.Duration(d.DueOffsetSeconds.Int64) * time.Second).String()
+		defaults.DueOffset = &offset
+	}
+	return defaults
+}
+
+// todoDefaultsResponse は設定した既定値と、Todoの作成時に使う既定値をレスポンスにする
+func (h *TodoDefaultsHandler) todoDefaultsResponse(ctx context.Context, d db.TodoDefault, projectID sql.NullInt64) (*model.TodoDefaultsOutput, error) {
+	effective, err := resolveTodoDefaults(ctx, h.queries, projectID)
+	if err != nil {
+		return nil, err
+	}
+	output := &model.TodoDefaultsOutput{}
+	output.Body.ProjectID = nullInt64ToPtr(projectID)
+	output.Body.Defaults = toTodoDefaults(d)
+	output.Body.Effective = toTodoDefaults(effective)
+	if d.ID != 0 {
+		updatedAt := d.UpdatedAt.UTC().Format(time.RFC3339)
+		output.Body.UpdatedAt = &updatedAt
+	}
+	return output, nil
+}
+
+// getTodoDefaults は設定した既定値を返す。設定していない場合は空の既定値を返す
+func (h *TodoDefaultsHandler) getTodoDefaults(ctx context.Context, projectID sql.NullInt64) (*model.TodoDefaultsOutput, error) {
+	d, err := h.queries.GetTodoDefaults(ctx, projectID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Warn("既定値の取得に失敗", "project_id", projectID.Int64, "err", err)
+		return nil, huma.Error500InternalServerError("既定値の取得に失敗", err)
+	}
+	return h.todoDefaultsResponse(ctx, d, projectID)
+}
+
+// updateTodoDefaults は既定値を置き換える。省略した項目は設定を外す
+func (h *TodoDefaultsHandler) updateTodoDefaults(ctx context.Context, projectID sql.NullInt64, body model.TodoDefaults) (*model.TodoDefaultsOutput, error) {
+	metadata, err := encodeMetadata(body.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := encodeDefaultTags(body.Tags)
+	if err != nil {
+		return nil, err
+	}
+	var dueOffset sql.NullInt64
+	if body.DueOffset != nil {
+		// Resolveで検証済みのため、ここでは解析に失敗しない
+		offset, _ := time.ParseDuration(*body.DueOffset)
+		dueOffset = sql.NullInt64{Int64: int64(offset.Round(time.Second) / time.Second), Valid: true}
+	}
+	d, err := h.queries.UpsertTodoDefaults(ctx, db.UpsertTodoDefaultsParams{
+		ProjectID:        projectID,
+		Priority:         ptrInt64ToNullInt64(body.Priority),
+		DueOffsetSeconds: dueOffset,
+		Metadata:         metadata,
+		Tags:             tags,
+	})
+	if err != nil {
+		slog.Warn("既定値の更新に失敗", "project_id", projectID.Int64, "err", err)
+		return nil, huma.Error500InternalServerError("既定値の更新に失敗", err)
+	}
+	slog.Info("既定値を更新", "project_id", projectID.Int64, "priority", d.Priority.Int64, "due_offset_seconds", d.DueOffsetSeconds.Int64)
+	return h.todoDefaultsResponse(ctx, d, projectID)
+}
+
+// GetGlobalTodoDefaults は全体の既定値を取得する
+func (h *TodoDefaultsHandler) GetGlobalTodoDefaults(ctx context.Context, _ *struct{}) (*model.TodoDefaultsOutput, error) {
+	return h.getTodoDefaults(ctx, sql.NullInt64{})
+}
+
+// UpdateGlobalTodoDefaults は全体の既定値を置き換える
+func (h *TodoDefaultsHandler) UpdateGlobalTodoDefaults(ctx context.Context, input *model.UpdateTodoDefaultsInput) (*model.TodoDefaultsOutput, error) {
+	return h.updateTodoDefaults(ctx, sql.NullInt64{}, input.Body)
+}
+
+// GetProjectTodoDefaults はプロジェクトの既定値を取得する
+func (h *TodoDefaultsHandler) GetProjectTodoDefaults(ctx context.Context, input *model.ProjectTodoDefaultsInput) (*model.TodoDefaultsOutput, error) {
+	if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+	return h.getTodoDefaults(ctx, sql.NullInt64{Int64: input.ID, Valid: true})
+}
+
+// UpdateProjectTodoDefaults はプロジェクトの既定値を置き換える
+func (h *TodoDefaultsHandler) UpdateProjectTodoDefaults(ctx context.Context, input *model.UpdateProjectTodoDefaultsInput) (*model.TodoDefaultsOutput, error) {
+	if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+	return h.updateTodoDefaults(ctx, sql.NullInt64{Int64: input.ID, Valid: true}, input.Body)
+}
+
+// DeleteProjectTodoDefaults はプロジェクトの既定値を削除し、全体の既定値を使うようにする
+func (h *TodoDefaultsHandler) DeleteProjectTodoDefaults(ctx context.Context, input *model.ProjectTodoDefaultsInput) (*model.DeleteTodoDefaultsOutput, error) {
+	n, err := h.queries.DeleteTodoDefaults(ctx, sql.NullInt64{Int64: input.ID, Valid: true})
+	if err != nil {
+		slog.Warn("既定値の削除に失敗", "project_id", input.ID, "err", err)
+		return nil, huma.Error500InternalServerError("既定値の削除に失敗", err)
+	}
+	if n == 0 {
+		return nil, huma.Error404NotFound(fmt.Sprintf("プロジェクトの既定値が設定されていません: %d", input.ID))
+	}
+
+	output := &model.DeleteTodoDefaultsOutput{}
+	output.Body.Message = "Todo defaults deleted successfully"
+	return output, nil
+}
+
+// DocumentTodoDefaults は全体の既定値をTodo作成のリクエストボディのスキーマのdefaultに反映する。
+// OpenAPIのドキュメントは最初に配信したときの内容を使い続けるため、起動時の既定値を反映し、変更は再起動後に反映される。
+func DocumentTodoDefaults(ctx context.Context, q *db.Queries, oapi *huma.OpenAPI) error {
+	d, err := q.GetTodoDefaults(ctx, sql.NullInt64{})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	item := oapi.Paths["/todos"]
+	if item == nil || item.Post == nil || item.Post.RequestBody == nil {
+		return nil
+	}
+	media := item.Post.RequestBody.Content["application/json"]
+	if media == nil || media.Schema == nil {
+		return nil
+	}
+	schema := media.Schema
+	if schema.Ref != "" {
+		schema = oapi.Components.Schemas.SchemaFromRef(schema.Ref)
+	}
+
+	defaults := toTodoDefaults(d)
+	if p := schema.Properties["priority"]; p != nil && defaults.Priority != nil {
+		p.Default = *defaults.Priority
+	}
+	if p := schema.Properties["metadata"]; p != nil && defaults.Metadata != nil {
+		p.Default = defaults.Metadata
+	}
+	if p := schema.Properties["tags"]; p != nil && defaults.Tags != nil {
+		p.Default = defaults.Tags
+	}
+	if p := schema.Properties["due_at"]; p != nil && defaults.DueOffset != nil {
+		p.Description = strings.TrimSuffix(p.Description, "。") + fmt.Sprintf("。省略すると作成日時の%s後になる", *defaults.DueOffset)
+	}
+	slog.Info("全体の既定値をOpenAPIのドキュメントに反映")
+	return nil
+}
@@ -0,0 +1,301 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"go-huma-test/ot"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// maxDescriptionLength はTodoの説明の最大文字数。作成と更新のmaxLengthと同じ。
+const maxDescriptionLength = 1000
+
+// maxDescriptionHistory はTodoごとに残す説明の操作の数。これより古い版を基にした操作は409を返す。
+const maxDescriptionHistory = 500
+
+// DescriptionHandler はTodoの説明の共同編集を処理するハンドラー。
+// クライアントは取得した版を基に作った操作を送り、サーバーはその版より後に適用した操作に対して
+// 変換してから適用するため、同じ説明を並行に編集しても互いの変更を上書きしない。
+type DescriptionHandler struct {
+	queries *db.Queries
+	db      *sql.DB
+	bus     *event.Bus
+	clock   clock.Clock
+}
+
+// NewDescriptionHandler はDescriptionHandlerの新しいインスタンスを生成する。Todoのロックの期限はclockの現在時刻で判定する
+func NewDescriptionHandler(queries *db.Queries, db *sql.DB, bus *event.Bus, clock clock.Clock) *DescriptionHandler {
+	return &DescriptionHandler{
+		queries: queries,
+		db:      db,
+		bus:     bus,
+		clock:   clock,
+	}
+}
+
+// descriptionHead は履歴の最新の版と説明を返す。履歴がない場合は空の説明を版0とする。
+// PUTなどの共同編集以外の更新で説明が変わっている場合は、説明全体を置き換える操作を次の版として返す。
+// この操作は記録していないため、記録するかは呼び出し元が決める。
+func descriptionHead(ctx context.Context, q *db.Queries, todo db.Todo) (head db.TodoDescriptionOp, sync *db.TodoDescriptionOp, err error) {
+	head, err = q.GetLatestDescriptionOp(ctx, todo.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return db.TodoDescriptionOp{}, nil, err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		head = db.TodoDescriptionOp{TodoID: todo.ID}
+	}
+	current := todo.Description.String
+	if head.Description == current {
+		return head, nil, nil
+	}
+	ops, err := json.Marshal(ot.Replace(utf8.RuneCountInString(head.Description), current))
+	if err != nil {
+		return db.TodoDescriptionOp{}, nil, err
+	}
+	return head, &db.TodoDescriptionOp{
+		TodoID:      todo.ID,
+		Revision:    head.Revision + 1,
+		Ops:         string(ops),
+		Description: current,
+		Actor:       activity.SystemActor,
+		CreatedAt:   todo.UpdatedAt,
+	}, nil
+}
+
+// toDescriptionRevision はdb.TodoDescriptionOpをmodel.DescriptionRevisionに変換する
+func toDescriptionRevision(op db.TodoDescriptionOp) (model.DescriptionRevision, error) {
+	var ops ot.Operation
+	if err := json.Unmarshal([]byte(op.Ops), &ops); err != nil {
+		return model.DescriptionRevision{}, fmt.Errorf("版%dの操作を解析できません: %w", op.Revision, err)
+	}
+	return model.DescriptionRevision{
+		Revision:  op.Revision,
+		Ops:       ops,
+		Actor:     op.Actor,
+		CreatedAt: op.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// descriptionOpsSince はsinceより後に適用した操作を返す。
+// 古い操作を削除済みでsinceから続く操作を返せない場合は409を返す。
+func descriptionOpsSince(ctx context.Context, q *db.Queries, todoID, since int64) ([]db.TodoDescriptionOp, error) {
+	oldest, err := q.GetOldestDescriptionRevision(ctx, todoID)
+	if err != nil {
+		slog.Warn("説明の操作履歴の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("説明の操作履歴の取得に失敗", err)
+	}
+	if oldest > 0 && since < oldest-1 {
+		slog.Warn("説明の操作履歴が残っていません", "todo_id", todoID, "since", since, "oldest", oldest)
+		return nil, huma.Error409Conflict(fmt.Sprintf("版%dからの操作履歴は残っていません。説明を取得し直してください", since))
+	}
+	ops, err := q.ListDescriptionOps(ctx, db.ListDescriptionOpsParams{TodoID: todoID, Revision: since})
+	if err != nil {
+		slog.Warn("説明の操作履歴の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("説明の操作履歴の取得に失敗", err)
+	}
+	return ops, nil
+}
+
+// GetDescription はTodoの説明の現在の版と内容を取得する
+func (h *DescriptionHandler) GetDescription(ctx context.Context, input *model.GetDescriptionInput) (*model.GetDescriptionOutput, error) {
+	todo, err := getTodo(ctx, h.queries, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	head, sync, err := descriptionHead(ctx, h.queries, todo)
+	if err != nil {
+		slog.Warn("説明の版の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("説明の版の取得に失敗", err)
+	}
+	if sync != nil {
+		head = *sync
+	}
+	return &model.GetDescriptionOutput{Body: model.DescriptionState{
+		TodoID:      todo.ID,
+		Revision:    head.Revision,
+		Description: head.Description,
+	}}, nil
+}
+
+// ListDescriptionOps は指定した版より後に適用した説明の操作を取得する。
+// クライアントは他のユーザーの変更を自分の説明に反映するために使う。
+func (h *DescriptionHandler) ListDescriptionOps(ctx context.Context, input *model.ListDescriptionOpsInput) (*model.ListDescriptionOpsOutput, error) {
+	todo, err := getTodo(ctx, h.queries, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	head, sync, err := descriptionHead(ctx, h.queries, todo)
+	if err != nil {
+		slog.Warn("説明の版の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("説明の版の取得に失敗", err)
+	}
+	ops, err := descriptionOpsSince(ctx, h.queries, todo.ID, input.Since)
+	if err != nil {
+		return nil, err
+	}
+	if sync != nil {
+		ops = append(ops, *sync)
+		head = *sync
+	}
+
+	output := &model.ListDescriptionOpsOutput{}
+	output.Body.TodoID = todo.ID
+	output.Body.Revision = head.Revision
+	output.Body.Description = head.Description
+	output.Body.Revisions = []model.DescriptionRevision{}
+	for _, op := range ops {
+		if op.Revision <= input.Since {
+			continue
+		}
+		r, err := toDescriptionRevision(op)
+		if err != nil {
+			slog.Warn("説明の操作履歴の変換に失敗", "err", err)
+			return nil, huma.Error500InternalServerError("説明の操作履歴の変換に失敗", err)
+		}
+		output.Body.Revisions = append(output.Body.Revisions, r)
+	}
+	return output, nil
+}
+
+// EditDescription はクライアントが基にした版より後に適用した操作に対して送られた操作を変換し、最新の説明に適用する。
+// 同じ位置への挿入は先に適用した操作の文字列を前に置く。
+func (h *DescriptionHandler) EditDescription(ctx context.Context, input *model.EditDescriptionInput) (*model.EditDescriptionOutput, error) {
+	op := input.Body.Ops
+	if err := op.Validate(); err != nil {
+		return nil, huma.Error422UnprocessableEntity(err.Error(), &huma.ErrorDetail{Location: "body.ops"})
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Warn("トランザクション開始に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("トランザクション開始に失敗", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := h.queries.WithTx(tx)
+
+	before, err := getUnlockedTodo(ctx, qtx, input.ID, h.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	head, sync, err := descriptionHead(ctx, qtx, before)
+	if err != nil {
+		slog.Warn("説明の版の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("説明の版の取得に失敗", err)
+	}
+	// 共同編集以外の更新による変更も、並行する操作として変換できるよう履歴に記録する
+	if sync != nil {
+		if err := qtx.CreateDescriptionOp(ctx, db.CreateDescriptionOpParams{
+			TodoID:      sync.TodoID,
+			Revision:    sync.Revision,
+			Ops:         sync.Ops,
+			Description: sync.Description,
+			Actor:       sync.Actor,
+		}); err != nil {
+			slog.Warn("説明の操作の記録に失敗", "err", err)
+			return nil, huma.Error500InternalServerError("説明の操作の記録に失敗", err)
+		}
+		head = *sync
+	}
+	if input.Body.BaseRevision > head.Revision {
+		return nil, huma.Error422UnprocessableEntity(fmt.Sprintf("base_revisionが説明の最新の版%dより新しい版です", head.Revision), &huma.ErrorDetail{
+			Location: "body.base_revision",
+			Value:    input.Body.BaseRevision,
+		})
+	}
+
+	concurrent, err := descriptionOpsSince(ctx, qtx, before.ID, input.Body.BaseRevision)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range concurrent {
+		var applied ot.Operation
+		if err := json.Unmarshal([]byte(c.Ops), &applied); err != nil {
+			slog.Warn("説明の操作を解析できません", "todo_id", before.ID, "revision", c.Revision, "err", err)
+			return nil, huma.Error500InternalServerError("説明の操作を解析できません", err)
+		}
+		if _, op, err = ot.Transform(applied, op); err != nil {
+			slog.Warn("説明の操作を変換できません", "todo_id", before.ID, "revision", c.Revision, "err", err)
+			return nil, huma.Error409Conflict(fmt.Sprintf("操作の長さが版%dの説明と一致しません。説明を取得し直してください", input.Body.BaseRevision))
+		}
+	}
+
+	description, err := op.Apply(head.Description)
+	if err != nil {
+		slog.Warn("説明に操作を適用できません", "todo_id", before.ID, "err", err)
+		return nil, huma.Error409Conflict(fmt.Sprintf("操作の長さが版%dの説明と一致しません。説明を取得し直してください", input.Body.BaseRevision))
+	}
+	if utf8.RuneCountInString(description) > maxDescriptionLength {
+		return nil, huma.Error422UnprocessableEntity(fmt.Sprintf("説明は%d文字以内にしてください", maxDescriptionLength), &huma.ErrorDetail{Location: "body.ops"})
+	}
+
+	output := &model.EditDescriptionOutput{}
+	output.Body.TodoID = before.ID
+	output.Body.Ops = op
+	todo := before
+	if description != head.Description {
+		encoded, err := json.Marshal(op)
+		if err != nil {
+			return nil, huma.Error500InternalServerError("操作のエンコードに失敗", err)
+		}
+		head = db.TodoDescriptionOp{TodoID: before.ID, Revision: head.Revision + 1, Description: description}
+		if err := qtx.CreateDescriptionOp(ctx, db.CreateDescriptionOpParams{
+			TodoID:      before.ID,
+			Revision:    head.Revision,
+			Ops:         string(encoded),
+			Description: description,
+			Actor:       activity.ActorFrom(ctx),
+		}); err != nil {
+			slog.Warn("説明の操作の記録に失敗", "err", err)
+			return nil, huma.Error500InternalServerError("説明の操作の記録に失敗", err)
+		}
+		if err := qtx.PruneDescriptionOps(ctx, db.PruneDescriptionOpsParams{TodoID: before.ID, Revision: head.Revision - maxDescriptionHistory}); err != nil {
+			slog.Warn("古い説明の操作の削除に失敗", "err", err)
+			return nil, huma.Error500InternalServerError("古い説明の操作の削除に失敗", err)
+		}
+
+		todo, err = qtx.UpdateTodoDescription(ctx, db.UpdateTodoDescriptionParams{
+			Description: sql.NullString{String: description, Valid: description != ""},
+			ID:          before.ID,
+		})
+		if err != nil {
+			slog.Warn("Todoの説明の更新に失敗", "err", err)
+			return nil, huma.Error500InternalServerError("Todoの説明の更新に失敗", err)
+		}
+		if err := recordActivity(ctx, qtx, activity.ActionUpdate, &before, &todo); err != nil {
+			return nil, err
+		}
+		if err := recordEvent(ctx, qtx, event.Event{Type: event.TodoUpdated, TodoID: todo.ID}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Warn("トランザクションのコミットに失敗", "err", err)
+		return nil, huma.Error500InternalServerError("トランザクションのコミットに失敗", err)
+	}
+
+	if todo.Version != before.Version {
+		h.bus.Publish(event.Event{Type: event.TodoUpdated, TodoID: todo.ID})
+	}
+
+	output.ETag = todoETag(todo)
+	output.Body.Revision = head.Revision
+	output.Body.Description = head.Description
+	return output, nil
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"go-huma-test/activity"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"go-huma-test/storage"
+	"log/slog"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// duplicateTitlePrefix は複製したTodoのタイトルに付ける接頭辞
+const duplicateTitlePrefix = "Copy of "
+
+// maxTodoTitleLength はTodoのタイトルの最大文字数
+const maxTodoTitleLength = 200
+
+// duplicateTitle は複製元のタイトルから複製のタイトルを生成する。
+// タイトルの上限を超える場合は末尾を切り詰める。
+func duplicateTitle(title string) string {
+	r := []rune(duplicateTitlePrefix + title)
+	if len(r) > maxTodoTitleLength {
+		r = r[:maxTodoTitleLength]
+	}
+	return string(r)
+}
+
+// copyBlob はストアに保存されたデータを新しいキーに複製し、そのキーを返す
+func copyBlob(ctx context.Context, store storage.BlobStore, key string) (string, error) {
+	r, err := store.Open(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	newKey, err := storage.NewKey()
+	if err != nil {
+		return "", err
+	}
+	if _, err := store.Put(ctx, newKey, r); err != nil {
+		return "", err
+	}
+	return newKey, nil
+}
+
+// DuplicateTodo は指定されたTodoを未完了の状態で複製する。
+// サブタスクと添付ファイルは指定された場合のみ複製し、すべて1つのトランザクションで登録する。
+func (h *TodoHandler) DuplicateTodo(ctx context.Context, input *model.DuplicateTodoInput) (*model.DuplicateTodoOutput, error) {
+	// 添付ファイルの本体はトランザクションの外にあるため、失敗時に複製した分を削除する
+	var copiedKeys []string
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		for _, key := range copiedKeys {
+			if err := h.store.Delete(ctx, key); err != nil {
+				slog.Warn("添付ファイルの削除に失敗", "key", key, "err", err)
+			}
+		}
+	}()
+
+	var opts model.DuplicateTodoOptions
+	if input.Body != nil {
+		opts = *input.Body
+	}
+
+	var todo db.Todo
+	err := runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		// 共有されたTodoの複製は、複製した利用者の所有とする
+		src, err := getTodo(ctx, qtx, input.ID)
+		if err != nil {
+			return err
+		}
+		if err := ensureProjectAssignable(ctx, qtx, nullInt64ToPtr(src.ProjectID)); err != nil {
+			return err
+		}
+		if err := checkWIPLimit(ctx, qtx, src.ProjectID); err != nil {
+			return err
+		}
+
+		todo, err = qtx.CreateTodo(ctx, db.CreateTodoParams{
+			Title:        duplicateTitle(src.Title),
+			Description:  src.Description,
+			Completed:    0,
+			Latitude:     src.Latitude,
+			Longitude:    src.Longitude,
+			PlaceName:    src.PlaceName,
+			ProjectID:    src.ProjectID,
+			DueAt:        src.DueAt,
+			Recurrence:   src.Recurrence,
+			Assignee:     src.Assignee,
+			OwnerID:      ownerID(ctx),
+			Metadata:     src.Metadata,
+			Priority:     src.Priority,
+			Translations: src.Translations,
+		})
+		if err != nil {
+			slog.Warn("Todoの複製に失敗", "id", src.ID, "err", err)
+			return huma.Error500InternalServerError("Todoの複製に失敗", err)
+		}
+
+		if opts.IncludeSubtasks {
+			subtasks, err := qtx.ListSubtasks(ctx, src.ID)
+			if err != nil {
+				slog.Warn("サブタスク一覧の取得に失敗", "id", src.ID, "err", err)
+				return huma.Error500InternalServerError("サブタスク一覧の取得に失敗", err)
+			}
+			for _, s := range subtasks {
+				if _, err := qtx.CreateSubtask(ctx, db.CreateSubtaskParams{
+					TodoID:    todo.ID,
+					Title:     s.Title,
+					Completed: 0,
+				}); err != nil {
+					slog.Warn("サブタスクの複製に失敗", "id", s.ID, "err", err)
+					return huma.Error500InternalServerError("サブタスクの複製に失敗", err)
+				}
+			}
+		}
+
+		if opts.IncludeAttachments {
+			attachments, err := qtx.ListAttachments(ctx, src.ID)
+			if err != nil {
+				slog.Warn("添付ファイル一覧の取得に失敗", "id", src.ID, "err", err)
+				return huma.Error500InternalServerError("添付ファイル一覧の取得に失敗", err)
+			}
+			for _, a := range attachments {
+				// 複製元の添付と独立して扱えるよう、本体も別のキーに複製する
+				key, err := copyBlob(ctx, h.store, a.StorageKey)
+				if err != nil {
+					slog.Warn("添付ファイルの複製に失敗", "id", a.ID, "err", err)
+					return huma.Error500InternalServerError("添付ファイルの複製に失敗", err)
+				}
+				copiedKeys = append(copiedKeys, key)
+
+				if _, err := qtx.CreateAttachment(ctx, db.CreateAttachmentParams{
+					TodoID:      todo.ID,
+					Filename:    a.Filename,
+					ContentType: a.ContentType,
+					Size:        a.Size,
+					StorageKey:  key,
+				}); err != nil {
+					slog.Warn("添付ファイルの登録に失敗", "id", a.ID, "err", err)
+					return huma.Error500InternalServerError("添付ファイルの登録に失敗", err)
+				}
+			}
+		}
+
+		// サブタスクの件数はトリガーで更新されるため、登録後のTodoを取り直す
+		todo, err = getTodo(ctx, qtx, todo.ID)
+		if err != nil {
+			return err
+		}
+		if err := recordActivity(ctx, qtx, activity.ActionCreate, nil, &todo); err != nil {
+			return err
+		}
+		return recordEvent(ctx, qtx, event.Event{Type: event.TodoCreated, TodoID: todo.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	committed = true
+
+	h.bus.Publish(event.Event{Type: event.TodoCreated, TodoID: todo.ID})
+
+	return &model.DuplicateTodoOutput{Body: toTodoResponse(ctx, todo)}, nil
+}
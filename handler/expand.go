@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"context"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"slices"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// expandTodos はexpandで指定された関連リソースをTodoのレスポンスに含める。
+// 関連リソースは種類ごとに1回のクエリでまとめて取得し、Todoの件数に比例した問い合わせを避ける。
+func expandTodos(ctx context.Context, q *db.Queries, todos []model.TodoResponse, expand []string) error {
+	if len(expand) == 0 || len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(todos))
+	index := make(map[int64]int, len(todos))
+	for i, t := range todos {
+		ids[i] = t.ID
+		index[t.ID] = i
+	}
+
+	for _, name := range slices.Compact(slices.Sorted(slices.Values(expand))) {
+		var err error
+		switch name {
+		case model.ExpandSubtasks:
+			err = expandSubtasks(ctx, q, todos, ids, index)
+		case model.ExpandComments:
+			err = expandComments(ctx, q, todos, ids, index)
+		case model.ExpandCommentCount:
+			err = expandCommentCounts(ctx, q, todos, ids, index)
+		case model.ExpandReminders:
+			err = expandReminders(ctx, q, todos, ids, index)
+		case model.ExpandAttachments:
+			err = expandAttachments(ctx, q, todos, ids, index)
+		case model.ExpandProject:
+			err = expandProjects(ctx, q, todos)
+		case model.ExpandWatchers:
+			err = expandWatchers(ctx, q, todos, ids, index)
+		case model.ExpandTags:
+			err = expandTags(ctx, q, todos, ids, index)
+		}
+		if err != nil {
+			slog.Warn("関連リソースの取得に失敗", "expand", name, "err", err)
+			return huma.Error500InternalServerError("関連リソースの取得に失敗", err)
+		}
+	}
+	return nil
+}
+
+// expandSubtasks はサブタスクをTodoに含める
+func expandSubtasks(ctx context.Context, q *db.Queries, todos []model.TodoResponse, ids []int64, index map[int64]int) error {
+	subtasks, err := q.ListSubtasksByTodoIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range todos {
+		todos[i].Subtasks = []model.SubtaskResponse{}
+	}
+	for _, s := range subtasks {
+		t := &todos[index[s.TodoID]]
+		t.Subtasks = append(t.Subtasks, toSubtaskResponse(s))
+	}
+	return nil
+}
+
+// expandComments はコメントをTodoに含める
+func expandComments(ctx context.Context, q *db.Queries, todos []model.TodoResponse, ids []int64, index map[int64]int) error {
+	comments, err := q.ListCommentsByTodoIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range todos {
+		todos[i].Comments = []model.CommentResponse{}
+	}
+	for _, c := range comments {
+		t := &todos[index[c.TodoID]]
+		t.Comments = append(t.Comments, toCommentResponse(c))
+	}
+	return nil
+}
+
+// expandCommentCounts はコメントの件数をTodoに含める
+func expandCommentCounts(ctx context.Context, q *db.Queries, todos []model.TodoResponse, ids []int64, index map[int64]int) error {
+	counts, err := q.CountCommentsByTodoIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range todos {
+		todos[i].CommentCount = new(int64)
+	}
+	for _, c := range counts {
+		*todos[index[c.TodoID]].CommentCount = c.Count
+	}
+	return nil
+}
+
+// expandReminders はリマインダーをTodoに含める
+func expandReminders(ctx context.Context, q *db.Queries, todos []model.TodoResponse, ids []int64, index map[int64]int) error {
+	reminders, err := q.ListRemindersByTodoIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range todos {
+		todos[i].Reminders = []model.ReminderResponse{}
+	}
+	for _, r := range reminders {
+		t := &todos[index[r.TodoID]]
+		t.Reminders = append(t.Reminders, toReminderResponse(r))
+	}
+	return nil
+}
+
+// expandAttachments は添付ファイルをTodoに含める
+func expandAttachments(ctx context.Context, q *db.Queries, todos []model.TodoResponse, ids []int64, index map[int64]int) error {
+	attachments, err := q.ListAttachmentsByTodoIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range todos {
+		todos[i].Attachments = []model.AttachmentResponse{}
+	}
+	for _, a := range attachments {
+		t := &todos[index[a.TodoID]]
+		t.Attachments = append(t.Attachments, toAttachmentResponse(a))
+	}
+	return nil
+}
+
+// expandWatchers はウォッチしているユーザーをTodoに含める
+func expandWatchers(ctx context.Context, q *db.Queries, todos []model.TodoResponse, ids []int64, index map[int64]int) error {
+	watchers, err := q.ListWatchersByTodoIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range todos {
+		todos[i].Watchers = []model.WatcherResponse{}
+	}
+	for _, w := range watchers {
+		t := &todos[index[w.TodoID]]
+		t.Watchers = append(t.Watchers, toWatcherResponse(w))
+	}
+	return nil
+}
+
+// expandTags はタグの名前をTodoに含める
+func expandTags(ctx context.Context, q *db.Queries, todos []model.TodoResponse, ids []int64, index map[int64]int) error {
+	tags, err := q.ListTodoTagNames(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range todos {
+		todos[i].Tags = []string{}
+	}
+	for _, tag := range tags {
+		t := &todos[index[tag.TodoID]]
+		t.Tags = append(t.Tags, tag.Name)
+	}
+	return nil
+}
+
+// expandProjects は所属するプロジェクトをTodoに含める
+func expandProjects(ctx context.Context, q *db.Queries, todos []model.TodoResponse) error {
+	var projectIDs []int64
+	for _, t := range todos {
+		if t.ProjectID != nil && !slices.Contains(projectIDs, *t.ProjectID) {
+			projectIDs = append(projectIDs, *t.ProjectID)
+		}
+	}
+	if len(projectIDs) == 0 {
+		return nil
+	}
+
+	projects, err := q.ListProjectsByIDs(ctx, projectIDs)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]model.ProjectResponse, len(projects))
+	for _, p := range projects {
+		byID[p.ID] = toProjectResponse(p)
+	}
+	for i, t := range todos {
+		if t.ProjectID == nil {
+			continue
+		}
+		if p, ok := byID[*t.ProjectID]; ok {
+			todos[i].Project = &p
+		}
+	}
+	return nil
+}
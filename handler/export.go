@@ -0,0 +1,578 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// exportBatchSize はエクスポートで一度にデータベースから取得するTodoの件数。
+// 件数によらずメモリの使用量がこの件数分に収まるよう、取得した分を書き込んでから続きを取得する
+const exportBatchSize = 500
+
+// todoCSVHeader はTodoのCSVのヘッダー行
+var todoCSVHeader = []string{
+	"id", "title", "description", "completed", "priority", "project_id", "due_at", "assignee", "recurrence",
+	"place_name", "latitude", "longitude", "metadata", "owner_id", "created_at", "updated_at", "archived_at",
+}
+
+// csvFormulaPrefixes は表計算ソフトが数式として解釈する先頭の文字
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvText はCSVに書く利用者の入力した文字列を返す。表計算ソフトで開いたときに数式として実行されないよう、
+// 数式と解釈される文字で始まる値は先頭に'を付ける
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune(csvFormulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// todoExporter はエクスポートするTodoの絞り込み条件で、続きのTodoを順に取得する
+type todoExporter struct {
+	queries *db.Queries
+	params  db.ListTodosForExportParams
+}
+
+// next は前回の続きのTodoを取得する。すべて取得した場合は空のスライスを返す
+func (e *todoExporter) next(ctx context.Context) ([]db.Todo, error) {
+	todos, err := e.queries.ListTodosForExport(ctx, e.params)
+	if err != nil {
+		return nil, err
+	}
+	if len(todos) > 0 {
+		e.params.AfterID = todos[len(todos)-1].ID
+	}
+	return todos, nil
+}
+
+// ExportTodos は絞り込み条件に一致するTodoをCSVまたはJSONでエクスポートする。
+// 件数が多くてもメモリに溜めないよう、IDの順に一定件数ずつ取得して書き込む。
+// 書き込み中に追加されたTodoは、取得済みのIDより後であれば含まれる。
+func (h *TodoHandler) ExportTodos(ctx context.Context, input *model.ExportTodosInput) (*huma.StreamResponse, error) {
+	metadataPath, metadataValue := parseMetadataFilter(input.Metadata)
+	var completed sql.NullInt64
+	switch input.Status {
+	case "open":
+		completed = sql.NullInt64{Int64: 0, Valid: true}
+	case "completed":
+		completed = sql.NullInt64{Int64: 1, Valid: true}
+	}
+	exporter := &todoExporter{
+		queries: h.queries,
+		params: db.ListTodosForExportParams{
+			UserID:               ownerID(ctx),
+			Completed:            completed,
+			ProjectID:            sql.NullInt64{Int64: input.ProjectID, Valid: input.ProjectID != 0},
+			IncludeArchivedLists: boolToInt64(input.IncludeArchivedLists),
+			MetadataPath:         metadataPath,
+			MetadataValue:        metadataValue,
+			Limit:                exportBatchSize,
+		},
+	}
+
+	// 書き込みを始めた後はステータスコードを変えられないため、最初の取得の失敗はここで返す
+	first, err := exporter.next(ctx)
+	if err != nil {
+		slog.Warn("エクスポートするTodoの取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("エクスポートするTodoの取得に失敗", err)
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	write := writeTodosCSV
+	if input.Format == "json" {
+		contentType = "application/json"
+		write = writeTodosJSON
+	}
+	filename := "todos-" + h.clock.Now().UTC().Format("20060102") + "." + input.Format
+
+	return &huma.StreamResponse{
+		Body: func(hctx huma.Context) {
+			hctx.SetHeader("Content-Type", contentType)
+			hctx.SetHeader("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+			hctx.SetHeader("Cache-Control", "no-store")
+
+			count, err := write(ctx, hctx.BodyWriter(), exporter, first)
+			if err != nil {
+				// ステータスコードは送信済みのため、途中で打ち切ったことはログにのみ残す
+				slog.Warn("Todoのエクスポートを途中で打ち切り", "format", input.Format, "count", count, "err", err)
+				return
+			}
+			slog.Info("Todoをエクスポート", "format", input.Format, "count", count)
+		},
+	}, nil
+}
+
+// flush はクライアントに書き込み済みの分を送る
+func flush(w io.Writer) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// writeTodosCSV はTodoをヘッダー行付きのCSVで書き込み、書き込んだ件数を返す
+func writeTodosCSV(ctx context.Context, w io.Writer, e *todoExporter, todos []db.Todo) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(todoCSVHeader); err != nil {
+		return 0, err
+	}
+	count := 0
+	for len(todos) > 0 {
+		for _, t := range todos {
+			r := toTodoResponse(ctx, t)
+			var metadata []byte
+			if r.Metadata != nil {
+				var err error
+				if metadata, err = json.Marshal(r.Metadata); err != nil {
+					return count, err
+				}
+			}
+			if err := cw.Write([]string{
+				strconv.FormatInt(r.ID, 10),
+				csvText(r.Title),
+				csvText(derefString(r.Description)),
+				strconv.FormatBool(r.Completed),
+				strconv.FormatInt(r.Priority, 10),
+				formatInt64Ptr(r.ProjectID),
+				derefString(r.DueAt),
+				csvText(derefString(r.Assignee)),
+				derefString(r.Recurrence),
+				csvText(derefString(r.PlaceName)),
+				formatFloat64Ptr(r.Latitude),
+				formatFloat64Ptr(r.Longitude),
+				string(metadata),
+				formatInt64Ptr(r.OwnerID),
+				r.CreatedAt,
+				r.UpdatedAt,
+				derefString(r.ArchivedAt),
+			}); err != nil {
+				return count, err
+			}
+			count++
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return count, err
+		}
+		flush(w)
+
+		var err error
+		if todos, err = e.next(ctx); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// writeTodosJSON はTodoをAPIのレスポンスと同じ形のオブジェクトの配列で書き込み、書き込んだ件数を返す
+func writeTodosJSON(ctx context.Context, w io.Writer, e *todoExporter, todos []db.Todo) (int, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return 0, err
+	}
+	count := 0
+	for len(todos) > 0 {
+		for _, t := range todos {
+			b, err := json.Marshal(toTodoResponse(ctx, t))
+			if err != nil {
+				return count, err
+			}
+			if count > 0 {
+				if _, err := io.WriteString(w, ",\n"); err != nil {
+					return count, err
+				}
+			}
+			if _, err := w.Write(b); err != nil {
+				return count, err
+			}
+			count++
+		}
+		flush(w)
+
+		var err error
+		if todos, err = e.next(ctx); err != nil {
+			return count, err
+		}
+	}
+	_, err := io.WriteString(w, "]\n")
+	return count, err
+}
+
+// derefString は文字列のポインターの値を返す。nilの場合は空文字を返す
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// formatInt64Ptr は整数のポインターを文字列にする。nilの場合は空文字を返す
+func formatInt64Ptr(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+// formatFloat64Ptr は小数のポインターを文字列にする。nilの場合は空文字を返す
+func formatFloat64Ptr(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+// maxImportSize はインポートするファイルの最大バイト数
+const maxImportSize = 10 << 20
+
+// importBatchSize はインポートで1つのトランザクションで作成するTodoの件数
+const importBatchSize = 500
+
+// importRowRegistry はインポートする行の検証に用いるレジストリ
+var importRowRegistry = huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+
+// importRowSchema はインポートする行を作成の操作と同じ制約で検証するスキーマ
+var importRowSchema = importRowRegistry.Schema(reflect.TypeFor[model.ImportTodoRow](), false, "")
+
+// importRowLocation は行の検証のエラーの位置の接頭辞。JSON Pointerに変換した後に取り除き、行の中の位置にする
+const importRowLocation = "row"
+
+// importRow は読み込んだ行と、その番号を表す構造体
+type importRow struct {
+	index  int
+	values map[string]any
+}
+
+// importFormat はファイルの形式を返す。指定がない場合はファイル名の拡張子とContent-Typeで判断する
+func importFormat(format string, file huma.FormFile) (string, error) {
+	if format != "" {
+		return format, nil
+	}
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		return "csv", nil
+	case ".json":
+		return "json", nil
+	}
+	if mediaType, _, err := mime.ParseMediaType(file.ContentType); err == nil {
+		switch mediaType {
+		case "text/csv":
+			return "csv", nil
+		case "application/json":
+			return "json", nil
+		}
+	}
+	return "", huma.Error422UnprocessableEntity("ファイルの形式を判断できません。formatにcsvかjsonを指定してください", &huma.ErrorDetail{Location: "query.format"})
+}
+
+// importErrorFrom はTodoの作成で返された入力の誤りを行のエラーにする。サーバーの障害の場合はfalseを返す
+func importErrorFrom(index int, err error) (model.ImportTodoRowError, bool) {
+	var em *model.ErrorModel
+	if !errors.As(err, &em) || em.Status >= http.StatusInternalServerError {
+		return model.ImportTodoRowError{}, false
+	}
+	// 作成の操作と同じ検証のエラーはリクエストボディを指すため、行の中の位置にする
+	rowErr := model.ImportTodoRowError{Row: index, Message: em.Detail}
+	for _, d := range em.Errors {
+		rowErr.Errors = append(rowErr.Errors, &model.ErrorDetail{Message: d.Message, Location: strings.TrimPrefix(d.Location, "/body"), Value: d.Value})
+	}
+	return rowErr, true
+}
+
+// decodeImportRow は行の値を作成の操作と同じ制約で検証し、インポートするTodoにする
+func decodeImportRow(r importRow) (model.ImportTodoRow, *model.ImportTodoRowError) {
+	res := &huma.ValidateResult{}
+	huma.Validate(importRowRegistry, importRowSchema, huma.NewPathBuffer([]byte(importRowLocation), len(importRowLocation)), huma.ModeWriteToServer, r.values, res)
+	if len(res.Errors) == 0 {
+		var row model.ImportTodoRow
+		b, err := json.Marshal(r.values)
+		if err == nil {
+			err = json.Unmarshal(b, &row)
+		}
+		if err != nil {
+			return model.ImportTodoRow{}, &model.ImportTodoRowError{Row: r.index, Message: "行を読み込めません: " + err.Error()}
+		}
+		res.Errors = append(res.Errors, row.Location.Resolve(nil, huma.NewPathBuffer([]byte(importRowLocation), len(importRowLocation)))...)
+		res.Errors = append(res.Errors, row.Schedule.Resolve(nil, huma.NewPathBuffer([]byte(importRowLocation), len(importRowLocation)))...)
+		if len(res.Errors) == 0 {
+			return row, nil
+		}
+	}
+	rowErr := &model.ImportTodoRowError{Row: r.index, Message: "入力の検証に失敗しました"}
+	for _, e := range res.Errors {
+		d, ok := e.(huma.ErrorDetailer)
+		if !ok {
+			rowErr.Errors = append(rowErr.Errors, &model.ErrorDetail{Message: e.Error()})
+			continue
+		}
+		detail := d.ErrorDetail()
+		rowErr.Errors = append(rowErr.Errors, &model.ErrorDetail{
+			Message:  detail.Message,
+			Location: strings.TrimPrefix(model.JSONPointer(detail.Location, detail.Message), "/"+importRowLocation),
+			Value:    detail.Value,
+		})
+	}
+	return model.ImportTodoRow{}, rowErr
+}
+
+// csvImportValue はCSVのセルを、列の型の値にする。エクスポートで数式を防ぐために付けた先頭の'は取り除く
+func csvImportValue(prop *huma.Schema, cell string) (any, error) {
+	switch prop.Type {
+	case huma.TypeInteger:
+		return strconv.ParseInt(cell, 10, 64)
+	case huma.TypeNumber:
+		return strconv.ParseFloat(cell, 64)
+	case huma.TypeBoolean:
+		return strconv.ParseBool(cell)
+	case huma.TypeObject:
+		var v any
+		err := json.Unmarshal([]byte(cell), &v)
+		return v, err
+	default:
+		if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(cell[1])) {
+			return cell[1:], nil
+		}
+		return cell, nil
+	}
+}
+
+// readImportCSV はヘッダー行付きのCSVを1行ずつ読み込み、rowに渡す。インポートしない列は無視する。
+// 読み込めない行は行のエラーとしてfailに渡し、CSVの構文の誤りで続きを読めない場合はそこで終える
+func readImportCSV(r io.Reader, row func(importRow) error, fail func(model.ImportTodoRowError)) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return huma.Error422UnprocessableEntity("CSVのヘッダー行を読み込めません", err)
+	}
+	// 表計算ソフトで保存したCSVの先頭のBOMは列名に含めない
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	if !slices.Contains(header, "title") {
+		return huma.Error422UnprocessableEntity("CSVにtitleの列がありません", &huma.ErrorDetail{Location: "body.file", Value: header})
+	}
+
+	for index := 1; ; index++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			fail(model.ImportTodoRowError{Row: index, Message: "CSVを解析できません: " + err.Error()})
+			if errors.Is(err, csv.ErrFieldCount) {
+				continue
+			}
+			return nil
+		}
+
+		values := make(map[string]any)
+		var details []*model.ErrorDetail
+		for i, cell := range record {
+			prop, ok := importRowSchema.Properties[header[i]]
+			if !ok || cell == "" {
+				continue
+			}
+			v, err := csvImportValue(prop, cell)
+			if err != nil {
+				details = append(details, &model.ErrorDetail{Location: "/" + header[i], Message: "値を読み込めません", Value: cell})
+				continue
+			}
+			values[header[i]] = v
+		}
+		if len(details) > 0 {
+			fail(model.ImportTodoRowError{Row: index, Message: "入力の検証に失敗しました", Errors: details})
+			continue
+		}
+		if err := row(importRow{index: index, values: values}); err != nil {
+			return err
+		}
+	}
+}
+
+// readImportJSON はTodoの配列のJSONを1件ずつ読み込み、rowに渡す。インポートしないフィールドは無視する。
+// オブジェクトでない要素は行のエラーとしてfailに渡し、JSONの構文の誤りで続きを読めない場合はそこで終える
+func readImportJSON(r io.Reader, row func(importRow) error, fail func(model.ImportTodoRowError)) error {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return huma.Error422UnprocessableEntity("JSONはTodoの配列にしてください", &huma.ErrorDetail{Location: "body.file"})
+	}
+
+	for index := 1; dec.More(); index++ {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			fail(model.ImportTodoRowError{Row: index, Message: "JSONを解析できません: " + err.Error()})
+			return nil
+		}
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail(model.ImportTodoRowError{Row: index, Message: "要素はTodoのオブジェクトにしてください"})
+			continue
+		}
+		values := make(map[string]any, len(obj))
+		for name, value := range obj {
+			if _, ok := importRowSchema.Properties[name]; ok && value != nil {
+				values[name] = value
+			}
+		}
+		if err := row(importRow{index: index, values: values}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportTodos はアップロードされたCSVまたはJSONのファイルからTodoを作成する。
+// 行ごとに作成の操作と同じ検証を行い、インポートできない行は飛ばして行のエラーとして返す。
+// importBatchSize件ごとに別のトランザクションで作成するため、ファイルの大きさによらずトランザクションは短く、
+// サーバーの障害で失敗した場合もそれまでのトランザクションで作成したTodoは残る。
+func (h *TodoHandler) ImportTodos(ctx context.Context, input *model.ImportTodosInput) (*model.ImportTodosOutput, error) {
+	file := input.RawBody.Data().File
+	defer file.Close()
+
+	if file.Size > maxImportSize {
+		return nil, huma.NewError(http.StatusRequestEntityTooLarge, fmt.Sprintf("ファイルサイズが上限を超えています: %d > %d バイト", file.Size, maxImportSize))
+	}
+	format, err := importFormat(input.Format, file)
+	if err != nil {
+		return nil, err
+	}
+
+	result := model.ImportTodosResult{DryRun: input.DryRun, TodoIDs: []int64{}, Errors: []model.ImportTodoRowError{}}
+	fail := func(e model.ImportTodoRowError) {
+		result.Rows++
+		result.Failed++
+		result.Errors = append(result.Errors, e)
+	}
+
+	var batch []importRow
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		var created []db.Todo
+		var rowErrors []model.ImportTodoRowError
+		err := runInTx(ctx, h.db, h.queries, input.DryRun, func(qtx *db.Queries) error {
+			for _, r := range batch {
+				row, rowErr := decodeImportRow(r)
+				if rowErr != nil {
+					rowErrors = append(rowErrors, *rowErr)
+					continue
+				}
+				todo, err := h.importTodo(ctx, qtx, row)
+				if err != nil {
+					e, ok := importErrorFrom(r.index, err)
+					if !ok {
+						return err
+					}
+					rowErrors = append(rowErrors, e)
+					continue
+				}
+				created = append(created, todo)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Rows += len(batch)
+		result.Created += len(created)
+		result.Failed += len(rowErrors)
+		result.Errors = append(result.Errors, rowErrors...)
+		batch = batch[:0]
+		if input.DryRun {
+			return nil
+		}
+		for _, t := range created {
+			result.TodoIDs = append(result.TodoIDs, t.ID)
+			h.bus.Publish(event.Event{Type: event.TodoCreated, TodoID: t.ID, Assigned: t.Assignee.Valid})
+		}
+		return nil
+	}
+	row := func(r importRow) error {
+		batch = append(batch, r)
+		if len(batch) < importBatchSize {
+			return nil
+		}
+		return flush()
+	}
+
+	read := readImportCSV
+	if format == "json" {
+		read = readImportJSON
+	}
+	if err := read(file, row, fail); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(result.Errors, func(a, b model.ImportTodoRowError) int { return a.Row - b.Row })
+
+	slog.Info("Todoをインポート", "format", format, "dry_run", input.DryRun, "rows", result.Rows, "created", result.Created, "failed", result.Failed)
+	return &model.ImportTodosOutput{Body: result}, nil
+}
+
+// importTodo はインポートする1件のTodoを作成する。入力の誤りは4xxのエラーで返し、呼び出し元が行のエラーにする
+func (h *TodoHandler) importTodo(ctx context.Context, qtx *db.Queries, row model.ImportTodoRow) (db.Todo, error) {
+	if err := ensureProjectAssignable(ctx, qtx, row.ProjectID); err != nil {
+		return db.Todo{}, err
+	}
+	projectID := ptrInt64ToNullInt64(row.ProjectID)
+	metadata, err := encodeMetadata(row.Metadata)
+	if err != nil {
+		return db.Todo{}, err
+	}
+	if err := validateTodoMetadata(ctx, qtx, projectID, metadata); err != nil {
+		return db.Todo{}, err
+	}
+	if !row.Completed {
+		if err := checkWIPLimit(ctx, qtx, projectID); err != nil {
+			return db.Todo{}, err
+		}
+	}
+
+	todo, err := qtx.CreateTodo(ctx, db.CreateTodoParams{
+		Title:       row.Title,
+		Description: ptrStringToNullString(row.Description),
+		Completed:   boolToInt64(row.Completed),
+		Latitude:    ptrFloat64ToNullFloat64(row.Latitude),
+		Longitude:   ptrFloat64ToNullFloat64(row.Longitude),
+		PlaceName:   ptrStringToNullString(row.PlaceName),
+		ProjectID:   projectID,
+		DueAt:       ptrTimeToNullTime(row.DueAt),
+		Recurrence:  ptrStringToNullString(row.Recurrence),
+		Assignee:    ptrStringToNullString(row.Assignee),
+		OwnerID:     ownerID(ctx),
+		Metadata:    metadata,
+		Priority:    row.Priority,
+	})
+	if err != nil {
+		slog.Warn("Todo作成に失敗", "err", err)
+		return db.Todo{}, huma.Error500InternalServerError("Todo作成に失敗", err)
+	}
+	if err := recordActivity(ctx, qtx, activity.ActionCreate, nil, &todo); err != nil {
+		return db.Todo{}, err
+	}
+	if err := recordEvent(ctx, qtx, event.Event{Type: event.TodoCreated, TodoID: todo.ID}); err != nil {
+		return db.Todo{}, err
+	}
+	return todo, nil
+}
@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/locale"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/sse"
+)
+
+// FilterHandler は保存済みフィルターとそのフィードに関する操作を処理するハンドラー
+type FilterHandler struct {
+	queries *db.Queries
+	bus     *event.Bus
+}
+
+// NewFilterHandler はFilterHandlerの新しいインスタンスを生成する
+func NewFilterHandler(queries *db.Queries, bus *event.Bus) *FilterHandler {
+	return &FilterHandler{
+		queries: queries,
+		bus:     bus,
+	}
+}
+
+// toSavedFilterResponse はdb.SavedFilterをmodel.SavedFilterResponseに変換する
+func toSavedFilterResponse(f db.SavedFilter) model.SavedFilterResponse {
+	return model.SavedFilterResponse{
+		ID:        f.ID,
+		Name:      f.Name,
+		Completed: f.Completed == 1,
+		Near:      nullStringToPtr(f.Near),
+		Radius:    f.Radius,
+		CreatedAt: f.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// toTodoFilter はdb.SavedFilterをTodoの絞り込み条件に変換する
+func toTodoFilter(f db.SavedFilter) todoFilter {
+	return todoFilter{
+		completed: f.Completed == 1,
+		near:      nullStringToString(f.Near),
+		radius:    f.Radius,
+	}
+}
+
+// getSavedFilter は指定されたIDの保存済みフィルターを取得する
+func (h *FilterHandler) getSavedFilter(ctx context.Context, id int64) (db.SavedFilter, error) {
+	f, err := h.queries.GetSavedFilter(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("フィルターIDが見つかりません", "id", id, "err", err)
+			return db.SavedFilter{}, huma.Error404NotFound(fmt.Sprintf("フィルターIDが見つかりません: %d", id))
+		}
+		slog.Warn("フィルターの取得に失敗", "err", err)
+		return db.SavedFilter{}, huma.Error500InternalServerError("フィルターの取得に失敗", err)
+	}
+	return f, nil
+}
+
+// ListSavedFilters は保存済みフィルターの一覧を取得する
+func (h *FilterHandler) ListSavedFilters(ctx context.Context, _ *struct{}) (*model.ListSavedFiltersOutput, error) {
+	filters, err := h.queries.ListSavedFilters(ctx)
+	if err != nil {
+		slog.Warn("フィルター一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("フィルター一覧の取得に失敗", err)
+	}
+
+	output := &model.ListSavedFiltersOutput{}
+	output.Body.Filters = make([]model.SavedFilterResponse, len(filters))
+	for i, f := range filters {
+		output.Body.Filters[i] = toSavedFilterResponse(f)
+	}
+
+	return output, nil
+}
+
+// CreateSavedFilter は新しいフィルターを保存する
+func (h *FilterHandler) CreateSavedFilter(ctx context.Context, input *model.CreateSavedFilterInput) (*model.CreateSavedFilterOutput, error) {
+	if input.Body.Near != nil {
+		if _, _, err := parseNear(*input.Body.Near); err != nil {
+			return nil, huma.Error422UnprocessableEntity(err.Error(), &huma.ErrorDetail{
+				Location: "body.near",
+				Value:    *input.Body.Near,
+			})
+		}
+	}
+
+	var completed int64
+	if input.Body.Completed {
+		completed = 1
+	}
+
+	f, err := h.queries.CreateSavedFilter(ctx, db.CreateSavedFilterParams{
+		Name:      input.Body.Name,
+		Completed: completed,
+		Near:      ptrStringToNullString(input.Body.Near),
+		Radius:    input.Body.Radius,
+	})
+	if err != nil {
+		slog.Warn("フィルターの保存に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("フィルターの保存に失敗", err)
+	}
+
+	return &model.CreateSavedFilterOutput{Body: toSavedFilterResponse(f)}, nil
+}
+
+// DeleteSavedFilter は指定されたIDの保存済みフィルターを削除する
+func (h *FilterHandler) DeleteSavedFilter(ctx context.Context, input *model.DeleteSavedFilterInput) (*model.DeleteSavedFilterOutput, error) {
+	n, err := h.queries.DeleteSavedFilter(ctx, input.ID)
+	if err != nil {
+		slog.Warn("フィルターの削除に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("フィルターの削除に失敗", err)
+	}
+	if n == 0 {
+		return nil, huma.Error404NotFound(fmt.Sprintf("フィルターIDが見つかりません: %d", input.ID))
+	}
+
+	output := &model.DeleteSavedFilterOutput{}
+	output.Body.Message = "Filter deleted successfully"
+	return output, nil
+}
+
+// FilterStreamEvents はSSEで配信するイベント名とデータ型の対応
+var FilterStreamEvents = map[string]any{
+	"enter": model.FilterEnterEvent{},
+	"leave": model.FilterLeaveEvent{},
+}
+
+// StreamFilter は保存済みフィルターの結果に出入りしたTodoをSSEで配信する。
+// 接続直後に現在の結果をenterとして送信し、以降は変更イベントごとに差分を送信する。
+func (h *FilterHandler) StreamFilter(ctx context.Context, input *model.FilterStreamInput, send sse.Sender) {
+	f, err := h.getSavedFilter(ctx, input.ID)
+	if err != nil {
+		return
+	}
+	filter := toTodoFilter(f)
+
+	// 初期状態の取得前に購読しておき、その間の変更を取りこぼさないようにする
+	events, unsubscribe := h.bus.Subscribe(64)
+	defer unsubscribe()
+
+	members := map[int64]struct{}{}
+	todos, err := findTodos(ctx, h.queries, filter)
+	if err != nil {
+		slog.Warn("フィルター結果の取得に失敗", "filter_id", f.ID, "err", err)
+		return
+	}
+	for _, t := range todos {
+		members[t.ID] = struct{}{}
+		if err := send.Data(model.FilterEnterEvent{Todo: toTodoResponse(ctx, t)}); err != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+			todos, err := findTodos(ctx, h.queries, filter)
+			if err != nil {
+				slog.Warn("フィルター結果の取得に失敗", "filter_id", f.ID, "err", err)
+				return
+			}
+
+			current := make(map[int64]struct{}, len(todos))
+			for _, t := range todos {
+				current[t.ID] = struct{}{}
+				if _, ok := members[t.ID]; ok {
+					continue
+				}
+				if err := send.Data(model.FilterEnterEvent{Todo: toTodoResponse(ctx, t)}); err != nil {
+					return
+				}
+			}
+			for id := range members {
+				if _, ok := current[id]; ok {
+					continue
+				}
+				if err := send.Data(model.FilterLeaveEvent{ID: id}); err != nil {
+					return
+				}
+			}
+			members = current
+		}
+	}
+}
+
+// atomFeed はAtomフィードのルート要素
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomEntry はAtomフィードの1エントリー
+type atomEntry struct {
+	ID      string `xml:"id"`
+	Title   string `xml:"title"`
+	Updated string `xml:"updated"`
+	Summary string `xml:"summary,omitempty"`
+}
+
+// FilterFeed は保存済みフィルターの現在の結果をAtomフィードとして返す。
+// 結果に入ったTodoは新しいエントリーとして、外れたTodoはエントリーの消失として現れる。
+func (h *FilterHandler) FilterFeed(ctx context.Context, input *model.FilterFeedInput) (*model.FilterFeedOutput, error) {
+	f, err := h.getSavedFilter(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := findTodos(ctx, h.queries, toTodoFilter(f))
+	if err != nil {
+		slog.Warn("フィルター結果の取得に失敗", "filter_id", f.ID, "err", err)
+		return nil, huma.Error500InternalServerError("フィルター結果の取得に失敗", err)
+	}
+
+	formatter := locale.FormatterFrom(ctx)
+	feed := atomFeed{
+		ID:      fmt.Sprintf("urn:todo-api:filters:%d", f.ID),
+		Title:   f.Name,
+		Updated: f.CreatedAt.Format(time.RFC3339),
+		Entries: make([]atomEntry, len(todos)),
+	}
+	for i, t := range todos {
+		updated := t.UpdatedAt.Format(time.RFC3339)
+		if updated > feed.Updated {
+			feed.Updated = updated
+		}
+		feed.Entries[i] = atomEntry{
+			ID:      fmt.Sprintf("urn:todo-api:todos:%d", t.ID),
+			Title:   t.Title,
+			Updated: updated,
+			Summary: feedSummary(formatter, t),
+		}
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		slog.Warn("Atomフィードの生成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("Atomフィードの生成に失敗", err)
+	}
+
+	return &model.FilterFeedOutput{
+		ContentType:     "application/atom+xml",
+		ContentLanguage: formatter.Language(),
+		Body:            append([]byte(xml.Header), body...),
+	}, nil
+}
+
+// feedSummary はTodoの説明と、期限がある場合は利用者の言語とタイムゾーンで書式化した期限をフィードの要約にする
+func feedSummary(f *locale.Formatter, t db.Todo) string {
+	summary := nullStringToString(t.Description)
+	if !t.DueAt.Valid {
+		return summary
+	}
+	due := fmt.Sprintf("%s: %s (%s)", f.Label("期限", "Due"), f.DateTime(t.DueAt.Time), f.Relative(t.DueAt.Time))
+	if summary == "" {
+		return due
+	}
+	return summary + "\n" + due
+}
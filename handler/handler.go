@@ -6,26 +6,42 @@ package handler
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
 	"go-huma-test/db"
+	"go-huma-test/event"
 	"go-huma-test/model"
+	"go-huma-test/storage"
 	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/conditional"
 )
 
 // TodoHandler はTodoに関する操作を処理するハンドラー
 type TodoHandler struct {
 	queries *db.Queries
 	db      *sql.DB
+	bus     *event.Bus
+	store   storage.BlobStore
+	clock   clock.Clock
 }
 
-// NewTodoHandler はTodoHandlerの新しいインスタンスを生成する
-func NewTodoHandler(queries *db.Queries, db *sql.DB) *TodoHandler {
+// NewTodoHandler はTodoHandlerの新しいインスタンスを生成する。既定の期限やエクスポートのファイル名の日付はclockの現在時刻で決める
+func NewTodoHandler(queries *db.Queries, db *sql.DB, bus *event.Bus, store storage.BlobStore, clock clock.Clock) *TodoHandler {
 	return &TodoHandler{
 		queries: queries,
 		db:      db,
+		bus:     bus,
+		store:   store,
+		clock:   clock,
 	}
 }
 
@@ -48,32 +64,210 @@ func nullStringToString(s sql.NullString) string {
 	return ""
 }
 
-// toTodoResponse はdb.Todoをmodel.TodoResponseに変換する
-func toTodoResponse(t db.Todo) model.TodoResponse {
+// ptrFloat64ToNullFloat64 は*float64をsql.NullFloat64に変換する
+func ptrFloat64ToNullFloat64(f *float64) sql.NullFloat64 {
+	if f == nil {
+		return sql.NullFloat64{Valid: false}
+	}
+	return sql.NullFloat64{
+		Float64: *f,
+		Valid:   true,
+	}
+}
+
+// nullFloat64ToPtr はsql.NullFloat64を*float64に変換する
+func nullFloat64ToPtr(f sql.NullFloat64) *float64 {
+	if f.Valid {
+		return &f.Float64
+	}
+	return nil
+}
+
+// ownerID は認証したユーザーのIDをTodoの所有者として返す。
+// ユーザーとして認証されていない場合は、所有者のいない共有のTodoを対象にするためNULLを返す。
+func ownerID(ctx context.Context) sql.NullInt64 {
+	id, ok := auth.UserIDFrom(ctx)
+	return sql.NullInt64{Int64: id, Valid: ok}
+}
+
+// ptrInt64ToNullInt64 は*int64をsql.NullInt64に変換する
+func ptrInt64ToNullInt64(i *int64) sql.NullInt64 {
+	if i == nil {
+		return sql.NullInt64{Valid: false}
+	}
+	return sql.NullInt64{
+		Int64: *i,
+		Valid: true,
+	}
+}
+
+// ptrTimeToNullTime は*time.TimeをUTCのsql.NullTimeに変換する
+func ptrTimeToNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{Valid: false}
+	}
+	return sql.NullTime{
+		Time:  t.UTC(),
+		Valid: true,
+	}
+}
+
+// nullInt64ToPtr はsql.NullInt64を*int64に変換する
+func nullInt64ToPtr(i sql.NullInt64) *int64 {
+	if i.Valid {
+		return &i.Int64
+	}
+	return nil
+}
+
+// nullStringToPtr はsql.NullStringを*stringに変換する
+func nullStringToPtr(s sql.NullString) *string {
+	if s.Valid {
+		return &s.String
+	}
+	return nil
+}
+
+// toTodoResponse はdb.Todoをmodel.TodoResponseに変換する。Accept-Languageヘッダーに合う訳があればタイトルと説明を訳にする。
+func toTodoResponse(ctx context.Context, t db.Todo) model.TodoResponse {
 	description := nullStringToString(t.Description)
 
-	return model.TodoResponse{
+	resp := model.TodoResponse{
 		ID:          t.ID,
 		Title:       t.Title,
 		Description: &description,
 		Completed:   t.Completed == 1,
-		CreatedAt:   t.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   t.UpdatedAt.Format(time.RFC3339),
+		Location: model.Location{
+			Latitude:  nullFloat64ToPtr(t.Latitude),
+			Longitude: nullFloat64ToPtr(t.Longitude),
+			PlaceName: nullStringToPtr(t.PlaceName),
+		},
+		SubtaskCount:          t.SubtaskCount,
+		SubtaskCompletedCount: t.SubtaskCompletedCount,
+		ProjectID:             nullInt64ToPtr(t.ProjectID),
+		DueAt:                 nullTimeToPtr(t.DueAt),
+		Recurrence:            nullStringToPtr(t.Recurrence),
+		NextTodoID:            nullInt64ToPtr(t.NextTodoID),
+		Position:              t.Position,
+		Version:               t.Version,
+		Assignee:              nullStringToPtr(t.Assignee),
+		OwnerID:               nullInt64ToPtr(t.OwnerID),
+		Metadata:              decodeJSONObject(t.Metadata),
+		Priority:              t.Priority,
+		CreatedAt:             t.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:             t.UpdatedAt.Format(time.RFC3339),
+		ArchivedAt:            nullTimeToPtr(t.ArchivedAt),
 	}
+	localizeTodo(ctx, &resp, t)
+	return resp
 }
 
-// ListTodos はTodoのリストを取得する
-func (h *TodoHandler) ListTodos(ctx context.Context, input *model.ListTodosInput) (*model.ListTodosOutput, error) {
-	var todos []db.Todo
-	var err error
+// todoETag はTodoのバージョンからETagヘッダーの値を生成する
+func todoETag(t db.Todo) string {
+	return strconv.Quote(strconv.FormatInt(t.Version, 10))
+}
 
-	if input.Completed {
-		todos, err = h.queries.ListTodosByStatus(ctx, 1)
-	} else {
-		todos, err = h.queries.ListTodos(ctx)
+// checkTodoPrecondition はIf-Matchなどの条件付きヘッダーをTodoの現在のバージョンと照合する。
+// requireIfMatchがtrueの場合、If-Matchの指定がなければ428を返す。
+func checkTodoPrecondition(p *conditional.Params, t db.Todo, requireIfMatch bool) error {
+	if requireIfMatch && len(p.IfMatch) == 0 {
+		return huma.NewError(http.StatusPreconditionRequired, "If-Matchヘッダーが必要です。Todo取得時のETagを指定してください")
+	}
+	if !p.HasConditionalParams() {
+		return nil
 	}
+	return p.PreconditionFailed(strconv.FormatInt(t.Version, 10), t.UpdatedAt)
+}
+
+// parseNear は"緯度,経度"形式の文字列を解析する
+func parseNear(near string) (lat, lng float64, err error) {
+	latStr, lngStr, ok := strings.Cut(near, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("near は\"緯度,経度\"の形式で指定してください: %s", near)
+	}
+	if lat, err = strconv.ParseFloat(latStr, 64); err != nil {
+		return 0, 0, fmt.Errorf("緯度の解析に失敗: %w", err)
+	}
+	if lng, err = strconv.ParseFloat(lngStr, 64); err != nil {
+		return 0, 0, fmt.Errorf("経度の解析に失敗: %w", err)
+	}
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return 0, 0, fmt.Errorf("緯度経度が範囲外です: %s", near)
+	}
+	return lat, lng, nil
+}
+
+// todoFilter はTodo一覧の絞り込み条件を表す構造体
+type todoFilter struct {
+	completed            bool
+	near                 string
+	radius               float64
+	includeArchivedLists bool
+	metadata             string
+}
+
+// findTodos は絞り込み条件に一致するTodoを取得する
+// 認証したユーザーが所有するTodoと、共有されたTodoのみを対象とし、
+// アーカイブ済みのプロジェクトに属するTodoは、includeArchivedListsを指定した場合のみ含める。
+func findTodos(ctx context.Context, q *db.Queries, f todoFilter) ([]db.Todo, error) {
+	user := ownerID(ctx)
+	includeArchivedLists := boolToInt64(f.includeArchivedLists)
+	metadataPath, metadataValue := parseMetadataFilter(f.metadata)
+	switch {
+	case f.near != "":
+		lat, lng, err := parseNear(f.near)
+		if err != nil {
+			return nil, huma.Error422UnprocessableEntity(err.Error(), &huma.ErrorDetail{
+				Location: "query.near",
+				Value:    f.near,
+			})
+		}
+		var completed sql.NullInt64
+		if f.completed {
+			completed = sql.NullInt64{Int64: 1, Valid: true}
+		}
+		return q.ListTodosNear(ctx, db.ListTodosNearParams{
+			UserID:               user,
+			Completed:            completed,
+			Lat:                  lat,
+			Lng:                  lng,
+			Radius:               f.radius,
+			IncludeArchivedLists: includeArchivedLists,
+			MetadataPath:         metadataPath,
+			MetadataValue:        metadataValue,
+		})
+	case f.completed:
+		return q.ListTodosByStatus(ctx, db.ListTodosByStatusParams{
+			Completed:            1,
+			UserID:               user,
+			IncludeArchivedLists: includeArchivedLists,
+			MetadataPath:         metadataPath,
+			MetadataValue:        metadataValue,
+		})
+	default:
+		return q.ListTodos(ctx, db.ListTodosParams{
+			UserID:               user,
+			IncludeArchivedLists: includeArchivedLists,
+			MetadataPath:         metadataPath,
+			MetadataValue:        metadataValue,
+		})
+	}
+}
 
+// ListTodos はTodoのリストを取得する
+func (h *TodoHandler) ListTodos(ctx context.Context, input *model.ListTodosInput) (*model.ListTodosOutput, error) {
+	todos, err := findTodos(ctx, h.queries, todoFilter{
+		completed:            input.Completed,
+		near:                 input.Near,
+		radius:               input.Radius,
+		includeArchivedLists: input.IncludeArchivedLists,
+		metadata:             input.Metadata,
+	})
 	if err != nil {
+		var se huma.StatusError
+		if errors.As(err, &se) {
+			return nil, err
+		}
 		slog.Warn("todoリストの取得に失敗", "err", err)
 		return nil, huma.Error500InternalServerError("Todoリストの取得に失敗", err)
 	}
@@ -81,7 +275,10 @@ func (h *TodoHandler) ListTodos(ctx context.Context, input *model.ListTodosInput
 	output := &model.ListTodosOutput{}
 	output.Body.Todos = make([]model.TodoResponse, len(todos))
 	for i, t := range todos {
-		output.Body.Todos[i] = toTodoResponse(t)
+		output.Body.Todos[i] = toTodoResponse(ctx, t)
+	}
+	if err := expandTodos(ctx, h.queries, output.Body.Todos, input.Expand); err != nil {
+		return nil, err
 	}
 
 	return output, nil
@@ -89,7 +286,7 @@ func (h *TodoHandler) ListTodos(ctx context.Context, input *model.ListTodosInput
 
 // GetTodo は指定されたIDのTodoを取得する
 func (h *TodoHandler) GetTodo(ctx context.Context, input *model.GetTodoInput) (*model.GetTodoOutput, error) {
-	todo, err := h.queries.GetTodo(ctx, input.ID)
+	todo, err := h.queries.GetTodo(ctx, db.GetTodoParams{ID: input.ID, UserID: ownerID(ctx)})
 	if err != nil {
 		if err == sql.ErrNoRows {
 			slog.Warn("Todo IDが見つかりません", "id", input.ID, "err", err)
@@ -99,24 +296,105 @@ func (h *TodoHandler) GetTodo(ctx context.Context, input *model.GetTodoInput) (*
 		return nil, huma.Error500InternalServerError("Todo取得に失敗", err)
 	}
 
-	return &model.GetTodoOutput{Body: toTodoResponse(todo)}, nil
+	resp := []model.TodoResponse{toTodoResponse(ctx, todo)}
+	if err := expandTodos(ctx, h.queries, resp, input.Expand); err != nil {
+		return nil, err
+	}
+
+	return &model.GetTodoOutput{
+		ETag:         todoETag(todo),
+		LastModified: todo.UpdatedAt.UTC(),
+		Body:         resp[0],
+	}, nil
 }
 
 // CreateTodo は新しいTodoを作成する
 func (h *TodoHandler) CreateTodo(ctx context.Context, input *model.CreateTodoInput) (*model.CreateTodoOutput, error) {
 	description := ptrStringToNullString(input.Body.Description)
 
-	todo, err := h.queries.CreateTodo(ctx, db.CreateTodoParams{
-		Title:       input.Body.Title,
-		Description: description,
-		Completed:   0,
+	if err := ensureProjectAssignable(ctx, h.queries, input.Body.ProjectID); err != nil {
+		return nil, err
+	}
+	// クライアントが省略した項目はプロジェクトか全体の既定値にする
+	defaults, err := resolveTodoDefaults(ctx, h.queries, ptrInt64ToNullInt64(input.Body.ProjectID))
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := encodeMetadata(input.Body.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if input.Body.Metadata == nil {
+		metadata = defaults.Metadata
+	}
+	if err := validateTodoMetadata(ctx, h.queries, ptrInt64ToNullInt64(input.Body.ProjectID), metadata); err != nil {
+		return nil, err
+	}
+	translations, err := encodeTranslations(input.Body.Translations)
+	if err != nil {
+		return nil, err
+	}
+	tags := decodeDefaultTags(defaults.Tags)
+	if input.Body.Tags != nil {
+		if tags, err = normalizeTagNames(input.Body.Tags, "body.tags"); err != nil {
+			return nil, err
+		}
+	}
+	priority := defaults.Priority.Int64
+	if input.Body.Priority != nil {
+		priority = *input.Body.Priority
+	}
+	dueAt := ptrTimeToNullTime(input.Body.DueAt)
+	if input.Body.DueAt == nil && defaults.DueOffsetSeconds.Valid {
+		dueAt = sql.NullTime{Time: h.clock.Now().UTC().Add(time.Duration(defaults.DueOffsetSeconds.Int64) * time.Second).Truncate(time.Second), Valid: true}
+	}
+
+	var todo db.Todo
+	err = runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		if err := checkWIPLimit(ctx, qtx, ptrInt64ToNullInt64(input.Body.ProjectID)); err != nil {
+			return err
+		}
+		var err error
+		todo, err = qtx.CreateTodo(ctx, db.CreateTodoParams{
+			Title:        input.Body.Title,
+			Description:  description,
+			Completed:    0,
+			Latitude:     ptrFloat64ToNullFloat64(input.Body.Latitude),
+			Longitude:    ptrFloat64ToNullFloat64(input.Body.Longitude),
+			PlaceName:    ptrStringToNullString(input.Body.PlaceName),
+			ProjectID:    ptrInt64ToNullInt64(input.Body.ProjectID),
+			DueAt:        dueAt,
+			Recurrence:   ptrStringToNullString(input.Body.Recurrence),
+			Assignee:     ptrStringToNullString(input.Body.Assignee),
+			OwnerID:      ownerID(ctx),
+			Metadata:     metadata,
+			Priority:     priority,
+			Translations: translations,
+		})
+		if err != nil {
+			slog.Warn("Todo作成に失敗", "err", err)
+			return huma.Error500InternalServerError("Todo作成に失敗", err)
+		}
+		if err := addTodoTags(ctx, qtx, todo.ID, todo.OwnerID, tags); err != nil {
+			return err
+		}
+		if err := recordActivity(ctx, qtx, activity.ActionCreate, nil, &todo); err != nil {
+			return err
+		}
+		return recordEvent(ctx, qtx, event.Event{Type: event.TodoCreated, TodoID: todo.ID})
 	})
 	if err != nil {
-		slog.Warn("Todo作成に失敗", "err", err)
-		return nil, huma.Error500InternalServerError("Todo作成に失敗", err)
+		return nil, err
 	}
 
-	return &model.CreateTodoOutput{Body: toTodoResponse(todo)}, nil
+	h.bus.Publish(event.Event{Type: event.TodoCreated, TodoID: todo.ID, Assigned: todo.Assignee.Valid})
+
+	resp := toTodoResponse(ctx, todo)
+	resp.Tags = tags
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+	return &model.CreateTodoOutput{Body: resp}, nil
 }
 
 // UpdateTodo は指定されたIDのTodoを更新する
@@ -139,23 +417,87 @@ func (h *TodoHandler) UpdateTodo(ctx context.Context, input *model.UpdateTodoInp
 
 	description := ptrStringToNullString(input.Body.Description)
 
+	before, err := getUnlockedTodo(ctx, qtx, input.ID, h.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	// アーカイブ済みのプロジェクトに残っているTodoも編集できるよう、所属が変わる場合のみ確認する
+	if ptrInt64ToNullInt64(input.Body.ProjectID) != before.ProjectID {
+		if err := ensureProjectAssignable(ctx, qtx, input.Body.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkTodoPrecondition(&input.Params, before, true); err != nil {
+		return nil, err
+	}
+	// 未完了に戻す場合と、未完了のまま別のプロジェクトに移す場合は、移した先の未完了のTodoが増える
+	if completed == 0 && (before.Completed == 1 || ptrInt64ToNullInt64(input.Body.ProjectID) != before.ProjectID) {
+		if err := checkWIPLimit(ctx, qtx, ptrInt64ToNullInt64(input.Body.ProjectID)); err != nil {
+			return nil, err
+		}
+	}
+	metadata, err := encodeMetadata(input.Body.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTodoMetadata(ctx, qtx, ptrInt64ToNullInt64(input.Body.ProjectID), metadata); err != nil {
+		return nil, err
+	}
+	translations, err := encodeTranslations(input.Body.Translations)
+	if err != nil {
+		return nil, err
+	}
+
+	// 期限が変わった場合は、新しい期限から改めて優先度を引き上げる
+	dueAt := ptrTimeToNullTime(input.Body.DueAt)
+	escalationLevel := before.EscalationLevel
+	if dueAt != before.DueAt {
+		escalationLevel = 0
+	}
+
 	todo, err := qtx.UpdateTodo(ctx, db.UpdateTodoParams{
-		ID:          input.ID,
-		Title:       input.Body.Title,
-		Description: description,
-		Completed:   completed,
+		ID:              input.ID,
+		Title:           input.Body.Title,
+		Description:     description,
+		Completed:       completed,
+		Latitude:        ptrFloat64ToNullFloat64(input.Body.Latitude),
+		Longitude:       ptrFloat64ToNullFloat64(input.Body.Longitude),
+		PlaceName:       ptrStringToNullString(input.Body.PlaceName),
+		ProjectID:       ptrInt64ToNullInt64(input.Body.ProjectID),
+		DueAt:           dueAt,
+		Recurrence:      ptrStringToNullString(input.Body.Recurrence),
+		Assignee:        ptrStringToNullString(input.Body.Assignee),
+		Metadata:        metadata,
+		Priority:        input.Body.Priority,
+		EscalationLevel: escalationLevel,
+		Translations:    translations,
 	})
 	if err != nil {
 		slog.Warn("Todo更新に失敗", "err", err)
 		return nil, huma.Error500InternalServerError("Todo更新に失敗", err)
 	}
 
+	if err := recordActivity(ctx, qtx, activity.ActionUpdate, &before, &todo); err != nil {
+		return nil, err
+	}
+	e := event.Event{
+		Type:      event.TodoUpdated,
+		TodoID:    todo.ID,
+		Completed: before.Completed == 0 && todo.Completed == 1,
+		Assigned:  todo.Assignee.Valid && todo.Assignee != before.Assignee,
+	}
+	if err := recordEvent(ctx, qtx, e); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		slog.Warn("トランザクションのコミットに失敗", "err", err)
 		return nil, huma.Error500InternalServerError("トランザクションのコミットに失敗", err)
 	}
 
-	return &model.UpdateTodoOutput{Body: toTodoResponse(todo)}, nil
+	h.bus.Publish(e)
+
+	return &model.UpdateTodoOutput{ETag: todoETag(todo), Body: toTodoResponse(ctx, todo)}, nil
 }
 
 // DeleteTodo は指定されたIDのTodoを削除する
@@ -171,9 +513,33 @@ func (h *TodoHandler) DeleteTodo(ctx context.Context, input *model.DeleteTodoInp
 
 	qtx := h.queries.WithTx(tx)
 
-	if err := qtx.DeleteTodo(ctx, input.ID); err != nil {
-		slog.Warn("Todo削除に失敗", "err", err)
-		return nil, huma.Error500InternalServerError("Todo削除に失敗", err)
+	if len(input.IfMatch) == 0 {
+		return nil, huma.NewError(http.StatusPreconditionRequired, "If-Matchヘッダーが必要です。Todo取得時のETagを指定してください")
+	}
+
+	// 存在しないIDの削除は従来どおり成功として扱い、履歴は残さない
+	before, err := getUnlockedTodo(ctx, qtx, input.ID, h.clock.Now())
+	var se huma.StatusError
+	if errors.As(err, &se) && se.GetStatus() != http.StatusNotFound {
+		return nil, err
+	}
+	found := err == nil
+	if found {
+		if err := checkTodoPrecondition(&input.Params, before, false); err != nil {
+			return nil, err
+		}
+
+		if err := qtx.DeleteTodo(ctx, input.ID); err != nil {
+			slog.Warn("Todo削除に失敗", "err", err)
+			return nil, huma.Error500InternalServerError("Todo削除に失敗", err)
+		}
+
+		if err := recordActivity(ctx, qtx, activity.ActionDelete, &before, nil); err != nil {
+			return nil, err
+		}
+		if err := recordEvent(ctx, qtx, event.Event{Type: event.TodoDeleted, TodoID: input.ID, OwnerID: before.OwnerID}); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -181,6 +547,10 @@ func (h *TodoHandler) DeleteTodo(ctx context.Context, input *model.DeleteTodoInp
 		return nil, huma.Error500InternalServerError("トランザクションのコミットに失敗", err)
 	}
 
+	if found {
+		h.bus.Publish(event.Event{Type: event.TodoDeleted, TodoID: input.ID, OwnerID: before.OwnerID})
+	}
+
 	output := &model.DeleteTodoOutput{}
 	output.Body.Message = "Todo deleted successfully"
 	return output, nil
@@ -199,16 +569,40 @@ func (h *TodoHandler) ToggleTodo(ctx context.Context, input *model.ToggleTodoInp
 
 	qtx := h.queries.WithTx(tx)
 
+	before, err := getUnlockedTodo(ctx, qtx, input.ID, h.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	// トグルは現在の状態に依存しないため、If-Matchは指定された場合のみ照合する
+	if err := checkTodoPrecondition(&input.Params, before, false); err != nil {
+		return nil, err
+	}
+	if before.Completed == 1 {
+		if err := checkWIPLimit(ctx, qtx, before.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
 	todo, err := qtx.ToggleTodoCompleted(ctx, input.ID)
 	if err != nil {
 		slog.Warn("Todoのトグルに失敗", "err", err)
 		return nil, huma.Error500InternalServerError("Todoのトグルに失敗", err)
 	}
 
+	if err := recordActivity(ctx, qtx, activity.ActionToggle, &before, &todo); err != nil {
+		return nil, err
+	}
+	e := event.Event{Type: event.TodoUpdated, TodoID: todo.ID, Completed: before.Completed == 0 && todo.Completed == 1}
+	if err := recordEvent(ctx, qtx, e); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		slog.Warn("トランザクションのコミットに失敗", "err", err)
 		return nil, huma.Error500InternalServerError("トランザクションのコミットに失敗", err)
 	}
 
-	return &model.ToggleTodoOutput{Body: toTodoResponse(todo)}, nil
+	h.bus.Publish(e)
+
+	return &model.ToggleTodoOutput{ETag: todoETag(todo), Body: toTodoResponse(ctx, todo)}, nil
 }
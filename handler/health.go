@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/diskspace"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HealthStatus は稼働状態の確認のレスポンスを表す構造体
+type HealthStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+	// Disk は/readyzで返すデータベースのディスクの空き容量
+	Disk *diskspace.Status `json:"disk,omitempty"`
+}
+
+// 稼働状態の確認結果
+const (
+	healthOK      = "ok"
+	healthFailed  = "failed"
+	healthSkipped = "skipped"
+	healthLow     = "low"
+)
+
+// HealthHandler はロードバランサーやKubernetesのプローブ向けの稼働状態の確認を処理するハンドラー。
+// 認証せずに呼び出せるよう、HumaのAPIではなくServeMuxに直接登録する。
+type HealthHandler struct {
+	queries  *db.Queries
+	db       *sql.DB
+	readOnly bool
+	disk     *diskspace.Monitor
+	timeout  time.Duration
+}
+
+// NewHealthHandler はHealthHandlerの新しいインスタンスを生成する。
+// 準備状態の確認はtimeout以内に終わらない場合に失敗とし、readOnlyの場合は書き込みを確認しない。
+// diskはディスクの空き容量の監視で、監視しない場合はnilにする。
+func NewHealthHandler(queries *db.Queries, db *sql.DB, readOnly bool, disk *diskspace.Monitor, timeout time.Duration) *HealthHandler {
+	return &HealthHandler{
+		queries:  queries,
+		db:       db,
+		readOnly: readOnly,
+		disk:     disk,
+		timeout:  timeout,
+	}
+}
+
+// writeHealth は稼働状態をJSONで書き込む
+func writeHealth(w http.ResponseWriter, status int, body HealthStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Warn("稼働状態の書き込みに失敗", "err", err)
+	}
+}
+
+// Liveness はプロセスが応答できることを返す。データベースの状態は確認しないため、
+// データベースの一時的な障害でプロセスが再起動されることはない。
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, HealthStatus{Status: healthOK})
+}
+
+// Readiness はSQLiteに接続でき、WALモードのデータベースに書き込めることを確認し、ディスクの空き容量を返す。
+// いずれかの確認に失敗した場合は503を返し、ロードバランサーがリクエストを振り分けないようにする。
+// 接続は1本のため、長い処理が実行中の場合もtimeoutを過ぎると失敗する。
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	body := HealthStatus{Status: healthOK, Checks: map[string]string{}}
+	if err := h.db.PingContext(ctx); err != nil {
+		slog.Warn("データベースに接続できません", "err", err)
+		body.Checks["database"] = healthFailed
+		body.Checks["wal"] = healthSkipped
+		body.Status = healthFailed
+		writeHealth(w, http.StatusServiceUnavailable, body)
+		return
+	}
+	body.Checks["database"] = healthOK
+
+	// レプリカは読み取り専用で開くため、書き込みは確認しない
+	if h.readOnly {
+		body.Checks["wal"] = healthSkipped
+	} else if err := h.checkWAL(ctx); err != nil {
+		slog.Warn("データベースに書き込めません", "err", err)
+		body.Checks["wal"] = healthFailed
+		body.Status = healthFailed
+	} else {
+		body.Checks["wal"] = healthOK
+	}
+
+	// 空き容量が不足していても読み取りと削除は受け付けるため、準備状態は失敗にしない
+	if h.disk != nil {
+		if disk, ok := h.disk.Status(); !ok {
+			body.Checks["disk"] = healthSkipped
+		} else {
+			body.Disk = &disk
+			body.Checks["disk"] = healthOK
+			if disk.Low {
+				body.Checks["disk"] = healthLow
+			}
+		}
+	}
+
+	status := http.StatusOK
+	if body.Status != healthOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealth(w, status, body)
+}
+
+// checkWAL はジャーナルモードがWAL（インメモリのデータベースではmemory）であり、1行の書き込みをコミットできることを確認する
+func (h *HealthHandler) checkWAL(ctx context.Context) error {
+	var mode string
+	if err := h.db.QueryRowContext(ctx, "PRAGMA journal_mode;").Scan(&mode); err != nil {
+		return fmt.Errorf("ジャーナルモードの取得に失敗: %w", err)
+	}
+	// インメモリのデータベースはWALにできず、ジャーナルモードはmemoryになる
+	if !strings.EqualFold(mode, "wal") && !strings.EqualFold(mode, "memory") {
+		return fmt.Errorf("ジャーナルモードがWALではありません: %s", mode)
+	}
+	if err := h.queries.TouchHealthProbe(ctx); err != nil {
+		return fmt.Errorf("書き込みに失敗: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/db"
+	"go-huma-test/importer"
+	"go-huma-test/model"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ImportHandler は外部のタスク管理サービスからのインポートを処理するハンドラー。
+// インポート元のリストごとにプロジェクトのインポートと同じ処理でプロジェクトを作成する。
+type ImportHandler struct {
+	queries  *db.Queries
+	projects *ProjectHandler
+	google   *importer.GoogleTasks
+	running  sync.WaitGroup
+}
+
+// NewImportHandler はImportHandlerの新しいインスタンスを生成する
+func NewImportHandler(queries *db.Queries, projects *ProjectHandler, google *importer.GoogleTasks) *ImportHandler {
+	return &ImportHandler{
+		queries:  queries,
+		projects: projects,
+		google:   google,
+	}
+}
+
+// Fetch はインポート元からリストごとのバンドルを取得する。
+// iCalendarの解析は入力の誤りのため422を、Googleの認可の失敗は401ではなく422を返す。
+func (h *ImportHandler) Fetch(ctx context.Context, req model.ImportRequest) ([]model.ProjectBundle, error) {
+	switch req.Source {
+	case importer.SourceAppleReminders:
+		bundles, err := importer.ParseAppleReminders(strings.NewReader(req.ICalendar))
+		if err != nil {
+			return nil, huma.Error422UnprocessableEntity(err.Error(), &huma.ErrorDetail{Location: "body.icalendar"})
+		}
+		return bundles, nil
+	case importer.SourceGoogleTasks:
+		bundles, err := h.google.Fetch(ctx, importer.GoogleCredentials{
+			AccessToken:  req.AccessToken,
+			RefreshToken: req.RefreshToken,
+		})
+		if errors.Is(err, importer.ErrGoogleAuth) {
+			return nil, huma.Error422UnprocessableEntity(err.Error())
+		}
+		if err != nil {
+			slog.Warn("Google Tasksからの取得に失敗", "err", err)
+			return nil, huma.Error502BadGateway("Google Tasksからの取得に失敗", err)
+		}
+		return bundles, nil
+	default:
+		return nil, huma.Error422UnprocessableEntity("インポート元に対応していません: " + req.Source)
+	}
+}
+
+// ImportBundles はバンドルを順にプロジェクトとして作成する。
+// リストごとに別のトランザクションで作成するため、失敗した場合もそれまでに作成したプロジェクトを返す。
+func (h *ImportHandler) ImportBundles(ctx context.Context, bundles []model.ProjectBundle, dryRun bool) ([]model.ImportedProject, error) {
+	imported := make([]model.ImportedProject, 0, len(bundles))
+	for _, b := range bundles {
+		project, todoIDs, err := h.projects.ImportBundle(ctx, b, dryRun)
+		if err != nil {
+			return imported, fmt.Errorf("リスト%sのインポートに失敗: %w", b.Project.Name, err)
+		}
+		imported = append(imported, model.ImportedProject{
+			ProjectID: project.ID,
+			Name:      project.Name,
+			Todos:     len(todoIDs),
+		})
+	}
+	return imported, nil
+}
+
+// ImportFile はインポート元のサービスから書き出したファイルを解析し、リストごとにプロジェクトを作成する。
+// ファイルの解析はインポート元ごとのアダプターに任せ、解析できない場合は422を返す。
+// リストごとに別のトランザクションで作成するため、途中で失敗した場合もそれまでに作成したプロジェクトは残る。
+func (h *ImportHandler) ImportFile(ctx context.Context, input *model.ImportFileInput) (*model.ImportFileOutput, error) {
+	adapter, ok := importer.FileAdapterFor(input.Source)
+	if !ok {
+		return nil, huma.Error422UnprocessableEntity("ファイルからのインポートに対応していません: "+input.Source, &huma.ErrorDetail{Location: "path.source", Value: input.Source})
+	}
+	file := input.RawBody.Data().File
+	defer file.Close()
+	if file.Size > maxImportSize {
+		return nil, huma.NewError(http.StatusRequestEntityTooLarge, fmt.Sprintf("ファイルサイズが上限を超えています: %d > %d バイト", file.Size, maxImportSize))
+	}
+
+	bundles, err := adapter(io.LimitReader(file, maxImportSize))
+	if err != nil {
+		slog.Warn("インポートするファイルを解析できません", "source", input.Source, "err", err)
+		return nil, huma.Error422UnprocessableEntity(err.Error(), &huma.ErrorDetail{Location: "body.file"})
+	}
+
+	imported, err := h.ImportBundles(ctx, bundles, input.DryRun)
+	if err != nil {
+		slog.Warn("インポートに失敗", "source", input.Source, "projects", len(imported), "err", err)
+		status := http.StatusInternalServerError
+		var se huma.StatusError
+		if errors.As(err, &se) {
+			status = se.GetStatus()
+		}
+		return nil, huma.NewError(status, fmt.Sprintf("%s（%d件のリストはインポート済みです）", err, len(imported)))
+	}
+	slog.Info("ファイルからインポート", "source", input.Source, "dry_run", input.DryRun, "projects", len(imported))
+
+	output := &model.ImportFileOutput{}
+	output.Body.Source = input.Source
+	output.Body.DryRun = input.DryRun
+	output.Body.Projects = imported
+	return output, nil
+}
+
+// StartImport はインポートジョブを開始する。
+// Googleのアクセストークンの検証やタスクの取得には時間がかかるため、ジョブを記録して202を返し、
+// インポートはリクエストとは別に実行する。iCalendarは受け付ける前に解析し、不正な場合は422を返す。
+func (h *ImportHandler) StartImport(ctx context.Context, input *model.StartImportInput) (*model.StartImportOutput, error) {
+	req := input.Body
+	fetch := func(ctx context.Context) ([]model.ProjectBundle, error) {
+		return h.Fetch(ctx, req)
+	}
+	switch req.Source {
+	case importer.SourceAppleReminders:
+		bundles, err := h.Fetch(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		fetch = func(context.Context) ([]model.ProjectBundle, error) {
+			return bundles, nil
+		}
+	case importer.SourceGoogleTasks:
+		if req.AccessToken == "" && req.RefreshToken == "" {
+			return nil, huma.Error422UnprocessableEntity("access_tokenかrefresh_tokenを指定してください", &huma.ErrorDetail{Location: "body.access_token"})
+		}
+	}
+
+	job, err := h.queries.CreateImportJob(ctx, db.CreateImportJobParams{
+		Source: req.Source,
+		DryRun: boolToInt64(req.DryRun),
+		Actor:  activity.ActorFrom(ctx),
+	})
+	if err != nil {
+		slog.Warn("インポートジョブの作成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("インポートジョブの作成に失敗", err)
+	}
+
+	// 実行者と所有者はcontextから引き継ぎ、リクエストの終了ではキャンセルしない
+	h.running.Go(func() {
+		h.run(context.WithoutCancel(ctx), job, fetch)
+	})
+
+	output := &model.StartImportOutput{}
+	output.Location = fmt.Sprintf("/admin/imports/%d", job.ID)
+	output.Body = toImportJobResponse(job)
+	return output, nil
+}
+
+// Wait は実行中のインポートジョブがすべて終わるまで待つ。サーバーの停止時にデータベースを閉じる前に呼び出す。
+func (h *ImportHandler) Wait() {
+	h.running.Wait()
+}
+
+// run はインポートを実行し、結果をジョブに記録する
+func (h *ImportHandler) run(ctx context.Context, job db.ImportJob, fetch func(context.Context) ([]model.ProjectBundle, error)) {
+	slog.Info("インポートを開始", "job_id", job.ID, "source", job.Source, "dry_run", job.DryRun == 1)
+
+	var imported []model.ImportedProject
+	bundles, err := fetch(ctx)
+	if err == nil {
+		imported, err = h.ImportBundles(ctx, bundles, job.DryRun == 1)
+	}
+
+	params := db.FinishImportJobParams{Status: model.ImportJobSucceeded, ID: job.ID}
+	if err != nil {
+		slog.Warn("インポートに失敗", "job_id", job.ID, "source", job.Source, "err", err)
+		params.Status = model.ImportJobFailed
+		params.Error = sql.NullString{String: err.Error(), Valid: true}
+	}
+	if imported != nil {
+		if result, err := json.Marshal(imported); err == nil {
+			params.Result = sql.NullString{String: string(result), Valid: true}
+		}
+	}
+	if _, err := h.queries.FinishImportJob(ctx, params); err != nil {
+		slog.Warn("インポートジョブの記録に失敗", "job_id", job.ID, "err", err)
+		return
+	}
+	slog.Info("インポートを終了", "job_id", job.ID, "status", params.Status, "projects", len(imported))
+}
+
+// GetImportJob は指定したIDのインポートジョブを取得する
+func (h *ImportHandler) GetImportJob(ctx context.Context, input *model.GetImportJobInput) (*model.GetImportJobOutput, error) {
+	job, err := h.queries.GetImportJob(ctx, input.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, huma.Error404NotFound("インポートジョブが見つかりません")
+	}
+	if err != nil {
+		slog.Warn("インポートジョブの取得に失敗", "id", input.ID, "err", err)
+		return nil, huma.Error500InternalServerError("インポートジョブの取得に失敗", err)
+	}
+
+	output := &model.GetImportJobOutput{}
+	output.Body = toImportJobResponse(job)
+	return output, nil
+}
+
+// ListImportJobs はインポートジョブを新しい順に取得する
+func (h *ImportHandler) ListImportJobs(ctx context.Context, input *model.ListImportJobsInput) (*model.ListImportJobsOutput, error) {
+	jobs, err := h.queries.ListImportJobs(ctx, input.Limit)
+	if err != nil {
+		slog.Warn("インポートジョブ一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("インポートジョブ一覧の取得に失敗", err)
+	}
+
+	output := &model.ListImportJobsOutput{}
+	output.Body.Jobs = make([]model.ImportJobResponse, len(jobs))
+	for i, j := range jobs {
+		output.Body.Jobs[i] = toImportJobResponse(j)
+	}
+	return output, nil
+}
+
+// toImportJobResponse はDBのインポートジョブをレスポンスに変換する
+func toImportJobResponse(j db.ImportJob) model.ImportJobResponse {
+	resp := model.ImportJobResponse{
+		ID:        j.ID,
+		Source:    j.Source,
+		Status:    j.Status,
+		DryRun:    j.DryRun == 1,
+		Actor:     j.Actor,
+		Projects:  []model.ImportedProject{},
+		Error:     nullStringToPtr(j.Error),
+		CreatedAt: j.CreatedAt.Format(time.RFC3339),
+	}
+	if j.Result.Valid {
+		if err := json.Unmarshal([]byte(j.Result.String), &resp.Projects); err != nil {
+			slog.Warn("インポートジョブの結果を解析できません", "id", j.ID, "err", err)
+		}
+	}
+	if j.FinishedAt.Valid {
+		finishedAt := j.FinishedAt.Time.Format(time.RFC3339)
+		resp.FinishedAt = &finishedAt
+	}
+	return resp
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// InvitationHandler は招待制の登録に使う招待の作成と取り消しを処理するハンドラー
+type InvitationHandler struct {
+	queries     *db.Queries
+	defaultRole auth.Role
+	ttl         time.Duration
+	clock       clock.Clock
+}
+
+// NewInvitationHandler はInvitationHandlerの新しいインスタンスを生成する。
+// 招待はttlの間有効で、ロールを指定しない招待で登録したユーザーはdefaultRoleになる。
+func NewInvitationHandler(queries *db.Queries, defaultRole auth.Role, ttl time.Duration, clock clock.Clock) *InvitationHandler {
+	return &InvitationHandler{
+		queries:     queries,
+		defaultRole: defaultRole,
+		ttl:         ttl,
+		clock:       clock,
+	}
+}
+
+// toInvitationResponse はdb.Invitationをmodel.InvitationResponseに変換する
+func (h *InvitationHandler) toInvitationResponse(inv db.Invitation) model.InvitationResponse {
+	status := model.InvitationPending
+	switch {
+	case inv.AcceptedAt.Valid:
+		status = model.InvitationAccepted
+	case !inv.ExpiresAt.After(h.clock.Now()):
+		status = model.InvitationExpired
+	}
+	return model.InvitationResponse{
+		ID:         inv.ID,
+		Email:      inv.Email,
+		Role:       inv.Role,
+		InvitedBy:  inv.InvitedBy,
+		Status:     status,
+		ExpiresAt:  inv.ExpiresAt.UTC().Format(time.RFC3339),
+		AcceptedAt: nullTimeToPtr(inv.AcceptedAt),
+		CreatedAt:  inv.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ListInvitations は招待の一覧を新しい順に取得する
+func (h *InvitationHandler) ListInvitations(ctx context.Context, input *model.ListInvitationsInput) (*model.ListInvitationsOutput, error) {
+	invitations, err := h.queries.ListInvitations(ctx, input.Limit)
+	if err != nil {
+		slog.Warn("招待一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("招待一覧の取得に失敗", err)
+	}
+
+	output := &model.ListInvitationsOutput{}
+	output.Body.Invitations = make([]model.InvitationResponse, len(invitations))
+	for i, inv := range invitations {
+		output.Body.Invitations[i] = h.toInvitationResponse(inv)
+	}
+	return output, nil
+}
+
+// CreateInvitation はメールアドレスを招待する。トークンはハッシュのみを保存し、平文はこの応答でのみ返す。
+// 登録済みのメールアドレスは409を返す。
+func (h *InvitationHandler) CreateInvitation(ctx context.Context, input *model.CreateInvitationInput) (*model.CreateInvitationOutput, error) {
+	email := strings.TrimSpace(input.Body.Email)
+	if _, err := h.queries.GetUserByEmail(ctx, email); err == nil {
+		slog.Warn("メールアドレスは登録済みです", "email", email)
+		return nil, huma.Error409Conflict("メールアドレスは登録済みです")
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		slog.Warn("ユーザーの取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("ユーザーの取得に失敗", err)
+	}
+
+	role := h.defaultRole
+	if input.Body.Role != "" {
+		role = auth.Role(input.Body.Role)
+	}
+	token, err := auth.NewInvitationToken()
+	if err != nil {
+		slog.Warn("招待のトークンの生成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("招待のトークンの生成に失敗", err)
+	}
+
+	inv, err := h.queries.CreateInvitation(ctx, db.CreateInvitationParams{
+		Email:     email,
+		Role:      string(role),
+		TokenHash: auth.HashAPIKey(token),
+		InvitedBy: activity.ActorFrom(ctx),
+		ExpiresAt: h.clock.Now().Add(h.ttl).UTC(),
+	})
+	if err != nil {
+		slog.Warn("招待の作成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("招待の作成に失敗", err)
+	}
+	slog.Info("メールアドレスを招待", "id", inv.ID, "email", inv.Email, "role", inv.Role)
+
+	output := &model.CreateInvitationOutput{}
+	output.Body.InvitationResponse = h.toInvitationResponse(inv)
+	output.Body.Token = token
+	return output, nil
+}
+
+// DeleteInvitation は指定されたIDの招待を取り消す。使用済みの招待を削除しても登録したユーザーには影響しない。
+func (h *InvitationHandler) DeleteInvitation(ctx context.Context, input *model.DeleteInvitationInput) (*model.DeleteInvitationOutput, error) {
+	n, err := h.queries.DeleteInvitation(ctx, input.ID)
+	if err != nil {
+		slog.Warn("招待の取り消しに失敗", "err", err)
+		return nil, huma.Error500InternalServerError("招待の取り消しに失敗", err)
+	}
+	if n == 0 {
+		slog.Warn("招待IDが見つかりません", "id", input.ID)
+		return nil, huma.Error404NotFound(fmt.Sprintf("招待IDが見つかりません: %d", input.ID))
+	}
+
+	output := &model.DeleteInvitationOutput{}
+	output.Body.Message = "Invitation deleted successfully"
+	return output, nil
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// LockHandler はリソースのロックを処理するハンドラー。
+// ロックしたTodoを他のユーザーが変更する操作は、変更するトランザクションの中でcheckTodoLocksが423で拒否する。
+type LockHandler struct {
+	queries    *db.Queries
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	clock      clock.Clock
+}
+
+// NewLockHandler はLockHandlerの新しいインスタンスを生成する
+func NewLockHandler(queries *db.Queries, defaultTTL, maxTTL time.Duration, clock clock.Clock) *LockHandler {
+	return &LockHandler{
+		queries:    queries,
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+		clock:      clock,
+	}
+}
+
+// toLockResponse はdb.GetResourceLockRowをmodel.LockResponseに変換する
+func toLockResponse(l db.GetResourceLockRow) model.LockResponse {
+	return model.LockResponse{
+		Resource:   l.ResourceType,
+		ResourceID: l.ResourceID,
+		HolderID:   l.HolderID,
+		HolderName: l.HolderName,
+		AcquiredAt: l.AcquiredAt.UTC().Format(time.RFC3339),
+		ExpiresAt:  l.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// lockingUser はロックを操作する認証したユーザーのIDを返す。ロックの持ち主は名前で示すため、ユーザー以外の認証主体はロックできない。
+func lockingUser(ctx context.Context) (int64, error) {
+	id, ok := auth.UserIDFrom(ctx)
+	if !ok {
+		slog.Warn("ユーザー以外の認証主体はロックを操作できません")
+		return 0, huma.Error403Forbidden("ロックはユーザーとして認証した場合のみ操作できます")
+	}
+	return id, nil
+}
+
+// checkTodoLocks は他のユーザーが期限内のロックを持っているTodoがidsに含まれていれば423を返す。
+// ロックを持っているユーザー自身の変更と、期限を過ぎたロックは妨げない。
+// 確認してから変更するまでにロックされないよう、変更と同じトランザクションのqで確認する。
+func checkTodoLocks(ctx context.Context, q *db.Queries, now time.Time, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	locks, err := q.ListTodoLocks(ctx, db.ListTodoLocksParams{Ids: ids, Now: now.UTC()})
+	if err != nil {
+		slog.Warn("ロックの取得に失敗", "ids", ids, "err", err)
+		return huma.Error500InternalServerError("ロックの取得に失敗", err)
+	}
+	userID, isUser := auth.UserIDFrom(ctx)
+	for _, lock := range locks {
+		if isUser && lock.HolderID == userID {
+			continue
+		}
+		slog.Warn("ロックされたTodoへの変更を拒否", "id", lock.ResourceID, "holder_id", lock.HolderID)
+		return huma.NewError(http.StatusLocked,
+			fmt.Sprintf("%sが%sまでロックしているため変更できません: %d", lock.HolderName, lock.ExpiresAt.UTC().Format(time.RFC3339), lock.ResourceID),
+			&huma.ErrorDetail{Message: "ロックを持っているユーザーのID", Value: lock.HolderID},
+		)
+	}
+	return nil
+}
+
+// getUnlockedTodo はgetWritableTodoと同じく変更できるTodoを取得し、他のユーザーがロックしている場合は423を返す
+func getUnlockedTodo(ctx context.Context, q *db.Queries, id int64, now time.Time) (db.Todo, error) {
+	todo, err := getWritableTodo(ctx, q, id)
+	if err != nil {
+		return db.Todo{}, err
+	}
+	if err := checkTodoLocks(ctx, q, now, id); err != nil {
+		return db.Todo{}, err
+	}
+	return todo, nil
+}
+
+// ensureTodoUnlocked は親Todoが存在して変更でき、他のユーザーがロックしていないことを確認する
+func ensureTodoUnlocked(ctx context.Context, q *db.Queries, id int64, now time.Time) error {
+	_, err := getUnlockedTodo(ctx, q, id, now)
+	return err
+}
+
+// getLock は期限内のロックを取得する。ロックされていない場合は404を返す
+func (h *LockHandler) getLock(ctx context.Context, resource string, id int64) (db.GetResourceLockRow, error) {
+	lock, err := h.queries.GetResourceLock(ctx, db.GetResourceLockParams{
+		ResourceType: resource,
+		ResourceID:   id,
+		Now:          h.clock.Now().UTC(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("ロックされていません", "resource", resource, "id", id)
+			return db.GetResourceLockRow{}, huma.Error404NotFound(fmt.Sprintf("ロックされていません: %d", id))
+		}
+		slog.Warn("ロックの取得に失敗", "resource", resource, "id", id, "err", err)
+		return db.GetResourceLockRow{}, huma.Error500InternalServerError("ロックの取得に失敗", err)
+	}
+	return lock, nil
+}
+
+// AcquireTodoLock は認証したユーザーとして指定されたIDのTodoをロックする。
+// 既に自分が持っているロックは期限を延ばし、期限内のロックを他のユーザーが持っている場合は423を返す。
+func (h *LockHandler) AcquireTodoLock(ctx context.Context, input *model.AcquireTodoLockInput) (*model.AcquireTodoLockOutput, error) {
+	userID, err := lockingUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureTodoWritable(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+
+	ttl := h.defaultTTL
+	if input.Body != nil && input.Body.TTLSeconds > 0 {
+		ttl = time.Duration(input.Body.TTLSeconds) * time.Second
+	}
+	ttl = min(ttl, h.maxTTL)
+
+	now := h.clock.Now().UTC()
+	if _, err := h.queries.PruneResourceLocks(ctx, now); err != nil {
+		// 期限切れのロックは無いものとして扱うため、削除に失敗しても取得は続ける
+		slog.Warn("期限切れのロックの削除に失敗", "err", err)
+	}
+	_, err = h.queries.AcquireResourceLock(ctx, db.AcquireResourceLockParams{
+		ResourceType: model.LockResourceTodo,
+		ResourceID:   input.ID,
+		HolderID:     userID,
+		Now:          now,
+		ExpiresAt:    now.Add(ttl),
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Warn("Todoのロックに失敗", "id", input.ID, "err", err)
+		return nil, huma.Error500InternalServerError("Todoのロックに失敗", err)
+	}
+
+	lock, err := h.getLock(ctx, model.LockResourceTodo, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if lock.HolderID != userID {
+		slog.Warn("他のユーザーがロックしています", "id", input.ID, "holder_id", lock.HolderID)
+		return nil, huma.NewError(http.StatusLocked,
+			fmt.Sprintf("%sが%sまでロックしています", lock.HolderName, lock.ExpiresAt.UTC().Format(time.RFC3339)),
+			&huma.ErrorDetail{Message: "ロックを持っているユーザーのID", Value: lock.HolderID},
+		)
+	}
+	return &model.AcquireTodoLockOutput{Body: toLockResponse(lock)}, nil
+}
+
+// GetTodoLock は指定されたIDのTodoのロックを取得する。ロックされていない場合は404を返す
+func (h *LockHandler) GetTodoLock(ctx context.Context, input *model.GetTodoLockInput) (*model.GetTodoLockOutput, error) {
+	if err := ensureTodoExists(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+	lock, err := h.getLock(ctx, model.LockResourceTodo, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.GetTodoLockOutput{Body: toLockResponse(lock)}, nil
+}
+
+// ReleaseTodoLock は認証したユーザーが持っている、指定されたIDのTodoのロックを解除する。
+// 他のユーザーのロックは423を返すが、adminロールはforceを指定して解除できる。編集していたユーザーが戻らない場合の救済に使う。
+func (h *LockHandler) ReleaseTodoLock(ctx context.Context, input *model.ReleaseTodoLockInput) (*model.ReleaseTodoLockOutput, error) {
+	if input.Force {
+		// adminはTodoを閲覧できなくても解除できる
+		if role, _ := auth.RoleFrom(ctx); !role.Allows(auth.RoleAdmin) {
+			slog.Warn("ロックの強制解除にはadminロールが必要です", "id", input.ID, "role", role)
+			return nil, huma.Error403Forbidden("ロックの強制解除にはadminロールが必要です")
+		}
+	} else if err := ensureTodoExists(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+
+	lock, err := h.getLock(ctx, model.LockResourceTodo, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	userID, isUser := auth.UserIDFrom(ctx)
+	if !input.Force && (!isUser || lock.HolderID != userID) {
+		slog.Warn("他のユーザーのロックは解除できません", "id", input.ID, "holder_id", lock.HolderID)
+		return nil, huma.NewError(http.StatusLocked,
+			fmt.Sprintf("%sのロックは解除できません。adminロールはforceを指定して解除できます", lock.HolderName),
+			&huma.ErrorDetail{Message: "ロックを持っているユーザーのID", Value: lock.HolderID},
+		)
+	}
+
+	if err := h.queries.DeleteResourceLock(ctx, db.DeleteResourceLockParams{ResourceType: model.LockResourceTodo, ResourceID: input.ID}); err != nil {
+		slog.Warn("ロックの解除に失敗", "id", input.ID, "err", err)
+		return nil, huma.Error500InternalServerError("ロックの解除に失敗", err)
+	}
+	if lock.HolderID != userID {
+		slog.Info("ロックを強制解除", "id", input.ID, "holder_id", lock.HolderID, "user_id", userID)
+	}
+
+	output := &model.ReleaseTodoLockOutput{}
+	output.Body.Message = "Todo unlocked successfully"
+	return output, nil
+}
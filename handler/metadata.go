@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-huma-test/db"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// maxMetadataSize はTodoのmetadataをJSONにエンコードした際の最大バイト数
+const maxMetadataSize = 16 * 1024
+
+// metadataLocation はmetadataの検証エラーで示す位置
+const metadataLocation = "body.metadata"
+
+// metadataRegistry はmetadataの検証に用いるレジストリ。プロジェクトのスキーマは$refを使わないため空のままになる。
+var metadataRegistry = huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+
+// encodeMetadata はmetadataをJSONにエンコードする。サイズが上限を超える場合は422を返す。
+func encodeMetadata(m map[string]any) (sql.NullString, error) {
+	if m == nil {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		slog.Warn("metadataのエンコードに失敗", "err", err)
+		return sql.NullString{}, huma.Error422UnprocessableEntity("metadataをJSONにエンコードできません", err)
+	}
+	if len(b) > maxMetadataSize {
+		slog.Warn("metadataが大きすぎます", "size", len(b))
+		return sql.NullString{}, huma.Error422UnprocessableEntity("metadataが大きすぎます", &huma.ErrorDetail{
+			Location: metadataLocation,
+			Message:  fmt.Sprintf("JSONで%dバイト以下にしてください", maxMetadataSize),
+			Value:    len(b),
+		})
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+// decodeJSONObject はJSONとして保存した値をオブジェクトとして読み込む。値がない場合はnilを返す。
+func decodeJSONObject(s sql.NullString) map[string]any {
+	if !s.Valid {
+		return nil
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(s.String), &m); err != nil {
+		slog.Warn("保存済みのJSONを読み込めません", "err", err)
+		return nil
+	}
+	return m
+}
+
+// parseMetadataSchema はプロジェクトに保存したJSON Schemaを検証に使えるスキーマに変換する
+func parseMetadataSchema(raw string) (*huma.Schema, error) {
+	var s huma.Schema
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return nil, err
+	}
+	if err := prepareSchema(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// prepareSchema はスキーマとその子要素の検証用メッセージを事前に計算する。
+// additionalPropertiesのスキーマはJSONからは汎用のmapとして読み込まれるため、*huma.Schemaに変換する。
+func prepareSchema(s *huma.Schema) error {
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("patternが不正です: %w", err)
+		}
+	}
+	if m, ok := s.AdditionalProperties.(map[string]any); ok {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		var addl huma.Schema
+		if err := json.Unmarshal(b, &addl); err != nil {
+			return err
+		}
+		s.AdditionalProperties = &addl
+	}
+
+	children := []*huma.Schema{s.Items, s.Not}
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	if addl, ok := s.AdditionalProperties.(*huma.Schema); ok {
+		children = append(children, addl)
+	}
+	children = append(children, s.OneOf...)
+	children = append(children, s.AnyOf...)
+	children = append(children, s.AllOf...)
+	for _, c := range children {
+		if c == nil {
+			continue
+		}
+		if err := prepareSchema(c); err != nil {
+			return err
+		}
+	}
+
+	s.PrecomputeMessages()
+	return nil
+}
+
+// encodeMetadataSchema はプロジェクトのmetadataのスキーマを検証し、JSONにエンコードする。
+// スキーマとして解釈できない場合は422を返す。
+func encodeMetadataSchema(m map[string]any) (sql.NullString, error) {
+	if m == nil {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		slog.Warn("metadata_schemaのエンコードに失敗", "err", err)
+		return sql.NullString{}, huma.Error422UnprocessableEntity("metadata_schemaをJSONにエンコードできません", err)
+	}
+	if _, err := parseMetadataSchema(string(b)); err != nil {
+		slog.Warn("metadata_schemaが不正です", "err", err)
+		return sql.NullString{}, huma.Error422UnprocessableEntity("metadata_schemaが不正です", &huma.ErrorDetail{
+			Location: "body.metadata_schema",
+			Message:  err.Error(),
+		})
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+// validateTodoMetadata はTodoのmetadataを所属するプロジェクトのスキーマで検証する。
+// プロジェクトにスキーマがない場合は検証しない。metadataがない場合は空のオブジェクトとして検証する。
+func validateTodoMetadata(ctx context.Context, q *db.Queries, projectID sql.NullInt64, metadata sql.NullString) error {
+	if !projectID.Valid {
+		return nil
+	}
+	p, err := q.GetProject(ctx, projectID.Int64)
+	if err != nil {
+		// プロジェクトの存在は呼び出し元で確認する
+		slog.Warn("プロジェクトの取得に失敗", "err", err)
+		return huma.Error500InternalServerError("プロジェクトの取得に失敗", err)
+	}
+	if !p.MetadataSchema.Valid {
+		return nil
+	}
+
+	schema, err := parseMetadataSchema(p.MetadataSchema.String)
+	if err != nil {
+		slog.Warn("保存済みのmetadata_schemaを読み込めません", "project_id", p.ID, "err", err)
+		return huma.Error500InternalServerError("プロジェクトのmetadata_schemaを読み込めません", err)
+	}
+
+	var value any = map[string]any{}
+	if metadata.Valid {
+		if err := json.Unmarshal([]byte(metadata.String), &value); err != nil {
+			return huma.Error500InternalServerError("metadataを読み込めません", err)
+		}
+	}
+
+	res := &huma.ValidateResult{}
+	huma.Validate(metadataRegistry, schema, huma.NewPathBuffer([]byte(metadataLocation), len(metadataLocation)), huma.ModeWriteToServer, value, res)
+	if len(res.Errors) > 0 {
+		slog.Warn("metadataがプロジェクトのスキーマに一致しません", "project_id", p.ID, "errors", len(res.Errors))
+		return huma.Error422UnprocessableEntity(fmt.Sprintf("metadataがプロジェクトのスキーマに一致しません: %d", p.ID), res.Errors...)
+	}
+	return nil
+}
+
+// parseMetadataFilter は "パス:値" 形式の絞り込み条件をjson_extractのパスと比較する値に分解する。
+// パスはドット区切りのキーで、例えば "labels.color:red" は $.labels.color が "red" のTodoに一致する。
+func parseMetadataFilter(filter string) (path, value sql.NullString) {
+	if filter == "" {
+		return sql.NullString{}, sql.NullString{}
+	}
+	key, v, _ := strings.Cut(filter, ":")
+	return sql.NullString{String: "$." + key, Valid: true}, sql.NullString{String: v, Valid: true}
+}
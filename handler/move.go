@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"go-huma-test/activity"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"log/slog"
+	"slices"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// renumberTodos は指定した順にTodoの並び順を1から振り直す。
+// 並び順が変わらないTodoとskipIDのTodoは更新しない。
+func renumberTodos(ctx context.Context, q *db.Queries, ids []int64, skipID int64) error {
+	for i, id := range ids {
+		if id == skipID {
+			continue
+		}
+		if _, err := q.SetTodoPosition(ctx, db.SetTodoPositionParams{
+			Position: int64(i + 1),
+			ID:       id,
+		}); err != nil {
+			slog.Warn("Todoの並び順の更新に失敗", "id", id, "err", err)
+			return huma.Error500InternalServerError("Todoの並び順の更新に失敗", err)
+		}
+	}
+	return nil
+}
+
+// listTodoIDsInProject はプロジェクト内のTodoのIDを並び順に取得する。excludeIDのTodoは含めない。
+func listTodoIDsInProject(ctx context.Context, q *db.Queries, projectID *int64, excludeID int64) ([]int64, error) {
+	ids, err := q.ListTodoIDsInProject(ctx, ptrInt64ToNullInt64(projectID))
+	if err != nil {
+		slog.Warn("プロジェクトのTodo一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("プロジェクトのTodo一覧の取得に失敗", err)
+	}
+	return slices.DeleteFunc(ids, func(id int64) bool { return id == excludeID }), nil
+}
+
+// MoveTodo は指定されたIDのTodoを別のプロジェクトや並び順に移動する。
+// 移動元と移動先の並び順は同じトランザクションで振り直す。
+func (h *TodoHandler) MoveTodo(ctx context.Context, input *model.MoveTodoInput) (*model.MoveTodoOutput, error) {
+	var todo db.Todo
+	err := runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		before, err := getUnlockedTodo(ctx, qtx, input.ID, h.clock.Now())
+		if err != nil {
+			return err
+		}
+		if err := checkTodoPrecondition(&input.Params, before, false); err != nil {
+			return err
+		}
+
+		// 別のリストへ移動する場合、移動元のリストは抜けた分を詰める
+		if ptrInt64ToNullInt64(input.Body.ProjectID) != before.ProjectID {
+			if err := ensureProjectAssignable(ctx, qtx, input.Body.ProjectID); err != nil {
+				return err
+			}
+			if err := validateTodoMetadata(ctx, qtx, ptrInt64ToNullInt64(input.Body.ProjectID), before.Metadata); err != nil {
+				return err
+			}
+			if before.Completed == 0 {
+				if err := checkWIPLimit(ctx, qtx, ptrInt64ToNullInt64(input.Body.ProjectID)); err != nil {
+					return err
+				}
+			}
+			ids, err := listTodoIDsInProject(ctx, qtx, nullInt64ToPtr(before.ProjectID), before.ID)
+			if err != nil {
+				return err
+			}
+			if err := renumberTodos(ctx, qtx, ids, before.ID); err != nil {
+				return err
+			}
+		}
+
+		ids, err := listTodoIDsInProject(ctx, qtx, input.Body.ProjectID, before.ID)
+		if err != nil {
+			return err
+		}
+		position := int64(len(ids)) + 1
+		if input.Body.Position != nil && *input.Body.Position < position {
+			position = *input.Body.Position
+		}
+		ids = slices.Insert(ids, int(position-1), before.ID)
+		if err := renumberTodos(ctx, qtx, ids, before.ID); err != nil {
+			return err
+		}
+
+		todo, err = qtx.MoveTodo(ctx, db.MoveTodoParams{
+			ProjectID: ptrInt64ToNullInt64(input.Body.ProjectID),
+			Position:  position,
+			ID:        before.ID,
+		})
+		if err != nil {
+			slog.Warn("Todoの移動に失敗", "id", before.ID, "err", err)
+			return huma.Error500InternalServerError("Todoの移動に失敗", err)
+		}
+
+		if err := recordActivity(ctx, qtx, activity.ActionMove, &before, &todo); err != nil {
+			return err
+		}
+		return recordEvent(ctx, qtx, event.Event{Type: event.TodoMoved, TodoID: todo.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.bus.Publish(event.Event{Type: event.TodoMoved, TodoID: todo.ID})
+
+	return &model.MoveTodoOutput{ETag: todoETag(todo), Body: toTodoResponse(ctx, todo)}, nil
+}
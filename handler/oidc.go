@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-huma-test/audit"
+	"go-huma-test/auth"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// oidcCookieName はログイン開始からコールバックまでstateなどを保持するCookieの名前。
+// model.OIDCCallbackInputのcookieタグと一致させる。
+const oidcCookieName = "todo_oidc"
+
+// oidcCookiePath はOIDCのCookieを送信するパス
+const oidcCookiePath = "/auth/oidc"
+
+// oidcLoginTTL はログインを開始してからコールバックまでに許容する時間
+const oidcLoginTTL = 10 * time.Minute
+
+// OIDCHandler は外部のOIDCプロバイダーによるログインを処理するハンドラー。
+// 初めてログインしたアカウントはユーザーとして自動で登録する。
+type OIDCHandler struct {
+	users        *UserHandler
+	provider     *auth.OIDC
+	issuer       string
+	secureCookie bool
+}
+
+// NewOIDCHandler はOIDCHandlerの新しいインスタンスを生成する。
+// トークンの発行と認証イベントの記録はusersと共通にする。
+// redirectURLがhttpsの場合はCookieにSecure属性を付ける。
+func NewOIDCHandler(users *UserHandler, provider *auth.OIDC, issuer, redirectURL string) *OIDCHandler {
+	secure := false
+	if u, err := url.Parse(redirectURL); err == nil {
+		secure = u.Scheme == "https"
+	}
+	return &OIDCHandler{
+		users:        users,
+		provider:     provider,
+		issuer:       issuer,
+		secureCookie: secure,
+	}
+}
+
+// cookie はOIDCのCookieを返す。valueが空の場合はCookieを削除する。
+func (h *OIDCHandler) cookie(value string) http.Cookie {
+	c := http.Cookie{
+		Name:     oidcCookieName,
+		Value:    value,
+		Path:     oidcCookiePath,
+		MaxAge:   int(oidcLoginTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		// プロバイダーからのリダイレクトでも送信されるようLaxにする
+		SameSite: http.SameSiteLaxMode,
+	}
+	if value == "" {
+		c.MaxAge = -1
+	}
+	return c
+}
+
+// providerError はOIDCプロバイダーとの通信の失敗をエラーレスポンスに変換する
+func providerError(err error) error {
+	if errors.Is(err, auth.ErrInvalidToken) {
+		slog.Warn("IDトークンが不正です", "err", err)
+		return huma.Error401Unauthorized("OIDCプロバイダーの認証結果を確認できませんでした")
+	}
+	slog.Warn("OIDCプロバイダーとの通信に失敗", "err", err)
+	return huma.Error502BadGateway("OIDCプロバイダーとの通信に失敗しました")
+}
+
+// Login はOIDCプロバイダーのログイン画面へリダイレクトする。
+// state、nonce、PKCEのコード検証子はCookieに保存し、コールバックで照合する。
+func (h *OIDCHandler) Login(ctx context.Context, _ *struct{}) (*model.OIDCLoginOutput, error) {
+	values := make([]string, 3)
+	for i := range values {
+		v, err := auth.NewOIDCState()
+		if err != nil {
+			slog.Warn("stateの生成に失敗", "err", err)
+			return nil, huma.Error500InternalServerError("stateの生成に失敗", err)
+		}
+		values[i] = v
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	location, err := h.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return nil, providerError(err)
+	}
+
+	return &model.OIDCLoginOutput{
+		Location:  location,
+		SetCookie: h.cookie(strings.Join(values, ".")),
+	}, nil
+}
+
+// Callback はOIDCプロバイダーから戻った認可コードをIDトークンと交換し、アクセストークンを発行する。
+// プロバイダーのアカウントが未登録の場合は、確認済みのメールアドレスが一致するユーザーに紐づけるか、新しいユーザーを登録する。
+func (h *OIDCHandler) Callback(ctx context.Context, input *model.OIDCCallbackInput) (*model.OIDCCallbackOutput, error) {
+	if input.Error != "" {
+		slog.Warn("OIDCプロバイダーでログインできませんでした", "error", input.Error, "description", input.ErrorDescription)
+		return nil, huma.Error401Unauthorized("OIDCプロバイダーでログインできませんでした: " + input.Error)
+	}
+
+	session := strings.Split(input.Session, ".")
+	if len(session) != 3 || input.State == "" || session[0] != input.State {
+		slog.Warn("OIDCのstateが一致しません")
+		return nil, huma.Error400BadRequest("stateが一致しません。ログインをやり直してください")
+	}
+	if input.Code == "" {
+		return nil, huma.Error400BadRequest("認可コードがありません", &huma.ErrorDetail{
+			Location: "query.code",
+		})
+	}
+	nonce, verifier := session[1], session[2]
+
+	claims, err := h.provider.Exchange(ctx, input.Code, verifier, nonce)
+	if err != nil {
+		h.users.record(ctx, audit.Subject(h.issuer), audit.EventLoginFailed)
+		return nil, providerError(err)
+	}
+
+	user, err := h.provision(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	h.users.record(ctx, auth.UserSubject(user.ID), audit.EventLoginSucceeded)
+
+	body, err := h.users.issueToken(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &model.OIDCCallbackOutput{SetCookie: h.cookie(""), Body: body}, nil
+}
+
+// provision はIDトークンのアカウントに対応するユーザーを返す。
+// 未登録のアカウントは、プロバイダーが確認済みとしたメールアドレスのユーザーがいればそのユーザーに紐づけ、いなければ新しく登録する。
+// 確認されていないメールアドレスでは既存のユーザーに紐づけない。他人のメールアドレスでアカウントを乗っ取られないようにするため。
+func (h *OIDCHandler) provision(ctx context.Context, claims *auth.Claims) (db.User, error) {
+	email, _ := claims.Extra["email"].(string)
+	email = strings.TrimSpace(email)
+	emailVerified, _ := claims.Extra["email_verified"].(bool)
+	name, _ := claims.Extra["name"].(string)
+	if name == "" {
+		name = email
+	}
+
+	var user db.User
+	created := false
+	err := runInTx(ctx, h.users.db, h.users.queries, false, func(qtx *db.Queries) error {
+		u, err := qtx.GetUserByIdentity(ctx, db.GetUserByIdentityParams{Issuer: h.issuer, Subject: claims.Subject})
+		if err == nil {
+			user = u
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("ユーザーの取得に失敗", "err", err)
+			return huma.Error500InternalServerError("ユーザーの取得に失敗", err)
+		}
+
+		if email == "" {
+			slog.Warn("IDトークンにメールアドレスがありません", "sub", claims.Subject)
+			return huma.Error403Forbidden("OIDCプロバイダーからメールアドレスを取得できません。emailスコープを許可してください")
+		}
+		u, err = qtx.GetUserByEmail(ctx, email)
+		switch {
+		case err == nil && !emailVerified:
+			slog.Warn("確認されていないメールアドレスのため既存のユーザーに紐づけません", "email", email)
+			return huma.Error409Conflict("メールアドレスは登録済みです。OIDCプロバイダーでメールアドレスを確認してください")
+		case err == nil:
+			user = u
+		case errors.Is(err, sql.ErrNoRows):
+			a, err := h.users.admit(ctx, qtx, email, "", emailVerified)
+			if err != nil {
+				return err
+			}
+			user, err = qtx.CreateUser(ctx, db.CreateUserParams{
+				Email: email,
+				Name:  name,
+				Role:  string(a.role),
+			})
+			if err != nil {
+				slog.Warn("ユーザーの登録に失敗", "err", err)
+				return huma.Error500InternalServerError("ユーザーの登録に失敗", err)
+			}
+			if err := h.users.accept(ctx, qtx, a, user.ID); err != nil {
+				return err
+			}
+			created = true
+		default:
+			slog.Warn("ユーザーの取得に失敗", "err", err)
+			return huma.Error500InternalServerError("ユーザーの取得に失敗", err)
+		}
+
+		if err := qtx.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+			UserID:  user.ID,
+			Issuer:  h.issuer,
+			Subject: claims.Subject,
+		}); err != nil {
+			slog.Warn("OIDCアカウントの紐づけに失敗", "err", err)
+			return huma.Error500InternalServerError("OIDCアカウントの紐づけに失敗", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+	if created {
+		slog.Info("OIDCでユーザーを登録", "user_id", user.ID, "role", user.Role)
+	}
+	return user, nil
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// OperationUsageHandler は操作ごとの利用状況を取得するハンドラー
+type OperationUsageHandler struct {
+	queries *db.Queries
+	oapi    *huma.OpenAPI
+}
+
+// NewOperationUsageHandler はOperationUsageHandlerの新しいインスタンスを生成する。
+// 操作の一覧はoapiから取得するため、一度も呼び出されていない操作も返す。
+func NewOperationUsageHandler(queries *db.Queries, oapi *huma.OpenAPI) *OperationUsageHandler {
+	return &OperationUsageHandler{
+		queries: queries,
+		oapi:    oapi,
+	}
+}
+
+// GetOperationUsage は登録済みのすべての操作の呼び出し回数と最終使用日時を、呼び出し回数の少ない順に取得する。
+// 呼び出し回数はusage-intervalごとに書き込むため、直近の呼び出しは含まれないことがある。
+func (h *OperationUsageHandler) GetOperationUsage(ctx context.Context, input *model.OperationUsageInput) (*model.OperationUsageOutput, error) {
+	rows, err := h.queries.ListOperationUsage(ctx)
+	if err != nil {
+		slog.Warn("操作の利用状況の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("操作の利用状況の取得に失敗", err)
+	}
+	recorded := make(map[string]db.OperationUsage, len(rows))
+	for _, r := range rows {
+		recorded[r.OperationID] = r
+	}
+
+	var unusedSince time.Time
+	if input.UnusedDays > 0 {
+		unusedSince = time.Now().UTC().AddDate(0, 0, -input.UnusedDays)
+	}
+
+	operations := []model.OperationUsage{}
+	for path, item := range h.oapi.Paths {
+		for method, op := range map[string]*huma.Operation{
+			http.MethodGet:     item.Get,
+			http.MethodPut:     item.Put,
+			http.MethodPost:    item.Post,
+			http.MethodDelete:  item.Delete,
+			http.MethodOptions: item.Options,
+			http.MethodHead:    item.Head,
+			http.MethodPatch:   item.Patch,
+			http.MethodTrace:   item.Trace,
+		} {
+			if op == nil || op.OperationID == "" {
+				continue
+			}
+			u := model.OperationUsage{
+				OperationID: op.OperationID,
+				Method:      method,
+				Path:        path,
+				Deprecated:  op.Deprecated,
+			}
+			if r, ok := recorded[op.OperationID]; ok {
+				if !unusedSince.IsZero() && r.LastUsedAt.After(unusedSince) {
+					continue
+				}
+				u.Calls = r.Calls
+				u.LastUsedAt = &r.LastUsedAt
+			}
+			operations = append(operations, u)
+		}
+	}
+	slices.SortFunc(operations, func(a, b model.OperationUsage) int {
+		return cmp.Or(cmp.Compare(a.Calls, b.Calls), cmp.Compare(a.OperationID, b.OperationID))
+	})
+
+	output := &model.OperationUsageOutput{}
+	output.Body.Operations = operations
+	return output, nil
+}
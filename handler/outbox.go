@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"context"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/outbox"
+	"log/slog"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// recordEvent はTodoの変更イベントをoutboxに記録する。qtxには変更と同じトランザクションを渡す。
+// コミットした後はh.bus.Publishで同じイベントを発行し、購読者と中継に知らせる
+func recordEvent(ctx context.Context, qtx *db.Queries, e event.Event) error {
+	if err := outbox.Record(ctx, qtx, e); err != nil {
+		slog.Warn("イベントの記録に失敗", "err", err)
+		return huma.Error500InternalServerError("イベントの記録に失敗", err)
+	}
+	return nil
+}
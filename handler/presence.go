@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-huma-test/auth"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"go-huma-test/presence"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/sse"
+)
+
+// PresenceHandler は共有しているプロジェクトを開いているユーザーの追跡を処理するハンドラー
+type PresenceHandler struct {
+	queries *db.Queries
+	tracker *presence.Tracker
+}
+
+// NewPresenceHandler はPresenceHandlerの新しいインスタンスを生成する
+func NewPresenceHandler(queries *db.Queries, tracker *presence.Tracker) *PresenceHandler {
+	return &PresenceHandler{
+		queries: queries,
+		tracker: tracker,
+	}
+}
+
+// PresenceStreamEvents はSSEで配信するイベント名とデータ型の対応
+var PresenceStreamEvents = map[string]any{
+	"presence": model.PresenceEvent{},
+}
+
+// toPresenceViewers はpresence.Viewerをmodel.PresenceViewerに変換する
+func toPresenceViewers(viewers []presence.Viewer) []model.PresenceViewer {
+	out := make([]model.PresenceViewer, len(viewers))
+	for i, v := range viewers {
+		out[i] = model.PresenceViewer{
+			UserID:   v.UserID,
+			Name:     v.Name,
+			Since:    v.Since.UTC().Format(time.RFC3339),
+			LastSeen: v.LastSeen.UTC().Format(time.RFC3339),
+		}
+	}
+	return out
+}
+
+// presenceResponse はプロジェクトの閲覧者のレスポンスを返す
+func (h *PresenceHandler) presenceResponse(projectID int64, viewers []presence.Viewer) model.PresenceResponse {
+	return model.PresenceResponse{
+		ProjectID:  projectID,
+		Viewers:    toPresenceViewers(viewers),
+		TTLSeconds: int64(h.tracker.TTL() / time.Second),
+	}
+}
+
+// presenceUser は閲覧者として記録する認証したユーザーのIDと名前を返す。
+// 閲覧者は名前で表示するため、ユーザー以外の認証主体は403を返す。
+func (h *PresenceHandler) presenceUser(ctx context.Context) (int64, string, error) {
+	id, ok := auth.UserIDFrom(ctx)
+	if !ok {
+		slog.Warn("ユーザー以外の認証主体は閲覧者として記録できません")
+		return 0, "", huma.Error403Forbidden("閲覧者はユーザーとして認証した場合のみ記録できます")
+	}
+	u, err := h.queries.GetUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("認証したユーザーが見つかりません", "user_id", id)
+			return 0, "", huma.Error401Unauthorized("ユーザーが見つかりません")
+		}
+		slog.Warn("ユーザーの取得に失敗", "err", err)
+		return 0, "", huma.Error500InternalServerError("ユーザーの取得に失敗", err)
+	}
+	return u.ID, u.Name, nil
+}
+
+// GetPresence はプロジェクトを開いているユーザーを取得する
+func (h *PresenceHandler) GetPresence(ctx context.Context, input *model.GetPresenceInput) (*model.GetPresenceOutput, error) {
+	if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+	return &model.GetPresenceOutput{Body: h.presenceResponse(input.ID, h.tracker.Viewers(input.ID))}, nil
+}
+
+// Heartbeat は認証したユーザーがプロジェクトを開いていることを記録する。
+// クライアントはプロジェクトを開いている間、ttl_secondsより短い間隔で呼び出す。
+func (h *PresenceHandler) Heartbeat(ctx context.Context, input *model.PresenceHeartbeatInput) (*model.PresenceHeartbeatOutput, error) {
+	userID, name, err := h.presenceUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+	viewers := h.tracker.Heartbeat(input.ID, userID, name)
+	return &model.PresenceHeartbeatOutput{Body: h.presenceResponse(input.ID, viewers)}, nil
+}
+
+// Leave は認証したユーザーがプロジェクトを閉じたことを記録する。開いていなかった場合も成功とする。
+func (h *PresenceHandler) Leave(ctx context.Context, input *model.LeavePresenceInput) (*model.LeavePresenceOutput, error) {
+	userID, ok := auth.UserIDFrom(ctx)
+	if !ok {
+		slog.Warn("ユーザー以外の認証主体は閲覧者として記録できません")
+		return nil, huma.Error403Forbidden("閲覧者はユーザーとして認証した場合のみ記録できます")
+	}
+	h.tracker.Leave(input.ID, userID)
+	return &model.LeavePresenceOutput{Body: h.presenceResponse(input.ID, h.tracker.Viewers(input.ID))}, nil
+}
+
+// StreamPresence はプロジェクトの閲覧者が変化するたびに現在の閲覧者をSSEで配信する。
+// 接続している間は認証したユーザーを閲覧者として記録するため、ハートビートを送る必要はない。
+func (h *PresenceHandler) StreamPresence(ctx context.Context, input *model.PresenceStreamInput, send sse.Sender) {
+	userID, name, err := h.presenceUser(ctx)
+	if err != nil {
+		return
+	}
+	if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+		return
+	}
+
+	// 自分が加わった変化は購読の前に配信し、購読後の最初のイベントで現在の閲覧者を送る
+	h.tracker.Heartbeat(input.ID, userID, name)
+	defer h.tracker.Leave(input.ID, userID)
+	changes, unsubscribe := h.tracker.Subscribe(input.ID)
+	defer unsubscribe()
+
+	if err := send.Data(model.PresenceEvent{Viewers: toPresenceViewers(h.tracker.Viewers(input.ID))}); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.tracker.TTL() / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.tracker.Heartbeat(input.ID, userID, name)
+		case viewers, ok := <-changes:
+			if !ok {
+				return
+			}
+			if err := send.Data(model.PresenceEvent{Viewers: toPresenceViewers(viewers)}); err != nil {
+				return
+			}
+		}
+	}
+}
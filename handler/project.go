@@ -0,0 +1,385 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
+	"go-huma-test/confirm"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"go-huma-test/presence"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// プロジェクト削除時の所属Todoの扱い
+const (
+	// ProjectDeleteModeArchive はプロジェクトをアーカイブし、所属するTodoを残す
+	ProjectDeleteModeArchive = "archive"
+	// ProjectDeleteModeDelete はプロジェクトと所属するTodoを削除する
+	ProjectDeleteModeDelete = "delete"
+)
+
+// ProjectHandler はプロジェクトに関する操作を処理するハンドラー
+type ProjectHandler struct {
+	queries   *db.Queries
+	db        *sql.DB
+	bus       *event.Bus
+	confirmer *confirm.Confirmer
+	presence  *presence.Tracker
+	clock     clock.Clock
+}
+
+// NewProjectHandler はProjectHandlerの新しいインスタンスを生成する
+// 所属するTodoごとの削除はconfirmerの確認トークンで2段階で実行する。
+// 一覧と取得の結果にはtrackerが追跡しているプロジェクトの閲覧者を含める。trackerがnilの場合は含めない。
+func NewProjectHandler(queries *db.Queries, db *sql.DB, bus *event.Bus, confirmer *confirm.Confirmer, tracker *presence.Tracker, clock clock.Clock) *ProjectHandler {
+	return &ProjectHandler{
+		queries:   queries,
+		db:        db,
+		bus:       bus,
+		confirmer: confirmer,
+		presence:  tracker,
+		clock:     clock,
+	}
+}
+
+// withViewers はプロジェクトのレスポンスに現在の閲覧者を設定する
+func (h *ProjectHandler) withViewers(p model.ProjectResponse) model.ProjectResponse {
+	if h.presence != nil {
+		p.Viewers = toPresenceViewers(h.presence.Viewers(p.ID))
+	}
+	return p
+}
+
+// nullTimeToPtr はsql.NullTimeをRFC3339形式の*stringに変換する
+func nullTimeToPtr(t sql.NullTime) *string {
+	if !t.Valid {
+		return nil
+	}
+	s := t.Time.Format(time.RFC3339)
+	return &s
+}
+
+// toProjectResponse はdb.Projectをmodel.ProjectResponseに変換する
+func toProjectResponse(p db.Project) model.ProjectResponse {
+	return model.ProjectResponse{
+		ID:             p.ID,
+		Name:           p.Name,
+		Description:    nullStringToPtr(p.Description),
+		Archived:       p.ArchivedAt.Valid,
+		ArchivedAt:     nullTimeToPtr(p.ArchivedAt),
+		OpenCount:      p.OpenCount,
+		CompletedCount: p.CompletedCount,
+		WIPLimit:       nullInt64ToPtr(p.WipLimit),
+		MetadataSchema: decodeJSONObject(p.MetadataSchema),
+		CreatedAt:      p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      p.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// projectNotFound はプロジェクトが見つからない場合のエラーを返す
+func projectNotFound(id int64) error {
+	return huma.Error404NotFound(fmt.Sprintf("プロジェクトIDが見つかりません: %d", id))
+}
+
+// getProject は指定されたIDのプロジェクトを取得する
+func getProject(ctx context.Context, q *db.Queries, id int64) (db.Project, error) {
+	p, err := q.GetProject(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("プロジェクトIDが見つかりません", "id", id, "err", err)
+			return db.Project{}, projectNotFound(id)
+		}
+		slog.Warn("プロジェクトの取得に失敗", "err", err)
+		return db.Project{}, huma.Error500InternalServerError("プロジェクトの取得に失敗", err)
+	}
+	return p, nil
+}
+
+// ensureProjectAssignable はTodoを指定されたプロジェクトに所属させられることを確認する。
+// アーカイブ済みのプロジェクトにはTodoを追加できない。
+func ensureProjectAssignable(ctx context.Context, q *db.Queries, projectID *int64) error {
+	if projectID == nil {
+		return nil
+	}
+	p, err := q.GetProject(ctx, *projectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return huma.Error422UnprocessableEntity(fmt.Sprintf("プロジェクトIDが見つかりません: %d", *projectID), &huma.ErrorDetail{
+				Location: "body.project_id",
+				Value:    *projectID,
+			})
+		}
+		slog.Warn("プロジェクトの取得に失敗", "err", err)
+		return huma.Error500InternalServerError("プロジェクトの取得に失敗", err)
+	}
+	if p.ArchivedAt.Valid {
+		slog.Warn("アーカイブ済みのプロジェクトにはTodoを追加できません", "project_id", p.ID)
+		return huma.Error409Conflict(fmt.Sprintf("プロジェクトはアーカイブ済みです: %d", p.ID))
+	}
+	return nil
+}
+
+// checkWIPLimit はプロジェクトに未完了のTodoを1件加えてもWIP制限を超えないことを確認する。
+// 同時に加えて上限を超えないよう、qには件数を変更するのと同じ書き込みのトランザクションを渡す。
+func checkWIPLimit(ctx context.Context, q *db.Queries, projectID sql.NullInt64) error {
+	if !projectID.Valid {
+		return nil
+	}
+	p, err := q.GetProject(ctx, projectID.Int64)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		slog.Warn("プロジェクトの取得に失敗", "err", err)
+		return huma.Error500InternalServerError("プロジェクトの取得に失敗", err)
+	}
+	if !p.WipLimit.Valid || p.OpenCount < p.WipLimit.Int64 {
+		return nil
+	}
+	slog.Warn("プロジェクトの未完了のTodoがWIP制限に達しています", "project_id", p.ID, "open_count", p.OpenCount, "wip_limit", p.WipLimit.Int64)
+	return huma.Error409Conflict(fmt.Sprintf("プロジェクトの未完了のTodoがWIP制限に達しています: %d件（上限%d件）", p.OpenCount, p.WipLimit.Int64), &huma.ErrorDetail{
+		Message: "未完了のTodoの件数と上限",
+		Value:   map[string]int64{"project_id": p.ID, "open_count": p.OpenCount, "wip_limit": p.WipLimit.Int64},
+	})
+}
+
+// ListProjects はプロジェクトの一覧を取得する
+func (h *ProjectHandler) ListProjects(ctx context.Context, input *model.ListProjectsInput) (*model.ListProjectsOutput, error) {
+	var includeArchived int64
+	if input.IncludeArchived {
+		includeArchived = 1
+	}
+
+	projects, err := h.queries.ListProjects(ctx, includeArchived)
+	if err != nil {
+		slog.Warn("プロジェクト一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("プロジェクト一覧の取得に失敗", err)
+	}
+
+	output := &model.ListProjectsOutput{}
+	output.Body.Projects = make([]model.ProjectResponse, len(projects))
+	for i, p := range projects {
+		output.Body.Projects[i] = h.withViewers(toProjectResponse(p))
+	}
+
+	return output, nil
+}
+
+// GetProject は指定されたIDのプロジェクトを取得する
+func (h *ProjectHandler) GetProject(ctx context.Context, input *model.GetProjectInput) (*model.GetProjectOutput, error) {
+	p, err := getProject(ctx, h.queries, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GetProjectOutput{Body: h.withViewers(toProjectResponse(p))}, nil
+}
+
+// CreateProject は新しいプロジェクトを作成する
+func (h *ProjectHandler) CreateProject(ctx context.Context, input *model.CreateProjectInput) (*model.CreateProjectOutput, error) {
+	metadataSchema, err := encodeMetadataSchema(input.Body.MetadataSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := h.queries.CreateProject(ctx, db.CreateProjectParams{
+		Name:           input.Body.Name,
+		Description:    ptrStringToNullString(input.Body.Description),
+		MetadataSchema: metadataSchema,
+		WipLimit:       ptrInt64ToNullInt64(input.Body.WIPLimit),
+	})
+	if err != nil {
+		slog.Warn("プロジェクト作成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("プロジェクト作成に失敗", err)
+	}
+
+	return &model.CreateProjectOutput{Body: toProjectResponse(p)}, nil
+}
+
+// UpdateProject は指定されたIDのプロジェクトを更新する
+func (h *ProjectHandler) UpdateProject(ctx context.Context, input *model.UpdateProjectInput) (*model.UpdateProjectOutput, error) {
+	metadataSchema, err := encodeMetadataSchema(input.Body.MetadataSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := h.queries.UpdateProject(ctx, db.UpdateProjectParams{
+		Name:           input.Body.Name,
+		Description:    ptrStringToNullString(input.Body.Description),
+		MetadataSchema: metadataSchema,
+		WipLimit:       ptrInt64ToNullInt64(input.Body.WIPLimit),
+		ID:             input.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, projectNotFound(input.ID)
+		}
+		slog.Warn("プロジェクト更新に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("プロジェクト更新に失敗", err)
+	}
+
+	return &model.UpdateProjectOutput{Body: toProjectResponse(p)}, nil
+}
+
+// confirmDeleteProject はプロジェクトの削除を確認トークンで確認する操作の名前
+const confirmDeleteProject = "delete-project"
+
+// projectDeletionImpact はプロジェクトの削除で一緒に削除されるTodoとサブタスクの件数を返す
+func projectDeletionImpact(todos []db.Todo) confirm.Impact {
+	impact := confirm.Impact{"todos": int64(len(todos)), "subtasks": 0}
+	for _, t := range todos {
+		impact["subtasks"] += t.SubtaskCount
+	}
+	return impact
+}
+
+// ensureProjectTodosDeletable はプロジェクトと一緒に削除されるTodoを、認証したユーザーが削除できることを確認する。
+// プロジェクトはユーザーをまたいで使われるため、他のユーザーのTodoを含むプロジェクトの削除はadminロールに限る。
+func ensureProjectTodosDeletable(ctx context.Context, id int64, todos []db.Todo) error {
+	if role, _ := auth.RoleFrom(ctx); role.Allows(auth.RoleAdmin) {
+		return nil
+	}
+	user := ownerID(ctx)
+	if slices.ContainsFunc(todos, func(t db.Todo) bool { return t.OwnerID != user }) {
+		slog.Warn("他のユーザーのTodoを含むプロジェクトは削除できません", "id", id)
+		return huma.Error403Forbidden("他のユーザーのTodoを含むプロジェクトの削除にはadminロールが必要です。mode=archiveでアーカイブしてください")
+	}
+	return nil
+}
+
+// DeleteProject は指定されたIDのプロジェクトをアーカイブまたは削除する。
+// deleteモードでは所属するTodoも同じトランザクションで削除される。他のユーザーのTodoを含む場合はadminロールのみ削除できる。
+// 取り消せないため、確認トークンを省略した場合は削除せずに影響範囲と確認トークンを202で返す。
+func (h *ProjectHandler) DeleteProject(ctx context.Context, input *model.DeleteProjectInput) (*model.DeleteProjectOutput, error) {
+	output := &model.DeleteProjectOutput{Status: http.StatusOK}
+
+	if input.Mode == ProjectDeleteModeArchive {
+		n, err := h.queries.ArchiveProject(ctx, input.ID)
+		if err != nil {
+			slog.Warn("プロジェクトのアーカイブに失敗", "err", err)
+			return nil, huma.Error500InternalServerError("プロジェクトのアーカイブに失敗", err)
+		}
+		if n == 0 {
+			// 既にアーカイブ済みの場合は存在確認のみ行う
+			if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+				return nil, err
+			}
+		}
+		output.Body.Message = "Project archived successfully"
+		return output, nil
+	}
+
+	target := strconv.FormatInt(input.ID, 10)
+	if input.ConfirmationToken == "" {
+		if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+			return nil, err
+		}
+		todos, err := h.queries.ListTodosByProject(ctx, sql.NullInt64{Int64: input.ID, Valid: true})
+		if err != nil {
+			slog.Warn("プロジェクトのTodo取得に失敗", "err", err)
+			return nil, huma.Error500InternalServerError("プロジェクトのTodo取得に失敗", err)
+		}
+		if err := ensureProjectTodosDeletable(ctx, input.ID, todos); err != nil {
+			return nil, err
+		}
+		confirmation, err := issueConfirmation(ctx, h.confirmer, confirmDeleteProject, target, projectDeletionImpact(todos))
+		if err != nil {
+			return nil, err
+		}
+		output.Status = http.StatusAccepted
+		output.Body.Message = "Confirmation required"
+		output.Body.Confirmation = confirmation
+		return output, nil
+	}
+
+	var deleted []db.Todo
+	err := runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		var err error
+		deleted, err = qtx.ListTodosByProject(ctx, sql.NullInt64{Int64: input.ID, Valid: true})
+		if err != nil {
+			slog.Warn("プロジェクトのTodo取得に失敗", "err", err)
+			return huma.Error500InternalServerError("プロジェクトのTodo取得に失敗", err)
+		}
+		// 確認の後に他のユーザーがTodoを追加した場合も削除しないよう、削除と同じトランザクションで確認し直す
+		if err := ensureProjectTodosDeletable(ctx, input.ID, deleted); err != nil {
+			return err
+		}
+		if err := consumeConfirmation(ctx, h.confirmer, qtx, input.ConfirmationToken, confirmDeleteProject, target, projectDeletionImpact(deleted)); err != nil {
+			return err
+		}
+
+		n, err := qtx.DeleteProject(ctx, input.ID)
+		if err != nil {
+			slog.Warn("プロジェクト削除に失敗", "err", err)
+			return huma.Error500InternalServerError("プロジェクト削除に失敗", err)
+		}
+		if n == 0 {
+			return projectNotFound(input.ID)
+		}
+		return recordDeletions(ctx, qtx, deleted)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range deleted {
+		h.bus.Publish(event.Event{Type: event.TodoDeleted, TodoID: t.ID, OwnerID: t.OwnerID})
+	}
+
+	output.Body.Message = "Project deleted successfully"
+	return output, nil
+}
+
+// UnarchiveProject は指定されたIDのアーカイブ済みプロジェクトを元に戻す。
+// アーカイブされていない場合は何もしない。
+func (h *ProjectHandler) UnarchiveProject(ctx context.Context, input *model.UnarchiveProjectInput) (*model.UnarchiveProjectOutput, error) {
+	if _, err := h.queries.UnarchiveProject(ctx, input.ID); err != nil {
+		slog.Warn("プロジェクトのアーカイブ解除に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("プロジェクトのアーカイブ解除に失敗", err)
+	}
+
+	p, err := getProject(ctx, h.queries, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UnarchiveProjectOutput{Body: toProjectResponse(p)}, nil
+}
+
+// ListProjectTodos は指定されたプロジェクトに所属するTodoのうち、所有するか共有されているものの一覧を取得する
+func (h *ProjectHandler) ListProjectTodos(ctx context.Context, input *model.ListProjectTodosInput) (*model.ListProjectTodosOutput, error) {
+	if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+
+	todos, err := h.queries.ListVisibleTodosByProject(ctx, db.ListVisibleTodosByProjectParams{
+		ProjectID: sql.NullInt64{Int64: input.ID, Valid: true},
+		UserID:    ownerID(ctx),
+	})
+	if err != nil {
+		slog.Warn("プロジェクトのTodo取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("プロジェクトのTodo取得に失敗", err)
+	}
+
+	output := &model.ListProjectTodosOutput{}
+	output.Body.Todos = make([]model.TodoResponse, len(todos))
+	for i, t := range todos {
+		output.Body.Todos[i] = toTodoResponse(ctx, t)
+	}
+	if err := expandTodos(ctx, h.queries, output.Body.Todos, input.Expand); err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-huma-test/actionlink"
+	"go-huma-test/activity"
+	"go-huma-test/audit"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"go-huma-test/notify"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// snoozeDay は1日後に再通知する操作で、新しいリマインダーを通知するまでの時間
+const snoozeDay = 24 * time.Hour
+
+// QuickActionHandler はリマインダーなどの通知に含めた、1回だけ使えるリンクによる操作を処理するハンドラー。
+// リンクのトークンが操作するユーザーを表すため、リクエストの認証は行わない。
+type QuickActionHandler struct {
+	queries *db.Queries
+	db      *sql.DB
+	bus     *event.Bus
+	links   *actionlink.Signer
+	clock   clock.Clock
+}
+
+// NewQuickActionHandler はQuickActionHandlerの新しいインスタンスを生成する
+func NewQuickActionHandler(queries *db.Queries, db *sql.DB, bus *event.Bus, links *actionlink.Signer, clock clock.Clock) *QuickActionHandler {
+	return &QuickActionHandler{
+		queries: queries,
+		db:      db,
+		bus:     bus,
+		links:   links,
+		clock:   clock,
+	}
+}
+
+// PerformQuickAction はリンクのトークンが表す操作をTodoに行う。
+// リンクの使用は操作と同じトランザクションで記録し、操作に失敗した場合は使用済みにしない。
+// 使用済みのリンクは410を返す。
+func (h *QuickActionHandler) PerformQuickAction(ctx context.Context, input *model.QuickActionInput) (*model.QuickActionOutput, error) {
+	claims, err := h.links.Parse(ctx, input.Token)
+	// 放置されたTodoの通知のリンクはIDを持たず何度でも使えるため、ここでは受け付けない
+	if errors.Is(err, actionlink.ErrInvalidToken) || (err == nil && claims.ID == "") {
+		slog.Warn("ワンクリック操作のリンクのトークンが無効です")
+		return nil, huma.Error404NotFound(actionlink.ErrInvalidToken.Error())
+	}
+	if err != nil {
+		slog.Warn("ワンクリック操作のリンクのトークンの検証に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("リンクのトークンの検証に失敗", err)
+	}
+	ctx = linkUserContext(ctx, claims)
+
+	output := &model.QuickActionOutput{}
+	output.Body.Action = claims.Action
+	var (
+		todo db.Todo
+		evt  event.Type
+	)
+	err = runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		client := audit.ClientFrom(ctx)
+		n, err := qtx.UseActionLink(ctx, db.UseActionLinkParams{
+			TokenID:   claims.ID,
+			Action:    claims.Action,
+			TodoID:    claims.TodoID,
+			UserID:    sql.NullInt64{Int64: claims.UserID, Valid: claims.UserID != 0},
+			Ip:        client.IP,
+			UserAgent: client.UserAgent,
+		})
+		if err != nil {
+			slog.Warn("リンクの使用の記録に失敗", "todo_id", claims.TodoID, "err", err)
+			return huma.Error500InternalServerError("リンクの使用の記録に失敗", err)
+		}
+		if n == 0 {
+			slog.Warn("使用済みのリンクです", "action", claims.Action, "todo_id", claims.TodoID, "user_id", claims.UserID, "ip", client.IP)
+			return huma.Error410Gone("このリンクは既に使用されています")
+		}
+
+		before, err := getUnlockedTodo(ctx, qtx, claims.TodoID, h.clock.Now())
+		if err != nil {
+			return err
+		}
+		todo = before
+
+		switch claims.Action {
+		case actionlink.ActionComplete:
+			if before.Completed != 0 {
+				output.Body.Message = "Todo already completed"
+				return nil
+			}
+			if todo, err = qtx.ToggleTodoCompleted(ctx, before.ID); err != nil {
+				slog.Warn("Todoの完了に失敗", "todo_id", before.ID, "err", err)
+				return huma.Error500InternalServerError("Todoの完了に失敗", err)
+			}
+			output.Body.Message = "Todo completed"
+			evt = event.TodoUpdated
+			if err := recordActivity(ctx, qtx, activity.ActionToggle, &before, &todo); err != nil {
+				return err
+			}
+			return recordEvent(ctx, qtx, event.Event{Type: evt, TodoID: todo.ID, OwnerID: todo.OwnerID, Completed: true})
+		case actionlink.ActionSnoozeDay:
+			channel := claims.Channel
+			if channel == "" {
+				channel = notify.ChannelLog
+			}
+			reminder, err := qtx.CreateReminder(ctx, db.CreateReminderParams{
+				TodoID:   before.ID,
+				RemindAt: h.clock.Now().UTC().Add(snoozeDay).Truncate(time.Second),
+				Channel:  channel,
+			})
+			if err != nil {
+				slog.Warn("リマインダーの作成に失敗", "todo_id", before.ID, "err", err)
+				return huma.Error500InternalServerError("リマインダーの作成に失敗", err)
+			}
+			remindAt := reminder.RemindAt.UTC().Format(time.RFC3339)
+			output.Body.RemindAt = &remindAt
+			output.Body.Message = "Todo snoozed for 1 day"
+			return nil
+		case actionlink.ActionDelete:
+			if err := qtx.DeleteTodo(ctx, before.ID); err != nil {
+				slog.Warn("Todo削除に失敗", "todo_id", before.ID, "err", err)
+				return huma.Error500InternalServerError("Todo削除に失敗", err)
+			}
+			output.Body.Message = "Todo deleted"
+			evt = event.TodoDeleted
+			if err := recordActivity(ctx, qtx, activity.ActionDelete, &before, nil); err != nil {
+				return err
+			}
+			return recordEvent(ctx, qtx, event.Event{Type: evt, TodoID: todo.ID, OwnerID: todo.OwnerID})
+		default:
+			slog.Warn("リンクの操作の種類に対応していません", "action", claims.Action)
+			return huma.Error404NotFound(actionlink.ErrInvalidToken.Error())
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if evt != "" {
+		h.bus.Publish(event.Event{Type: evt, TodoID: todo.ID, OwnerID: todo.OwnerID, Completed: claims.Action == actionlink.ActionComplete})
+	}
+	slog.Info("リンクからTodoを操作", "action", claims.Action, "todo_id", todo.ID, "user_id", claims.UserID, "ip", audit.ClientFrom(ctx).IP)
+	output.Body.Todo = toTodoResponse(ctx, todo)
+	return output, nil
+}
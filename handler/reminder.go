@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ReminderHandler はTodoに紐づくリマインダーの操作を処理するハンドラー
+type ReminderHandler struct {
+	queries *db.Queries
+}
+
+// NewReminderHandler はReminderHandlerの新しいインスタンスを生成する
+func NewReminderHandler(queries *db.Queries) *ReminderHandler {
+	return &ReminderHandler{
+		queries: queries,
+	}
+}
+
+// toReminderResponse はdb.Reminderをmodel.ReminderResponseに変換する
+func toReminderResponse(r db.Reminder) model.ReminderResponse {
+	return model.ReminderResponse{
+		ID:        r.ID,
+		TodoID:    r.TodoID,
+		RemindAt:  r.RemindAt.Format(time.RFC3339),
+		Channel:   r.Channel,
+		SentAt:    nullTimeToPtr(r.SentAt),
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ListReminders は指定されたTodoのリマインダー一覧を取得する
+func (h *ReminderHandler) ListReminders(ctx context.Context, input *model.ListRemindersInput) (*model.ListRemindersOutput, error) {
+	if err := ensureTodoExists(ctx, h.queries, input.TodoID); err != nil {
+		return nil, err
+	}
+
+	reminders, err := h.queries.ListReminders(ctx, input.TodoID)
+	if err != nil {
+		slog.Warn("リマインダー一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("リマインダー一覧の取得に失敗", err)
+	}
+
+	output := &model.ListRemindersOutput{}
+	output.Body.Reminders = make([]model.ReminderResponse, len(reminders))
+	for i, r := range reminders {
+		output.Body.Reminders[i] = toReminderResponse(r)
+	}
+
+	return output, nil
+}
+
+// CreateReminder は指定されたTodoにリマインダーを追加する
+func (h *ReminderHandler) CreateReminder(ctx context.Context, input *model.CreateReminderInput) (*model.CreateReminderOutput, error) {
+	if err := ensureTodoWritable(ctx, h.queries, input.TodoID); err != nil {
+		return nil, err
+	}
+
+	reminder, err := h.queries.CreateReminder(ctx, db.CreateReminderParams{
+		TodoID:   input.TodoID,
+		RemindAt: input.Body.RemindAt.UTC(),
+		Channel:  input.Body.Channel,
+	})
+	if err != nil {
+		slog.Warn("リマインダー作成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("リマインダー作成に失敗", err)
+	}
+
+	return &model.CreateReminderOutput{Body: toReminderResponse(reminder)}, nil
+}
+
+// DeleteReminder は指定されたIDのリマインダーを削除する
+func (h *ReminderHandler) DeleteReminder(ctx context.Context, input *model.DeleteReminderInput) (*model.DeleteReminderOutput, error) {
+	if err := ensureTodoWritable(ctx, h.queries, input.TodoID); err != nil {
+		return nil, err
+	}
+
+	n, err := h.queries.DeleteReminder(ctx, db.DeleteReminderParams{
+		ID:     input.ReminderID,
+		TodoID: input.TodoID,
+	})
+	if err != nil {
+		slog.Warn("リマインダー削除に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("リマインダー削除に失敗", err)
+	}
+	if n == 0 {
+		return nil, huma.Error404NotFound(fmt.Sprintf("リマインダーIDが見つかりません: todo=%d reminder=%d", input.TodoID, input.ReminderID))
+	}
+
+	output := &model.DeleteReminderOutput{}
+	output.Body.Message = "Reminder deleted successfully"
+	return output, nil
+}
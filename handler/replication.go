@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// replicationStatusPath は複製の状態を返すパス。レプリカはプライマリの同じパスを参照する。
+const replicationStatusPath = "/replication/status"
+
+// ReplicationHandler は複製の状態を処理するハンドラー。
+// 変更はアクティビティログのIDで数え、レプリカに反映済みの最後のIDをプライマリと比較して遅延を求める。
+type ReplicationHandler struct {
+	queries    *db.Queries
+	readOnly   bool
+	primaryURL string
+	source     string
+	maxLag     time.Duration
+	client     *http.Client
+	now        func() time.Time
+}
+
+// NewReplicationHandler はReplicationHandlerの新しいインスタンスを生成する。
+// readOnlyの場合はレプリカとして、primaryURLのプライマリに対する遅延がmaxLagを超えると503を返す。
+func NewReplicationHandler(queries *db.Queries, readOnly bool, primaryURL, source string, maxLag time.Duration) *ReplicationHandler {
+	return &ReplicationHandler{
+		queries:    queries,
+		readOnly:   readOnly,
+		primaryURL: strings.TrimSuffix(primaryURL, "/"),
+		source:     source,
+		maxLag:     maxLag,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		now:        time.Now,
+	}
+}
+
+// GetReplicationStatus はこのインスタンスに反映済みの最後の変更を返す。
+// レプリカの場合はプライマリの最後の変更と比較した遅延と、データベースの復元元も返す。
+// プライマリに接続できない場合は遅延が分からないため、古いとは判定せずにエラーを含めて200を返す。
+func (h *ReplicationHandler) GetReplicationStatus(ctx context.Context, input *model.GetReplicationStatusInput) (*model.GetReplicationStatusOutput, error) {
+	output := &model.GetReplicationStatusOutput{Status: http.StatusOK}
+	status := &output.Body
+	status.Role = model.RolePrimary
+
+	last, err := h.queries.GetLatestActivity(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Warn("最後の変更の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("最後の変更の取得に失敗", err)
+	}
+	if err == nil {
+		status.LastChange = replicationChange(last)
+	}
+
+	next, err := h.queries.GetNextActivity(ctx, input.After)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Warn("未反映の変更の取得に失敗", "after", input.After, "err", err)
+		return nil, huma.Error500InternalServerError("未反映の変更の取得に失敗", err)
+	}
+	if err == nil {
+		status.NextChange = replicationChange(next)
+	}
+
+	if !h.readOnly {
+		return output, nil
+	}
+
+	status.Role = model.RoleReplica
+	status.RestoreSource = h.source
+	if h.primaryURL == "" {
+		return output, nil
+	}
+
+	status.Primary = &model.ReplicationPrimary{URL: h.primaryURL}
+	primary, err := h.fetchPrimary(ctx, last.ID)
+	if err != nil {
+		slog.Warn("プライマリの状態の取得に失敗", "primary", h.primaryURL, "err", err)
+		status.Primary.Error = err.Error()
+		return output, nil
+	}
+	status.Primary.LastChange = primary.LastChange
+
+	if primary.LastChange != nil && primary.LastChange.ID > last.ID {
+		status.LagChanges = primary.LastChange.ID - last.ID
+		if primary.NextChange != nil {
+			if at, err := time.Parse(time.RFC3339, primary.NextChange.At); err == nil {
+				status.LagSeconds = max(h.now().Sub(at).Seconds(), 0)
+			}
+		}
+	}
+	if status.LagSeconds > h.maxLag.Seconds() {
+		status.Stale = true
+		output.Status = http.StatusServiceUnavailable
+	}
+
+	return output, nil
+}
+
+// fetchPrimary はプライマリの複製の状態を取得する。
+// afterにはレプリカに反映済みの最後の変更のIDを渡し、未反映の最も古い変更をnext_changeで受け取る。
+func (h *ReplicationHandler) fetchPrimary(ctx context.Context, after int64) (*model.ReplicationStatus, error) {
+	u := h.primaryURL + replicationStatusPath + "?" + url.Values{"after": {strconv.FormatInt(after, 10)}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("リクエストの作成に失敗: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("プライマリに接続できません: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// プライマリ自体が古いレプリカの場合は503を返すが、状態は本文に含まれる
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil, fmt.Errorf("プライマリがエラーを返しました: %s", resp.Status)
+	}
+
+	var status model.ReplicationStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("プライマリの応答を解析できません: %w", err)
+	}
+	return &status, nil
+}
+
+// replicationChange はアクティビティログの行を変更のレスポンスに変換する
+func replicationChange(a db.ActivityLog) *model.ReplicationChange {
+	return &model.ReplicationChange{
+		ID: a.ID,
+		At: a.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
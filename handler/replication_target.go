@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"go-huma-test/model"
+	"go-huma-test/replication"
+	"net/http"
+	"time"
+)
+
+// ReplicationTargetHandler はデータベースのスナップショットを別の場所に複製する状態を処理するハンドラー
+type ReplicationTargetHandler struct {
+	replicator *replication.Replicator
+	now        func() time.Time
+}
+
+// NewReplicationTargetHandler はReplicationTargetHandlerの新しいインスタンスを生成する。複製しない場合はreplicatorにnilを渡す。
+func NewReplicationTargetHandler(replicator *replication.Replicator) *ReplicationTargetHandler {
+	return &ReplicationTargetHandler{
+		replicator: replicator,
+		now:        time.Now,
+	}
+}
+
+// GetReplicationTarget は複製先と最後の複製の結果を返す。
+// 複製先の内容が間隔の2倍より古い場合は、監視から検知できるよう503を返す。
+func (h *ReplicationTargetHandler) GetReplicationTarget(_ context.Context, _ *struct{}) (*model.GetReplicationTargetOutput, error) {
+	output := &model.GetReplicationTargetOutput{Status: http.StatusOK}
+	if h.replicator == nil {
+		return output, nil
+	}
+
+	s := h.replicator.Status()
+	status := &output.Body
+	status.Enabled = true
+	status.Target = s.Target
+	status.Interval = s.Interval.String()
+	status.LastError = s.LastError
+	status.ConsecutiveFailures = s.ConsecutiveFailures
+	status.LastAttemptAt = optionalTime(s.LastAttemptAt)
+	status.LastSuccessAt = optionalTime(s.LastSuccessAt)
+	status.NextAt = optionalTime(s.NextAt)
+	if s.LastSnapshot != nil {
+		status.LastSnapshot = &model.ReplicationSnapshotResponse{
+			Key:       s.LastSnapshot.Key,
+			Size:      s.LastSnapshot.Size,
+			CreatedAt: s.LastSnapshot.CreatedAt,
+		}
+	}
+
+	// 起動してからまだ複製していない場合は、複製先の最新のスナップショットの日時で判定する
+	upToDateAt := s.LastSuccessAt
+	if upToDateAt.IsZero() && s.LastSnapshot != nil {
+		upToDateAt = s.LastSnapshot.CreatedAt
+	}
+	if s.ConsecutiveFailures > 0 && h.now().Sub(upToDateAt) > 2*s.Interval {
+		status.Stale = true
+		output.Status = http.StatusServiceUnavailable
+	}
+	return output, nil
+}
+
+// optionalTime はゼロ値の日時を省略するためにnilにする
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-huma-test/model"
+	"go-huma-test/repository"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// RepositoryHandler はTodoRepositoryに保存したTodoの操作を処理するハンドラー。
+// PostgreSQLやインメモリのように、SQLiteのスキーマを持たない保存先でTodoの基本的な操作を提供する。
+// レスポンスはTodoHandlerと同じ形式で、保存先にない項目は省略するかゼロ値になる。
+type RepositoryHandler struct {
+	repo    repository.TodoRepository
+	timeout time.Duration
+}
+
+// NewRepositoryHandler はRepositoryHandlerの新しいインスタンスを生成する。
+// 準備状態の確認はtimeout以内に保存先に接続できない場合に失敗とする。
+func NewRepositoryHandler(repo repository.TodoRepository, timeout time.Duration) *RepositoryHandler {
+	return &RepositoryHandler{repo: repo, timeout: timeout}
+}
+
+// toRepositoryTodoResponse はrepository.Todoをmodel.TodoResponseに変換する
+func toRepositoryTodoResponse(t repository.Todo) model.TodoResponse {
+	description := nullStringToString(t.Description)
+	return model.TodoResponse{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: &description,
+		Completed:   t.Completed,
+		DueAt:       nullTimeToPtr(t.DueAt),
+		OwnerID:     nullInt64ToPtr(t.OwnerID),
+		Priority:    t.Priority,
+		CreatedAt:   t.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   t.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// repositoryError はリポジトリのエラーをレスポンスのエラーに変換する
+func repositoryError(err error, id int64, msg string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return huma.Error404NotFound(fmt.Sprintf("Todo IDが見つかりません: %d", id))
+	}
+	slog.Warn(msg, "id", id, "err", err)
+	return huma.Error500InternalServerError(msg, err)
+}
+
+// ListTodos は認証したユーザーのTodoのうち、完了状態が一致するものを作成日時の新しい順に返す
+func (h *RepositoryHandler) ListTodos(ctx context.Context, input *model.ListRepositoryTodosInput) (*model.ListTodosOutput, error) {
+	todos, err := h.repo.ListTodos(ctx, repository.ListTodosParams{
+		OwnerID:   ownerID(ctx),
+		Completed: input.Completed,
+	})
+	if err != nil {
+		slog.Warn("todoリストの取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("Todoリストの取得に失敗", err)
+	}
+
+	output := &model.ListTodosOutput{}
+	output.Body.Todos = make([]model.TodoResponse, len(todos))
+	for i, t := range todos {
+		output.Body.Todos[i] = toRepositoryTodoResponse(t)
+	}
+	return output, nil
+}
+
+// GetTodo は指定されたIDのTodoを取得する
+func (h *RepositoryHandler) GetTodo(ctx context.Context, input *model.RepositoryTodoInput) (*model.RepositoryTodoOutput, error) {
+	todo, err := h.repo.GetTodo(ctx, input.ID, ownerID(ctx))
+	if err != nil {
+		return nil, repositoryError(err, input.ID, "Todo取得に失敗")
+	}
+	return &model.RepositoryTodoOutput{LastModified: todo.UpdatedAt.UTC(), Body: toRepositoryTodoResponse(todo)}, nil
+}
+
+// CreateTodo は新しいTodoを作成する
+func (h *RepositoryHandler) CreateTodo(ctx context.Context, input *model.CreateRepositoryTodoInput) (*model.CreateTodoOutput, error) {
+	todo, err := h.repo.CreateTodo(ctx, repository.CreateTodoParams{
+		OwnerID:     ownerID(ctx),
+		Title:       input.Body.Title,
+		Description: ptrStringToNullString(input.Body.Description),
+		Priority:    input.Body.Priority,
+		DueAt:       ptrTimeToNullTime(input.Body.DueAt),
+	})
+	if err != nil {
+		slog.Warn("Todo作成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("Todo作成に失敗", err)
+	}
+	return &model.CreateTodoOutput{Body: toRepositoryTodoResponse(todo)}, nil
+}
+
+// UpdateTodo は指定されたIDのTodoの内容を置き換える
+func (h *RepositoryHandler) UpdateTodo(ctx context.Context, input *model.UpdateRepositoryTodoInput) (*model.RepositoryTodoOutput, error) {
+	todo, err := h.repo.UpdateTodo(ctx, repository.UpdateTodoParams{
+		ID:          input.ID,
+		OwnerID:     ownerID(ctx),
+		Title:       input.Body.Title,
+		Description: ptrStringToNullString(input.Body.Description),
+		Completed:   input.Body.Completed,
+		Priority:    input.Body.Priority,
+		DueAt:       ptrTimeToNullTime(input.Body.DueAt),
+	})
+	if err != nil {
+		return nil, repositoryError(err, input.ID, "Todo更新に失敗")
+	}
+	return &model.RepositoryTodoOutput{LastModified: todo.UpdatedAt.UTC(), Body: toRepositoryTodoResponse(todo)}, nil
+}
+
+// ToggleTodo は指定されたIDのTodoの完了状態を切り替える
+func (h *RepositoryHandler) ToggleTodo(ctx context.Context, input *model.RepositoryTodoInput) (*model.RepositoryTodoOutput, error) {
+	todo, err := h.repo.ToggleTodo(ctx, input.ID, ownerID(ctx))
+	if err != nil {
+		return nil, repositoryError(err, input.ID, "Todoのトグルに失敗")
+	}
+	return &model.RepositoryTodoOutput{LastModified: todo.UpdatedAt.UTC(), Body: toRepositoryTodoResponse(todo)}, nil
+}
+
+// DeleteTodo は指定されたIDのTodoを削除する。TodoHandlerと同じく、存在しないIDの削除も成功として扱う
+func (h *RepositoryHandler) DeleteTodo(ctx context.Context, input *model.RepositoryTodoInput) (*model.DeleteTodoOutput, error) {
+	err := h.repo.DeleteTodo(ctx, input.ID, ownerID(ctx))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, repositoryError(err, input.ID, "Todo削除に失敗")
+	}
+
+	output := &model.DeleteTodoOutput{}
+	output.Body.Message = "Todo deleted successfully"
+	return output, nil
+}
+
+// Liveness はプロセスが応答できることを返す。HealthHandlerと同じく、認証せずに呼び出せるようServeMuxに直接登録する
+func (h *RepositoryHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, HealthStatus{Status: healthOK})
+}
+
+// Readiness は保存先に接続できることを確認する
+func (h *RepositoryHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	if err := h.repo.Ping(ctx); err != nil {
+		slog.Warn("データベースに接続できません", "err", err)
+		writeHealth(w, http.StatusServiceUnavailable, HealthStatus{Status: healthFailed, Checks: map[string]string{"database": healthFailed}})
+		return
+	}
+	writeHealth(w, http.StatusOK, HealthStatus{Status: healthOK, Checks: map[string]string{"database": healthOK}})
+}
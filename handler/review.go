@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-huma-test/actionlink"
+	"go-huma-test/activity"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ReviewHandler は放置されたTodoの通知に含めたリンクによる、アーカイブと通知の延期を処理するハンドラー。
+// リンクのトークンが操作するユーザーを表すため、リクエストの認証は行わない。
+type ReviewHandler struct {
+	queries *db.Queries
+	db      *sql.DB
+	bus     *event.Bus
+	links   *actionlink.Signer
+	snooze  time.Duration
+	clock   clock.Clock
+}
+
+// NewReviewHandler はReviewHandlerの新しいインスタンスを生成する。通知の延期はsnoozeの間だけ行う。
+func NewReviewHandler(queries *db.Queries, db *sql.DB, bus *event.Bus, links *actionlink.Signer, snooze time.Duration, clock clock.Clock) *ReviewHandler {
+	return &ReviewHandler{
+		queries: queries,
+		db:      db,
+		bus:     bus,
+		links:   links,
+		snooze:  snooze,
+		clock:   clock,
+	}
+}
+
+// verify はリンクのトークンを検証し、トークンのユーザーとして操作するcontextを返す
+func (h *ReviewHandler) verify(ctx context.Context, action, token string) (context.Context, actionlink.Claims, error) {
+	claims, err := h.links.Verify(ctx, action, token)
+	if errors.Is(err, actionlink.ErrInvalidToken) {
+		slog.Warn("リンクのトークンが無効です", "action", action)
+		return nil, actionlink.Claims{}, huma.Error404NotFound(err.Error())
+	}
+	if err != nil {
+		slog.Warn("リンクのトークンの検証に失敗", "action", action, "err", err)
+		return nil, actionlink.Claims{}, huma.Error500InternalServerError("リンクのトークンの検証に失敗", err)
+	}
+	return linkUserContext(ctx, claims), claims, nil
+}
+
+// linkUserContext はリンクのトークンが表すユーザーとして操作するcontextを返す。所有者のいないTodoのリンクはシステムとして操作する。
+func linkUserContext(ctx context.Context, claims actionlink.Claims) context.Context {
+	actor := activity.SystemActor
+	if claims.UserID != 0 {
+		ctx = auth.WithUserID(ctx, claims.UserID)
+		actor = auth.UserSubject(claims.UserID)
+	}
+	return activity.WithActor(ctx, actor)
+}
+
+// ArchiveStaleTodo はリンクのTodoをアーカイブする。既にアーカイブ済みの場合は何もしない。
+func (h *ReviewHandler) ArchiveStaleTodo(ctx context.Context, input *model.ReviewActionInput) (*model.ReviewActionOutput, error) {
+	ctx, claims, err := h.verify(ctx, actionlink.ActionArchive, input.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &model.ReviewActionOutput{}
+	todo, err := setTodoArchived(ctx, h.db, h.queries, h.bus, claims.TodoID, true, nil, h.clock.Now())
+	switch {
+	case errors.Is(err, errAlreadyArchived):
+		output.Body.Message = "Todo already archived"
+	case err != nil:
+		return nil, err
+	default:
+		slog.Info("リンクからTodoをアーカイブ", "todo_id", todo.ID, "user_id", claims.UserID)
+		output.Body.Message = "Todo archived"
+	}
+	output.Body.Todo = toTodoResponse(ctx, todo)
+	return output, nil
+}
+
+// SnoozeStaleTodo はリンクのTodoを、延期の期間が過ぎるまで放置されたTodoの通知に含めないようにする
+func (h *ReviewHandler) SnoozeStaleTodo(ctx context.Context, input *model.ReviewActionInput) (*model.ReviewActionOutput, error) {
+	ctx, claims, err := h.verify(ctx, actionlink.ActionSnooze, input.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.clock.Now()
+	until := now.UTC().Add(h.snooze)
+	var todo db.Todo
+	err = runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		var err error
+		todo, err = getUnlockedTodo(ctx, qtx, claims.TodoID, now)
+		if err != nil {
+			return err
+		}
+		if err := qtx.SnoozeTodo(ctx, db.SnoozeTodoParams{TodoID: todo.ID, SnoozedUntil: until}); err != nil {
+			slog.Warn("通知の延期に失敗", "todo_id", todo.ID, "err", err)
+			return huma.Error500InternalServerError("通知の延期に失敗", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("リンクから放置されたTodoの通知を延期", "todo_id", todo.ID, "user_id", claims.UserID, "until", until)
+
+	output := &model.ReviewActionOutput{}
+	output.Body.Message = "Todo snoozed"
+	snoozedUntil := until.Format(time.RFC3339)
+	output.Body.SnoozedUntil = &snoozedUntil
+	output.Body.Todo = toTodoResponse(ctx, todo)
+	return output, nil
+}
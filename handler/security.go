@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"go-huma-test/audit"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// SecurityHandler は認証に関するセキュリティ情報を処理するハンドラー
+type SecurityHandler struct {
+	queries *db.Queries
+}
+
+// NewSecurityHandler はSecurityHandlerの新しいインスタンスを生成する
+func NewSecurityHandler(queries *db.Queries) *SecurityHandler {
+	return &SecurityHandler{
+		queries: queries,
+	}
+}
+
+// ListSecurityEvents はリクエストの資格情報に紐づく認証イベントを新しい順に取得する
+func (h *SecurityHandler) ListSecurityEvents(ctx context.Context, input *model.ListSecurityEventsInput) (*model.ListSecurityEventsOutput, error) {
+	credential := input.Authorization
+	if credential == "" {
+		credential = input.APIKey
+	}
+	subject := audit.Subject(credential)
+
+	events, err := h.queries.ListAuthEvents(ctx, db.ListAuthEventsParams{
+		Subject: subject,
+		Limit:   input.Limit,
+	})
+	if err != nil {
+		slog.Warn("認証イベント一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("認証イベント一覧の取得に失敗", err)
+	}
+
+	output := &model.ListSecurityEventsOutput{}
+	output.Body.Subject = subject
+	output.Body.Events = make([]model.AuthEventResponse, len(events))
+	for i, e := range events {
+		output.Body.Events[i] = model.AuthEventResponse{
+			ID:        e.ID,
+			EventType: e.EventType,
+			IP:        e.Ip,
+			UserAgent: e.UserAgent,
+			CreatedAt: e.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return output, nil
+}
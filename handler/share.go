@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/auth"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ShareHandler はTodoとプロジェクトの共有を処理するハンドラー
+type ShareHandler struct {
+	queries *db.Queries
+}
+
+// NewShareHandler はShareHandlerの新しいインスタンスを生成する
+func NewShareHandler(queries *db.Queries) *ShareHandler {
+	return &ShareHandler{
+		queries: queries,
+	}
+}
+
+// toShareResponse は共有先のユーザーを含む共有の情報をmodel.ShareResponseに変換する
+func toShareResponse(id, userID int64, email, permission string, createdAt time.Time) model.ShareResponse {
+	return model.ShareResponse{
+		ID:         id,
+		UserID:     userID,
+		Email:      email,
+		Permission: permission,
+		CreatedAt:  createdAt.Format(time.RFC3339),
+	}
+}
+
+// sharingUser は共有を操作する認証したユーザーのIDを返す。ユーザー以外の認証主体は共有を操作できない。
+func sharingUser(ctx context.Context) (int64, error) {
+	id, ok := auth.UserIDFrom(ctx)
+	if !ok {
+		slog.Warn("ユーザー以外の認証主体は共有を操作できません")
+		return 0, huma.Error403Forbidden("共有はユーザーとして認証した場合のみ操作できます")
+	}
+	return id, nil
+}
+
+// getOwnedTodo は認証したユーザーが所有する、指定されたIDのTodoを取得する。
+// 共有されているだけのTodoの共有は操作できないため403を返す。
+func getOwnedTodo(ctx context.Context, q *db.Queries, userID, id int64) (db.Todo, error) {
+	todo, err := getTodo(ctx, q, id)
+	if err != nil {
+		return db.Todo{}, err
+	}
+	if !todo.OwnerID.Valid || todo.OwnerID.Int64 != userID {
+		slog.Warn("Todoの所有者ではありません", "id", id, "user", userID)
+		return db.Todo{}, huma.Error403Forbidden(fmt.Sprintf("Todoの共有は所有者のみ操作できます: %d", id))
+	}
+	return todo, nil
+}
+
+// findShareTarget は共有先のユーザーをメールアドレスで取得する。自分自身とは共有できない。
+func findShareTarget(ctx context.Context, q *db.Queries, userID int64, email string) (db.User, error) {
+	email = strings.TrimSpace(email)
+	target, err := q.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("共有先のユーザーが見つかりません", "email", email)
+			return db.User{}, huma.Error422UnprocessableEntity(fmt.Sprintf("ユーザーが見つかりません: %s", email), &huma.ErrorDetail{
+				Location: "body.email",
+				Value:    email,
+			})
+		}
+		slog.Warn("ユーザーの取得に失敗", "err", err)
+		return db.User{}, huma.Error500InternalServerError("ユーザーの取得に失敗", err)
+	}
+	if target.ID == userID {
+		return db.User{}, huma.Error422UnprocessableEntity("自分自身とは共有できません", &huma.ErrorDetail{
+			Location: "body.email",
+			Value:    email,
+		})
+	}
+	return target, nil
+}
+
+// ListTodoShares は指定されたTodoの共有一覧を取得する
+func (h *ShareHandler) ListTodoShares(ctx context.Context, input *model.ListSharesInput) (*model.ListSharesOutput, error) {
+	userID, err := sharingUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getOwnedTodo(ctx, h.queries, userID, input.ID); err != nil {
+		return nil, err
+	}
+
+	shares, err := h.queries.ListTodoShares(ctx, sql.NullInt64{Int64: input.ID, Valid: true})
+	if err != nil {
+		slog.Warn("共有一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("共有一覧の取得に失敗", err)
+	}
+
+	output := &model.ListSharesOutput{}
+	output.Body.Shares = make([]model.ShareResponse, len(shares))
+	for i, s := range shares {
+		output.Body.Shares[i] = toShareResponse(s.ID, s.UserID, s.Email, s.Permission, s.CreatedAt)
+	}
+	return output, nil
+}
+
+// ShareTodo は指定されたTodoを他のユーザーと共有する。共有済みの場合は権限を更新する。
+func (h *ShareHandler) ShareTodo(ctx context.Context, input *model.CreateShareInput) (*model.CreateShareOutput, error) {
+	userID, err := sharingUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getOwnedTodo(ctx, h.queries, userID, input.ID); err != nil {
+		return nil, err
+	}
+	target, err := findShareTarget(ctx, h.queries, userID, input.Body.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := h.queries.ShareTodo(ctx, db.ShareTodoParams{
+		TodoID:     sql.NullInt64{Int64: input.ID, Valid: true},
+		UserID:     target.ID,
+		SharedBy:   userID,
+		Permission: input.Body.Permission,
+	})
+	if err != nil {
+		slog.Warn("Todoの共有に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("Todoの共有に失敗", err)
+	}
+
+	return &model.CreateShareOutput{Body: toShareResponse(s.ID, s.UserID, target.Email, s.Permission, s.CreatedAt)}, nil
+}
+
+// UnshareTodo は指定されたTodoの共有を解除する
+func (h *ShareHandler) UnshareTodo(ctx context.Context, input *model.DeleteShareInput) (*model.DeleteShareOutput, error) {
+	userID, err := sharingUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getOwnedTodo(ctx, h.queries, userID, input.ID); err != nil {
+		return nil, err
+	}
+
+	n, err := h.queries.DeleteTodoShare(ctx, db.DeleteTodoShareParams{
+		ID:     input.ShareID,
+		TodoID: sql.NullInt64{Int64: input.ID, Valid: true},
+	})
+	if err != nil {
+		slog.Warn("共有の解除に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("共有の解除に失敗", err)
+	}
+	if n == 0 {
+		return nil, huma.Error404NotFound(fmt.Sprintf("共有IDが見つかりません: todo=%d share=%d", input.ID, input.ShareID))
+	}
+
+	output := &model.DeleteShareOutput{}
+	output.Body.Message = "Share deleted successfully"
+	return output, nil
+}
+
+// ListProjectShares は認証したユーザーが指定されたプロジェクトで行っている共有の一覧を取得する
+func (h *ShareHandler) ListProjectShares(ctx context.Context, input *model.ListSharesInput) (*model.ListSharesOutput, error) {
+	userID, err := sharingUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+
+	shares, err := h.queries.ListProjectShares(ctx, db.ListProjectSharesParams{
+		ProjectID: sql.NullInt64{Int64: input.ID, Valid: true},
+		SharedBy:  userID,
+	})
+	if err != nil {
+		slog.Warn("共有一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("共有一覧の取得に失敗", err)
+	}
+
+	output := &model.ListSharesOutput{}
+	output.Body.Shares = make([]model.ShareResponse, len(shares))
+	for i, s := range shares {
+		output.Body.Shares[i] = toShareResponse(s.ID, s.UserID, s.Email, s.Permission, s.CreatedAt)
+	}
+	return output, nil
+}
+
+// ShareProject は認証したユーザーが指定されたプロジェクトに所有するTodoを、他のユーザーとまとめて共有する。
+// 共有後にプロジェクトへ追加したTodoも共有の対象になる。共有済みの場合は権限を更新する。
+func (h *ShareHandler) ShareProject(ctx context.Context, input *model.CreateShareInput) (*model.CreateShareOutput, error) {
+	userID, err := sharingUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getProject(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+	target, err := findShareTarget(ctx, h.queries, userID, input.Body.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := h.queries.ShareProject(ctx, db.ShareProjectParams{
+		ProjectID:  sql.NullInt64{Int64: input.ID, Valid: true},
+		UserID:     target.ID,
+		SharedBy:   userID,
+		Permission: input.Body.Permission,
+	})
+	if err != nil {
+		slog.Warn("プロジェクトの共有に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("プロジェクトの共有に失敗", err)
+	}
+
+	return &model.CreateShareOutput{Body: toShareResponse(s.ID, s.UserID, target.Email, s.Permission, s.CreatedAt)}, nil
+}
+
+// UnshareProject は認証したユーザーが指定されたプロジェクトで行っている共有を解除する
+func (h *ShareHandler) UnshareProject(ctx context.Context, input *model.DeleteShareInput) (*model.DeleteShareOutput, error) {
+	userID, err := sharingUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := h.queries.DeleteProjectShare(ctx, db.DeleteProjectShareParams{
+		ID:        input.ShareID,
+		ProjectID: sql.NullInt64{Int64: input.ID, Valid: true},
+		SharedBy:  userID,
+	})
+	if err != nil {
+		slog.Warn("共有の解除に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("共有の解除に失敗", err)
+	}
+	if n == 0 {
+		return nil, huma.Error404NotFound(fmt.Sprintf("共有IDが見つかりません: project=%d share=%d", input.ID, input.ShareID))
+	}
+
+	output := &model.DeleteShareOutput{}
+	output.Body.Message = "Share deleted successfully"
+	return output, nil
+}
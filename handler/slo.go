@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"go-huma-test/model"
+	"go-huma-test/slo"
+	"time"
+)
+
+// SLOHandler はSLOの達成状況を処理するハンドラー
+type SLOHandler struct {
+	tracker *slo.Tracker
+}
+
+// NewSLOHandler はSLOHandlerの新しいインスタンスを生成する
+func NewSLOHandler(tracker *slo.Tracker) *SLOHandler {
+	return &SLOHandler{
+		tracker: tracker,
+	}
+}
+
+// GetSLO は目標ごとのSLOの期間の達成率、残りのエラーバジェット、期間ごとのバーンレートとアラートの状態を返す
+func (h *SLOHandler) GetSLO(_ context.Context, _ *struct{}) (*model.GetSLOOutput, error) {
+	report := h.tracker.Report(time.Now())
+	output := &model.GetSLOOutput{Body: model.SLOStatus{
+		Window:     report.Window.String(),
+		Since:      report.Since.UTC(),
+		Objectives: []model.SLOObjective{},
+	}}
+	for _, o := range report.Objectives {
+		objective := model.SLOObjective{
+			Name:                 o.Name,
+			Target:               o.Target,
+			ThresholdMS:          o.Threshold.Milliseconds(),
+			Total:                o.Total,
+			Bad:                  o.Bad,
+			Compliance:           o.Compliance,
+			ErrorBudgetRemaining: o.BudgetRemaining,
+			BurnRates:            []model.SLOBurnRate{},
+			Alerts:               []model.SLOAlert{},
+		}
+		for _, b := range o.BurnRates {
+			objective.BurnRates = append(objective.BurnRates, model.SLOBurnRate{
+				Window: b.Window.String(),
+				Total:  b.Total,
+				Bad:    b.Bad,
+				Rate:   b.Rate,
+			})
+		}
+		for _, a := range o.Alerts {
+			objective.Alerts = append(objective.Alerts, model.SLOAlert{
+				Severity:    a.Severity,
+				LongWindow:  a.LongWindow.String(),
+				ShortWindow: a.ShortWindow.String(),
+				Threshold:   a.Threshold,
+				Firing:      a.Firing,
+			})
+		}
+		output.Body.Objectives = append(output.Body.Objectives, objective)
+	}
+	return output, nil
+}
@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// SubtaskHandler はTodoに紐づくサブタスクの操作を処理するハンドラー
+type SubtaskHandler struct {
+	queries *db.Queries
+	db      *sql.DB
+	bus     *event.Bus
+	clock   clock.Clock
+}
+
+// NewSubtaskHandler はSubtaskHandlerの新しいインスタンスを生成する。Todoのロックの期限はclockの現在時刻で判定する
+func NewSubtaskHandler(queries *db.Queries, db *sql.DB, bus *event.Bus, clock clock.Clock) *SubtaskHandler {
+	return &SubtaskHandler{
+		queries: queries,
+		db:      db,
+		bus:     bus,
+		clock:   clock,
+	}
+}
+
+// toSubtaskResponse はdb.Subtaskをmodel.SubtaskResponseに変換する
+func toSubtaskResponse(s db.Subtask) model.SubtaskResponse {
+	return model.SubtaskResponse{
+		ID:        s.ID,
+		TodoID:    s.TodoID,
+		Title:     s.Title,
+		Completed: s.Completed == 1,
+		CreatedAt: s.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: s.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// subtaskNotFound はサブタスクが見つからない場合のエラーを返す
+func subtaskNotFound(todoID, subtaskID int64) error {
+	return huma.Error404NotFound(fmt.Sprintf("サブタスクIDが見つかりません: todo=%d subtask=%d", todoID, subtaskID))
+}
+
+// getTodo は認証したユーザーが所有するか、共有されている指定されたIDのTodoを取得する
+func getTodo(ctx context.Context, q *db.Queries, id int64) (db.Todo, error) {
+	todo, err := q.GetTodo(ctx, db.GetTodoParams{ID: id, UserID: ownerID(ctx)})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("Todo IDが見つかりません", "id", id, "err", err)
+			return db.Todo{}, huma.Error404NotFound(fmt.Sprintf("Todo IDが見つかりません: %d", id))
+		}
+		slog.Warn("Todoの取得に失敗", "err", err)
+		return db.Todo{}, huma.Error500InternalServerError("Todo取得に失敗", err)
+	}
+	return todo, nil
+}
+
+// getWritableTodo は認証したユーザーが変更できる、指定されたIDのTodoを取得する。
+// 閲覧のみで共有されている場合は403を返す。
+func getWritableTodo(ctx context.Context, q *db.Queries, id int64) (db.Todo, error) {
+	todo, err := q.GetTodo(ctx, db.GetTodoParams{ID: id, UserID: ownerID(ctx), ForWrite: 1})
+	if err == nil {
+		return todo, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		slog.Warn("Todoの取得に失敗", "err", err)
+		return db.Todo{}, huma.Error500InternalServerError("Todo取得に失敗", err)
+	}
+	if _, err := getTodo(ctx, q, id); err != nil {
+		return db.Todo{}, err
+	}
+	slog.Warn("Todoを変更する権限がありません", "id", id)
+	return db.Todo{}, huma.Error403Forbidden(fmt.Sprintf("Todoを変更する権限がありません: %d", id))
+}
+
+// ensureTodoExists は親Todoが存在し、閲覧できることを確認する
+func ensureTodoExists(ctx context.Context, q *db.Queries, id int64) error {
+	_, err := getTodo(ctx, q, id)
+	return err
+}
+
+// ensureTodoWritable は親Todoが存在し、変更できることを確認する
+func ensureTodoWritable(ctx context.Context, q *db.Queries, id int64) error {
+	_, err := getWritableTodo(ctx, q, id)
+	return err
+}
+
+// ListSubtasks は指定されたTodoのサブタスク一覧を取得する
+func (h *SubtaskHandler) ListSubtasks(ctx context.Context, input *model.ListSubtasksInput) (*model.ListSubtasksOutput, error) {
+	if err := ensureTodoExists(ctx, h.queries, input.TodoID); err != nil {
+		return nil, err
+	}
+
+	subtasks, err := h.queries.ListSubtasks(ctx, input.TodoID)
+	if err != nil {
+		slog.Warn("サブタスク一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("サブタスク一覧の取得に失敗", err)
+	}
+
+	output := &model.ListSubtasksOutput{}
+	output.Body.Subtasks = make([]model.SubtaskResponse, len(subtasks))
+	for i, s := range subtasks {
+		output.Body.Subtasks[i] = toSubtaskResponse(s)
+	}
+
+	return output, nil
+}
+
+// GetSubtask は指定されたIDのサブタスクを取得する
+func (h *SubtaskHandler) GetSubtask(ctx context.Context, input *model.GetSubtaskInput) (*model.GetSubtaskOutput, error) {
+	if err := ensureTodoExists(ctx, h.queries, input.TodoID); err != nil {
+		return nil, err
+	}
+
+	subtask, err := h.queries.GetSubtask(ctx, db.GetSubtaskParams{
+		ID:     input.SubtaskID,
+		TodoID: input.TodoID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, subtaskNotFound(input.TodoID, input.SubtaskID)
+		}
+		slog.Warn("サブタスクの取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("サブタスクの取得に失敗", err)
+	}
+
+	return &model.GetSubtaskOutput{Body: toSubtaskResponse(subtask)}, nil
+}
+
+// CreateSubtask は指定されたTodoにサブタスクを追加する
+func (h *SubtaskHandler) CreateSubtask(ctx context.Context, input *model.CreateSubtaskInput) (*model.CreateSubtaskOutput, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Warn("トランザクション開始に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("トランザクション開始に失敗", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := h.queries.WithTx(tx)
+
+	if err := ensureTodoUnlocked(ctx, qtx, input.TodoID, h.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	var completed int64
+	if input.Body.Completed {
+		completed = 1
+	}
+
+	subtask, err := qtx.CreateSubtask(ctx, db.CreateSubtaskParams{
+		TodoID:    input.TodoID,
+		Title:     input.Body.Title,
+		Completed: completed,
+	})
+	if err != nil {
+		slog.Warn("サブタスク作成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("サブタスク作成に失敗", err)
+	}
+
+	e := event.Event{Type: event.TodoUpdated, TodoID: input.TodoID}
+	if err := recordEvent(ctx, qtx, e); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Warn("トランザクションのコミットに失敗", "err", err)
+		return nil, huma.Error500InternalServerError("トランザクションのコミットに失敗", err)
+	}
+
+	h.bus.Publish(e)
+
+	return &model.CreateSubtaskOutput{Body: toSubtaskResponse(subtask)}, nil
+}
+
+// UpdateSubtask は指定されたIDのサブタスクを更新する
+func (h *SubtaskHandler) UpdateSubtask(ctx context.Context, input *model.UpdateSubtaskInput) (*model.UpdateSubtaskOutput, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Warn("トランザクション開始に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("トランザクション開始に失敗", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := h.queries.WithTx(tx)
+
+	if err := ensureTodoUnlocked(ctx, qtx, input.TodoID, h.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	var completed int64
+	if input.Body.Completed {
+		completed = 1
+	}
+
+	subtask, err := qtx.UpdateSubtask(ctx, db.UpdateSubtaskParams{
+		Title:     input.Body.Title,
+		Completed: completed,
+		ID:        input.SubtaskID,
+		TodoID:    input.TodoID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, subtaskNotFound(input.TodoID, input.SubtaskID)
+		}
+		slog.Warn("サブタスク更新に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("サブタスク更新に失敗", err)
+	}
+
+	e := event.Event{Type: event.TodoUpdated, TodoID: input.TodoID}
+	if err := recordEvent(ctx, qtx, e); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Warn("トランザクションのコミットに失敗", "err", err)
+		return nil, huma.Error500InternalServerError("トランザクションのコミットに失敗", err)
+	}
+
+	h.bus.Publish(e)
+
+	return &model.UpdateSubtaskOutput{Body: toSubtaskResponse(subtask)}, nil
+}
+
+// DeleteSubtask は指定されたIDのサブタスクを削除する
+func (h *SubtaskHandler) DeleteSubtask(ctx context.Context, input *model.DeleteSubtaskInput) (*model.DeleteSubtaskOutput, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Warn("トランザクション開始に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("トランザクション開始に失敗", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := h.queries.WithTx(tx)
+
+	if err := ensureTodoUnlocked(ctx, qtx, input.TodoID, h.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	n, err := qtx.DeleteSubtask(ctx, db.DeleteSubtaskParams{
+		ID:     input.SubtaskID,
+		TodoID: input.TodoID,
+	})
+	if err != nil {
+		slog.Warn("サブタスク削除に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("サブタスク削除に失敗", err)
+	}
+	if n == 0 {
+		return nil, subtaskNotFound(input.TodoID, input.SubtaskID)
+	}
+
+	e := event.Event{Type: event.TodoUpdated, TodoID: input.TodoID}
+	if err := recordEvent(ctx, qtx, e); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Warn("トランザクションのコミットに失敗", "err", err)
+		return nil, huma.Error500InternalServerError("トランザクションのコミットに失敗", err)
+	}
+
+	h.bus.Publish(e)
+
+	output := &model.DeleteSubtaskOutput{}
+	output.Body.Message = "Subtask deleted successfully"
+	return output, nil
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// SummaryHandler はモバイルクライアントのホーム画面向けのサマリーを処理するハンドラー
+type SummaryHandler struct {
+	queries *db.Queries
+	clock   clock.Clock
+}
+
+// NewSummaryHandler はSummaryHandlerの新しいインスタンスを生成する。期限切れと今日の範囲はclockの現在時刻で決める
+func NewSummaryHandler(queries *db.Queries, clock clock.Clock) *SummaryHandler {
+	return &SummaryHandler{
+		queries: queries,
+		clock:   clock,
+	}
+}
+
+// GetSummary はリクエストの認証主体から見た期限切れ、今日が期限、担当、メンション、未読の件数を1回のクエリで取得する
+func (h *SummaryHandler) GetSummary(ctx context.Context, input *model.GetSummaryInput) (*model.GetSummaryOutput, error) {
+	loc, err := time.LoadLocation(input.TZ)
+	if err != nil {
+		return nil, huma.Error422UnprocessableEntity(fmt.Sprintf("タイムゾーンが不正です: %s", input.TZ), &huma.ErrorDetail{
+			Location: "query.tz",
+			Value:    input.TZ,
+		})
+	}
+
+	now := h.clock.Now().In(loc)
+	dayEnd := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+	subject := activity.ActorFrom(ctx)
+
+	counts, err := h.queries.GetInboxSummary(ctx, db.GetInboxSummaryParams{
+		Now:     sql.NullTime{Time: now.UTC(), Valid: true},
+		OwnerID: ownerID(ctx),
+		DayEnd:  sql.NullTime{Time: dayEnd.UTC(), Valid: true},
+		Subject: subject,
+	})
+	if err != nil {
+		slog.Warn("サマリーの取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("サマリーの取得に失敗", err)
+	}
+
+	return &model.GetSummaryOutput{Body: model.SummaryResponse{
+		Subject:        subject,
+		Overdue:        counts.OverdueCount,
+		DueToday:       counts.DueTodayCount,
+		AssignedToMe:   counts.AssignedCount,
+		Mentions:       counts.MentionCount,
+		UnreadActivity: counts.UnreadActivityCount,
+	}}, nil
+}
+
+// GetBadges はリクエストの認証主体のバッジに表示する期限切れ、今日が期限、担当、未読のコメントの件数を取得する。
+// 数秒ごとのポーリングを想定し、所有者ごとの未完了のTodoのインデックスだけを使うクエリで数え、件数からETagを生成する。
+func (h *SummaryHandler) GetBadges(ctx context.Context, input *model.GetBadgesInput) (*model.GetBadgesOutput, error) {
+	loc, err := time.LoadLocation(input.TZ)
+	if err != nil {
+		return nil, huma.Error422UnprocessableEntity(fmt.Sprintf("タイムゾーンが不正です: %s", input.TZ), &huma.ErrorDetail{
+			Location: "query.tz",
+			Value:    input.TZ,
+		})
+	}
+
+	now := h.clock.Now().In(loc)
+	dayEnd := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+
+	counts, err := h.queries.GetBadgeCounts(ctx, db.GetBadgeCountsParams{
+		OwnerID: ownerID(ctx),
+		Now:     sql.NullTime{Time: now.UTC(), Valid: true},
+		DayEnd:  sql.NullTime{Time: dayEnd.UTC(), Valid: true},
+		Subject: activity.ActorFrom(ctx),
+	})
+	if err != nil {
+		slog.Warn("バッジの件数の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("バッジの件数の取得に失敗", err)
+	}
+
+	return &model.GetBadgesOutput{
+		// 件数が同じなら本文も同じため、本文のハッシュの代わりに件数をそのままETagにする
+		ETag: fmt.Sprintf(`"%d-%d-%d-%d"`, counts.OverdueCount, counts.DueTodayCount, counts.AssignedCount, counts.UnreadCommentCount),
+		Body: model.BadgesResponse{
+			Overdue:        counts.OverdueCount,
+			DueToday:       counts.DueTodayCount,
+			Assigned:       counts.AssignedCount,
+			UnreadComments: counts.UnreadCommentCount,
+		},
+	}, nil
+}
+
+// MarkSummaryRead は現時点までのアクティビティとコメントを既読にし、メンションと未読の件数を0に戻す
+func (h *SummaryHandler) MarkSummaryRead(ctx context.Context, _ *struct{}) (*model.MarkSummaryReadOutput, error) {
+	if err := h.queries.MarkInboxRead(ctx, activity.ActorFrom(ctx)); err != nil {
+		slog.Warn("既読位置の更新に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("既読位置の更新に失敗", err)
+	}
+
+	output := &model.MarkSummaryReadOutput{}
+	output.Body.Message = "Marked as read"
+	return output, nil
+}
@@ -0,0 +1,346 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/auth"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"go-huma-test/websocket"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+)
+
+// syncOperations は同期チャネルから呼び出せる操作のoperationId
+var syncOperations = []string{
+	"create-todo",
+	"update-todo",
+	"toggle-todo",
+	"move-todo",
+	"archive-todo",
+	"unarchive-todo",
+	"duplicate-todo",
+	"delete-todo",
+	"create-subtask",
+	"update-subtask",
+	"delete-subtask",
+}
+
+// syncForwardHeaders は同期チャネルの接続のリクエストから、変更の依頼に引き継ぐヘッダー
+var syncForwardHeaders = []string{"Authorization", auth.APIKeyHeader, "Accept-Language", "X-Timezone", "User-Agent"}
+
+const (
+	// syncPingInterval は接続を確かめるpingを送る間隔
+	syncPingInterval = 30 * time.Second
+	// syncReadTimeout はpongも含めて何も届かない接続を切るまでの時間
+	syncReadTimeout = 2*syncPingInterval + 15*time.Second
+	// syncMaxMessageSize はクライアントから受け取るメッセージの最大バイト数
+	syncMaxMessageSize = 1 << 20
+)
+
+// SyncHandler はWebSocketでTodoの変更を通知し、変更の依頼を受け付ける同期チャネルを処理するハンドラー
+type SyncHandler struct {
+	queries *db.Queries
+	bus     *event.Bus
+	// api は変更の依頼をRESTのリクエストとして処理するハンドラー
+	api  http.Handler
+	oapi *huma.OpenAPI
+
+	opsOnce sync.Once
+	ops     map[string]*huma.Operation
+}
+
+// NewSyncHandler はSyncHandlerの新しいインスタンスを生成する。
+// apiには操作を登録したルーターを渡し、変更の依頼をRESTの操作と同じ検証、認証、認可で処理する。
+func NewSyncHandler(queries *db.Queries, bus *event.Bus, api http.Handler, oapi *huma.OpenAPI) *SyncHandler {
+	return &SyncHandler{
+		queries: queries,
+		bus:     bus,
+		api:     api,
+		oapi:    oapi,
+	}
+}
+
+// operation は同期チャネルから呼び出せる操作をoperationIdで探す。
+// 操作の登録が終わった後に呼び出されるため、初回の呼び出しで一覧を作る。
+func (h *SyncHandler) operation(id string) (*huma.Operation, bool) {
+	h.opsOnce.Do(func() {
+		h.ops = make(map[string]*huma.Operation, len(syncOperations))
+		for _, item := range h.oapi.Paths {
+			for _, op := range []*huma.Operation{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+				if op != nil && slices.Contains(syncOperations, op.OperationID) {
+					h.ops[op.OperationID] = op
+				}
+			}
+		}
+	})
+	op, ok := h.ops[id]
+	return op, ok
+}
+
+// Connect はWebSocketの同期チャネルを開く。
+// 接続直後に呼び出せる操作の一覧を送り、以降は閲覧できるTodoの変更を通知しながら変更の依頼を処理する。
+func (h *SyncHandler) Connect(ctx context.Context, input *model.SyncConnectInput) (*huma.StreamResponse, error) {
+	if !strings.EqualFold(input.Upgrade, "websocket") {
+		return nil, huma.NewError(http.StatusUpgradeRequired, "WebSocketで接続してください")
+	}
+	if input.Version != websocket.Version {
+		return nil, huma.NewError(http.StatusUpgradeRequired, fmt.Sprintf("Sec-WebSocket-Versionには%sを指定してください", websocket.Version))
+	}
+
+	return &huma.StreamResponse{
+		Body: func(hctx huma.Context) {
+			r, w := humago.Unwrap(hctx)
+			conn, err := websocket.Upgrade(w, r)
+			if err != nil {
+				slog.Warn("WebSocketのハンドシェイクに失敗", "err", err)
+				return
+			}
+			defer func() { _ = conn.Close() }()
+			conn.MaxMessageSize = syncMaxMessageSize
+			conn.ReadTimeout = syncReadTimeout
+
+			s := &syncSession{
+				h:       h,
+				conn:    conn,
+				upgrade: r,
+				user:    ownerID(hctx.Context()),
+			}
+			s.run(hctx.Context())
+		},
+	}, nil
+}
+
+// syncSession は同期チャネルの1つの接続
+type syncSession struct {
+	h    *SyncHandler
+	conn *websocket.Conn
+	// upgrade は接続のリクエスト。変更の依頼に資格情報などのヘッダーを引き継ぐ
+	upgrade *http.Request
+	user    sql.NullInt64
+}
+
+// run は接続が閉じるまで変更を通知し、変更の依頼を処理する
+func (s *syncSession) run(ctx context.Context) {
+	// 一覧を送る前に購読しておき、その間の変更を取りこぼさないようにする
+	events, unsubscribe := s.h.bus.Subscribe(256)
+	defer unsubscribe()
+
+	if err := s.conn.WriteJSON(model.SyncReadyMessage{Type: model.SyncMessageReady, Operations: syncOperations}); err != nil {
+		return
+	}
+	slog.Info("同期チャネルを開始", "user_id", s.user.Int64)
+
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		s.readLoop(ctx)
+	}()
+	defer func() {
+		_ = s.conn.Close()
+		<-readerDone
+		slog.Info("同期チャネルを終了", "user_id", s.user.Int64)
+	}()
+
+	ping := time.NewTicker(syncPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-readerDone:
+			return
+		case <-ping.C:
+			if err := s.conn.Ping(); err != nil {
+				return
+			}
+		case e, ok := <-events:
+			if !ok {
+				// サーバーの停止
+				_ = s.conn.WriteClose(websocket.CloseGoingAway, "")
+				return
+			}
+			msg, ok := s.eventMessage(ctx, e)
+			if !ok {
+				continue
+			}
+			if err := s.conn.WriteJSON(msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventMessage はイベントを通知のメッセージにする。接続したユーザーが閲覧できないTodoのイベントはfalseを返す
+func (s *syncSession) eventMessage(ctx context.Context, e event.Event) (model.SyncEventMessage, bool) {
+	msg := model.SyncEventMessage{Type: model.SyncMessageEvent, Event: string(e.Type), TodoID: e.TodoID, Completed: e.Completed}
+	if e.Type == event.TodoDeleted {
+		// 削除されたTodoは共有を確かめられないため、所有者にのみ通知する
+		return msg, e.OwnerID == s.user
+	}
+	t, err := s.h.queries.GetTodo(ctx, db.GetTodoParams{ID: e.TodoID, UserID: s.user})
+	if errors.Is(err, sql.ErrNoRows) {
+		return msg, false
+	}
+	if err != nil {
+		slog.Warn("同期チャネルで通知するTodoの取得に失敗", "todo_id", e.TodoID, "err", err)
+		return msg, false
+	}
+	todo := toTodoResponse(ctx, t)
+	msg.Todo = &todo
+	msg.ETag = todoETag(t)
+	return msg, true
+}
+
+// readLoop は接続が閉じるまでクライアントのメッセージを読み、変更の依頼を順に処理する
+func (s *syncSession) readLoop(ctx context.Context) {
+	for {
+		messageType, data, err := s.conn.ReadMessage()
+		if err != nil {
+			var ce *websocket.CloseError
+			if !errors.As(err, &ce) && !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				slog.Debug("同期チャネルの受信に失敗", "err", err)
+			}
+			return
+		}
+		if messageType != websocket.TextMessage {
+			_ = s.conn.WriteClose(websocket.CloseUnsupportedData, "JSONのテキストで送信してください")
+			return
+		}
+
+		var m model.SyncMutateMessage
+		dec := json.NewDecoder(bytes.NewReader(data))
+		// パスパラメータの数値を丸めずに文字列にするため、json.Numberで受け取る
+		dec.UseNumber()
+		if err := dec.Decode(&m); err != nil || m.Type != model.SyncMessageMutate {
+			if err := s.conn.WriteJSON(model.SyncErrorMessage{Type: model.SyncMessageError, ID: m.ID, Message: "typeがmutateのJSONのメッセージを送信してください"}); err != nil {
+				return
+			}
+			continue
+		}
+		if err := s.conn.WriteJSON(s.mutate(ctx, m)); err != nil {
+			return
+		}
+	}
+}
+
+// syncRecorder は変更の依頼をRESTのリクエストとして処理したレスポンスを記録するResponseWriter
+type syncRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *syncRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *syncRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *syncRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+// syncProblem は変更の依頼を処理する前に失敗した結果を、RESTのエラーと同じ形式で返す
+func syncProblem(m model.SyncMutateMessage, status int, msg string) model.SyncResultMessage {
+	body, _ := json.Marshal(huma.NewError(status, msg))
+	return model.SyncResultMessage{Type: model.SyncMessageResult, ID: m.ID, Operation: m.Operation, Status: status, Body: body}
+}
+
+// mutate は変更の依頼を、接続のリクエストの資格情報でRESTのリクエストとして処理する。
+// RESTの操作と同じミドルウェアとハンドラーを通すため、検証、認可、レート制限、Todoのバージョンの照合も同じになる。
+func (s *syncSession) mutate(ctx context.Context, m model.SyncMutateMessage) model.SyncResultMessage {
+	op, ok := s.h.operation(m.Operation)
+	if !ok {
+		return syncProblem(m, http.StatusBadRequest, fmt.Sprintf("同期チャネルから呼び出せない操作です: %s", m.Operation))
+	}
+
+	query := url.Values{}
+	path := op.Path
+	for name, v := range m.Params {
+		placeholder := "{" + name + "}"
+		if strings.Contains(path, placeholder) {
+			path = strings.ReplaceAll(path, placeholder, url.PathEscape(fmt.Sprint(v)))
+			continue
+		}
+		query.Set(name, fmt.Sprint(v))
+	}
+	if strings.Contains(path, "{") {
+		return syncProblem(m, http.StatusBadRequest, fmt.Sprintf("paramsにパスパラメータを指定してください: %s", op.Path))
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var body io.Reader = http.NoBody
+	if len(m.Body) > 0 {
+		body = bytes.NewReader(m.Body)
+	}
+	// 接続のリクエストのcontextには接続時の認証の結果が入っているため、HTTPのレベルのcontextを使い、依頼ごとに認証し直す
+	req, err := http.NewRequestWithContext(s.upgrade.Context(), op.Method, path, body)
+	if err != nil {
+		return syncProblem(m, http.StatusBadRequest, fmt.Sprintf("paramsが不正です: %v", err))
+	}
+	req.RemoteAddr = s.upgrade.RemoteAddr
+	req.Host = s.upgrade.Host
+	for _, name := range syncForwardHeaders {
+		if v := s.upgrade.Header.Get(name); v != "" {
+			req.Header.Set(name, v)
+		}
+	}
+	if len(m.Body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if m.IfMatch != "" {
+		req.Header.Set("If-Match", m.IfMatch)
+	}
+	if m.IdempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", m.IdempotencyKey)
+	}
+
+	rec := &syncRecorder{header: http.Header{}}
+	s.h.api.ServeHTTP(rec, req)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if ctx.Err() == nil && rec.status >= http.StatusInternalServerError {
+		slog.Warn("同期チャネルの変更の依頼に失敗", "operation", m.Operation, "status", rec.status)
+	}
+
+	result := model.SyncResultMessage{
+		Type:      model.SyncMessageResult,
+		ID:        m.ID,
+		Operation: m.Operation,
+		Status:    rec.status,
+		ETag:      rec.header.Get("ETag"),
+	}
+	if b := rec.body.Bytes(); len(b) > 0 {
+		if json.Valid(b) {
+			result.Body = b
+		} else {
+			result.Body, _ = json.Marshal(string(b))
+		}
+	}
+	return result
+}
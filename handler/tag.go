@@ -0,0 +1,432 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// maxTagNameLength はタグの名前の最大文字数
+const maxTagNameLength = 50
+
+// TagHandler はタグとTodoに付けたタグの操作を処理するハンドラー。
+// タグはTodoの所有者ごとに名前で区別し、名前の変更、統合、削除はそのタグを付けたすべてのTodoに1つのトランザクションで反映する。
+type TagHandler struct {
+	queries *db.Queries
+	db      *sql.DB
+	bus     *event.Bus
+	clock   clock.Clock
+}
+
+// NewTagHandler はTagHandlerの新しいインスタンスを生成する。Todoのロックの期限はclockの現在時刻で判定する
+func NewTagHandler(queries *db.Queries, db *sql.DB, bus *event.Bus, clock clock.Clock) *TagHandler {
+	return &TagHandler{
+		queries: queries,
+		db:      db,
+		bus:     bus,
+		clock:   clock,
+	}
+}
+
+// toTagResponse はdb.Tagをmodel.TagResponseに変換する
+func toTagResponse(t db.Tag, todoCount int64) model.TagResponse {
+	return model.TagResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		TodoCount: todoCount,
+		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// tagNotFound はタグが見つからない場合のエラーを返す
+func tagNotFound(id int64) error {
+	return huma.Error404NotFound(fmt.Sprintf("タグIDが見つかりません: %d", id))
+}
+
+// getTag は認証したユーザーが所有する、指定されたIDのタグを取得する
+func getTag(ctx context.Context, q *db.Queries, id int64) (db.Tag, error) {
+	tag, err := q.GetTag(ctx, db.GetTagParams{ID: id, OwnerID: ownerID(ctx)})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("タグIDが見つかりません", "id", id)
+			return db.Tag{}, tagNotFound(id)
+		}
+		slog.Warn("タグの取得に失敗", "err", err)
+		return db.Tag{}, huma.Error500InternalServerError("タグの取得に失敗", err)
+	}
+	return tag, nil
+}
+
+// getTargetTag はタグの付け替え先を取得する。見つからない場合はlocationを示して422を返す
+func getTargetTag(ctx context.Context, q *db.Queries, id int64, location string) (db.Tag, error) {
+	tag, err := getTag(ctx, q, id)
+	var se huma.StatusError
+	if errors.As(err, &se) && se.GetStatus() == http.StatusNotFound {
+		return db.Tag{}, huma.Error422UnprocessableEntity(fmt.Sprintf("タグIDが見つかりません: %d", id), &huma.ErrorDetail{
+			Location: location,
+			Value:    id,
+		})
+	}
+	return tag, err
+}
+
+// normalizeTagNames は前後の空白を除き、重複を除いて名前の順に並べたタグの名前を返す。
+// locationはエラーの場所として使うタグの配列の位置
+func normalizeTagNames(names []string, location string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || utf8.RuneCountInString(name) > maxTagNameLength {
+			return nil, huma.Error422UnprocessableEntity(fmt.Sprintf("タグの名前は1文字以上%d文字以下で指定してください", maxTagNameLength), &huma.ErrorDetail{
+				Location: fmt.Sprintf("%s[%d]", location, i),
+				Value:    names[i],
+			})
+		}
+		normalized = append(normalized, name)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
+
+// ensureTag は所有者のタグを名前で取得し、ない場合は作成する
+func ensureTag(ctx context.Context, q *db.Queries, owner sql.NullInt64, name string) (db.Tag, error) {
+	tag, err := q.GetTagByName(ctx, db.GetTagByNameParams{OwnerID: owner, Name: name})
+	if errors.Is(err, sql.ErrNoRows) {
+		tag, err = q.CreateTag(ctx, db.CreateTagParams{OwnerID: owner, Name: name})
+	}
+	if err != nil {
+		slog.Warn("タグの作成に失敗", "name", name, "err", err)
+		return db.Tag{}, huma.Error500InternalServerError("タグの作成に失敗", err)
+	}
+	return tag, nil
+}
+
+// addTodoTags はTodoに所有者のタグを名前で付ける。まだないタグは作成する
+func addTodoTags(ctx context.Context, q *db.Queries, todoID int64, owner sql.NullInt64, names []string) error {
+	for _, name := range names {
+		tag, err := ensureTag(ctx, q, owner, name)
+		if err != nil {
+			return err
+		}
+		if err := q.AddTodoTag(ctx, db.AddTodoTagParams{TodoID: todoID, TagID: tag.ID}); err != nil {
+			slog.Warn("Todoへのタグの追加に失敗", "id", todoID, "err", err)
+			return huma.Error500InternalServerError("Todoへのタグの追加に失敗", err)
+		}
+	}
+	return nil
+}
+
+// todoTagNames はTodoごとに付けているタグの名前を名前の順で返す
+func todoTagNames(ctx context.Context, q *db.Queries, ids []int64) (map[int64][]string, error) {
+	rows, err := q.ListTodoTagNames(ctx, ids)
+	if err != nil {
+		slog.Warn("Todoのタグの取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("Todoのタグの取得に失敗", err)
+	}
+	names := make(map[int64][]string, len(ids))
+	for _, r := range rows {
+		names[r.TodoID] = append(names[r.TodoID], r.Name)
+	}
+	return names, nil
+}
+
+// recordTagChanges はbeforeとafterでタグが変わったTodoごとにアクティビティと変更イベントを記録し、変わったTodoのIDとイベントを返す
+func recordTagChanges(ctx context.Context, qtx *db.Queries, ids []int64, before, after map[int64][]string) ([]int64, []event.Event, error) {
+	changed := []int64{}
+	var events []event.Event
+	for _, id := range ids {
+		if slices.Equal(before[id], after[id]) {
+			continue
+		}
+		if err := activity.RecordTags(ctx, qtx, id, before[id], after[id]); err != nil {
+			slog.Warn("アクティビティの記録に失敗", "err", err)
+			return nil, nil, huma.Error500InternalServerError("アクティビティの記録に失敗", err)
+		}
+		e := event.Event{Type: event.TodoUpdated, TodoID: id}
+		if err := recordEvent(ctx, qtx, e); err != nil {
+			return nil, nil, err
+		}
+		changed = append(changed, id)
+		events = append(events, e)
+	}
+	return changed, events, nil
+}
+
+// retag はタグを付けたすべてのTodoに対してfnでタグを変更し、タグが変わったTodoごとにアクティビティと変更イベントを記録する。
+// 他のユーザーがロックしているTodoが含まれる場合は423を返す。
+func (h *TagHandler) retag(ctx context.Context, qtx *db.Queries, tagID int64, fn func() error) ([]int64, []event.Event, error) {
+	ids, err := qtx.ListTagTodoIDs(ctx, tagID)
+	if err != nil {
+		slog.Warn("タグを付けたTodoの取得に失敗", "err", err)
+		return nil, nil, huma.Error500InternalServerError("タグを付けたTodoの取得に失敗", err)
+	}
+	if err := checkTodoLocks(ctx, qtx, h.clock.Now(), ids...); err != nil {
+		return nil, nil, err
+	}
+	before, err := todoTagNames(ctx, qtx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := fn(); err != nil {
+		return nil, nil, err
+	}
+	after, err := todoTagNames(ctx, qtx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	return recordTagChanges(ctx, qtx, ids, before, after)
+}
+
+// tagSummary は操作の後のタグと、タグが変わったTodoから結果を生成する
+func tagSummary(ctx context.Context, q *db.Queries, tag *db.Tag, todoIDs []int64, dryRun bool) (model.TagOperationSummary, error) {
+	summary := model.TagOperationSummary{DryRun: dryRun, TodoIDs: todoIDs}
+	if tag != nil {
+		ids, err := q.ListTagTodoIDs(ctx, tag.ID)
+		if err != nil {
+			slog.Warn("タグを付けたTodoの取得に失敗", "err", err)
+			return summary, huma.Error500InternalServerError("タグを付けたTodoの取得に失敗", err)
+		}
+		r := toTagResponse(*tag, int64(len(ids)))
+		summary.Tag = &r
+	}
+	return summary, nil
+}
+
+// publish はコミットした変更イベントを発行する
+func (h *TagHandler) publish(events []event.Event) {
+	for _, e := range events {
+		h.bus.Publish(e)
+	}
+}
+
+// ListTags は認証したユーザーのタグを、付けているTodoの件数とともに返す
+func (h *TagHandler) ListTags(ctx context.Context, _ *model.ListTagsInput) (*model.ListTagsOutput, error) {
+	tags, err := h.queries.ListTags(ctx, ownerID(ctx))
+	if err != nil {
+		slog.Warn("タグ一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("タグ一覧の取得に失敗", err)
+	}
+
+	output := &model.ListTagsOutput{}
+	output.Body.Tags = make([]model.TagResponse, len(tags))
+	for i, t := range tags {
+		output.Body.Tags[i] = toTagResponse(db.Tag{ID: t.ID, OwnerID: t.OwnerID, Name: t.Name, CreatedAt: t.CreatedAt}, t.TodoCount)
+	}
+	return output, nil
+}
+
+// SetTodoTags はTodoに付けるタグを指定された名前のタグに置き換える。まだないタグはTodoの所有者のタグとして作成する
+func (h *TagHandler) SetTodoTags(ctx context.Context, input *model.SetTodoTagsInput) (*model.SetTodoTagsOutput, error) {
+	names, err := normalizeTagNames(input.Body.Tags, "body.tags")
+	if err != nil {
+		return nil, err
+	}
+
+	var events []event.Event
+	err = runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		todo, err := getUnlockedTodo(ctx, qtx, input.ID, h.clock.Now())
+		if err != nil {
+			return err
+		}
+		ids := []int64{todo.ID}
+		before, err := todoTagNames(ctx, qtx, ids)
+		if err != nil {
+			return err
+		}
+		if err := qtx.DeleteTodoTags(ctx, todo.ID); err != nil {
+			slog.Warn("Todoのタグの削除に失敗", "err", err)
+			return huma.Error500InternalServerError("Todoのタグの削除に失敗", err)
+		}
+		if err := addTodoTags(ctx, qtx, todo.ID, todo.OwnerID, names); err != nil {
+			return err
+		}
+		_, events, err = recordTagChanges(ctx, qtx, ids, before, map[int64][]string{todo.ID: names})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.publish(events)
+
+	output := &model.SetTodoTagsOutput{}
+	output.Body.TodoID = input.ID
+	output.Body.Tags = names
+	return output, nil
+}
+
+// RenameTag はタグの名前を変更し、タグを付けたすべてのTodoに反映する。同じ名前のタグが既にある場合は409を返す
+func (h *TagHandler) RenameTag(ctx context.Context, input *model.RenameTagInput) (*model.RenameTagOutput, error) {
+	name := strings.TrimSpace(input.Body.Name)
+	if name == "" {
+		return nil, huma.Error422UnprocessableEntity("タグの名前を指定してください", &huma.ErrorDetail{
+			Location: "body.name",
+			Value:    input.Body.Name,
+		})
+	}
+
+	var (
+		summary model.TagOperationSummary
+		events  []event.Event
+	)
+	err := runInTx(ctx, h.db, h.queries, input.DryRun, func(qtx *db.Queries) error {
+		tag, err := getTag(ctx, qtx, input.ID)
+		if err != nil {
+			return err
+		}
+		existing, err := qtx.GetTagByName(ctx, db.GetTagByNameParams{OwnerID: tag.OwnerID, Name: name})
+		if err == nil && existing.ID != tag.ID {
+			slog.Warn("同じ名前のタグが既にあります", "id", tag.ID, "existing_id", existing.ID)
+			return huma.Error409Conflict(fmt.Sprintf("同じ名前のタグが既にあります: %d。統合する場合は/tags/%d/mergeを使用してください", existing.ID, tag.ID))
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("タグの取得に失敗", "err", err)
+			return huma.Error500InternalServerError("タグの取得に失敗", err)
+		}
+
+		todoIDs, evs, err := h.retag(ctx, qtx, tag.ID, func() error {
+			tag, err = qtx.RenameTag(ctx, db.RenameTagParams{Name: name, ID: tag.ID})
+			if err != nil {
+				slog.Warn("タグの名前の変更に失敗", "err", err)
+				return huma.Error500InternalServerError("タグの名前の変更に失敗", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		events = evs
+		summary, err = tagSummary(ctx, qtx, &tag, todoIDs, input.DryRun)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !input.DryRun {
+		h.publish(events)
+	}
+
+	return &model.RenameTagOutput{Body: summary}, nil
+}
+
+// MergeTag はタグを統合先のタグに統合する。統合するタグを付けていたTodoに統合先のタグを付け、統合したタグを削除する
+func (h *TagHandler) MergeTag(ctx context.Context, input *model.MergeTagInput) (*model.MergeTagOutput, error) {
+	if input.Body.Into == input.ID {
+		return nil, huma.Error422UnprocessableEntity("統合先には別のタグを指定してください", &huma.ErrorDetail{
+			Location: "body.into",
+			Value:    input.Body.Into,
+		})
+	}
+
+	var (
+		summary model.TagOperationSummary
+		events  []event.Event
+	)
+	err := runInTx(ctx, h.db, h.queries, input.DryRun, func(qtx *db.Queries) error {
+		tag, err := getTag(ctx, qtx, input.ID)
+		if err != nil {
+			return err
+		}
+		target, err := getTargetTag(ctx, qtx, input.Body.Into, "body.into")
+		if err != nil {
+			return err
+		}
+		todoIDs, evs, err := h.retag(ctx, qtx, tag.ID, func() error {
+			return h.reassign(ctx, qtx, tag, &target)
+		})
+		if err != nil {
+			return err
+		}
+		events = evs
+		summary, err = tagSummary(ctx, qtx, &target, todoIDs, input.DryRun)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !input.DryRun {
+		h.publish(events)
+	}
+
+	slog.Info("タグを統合", "id", input.ID, "into", input.Body.Into, "todos", len(summary.TodoIDs), "dry_run", input.DryRun)
+	return &model.MergeTagOutput{Body: summary}, nil
+}
+
+// DeleteTag はタグを削除する。reassign_toを指定した場合は、削除するタグを付けていたTodoに代わりのタグを付ける
+func (h *TagHandler) DeleteTag(ctx context.Context, input *model.DeleteTagInput) (*model.DeleteTagOutput, error) {
+	if input.ReassignTo == input.ID {
+		return nil, huma.Error422UnprocessableEntity("付け替え先には別のタグを指定してください", &huma.ErrorDetail{
+			Location: "query.reassign_to",
+			Value:    input.ReassignTo,
+		})
+	}
+
+	var (
+		summary model.TagOperationSummary
+		events  []event.Event
+	)
+	err := runInTx(ctx, h.db, h.queries, input.DryRun, func(qtx *db.Queries) error {
+		tag, err := getTag(ctx, qtx, input.ID)
+		if err != nil {
+			return err
+		}
+		var target *db.Tag
+		if input.ReassignTo != 0 {
+			t, err := getTargetTag(ctx, qtx, input.ReassignTo, "query.reassign_to")
+			if err != nil {
+				return err
+			}
+			target = &t
+		}
+		todoIDs, evs, err := h.retag(ctx, qtx, tag.ID, func() error {
+			return h.reassign(ctx, qtx, tag, target)
+		})
+		if err != nil {
+			return err
+		}
+		events = evs
+		summary, err = tagSummary(ctx, qtx, target, todoIDs, input.DryRun)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !input.DryRun {
+		h.publish(events)
+	}
+
+	slog.Info("タグを削除", "id", input.ID, "reassign_to", input.ReassignTo, "todos", len(summary.TodoIDs), "dry_run", input.DryRun)
+	return &model.DeleteTagOutput{Body: summary}, nil
+}
+
+// reassign はtagを付けたTodoにtargetを付けてからtagを削除する。targetがnilの場合はTodoからtagを外すだけにする
+func (h *TagHandler) reassign(ctx context.Context, qtx *db.Queries, tag db.Tag, target *db.Tag) error {
+	if target != nil {
+		if err := qtx.ReassignTodoTags(ctx, db.ReassignTodoTagsParams{ToTagID: target.ID, FromTagID: tag.ID}); err != nil {
+			slog.Warn("タグの付け替えに失敗", "err", err)
+			return huma.Error500InternalServerError("タグの付け替えに失敗", err)
+		}
+	}
+	if _, err := qtx.DeleteTag(ctx, tag.ID); err != nil {
+		slog.Warn("タグの削除に失敗", "err", err)
+		return huma.Error500InternalServerError("タグの削除に失敗", err)
+	}
+	return nil
+}
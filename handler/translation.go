@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"go-huma-test/db"
+	"go-huma-test/locale"
+	"go-huma-test/model"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// languageTagPattern は訳のキーとして受け付ける言語タグ（BCP 47の言語と任意のサブタグ）
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+// encodeTranslations はTodoの訳を検証し、JSONにエンコードする。訳がない場合はNULLにする。
+func encodeTranslations(m map[string]model.TodoTranslation) (sql.NullString, error) {
+	if len(m) == 0 {
+		return sql.NullString{}, nil
+	}
+	seen := make(map[string]string, len(m))
+	for _, tag := range slices.Sorted(maps.Keys(m)) {
+		location := "body.translations." + tag
+		if !languageTagPattern.MatchString(tag) {
+			slog.Warn("訳の言語タグが不正です", "tag", tag)
+			return sql.NullString{}, huma.Error422UnprocessableEntity("訳の言語タグが不正です", &huma.ErrorDetail{
+				Location: location,
+				Message:  "enやpt-BRのような言語タグを指定してください",
+				Value:    tag,
+			})
+		}
+		// 言語タグは大文字と小文字を区別しないため、enとENのような重複は受け付けない
+		if other, ok := seen[strings.ToLower(tag)]; ok {
+			slog.Warn("訳の言語タグが重複しています", "tag", tag)
+			return sql.NullString{}, huma.Error422UnprocessableEntity("訳の言語タグが重複しています", &huma.ErrorDetail{
+				Location: location,
+				Message:  other + "と同じ言語タグです",
+				Value:    tag,
+			})
+		}
+		seen[strings.ToLower(tag)] = tag
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		slog.Warn("訳のエンコードに失敗", "err", err)
+		return sql.NullString{}, huma.Error500InternalServerError("訳のエンコードに失敗", err)
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+// decodeTranslations はJSONとして保存したTodoの訳を読み込む。訳がない場合はnilを返す。
+func decodeTranslations(s sql.NullString) map[string]model.TodoTranslation {
+	if !s.Valid {
+		return nil
+	}
+	var m map[string]model.TodoTranslation
+	if err := json.Unmarshal([]byte(s.String), &m); err != nil {
+		slog.Warn("保存済みの訳を読み込めません", "err", err)
+		return nil
+	}
+	return m
+}
+
+// localizeTodo はAccept-Languageヘッダーに合う訳があれば、レスポンスのタイトルと説明をその訳にする。
+// 元のタイトルは既定の言語で書かれているものとし、訳よりその言語が優先される場合は元のままにする。
+// 説明の訳がない場合は元の説明のままにする。
+func localizeTodo(ctx context.Context, resp *model.TodoResponse, t db.Todo) {
+	resp.Translations = decodeTranslations(t.Translations)
+	acceptLanguage, original := locale.AcceptLanguage(ctx)
+	if acceptLanguage == "" || len(resp.Translations) == 0 {
+		return
+	}
+	available := slices.Sorted(maps.Keys(resp.Translations))
+	if original != "" {
+		available = append(available, original)
+	}
+	tag, ok := locale.Match(acceptLanguage, available)
+	if !ok {
+		return
+	}
+	tr, ok := resp.Translations[tag]
+	if !ok {
+		return
+	}
+	resp.Title = tr.Title
+	if tr.Description != nil {
+		resp.Description = tr.Description
+	}
+	resp.Language = &tag
+}
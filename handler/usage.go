@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"go-huma-test/usage"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// defaultUsageDays は期間を省略した場合に集計する日数
+const defaultUsageDays = 30
+
+// maxUsageDays は一度に取得できる期間の最大の日数
+const maxUsageDays = 366
+
+// usageCSVHeader はCSVエクスポートの見出し行
+var usageCSVHeader = []string{"day", "user_id", "email", "api_calls", "storage_bytes", "active_todos"}
+
+// UsageHandler はユーザーごとの利用量を取得するハンドラー
+type UsageHandler struct {
+	queries *db.Queries
+}
+
+// NewUsageHandler はUsageHandlerの新しいインスタンスを生成する
+func NewUsageHandler(queries *db.Queries) *UsageHandler {
+	return &UsageHandler{
+		queries: queries,
+	}
+}
+
+// usageRange はリクエストの集計期間を解析する。省略した場合は今日までの30日間とする。
+func usageRange(input *model.UsageInput) (from, to string, err error) {
+	end := time.Now().UTC()
+	if input.To != "" {
+		if end, err = time.Parse(usage.DayLayout, input.To); err != nil {
+			return "", "", huma.Error422UnprocessableEntity("toの日付が不正です", &huma.ErrorDetail{Location: "query.to", Value: input.To})
+		}
+	}
+	start := end.AddDate(0, 0, -(defaultUsageDays - 1))
+	if input.From != "" {
+		if start, err = time.Parse(usage.DayLayout, input.From); err != nil {
+			return "", "", huma.Error422UnprocessableEntity("fromの日付が不正です", &huma.ErrorDetail{Location: "query.from", Value: input.From})
+		}
+	}
+
+	from, to = start.Format(usage.DayLayout), end.Format(usage.DayLayout)
+	if from > to {
+		return "", "", huma.Error422UnprocessableEntity("fromにはto以前の日付を指定してください", &huma.ErrorDetail{Location: "query.from", Value: input.From})
+	}
+	if end.Sub(start) >= maxUsageDays*24*time.Hour {
+		return "", "", huma.Error422UnprocessableEntity(fmt.Sprintf("期間は%d日以内で指定してください", maxUsageDays), &huma.ErrorDetail{Location: "query.from", Value: input.From})
+	}
+	return from, to, nil
+}
+
+// listUsage は集計期間の日次の利用量を取得する
+func (h *UsageHandler) listUsage(ctx context.Context, input *model.UsageInput) (from, to string, rows []db.ListUsageRow, err error) {
+	if from, to, err = usageRange(input); err != nil {
+		return "", "", nil, err
+	}
+	rows, err = h.queries.ListUsage(ctx, db.ListUsageParams{
+		FromDay: from,
+		ToDay:   to,
+		UserID:  sql.NullInt64{Int64: input.UserID, Valid: input.UserID > 0},
+	})
+	if err != nil {
+		slog.Warn("利用量の取得に失敗", "from", from, "to", to, "err", err)
+		return "", "", nil, huma.Error500InternalServerError("利用量の取得に失敗", err)
+	}
+	return from, to, rows, nil
+}
+
+// GetUsage はユーザーごとの日次の利用量と期間全体の合計を取得する
+func (h *UsageHandler) GetUsage(ctx context.Context, input *model.UsageInput) (*model.UsageOutput, error) {
+	from, to, rows, err := h.listUsage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &model.UsageOutput{}
+	output.Body.From = from
+	output.Body.To = to
+	output.Body.Days = make([]model.DailyUsage, len(rows))
+	output.Body.Totals = []model.UsageTotal{}
+	totals := make(map[int64]int)
+	for i, r := range rows {
+		output.Body.Days[i] = model.DailyUsage{
+			Day:          r.Day,
+			UserID:       r.UserID,
+			Email:        r.Email,
+			APICalls:     r.ApiCalls,
+			StorageBytes: r.StorageBytes,
+			ActiveTodos:  r.ActiveTodos,
+		}
+
+		idx, ok := totals[r.UserID]
+		if !ok {
+			idx = len(output.Body.Totals)
+			totals[r.UserID] = idx
+			output.Body.Totals = append(output.Body.Totals, model.UsageTotal{UserID: r.UserID, Email: r.Email})
+		}
+		t := &output.Body.Totals[idx]
+		t.APICalls += r.ApiCalls
+		t.MaxStorageBytes = max(t.MaxStorageBytes, r.StorageBytes)
+		t.MaxActiveTodos = max(t.MaxActiveTodos, r.ActiveTodos)
+	}
+	return output, nil
+}
+
+// ExportUsage は日次の利用量をCSVでエクスポートする
+func (h *UsageHandler) ExportUsage(ctx context.Context, input *model.UsageInput) (*model.ExportUsageOutput, error) {
+	from, to, rows, err := h.listUsage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(usageCSVHeader)
+	for _, r := range rows {
+		_ = w.Write([]string{
+			r.Day,
+			strconv.FormatInt(r.UserID, 10),
+			r.Email,
+			strconv.FormatInt(r.ApiCalls, 10),
+			strconv.FormatInt(r.StorageBytes, 10),
+			strconv.FormatInt(r.ActiveTodos, 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		slog.Warn("利用量のCSVの書き込みに失敗", "err", err)
+		return nil, huma.Error500InternalServerError("利用量のCSVの書き込みに失敗", err)
+	}
+
+	return &model.ExportUsageOutput{
+		ContentType:        "text/csv; charset=utf-8",
+		ContentDisposition: fmt.Sprintf(`attachment; filename="usage-%s-%s.csv"`, from, to),
+		Body:               buf.Bytes(),
+	}, nil
+}
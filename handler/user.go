@@ -0,0 +1,526 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/audit"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
+	"go-huma-test/confirm"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/model"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// dummyPasswordHash は存在しないメールアドレスでログインした場合にも同じ時間をかけて照合するためのハッシュ。
+// 応答時間の差から登録済みのメールアドレスを推測されないようにする。
+var dummyPasswordHash = sync.OnceValue(func() string {
+	h, err := auth.HashPassword("")
+	if err != nil {
+		slog.Warn("ダミーのパスワードハッシュの生成に失敗", "err", err)
+	}
+	return h
+})
+
+// UserHandler はユーザーの登録とログインを処理するハンドラー
+type UserHandler struct {
+	queries     *db.Queries
+	db          *sql.DB
+	signer      *auth.Signer
+	recorder    *audit.Recorder
+	defaultRole auth.Role
+	bus         *event.Bus
+	confirmer   *confirm.Confirmer
+	inviteOnly  bool
+	clock       clock.Clock
+
+	// bootstrapHash は初期管理者を作成するトークンのハッシュ。作成済みか、トークンを発行していない場合は空
+	bootstrapMu   sync.Mutex
+	bootstrapHash string
+}
+
+// NewUserHandler はUserHandlerの新しいインスタンスを生成する。
+// 最初に登録したユーザーはadmin、以降のユーザーはdefaultRoleになる。
+// inviteOnlyの場合は招待されたメールアドレスのみ登録でき、初期管理者はbootstrapで作成する。
+// ユーザーの削除はconfirmerの確認トークンで2段階で実行する。
+func NewUserHandler(queries *db.Queries, db *sql.DB, signer *auth.Signer, recorder *audit.Recorder, defaultRole auth.Role, bus *event.Bus, confirmer *confirm.Confirmer, inviteOnly bool, clock clock.Clock) *UserHandler {
+	return &UserHandler{
+		queries:     queries,
+		db:          db,
+		signer:      signer,
+		recorder:    recorder,
+		defaultRole: defaultRole,
+		bus:         bus,
+		confirmer:   confirmer,
+		inviteOnly:  inviteOnly,
+		clock:       clock,
+	}
+}
+
+// toUserResponse はdb.Userをmodel.UserResponseに変換する
+func toUserResponse(u db.User) model.UserResponse {
+	return model.UserResponse{
+		ID:        u.ID,
+		Email:     u.Email,
+		Name:      u.Name,
+		Role:      u.Role,
+		CreatedAt: u.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// record は認証イベントを記録する。記録に失敗してもリクエストは処理する。
+func (h *UserHandler) record(ctx context.Context, subject, eventType string) {
+	client := audit.ClientFrom(ctx)
+	if err := h.recorder.Record(ctx, audit.Attempt{
+		Subject:   subject,
+		EventType: eventType,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	}); err != nil {
+		slog.Warn("認証イベントの記録に失敗", "err", err)
+	}
+}
+
+// issueToken はユーザーのアクセストークンを発行する
+func (h *UserHandler) issueToken(ctx context.Context, u db.User) (model.AuthTokenBody, error) {
+	subject := auth.UserSubject(u.ID)
+	token, expiresAt, err := h.signer.Sign(ctx, subject)
+	if err != nil {
+		slog.Warn("アクセストークンの発行に失敗", "user_id", u.ID, "err", err)
+		return model.AuthTokenBody{}, huma.Error500InternalServerError("アクセストークンの発行に失敗", err)
+	}
+	h.record(ctx, subject, audit.EventTokenIssued)
+
+	return model.AuthTokenBody{
+		User:        toUserResponse(u),
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// newUserRole は新しく登録するユーザーのロールを返す。
+// 最初のユーザーが他のユーザーのロールを管理できるよう、adminにする。
+func (h *UserHandler) newUserRole(ctx context.Context, q *db.Queries) (auth.Role, error) {
+	count, err := q.CountUsers(ctx)
+	if err != nil {
+		slog.Warn("ユーザー数の取得に失敗", "err", err)
+		return "", huma.Error500InternalServerError("ユーザー数の取得に失敗", err)
+	}
+	if count == 0 {
+		return auth.RoleAdmin, nil
+	}
+	return h.defaultRole, nil
+}
+
+// admission は新しく登録するユーザーのロールと、登録に使う招待を表す構造体
+type admission struct {
+	role auth.Role
+	// invitationID は使う招待のID。招待なしで登録する場合は0
+	invitationID int64
+}
+
+// admit は新しく登録するユーザーを受け入れるかを判断し、ロールと使う招待を返す。
+// invitationTokenを指定した場合は、メールアドレスが一致する有効な招待のロールにする。
+// 招待制では招待のない登録を403で拒否するが、OIDCプロバイダーが確認したメールアドレス（verified）はトークンなしで招待と照合する。
+func (h *UserHandler) admit(ctx context.Context, q *db.Queries, email, invitationToken string, verified bool) (admission, error) {
+	now := h.clock.Now().UTC()
+	if invitationToken != "" {
+		inv, err := q.GetInvitationByTokenHash(ctx, auth.HashAPIKey(invitationToken))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("招待の取得に失敗", "err", err)
+			return admission{}, huma.Error500InternalServerError("招待の取得に失敗", err)
+		}
+		if err != nil || !strings.EqualFold(inv.Email, email) || inv.AcceptedAt.Valid || !inv.ExpiresAt.After(now) {
+			slog.Warn("招待が無効です", "email", email)
+			return admission{}, huma.Error403Forbidden("招待が無効か、使用済みまたは期限切れです", &huma.ErrorDetail{Location: "body.invitation_token"})
+		}
+		return admission{role: auth.Role(inv.Role), invitationID: inv.ID}, nil
+	}
+
+	if !h.inviteOnly {
+		role, err := h.newUserRole(ctx, q)
+		return admission{role: role}, err
+	}
+	if verified {
+		inv, err := q.GetPendingInvitationByEmail(ctx, db.GetPendingInvitationByEmailParams{Email: email, Now: now})
+		if err == nil {
+			return admission{role: auth.Role(inv.Role), invitationID: inv.ID}, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("招待の取得に失敗", "err", err)
+			return admission{}, huma.Error500InternalServerError("招待の取得に失敗", err)
+		}
+	}
+
+	count, err := q.CountUsers(ctx)
+	if err != nil {
+		slog.Warn("ユーザー数の取得に失敗", "err", err)
+		return admission{}, huma.Error500InternalServerError("ユーザー数の取得に失敗", err)
+	}
+	if count == 0 {
+		slog.Warn("招待制のため初期管理者は登録できません", "email", email)
+		return admission{}, huma.Error403Forbidden("招待制のため、初期管理者はbootstrapサブコマンドかPOST /auth/bootstrapで作成してください")
+	}
+	slog.Warn("招待されていないメールアドレスの登録を拒否", "email", email)
+	return admission{}, huma.Error403Forbidden("招待制のため、招待されたメールアドレスのみ登録できます")
+}
+
+// accept は登録したユーザーが使った招待を使用済みにする。同時に使われた招待は409を返す
+func (h *UserHandler) accept(ctx context.Context, q *db.Queries, a admission, userID int64) error {
+	if a.invitationID == 0 {
+		return nil
+	}
+	n, err := q.AcceptInvitation(ctx, db.AcceptInvitationParams{
+		AcceptedAt: sql.NullTime{Time: h.clock.Now().UTC(), Valid: true},
+		UserID:     sql.NullInt64{Int64: userID, Valid: true},
+		ID:         a.invitationID,
+	})
+	if err != nil {
+		slog.Warn("招待の使用の記録に失敗", "invitation_id", a.invitationID, "err", err)
+		return huma.Error500InternalServerError("招待の使用の記録に失敗", err)
+	}
+	if n == 0 {
+		slog.Warn("招待は使用済みです", "invitation_id", a.invitationID)
+		return huma.Error409Conflict("招待は使用済みです")
+	}
+	return nil
+}
+
+// Signup はユーザーを登録し、アクセストークンを発行する
+func (h *UserHandler) Signup(ctx context.Context, input *model.SignupInput) (*model.SignupOutput, error) {
+	email := strings.TrimSpace(input.Body.Email)
+	passwordHash, err := auth.HashPassword(input.Body.Password)
+	if err != nil {
+		slog.Warn("パスワードのハッシュ化に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("パスワードのハッシュ化に失敗", err)
+	}
+
+	var user db.User
+	err = runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		if _, err := qtx.GetUserByEmail(ctx, email); err == nil {
+			slog.Warn("メールアドレスは登録済みです", "email", email)
+			return huma.Error409Conflict("メールアドレスは登録済みです")
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("ユーザーの取得に失敗", "err", err)
+			return huma.Error500InternalServerError("ユーザーの取得に失敗", err)
+		}
+
+		a, err := h.admit(ctx, qtx, email, input.Body.InvitationToken, false)
+		if err != nil {
+			return err
+		}
+
+		user, err = qtx.CreateUser(ctx, db.CreateUserParams{
+			Email:        email,
+			Name:         input.Body.Name,
+			PasswordHash: passwordHash,
+			Role:         string(a.role),
+		})
+		if err != nil {
+			slog.Warn("ユーザーの登録に失敗", "err", err)
+			return huma.Error500InternalServerError("ユーザーの登録に失敗", err)
+		}
+		return h.accept(ctx, qtx, a, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("ユーザーを登録", "user_id", user.ID, "role", user.Role)
+
+	body, err := h.issueToken(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &model.SignupOutput{Body: body}, nil
+}
+
+// StartBootstrap はユーザーが1人もいない場合に、初期管理者を1回だけ作成できるトークンを発行して返す。
+// トークンは再起動で無効になり、ユーザーがいる場合は発行せずに空文字を返す。
+func (h *UserHandler) StartBootstrap(ctx context.Context) (string, error) {
+	count, err := h.queries.CountUsers(ctx)
+	if err != nil || count > 0 {
+		return "", err
+	}
+	token, err := auth.NewBootstrapToken()
+	if err != nil {
+		return "", err
+	}
+	h.bootstrapMu.Lock()
+	h.bootstrapHash = auth.HashAPIKey(token)
+	h.bootstrapMu.Unlock()
+	return token, nil
+}
+
+// Bootstrap は起動時に発行したトークンを照合し、初期管理者をadminとして登録してアクセストークンを発行する。
+// ユーザーが1人もいない間だけ使え、作成するとトークンは無効になる。
+func (h *UserHandler) Bootstrap(ctx context.Context, input *model.BootstrapInput) (*model.BootstrapOutput, error) {
+	h.bootstrapMu.Lock()
+	defer h.bootstrapMu.Unlock()
+	if h.bootstrapHash == "" || subtle.ConstantTimeCompare([]byte(auth.HashAPIKey(input.Body.Token)), []byte(h.bootstrapHash)) != 1 {
+		slog.Warn("初期管理者の作成トークンが無効です")
+		return nil, huma.Error403Forbidden("トークンが無効か、初期管理者は作成済みです", &huma.ErrorDetail{Location: "body.token"})
+	}
+
+	passwordHash, err := auth.HashPassword(input.Body.Password)
+	if err != nil {
+		slog.Warn("パスワードのハッシュ化に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("パスワードのハッシュ化に失敗", err)
+	}
+	var user db.User
+	err = runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		count, err := qtx.CountUsers(ctx)
+		if err != nil {
+			slog.Warn("ユーザー数の取得に失敗", "err", err)
+			return huma.Error500InternalServerError("ユーザー数の取得に失敗", err)
+		}
+		if count > 0 {
+			slog.Warn("ユーザーが登録済みのため初期管理者は作成できません")
+			return huma.Error409Conflict("ユーザーが登録済みのため、初期管理者は作成できません")
+		}
+		user, err = qtx.CreateUser(ctx, db.CreateUserParams{
+			Email:        strings.TrimSpace(input.Body.Email),
+			Name:         input.Body.Name,
+			PasswordHash: passwordHash,
+			Role:         string(auth.RoleAdmin),
+		})
+		if err != nil {
+			slog.Warn("初期管理者の登録に失敗", "err", err)
+			return huma.Error500InternalServerError("初期管理者の登録に失敗", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.bootstrapHash = ""
+	slog.Info("初期管理者を登録", "user_id", user.ID)
+
+	body, err := h.issueToken(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &model.BootstrapOutput{Body: body}, nil
+}
+
+// Login はメールアドレスとパスワードを照合し、アクセストークンを発行する
+func (h *UserHandler) Login(ctx context.Context, input *model.LoginInput) (*model.LoginOutput, error) {
+	email := strings.TrimSpace(input.Body.Email)
+	user, err := h.queries.GetUserByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("ユーザーの取得に失敗", "err", err)
+			return nil, huma.Error500InternalServerError("ユーザーの取得に失敗", err)
+		}
+		_ = auth.VerifyPassword(dummyPasswordHash(), input.Body.Password)
+		slog.Warn("ログインに失敗", "reason", "unknown email")
+		h.record(ctx, audit.Subject(strings.ToLower(email)), audit.EventLoginFailed)
+		return nil, huma.Error401Unauthorized("メールアドレスまたはパスワードが正しくありません")
+	}
+
+	// OIDCで登録したユーザーはパスワードを持たないため、パスワードではログインできない
+	if user.PasswordHash == "" {
+		_ = auth.VerifyPassword(dummyPasswordHash(), input.Body.Password)
+		slog.Warn("ログインに失敗", "user_id", user.ID, "reason", "no password")
+		h.record(ctx, auth.UserSubject(user.ID), audit.EventLoginFailed)
+		return nil, huma.Error401Unauthorized("メールアドレスまたはパスワードが正しくありません")
+	}
+	if err := auth.VerifyPassword(user.PasswordHash, input.Body.Password); err != nil {
+		if !errors.Is(err, auth.ErrPasswordMismatch) {
+			slog.Warn("パスワードの照合に失敗", "user_id", user.ID, "err", err)
+			return nil, huma.Error500InternalServerError("パスワードの照合に失敗", err)
+		}
+		slog.Warn("ログインに失敗", "user_id", user.ID, "reason", "password mismatch")
+		h.record(ctx, auth.UserSubject(user.ID), audit.EventLoginFailed)
+		return nil, huma.Error401Unauthorized("メールアドレスまたはパスワードが正しくありません")
+	}
+	h.record(ctx, auth.UserSubject(user.ID), audit.EventLoginSucceeded)
+
+	body, err := h.issueToken(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LoginOutput{Body: body}, nil
+}
+
+// ListUsers はユーザーの一覧を取得する
+func (h *UserHandler) ListUsers(ctx context.Context, _ *struct{}) (*model.ListUsersOutput, error) {
+	users, err := h.queries.ListUsers(ctx)
+	if err != nil {
+		slog.Warn("ユーザー一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("ユーザー一覧の取得に失敗", err)
+	}
+
+	output := &model.ListUsersOutput{}
+	output.Body.Users = make([]model.UserResponse, len(users))
+	for i, u := range users {
+		output.Body.Users[i] = toUserResponse(u)
+	}
+	return output, nil
+}
+
+// getUser は指定されたIDのユーザーを取得する
+func getUser(ctx context.Context, q *db.Queries, id int64) (db.User, error) {
+	u, err := q.GetUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("ユーザーIDが見つかりません", "id", id)
+			return db.User{}, huma.Error404NotFound(fmt.Sprintf("ユーザーIDが見つかりません: %d", id))
+		}
+		slog.Warn("ユーザーの取得に失敗", "err", err)
+		return db.User{}, huma.Error500InternalServerError("ユーザーの取得に失敗", err)
+	}
+	return u, nil
+}
+
+// ensureNotLastAdmin はadminのユーザーが最後のadminでないことを確認する。
+// adminがいなくなると誰もロールを管理できなくなるため、最後のadminの場合はmessageの409を返す。
+func ensureNotLastAdmin(ctx context.Context, q *db.Queries, u db.User, message string) error {
+	if auth.Role(u.Role) != auth.RoleAdmin {
+		return nil
+	}
+	admins, err := q.CountUsersByRole(ctx, string(auth.RoleAdmin))
+	if err != nil {
+		slog.Warn("adminの人数の取得に失敗", "err", err)
+		return huma.Error500InternalServerError("adminの人数の取得に失敗", err)
+	}
+	if admins <= 1 {
+		slog.Warn(message, "id", u.ID)
+		return huma.Error409Conflict(message)
+	}
+	return nil
+}
+
+// UpdateUserRole はユーザーのロールを変更する。
+// adminがいなくなると誰もロールを管理できなくなるため、最後のadminは降格できない。
+func (h *UserHandler) UpdateUserRole(ctx context.Context, input *model.UpdateUserRoleInput) (*model.UpdateUserRoleOutput, error) {
+	var user db.User
+	err := runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		current, err := getUser(ctx, qtx, input.ID)
+		if err != nil {
+			return err
+		}
+
+		if auth.Role(current.Role) == auth.RoleAdmin && auth.Role(input.Body.Role) != auth.RoleAdmin {
+			if err := ensureNotLastAdmin(ctx, qtx, current, "最後のadminは降格できません"); err != nil {
+				return err
+			}
+		}
+
+		user, err = qtx.UpdateUserRole(ctx, db.UpdateUserRoleParams{Role: input.Body.Role, ID: input.ID})
+		if err != nil {
+			slog.Warn("ロールの変更に失敗", "err", err)
+			return huma.Error500InternalServerError("ロールの変更に失敗", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("ユーザーのロールを変更", "user_id", user.ID, "role", user.Role)
+
+	return &model.UpdateUserRoleOutput{Body: toUserResponse(user)}, nil
+}
+
+// confirmDeleteUser はユーザーの削除を確認トークンで確認する操作の名前
+const confirmDeleteUser = "delete-user"
+
+// userDeletionImpact はユーザーの削除で一緒に削除されるTodoと、失効するAPIキーの件数を返す。
+// 削除するTodoも返し、アクティビティログへの記録に用いる。
+func userDeletionImpact(ctx context.Context, q *db.Queries, id int64) (confirm.Impact, []db.Todo, error) {
+	todos, err := q.ListTodosByOwner(ctx, sql.NullInt64{Int64: id, Valid: true})
+	if err != nil {
+		slog.Warn("ユーザーのTodo取得に失敗", "err", err)
+		return nil, nil, huma.Error500InternalServerError("ユーザーのTodo取得に失敗", err)
+	}
+	keys, err := q.CountActiveAPIKeysByCreator(ctx, auth.UserSubject(id))
+	if err != nil {
+		slog.Warn("ユーザーのAPIキーの件数の取得に失敗", "err", err)
+		return nil, nil, huma.Error500InternalServerError("ユーザーのAPIキーの件数の取得に失敗", err)
+	}
+	return confirm.Impact{"todos": int64(len(todos)), "api_keys": keys}, todos, nil
+}
+
+// DeleteUser はユーザーを、所有するTodoや共有とともに削除し、ユーザーが作成したAPIキーを失効させる。
+// 取り消せないため、確認トークンを省略した場合は削除せずに影響範囲と確認トークンを202で返す。
+// 最後のadminは削除できない。
+func (h *UserHandler) DeleteUser(ctx context.Context, input *model.DeleteUserInput) (*model.DeleteUserOutput, error) {
+	output := &model.DeleteUserOutput{Status: http.StatusOK}
+	target := strconv.FormatInt(input.ID, 10)
+
+	if input.ConfirmationToken == "" {
+		u, err := getUser(ctx, h.queries, input.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := ensureNotLastAdmin(ctx, h.queries, u, "最後のadminは削除できません"); err != nil {
+			return nil, err
+		}
+		impact, _, err := userDeletionImpact(ctx, h.queries, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		confirmation, err := issueConfirmation(ctx, h.confirmer, confirmDeleteUser, target, impact)
+		if err != nil {
+			return nil, err
+		}
+		output.Status = http.StatusAccepted
+		output.Body.Message = "Confirmation required"
+		output.Body.Confirmation = confirmation
+		return output, nil
+	}
+
+	var deleted []db.Todo
+	err := runInTx(ctx, h.db, h.queries, false, func(qtx *db.Queries) error {
+		u, err := getUser(ctx, qtx, input.ID)
+		if err != nil {
+			return err
+		}
+		if err := ensureNotLastAdmin(ctx, qtx, u, "最後のadminは削除できません"); err != nil {
+			return err
+		}
+		impact, todos, err := userDeletionImpact(ctx, qtx, u.ID)
+		if err != nil {
+			return err
+		}
+		if err := consumeConfirmation(ctx, h.confirmer, qtx, input.ConfirmationToken, confirmDeleteUser, target, impact); err != nil {
+			return err
+		}
+
+		if _, err := qtx.RevokeAPIKeysByCreator(ctx, auth.UserSubject(u.ID)); err != nil {
+			slog.Warn("APIキーの失効に失敗", "err", err)
+			return huma.Error500InternalServerError("APIキーの失効に失敗", err)
+		}
+		if err := recordDeletions(ctx, qtx, todos); err != nil {
+			return err
+		}
+		if _, err := qtx.DeleteUser(ctx, u.ID); err != nil {
+			slog.Warn("ユーザーの削除に失敗", "err", err)
+			return huma.Error500InternalServerError("ユーザーの削除に失敗", err)
+		}
+		deleted = todos
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("ユーザーを削除", "user_id", input.ID, "todos", len(deleted))
+
+	for _, t := range deleted {
+		h.bus.Publish(event.Event{Type: event.TodoDeleted, TodoID: t.ID, OwnerID: t.OwnerID})
+	}
+
+	output.Body.Message = "User deleted successfully"
+	return output, nil
+}
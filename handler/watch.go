@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"go-huma-test/auth"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// WatchHandler はTodoのウォッチに関する操作を処理するハンドラー。
+// ウォッチしたTodoの変更とコメントは、担当者や所有者でなくてもWatchNotifierが通知する。
+type WatchHandler struct {
+	queries *db.Queries
+}
+
+// NewWatchHandler はWatchHandlerの新しいインスタンスを生成する
+func NewWatchHandler(queries *db.Queries) *WatchHandler {
+	return &WatchHandler{
+		queries: queries,
+	}
+}
+
+// toWatcherResponse はdb.ListWatchersByTodoIDsRowをmodel.WatcherResponseに変換する
+func toWatcherResponse(w db.ListWatchersByTodoIDsRow) model.WatcherResponse {
+	return model.WatcherResponse{
+		UserID:    w.UserID,
+		Name:      w.Name,
+		CreatedAt: w.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// watchingUser はウォッチを操作する認証したユーザーのIDを返す。通知の宛先がないため、ユーザー以外の認証主体はウォッチできない。
+func watchingUser(ctx context.Context) (int64, error) {
+	id, ok := auth.UserIDFrom(ctx)
+	if !ok {
+		slog.Warn("ユーザー以外の認証主体はウォッチを操作できません")
+		return 0, huma.Error403Forbidden("ウォッチはユーザーとして認証した場合のみ操作できます")
+	}
+	return id, nil
+}
+
+// WatchTodo は認証したユーザーに指定されたIDのTodoをウォッチさせ、ウォッチしているユーザーを返す。
+// 閲覧できるTodoであればウォッチでき、既にウォッチしている場合も成功とする。
+func (h *WatchHandler) WatchTodo(ctx context.Context, input *model.WatchTodoInput) (*model.WatchTodoOutput, error) {
+	userID, err := watchingUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureTodoExists(ctx, h.queries, input.ID); err != nil {
+		return nil, err
+	}
+
+	if err := h.queries.WatchTodo(ctx, db.WatchTodoParams{TodoID: input.ID, UserID: userID}); err != nil {
+		slog.Warn("Todoのウォッチに失敗", "id", input.ID, "err", err)
+		return nil, huma.Error500InternalServerError("Todoのウォッチに失敗", err)
+	}
+
+	watchers, err := h.queries.ListWatchersByTodoIDs(ctx, []int64{input.ID})
+	if err != nil {
+		slog.Warn("ウォッチしているユーザーの取得に失敗", "id", input.ID, "err", err)
+		return nil, huma.Error500InternalServerError("ウォッチしているユーザーの取得に失敗", err)
+	}
+	output := &model.WatchTodoOutput{}
+	output.Body.TodoID = input.ID
+	output.Body.Watchers = make([]model.WatcherResponse, len(watchers))
+	for i, w := range watchers {
+		output.Body.Watchers[i] = toWatcherResponse(w)
+	}
+	return output, nil
+}
+
+// UnwatchTodo は認証したユーザーによる指定されたIDのTodoのウォッチを解除する。
+// 共有を取り消されて閲覧できなくなったTodoでも、ウォッチしていれば解除できる。
+func (h *WatchHandler) UnwatchTodo(ctx context.Context, input *model.UnwatchTodoInput) (*model.UnwatchTodoOutput, error) {
+	userID, err := watchingUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := h.queries.UnwatchTodo(ctx, db.UnwatchTodoParams{TodoID: input.ID, UserID: userID})
+	if err != nil {
+		slog.Warn("Todoのウォッチの解除に失敗", "id", input.ID, "err", err)
+		return nil, huma.Error500InternalServerError("Todoのウォッチの解除に失敗", err)
+	}
+	if n == 0 {
+		// ウォッチしていない場合も成功とするが、閲覧できないTodoは存在を明かさないよう404にする
+		if err := ensureTodoExists(ctx, h.queries, input.ID); err != nil {
+			return nil, err
+		}
+	}
+	output := &model.UnwatchTodoOutput{}
+	output.Body.Message = "Todo unwatched successfully"
+	return output, nil
+}
+
+// BulkUnwatchTodos は認証したユーザーによる指定されたIDのTodoのウォッチをまとめて解除する。
+// ウォッチしていないIDや存在しないIDは無視する。
+func (h *WatchHandler) BulkUnwatchTodos(ctx context.Context, input *model.BulkUnwatchTodosInput) (*model.BulkUnwatchTodosOutput, error) {
+	userID, err := watchingUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := h.queries.UnwatchTodos(ctx, db.UnwatchTodosParams{UserID: userID, TodoIds: input.Body.IDs})
+	if err != nil {
+		slog.Warn("Todoのウォッチの一括解除に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("Todoのウォッチの一括解除に失敗", err)
+	}
+	output := &model.BulkUnwatchTodosOutput{}
+	output.Body.Count = n
+	return output, nil
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/model"
+	"go-huma-test/scheduler"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// WebhookHandler はユーザーが登録するTodoのイベントのWebhookに関する操作を処理するハンドラー
+type WebhookHandler struct {
+	queries *db.Queries
+}
+
+// NewWebhookHandler はWebhookHandlerの新しいインスタンスを生成する
+func NewWebhookHandler(queries *db.Queries) *WebhookHandler {
+	return &WebhookHandler{
+		queries: queries,
+	}
+}
+
+// WebhookTodo は配信の本文に含めるTodoを、APIのレスポンスと同じ形式にする
+func WebhookTodo(ctx context.Context, t db.Todo) any {
+	return toTodoResponse(ctx, t)
+}
+
+// toWebhookResponse はdb.Webhookをmodel.WebhookResponseに変換する
+func toWebhookResponse(w db.Webhook) model.WebhookResponse {
+	events := []string{}
+	if err := json.Unmarshal([]byte(w.Events), &events); err != nil {
+		slog.Warn("Webhookの購読イベントを解析できません", "id", w.ID, "err", err)
+	}
+	return model.WebhookResponse{
+		ID:        w.ID,
+		URL:       w.Url,
+		Events:    events,
+		Active:    w.Active == 1,
+		CreatedAt: w.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: w.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// toWebhookDeliveryResponse はdb.WebhookDeliveryをmodel.WebhookDeliveryResponseに変換する
+func toWebhookDeliveryResponse(d db.WebhookDelivery) model.WebhookDeliveryResponse {
+	resp := model.WebhookDeliveryResponse{
+		ID:             d.ID,
+		Event:          d.Event,
+		TodoID:         d.TodoID,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: nullInt64ToPtr(d.ResponseStatus),
+		CreatedAt:      d.CreatedAt.Format(time.RFC3339),
+		DeliveredAt:    nullTimeToPtr(d.DeliveredAt),
+	}
+	if d.Status == scheduler.WebhookPending {
+		next := d.NextAttemptAt.UTC().Format(time.RFC3339)
+		resp.NextAttemptAt = &next
+	}
+	if d.LastError.Valid {
+		resp.LastError = &d.LastError.String
+	}
+	return resp
+}
+
+// validateWebhookBody はURLがhttpまたはhttpsであることを検証し、購読イベントをJSONにエンコードする
+func validateWebhookBody(body model.WebhookBody) (string, error) {
+	u, err := url.Parse(body.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", huma.Error422UnprocessableEntity("URLはhttpまたはhttpsで指定してください", &huma.ErrorDetail{
+			Location: "body.url",
+			Value:    body.URL,
+		})
+	}
+	events, err := json.Marshal(body.Events)
+	if err != nil {
+		return "", huma.Error500InternalServerError("購読イベントのエンコードに失敗", err)
+	}
+	return string(events), nil
+}
+
+// getWebhook は認証済みユーザーのWebhookを取得する。他のユーザーのWebhookは存在しないものとして扱う
+func (h *WebhookHandler) getWebhook(ctx context.Context, id int64) (db.Webhook, error) {
+	w, err := h.queries.GetWebhook(ctx, db.GetWebhookParams{ID: id, OwnerID: ownerID(ctx)})
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Webhook{}, huma.Error404NotFound(fmt.Sprintf("WebhookIDが見つかりません: %d", id))
+	}
+	if err != nil {
+		slog.Warn("Webhookの取得に失敗", "id", id, "err", err)
+		return db.Webhook{}, huma.Error500InternalServerError("Webhookの取得に失敗", err)
+	}
+	return w, nil
+}
+
+// ListWebhooks は認証済みユーザーのWebhookの一覧を取得する
+func (h *WebhookHandler) ListWebhooks(ctx context.Context, _ *struct{}) (*model.ListWebhooksOutput, error) {
+	hooks, err := h.queries.ListWebhooks(ctx, ownerID(ctx))
+	if err != nil {
+		slog.Warn("Webhook一覧の取得に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("Webhook一覧の取得に失敗", err)
+	}
+
+	output := &model.ListWebhooksOutput{}
+	output.Body.Webhooks = make([]model.WebhookResponse, len(hooks))
+	for i, w := range hooks {
+		output.Body.Webhooks[i] = toWebhookResponse(w)
+	}
+	return output, nil
+}
+
+// CreateWebhook はWebhookを登録する。署名の鍵を生成し、この応答でのみ返す。
+func (h *WebhookHandler) CreateWebhook(ctx context.Context, input *model.CreateWebhookInput) (*model.CreateWebhookOutput, error) {
+	events, err := validateWebhookBody(input.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		slog.Warn("Webhookの署名の鍵の生成に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("Webhookの署名の鍵の生成に失敗", err)
+	}
+	secret := hex.EncodeToString(b)
+
+	w, err := h.queries.CreateWebhook(ctx, db.CreateWebhookParams{
+		OwnerID: ownerID(ctx),
+		Url:     input.Body.URL,
+		Events:  events,
+		Secret:  secret,
+	})
+	if err != nil {
+		slog.Warn("Webhookの登録に失敗", "err", err)
+		return nil, huma.Error500InternalServerError("Webhookの登録に失敗", err)
+	}
+	slog.Info("Webhookを登録", "id", w.ID, "events", w.Events)
+
+	output := &model.CreateWebhookOutput{}
+	output.Body.WebhookResponse = toWebhookResponse(w)
+	output.Body.Secret = secret
+	return output, nil
+}
+
+// GetWebhook は指定されたIDのWebhookを取得する
+func (h *WebhookHandler) GetWebhook(ctx context.Context, input *model.GetWebhookInput) (*model.GetWebhookOutput, error) {
+	w, err := h.getWebhook(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.GetWebhookOutput{Body: toWebhookResponse(w)}, nil
+}
+
+// UpdateWebhook は指定されたIDのWebhookのURLと購読イベントを置き換える。署名の鍵は変更しない。
+func (h *WebhookHandler) UpdateWebhook(ctx context.Context, input *model.UpdateWebhookInput) (*model.GetWebhookOutput, error) {
+	events, err := validateWebhookBody(input.Body.WebhookBody)
+	if err != nil {
+		return nil, err
+	}
+	current, err := h.getWebhook(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	active := current.Active
+	if input.Body.Active != nil {
+		active = 0
+		if *input.Body.Active {
+			active = 1
+		}
+	}
+
+	w, err := h.queries.UpdateWebhook(ctx, db.UpdateWebhookParams{
+		Url:     input.Body.URL,
+		Events:  events,
+		Active:  active,
+		ID:      input.ID,
+		OwnerID: ownerID(ctx),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, huma.Error404NotFound(fmt.Sprintf("WebhookIDが見つかりません: %d", input.ID))
+	}
+	if err != nil {
+		slog.Warn("Webhookの更新に失敗", "id", input.ID, "err", err)
+		return nil, huma.Error500InternalServerError("Webhookの更新に失敗", err)
+	}
+	return &model.GetWebhookOutput{Body: toWebhookResponse(w)}, nil
+}
+
+// DeleteWebhook は指定されたIDのWebhookと配信の記録を削除する。送信前の配信は送信しない。
+func (h *WebhookHandler) DeleteWebhook(ctx context.Context, input *model.GetWebhookInput) (*model.DeleteWebhookOutput, error) {
+	n, err := h.queries.DeleteWebhook(ctx, db.DeleteWebhookParams{ID: input.ID, OwnerID: ownerID(ctx)})
+	if err != nil {
+		slog.Warn("Webhookの削除に失敗", "id", input.ID, "err", err)
+		return nil, huma.Error500InternalServerError("Webhookの削除に失敗", err)
+	}
+	if n == 0 {
+		return nil, huma.Error404NotFound(fmt.Sprintf("WebhookIDが見つかりません: %d", input.ID))
+	}
+
+	output := &model.DeleteWebhookOutput{}
+	output.Body.Message = "Webhook deleted successfully"
+	return output, nil
+}
+
+// ListWebhookDeliveries は指定されたIDのWebhookへの配信の記録を新しい順に取得する
+func (h *WebhookHandler) ListWebhookDeliveries(ctx context.Context, input *model.ListWebhookDeliveriesInput) (*model.ListWebhookDeliveriesOutput, error) {
+	if _, err := h.getWebhook(ctx, input.ID); err != nil {
+		return nil, err
+	}
+
+	// 次のページがあるかを判定するため1件多く取得する
+	deliveries, err := h.queries.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		WebhookID: input.ID,
+		BeforeID:  input.Cursor,
+		Limit:     input.Limit + 1,
+	})
+	if err != nil {
+		slog.Warn("Webhookの配信の記録の取得に失敗", "id", input.ID, "err", err)
+		return nil, huma.Error500InternalServerError("Webhookの配信の記録の取得に失敗", err)
+	}
+
+	output := &model.ListWebhookDeliveriesOutput{}
+	if int64(len(deliveries)) > input.Limit {
+		deliveries = deliveries[:input.Limit]
+		output.Body.NextCursor = &deliveries[len(deliveries)-1].ID
+	}
+	output.Body.Deliveries = make([]model.WebhookDeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		output.Body.Deliveries[i] = toWebhookDeliveryResponse(d)
+	}
+	return output, nil
+}
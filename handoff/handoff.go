@@ -0,0 +1,221 @@
+// Package handoff は待ち受け中のソケットを新しいプロセスに引き継ぎ、リクエストを落とさずにサーバーを再起動する機能を提供する。
+// 古いプロセスはソケットのファイル記述子を継承させて新しいプロセスを起動し、新しいプロセスが受け付けを始めてから停止する。
+// 引き継ぎの間は両方のプロセスが同じソケットで受け付けるため、カーネルに積まれた接続は取りこぼされない。
+package handoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 新しいプロセスに引き継ぐものを伝える環境変数
+const (
+	// listenersEnv は継承したリスナーの名前をファイル記述子3からの順にカンマ区切りで並べる
+	listenersEnv = "TODO_HANDOFF_LISTENERS"
+	// readyEnv は受け付けの開始を古いプロセスに伝えるパイプのファイル記述子
+	readyEnv = "TODO_HANDOFF_READY_FD"
+)
+
+// firstFD はexec.CmdのExtraFilesで渡したファイルの最初のファイル記述子
+const firstFD = 3
+
+// drainPollInterval は受け付けた接続がリクエストを送るのを待つ間の確認の間隔
+const drainPollInterval = 10 * time.Millisecond
+
+var (
+	inheritOnce sync.Once
+	inherited   map[string]net.Listener
+	inheritErr  error
+)
+
+// Listener は新しいプロセスに引き継いだ後に受け付けを止められるリスナー
+type Listener struct {
+	net.Listener
+
+	paused    atomic.Bool
+	parked    chan struct{}
+	parkOnce  sync.Once
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Listen はnameのリスナーを古いプロセスから継承していればそれを、継承していなければaddrで新しく待ち受けたリスナーを返す。
+// 継承したリスナーは古いプロセスで待ち受けていたアドレスのままになる。
+func Listen(name, addr string) (*Listener, error) {
+	inheritOnce.Do(func() {
+		inherited, inheritErr = inheritListeners()
+	})
+	if inheritErr != nil {
+		return nil, inheritErr
+	}
+	ln, ok := inherited[name]
+	if ok {
+		delete(inherited, name)
+	} else {
+		var err error
+		if ln, err = net.Listen("tcp", addr); err != nil {
+			return nil, err
+		}
+	}
+	return &Listener{Listener: ln, parked: make(chan struct{}), closed: make(chan struct{})}, nil
+}
+
+// Accept は接続を受け付ける。Pauseの後はCloseされるまで待ち、接続を受け付けない。
+func (l *Listener) Accept() (net.Conn, error) {
+	if l.paused.Load() {
+		return l.park()
+	}
+	c, err := l.Listener.Accept()
+	if err != nil && l.paused.Load() {
+		var ne net.Error
+		if errors.As(err, &ne) && ne.Timeout() {
+			return l.park()
+		}
+	}
+	return c, err
+}
+
+// park はPauseの後の受け付けを止め、Closeされるまで待つ
+func (l *Listener) park() (net.Conn, error) {
+	l.parkOnce.Do(func() {
+		close(l.parked)
+	})
+	<-l.closed
+	return nil, net.ErrClosed
+}
+
+// Pause は接続の受け付けを止め、同じソケットを共有する新しいプロセスにすべての接続を受け付けさせる。
+// ソケットは閉じないため、カーネルが受け付けた接続は新しいプロセスが引き取る。
+// 止める直前に受け付けた接続をhttp.Serverが登録し終えるよう、次のAcceptの呼び出しまで待つ。
+func (l *Listener) Pause(ctx context.Context) {
+	l.paused.Store(true)
+	// 待機中のAcceptをタイムアウトさせて戻す
+	if d, ok := l.Listener.(interface{ SetDeadline(time.Time) error }); ok {
+		_ = d.SetDeadline(time.Now())
+	}
+	select {
+	case <-l.parked:
+	case <-ctx.Done():
+	}
+}
+
+// Close はリスナーを閉じる
+func (l *Listener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+	})
+	return l.Listener.Close()
+}
+
+// NewConns はhttp.ServerのConnStateに設定し、受け付けてからリクエストをまだ読み込んでいない接続を数える。
+// http.Serverはシャットダウンの開始後に最初のリクエストを読み込んだ接続を応答せずに閉じるため、
+// 受け付けを止めてからそれらの接続がリクエストを送るのを待ってシャットダウンする。
+type NewConns struct {
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// ConnState は接続の状態の変化を記録する
+func (n *NewConns) ConnState(c net.Conn, state http.ConnState) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if state == http.StateNew {
+		if n.conns == nil {
+			n.conns = make(map[net.Conn]struct{})
+		}
+		n.conns[c] = struct{}{}
+		return
+	}
+	delete(n.conns, c)
+}
+
+// Wait はリクエストを読み込んでいない接続がなくなるまで待つ。ctxが終了した場合はfalseを返す。
+func (n *NewConns) Wait(ctx context.Context) bool {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		n.mu.Lock()
+		remaining := len(n.conns)
+		n.mu.Unlock()
+		if remaining == 0 {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// Inherited はこのプロセスが古いプロセスから引き継いで起動されたかを返す
+func Inherited() bool {
+	return os.Getenv(listenersEnv) != ""
+}
+
+// inheritListeners は環境変数で伝えられたファイル記述子からリスナーを復元する
+func inheritListeners() (map[string]net.Listener, error) {
+	listeners := make(map[string]net.Listener)
+	names := os.Getenv(listenersEnv)
+	if names == "" {
+		return listeners, nil
+	}
+	for i, name := range strings.Split(names, ",") {
+		f := os.NewFile(uintptr(firstFD+i), name)
+		if f == nil {
+			return nil, fmt.Errorf("継承したリスナーがありません: %s", name)
+		}
+		ln, err := net.FileListener(f)
+		// FileListenerはファイル記述子を複製するため、元のファイルは閉じる
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("継承したリスナーを復元できません: %s: %w", name, err)
+		}
+		listeners[name] = ln
+	}
+	return listeners, nil
+}
+
+// Ready は受け付けを始めたことを古いプロセスに伝える。引き継いで起動されていない場合は何もしない。
+func Ready() error {
+	fd := os.Getenv(readyEnv)
+	if fd == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return fmt.Errorf("%sが不正です: %s", readyEnv, fd)
+	}
+	f := os.NewFile(uintptr(n), "ready")
+	if f == nil {
+		return errors.New("古いプロセスへのパイプがありません")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	if _, err := f.Write([]byte{1}); err != nil {
+		return fmt.Errorf("古いプロセスへの通知に失敗: %w", err)
+	}
+	return nil
+}
+
+// childEnv は新しいプロセスの環境変数を返す。自身が継承した際の値は引き継ぐ内容で置き換える。
+func childEnv(names []string) []string {
+	env := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		return strings.HasPrefix(kv, listenersEnv+"=") || strings.HasPrefix(kv, readyEnv+"=")
+	})
+	return append(env,
+		listenersEnv+"="+strings.Join(names, ","),
+		readyEnv+"="+strconv.Itoa(firstFD+len(names)),
+	)
+}
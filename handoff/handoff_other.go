@@ -0,0 +1,30 @@
+//go:build !unix
+
+package handoff
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// errUnsupported はソケットの継承とファイルロックに対応していないOSで返すエラー
+var errUnsupported = errors.New("このOSではリスナーを引き継いだ再起動に対応していません")
+
+// Restart はこのOSでは対応していないため、常にエラーを返す
+func Restart(string, map[string]*Listener, time.Duration) (int, error) {
+	return 0, errUnsupported
+}
+
+// Lock はプロセスの間で排他するファイルロック。このOSでは排他しない。
+type Lock struct{}
+
+// AcquireLock はこのOSでは排他せずにロックを返す。再起動に対応していないため、プロセスが重なることはない。
+func AcquireLock(context.Context, string) (*Lock, error) {
+	return &Lock{}, nil
+}
+
+// Release はロックを解放する
+func (l *Lock) Release() error {
+	return nil
+}
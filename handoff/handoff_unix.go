@@ -0,0 +1,127 @@
+//go:build unix
+
+package handoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// lockRetryInterval はロックを保持する他のプロセスが解放するのを待つ間隔
+const lockRetryInterval = 100 * time.Millisecond
+
+// Restart はlistenersを継承させてexeを同じ引数で起動し、新しいプロセスが受け付けを始めるまで待って、そのプロセスIDを返す。
+// timeoutまでに受け付けを始めない、または終了した場合は新しいプロセスを止めてエラーを返し、呼び出し側は受け付けを続ける。
+func Restart(exe string, listeners map[string]*Listener, timeout time.Duration) (int, error) {
+	names := slices.Sorted(maps.Keys(listeners))
+	files := make([]*os.File, 0, len(names)+1)
+	defer func() {
+		// 新しいプロセスには複製されるため、こちらのファイルは閉じる
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, name := range names {
+		ln, ok := listeners[name].Listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			return 0, fmt.Errorf("リスナーを引き継げません: %s", name)
+		}
+		f, err := ln.File()
+		if err != nil {
+			return 0, fmt.Errorf("リスナーのファイル記述子を取得できません: %s: %w", name, err)
+		}
+		files = append(files, f)
+	}
+
+	ready, notify, err := os.Pipe()
+	if err != nil {
+		return 0, fmt.Errorf("新しいプロセスとのパイプの作成に失敗: %w", err)
+	}
+	defer func() {
+		_ = ready.Close()
+	}()
+	files = append(files, notify)
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = childEnv(names)
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("新しいプロセスの起動に失敗: %w", err)
+	}
+	// 新しいプロセスが終了した場合に回収する
+	go func() {
+		_ = cmd.Wait()
+	}()
+	// 新しいプロセスが通知せずに終了した場合に読み込みがEOFで終わるよう、書き込み側を閉じる
+	_ = notify.Close()
+	files = files[:len(files)-1]
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := ready.Read(make([]byte, 1))
+		if errors.Is(err, io.EOF) {
+			err = errors.New("新しいプロセスが受け付けを始める前に終了しました")
+		}
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			_ = cmd.Process.Kill()
+			return 0, err
+		}
+		return cmd.Process.Pid, nil
+	case <-time.After(timeout):
+		_ = cmd.Process.Kill()
+		return 0, fmt.Errorf("新しいプロセスが%s以内に受け付けを始めませんでした", timeout)
+	}
+}
+
+// Lock はプロセスの間で排他するファイルロック
+type Lock struct {
+	f *os.File
+}
+
+// AcquireLock はpathのファイルの排他ロックを取得する。他のプロセスが保持している場合は解放されるかctxが終了するまで待つ。
+// ロックはプロセスの終了時にも解放されるため、異常終了したプロセスのロックが残ることはない。
+func AcquireLock(ctx context.Context, path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("ロックファイルを開けません: %w", err)
+	}
+	ticker := time.NewTicker(lockRetryInterval)
+	defer ticker.Stop()
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			return &Lock{f: f}, nil
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			_ = f.Close()
+			return nil, fmt.Errorf("ロックの取得に失敗: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			_ = f.Close()
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Release はロックを解放する
+func (l *Lock) Release() error {
+	return l.f.Close()
+}
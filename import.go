@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/handler"
+	"go-huma-test/importer"
+	"go-huma-test/model"
+	"go-huma-test/secrets"
+	"os"
+
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+)
+
+// importActor はCLIからのインポートをアクティビティログに記録する実行者
+const importActor = "cli:import"
+
+// newImportCommand はAppleのリマインダーやGoogle Tasksからプロジェクトを取り込むimportサブコマンドを生成する。
+// サーバーを起動せずにデータベースへ直接取り込み、作成したプロジェクトをJSONで標準出力に書き出す。
+func newImportCommand() *cobra.Command {
+	var (
+		file         string
+		owner        string
+		accessToken  string
+		refreshToken string
+		dryRun       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:       "import {apple-reminders|google-tasks}",
+		Short:     "Import lists and tasks from Apple Reminders or Google Tasks as projects",
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{importer.SourceAppleReminders, importer.SourceGoogleTasks},
+		Run: humacli.WithOptions(func(cmd *cobra.Command, args []string, o *model.Options) {
+			req := model.ImportRequest{
+				Source:       args[0],
+				AccessToken:  accessToken,
+				RefreshToken: refreshToken,
+				DryRun:       dryRun,
+			}
+			if req.Source == importer.SourceAppleReminders {
+				if file == "" {
+					fmt.Fprintln(os.Stderr, "apple-remindersには--fileでiCalendarファイルを指定してください")
+					os.Exit(1)
+				}
+				b, err := os.ReadFile(file)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+				req.ICalendar = string(b)
+			}
+
+			imported, err := runImport(cmd.Context(), o, req, owner)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				// 失敗までに作成したプロジェクトがなければ結果は出力しない
+				if len(imported) == 0 {
+					os.Exit(1)
+				}
+			}
+
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(map[string]any{"dry_run": dryRun, "projects": imported}); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			if err != nil {
+				os.Exit(1)
+			}
+		}),
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "iCalendar (VTODO) file exported from Apple Reminders")
+	cmd.Flags().StringVar(&owner, "owner", "", "Email of the user who will own the imported todos")
+	cmd.Flags().StringVar(&accessToken, "access-token", "", "Google OAuth access token with the tasks.readonly scope")
+	cmd.Flags().StringVar(&refreshToken, "refresh-token", "", "Google OAuth refresh token issued to google-client-id, used instead of --access-token")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report what would be imported without committing")
+	return cmd
+}
+
+// runImport はデータベースを開いてインポート元から取り込み、作成したプロジェクトを返す。
+// ownerが空でない場合はそのメールアドレスのユーザーを取り込んだTodoの所有者にする。
+func runImport(ctx context.Context, o *model.Options, req model.ImportRequest, owner string) ([]model.ImportedProject, error) {
+	sqlDB, err := initDB(o.DatabaseURL, false, o.AutoMigrate)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+
+	queries, err := db.Prepare(ctx, sqlDB)
+	if err != nil {
+		return nil, fmt.Errorf("データベースのPrepareに失敗: %w", err)
+	}
+
+	provider, err := secrets.ParseProviders(o.SecretProviders, secrets.VaultConfig{
+		Addr:  o.VaultAddr,
+		Token: os.Getenv("VAULT_TOKEN"),
+		Path:  o.VaultPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("秘密情報プロバイダーの設定が不正です: %w", err)
+	}
+
+	ctx = activity.WithActor(ctx, importActor)
+	if owner != "" {
+		u, err := queries.GetUserByEmail(ctx, owner)
+		if err != nil {
+			return nil, fmt.Errorf("所有者のユーザーが見つかりません: %s: %w", owner, err)
+		}
+		ctx = auth.WithUserID(ctx, u.ID)
+	}
+
+	// CLIではサーバーが動いていないため、イベントの購読者はいない
+	projects := handler.NewProjectHandler(queries, sqlDB, event.NewBus(), nil, nil, clock.System)
+	h := handler.NewImportHandler(queries, projects, importer.NewGoogleTasks(importer.GoogleConfig{
+		ClientID: o.GoogleClientID,
+		Secrets:  secrets.NewManager(provider, o.SecretTTL),
+	}))
+
+	bundles, err := h.Fetch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return h.ImportBundles(ctx, bundles, req.DryRun)
+}
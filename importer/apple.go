@@ -0,0 +1,234 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go-huma-test/model"
+	"go-huma-test/recurrence"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidICalendar はiCalendarとして解析できない入力を表すエラー
+var ErrInvalidICalendar = errors.New("iCalendarとして解析できません")
+
+// maxICalendarLine は読み込むiCalendarの1行（折り返しを戻す前）の最大長
+const maxICalendarLine = 1024 * 1024
+
+// property はiCalendarのプロパティ（NAME;PARAM=VALUE:値）を表す構造体
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseProperty は折り返しを戻した1行をプロパティに分解する。パラメーターの値の引用符は外す。
+func parseProperty(line string) (property, bool) {
+	// 値にはコロンを含められるため、引用符の外にある最初のコロンで区切る
+	quoted, sep := false, -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		}
+		if c == ':' && !quoted {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return property{}, false
+	}
+
+	parts := strings.Split(line[:sep], ";")
+	p := property{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string, len(parts)-1),
+		value:  line[sep+1:],
+	}
+	for _, param := range parts[1:] {
+		k, v, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return p, true
+}
+
+// unescapeText はTEXT型の値のエスケープ（\n、\,、\;、\\）を戻す
+func unescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// parseDateTime はDATEまたはDATE-TIME型の値を解析する。
+// UTC（末尾のZ）でもTZIDもない時刻はファイルを書き出した端末の時刻のため、UTCとして扱う。
+func parseDateTime(p property) (time.Time, error) {
+	if p.params["VALUE"] == "DATE" || len(p.value) == len("20060102") {
+		return time.Parse("20060102", p.value)
+	}
+	if strings.HasSuffix(p.value, "Z") {
+		return time.Parse("20060102T150405Z", p.value)
+	}
+	loc := time.UTC
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", p.value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// icalPriority はiCalendarのPRIORITY（1が最高、9が最低、0は未指定）をTodoの優先度に変換する。
+// RFC 5545の区分に合わせ、1〜4を高、5を中、6〜9を低とする。
+func icalPriority(v string) int64 {
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	switch {
+	case err != nil || n <= 0 || n > 9:
+		return 0
+	case n <= 4:
+		return 3
+	case n == 5:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// unfoldLines はiCalendarの行を読み込み、空白で始まる継続行を前の行につなげて返す
+func unfoldLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxICalendarLine)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// ParseAppleReminders はAppleのリマインダーから書き出したiCalendarファイルを解析し、
+// リストごとのバンドルを返す。1つのファイルに複数のVCALENDARを連結したものも受け付ける。
+// リスト名はX-WR-CALNAMEから、完了状態はSTATUS:COMPLETEDまたはCOMPLETEDから取得し、
+// RELATED-TO（RELTYPE=PARENTまたは省略）で親を指すリマインダーは親のサブタスクにする。
+func ParseAppleReminders(r io.Reader) ([]model.ProjectBundle, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidICalendar, err)
+	}
+
+	var (
+		lists   []*list
+		current *list
+		todo    *task
+		depth   int // VTODOの中にあるVALARMなどのコンポーネントの深さ
+	)
+	for n, line := range lines {
+		p, ok := parseProperty(line)
+		if !ok {
+			return nil, fmt.Errorf("%w: %d行目: %s", ErrInvalidICalendar, n+1, line)
+		}
+
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VCALENDAR"):
+			current = &list{}
+			lists = append(lists, current)
+		case p.name == "END" && strings.EqualFold(p.value, "VCALENDAR"):
+			current = nil
+		case current == nil:
+			return nil, fmt.Errorf("%w: %d行目がVCALENDARの外にあります", ErrInvalidICalendar, n+1)
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VTODO") && todo == nil:
+			todo = &task{}
+		case p.name == "END" && strings.EqualFold(p.value, "VTODO") && depth == 0 && todo != nil:
+			if strings.TrimSpace(todo.todo.Title) != "" {
+				current.tasks = append(current.tasks, *todo)
+			}
+			todo = nil
+		case todo != nil && p.name == "BEGIN":
+			depth++
+		case todo != nil && p.name == "END":
+			depth--
+		case todo == nil:
+			if p.name == "X-WR-CALNAME" {
+				current.name = unescapeText(p.value)
+			}
+		case depth > 0:
+			// VALARMなどのプロパティは取り込まない
+		default:
+			if err := todo.apply(p); err != nil {
+				return nil, fmt.Errorf("%w: %d行目: %w", ErrInvalidICalendar, n+1, err)
+			}
+		}
+	}
+	if current != nil || todo != nil {
+		return nil, fmt.Errorf("%w: ENDで閉じられていないコンポーネントがあります", ErrInvalidICalendar)
+	}
+	if len(lists) == 0 {
+		return nil, fmt.Errorf("%w: VCALENDARがありません", ErrInvalidICalendar)
+	}
+
+	bundles := make([]model.ProjectBundle, 0, len(lists))
+	for _, l := range lists {
+		bundles = append(bundles, l.bundle())
+	}
+	return bundles, nil
+}
+
+// apply はVTODOのプロパティをタスクに反映する
+func (t *task) apply(p property) error {
+	switch p.name {
+	case "UID":
+		t.id = p.value
+	case "SUMMARY":
+		t.todo.Title = truncate(strings.TrimSpace(unescapeText(p.value)), maxTitle)
+	case "DESCRIPTION":
+		t.todo.Description = optionalText(unescapeText(p.value), maxDescription)
+	case "STATUS":
+		t.todo.Completed = strings.EqualFold(p.value, "COMPLETED")
+	case "COMPLETED":
+		t.todo.Completed = true
+	case "PRIORITY":
+		t.todo.Priority = icalPriority(p.value)
+	case "DUE":
+		due, err := parseDateTime(p)
+		if err != nil {
+			return fmt.Errorf("DUEを解析できません: %s", p.value)
+		}
+		t.todo.DueAt = &due
+	case "RRULE":
+		// 対応していない繰り返しルールは取り込まずにTodoだけを取り込む
+		if _, err := recurrence.Parse(p.value); err == nil {
+			rule := p.value
+			t.todo.Recurrence = &rule
+		}
+	case "RELATED-TO":
+		if rel := p.params["RELTYPE"]; rel == "" || strings.EqualFold(rel, "PARENT") {
+			t.parent = p.value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/model"
+	"go-huma-test/secrets"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Google Tasks APIとOAuthのエンドポイント
+const (
+	GoogleTasksAPIURL = "https://tasks.googleapis.com/tasks/v1"
+	GoogleTokenURL    = "https://oauth2.googleapis.com/token"
+)
+
+// maxGoogleResponseBytes は読み込むGoogle Tasks APIの応答の最大サイズ
+const maxGoogleResponseBytes = 10 * 1024 * 1024
+
+// ErrGoogleAuth はGoogleのアクセストークンが取得できないか、APIに拒否されたことを表すエラー
+var ErrGoogleAuth = errors.New("Googleの認可に失敗しました")
+
+// GoogleConfig はGoogle Tasksのインポートの設定を表す構造体
+type GoogleConfig struct {
+	// ClientID はリフレッシュトークンを発行したOAuthクライアントのID
+	ClientID string
+	// Secrets はクライアントシークレット（secrets.GoogleClientSecret）を読み込むマネージャー
+	Secrets *secrets.Manager
+	// APIURL はGoogle Tasks APIのベースURL。空の場合はGoogleTasksAPIURLを使う。
+	APIURL string
+	// TokenURL はOAuthのトークンエンドポイント。空の場合はGoogleTokenURLを使う。
+	TokenURL string
+}
+
+// GoogleCredentials はGoogle Tasks APIを呼び出すための利用者の資格情報を表す構造体。
+// AccessTokenを優先し、ない場合はRefreshTokenからアクセストークンを取得する。
+type GoogleCredentials struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// GoogleTasks はGoogle Tasks APIからタスクリストとタスクを取得する
+type GoogleTasks struct {
+	cfg    GoogleConfig
+	client *http.Client
+}
+
+// NewGoogleTasks はGoogleTasksの新しいインスタンスを生成する
+func NewGoogleTasks(cfg GoogleConfig) *GoogleTasks {
+	if cfg.APIURL == "" {
+		cfg.APIURL = GoogleTasksAPIURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = GoogleTokenURL
+	}
+	return &GoogleTasks{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// googleTaskList はGoogle Tasks APIのタスクリストを表す構造体
+type googleTaskList struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// googleTask はGoogle Tasks APIのタスクを表す構造体
+type googleTask struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Notes   string `json:"notes"`
+	Status  string `json:"status"`
+	Due     string `json:"due"`
+	Parent  string `json:"parent"`
+	Deleted bool   `json:"deleted"`
+}
+
+// Fetch はすべてのタスクリストを取得し、リストごとのバンドルを返す。
+// 完了済みと非表示のタスクも含め、削除済みのタスクとタイトルのないタスクは除く。
+func (g *GoogleTasks) Fetch(ctx context.Context, creds GoogleCredentials) ([]model.ProjectBundle, error) {
+	token, err := g.accessToken(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	var lists []googleTaskList
+	if err := g.paginate(ctx, token, "/users/@me/lists", url.Values{"maxResults": {"100"}}, func(raw json.RawMessage) error {
+		var page []googleTaskList
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		lists = append(lists, page...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("タスクリストの取得に失敗: %w", err)
+	}
+
+	bundles := make([]model.ProjectBundle, 0, len(lists))
+	for _, tl := range lists {
+		var tasks []googleTask
+		query := url.Values{
+			"maxResults":    {"100"},
+			"showCompleted": {"true"},
+			"showHidden":    {"true"},
+		}
+		if err := g.paginate(ctx, token, "/lists/"+url.PathEscape(tl.ID)+"/tasks", query, func(raw json.RawMessage) error {
+			var page []googleTask
+			if err := json.Unmarshal(raw, &page); err != nil {
+				return err
+			}
+			tasks = append(tasks, page...)
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("タスクリスト%sのタスクの取得に失敗: %w", tl.Title, err)
+		}
+
+		l := list{name: tl.Title}
+		for _, t := range tasks {
+			if t.Deleted || strings.TrimSpace(t.Title) == "" {
+				continue
+			}
+			l.tasks = append(l.tasks, t.task())
+		}
+		bundles = append(bundles, l.bundle())
+	}
+	return bundles, nil
+}
+
+// task はGoogle Tasksのタスクをインポートするタスクに変換する
+func (t googleTask) task() task {
+	it := task{
+		id:     t.ID,
+		parent: t.Parent,
+		todo: model.BundleTodo{
+			Title:       truncate(strings.TrimSpace(t.Title), maxTitle),
+			Description: optionalText(t.Notes, maxDescription),
+			Completed:   t.Status == "completed",
+		},
+	}
+	// 期限は日付のみが有効で、時刻は常に0時（UTC）になる
+	if due, err := time.Parse(time.RFC3339, t.Due); err == nil {
+		it.todo.DueAt = &due
+	}
+	return it
+}
+
+// paginate はnextPageTokenがなくなるまで一覧のAPIを呼び出し、各ページのitemsをfnに渡す
+func (g *GoogleTasks) paginate(ctx context.Context, token, path string, query url.Values, fn func(json.RawMessage) error) error {
+	for {
+		var page struct {
+			Items         json.RawMessage `json:"items"`
+			NextPageToken string          `json:"nextPageToken"`
+		}
+		if err := g.get(ctx, token, path+"?"+query.Encode(), &page); err != nil {
+			return err
+		}
+		if len(page.Items) > 0 {
+			if err := fn(page.Items); err != nil {
+				return fmt.Errorf("応答を解析できません: %w", err)
+			}
+		}
+		if page.NextPageToken == "" {
+			return nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// get はGoogle Tasks APIを呼び出し、JSONの応答をvに読み込む
+func (g *GoogleTasks) get(ctx context.Context, token, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(g.cfg.APIURL, "/")+path, nil)
+	if err != nil {
+		return fmt.Errorf("リクエストの作成に失敗: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Google Tasks APIに接続できません: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: Google Tasks APIがアクセスを拒否しました: %s", ErrGoogleAuth, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("Google Tasks APIがエラーを返しました: %s", resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxGoogleResponseBytes)).Decode(v)
+}
+
+// accessToken は資格情報からAPIの呼び出しに使うアクセストークンを返す。
+// リフレッシュトークンの場合はトークンエンドポイントでアクセストークンと交換する。
+func (g *GoogleTasks) accessToken(ctx context.Context, creds GoogleCredentials) (string, error) {
+	if creds.AccessToken != "" {
+		return creds.AccessToken, nil
+	}
+	if creds.RefreshToken == "" {
+		return "", fmt.Errorf("%w: アクセストークンかリフレッシュトークンが必要です", ErrGoogleAuth)
+	}
+	if g.cfg.ClientID == "" {
+		return "", fmt.Errorf("%w: リフレッシュトークンを使うにはgoogle-client-idが必要です", ErrGoogleAuth)
+	}
+	clientSecret, _, err := g.cfg.Secrets.Lookup(ctx, secrets.GoogleClientSecret)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {creds.RefreshToken},
+		"client_id":     {g.cfg.ClientID},
+	}
+	// シークレットがない場合は公開クライアントとしてclient_idのみを送る
+	if clientSecret != "" {
+		form.Set("client_secret", clientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("トークンのリクエスト作成に失敗: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("トークンエンドポイントに接続できません: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxGoogleResponseBytes)).Decode(&token); err != nil {
+		return "", fmt.Errorf("トークンの応答を読み込めません: HTTP %d", resp.StatusCode)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("%w: %s %s", ErrGoogleAuth, token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("%w: アクセストークンが返されませんでした: HTTP %d", ErrGoogleAuth, resp.StatusCode)
+	}
+	return token.AccessToken, nil
+}
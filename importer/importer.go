@@ -0,0 +1,149 @@
+// Package importer は外部のタスク管理サービスのデータをプロジェクトのバンドルに変換する。
+// サービスのリストをプロジェクトに、タスクをTodoに、親を持つタスクを親のTodoのサブタスクに、ラベルをTodoのタグに対応させる。
+// 変換したバンドルはプロジェクトのインポートと同じ処理で取り込む。
+package importer
+
+import (
+	"go-huma-test/model"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// インポート元のサービス
+const (
+	// SourceAppleReminders はAppleのリマインダーから書き出したiCalendar（VTODO）ファイル
+	SourceAppleReminders = "apple-reminders"
+	// SourceGoogleTasks はGoogle Tasks API
+	SourceGoogleTasks = "google-tasks"
+	// SourceTodoist はTodoistのSync APIまたはREST APIから書き出したJSON
+	SourceTodoist = "todoist"
+	// SourceTrello はTrelloのボードから書き出したJSON
+	SourceTrello = "trello"
+)
+
+// FileAdapter はインポート元のサービスから書き出したファイルを解析し、リストごとのバンドルを返す
+type FileAdapter func(r io.Reader) ([]model.ProjectBundle, error)
+
+// fileAdapters はファイルを受け付けるインポート元ごとのアダプター。新しいインポート元はここに加える
+var fileAdapters = map[string]FileAdapter{
+	SourceAppleReminders: ParseAppleReminders,
+	SourceTodoist:        ParseTodoist,
+	SourceTrello:         ParseTrello,
+}
+
+// FileAdapterFor はインポート元のファイルを解析するアダプターを返す。ファイルを受け付けないインポート元はfalseを返す
+func FileAdapterFor(source string) (FileAdapter, bool) {
+	a, ok := fileAdapters[source]
+	return a, ok
+}
+
+// FileSources はファイルを受け付けるインポート元を名前の順に返す
+func FileSources() []string {
+	sources := make([]string, 0, len(fileAdapters))
+	for s := range fileAdapters {
+		sources = append(sources, s)
+	}
+	slices.Sort(sources)
+	return sources
+}
+
+// バンドルの各項目の最大長。インポートの操作の入力の検証と合わせる
+const (
+	maxProjectName = 100
+	maxTitle       = 200
+	maxDescription = 1000
+	maxTagName     = 50
+	maxTags        = 20
+)
+
+// untitledList はリストの名前がない場合に使うプロジェクト名
+const untitledList = "インポートしたリスト"
+
+// truncate は文字列を最大文字数で切り詰める
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// optionalText は空白のみの文字列をnilにし、それ以外は最大文字数で切り詰める
+func optionalText(s string, n int) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	s = truncate(s, n)
+	return &s
+}
+
+// labelTags はラベルの名前をTodoに付けるタグの名前にする。空白のみの名前と重複は除き、ラベルがない場合はnilを返す
+func labelTags(labels []string) []string {
+	var tags []string
+	for _, l := range labels {
+		l = truncate(strings.TrimSpace(l), maxTagName)
+		if l == "" || slices.Contains(tags, l) {
+			continue
+		}
+		if len(tags) == maxTags {
+			break
+		}
+		tags = append(tags, l)
+	}
+	return tags
+}
+
+// list はインポート元のリストを組み立てる途中の状態を表す構造体
+type list struct {
+	name  string
+	tasks []task
+}
+
+// task はインポート元のタスクを表す構造体。parentが空でない場合は同じリストのタスクのサブタスクになる。
+type task struct {
+	id     string
+	parent string
+	todo   model.BundleTodo
+}
+
+// bundle はリストをバンドルに変換する。
+// 親が同じリストにあるタスクは親のサブタスクにし、親が見つからないタスクはTodoとして取り込む。
+func (l *list) bundle() model.ProjectBundle {
+	name := strings.TrimSpace(l.name)
+	if name == "" {
+		name = untitledList
+	}
+
+	b := model.ProjectBundle{
+		Version: model.BundleVersion,
+		Project: model.BundleProject{Name: truncate(name, maxProjectName)},
+		Todos:   []model.BundleTodo{},
+	}
+
+	refs := make(map[string]int, len(l.tasks))
+	for _, t := range l.tasks {
+		if t.parent == "" {
+			refs[t.id] = len(b.Todos)
+			t.todo.Ref = int64(len(b.Todos) + 1)
+			b.Todos = append(b.Todos, t.todo)
+		}
+	}
+	for _, t := range l.tasks {
+		if t.parent == "" {
+			continue
+		}
+		i, ok := refs[t.parent]
+		if !ok {
+			t.todo.Ref = int64(len(b.Todos) + 1)
+			b.Todos = append(b.Todos, t.todo)
+			continue
+		}
+		b.Todos[i].Subtasks = append(b.Todos[i].Subtasks, model.BundleSubtask{
+			Title:     t.todo.Title,
+			Completed: t.todo.Completed,
+		})
+	}
+	return b
+}
@@ -0,0 +1,188 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/model"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTodoist はTodoistから書き出したJSONとして解析できない入力を表すエラー
+var ErrInvalidTodoist = errors.New("TodoistのJSONとして解析できません")
+
+// todoistID はTodoistのID。Sync API v9以降は文字列、それより前は数値で表すため、どちらも受け付ける
+type todoistID string
+
+func (id *todoistID) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*id = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*id = todoistID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("IDは文字列か数値で指定してください: %s", b)
+	}
+	*id = todoistID(n.String())
+	return nil
+}
+
+// todoistProject はTodoistのプロジェクトを表す構造体
+type todoistProject struct {
+	ID        todoistID `json:"id"`
+	Name      string    `json:"name"`
+	IsDeleted bool      `json:"is_deleted"`
+}
+
+// todoistDue はTodoistのタスクの期限を表す構造体。
+// Sync APIはdateに日付か日時を、REST APIはdateに日付を、datetimeに日時を入れる
+type todoistDue struct {
+	Date     string `json:"date"`
+	Datetime string `json:"datetime"`
+	Timezone string `json:"timezone"`
+}
+
+// todoistItem はTodoistのタスクを表す構造体。Sync APIのitemsとREST APIのtasksのどちらの形式も受け付ける
+type todoistItem struct {
+	ID          todoistID   `json:"id"`
+	ProjectID   todoistID   `json:"project_id"`
+	ParentID    todoistID   `json:"parent_id"`
+	Content     string      `json:"content"`
+	Description string      `json:"description"`
+	Checked     bool        `json:"checked"`
+	IsCompleted bool        `json:"is_completed"`
+	Priority    int64       `json:"priority"`
+	Due         *todoistDue `json:"due"`
+	Labels      []string    `json:"labels"`
+	IsDeleted   bool        `json:"is_deleted"`
+}
+
+// todoistExport はTodoistから書き出したJSONを表す構造体
+type todoistExport struct {
+	Projects []todoistProject `json:"projects"`
+	Items    []todoistItem    `json:"items"`
+	Tasks    []todoistItem    `json:"tasks"`
+}
+
+// ParseTodoist はTodoistのSync APIの同期結果（projectsとitems）またはREST APIの一覧（projectsとtasks）を
+// 1つのJSONオブジェクトにまとめたものを解析し、プロジェクトごとのバンドルを返す。
+// ラベルはTodoのmetadataのtagsに、期限はdue_atに、親を持つタスクは親のサブタスクにする。
+// 削除済みのプロジェクトとタスクは除き、プロジェクトが見つからないタスクは名前のないリストにまとめる。
+func ParseTodoist(r io.Reader) ([]model.ProjectBundle, error) {
+	var export todoistExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTodoist, err)
+	}
+	items := slices.Concat(export.Items, export.Tasks)
+	if len(export.Projects) == 0 && len(items) == 0 {
+		return nil, fmt.Errorf("%w: projectsとitems（またはtasks）がありません", ErrInvalidTodoist)
+	}
+
+	var lists []*list
+	byProject := make(map[todoistID]*list, len(export.Projects))
+	for _, p := range export.Projects {
+		if p.IsDeleted {
+			continue
+		}
+		l := &list{name: p.Name}
+		lists = append(lists, l)
+		byProject[p.ID] = l
+	}
+
+	var orphans *list
+	for _, item := range items {
+		if item.IsDeleted || strings.TrimSpace(item.Content) == "" {
+			continue
+		}
+		l, ok := byProject[item.ProjectID]
+		if !ok {
+			if orphans == nil {
+				orphans = &list{}
+				lists = append(lists, orphans)
+			}
+			l = orphans
+		}
+		t, err := item.task()
+		if err != nil {
+			return nil, fmt.Errorf("%w: タスク%s: %w", ErrInvalidTodoist, item.ID, err)
+		}
+		l.tasks = append(l.tasks, t)
+	}
+
+	bundles := make([]model.ProjectBundle, 0, len(lists))
+	for _, l := range lists {
+		bundles = append(bundles, l.bundle())
+	}
+	return bundles, nil
+}
+
+// task はTodoistのタスクをインポートするタスクに変換する
+func (i todoistItem) task() (task, error) {
+	t := task{
+		id:     string(i.ID),
+		parent: string(i.ParentID),
+		todo: model.BundleTodo{
+			Title:       truncate(strings.TrimSpace(i.Content), maxTitle),
+			Description: optionalText(i.Description, maxDescription),
+			Completed:   i.Checked || i.IsCompleted,
+			Priority:    todoistPriority(i.Priority),
+			Tags:        labelTags(i.Labels),
+		},
+	}
+	if i.Due != nil {
+		due, err := i.Due.at()
+		if err != nil {
+			return task{}, err
+		}
+		if !due.IsZero() {
+			t.todo.DueAt = &due
+		}
+	}
+	return t, nil
+}
+
+// at は期限の日時を返す。日付のみの期限は0時（UTC）、タイムゾーンのない日時はtimezoneの時刻として扱う。
+// Todoistの繰り返しは自然言語で表すためRRULEに変換できず、次の期限だけを取り込む
+func (d todoistDue) at() (time.Time, error) {
+	value := d.Datetime
+	if value == "" {
+		value = d.Date
+	}
+	switch {
+	case value == "":
+		return time.Time{}, nil
+	case len(value) == len(time.DateOnly):
+		return time.Parse(time.DateOnly, value)
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	loc := time.UTC
+	if d.Timezone != "" {
+		if l, err := time.LoadLocation(d.Timezone); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04:05", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("期限を解析できません: %s", value)
+	}
+	return t.UTC(), nil
+}
+
+// todoistPriority はTodoistの優先度（4が最高のp1、1が既定のp4）をTodoの優先度に変換する
+func todoistPriority(p int64) int64 {
+	if p < 1 || p > 4 {
+		return 0
+	}
+	return p - 1
+}
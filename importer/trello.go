@@ -0,0 +1,155 @@
+package importer
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/model"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTrello はTrelloのボードから書き出したJSONとして解析できない入力を表すエラー
+var ErrInvalidTrello = errors.New("TrelloのボードのJSONとして解析できません")
+
+// trelloList はTrelloのリストを表す構造体
+type trelloList struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+// trelloLabel はTrelloのラベルを表す構造体。名前のないラベルは色で区別する
+type trelloLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// trelloCard はTrelloのカードを表す構造体
+type trelloCard struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Desc        string        `json:"desc"`
+	IDList      string        `json:"idList"`
+	Closed      bool          `json:"closed"`
+	Due         string        `json:"due"`
+	DueComplete bool          `json:"dueComplete"`
+	Labels      []trelloLabel `json:"labels"`
+	Pos         float64       `json:"pos"`
+}
+
+// trelloCheckItem はTrelloのチェックリストの項目を表す構造体
+type trelloCheckItem struct {
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+// trelloChecklist はTrelloのチェックリストを表す構造体
+type trelloChecklist struct {
+	IDCard     string            `json:"idCard"`
+	Pos        float64           `json:"pos"`
+	CheckItems []trelloCheckItem `json:"checkItems"`
+}
+
+// trelloBoard はTrelloのボードのメニューから書き出したJSONを表す構造体
+type trelloBoard struct {
+	Name       string            `json:"name"`
+	Lists      []trelloList      `json:"lists"`
+	Cards      []trelloCard      `json:"cards"`
+	Checklists []trelloChecklist `json:"checklists"`
+}
+
+// ParseTrello はTrelloのボードから書き出したJSONを解析し、リストごとのバンドルを返す。
+// カードをTodoに、ラベルをTodoのmetadataのtagsに、期限をdue_atに、期限の完了を完了状態に、
+// チェックリストの項目をサブタスクにする。アーカイブしたリストとカードは除き、リストとカードはボードの並び順にする。
+func ParseTrello(r io.Reader) ([]model.ProjectBundle, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTrello, err)
+	}
+	if board.Lists == nil {
+		return nil, fmt.Errorf("%w: listsがありません", ErrInvalidTrello)
+	}
+
+	checklists := make(map[string][]trelloChecklist)
+	for _, c := range board.Checklists {
+		checklists[c.IDCard] = append(checklists[c.IDCard], c)
+	}
+
+	slices.SortStableFunc(board.Lists, func(a, b trelloList) int { return cmp.Compare(a.Pos, b.Pos) })
+	slices.SortStableFunc(board.Cards, func(a, b trelloCard) int { return cmp.Compare(a.Pos, b.Pos) })
+
+	var lists []*list
+	byID := make(map[string]*list, len(board.Lists))
+	for _, tl := range board.Lists {
+		if tl.Closed {
+			continue
+		}
+		l := &list{name: tl.Name}
+		lists = append(lists, l)
+		byID[tl.ID] = l
+	}
+
+	for _, c := range board.Cards {
+		l, ok := byID[c.IDList]
+		if c.Closed || !ok || strings.TrimSpace(c.Name) == "" {
+			continue
+		}
+		t, err := c.task(checklists[c.ID])
+		if err != nil {
+			return nil, fmt.Errorf("%w: カード%s: %w", ErrInvalidTrello, c.ID, err)
+		}
+		l.tasks = append(l.tasks, t)
+	}
+
+	bundles := make([]model.ProjectBundle, 0, len(lists))
+	for _, l := range lists {
+		bundles = append(bundles, l.bundle())
+	}
+	return bundles, nil
+}
+
+// task はTrelloのカードをインポートするタスクに変換する
+func (c trelloCard) task(checklists []trelloChecklist) (task, error) {
+	labels := make([]string, len(c.Labels))
+	for i, l := range c.Labels {
+		labels[i] = cmp.Or(strings.TrimSpace(l.Name), l.Color)
+	}
+	t := task{
+		id: c.ID,
+		todo: model.BundleTodo{
+			Title:       truncate(strings.TrimSpace(c.Name), maxTitle),
+			Description: optionalText(c.Desc, maxDescription),
+			Completed:   c.DueComplete,
+			Tags:        labelTags(labels),
+		},
+	}
+	if c.Due != "" {
+		due, err := time.Parse(time.RFC3339, c.Due)
+		if err != nil {
+			return task{}, fmt.Errorf("期限を解析できません: %s", c.Due)
+		}
+		t.todo.DueAt = &due
+	}
+
+	slices.SortStableFunc(checklists, func(a, b trelloChecklist) int { return cmp.Compare(a.Pos, b.Pos) })
+	for _, cl := range checklists {
+		slices.SortStableFunc(cl.CheckItems, func(a, b trelloCheckItem) int { return cmp.Compare(a.Pos, b.Pos) })
+		for _, item := range cl.CheckItems {
+			title := strings.TrimSpace(item.Name)
+			if title == "" {
+				continue
+			}
+			t.todo.Subtasks = append(t.todo.Subtasks, model.BundleSubtask{
+				Title:     truncate(title, maxTitle),
+				Completed: item.State == "complete",
+			})
+		}
+	}
+	return t, nil
+}
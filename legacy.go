@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// legacyColumn は移行の仕組みを導入する前に、schema.sqlで既存のテーブルに追加した列を表す構造体。
+// schema.sqlはCREATE TABLE IF NOT EXISTSで実行していたため、追加した列は作成済みのテーブルに反映されていない。
+type legacyColumn struct {
+	table      string
+	name       string
+	definition string
+}
+
+// legacyColumns は初期スキーマにあり、移行の仕組みを導入する前のデータベースにない可能性がある列。
+// ALTER TABLE ADD COLUMNで追加できるよう、既定値は定数にしている。
+var legacyColumns = []legacyColumn{
+	{"users", "role", "TEXT NOT NULL DEFAULT 'editor' CHECK (role IN ('viewer', 'editor', 'admin'))"},
+	{"projects", "open_count", "INTEGER NOT NULL DEFAULT 0"},
+	{"projects", "completed_count", "INTEGER NOT NULL DEFAULT 0"},
+	{"projects", "metadata_schema", "TEXT CHECK (metadata_schema IS NULL OR json_valid(metadata_schema))"},
+	{"todos", "latitude", "REAL CHECK (latitude BETWEEN -90 AND 90)"},
+	{"todos", "longitude", "REAL CHECK (longitude BETWEEN -180 AND 180)"},
+	{"todos", "place_name", "TEXT"},
+	{"todos", "subtask_count", "INTEGER NOT NULL DEFAULT 0"},
+	{"todos", "subtask_completed_count", "INTEGER NOT NULL DEFAULT 0"},
+	{"todos", "project_id", "INTEGER REFERENCES projects(id) ON DELETE CASCADE"},
+	{"todos", "due_at", "DATETIME"},
+	{"todos", "recurrence", "TEXT"},
+	{"todos", "next_todo_id", "INTEGER REFERENCES todos(id) ON DELETE SET NULL"},
+	{"todos", "position", "INTEGER NOT NULL DEFAULT 0"},
+	{"todos", "version", "INTEGER NOT NULL DEFAULT 1"},
+	{"todos", "assignee", "TEXT"},
+	{"todos", "owner_id", "INTEGER REFERENCES users(id) ON DELETE CASCADE"},
+	{"todos", "metadata", "TEXT CHECK (metadata IS NULL OR json_valid(metadata))"},
+	{"todos", "priority", "INTEGER NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 3)"},
+	{"todos", "escalation_level", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// legacyRecount は件数の列を追加した場合に、既存の行から件数を数え直すSQLを表す構造体。
+// 件数はトリガーで保つため、列を追加する前に作成した行の分は数え直さないと0のままになる。
+type legacyRecount struct {
+	// source は数える行のテーブル。ない場合は件数が0のため数え直さない
+	source string
+	sql    string
+}
+
+// legacyRecounts は追加した件数の列（テーブル.列）ごとの数え直し
+var legacyRecounts = map[string]legacyRecount{
+	"projects.open_count": {"todos", `UPDATE projects SET
+    open_count = (SELECT COUNT(*) FROM todos WHERE todos.project_id = projects.id AND todos.completed = 0),
+    completed_count = (SELECT COUNT(*) FROM todos WHERE todos.project_id = projects.id AND todos.completed = 1)`},
+	"todos.subtask_count": {"subtasks", `UPDATE todos SET
+    subtask_count = (SELECT COUNT(*) FROM subtasks WHERE subtasks.todo_id = todos.id),
+    subtask_completed_count = (SELECT COUNT(*) FROM subtasks WHERE subtasks.todo_id = todos.id AND subtasks.completed = 1)`},
+}
+
+// upgradeLegacySchema は移行の仕組みを導入する前に作成したデータベースに、初期スキーマとの差分の列を追加する。
+// まだないテーブルは初期スキーマの移行で作成されるため、既存のテーブルにない列だけを追加する。
+// トリガーも本文を変えたものがあるため削除し、初期スキーマの移行で作成し直す。
+func upgradeLegacySchema(ctx context.Context, tx *sql.Tx) error {
+	if err := dropTriggers(ctx, tx); err != nil {
+		return err
+	}
+
+	var recounts []legacyRecount
+	columns := make(map[string]map[string]bool)
+	for _, c := range legacyColumns {
+		names, ok := columns[c.table]
+		if !ok {
+			var err error
+			if names, err = existingColumns(ctx, tx, c.table); err != nil {
+				return err
+			}
+			columns[c.table] = names
+		}
+		if len(names) == 0 || names[c.name] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.name, c.definition)); err != nil {
+			return fmt.Errorf("列%s.%sの追加に失敗: %w", c.table, c.name, err)
+		}
+		if r, ok := legacyRecounts[c.table+"."+c.name]; ok {
+			recounts = append(recounts, r)
+		}
+	}
+
+	// 数える行の列がすべて揃ってから数え直す
+	for _, r := range recounts {
+		names, err := existingColumns(ctx, tx, r.source)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, r.sql); err != nil {
+			return fmt.Errorf("%sの件数の集計に失敗: %w", r.source, err)
+		}
+	}
+	return nil
+}
+
+// dropTriggers はデータベースのすべてのトリガーを削除する
+func dropTriggers(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'trigger'")
+	if err != nil {
+		return fmt.Errorf("トリガーの取得に失敗: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("トリガーの取得に失敗: %w", err)
+		}
+		names = append(names, name)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("トリガーの取得に失敗: %w", err)
+	}
+
+	for _, name := range names {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TRIGGER %q", name)); err != nil {
+			return fmt.Errorf("トリガー%sの削除に失敗: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// existingColumns はテーブルの列名を返す。テーブルがない場合は空を返す
+func existingColumns(ctx context.Context, tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("テーブル%sの列の取得に失敗: %w", table, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	names := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("テーブル%sの列の取得に失敗: %w", table, err)
+		}
+		names[name] = true
+	}
+	return names, rows.Err()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// openLegacyDB はschemaのSQLで作成したデータベースのdatabase-urlを返す。setupは作成後に実行する
+func openLegacyDB(t *testing.T, schema string, setup ...string) string {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "todos.db")
+	sqlDB, err := sql.Open(sqliteDriverName, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+	for _, stmt := range append([]string{string(body)}, setup...) {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	return "sqlite:" + path
+}
+
+func TestInitDBUpgradesBaselineDatabase(t *testing.T) {
+	url := openLegacyDB(t, "schema_baseline.sql",
+		"INSERT INTO todos (title, description, completed) VALUES ('牛乳を買う', '2本', 0), ('本を返す', NULL, 1)")
+
+	sqlDB, err := initDB(url, false, true)
+	if err != nil {
+		t.Fatalf("initDB: %v", err)
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+
+	ctx := context.Background()
+	pending, err := newMigrator(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := pending.Pending(ctx); err != nil || len(p) != 0 {
+		t.Fatalf("pending migrations = %v, %v", p, err)
+	}
+	if result := checkSchema(ctx, sqlDB); result.Status != checkOK {
+		t.Fatalf("checkSchema = %+v", result)
+	}
+
+	var title string
+	var version, priority int64
+	if err := sqlDB.QueryRow("SELECT title, version, priority FROM todos WHERE id = 1").Scan(&title, &version, &priority); err != nil {
+		t.Fatal(err)
+	}
+	if title != "牛乳を買う" || version != 1 || priority != 0 {
+		t.Fatalf("todo 1 = %q version %d priority %d", title, version, priority)
+	}
+
+	// 初期スキーマのトリガーで版が上がる
+	if _, err := sqlDB.Exec("INSERT INTO subtasks (todo_id, title) VALUES (1, '低脂肪')"); err != nil {
+		t.Fatal(err)
+	}
+	var subtasks int64
+	if err := sqlDB.QueryRow("SELECT subtask_count, version FROM todos WHERE id = 1").Scan(&subtasks, &version); err != nil {
+		t.Fatal(err)
+	}
+	if subtasks != 1 || version != 2 {
+		t.Fatalf("subtask_count = %d, version = %d; want 1, 2", subtasks, version)
+	}
+}
+
+func TestInitDBRecountsLegacyCounters(t *testing.T) {
+	url := openLegacyDB(t, "schema_projects.sql",
+		"INSERT INTO projects (name) VALUES ('家')",
+		"INSERT INTO todos (title, completed, project_id) VALUES ('掃除', 0, 1), ('洗濯', 1, 1), ('料理', 0, 1)",
+		"INSERT INTO subtasks (todo_id, title, completed) VALUES (1, '床', 1), (1, '窓', 0)")
+
+	sqlDB, err := initDB(url, false, true)
+	if err != nil {
+		t.Fatalf("initDB: %v", err)
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+
+	var open, completed int64
+	if err := sqlDB.QueryRow("SELECT open_count, completed_count FROM projects WHERE id = 1").Scan(&open, &completed); err != nil {
+		t.Fatal(err)
+	}
+	if open != 2 || completed != 1 {
+		t.Fatalf("open_count = %d, completed_count = %d; want 2, 1", open, completed)
+	}
+
+	var subtasks, subtasksCompleted int64
+	if err := sqlDB.QueryRow("SELECT subtask_count, subtask_completed_count FROM todos WHERE id = 1").Scan(&subtasks, &subtasksCompleted); err != nil {
+		t.Fatal(err)
+	}
+	if subtasks != 2 || subtasksCompleted != 1 {
+		t.Fatalf("subtask_count = %d, subtask_completed_count = %d; want 2, 1", subtasks, subtasksCompleted)
+	}
+}
@@ -0,0 +1,257 @@
+// Package locale はHTMLやフィード、メールなど人が読む出力で、日時と相対時間を
+// 利用者の言語とタイムゾーンに合わせて書式化するFormatterを提供する。
+// 言語はAccept-Languageヘッダーから対応している言語を選び、各出力の描画処理はcontextからFormatterを取り出して使う。
+package locale
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 対応する言語
+const (
+	// Japanese は日本語
+	Japanese = "ja"
+	// English は英語
+	English = "en"
+)
+
+// TimezoneHeader は出力に用いるタイムゾーンをIANAの名前で指定するリクエストヘッダー
+const TimezoneHeader = "X-Timezone"
+
+// Supported は言語が対応しているかを返す
+func Supported(lang string) bool {
+	return lang == Japanese || lang == English
+}
+
+// Negotiate はAccept-Languageヘッダーの値から、品質値が最も高い対応言語を返す。
+// 対応する言語がない場合はfallbackを返す。
+func Negotiate(acceptLanguage, fallback string) string {
+	best, bestQ := fallback, 0.0
+	for _, p := range parseAcceptLanguage(acceptLanguage) {
+		// ja-JPやen-USのような地域付きの指定は言語部分で照合する
+		lang := primaryLanguage(p.tag)
+		if lang == "*" {
+			lang = fallback
+		}
+		if Supported(lang) && p.q > bestQ {
+			best, bestQ = lang, p.q
+		}
+	}
+	return best
+}
+
+// Match はAccept-Languageヘッダーの値から、availableの言語タグのうち品質値が最も高いものを返す。
+// 同じ品質値では指定の順を優先し、タグが完全に一致するものがなければpt-BRとptのように言語部分が一致するものを選ぶ。
+// 一致するものがない場合や*のみが指定された場合はfalseを返す。
+func Match(acceptLanguage string, available []string) (string, bool) {
+	for _, p := range parseAcceptLanguage(acceptLanguage) {
+		if p.q <= 0 || p.tag == "*" {
+			continue
+		}
+		for _, a := range available {
+			if strings.EqualFold(a, p.tag) {
+				return a, true
+			}
+		}
+		// 言語部分のみのタグを地域付きのタグより優先する
+		lang := primaryLanguage(p.tag)
+		var candidate string
+		for _, a := range available {
+			if primaryLanguage(a) != lang {
+				continue
+			}
+			if strings.EqualFold(a, lang) {
+				return a, true
+			}
+			if candidate == "" || a < candidate {
+				candidate = a
+			}
+		}
+		if candidate != "" {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// languagePreference はAccept-Languageヘッダーで指定された言語1件を表す構造体
+type languagePreference struct {
+	tag string
+	q   float64
+}
+
+// parseAcceptLanguage はAccept-Languageヘッダーの値を品質値の高い順に並べて返す。品質値が不正な指定は除く。
+func parseAcceptLanguage(acceptLanguage string) []languagePreference {
+	var prefs []languagePreference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		prefs = append(prefs, languagePreference{tag: tag, q: q})
+	}
+	slices.SortStableFunc(prefs, func(a, b languagePreference) int {
+		return cmp.Compare(b.q, a.q)
+	})
+	return prefs
+}
+
+// primaryLanguage は言語タグの言語部分を小文字で返す
+func primaryLanguage(tag string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	return lang
+}
+
+// Formatter は言語とタイムゾーンに合わせて日時を書式化する
+type Formatter struct {
+	lang string
+	loc  *time.Location
+	now  func() time.Time
+}
+
+// New はFormatterの新しいインスタンスを生成する。
+// 対応していない言語は日本語として、locがnilの場合はUTCとして扱う。
+func New(lang string, loc *time.Location) *Formatter {
+	if !Supported(lang) {
+		lang = Japanese
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Formatter{
+		lang: lang,
+		loc:  loc,
+		now:  time.Now,
+	}
+}
+
+// Language は書式化に用いる言語を返す
+func (f *Formatter) Language() string {
+	return f.lang
+}
+
+// Location は書式化に用いるタイムゾーンを返す
+func (f *Formatter) Location() *time.Location {
+	return f.loc
+}
+
+// Date は日付を書式化する
+func (f *Formatter) Date(t time.Time) string {
+	t = t.In(f.loc)
+	if f.lang == English {
+		return t.Format("Jan 2, 2006")
+	}
+	return t.Format("2006年1月2日")
+}
+
+// DateTime は日時を分まで書式化する
+func (f *Formatter) DateTime(t time.Time) string {
+	t = t.In(f.loc)
+	if f.lang == English {
+		return t.Format("Jan 2, 2006 3:04 PM MST")
+	}
+	return t.Format("2006年1月2日 15:04 MST")
+}
+
+// relativeUnit は相対時間の単位を表す構造体
+type relativeUnit struct {
+	size time.Duration
+	ja   string
+	en   string
+}
+
+// relativeUnits は相対時間で用いる単位。大きい順に並べる。
+var relativeUnits = []relativeUnit{
+	{365 * 24 * time.Hour, "年", "year"},
+	{30 * 24 * time.Hour, "か月", "month"},
+	{7 * 24 * time.Hour, "週間", "week"},
+	{24 * time.Hour, "日", "day"},
+	{time.Hour, "時間", "hour"},
+	{time.Minute, "分", "minute"},
+}
+
+// Relative は現在からの相対時間を「3日後」「2 hours ago」のように書式化する。
+// 1分未満の差は「たった今」として扱う。
+func (f *Formatter) Relative(t time.Time) string {
+	d := t.Sub(f.now())
+	future := d > 0
+	if !future {
+		d = -d
+	}
+	for _, u := range relativeUnits {
+		if d < u.size {
+			continue
+		}
+		// 数秒の差で「2日後」のように短く見えないよう、単位未満は四捨五入する
+		n := int64((d + u.size/2) / u.size)
+		if f.lang == English {
+			unit := u.en
+			if n != 1 {
+				unit += "s"
+			}
+			if future {
+				return fmt.Sprintf("in %d %s", n, unit)
+			}
+			return fmt.Sprintf("%d %s ago", n, unit)
+		}
+		if future {
+			return fmt.Sprintf("%d%s後", n, u.ja)
+		}
+		return fmt.Sprintf("%d%s前", n, u.ja)
+	}
+	if f.lang == English {
+		return "just now"
+	}
+	return "たった今"
+}
+
+// Label は言語に合わせて、日本語の見出しjaか英語の見出しenのいずれかを返す
+func (f *Formatter) Label(ja, en string) string {
+	if f.lang == English {
+		return en
+	}
+	return ja
+}
+
+type formatterKey struct{}
+
+type acceptLanguageKey struct{}
+
+// WithFormatter はFormatterを格納したcontextを返す
+func WithFormatter(ctx context.Context, f *Formatter) context.Context {
+	return context.WithValue(ctx, formatterKey{}, f)
+}
+
+// FormatterFrom はcontextに格納されたFormatterを返す。格納されていない場合は日本語、UTCのFormatterを返す。
+func FormatterFrom(ctx context.Context) *Formatter {
+	if f, ok := ctx.Value(formatterKey{}).(*Formatter); ok {
+		return f
+	}
+	return New(Japanese, time.UTC)
+}
+
+// WithAcceptLanguage はリクエストのAccept-Languageヘッダーの値と、訳のない元の文章の言語を格納したcontextを返す
+func WithAcceptLanguage(ctx context.Context, acceptLanguage, original string) context.Context {
+	return context.WithValue(ctx, acceptLanguageKey{}, [2]string{acceptLanguage, original})
+}
+
+// AcceptLanguage はcontextに格納されたAccept-Languageヘッダーの値と元の文章の言語を返す。格納されていない場合は空を返す。
+func AcceptLanguage(ctx context.Context) (acceptLanguage, original string) {
+	v, _ := ctx.Value(acceptLanguageKey{}).([2]string)
+	return v[0], v[1]
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/handler"
+	"go-huma-test/model"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// wantStatus はerrがstatusのHTTPエラーであることを確認する
+func wantStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var se huma.StatusError
+	if !errors.As(err, &se) || se.GetStatus() != status {
+		t.Fatalf("err = %v, want status %d", err, status)
+	}
+}
+
+func TestTodoLocksAreEnforcedInTransactions(t *testing.T) {
+	sqlDB, err := initDB("sqlite:"+filepath.Join(t.TempDir(), "todos.db"), false, true)
+	if err != nil {
+		t.Fatalf("initDB: %v", err)
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	for _, stmt := range []string{
+		"INSERT INTO users (email, name, password_hash) VALUES ('a@example.com', 'A', ''), ('b@example.com', 'B', '')",
+		"INSERT INTO todos (title, completed, owner_id) VALUES ('編集中', 1, 1), ('ロックなし', 1, 1)",
+		"INSERT INTO todo_shares (todo_id, user_id, shared_by, permission) VALUES (1, 2, 1, 'write')",
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	// ユーザー2が10分間ロックしている
+	if _, err := sqlDB.Exec("INSERT INTO resource_locks (resource_type, resource_id, holder_id, acquired_at, expires_at) VALUES (?, 1, 2, ?, ?)",
+		model.LockResourceTodo, now, now.Add(10*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := db.New(sqlDB)
+	ctx := auth.WithUserID(context.Background(), 1)
+	todos := handler.NewTodoHandler(queries, sqlDB, event.NewBus(), nil, clock.Freeze(now))
+
+	_, err = todos.ToggleTodo(ctx, &model.ToggleTodoInput{ID: 1})
+	wantStatus(t, err, http.StatusLocked)
+
+	bulk := &model.BulkDeleteTodosInput{}
+	bulk.Body.IDs = []int64{1, 2}
+	_, err = todos.BulkDeleteTodos(ctx, bulk)
+	wantStatus(t, err, http.StatusLocked)
+	_, err = todos.ClearCompletedTodos(ctx, &model.ClearCompletedTodosInput{})
+	wantStatus(t, err, http.StatusLocked)
+
+	var count int64
+	if err := sqlDB.QueryRow("SELECT COUNT(*) FROM todos").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("todos after rejected deletes = %d, want 2", count)
+	}
+
+	// ロックを持っているユーザー自身は変更できる
+	holder := auth.WithUserID(context.Background(), 2)
+	if _, err := todos.ToggleTodo(holder, &model.ToggleTodoInput{ID: 1}); err != nil {
+		t.Fatalf("ToggleTodo by the holder: %v", err)
+	}
+
+	// 期限を過ぎたロックは妨げず、削除したTodoのロックも消える
+	later := handler.NewTodoHandler(queries, sqlDB, event.NewBus(), nil, clock.Freeze(now.Add(time.Hour)))
+	out, err := later.BulkDeleteTodos(ctx, bulk)
+	if err != nil {
+		t.Fatalf("BulkDeleteTodos after expiry: %v", err)
+	}
+	if out.Body.Count != 2 {
+		t.Fatalf("deleted %d todos, want 2", out.Body.Count)
+	}
+	if err := sqlDB.QueryRow("SELECT COUNT(*) FROM resource_locks").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("resource_locks after deleting the todo = %d, want 0", count)
+	}
+}
@@ -6,37 +6,161 @@ package main
 import (
 	"context"
 	"database/sql"
+	"embed"
+	"errors"
+	"expvar"
 	"fmt"
+	"go-huma-test/actionlink"
+	"go-huma-test/apidoc"
+	"go-huma-test/audit"
+	"go-huma-test/auth"
+	"go-huma-test/backup"
+	"go-huma-test/clock"
+	"go-huma-test/confirm"
 	"go-huma-test/db"
+	"go-huma-test/dbpool"
+	"go-huma-test/diskspace"
+	"go-huma-test/event"
 	"go-huma-test/handler"
+	"go-huma-test/handoff"
+	"go-huma-test/importer"
+	"go-huma-test/locale"
+	"go-huma-test/middleware"
+	"go-huma-test/migrate"
 	"go-huma-test/model"
+	"go-huma-test/notify"
+	"go-huma-test/presence"
+	"go-huma-test/replication"
+	"go-huma-test/scheduler"
+	"go-huma-test/secrets"
+	"go-huma-test/slo"
+	"go-huma-test/storage"
+	"go-huma-test/tracing"
+	"go-huma-test/usage"
+	"go-huma-test/webui"
 	"log/slog"
+	"maps"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
-	_ "embed"
-
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humago"
 	"github.com/danielgtaylor/huma/v2/humacli"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/danielgtaylor/huma/v2/sse"
 )
 
-//go:embed schema/schema.sql
-var schema string
+//go:embed schema/migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationsDir はmigrationFilesの中で移行のファイルを置くディレクトリ
+const migrationsDir = "schema/migrations"
+
+// initDB はdatabase-urlのデータベースに接続し、未適用の移行を適用する。autoMigrateがfalseの場合は適用せず、未適用の移行があればエラーにする。
+// readOnlyの場合はプライマリから複製されたデータベースを読み取り専用で開き、移行を適用しない。
+func initDB(databaseURL string, readOnly, autoMigrate bool) (*sql.DB, error) {
+	dbPath, err := sqlitePath(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := openDB(dbPath, readOnly)
+	if err != nil {
+		return nil, err
+	}
+	if dbPath == memoryDatabase {
+		slog.Warn("インメモリのデータベースを使います。停止すると内容は失われます")
+	}
+	if readOnly {
+		slog.Info("データベース接続に成功")
+		return sqlDB, nil
+	}
+
+	migrator, err := newMigrator(sqlDB)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if !autoMigrate {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(pending) > 0 {
+			return nil, fmt.Errorf("未適用の移行が%d件あります。migrate upで適用してください", len(pending))
+		}
+		slog.Info("データベース接続に成功")
+		return sqlDB, nil
+	}
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		slog.Info("移行を適用", "version", m.Version, "name", m.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("データベース接続に成功")
+	return sqlDB, nil
+}
+
+// newMigrator は埋め込んだ移行のファイルをデータベースに適用するMigratorを生成する
+func newMigrator(sqlDB *sql.DB) (*migrate.Migrator, error) {
+	migrations, err := migrate.Load(migrationFiles, migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, migrations).WithLegacyUpgrade(upgradeLegacySchema), nil
+}
+
+// openDB はデータベースに接続して接続の設定を行う。
+// readOnlyの場合はプライマリから複製されたデータベースを読み取り専用で開く。
+// インメモリのデータベースはコネクションごとに別になるが、コネクションを1つに限って閉じずに使い続けるため、プロセスの間は内容を保つ。
+func openDB(dbPath string, readOnly bool) (*sql.DB, error) {
+	dsn := dbPath
+	if dbPath == memoryDatabase && readOnly {
+		return nil, errors.New("インメモリのデータベースはレプリカとして開けません")
+	}
+	if readOnly {
+		abs, err := filepath.Abs(dbPath)
+		if err != nil {
+			return nil, fmt.Errorf("データベースのパスを解決できません: %w", err)
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return nil, fmt.Errorf("レプリカのデータベースがありません: %w", err)
+		}
+		dsn = "file:" + abs + "?mode=ro"
+	} else {
+		// 書き込みのトランザクションは開始の時点でロックを取り、ロックの競合で途中の書き込みが失敗しないようにする。
+		// 競合した場合はドライバーが開始を再試行する
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn += sep + "_txlock=immediate"
+	}
 
-func initDB(dbPath string) (*sql.DB, error) {
-	sqlDB, err := sql.Open("sqlite3", dbPath)
+	sqlDB, err := sql.Open(sqliteDriverName, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("データベース接続に失敗: %w", err)
 	}
 
 	params := []string{
 		"PRAGMA busy_timeout = 5000;", // ロックされている場合最大5秒待つ
-		"PRAGMA journal_mode = WAL;",  // 読み取りは複数同時に可能だが書き込みは１つだけ。SQLiteをWebAPIで使用する場合はほぼ必須
 		"PRAGMA foreign_keys = ON;",   // 外部キー制約を有効化（将来のために）
 	}
+	if !readOnly {
+		// 読み取りは複数同時に可能だが書き込みは１つだけ。SQLiteをWebAPIで使用する場合はほぼ必須
+		// ジャーナルモードの変更は書き込みになるため、レプリカではプライマリの設定に従う
+		// 保守でincremental vacuumを実行できるようにする。テーブルを作成する前の新しいデータベースにのみ反映される
+		params = append(params, "PRAGMA auto_vacuum = INCREMENTAL;", "PRAGMA journal_mode = WAL;")
+	}
 	for _, p := range params {
 		if _, err := sqlDB.Exec(p); err != nil {
 			return nil, err
@@ -46,88 +170,710 @@ func initDB(dbPath string) (*sql.DB, error) {
 	sqlDB.SetMaxOpenConns(1) // 同時に開ける最大コネクション数
 	sqlDB.SetMaxIdleConns(1) // アイドル状態のコネクション数
 
-	if _, err := sqlDB.Exec(schema); err != nil {
-		return nil, fmt.Errorf("データベース初期化スキーマの実行失敗: %w", err)
+	return sqlDB, nil
+}
+
+// databaseDir はデータベースのファイルがあるディレクトリの絶対パスを返す。インメモリのデータベースなどファイルがない場合は空文字を返す
+func databaseDir(databaseURL string) string {
+	dbPath, err := sqlitePath(databaseURL)
+	if err != nil || dbPath == memoryDatabase {
+		return ""
+	}
+	abs, err := filepath.Abs(dbPath)
+	if err != nil {
+		return ""
 	}
+	return filepath.Dir(abs)
+}
 
-	slog.Info("データベース接続に成功")
+// openReadDB は読み取り専用のクエリを実行する、conns個のコネクションのプールを開く。
+// 書き込み用のプールがWALモードにしたデータベースを読み取り専用で開き、コネクションごとの設定はDSNで行う。
+// インメモリのデータベースはコネクションごとに別になるため、読み取り用のプールを使わずにnilを返す。
+func openReadDB(databaseURL string, conns int) (*sql.DB, error) {
+	dbPath, err := sqlitePath(databaseURL)
+	if err != nil || dbPath == memoryDatabase {
+		return nil, err
+	}
+	abs, err := filepath.Abs(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("データベースのパスを解決できません: %w", err)
+	}
+	readDB, err := sql.Open(sqliteDriverName, "file:"+abs+"?mode=ro&_busy_timeout=5000&_foreign_keys=1")
+	if err != nil {
+		return nil, fmt.Errorf("読み取り用のデータベース接続に失敗: %w", err)
+	}
+	if err := readDB.Ping(); err != nil {
+		_ = readDB.Close()
+		return nil, fmt.Errorf("読み取り用のデータベース接続に失敗: %w", err)
+	}
+	readDB.SetMaxOpenConns(conns)
+	readDB.SetMaxIdleConns(conns)
+	return readDB, nil
+}
 
-	return sqlDB, nil
+// splitList はカンマ区切りのオプションの値を空白を除いた要素に分割する。空の要素は捨てる。
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// newNotifier は起動オプションで設定された通知チャネルと、プロジェクトごとのチャットの通知チャネルを登録したNotifierを生成する
+func newNotifier(o *model.Options, secretManager *secrets.Manager, formatter *locale.Formatter, chats map[string]*notify.ChatNotifier) notify.Notifier {
+	logNotifier := notify.NewLogNotifier()
+	router := notify.NewRouter(logNotifier)
+	router.Register(notify.ChannelLog, logNotifier)
+	for channel, chat := range chats {
+		router.Register(channel, chat)
+	}
+	if o.WebhookURL != "" {
+		var template *notify.PayloadTemplate
+		if o.WebhookTemplate != "" {
+			t, err := notify.ParsePayloadTemplate(o.WebhookTemplate)
+			if err != nil {
+				slog.Error("webhook-templateが不正です", "err", err)
+				os.Exit(1)
+			}
+			template = t
+		}
+		router.Register(notify.ChannelWebhook, notify.NewWebhookNotifier(o.WebhookURL, 10*time.Second, secretManager, template))
+	}
+	if o.SMTPAddr != "" && o.SMTPTo != "" {
+		html, err := notify.LoadHTMLTemplate(o.EmailTemplate)
+		if err != nil {
+			slog.Error("email-templateが不正です", "err", err)
+			os.Exit(1)
+		}
+		router.Register(notify.ChannelEmail, notify.NewEmailNotifier(o.SMTPAddr, o.SMTPFrom, strings.Split(o.SMTPTo, ","), secretManager, formatter, html))
+	}
+	return router
 }
 
-func LoggingMiddleware(ctx huma.Context, next func(huma.Context)) {
-	fmt.Printf("[%s] %s\n", ctx.Method(), ctx.URL().Path)
-	next(ctx)
+// seedDemo はデータベースが空の場合に、/docsの操作の例が参照するデモ用のデータを投入し、試すための資格情報をログに出力する。
+// ユーザーがいる場合は例のIDと合わないデータを増やさないよう、何もしない
+func seedDemo(queries *db.Queries, h http.Handler, oapi *huma.OpenAPI) {
+	ctx := context.Background()
+	count, err := queries.CountUsers(ctx)
+	if err != nil {
+		slog.Error("ユーザー数の取得に失敗", "err", err)
+		os.Exit(1)
+	}
+	if count > 0 {
+		slog.Info("ユーザーが登録済みのため、デモ用のデータは投入しません", "users", count)
+		return
+	}
+	account, err := apidoc.SeedDemo(ctx, h, oapi)
+	if err != nil {
+		slog.Error("デモ用のデータの投入に失敗", "err", err)
+		os.Exit(1)
+	}
+	slog.Info("デモ用のデータを投入", "email", account.Email, "password", account.Password, "api_key", account.APIKey)
 }
 
-func AuthMiddleware(ctx huma.Context, next func(huma.Context)) {
-	// 認証チェック
-	token := ctx.Header("Authorization")
+// startBootstrap はユーザーが1人もいない場合に初期管理者を作成するトークンを発行し、ログに出力する。
+// 招待制ではサインアップで初期管理者を作れないため、POST /auth/bootstrapかbootstrapサブコマンドで作成する
+func startBootstrap(users *handler.UserHandler, inviteOnly bool) {
+	token, err := users.StartBootstrap(context.Background())
+	if err != nil {
+		slog.Error("初期管理者の作成トークンの発行に失敗", "err", err)
+		os.Exit(1)
+	}
 	if token == "" {
-		slog.Warn("Authorizationが設定されていません")
-		if err := huma.WriteErr(huma.NewAPI(huma.Config{}, nil), ctx, http.StatusUnauthorized, "Authorization header required"); err != nil {
-			slog.Warn("エラーレスポンスの書き込みに失敗", "err", err)
-		}
 		return
 	}
+	if inviteOnly {
+		slog.Warn("ユーザーが登録されていません。POST /auth/bootstrapにこのトークンを指定して初期管理者を作成してください", "token", token)
+		return
+	}
+	slog.Info("ユーザーが登録されていません。最初にサインアップしたユーザーか、POST /auth/bootstrapにこのトークンを指定したユーザーが管理者になります", "token", token)
+}
+
+// serverWriteTimeout はリクエストを読み始めてからレスポンスを書き終えるまでの制限。query-timeoutはこれより短くする
+const serverWriteTimeout = 15 * time.Second
+
+// shutdownFlushTimeout はシャットダウン時に利用量の書き込みとトレースの送信に使う時間
+const shutdownFlushTimeout = 5 * time.Second
+
+// handoffDrainTimeout は再起動で受け付けを止めた後に、受け付け済みの接続がリクエストを送るのを待つ時間。
+// http.Serverが最初のリクエストを待つ接続をアイドルとみなすまでの時間に合わせる
+const handoffDrainTimeout = 5 * time.Second
+
+// waitContext はwaitが戻るかctxが終了するまで待ち、waitが戻った場合にtrueを返す
+func waitContext(ctx context.Context, wait func()) bool {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// maxPanicAlertStack は通知に含めるスタックトレースの最大バイト数
+const maxPanicAlertStack = 4096
+
+// newPanicAlert はパニックを通知するフックを生成する。
+// 同じメソッドとパスのパニックはintervalの間に1回だけ通知し、繰り返し発生するパニックで通知先を溢れさせない。
+func newPanicAlert(notifier notify.Notifier, channel string, interval time.Duration) middleware.PanicHook {
+	var (
+		mu       sync.Mutex
+		notified = make(map[string]time.Time)
+	)
+	return func(ctx context.Context, method, path string, recovered any, stack []byte) {
+		key := method + " " + path
+		now := time.Now()
+		mu.Lock()
+		if last, ok := notified[key]; ok && now.Sub(last) < interval {
+			mu.Unlock()
+			return
+		}
+		// パスにはIDが含まれるため、通知の間隔を過ぎた記録は捨てる
+		for k, last := range notified {
+			if now.Sub(last) >= interval {
+				delete(notified, k)
+			}
+		}
+		notified[key] = now
+		mu.Unlock()
 
-	next(ctx)
+		if len(stack) > maxPanicAlertStack {
+			stack = stack[:maxPanicAlertStack]
+		}
+		if err := notifier.Notify(ctx, notify.Notification{
+			Kind:    notify.KindPanic,
+			Title:   "ハンドラーでパニックが発生: " + key,
+			Message: fmt.Sprintf("%v\nリクエストID: %s\n\n%s", recovered, middleware.RequestIDFrom(ctx), stack),
+			Channel: channel,
+		}); err != nil {
+			slog.Warn("パニックの通知に失敗", "method", method, "path", path, "err", err)
+		}
+	}
 }
 
 func main() {
-	// ロガー初期化
+	// ロガー初期化。ログレベルは起動オプションを読み込んだ後に設定する
+	var logLevel slog.LevelVar
 	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level:     slog.LevelInfo,
+		Level:     &logLevel,
 		AddSource: false,
 	})))
 
-	sqlDB, err := initDB("./todos.db")
-	if err != nil {
-		slog.Error("データベース初期化に失敗", "err", err)
-		os.Exit(1)
-	}
-	defer func() {
-		if err := sqlDB.Close(); err != nil {
-			slog.Error("データベースの終了に失敗", "err", err)
-		}
-	}()
+	// エラーのレスポンスのlocationをJSON Pointerにし、データベースが混み合っている場合は503にする。
+	// 操作の登録時にエラーのスキーマを作成するため、登録より前に設定する
+	huma.NewError = newError
 
-	queries, err := db.Prepare(context.Background(), sqlDB)
-	if err != nil {
-		slog.Error("データベースのPrepareに失敗", "err", err)
-		os.Exit(1)
-	}
-	handler := handler.NewTodoHandler(queries, sqlDB)
+	checkCmd := newCheckCommand()
+	importCmd := newImportCommand()
+	bootstrapCmd := newBootstrapCommand()
+	migrateCmd := newMigrateCommand()
+	backupCmd := newBackupCommand()
+	configCmd := newConfigCommand()
+	replicationCmd := newReplicationCommand()
+	todoCmd := newTodoCommand()
+	// specサブコマンドはサーバーの初期化で操作を登録したドキュメントを使う
+	var specDocument *huma.OpenAPI
+	specCmd := newSpecCommand(func() *huma.OpenAPI { return specDocument })
 
 	cli := humacli.New(func(h humacli.Hooks, o *model.Options) {
+		// dbはdatabase-urlを短く指定するためのもので、サブコマンドにも同じ値を使う
+		if o.DB != "" {
+			o.DatabaseURL = o.DB
+		}
+		// サブコマンドはそれぞれ必要なものだけを初期化するため、サーバーの初期化を行わない
+		if checkCmd.CalledAs() != "" || importCmd.CalledAs() != "" || calledAs(bootstrapCmd) || calledAs(migrateCmd) || calledAs(backupCmd) || calledAs(configCmd) || calledAs(replicationCmd) || calledAs(todoCmd) {
+			return
+		}
+		// specサブコマンドはドキュメントを生成するためだけに初期化するため、データベースはインメモリにし、ログは出力を汚さないよう標準エラー出力に書く
+		specMode := calledAs(specCmd)
+		if specMode {
+			o.DatabaseURL = "memory"
+			o.ReadOnly = false
+			o.AutoMigrate = true
+			slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+		}
+
+		if err := logLevel.UnmarshalText([]byte(o.LogLevel)); err != nil {
+			slog.Error("log-levelにはdebug、info、warn、errorのいずれかを指定してください", "log_level", o.LogLevel)
+			os.Exit(1)
+		}
+		// MCPのサーバーはHTTPのサーバーを起動せず、Todoのハンドラーだけを初期化する。標準出力はMCPのメッセージに使うため、ログは標準エラー出力に書く
+		if o.MCP {
+			slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: &logLevel})))
+			h.OnStart(func() {
+				if err := runMCP(context.Background(), o); err != nil {
+					slog.Error("MCPのサーバーが停止", "err", err)
+					os.Exit(1)
+				}
+			})
+			return
+		}
+
+		// PostgreSQLはTodoRepositoryを使い、Todoの基本的な操作のみを提供するサーバーで扱う。SQLiteが必要な起動オプションを指定した場合は起動しない
+		if usesRepository(o.DatabaseURL) {
+			runRepositoryServer(h, o)
+			return
+		}
+
+		// トレースのエクスポート先がない場合はスパンを作成しても記録しない
+		shutdownTracing := func(context.Context) error { return nil }
+		tracingConfig := tracing.Config{Endpoint: o.OTelEndpoint, ServiceName: o.OTelServiceName, SampleRatio: float64(o.OTelSamplePercent) / 100}
+		if tracingConfig.Enabled() {
+			shutdown, err := tracing.Setup(context.Background(), tracingConfig)
+			if err != nil {
+				slog.Error("トレースの設定に失敗", "err", err)
+				os.Exit(1)
+			}
+			shutdownTracing = shutdown
+			slog.Info("トレースのエクスポートを開始", "otel_endpoint", o.OTelEndpoint, "sample_percent", o.OTelSamplePercent)
+		}
+
+		tracing.SetOverrunThreshold(o.QueryOverrunThreshold)
+		tracing.SetBusyRetry(o.DBBusyRetries, o.DBBusyBackoff)
+		sqlDB, err := initDB(o.DatabaseURL, o.ReadOnly, o.AutoMigrate)
+		if err != nil {
+			slog.Error("データベース初期化に失敗", "err", err)
+			os.Exit(1)
+		}
+
+		// SIGHUPで再起動する際に置き換えた新しいバイナリを起動できるよう、実行ファイルのパスを置き換えられる前に解決する
+		executable, err := os.Executable()
+		if err != nil {
+			slog.Error("実行ファイルのパスを取得できません", "err", err)
+			os.Exit(1)
+		}
+		// 定期ジョブは再起動の間に古いプロセスと新しいプロセスで重ねて実行しないよう、データベースごとのロックを取得して実行する。
+		// インメモリのデータベースはプロセスごとに別のため、ロックしない
+		dbPath, _ := sqlitePath(o.DatabaseURL)
+		var jobLockPath string
+		if dbPath != memoryDatabase {
+			jobLockPath = dbPath + ".lock"
+		}
+
+		// レプリカのデータベースはプライマリが保守する
+		var maintenance *scheduler.MaintenanceScheduler
+		if !o.ReadOnly {
+			walPath := ""
+			if dbPath != memoryDatabase {
+				walPath = dbPath + "-wal"
+			}
+			maintenance = scheduler.NewMaintenanceScheduler(sqlDB, walPath, scheduler.MaintenanceConfig{
+				CheckpointInterval: o.DBCheckpointInterval,
+				AnalyzeInterval:    o.DBAnalyzeInterval,
+				VacuumInterval:     o.DBVacuumInterval,
+				VacuumPages:        o.DBVacuumPages,
+			})
+			expvar.Publish("db_maintenance", expvar.Func(func() any { return maintenance.Metrics() }))
+		}
+		expvar.Publish("db_busy_retry", expvar.Func(func() any { return tracing.BusyRetryStats() }))
+
+		queries, err := db.Prepare(context.Background(), sqlDB)
+		if err != nil {
+			slog.Error("データベースのPrepareに失敗", "err", err)
+			os.Exit(1)
+		}
+		// 書き込みは1コネクションで順に行い、読み取り専用のクエリは複数コネクションのプールで並行して行う。
+		// Prepareはすべてのクエリを起動時に検証するために行い、振り分けでは各プールで準備し直す
+		var readDB *sql.DB
+		if o.DBReadConns > 0 && !o.ReadOnly {
+			readDB, err = openReadDB(o.DatabaseURL, o.DBReadConns)
+			if err != nil {
+				slog.Error("読み取り用のデータベース初期化に失敗", "err", err)
+				os.Exit(1)
+			}
+		}
+		if readDB != nil {
+			_ = queries.Close()
+			queries = db.New(dbpool.New(sqlDB, readDB))
+			slog.Info("読み取り専用のクエリを読み取り用のプールで実行", "db_read_conns", o.DBReadConns)
+		}
+
+		attachmentStore, err := storage.NewLocalStore(o.AttachmentDir)
+		if err != nil {
+			slog.Error("添付ファイル保存先の初期化に失敗", "err", err)
+			os.Exit(1)
+		}
+		bus := event.NewBus()
+		var sinks []event.Sink
+		for _, raw := range splitList(o.EventSinks) {
+			sink, err := event.OpenSink(raw)
+			if err != nil {
+				slog.Error("イベントの送信先の設定が不正です", "err", err)
+				os.Exit(1)
+			}
+			sinks = append(sinks, sink)
+		}
+		// 期限や通知の日時を決める時計。freeze-timeを指定した場合はその時刻で止める
+		clk, err := clock.Parse(o.FreezeTime)
+		if err != nil {
+			slog.Error("freeze-timeの指定が不正です", "err", err)
+			os.Exit(1)
+		}
+		if o.FreezeTime != "" {
+			slog.Warn("時計をfreeze-timeの時刻で止めて起動", "now", clk.Now())
+		}
+		todoHandler := handler.NewTodoHandler(queries, sqlDB, bus, attachmentStore, clk)
+		subtaskHandler := handler.NewSubtaskHandler(queries, sqlDB, bus, clk)
+		tagHandler := handler.NewTagHandler(queries, sqlDB, bus, clk)
+		descriptionHandler := handler.NewDescriptionHandler(queries, sqlDB, bus, clk)
+		confirmer := confirm.NewConfirmer(queries, o.ConfirmationTTL)
+		presenceTracker := presence.NewTracker(o.PresenceTTL)
+		projectHandler := handler.NewProjectHandler(queries, sqlDB, bus, confirmer, presenceTracker, clk)
+		presenceHandler := handler.NewPresenceHandler(queries, presenceTracker)
+		shareHandler := handler.NewShareHandler(queries)
+		todoDefaultsHandler := handler.NewTodoDefaultsHandler(queries)
+		webhookHandler := handler.NewWebhookHandler(queries)
+		filterHandler := handler.NewFilterHandler(queries, bus)
+		reminderHandler := handler.NewReminderHandler(queries)
+		commentHandler := handler.NewCommentHandler(queries, sqlDB, bus)
+		watchHandler := handler.NewWatchHandler(queries)
+		lockHandler := handler.NewLockHandler(queries, o.LockTTL, o.LockMaxTTL, clk)
+		securityHandler := handler.NewSecurityHandler(queries)
+		apiKeyHandler := handler.NewAPIKeyHandler(queries)
+		summaryHandler := handler.NewSummaryHandler(queries, clk)
+		agendaHandler := handler.NewAgendaHandler(queries, clk)
+		activityHandler := handler.NewActivityHandler(queries)
+		advisorHandler := handler.NewAdvisorHandler(sqlDB)
+		// バックアップは読み取りのみのため、読み取り用のプールがあればそちらで行い書き込みを待たせない
+		backupDB := sqlDB
+		if readDB != nil {
+			backupDB = readDB
+		}
+		backupManager := backup.NewManager(backupDB, o.BackupDir, o.BackupRetention)
+		backupHandler := handler.NewBackupHandler(backupManager)
+		usageHandler := handler.NewUsageHandler(queries)
+		replicationHandler := handler.NewReplicationHandler(queries, o.ReadOnly, o.ReplicaPrimaryURL, o.ReplicaSource, o.ReplicaMaxLag)
+
 		mux := http.NewServeMux()
 
-		config := huma.DefaultConfig("Todo API", "1.0.0")
-		config.Info.Description = "SQLite + sqlc + Humaを使ったシンプルなTodo API"
+		config := huma.DefaultConfig("Todo API", apiVersion)
+		config.Info.Description = "SQLite + sqlc + Humaを使ったシンプルなTodo API。TodoとTodoの一覧は、Acceptヘッダーにapplication/vnd.api+json（JSON:API）かapplication/hal+json（HAL）を指定すると、関連リソースへのリンク付きの形式で返します。"
 		config.CreateHooks = []func(huma.Config) huma.Config{}
+		if o.DeprecationHeader {
+			config.Transformers = append(config.Transformers, middleware.DeprecationTransformer())
+		}
+		// Todoのレスポンスは、Acceptヘッダーで選んだ場合にJSON:APIかHALの形式で返す。非推奨フィールドは元のレスポンスの値で調べるため、その後に包む
+		config.Formats = maps.Clone(config.Formats)
+		config.Formats[model.MediaTypeJSONAPI] = huma.DefaultJSONFormat
+		config.Formats[model.MediaTypeHAL] = huma.DefaultJSONFormat
+		config.Transformers = append(config.Transformers, middleware.HypermediaTransformer(config.DefaultFormat, config.Formats))
+		authProviders, err := auth.ParseProviders(o.AuthProviders)
+		if err != nil {
+			slog.Error("auth-providersの指定が不正です", "err", err)
+			os.Exit(1)
+		}
+		if slices.Contains(authProviders, auth.ProviderOIDC) && o.OIDCIssuer == "" {
+			slog.Error("認証プロバイダーoidcにはoidc-issuerが必要です")
+			os.Exit(1)
+		}
+		// 認証プロバイダーはいずれか1つを満たせばよいため、使うスキームをそれぞれ別の要件にする
+		config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{}
+		if bearer := middleware.BearerSecuritySchemeDef(authProviders); bearer != nil {
+			config.Components.SecuritySchemes[middleware.BearerSecurityScheme] = bearer
+			config.Security = append(config.Security, map[string][]string{middleware.BearerSecurityScheme: {}})
+		}
+		if slices.Contains(authProviders, auth.ProviderAPIKey) {
+			config.Components.SecuritySchemes[middleware.APIKeySecurityScheme] = middleware.APIKeySecuritySchemeDef()
+			config.Security = append(config.Security, map[string][]string{middleware.APIKeySecurityScheme: {}})
+		}
+		// OIDCはアクセストークンを取得する手段のため、スキームのみを登録して要件には含めない
+		if o.OIDCIssuer != "" {
+			config.Components.SecuritySchemes[middleware.OIDCSecurityScheme] = middleware.OIDCSecuritySchemeDef(o.OIDCIssuer)
+		}
 		api := humago.New(mux, config)
+		api.OpenAPI().OnAddOperation = append(api.OpenAPI().OnAddOperation, apidoc.DocumentValidationErrors)
+		operationUsageHandler := handler.NewOperationUsageHandler(queries, api.OpenAPI())
+		// 同期チャネルの変更の依頼はRESTの操作と同じ検証と認可を通すため、操作を登録したルーターで処理する
+		syncHandler := handler.NewSyncHandler(queries, bus, mux, api.OpenAPI())
+		secretProvider, err := secrets.ParseProviders(o.SecretProviders, secrets.VaultConfig{
+			Addr:  o.VaultAddr,
+			Token: os.Getenv("VAULT_TOKEN"),
+			Path:  o.VaultPath,
+		})
+		if err != nil {
+			slog.Error("秘密情報プロバイダーの設定が不正です", "err", err)
+			os.Exit(1)
+		}
+		secretManager := secrets.NewManager(secretProvider, o.SecretTTL)
+
+		// 障害に備えてスナップショットを別の場所に複製する。レプリカはプライマリを複製したものなので複製しない
+		var replicator *replication.Replicator
+		if o.ReplicationURL != "" && !o.ReadOnly {
+			target, err := replication.OpenTarget(o.ReplicationURL, replication.Config{
+				Endpoint: o.ReplicationEndpoint,
+				Region:   o.ReplicationRegion,
+				Secrets:  secretManager,
+			})
+			if err != nil {
+				slog.Error("複製先を開けません", "replication_url", o.ReplicationURL, "err", err)
+				os.Exit(1)
+			}
+			replicator = replication.NewReplicator(backupDB, target, o.ReplicationInterval, o.ReplicationRetention)
+		}
+		replicationTargetHandler := handler.NewReplicationTargetHandler(replicator)
+
+		if !locale.Supported(o.Locale) {
+			slog.Error("localeにはjaかenを指定してください", "locale", o.Locale)
+			os.Exit(1)
+		}
+		timezone, err := time.LoadLocation(o.Timezone)
+		if err != nil {
+			slog.Error("timezoneにはIANAのタイムゾーン名を指定してください", "timezone", o.Timezone, "err", err)
+			os.Exit(1)
+		}
+		defaultFormatter := locale.New(o.Locale, timezone)
+		// /docsで試せる操作の例は、デモ用のデータと同じIDと、時計の今日を基準にした日時で組み立てる
+		api.OpenAPI().OnAddOperation = append(api.OpenAPI().OnAddOperation, apidoc.NewExamples(clk.Now().In(timezone)).Document)
+
+		// 送信先のチャットが設定されていないプロジェクトのTodoの通知はログに出力する
+		chats := map[string]*notify.ChatNotifier{
+			notify.ChannelDiscord: notify.NewChatNotifier(notify.ChannelDiscord, queries, notify.NewLogNotifier(), defaultFormatter, 10*time.Second),
+			notify.ChannelSlack:   notify.NewChatNotifier(notify.ChannelSlack, queries, notify.NewLogNotifier(), defaultFormatter, 10*time.Second),
+			notify.ChannelTeams:   notify.NewChatNotifier(notify.ChannelTeams, queries, notify.NewLogNotifier(), defaultFormatter, 10*time.Second),
+		}
+		chatChannelHandler := handler.NewChatChannelHandler(queries, chats)
+		notifier := newNotifier(o, secretManager, defaultFormatter, chats)
+		recorder := audit.NewRecorder(queries, notifier, o.SecurityAlertChannel, o.AuthEventInterval)
+
+		var diskMonitor *diskspace.Monitor
+		if dir := databaseDir(o.DatabaseURL); dir != "" && o.DiskMinFree > 0 && !o.ReadOnly {
+			diskMonitor = diskspace.NewMonitor(dir, uint64(o.DiskMinFree), o.DiskCheckInterval, notifier, o.DiskAlertChannel, defaultFormatter)
+			// 起動した時点で空き容量が不足している場合も、最初のリクエストから拒否する
+			diskMonitor.Check(context.Background())
+		}
+		healthHandler := handler.NewHealthHandler(queries, sqlDB, o.ReadOnly, diskMonitor, o.ReadinessTimeout)
+		// プローブは認証やレート制限の対象外にするため、HumaのAPIを通さずに登録する
+		mux.HandleFunc("GET /healthz", healthHandler.Liveness)
+		mux.HandleFunc("GET /readyz", healthHandler.Readiness)
+		if o.WebUI {
+			// アプリのページとファイルはAPIのキャッシュやレート制限の対象にしないよう、HumaのAPIを通さずに登録する
+			ui := webui.Handler()
+			mux.Handle("GET /{$}", ui)
+			mux.Handle("GET "+webui.AssetsPrefix, ui)
+		}
+
+		escalationThresholds, err := scheduler.ParseEscalationThresholds(o.EscalationThresholds)
+		if err != nil {
+			slog.Error("escalation-thresholdsの指定が不正です", "err", err)
+			os.Exit(1)
+		}
+		cacheTTLs, err := middleware.ParseCacheTTLs(o.CacheTTLs)
+		if err != nil {
+			slog.Error("cache-ttlsの指定が不正です", "err", err)
+			os.Exit(1)
+		}
+		responseCache := middleware.NewResponseCache(o.ResponseCacheSize, cacheTTLs)
+		expvar.Publish("response_cache", expvar.Func(func() any { return responseCache.Metrics() }))
+
+		if o.StaleAfter > 0 && o.PublicURL == "" {
+			slog.Error("stale-afterを指定する場合は、通知のリンクに使うpublic-urlを指定してください")
+			os.Exit(1)
+		}
+		actionLinks := actionlink.NewSigner(secretManager, o.PublicURL, o.ActionLinkTTL)
+		reviewHandler := handler.NewReviewHandler(queries, sqlDB, bus, actionLinks, o.StaleSnooze, clk)
+		quickActionHandler := handler.NewQuickActionHandler(queries, sqlDB, bus, actionLinks, clk)
+		// public-urlがない場合はリンクを組み立てられないため、リマインダーに操作のリンクを含めない
+		var reminderLinks *actionlink.Signer
+		if o.PublicURL != "" {
+			reminderLinks = actionLinks
+		}
+		var dueDigestAt time.Duration
+		if o.DueDigestTime != "" {
+			if dueDigestAt, err = scheduler.ParseDigestTime(o.DueDigestTime); err != nil {
+				slog.Error("due-digest-timeの指定が不正です", "err", err)
+				os.Exit(1)
+			}
+		}
+
+		defaultRole := auth.Role(o.DefaultRole)
+		if !defaultRole.Valid() {
+			slog.Error("default-roleにはviewer、editor、adminのいずれかを指定してください", "default_role", o.DefaultRole)
+			os.Exit(1)
+		}
+
+		var jwks *auth.JWKS
+		if o.JWKSURL != "" {
+			jwks = auth.NewJWKS(o.JWKSURL, o.JWKSRefresh)
+		} else if key, _, err := secretManager.Lookup(context.Background(), secrets.JWTSigningKey); slices.Contains(authProviders, auth.ProviderJWT) && (err != nil || key == "") && !specMode {
+			// 検証できる鍵がなければJWTのリクエストがすべて401になるため、起動しない。ドキュメントの生成では鍵を使わない
+			slog.Error("JWTの検証鍵がありません。jwks-urlか秘密情報jwt_signing_keyを設定してください", "err", err)
+			os.Exit(1)
+		}
+
+		attachmentHandler := handler.NewAttachmentHandler(queries, attachmentStore, o.MaxAttachmentSize)
+		userHandler := handler.NewUserHandler(queries, sqlDB, auth.NewSigner(auth.SignerConfig{
+			Secrets:  secretManager,
+			Issuer:   o.JWTIssuer,
+			Audience: o.JWTAudience,
+			TTL:      o.TokenTTL,
+		}), recorder, defaultRole, bus, confirmer, o.InviteOnly, clk)
+		invitationHandler := handler.NewInvitationHandler(queries, defaultRole, o.InvitationTTL, clk)
+
+		importHandler := handler.NewImportHandler(queries, projectHandler, importer.NewGoogleTasks(importer.GoogleConfig{
+			ClientID: o.GoogleClientID,
+			Secrets:  secretManager,
+		}))
+		if !o.ReadOnly {
+			// 前回の停止で中断したインポートは再開できないため、失敗として記録する
+			if n, err := queries.FailInterruptedImportJobs(context.Background(), sql.NullString{String: "サーバーの停止により中断しました", Valid: true}); err != nil {
+				slog.Warn("中断したインポートジョブの記録に失敗", "err", err)
+			} else if n > 0 {
+				slog.Info("中断したインポートジョブを失敗として記録", "count", n)
+			}
+		}
+
+		var oidcProvider *auth.OIDC
+		var oidcHandler *handler.OIDCHandler
+		if o.OIDCIssuer != "" {
+			if o.OIDCClientID == "" || o.OIDCRedirectURL == "" {
+				slog.Error("OIDCログインにはoidc-client-idとoidc-redirect-urlが必要です", "oidc_issuer", o.OIDCIssuer)
+				os.Exit(1)
+			}
+			oidcProvider = auth.NewOIDC(auth.OIDCConfig{
+				Issuer:      o.OIDCIssuer,
+				ClientID:    o.OIDCClientID,
+				RedirectURL: o.OIDCRedirectURL,
+				Scopes:      strings.Split(o.OIDCScopes, ","),
+				Secrets:     secretManager,
+				JWKSRefresh: o.JWKSRefresh,
+				Leeway:      o.JWTLeeway,
+			})
+			oidcHandler = handler.NewOIDCHandler(userHandler, oidcProvider, o.OIDCIssuer, o.OIDCRedirectURL)
+		}
+
+		// 認証プロバイダーはauth-providersの順に試す
+		var authenticators auth.Stack
+		for _, p := range authProviders {
+			switch p {
+			case auth.ProviderStatic:
+				authenticators = append(authenticators, auth.NewStaticTokenAuthenticator(secretManager))
+			case auth.ProviderJWT:
+				authenticators = append(authenticators, auth.NewJWTAuthenticator(auth.NewVerifier(auth.Config{
+					Secrets:  secretManager,
+					JWKS:     jwks,
+					Issuer:   o.JWTIssuer,
+					Audience: o.JWTAudience,
+					Leeway:   o.JWTLeeway,
+				})))
+			case auth.ProviderAPIKey:
+				authenticators = append(authenticators, auth.NewAPIKeyAuthenticator(queries))
+			case auth.ProviderOIDC:
+				authenticators = append(authenticators, auth.NewOIDCAuthenticator(oidcProvider, queries))
+			}
+		}
+		slog.Info("認証プロバイダーを設定", "auth_providers", authProviders)
 
 		// ミドルウェア設定
-		api.UseMiddleware(LoggingMiddleware)
-		api.UseMiddleware(AuthMiddleware)
+		sloAvailability, err := slo.ParseTarget(o.SLOAvailability)
+		if err != nil {
+			slog.Error("slo-availabilityの指定が不正です", "err", err)
+			os.Exit(1)
+		}
+		sloLatency, err := slo.ParseTarget(o.SLOLatency)
+		if err != nil {
+			slog.Error("slo-latencyの指定が不正です", "err", err)
+			os.Exit(1)
+		}
+		sloTracker := slo.NewTracker(slo.Config{
+			Availability:     sloAvailability,
+			Latency:          sloLatency,
+			LatencyThreshold: o.SLOLatencyThreshold,
+			Window:           o.SLOWindow,
+		})
+		sloUntimed := make(map[string]bool)
+		for _, id := range splitList(o.SLOLatencyExclude) {
+			sloUntimed[id] = true
+		}
+		sloHandler := handler.NewSLOHandler(sloTracker)
+		api.UseMiddleware(middleware.Tracing)
+		api.UseMiddleware(middleware.SLO(sloTracker, sloUntimed))
+		api.UseMiddleware(middleware.Locale(defaultFormatter))
+		if o.QueryTimeout > 0 {
+			api.UseMiddleware(middleware.QueryTimeout(o.QueryTimeout))
+		}
+		if o.ReadOnly {
+			slog.Info("読み取り専用のレプリカとして起動", "replica_source", o.ReplicaSource, "replica_primary_url", o.ReplicaPrimaryURL)
+			api.UseMiddleware(middleware.ReadOnly(api))
+		}
+		if diskMonitor != nil {
+			api.UseMiddleware(middleware.DiskSpace(api, diskMonitor))
+		}
+		api.UseMiddleware(middleware.AuthAudit(recorder))
+		api.UseMiddleware(middleware.Auth(api, authenticators))
+		if o.RateLimit > 0 {
+			if o.RateLimitBurst <= 0 {
+				slog.Error("rate-limit-burstには正の値を指定してください", "rate_limit_burst", o.RateLimitBurst)
+				os.Exit(1)
+			}
+			api.UseMiddleware(middleware.NewRateLimiter(o.RateLimit, o.RateLimitBurst).Middleware(api))
+		}
+		// レプリカは利用量を書き込めないため、プライマリで受け付けた呼び出しのみを数える
+		var meter *usage.Meter
+		if !o.ReadOnly {
+			meter = usage.NewMeter(queries, o.UsageInterval)
+			api.UseMiddleware(middleware.Usage(meter))
+		}
+		api.UseMiddleware(middleware.Actor)
+		api.UseMiddleware(middleware.Authorize(api, queries, defaultRole))
+		api.UseMiddleware(responseCache.Middleware())
+
+		// Idempotency-Keyによるリトライを受け付ける操作に個別に設定する
+		idempotent := middleware.IdempotencyKey(api, queries, o.IdempotencyKeyTTL)
 
 		huma.Register(api, huma.Operation{
 			OperationID: "list-todos",
 			Method:      http.MethodGet,
 			Path:        "/todos",
 			Summary:     "Todo一覧取得",
-			Description: "すべてのTodoを取得",
+			Description: "すべてのTodoを取得します。アーカイブ済みのプロジェクトに属するTodoはinclude_archived_lists=trueを指定した場合のみ含まれます。metadata=パス:値でmetadataの値による絞り込みができます。expandにtags、subtasksやcomments.countなどを指定すると、関連リソースをまとめて含めます。",
+			Tags:        []string{"todos"},
+			Metadata:    middleware.CacheMetadata(middleware.CachePolicy{TTL: 5 * time.Second, VaryBy: []string{"completed", "near", "radius", "include_archived_lists", "metadata", "expand"}}),
+		}, todoHandler.ListTodos)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "export-todos",
+			Method:      http.MethodGet,
+			Path:        "/todos/export",
+			Summary:     "Todoのエクスポート",
+			Description: "閲覧できるすべてのTodoを、format=csvではヘッダー行付きのCSV、format=jsonではTodoの配列としてダウンロードします。status、project_id、include_archived_lists、metadataで絞り込めます。件数が多くてもメモリに溜めず、IDの順に一定件数ずつ取得して送ります。CSVでは表計算ソフトで数式として実行されないよう、=や+などで始まる値の先頭に'を付けます。",
 			Tags:        []string{"todos"},
-		}, handler.ListTodos)
+			Responses: map[string]*huma.Response{
+				"200": {
+					Description: "TodoのCSVまたはJSON",
+					Content: map[string]*huma.MediaType{
+						"text/csv":         {Schema: &huma.Schema{Type: "string"}},
+						"application/json": {Schema: &huma.Schema{Type: "array", Items: &huma.Schema{Ref: "#/components/schemas/TodoResponse"}}},
+					},
+				},
+			},
+		}, todoHandler.ExportTodos)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "import-todos",
+			Method:      http.MethodPost,
+			Path:        "/todos/import",
+			Summary:     "Todoのインポート",
+			Description: "multipart/form-dataのfileフィールドで送信したCSVまたはJSONのファイルからTodoを作成します。ファイルはGET /todos/exportと同じ形式で、CSVはヘッダー行の列名で、JSONはTodoの配列の要素のフィールド名で値を読み込み、id、owner_id、created_atなどインポートしない列は無視します。" +
+				"行ごとにTodoの作成と同じ検証を行い、インポートできない行は飛ばして行番号と理由をerrorsで返します。500件ごとに別のトランザクションで作成します。dry_run=trueでは検証だけを行い、何も作成しません。ファイルは10MiBまでです。",
+			Tags: []string{"todos"},
+		}, todoHandler.ImportTodos)
 
 		huma.Register(api, huma.Operation{
 			OperationID: "get-todo",
 			Method:      http.MethodGet,
 			Path:        "/todos/{id}",
 			Summary:     "Todo取得",
-			Description: "指定したIDのTodoを取得します。",
+			Description: "指定したIDのTodoを取得します。ETagヘッダーにTodoのバージョンを返し、If-None-MatchまたはIf-Modified-Sinceに一致した場合は304を返します。",
 			Tags:        []string{"todos"},
-		}, handler.GetTodo)
+			Metadata:    middleware.CacheMetadata(middleware.CachePolicy{TTL: 5 * time.Second, VaryBy: []string{"expand"}}),
+		}, todoHandler.GetTodo)
 
 		huma.Register(api, huma.Operation{
 			OperationID:   "create-todo",
@@ -137,71 +883,1565 @@ func main() {
 			Description:   "新しいTodoを作成します。",
 			Tags:          []string{"todos"},
 			DefaultStatus: http.StatusCreated,
-		}, handler.CreateTodo)
+			Parameters:    []*huma.Param{middleware.IdempotencyKeyParam()},
+			Middlewares:   huma.Middlewares{idempotent},
+		}, todoHandler.CreateTodo)
 
 		huma.Register(api, huma.Operation{
 			OperationID: "update-todo",
 			Method:      http.MethodPut,
 			Path:        "/todos/{id}",
 			Summary:     "Todo更新",
-			Description: "指定したIDのTodoを更新します。",
+			Description: "指定したIDのTodoを更新します。If-MatchヘッダーにTodo取得時のETagが必要で、他の更新と競合した場合は412を返します。",
 			Tags:        []string{"todos"},
-		}, handler.UpdateTodo)
+		}, todoHandler.UpdateTodo)
 
 		huma.Register(api, huma.Operation{
 			OperationID: "delete-todo",
 			Method:      http.MethodDelete,
 			Path:        "/todos/{id}",
 			Summary:     "Todo削除",
-			Description: "指定したIDのTodoを削除します。",
+			Description: "指定したIDのTodoを削除します。If-MatchヘッダーにTodo取得時のETagが必要で、他の更新と競合した場合は412を返します。",
 			Tags:        []string{"todos"},
-		}, handler.DeleteTodo)
+		}, todoHandler.DeleteTodo)
 
 		huma.Register(api, huma.Operation{
 			OperationID: "toggle-todo",
 			Method:      http.MethodPost,
 			Path:        "/todos/{id}/toggle",
 			Summary:     "Todo完了状態切り替え",
-			Description: "指定したIDのTodoの完了状態を切り替えます。",
+			Description: "指定したIDのTodoの完了状態を切り替えます。If-Matchヘッダーを指定した場合はETagと照合します。",
 			Tags:        []string{"todos"},
-		}, handler.ToggleTodo)
+		}, todoHandler.ToggleTodo)
 
-		srv := &http.Server{
-			Addr:              fmt.Sprintf("%s:%d", o.Host, o.Port),
-			Handler:           mux,
-			ReadHeaderTimeout: 5 * time.Second,  // ヘッダ読み取り制限
-			ReadTimeout:       15 * time.Second, // 全体の読み取り制限
-			WriteTimeout:      15 * time.Second, // レスポンス書き込み制限
-			IdleTimeout:       60 * time.Second, // keep-alive制御
-		}
+		huma.Register(api, huma.Operation{
+			OperationID: "archive-todo",
+			Method:      http.MethodPost,
+			Path:        "/todos/{id}/archive",
+			Summary:     "Todoアーカイブ",
+			Description: "指定したIDのTodoをアーカイブします。アーカイブしたTodoはinclude_archived_lists=trueを指定した場合のみ一覧に含まれ、期限切れによる優先度の引き上げや放置されたTodoの通知の対象になりません。If-Matchヘッダーを指定した場合はETagと照合します。",
+			Tags:        []string{"todos"},
+		}, todoHandler.ArchiveTodo)
 
-		h.OnStart(func() {
-			slog.Info("サーバー起動開始...")
-			addr := fmt.Sprintf("%s:%d", o.Host, o.Port)
-			fmt.Printf("🚀 Todo API Server starting on http://%s\n", addr)
-			fmt.Printf("📚 API Documentation: http://%s/docs\n", addr)
-			fmt.Printf("📚 Get OpenAPI File: http://%s/openapi.yaml\n", addr)
-			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				slog.Error("サーバー起動に失敗", "err", err)
-				os.Exit(1)
-			}
-		})
+		huma.Register(api, huma.Operation{
+			OperationID: "unarchive-todo",
+			Method:      http.MethodPost,
+			Path:        "/todos/{id}/unarchive",
+			Summary:     "Todoアーカイブ解除",
+			Description: "指定したIDのアーカイブ済みのTodoを元に戻します。If-Matchヘッダーを指定した場合はETagと照合します。",
+			Tags:        []string{"todos"},
+		}, todoHandler.UnarchiveTodo)
 
-		h.OnStop(func() {
-			slog.Info("Shutting down server...")
-			slog.Info("サーバーのシャットダウン開始...")
+		huma.Register(api, huma.Operation{
+			OperationID: "archive-stale-todo",
+			Method:      http.MethodGet,
+			Path:        actionlink.PathPrefix + actionlink.ActionArchive + "/{token}",
+			Summary:     "放置されたTodoをリンクからアーカイブ",
+			Description: "放置されたTodoの通知に含まれるリンクです。トークンが表すユーザーとしてTodoをアーカイブするため、認証は不要です。既にアーカイブ済みの場合は何もしません。トークンが無効か期限切れの場合は404を返します。",
+			Tags:        []string{"todos"},
+			Security:    []map[string][]string{},
+			Metadata:    middleware.WritesMetadata(),
+		}, reviewHandler.ArchiveStaleTodo)
 
-			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-			defer cancel()
+		huma.Register(api, huma.Operation{
+			OperationID: "snooze-stale-todo",
+			Method:      http.MethodGet,
+			Path:        actionlink.PathPrefix + actionlink.ActionSnooze + "/{token}",
+			Summary:     "放置されたTodoの通知をリンクから延期",
+			Description: "放置されたTodoの通知に含まれるリンクです。stale-snoozeの期間が過ぎるまで、Todoを放置されたTodoの通知に含めません。トークンが表すユーザーとして操作するため、認証は不要です。トークンが無効か期限切れの場合は404を返します。",
+			Tags:        []string{"todos"},
+			Security:    []map[string][]string{},
+			Metadata:    middleware.WritesMetadata(),
+		}, reviewHandler.SnoozeStaleTodo)
 
-			if err := srv.Shutdown(ctx); err != nil {
-				slog.Error("サーバーのシャットダウンに失敗", "err", err)
-				os.Exit(1)
+		huma.Register(api, huma.Operation{
+			OperationID: "perform-quick-action",
+			Method:      http.MethodGet,
+			Path:        actionlink.QuickActionPathPrefix + "{token}",
+			Summary:     "通知のリンクからTodoを操作",
+			Description: "リマインダーの通知に含まれる、1回だけ使えるリンクです。トークンが表すユーザーとして、Todoの完了、1日後の再通知、削除のいずれかを行うため、認証は不要です。" +
+				"リンクの使用はIPアドレスとUser-Agentとともに記録し、使用済みのリンクは410を返します。トークンが無効か期限切れの場合は404を返します。",
+			Tags:     []string{"todos"},
+			Security: []map[string][]string{},
+			Metadata: middleware.WritesMetadata(),
+		}, quickActionHandler.PerformQuickAction)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "duplicate-todo",
+			Method:        http.MethodPost,
+			Path:          "/todos/{id}/duplicate",
+			Summary:       "Todo複製",
+			Description:   "指定したIDのTodoを未完了の状態で複製します。タイトルには\"Copy of \"が付きます。サブタスクと添付ファイルも複製できます。",
+			Tags:          []string{"todos"},
+			DefaultStatus: http.StatusCreated,
+		}, todoHandler.DuplicateTodo)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "move-todo",
+			Method:      http.MethodPost,
+			Path:        "/todos/{id}/move",
+			Summary:     "Todo移動",
+			Description: "指定したIDのTodoを別のプロジェクトや並び順に移動します。移動元と移動先の並び順はまとめて更新されます。If-Matchヘッダーを指定した場合はETagと照合します。",
+			Tags:        []string{"todos"},
+		}, todoHandler.MoveTodo)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "bulk-delete-todos",
+			Method:      http.MethodPost,
+			Path:        "/todos/bulk-delete",
+			Summary:     "Todo一括削除",
+			Description: "指定したIDのTodoをまとめて削除します。dry_run=trueの場合は削除される内容のみを返します。",
+			Tags:        []string{"todos"},
+			Parameters:  []*huma.Param{middleware.IdempotencyKeyParam()},
+			Middlewares: huma.Middlewares{idempotent},
+		}, todoHandler.BulkDeleteTodos)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "clear-completed-todos",
+			Method:      http.MethodPost,
+			Path:        "/todos/clear-completed",
+			Summary:     "完了済みTodo一括削除",
+			Description: "完了済みのTodoをすべて削除します。dry_run=trueの場合は削除される内容のみを返します。",
+			Tags:        []string{"todos"},
+			Parameters:  []*huma.Param{middleware.IdempotencyKeyParam()},
+			Middlewares: huma.Middlewares{idempotent},
+		}, todoHandler.ClearCompletedTodos)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-todo-history",
+			Method:      http.MethodGet,
+			Path:        "/todos/{id}/history",
+			Summary:     "Todoの変更履歴取得",
+			Description: "指定したIDのTodoの作成・更新・削除・完了切り替えの履歴を新しい順に取得します。",
+			Tags:        []string{"activity"},
+		}, activityHandler.ListTodoHistory)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-activity",
+			Method:      http.MethodGet,
+			Path:        "/activity",
+			Summary:     "アクティビティフィード取得",
+			Description: "すべてのTodoの変更履歴を新しい順に取得します。",
+			Tags:        []string{"activity"},
+		}, activityHandler.ListActivity)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-todo-description",
+			Method:      http.MethodGet,
+			Path:        "/todos/{id}/description",
+			Summary:     "共同編集する説明の取得",
+			Description: "指定したIDのTodoの説明の現在の版と内容を取得します。説明を共同編集する場合は、この版を基に操作を作成します。",
+			Tags:        []string{"todos"},
+		}, descriptionHandler.GetDescription)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-todo-description-ops",
+			Method:      http.MethodGet,
+			Path:        "/todos/{id}/description/ops",
+			Summary:     "説明の操作履歴取得",
+			Description: "指定したIDのTodoの説明に、sinceの版より後に適用された操作を取得します。他のユーザーの変更を手元の説明に反映するために使います。",
+			Tags:        []string{"todos"},
+		}, descriptionHandler.ListDescriptionOps)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "edit-todo-description",
+			Method:        http.MethodPost,
+			Path:          "/todos/{id}/description/ops",
+			Summary:       "説明の共同編集",
+			Description:   "base_revisionの版の説明に対する操作（retain、insert、delete）を送信します。サーバーはその版より後に他のユーザーが適用した操作に合わせて変換してから適用するため、並行に編集しても互いの変更を上書きしません。If-Matchは不要です。",
+			Tags:          []string{"todos"},
+			DefaultStatus: http.StatusOK,
+		}, descriptionHandler.EditDescription)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-subtasks",
+			Method:      http.MethodGet,
+			Path:        "/todos/{id}/subtasks",
+			Summary:     "サブタスク一覧取得",
+			Description: "指定したIDのTodoに紐づくサブタスクをすべて取得します。",
+			Tags:        []string{"subtasks"},
+		}, subtaskHandler.ListSubtasks)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-subtask",
+			Method:      http.MethodGet,
+			Path:        "/todos/{id}/subtasks/{subtask_id}",
+			Summary:     "サブタスク取得",
+			Description: "指定したIDのサブタスクを取得します。",
+			Tags:        []string{"subtasks"},
+		}, subtaskHandler.GetSubtask)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "create-subtask",
+			Method:        http.MethodPost,
+			Path:          "/todos/{id}/subtasks",
+			Summary:       "サブタスク作成",
+			Description:   "指定したIDのTodoにサブタスクを追加します。",
+			Tags:          []string{"subtasks"},
+			DefaultStatus: http.StatusCreated,
+		}, subtaskHandler.CreateSubtask)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "update-subtask",
+			Method:      http.MethodPut,
+			Path:        "/todos/{id}/subtasks/{subtask_id}",
+			Summary:     "サブタスク更新",
+			Description: "指定したIDのサブタスクを更新します。",
+			Tags:        []string{"subtasks"},
+		}, subtaskHandler.UpdateSubtask)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-subtask",
+			Method:      http.MethodDelete,
+			Path:        "/todos/{id}/subtasks/{subtask_id}",
+			Summary:     "サブタスク削除",
+			Description: "指定したIDのサブタスクを削除します。",
+			Tags:        []string{"subtasks"},
+		}, subtaskHandler.DeleteSubtask)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "set-todo-tags",
+			Method:      http.MethodPut,
+			Path:        "/todos/{id}/tags",
+			Summary:     "Todoのタグ設定",
+			Description: "指定したIDのTodoに付けるタグを、指定した名前のタグに置き換えます。まだないタグはTodoの所有者のタグとして作成します。",
+			Tags:        []string{"tags"},
+		}, tagHandler.SetTodoTags)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-tags",
+			Method:      http.MethodGet,
+			Path:        "/tags",
+			Summary:     "タグ一覧取得",
+			Description: "認証したユーザーのタグを、付けているTodoの件数とともに名前の順で取得します。",
+			Tags:        []string{"tags"},
+		}, tagHandler.ListTags)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "rename-tag",
+			Method:      http.MethodPatch,
+			Path:        "/tags/{id}",
+			Summary:     "タグの名前の変更",
+			Description: "タグの名前を変更し、タグを付けたすべてのTodoに反映します。タグが変わったTodoごとに変更イベントとアクティビティを記録します。" +
+				"同じ名前のタグが既にある場合は409を返します。dry_run=trueの場合は影響するTodoのみを返します。",
+			Tags: []string{"tags"},
+		}, tagHandler.RenameTag)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "merge-tag",
+			Method:      http.MethodPost,
+			Path:        "/tags/{id}/merge",
+			Summary:     "タグの統合",
+			Description: "タグを付けていたTodoに統合先のタグを付け、統合したタグを削除します。1つのトランザクションで実行し、タグが変わったTodoごとに変更イベントとアクティビティを記録します。" +
+				"他のユーザーがロックしているTodoが含まれる場合は423を返し、何も変更しません。dry_run=trueの場合は影響するTodoのみを返します。",
+			Tags:        []string{"tags"},
+			Parameters:  []*huma.Param{middleware.IdempotencyKeyParam()},
+			Middlewares: huma.Middlewares{idempotent},
+		}, tagHandler.MergeTag)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-tag",
+			Method:      http.MethodDelete,
+			Path:        "/tags/{id}",
+			Summary:     "タグの削除",
+			Description: "タグを削除し、すべてのTodoから外します。reassign_toを指定した場合は、削除するタグを付けていたTodoに代わりのタグを付けます。" +
+				"1つのトランザクションで実行し、タグが変わったTodoごとに変更イベントとアクティビティを記録します。dry_run=trueの場合は影響するTodoのみを返します。",
+			Tags: []string{"tags"},
+		}, tagHandler.DeleteTag)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-reminders",
+			Method:      http.MethodGet,
+			Path:        "/todos/{id}/reminders",
+			Summary:     "リマインダー一覧取得",
+			Description: "指定したIDのTodoに設定されたリマインダーをすべて取得します。",
+			Tags:        []string{"reminders"},
+		}, reminderHandler.ListReminders)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "create-reminder",
+			Method:        http.MethodPost,
+			Path:          "/todos/{id}/reminders",
+			Summary:       "リマインダー作成",
+			Description:   "指定したIDのTodoにリマインダーを追加します。通知日時を過ぎるとバックグラウンドで指定チャネルに通知されます。",
+			Tags:          []string{"reminders"},
+			DefaultStatus: http.StatusCreated,
+		}, reminderHandler.CreateReminder)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-reminder",
+			Method:      http.MethodDelete,
+			Path:        "/todos/{id}/reminders/{reminder_id}",
+			Summary:     "リマインダー削除",
+			Description: "指定したIDのリマインダーを削除します。",
+			Tags:        []string{"reminders"},
+		}, reminderHandler.DeleteReminder)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-comments",
+			Method:      http.MethodGet,
+			Path:        "/todos/{id}/comments",
+			Summary:     "コメント一覧取得",
+			Description: "指定したIDのTodoのコメントを投稿日時の古い順に取得します。next_cursorをcursorに指定すると次のページを取得できます。",
+			Tags:        []string{"comments"},
+		}, commentHandler.ListComments)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "create-comment",
+			Method:        http.MethodPost,
+			Path:          "/todos/{id}/comments",
+			Summary:       "コメント投稿",
+			Description:   "指定したIDのTodoにコメントを投稿します。Todoをウォッチしているユーザーに投稿者と本文を通知します。",
+			Tags:          []string{"comments"},
+			DefaultStatus: http.StatusCreated,
+		}, commentHandler.CreateComment)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-comment",
+			Method:      http.MethodDelete,
+			Path:        "/todos/{id}/comments/{comment_id}",
+			Summary:     "コメント削除",
+			Description: "指定したIDのコメントを削除します。",
+			Tags:        []string{"comments"},
+		}, commentHandler.DeleteComment)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "lock-todo",
+			Method:      http.MethodPost,
+			Path:        "/todos/{id}/lock",
+			Summary:     "Todoのロック",
+			Description: "リッチクライアントでの長い編集の間、認証したユーザーとして指定したIDのTodoをロックします。ロックの間、他のユーザーによるTodoの更新、削除、完了の切り替え、アーカイブ、移動、説明の編集とサブタスクの変更は423を返します。" +
+				"ロックはttl_seconds（省略時はlock-ttl、上限はlock-max-ttl）が過ぎると自動で解除されます。自分が持っているロックを取得し直すと期限を延ばし、期限内のロックを他のユーザーが持っている場合は423を返します。",
+			Tags: []string{"locks"},
+		}, lockHandler.AcquireTodoLock)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-todo-lock",
+			Method:      http.MethodGet,
+			Path:        "/todos/{id}/lock",
+			Summary:     "Todoのロックの取得",
+			Description: "指定したIDのTodoのロックを持っているユーザーと期限を返します。ロックされていない場合は404を返します。",
+			Tags:        []string{"locks"},
+		}, lockHandler.GetTodoLock)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "unlock-todo",
+			Method:      http.MethodDelete,
+			Path:        "/todos/{id}/lock",
+			Summary:     "Todoのロックの解除",
+			Description: "認証したユーザーが持っている、指定したIDのTodoのロックを解除します。他のユーザーのロックは423を返しますが、adminロールはforceを指定して解除できます。",
+			Tags:        []string{"locks"},
+		}, lockHandler.ReleaseTodoLock)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "watch-todo",
+			Method:      http.MethodPost,
+			Path:        "/todos/{id}/watch",
+			Summary:     "Todoのウォッチ",
+			Description: "指定したIDのTodoを認証したユーザーがウォッチし、ウォッチしているユーザーの一覧を返します。担当者や所有者でなくても、閲覧できるTodoであればウォッチでき、Todoの更新、完了、移動とコメントの投稿がwatch-channelで通知されます。Todoを変更しないため、viewerロールでも呼び出せます。",
+			Tags:        []string{"watching"},
+			Metadata:    middleware.RoleMetadata(auth.RoleViewer),
+		}, watchHandler.WatchTodo)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "unwatch-todo",
+			Method:      http.MethodDelete,
+			Path:        "/todos/{id}/watch",
+			Summary:     "Todoのウォッチ解除",
+			Description: "指定したIDのTodoの、認証したユーザーによるウォッチを解除します。ウォッチしていない場合も成功します。",
+			Tags:        []string{"watching"},
+			Metadata:    middleware.RoleMetadata(auth.RoleViewer),
+		}, watchHandler.UnwatchTodo)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "bulk-unwatch-todos",
+			Method:      http.MethodPost,
+			Path:        "/todos/bulk-unwatch",
+			Summary:     "Todoのウォッチ一括解除",
+			Description: "指定したIDのTodoの、認証したユーザーによるウォッチをまとめて解除し、解除した件数を返します。ウォッチしていないIDは無視します。",
+			Tags:        []string{"watching"},
+			Metadata:    middleware.RoleMetadata(auth.RoleViewer),
+		}, watchHandler.BulkUnwatchTodos)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-todo-shares",
+			Method:      http.MethodGet,
+			Path:        "/todos/{id}/shares",
+			Summary:     "Todoの共有一覧取得",
+			Description: "指定したIDのTodoを共有しているユーザーと権限を取得します。Todoの所有者のみ呼び出せます。",
+			Tags:        []string{"sharing"},
+		}, shareHandler.ListTodoShares)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "share-todo",
+			Method:      http.MethodPost,
+			Path:        "/todos/{id}/shares",
+			Summary:     "Todoの共有",
+			Description: "指定したIDのTodoをメールアドレスで指定したユーザーと共有します。readは閲覧のみ、writeは変更も許可します。共有済みの場合は権限を更新します。Todoの所有者のみ呼び出せます。",
+			Tags:        []string{"sharing"},
+		}, shareHandler.ShareTodo)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "unshare-todo",
+			Method:      http.MethodDelete,
+			Path:        "/todos/{id}/shares/{share_id}",
+			Summary:     "Todoの共有解除",
+			Description: "指定したIDの共有を解除します。Todoの所有者のみ呼び出せます。",
+			Tags:        []string{"sharing"},
+		}, shareHandler.UnshareTodo)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "upload-attachment",
+			Method:        http.MethodPost,
+			Path:          "/todos/{id}/attachments",
+			Summary:       "添付ファイルのアップロード",
+			Description:   "指定したIDのTodoにファイルを添付します。multipart/form-dataのfileフィールドで送信してください。",
+			Tags:          []string{"attachments"},
+			DefaultStatus: http.StatusCreated,
+		}, attachmentHandler.UploadAttachment)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-attachments",
+			Method:      http.MethodGet,
+			Path:        "/todos/{id}/attachments",
+			Summary:     "添付ファイル一覧取得",
+			Description: "指定したIDのTodoの添付ファイルをすべて取得します。",
+			Tags:        []string{"attachments"},
+		}, attachmentHandler.ListAttachments)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "download-attachment",
+			Method:      http.MethodGet,
+			Path:        "/attachments/{id}",
+			Summary:     "添付ファイルのダウンロード",
+			Description: "指定したIDの添付ファイルをアップロード時のContent-Typeで返します。",
+			Tags:        []string{"attachments"},
+			Responses: map[string]*huma.Response{
+				"200": {
+					Description: "添付ファイルの内容",
+					Content: map[string]*huma.MediaType{
+						"application/octet-stream": {Schema: &huma.Schema{Type: "string", Format: "binary"}},
+					},
+				},
+			},
+		}, attachmentHandler.DownloadAttachment)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-security-events",
+			Method:      http.MethodGet,
+			Path:        "/me/security/events",
+			Summary:     "認証イベント一覧取得",
+			Description: "リクエストの資格情報に紐づく認証イベント（認証の失敗、IPアドレス・端末ごとの利用）を新しい順に取得します。",
+			Tags:        []string{"security"},
+		}, securityHandler.ListSecurityEvents)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-summary",
+			Method:      http.MethodGet,
+			Path:        "/me/summary",
+			Summary:     "ホーム画面用サマリー取得",
+			Description: "期限切れ、今日が期限、自分が担当、自分宛てのメンション、未読のアクティビティの件数をまとめて取得します。モバイルクライアントのホーム画面を1回のリクエストで描画するためのものです。",
+			Tags:        []string{"me"},
+		}, summaryHandler.GetSummary)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-badges",
+			Method:      http.MethodGet,
+			Path:        "/me/badges",
+			Summary:     "バッジの件数取得",
+			Description: "期限切れ、今日が期限、自分が担当、未読のコメントの件数を取得します。クライアントが数秒ごとにポーリングすることを想定し、インデックスだけで数えられるクエリで取得します。件数から生成したETagをIf-None-Matchに指定すると、変化がない場合は304を返します。未読のコメントは/me/summary/readで既読にします。",
+			Tags:        []string{"me"},
+			Metadata:    middleware.CacheMetadata(middleware.CachePolicy{TTL: 2 * time.Second, VaryBy: []string{"tz"}}),
+		}, summaryHandler.GetBadges)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-agenda",
+			Method:      http.MethodGet,
+			Path:        "/agenda",
+			Summary:     "1日のアジェンダ取得",
+			Description: "指定した日が期限のTodo、その日に通知するリマインダー、繰り返しのTodoを完了したときに作成されるその日が期限の次の回を、時刻の順にまとめて取得します。" +
+				"日付の範囲と時刻はtz、X-Timezoneヘッダー、サーバーの既定のタイムゾーンの順で決めます。format=markdownを指定すると印刷向けのチェックリストを返します。",
+			Tags:     []string{"todos"},
+			Metadata: middleware.CacheMetadata(middleware.CachePolicy{TTL: 5 * time.Second, VaryBy: []string{"date", "tz", "format"}}),
+			Responses: map[string]*huma.Response{
+				"200": {
+					Description: "アジェンダ",
+					Content: map[string]*huma.MediaType{
+						"application/json": {Schema: api.OpenAPI().Components.Schemas.Schema(reflect.TypeFor[model.AgendaResponse](), true, "")},
+						"text/markdown":    {},
+					},
+				},
+			},
+		}, agendaHandler.GetAgenda)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "mark-summary-read",
+			Method:      http.MethodPost,
+			Path:        "/me/summary/read",
+			Summary:     "サマリーの既読化",
+			Description: "現時点までのアクティビティとコメントを既読にします。以降のサマリーではこれより後の件数のみを数えます。",
+			Tags:        []string{"me"},
+			Metadata:    middleware.RoleMetadata(auth.RoleViewer),
+		}, summaryHandler.MarkSummaryRead)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-api-keys",
+			Method:      http.MethodGet,
+			Path:        "/api-keys",
+			Summary:     "APIキー一覧取得",
+			Description: "発行済みのAPIキーを取得します。キーそのものは含まれません。",
+			Tags:        []string{"security"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, apiKeyHandler.ListAPIKeys)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "create-api-key",
+			Method:        http.MethodPost,
+			Path:          "/api-keys",
+			Summary:       "APIキー発行",
+			Description:   "自動化クライアント向けのAPIキーを発行します。キーはこの応答でのみ返され、X-API-Keyヘッダーで送信するとJWTの代わりに認証できます。",
+			Tags:          []string{"security"},
+			Metadata:      middleware.RoleMetadata(auth.RoleAdmin),
+			DefaultStatus: http.StatusCreated,
+		}, apiKeyHandler.CreateAPIKey)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "revoke-api-key",
+			Method:      http.MethodDelete,
+			Path:        "/api-keys/{id}",
+			Summary:     "APIキー失効",
+			Description: "指定したIDのAPIキーを失効させます。失効したキーでは認証できなくなります。",
+			Tags:        []string{"security"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, apiKeyHandler.RevokeAPIKey)
+
+		// サインアップとログインはトークンを持たない状態で呼び出すため、認証を要求しない
+		huma.Register(api, huma.Operation{
+			OperationID:   "signup",
+			Method:        http.MethodPost,
+			Path:          "/auth/signup",
+			Summary:       "ユーザー登録",
+			Description:   "ユーザーを登録し、アクセストークンを発行します。登録済みのメールアドレスの場合は409を返します。招待のトークンを指定すると招待のロールで登録します。invite-onlyでは招待のトークンがない場合は403を返します。",
+			Tags:          []string{"auth"},
+			DefaultStatus: http.StatusCreated,
+			Security:      []map[string][]string{},
+		}, userHandler.Signup)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "bootstrap",
+			Method:        http.MethodPost,
+			Path:          "/auth/bootstrap",
+			Summary:       "初期管理者の作成",
+			Description:   "ユーザーが1人もいない場合に、起動時にログへ出力したトークンを指定して初期管理者をadminロールで登録し、アクセストークンを発行します。トークンは1回だけ使え、再起動すると新しいトークンに変わります。ユーザーが登録済みの場合は403か409を返します。",
+			Tags:          []string{"auth"},
+			DefaultStatus: http.StatusCreated,
+			Security:      []map[string][]string{},
+		}, userHandler.Bootstrap)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "login",
+			Method:      http.MethodPost,
+			Path:        "/auth/login",
+			Summary:     "ログイン",
+			Description: "メールアドレスとパスワードを照合し、アクセストークンを発行します。発行したトークンで認証したリクエストは、そのユーザーが所有するTodoのみを対象にします。",
+			Tags:        []string{"auth"},
+			Security:    []map[string][]string{},
+		}, userHandler.Login)
+
+		if oidcHandler != nil {
+			huma.Register(api, huma.Operation{
+				OperationID:   "oidc-login",
+				Method:        http.MethodGet,
+				Path:          "/auth/oidc/login",
+				Summary:       "OIDCログイン開始",
+				Description:   "OIDCプロバイダーのログイン画面へリダイレクトします。ログイン後はプロバイダーから/auth/oidc/callbackへ戻ります。",
+				Tags:          []string{"auth"},
+				Security:      []map[string][]string{},
+				DefaultStatus: http.StatusFound,
+			}, oidcHandler.Login)
+
+			huma.Register(api, huma.Operation{
+				OperationID: "oidc-callback",
+				Method:      http.MethodGet,
+				Path:        "/auth/oidc/callback",
+				Summary:     "OIDCログインのコールバック",
+				Description: "OIDCプロバイダーから戻った認可コードをIDトークンと交換し、アクセストークンを発行します。初めてログインしたアカウントはユーザーとして登録し、プロバイダーが確認済みのメールアドレスが一致するユーザーがいればそのユーザーに紐づけます。",
+				Tags:        []string{"auth"},
+				Security:    []map[string][]string{},
+			}, oidcHandler.Callback)
+		}
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-users",
+			Method:      http.MethodGet,
+			Path:        "/users",
+			Summary:     "ユーザー一覧取得",
+			Description: "登録済みのユーザーとロールを取得します。adminロールが必要です。",
+			Tags:        []string{"auth"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, userHandler.ListUsers)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "update-user-role",
+			Method:      http.MethodPut,
+			Path:        "/users/{id}/role",
+			Summary:     "ユーザーのロール変更",
+			Description: "ユーザーのロールをviewer、editor、adminのいずれかに変更します。adminロールが必要です。最後のadminを降格する場合は409を返します。",
+			Tags:        []string{"auth"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, userHandler.UpdateUserRole)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-user",
+			Method:      http.MethodDelete,
+			Path:        "/users/{id}",
+			Summary:     "ユーザー削除",
+			Description: "ユーザーを所有するTodoとともに削除し、ユーザーが作成したAPIキーを失効させます。adminロールが必要です。X-Confirmation-Tokenを省略すると削除せず、影響範囲と確認トークンを202で返します。確認トークンを指定して再度呼び出すと削除します。最後のadminを削除する場合は409を返します。",
+			Tags:        []string{"auth"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, userHandler.DeleteUser)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-invitations",
+			Method:      http.MethodGet,
+			Path:        "/admin/invitations",
+			Summary:     "招待一覧取得",
+			Description: "招待を新しい順に取得します。トークンそのものは含まれません。adminロールが必要です。",
+			Tags:        []string{"auth"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, invitationHandler.ListInvitations)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "create-invitation",
+			Method:        http.MethodPost,
+			Path:          "/admin/invitations",
+			Summary:       "招待作成",
+			Description:   "メールアドレスを招待し、サインアップで指定するトークンを発行します。トークンはこの応答でのみ返され、invitation-ttlの間1回だけ使えます。OIDCでは確認済みのメールアドレスが一致すればトークンなしで登録できます。登録済みのメールアドレスの場合は409を返します。adminロールが必要です。",
+			Tags:          []string{"auth"},
+			Metadata:      middleware.RoleMetadata(auth.RoleAdmin),
+			DefaultStatus: http.StatusCreated,
+		}, invitationHandler.CreateInvitation)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-invitation",
+			Method:      http.MethodDelete,
+			Path:        "/admin/invitations/{id}",
+			Summary:     "招待取り消し",
+			Description: "指定したIDの招待を削除し、そのトークンで登録できなくします。adminロールが必要です。",
+			Tags:        []string{"auth"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, invitationHandler.DeleteInvitation)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-todo-defaults",
+			Method:      http.MethodGet,
+			Path:        "/admin/todo-defaults",
+			Summary:     "全体のTodoの既定値取得",
+			Description: "Todoを作成する際に、クライアントが省略した優先度、期限、metadata、タグに使う全体の既定値を取得します。プロジェクトの既定値で指定していない項目にも使います。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, todoDefaultsHandler.GetGlobalTodoDefaults)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "put-todo-defaults",
+			Method:      http.MethodPut,
+			Path:        "/admin/todo-defaults",
+			Summary:     "全体のTodoの既定値設定",
+			Description: "全体のTodoの既定値を置き換えます。省略した項目は既定値を外します。OpenAPIのドキュメントのTodo作成のdefaultには起動時の値を反映するため、変更は再起動後に反映されます。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, todoDefaultsHandler.UpdateGlobalTodoDefaults)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-db-advisor",
+			Method:      http.MethodGet,
+			Path:        "/admin/db/advisor",
+			Summary:     "クエリプランの診断",
+			Description: "頻繁に実行されるクエリのEXPLAIN QUERY PLANを現在のデータで実行し、インデックスを使わない全件走査や一時B-treeによる並べ替え、インデックスのない外部キーを報告します。analyze=trueを指定すると先にANALYZEで統計情報を更新します。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, advisorHandler.GetDBAdvisor)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "start-import",
+			Method:        http.MethodPost,
+			Path:          "/admin/imports",
+			Summary:       "外部サービスからのインポート開始",
+			Description:   "Appleのリマインダーから書き出したiCalendarファイル、またはGoogle Tasks APIからリストとタスクを取り込むジョブを開始し、202を返します。リストごとにプロジェクトを作成し、完了状態と期限を引き継ぎ、親を持つタスクは親のTodoのサブタスクにします。進捗はLocationのURLで確認します。adminロールが必要です。",
+			Tags:          []string{"admin"},
+			DefaultStatus: http.StatusAccepted,
+			MaxBodyBytes:  11 * 1024 * 1024,
+			Metadata:      middleware.RoleMetadata(auth.RoleAdmin),
+		}, importHandler.StartImport)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-import-jobs",
+			Method:      http.MethodGet,
+			Path:        "/admin/imports",
+			Summary:     "インポートジョブ一覧取得",
+			Description: "インポートジョブを新しい順に取得します。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, importHandler.ListImportJobs)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-import-job",
+			Method:      http.MethodGet,
+			Path:        "/admin/imports/{id}",
+			Summary:     "インポートジョブ取得",
+			Description: "指定したIDのインポートジョブの状態と、作成したプロジェクトを取得します。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, importHandler.GetImportJob)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-usage",
+			Method:      http.MethodGet,
+			Path:        "/admin/usage",
+			Summary:     "利用量取得",
+			Description: "ユーザーごとの日次のAPIの呼び出し回数、添付ファイルの合計サイズ、未完了のTodoの件数と、期間全体の合計を取得します。費用の配賦や課金の基礎データとして使います。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, usageHandler.GetUsage)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "export-usage",
+			Method:      http.MethodGet,
+			Path:        "/admin/usage/export",
+			Summary:     "利用量のCSVエクスポート",
+			Description: "ユーザーごとの日次の利用量をCSVでエクスポートします。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+			Responses: map[string]*huma.Response{
+				"200": {
+					Description: "日次の利用量のCSV",
+					Content: map[string]*huma.MediaType{
+						"text/csv": {},
+					},
+				},
+			},
+		}, usageHandler.ExportUsage)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-operation-usage",
+			Method:      http.MethodGet,
+			Path:        "/admin/operations/usage",
+			Summary:     "操作ごとの利用状況取得",
+			Description: "登録済みのすべての操作について、記録を始めてからの呼び出し回数と最終使用日時を呼び出し回数の少ない順に取得します。非推奨にする、または削除する前に実際に使われているかを確認するために使います。呼び出し回数はusage-intervalごとに書き込むため、直近の呼び出しは含まれないことがあります。レプリカで受け付けた呼び出しは数えません。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, operationUsageHandler.GetOperationUsage)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-slo",
+			Method:      http.MethodGet,
+			Path:        "/admin/slo",
+			Summary:     "SLOの達成状況取得",
+			Description: "slo-availabilityとslo-latencyで設定した目標ごとに、slo-windowの期間の達成率と残りのエラーバジェット、直近の期間ごとのバーンレート、複数期間のバーンレートのアラートの状態を取得します。外部の監視の仕組みがなくてもSLOを確認できます。記録はメモリに保持するため、起動してからの応答だけを数えます。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, sloHandler.GetSLO)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "create-backup",
+			Method:        http.MethodPost,
+			Path:          "/admin/backups",
+			Summary:       "バックアップ作成",
+			Description:   "データベースのバックアップをVACUUM INTOでbackup-dirに作成し、backup-retentionを超えた古いバックアップを削除します。書き込みを止めずに作成します。復元はサーバーのホストでbackup restoreサブコマンドを実行します。adminロールが必要です。",
+			Tags:          []string{"admin"},
+			DefaultStatus: http.StatusCreated,
+			Metadata:      middleware.RoleMetadata(auth.RoleAdmin),
+		}, backupHandler.CreateBackup)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-backups",
+			Method:      http.MethodGet,
+			Path:        "/admin/backups",
+			Summary:     "バックアップ一覧取得",
+			Description: "backup-dirにあるバックアップを新しい順に取得します。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, backupHandler.ListBackups)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "download-backup",
+			Method:      http.MethodGet,
+			Path:        "/admin/backups/{name}",
+			Summary:     "バックアップのダウンロード",
+			Description: "指定したバックアップをSQLiteのデータベースファイルとして返します。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+			Responses: map[string]*huma.Response{
+				"200": {
+					Description: "SQLiteのデータベースファイル",
+					Content: map[string]*huma.MediaType{
+						"application/vnd.sqlite3": {Schema: &huma.Schema{Type: "string", Format: "binary"}},
+					},
+				},
+			},
+		}, backupHandler.DownloadBackup)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-replication-target",
+			Method:      http.MethodGet,
+			Path:        "/admin/replication",
+			Summary:     "スナップショットの複製の状態取得",
+			Description: "replication-urlへのデータベースのスナップショットの複製について、複製先、最新のスナップショット、最後の複製の結果と次に複製する日時を取得します。複製に失敗し続け、複製先の内容がreplication-intervalの2倍より古くなった場合は503を返します。複製先からの復元はreplication pullサブコマンドでスナップショットを取得し、backup restoreサブコマンドで行います。adminロールが必要です。",
+			Tags:        []string{"admin"},
+			Metadata:    middleware.RoleMetadata(auth.RoleAdmin),
+		}, replicationTargetHandler.GetReplicationTarget)
+
+		// ロードバランサーが遅延したレプリカを振り分けから外すために呼び出すため、認証を要求しない
+		huma.Register(api, huma.Operation{
+			OperationID: "get-replication-status",
+			Method:      http.MethodGet,
+			Path:        "/replication/status",
+			Summary:     "複製の状態取得",
+			Description: "このインスタンスに反映済みの最後の変更を返します。read-onlyで起動したレプリカでは、replica-primary-urlのプライマリと比較した遅延とデータベースの復元元も返し、遅延がreplica-max-lagを超えている場合は503を返します。",
+			Tags:        []string{"admin"},
+			Security:    []map[string][]string{},
+		}, replicationHandler.GetReplicationStatus)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-projects",
+			Method:      http.MethodGet,
+			Path:        "/projects",
+			Summary:     "プロジェクト一覧取得",
+			Description: "プロジェクトをすべて取得します。",
+			Tags:        []string{"projects"},
+			Metadata:    middleware.CacheMetadata(middleware.CachePolicy{TTL: 30 * time.Second, VaryBy: []string{"include_archived"}}),
+		}, projectHandler.ListProjects)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-project",
+			Method:      http.MethodGet,
+			Path:        "/projects/{id}",
+			Summary:     "プロジェクト取得",
+			Description: "指定したIDのプロジェクトを取得します。",
+			Tags:        []string{"projects"},
+			Metadata:    middleware.CacheMetadata(middleware.CachePolicy{TTL: 30 * time.Second}),
+		}, projectHandler.GetProject)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "create-project",
+			Method:        http.MethodPost,
+			Path:          "/projects",
+			Summary:       "プロジェクト作成",
+			Description:   "新しいプロジェクトを作成します。",
+			Tags:          []string{"projects"},
+			DefaultStatus: http.StatusCreated,
+		}, projectHandler.CreateProject)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "update-project",
+			Method:      http.MethodPut,
+			Path:        "/projects/{id}",
+			Summary:     "プロジェクト更新",
+			Description: "指定したIDのプロジェクトを更新します。",
+			Tags:        []string{"projects"},
+		}, projectHandler.UpdateProject)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-project",
+			Method:      http.MethodDelete,
+			Path:        "/projects/{id}",
+			Summary:     "プロジェクト削除",
+			Description: "指定したIDのプロジェクトをアーカイブ、または所属するTodoごと削除します。mode=deleteはX-Confirmation-Tokenを省略すると削除せず、削除されるTodoの件数と確認トークンを202で返します。確認トークンを指定して再度呼び出すと削除します。他のユーザーのTodoを含むプロジェクトをmode=deleteで削除できるのはadminロールのみで、それ以外は403を返します。",
+			Tags:        []string{"projects"},
+		}, projectHandler.DeleteProject)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "unarchive-project",
+			Method:      http.MethodPost,
+			Path:        "/projects/{id}/unarchive",
+			Summary:     "プロジェクトのアーカイブ解除",
+			Description: "アーカイブ済みのプロジェクトを元に戻し、所属するTodoを再び一覧に表示します。",
+			Tags:        []string{"projects"},
+		}, projectHandler.UnarchiveProject)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-project-todos",
+			Method:      http.MethodGet,
+			Path:        "/projects/{id}/todos",
+			Summary:     "プロジェクトのTodo一覧取得",
+			Description: "指定したIDのプロジェクトに所属するTodoを取得します。",
+			Tags:        []string{"projects"},
+			Metadata:    middleware.CacheMetadata(middleware.CachePolicy{TTL: 5 * time.Second, VaryBy: []string{"expand"}}),
+		}, projectHandler.ListProjectTodos)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-project-shares",
+			Method:      http.MethodGet,
+			Path:        "/projects/{id}/shares",
+			Summary:     "プロジェクトの共有一覧取得",
+			Description: "指定したIDのプロジェクトで、自分が所有するTodoを共有しているユーザーと権限を取得します。",
+			Tags:        []string{"sharing"},
+		}, shareHandler.ListProjectShares)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "share-project",
+			Method:      http.MethodPost,
+			Path:        "/projects/{id}/shares",
+			Summary:     "プロジェクトの共有",
+			Description: "指定したIDのプロジェクトで自分が所有するTodoを、メールアドレスで指定したユーザーとまとめて共有します。共有後に追加したTodoも対象になります。共有済みの場合は権限を更新します。",
+			Tags:        []string{"sharing"},
+		}, shareHandler.ShareProject)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "unshare-project",
+			Method:      http.MethodDelete,
+			Path:        "/projects/{id}/shares/{share_id}",
+			Summary:     "プロジェクトの共有解除",
+			Description: "指定したIDのプロジェクトの共有を解除します。",
+			Tags:        []string{"sharing"},
+		}, shareHandler.UnshareProject)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-project-presence",
+			Method:      http.MethodGet,
+			Path:        "/projects/{id}/presence",
+			Summary:     "プロジェクトの閲覧者取得",
+			Description: "指定したIDのプロジェクトを現在開いているユーザーを取得します。",
+			Tags:        []string{"projects"},
+		}, presenceHandler.GetPresence)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "heartbeat-project-presence",
+			Method:      http.MethodPut,
+			Path:        "/projects/{id}/presence",
+			Summary:     "プロジェクトの閲覧の通知",
+			Description: "認証したユーザーが指定したIDのプロジェクトを開いていることを記録します。開いている間はttl_secondsより短い間隔で呼び出してください。閲覧者はインスタンスごとにメモリ上で追跡します。",
+			Tags:        []string{"projects"},
+			Metadata:    middleware.RoleMetadata(auth.RoleViewer),
+		}, presenceHandler.Heartbeat)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "leave-project-presence",
+			Method:      http.MethodDelete,
+			Path:        "/projects/{id}/presence",
+			Summary:     "プロジェクトの閲覧終了の通知",
+			Description: "認証したユーザーが指定したIDのプロジェクトを閉じたことを記録します。",
+			Tags:        []string{"projects"},
+			Metadata:    middleware.RoleMetadata(auth.RoleViewer),
+		}, presenceHandler.Leave)
+
+		sse.Register(api, huma.Operation{
+			OperationID: "stream-project-presence",
+			Method:      http.MethodGet,
+			Path:        "/projects/{id}/presence/events",
+			Summary:     "プロジェクトの閲覧者の変化の購読",
+			Description: "指定したIDのプロジェクトの閲覧者が変わるたびに、現在の閲覧者をServer-Sent Eventsで配信します。接続している間は認証したユーザーを閲覧者として記録します。",
+			Tags:        []string{"projects"},
+		}, handler.PresenceStreamEvents, presenceHandler.StreamPresence)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-project-chat-channels",
+			Method:      http.MethodGet,
+			Path:        "/projects/{id}/chat-channels",
+			Summary:     "プロジェクトのチャットの送信先一覧取得",
+			Description: "指定したIDのプロジェクトのTodoの変更とリマインダーを投稿するDiscord、SlackとTeamsの送信先を取得します。Webhook URLのトークンは伏せて返します。",
+			Tags:        []string{"projects"},
+		}, chatChannelHandler.ListChatChannels)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "put-project-chat-channel",
+			Method:      http.MethodPut,
+			Path:        "/projects/{id}/chat-channels/{provider}",
+			Summary:     "プロジェクトのチャットの送信先設定",
+			Description: "指定したIDのプロジェクトのTodoの作成、更新、完了、担当者の設定と期限切れを、DiscordのWebhook、Slackの着信WebhookまたはTeamsの受信Webhookに投稿するよう設定します。イベントごとにメッセージのテンプレートを指定できます。channelがdiscordまたはteamsのリマインダーと、escalation-channelに指定したチャットへの優先度の引き上げの通知もこの送信先に投稿します。設定済みの場合は置き換えます。",
+			Tags:        []string{"projects"},
+		}, chatChannelHandler.PutChatChannel)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "test-project-chat-channel",
+			Method:      http.MethodPost,
+			Path:        "/projects/{id}/chat-channels/{provider}/test",
+			Summary:     "プロジェクトのチャットの送信先への試験投稿",
+			Description: "指定したIDのプロジェクトのチャットの送信先に見本の通知を投稿し、設定したWebhook URLとテンプレートを確かめます。購読イベントの設定によらず投稿し、eventのテンプレートがあれば適用します。チャットが投稿を受け付けなかった場合は502を返します。",
+			Tags:        []string{"projects"},
+		}, chatChannelHandler.TestChatChannel)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-project-chat-channel",
+			Method:      http.MethodDelete,
+			Path:        "/projects/{id}/chat-channels/{provider}",
+			Summary:     "プロジェクトのチャットの送信先削除",
+			Description: "指定したIDのプロジェクトのチャットの送信先を削除します。",
+			Tags:        []string{"projects"},
+		}, chatChannelHandler.DeleteChatChannel)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-project-todo-defaults",
+			Method:      http.MethodGet,
+			Path:        "/projects/{id}/todo-defaults",
+			Summary:     "プロジェクトのTodoの既定値取得",
+			Description: "指定したIDのプロジェクトにTodoを作成する際に、クライアントが省略した優先度、期限、metadata、タグに使う既定値を取得します。effectiveには全体の既定値を合わせた実際に使う値を返します。",
+			Tags:        []string{"projects"},
+		}, todoDefaultsHandler.GetProjectTodoDefaults)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "put-project-todo-defaults",
+			Method:      http.MethodPut,
+			Path:        "/projects/{id}/todo-defaults",
+			Summary:     "プロジェクトのTodoの既定値設定",
+			Description: "指定したIDのプロジェクトのTodoの既定値を置き換えます。省略した項目は全体の既定値を使います。",
+			Tags:        []string{"projects"},
+		}, todoDefaultsHandler.UpdateProjectTodoDefaults)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-project-todo-defaults",
+			Method:      http.MethodDelete,
+			Path:        "/projects/{id}/todo-defaults",
+			Summary:     "プロジェクトのTodoの既定値削除",
+			Description: "指定したIDのプロジェクトのTodoの既定値を削除し、全体の既定値を使うようにします。",
+			Tags:        []string{"projects"},
+		}, todoDefaultsHandler.DeleteProjectTodoDefaults)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-webhooks",
+			Method:      http.MethodGet,
+			Path:        "/webhooks",
+			Summary:     "Webhook一覧取得",
+			Description: "認証したユーザーが登録したWebhookの一覧を取得します。署名の鍵は返しません。",
+			Tags:        []string{"webhooks"},
+		}, webhookHandler.ListWebhooks)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "create-webhook",
+			Method:        http.MethodPost,
+			Path:          "/webhooks",
+			Summary:       "Webhook登録",
+			Description:   "認証したユーザーのTodoの作成、更新、削除、完了を指定したURLにPOSTで配信するWebhookを登録します。本文は署名の鍵によるHMAC-SHA256署名をX-Todo-Signatureヘッダーに付けて送り、2xx以外の応答や接続の失敗は待ち時間を倍にしながら再送します。署名の鍵はこの応答でのみ返します。",
+			Tags:          []string{"webhooks"},
+			DefaultStatus: http.StatusCreated,
+		}, webhookHandler.CreateWebhook)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-webhook",
+			Method:      http.MethodGet,
+			Path:        "/webhooks/{id}",
+			Summary:     "Webhook取得",
+			Description: "指定したIDのWebhookを取得します。",
+			Tags:        []string{"webhooks"},
+		}, webhookHandler.GetWebhook)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "update-webhook",
+			Method:      http.MethodPut,
+			Path:        "/webhooks/{id}",
+			Summary:     "Webhook更新",
+			Description: "指定したIDのWebhookのURLと購読イベントを置き換え、配信を有効または無効にします。署名の鍵は変わりません。",
+			Tags:        []string{"webhooks"},
+		}, webhookHandler.UpdateWebhook)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-webhook",
+			Method:      http.MethodDelete,
+			Path:        "/webhooks/{id}",
+			Summary:     "Webhook削除",
+			Description: "指定したIDのWebhookと配信の記録を削除します。再送を待っている配信も送信しません。",
+			Tags:        []string{"webhooks"},
+		}, webhookHandler.DeleteWebhook)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-webhook-deliveries",
+			Method:      http.MethodGet,
+			Path:        "/webhooks/{id}/deliveries",
+			Summary:     "Webhookの配信の記録取得",
+			Description: "指定したIDのWebhookへの配信を新しい順に取得します。再送を待っている配信は次に送信する日時を、失敗した配信は最後のエラーと応答のステータスコードを返します。",
+			Tags:        []string{"webhooks"},
+		}, webhookHandler.ListWebhookDeliveries)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "connect-sync",
+			Method:      http.MethodGet,
+			Path:        "/ws",
+			Summary:     "同期チャネルへの接続",
+			Description: "WebSocketで接続し、閲覧できるTodoの変更を受け取りながら変更を依頼します。" +
+				"接続直後にtypeがreadyのメッセージで呼び出せる操作のoperationIdの一覧を送り、Todoが変わるたびにtypeがeventのメッセージで変更後のTodoとETagを送ります。" +
+				"クライアントはtypeがmutateのメッセージでoperation、params（パスパラメータ、残りはクエリパラメータ）、body、if_match、idempotency_keyを送り、楽観的に画面を更新できます。" +
+				"依頼は接続時のAuthorizationまたはX-API-Keyで同じ操作のRESTのリクエストとして処理し、同じidのtypeがresultのメッセージでステータスコードとレスポンスボディを返します。" +
+				"失敗した場合は楽観的な変更を取り消してください。WebSocketのハンドシェイクでないリクエストには426を返します。",
+			Tags: []string{"sync"},
+			Responses: map[string]*huma.Response{
+				"101": {Description: "WebSocketへの切り替え"},
+			},
+		}, syncHandler.Connect)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "export-project",
+			Method:      http.MethodGet,
+			Path:        "/projects/{id}/export",
+			Summary:     "プロジェクトのエクスポート",
+			Description: "プロジェクトと所属するTodo、サブタスク、コメントを1つのJSONバンドルとしてエクスポートします。",
+			Tags:        []string{"projects"},
+		}, projectHandler.ExportProject)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "import-project",
+			Method:        http.MethodPost,
+			Path:          "/projects/import",
+			Summary:       "プロジェクトのインポート",
+			Description:   "エクスポートしたバンドルから新しいプロジェクトを作成します。IDはすべて振り直されます。dry_run=trueの場合は変更を行わずに結果のみを返します。",
+			Tags:          []string{"projects"},
+			DefaultStatus: http.StatusCreated,
+			Parameters:    []*huma.Param{middleware.IdempotencyKeyParam()},
+			Middlewares:   huma.Middlewares{idempotent},
+		}, projectHandler.ImportProject)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "import-file",
+			Method:      http.MethodPost,
+			Path:        "/import/{source}",
+			Summary:     "外部サービスのファイルからのインポート",
+			Description: "multipart/form-dataのfileフィールドで送信した、外部のタスク管理サービスから書き出したファイルを取り込みます。" +
+				"todoistはSync APIの同期結果またはREST APIのprojectsとtasksをまとめたJSON、trelloはボードのメニューから書き出したJSON、apple-remindersはリマインダーから書き出したiCalendarを受け付けます。" +
+				"リスト（Todoistのプロジェクト、Trelloのリスト）ごとにプロジェクトを作成し、ラベルはTodoのタグに、期限はdue_atに、親を持つタスクとTrelloのチェックリストの項目はサブタスクにします。" +
+				"ファイルを解析できない場合は422を返します。リストごとに別のトランザクションで作成します。dry_run=trueの場合は変更を行わずに結果のみを返します。ファイルは10MiBまでです。",
+			Tags:          []string{"projects"},
+			DefaultStatus: http.StatusCreated,
+		}, importHandler.ImportFile)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-filters",
+			Method:      http.MethodGet,
+			Path:        "/filters",
+			Summary:     "保存済みフィルター一覧取得",
+			Description: "保存済みフィルター（スター付き検索）をすべて取得します。",
+			Tags:        []string{"filters"},
+		}, filterHandler.ListSavedFilters)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "create-filter",
+			Method:        http.MethodPost,
+			Path:          "/filters",
+			Summary:       "フィルター保存",
+			Description:   "Todo一覧の絞り込み条件をフィルターとして保存します。",
+			Tags:          []string{"filters"},
+			DefaultStatus: http.StatusCreated,
+		}, filterHandler.CreateSavedFilter)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-filter",
+			Method:      http.MethodDelete,
+			Path:        "/filters/{id}",
+			Summary:     "フィルター削除",
+			Description: "指定したIDの保存済みフィルターを削除します。",
+			Tags:        []string{"filters"},
+		}, filterHandler.DeleteSavedFilter)
+
+		sse.Register(api, huma.Operation{
+			OperationID: "stream-filter",
+			Method:      http.MethodGet,
+			Path:        "/filters/{id}/events",
+			Summary:     "フィルター結果の変更購読",
+			Description: "保存済みフィルターの結果に入った/外れたTodoをServer-Sent Eventsで配信します。",
+			Tags:        []string{"filters"},
+		}, handler.FilterStreamEvents, filterHandler.StreamFilter)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-filter-feed",
+			Method:      http.MethodGet,
+			Path:        "/filters/{id}/feed",
+			Summary:     "フィルターのAtomフィード取得",
+			Description: "保存済みフィルターの結果をAtomフィードとして取得します。",
+			Tags:        []string{"filters"},
+			Responses: map[string]*huma.Response{
+				"200": {
+					Description: "Atomフィード",
+					Content: map[string]*huma.MediaType{
+						"application/atom+xml": {},
+					},
+				},
+			},
+		}, filterHandler.FilterFeed)
+
+		if err := handler.DocumentTodoDefaults(context.Background(), queries, api.OpenAPI()); err != nil {
+			slog.Error("Todoの既定値をドキュメントに反映できません", "err", err)
+			os.Exit(1)
+		}
+
+		// ドキュメントでの操作のまとめ方はコードを変えずに設定ファイルで調整できるようにする
+		if o.OpenAPITags != "" {
+			tagConfig, err := apidoc.LoadTagConfig(o.OpenAPITags)
+			if err == nil {
+				err = tagConfig.Apply(api.OpenAPI())
+			}
+			if err != nil {
+				slog.Error("openapi-tagsの設定が不正です", "err", err)
+				os.Exit(1)
+			}
+		}
+		if specMode {
+			specDocument = api.OpenAPI()
+			return
+		}
+		if o.Demo {
+			seedDemo(queries, mux, api.OpenAPI())
+		}
+		startBootstrap(userHandler, o.InviteOnly)
+
+		var httpHandler http.Handler = middleware.ConditionalGET(mux)
+		httpHandler = middleware.Recover(newPanicAlert(notifier, o.PanicAlertChannel, o.PanicAlertInterval), httpHandler)
+		if origins := splitList(o.CORSAllowedOrigins); len(origins) > 0 {
+			if o.CORSAllowCredentials && slices.Contains(origins, "*") {
+				slog.Error("cors-allow-credentialsを指定する場合はcors-allowed-originsに*を含めず、オリジンを個別に指定してください")
+				os.Exit(1)
+			}
+			// プリフライトはHumaの操作に届かないため、ルーティングより外側で処理する
+			httpHandler = middleware.CORS(middleware.CORSConfig{
+				AllowedOrigins:   origins,
+				AllowedMethods:   splitList(o.CORSAllowedMethods),
+				AllowedHeaders:   splitList(o.CORSAllowedHeaders),
+				ExposedHeaders:   splitList(o.CORSExposedHeaders),
+				AllowCredentials: o.CORSAllowCredentials,
+				MaxAge:           o.CORSMaxAge,
+			}, httpHandler)
+		}
+
+		sampling, err := middleware.ParseSampling(o.AccessLogSampling)
+		if err != nil {
+			slog.Error("access-log-samplingの指定が不正です", "err", err)
+			os.Exit(1)
+		}
+		if err := checkTLS(o); err != nil {
+			slog.Error("TLSの設定が不正です", "err", err)
+			os.Exit(1)
+		}
+		if tlsEnabled(o) && o.HSTSMaxAge > 0 {
+			httpHandler = middleware.HSTS(middleware.HSTSConfig{MaxAge: o.HSTSMaxAge, IncludeSubdomains: o.HSTSIncludeSubdomains}, httpHandler)
+		}
+		if o.MirrorURL != "" {
+			// 複製先の応答を待たないよう、複製は元のリクエストの処理と並行して送る
+			httpHandler = middleware.Mirror(middleware.MirrorConfig{
+				URL:         o.MirrorURL,
+				Percent:     o.MirrorPercent,
+				Timeout:     o.MirrorTimeout,
+				MaxBody:     o.MirrorMaxBody,
+				Concurrency: o.MirrorConcurrency,
+			}, httpHandler)
+			slog.Info("リクエストの複製を有効化", "url", o.MirrorURL, "percent", o.MirrorPercent)
+		}
+		httpHandler = middleware.AccessLog(middleware.AccessLogConfig{Sampling: sampling}, httpHandler)
+
+		// 再起動で受け付けを止めた後に、受け付け済みの接続のリクエストを落とさずに停止するため、接続の状態を追跡する
+		var newConns handoff.NewConns
+		srv := &http.Server{
+			Addr:              fmt.Sprintf("%s:%d", o.Host, o.Port),
+			Handler:           httpHandler,
+			ReadHeaderTimeout: 5 * time.Second,    // ヘッダ読み取り制限
+			ReadTimeout:       15 * time.Second,   // 全体の読み取り制限
+			WriteTimeout:      serverWriteTimeout, // レスポンス書き込み制限
+			IdleTimeout:       60 * time.Second,   // keep-alive制御
+			ConnState:         newConns.ConnState,
+		}
+
+		// HTTPSで待ち受ける場合は、平文のHTTPのリスナーでHTTPSへのリダイレクトとACMEのチャレンジに応答する
+		var redirectSrv *http.Server
+		if tlsEnabled(o) {
+			tlsConfig, redirectHandler, err := newTLSConfig(o)
+			if err != nil {
+				slog.Error("TLSの設定に失敗", "err", err)
+				os.Exit(1)
+			}
+			srv.TLSConfig = tlsConfig
+			if o.HTTPRedirectPort != 0 {
+				redirectSrv = &http.Server{
+					Addr:              fmt.Sprintf("%s:%d", o.Host, o.HTTPRedirectPort),
+					Handler:           middleware.AccessLog(middleware.AccessLogConfig{Sampling: sampling}, redirectHandler),
+					ReadHeaderTimeout: 5 * time.Second,
+					ReadTimeout:       15 * time.Second,
+					WriteTimeout:      serverWriteTimeout,
+					IdleTimeout:       60 * time.Second,
+				}
+			}
+		}
+
+		// SSEの配信は終わらないリクエストのため、シャットダウンの開始時に購読を閉じて終了させる
+		srv.RegisterOnShutdown(bus.Close)
+		srv.RegisterOnShutdown(presenceTracker.Close)
+
+		// デバッグ用のエンドポイントは認証しないため、ループバックアドレスの別のリスナーでのみ公開する
+		var debugSrv *http.Server
+		if o.DebugAddr != "" {
+			if err := checkDebugAddr(o.DebugAddr); err != nil {
+				slog.Error("debug-addrが不正です", "err", err)
+				os.Exit(1)
+			}
+			debugSrv = &http.Server{
+				Addr:              o.DebugAddr,
+				Handler:           newDebugHandler(o),
+				ReadHeaderTimeout: 5 * time.Second,
+				// CPUプロファイルやトレースは指定した秒数の間応答しないため、書き込みの制限は設けない
+			}
+		}
+
+		// バックグラウンドジョブはシャットダウン時にキャンセルし、データベースを閉じる前に終了を待つ
+		jobCtx, cancelJobs := context.WithCancel(context.Background())
+		var jobs sync.WaitGroup
+		var jobLock *handoff.Lock
+
+		h.OnStart(func() {
+			// 再起動で新しいプロセスに引き継げるよう、リスナーは名前を付けて作成する。
+			// 引き継いで起動された場合は古いプロセスのリスナーをそのまま使う
+			listeners := make(map[string]*handoff.Listener)
+			listen := func(name, addr string) *handoff.Listener {
+				ln, err := handoff.Listen(name, addr)
+				if err != nil {
+					slog.Error("リスナーの作成に失敗", "listener", name, "addr", addr, "err", err)
+					os.Exit(1)
+				}
+				listeners[name] = ln
+				return ln
+			}
+			ln := listen("http", srv.Addr)
+			var redirectLn, debugLn *handoff.Listener
+			if redirectSrv != nil {
+				redirectLn = listen("redirect", redirectSrv.Addr)
+			}
+			if debugSrv != nil {
+				debugLn = listen("debug", debugSrv.Addr)
+			}
+
+			// 閲覧者はメモリ上で追跡するため、レプリカでも実行する
+			jobs.Go(func() { presenceTracker.Run(jobCtx) })
+			// 応答のキャッシュはプロセスごとに持つため、定期ジョブを実行しないプロセスでも変更を検知して破棄する
+			jobs.Go(func() {
+				scheduler.NewChangeWatcher(queries, bus, o.OutboxInterval, responseCache.Invalidate).Run(jobCtx)
+			})
+
+			// 定期ジョブはデータベースを変更するため、プライマリでのみ実行する
+			if !o.ReadOnly {
+				jobs.Go(func() {
+					if jobLockPath != "" {
+						if handoff.Inherited() {
+							slog.Info("古いプロセスが定期ジョブを停止するのを待ちます")
+						}
+						lock, err := handoff.AcquireLock(jobCtx, jobLockPath)
+						if err != nil {
+							if jobCtx.Err() == nil {
+								slog.Error("定期ジョブのロックの取得に失敗したため、定期ジョブを実行しません", "err", err)
+							}
+							return
+						}
+						jobLock = lock
+					}
+
+					jobs.Go(func() { scheduler.NewRecurrenceScheduler(queries, sqlDB, bus, o.RecurrenceInterval).Run(jobCtx) })
+					jobs.Go(func() {
+						scheduler.NewReminderScheduler(queries, notifier, reminderLinks, o.ReminderInterval, clk, responseCache.Invalidate).Run(jobCtx)
+					})
+					if len(escalationThresholds) > 0 {
+						jobs.Go(func() {
+							scheduler.NewEscalationScheduler(queries, sqlDB, bus, notifier, o.EscalationChannel, defaultFormatter, escalationThresholds, o.EscalationInterval, clk).Run(jobCtx)
+						})
+					}
+					if o.StaleAfter > 0 {
+						jobs.Go(func() {
+							scheduler.NewStaleDigestScheduler(queries, notifier, actionLinks, o.StaleDigestChannel, defaultFormatter, o.StaleAfter, o.StaleDigestInterval, clk).Run(jobCtx)
+						})
+					}
+					if o.DueDigestTime != "" {
+						jobs.Go(func() {
+							scheduler.NewDueDigestScheduler(queries, notifier, reminderLinks, o.DueDigestChannel, defaultFormatter, dueDigestAt, clk).Run(jobCtx)
+						})
+					}
+					jobs.Go(func() { meter.Run(jobCtx) })
+					jobs.Go(func() { scheduler.NewChatForwarder(queries, bus, chats, clk).Run(jobCtx) })
+					if diskMonitor != nil {
+						jobs.Go(func() { diskMonitor.Run(jobCtx) })
+					}
+					jobs.Go(func() {
+						scheduler.NewWatchNotifier(queries, bus, notifier, o.WatchChannel, defaultFormatter).Run(jobCtx)
+					})
+					webhooks := scheduler.NewWebhookDispatcher(queries, handler.WebhookTodo, scheduler.WebhookConfig{
+						Interval:     o.WebhookInterval,
+						MaxAttempts:  o.WebhookMaxAttempts,
+						Backoff:      o.WebhookBackoff,
+						Timeout:      o.WebhookTimeout,
+						Retention:    o.WebhookRetention,
+						AllowPrivate: o.WebhookAllowPrivate,
+					}, clk)
+					jobs.Go(func() { webhooks.Run(jobCtx) })
+					jobs.Go(func() {
+						scheduler.NewOutboxRelay(sqlDB, queries, bus, sinks, webhooks, scheduler.OutboxConfig{
+							Interval:    o.OutboxInterval,
+							SinkTimeout: o.EventSinkTimeout,
+							Retention:   o.OutboxRetention,
+						}, clk).Run(jobCtx)
+					})
+					if o.BackupInterval > 0 {
+						jobs.Go(func() { scheduler.NewBackupScheduler(backupManager, o.BackupInterval).Run(jobCtx) })
+					}
+					if replicator != nil {
+						jobs.Go(func() { replicator.Run(jobCtx) })
+					}
+					jobs.Go(func() { maintenance.Run(jobCtx) })
+				})
+			}
+
+			if debugSrv != nil {
+				go func() {
+					slog.Info("デバッグ用のリスナーを開始", "addr", debugLn.Addr().String())
+					if err := debugSrv.Serve(debugLn); err != nil && err != http.ErrServerClosed {
+						slog.Error("デバッグ用のリスナーの起動に失敗", "err", err)
+					}
+				}()
+			}
+
+			if redirectSrv != nil {
+				go func() {
+					slog.Info("HTTPSへのリダイレクトを開始", "addr", redirectLn.Addr().String())
+					if err := redirectSrv.Serve(redirectLn); err != nil && err != http.ErrServerClosed {
+						slog.Error("HTTPSへのリダイレクトの起動に失敗", "err", err)
+					}
+				}()
+			}
+
+			// SIGHUPを受けたら、置き換えた実行ファイルでリスナーを引き継ぐ新しいプロセスを起動し、
+			// 新しいプロセスが受け付けを始めてからSIGTERMと同じ手順でこのプロセスを停止する
+			go func() {
+				hup := make(chan os.Signal, 1)
+				signal.Notify(hup, syscall.SIGHUP)
+				for range hup {
+					if jobLockPath == "" {
+						slog.Warn("インメモリのデータベースは新しいプロセスに引き継げないため、再起動しません")
+						continue
+					}
+					slog.Info("新しいプロセスを起動してリスナーを引き継ぎます", "executable", executable)
+					pid, err := handoff.Restart(executable, listeners, o.RestartTimeout)
+					if err != nil {
+						slog.Error("再起動に失敗したため、このプロセスで受け付けを続けます", "err", err)
+						continue
+					}
+					slog.Info("新しいプロセスが受け付けを始めたため、このプロセスを停止します", "pid", pid)
+					signal.Stop(hup)
+					// 新しい接続はすべて新しいプロセスに受け付けさせ、受け付け済みの接続がリクエストを送るのを待ってから停止する
+					drainCtx, cancelDrain := context.WithTimeout(context.Background(), handoffDrainTimeout)
+					for _, l := range listeners {
+						l.Pause(drainCtx)
+					}
+					if !newConns.Wait(drainCtx) {
+						slog.Warn("リクエストを送らない接続があるため、待たずに停止します", "timeout", handoffDrainTimeout)
+					}
+					cancelDrain()
+					if p, err := os.FindProcess(os.Getpid()); err == nil {
+						_ = p.Signal(syscall.SIGTERM)
+					}
+					return
+				}
+			}()
+
+			slog.Info("サーバー起動開始...")
+			scheme := "http"
+			serve := func() error { return srv.Serve(ln) }
+			if srv.TLSConfig != nil {
+				// 証明書はTLSConfigのGetCertificateが返す
+				scheme = "https"
+				serve = func() error { return srv.ServeTLS(ln, "", "") }
+			}
+			// 引き継いで起動された場合は、受け付けを始めたことを古いプロセスに伝えて停止させる
+			if handoff.Inherited() {
+				if err := handoff.Ready(); err != nil {
+					slog.Error("古いプロセスへの通知に失敗", "err", err)
+				}
+				slog.Info("古いプロセスからリスナーを引き継ぎました", "addr", ln.Addr().String())
+			}
+			addr := fmt.Sprintf("%s:%d", o.Host, o.Port)
+			fmt.Printf("🚀 Todo API Server starting on %s://%s\n", scheme, addr)
+			fmt.Printf("📚 API Documentation: %s://%s/docs\n", scheme, addr)
+			if o.WebUI {
+				fmt.Printf("📝 Web UI: %s://%s/\n", scheme, addr)
+			}
+			fmt.Printf("📚 Get OpenAPI File: %s://%s/openapi.yaml\n", scheme, addr)
+			if err := serve(); err != nil && err != http.ErrServerClosed {
+				slog.Error("サーバー起動に失敗", "err", err)
+				os.Exit(1)
+			}
+		})
+
+		h.OnStop(func() {
+			slog.Info("Shutting down server...")
+			slog.Info("サーバーのシャットダウン開始...")
+
+			// 新しいジョブの実行を止め、実行中のジョブにはキャンセルを伝える
+			cancelJobs()
+
+			// 実行中のリクエストとジョブはshutdown-timeoutまで待つ
+			ctx, cancel := context.WithTimeout(context.Background(), o.ShutdownTimeout)
+			defer cancel()
+
+			// 新しい接続の受け付けを止め、実行中のリクエストが終わるまで待つ
+			if err := srv.Shutdown(ctx); err != nil {
+				slog.Warn("時間内に終わらなかったリクエストを打ち切ります", "shutdown_timeout", o.ShutdownTimeout, "err", err)
+				srv.Close()
+			}
+			if redirectSrv != nil {
+				if err := redirectSrv.Shutdown(ctx); err != nil {
+					redirectSrv.Close()
+				}
+			}
+			// 取得中のプロファイルは待たずに打ち切る
+			if debugSrv != nil {
+				debugSrv.Close()
+			}
+
+			// リクエストから開始したインポートと定期ジョブの終了を待つ
+			if !waitContext(ctx, importHandler.Wait) {
+				slog.Warn("時間内に終わらなかったインポートジョブがあります", "shutdown_timeout", o.ShutdownTimeout)
+			}
+			if !waitContext(ctx, jobs.Wait) {
+				slog.Warn("時間内に終わらなかったバックグラウンドジョブがあります", "shutdown_timeout", o.ShutdownTimeout)
+			} else if jobLock != nil {
+				// 再起動した新しいプロセスが定期ジョブを始められるよう、データベースを閉じるのを待たずに解放する
+				if err := jobLock.Release(); err != nil {
+					slog.Warn("定期ジョブのロックの解放に失敗", "err", err)
+				}
+			}
+
+			// 待機で時間を使い切った場合も書き込みと送信はできるよう、別の期限を設ける
+			flushCtx, cancelFlush := context.WithTimeout(context.Background(), shutdownFlushTimeout)
+			defer cancelFlush()
+
+			// 停止までに受け付けたリクエストの呼び出し回数を書き込む
+			if meter != nil {
+				meter.Flush(flushCtx)
+			}
+
+			// 送信していないスパンを送信する
+			if err := shutdownTracing(flushCtx); err != nil {
+				slog.Warn("トレースの送信に失敗", "err", err)
+			}
+
+			if readDB != nil {
+				if err := readDB.Close(); err != nil {
+					slog.Error("読み取り用のデータベースの終了に失敗", "err", err)
+				}
+			}
+			// 再起動した新しいプロセスが大きなWALを引き継がないよう、書き込んだ内容をデータベースのファイルに反映する
+			if maintenance != nil && jobLockPath != "" {
+				if result, err := maintenance.Checkpoint(flushCtx); err != nil {
+					slog.Warn("WALのチェックポイントに失敗", "err", err)
+				} else {
+					slog.Info("WALのチェックポイントを実行", "busy", result.Busy, "wal_frames", result.WALFrames, "checkpointed_frames", result.CheckpointedFrames)
+				}
+			}
+			if err := sqlDB.Close(); err != nil {
+				slog.Error("データベースの終了に失敗", "err", err)
 			}
 
 			slog.Info("サーバーは正常にシャットダウンされました")
 		})
 	})
 
+	cli.Root().AddCommand(checkCmd)
+	cli.Root().AddCommand(importCmd)
+	cli.Root().AddCommand(bootstrapCmd)
+	cli.Root().AddCommand(migrateCmd)
+	cli.Root().AddCommand(backupCmd)
+	cli.Root().AddCommand(configCmd)
+	cli.Root().AddCommand(replicationCmd)
+	cli.Root().AddCommand(todoCmd)
+	cli.Root().AddCommand(specCmd)
 	cli.Run()
 }
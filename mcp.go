@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/handler"
+	"go-huma-test/mcp"
+	"go-huma-test/model"
+	"go-huma-test/storage"
+	"os"
+	"strings"
+)
+
+// mcpActor はMCPのツールによる変更をアクティビティログに記録する実行者
+const mcpActor = "mcp"
+
+// runMCP はデータベースを開き、Todoを操作するツールを公開するMCPのサーバーを標準入出力で動かす。標準入力が閉じると戻る。
+// ツールはHTTPの操作と同じハンドラーを呼び出し、mcp-userを指定した場合はそのユーザーとして、指定しない場合は所有者のいないTodoを操作する。
+func runMCP(ctx context.Context, o *model.Options) error {
+	sqlDB, err := initDB(o.DatabaseURL, false, o.AutoMigrate)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+
+	queries, err := db.Prepare(ctx, sqlDB)
+	if err != nil {
+		return fmt.Errorf("データベースのPrepareに失敗: %w", err)
+	}
+	store, err := storage.NewLocalStore(o.AttachmentDir)
+	if err != nil {
+		return err
+	}
+	clk, err := clock.Parse(o.FreezeTime)
+	if err != nil {
+		return fmt.Errorf("freeze-timeの指定が不正です: %w", err)
+	}
+
+	actor := mcpActor
+	if o.MCPUser != "" {
+		u, err := queries.GetUserByEmail(ctx, o.MCPUser)
+		if err != nil {
+			return fmt.Errorf("mcp-userのユーザーが見つかりません: %s: %w", o.MCPUser, err)
+		}
+		ctx = auth.WithUserID(ctx, u.ID)
+		actor = fmt.Sprintf("%s:%d", mcpActor, u.ID)
+	}
+	ctx = activity.WithActor(ctx, actor)
+
+	// MCPではサーバーが動いていないため、イベントの購読者はいない。配信はoutboxに記録したイベントからサーバーが行う
+	todos := &mcpTodoTools{
+		todos: handler.NewTodoHandler(queries, sqlDB, event.NewBus(), store, clk),
+	}
+	server := mcp.NewServer("todo-api", apiVersion,
+		mcp.NewTool("list_todos", "List the todos you can see, newest first. Defaults to open (not completed) todos.", todos.list),
+		mcp.NewTool("search_todos", "Find todos whose title or description contains the query, ignoring case.", todos.search),
+		mcp.NewTool("create_todo", "Create a todo. Omitted fields take the project's or the global defaults.", todos.create),
+		mcp.NewTool("complete_todo", "Mark a todo as completed. A todo that is already completed is returned unchanged.", todos.complete),
+	)
+	return server.Serve(ctx, os.Stdin, os.Stdout)
+}
+
+// mcpTodoTools はMCPのツールからTodoのハンドラーを呼び出す
+type mcpTodoTools struct {
+	todos *handler.TodoHandler
+}
+
+// find は完了状態で絞り込んだTodoのうち、matchに一致するものをlimit件まで返す
+func (t *mcpTodoTools) find(ctx context.Context, input model.MCPListTodosInput, match func(model.TodoResponse) bool) (model.MCPTodosOutput, error) {
+	out, err := t.todos.ListTodos(ctx, &model.ListTodosInput{Completed: input.Status == "completed"})
+	if err != nil {
+		return model.MCPTodosOutput{}, err
+	}
+	result := model.MCPTodosOutput{Todos: []model.TodoResponse{}}
+	for _, todo := range out.Body.Todos {
+		if (input.Status == "open" && todo.Completed) || !match(todo) {
+			continue
+		}
+		result.Total++
+		if int64(len(result.Todos)) < input.Limit {
+			result.Todos = append(result.Todos, todo)
+		}
+	}
+	return result, nil
+}
+
+// list はTodoの一覧を返す
+func (t *mcpTodoTools) list(ctx context.Context, input *model.MCPListTodosInput) (model.MCPTodosOutput, error) {
+	return t.find(ctx, *input, func(model.TodoResponse) bool { return true })
+}
+
+// search はタイトルか説明にqueryを含むTodoを返す
+func (t *mcpTodoTools) search(ctx context.Context, input *model.MCPSearchTodosInput) (model.MCPTodosOutput, error) {
+	query := strings.ToLower(input.Query)
+	return t.find(ctx, input.MCPListTodosInput, func(todo model.TodoResponse) bool {
+		if strings.Contains(strings.ToLower(todo.Title), query) {
+			return true
+		}
+		return todo.Description != nil && strings.Contains(strings.ToLower(*todo.Description), query)
+	})
+}
+
+// create はTodoを作成する
+func (t *mcpTodoTools) create(ctx context.Context, input *model.MCPCreateTodoInput) (model.TodoResponse, error) {
+	in := &model.CreateTodoInput{}
+	in.Body.Title = input.Title
+	in.Body.Description = input.Description
+	in.Body.ProjectID = input.ProjectID
+	in.Body.DueAt = input.DueAt
+	in.Body.Priority = input.Priority
+	out, err := t.todos.CreateTodo(ctx, in)
+	if err != nil {
+		return model.TodoResponse{}, err
+	}
+	return out.Body, nil
+}
+
+// complete は未完了のTodoを完了にする。HTTPの操作と同じく、他のユーザーがロックしているTodoは423を返す
+func (t *mcpTodoTools) complete(ctx context.Context, input *model.MCPCompleteTodoInput) (model.TodoResponse, error) {
+	got, err := t.todos.GetTodo(ctx, &model.GetTodoInput{ID: input.ID})
+	if err != nil {
+		return model.TodoResponse{}, err
+	}
+	if got.Body.Completed {
+		return got.Body, nil
+	}
+
+	out, err := t.todos.ToggleTodo(ctx, &model.ToggleTodoInput{ID: input.ID})
+	if err != nil {
+		return model.TodoResponse{}, err
+	}
+	return out.Body, nil
+}
@@ -0,0 +1,195 @@
+// Package mcp はModel Context Protocol（MCP）のサーバーを提供する。
+// 改行で区切ったJSON-RPC 2.0のメッセージを標準入出力でやり取りし、登録したツールをLLMのエージェントから呼び出せるようにする。
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+)
+
+// supportedVersions は対応するMCPのプロトコルのバージョン。先頭が最新
+var supportedVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// maxMessageSize は1行のメッセージの上限。ツールの引数はTodo数件分のため、これを超えるメッセージは受け付けない
+const maxMessageSize = 4 << 20
+
+// JSON-RPC 2.0のエラーコード
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// request はJSON-RPCのリクエストと通知を表す構造体。通知はIDを持たない
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcError はJSON-RPCのエラーオブジェクトを表す構造体
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// response はJSON-RPCのレスポンスを表す構造体
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// Server はツールを公開するMCPのサーバー
+type Server struct {
+	name    string
+	version string
+	tools   []Tool
+}
+
+// NewServer はnameとversionをサーバーの情報として返し、toolsを公開するServerを生成する
+func NewServer(name, version string, tools ...Tool) *Server {
+	return &Server{
+		name:    name,
+		version: version,
+		tools:   tools,
+	}
+}
+
+// Serve はrから1行ずつメッセージを読み、応答をwに書き出す。rが終わるかctxが終了するまで戻らない。
+// メッセージは受け取った順に1つずつ処理する。
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	reader := bufio.NewReaderSize(r, 64<<10)
+	enc := json.NewEncoder(w)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line, err := readLine(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(line) == 0 {
+			continue
+		}
+
+		resp := s.handle(ctx, line)
+		if resp == nil {
+			continue
+		}
+		if err := enc.Encode(resp); err != nil {
+			return fmt.Errorf("MCPの応答の書き込みに失敗: %w", err)
+		}
+	}
+}
+
+// readLine は改行までの1行を読む。maxMessageSizeを超える行はエラーにする
+func readLine(r *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxMessageSize {
+			return nil, fmt.Errorf("MCPのメッセージが%dバイトを超えています", maxMessageSize)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+			return nil, err
+		}
+		return bytes.TrimSpace(line), nil
+	}
+}
+
+// handle は1つのメッセージを処理し、応答を返す。通知には応答しないためnilを返す
+func (s *Server) handle(ctx context.Context, line []byte) *response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		slog.Warn("MCPのメッセージを解析できません", "err", err)
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "JSON-RPCのメッセージとして解析できません"}}
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if req.ID == nil {
+			return nil
+		}
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: codeInvalidRequest, Message: "JSON-RPC 2.0のリクエストではありません"}}
+	}
+	// IDのないメッセージは通知のため、処理しても応答しない
+	if req.ID == nil {
+		slog.Debug("MCPの通知を受信", "method", req.Method)
+		return nil
+	}
+
+	result, rerr := s.dispatch(ctx, req)
+	if rerr != nil {
+		slog.Warn("MCPのリクエストの処理に失敗", "method", req.Method, "code", rerr.Code, "err", rerr.Message)
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: rerr}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// dispatch はメソッドごとにリクエストを処理する
+func (s *Server) dispatch(ctx context.Context, req request) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+			}
+		}
+		// クライアントが求めたバージョンに対応していなければ最新のバージョンを返し、クライアントに判断を任せる
+		version := supportedVersions[0]
+		if slices.Contains(supportedVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{"listChanged": false}},
+			"serverInfo":      map[string]any{"name": s.name, "version": s.version},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		tools := make([]map[string]any, len(s.tools))
+		for i, t := range s.tools {
+			tools[i] = map[string]any{
+				"name":        t.Name,
+				"description": t.Description,
+				"inputSchema": t.InputSchema,
+			}
+		}
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		i := slices.IndexFunc(s.tools, func(t Tool) bool { return t.Name == params.Name })
+		if i < 0 {
+			return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("ツールが見つかりません: %s", params.Name)}
+		}
+		return s.tools[i].call(ctx, params.Arguments), nil
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("メソッドに対応していません: %s", req.Method)}
+	}
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Tool はMCPのクライアントから呼び出せるツール
+type Tool struct {
+	Name        string
+	Description string
+	// InputSchema は引数のJSON Schema。Humaの操作と同じタグから生成し、呼び出しの引数の検証にも使う
+	InputSchema *huma.Schema
+
+	run func(ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// NewTool は引数をIの構造体で受け取り、fnの結果を返すツールを生成する。
+// 引数のスキーマはIのフィールドのタグからHumaと同じ規則で生成し、呼び出し時にdefaultタグの既定値を補って検証してからfnに渡す。
+func NewTool[I, O any](name, description string, fn func(ctx context.Context, input *I) (O, error)) Tool {
+	registry := huma.NewMapRegistry("#/$defs/", huma.DefaultSchemaNamer)
+	schema := huma.SchemaFromType(registry, reflect.TypeFor[I]())
+
+	return Tool{
+		Name:        name,
+		Description: description,
+		InputSchema: schema,
+		run: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var value any = map[string]any{}
+			if len(args) > 0 && string(args) != "null" {
+				if err := json.Unmarshal(args, &value); err != nil {
+					return nil, fmt.Errorf("引数をJSONとして解析できません: %w", err)
+				}
+			}
+			if m, ok := value.(map[string]any); ok {
+				for name, prop := range schema.Properties {
+					if _, ok := m[name]; !ok && prop.Default != nil {
+						m[name] = prop.Default
+					}
+				}
+			}
+
+			res := &huma.ValidateResult{}
+			huma.Validate(registry, schema, huma.NewPathBuffer([]byte{}, 0), huma.ModeWriteToServer, value, res)
+			if len(res.Errors) > 0 {
+				return nil, huma.Error422UnprocessableEntity("引数が不正です", res.Errors...)
+			}
+
+			b, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			input := new(I)
+			if err := json.Unmarshal(b, input); err != nil {
+				return nil, fmt.Errorf("引数が不正です: %w", err)
+			}
+			return fn(ctx, input)
+		},
+	}
+}
+
+// call はツールを呼び出し、tools/callの結果を返す。
+// ツールの失敗はエージェントが内容を読んで対処できるよう、プロトコルのエラーではなくisErrorを付けた結果にする。
+func (t Tool) call(ctx context.Context, args json.RawMessage) map[string]any {
+	out, err := t.run(ctx, args)
+	if err != nil {
+		slog.Warn("MCPのツールの呼び出しに失敗", "tool", t.Name, "err", err)
+		return map[string]any{
+			"content": []any{map[string]any{"type": "text", "text": errorText(err)}},
+			"isError": true,
+		}
+	}
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		slog.Warn("MCPのツールの結果をJSONにできません", "tool", t.Name, "err", err)
+		return map[string]any{
+			"content": []any{map[string]any{"type": "text", "text": err.Error()}},
+			"isError": true,
+		}
+	}
+	return map[string]any{
+		"content":           []any{map[string]any{"type": "text", "text": string(b)}},
+		"structuredContent": json.RawMessage(b),
+	}
+}
+
+// errorText はエラーをエージェントに返す文章にする。HTTPのエラーはステータスと原因の箇所を含むProblem DetailsのJSONにする
+func errorText(err error) string {
+	var se huma.StatusError
+	if errors.As(err, &se) {
+		if b, err := json.Marshal(se); err == nil {
+			return string(b)
+		}
+	}
+	return err.Error()
+}
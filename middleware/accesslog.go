@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	mathrand "math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequestIDHeader はリクエストIDを受け取り、応答で返すヘッダー
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength はクライアントから受け取るリクエストIDの最大長
+const maxRequestIDLength = 128
+
+// requestIDKey はcontextにリクエストIDを格納するキー
+type requestIDKey struct{}
+
+// RequestIDFrom はcontextに格納されたリクエストIDを返す
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// AccessLogConfig はアクセスログの設定を表す構造体
+type AccessLogConfig struct {
+	// Sampling はパスの接頭辞ごとに記録する成功応答の割合。最も長く一致した接頭辞の割合を使う。
+	// 4xxと5xxの応答は割合にかかわらず記録する。
+	Sampling map[string]float64
+}
+
+// ParseSampling は"/todos=0.1,/replication/status=0"のようにカンマ区切りで指定された、
+// パスの接頭辞ごとのサンプリングの割合を解析する。割合は0から1の範囲で指定する。
+func ParseSampling(s string) (map[string]float64, error) {
+	sampling := make(map[string]float64)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		path, rate, ok := strings.Cut(part, "=")
+		path = strings.TrimSpace(path)
+		if !ok || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("パス=割合の形式で指定してください: %s", part)
+		}
+		r, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if err != nil || r < 0 || r > 1 {
+			return nil, fmt.Errorf("割合は0から1の範囲で指定してください: %s", part)
+		}
+		sampling[path] = r
+	}
+	return sampling, nil
+}
+
+// sampleRate はパスに適用するサンプリングの割合を返す
+func (c *AccessLogConfig) sampleRate(path string) float64 {
+	rate, matched := 1.0, -1
+	for prefix, r := range c.Sampling {
+		if strings.HasPrefix(path, prefix) && len(prefix) > matched {
+			rate, matched = r, len(prefix)
+		}
+	}
+	return rate
+}
+
+// accessLogWriter は応答のステータスコードとサイズを記録するResponseWriter
+type accessLogWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *accessLogWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessLogWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush は下位のWriterへフラッシュする
+func (w *accessLogWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap は下位のResponseWriterを返す
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// requestID はクライアントが送ったリクエストIDを返す。ない場合や不正な場合は新しく生成する。
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(RequestIDHeader); id != "" && len(id) <= maxRequestIDLength && !strings.ContainsFunc(id, func(c rune) bool {
+		return c < 0x21 || c > 0x7e
+	}) {
+		return id
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// remoteIP はリクエスト元のIPアドレスを返す
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// AccessLog はリクエストごとにslogで構造化したアクセスログを記録するミドルウェア。
+// リクエストIDをcontextに格納してRequestIDHeaderで返し、ハンドラーのログと突き合わせられるようにする。
+// 5xxはError、4xxはWarn、それ以外はInfoで記録する。
+// CORSのプリフライトや存在しないパスへのリクエストも記録するため、最も外側に登録する。
+func AccessLog(cfg AccessLogConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := requestID(r)
+		w.Header().Set(RequestIDHeader, id)
+
+		lw := &accessLogWriter{ResponseWriter: w}
+		next.ServeHTTP(lw, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+
+		status := lw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		default:
+			if rate := cfg.sampleRate(r.URL.Path); rate < 1 && mathrand.Float64() >= rate {
+				return
+			}
+		}
+
+		slog.Log(r.Context(), level, "リクエストを処理",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+			"size", lw.size,
+			"ip", remoteIP(r),
+			"user_agent", r.UserAgent(),
+			"request_id", id,
+		)
+	})
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"go-huma-test/activity"
+	"go-huma-test/audit"
+	"go-huma-test/auth"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Actor はリクエストの認証主体を変更の実行者としてcontextに格納するミドルウェア。
+// ハンドラーが記録するアクティビティログの実行者として使われる。
+// JWTのsubクレームがある場合はそれを、ない場合は資格情報のハッシュを実行者とする。
+func Actor(ctx huma.Context, next func(huma.Context)) {
+	actor := audit.Subject(credential(ctx))
+	if claims, ok := auth.ClaimsFrom(ctx.Context()); ok && claims.Subject != "" {
+		actor = claims.Subject
+	}
+	next(huma.WithContext(ctx, activity.WithActor(ctx.Context(), actor)))
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"errors"
+	"go-huma-test/auth"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// BearerSecurityScheme はOpenAPIに登録するBearer認証のセキュリティスキーム名
+const BearerSecurityScheme = "bearer"
+
+// APIKeySecurityScheme はOpenAPIに登録するAPIキー認証のセキュリティスキーム名
+const APIKeySecurityScheme = "apiKey"
+
+// BearerSecuritySchemeDef は認証プロバイダーのうちBearerトークンを扱うものをまとめたOpenAPIのセキュリティスキームを返す。
+// Bearerトークンを扱うプロバイダーがない場合はnilを返す。
+func BearerSecuritySchemeDef(providers []string) *huma.SecurityScheme {
+	var tokens []string
+	for _, p := range providers {
+		switch p {
+		case auth.ProviderStatic:
+			tokens = append(tokens, "秘密情報static_tokensに設定した固定のトークン")
+		case auth.ProviderJWT:
+			tokens = append(tokens, "HS256またはRS256で署名されたJWT")
+		case auth.ProviderOIDC:
+			tokens = append(tokens, "/auth/oidc/loginでログインしたアカウントのOIDCプロバイダーのIDトークン")
+		}
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+	scheme := &huma.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: strings.Join(tokens, "、") + "をAuthorization: Bearer <token>で送信する",
+	}
+	if !slices.Contains(providers, auth.ProviderStatic) {
+		scheme.BearerFormat = "JWT"
+	}
+	return scheme
+}
+
+// APIKeySecuritySchemeDef はX-API-KeyヘッダーによるAPIキー認証を表すOpenAPIのセキュリティスキームを返す
+func APIKeySecuritySchemeDef() *huma.SecurityScheme {
+	return &huma.SecurityScheme{
+		Type:        "apiKey",
+		In:          "header",
+		Name:        auth.APIKeyHeader,
+		Description: "POST /api-keysで発行したAPIキーを送信する",
+	}
+}
+
+// OIDCSecurityScheme はOpenAPIに登録するOIDCログインのセキュリティスキーム名
+const OIDCSecurityScheme = "oidc"
+
+// OIDCSecuritySchemeDef は外部のOIDCプロバイダーによるログインを表すOpenAPIのセキュリティスキームを返す。
+// プロバイダーのトークンは直接受け付けず、/auth/oidc/callbackで発行したアクセストークンをBearer認証に用いる。
+func OIDCSecuritySchemeDef(issuer string) *huma.SecurityScheme {
+	return &huma.SecurityScheme{
+		Type:             "openIdConnect",
+		OpenIDConnectURL: auth.OpenIDConfigurationURL(issuer),
+		Description: "GET /auth/oidc/loginでOIDCプロバイダーのログイン画面へリダイレクトし、" +
+			"プロバイダーから戻ったGET /auth/oidc/callbackでアクセストークンを発行する。" +
+			"発行したトークンはAuthorization: Bearer <token>で送信する",
+	}
+}
+
+// credential はリクエストの資格情報を返す。AuthorizationヘッダーがなければAPIキーを返す。
+func credential(ctx huma.Context) string {
+	if v := ctx.Header("Authorization"); v != "" {
+		return v
+	}
+	return ctx.Header(auth.APIKeyHeader)
+}
+
+// IsPublicOperation はSecurityに空の要件が明示され、認証なしで呼び出せる操作かを返す
+func IsPublicOperation(op *huma.Operation) bool {
+	return op != nil && op.Security != nil && len(op.Security) == 0
+}
+
+// withIdentity はクレームと、認証主体がユーザーの場合はそのIDを格納したcontextを返す
+func withIdentity(ctx huma.Context, claims *auth.Claims) huma.Context {
+	c := auth.WithClaims(ctx.Context(), claims)
+	if id, ok := auth.ParseUserSubject(claims.Subject); ok {
+		c = auth.WithUserID(c, id)
+	}
+	return huma.WithContext(ctx, c)
+}
+
+// writeUnauthorized はWWW-Authenticateヘッダーを付けて401を返す
+func writeUnauthorized(api huma.API, ctx huma.Context, challenge, message string) {
+	ctx.SetHeader("WWW-Authenticate", challenge)
+	if err := huma.WriteErr(api, ctx, http.StatusUnauthorized, message); err != nil {
+		slog.Warn("エラーレスポンスの書き込みに失敗", "err", err)
+	}
+}
+
+// Auth はAuthenticatorでリクエストの資格情報を検証するミドルウェアを返す。
+// 複数の方式を受け付ける場合はauth.Stackに並べて渡す。
+// 検証に成功した場合はクレームをcontextに格納し、auth.ClaimsFromで取り出せるようにする。
+// 認証主体がユーザー（user:<id>）の場合はauth.UserIDFromでユーザーIDも取り出せる。
+// IsPublicOperationに該当する操作は検証せずに処理する。
+func Auth(api huma.API, authenticator auth.Authenticator) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if IsPublicOperation(ctx.Operation()) {
+			next(ctx)
+			return
+		}
+
+		claims, err := authenticator.Authenticate(ctx.Context(), ctx)
+		if err != nil {
+			var unauthorized *auth.Unauthorized
+			switch {
+			case errors.Is(err, auth.ErrNoCredentials):
+				slog.Warn("資格情報が設定されていません")
+				writeUnauthorized(api, ctx, authenticator.Challenge(), "Authentication required")
+			case errors.As(err, &unauthorized):
+				slog.Warn("資格情報が不正です", "err", err)
+				writeUnauthorized(api, ctx, unauthorized.Challenge, unauthorized.Message)
+			default:
+				slog.Error("資格情報の検証に失敗", "err", err)
+				writeErr(api, ctx, huma.Error503ServiceUnavailable("資格情報を検証できません"))
+			}
+			return
+		}
+
+		next(withIdentity(ctx, claims))
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"go-huma-test/audit"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// clientIP はリクエスト元のIPアドレスを返す
+func clientIP(ctx huma.Context) string {
+	host, _, err := net.SplitHostPort(ctx.RemoteAddr())
+	if err != nil {
+		return ctx.RemoteAddr()
+	}
+	return host
+}
+
+// AuthAudit は認証の結果を認証イベントとして記録するミドルウェアを返す。
+// 認証を行うミドルウェアより前に登録し、401応答を認証の失敗として扱う。
+// サインインなど認証なしで呼び出せる操作はハンドラーが結果を記録するため、
+// リクエスト元の端末をcontextに格納するだけにする。
+func AuthAudit(rec *audit.Recorder) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if IsPublicOperation(ctx.Operation()) {
+			next(huma.WithContext(ctx, audit.WithClient(ctx.Context(), audit.Client{
+				IP:        clientIP(ctx),
+				UserAgent: ctx.Header("User-Agent"),
+			})))
+			return
+		}
+
+		next(ctx)
+
+		eventType := audit.EventKeyUsed
+		if ctx.Status() == http.StatusUnauthorized {
+			eventType = audit.EventLoginFailed
+		}
+
+		if err := rec.Record(ctx.Context(), audit.Attempt{
+			Subject:   audit.Subject(credential(ctx)),
+			EventType: eventType,
+			IP:        clientIP(ctx),
+			UserAgent: ctx.Header("User-Agent"),
+		}); err != nil {
+			slog.Warn("認証イベントの記録に失敗", "err", err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/auth"
+	"go-huma-test/db"
+	"log/slog"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// RoleMetadataKey はOperation.Metadataに操作に必要なロールを設定するキー
+const RoleMetadataKey = "role"
+
+// roleClaim は外部の発行者のJWTでロールを指定するクレーム
+const roleClaim = "role"
+
+// RoleMetadata はOperation.Metadataに設定する、操作に必要なロールを返す
+func RoleMetadata(r auth.Role) map[string]any {
+	return map[string]any{RoleMetadataKey: r}
+}
+
+// requiredRole は操作に必要なロールを返す。
+// Metadataで指定がない場合、GETとHEADはviewer、それ以外の変更を伴う操作はeditorを必要とする。
+func requiredRole(op *huma.Operation) auth.Role {
+	if r, ok := op.Metadata[RoleMetadataKey].(auth.Role); ok {
+		return r
+	}
+	if op.Method == http.MethodGet || op.Method == http.MethodHead {
+		return auth.RoleViewer
+	}
+	return auth.RoleEditor
+}
+
+// resolveRole は認証主体のロールを返す。
+// ユーザーはusersテーブルのロールを、それ以外はJWTのroleクレームを用い、指定がなければdefaultRoleとする。
+func resolveRole(ctx huma.Context, queries *db.Queries, defaultRole auth.Role) (auth.Role, error) {
+	if id, ok := auth.UserIDFrom(ctx.Context()); ok {
+		u, err := queries.GetUser(ctx.Context(), id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// 削除されたユーザーのトークンには何も許可しない
+				return "", nil
+			}
+			return "", err
+		}
+		return auth.Role(u.Role), nil
+	}
+	if claims, ok := auth.ClaimsFrom(ctx.Context()); ok {
+		if r, ok := claims.Extra[roleClaim].(string); ok {
+			return auth.Role(r), nil
+		}
+	}
+	return defaultRole, nil
+}
+
+// Authorize は認証主体のロールが操作に必要なロールを満たすか検証するミドルウェアを返す。
+// 満たさない場合は必要なロールと現在のロールを含む403を返す。
+// 検証したロールはcontextに格納し、auth.RoleFromで取り出せるようにする。
+// 認証を行うミドルウェアより後に登録する。
+func Authorize(api huma.API, queries *db.Queries, defaultRole auth.Role) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if IsPublicOperation(ctx.Operation()) {
+			next(ctx)
+			return
+		}
+
+		role, err := resolveRole(ctx, queries, defaultRole)
+		if err != nil {
+			slog.Warn("ロールの取得に失敗", "err", err)
+			writeErr(api, ctx, huma.Error500InternalServerError("ロールの取得に失敗", err))
+			return
+		}
+
+		required := requiredRole(ctx.Operation())
+		if !role.Allows(required) {
+			slog.Warn("ロールが不足しています", "operation", ctx.Operation().OperationID, "role", role, "required", required)
+			if err := huma.WriteErr(api, ctx, http.StatusForbidden,
+				fmt.Sprintf("この操作には%sロールが必要です", required),
+				&huma.ErrorDetail{Message: fmt.Sprintf("必要なロール: %s", required), Value: string(role)},
+			); err != nil {
+				slog.Warn("エラーレスポンスの書き込みに失敗", "err", err)
+			}
+			return
+		}
+
+		next(huma.WithContext(ctx, auth.WithRole(ctx.Context(), role)))
+	}
+}
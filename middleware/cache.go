@@ -0,0 +1,293 @@
+package middleware
+
+import (
+	"container/list"
+	"fmt"
+	"go-huma-test/audit"
+	"go-huma-test/locale"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// CacheMetadataKey はOperation.Metadataにキャッシュ方針を設定するキー
+const CacheMetadataKey = "cache"
+
+// cacheVary はキャッシュした応答が依存するリクエストヘッダー
+const cacheVary = "Authorization, X-API-Key, Accept, Accept-Language, X-Timezone"
+
+// CachePolicy は操作の応答をキャッシュする方針を表す構造体
+type CachePolicy struct {
+	// TTL は応答をキャッシュする時間。Cache-Controlのmax-ageにも用いる
+	TTL time.Duration
+	// VaryBy は応答が依存するクエリパラメータ名。キャッシュのキーに含める
+	VaryBy []string
+
+	// varyByAll はすべてのクエリパラメータをキャッシュのキーに含めるか。
+	// Metadataで方針を宣言していない操作をcache-ttlsでキャッシュする場合に用いる
+	varyByAll bool
+}
+
+// CacheMetadata はOperation.Metadataに設定するキャッシュ方針を返す
+func CacheMetadata(p CachePolicy) map[string]any {
+	return map[string]any{CacheMetadataKey: p}
+}
+
+// ParseCacheTTLs は"list-todos=10s,get-todo=0"のようにカンマ区切りで指定された、操作IDごとのキャッシュの時間を解析する。
+// 0は操作のキャッシュを無効にする。空文字列の場合は空のマップを返す。
+func ParseCacheTTLs(s string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, value, ok := strings.Cut(part, "=")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("操作ID=時間の形式で指定してください: %s", part)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("時間を解析できません: %s", part)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("負の時間は指定できません: %s", part)
+		}
+		if _, dup := ttls[id]; dup {
+			return nil, fmt.Errorf("操作IDが重複しています: %s", id)
+		}
+		ttls[id] = d
+	}
+	return ttls, nil
+}
+
+// cachedResponse はキャッシュした応答を表す構造体
+type cachedResponse struct {
+	key       string
+	status    int
+	header    http.Header
+	body      []byte
+	storedAt  time.Time
+	expiresAt time.Time
+}
+
+// CacheOperationMetrics は操作ごとのキャッシュの利用状況を表す構造体
+type CacheOperationMetrics struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// CacheMetrics は応答のキャッシュの利用状況を表す構造体。/debug/varsに公開する。
+type CacheMetrics struct {
+	Entries int   `json:"entries"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	// Evictions は上限を超えたために最も長く使われていない応答を捨てた回数
+	Evictions int64 `json:"evictions"`
+	// Invalidations はデータの変更を受けてキャッシュをすべて破棄した回数
+	Invalidations int64                            `json:"invalidations"`
+	Operations    map[string]CacheOperationMetrics `json:"operations,omitempty"`
+}
+
+// ResponseCache はキャッシュ方針が設定された操作の応答をメモリに保持するLRUキャッシュ。
+// 上限を超えた場合は最も長く使われていない応答から捨てる。
+// 更新系の操作が成功したときと、定期ジョブや他のプロセスによる変更をInvalidateで知らされたときにすべて破棄する。
+type ResponseCache struct {
+	maxEntries int
+	ttls       map[string]time.Duration
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	generation uint64
+	metrics    CacheMetrics
+}
+
+// NewResponseCache はResponseCacheの新しいインスタンスを生成する。
+// maxEntriesが0の場合はサーバー側でキャッシュせず、Cache-Controlヘッダーのみを付与する。
+// ttlsは操作IDごとにキャッシュの時間を上書きし、Metadataで方針を宣言していないGETの操作もキャッシュできるようにする。
+func NewResponseCache(maxEntries int, ttls map[string]time.Duration) *ResponseCache {
+	return &ResponseCache{
+		maxEntries: maxEntries,
+		ttls:       ttls,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		metrics:    CacheMetrics{Operations: make(map[string]CacheOperationMetrics)},
+	}
+}
+
+// policy は操作のキャッシュ方針を返す。cache-ttlsの指定はMetadataの方針より優先する。
+func (c *ResponseCache) policy(op *huma.Operation) (CachePolicy, bool) {
+	p, ok := op.Metadata[CacheMetadataKey].(CachePolicy)
+	if ttl, override := c.ttls[op.OperationID]; override {
+		if !ok {
+			p = CachePolicy{varyByAll: true}
+		}
+		p.TTL = ttl
+		ok = true
+	}
+	return p, ok && p.TTL > 0
+}
+
+// cacheKey は操作、認証主体、パス、Accept、書式化の言語とタイムゾーン、VaryByのクエリパラメータからキャッシュのキーを組み立てる
+func cacheKey(ctx huma.Context, p CachePolicy) string {
+	u := ctx.URL()
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\x00%s\x00%s\x00%s\x00%s\x00%s", ctx.Operation().OperationID, audit.Subject(credential(ctx)), u.Path, ctx.Header("Accept"), ctx.Header("Accept-Language"), ctx.Header(locale.TimezoneHeader))
+	query := u.Query()
+	if p.varyByAll {
+		// Encodeはパラメータ名の順に並べるため、順序の違うURLも同じキーになる
+		fmt.Fprintf(&b, "\x00%s", query.Encode())
+		return b.String()
+	}
+	for _, name := range p.VaryBy {
+		fmt.Fprintf(&b, "\x00%s=%s", name, strings.Join(query[name], ","))
+	}
+	return b.String()
+}
+
+// get は期限内のキャッシュを返し、最近使った応答にする。期限切れの応答は捨てる。
+func (c *ResponseCache) get(operationID, key string, now time.Time) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	om := c.metrics.Operations[operationID]
+	defer func() { c.metrics.Operations[operationID] = om }()
+
+	el, ok := c.entries[key]
+	if ok && !now.Before(el.Value.(cachedResponse).expiresAt) {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+		ok = false
+	}
+	if !ok {
+		c.metrics.Misses++
+		om.Misses++
+		return cachedResponse{}, false
+	}
+	c.metrics.Hits++
+	om.Hits++
+	c.lru.MoveToFront(el)
+	return el.Value.(cachedResponse), true
+}
+
+// put は応答をキャッシュする。上限に達している場合は最も長く使われていない応答を捨てる。
+// 取得を始めてから更新があった場合は古い応答になり得るため保存しない。
+func (c *ResponseCache) put(generation uint64, e cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if el, ok := c.entries[e.key]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+	for c.lru.Len() >= c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(cachedResponse).key)
+		c.metrics.Evictions++
+	}
+	c.entries[e.key] = c.lru.PushFront(e)
+}
+
+// currentGeneration は現在のキャッシュの世代を返す
+func (c *ResponseCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Invalidate はキャッシュをすべて破棄し、世代を進める。
+// APIを経由せずにデータを変更した場合に呼び出し、古い応答を返さないようにする。
+func (c *ResponseCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.metrics.Invalidations++
+	clear(c.entries)
+	c.lru.Init()
+}
+
+// Metrics はキャッシュの利用状況を返す
+func (c *ResponseCache) Metrics() CacheMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := c.metrics
+	m.Entries = c.lru.Len()
+	m.Operations = maps.Clone(c.metrics.Operations)
+	return m
+}
+
+// Middleware はOperation.Metadataのキャッシュ方針に従ってCache-Controlヘッダーを付与し、
+// GETの200応答をサーバー側でキャッシュするミドルウェアを返す。
+// データを変更する操作が成功した場合はデータが変わった可能性があるため、キャッシュを破棄する。
+func (c *ResponseCache) Middleware() func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if isWrite(ctx) {
+			next(ctx)
+			if ctx.Status() < http.StatusBadRequest {
+				c.Invalidate()
+			}
+			return
+		}
+
+		p, ok := c.policy(ctx.Operation())
+		if !ok {
+			next(ctx)
+			return
+		}
+
+		ctx.SetHeader("Cache-Control", "private, max-age="+strconv.Itoa(int(p.TTL.Seconds())))
+		ctx.AppendHeader("Vary", cacheVary)
+		if c.maxEntries <= 0 {
+			next(ctx)
+			return
+		}
+
+		key := cacheKey(ctx, p)
+		now := time.Now()
+		if e, ok := c.get(ctx.Operation().OperationID, key, now); ok {
+			for name, values := range e.header {
+				for _, v := range values {
+					ctx.AppendHeader(name, v)
+				}
+			}
+			ctx.SetHeader("Age", strconv.Itoa(int(now.Sub(e.storedAt).Seconds())))
+			ctx.SetHeader("X-Cache", "HIT")
+			ctx.SetStatus(e.status)
+			_, _ = ctx.BodyWriter().Write(e.body)
+			return
+		}
+
+		generation := c.currentGeneration()
+		ctx.SetHeader("X-Cache", "MISS")
+		rc := &recordingContext{humaContext: ctx, reqBody: ctx.BodyReader(), header: http.Header{}}
+		next(rc)
+		if rc.status != http.StatusOK {
+			return
+		}
+
+		header := http.Header{}
+		for _, name := range replayedHeaders {
+			if v := rc.header.Get(name); v != "" {
+				header.Set(name, v)
+			}
+		}
+		c.put(generation, cachedResponse{
+			key:       key,
+			status:    rc.status,
+			header:    header,
+			body:      rc.body.Bytes(),
+			storedAt:  now,
+			expiresAt: now.Add(p.TTL),
+		})
+	}
+}
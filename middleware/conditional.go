@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// conditionalWriter はGETの200応答をバッファし、条件付きリクエストの判定に使うResponseWriter。
+// JSONやCBOR以外の応答（SSEやファイルのダウンロードなど）はバッファせずにそのまま書き出す。
+type conditionalWriter struct {
+	http.ResponseWriter
+	status      int
+	passthrough bool
+	buf         bytes.Buffer
+}
+
+// bufferable は応答の本文をバッファしてハッシュを計算する対象かを返す
+func bufferable(status int, header http.Header) bool {
+	if status != http.StatusOK {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || mediaType == "application/cbor" ||
+		strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+cbor")
+}
+
+func (w *conditionalWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	if !bufferable(status, w.Header()) {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *conditionalWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+// Flush はバッファしていない応答のみ下位のWriterへフラッシュする
+func (w *conditionalWriter) Flush() {
+	if !w.passthrough {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap は下位のResponseWriterを返す
+func (w *conditionalWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// etagMatches はIf-None-Matchの値がETagに弱い比較で一致するかを返す
+func etagMatches(ifNoneMatch, etag string) bool {
+	trim := func(s string) string {
+		return strings.TrimPrefix(strings.TrimSpace(s), "W/")
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || trim(candidate) == trim(etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified は応答のETagとLast-Modifiedを条件付きヘッダーと照合し、304を返すべきかを判定する。
+// RFC 9110に従い、If-None-Matchが指定されている場合はIf-Modified-Sinceを無視する。
+func notModified(r *http.Request, header http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, header.Get("ETag"))
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(ims)
+}
+
+// ConditionalGET はGETの応答にETagを付与し、If-None-MatchとIf-Modified-Sinceに応じて304を返すミドルウェア。
+// ハンドラーがETagを設定していない場合は本文のハッシュをETagとする。
+// 一覧をポーリングするクライアントが、変更がない場合に本文を受け取らずに済むようにする。
+func ConditionalGET(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &conditionalWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+		if cw.status == 0 || cw.passthrough {
+			return
+		}
+
+		header := w.Header()
+		if header.Get("ETag") == "" {
+			sum := sha256.Sum256(cw.buf.Bytes())
+			header.Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		}
+
+		if notModified(r, header) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.WriteHeader(cw.status)
+		_, _ = w.Write(cw.buf.Bytes())
+	})
+}
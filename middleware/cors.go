@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig はブラウザからのクロスオリジンのリクエストを許可する設定を表す構造体
+type CORSConfig struct {
+	// AllowedOrigins は許可するオリジン（https://app.example.comなど）。"*"はすべてのオリジンを許可する。
+	AllowedOrigins []string
+	// AllowedMethods はプリフライトで許可するメソッド
+	AllowedMethods []string
+	// AllowedHeaders はプリフライトで許可するリクエストヘッダー。"*"は要求されたヘッダーをすべて許可する。
+	AllowedHeaders []string
+	// ExposedHeaders はブラウザのスクリプトから読み取れるようにするレスポンスヘッダー
+	ExposedHeaders []string
+	// AllowCredentials はCookieなどの資格情報付きのリクエストを許可するか。
+	// ブラウザは"*"のオリジンに資格情報を送らないため、AllowedOriginsには個別のオリジンを指定する。
+	AllowCredentials bool
+	// MaxAge はプリフライトの結果をブラウザがキャッシュする時間
+	MaxAge time.Duration
+}
+
+// allowOrigin はオリジンが許可されている場合にAccess-Control-Allow-Originへ返す値を返す
+func (c *CORSConfig) allowOrigin(origin string) (string, bool) {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" {
+			return "*", true
+		}
+		if strings.EqualFold(o, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// allowHeaders はプリフライトで要求されたヘッダーに対してAccess-Control-Allow-Headersへ返す値を返す
+func (c *CORSConfig) allowHeaders(requested string) string {
+	if slices.Contains(c.AllowedHeaders, "*") {
+		return requested
+	}
+	return strings.Join(c.AllowedHeaders, ", ")
+}
+
+// CORS はCORSのヘッダーを付与するミドルウェア。
+// プリフライト（Access-Control-Request-Methodを含むOPTIONS）はHumaの操作として登録されていないため、
+// nextに渡さずにここで応答する。許可されていないオリジンにはCORSのヘッダーを付けず、ブラウザに読み取りを拒否させる。
+func CORS(cfg CORSConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		allowed, ok := cfg.allowOrigin(origin)
+		if !ok {
+			if preflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header.Set("Access-Control-Allow-Origin", allowed)
+		if cfg.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if len(cfg.ExposedHeaders) > 0 {
+				header.Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ", "))
+		if h := cfg.allowHeaders(r.Header.Get("Access-Control-Request-Headers")); h != "" {
+			header.Set("Access-Control-Allow-Headers", h)
+		}
+		if cfg.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,138 @@
+// Package middleware はHuma APIに適用する共通のミドルウェアとトランスフォーマーを提供する。
+package middleware
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// deprecatedFieldsCache は型ごとの非推奨フィールド名のキャッシュ
+var deprecatedFieldsCache sync.Map // map[reflect.Type][]string
+
+// deprecatedFields は型に含まれる `deprecated:"true"` タグ付きフィールドのJSON名を再帰的に収集する。
+// 値によっては書き出されないフィールドも含むため、値を調べる前に非推奨フィールドを含みうる型かの判定に使う。
+func deprecatedFields(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := deprecatedFieldsCache.Load(t); ok {
+		return cached.([]string)
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Tag.Get("deprecated") == "true" {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" {
+				name = f.Name
+			}
+			names = append(names, name)
+		}
+		for _, n := range deprecatedFields(f.Type) {
+			if !slices.Contains(names, n) {
+				names = append(names, n)
+			}
+		}
+	}
+
+	deprecatedFieldsCache.Store(t, names)
+	return names
+}
+
+// isEmptyValue はomitemptyのフィールドがJSONで省略される値かを返す
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// serializedDeprecatedFields はvをJSONにした場合に書き出される `deprecated:"true"` タグ付きフィールドのJSON名をnamesに加えて返す。
+// nilのポインタや省略されるomitempty、omitzeroのフィールドは書き出されないため、その中のフィールドも対象にしない。
+func serializedDeprecatedFields(v reflect.Value, names []string) []string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return names
+		}
+		v = v.Elem()
+	}
+	if len(deprecatedFields(v.Type())) == 0 {
+		return names
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			names = serializedDeprecatedFields(v.Index(i), names)
+		}
+	case reflect.Map:
+		for iter := v.MapRange(); iter.Next(); {
+			names = serializedDeprecatedFields(iter.Value(), names)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			fv := v.Field(i)
+			if strings.Contains(opts, "omitempty") && isEmptyValue(fv) || strings.Contains(opts, "omitzero") && fv.IsZero() {
+				continue
+			}
+			if f.Tag.Get("deprecated") == "true" {
+				if name == "" {
+					name = f.Name
+				}
+				if !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+			}
+			names = serializedDeprecatedFields(fv, names)
+		}
+	}
+	return names
+}
+
+// DeprecationTransformer はレスポンスに非推奨フィールドが含まれる場合に
+// Deprecationヘッダーと対象フィールド名を示すX-Deprecated-Fieldsヘッダーを付与するトランスフォーマーを返す。
+// 型に非推奨フィールドがあっても、nilの関連リソースの中にあるなど実際のレスポンスに書き出されない場合は付与しない。
+func DeprecationTransformer() huma.Transformer {
+	return func(ctx huma.Context, status string, v any) (any, error) {
+		if v == nil || !strings.HasPrefix(status, "2") {
+			return v, nil
+		}
+
+		deprecated := serializedDeprecatedFields(reflect.ValueOf(v), nil)
+		if len(deprecated) == 0 {
+			return v, nil
+		}
+
+		ctx.SetHeader("Deprecation", "true")
+		ctx.SetHeader("X-Deprecated-Fields", strings.Join(deprecated, ","))
+		return v, nil
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type deprecationProject struct {
+	Name     string `json:"name"`
+	Archived bool   `json:"archived" deprecated:"true"`
+}
+
+type deprecationTodo struct {
+	Title    string              `json:"title"`
+	Legacy   string              `json:"legacy,omitempty" deprecated:"true"`
+	Project  *deprecationProject `json:"project,omitempty"`
+	Internal string              `json:"-" deprecated:"true"`
+}
+
+func TestSerializedDeprecatedFields(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want []string
+	}{
+		{"関連リソースがnil", deprecationTodo{Title: "a"}, nil},
+		{"関連リソースあり", &deprecationTodo{Project: &deprecationProject{}}, []string{"archived"}},
+		{"omitemptyの値あり", deprecationTodo{Legacy: "x"}, []string{"legacy"}},
+		{"一覧の一部だけ", []deprecationTodo{{}, {Project: &deprecationProject{}}, {Project: &deprecationProject{}}}, []string{"archived"}},
+		{"空の一覧", []deprecationTodo{}, nil},
+		{"非推奨フィールドのない型", struct{ Name string }{"a"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serializedDeprecatedFields(reflect.ValueOf(tt.v), nil)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("serializedDeprecatedFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
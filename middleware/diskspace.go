@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"go-huma-test/diskspace"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// diskSpaceExemptOperations は空き容量が不足していても受け付ける、データベースに書き込まない操作
+var diskSpaceExemptOperations = map[string]bool{
+	// ログインはトークンを発行するだけで、ログインできないと読み取りもできなくなる
+	"login": true,
+}
+
+// DiskSpace はデータベースのディスクの空き容量が下限を下回っている間、変更する操作を507で拒否するミドルウェアを返す。
+// 削除は空き容量を増やす手段になるため受け付ける。
+func DiskSpace(api huma.API, monitor *diskspace.Monitor) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if monitor.Low() && isWrite(ctx) && ctx.Method() != http.MethodDelete {
+			if op := ctx.Operation(); op == nil || !diskSpaceExemptOperations[op.OperationID] {
+				writeErr(api, ctx, huma.NewError(http.StatusInsufficientStorage, "ディスクの空き容量が不足しているため変更できません。削除と読み取りは引き続き行えます"))
+				return
+			}
+		}
+		next(ctx)
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HSTSConfig はStrict-Transport-Securityヘッダーの設定を表す構造体
+type HSTSConfig struct {
+	// MaxAge はブラウザがHTTPSでのみ接続するよう記憶する期間
+	MaxAge time.Duration
+	// IncludeSubdomains はサブドメインにも適用するか
+	IncludeSubdomains bool
+}
+
+// HSTS はTLSで受け付けたリクエストの応答にStrict-Transport-Securityヘッダーを付けるミドルウェア。
+// 平文のHTTPの応答に付けたヘッダーはブラウザが無視するため、TLSのリクエストにのみ付ける。
+func HSTS(cfg HSTSConfig, next http.Handler) http.Handler {
+	value := "max-age=" + strconv.FormatInt(int64(cfg.MaxAge/time.Second), 10)
+	if cfg.IncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RedirectHTTPS は平文のHTTPのリクエストを同じホストとパスのHTTPSのURLへリダイレクトするハンドラーを返す。
+// httpsPortが443以外の場合はURLにポートを付ける。
+// GETとHEAD以外はメソッドとボディを保ったまま送り直されるよう、301ではなく308で応答する。
+func RedirectHTTPS(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		if host == "" {
+			http.Error(w, "Hostヘッダーがありません", http.StatusBadRequest)
+			return
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		status := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			status = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, target, status)
+	})
+}
@@ -0,0 +1,306 @@
+package middleware
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"go-huma-test/model"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/negotiation"
+)
+
+// todoResponseType はJSON:APIとHALの形式に包むTodoのレスポンスの型
+var todoResponseType = reflect.TypeFor[model.TodoResponse]()
+
+// todoRelation はTodoからリンクする一覧の関連リソース。expandで展開した場合はTodoのnameのキーに含まれる
+type todoRelation struct {
+	name string
+	// resourceType はJSON:APIのリソースの種類
+	resourceType string
+}
+
+// todoRelations はTodoのパスの下に一覧がある関連リソース
+var todoRelations = []todoRelation{
+	{name: model.ExpandSubtasks, resourceType: "subtasks"},
+	{name: model.ExpandComments, resourceType: "comments"},
+	{name: model.ExpandReminders, resourceType: "reminders"},
+	{name: model.ExpandAttachments, resourceType: "attachments"},
+}
+
+// HypermediaTransformer はAcceptヘッダーでJSON:API（application/vnd.api+json）かHAL（application/hal+json）を選んだリクエストに対し、
+// Todoと、Todoの一覧を含むレスポンスを、それぞれの形式の関連リソースへのリンク付きのエンベロープに包むトランスフォーマーを返す。
+// JSON:APIではexpandで展開した関連リソースをincludedに、HALでは_embeddedに移し、JSON:APIのエラーはerrorsの形式にする。
+// Todoを含まないレスポンスは形を変えない。応答の形式はHumaと同じく、defaultFormatとformatsからAcceptヘッダーで選ぶ。
+func HypermediaTransformer(defaultFormat string, formats map[string]huma.Format) huma.Transformer {
+	keys := []string{defaultFormat}
+	for _, k := range slices.Sorted(maps.Keys(formats)) {
+		if k != defaultFormat {
+			keys = append(keys, k)
+		}
+	}
+
+	return func(ctx huma.Context, status string, v any) (any, error) {
+		ct := negotiation.SelectQValueFast(ctx.Header("Accept"), keys)
+		if v == nil || (ct != model.MediaTypeJSONAPI && ct != model.MediaTypeHAL) {
+			return v, nil
+		}
+		if e, ok := v.(*model.ErrorModel); ok && ct == model.MediaTypeJSONAPI {
+			return jsonAPIErrors(e), nil
+		}
+		if !strings.HasPrefix(status, "2") {
+			return v, nil
+		}
+
+		u := ctx.URL()
+		self := u.RequestURI()
+		rv := reflect.Indirect(reflect.ValueOf(v))
+		if rv.Type() == todoResponseType {
+			todo, err := toJSONMap(v)
+			if err != nil {
+				return nil, err
+			}
+			if ct == model.MediaTypeJSONAPI {
+				return jsonAPITodo(todo, self), nil
+			}
+			return halTodo(todo), nil
+		}
+
+		name, ok := todoListField(rv.Type())
+		if !ok {
+			return v, nil
+		}
+		body, err := toJSONMap(v)
+		if err != nil {
+			return nil, err
+		}
+		todos, _ := body[name].([]any)
+		delete(body, name)
+		if ct == model.MediaTypeJSONAPI {
+			return jsonAPITodos(todos, body, self), nil
+		}
+		return halTodos(name, todos, body, self), nil
+	}
+}
+
+// todoListField はレスポンスの構造体からTodoの一覧のフィールドを探し、そのJSONの名前を返す
+func todoListField(t reflect.Type) (string, bool) {
+	if t.Kind() != reflect.Struct {
+		return "", false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type.Kind() != reflect.Slice || f.Type.Elem() != todoResponseType {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = f.Name
+		}
+		return name, name != "-"
+	}
+	return "", false
+}
+
+// toJSONMap はレスポンスをJSONのオブジェクトに変換する。IDを書き換えずに文字列にできるよう、数値はjson.Numberにする
+func toJSONMap(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("レスポンスのJSONへの変換に失敗: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("レスポンスのJSONへの変換に失敗: %w", err)
+	}
+	return m, nil
+}
+
+// idString はJSONのオブジェクトのidを文字列で返す。idがない場合は空文字を返す
+func idString(m map[string]any) string {
+	if id, ok := m["id"]; ok && id != nil {
+		return fmt.Sprint(id)
+	}
+	return ""
+}
+
+// jsonAPIResource はJSONのオブジェクトをidを除いた属性とするJSON:APIのリソースオブジェクトを返す
+func jsonAPIResource(resourceType string, m map[string]any) map[string]any {
+	attrs := maps.Clone(m)
+	delete(attrs, "id")
+	return map[string]any{"type": resourceType, "id": idString(m), "attributes": attrs}
+}
+
+// jsonAPITodoResource はTodoをJSON:APIのリソースオブジェクトに変換し、展開した関連リソースをincludedに加える。
+// プロジェクトと次回のTodoのIDは属性から除き、relationshipsのリソース識別子にする
+func jsonAPITodoResource(todo map[string]any, included *[]map[string]any) map[string]any {
+	id := idString(todo)
+	attrs := maps.Clone(todo)
+	delete(attrs, "id")
+	relationships := map[string]any{}
+
+	if projectID, ok := attrs["project_id"]; ok {
+		relationships[model.ExpandProject] = map[string]any{
+			"data":  map[string]any{"type": "projects", "id": fmt.Sprint(projectID)},
+			"links": map[string]any{"related": fmt.Sprintf("/projects/%v", projectID)},
+		}
+		if project, ok := attrs[model.ExpandProject].(map[string]any); ok {
+			*included = append(*included, jsonAPIResource("projects", project))
+		}
+		delete(attrs, "project_id")
+		delete(attrs, model.ExpandProject)
+	}
+	if nextID, ok := attrs["next_todo_id"]; ok {
+		relationships["next"] = map[string]any{
+			"data":  map[string]any{"type": "todos", "id": fmt.Sprint(nextID)},
+			"links": map[string]any{"related": fmt.Sprintf("/todos/%v", nextID)},
+		}
+		delete(attrs, "next_todo_id")
+	}
+	for _, r := range todoRelations {
+		rel := map[string]any{"links": map[string]any{"related": fmt.Sprintf("/todos/%s/%s", id, r.name)}}
+		if items, ok := attrs[r.name].([]any); ok {
+			data := make([]any, 0, len(items))
+			for _, item := range items {
+				m, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				data = append(data, map[string]any{"type": r.resourceType, "id": idString(m)})
+				*included = append(*included, jsonAPIResource(r.resourceType, m))
+			}
+			rel["data"] = data
+			delete(attrs, r.name)
+		}
+		relationships[r.name] = rel
+	}
+
+	return map[string]any{
+		"type":          "todos",
+		"id":            id,
+		"attributes":    attrs,
+		"relationships": relationships,
+		"links":         map[string]any{"self": "/todos/" + id},
+	}
+}
+
+// jsonAPIDocument はdataとリクエストのURIを指すリンクからJSON:APIのトップレベルのドキュメントを組み立てる。
+// includedは同じリソースを1つにまとめ、空のincludedとmetaは省く
+func jsonAPIDocument(data any, included []map[string]any, meta map[string]any, self string) map[string]any {
+	doc := map[string]any{"data": data, "links": map[string]any{"self": self}}
+	seen := make(map[string]bool, len(included))
+	unique := make([]map[string]any, 0, len(included))
+	for _, r := range included {
+		key := fmt.Sprint(r["type"], "/", r["id"])
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, r)
+	}
+	if len(unique) > 0 {
+		doc["included"] = unique
+	}
+	if len(meta) > 0 {
+		doc["meta"] = meta
+	}
+	return doc
+}
+
+// jsonAPITodo はTodoをJSON:APIのドキュメントに包む
+func jsonAPITodo(todo map[string]any, self string) map[string]any {
+	var included []map[string]any
+	return jsonAPIDocument(jsonAPITodoResource(todo, &included), included, nil, self)
+}
+
+// jsonAPITodos はTodoの一覧をJSON:APIのドキュメントに包む。一覧以外のフィールドはmetaにする
+func jsonAPITodos(todos []any, rest map[string]any, self string) map[string]any {
+	var included []map[string]any
+	data := make([]any, 0, len(todos))
+	for _, t := range todos {
+		if todo, ok := t.(map[string]any); ok {
+			data = append(data, jsonAPITodoResource(todo, &included))
+		}
+	}
+	return jsonAPIDocument(data, included, rest, self)
+}
+
+// jsonAPIErrors はエラーをJSON:APIのエラーオブジェクトの一覧に変換する。原因の箇所ごとに1つのエラーオブジェクトにする
+func jsonAPIErrors(e *model.ErrorModel) map[string]any {
+	status := strconv.Itoa(e.Status)
+	title := cmp.Or(e.Title, http.StatusText(e.Status))
+	if len(e.Errors) == 0 {
+		return map[string]any{"errors": []any{map[string]any{"status": status, "title": title, "detail": e.Detail}}}
+	}
+	errs := make([]any, 0, len(e.Errors))
+	for _, d := range e.Errors {
+		obj := map[string]any{"status": status, "title": title, "detail": cmp.Or(d.Message, e.Detail)}
+		if d.Location != "" {
+			obj["source"] = map[string]any{"pointer": d.Location}
+		}
+		if d.Value != nil {
+			obj["meta"] = map[string]any{"value": d.Value}
+		}
+		errs = append(errs, obj)
+	}
+	return map[string]any{"errors": errs}
+}
+
+// halLink はHALのリンクオブジェクトを返す
+func halLink(href string) map[string]any {
+	return map[string]any{"href": href}
+}
+
+// halTodo はTodoに関連リソースへの_linksを加えたHALのリソースに変換する。展開した関連リソースは_embeddedに移す
+func halTodo(todo map[string]any) map[string]any {
+	id := idString(todo)
+	out := maps.Clone(todo)
+	links := map[string]any{"self": halLink("/todos/" + id)}
+	embedded := map[string]any{}
+
+	if projectID, ok := out["project_id"]; ok {
+		links[model.ExpandProject] = halLink(fmt.Sprintf("/projects/%v", projectID))
+	}
+	if project, ok := out[model.ExpandProject]; ok {
+		embedded[model.ExpandProject] = project
+		delete(out, model.ExpandProject)
+	}
+	if nextID, ok := out["next_todo_id"]; ok {
+		links["next"] = halLink(fmt.Sprintf("/todos/%v", nextID))
+	}
+	for _, r := range todoRelations {
+		links[r.name] = halLink(fmt.Sprintf("/todos/%s/%s", id, r.name))
+		if items, ok := out[r.name]; ok {
+			embedded[r.name] = items
+			delete(out, r.name)
+		}
+	}
+
+	out["_links"] = links
+	if len(embedded) > 0 {
+		out["_embedded"] = embedded
+	}
+	return out
+}
+
+// halTodos はTodoの一覧をnameの_embeddedに入れたHALのリソースに変換する。一覧以外のフィールドはそのまま残す
+func halTodos(name string, todos []any, rest map[string]any, self string) map[string]any {
+	items := make([]any, 0, len(todos))
+	for _, t := range todos {
+		if todo, ok := t.(map[string]any); ok {
+			items = append(items, halTodo(todo))
+		}
+	}
+	out := maps.Clone(rest)
+	out["_links"] = map[string]any{"self": halLink(self)}
+	out["_embedded"] = map[string]any{name: items}
+	return out
+}
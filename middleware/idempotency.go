@@ -0,0 +1,249 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"go-huma-test/audit"
+	"go-huma-test/auth"
+	"go-huma-test/db"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// IdempotencyKeyHeader は冪等性キーを指定するリクエストヘッダー
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader は保存済みの応答を再送したことを示すレスポンスヘッダー
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength は冪等性キーの最大長
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotentBodyBytes はハッシュを計算するために読み込むリクエストボディの上限
+const maxIdempotentBodyBytes = 1024 * 1024
+
+// replayedHeaders は応答を再送する際に復元するレスポンスヘッダー
+var replayedHeaders = []string{"Content-Type", "Location", "ETag", "Last-Modified"}
+
+// IdempotencyKeyParam はIdempotency-Keyヘッダーを受け付ける操作のOpenAPIパラメータを返す
+func IdempotencyKeyParam() *huma.Param {
+	maxLength := maxIdempotencyKeyLength
+	return &huma.Param{
+		Name:        IdempotencyKeyHeader,
+		In:          "header",
+		Description: "リトライ時に同じ値を指定すると、再度処理せずに最初の応答を返す",
+		Schema:      &huma.Schema{Type: "string", MaxLength: &maxLength},
+	}
+}
+
+// humaContext はhuma.Contextを埋め込んでもContextメソッドと名前が衝突しないようにする別名
+type humaContext = huma.Context
+
+// recordingContext はリクエストボディを差し替え、書き出した応答を記録するhuma.Context
+type recordingContext struct {
+	humaContext
+	reqBody io.Reader
+	status  int
+	header  http.Header
+	body    bytes.Buffer
+}
+
+func (c *recordingContext) BodyReader() io.Reader {
+	return c.reqBody
+}
+
+func (c *recordingContext) SetStatus(code int) {
+	c.status = code
+	c.humaContext.SetStatus(code)
+}
+
+func (c *recordingContext) SetHeader(name, value string) {
+	c.header.Set(name, value)
+	c.humaContext.SetHeader(name, value)
+}
+
+func (c *recordingContext) AppendHeader(name, value string) {
+	c.header.Add(name, value)
+	c.humaContext.AppendHeader(name, value)
+}
+
+func (c *recordingContext) BodyWriter() io.Writer {
+	return io.MultiWriter(c.humaContext.BodyWriter(), &c.body)
+}
+
+// requestHash はメソッド、パス、ボディから同一のリクエストかを判定するためのハッシュを計算する
+func requestHash(ctx huma.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(ctx.Method() + " " + ctx.URL().Path + "?" + ctx.URL().RawQuery + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencySubject は冪等性キーを区別する認証主体を返す。
+// ユーザーとして認証した場合はユーザーIDで区別し、トークンを更新してリトライしても同じキーとして扱う。
+// ユーザー以外はトークンの発行者と認証主体、クレームのない場合は資格情報のハッシュで区別する。
+func idempotencySubject(ctx huma.Context) string {
+	if id, ok := auth.UserIDFrom(ctx.Context()); ok {
+		return auth.UserSubject(id)
+	}
+	if claims, ok := auth.ClaimsFrom(ctx.Context()); ok && claims.Subject != "" {
+		return audit.Subject(claims.Issuer + "\n" + claims.Subject)
+	}
+	return audit.Subject(credential(ctx))
+}
+
+// IdempotencyKey はIdempotency-Keyヘッダー付きのリクエストの応答を保存し、
+// 同じキーでリトライされた場合は処理を繰り返さずに保存した応答を返すミドルウェアを返す。
+// キーは認証主体ごとに区別し、ttlを過ぎると再利用できる。
+// 5xxの応答やパニックした処理は保存せずにキーを解放し、同じキーでのリトライを許可する。
+func IdempotencyKey(api huma.API, queries *db.Queries, ttl time.Duration) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		key := ctx.Header(IdempotencyKeyHeader)
+		if key == "" {
+			next(ctx)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeErr(api, ctx, huma.Error400BadRequest("Idempotency-Keyが長すぎます"))
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(ctx.BodyReader(), maxIdempotentBodyBytes+1))
+		if err != nil {
+			writeErr(api, ctx, huma.Error400BadRequest("リクエストボディの読み込みに失敗", err))
+			return
+		}
+		if len(body) > maxIdempotentBodyBytes {
+			// 上限を超えるボディは処理側で413になるため、キーを記録せずにそのまま渡す
+			next(&recordingContext{humaContext: ctx, reqBody: io.MultiReader(bytes.NewReader(body), ctx.BodyReader()), header: http.Header{}})
+			return
+		}
+
+		subject := idempotencySubject(ctx)
+		hash := requestHash(ctx, body)
+		now := time.Now().UTC()
+
+		stored, err := queries.GetIdempotencyKey(ctx.Context(), db.GetIdempotencyKeyParams{
+			Subject:        subject,
+			IdempotencyKey: key,
+			ExpiresAt:      now,
+		})
+		switch {
+		case err == nil:
+			replay(api, ctx, stored, hash)
+			return
+		case !errors.Is(err, sql.ErrNoRows):
+			slog.Warn("冪等性キーの取得に失敗", "err", err)
+			writeErr(api, ctx, huma.Error500InternalServerError("冪等性キーの取得に失敗", err))
+			return
+		}
+
+		if _, err := queries.DeleteExpiredIdempotencyKeys(ctx.Context(), now); err != nil {
+			slog.Warn("期限切れの冪等性キーの削除に失敗", "err", err)
+		}
+		n, err := queries.CreateIdempotencyKey(ctx.Context(), db.CreateIdempotencyKeyParams{
+			Subject:        subject,
+			IdempotencyKey: key,
+			RequestHash:    hash,
+			ExpiresAt:      now.Add(ttl),
+		})
+		if err != nil {
+			slog.Warn("冪等性キーの登録に失敗", "err", err)
+			writeErr(api, ctx, huma.Error500InternalServerError("冪等性キーの登録に失敗", err))
+			return
+		}
+		if n == 0 {
+			// 取得から登録までの間に同じキーのリクエストが登録した
+			writeErr(api, ctx, huma.Error409Conflict("同じIdempotency-Keyのリクエストを処理中です"))
+			return
+		}
+
+		release := func() {
+			// リクエストが中断されていてもキーを解放する
+			if err := queries.DeleteIdempotencyKey(context.WithoutCancel(ctx.Context()), db.DeleteIdempotencyKeyParams{
+				Subject:        subject,
+				IdempotencyKey: key,
+			}); err != nil {
+				slog.Warn("冪等性キーの削除に失敗", "err", err)
+			}
+		}
+		rc := &recordingContext{humaContext: ctx, reqBody: bytes.NewReader(body), header: http.Header{}}
+		func() {
+			// パニックした場合も処理中のままにせず、キーを解放してからRecoverに任せる
+			defer func() {
+				if r := recover(); r != nil {
+					release()
+					panic(r)
+				}
+			}()
+			next(rc)
+		}()
+
+		if rc.status == 0 || rc.status >= http.StatusInternalServerError {
+			release()
+			return
+		}
+
+		headers := make(map[string]string)
+		for _, name := range replayedHeaders {
+			if v := rc.header.Get(name); v != "" {
+				headers[name] = v
+			}
+		}
+		encoded, err := json.Marshal(headers)
+		if err != nil {
+			slog.Warn("レスポンスヘッダーのエンコードに失敗", "err", err)
+			return
+		}
+		if err := queries.CompleteIdempotencyKey(ctx.Context(), db.CompleteIdempotencyKeyParams{
+			Status:         int64(rc.status),
+			Headers:        string(encoded),
+			Body:           rc.body.Bytes(),
+			Subject:        subject,
+			IdempotencyKey: key,
+		}); err != nil {
+			slog.Warn("冪等性キーの応答の保存に失敗", "err", err)
+		}
+	}
+}
+
+// replay は保存済みの応答を返す。処理中の場合やリクエストの内容が異なる場合はエラーを返す。
+func replay(api huma.API, ctx huma.Context, stored db.IdempotencyKey, hash string) {
+	if stored.RequestHash != hash {
+		writeErr(api, ctx, huma.Error422UnprocessableEntity("Idempotency-Keyが異なる内容のリクエストで使用されています"))
+		return
+	}
+	if stored.Status == 0 {
+		writeErr(api, ctx, huma.Error409Conflict("同じIdempotency-Keyのリクエストを処理中です"))
+		return
+	}
+
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(stored.Headers), &headers); err != nil {
+		slog.Warn("保存済みレスポンスヘッダーの読み込みに失敗", "err", err)
+	}
+	for name, value := range headers {
+		ctx.SetHeader(name, value)
+	}
+	ctx.SetHeader(IdempotentReplayedHeader, "true")
+	ctx.SetStatus(int(stored.Status))
+	if _, err := ctx.BodyWriter().Write(stored.Body); err != nil {
+		slog.Warn("保存済みレスポンスの書き込みに失敗", "err", err)
+	}
+}
+
+// writeErr はエラーレスポンスを書き込む
+func writeErr(api huma.API, ctx huma.Context, err huma.StatusError) {
+	if err := huma.WriteErr(api, ctx, err.GetStatus(), err.Error()); err != nil {
+		slog.Warn("エラーレスポンスの書き込みに失敗", "err", err)
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"go-huma-test/locale"
+	"log/slog"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Locale はAccept-Languageヘッダーと、X-Timezoneヘッダーで指定されたタイムゾーンから
+// 出力の書式化に用いるlocale.Formatterを組み立ててcontextに格納するミドルウェアを返す。
+// 指定がない場合や対応していない場合はdefaultsの言語とタイムゾーンを用いる。
+// Todoの訳を選べるよう、Accept-Languageヘッダーの値と、元のタイトルの言語とみなす既定の言語もcontextに格納する。
+func Locale(defaults *locale.Formatter) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		acceptLanguage := ctx.Header("Accept-Language")
+		lang := locale.Negotiate(acceptLanguage, defaults.Language())
+		loc := defaults.Location()
+		if tz := ctx.Header(locale.TimezoneHeader); tz != "" {
+			l, err := time.LoadLocation(tz)
+			if err != nil {
+				slog.Warn("タイムゾーンが不正なため既定のタイムゾーンを使用", "tz", tz, "err", err)
+			} else {
+				loc = l
+			}
+		}
+		c := locale.WithFormatter(ctx.Context(), locale.New(lang, loc))
+		next(huma.WithContext(ctx, locale.WithAcceptLanguage(c, acceptLanguage, defaults.Language())))
+	}
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MirroredHeader は複製したリクエストに付けるヘッダー。受け取った側がさらに複製して送り返すことを防ぐ
+const MirroredHeader = "X-Mirrored-Request"
+
+// hopHeaders は接続ごとのヘッダー。複製したリクエストには含めない
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// MirrorConfig はリクエストの複製の設定を表す構造体
+type MirrorConfig struct {
+	// URL は複製したリクエストを送る先のベースURL。リクエストのパスとクエリを後ろに付ける
+	URL string
+	// Percent は複製するリクエストの割合（0から100）
+	Percent int
+	// Timeout は複製したリクエストの応答を待つ時間
+	Timeout time.Duration
+	// MaxBody は複製するリクエストのボディの最大サイズ。超えるリクエストは複製しない
+	MaxBody int64
+	// Concurrency は同時に送る複製したリクエストの最大数。送信中のものが上限に達している間のリクエストは複製しない
+	Concurrency int
+}
+
+// Mirror はリクエストの一部をヘッダーとボディごと複製し、cfg.URLへ非同期に送るミドルウェア。
+// 本番のトラフィックで新しいバージョンを安全に検証するためのもので、複製先の応答や失敗は元のリクエストの応答に影響しない。
+// 複製先に送るまでに待たないよう、ボディを読み切れない大きなリクエストや、送信中の複製が上限に達している間のリクエストは複製せずに捨てる。
+// アクセスログと同じリクエストIDで突き合わせられるよう、AccessLogの内側に登録する。
+func Mirror(cfg MirrorConfig, next http.Handler) http.Handler {
+	base := strings.TrimSuffix(cfg.URL, "/")
+	client := &http.Client{
+		Timeout: cfg.Timeout,
+		// 複製先のリダイレクトはたどらずに、その応答で終える
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	slots := make(chan struct{}, cfg.Concurrency)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !shouldMirror(cfg, r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// ボディは元のハンドラーが読む前に読み取り、読んだ分を戻して元のハンドラーにそのまま渡す
+		var body []byte
+		if r.Body != nil && r.Body != http.NoBody {
+			buf, err := io.ReadAll(io.LimitReader(r.Body, cfg.MaxBody+1))
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+			if err != nil || int64(len(buf)) > cfg.MaxBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+			body = buf
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			slog.Debug("送信中の複製が上限に達しているため、リクエストを複製しません", "method", r.Method, "path", r.URL.Path)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := r.Header.Clone()
+		for _, h := range hopHeaders {
+			header.Del(h)
+		}
+		header.Set(MirroredHeader, "true")
+		if prior := header.Get("X-Forwarded-For"); prior != "" {
+			header.Set("X-Forwarded-For", prior+", "+remoteIP(r))
+		} else {
+			header.Set("X-Forwarded-For", remoteIP(r))
+		}
+		if id := RequestIDFrom(r.Context()); id != "" {
+			header.Set(RequestIDHeader, id)
+		}
+		method, target := r.Method, base+r.URL.RequestURI()
+		go func() {
+			defer func() { <-slots }()
+			sendMirror(client, method, target, header, body)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// shouldMirror はリクエストを複製するかを返す
+func shouldMirror(cfg MirrorConfig, r *http.Request) bool {
+	switch {
+	case cfg.Percent <= 0:
+		return false
+	case r.Header.Get(MirroredHeader) != "":
+		// 複製されたリクエストを複製し直すと、互いに複製し合う設定で送り合いが止まらなくなる
+		return false
+	case r.Header.Get("Upgrade") != "":
+		// WebSocketなどの接続の切り替えは、ボディを複製しても同じやり取りを再現できない
+		return false
+	case r.ContentLength > cfg.MaxBody:
+		return false
+	}
+	return cfg.Percent >= 100 || mathrand.IntN(100) < cfg.Percent
+}
+
+// sendMirror は複製したリクエストを送り、応答を読み捨てる
+func sendMirror(client *http.Client, method, target string, header http.Header, body []byte) {
+	req, err := http.NewRequestWithContext(context.Background(), method, target, bytes.NewReader(body))
+	if err != nil {
+		slog.Warn("複製したリクエストの作成に失敗", "method", method, "url", target, "err", err)
+		return
+	}
+	req.Header = header
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		slog.Warn("複製したリクエストの送信に失敗", "method", method, "url", target, "err", err)
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	slog.Debug("リクエストを複製",
+		"method", method,
+		"url", target,
+		"status", resp.StatusCode,
+		"duration_ms", float64(time.Since(start).Microseconds())/1000,
+		"request_id", header.Get(RequestIDHeader),
+	)
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"go-huma-test/tracing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// QueryTimeout はリクエストの処理中に実行するSQLそれぞれの実行時間の上限をdにするミドルウェアを返す。
+// 上限を超えたSQLは中断されてcontext.DeadlineExceededになり、遅いクエリがリクエストを書き込みのタイムアウトまで待たせないようにする。
+func QueryTimeout(d time.Duration) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		next(huma.WithContext(ctx, tracing.WithQueryTimeout(ctx.Context(), d)))
+	}
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"go-huma-test/auth"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// レート制限の状態を返すレスポンスヘッダー
+const (
+	// RateLimitLimitHeader は1分あたりに許可するリクエスト数
+	RateLimitLimitHeader = "X-RateLimit-Limit"
+	// RateLimitPolicyHeader は制限の方針。"600;w=60;burst=100"のように、ウィンドウの秒数と連続して送れるリクエスト数を示す
+	RateLimitPolicyHeader = "X-RateLimit-Policy"
+	// RateLimitRemainingHeader は現在送れる残りのリクエスト数
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	// RateLimitResetHeader はバケットが満杯に戻るまでの秒数
+	RateLimitResetHeader = "X-RateLimit-Reset"
+)
+
+// bucket はクライアントごとのトークンバケットを表す構造体
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// RateLimiter はクライアントごとのトークンバケットでリクエスト数を制限する。
+// クライアントは認証した場合は認証主体（ユーザーやAPIキー）で、認証なしの操作ではIPアドレスで区別する。
+type RateLimiter struct {
+	limit int
+	burst int
+	rate  float64 // 1秒あたりに補充するトークン数
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter はRateLimiterの新しいインスタンスを生成する。
+// perMinuteは1分あたりに許可するリクエスト数、burstは連続して許可するリクエスト数。
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+	return &RateLimiter{
+		limit:   perMinute,
+		burst:   burst,
+		rate:    float64(perMinute) / 60,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// take はクライアントのバケットからトークンを1つ取り出す。
+// 取り出せた場合はtrueを、取り出せない場合は次のトークンが補充されるまでの時間を返す。
+// remainingは残りのトークン数、resetはバケットが満杯に戻るまでの時間。
+func (l *RateLimiter) take(key string) (ok bool, remaining int, reset, retryAfter time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, found := l.buckets[key]
+	if !found {
+		b = &bucket{tokens: float64(l.burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		ok = true
+	} else {
+		retryAfter = l.duration(1 - b.tokens)
+	}
+	return ok, int(b.tokens), l.duration(float64(l.burst) - b.tokens), retryAfter
+}
+
+// duration はトークンをn個補充するのにかかる時間を返す
+func (l *RateLimiter) duration(n float64) time.Duration {
+	return time.Duration(n / l.rate * float64(time.Second))
+}
+
+// sweep は満杯に戻ったバケットを捨てる。満杯のバケットは新しく作るものと同じため、捨てても制限は変わらない。
+// 呼び出し元でmuをロックしておく。
+func (l *RateLimiter) sweep(now time.Time) {
+	full := l.duration(float64(l.burst))
+	if now.Sub(l.lastSweep) < full {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// clientKey はレート制限に用いるクライアントの識別子を返す
+func clientKey(ctx huma.Context) string {
+	if claims, ok := auth.ClaimsFrom(ctx.Context()); ok && claims.Subject != "" {
+		return "sub:" + claims.Subject
+	}
+	return "ip:" + clientIP(ctx)
+}
+
+// seconds は時間を切り上げた秒数の文字列にする
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// rateLimitWindow はX-RateLimit-Limitのリクエスト数を数えるウィンドウ
+const rateLimitWindow = time.Minute
+
+// policy はX-RateLimit-Policyヘッダーの値を返す
+func (l *RateLimiter) policy() string {
+	return strconv.Itoa(l.limit) + ";w=" + seconds(rateLimitWindow) + ";burst=" + strconv.Itoa(l.burst)
+}
+
+// Middleware はクライアントごとにリクエスト数を制限し、X-RateLimit-*ヘッダーで制限の状態を返すミドルウェアを返す。
+// X-RateLimit-Limitは設定した1分あたりのリクエスト数を、X-RateLimit-Remainingは今すぐ連続して送れる残りのリクエスト数を返す。
+// 上限を超えた場合はRetry-Afterヘッダーを付けて429を返す。
+// 認証主体でクライアントを区別するため、認証を行うミドルウェアより後に登録する。
+func (l *RateLimiter) Middleware(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		key := clientKey(ctx)
+		ok, remaining, reset, retryAfter := l.take(key)
+
+		ctx.SetHeader(RateLimitLimitHeader, strconv.Itoa(l.limit))
+		ctx.SetHeader(RateLimitPolicyHeader, l.policy())
+		ctx.SetHeader(RateLimitRemainingHeader, strconv.Itoa(remaining))
+		ctx.SetHeader(RateLimitResetHeader, seconds(reset))
+		if !ok {
+			slog.Warn("リクエスト数が上限を超えました", "client", key, "retry_after", retryAfter)
+			ctx.SetHeader("Retry-After", seconds(retryAfter))
+			writeErr(api, ctx, huma.NewError(http.StatusTooManyRequests, "リクエスト数が上限を超えました。Retry-Afterの秒数が経過してから再試行してください"))
+			return
+		}
+		next(ctx)
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterTake(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(60, 2)
+	l.now = func() time.Time { return now }
+
+	if got := l.policy(); got != "60;w=60;burst=2" {
+		t.Errorf("policy() = %q", got)
+	}
+
+	for i, wantRemaining := range []int{1, 0} {
+		ok, remaining, _, _ := l.take("a")
+		if !ok || remaining != wantRemaining {
+			t.Fatalf("take #%d = %v, %d; want true, %d", i+1, ok, remaining, wantRemaining)
+		}
+	}
+	ok, _, reset, retryAfter := l.take("a")
+	if ok || retryAfter != time.Second || reset != 2*time.Second {
+		t.Fatalf("take over burst = %v, reset %v, retryAfter %v", ok, reset, retryAfter)
+	}
+	// 別のクライアントは別のバケット
+	if ok, _, _, _ := l.take("b"); !ok {
+		t.Fatal("take for another client was rejected")
+	}
+
+	// 1秒に1件補充される
+	now = now.Add(time.Second)
+	if ok, _, _, _ := l.take("a"); !ok {
+		t.Fatal("take after refill was rejected")
+	}
+}
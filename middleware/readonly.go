@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// WritesMetadataKey はOperation.Metadataに、GETでもデータを変更する操作であることを設定するキー
+const WritesMetadataKey = "writes"
+
+// WritesMetadata は通知のリンクのように、GETでもデータを変更する操作のOperation.Metadataを返す
+func WritesMetadata() map[string]any {
+	return map[string]any{WritesMetadataKey: true}
+}
+
+// isWrite はデータを変更する操作かを返す。GETとHEADはWritesMetadataが設定されている場合のみ変更とみなす。
+func isWrite(ctx huma.Context) bool {
+	switch ctx.Method() {
+	case http.MethodGet, http.MethodHead:
+		op := ctx.Operation()
+		if op == nil {
+			return false
+		}
+		writes, _ := op.Metadata[WritesMetadataKey].(bool)
+		return writes
+	default:
+		return true
+	}
+}
+
+// ReadOnly は読み取り専用のレプリカで、データを変更する操作を503で拒否するミドルウェアを返す。
+// レプリカのデータベースは読み取り専用で開くため、書き込みを試みる前に拒否してプライマリへの送信を促す。
+func ReadOnly(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if isWrite(ctx) {
+			writeErr(api, ctx, huma.NewError(http.StatusServiceUnavailable, "読み取り専用のレプリカでは変更できません。プライマリに送信してください"))
+			return
+		}
+		next(ctx)
+	}
+}
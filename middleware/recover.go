@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// PanicHook はハンドラーでパニックが発生したときに呼び出される関数。
+// 応答を返した後に別のgoroutineで呼び出すため、リクエストのキャンセルの影響を受けない。
+type PanicHook func(ctx context.Context, method, path string, recovered any, stack []byte)
+
+// recoverWriter は応答のヘッダーを送信済みかを記録するResponseWriter
+type recoverWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *recoverWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recoverWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush は下位のWriterへフラッシュする
+func (w *recoverWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Unwrap は下位のResponseWriterを返す
+func (w *recoverWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Recover はハンドラーのパニックから復帰し、スタックトレースをslogで記録して500のproblem+jsonを返すミドルウェア。
+// 応答のヘッダーを送信済みの場合は500に置き換えられないため、http.ErrAbortHandlerで接続を切断し、
+// 不完全な応答であることをクライアントに伝える。hookがnilでない場合は警告の通知などのために呼び出す。
+// CORSのヘッダーを付けたまま500を返せるよう、CORSより内側に登録する。
+func Recover(hook PanicHook, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &recoverWriter{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// 接続の中断を意図したパニックはnet/httpにそのまま処理させる
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			stack := debug.Stack()
+			slog.ErrorContext(r.Context(), "ハンドラーでパニックが発生",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"panic", fmt.Sprint(recovered),
+				"request_id", RequestIDFrom(r.Context()),
+				"stack", string(stack),
+			)
+			if hook != nil {
+				go hook(context.WithoutCancel(r.Context()), r.Method, r.URL.Path, recovered, stack)
+			}
+
+			if rw.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+			body, _ := json.Marshal(huma.NewError(http.StatusInternalServerError, "サーバー内部でエラーが発生しました"))
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write(body)
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"go-huma-test/slo"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// SLO は操作の応答の結果と応答時間をtrackerに記録するミドルウェアを返す。
+// 応答時間が応答の大きさや接続の長さで決まる操作は、untimedに操作IDを含めてレイテンシの目標の対象から除く。
+// 認証やレート制限で拒否したリクエストも数えるため、Tracingの次に登録する。パニックは500として数える。
+func SLO(tracker *slo.Tracker, untimed map[string]bool) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		start := time.Now()
+		timed := true
+		if op := ctx.Operation(); op != nil {
+			timed = !untimed[op.OperationID]
+		}
+		defer func() {
+			if p := recover(); p != nil {
+				end := time.Now()
+				tracker.Record(end, http.StatusInternalServerError, end.Sub(start), timed)
+				panic(p)
+			}
+		}()
+
+		next(ctx)
+
+		status := ctx.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		end := time.Now()
+		tracker.Record(end, status, end.Sub(start), timed)
+	}
+}
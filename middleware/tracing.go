@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"go-huma-test/tracing"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier はリクエストのヘッダーからトレースの文脈を読み込み、応答のヘッダーに書き込むTextMapCarrier
+type headerCarrier struct {
+	ctx huma.Context
+}
+
+func (c headerCarrier) Get(key string) string {
+	return c.ctx.Header(key)
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c.ctx.SetHeader(key, value)
+}
+
+func (c headerCarrier) Keys() []string {
+	var keys []string
+	c.ctx.EachHeader(func(name, _ string) {
+		keys = append(keys, strings.ToLower(name))
+	})
+	return keys
+}
+
+// Tracing は操作ごとにサーバーのスパンを作成するミドルウェア。
+// リクエストのtraceparentヘッダーで伝播された文脈を親とし、スパン名は"GET /todos/{id}"のように
+// メソッドとパスのテンプレートにする。5xxの応答はスパンをエラーにする。
+// 認証などで拒否したリクエストも記録するため、最初に登録する。
+func Tracing(ctx huma.Context, next func(huma.Context)) {
+	op := ctx.Operation()
+	parent := otel.GetTextMapPropagator().Extract(ctx.Context(), headerCarrier{ctx: ctx})
+	c, span := tracing.Tracer().Start(parent, op.Method+" "+op.Path,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRoute(op.Path),
+			semconv.HTTPRequestMethodKey.String(op.Method),
+			attribute.String("huma.operation_id", op.OperationID),
+			semconv.URLPath(ctx.URL().Path),
+			semconv.ClientAddress(clientIP(ctx)),
+			semconv.UserAgentOriginal(ctx.Header("User-Agent")),
+		),
+	)
+	defer span.End()
+
+	next(huma.WithContext(ctx, c))
+
+	status := ctx.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	if id := RequestIDFrom(c); id != "" {
+		span.SetAttributes(attribute.String("http.request.id", id))
+	}
+}
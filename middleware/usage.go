@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"go-huma-test/auth"
+	"go-huma-test/usage"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Usage は認証したユーザーのAPIの呼び出しを利用量として、すべての呼び出しを操作ごとの呼び出し回数として数えるミドルウェアを返す。
+// ユーザーのIDで数えるため認証を行うミドルウェアより後に、レート制限で拒否したリクエストを
+// 数えないようレート制限より後に登録する。ユーザー以外の認証主体や認証なしの操作は利用量には数えない。
+func Usage(meter *usage.Meter) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if op := ctx.Operation(); op != nil && op.OperationID != "" {
+			meter.RecordOperation(op.OperationID)
+		}
+		if id, ok := auth.UserIDFrom(ctx.Context()); ok {
+			meter.RecordCall(id)
+		}
+		next(ctx)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-huma-test/migrate"
+	"go-huma-test/model"
+	"os"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+)
+
+// 移行の適用状況
+const (
+	migrationApplied = "applied"
+	migrationPending = "pending"
+	migrationUnknown = "unknown"
+)
+
+// migrationResult はmigrateサブコマンドが出力する移行1件を表す構造体
+type migrationResult struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// newMigrateCommand はデータベースの移行を適用、確認するmigrateサブコマンドを生成する。
+// 結果はJSONで標準出力に書き出し、失敗した場合は終了コード1で終了する。
+func newMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply, roll back or list database migrations",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Args:  cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, o *model.Options) {
+			migrator, sqlDB := openMigrator(o.DatabaseURL)
+			applied, err := migrator.Up(cmd.Context())
+			_ = sqlDB.Close()
+			writeMigrations("applied", applied, err)
+		}),
+	})
+
+	var steps int
+	down := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the most recently applied migrations",
+		Args:  cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, o *model.Options) {
+			if steps < 1 {
+				fmt.Fprintln(os.Stderr, "--stepsには1以上を指定してください")
+				os.Exit(1)
+			}
+			migrator, sqlDB := openMigrator(o.DatabaseURL)
+			reverted, err := migrator.Down(cmd.Context(), steps)
+			_ = sqlDB.Close()
+			writeMigrations("reverted", reverted, err)
+		}),
+	}
+	down.Flags().IntVar(&steps, "steps", 1, "Number of migrations to roll back")
+	cmd.AddCommand(down)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "List migrations and whether each is applied",
+		Args:  cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, o *model.Options) {
+			migrator, sqlDB := openMigrator(o.DatabaseURL)
+			statuses, err := migrator.Status(cmd.Context())
+			_ = sqlDB.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			results := make([]migrationResult, 0, len(statuses))
+			for _, s := range statuses {
+				r := migrationResult{Version: s.Version, Name: s.Name, Status: migrationPending}
+				if !s.AppliedAt.IsZero() {
+					r.Status = migrationApplied
+					r.AppliedAt = &s.AppliedAt
+				}
+				if s.Unknown {
+					r.Status = migrationUnknown
+				}
+				results = append(results, r)
+			}
+			writeJSON(map[string]any{"migrations": results})
+		}),
+	})
+	return cmd
+}
+
+// calledAs はcmdかそのサブコマンドが実行されたかを返す
+func calledAs(cmd *cobra.Command) bool {
+	if cmd.CalledAs() != "" {
+		return true
+	}
+	for _, c := range cmd.Commands() {
+		if calledAs(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// openMigrator はdatabase-urlのデータベースを開いてMigratorを生成する。失敗した場合は終了コード1で終了する。
+func openMigrator(databaseURL string) (*migrate.Migrator, *sql.DB) {
+	dbPath, err := sqlitePath(databaseURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	sqlDB, err := openDB(dbPath, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	migrator, err := newMigrator(sqlDB)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return migrator, sqlDB
+}
+
+// writeMigrations は適用または戻した移行を出力する。errがある場合はそれまでに処理した移行を出力してから終了コード1で終了する。
+func writeMigrations(key string, migrations []migrate.Migration, err error) {
+	results := make([]migrationResult, 0, len(migrations))
+	for _, m := range migrations {
+		results = append(results, migrationResult{Version: m.Version, Name: m.Name})
+	}
+	writeJSON(map[string]any{key: results})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// writeJSON は値を整形したJSONで標準出力に書き出す
+func writeJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
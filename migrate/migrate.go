@@ -0,0 +1,336 @@
+// Package migrate は番号付きのSQLファイルによるデータベースのスキーマの移行を提供する。
+// 移行は"0002_add_tags.up.sql"のように番号と名前を付けたファイルで、戻す場合の".down.sql"を対にして置く。
+// 適用済みの移行はmigrationsテーブルに記録し、番号の順に未適用のものだけを適用する。
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// createTable は適用済みの移行を記録するテーブルを作成するSQL
+const createTable = `CREATE TABLE IF NOT EXISTS migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// fileName は移行のファイル名（番号_名前.up.sqlまたは番号_名前.down.sql）
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrIrreversible は戻すSQLのない移行を戻そうとした場合のエラー
+var ErrIrreversible = errors.New("戻せない移行です")
+
+// Migration は1つの移行を表す構造体
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	// Down は移行を戻すSQL。空の場合は戻せない
+	Down string
+}
+
+// Status は移行の適用状況を表す構造体
+type Status struct {
+	Version int64
+	Name    string
+	// AppliedAt は適用日時。未適用の場合はゼロ値
+	AppliedAt time.Time
+	// Unknown はデータベースに適用済みだが、このバージョンのアプリケーションにない移行か
+	Unknown bool
+}
+
+// Load はdirにある移行のファイルを読み込み、番号の順に返す
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("移行のファイルを読み込めません: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m := fileName.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("移行のファイル名は0001_name.up.sqlの形式にしてください: %s", e.Name())
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("移行の番号が不正です: %s", e.Name())
+		}
+		body, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("移行のファイルを読み込めません: %w", err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("移行の番号が重複しています: %d（%sと%s）", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("移行%d_%sの.up.sqlがありません", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return migrations, nil
+}
+
+// LegacyUpgrade は移行の仕組みを導入する前に作成したデータベースを、最初の移行を適用できる形に更新する関数
+type LegacyUpgrade func(ctx context.Context, tx *sql.Tx) error
+
+// Migrator はデータベースに移行を適用する
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	legacy     LegacyUpgrade
+}
+
+// New はMigratorの新しいインスタンスを生成する。migrationsは番号の順に並べる。
+func New(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// WithLegacyUpgrade はmigrationsテーブルがなく既存のテーブルがあるデータベースに、最初の移行の前にupgradeを実行するMigratorを返す。
+// upgradeは最初の移行と同じトランザクションで実行するため、移行に失敗した場合は一緒に戻る。
+func (m *Migrator) WithLegacyUpgrade(upgrade LegacyUpgrade) *Migrator {
+	c := *m
+	c.legacy = upgrade
+	return &c
+}
+
+// isLegacy はmigrationsテーブルがなく、移行の仕組みを導入する前に作成したテーブルがあるかを返す
+func (m *Migrator) isLegacy(ctx context.Context) (bool, error) {
+	var legacy bool
+	err := m.db.QueryRowContext(ctx, `SELECT NOT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'migrations')
+    AND EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%')`).Scan(&legacy)
+	if err != nil {
+		return false, fmt.Errorf("既存のテーブルの確認に失敗: %w", err)
+	}
+	return legacy, nil
+}
+
+// applied は適用済みの移行の番号と適用日時を返す。
+// 読み取り専用のデータベースでも確認できるよう、migrationsテーブルがない場合は作成せずに空を返す。
+func (m *Migrator) applied(ctx context.Context) (map[int64]Status, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'migrations')").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("migrationsテーブルの確認に失敗: %w", err)
+	}
+	if !exists {
+		return map[int64]Status{}, nil
+	}
+	rows, err := m.db.QueryContext(ctx, "SELECT version, name, applied_at FROM migrations")
+	if err != nil {
+		return nil, fmt.Errorf("適用済みの移行の取得に失敗: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	applied := make(map[int64]Status)
+	for rows.Next() {
+		var s Status
+		if err := rows.Scan(&s.Version, &s.Name, &s.AppliedAt); err != nil {
+			return nil, fmt.Errorf("適用済みの移行の取得に失敗: %w", err)
+		}
+		applied[s.Version] = s
+	}
+	return applied, rows.Err()
+}
+
+// Status はすべての移行の適用状況を番号の順に返す。
+// データベースに適用済みだがmigrationsにない移行は、新しいバージョンのアプリケーションが適用したものとしてUnknownにする。
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s, ok := applied[mig.Version]
+		if !ok {
+			s = Status{Version: mig.Version, Name: mig.Name}
+		}
+		statuses = append(statuses, s)
+		delete(applied, mig.Version)
+	}
+	for _, s := range applied {
+		s.Unknown = true
+		statuses = append(statuses, s)
+	}
+	slices.SortFunc(statuses, func(a, b Status) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return statuses, nil
+}
+
+// Pending は未適用の移行を番号の順に返す
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Up は未適用の移行を番号の順に適用し、適用した移行を返す。
+// 移行ごとにトランザクションで適用と記録を行うため、失敗した移行より前の移行は適用されたままになる。
+// データベースにこのアプリケーションの知らない移行が適用されている場合は、古いバージョンで開いたものとしてエラーにする。
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, s := range statuses {
+		if s.Unknown {
+			return nil, fmt.Errorf("データベースにこのバージョンにない移行%d_%sが適用されています。新しいバージョンで起動するか、そのバージョンのmigrate downで戻してください", s.Version, s.Name)
+		}
+		if s.AppliedAt.IsZero() {
+			i := slices.IndexFunc(m.migrations, func(mig Migration) bool { return mig.Version == s.Version })
+			pending = append(pending, m.migrations[i])
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	legacy := false
+	if m.legacy != nil {
+		if legacy, err = m.isLegacy(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := m.db.ExecContext(ctx, createTable); err != nil {
+		return nil, fmt.Errorf("migrationsテーブルの作成に失敗: %w", err)
+	}
+
+	var done []Migration
+	for i, mig := range pending {
+		var upgrade LegacyUpgrade
+		if legacy && i == 0 {
+			upgrade = m.legacy
+		}
+		ok, err := m.apply(ctx, mig, upgrade)
+		if err != nil {
+			return done, fmt.Errorf("移行%d_%sの適用に失敗: %w", mig.Version, mig.Name, err)
+		}
+		if ok {
+			done = append(done, mig)
+		}
+	}
+	return done, nil
+}
+
+// apply は1つの移行を適用して記録する。upgradeがnilでない場合は移行の前に同じトランザクションで実行する。
+// 同時に起動した別のプロセスが先に適用した場合は何もせずにfalseを返す。
+func (m *Migrator) apply(ctx context.Context, mig Migration, upgrade LegacyUpgrade) (bool, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// 記録を先に書き込んで書き込みのロックを取り、別のプロセスとの二重の適用を防ぐ
+	res, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO migrations (version, name) VALUES (?, ?)", mig.Version, mig.Name)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	if upgrade != nil {
+		if err := upgrade(ctx, tx); err != nil {
+			return false, fmt.Errorf("既存のデータベースの更新に失敗: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Down は適用済みの移行を新しいものから順にsteps件戻し、戻した移行を返す
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, s := range slices.Backward(statuses) {
+		if len(done) == steps {
+			break
+		}
+		if s.AppliedAt.IsZero() {
+			continue
+		}
+		if s.Unknown {
+			return done, fmt.Errorf("移行%d_%sはこのバージョンにないため戻せません", s.Version, s.Name)
+		}
+		i := slices.IndexFunc(m.migrations, func(mig Migration) bool { return mig.Version == s.Version })
+		mig := m.migrations[i]
+		if mig.Down == "" {
+			return done, fmt.Errorf("移行%d_%s: %w", mig.Version, mig.Name, ErrIrreversible)
+		}
+		if err := m.revert(ctx, mig); err != nil {
+			return done, fmt.Errorf("移行%d_%sを戻せません: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// revert は1つの移行を戻して記録を消す
+func (m *Migrator) revert(ctx context.Context, mig Migration) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM migrations WHERE version = ?", mig.Version); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,46 @@
+package model
+
+// ActivityChange はフィールドの変更前後の値を表す構造体
+type ActivityChange struct {
+	From any `json:"from" doc:"変更前の値"`
+	To   any `json:"to" doc:"変更後の値"`
+}
+
+// ActivityResponse はアクティビティログの1件を表す構造体
+type ActivityResponse struct {
+	ID        int64                     `json:"id" example:"1" doc:"アクティビティのID"`
+	TodoID    int64                     `json:"todo_id" example:"1" doc:"対象TodoのID"`
+	Actor     string                    `json:"actor" example:"9f86d081884c7d65" doc:"変更の実行者。リクエストによらない変更はsystem"`
+	Action    string                    `json:"action" enum:"create,update,delete,toggle,move,escalate,archive,unarchive,tag" example:"update" doc:"変更の種類"`
+	Diff      map[string]ActivityChange `json:"diff" doc:"変更されたフィールドごとの変更前後の値"`
+	CreatedAt string                    `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"変更日時"`
+}
+
+// ActivityPage はアクティビティログのページを表す構造体
+type ActivityPage struct {
+	Activities []ActivityResponse `json:"activities" doc:"新しい順のアクティビティのリスト"`
+	NextCursor *int64             `json:"next_cursor,omitempty" example:"20" doc:"次のページを取得するためのカーソル。最後のページでは省略される"`
+}
+
+// ListTodoHistoryInput はTodoの変更履歴取得のリクエストパラメータを表す構造体
+type ListTodoHistoryInput struct {
+	TodoID int64 `path:"id" doc:"対象TodoのID。削除済みのTodoも指定できる"`
+	Cursor int64 `query:"cursor" minimum:"0" doc:"前のページのnext_cursor。指定したIDより前のアクティビティを返す"`
+	Limit  int64 `query:"limit" minimum:"1" maximum:"100" default:"20" doc:"1ページあたりの件数"`
+}
+
+// ListTodoHistoryOutput はTodoの変更履歴取得のレスポンスを表す構造体
+type ListTodoHistoryOutput struct {
+	Body ActivityPage
+}
+
+// ListActivityInput は全体のアクティビティフィード取得のリクエストパラメータを表す構造体
+type ListActivityInput struct {
+	Cursor int64 `query:"cursor" minimum:"0" doc:"前のページのnext_cursor。指定したIDより前のアクティビティを返す"`
+	Limit  int64 `query:"limit" minimum:"1" maximum:"100" default:"20" doc:"1ページあたりの件数"`
+}
+
+// ListActivityOutput は全体のアクティビティフィード取得のレスポンスを表す構造体
+type ListActivityOutput struct {
+	Body ActivityPage
+}
@@ -0,0 +1,62 @@
+package model
+
+// 診断結果の重要度
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+)
+
+// GetDBAdvisorInput はクエリプランの診断のリクエストパラメータを表す構造体
+type GetDBAdvisorInput struct {
+	Analyze bool  `query:"analyze" default:"false" doc:"trueの場合は診断の前にANALYZEを実行し、クエリプランナーが使う統計情報を更新する"`
+	MinRows int64 `query:"min_rows" minimum:"0" default:"1000" doc:"全件走査やインデックスの不足をwarningとして報告する行数の下限。これより少ないテーブルはinfoとして報告する"`
+}
+
+// TableStatsResponse はテーブルの行数を表す構造体
+type TableStatsResponse struct {
+	Name string `json:"name" example:"todos" doc:"テーブル名"`
+	Rows int64  `json:"rows" example:"12000" doc:"行数"`
+}
+
+// QueryPlanStep はEXPLAIN QUERY PLANの1行を表す構造体
+type QueryPlanStep struct {
+	ID     int64  `json:"id" example:"2" doc:"ステップのID"`
+	Parent int64  `json:"parent" example:"0" doc:"親ステップのID"`
+	Detail string `json:"detail" example:"SEARCH todos USING INDEX idx_todos_owner_id (owner_id=?)" doc:"ステップの内容"`
+}
+
+// AdvisorFinding はクエリプランから見つかった問題を表す構造体
+type AdvisorFinding struct {
+	Severity string `json:"severity" enum:"info,warning" example:"warning" doc:"重要度"`
+	Table    string `json:"table,omitempty" example:"todos" doc:"対象のテーブル"`
+	Rows     int64  `json:"rows,omitempty" example:"12000" doc:"対象のテーブルの行数"`
+	Detail   string `json:"detail" example:"SCAN todos" doc:"問題のあるステップの内容"`
+	Message  string `json:"message" example:"todosを全件走査しています" doc:"問題の説明"`
+}
+
+// QueryPlanResponse は頻繁に実行されるクエリの実行計画と診断結果を表す構造体
+type QueryPlanResponse struct {
+	Name     string           `json:"name" example:"ListTodos" doc:"クエリ名"`
+	Plan     []QueryPlanStep  `json:"plan" doc:"実行計画"`
+	Findings []AdvisorFinding `json:"findings" doc:"実行計画から見つかった問題"`
+}
+
+// MissingIndexResponse はインデックスのない外部キーを表す構造体
+type MissingIndexResponse struct {
+	Severity   string `json:"severity" enum:"info,warning" example:"warning" doc:"重要度"`
+	Table      string `json:"table" example:"todo_shares" doc:"外部キーを持つテーブル"`
+	Column     string `json:"column" example:"todo_id" doc:"外部キーの列"`
+	References string `json:"references" example:"todos" doc:"参照先のテーブル"`
+	Rows       int64  `json:"rows" example:"12000" doc:"外部キーを持つテーブルの行数"`
+	Suggestion string `json:"suggestion" example:"CREATE INDEX idx_todo_shares_todo_id ON todo_shares(todo_id);" doc:"作成を推奨するインデックス"`
+}
+
+// GetDBAdvisorOutput はクエリプランの診断のレスポンスを表す構造体
+type GetDBAdvisorOutput struct {
+	Body struct {
+		Analyzed       bool                   `json:"analyzed" doc:"診断の前にANALYZEを実行したか"`
+		Tables         []TableStatsResponse   `json:"tables" doc:"テーブルごとの行数"`
+		Queries        []QueryPlanResponse    `json:"queries" doc:"頻繁に実行されるクエリごとの実行計画と診断結果"`
+		MissingIndexes []MissingIndexResponse `json:"missing_indexes" doc:"インデックスのない外部キー"`
+	}
+}
@@ -0,0 +1,54 @@
+package model
+
+// アジェンダの項目の種類
+const (
+	// AgendaDue はその日が期限のTodo
+	AgendaDue = "due"
+	// AgendaReminder はその日に通知するリマインダー
+	AgendaReminder = "reminder"
+	// AgendaRecurrence は繰り返しのTodoを完了したときに作成される、その日が期限の次の回
+	AgendaRecurrence = "recurrence"
+)
+
+// アジェンダの出力形式
+const (
+	AgendaFormatJSON     = "json"
+	AgendaFormatMarkdown = "markdown"
+)
+
+// AgendaInput はアジェンダ取得のリクエストパラメータを表す構造体
+type AgendaInput struct {
+	Date   string `query:"date" format:"date" example:"2024-01-01" doc:"アジェンダの日付。省略した場合はタイムゾーンでの今日"`
+	TZ     string `query:"tz" example:"Asia/Tokyo" doc:"日付の範囲と時刻の表示に使うタイムゾーン（IANA名）。省略した場合はX-Timezoneヘッダー、それもなければサーバーの既定のタイムゾーン"`
+	Format string `query:"format" enum:"json,markdown" default:"json" doc:"出力形式。markdownは印刷や共有に向けたチェックリストを返す"`
+}
+
+// AgendaItem はアジェンダの1項目を表す構造体
+type AgendaItem struct {
+	Kind      string `json:"kind" enum:"due,reminder,recurrence" example:"due" doc:"項目の種類。dueは期限、reminderはリマインダー、recurrenceは繰り返しのTodoの次の回（前の回を完了すると作成される）"`
+	At        string `json:"at" example:"2024-01-01T09:00:00+09:00" doc:"項目の日時。タイムゾーンのオフセット付き"`
+	Time      string `json:"time" example:"09:00" doc:"タイムゾーンでの時刻"`
+	TodoID    int64  `json:"todo_id" example:"1" doc:"対象のTodoのID"`
+	Title     string `json:"title" example:"買い物" doc:"Todoのタイトル"`
+	Completed bool   `json:"completed" doc:"Todoが完了しているか"`
+	ProjectID *int64 `json:"project_id,omitempty" example:"1" doc:"Todoが属するプロジェクトのID"`
+	Priority  int64  `json:"priority" example:"0" doc:"Todoの優先度"`
+	// ReminderID 以降はリマインダーの場合のみ設定する
+	ReminderID *int64  `json:"reminder_id,omitempty" example:"1" doc:"リマインダーのID。reminderの場合のみ"`
+	Channel    *string `json:"channel,omitempty" example:"email" doc:"リマインダーの通知チャネル。reminderの場合のみ"`
+	Sent       *bool   `json:"sent,omitempty" doc:"リマインダーを通知済みか。reminderの場合のみ"`
+}
+
+// AgendaResponse はアジェンダを表す構造体
+type AgendaResponse struct {
+	Date     string       `json:"date" example:"2024-01-01" doc:"アジェンダの日付"`
+	Timezone string       `json:"timezone" example:"Asia/Tokyo" doc:"日付の範囲と時刻に使ったタイムゾーン"`
+	Items    []AgendaItem `json:"items" doc:"時刻の順の項目。同じ時刻では期限、リマインダー、繰り返しの次の回の順"`
+}
+
+// AgendaOutput はアジェンダ取得のレスポンスを表す構造体
+type AgendaOutput struct {
+	ContentType     string `header:"Content-Type"`
+	ContentLanguage string `header:"Content-Language"`
+	Body            []byte
+}
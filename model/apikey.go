@@ -0,0 +1,51 @@
+package model
+
+// APIKeyResponse はAPIキーのレスポンスを表す構造体。キーそのものは含まない。
+type APIKeyResponse struct {
+	ID         int64   `json:"id" example:"1" doc:"APIキーのID"`
+	Name       string  `json:"name" example:"CI" doc:"APIキーの名前"`
+	Prefix     string  `json:"prefix" example:"tk_AbCdEfGh" doc:"キーを識別するための先頭部分"`
+	CreatedBy  string  `json:"created_by" example:"alice" doc:"APIキーを発行した認証主体"`
+	LastUsedAt *string `json:"last_used_at,omitempty" example:"2024-01-01T00:00:00Z" doc:"最後に使用された日時（1分単位で記録）"`
+	RevokedAt  *string `json:"revoked_at,omitempty" example:"2024-01-01T00:00:00Z" doc:"失効日時"`
+	CreatedAt  string  `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"発行日時"`
+}
+
+// ListAPIKeysInput はAPIキー一覧取得のリクエストパラメータを表す構造体
+type ListAPIKeysInput struct {
+	IncludeRevoked bool `query:"include_revoked" doc:"失効したAPIキーも含める"`
+}
+
+// ListAPIKeysOutput はAPIキー一覧取得のレスポンスを表す構造体
+type ListAPIKeysOutput struct {
+	Body struct {
+		APIKeys []APIKeyResponse `json:"api_keys" doc:"APIキーのリスト"`
+	}
+}
+
+// CreateAPIKeyInput はAPIキー発行のリクエストボディを表す構造体
+type CreateAPIKeyInput struct {
+	Body struct {
+		Name string `json:"name" minLength:"1" maxLength:"100" doc:"APIキーの用途がわかる名前"`
+	}
+}
+
+// CreateAPIKeyOutput はAPIキー発行のレスポンスを表す構造体
+type CreateAPIKeyOutput struct {
+	Body struct {
+		APIKeyResponse
+		Key string `json:"key" example:"tk_AbCdEfGhIjKlMnOpQrStUvWxYz0123456789abcdefg" doc:"APIキー。この応答でのみ返されるため、安全な場所に保存してください"`
+	}
+}
+
+// RevokeAPIKeyInput はAPIキー失効のリクエストパラメータを表す構造体
+type RevokeAPIKeyInput struct {
+	ID int64 `path:"id" doc:"APIキーのID"`
+}
+
+// RevokeAPIKeyOutput はAPIキー失効のレスポンスを表す構造体
+type RevokeAPIKeyOutput struct {
+	Body struct {
+		Message string `json:"message" example:"API key revoked successfully" doc:"失効結果メッセージ"`
+	}
+}
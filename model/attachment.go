@@ -0,0 +1,46 @@
+package model
+
+import "github.com/danielgtaylor/huma/v2"
+
+// AttachmentResponse は添付ファイルのメタデータのレスポンスを表す構造体
+type AttachmentResponse struct {
+	ID          int64  `json:"id" example:"1" doc:"添付ファイルのID"`
+	TodoID      int64  `json:"todo_id" example:"1" doc:"対象TodoのID"`
+	Filename    string `json:"filename" example:"receipt.pdf" doc:"ファイル名"`
+	ContentType string `json:"content_type" example:"application/pdf" doc:"ファイルのContent-Type"`
+	Size        int64  `json:"size" example:"102400" doc:"ファイルサイズ（バイト）"`
+	CreatedAt   string `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"アップロード日時"`
+}
+
+// AttachmentForm は添付ファイルのアップロードフォームを表す構造体
+type AttachmentForm struct {
+	File huma.FormFile `form:"file" required:"true" doc:"添付するファイル"`
+}
+
+// UploadAttachmentInput は添付ファイルのアップロードのリクエストパラメータとフォームを表す構造体
+type UploadAttachmentInput struct {
+	TodoID  int64 `path:"id" doc:"対象TodoのID"`
+	RawBody huma.MultipartFormFiles[AttachmentForm]
+}
+
+// UploadAttachmentOutput は添付ファイルのアップロードのレスポンスを表す構造体
+type UploadAttachmentOutput struct {
+	Body AttachmentResponse
+}
+
+// ListAttachmentsInput は添付ファイル一覧取得のリクエストパラメータを表す構造体
+type ListAttachmentsInput struct {
+	TodoID int64 `path:"id" doc:"対象TodoのID"`
+}
+
+// ListAttachmentsOutput は添付ファイル一覧取得のレスポンスを表す構造体
+type ListAttachmentsOutput struct {
+	Body struct {
+		Attachments []AttachmentResponse `json:"attachments" doc:"添付ファイルのリスト"`
+	}
+}
+
+// DownloadAttachmentInput は添付ファイルのダウンロードのリクエストパラメータを表す構造体
+type DownloadAttachmentInput struct {
+	ID int64 `path:"id" doc:"添付ファイルのID"`
+}
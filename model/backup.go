@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// BackupResponse はデータベースのバックアップ1件を表す構造体
+type BackupResponse struct {
+	Name      string    `json:"name" example:"todos-20240101T030000.000Z.db" doc:"バックアップのファイル名"`
+	Size      int64     `json:"size" example:"1048576" doc:"バックアップのサイズ（バイト）"`
+	CreatedAt time.Time `json:"created_at" doc:"バックアップの作成日時"`
+}
+
+// CreateBackupOutput はバックアップ作成のレスポンスを表す構造体
+type CreateBackupOutput struct {
+	Body BackupResponse
+}
+
+// ListBackupsOutput はバックアップ一覧取得のレスポンスを表す構造体
+type ListBackupsOutput struct {
+	Body struct {
+		Backups []BackupResponse `json:"backups" doc:"新しい順のバックアップのリスト"`
+	}
+}
+
+// DownloadBackupInput はバックアップのダウンロードのリクエストパラメータを表す構造体
+type DownloadBackupInput struct {
+	Name string `path:"name" pattern:"^todos-[0-9]{8}T[0-9]{6}\\.[0-9]{3}Z\\.db$" example:"todos-20240101T030000.000Z.db" doc:"バックアップのファイル名"`
+}
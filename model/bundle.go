@@ -0,0 +1,85 @@
+package model
+
+import "time"
+
+// BundleVersion はエクスポートバンドルの形式バージョン
+const BundleVersion = 1
+
+// ProjectBundle はプロジェクトとその配下のデータをまとめたエクスポート形式を表す構造体。
+// 各要素のIDはバンドル内でのみ有効な参照番号であり、インポート時に新しいIDへ振り直される。
+type ProjectBundle struct {
+	Version int               `json:"version" minimum:"1" maximum:"1" example:"1" doc:"バンドルの形式バージョン"`
+	Project BundleProject     `json:"project" doc:"プロジェクト"`
+	Todos   []BundleTodo      `json:"todos" maxItems:"10000" doc:"プロジェクトに所属するTodoのリスト"`
+	Meta    *BundleExportMeta `json:"meta,omitempty" doc:"エクスポート時の情報。インポート時は無視される"`
+}
+
+// BundleExportMeta はエクスポート時の情報を表す構造体
+type BundleExportMeta struct {
+	ExportedAt string `json:"exported_at" example:"2024-01-01T00:00:00Z" doc:"エクスポート日時"`
+	SourceID   int64  `json:"source_id" example:"1" doc:"エクスポート元のプロジェクトID"`
+}
+
+// BundleProject はバンドル内のプロジェクトを表す構造体
+type BundleProject struct {
+	Name           string         `json:"name" minLength:"1" maxLength:"100" doc:"プロジェクトの名前"`
+	Description    *string        `json:"description,omitempty" maxLength:"1000" doc:"プロジェクトの説明"`
+	MetadataSchema map[string]any `json:"metadata_schema,omitempty" doc:"所属するTodoのmetadataを検証するJSON Schema"`
+	WIPLimit       *int64         `json:"wip_limit,omitempty" minimum:"1" doc:"未完了のTodoの件数の上限（WIP制限）。インポートするTodoには適用しない"`
+}
+
+// BundleTodo はバンドル内のTodoを表す構造体
+type BundleTodo struct {
+	Ref         int64          `json:"ref" example:"1" doc:"バンドル内での参照番号"`
+	Title       string         `json:"title" minLength:"1" maxLength:"200" doc:"Todoのタイトル"`
+	Description *string        `json:"description,omitempty" maxLength:"1000" doc:"Todoの詳細説明"`
+	Completed   bool           `json:"completed,omitempty" doc:"完了状態"`
+	Assignee    *string        `json:"assignee,omitempty" maxLength:"200" doc:"担当者の認証主体"`
+	Metadata    map[string]any `json:"metadata,omitempty" doc:"クライアントが自由に使うJSONオブジェクト"`
+	Priority    int64          `json:"priority,omitempty" minimum:"0" maximum:"3" doc:"優先度（0: なし、1: 低、2: 中、3: 高）"`
+	Location
+	Schedule
+	NextRef  *int64          `json:"next_ref,omitempty" doc:"繰り返しにより生成された次回のTodoの参照番号"`
+	Tags     []string        `json:"tags,omitempty" maxItems:"20" doc:"Todoに付けたタグの名前。インポートするユーザーのタグとして付け、まだないタグは作成する"`
+	Subtasks []BundleSubtask `json:"subtasks,omitempty" maxItems:"1000" doc:"サブタスク（チェックリスト）のリスト"`
+	Comments []BundleComment `json:"comments,omitempty" maxItems:"10000" doc:"コメントのリスト"`
+}
+
+// BundleSubtask はバンドル内のサブタスクを表す構造体
+type BundleSubtask struct {
+	Title     string `json:"title" minLength:"1" maxLength:"200" doc:"サブタスクのタイトル"`
+	Completed bool   `json:"completed,omitempty" doc:"完了状態"`
+}
+
+// BundleComment はバンドル内のコメントを表す構造体
+type BundleComment struct {
+	Author    string     `json:"author" minLength:"1" maxLength:"100" doc:"コメントの投稿者"`
+	Body      string     `json:"body" minLength:"1" maxLength:"4000" doc:"コメント本文"`
+	CreatedAt *time.Time `json:"created_at,omitempty" doc:"投稿日時。省略した場合はインポート日時になる"`
+}
+
+// ExportProjectInput はプロジェクトのエクスポートのリクエストパラメータを表す構造体
+type ExportProjectInput struct {
+	ID int64 `path:"id" doc:"プロジェクトのID"`
+}
+
+// ExportProjectOutput はプロジェクトのエクスポートのレスポンスを表す構造体
+type ExportProjectOutput struct {
+	ContentDisposition string `header:"Content-Disposition"`
+	Body               ProjectBundle
+}
+
+// ImportProjectInput はプロジェクトのインポートのリクエストパラメータとボディを表す構造体
+type ImportProjectInput struct {
+	DryRun bool `query:"dry_run" doc:"trueの場合、変更をコミットせずにインポート結果のみを返す"`
+	Body   ProjectBundle
+}
+
+// ImportProjectOutput はプロジェクトのインポートのレスポンスを表す構造体
+type ImportProjectOutput struct {
+	Body struct {
+		DryRun  bool             `json:"dry_run" example:"false" doc:"dry_runで実行されたか。trueの場合は何も変更されていない"`
+		Project ProjectResponse  `json:"project" doc:"作成されたプロジェクト"`
+		TodoIDs map[string]int64 `json:"todo_ids" doc:"バンドル内の参照番号から新しいTodoのIDへの対応"`
+	}
+}
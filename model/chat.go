@@ -0,0 +1,67 @@
+package model
+
+// ChatChannelResponse はプロジェクトに設定されたチャットの送信先のレスポンスを表す構造体
+type ChatChannelResponse struct {
+	ProjectID  int64             `json:"project_id" example:"1" doc:"プロジェクトのID"`
+	Provider   string            `json:"provider" example:"discord" doc:"チャットの種類"`
+	WebhookURL string            `json:"webhook_url" example:"https://discord.com/api/webhooks/123/***" doc:"投稿先のWebhook URL。トークンは伏せて返す"`
+	Events     []string          `json:"events" example:"todo.created" doc:"投稿するTodoのイベント。リマインダーと優先度の引き上げの通知は設定によらず投稿する"`
+	Templates  map[string]string `json:"templates" doc:"イベントの種類ごとのメッセージのテンプレート（Goのtext/template）"`
+	CreatedAt  string            `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"作成日時"`
+	UpdatedAt  string            `json:"updated_at" example:"2024-01-01T00:00:00Z" doc:"更新日時"`
+}
+
+// ListChatChannelsInput はチャットの送信先一覧取得のリクエストパラメータを表す構造体
+type ListChatChannelsInput struct {
+	ID int64 `path:"id" doc:"プロジェクトのID"`
+}
+
+// ListChatChannelsOutput はチャットの送信先一覧取得のレスポンスを表す構造体
+type ListChatChannelsOutput struct {
+	Body struct {
+		Channels []ChatChannelResponse `json:"channels" doc:"チャットの送信先のリスト"`
+	}
+}
+
+// PutChatChannelInput はチャットの送信先設定のリクエストパラメータとボディを表す構造体
+type PutChatChannelInput struct {
+	ID       int64  `path:"id" doc:"プロジェクトのID"`
+	Provider string `path:"provider" enum:"discord,slack,teams" doc:"チャットの種類"`
+	Body     struct {
+		WebhookURL string            `json:"webhook_url" format:"uri" maxLength:"2048" doc:"DiscordのWebhook URL、Slackの着信Webhook URL、またはTeamsの受信Webhook（*.webhook.office.com）かワークフロー（*.logic.azure.com、*.environment.api.powerplatform.com）のURL"`
+		Events     []string          `json:"events,omitempty" enum:"todo.created,todo.updated,todo.moved,todo.completed,todo.assigned,todo.overdue" uniqueItems:"true" doc:"投稿するTodoのイベント。省略するとすべてのイベントを投稿する。空の配列の場合はリマインダーと優先度の引き上げの通知だけを投稿する。todo.completedとtodo.assignedを購読している場合、その更新はtodo.updatedの代わりに投稿する"`
+		Templates  map[string]string `json:"templates,omitempty" doc:"イベントの種類（todo.completedなど）ごとのメッセージのテンプレート（Goのtext/template）。.Title、.Message、.Project、.Assignee、.DueAtなどの通知のフィールドと、日時を書式化する.Fを参照できる。テンプレートのないイベントは既定の形式で投稿する"`
+	}
+}
+
+// PutChatChannelOutput はチャットの送信先設定のレスポンスを表す構造体
+type PutChatChannelOutput struct {
+	Body ChatChannelResponse
+}
+
+// TestChatChannelInput はチャットの送信先への試験投稿のリクエストパラメータを表す構造体
+type TestChatChannelInput struct {
+	ID       int64  `path:"id" doc:"プロジェクトのID"`
+	Provider string `path:"provider" enum:"discord,slack,teams" doc:"チャットの種類"`
+	Event    string `query:"event" enum:"todo.created,todo.updated,todo.moved,todo.completed,todo.assigned,todo.overdue" default:"todo.created" doc:"見本の通知のイベントの種類。このイベントのテンプレートがあれば適用する"`
+}
+
+// TestChatChannelOutput はチャットの送信先への試験投稿のレスポンスを表す構造体
+type TestChatChannelOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Test message posted successfully" doc:"投稿結果メッセージ"`
+	}
+}
+
+// DeleteChatChannelInput はチャットの送信先削除のリクエストパラメータを表す構造体
+type DeleteChatChannelInput struct {
+	ID       int64  `path:"id" doc:"プロジェクトのID"`
+	Provider string `path:"provider" enum:"discord,slack,teams" doc:"チャットの種類"`
+}
+
+// DeleteChatChannelOutput はチャットの送信先削除のレスポンスを表す構造体
+type DeleteChatChannelOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Chat channel deleted successfully" doc:"削除結果メッセージ"`
+	}
+}
@@ -0,0 +1,52 @@
+package model
+
+// CommentResponse はコメントのレスポンスを表す構造体
+type CommentResponse struct {
+	ID        int64  `json:"id" example:"1" doc:"コメントのID"`
+	TodoID    int64  `json:"todo_id" example:"1" doc:"対象TodoのID"`
+	Author    string `json:"author" example:"tanaka" doc:"コメントの投稿者"`
+	Body      string `json:"body" example:"牛乳は低脂肪のものでお願いします" doc:"コメント本文"`
+	CreatedAt string `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"投稿日時"`
+}
+
+// ListCommentsInput はコメント一覧取得のリクエストパラメータを表す構造体
+type ListCommentsInput struct {
+	TodoID int64 `path:"id" doc:"対象TodoのID"`
+	Cursor int64 `query:"cursor" minimum:"0" doc:"前のページのnext_cursor。指定したIDより後のコメントを返す"`
+	Limit  int64 `query:"limit" minimum:"1" maximum:"100" default:"20" doc:"1ページあたりの件数"`
+}
+
+// ListCommentsOutput はコメント一覧取得のレスポンスを表す構造体
+type ListCommentsOutput struct {
+	Body struct {
+		Comments   []CommentResponse `json:"comments" doc:"投稿日時の古い順のコメントのリスト"`
+		NextCursor *int64            `json:"next_cursor,omitempty" example:"20" doc:"次のページを取得するためのカーソル。最後のページでは省略される"`
+	}
+}
+
+// CreateCommentInput はコメント投稿のリクエストパラメータとボディを表す構造体
+type CreateCommentInput struct {
+	TodoID int64 `path:"id" doc:"対象TodoのID"`
+	Body   struct {
+		Author string `json:"author" minLength:"1" maxLength:"100" doc:"コメントの投稿者"`
+		Body   string `json:"body" minLength:"1" maxLength:"4000" doc:"コメント本文"`
+	}
+}
+
+// CreateCommentOutput はコメント投稿のレスポンスを表す構造体
+type CreateCommentOutput struct {
+	Body CommentResponse
+}
+
+// DeleteCommentInput はコメント削除のリクエストパラメータを表す構造体
+type DeleteCommentInput struct {
+	TodoID    int64 `path:"id" doc:"対象TodoのID"`
+	CommentID int64 `path:"comment_id" doc:"コメントのID"`
+}
+
+// DeleteCommentOutput はコメント削除のレスポンスを表す構造体
+type DeleteCommentOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Comment deleted successfully" doc:"削除結果メッセージ"`
+	}
+}
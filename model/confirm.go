@@ -0,0 +1,13 @@
+package model
+
+// ConfirmationParams は2段階で実行する操作の確認トークンを受け付けるリクエストパラメータを表す構造体
+type ConfirmationParams struct {
+	ConfirmationToken string `header:"X-Confirmation-Token" doc:"1回目の呼び出しで返された確認トークン。省略すると操作を実行せず、影響範囲と確認トークンを返す"`
+}
+
+// ConfirmationResponse は操作の実行前に返す影響範囲と確認トークンを表す構造体
+type ConfirmationResponse struct {
+	Token     string           `json:"token" doc:"操作を実行する際にX-Confirmation-Tokenヘッダーで送る確認トークン"`
+	ExpiresAt string           `json:"expires_at" example:"2024-01-01T00:05:00Z" doc:"確認トークンの有効期限"`
+	Impact    map[string]int64 `json:"impact" example:"{\"todos\":12}" doc:"操作で削除されるリソースの件数。実行時に件数が変わっている場合は409を返す"`
+}
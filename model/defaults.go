@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// TodoDefaults はTodoの作成時にクライアントが省略した項目に使う既定値を表す構造体
+type TodoDefaults struct {
+	Priority  *int64         `json:"priority,omitempty" minimum:"0" maximum:"3" doc:"priorityを省略した場合の優先度"`
+	DueOffset *string        `json:"due_offset,omitempty" maxLength:"32" example:"72h" doc:"due_atを省略した場合に、作成日時から期限までの時間（72hや90mなどの形式）"`
+	Metadata  map[string]any `json:"metadata,omitempty" doc:"metadataを省略した場合のmetadata。クライアントが決める項目の既定値に使う"`
+	Tags      []string       `json:"tags,omitempty" maxItems:"20" doc:"tagsを省略した場合に付けるタグの名前。まだないタグはTodoの作成時に作成する"`
+}
+
+// Resolve は期限までの時間が1秒以上の時間として解析できることを検証する
+func (d *TodoDefaults) Resolve(_ huma.Context, prefix *huma.PathBuffer) []error {
+	if d.DueOffset == nil {
+		return nil
+	}
+	if offset, err := time.ParseDuration(*d.DueOffset); err != nil || offset < time.Second {
+		return []error{&huma.ErrorDetail{
+			Location: prefix.With("due_offset"),
+			Message:  "72hや90mなどの形式で1秒以上の時間を指定してください",
+			Value:    *d.DueOffset,
+		}}
+	}
+	return nil
+}
+
+// TodoDefaultsResponse は既定値のレスポンスを表す構造体
+type TodoDefaultsResponse struct {
+	ProjectID *int64       `json:"project_id,omitempty" doc:"既定値を設定したプロジェクトのID。全体の既定値の場合は省略"`
+	Defaults  TodoDefaults `json:"defaults" doc:"設定した既定値"`
+	Effective TodoDefaults `json:"effective" doc:"Todoの作成時に使う既定値。プロジェクトの既定値で指定していない項目は全体の既定値になる"`
+	UpdatedAt *string      `json:"updated_at,omitempty" doc:"最終更新日時。設定していない場合は省略"`
+}
+
+// TodoDefaultsOutput は既定値の取得と更新のレスポンスを表す構造体
+type TodoDefaultsOutput struct {
+	Body TodoDefaultsResponse
+}
+
+// UpdateTodoDefaultsInput は全体の既定値の更新のリクエストボディを表す構造体
+type UpdateTodoDefaultsInput struct {
+	Body TodoDefaults
+}
+
+// ProjectTodoDefaultsInput はプロジェクトの既定値の取得と削除のリクエストパラメータを表す構造体
+type ProjectTodoDefaultsInput struct {
+	ID int64 `path:"id" doc:"プロジェクトのID"`
+}
+
+// UpdateProjectTodoDefaultsInput はプロジェクトの既定値の更新のリクエストパラメータとボディを表す構造体
+type UpdateProjectTodoDefaultsInput struct {
+	ID   int64 `path:"id" doc:"プロジェクトのID"`
+	Body TodoDefaults
+}
+
+// DeleteTodoDefaultsOutput はプロジェクトの既定値の削除のレスポンスを表す構造体
+type DeleteTodoDefaultsOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Todo defaults deleted successfully" doc:"削除結果メッセージ"`
+	}
+}
@@ -0,0 +1,60 @@
+package model
+
+import "go-huma-test/ot"
+
+// DescriptionRevision は説明に適用した1つの操作を表す構造体
+type DescriptionRevision struct {
+	Revision  int64        `json:"revision" example:"3" doc:"操作を適用した後の版"`
+	Ops       ot.Operation `json:"ops" doc:"適用した操作"`
+	Actor     string       `json:"actor" example:"user:1" doc:"操作の実行者。共同編集以外の更新による変更はsystem"`
+	CreatedAt string       `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"適用日時"`
+}
+
+// DescriptionState は共同編集する説明の現在の状態を表す構造体
+type DescriptionState struct {
+	TodoID      int64  `json:"todo_id" example:"1" doc:"TodoのID"`
+	Revision    int64  `json:"revision" example:"3" doc:"説明の現在の版。操作を送信するときにbase_revisionとして指定する"`
+	Description string `json:"description" example:"牛乳を2本買う" doc:"説明の現在の内容"`
+}
+
+// GetDescriptionInput は説明の状態取得のリクエストパラメータを表す構造体
+type GetDescriptionInput struct {
+	ID int64 `path:"id" doc:"TodoのID"`
+}
+
+// GetDescriptionOutput は説明の状態取得のレスポンスを表す構造体
+type GetDescriptionOutput struct {
+	Body DescriptionState
+}
+
+// ListDescriptionOpsInput は説明の操作履歴取得のリクエストパラメータを表す構造体
+type ListDescriptionOpsInput struct {
+	ID    int64 `path:"id" doc:"TodoのID"`
+	Since int64 `query:"since" required:"true" minimum:"0" doc:"この版より後に適用された操作を返す"`
+}
+
+// ListDescriptionOpsOutput は説明の操作履歴取得のレスポンスを表す構造体
+type ListDescriptionOpsOutput struct {
+	Body struct {
+		DescriptionState
+		Revisions []DescriptionRevision `json:"revisions" doc:"sinceより後に適用された操作。版の順"`
+	}
+}
+
+// EditDescriptionInput は説明への操作の送信のリクエストパラメータとボディを表す構造体
+type EditDescriptionInput struct {
+	ID   int64 `path:"id" doc:"TodoのID"`
+	Body struct {
+		BaseRevision int64        `json:"base_revision" minimum:"0" doc:"操作の基にした説明の版"`
+		Ops          ot.Operation `json:"ops" minItems:"1" maxItems:"1000" doc:"基にした版の説明の先頭から順に、retain（保持）、insert（挿入）、delete（削除）を並べた操作。文字数はUnicodeのコードポイント単位"`
+	}
+}
+
+// EditDescriptionOutput は説明への操作の送信のレスポンスを表す構造体
+type EditDescriptionOutput struct {
+	ETag string `header:"ETag" doc:"更新後のTodoのバージョン"`
+	Body struct {
+		DescriptionState
+		Ops ot.Operation `json:"ops" doc:"基にした版より後の操作に合わせて変換し、最新の版に適用した操作"`
+	}
+}
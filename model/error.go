@@ -0,0 +1,145 @@
+package model
+
+import (
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/validation"
+)
+
+// ErrorDetail はエラーの原因となった箇所1つを表す構造体
+type ErrorDetail struct {
+	Message  string `json:"message,omitempty" example:"expected length <= 200" doc:"エラーの内容"`
+	Location string `json:"location,omitempty" example:"/body/title" doc:"エラーの箇所を示すリクエストのJSON Pointer（RFC 6901）。/body、/query、/path、/headerのいずれかで始まり、ボディ内はプロパティ名と配列の添字で続ける（例: /body/ids/2、/query/radius）"`
+	Value    any    `json:"value,omitempty" doc:"エラーの箇所の値"`
+}
+
+// Error はエラーの内容を返す
+func (e *ErrorDetail) Error() string {
+	if e.Location == "" {
+		return e.Message
+	}
+	return e.Message + " (" + e.Location + ")"
+}
+
+// ErrorModel はRFC 9457（Problem Details）形式のエラーのレスポンスを表す構造体。
+// バリデーションではボディ、クエリ、パスの検証とResolveによる検証のエラーをすべてerrorsにまとめて1つの422で返す。
+type ErrorModel struct {
+	Type     string         `json:"type,omitempty" format:"uri" default:"about:blank" doc:"エラーの種類を表すURI"`
+	Title    string         `json:"title,omitempty" example:"Unprocessable Entity" doc:"HTTPのステータスの説明"`
+	Status   int            `json:"status,omitempty" example:"422" doc:"HTTPのステータスコード"`
+	Detail   string         `json:"detail,omitempty" example:"validation failed" doc:"エラーの説明"`
+	Instance string         `json:"instance,omitempty" format:"uri" doc:"このエラーの発生を識別するURI"`
+	Errors   []*ErrorDetail `json:"errors,omitempty" doc:"エラーの原因となった箇所ごとの詳細"`
+
+	// headers はエラーのレスポンスに付けるヘッダー
+	headers http.Header
+}
+
+// Error はエラーの説明を返す
+func (e *ErrorModel) Error() string {
+	return e.Detail
+}
+
+// GetStatus はHTTPのステータスコードを返す
+func (e *ErrorModel) GetStatus() int {
+	return e.Status
+}
+
+// GetHeaders はエラーのレスポンスに付けるヘッダーを返す
+func (e *ErrorModel) GetHeaders() http.Header {
+	return e.headers
+}
+
+// ContentType はJSONのエラーをapplication/problem+jsonとして返す。
+// HALにはエラーの形式がないため同様に返し、JSON:APIのエラーはトランスフォーマーがJSON:APIの形式に変換する。
+func (e *ErrorModel) ContentType(ct string) string {
+	if ct == "application/json" || ct == MediaTypeHAL {
+		return "application/problem+json"
+	}
+	return ct
+}
+
+// NewError はErrorModelを生成する。huma.NewErrorに設定し、Humaのバリデーションやhuma.Error422UnprocessableEntityなどが返すエラーをこの形式にする。
+// huma.ErrorDetailの"body.items[3].tags"形式のLocationはJSON Pointerに変換する。
+func NewError(status int, msg string, errs ...error) huma.StatusError {
+	details := make([]*ErrorDetail, 0, len(errs))
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		d, ok := err.(huma.ErrorDetailer)
+		if !ok {
+			details = append(details, &ErrorDetail{Message: err.Error()})
+			continue
+		}
+		detail := d.ErrorDetail()
+		details = append(details, &ErrorDetail{
+			Message:  detail.Message,
+			Location: JSONPointer(detail.Location, detail.Message),
+			Value:    detail.Value,
+		})
+	}
+	return &ErrorModel{
+		Status: status,
+		Title:  http.StatusText(status),
+		Detail: msg,
+		Errors: details,
+	}
+}
+
+// NewUnavailableError は一時的に処理できない場合の503のErrorModelを生成する。
+// Retry-Afterヘッダーで、再試行するまで待つ秒数をretryAfterの切り上げで返す。
+func NewUnavailableError(msg string, retryAfter time.Duration, errs ...error) huma.StatusError {
+	e := NewError(http.StatusServiceUnavailable, msg, errs...).(*ErrorModel)
+	e.headers = http.Header{"Retry-After": {strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))}}
+	return e
+}
+
+// pointerEscaper はJSON Pointerの参照トークンで~と/をエスケープする
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// JSONPointer はHumaの"body.items[3].tags"形式の位置をJSON Pointer（"/body/items/3/tags"）に変換する。
+// 必須のプロパティがないエラーは親のオブジェクトを指すため、messageからプロパティ名を補ってそのプロパティを指す。
+// "/"で始まる位置はJSON Pointerとみなしてそのまま返す。
+func JSONPointer(location, message string) string {
+	if location == "" || strings.HasPrefix(location, "/") {
+		return location
+	}
+
+	var b strings.Builder
+	for part := range strings.SplitSeq(location, ".") {
+		// 配列の添字は"items[3]"のように名前の後に続く
+		name, rest, _ := strings.Cut(part, "[")
+		if name != "" {
+			b.WriteString("/" + pointerEscaper.Replace(name))
+		}
+		for rest != "" {
+			index, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				break
+			}
+			if _, err := strconv.Atoi(index); err == nil {
+				b.WriteString("/" + index)
+			}
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+
+	if m := requiredProperty().FindStringSubmatch(message); m != nil {
+		b.WriteString("/" + pointerEscaper.Replace(m[1]))
+	}
+	return b.String()
+}
+
+// requiredProperty は必須のプロパティがないエラーのメッセージからプロパティ名を取り出す正規表現を返す。
+// メッセージは差し替えられるため、呼び出すたびにvalidation.MsgExpectedRequiredPropertyから作成する。
+func requiredProperty() *regexp.Regexp {
+	before, after, _ := strings.Cut(validation.MsgExpectedRequiredProperty, "%s")
+	return regexp.MustCompile("^" + regexp.QuoteMeta(before) + "(.+)" + regexp.QuoteMeta(after) + "$")
+}
@@ -0,0 +1,61 @@
+package model
+
+import "github.com/danielgtaylor/huma/v2"
+
+// ExportTodosInput はTodoのエクスポートのリクエストパラメータを表す構造体
+type ExportTodosInput struct {
+	Format               string `query:"format" enum:"csv,json" default:"csv" doc:"出力形式。csvはヘッダー行付きのCSV、jsonはTodoの配列"`
+	Status               string `query:"status" enum:"all,open,completed" default:"all" doc:"完了状態で絞り込む。openは未完了、completedは完了済みのTodoのみ"`
+	ProjectID            int64  `query:"project_id" minimum:"0" doc:"指定したプロジェクトのTodoに絞り込む。0の場合は絞り込まない"`
+	IncludeArchivedLists bool   `query:"include_archived_lists" doc:"アーカイブ済みのプロジェクトに属するTodoと、アーカイブしたTodoも含める"`
+	Metadata             string `query:"metadata" pattern:"^[A-Za-z0-9_]+(\\.[A-Za-z0-9_]+)*:" example:"labels.color:red" doc:"metadataの値で絞り込む。パス:値の形式で、パスはドット区切りのキー"`
+}
+
+// ImportTodoRow はインポートするファイルの1件のTodoを表す構造体。
+// GET /todos/exportで書き出したファイルをそのまま読み込めるよう、CSVの列とJSONのフィールドはエクスポートと同じ名前にする。
+// 記録を移すためのインポートのため、プロジェクトや全体の既定値は使わない
+type ImportTodoRow struct {
+	Title       string         `json:"title" minLength:"1" maxLength:"200" doc:"Todoのタイトル"`
+	Description *string        `json:"description,omitempty" maxLength:"1000" doc:"Todoの詳細説明"`
+	Completed   bool           `json:"completed,omitempty" doc:"完了状態"`
+	Priority    int64          `json:"priority,omitempty" minimum:"0" maximum:"3" doc:"優先度（0: なし、1: 低、2: 中、3: 高）"`
+	ProjectID   *int64         `json:"project_id,omitempty" doc:"所属するプロジェクトのID"`
+	Assignee    *string        `json:"assignee,omitempty" maxLength:"200" doc:"担当者の認証主体"`
+	Metadata    map[string]any `json:"metadata,omitempty" doc:"クライアントが自由に使うJSONオブジェクト。プロジェクトにmetadata_schemaがある場合はそのスキーマで検証する"`
+	Location
+	Schedule
+}
+
+// ImportTodosForm はTodoのインポートのアップロードフォームを表す構造体
+type ImportTodosForm struct {
+	File huma.FormFile `form:"file" required:"true" doc:"GET /todos/exportと同じ形式のCSVまたはJSONのファイル"`
+}
+
+// ImportTodosInput はTodoのインポートのリクエストパラメータとフォームを表す構造体
+type ImportTodosInput struct {
+	Format  string `query:"format" enum:"csv,json" doc:"ファイルの形式。省略するとファイル名の拡張子かContent-Typeで判断する"`
+	DryRun  bool   `query:"dry_run" doc:"trueの場合、検証だけを行い、Todoを作成しない"`
+	RawBody huma.MultipartFormFiles[ImportTodosForm]
+}
+
+// ImportTodoRowError はインポートできなかった行を表す構造体
+type ImportTodoRowError struct {
+	Row     int            `json:"row" example:"3" doc:"1から数えた行の番号。CSVはヘッダー行を除いたデータの行、JSONは配列の要素の番号"`
+	Message string         `json:"message" example:"入力の検証に失敗しました" doc:"インポートできなかった理由"`
+	Errors  []*ErrorDetail `json:"errors,omitempty" doc:"項目ごとの検証エラー。行の検証のエラーのlocationは行の中の位置を指す（例: /title）"`
+}
+
+// ImportTodosResult はTodoのインポートの結果を表す構造体
+type ImportTodosResult struct {
+	DryRun  bool                 `json:"dry_run" doc:"dry_runで実行したか。trueの場合は何も作成していない"`
+	Rows    int                  `json:"rows" example:"120" doc:"読み込んだ行の数"`
+	Created int                  `json:"created" example:"118" doc:"作成した（dry_runでは作成できる）Todoの件数"`
+	Failed  int                  `json:"failed" example:"2" doc:"インポートできなかった行の数"`
+	TodoIDs []int64              `json:"todo_ids" doc:"作成したTodoのIDの行の順のリスト。dry_runでは空"`
+	Errors  []ImportTodoRowError `json:"errors" doc:"インポートできなかった行のリスト"`
+}
+
+// ImportTodosOutput はTodoのインポートのレスポンスを表す構造体
+type ImportTodosOutput struct {
+	Body ImportTodosResult
+}
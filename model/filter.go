@@ -0,0 +1,72 @@
+package model
+
+// SavedFilterResponse は保存済みフィルターのレスポンスを表す構造体
+type SavedFilterResponse struct {
+	ID        int64   `json:"id" example:"1" doc:"フィルターのID"`
+	Name      string  `json:"name" example:"駅周辺の未完了" doc:"フィルターの名前"`
+	Completed bool    `json:"completed" example:"false" doc:"完了済みのTodoのみを対象とするか"`
+	Near      *string `json:"near,omitempty" example:"35.681236,139.767125" doc:"周辺検索の中心（緯度,経度）"`
+	Radius    float64 `json:"radius" example:"1000" doc:"周辺検索の半径（メートル）"`
+	CreatedAt string  `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"作成日時"`
+}
+
+// ListSavedFiltersOutput は保存済みフィルター一覧取得のレスポンスを表す構造体
+type ListSavedFiltersOutput struct {
+	Body struct {
+		Filters []SavedFilterResponse `json:"filters" doc:"保存済みフィルターのリスト"`
+	}
+}
+
+// CreateSavedFilterInput は保存済みフィルター作成のリクエストボディを表す構造体
+type CreateSavedFilterInput struct {
+	Body struct {
+		Name      string  `json:"name" minLength:"1" maxLength:"100" doc:"フィルターの名前"`
+		Completed bool    `json:"completed,omitempty" doc:"完了済みのTodoのみを対象とするか"`
+		Near      *string `json:"near,omitempty" pattern:"^-?[0-9]+(\\.[0-9]+)?,-?[0-9]+(\\.[0-9]+)?$" doc:"周辺検索の中心（緯度,経度）"`
+		Radius    float64 `json:"radius,omitempty" minimum:"0" default:"1000" doc:"周辺検索の半径（メートル）"`
+	}
+}
+
+// CreateSavedFilterOutput は保存済みフィルター作成のレスポンスを表す構造体
+type CreateSavedFilterOutput struct {
+	Body SavedFilterResponse
+}
+
+// DeleteSavedFilterInput は保存済みフィルター削除のリクエストパラメータを表す構造体
+type DeleteSavedFilterInput struct {
+	ID int64 `path:"id" doc:"フィルターのID"`
+}
+
+// DeleteSavedFilterOutput は保存済みフィルター削除のレスポンスを表す構造体
+type DeleteSavedFilterOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Filter deleted successfully" doc:"削除結果メッセージ"`
+	}
+}
+
+// FilterStreamInput はフィルターのイベントストリーム購読のリクエストパラメータを表す構造体
+type FilterStreamInput struct {
+	ID int64 `path:"id" doc:"フィルターのID"`
+}
+
+// FilterEnterEvent はTodoがフィルターの結果に入ったことを表すイベント
+type FilterEnterEvent struct {
+	Todo TodoResponse `json:"todo" doc:"フィルターの結果に入ったTodo"`
+}
+
+// FilterLeaveEvent はTodoがフィルターの結果から外れたことを表すイベント
+type FilterLeaveEvent struct {
+	ID int64 `json:"id" example:"1" doc:"フィルターの結果から外れたTodoのID"`
+}
+
+// FilterFeedInput はフィルターのAtomフィード取得のリクエストパラメータを表す構造体
+type FilterFeedInput struct {
+	ID int64 `path:"id" doc:"フィルターのID"`
+}
+
+// FilterFeedOutput はフィルターのAtomフィード取得のレスポンスを表す構造体
+type FilterFeedOutput struct {
+	ContentType     string `header:"Content-Type"`
+	ContentLanguage string `header:"Content-Language"`
+	Body            []byte
+}
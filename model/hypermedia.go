@@ -0,0 +1,9 @@
+package model
+
+// Acceptヘッダーで選べる、Todoのレスポンスを関連リソースへのリンク付きの形式で包むメディアタイプ
+const (
+	// MediaTypeJSONAPI はJSON:API（https://jsonapi.org）のメディアタイプ
+	MediaTypeJSONAPI = "application/vnd.api+json"
+	// MediaTypeHAL はHAL（Hypertext Application Language）のメディアタイプ
+	MediaTypeHAL = "application/hal+json"
+)
@@ -0,0 +1,93 @@
+package model
+
+import "github.com/danielgtaylor/huma/v2"
+
+// インポートジョブの状態
+const (
+	ImportJobRunning   = "running"
+	ImportJobSucceeded = "succeeded"
+	ImportJobFailed    = "failed"
+)
+
+// ImportRequest は外部のタスク管理サービスからのインポートの内容を表す構造体
+type ImportRequest struct {
+	Source       string `json:"source" enum:"apple-reminders,google-tasks" example:"google-tasks" doc:"インポート元のサービス"`
+	ICalendar    string `json:"icalendar,omitempty" maxLength:"10485760" doc:"sourceがapple-remindersの場合に、リマインダーから書き出したiCalendar（VTODO）ファイルの内容"`
+	AccessToken  string `json:"access_token,omitempty" doc:"sourceがgoogle-tasksの場合に、tasks.readonlyスコープを持つGoogleのアクセストークン"`
+	RefreshToken string `json:"refresh_token,omitempty" doc:"sourceがgoogle-tasksの場合に、access_tokenの代わりに使うGoogleのリフレッシュトークン。google-client-idのクライアントで発行したもの"`
+	DryRun       bool   `json:"dry_run,omitempty" doc:"trueの場合、変更をコミットせずにインポート結果のみを記録する"`
+}
+
+// ImportedProject はインポートで作成したプロジェクトを表す構造体
+type ImportedProject struct {
+	ProjectID int64  `json:"project_id" example:"1" doc:"作成したプロジェクトのID"`
+	Name      string `json:"name" example:"買い物" doc:"プロジェクトの名前（インポート元のリスト名）"`
+	Todos     int    `json:"todos" example:"12" doc:"作成したTodoの件数"`
+}
+
+// ImportJobResponse はインポートジョブのレスポンスを表す構造体
+type ImportJobResponse struct {
+	ID         int64             `json:"id" example:"1" doc:"インポートジョブのID"`
+	Source     string            `json:"source" example:"google-tasks" doc:"インポート元のサービス"`
+	Status     string            `json:"status" enum:"running,succeeded,failed" example:"succeeded" doc:"ジョブの状態"`
+	DryRun     bool              `json:"dry_run" doc:"dry_runで実行したか。trueの場合は何も変更されていない"`
+	Actor      string            `json:"actor" example:"alice" doc:"ジョブを開始した認証主体"`
+	Projects   []ImportedProject `json:"projects" doc:"作成したプロジェクトのリスト。失敗した場合は失敗までに作成したもの"`
+	Error      *string           `json:"error,omitempty" doc:"失敗した理由"`
+	CreatedAt  string            `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"開始日時"`
+	FinishedAt *string           `json:"finished_at,omitempty" example:"2024-01-01T00:00:05Z" doc:"終了日時"`
+}
+
+// StartImportInput はインポートジョブの開始のリクエストボディを表す構造体
+type StartImportInput struct {
+	Body ImportRequest
+}
+
+// StartImportOutput はインポートジョブの開始のレスポンスを表す構造体
+type StartImportOutput struct {
+	Location string `header:"Location" doc:"ジョブの状態を取得するURL"`
+	Body     ImportJobResponse
+}
+
+// GetImportJobInput はインポートジョブの取得のリクエストパラメータを表す構造体
+type GetImportJobInput struct {
+	ID int64 `path:"id" doc:"インポートジョブのID"`
+}
+
+// GetImportJobOutput はインポートジョブの取得のレスポンスを表す構造体
+type GetImportJobOutput struct {
+	Body ImportJobResponse
+}
+
+// ListImportJobsInput はインポートジョブ一覧取得のリクエストパラメータを表す構造体
+type ListImportJobsInput struct {
+	Limit int64 `query:"limit" minimum:"1" maximum:"200" default:"50" doc:"取得する件数"`
+}
+
+// ListImportJobsOutput はインポートジョブ一覧取得のレスポンスを表す構造体
+type ListImportJobsOutput struct {
+	Body struct {
+		Jobs []ImportJobResponse `json:"jobs" doc:"新しい順のインポートジョブのリスト"`
+	}
+}
+
+// ImportFileForm はインポート元から書き出したファイルのアップロードのフォームを表す構造体
+type ImportFileForm struct {
+	File huma.FormFile `form:"file" required:"true" doc:"インポート元のサービスから書き出したファイル"`
+}
+
+// ImportFileInput はファイルからのインポートのリクエストパラメータを表す構造体
+type ImportFileInput struct {
+	Source  string `path:"source" enum:"apple-reminders,todoist,trello" doc:"インポート元のサービス。apple-remindersはリマインダーから書き出したiCalendar、todoistはSync APIまたはREST APIのJSON、trelloはボードから書き出したJSON"`
+	DryRun  bool   `query:"dry_run" doc:"trueの場合、変更をコミットせずにインポート結果のみを返す"`
+	RawBody huma.MultipartFormFiles[ImportFileForm]
+}
+
+// ImportFileOutput はファイルからのインポートのレスポンスを表す構造体
+type ImportFileOutput struct {
+	Body struct {
+		Source   string            `json:"source" example:"trello" doc:"インポート元のサービス"`
+		DryRun   bool              `json:"dry_run" doc:"dry_runで実行したか。trueの場合は何も変更されていない"`
+		Projects []ImportedProject `json:"projects" doc:"作成したプロジェクトのリスト。インポート元のリストの順"`
+	}
+}
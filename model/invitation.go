@@ -0,0 +1,60 @@
+package model
+
+// 招待の状態
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationExpired  = "expired"
+)
+
+// InvitationResponse は招待のレスポンスを表す構造体。トークンそのものは含まない。
+type InvitationResponse struct {
+	ID         int64   `json:"id" example:"1" doc:"招待のID"`
+	Email      string  `json:"email" example:"carol@example.com" doc:"招待したメールアドレス"`
+	Role       string  `json:"role" enum:"viewer,editor,admin" doc:"登録したユーザーのロール"`
+	InvitedBy  string  `json:"invited_by" example:"alice" doc:"招待した認証主体"`
+	Status     string  `json:"status" enum:"pending,accepted,expired" doc:"招待の状態"`
+	ExpiresAt  string  `json:"expires_at" example:"2024-01-08T00:00:00Z" doc:"招待の有効期限"`
+	AcceptedAt *string `json:"accepted_at,omitempty" example:"2024-01-02T00:00:00Z" doc:"招待を使って登録した日時"`
+	CreatedAt  string  `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"招待した日時"`
+}
+
+// ListInvitationsInput は招待一覧取得のリクエストパラメータを表す構造体
+type ListInvitationsInput struct {
+	Limit int64 `query:"limit" minimum:"1" maximum:"200" default:"50" doc:"取得する件数"`
+}
+
+// ListInvitationsOutput は招待一覧取得のレスポンスを表す構造体
+type ListInvitationsOutput struct {
+	Body struct {
+		Invitations []InvitationResponse `json:"invitations" doc:"新しい順の招待のリスト"`
+	}
+}
+
+// CreateInvitationInput は招待作成のリクエストボディを表す構造体
+type CreateInvitationInput struct {
+	Body struct {
+		Email string `json:"email" format:"email" maxLength:"254" example:"carol@example.com" doc:"招待するメールアドレス。大文字と小文字は区別しない"`
+		Role  string `json:"role,omitempty" required:"false" enum:"viewer,editor,admin" doc:"登録したユーザーのロール。省略した場合はdefault-role"`
+	}
+}
+
+// CreateInvitationOutput は招待作成のレスポンスを表す構造体
+type CreateInvitationOutput struct {
+	Body struct {
+		InvitationResponse
+		Token string `json:"token" example:"inv_AbCdEfGhIjKlMnOpQrStUvWxYz0123456789abcdefg" doc:"招待のトークン。この応答でのみ返されるため、招待したユーザーに安全な方法で伝えてください"`
+	}
+}
+
+// DeleteInvitationInput は招待取り消しのリクエストパラメータを表す構造体
+type DeleteInvitationInput struct {
+	ID int64 `path:"id" doc:"招待のID"`
+}
+
+// DeleteInvitationOutput は招待取り消しのレスポンスを表す構造体
+type DeleteInvitationOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Invitation deleted successfully" doc:"取り消し結果メッセージ"`
+	}
+}
@@ -0,0 +1,50 @@
+package model
+
+// LockResourceTodo はTodoのロックを表すリソースの種類
+const LockResourceTodo = "todo"
+
+// LockResponse はリソースのロックを表す構造体
+type LockResponse struct {
+	Resource   string `json:"resource" enum:"todo" example:"todo" doc:"ロックしたリソースの種類"`
+	ResourceID int64  `json:"resource_id" example:"1" doc:"ロックしたリソースのID"`
+	HolderID   int64  `json:"holder_id" example:"1" doc:"ロックを持っているユーザーのID"`
+	HolderName string `json:"holder_name" example:"山田太郎" doc:"ロックを持っているユーザーの名前"`
+	AcquiredAt string `json:"acquired_at" example:"2024-01-01T00:00:00Z" doc:"ロックを取得した日時。延長しても変わらない"`
+	ExpiresAt  string `json:"expires_at" example:"2024-01-01T00:05:00Z" doc:"ロックが自動で解除される日時"`
+}
+
+// AcquireTodoLockInput はTodoのロック取得のリクエストパラメータを表す構造体
+type AcquireTodoLockInput struct {
+	ID   int64 `path:"id" doc:"ロックするTodoのID"`
+	Body *struct {
+		TTLSeconds int64 `json:"ttl_seconds,omitempty" minimum:"1" example:"300" doc:"ロックを保つ秒数。省略した場合はlock-ttl、lock-max-ttlを超える場合はlock-max-ttlになる"`
+	} `required:"false"`
+}
+
+// AcquireTodoLockOutput はTodoのロック取得のレスポンスを表す構造体
+type AcquireTodoLockOutput struct {
+	Body LockResponse
+}
+
+// GetTodoLockInput はTodoのロック取得状況のリクエストパラメータを表す構造体
+type GetTodoLockInput struct {
+	ID int64 `path:"id" doc:"TodoのID"`
+}
+
+// GetTodoLockOutput はTodoのロック取得状況のレスポンスを表す構造体
+type GetTodoLockOutput struct {
+	Body LockResponse
+}
+
+// ReleaseTodoLockInput はTodoのロック解除のリクエストパラメータを表す構造体
+type ReleaseTodoLockInput struct {
+	ID    int64 `path:"id" doc:"ロックを解除するTodoのID"`
+	Force bool  `query:"force" doc:"他のユーザーが持っているロックを解除する。adminロールが必要"`
+}
+
+// ReleaseTodoLockOutput はTodoのロック解除のレスポンスを表す構造体
+type ReleaseTodoLockOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Todo unlocked successfully" doc:"解除結果メッセージ"`
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// MCPListTodosInput はMCPのlist_todosツールの引数を表す構造体
+type MCPListTodosInput struct {
+	Status string `json:"status,omitempty" enum:"open,completed,all" default:"open" doc:"取得するTodoの完了状態。openは未完了、completedは完了済み、allはすべて"`
+	Limit  int64  `json:"limit,omitempty" minimum:"1" maximum:"200" default:"50" doc:"返すTodoの件数の上限"`
+}
+
+// MCPSearchTodosInput はMCPのsearch_todosツールの引数を表す構造体
+type MCPSearchTodosInput struct {
+	Query string `json:"query" minLength:"1" maxLength:"200" doc:"タイトルか説明に含まれる文字列。大文字と小文字は区別しない"`
+	MCPListTodosInput
+}
+
+// MCPTodosOutput はMCPのTodoの一覧を返すツールの結果を表す構造体
+type MCPTodosOutput struct {
+	Todos []TodoResponse `json:"todos" doc:"作成日時の新しい順のTodoのリスト"`
+	Total int            `json:"total" doc:"条件に一致したTodoの件数。limitを超えた分はtodosに含めない"`
+}
+
+// MCPCreateTodoInput はMCPのcreate_todoツールの引数を表す構造体
+type MCPCreateTodoInput struct {
+	Title       string     `json:"title" minLength:"1" maxLength:"200" doc:"Todoのタイトル"`
+	Description *string    `json:"description,omitempty" maxLength:"1000" doc:"Todoの詳細説明"`
+	ProjectID   *int64     `json:"project_id,omitempty" doc:"所属するプロジェクトのID"`
+	DueAt       *time.Time `json:"due_at,omitempty" doc:"RFC 3339形式の期限日時"`
+	Priority    *int64     `json:"priority,omitempty" minimum:"0" maximum:"3" doc:"優先度（0: なし、1: 低、2: 中、3: 高）"`
+}
+
+// MCPCompleteTodoInput はMCPのcomplete_todoツールの引数を表す構造体
+type MCPCompleteTodoInput struct {
+	ID int64 `json:"id" minimum:"1" doc:"完了にするTodoのID"`
+}
@@ -3,10 +3,198 @@
 // バリデーションルールとドキュメント情報を含む。
 package model
 
+import (
+	"go-huma-test/recurrence"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/conditional"
+)
+
 // Options はサーバーの起動オプションを表す構造体
 type Options struct {
-	Port int    `doc:"Port to listen on." short:"p" default:"8888"`
-	Host string `doc:"Hostname to listen on." default:"localhost"`
+	Port                  int           `doc:"Port to listen on." short:"p" default:"8888"`
+	Host                  string        `doc:"Hostname to listen on." default:"localhost"`
+	DatabaseURL           string        `doc:"Database to use, such as sqlite:./todos.db or sqlite:///var/lib/todo/todos.db. A plain path, including a Windows path such as C:\\data\\todos.db, is treated as a SQLite file, and memory keeps the data in memory until the server stops. A postgres:// or postgresql:// URL stores todos in PostgreSQL and starts a reduced server with only the basic todo operations (list, get, create, update, toggle and delete), /healthz and /readyz. Projects, tags, users, sharing, webhooks, the outbox and the rest of the API need SQLite. That server accepts only static and jwt in auth-providers and refuses to start when any other option that needs SQLite is changed from its default." name:"database-url" default:"sqlite:./todos.db"`
+	DB                    string        `doc:"Overrides database-url when set, such as --db memory to run the full API on an in-memory SQLite database for tests and quick demos." name:"db"`
+	DeprecationHeader     bool          `doc:"Send a Deprecation header when a response contains deprecated fields." default:"true"`
+	RecurrenceInterval    time.Duration `doc:"Interval for sweeping completed recurring todos." default:"1m"`
+	ReminderInterval      time.Duration `doc:"Interval for firing due reminders." default:"30s"`
+	EscalationInterval    time.Duration `doc:"Interval for raising the priority of overdue todos." default:"10m"`
+	EscalationThresholds  string        `doc:"Comma-separated overdue durations, in ascending order, at which a todo's priority is raised by one level. Empty disables escalation." default:"24h,72h,168h"`
+	EscalationChannel     string        `doc:"Notification channel used to tell owners that a todo was escalated (log, webhook, email, discord, slack or teams). Discord, Slack and Teams post to the chat configured on the todo's project." default:"log"`
+	StaleAfter            time.Duration `doc:"Open todos not updated for this long are sent to their owners in a digest, with one-click links to archive each todo or snooze it. The links are built from public-url and signed with the action_link_key secret. 0 disables the digest." name:"stale-after" default:"0"`
+	StaleDigestInterval   time.Duration `doc:"Minimum interval between stale todo digests sent to the same owner." name:"stale-digest-interval" default:"168h"`
+	StaleDigestChannel    string        `doc:"Notification channel for stale todo digests (log, webhook or email)." name:"stale-digest-channel" default:"log"`
+	WatchChannel          string        `doc:"Notification channel used to tell users about changes and comments on todos they watch (log, webhook or email). Email is sent to each watcher's address." name:"watch-channel" default:"log"`
+	DueDigestTime         string        `doc:"Local time (HH:MM in timezone) at which each user is sent a daily summary of their incomplete todos due today or overdue. Empty disables the summary." name:"due-digest-time"`
+	DueDigestChannel      string        `doc:"Notification channel for the daily due todo summary (log, webhook or email). Email is sent to each owner's address." name:"due-digest-channel" default:"email"`
+	StaleSnooze           time.Duration `doc:"How long a todo snoozed from a stale todo digest is left out of later digests." name:"stale-snooze" default:"168h"`
+	ActionLinkTTL         time.Duration `doc:"How long the one-click links in notifications stay valid." name:"action-link-ttl" default:"168h"`
+	PublicURL             string        `doc:"Base URL at which users reach this server, such as https://todo.example.com. Used to build links in notifications." name:"public-url"`
+	WebhookURL            string        `doc:"URL that receives reminder notifications for the webhook channel." name:"webhook-url" redact:"true"`
+	WebhookTemplate       string        `doc:"Go text/template rendering each notification into the JSON body sent to webhook-url, e.g. {\"content\": {{json .Title}}} for Discord. Functions: json, default, truncate, rfc3339, upper, lower. Empty sends the notification as is." name:"webhook-template"`
+	WebhookInterval       time.Duration `doc:"Interval for retrying deliveries to webhooks registered under /webhooks whose retry time has passed. New events are sent right away." name:"webhook-delivery-interval" default:"5s"`
+	WebhookMaxAttempts    int           `doc:"How many times a delivery to a registered webhook is sent before it is marked failed. Only 2xx responses count as delivered." name:"webhook-max-attempts" default:"8"`
+	WebhookBackoff        time.Duration `doc:"Wait before the first retry of a failed webhook delivery. The wait doubles with each failure, capped at 6h." name:"webhook-backoff" default:"30s"`
+	WebhookTimeout        time.Duration `doc:"How long each webhook delivery waits for the receiver to respond." name:"webhook-delivery-timeout" default:"10s"`
+	WebhookRetention      time.Duration `doc:"How long finished webhook deliveries stay in the delivery log." name:"webhook-delivery-retention" default:"168h"`
+	WebhookAllowPrivate   bool          `doc:"Allow registered webhooks to deliver to loopback, private and link-local addresses. Keep off unless every user is trusted, as the server would otherwise reach internal services on their behalf." name:"webhook-allow-private"`
+	EventSinks            string        `doc:"Comma-separated message brokers that also receive every todo event published to in-process subscribers, as nats://[user:pass@]host:4222/subject or kafka://host:9092/topic. NATS subjects are <subject>.<event type>; Kafka records are keyed by todo ID. Events are relayed from the outbox table in commit order and retried until every broker acknowledges them, so consumers may see an event more than once and should deduplicate by its id." name:"event-sinks" redact:"true"`
+	EventSinkTimeout      time.Duration `doc:"How long sending one event to a broker in event-sinks waits for the broker to acknowledge it." name:"event-sink-timeout" default:"5s"`
+	OutboxInterval        time.Duration `doc:"Interval for relaying unsent events in the outbox table to event-sinks and webhooks, and for retrying after a broker failure. New events are relayed right away. Also the interval for detecting changes made by other processes, such as the MCP server, to clear the response cache." name:"outbox-relay-interval" default:"5s"`
+	OutboxRetention       time.Duration `doc:"How long relayed events stay in the outbox table." name:"outbox-retention" default:"24h"`
+	SMTPAddr              string        `doc:"SMTP server address (host:port) for the email channel." name:"smtp-addr"`
+	SMTPFrom              string        `doc:"Sender address of reminder emails." name:"smtp-from"`
+	SMTPTo                string        `doc:"Comma-separated recipient addresses of emails not addressed to a particular user, such as security alerts and reminders for todos without an owner." name:"smtp-to"`
+	EmailTemplate         string        `doc:"File of Go html/template definitions overriding the HTML bodies of reminder, due_digest and stale_digest emails. Each template receives the notification with .F for locale formatting (e.g. {{.F.DateTime .RemindAt}}) and .Links for its action links." name:"email-template"`
+	SecretProviders       string        `doc:"Comma-separated secret providers tried in order: env, docker, file:<dir>, vault." default:"env,docker"`
+	SecretTTL             time.Duration `doc:"How long a loaded secret is cached before it is re-read, so rotated secrets are picked up." name:"secret-ttl" default:"5m"`
+	VaultAddr             string        `doc:"Vault server address for the vault secret provider. The token is read from VAULT_TOKEN."`
+	VaultPath             string        `doc:"KV v2 data path of the secret holding the values, e.g. secret/data/todo." default:"secret/data/todo"`
+	AuthEventInterval     time.Duration `doc:"Minimum interval between recorded successful authentications from the same credential, IP and device." default:"1h"`
+	SecurityAlertChannel  string        `doc:"Notification channel for sign-ins from new locations (log, webhook or email)." default:"log"`
+	PanicAlertChannel     string        `doc:"Notification channel for panics recovered in request handlers (log, webhook or email)." default:"log"`
+	PanicAlertInterval    time.Duration `doc:"Minimum interval between panic notifications for the same method and path. Every panic is still logged." default:"5m"`
+	AttachmentDir         string        `doc:"Directory where uploaded attachments are stored." default:"./attachments"`
+	MaxAttachmentSize     int64         `doc:"Maximum size of an uploaded attachment in bytes." default:"10485760"`
+	IdempotencyKeyTTL     time.Duration `doc:"How long a response stored for an Idempotency-Key is replayed on retries." name:"idempotency-key-ttl" default:"24h"`
+	JWKSURL               string        `doc:"JWKS URL providing the RSA public keys for RS256 tokens. HS256 tokens use the jwt_signing_key secret." name:"jwks-url"`
+	JWKSRefresh           time.Duration `doc:"How long keys fetched from the JWKS URL are cached before they are re-fetched." name:"jwks-refresh" default:"1h"`
+	JWTIssuer             string        `doc:"Required iss claim of access tokens. Not checked when empty." name:"jwt-issuer"`
+	JWTAudience           string        `doc:"Required aud claim of access tokens. Not checked when empty." name:"jwt-audience"`
+	JWTLeeway             time.Duration `doc:"Allowed clock skew when checking the exp and nbf claims." name:"jwt-leeway" default:"1m"`
+	AuthProviders         string        `doc:"Comma-separated authentication providers tried in this order: static (tokens in the static_tokens secret as subject=token pairs), oidc (ID tokens of accounts that logged in via /auth/oidc/login), jwt and api-key (X-API-Key header). List static and oidc before jwt." name:"auth-providers" default:"api-key,jwt"`
+	CORSAllowedOrigins    string        `doc:"Comma-separated origins allowed to call the API from a browser, or * for any origin. Empty disables CORS." name:"cors-allowed-origins"`
+	CORSAllowedMethods    string        `doc:"Comma-separated methods allowed in CORS preflight responses." name:"cors-allowed-methods" default:"GET,HEAD,POST,PUT,PATCH,DELETE"`
+	CORSAllowedHeaders    string        `doc:"Comma-separated request headers allowed in CORS preflight responses, or * to allow any requested header." name:"cors-allowed-headers" default:"Authorization,Content-Type,X-API-Key,If-Match,If-None-Match,Idempotency-Key,X-Confirmation-Token,Accept-Language,X-Timezone,X-Request-ID"`
+	CORSExposedHeaders    string        `doc:"Comma-separated response headers readable by browser scripts." name:"cors-exposed-headers" default:"ETag,Last-Modified,Link,Location,Retry-After,X-RateLimit-Limit,X-RateLimit-Policy,X-RateLimit-Remaining,X-RateLimit-Reset,X-Request-ID"`
+	CORSAllowCredentials  bool          `doc:"Allow credentialed CORS requests such as those sending cookies. Requires explicit origins instead of *." name:"cors-allow-credentials"`
+	CORSMaxAge            time.Duration `doc:"How long browsers may cache a CORS preflight response." name:"cors-max-age" default:"10m"`
+	ConfirmationTTL       time.Duration `doc:"How long a confirmation token for destructive operations such as deleting a user stays valid." name:"confirmation-ttl" default:"5m"`
+	RateLimit             int           `doc:"Requests per minute allowed for each client, identified by its authenticated principal or, for unauthenticated operations, its IP address. 0 disables rate limiting." default:"600"`
+	RateLimitBurst        int           `doc:"Number of requests a client may send in a burst before the per-minute rate applies." default:"100"`
+	ResponseCacheSize     int           `doc:"Maximum number of responses cached in memory for operations that declare a cache policy. When full, the least recently used response is dropped. 0 only sends Cache-Control headers." name:"response-cache-size" default:"1000"`
+	CacheTTLs             string        `doc:"Comma-separated operation-id=duration pairs overriding how long responses of GET operations are cached and the Cache-Control max-age, such as list-todos=10s,get-todo=0. 0 disables caching for the operation. Operations without a built-in cache policy are cached per combination of all query parameters." name:"cache-ttls"`
+	TokenTTL              time.Duration `doc:"Lifetime of the access tokens issued by /auth/signup and /auth/login." name:"token-ttl" default:"24h"`
+	DefaultRole           string        `doc:"Role (viewer, editor or admin) of new users after the first, who becomes admin, of invitations that give no role, and of tokens and API keys not tied to a user that carry no role claim." name:"default-role" default:"editor"`
+	InviteOnly            bool          `doc:"Only let emails invited through /admin/invitations register, by signing up with the invitation token or signing in with OIDC under the verified invited email. The first admin is then created with the bootstrap subcommand or the one-time token logged on start while there are no users." name:"invite-only"`
+	InvitationTTL         time.Duration `doc:"How long an invitation token can be used to register." name:"invitation-ttl" default:"168h"`
+	OIDCIssuer            string        `doc:"Issuer URL of an external OIDC provider for /auth/oidc/login. The client secret is read from the oidc_client_secret secret. Disabled when empty." name:"oidc-issuer"`
+	OIDCClientID          string        `doc:"Client ID registered with the OIDC provider." name:"oidc-client-id"`
+	OIDCRedirectURL       string        `doc:"Callback URL registered with the OIDC provider, pointing to /auth/oidc/callback." name:"oidc-redirect-url"`
+	OIDCScopes            string        `doc:"Comma-separated scopes requested from the OIDC provider. openid is always requested." name:"oidc-scopes" default:"openid,email,profile"`
+	Locale                string        `doc:"Language (ja or en) of dates in feeds and emails when the request has no supported Accept-Language. Todo titles and descriptions are taken to be written in this language when choosing among their translations." default:"ja"`
+	Timezone              string        `doc:"IANA time zone of dates in feeds and emails when the request has no X-Timezone header." default:"UTC"`
+	GoogleClientID        string        `doc:"OAuth client ID used to exchange Google refresh tokens when importing from Google Tasks. The client secret is read from the google_client_secret secret." name:"google-client-id"`
+	LogLevel              string        `doc:"Minimum level (debug, info, warn or error) of logs written to stdout." name:"log-level" default:"info"`
+	AccessLogSampling     string        `doc:"Comma-separated path=rate pairs such as /todos=0.1 that log only that fraction of successful requests under the path prefix. Error responses are always logged." name:"access-log-sampling"`
+	ReadOnly              bool          `doc:"Run as a read-only replica of a database copied from the primary by an external tool. Opens the database read-only, rejects changes with 503 and runs no scheduled jobs." name:"read-only"`
+	ReplicaPrimaryURL     string        `doc:"Base URL of the primary instance, used by /replication/status to measure replica lag." name:"replica-primary-url"`
+	ReplicaSource         string        `doc:"Where the replica database was restored from, such as s3://bucket/todos.db, reported by /replication/status." name:"replica-source"`
+	ReplicaMaxLag         time.Duration `doc:"Lag behind the primary above which /replication/status responds 503 so load balancers stop routing to the replica." name:"replica-max-lag" default:"30s"`
+	OTelEndpoint          string        `doc:"OTLP/HTTP endpoint such as http://localhost:4318 that receives traces of operations and SQL queries. Empty uses OTEL_EXPORTER_OTLP_ENDPOINT, and tracing is off when neither is set." name:"otel-endpoint"`
+	OTelServiceName       string        `doc:"service.name of exported traces. OTEL_SERVICE_NAME takes precedence." name:"otel-service-name" default:"todo-api"`
+	OTelSamplePercent     int           `doc:"Percentage (0 to 100) of requests without a sampled parent span whose traces are recorded." name:"otel-sample-percent" default:"100"`
+	UsageInterval         time.Duration `doc:"Interval for writing per-user API call counts and recording storage and active todo usage for /admin/usage." default:"5m"`
+	DBReadConns           int           `doc:"Connections of the pool that runs read-only queries alongside the single write connection. SQLite in WAL mode lets these readers run while a write is in progress. 0 runs every query on the write connection. Not used with the in-memory database or read-only replicas." name:"db-read-conns" default:"4"`
+	DBCheckpointInterval  time.Duration `doc:"Interval between PRAGMA wal_checkpoint(TRUNCATE) runs that copy the WAL into the database file and truncate it. 0 disables them and leaves checkpoints to SQLite's automatic ones." name:"db-checkpoint-interval" default:"5m"`
+	DBAnalyzeInterval     time.Duration `doc:"Interval between ANALYZE runs that refresh the statistics used by the query planner. 0 disables them." name:"db-analyze-interval" default:"24h"`
+	DBVacuumInterval      time.Duration `doc:"Interval between PRAGMA incremental_vacuum runs that return free pages to the file system. New databases are created with auto_vacuum=incremental; older ones need PRAGMA auto_vacuum = INCREMENTAL followed by VACUUM once. 0 disables them." name:"db-vacuum-interval" default:"1h"`
+	DBVacuumPages         int           `doc:"Maximum number of free pages released by each incremental vacuum. 0 releases every free page." name:"db-vacuum-pages" default:"0"`
+	DiskMinFree           int64         `doc:"Minimum free bytes on the file system holding the database. Below it, changes other than deletions are rejected with 507 Insufficient Storage and disk-alert-channel is notified until space is freed. 0 disables the check, as does the in-memory database." name:"disk-min-free" default:"104857600"`
+	DiskCheckInterval     time.Duration `doc:"Interval between checks of the free space on the database's file system." name:"disk-check-interval" default:"30s"`
+	DiskAlertChannel      string        `doc:"Notification channel told when free disk space falls below disk-min-free and when it recovers (log, webhook or email)." name:"disk-alert-channel" default:"log"`
+	SLOAvailability       string        `doc:"Target percentage, such as 99.9, of API requests answered without a 5xx status over slo-window. /admin/slo reports its compliance, remaining error budget, burn rates and multi-window burn-rate alerts. Empty or 0 disables the objective." name:"slo-availability" default:"99.9"`
+	SLOLatency            string        `doc:"Target percentage of API requests answered without a 5xx status that complete within slo-latency-threshold over slo-window. Empty or 0 disables the objective." name:"slo-latency" default:"99"`
+	SLOLatencyThreshold   time.Duration `doc:"Response time the latency objective requires." name:"slo-latency-threshold" default:"500ms"`
+	SLOLatencyExclude     string        `doc:"Comma-separated operation IDs left out of the latency objective because their response time depends on the size of the response or the lifetime of the connection." name:"slo-latency-exclude" default:"connect-sync,export-todos,download-attachment,download-backup"`
+	SLOWindow             time.Duration `doc:"Period over which SLO compliance and the error budget are counted. Results are kept in memory and start over when the server restarts." name:"slo-window" default:"720h"`
+	MirrorURL             string        `doc:"Base URL of a secondary instance, such as staging, that receives asynchronous copies of incoming requests with their headers and body, including credentials, for validating a new version with production traffic. The request path and query are appended. Responses and failures of the copies never affect the original responses. Disabled when empty." name:"mirror-url"`
+	MirrorPercent         int           `doc:"Percentage (0 to 100) of incoming requests copied to mirror-url." name:"mirror-percent" default:"100"`
+	MirrorTimeout         time.Duration `doc:"Time to wait for mirror-url to answer a copied request." name:"mirror-timeout" default:"10s"`
+	MirrorMaxBody         int64         `doc:"Maximum body size in bytes of a copied request. Larger requests are not copied." name:"mirror-max-body" default:"1048576"`
+	MirrorConcurrency     int           `doc:"Maximum number of copied requests in flight. Requests arriving while the limit is reached are not copied." name:"mirror-concurrency" default:"32"`
+	BackupDir             string        `doc:"Directory where database backups are written by the backup job, POST /admin/backups and backup now." name:"backup-dir" default:"./backups"`
+	BackupInterval        time.Duration `doc:"Interval between automatic database backups taken with VACUUM INTO while the server keeps running. 0 disables the backup job." name:"backup-interval" default:"24h"`
+	BackupRetention       int           `doc:"Number of most recent backups kept in backup-dir. Older backups are deleted after each new backup. 0 keeps every backup." name:"backup-retention" default:"7"`
+	ReplicationURL        string        `doc:"Where database snapshots are replicated for disaster recovery: s3://bucket/prefix for S3-compatible object storage, authenticated with the s3_access_key_id and s3_secret_access_key secrets, or file:///dir such as a mounted network volume. Empty disables replication." name:"replication-url"`
+	ReplicationEndpoint   string        `doc:"Endpoint URL of S3-compatible storage such as http://minio:9000. Empty uses AWS S3 in replication-region." name:"replication-endpoint"`
+	ReplicationRegion     string        `doc:"Region used to sign requests to replication-url." name:"replication-region" default:"us-east-1"`
+	ReplicationInterval   time.Duration `doc:"Interval between gzip-compressed snapshots sent to replication-url. The snapshot is not sent when the database has not changed since the previous one." name:"replication-interval" default:"5m"`
+	ReplicationRetention  int           `doc:"Number of most recent snapshots kept at replication-url. 0 keeps every snapshot." name:"replication-retention" default:"48"`
+	QueryOverrunThreshold time.Duration `doc:"How long a SQL query may keep running after its request is canceled or times out, such as when the client disconnects, before a warning is logged." default:"1s"`
+	DBBusyRetries         int           `doc:"How many times a write transaction that cannot start because another connection holds the database lock is retried, with jittered exponential backoff, before the request fails with 503. Write transactions take the lock when they begin, so a retry never repeats work. 0 disables retries." name:"db-busy-retries" default:"3"`
+	DBBusyBackoff         time.Duration `doc:"Base wait before retrying a write transaction that hit a locked database. Each retry waits a random time up to this value doubled per attempt, capped at 2s." name:"db-busy-backoff" default:"50ms"`
+	QueryTimeout          time.Duration `doc:"Time limit for each SQL statement run while handling a request, including those inside transactions. Statements that exceed it are interrupted and the request responds 503 with Retry-After, as it does when the database stays locked past the busy timeout. Keep it below the 15-second write timeout. 0 disables it." default:"5s"`
+	ReadinessTimeout      time.Duration `doc:"Time limit for the database checks of /readyz. Checks that take longer respond 503." default:"2s"`
+	PresenceTTL           time.Duration `doc:"How long a user stays listed as viewing a project after their last presence heartbeat." name:"presence-ttl" default:"1m"`
+	LockTTL               time.Duration `doc:"How long a todo lock from POST /todos/{id}/lock lasts when the request gives no ttl_seconds. Locks expire automatically and are renewed by locking again." name:"lock-ttl" default:"5m"`
+	LockMaxTTL            time.Duration `doc:"Longest lifetime a todo lock can be given." name:"lock-max-ttl" default:"1h"`
+	ShutdownTimeout       time.Duration `doc:"How long shutdown waits for in-flight requests, imports and background jobs before closing the database. Keep it below the orchestrator's grace period." default:"25s"`
+	RestartTimeout        time.Duration `doc:"How long the new process started on SIGHUP may take to open the database and start accepting on the inherited listeners. If it does not, it is stopped and this process keeps serving." default:"30s"`
+	DebugAddr             string        `doc:"Loopback address (host:port) such as localhost:6060 of a separate listener serving /debug/pprof, /debug/vars and /debug/config. Empty disables it." name:"debug-addr"`
+	TLSCert               string        `doc:"PEM certificate file, including intermediate certificates, to serve HTTPS with. Reloaded when the file changes." name:"tls-cert"`
+	TLSKey                string        `doc:"PEM private key file of tls-cert." name:"tls-key"`
+	AutocertDomains       string        `doc:"Comma-separated host names to obtain and renew certificates for via ACME (Let's Encrypt) and serve HTTPS with. Cannot be combined with tls-cert." name:"autocert-domains"`
+	AutocertEmail         string        `doc:"Contact email registered with the ACME account for expiry and problem notices." name:"autocert-email"`
+	AutocertCacheDir      string        `doc:"Directory where the ACME account key and obtained certificates are stored across restarts." name:"autocert-cache-dir" default:"./autocert"`
+	AutocertDirectoryURL  string        `doc:"ACME directory URL, such as Let's Encrypt's staging environment. Empty uses Let's Encrypt production." name:"autocert-directory-url"`
+	HTTPRedirectPort      int           `doc:"Port of a plain HTTP listener, usually 80, that redirects to HTTPS and answers ACME HTTP-01 challenges. 0 disables it." name:"http-redirect-port"`
+	HSTSMaxAge            time.Duration `doc:"max-age of the Strict-Transport-Security header sent on HTTPS responses. 0 disables the header." name:"hsts-max-age" default:"4320h"`
+	HSTSIncludeSubdomains bool          `doc:"Add includeSubDomains to the Strict-Transport-Security header." name:"hsts-include-subdomains"`
+	OpenAPITags           string        `doc:"JSON file that overrides operation tags and adds tag descriptions, external docs, ordering and x-tagGroups to the OpenAPI document." name:"openapi-tags"`
+	Demo                  bool          `doc:"Seed an empty database with the demo users and data that the request examples in /docs refer to, so that trying the examples succeeds. The demo account and an API key are logged on start." name:"demo"`
+	WebUI                 bool          `doc:"Serve the embedded todo web app at /, which signs in with an email and password and lists, creates, completes, and deletes todos." name:"web-ui" default:"true"`
+	MCP                   bool          `doc:"Instead of serving HTTP, serve the Model Context Protocol over stdin and stdout with tools to list, search, create and complete todos, so that LLM agents can manage todos directly. Logs are written to stderr." name:"mcp"`
+	MCPUser               string        `doc:"Email of the user whose todos the MCP tools see and change. Empty works on todos with no owner." name:"mcp-user"`
+	FreezeTime            string        `doc:"RFC 3339 time such as 2026-01-15T09:00:00+09:00 at which to freeze the clock that decides due dates, agendas, reminders, digests, escalation and retention, so that demos and reproductions behave the same on every run. Timestamps written by the database, intervals, timeouts and webhook retries keep using the real time. Empty uses the system clock." name:"freeze-time"`
+	AutoMigrate           bool          `doc:"Apply pending database migrations on start. When false, start fails if migrations are pending so they can be applied with migrate up in a separate deploy step." name:"auto-migrate" default:"true"`
+}
+
+// Location はTodoに紐づく位置情報を表す構造体
+type Location struct {
+	Latitude  *float64 `json:"latitude,omitempty" minimum:"-90" maximum:"90" example:"35.681236" doc:"緯度"`
+	Longitude *float64 `json:"longitude,omitempty" minimum:"-180" maximum:"180" example:"139.767125" doc:"経度"`
+	PlaceName *string  `json:"place_name,omitempty" maxLength:"200" example:"東京駅" doc:"場所の名称"`
+}
+
+// Resolve は緯度と経度が揃って指定されていることを検証する
+func (l *Location) Resolve(_ huma.Context, prefix *huma.PathBuffer) []error {
+	if (l.Latitude == nil) == (l.Longitude == nil) {
+		return nil
+	}
+	if l.Latitude == nil {
+		return []error{&huma.ErrorDetail{
+			Location: prefix.With("latitude"),
+			Message:  "longitude を指定する場合は latitude も必要です",
+		}}
+	}
+	return []error{&huma.ErrorDetail{
+		Location: prefix.With("longitude"),
+		Message:  "latitude を指定する場合は longitude も必要です",
+	}}
+}
+
+// Schedule はTodoの期限と繰り返しルールを表す構造体
+type Schedule struct {
+	DueAt      *time.Time `json:"due_at,omitempty" doc:"期限日時"`
+	Recurrence *string    `json:"recurrence,omitempty" maxLength:"200" example:"FREQ=WEEKLY;BYDAY=MO,WE" doc:"繰り返しルール（iCalendar RRULE）。完了時に次回のTodoが自動生成される"`
+}
+
+// Resolve は繰り返しルールがRRULEとして解析できることを検証する
+func (s *Schedule) Resolve(_ huma.Context, prefix *huma.PathBuffer) []error {
+	if s.Recurrence == nil {
+		return nil
+	}
+	if _, err := recurrence.Parse(*s.Recurrence); err != nil {
+		return []error{&huma.ErrorDetail{
+			Location: prefix.With("recurrence"),
+			Message:  err.Error(),
+			Value:    *s.Recurrence,
+		}}
+	}
+	return nil
 }
 
 // TodoResponse はTodoのレスポンスを表す構造体
@@ -15,13 +203,71 @@ type TodoResponse struct {
 	Title       string  `json:"title" example:"買い物" doc:"Todoのタイトル"`
 	Description *string `json:"description,omitempty" example:"牛乳を買う" doc:"Todoの詳細説明"`
 	Completed   bool    `json:"completed" example:"false" doc:"完了状態"`
-	CreatedAt   string  `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"作成日時"`
-	UpdatedAt   string  `json:"updated_at" example:"2024-01-01T00:00:00Z" doc:"更新日時"`
+	Location
+	SubtaskCount          int64          `json:"subtask_count" example:"3" doc:"サブタスクの件数"`
+	SubtaskCompletedCount int64          `json:"subtask_completed_count" example:"1" doc:"完了済みサブタスクの件数"`
+	ProjectID             *int64         `json:"project_id,omitempty" example:"1" doc:"所属するプロジェクトのID"`
+	DueAt                 *string        `json:"due_at,omitempty" example:"2024-01-01T09:00:00Z" doc:"期限日時"`
+	Recurrence            *string        `json:"recurrence,omitempty" example:"FREQ=WEEKLY;BYDAY=MO,WE" doc:"繰り返しルール（iCalendar RRULE）"`
+	NextTodoID            *int64         `json:"next_todo_id,omitempty" example:"2" doc:"繰り返しにより生成された次回のTodoのID"`
+	Position              int64          `json:"position" example:"1" doc:"リスト（プロジェクト）内での並び順"`
+	Version               int64          `json:"version" example:"1" doc:"Todoのバージョン。更新のたびに増え、ETagとして返される"`
+	Assignee              *string        `json:"assignee,omitempty" example:"alice" doc:"担当者の認証主体（JWTのsubやapikey:<ID>）"`
+	OwnerID               *int64         `json:"owner_id,omitempty" example:"1" doc:"所有者のユーザーID。ユーザー登録前から共有されているTodoでは省略される"`
+	Metadata              map[string]any `json:"metadata,omitempty" doc:"クライアントが自由に使うJSONオブジェクト"`
+	Priority              int64          `json:"priority" example:"2" doc:"優先度（0: なし、1: 低、2: 中、3: 高）。期限を過ぎると自動で引き上げられる"`
+	CreatedAt             string         `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"作成日時"`
+	UpdatedAt             string         `json:"updated_at" example:"2024-01-01T00:00:00Z" doc:"更新日時"`
+	// Translationsは更新時にそのまま送り返せるよう、選んだ訳に関わらずすべて含める
+	Translations map[string]TodoTranslation `json:"translations,omitempty" doc:"言語タグごとのタイトルと説明の訳"`
+	Language     *string                    `json:"language,omitempty" example:"en" doc:"Accept-Languageヘッダーにより選んだ訳の言語タグ。訳を選んだ場合はtitleとdescriptionが訳になり、省略された場合は元のタイトルと説明になる"`
+	ArchivedAt   *string                    `json:"archived_at,omitempty" example:"2024-01-01T00:00:00Z" doc:"アーカイブ日時。アーカイブしたTodoは一覧にinclude_archived_lists=trueを指定した場合のみ含まれる"`
+	TodoExpansion
+}
+
+// TodoTranslation はTodoのタイトルと説明の訳を表す構造体
+type TodoTranslation struct {
+	Title       string  `json:"title" minLength:"1" maxLength:"200" example:"Shopping" doc:"タイトルの訳"`
+	Description *string `json:"description,omitempty" maxLength:"1000" example:"Buy milk" doc:"説明の訳。省略した場合は元の説明を返す"`
+}
+
+// 展開してTodoに含められる関連リソース
+const (
+	ExpandSubtasks     = "subtasks"
+	ExpandComments     = "comments"
+	ExpandCommentCount = "comments.count"
+	ExpandReminders    = "reminders"
+	ExpandAttachments  = "attachments"
+	ExpandProject      = "project"
+	ExpandWatchers     = "watchers"
+	ExpandTags         = "tags"
+)
+
+// ExpandParam は関連リソースの展開を指定するクエリパラメータを表す構造体
+type ExpandParam struct {
+	Expand []string `query:"expand" enum:"subtasks,comments,comments.count,reminders,attachments,project,watchers,tags" example:"tags,subtasks,comments.count" doc:"カンマ区切りで指定した関連リソースをTodoに含める。指定しない場合は含めない"`
+}
+
+// TodoExpansion はexpandで指定された場合のみTodoに含める関連リソースを表す構造体
+type TodoExpansion struct {
+	Subtasks     []SubtaskResponse    `json:"subtasks,omitzero" doc:"サブタスク（expand=subtasks）"`
+	Comments     []CommentResponse    `json:"comments,omitzero" doc:"コメント（expand=comments）"`
+	CommentCount *int64               `json:"comment_count,omitempty" example:"2" doc:"コメントの件数（expand=comments.count）"`
+	Reminders    []ReminderResponse   `json:"reminders,omitzero" doc:"リマインダー（expand=reminders）"`
+	Attachments  []AttachmentResponse `json:"attachments,omitzero" doc:"添付ファイル（expand=attachments）"`
+	Project      *ProjectResponse     `json:"project,omitempty" doc:"所属するプロジェクト（expand=project）"`
+	Watchers     []WatcherResponse    `json:"watchers,omitzero" doc:"ウォッチしているユーザー（expand=watchers）"`
+	Tags         []string             `json:"tags,omitzero" doc:"付けたタグの名前。名前の順に並べる（expand=tags。作成時は常に含める）"`
 }
 
 // ListTodosInput はTodoリスト取得のリクエストパラメータを表す構造体
 type ListTodosInput struct {
-	Completed bool `query:"completed" doc:"完了状態でフィルタリング"`
+	Completed            bool    `query:"completed" doc:"完了状態でフィルタリング"`
+	Near                 string  `query:"near" pattern:"^-?[0-9]+(\\.[0-9]+)?,-?[0-9]+(\\.[0-9]+)?$" example:"35.681236,139.767125" doc:"指定した緯度,経度の周辺にあるTodoに絞り込む"`
+	Radius               float64 `query:"radius" minimum:"0" default:"1000" doc:"near指定時の検索半径（メートル）"`
+	IncludeArchivedLists bool    `query:"include_archived_lists" doc:"アーカイブ済みのプロジェクトに属するTodoと、アーカイブしたTodoも含める"`
+	Metadata             string  `query:"metadata" pattern:"^[A-Za-z0-9_]+(\\.[A-Za-z0-9_]+)*:" example:"labels.color:red" doc:"metadataの値で絞り込む。パス:値の形式で、パスはドット区切りのキー。値は文字列として比較し、真偽値は1と0になる"`
+	ExpandParam
 }
 
 // ListTodosOutput はTodoリスト取得のレスポンスを表す構造体
@@ -34,18 +280,30 @@ type ListTodosOutput struct {
 // GetTodoInput はTodo取得のリクエストパラメータを表す構造体
 type GetTodoInput struct {
 	ID int64 `path:"id" doc:"TodoのID"`
+	ExpandParam
 }
 
 // GetTodoOutput はTodo取得のレスポンスを表す構造体
 type GetTodoOutput struct {
-	Body TodoResponse
+	ETag         string    `header:"ETag" doc:"Todoのバージョン。更新・削除時にIf-Matchヘッダーへ指定する"`
+	LastModified time.Time `header:"Last-Modified" doc:"Todoの更新日時"`
+	Body         TodoResponse
 }
 
 // CreateTodoInput はTodo作成のリクエストボディを表す構造体
 type CreateTodoInput struct {
 	Body struct {
-		Title       string  `json:"title" minLength:"1" maxLength:"200" doc:"Todoのタイトル"`
-		Description *string `json:"description,omitempty" maxLength:"1000" doc:"Todoの詳細説明"`
+		Title       string         `json:"title" minLength:"1" maxLength:"200" doc:"Todoのタイトル"`
+		Description *string        `json:"description,omitempty" maxLength:"1000" doc:"Todoの詳細説明"`
+		ProjectID   *int64         `json:"project_id,omitempty" doc:"所属するプロジェクトのID"`
+		Assignee    *string        `json:"assignee,omitempty" maxLength:"200" doc:"担当者の認証主体（JWTのsubやapikey:<ID>）"`
+		Metadata    map[string]any `json:"metadata,omitempty" doc:"クライアントが自由に使うJSONオブジェクト（16KiBまで）。プロジェクトにmetadata_schemaがある場合はそのスキーマで検証する。省略するとプロジェクトか全体の既定値になる"`
+		Priority    *int64         `json:"priority,omitempty" minimum:"0" maximum:"3" doc:"優先度（0: なし、1: 低、2: 中、3: 高）。省略するとプロジェクトか全体の既定値、既定値がなければ0になる"`
+		Tags        []string       `json:"tags,omitempty" maxItems:"20" doc:"付けるタグの名前。まだないタグは作成する。省略するとプロジェクトか全体の既定値になり、空の配列を指定するとタグを付けない"`
+		// Translations はtitleとdescriptionを元の言語とし、Accept-Languageヘッダーに合う訳があればそちらを返す
+		Translations map[string]TodoTranslation `json:"translations,omitempty" maxProperties:"20" doc:"言語タグ（enやpt-BRなど）ごとのタイトルと説明の訳"`
+		Location
+		Schedule
 	}
 }
 
@@ -56,22 +314,32 @@ type CreateTodoOutput struct {
 
 // UpdateTodoInput はTodo更新のリクエストパラメータとボディを表す構造体
 type UpdateTodoInput struct {
-	ID   int64 `path:"id" doc:"TodoのID"`
+	ID int64 `path:"id" doc:"TodoのID"`
+	conditional.Params
 	Body struct {
-		Title       string  `json:"title" minLength:"1" maxLength:"200" doc:"Todoのタイトル"`
-		Description *string `json:"description,omitempty" maxLength:"1000" doc:"Todoの詳細説明"`
-		Completed   bool    `json:"completed" doc:"完了状態"`
+		Title        string                     `json:"title" minLength:"1" maxLength:"200" doc:"Todoのタイトル"`
+		Description  *string                    `json:"description,omitempty" maxLength:"1000" doc:"Todoの詳細説明"`
+		Completed    bool                       `json:"completed" doc:"完了状態"`
+		ProjectID    *int64                     `json:"project_id,omitempty" doc:"所属するプロジェクトのID"`
+		Assignee     *string                    `json:"assignee,omitempty" maxLength:"200" doc:"担当者の認証主体。省略すると担当者を外す"`
+		Metadata     map[string]any             `json:"metadata,omitempty" doc:"クライアントが自由に使うJSONオブジェクト（16KiBまで）。省略するとmetadataを外す"`
+		Priority     int64                      `json:"priority,omitempty" minimum:"0" maximum:"3" doc:"優先度（0: なし、1: 低、2: 中、3: 高）。省略すると0になる"`
+		Translations map[string]TodoTranslation `json:"translations,omitempty" maxProperties:"20" doc:"言語タグ（enやpt-BRなど）ごとのタイトルと説明の訳。省略すると訳を外す"`
+		Location
+		Schedule
 	}
 }
 
 // UpdateTodoOutput はTodo更新のレスポンスを表す構造体
 type UpdateTodoOutput struct {
+	ETag string `header:"ETag" doc:"更新後のTodoのバージョン"`
 	Body TodoResponse
 }
 
 // DeleteTodoInput はTodo削除のリクエストパラメータを表す構造体
 type DeleteTodoInput struct {
 	ID int64 `path:"id" doc:"TodoのID"`
+	conditional.Params
 }
 
 // DeleteTodoOutput はTodo削除のレスポンスを表す構造体
@@ -84,9 +352,86 @@ type DeleteTodoOutput struct {
 // ToggleTodoInput はTodo完了状態トグルのリクエストパラメータを表す構造体
 type ToggleTodoInput struct {
 	ID int64 `path:"id" doc:"TodoのID"`
+	conditional.Params
 }
 
 // ToggleTodoOutput はTodo完了状態トグルのレスポンスを表す構造体
 type ToggleTodoOutput struct {
+	ETag string `header:"ETag" doc:"更新後のTodoのバージョン"`
+	Body TodoResponse
+}
+
+// ArchiveTodoInput はTodoのアーカイブとアーカイブ解除のリクエストパラメータを表す構造体
+type ArchiveTodoInput struct {
+	ID int64 `path:"id" doc:"TodoのID"`
+	conditional.Params
+}
+
+// ArchiveTodoOutput はTodoのアーカイブとアーカイブ解除のレスポンスを表す構造体
+type ArchiveTodoOutput struct {
+	ETag string `header:"ETag" doc:"更新後のTodoのバージョン"`
+	Body TodoResponse
+}
+
+// DeletionSummary は削除系操作の結果（dry_run時は削除予定の内容）を表す構造体
+type DeletionSummary struct {
+	DryRun bool           `json:"dry_run" example:"false" doc:"dry_runで実行されたか。trueの場合は何も変更されていない"`
+	Count  int            `json:"count" example:"2" doc:"削除された（dry_run時は削除される予定の）Todoの件数"`
+	Todos  []TodoResponse `json:"todos" doc:"削除された（dry_run時は削除される予定の）Todoのリスト"`
+}
+
+// BulkDeleteTodosInput はTodo一括削除のリクエストパラメータとボディを表す構造体
+type BulkDeleteTodosInput struct {
+	DryRun bool `query:"dry_run" doc:"trueの場合、変更をコミットせずに削除される内容のみを返す"`
+	Body   struct {
+		IDs []int64 `json:"ids" minItems:"1" maxItems:"1000" uniqueItems:"true" doc:"削除するTodoのIDリスト"`
+	}
+}
+
+// BulkDeleteTodosOutput はTodo一括削除のレスポンスを表す構造体
+type BulkDeleteTodosOutput struct {
+	Body DeletionSummary
+}
+
+// ClearCompletedTodosInput は完了済みTodo一括削除のリクエストパラメータを表す構造体
+type ClearCompletedTodosInput struct {
+	DryRun bool `query:"dry_run" doc:"trueの場合、変更をコミットせずに削除される内容のみを返す"`
+}
+
+// ClearCompletedTodosOutput は完了済みTodo一括削除のレスポンスを表す構造体
+type ClearCompletedTodosOutput struct {
+	Body DeletionSummary
+}
+
+// DuplicateTodoOptions はTodo複製時に一緒に複製する対象を表す構造体
+type DuplicateTodoOptions struct {
+	IncludeSubtasks    bool `json:"include_subtasks,omitempty" doc:"サブタスク（チェックリスト）も複製するか。複製したサブタスクは未完了になる"`
+	IncludeAttachments bool `json:"include_attachments,omitempty" doc:"添付ファイルも複製するか"`
+}
+
+// DuplicateTodoInput はTodo複製のリクエストパラメータとボディを表す構造体
+type DuplicateTodoInput struct {
+	ID   int64                 `path:"id" doc:"複製元のTodoのID"`
+	Body *DuplicateTodoOptions `doc:"省略した場合はTodoのみを複製する"`
+}
+
+// DuplicateTodoOutput はTodo複製のレスポンスを表す構造体
+type DuplicateTodoOutput struct {
+	Body TodoResponse
+}
+
+// MoveTodoInput はTodo移動のリクエストパラメータとボディを表す構造体
+type MoveTodoInput struct {
+	ID int64 `path:"id" doc:"移動するTodoのID"`
+	conditional.Params
+	Body struct {
+		ProjectID *int64 `json:"project_id,omitempty" doc:"移動先のプロジェクトのID。省略した場合はどのプロジェクトにも属さないTodoになる"`
+		Position  *int64 `json:"position,omitempty" minimum:"1" example:"1" doc:"移動先での並び順（1始まり）。省略した場合や件数を超える場合は末尾に移動する"`
+	}
+}
+
+// MoveTodoOutput はTodo移動のレスポンスを表す構造体
+type MoveTodoOutput struct {
+	ETag string `header:"ETag" doc:"移動後のTodoのバージョン"`
 	Body TodoResponse
 }
@@ -0,0 +1,57 @@
+package model
+
+// PresenceViewer はプロジェクトを開いているユーザーを表す構造体
+type PresenceViewer struct {
+	UserID   int64  `json:"user_id" example:"1" doc:"ユーザーのID"`
+	Name     string `json:"name" example:"Alice" doc:"ユーザーの名前"`
+	Since    string `json:"since" example:"2024-01-01T00:00:00Z" doc:"開き始めた日時"`
+	LastSeen string `json:"last_seen" example:"2024-01-01T00:00:30Z" doc:"最後にハートビートを受け取った日時"`
+}
+
+// PresenceResponse はプロジェクトの閲覧者のレスポンスを表す構造体
+type PresenceResponse struct {
+	ProjectID int64            `json:"project_id" example:"1" doc:"プロジェクトのID"`
+	Viewers   []PresenceViewer `json:"viewers" doc:"プロジェクトを開いているユーザー。開き始めた順"`
+	// TTLSeconds はハートビートの間隔の目安をクライアントに伝える
+	TTLSeconds int64 `json:"ttl_seconds" example:"60" doc:"この秒数の間ハートビートがないユーザーは閉じたものとみなす。これより短い間隔で送信する"`
+}
+
+// GetPresenceInput はプロジェクトの閲覧者取得のリクエストパラメータを表す構造体
+type GetPresenceInput struct {
+	ID int64 `path:"id" doc:"プロジェクトのID"`
+}
+
+// GetPresenceOutput はプロジェクトの閲覧者取得のレスポンスを表す構造体
+type GetPresenceOutput struct {
+	Body PresenceResponse
+}
+
+// PresenceHeartbeatInput はプロジェクトを開いていることを伝えるリクエストパラメータを表す構造体
+type PresenceHeartbeatInput struct {
+	ID int64 `path:"id" doc:"プロジェクトのID"`
+}
+
+// PresenceHeartbeatOutput はプロジェクトを開いていることを伝えた結果のレスポンスを表す構造体
+type PresenceHeartbeatOutput struct {
+	Body PresenceResponse
+}
+
+// LeavePresenceInput はプロジェクトを閉じたことを伝えるリクエストパラメータを表す構造体
+type LeavePresenceInput struct {
+	ID int64 `path:"id" doc:"プロジェクトのID"`
+}
+
+// LeavePresenceOutput はプロジェクトを閉じたことを伝えた結果のレスポンスを表す構造体
+type LeavePresenceOutput struct {
+	Body PresenceResponse
+}
+
+// PresenceStreamInput はプロジェクトの閲覧者の変化の購読のリクエストパラメータを表す構造体
+type PresenceStreamInput struct {
+	ID int64 `path:"id" doc:"プロジェクトのID"`
+}
+
+// PresenceEvent はプロジェクトの閲覧者が変化したことを表すイベント
+type PresenceEvent struct {
+	Viewers []PresenceViewer `json:"viewers" doc:"プロジェクトを開いているユーザー。開き始めた順"`
+}
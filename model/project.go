@@ -0,0 +1,110 @@
+package model
+
+// ProjectResponse はプロジェクトのレスポンスを表す構造体
+type ProjectResponse struct {
+	ID             int64            `json:"id" example:"1" doc:"プロジェクトのID"`
+	Name           string           `json:"name" example:"引っ越し" doc:"プロジェクトの名前"`
+	Description    *string          `json:"description,omitempty" example:"3月末までに完了" doc:"プロジェクトの説明"`
+	Archived       bool             `json:"archived" example:"false" deprecated:"true" doc:"アーカイブ済みか。archived_atの有無で判定してください"`
+	ArchivedAt     *string          `json:"archived_at,omitempty" example:"2024-01-01T00:00:00Z" doc:"アーカイブ日時"`
+	OpenCount      int64            `json:"open_count" example:"3" doc:"未完了のTodoの件数"`
+	CompletedCount int64            `json:"completed_count" example:"5" doc:"完了済みのTodoの件数"`
+	WIPLimit       *int64           `json:"wip_limit,omitempty" example:"5" doc:"未完了のTodoの件数の上限（WIP制限）。制限しない場合は省略"`
+	MetadataSchema map[string]any   `json:"metadata_schema,omitempty" doc:"所属するTodoのmetadataを検証するJSON Schema"`
+	Viewers        []PresenceViewer `json:"viewers,omitempty" doc:"プロジェクトを開いているユーザー。一覧と取得の場合のみ返す"`
+	CreatedAt      string           `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"作成日時"`
+	UpdatedAt      string           `json:"updated_at" example:"2024-01-01T00:00:00Z" doc:"更新日時"`
+}
+
+// ListProjectsInput はプロジェクト一覧取得のリクエストパラメータを表す構造体
+type ListProjectsInput struct {
+	IncludeArchived bool `query:"include_archived" doc:"アーカイブ済みのプロジェクトも含める"`
+}
+
+// ListProjectsOutput はプロジェクト一覧取得のレスポンスを表す構造体
+type ListProjectsOutput struct {
+	Body struct {
+		Projects []ProjectResponse `json:"projects" doc:"プロジェクトのリスト"`
+	}
+}
+
+// GetProjectInput はプロジェクト取得のリクエストパラメータを表す構造体
+type GetProjectInput struct {
+	ID int64 `path:"id" doc:"プロジェクトのID"`
+}
+
+// GetProjectOutput はプロジェクト取得のレスポンスを表す構造体
+type GetProjectOutput struct {
+	Body ProjectResponse
+}
+
+// CreateProjectInput はプロジェクト作成のリクエストボディを表す構造体
+type CreateProjectInput struct {
+	Body struct {
+		Name           string         `json:"name" minLength:"1" maxLength:"100" doc:"プロジェクトの名前"`
+		Description    *string        `json:"description,omitempty" maxLength:"1000" doc:"プロジェクトの説明"`
+		MetadataSchema map[string]any `json:"metadata_schema,omitempty" doc:"所属するTodoのmetadataを検証するJSON Schema"`
+		WIPLimit       *int64         `json:"wip_limit,omitempty" minimum:"1" doc:"未完了のTodoの件数の上限（WIP制限）。上限に達している間は、Todoの作成、移動、未完了への変更で未完了のTodoを増やすと409になり、繰り返しTodoの次回は上限を下回るまで生成しない。省略すると制限しない"`
+	}
+}
+
+// CreateProjectOutput はプロジェクト作成のレスポンスを表す構造体
+type CreateProjectOutput struct {
+	Body ProjectResponse
+}
+
+// UpdateProjectInput はプロジェクト更新のリクエストパラメータとボディを表す構造体
+type UpdateProjectInput struct {
+	ID   int64 `path:"id" doc:"プロジェクトのID"`
+	Body struct {
+		Name           string         `json:"name" minLength:"1" maxLength:"100" doc:"プロジェクトの名前"`
+		Description    *string        `json:"description,omitempty" maxLength:"1000" doc:"プロジェクトの説明"`
+		MetadataSchema map[string]any `json:"metadata_schema,omitempty" doc:"所属するTodoのmetadataを検証するJSON Schema。省略するとスキーマを外す。既存のTodoは再検証しない"`
+		WIPLimit       *int64         `json:"wip_limit,omitempty" minimum:"1" doc:"未完了のTodoの件数の上限（WIP制限）。省略すると制限を外す。現在の件数より小さくした場合、件数が上限を下回るまで未完了のTodoを増やせない"`
+	}
+}
+
+// UpdateProjectOutput はプロジェクト更新のレスポンスを表す構造体
+type UpdateProjectOutput struct {
+	Body ProjectResponse
+}
+
+// DeleteProjectInput はプロジェクト削除のリクエストパラメータを表す構造体
+type DeleteProjectInput struct {
+	ID   int64  `path:"id" doc:"プロジェクトのID"`
+	Mode string `query:"mode" enum:"archive,delete" default:"archive" doc:"archiveはプロジェクトをアーカイブしTodoを残す。deleteはプロジェクトと所属するTodoを削除する。他のユーザーのTodoを含む場合はadminロールが必要"`
+	ConfirmationParams
+}
+
+// DeleteProjectOutput はプロジェクト削除のレスポンスを表す構造体
+type DeleteProjectOutput struct {
+	// Status は削除した場合は200、確認トークンを返した場合は202
+	Status int
+	Body   struct {
+		Message      string                `json:"message" example:"Project archived successfully" doc:"削除結果メッセージ"`
+		Confirmation *ConfirmationResponse `json:"confirmation,omitempty" doc:"deleteモードで確認トークンを省略した場合に返す、削除の影響範囲と確認トークン"`
+	}
+}
+
+// UnarchiveProjectInput はプロジェクトのアーカイブ解除のリクエストパラメータを表す構造体
+type UnarchiveProjectInput struct {
+	ID int64 `path:"id" doc:"プロジェクトのID"`
+}
+
+// UnarchiveProjectOutput はプロジェクトのアーカイブ解除のレスポンスを表す構造体
+type UnarchiveProjectOutput struct {
+	Body ProjectResponse
+}
+
+// ListProjectTodosInput はプロジェクトに所属するTodo一覧取得のリクエストパラメータを表す構造体
+type ListProjectTodosInput struct {
+	ID int64 `path:"id" doc:"プロジェクトのID"`
+	ExpandParam
+}
+
+// ListProjectTodosOutput はプロジェクトに所属するTodo一覧取得のレスポンスを表す構造体
+type ListProjectTodosOutput struct {
+	Body struct {
+		Todos []TodoResponse `json:"todos" doc:"Todoのリスト"`
+	}
+}
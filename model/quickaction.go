@@ -0,0 +1,16 @@
+package model
+
+// QuickActionInput はリマインダーなどの通知に含めた、1回だけ使えるリンクによる操作のリクエストパラメータを表す構造体
+type QuickActionInput struct {
+	Token string `path:"token" maxLength:"1024" doc:"通知のリンクに含まれる署名付きのトークン。操作の種類と対象のTodoを含む"`
+}
+
+// QuickActionOutput はリマインダーなどの通知に含めた、1回だけ使えるリンクによる操作のレスポンスを表す構造体
+type QuickActionOutput struct {
+	Body struct {
+		Message  string       `json:"message" example:"Todo completed" doc:"操作の結果メッセージ"`
+		Action   string       `json:"action" enum:"complete,snooze_day,delete" doc:"行った操作"`
+		RemindAt *string      `json:"remind_at,omitempty" example:"2024-01-02T09:00:00Z" doc:"1日後に再通知する場合の、新しいリマインダーの通知日時"`
+		Todo     TodoResponse `json:"todo" doc:"操作したTodo。削除した場合は削除前の内容"`
+	}
+}
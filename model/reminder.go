@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// ReminderResponse はリマインダーのレスポンスを表す構造体
+type ReminderResponse struct {
+	ID        int64   `json:"id" example:"1" doc:"リマインダーのID"`
+	TodoID    int64   `json:"todo_id" example:"1" doc:"対象TodoのID"`
+	RemindAt  string  `json:"remind_at" example:"2024-01-01T09:00:00Z" doc:"通知日時"`
+	Channel   string  `json:"channel" example:"log" doc:"通知チャネル"`
+	SentAt    *string `json:"sent_at,omitempty" example:"2024-01-01T09:00:05Z" doc:"通知済みの場合の送信日時"`
+	CreatedAt string  `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"作成日時"`
+}
+
+// ListRemindersInput はリマインダー一覧取得のリクエストパラメータを表す構造体
+type ListRemindersInput struct {
+	TodoID int64 `path:"id" doc:"対象TodoのID"`
+}
+
+// ListRemindersOutput はリマインダー一覧取得のレスポンスを表す構造体
+type ListRemindersOutput struct {
+	Body struct {
+		Reminders []ReminderResponse `json:"reminders" doc:"リマインダーのリスト"`
+	}
+}
+
+// CreateReminderInput はリマインダー作成のリクエストパラメータとボディを表す構造体
+type CreateReminderInput struct {
+	TodoID int64 `path:"id" doc:"対象TodoのID"`
+	Body   struct {
+		RemindAt time.Time `json:"remind_at" doc:"通知日時。過去の日時を指定した場合は次回のスケジューラー実行時に通知される"`
+		Channel  string    `json:"channel,omitempty" enum:"log,webhook,email,discord,teams" default:"log" doc:"通知チャネル。discordとteamsはTodoが属するプロジェクトに設定されたチャットに投稿し、未設定の場合はログに出力する"`
+	}
+}
+
+// CreateReminderOutput はリマインダー作成のレスポンスを表す構造体
+type CreateReminderOutput struct {
+	Body ReminderResponse
+}
+
+// DeleteReminderInput はリマインダー削除のリクエストパラメータを表す構造体
+type DeleteReminderInput struct {
+	TodoID     int64 `path:"id" doc:"対象TodoのID"`
+	ReminderID int64 `path:"reminder_id" doc:"リマインダーのID"`
+}
+
+// DeleteReminderOutput はリマインダー削除のレスポンスを表す構造体
+type DeleteReminderOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Reminder deleted successfully" doc:"削除結果メッセージ"`
+	}
+}
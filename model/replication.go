@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+// インスタンスの役割
+const (
+	RolePrimary = "primary"
+	RoleReplica = "replica"
+)
+
+// ReplicationChange はアクティビティログに記録された変更を表す構造体
+type ReplicationChange struct {
+	ID int64  `json:"id" example:"1024" doc:"アクティビティログのID"`
+	At string `json:"at" example:"2024-01-01T00:00:00Z" doc:"変更日時"`
+}
+
+// ReplicationPrimary はレプリカから見たプライマリの状態を表す構造体
+type ReplicationPrimary struct {
+	URL        string             `json:"url" example:"https://primary.example.com" doc:"プライマリのURL"`
+	LastChange *ReplicationChange `json:"last_change,omitempty" doc:"プライマリの最後の変更"`
+	Error      string             `json:"error,omitempty" doc:"プライマリの状態を取得できなかった場合の理由"`
+}
+
+// ReplicationStatus はインスタンスの複製の状態を表す構造体
+type ReplicationStatus struct {
+	Role          string              `json:"role" enum:"primary,replica" example:"replica" doc:"インスタンスの役割"`
+	LastChange    *ReplicationChange  `json:"last_change,omitempty" doc:"このインスタンスに反映済みの最後の変更。変更がない場合は省略"`
+	NextChange    *ReplicationChange  `json:"next_change,omitempty" doc:"afterより後の最初の変更。レプリカの遅延の計測に使う"`
+	RestoreSource string              `json:"restore_source,omitempty" example:"s3://bucket/todos.db" doc:"レプリカのデータベースの復元元"`
+	Primary       *ReplicationPrimary `json:"primary,omitempty" doc:"プライマリの状態。レプリカでreplica-primary-urlを設定した場合のみ"`
+	LagChanges    int64               `json:"lag_changes" example:"3" doc:"プライマリに対して反映されていない変更の数"`
+	LagSeconds    float64             `json:"lag_seconds" example:"12.5" doc:"反映されていない最も古い変更からの経過秒数"`
+	Stale         bool                `json:"stale" doc:"遅延がreplica-max-lagを超えているか。trueの場合は503を返す"`
+}
+
+// GetReplicationStatusInput は複製の状態取得のリクエストパラメータを表す構造体
+type GetReplicationStatusInput struct {
+	After int64 `query:"after" minimum:"0" doc:"このIDより後の最初の変更をnext_changeに含める。レプリカが反映済みの最後の変更のIDを指定する"`
+}
+
+// GetReplicationStatusOutput は複製の状態取得のレスポンスを表す構造体
+type GetReplicationStatusOutput struct {
+	Status int
+	Body   ReplicationStatus
+}
+
+// ReplicationSnapshotResponse は複製先のデータベースのスナップショット1件を表す構造体
+type ReplicationSnapshotResponse struct {
+	Key       string    `json:"key" example:"todo-api/todos-20240101T030000.000Z.db.gz" doc:"複製先でのキー"`
+	Size      int64     `json:"size" example:"262144" doc:"圧縮したスナップショットのサイズ（バイト）"`
+	CreatedAt time.Time `json:"created_at" doc:"スナップショットの作成日時"`
+}
+
+// ReplicationTargetStatus はデータベースのスナップショットを別の場所に複製する状態を表す構造体
+type ReplicationTargetStatus struct {
+	Enabled             bool                         `json:"enabled" doc:"複製するか。replication-urlを設定した場合のみtrue"`
+	Target              string                       `json:"target,omitempty" example:"s3://backups/todo-api" doc:"複製先"`
+	Interval            string                       `json:"interval,omitempty" example:"5m0s" doc:"複製する間隔"`
+	LastSnapshot        *ReplicationSnapshotResponse `json:"last_snapshot,omitempty" doc:"複製先にある最新のスナップショット"`
+	LastAttemptAt       *time.Time                   `json:"last_attempt_at,omitempty" doc:"最後に複製を試みた日時"`
+	LastSuccessAt       *time.Time                   `json:"last_success_at,omitempty" doc:"最後に複製先が最新の内容になったことを確認した日時。変更がなく送らなかった場合も含む"`
+	LastError           string                       `json:"last_error,omitempty" doc:"最後の複製に失敗した理由"`
+	ConsecutiveFailures int                          `json:"consecutive_failures" example:"0" doc:"最後に成功してから続けて失敗した回数"`
+	NextAt              *time.Time                   `json:"next_at,omitempty" doc:"次に複製する日時"`
+	Stale               bool                         `json:"stale" doc:"複製先の内容が間隔の2倍より古いか。trueの場合は503を返す"`
+}
+
+// GetReplicationTargetOutput は複製先の状態取得のレスポンスを表す構造体
+type GetReplicationTargetOutput struct {
+	Status int
+	Body   ReplicationTargetStatus
+}
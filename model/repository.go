@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// RepositoryTodoInput はリポジトリのTodoを指定するリクエストパラメータを表す構造体
+type RepositoryTodoInput struct {
+	ID int64 `path:"id" doc:"TodoのID"`
+}
+
+// ListRepositoryTodosInput はリポジトリのTodoリスト取得のリクエストパラメータを表す構造体
+type ListRepositoryTodosInput struct {
+	Completed bool `query:"completed" doc:"完了状態でフィルタリング"`
+}
+
+// CreateRepositoryTodoInput はリポジトリのTodo作成のリクエストボディを表す構造体
+type CreateRepositoryTodoInput struct {
+	Body struct {
+		Title       string     `json:"title" minLength:"1" maxLength:"200" doc:"Todoのタイトル"`
+		Description *string    `json:"description,omitempty" maxLength:"1000" doc:"Todoの詳細説明"`
+		Priority    int64      `json:"priority,omitempty" minimum:"0" maximum:"3" doc:"優先度（0: なし、1: 低、2: 中、3: 高）。省略すると0になる"`
+		DueAt       *time.Time `json:"due_at,omitempty" doc:"期限日時"`
+	}
+}
+
+// UpdateRepositoryTodoInput はリポジトリのTodo更新のリクエストパラメータとボディを表す構造体
+type UpdateRepositoryTodoInput struct {
+	ID   int64 `path:"id" doc:"TodoのID"`
+	Body struct {
+		Title       string     `json:"title" minLength:"1" maxLength:"200" doc:"Todoのタイトル"`
+		Description *string    `json:"description,omitempty" maxLength:"1000" doc:"Todoの詳細説明"`
+		Completed   bool       `json:"completed" doc:"完了状態"`
+		Priority    int64      `json:"priority,omitempty" minimum:"0" maximum:"3" doc:"優先度（0: なし、1: 低、2: 中、3: 高）。省略すると0になる"`
+		DueAt       *time.Time `json:"due_at,omitempty" doc:"期限日時。省略すると期限を外す"`
+	}
+}
+
+// RepositoryTodoOutput はリポジトリのTodoを1件返すレスポンスを表す構造体
+type RepositoryTodoOutput struct {
+	LastModified time.Time `header:"Last-Modified" doc:"Todoの更新日時"`
+	Body         TodoResponse
+}
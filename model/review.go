@@ -0,0 +1,15 @@
+package model
+
+// ReviewActionInput は放置されたTodoの通知に含めたリンクによる操作のリクエストパラメータを表す構造体
+type ReviewActionInput struct {
+	Token string `path:"token" maxLength:"1024" doc:"通知のリンクに含まれる署名付きのトークン"`
+}
+
+// ReviewActionOutput は放置されたTodoの通知に含めたリンクによる操作のレスポンスを表す構造体
+type ReviewActionOutput struct {
+	Body struct {
+		Message      string       `json:"message" example:"Todo archived" doc:"操作の結果メッセージ"`
+		SnoozedUntil *string      `json:"snoozed_until,omitempty" example:"2024-01-08T00:00:00Z" doc:"通知を延期した場合の、次に通知に含める日時"`
+		Todo         TodoResponse `json:"todo" doc:"操作したTodo"`
+	}
+}
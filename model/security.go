@@ -0,0 +1,25 @@
+package model
+
+// AuthEventResponse は認証イベントのレスポンスを表す構造体
+type AuthEventResponse struct {
+	ID        int64  `json:"id" example:"1" doc:"認証イベントのID"`
+	EventType string `json:"event_type" example:"key.used" doc:"イベントの種類（login.succeeded, login.failed, token.issued, key.used）"`
+	IP        string `json:"ip" example:"192.0.2.1" doc:"リクエスト元のIPアドレス"`
+	UserAgent string `json:"user_agent" example:"curl/8.5.0" doc:"リクエスト元の端末（User-Agent）"`
+	CreatedAt string `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"発生日時"`
+}
+
+// ListSecurityEventsInput は自分の認証イベント一覧取得のリクエストパラメータを表す構造体
+type ListSecurityEventsInput struct {
+	Authorization string `header:"Authorization" doc:"認証情報。この資格情報に紐づくイベントを返す"`
+	APIKey        string `header:"X-API-Key" doc:"APIキー。Authorizationがない場合はこのキーに紐づくイベントを返す"`
+	Limit         int64  `query:"limit" minimum:"1" maximum:"200" default:"50" doc:"取得する件数"`
+}
+
+// ListSecurityEventsOutput は自分の認証イベント一覧取得のレスポンスを表す構造体
+type ListSecurityEventsOutput struct {
+	Body struct {
+		Subject string              `json:"subject" example:"9f86d081884c7d65" doc:"認証主体の識別子"`
+		Events  []AuthEventResponse `json:"events" doc:"新しい順の認証イベントのリスト"`
+	}
+}
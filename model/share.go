@@ -0,0 +1,57 @@
+package model
+
+// 共有の権限
+const (
+	// SharePermissionRead は閲覧のみを許可する権限
+	SharePermissionRead = "read"
+	// SharePermissionWrite は閲覧と変更を許可する権限
+	SharePermissionWrite = "write"
+)
+
+// ShareResponse は共有のレスポンスを表す構造体
+type ShareResponse struct {
+	ID         int64  `json:"id" example:"1" doc:"共有のID"`
+	UserID     int64  `json:"user_id" example:"2" doc:"共有先のユーザーID"`
+	Email      string `json:"email" example:"bob@example.com" doc:"共有先のユーザーのメールアドレス"`
+	Permission string `json:"permission" example:"read" doc:"共有の権限"`
+	CreatedAt  string `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"共有した日時"`
+}
+
+// ListSharesInput は共有一覧取得のリクエストパラメータを表す構造体
+type ListSharesInput struct {
+	ID int64 `path:"id" doc:"共有するTodoまたはプロジェクトのID"`
+}
+
+// ListSharesOutput は共有一覧取得のレスポンスを表す構造体
+type ListSharesOutput struct {
+	Body struct {
+		Shares []ShareResponse `json:"shares" doc:"共有のリスト"`
+	}
+}
+
+// CreateShareInput は共有のリクエストパラメータとボディを表す構造体
+type CreateShareInput struct {
+	ID   int64 `path:"id" doc:"共有するTodoまたはプロジェクトのID"`
+	Body struct {
+		Email      string `json:"email" format:"email" maxLength:"254" doc:"共有先のユーザーのメールアドレス"`
+		Permission string `json:"permission" enum:"read,write" doc:"共有の権限。readは閲覧のみ、writeは変更も許可する"`
+	}
+}
+
+// CreateShareOutput は共有のレスポンスを表す構造体
+type CreateShareOutput struct {
+	Body ShareResponse
+}
+
+// DeleteShareInput は共有解除のリクエストパラメータを表す構造体
+type DeleteShareInput struct {
+	ID      int64 `path:"id" doc:"共有したTodoまたはプロジェクトのID"`
+	ShareID int64 `path:"share_id" doc:"共有のID"`
+}
+
+// DeleteShareOutput は共有解除のレスポンスを表す構造体
+type DeleteShareOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Share deleted successfully" doc:"削除結果メッセージ"`
+	}
+}
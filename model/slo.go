@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// SLOBurnRate は1つの期間のバーンレートを表す構造体
+type SLOBurnRate struct {
+	Window string  `json:"window" example:"1h0m0s" doc:"直近の期間"`
+	Total  int64   `json:"total" example:"1200" doc:"期間の対象のリクエストの数"`
+	Bad    int64   `json:"bad" example:"3" doc:"期間の目標を満たさなかったリクエストの数"`
+	Rate   float64 `json:"rate" example:"2.5" doc:"エラーバジェットを消費する速さ。1はSLOの期間でちょうどエラーバジェットを使い切る速さ"`
+}
+
+// SLOAlert は複数期間のバーンレートのアラートを表す構造体
+type SLOAlert struct {
+	Severity    string  `json:"severity" enum:"page,ticket" example:"page" doc:"アラートの重大度。pageはすぐに対応し、ticketは営業時間内に対応する"`
+	LongWindow  string  `json:"long_window" example:"1h0m0s" doc:"バーンレートを求める長い期間"`
+	ShortWindow string  `json:"short_window" example:"5m0s" doc:"回復したらすぐに解消するよう、あわせて確認する短い期間"`
+	Threshold   float64 `json:"threshold" example:"14.4" doc:"発火するバーンレート。SLOの期間から求める"`
+	Firing      bool    `json:"firing" doc:"長い期間と短い期間のバーンレートがどちらも閾値以上か"`
+}
+
+// SLOObjective は1つの目標の達成状況を表す構造体
+type SLOObjective struct {
+	Name                 string        `json:"name" enum:"availability,latency" example:"availability" doc:"目標。availabilityは5xx以外の応答、latencyは5xx以外の応答のうちthreshold_ms以内の応答の割合"`
+	Target               float64       `json:"target" example:"99.9" doc:"目標の割合（%）"`
+	ThresholdMS          int64         `json:"threshold_ms,omitempty" example:"500" doc:"latencyの目標の応答時間（ミリ秒）"`
+	Total                int64         `json:"total" example:"86400" doc:"SLOの期間の対象のリクエストの数"`
+	Bad                  int64         `json:"bad" example:"12" doc:"SLOの期間の目標を満たさなかったリクエストの数"`
+	Compliance           float64       `json:"compliance" example:"99.986" doc:"SLOの期間の目標を満たしたリクエストの割合（%）"`
+	ErrorBudgetRemaining float64       `json:"error_budget_remaining" example:"0.86" doc:"SLOの期間のエラーバジェットの残りの割合。使い切ると0、超えると負になる"`
+	BurnRates            []SLOBurnRate `json:"burn_rates" doc:"期間ごとのバーンレート。最後はSLOの期間全体"`
+	Alerts               []SLOAlert    `json:"alerts" doc:"複数期間のバーンレートのアラート。SLOの期間より長い期間を使うものは含めない"`
+}
+
+// SLOStatus はSLOの達成状況を表す構造体
+type SLOStatus struct {
+	Window     string         `json:"window" example:"720h0m0s" doc:"達成率とエラーバジェットを数えるSLOの期間"`
+	Since      time.Time      `json:"since" doc:"記録を始めた日時。記録はメモリに保持するため、起動した日時になる"`
+	Objectives []SLOObjective `json:"objectives" doc:"設定した目標ごとの達成状況"`
+}
+
+// GetSLOOutput はSLOの達成状況取得のレスポンスを表す構造体
+type GetSLOOutput struct {
+	Body SLOStatus
+}
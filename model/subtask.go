@@ -0,0 +1,76 @@
+package model
+
+// SubtaskResponse はサブタスクのレスポンスを表す構造体
+type SubtaskResponse struct {
+	ID        int64  `json:"id" example:"1" doc:"サブタスクのID"`
+	TodoID    int64  `json:"todo_id" example:"1" doc:"親TodoのID"`
+	Title     string `json:"title" example:"牛乳" doc:"サブタスクのタイトル"`
+	Completed bool   `json:"completed" example:"false" doc:"完了状態"`
+	CreatedAt string `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"作成日時"`
+	UpdatedAt string `json:"updated_at" example:"2024-01-01T00:00:00Z" doc:"更新日時"`
+}
+
+// ListSubtasksInput はサブタスク一覧取得のリクエストパラメータを表す構造体
+type ListSubtasksInput struct {
+	TodoID int64 `path:"id" doc:"親TodoのID"`
+}
+
+// ListSubtasksOutput はサブタスク一覧取得のレスポンスを表す構造体
+type ListSubtasksOutput struct {
+	Body struct {
+		Subtasks []SubtaskResponse `json:"subtasks" doc:"サブタスクのリスト"`
+	}
+}
+
+// GetSubtaskInput はサブタスク取得のリクエストパラメータを表す構造体
+type GetSubtaskInput struct {
+	TodoID    int64 `path:"id" doc:"親TodoのID"`
+	SubtaskID int64 `path:"subtask_id" doc:"サブタスクのID"`
+}
+
+// GetSubtaskOutput はサブタスク取得のレスポンスを表す構造体
+type GetSubtaskOutput struct {
+	Body SubtaskResponse
+}
+
+// CreateSubtaskInput はサブタスク作成のリクエストパラメータとボディを表す構造体
+type CreateSubtaskInput struct {
+	TodoID int64 `path:"id" doc:"親TodoのID"`
+	Body   struct {
+		Title     string `json:"title" minLength:"1" maxLength:"200" doc:"サブタスクのタイトル"`
+		Completed bool   `json:"completed,omitempty" doc:"完了状態"`
+	}
+}
+
+// CreateSubtaskOutput はサブタスク作成のレスポンスを表す構造体
+type CreateSubtaskOutput struct {
+	Body SubtaskResponse
+}
+
+// UpdateSubtaskInput はサブタスク更新のリクエストパラメータとボディを表す構造体
+type UpdateSubtaskInput struct {
+	TodoID    int64 `path:"id" doc:"親TodoのID"`
+	SubtaskID int64 `path:"subtask_id" doc:"サブタスクのID"`
+	Body      struct {
+		Title     string `json:"title" minLength:"1" maxLength:"200" doc:"サブタスクのタイトル"`
+		Completed bool   `json:"completed" doc:"完了状態"`
+	}
+}
+
+// UpdateSubtaskOutput はサブタスク更新のレスポンスを表す構造体
+type UpdateSubtaskOutput struct {
+	Body SubtaskResponse
+}
+
+// DeleteSubtaskInput はサブタスク削除のリクエストパラメータを表す構造体
+type DeleteSubtaskInput struct {
+	TodoID    int64 `path:"id" doc:"親TodoのID"`
+	SubtaskID int64 `path:"subtask_id" doc:"サブタスクのID"`
+}
+
+// DeleteSubtaskOutput はサブタスク削除のレスポンスを表す構造体
+type DeleteSubtaskOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Subtask deleted successfully" doc:"削除結果メッセージ"`
+	}
+}
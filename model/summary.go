@@ -0,0 +1,47 @@
+package model
+
+// GetSummaryInput はホーム画面用サマリー取得のリクエストパラメータを表す構造体
+type GetSummaryInput struct {
+	TZ string `query:"tz" default:"UTC" example:"Asia/Tokyo" doc:"「今日」の範囲を判定するタイムゾーン（IANA名）"`
+}
+
+// SummaryResponse はホーム画面用サマリーのレスポンスを表す構造体
+type SummaryResponse struct {
+	Subject        string `json:"subject" example:"alice" doc:"認証主体"`
+	Overdue        int64  `json:"overdue" example:"2" doc:"期限を過ぎた未完了のTodoの件数"`
+	DueToday       int64  `json:"due_today" example:"3" doc:"今日が期限でまだ期限を過ぎていない未完了のTodoの件数"`
+	AssignedToMe   int64  `json:"assigned_to_me" example:"5" doc:"自分が担当者の未完了のTodoの件数"`
+	Mentions       int64  `json:"mentions" example:"1" doc:"既読にしてから投稿された、自分宛て（@認証主体）のコメントの件数"`
+	UnreadActivity int64  `json:"unread_activity" example:"12" doc:"既読にしてから他の認証主体が行った変更の件数"`
+}
+
+// GetSummaryOutput はホーム画面用サマリー取得のレスポンスを表す構造体
+type GetSummaryOutput struct {
+	Body SummaryResponse
+}
+
+// MarkSummaryReadOutput はサマリーの既読化のレスポンスを表す構造体
+type MarkSummaryReadOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Marked as read" doc:"結果メッセージ"`
+	}
+}
+
+// GetBadgesInput はバッジの件数取得のリクエストパラメータを表す構造体
+type GetBadgesInput struct {
+	TZ string `query:"tz" default:"UTC" example:"Asia/Tokyo" doc:"「今日」の範囲を判定するタイムゾーン（IANA名）"`
+}
+
+// BadgesResponse はクライアントがバッジに表示する件数のレスポンスを表す構造体
+type BadgesResponse struct {
+	Overdue        int64 `json:"overdue" example:"2" doc:"期限を過ぎた未完了のTodoの件数"`
+	DueToday       int64 `json:"due_today" example:"3" doc:"今日が期限でまだ期限を過ぎていない未完了のTodoの件数"`
+	Assigned       int64 `json:"assigned" example:"5" doc:"自分が担当者の未完了のTodoの件数"`
+	UnreadComments int64 `json:"unread_comments" example:"4" doc:"既読にしてから他の認証主体が自分のTodoに投稿したコメントの件数"`
+}
+
+// GetBadgesOutput はバッジの件数取得のレスポンスを表す構造体
+type GetBadgesOutput struct {
+	ETag string `header:"ETag" doc:"件数から生成したETag。If-None-Matchに指定すると、件数が変わっていない場合は本文を返さず304を返す"`
+	Body BadgesResponse
+}
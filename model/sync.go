@@ -0,0 +1,76 @@
+package model
+
+import "encoding/json"
+
+// 同期チャネルのメッセージの種類
+const (
+	// SyncMessageReady は接続直後にサーバーが送る、呼び出せる操作の一覧
+	SyncMessageReady = "ready"
+	// SyncMessageEvent はTodoの変更の通知
+	SyncMessageEvent = "event"
+	// SyncMessageMutate はクライアントが送る変更の依頼
+	SyncMessageMutate = "mutate"
+	// SyncMessageResult は変更の依頼に対する結果
+	SyncMessageResult = "result"
+	// SyncMessageError は解釈できないメッセージを受け取ったことの通知
+	SyncMessageError = "error"
+)
+
+// SyncConnectInput は同期チャネルへの接続のリクエストパラメータを表す構造体
+type SyncConnectInput struct {
+	Upgrade string `header:"Upgrade" example:"websocket" doc:"websocketを指定する"`
+	Version string `header:"Sec-WebSocket-Version" example:"13" doc:"WebSocketのプロトコルのバージョン。13のみ対応する"`
+}
+
+// SyncReadyMessage は接続直後にサーバーが送るメッセージを表す構造体
+type SyncReadyMessage struct {
+	Type       string   `json:"type"`
+	Operations []string `json:"operations"`
+}
+
+// SyncEventMessage はTodoの変更をクライアントに通知するメッセージを表す構造体
+type SyncEventMessage struct {
+	Type      string `json:"type"`
+	Event     string `json:"event"`
+	TodoID    int64  `json:"todo_id"`
+	Completed bool   `json:"completed,omitempty"`
+	// ETag はTodoのバージョン。変更を依頼する際のif_matchに使う。削除の場合は省略する
+	ETag string `json:"etag,omitempty"`
+	// Todo は変更後のTodo。削除の場合は省略する
+	Todo *TodoResponse `json:"todo,omitempty"`
+}
+
+// SyncMutateMessage はクライアントが送る変更の依頼を表す構造体。
+// RESTの操作と同じ検証と認可を経て処理し、結果をSyncResultMessageで返す。
+type SyncMutateMessage struct {
+	Type string `json:"type"`
+	// ID は結果のメッセージに含めて返す、クライアントが決める識別子
+	ID string `json:"id"`
+	// Operation は呼び出す操作のoperationId
+	Operation string `json:"operation"`
+	// Params はパスパラメータ。パスに含まれない値はクエリパラメータにする
+	Params map[string]any `json:"params,omitempty"`
+	// IfMatch は楽観的な変更の元にしたTodoのETag。If-Matchヘッダーとして渡す
+	IfMatch string `json:"if_match,omitempty"`
+	// IdempotencyKey は再送しても1回だけ処理させるためのキー。Idempotency-Keyヘッダーとして渡す
+	IdempotencyKey string          `json:"idempotency_key,omitempty"`
+	Body           json.RawMessage `json:"body,omitempty"`
+}
+
+// SyncResultMessage は変更の依頼の結果を表す構造体。
+// statusとbodyはRESTで同じ操作を呼び出した場合のレスポンスと同じで、失敗した場合はクライアントが楽観的な変更を取り消す。
+type SyncResultMessage struct {
+	Type      string          `json:"type"`
+	ID        string          `json:"id"`
+	Operation string          `json:"operation"`
+	Status    int             `json:"status"`
+	ETag      string          `json:"etag,omitempty"`
+	Body      json.RawMessage `json:"body,omitempty"`
+}
+
+// SyncErrorMessage は解釈できないメッセージを受け取ったことを表す構造体
+type SyncErrorMessage struct {
+	Type    string `json:"type"`
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,82 @@
+package model
+
+// TagResponse はタグのレスポンスを表す構造体
+type TagResponse struct {
+	ID        int64  `json:"id" example:"1" doc:"タグのID"`
+	Name      string `json:"name" example:"買い物" doc:"タグの名前"`
+	TodoCount int64  `json:"todo_count" example:"3" doc:"タグを付けているTodoの件数"`
+	CreatedAt string `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"作成日時"`
+}
+
+// ListTagsInput はタグ一覧取得のリクエストパラメータを表す構造体
+type ListTagsInput struct{}
+
+// ListTagsOutput はタグ一覧取得のレスポンスを表す構造体
+type ListTagsOutput struct {
+	Body struct {
+		Tags []TagResponse `json:"tags" doc:"認証したユーザーのタグのリスト。名前の順に並べる"`
+	}
+}
+
+// SetTodoTagsInput はTodoのタグの設定のリクエストパラメータとボディを表す構造体
+type SetTodoTagsInput struct {
+	ID   int64 `path:"id" doc:"TodoのID"`
+	Body struct {
+		Tags []string `json:"tags" maxItems:"20" doc:"Todoに付けるタグの名前。前後の空白は除き、まだないタグはTodoの所有者のタグとして作成する。空の配列ですべて外す"`
+	}
+}
+
+// SetTodoTagsOutput はTodoのタグの設定のレスポンスを表す構造体
+type SetTodoTagsOutput struct {
+	Body struct {
+		TodoID int64    `json:"todo_id" example:"1" doc:"TodoのID"`
+		Tags   []string `json:"tags" doc:"Todoに付けたタグの名前。名前の順に並べる"`
+	}
+}
+
+// TagOperationSummary はタグの名前の変更、統合、削除の結果を表す構造体
+type TagOperationSummary struct {
+	DryRun  bool         `json:"dry_run" example:"false" doc:"dry_runで実行されたか。trueの場合は何も変更されていない"`
+	Tag     *TagResponse `json:"tag,omitempty" doc:"操作の後にTodoに付いているタグ。付け替えずに削除した場合は省略"`
+	TodoIDs []int64      `json:"todo_ids" doc:"タグが変わったTodoのID。それぞれ変更イベントとアクティビティを記録する"`
+}
+
+// RenameTagInput はタグの名前の変更のリクエストパラメータとボディを表す構造体
+type RenameTagInput struct {
+	ID     int64 `path:"id" doc:"タグのID"`
+	DryRun bool  `query:"dry_run" doc:"trueの場合、変更をコミットせずに影響するTodoのみを返す"`
+	Body   struct {
+		Name string `json:"name" minLength:"1" maxLength:"50" doc:"新しい名前。同じ名前のタグが既にある場合は409になるため、統合を使う"`
+	}
+}
+
+// RenameTagOutput はタグの名前の変更のレスポンスを表す構造体
+type RenameTagOutput struct {
+	Body TagOperationSummary
+}
+
+// MergeTagInput はタグの統合のリクエストパラメータとボディを表す構造体
+type MergeTagInput struct {
+	ID     int64 `path:"id" doc:"統合して削除するタグのID"`
+	DryRun bool  `query:"dry_run" doc:"trueの場合、変更をコミットせずに影響するTodoのみを返す"`
+	Body   struct {
+		Into int64 `json:"into" minimum:"1" example:"2" doc:"統合先のタグのID。統合するタグを付けていたTodoに付ける"`
+	}
+}
+
+// MergeTagOutput はタグの統合のレスポンスを表す構造体
+type MergeTagOutput struct {
+	Body TagOperationSummary
+}
+
+// DeleteTagInput はタグの削除のリクエストパラメータを表す構造体
+type DeleteTagInput struct {
+	ID         int64 `path:"id" doc:"タグのID"`
+	ReassignTo int64 `query:"reassign_to" minimum:"0" doc:"削除するタグを付けていたTodoに代わりに付けるタグのID。省略するとTodoからタグを外すだけにする"`
+	DryRun     bool  `query:"dry_run" doc:"trueの場合、変更をコミットせずに影響するTodoのみを返す"`
+}
+
+// DeleteTagOutput はタグの削除のレスポンスを表す構造体
+type DeleteTagOutput struct {
+	Body TagOperationSummary
+}
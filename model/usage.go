@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+// UsageInput は利用量の取得のリクエストパラメータを表す構造体
+type UsageInput struct {
+	From   string `query:"from" format:"date" example:"2024-01-01" doc:"集計期間の開始日（UTC、この日を含む）。省略した場合はtoの29日前"`
+	To     string `query:"to" format:"date" example:"2024-01-31" doc:"集計期間の終了日（UTC、この日を含む）。省略した場合は今日"`
+	UserID int64  `query:"user_id" minimum:"0" doc:"指定したユーザーの利用量のみを返す。0の場合はすべてのユーザー"`
+}
+
+// DailyUsage はユーザーの1日の利用量を表す構造体
+type DailyUsage struct {
+	Day          string `json:"day" example:"2024-01-01" doc:"集計した日（UTC）"`
+	UserID       int64  `json:"user_id" example:"1" doc:"ユーザーのID"`
+	Email        string `json:"email" example:"alice@example.com" doc:"ユーザーのメールアドレス"`
+	APICalls     int64  `json:"api_calls" example:"1200" doc:"その日のAPIの呼び出し回数"`
+	StorageBytes int64  `json:"storage_bytes" example:"1048576" doc:"その日の最後の集計時点の添付ファイルの合計サイズ（バイト）"`
+	ActiveTodos  int64  `json:"active_todos" example:"42" doc:"その日の最後の集計時点の未完了のTodoの件数"`
+}
+
+// UsageTotal はユーザーの集計期間全体の利用量を表す構造体
+type UsageTotal struct {
+	UserID          int64  `json:"user_id" example:"1" doc:"ユーザーのID"`
+	Email           string `json:"email" example:"alice@example.com" doc:"ユーザーのメールアドレス"`
+	APICalls        int64  `json:"api_calls" example:"36000" doc:"期間中のAPIの呼び出し回数の合計"`
+	MaxStorageBytes int64  `json:"max_storage_bytes" example:"2097152" doc:"期間中の添付ファイルの合計サイズの最大値（バイト）"`
+	MaxActiveTodos  int64  `json:"max_active_todos" example:"50" doc:"期間中の未完了のTodoの件数の最大値"`
+}
+
+// UsageOutput は利用量の取得のレスポンスを表す構造体
+type UsageOutput struct {
+	Body struct {
+		From   string       `json:"from" example:"2024-01-01" doc:"集計期間の開始日"`
+		To     string       `json:"to" example:"2024-01-31" doc:"集計期間の終了日"`
+		Days   []DailyUsage `json:"days" doc:"日付とユーザーの順の日次の利用量"`
+		Totals []UsageTotal `json:"totals" doc:"ユーザーごとの期間全体の利用量"`
+	}
+}
+
+// ExportUsageOutput は利用量のCSVエクスポートのレスポンスを表す構造体
+type ExportUsageOutput struct {
+	ContentType        string `header:"Content-Type"`
+	ContentDisposition string `header:"Content-Disposition"`
+	Body               []byte
+}
+
+// OperationUsageInput は操作ごとの利用状況の取得のリクエストを表す構造体
+type OperationUsageInput struct {
+	UnusedDays int `query:"unused_days" minimum:"0" doc:"この日数の間に呼び出されていない操作のみを返す。0の場合はすべての操作"`
+}
+
+// OperationUsage は操作1つの利用状況を表す構造体
+type OperationUsage struct {
+	OperationID string     `json:"operation_id" example:"list-todos" doc:"操作ID"`
+	Method      string     `json:"method" example:"GET" doc:"HTTPメソッド"`
+	Path        string     `json:"path" example:"/todos" doc:"パス"`
+	Deprecated  bool       `json:"deprecated" doc:"非推奨の操作か"`
+	Calls       int64      `json:"calls" example:"1200" doc:"記録を始めてからの呼び出し回数"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" doc:"最後に呼び出された日時。呼び出されていない場合は省略"`
+}
+
+// OperationUsageOutput は操作ごとの利用状況のレスポンスを表す構造体
+type OperationUsageOutput struct {
+	Body struct {
+		Operations []OperationUsage `json:"operations" doc:"呼び出し回数の少ない順の操作の利用状況"`
+	}
+}
@@ -0,0 +1,120 @@
+package model
+
+import "net/http"
+
+// UserResponse はユーザーのレスポンスを表す構造体。パスワードハッシュは含まない。
+type UserResponse struct {
+	ID        int64  `json:"id" example:"1" doc:"ユーザーのID"`
+	Email     string `json:"email" example:"alice@example.com" doc:"メールアドレス"`
+	Name      string `json:"name" example:"Alice" doc:"表示名"`
+	Role      string `json:"role" enum:"viewer,editor,admin" example:"editor" doc:"ロール。viewerは読み取りのみ、editorはTodoの変更、adminはAPIキーやユーザーの管理もできる"`
+	CreatedAt string `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"登録日時"`
+}
+
+// AuthTokenBody はサインアップとログインのレスポンスボディを表す構造体
+type AuthTokenBody struct {
+	User        UserResponse `json:"user" doc:"認証したユーザー"`
+	AccessToken string       `json:"access_token" doc:"Authorization: Bearerで送信するアクセストークン"`
+	TokenType   string       `json:"token_type" example:"Bearer" doc:"トークンの種類"`
+	ExpiresAt   string       `json:"expires_at" example:"2024-01-02T00:00:00Z" doc:"アクセストークンの有効期限"`
+}
+
+// SignupInput はサインアップのリクエストボディを表す構造体
+type SignupInput struct {
+	Body struct {
+		Email           string `json:"email" format:"email" maxLength:"254" example:"alice@example.com" doc:"メールアドレス。大文字と小文字は区別しない"`
+		Name            string `json:"name" minLength:"1" maxLength:"100" example:"Alice" doc:"表示名"`
+		Password        string `json:"password" minLength:"8" maxLength:"200" doc:"パスワード（8文字以上）"`
+		InvitationToken string `json:"invitation_token,omitempty" required:"false" maxLength:"100" doc:"招待のトークン。招待制ではこのトークンかOIDCで確認したメールアドレスの招待が必要"`
+	}
+}
+
+// SignupOutput はサインアップのレスポンスを表す構造体
+type SignupOutput struct {
+	Body AuthTokenBody
+}
+
+// BootstrapInput は初期管理者の作成のリクエストボディを表す構造体
+type BootstrapInput struct {
+	Body struct {
+		Token    string `json:"token" maxLength:"100" doc:"起動時にログへ出力した初期管理者の作成トークン"`
+		Email    string `json:"email" format:"email" maxLength:"254" example:"admin@example.com" doc:"メールアドレス"`
+		Name     string `json:"name" minLength:"1" maxLength:"100" example:"Admin" doc:"表示名"`
+		Password string `json:"password" minLength:"8" maxLength:"200" doc:"パスワード（8文字以上）"`
+	}
+}
+
+// BootstrapOutput は初期管理者の作成のレスポンスを表す構造体
+type BootstrapOutput struct {
+	Body AuthTokenBody
+}
+
+// LoginInput はログインのリクエストボディを表す構造体
+type LoginInput struct {
+	Body struct {
+		Email    string `json:"email" maxLength:"254" example:"alice@example.com" doc:"メールアドレス"`
+		Password string `json:"password" maxLength:"200" doc:"パスワード"`
+	}
+}
+
+// LoginOutput はログインのレスポンスを表す構造体
+type LoginOutput struct {
+	Body AuthTokenBody
+}
+
+// ListUsersOutput はユーザー一覧取得のレスポンスを表す構造体
+type ListUsersOutput struct {
+	Body struct {
+		Users []UserResponse `json:"users" doc:"ユーザーのリスト"`
+	}
+}
+
+// UpdateUserRoleInput はユーザーのロール変更のリクエストを表す構造体
+type UpdateUserRoleInput struct {
+	ID   int64 `path:"id" doc:"ユーザーのID"`
+	Body struct {
+		Role string `json:"role" enum:"viewer,editor,admin" doc:"新しいロール"`
+	}
+}
+
+// UpdateUserRoleOutput はユーザーのロール変更のレスポンスを表す構造体
+type UpdateUserRoleOutput struct {
+	Body UserResponse
+}
+
+// DeleteUserInput はユーザー削除のリクエストパラメータを表す構造体
+type DeleteUserInput struct {
+	ID int64 `path:"id" doc:"ユーザーのID"`
+	ConfirmationParams
+}
+
+// DeleteUserOutput はユーザー削除のレスポンスを表す構造体
+type DeleteUserOutput struct {
+	// Status は削除した場合は200、確認トークンを返した場合は202
+	Status int
+	Body   struct {
+		Message      string                `json:"message" example:"User deleted successfully" doc:"削除結果メッセージ"`
+		Confirmation *ConfirmationResponse `json:"confirmation,omitempty" doc:"確認トークンを省略した場合に返す、削除の影響範囲と確認トークン"`
+	}
+}
+
+// OIDCLoginOutput はOIDCログイン開始のレスポンスを表す構造体
+type OIDCLoginOutput struct {
+	Location  string      `header:"Location" doc:"OIDCプロバイダーのログイン画面のURL"`
+	SetCookie http.Cookie `header:"Set-Cookie" doc:"コールバックで照合するstateなどを保持するCookie"`
+}
+
+// OIDCCallbackInput はOIDCプロバイダーからのコールバックのリクエストパラメータを表す構造体
+type OIDCCallbackInput struct {
+	Code             string `query:"code" doc:"プロバイダーが発行した認可コード"`
+	State            string `query:"state" doc:"ログイン開始時に発行したstate"`
+	Error            string `query:"error" doc:"プロバイダーでログインできなかった場合のエラーコード"`
+	ErrorDescription string `query:"error_description" doc:"プロバイダーでログインできなかった場合のエラーの説明"`
+	Session          string `cookie:"todo_oidc" doc:"ログイン開始時に設定したCookie"`
+}
+
+// OIDCCallbackOutput はOIDCプロバイダーからのコールバックのレスポンスを表す構造体
+type OIDCCallbackOutput struct {
+	SetCookie http.Cookie `header:"Set-Cookie" doc:"ログイン開始時に設定したCookieを削除する"`
+	Body      AuthTokenBody
+}
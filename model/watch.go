@@ -0,0 +1,47 @@
+package model
+
+// WatcherResponse はTodoをウォッチしているユーザーを表す構造体
+type WatcherResponse struct {
+	UserID    int64  `json:"user_id" example:"1" doc:"ウォッチしているユーザーのID"`
+	Name      string `json:"name" example:"山田太郎" doc:"ウォッチしているユーザーの名前"`
+	CreatedAt string `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"ウォッチを始めた日時"`
+}
+
+// WatchTodoInput はTodoのウォッチのリクエストパラメータを表す構造体
+type WatchTodoInput struct {
+	ID int64 `path:"id" doc:"ウォッチするTodoのID"`
+}
+
+// WatchTodoOutput はTodoのウォッチのレスポンスを表す構造体
+type WatchTodoOutput struct {
+	Body struct {
+		TodoID   int64             `json:"todo_id" example:"1" doc:"ウォッチしたTodoのID"`
+		Watchers []WatcherResponse `json:"watchers" doc:"Todoをウォッチしているユーザー。ウォッチを始めた順"`
+	}
+}
+
+// UnwatchTodoInput はTodoのウォッチ解除のリクエストパラメータを表す構造体
+type UnwatchTodoInput struct {
+	ID int64 `path:"id" doc:"ウォッチを解除するTodoのID"`
+}
+
+// UnwatchTodoOutput はTodoのウォッチ解除のレスポンスを表す構造体
+type UnwatchTodoOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Todo unwatched successfully" doc:"解除結果メッセージ"`
+	}
+}
+
+// BulkUnwatchTodosInput はTodoのウォッチの一括解除のボディを表す構造体
+type BulkUnwatchTodosInput struct {
+	Body struct {
+		IDs []int64 `json:"ids" minItems:"1" maxItems:"1000" uniqueItems:"true" doc:"ウォッチを解除するTodoのIDリスト"`
+	}
+}
+
+// BulkUnwatchTodosOutput はTodoのウォッチの一括解除のレスポンスを表す構造体
+type BulkUnwatchTodosOutput struct {
+	Body struct {
+		Count int64 `json:"count" example:"2" doc:"ウォッチを解除したTodoの件数。ウォッチしていなかったTodoは数えない"`
+	}
+}
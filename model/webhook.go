@@ -0,0 +1,92 @@
+package model
+
+// WebhookResponse はWebhookのレスポンスを表す構造体。署名の鍵は含まない。
+type WebhookResponse struct {
+	ID        int64    `json:"id" example:"1" doc:"WebhookのID"`
+	URL       string   `json:"url" example:"https://example.com/hooks/todo" doc:"配信先のURL"`
+	Events    []string `json:"events" example:"todo.created" doc:"配信するTodoのイベント"`
+	Active    bool     `json:"active" doc:"配信を有効にしているか"`
+	CreatedAt string   `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"作成日時"`
+	UpdatedAt string   `json:"updated_at" example:"2024-01-01T00:00:00Z" doc:"更新日時"`
+}
+
+// WebhookBody はWebhookの登録と更新のリクエストボディを表す構造体
+type WebhookBody struct {
+	URL    string   `json:"url" format:"uri" maxLength:"2048" doc:"配信先のURL。httpまたはhttps"`
+	Events []string `json:"events" minItems:"1" enum:"todo.created,todo.updated,todo.deleted,todo.completed" uniqueItems:"true" doc:"配信するTodoのイベント。todo.completedは未完了のTodoを完了にしたときに、todo.updatedとは別に配信する"`
+}
+
+// ListWebhooksOutput はWebhook一覧取得のレスポンスを表す構造体
+type ListWebhooksOutput struct {
+	Body struct {
+		Webhooks []WebhookResponse `json:"webhooks" doc:"Webhookのリスト"`
+	}
+}
+
+// CreateWebhookInput はWebhook登録のリクエストボディを表す構造体
+type CreateWebhookInput struct {
+	Body WebhookBody
+}
+
+// CreateWebhookOutput はWebhook登録のレスポンスを表す構造体
+type CreateWebhookOutput struct {
+	Body struct {
+		WebhookResponse
+		Secret string `json:"secret" example:"4f1c...e9" doc:"配信の署名の鍵。X-Todo-Signatureヘッダーのsha256=に続くHMAC-SHA256を検証するのに使う。この応答でのみ返されるため、安全な場所に保存してください"`
+	}
+}
+
+// GetWebhookInput はWebhookの取得と削除のリクエストパラメータを表す構造体
+type GetWebhookInput struct {
+	ID int64 `path:"id" doc:"WebhookのID"`
+}
+
+// GetWebhookOutput はWebhookの取得と更新のレスポンスを表す構造体
+type GetWebhookOutput struct {
+	Body WebhookResponse
+}
+
+// UpdateWebhookInput はWebhook更新のリクエストパラメータとボディを表す構造体
+type UpdateWebhookInput struct {
+	ID   int64 `path:"id" doc:"WebhookのID"`
+	Body struct {
+		WebhookBody
+		Active *bool `json:"active,omitempty" doc:"配信を有効にするか。省略すると変更しない。無効の間のイベントは配信しない"`
+	}
+}
+
+// DeleteWebhookOutput はWebhook削除のレスポンスを表す構造体
+type DeleteWebhookOutput struct {
+	Body struct {
+		Message string `json:"message" example:"Webhook deleted successfully" doc:"削除結果メッセージ"`
+	}
+}
+
+// WebhookDeliveryResponse はWebhookへの配信の1件を表す構造体
+type WebhookDeliveryResponse struct {
+	ID             int64   `json:"id" example:"1" doc:"配信のID。X-Todo-Deliveryヘッダーの値"`
+	Event          string  `json:"event" example:"todo.created" doc:"配信したTodoのイベント"`
+	TodoID         int64   `json:"todo_id" example:"1" doc:"対象TodoのID"`
+	Status         string  `json:"status" enum:"pending,succeeded,failed" example:"succeeded" doc:"配信の状態。pendingは送信前か再送待ち、failedは送信の回数の上限まで失敗した"`
+	Attempts       int64   `json:"attempts" example:"1" doc:"送信した回数"`
+	NextAttemptAt  *string `json:"next_attempt_at,omitempty" example:"2024-01-01T00:00:00Z" doc:"次に送信する日時。pendingの場合のみ"`
+	ResponseStatus *int64  `json:"response_status,omitempty" example:"200" doc:"最後の送信で受け取ったHTTPステータスコード"`
+	LastError      *string `json:"last_error,omitempty" doc:"最後に失敗した送信のエラー"`
+	CreatedAt      string  `json:"created_at" example:"2024-01-01T00:00:00Z" doc:"イベントの発生を記録した日時"`
+	DeliveredAt    *string `json:"delivered_at,omitempty" example:"2024-01-01T00:00:00Z" doc:"送信に成功した日時"`
+}
+
+// ListWebhookDeliveriesInput はWebhookの配信の記録取得のリクエストパラメータを表す構造体
+type ListWebhookDeliveriesInput struct {
+	ID     int64 `path:"id" doc:"WebhookのID"`
+	Cursor int64 `query:"cursor" minimum:"0" doc:"前のページのnext_cursor。指定したIDより前の配信を返す"`
+	Limit  int64 `query:"limit" minimum:"1" maximum:"200" default:"50" doc:"1ページあたりの件数"`
+}
+
+// ListWebhookDeliveriesOutput はWebhookの配信の記録取得のレスポンスを表す構造体
+type ListWebhookDeliveriesOutput struct {
+	Body struct {
+		Deliveries []WebhookDeliveryResponse `json:"deliveries" doc:"新しい順の配信のリスト。送信を終えた配信は保持期間を過ぎると削除される"`
+		NextCursor *int64                    `json:"next_cursor,omitempty" example:"20" doc:"次のページを取得するためのカーソル。最後のページでは省略される"`
+	}
+}
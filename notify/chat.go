@@ -0,0 +1,322 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/locale"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// チャットの送信先（プロジェクトごとに設定する）の通知チャネル名
+const (
+	// ChannelDiscord はDiscordのWebhookへ投稿するチャネル
+	ChannelDiscord = "discord"
+	// ChannelSlack はSlackの着信Webhookへ投稿するチャネル
+	ChannelSlack = "slack"
+	// ChannelTeams はMicrosoft Teamsの受信Webhook（ワークフロー）へ投稿するチャネル
+	ChannelTeams = "teams"
+)
+
+// KindTodoEvent はTodoの作成や更新などの変更の通知
+const KindTodoEvent = "todo_event"
+
+// Discordの埋め込みとメッセージの文字数の上限
+const (
+	discordMaxTitle       = 256
+	discordMaxDescription = 4096
+	discordMaxContent     = 2000
+)
+
+// Slackのブロックの文字数と項目数の上限
+const (
+	slackMaxText   = 3000
+	slackMaxFields = 10
+)
+
+// kindColors は通知の種類ごとのDiscordの埋め込みの色
+var kindColors = map[string]int{
+	KindReminder:      0x3498db,
+	KindEscalation:    0xe67e22,
+	KindTodoEvent:     0x2ecc71,
+	KindSecurityAlert: 0xe74c3c,
+	KindPanic:         0xe74c3c,
+}
+
+// eventColors はTodoのイベントごとのDiscordの埋め込みの色。ないイベントはkindColorsの色にする
+var eventColors = map[string]int{
+	"todo.completed": 0x95a5a6,
+	"todo.assigned":  0x9b59b6,
+	"todo.overdue":   0xe67e22,
+}
+
+// slackEscaper はSlackのmrkdwnで制御文字として扱われる文字をエスケープする
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// ChatNotifier はTodoが属するプロジェクトに設定されたDiscord、SlackまたはTeamsのチャットへ通知を投稿するNotifier。
+// 送信先のないTodoの通知やTodoに関係しない通知はfallbackで通知する。
+type ChatNotifier struct {
+	provider  string
+	queries   *db.Queries
+	fallback  Notifier
+	formatter *locale.Formatter
+	client    *http.Client
+}
+
+// NewChatNotifier はChatNotifierの新しいインスタンスを生成する。providerはChannelDiscord、ChannelSlackまたはChannelTeams。
+// 本文の日時はformatterの言語とタイムゾーンで書式化する。Webhook URLはプロジェクトの編集者が設定するため、内部のアドレスへは送信しない。
+func NewChatNotifier(provider string, queries *db.Queries, fallback Notifier, formatter *locale.Formatter, timeout time.Duration) *ChatNotifier {
+	return &ChatNotifier{
+		provider:  provider,
+		queries:   queries,
+		fallback:  fallback,
+		formatter: formatter,
+		client:    NewOutboundClient(timeout, false),
+	}
+}
+
+// Notify は通知のTodoが属するプロジェクトのチャットへ投稿する
+func (c *ChatNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.TodoID == 0 {
+		return c.fallback.Notify(ctx, n)
+	}
+	ch, err := c.queries.GetChatChannelForTodo(ctx, db.GetChatChannelForTodoParams{TodoID: n.TodoID, Provider: c.provider})
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.fallback.Notify(ctx, n)
+	}
+	if err != nil {
+		return fmt.Errorf("チャットの送信先の取得に失敗: %w", err)
+	}
+	return c.Post(ctx, ch.WebhookUrl, n, nil)
+}
+
+// Post は通知をチャットの形式に整形してWebhookのURLへ投稿する。2xx以外の応答はエラーとして扱う。
+// tmplを指定した場合は、既定のカードの代わりにテンプレートを適用した文章を投稿する。
+func (c *ChatNotifier) Post(ctx context.Context, url string, n Notification, tmpl *ChatTemplate) error {
+	var text string
+	if tmpl != nil {
+		t, err := tmpl.Execute(c.formatter, n)
+		if err != nil {
+			return err
+		}
+		text = t
+	}
+
+	var payload any
+	switch {
+	case c.provider == ChannelDiscord && tmpl != nil:
+		payload = map[string]any{
+			"content":          truncateRunes(discordMaxContent, text),
+			"allowed_mentions": map[string]any{"parse": []string{}},
+		}
+	case c.provider == ChannelDiscord:
+		payload = c.discordPayload(n)
+	case c.provider == ChannelSlack && tmpl != nil:
+		payload = map[string]any{"text": text}
+	case c.provider == ChannelSlack:
+		payload = c.slackPayload(n)
+	case c.provider == ChannelTeams && tmpl != nil:
+		payload = teamsCard([]map[string]any{{"type": "TextBlock", "text": text, "wrap": true}})
+	case c.provider == ChannelTeams:
+		payload = c.teamsPayload(n)
+	default:
+		return fmt.Errorf("未対応のチャットです: %s", c.provider)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("通知内容のエンコードに失敗: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("チャットへのリクエストの作成に失敗: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("チャットへの投稿に失敗: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("チャットがエラーを返しました: %s", resp.Status)
+	}
+	return nil
+}
+
+// Sample は送信先の試験投稿に使う、eventの見本の通知を返す。見出しと本文はformatterの言語で書く
+func (c *ChatNotifier) Sample(event, project, assignee string) Notification {
+	f := c.formatter
+	return Notification{
+		Kind:     KindTodoEvent,
+		Title:    f.Label("チャットの送信先の試験投稿", "Test post to the chat channel"),
+		Message:  f.Label("このプロジェクトのTodoの通知はここに投稿されます。", "Notifications about todos in this project will be posted here."),
+		Channel:  c.provider,
+		Event:    event,
+		Project:  project,
+		Assignee: assignee,
+		DueAt:    time.Now().Add(24 * time.Hour),
+	}
+}
+
+// heading は通知の種類を表す見出しを返す
+func (c *ChatNotifier) heading(n Notification) string {
+	switch n.Kind {
+	case KindReminder:
+		return c.formatter.Label("リマインダー", "Reminder")
+	case KindEscalation:
+		return c.formatter.Label("期限切れ", "Overdue")
+	case KindTodoEvent:
+		switch n.Event {
+		case "todo.created":
+			return c.formatter.Label("Todoを作成", "Todo created")
+		case "todo.moved":
+			return c.formatter.Label("Todoを移動", "Todo moved")
+		case "todo.completed":
+			return c.formatter.Label("Todoを完了", "Todo completed")
+		case "todo.assigned":
+			return c.formatter.Label("担当者を設定", "Todo assigned")
+		case "todo.overdue":
+			return c.formatter.Label("期限切れ", "Overdue")
+		}
+		return c.formatter.Label("Todoを更新", "Todo updated")
+	default:
+		return n.Kind
+	}
+}
+
+// facts は本文の下に表示する項目と値の組を返す。escapeでTodoの内容の値をエスケープし、linkで操作のリンクを書く
+func (c *ChatNotifier) facts(n Notification, escape func(string) string, link func(label, url string) string) [][2]string {
+	f := c.formatter
+	var facts [][2]string
+	if n.TodoID != 0 {
+		facts = append(facts, [2]string{"Todo", "#" + strconv.FormatInt(n.TodoID, 10)})
+	}
+	if n.Project != "" {
+		facts = append(facts, [2]string{f.Label("プロジェクト", "Project"), escape(n.Project)})
+	}
+	if n.Assignee != "" {
+		facts = append(facts, [2]string{f.Label("担当者", "Assignee"), escape(n.Assignee)})
+	}
+	if !n.DueAt.IsZero() {
+		facts = append(facts, [2]string{f.Label("期限", "Due"), f.DateTime(n.DueAt) + " (" + f.Relative(n.DueAt) + ")"})
+	}
+	if !n.RemindAt.IsZero() {
+		facts = append(facts, [2]string{f.Label("通知日時", "Remind at"), f.DateTime(n.RemindAt)})
+	}
+	var links []string
+	for _, l := range actionLinks(f, n) {
+		links = append(links, link(l[0], l[1]))
+	}
+	if len(links) > 0 {
+		facts = append(facts, [2]string{f.Label("操作", "Actions"), strings.Join(links, " · ")})
+	}
+	return facts
+}
+
+// markdownLink はDiscordとTeamsが表示するMarkdownのリンクを返す
+func markdownLink(label, url string) string {
+	return "[" + label + "](" + url + ")"
+}
+
+// noEscape はDiscordとTeamsの項目の値をそのまま返す
+func noEscape(s string) string {
+	return s
+}
+
+// color は通知のDiscordの埋め込みの色を返す
+func color(n Notification) int {
+	if n.Kind == KindTodoEvent {
+		if c, ok := eventColors[n.Event]; ok {
+			return c
+		}
+	}
+	return kindColors[n.Kind]
+}
+
+// discordPayload はDiscordのWebhookに送る埋め込みのメッセージを返す
+func (c *ChatNotifier) discordPayload(n Notification) map[string]any {
+	fields := []map[string]any{}
+	for _, f := range c.facts(n, noEscape, markdownLink) {
+		fields = append(fields, map[string]any{"name": f[0], "value": f[1], "inline": true})
+	}
+	embed := map[string]any{
+		"title":     truncateRunes(discordMaxTitle, n.Title),
+		"color":     color(n),
+		"fields":    fields,
+		"footer":    map[string]any{"text": c.heading(n)},
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	}
+	if n.Message != "" {
+		embed["description"] = truncateRunes(discordMaxDescription, n.Message)
+	}
+	return map[string]any{
+		"embeds": []map[string]any{embed},
+		// 本文に含まれる@everyoneなどでメンションしない
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+}
+
+// slackPayload はSlackの着信Webhookに送るBlock Kitのメッセージを返す。textは通知やブロックを表示できない場合に使われる
+func (c *ChatNotifier) slackPayload(n Notification) map[string]any {
+	text := "*" + slackEscaper.Replace(n.Title) + "*"
+	if n.Message != "" {
+		text += "\n" + slackEscaper.Replace(n.Message)
+	}
+	fields := []map[string]any{}
+	for _, f := range c.facts(n, slackEscaper.Replace, func(label, url string) string { return "<" + url + "|" + label + ">" }) {
+		if len(fields) == slackMaxFields {
+			break
+		}
+		fields = append(fields, map[string]any{"type": "mrkdwn", "text": "*" + f[0] + "*\n" + f[1]})
+	}
+	return map[string]any{
+		"text": c.heading(n) + ": " + n.Title,
+		"blocks": []map[string]any{
+			{"type": "context", "elements": []map[string]any{{"type": "plain_text", "text": c.heading(n)}}},
+			{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": truncateRunes(slackMaxText, text)}},
+			{"type": "section", "fields": fields},
+		},
+	}
+}
+
+// teamsPayload はTeamsの受信Webhookに送るAdaptive Cardのメッセージを返す
+func (c *ChatNotifier) teamsPayload(n Notification) map[string]any {
+	facts := []map[string]any{}
+	for _, f := range c.facts(n, noEscape, markdownLink) {
+		facts = append(facts, map[string]any{"title": f[0], "value": f[1]})
+	}
+	body := []map[string]any{
+		{"type": "TextBlock", "text": c.heading(n), "size": "Small", "isSubtle": true},
+		{"type": "TextBlock", "text": n.Title, "size": "Medium", "weight": "Bolder", "wrap": true},
+	}
+	if n.Message != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": n.Message, "wrap": true})
+	}
+	body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+	return teamsCard(body)
+}
+
+// teamsCard は本文の要素を並べたAdaptive Cardのメッセージを返す
+func teamsCard(body []map[string]any) map[string]any {
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
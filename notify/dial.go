@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress は送信先がループバックやプライベートのアドレスだったことを表すエラー
+var ErrPrivateAddress = errors.New("ループバックやプライベートのアドレスへは送信できません")
+
+// NewOutboundClient はユーザーが指定したURLへ送信するためのhttp.Clientを返す。
+// allowPrivateがfalseの場合は、サーバーから内部のサービスやメタデータのアドレスへ送らせないよう、
+// ループバック、プライベート、リンクローカルのアドレスへは接続せずにErrPrivateAddressを返す。
+func NewOutboundClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		// 名前解決の後の接続先のアドレスで確認し、DNSの応答を変えて内部のアドレスに送らせる攻撃を防ぐ
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+				return ErrPrivateAddress
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout: timeout,
+		// プロキシを経由すると接続先のアドレスを確認できないため、環境変数のプロキシは使わない
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: timeout},
+		// リダイレクト先は確認していないURLのため、追わずに応答をそのまま結果にする
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
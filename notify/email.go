@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"go-huma-test/locale"
+	"go-huma-test/secrets"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+)
+
+// EmailNotifier は通知をSMTPでメール送信するNotifier
+type EmailNotifier struct {
+	addr      string
+	from      string
+	to        []string
+	secrets   *secrets.Manager
+	formatter *locale.Formatter
+	html      *HTMLTemplate
+}
+
+// NewEmailNotifier はEmailNotifierの新しいインスタンスを生成する。
+// SMTP認証の情報は送信のたびにsecretsから取得し、ユーザー名が設定されていない場合は認証を行わない。
+// 本文の日時はformatterの言語とタイムゾーンで書式化する。
+// htmlにテンプレートがある種類の通知は、テキストとHTMLの本文を両方含むメールにする。htmlがnilの場合はテキストの本文だけを送る。
+func NewEmailNotifier(addr, from string, to []string, secrets *secrets.Manager, formatter *locale.Formatter, html *HTMLTemplate) *EmailNotifier {
+	return &EmailNotifier{
+		addr:      addr,
+		from:      from,
+		to:        to,
+		secrets:   secrets,
+		formatter: formatter,
+		html:      html,
+	}
+}
+
+// auth はSMTP認証の情報を取得する
+func (e *EmailNotifier) auth(ctx context.Context) (smtp.Auth, error) {
+	username, ok, err := e.secrets.Lookup(ctx, secrets.SMTPUsername)
+	if err != nil || !ok {
+		return nil, err
+	}
+	password, _, err := e.secrets.Lookup(ctx, secrets.SMTPPassword)
+	if err != nil {
+		return nil, err
+	}
+	host, _, _ := net.SplitHostPort(e.addr)
+	return smtp.PlainAuth("", username, password, host), nil
+}
+
+// Notify は通知内容をメールで送信する。宛先のユーザーが指定されている場合はそのユーザーに送る。
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	auth, err := e.auth(ctx)
+	if err != nil {
+		return fmt.Errorf("SMTP認証情報の取得に失敗: %w", err)
+	}
+
+	to := e.to
+	if n.Recipient != "" {
+		to = []string{n.Recipient}
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	switch n.Kind {
+	case KindSecurityAlert:
+		fmt.Fprintf(&msg, "Subject: [Security] %s\r\n", n.Title)
+	case KindEscalation:
+		fmt.Fprintf(&msg, "Subject: [Overdue] %s\r\n", n.Title)
+	case KindPanic:
+		fmt.Fprintf(&msg, "Subject: [Panic] %s\r\n", n.Title)
+	case KindStaleDigest:
+		fmt.Fprintf(&msg, "Subject: [Review] %s\r\n", n.Title)
+	case KindWatch:
+		fmt.Fprintf(&msg, "Subject: [Watching] %s\r\n", n.Title)
+	case KindDueDigest:
+		fmt.Fprintf(&msg, "Subject: [Due] %s\r\n", n.Title)
+	case KindDiskSpace:
+		fmt.Fprintf(&msg, "Subject: [Disk] %s\r\n", n.Title)
+	default:
+		fmt.Fprintf(&msg, "Subject: [Reminder] %s\r\n", n.Title)
+	}
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Language: %s\r\n", e.formatter.Language())
+
+	text := e.text(n)
+	var html []byte
+	if e.html != nil {
+		body, ok, err := e.html.Execute(e.formatter, n)
+		if err != nil {
+			return err
+		}
+		if ok {
+			html = body
+		}
+	}
+	if html == nil {
+		msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		msg.WriteString("\r\n")
+		msg.WriteString(text)
+	} else if err := writeAlternative(&msg, text, html); err != nil {
+		return fmt.Errorf("メールの本文の作成に失敗: %w", err)
+	}
+
+	if err := smtp.SendMail(e.addr, auth, e.from, to, []byte(msg.String())); err != nil {
+		return fmt.Errorf("メール送信に失敗: %w", err)
+	}
+	return nil
+}
+
+// text は通知のテキストの本文を返す
+func (e *EmailNotifier) text(n Notification) string {
+	var msg strings.Builder
+	switch n.Kind {
+	case KindSecurityAlert, KindPanic, KindDiskSpace:
+		fmt.Fprintf(&msg, "%s\r\n\r\n%s\r\n", n.Title, n.Message)
+	case KindStaleDigest, KindDueDigest:
+		// 本文は改行を含むため、メールの改行に揃える
+		fmt.Fprintf(&msg, "%s\r\n\r\n%s\r\n", n.Title, strings.ReplaceAll(n.Message, "\n", "\r\n"))
+	case KindEscalation:
+		fmt.Fprintf(&msg, "%s\r\n\r\nTodo ID: %d\r\n%s\r\n", n.Title, n.TodoID, n.Message)
+	case KindWatch:
+		// コメントの本文は改行を含むため、メールの改行に揃える
+		fmt.Fprintf(&msg, "%s\r\n\r\nTodo ID: %d\r\n%s\r\n", n.Title, n.TodoID, strings.ReplaceAll(n.Message, "\n", "\r\n"))
+	default:
+		fmt.Fprintf(&msg, "%s\r\n\r\nTodo ID: %d\r\n%s: %s\r\n", n.Title, n.TodoID, e.formatter.Label("通知日時", "Remind at"), e.formatter.DateTime(n.RemindAt))
+	}
+	if links := actionLinks(e.formatter, n); len(links) > 0 {
+		msg.WriteString("\r\n")
+		for _, l := range links {
+			fmt.Fprintf(&msg, "%s: %s\r\n", l[0], l[1])
+		}
+	}
+	return msg.String()
+}
+
+// writeAlternative はテキストとHTMLの本文をmultipart/alternativeの本文として書き込む。
+// どちらも行の長さの上限を超えないようquoted-printableで符号化する。
+func writeAlternative(msg *strings.Builder, text string, html []byte) error {
+	mw := multipart.NewWriter(msg)
+	fmt.Fprintf(msg, "Content-Type: multipart/alternative; boundary=%q\r\n", mw.Boundary())
+	msg.WriteString("\r\n")
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=UTF-8", []byte(text)},
+		{"text/html; charset=UTF-8", html},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qw := quotedprintable.NewWriter(w)
+		if _, err := qw.Write(part.body); err != nil {
+			return err
+		}
+		if err := qw.Close(); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go-huma-test/locale"
+	"html/template"
+	"os"
+	"time"
+)
+
+//go:embed email.html
+var defaultHTMLTemplate string
+
+// htmlTemplateKinds はHTMLの本文を書く通知の種類。ほかの種類はテキストの本文だけを送る
+var htmlTemplateKinds = []string{KindReminder, KindDueDigest, KindStaleDigest}
+
+// htmlTemplateFuncs はメールのテンプレートで使える関数
+var htmlTemplateFuncs = template.FuncMap{
+	// sub は件数の差を求める。「ほか何件」の表示に使う
+	"sub": func(a, b int) int { return a - b },
+}
+
+// HTMLTemplate は通知をメールのHTMLの本文にするGoのhtml/template。
+// 通知の種類（Kind）と同じ名前のテンプレートで本文を書き、既定のテンプレートを同じ名前の定義で置き換えられる。
+type HTMLTemplate struct {
+	tmpl *template.Template
+}
+
+// htmlLink はHTMLの本文に並べる操作のリンク
+type htmlLink struct {
+	Label string
+	URL   string
+}
+
+// htmlData はテンプレートに渡すデータ。通知のフィールドに加えて、.Fで日時の書式化と見出しの言語の切り替えを行える。
+type htmlData struct {
+	Notification
+	// F は本文の言語とタイムゾーンの書式化。{{.F.DateTime .RemindAt}}や{{.F.Label "期限" "Due"}}のように使う
+	F *locale.Formatter
+	// Links は通知のActionsを見出しとURLの組にしてactionlink.QuickActionsの順に並べたもの
+	Links []htmlLink
+}
+
+// ParseHTMLTemplate は既定のテンプレートに続けてsrcを解析する。srcが空の場合は既定のテンプレートだけを使う。
+// 解析に加えて見本の通知で種類ごとのテンプレートを実行し、実行できないテンプレートはエラーにする。
+func ParseHTMLTemplate(src string) (*HTMLTemplate, error) {
+	tmpl, err := template.New("email").Funcs(htmlTemplateFuncs).Option("missingkey=error").Parse(defaultHTMLTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("既定のテンプレートを解析できません: %w", err)
+	}
+	if src != "" {
+		if tmpl, err = tmpl.Parse(src); err != nil {
+			return nil, fmt.Errorf("テンプレートを解析できません: %w", err)
+		}
+	}
+
+	t := &HTMLTemplate{tmpl: tmpl}
+	now := time.Now()
+	for _, kind := range htmlTemplateKinds {
+		sample := Notification{
+			Kind:     kind,
+			TodoID:   1,
+			Title:    `見本の "通知"`,
+			Message:  "1行目\n2行目",
+			Channel:  ChannelEmail,
+			RemindAt: now,
+			Items: []DigestItem{
+				{TodoID: 1, Title: "見本のTodo", Project: "見本のプロジェクト", UpdatedAt: now, DueAt: now, Overdue: true, Actions: map[string]string{}},
+			},
+			Total:   2,
+			Actions: map[string]string{},
+		}
+		if _, _, err := t.Execute(locale.New(locale.Japanese, time.UTC), sample); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// LoadHTMLTemplate はpathのファイルで既定のテンプレートを置き換えたテンプレートを返す。pathが空の場合は既定のテンプレートを返す
+func LoadHTMLTemplate(path string) (*HTMLTemplate, error) {
+	if path == "" {
+		return ParseHTMLTemplate("")
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("メールのテンプレートのファイルを読み込めません: %w", err)
+	}
+	return ParseHTMLTemplate(string(src))
+}
+
+// Execute は通知にテンプレートを適用し、HTMLの本文を返す。通知の種類のテンプレートがない場合はfalseを返す
+func (t *HTMLTemplate) Execute(f *locale.Formatter, n Notification) ([]byte, bool, error) {
+	if t.tmpl.Lookup(n.Kind) == nil {
+		return nil, false, nil
+	}
+	data := htmlData{Notification: n, F: f}
+	for _, l := range actionLinks(f, n) {
+		data.Links = append(data.Links, htmlLink{Label: l[0], URL: l[1]})
+	}
+	var buf bytes.Buffer
+	if err := t.tmpl.ExecuteTemplate(&buf, n.Kind, data); err != nil {
+		return nil, false, fmt.Errorf("テンプレートを実行できません: %w", err)
+	}
+	return buf.Bytes(), true, nil
+}
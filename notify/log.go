@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogNotifier は通知をサーバーログに出力するNotifier
+type LogNotifier struct{}
+
+// NewLogNotifier はLogNotifierの新しいインスタンスを生成する
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify は通知内容をログに出力する
+func (l *LogNotifier) Notify(_ context.Context, n Notification) error {
+	switch n.Kind {
+	case KindSecurityAlert:
+		slog.Warn("セキュリティ警告", "title", n.Title, "message", n.Message)
+		return nil
+	case KindPanic:
+		slog.Error("パニックの警告", "title", n.Title, "message", n.Message)
+		return nil
+	case KindDiskSpace:
+		slog.Warn("ディスクの空き容量の通知", "title", n.Title, "message", n.Message)
+		return nil
+	case KindEscalation:
+		slog.Info("優先度の引き上げ", "todo_id", n.TodoID, "title", n.Title, "message", n.Message, "recipient", n.Recipient)
+		return nil
+	case KindStaleDigest:
+		slog.Info("放置されたTodoの通知", "title", n.Title, "message", n.Message, "recipient", n.Recipient, "count", len(n.Items))
+		return nil
+	case KindDueDigest:
+		slog.Info("期限が近いTodoのまとめ", "title", n.Title, "message", n.Message, "recipient", n.Recipient, "count", len(n.Items))
+		return nil
+	case KindWatch:
+		slog.Info("ウォッチしているTodoの通知", "todo_id", n.TodoID, "event", n.Event, "title", n.Title, "message", n.Message, "recipient", n.Recipient)
+		return nil
+	}
+	slog.Info("リマインダー", "todo_id", n.TodoID, "title", n.Title, "remind_at", n.RemindAt, "actions", n.Actions)
+	return nil
+}
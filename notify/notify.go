@@ -0,0 +1,139 @@
+// Package notify はリマインダーやセキュリティ警告を外部へ通知するチャネルを提供する。
+// 各チャネルはNotifierインターフェースを実装し、Routerによって通知のチャネル名で振り分けられる。
+package notify
+
+import (
+	"context"
+	"go-huma-test/actionlink"
+	"go-huma-test/locale"
+	"log/slog"
+	"time"
+)
+
+// 通知チャネル名
+const (
+	// ChannelLog はサーバーログへ出力するチャネル
+	ChannelLog = "log"
+	// ChannelWebhook はWebhookへHTTP POSTするチャネル
+	ChannelWebhook = "webhook"
+	// ChannelEmail はSMTPでメールを送信するチャネル
+	ChannelEmail = "email"
+)
+
+// 通知の種類
+const (
+	// KindReminder はリマインダーの通知
+	KindReminder = "reminder"
+	// KindSecurityAlert は不審な認証などのセキュリティ警告
+	KindSecurityAlert = "security_alert"
+	// KindEscalation は期限切れのTodoの優先度を引き上げたことの通知
+	KindEscalation = "escalation"
+	// KindPanic はハンドラーでパニックが発生したことの警告
+	KindPanic = "panic"
+	// KindStaleDigest は長く更新されていないTodoをまとめて見直しを促す通知
+	KindStaleDigest = "stale_digest"
+	// KindWatch はユーザーがウォッチしているTodoの変更やコメントの通知
+	KindWatch = "watch"
+	// KindDueDigest は期限が今日までの未完了のTodoをユーザーごとにまとめた毎日の通知
+	KindDueDigest = "due_digest"
+	// KindDiskSpace はデータベースのディスクの空き容量が不足して変更を拒否し始めたことと、回復したことの通知
+	KindDiskSpace = "disk_space"
+)
+
+// Notification は通知する内容を表す構造体
+type Notification struct {
+	Kind       string `json:"kind"`
+	ReminderID int64  `json:"reminder_id,omitempty"`
+	TodoID     int64  `json:"todo_id,omitempty"`
+	Title      string `json:"title"`
+	Message    string `json:"message,omitempty"`
+	Channel    string `json:"channel"`
+	// Event はKindTodoEventとKindWatchの場合の変更の種類（todo.createdなど）
+	Event    string    `json:"event,omitempty"`
+	RemindAt time.Time `json:"remind_at,omitzero"`
+	// Project、Assignee、DueAtはKindTodoEventの場合のTodoのプロジェクト名、担当者、期限
+	Project  string    `json:"project,omitempty"`
+	Assignee string    `json:"assignee,omitempty"`
+	DueAt    time.Time `json:"due_at,omitzero"`
+	// Recipient は通知を受け取るユーザーのメールアドレス。空の場合はチャネルに設定された宛先に送る。
+	Recipient string `json:"recipient,omitempty"`
+	// Items はKindStaleDigestとKindDueDigestの場合にまとめて通知するTodo
+	Items []DigestItem `json:"items,omitempty"`
+	// Total はまとめて通知するTodoの件数。Itemsは件数の上限で切り詰めるため、Itemsより多い場合がある
+	Total int `json:"total,omitempty"`
+	// Actions は操作の種類（completeやdeleteなど）ごとの、ログインせずに1回だけ操作できるリンク
+	Actions map[string]string `json:"actions,omitempty"`
+}
+
+// DigestItem はまとめて通知するTodo1件を表す構造体
+type DigestItem struct {
+	TodoID    int64     `json:"todo_id"`
+	Title     string    `json:"title"`
+	Project   string    `json:"project,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// DueAt はKindDueDigestの場合のTodoの期限
+	DueAt time.Time `json:"due_at,omitzero"`
+	// Overdue はKindDueDigestの場合に、期限を過ぎていることを表す
+	Overdue bool `json:"overdue,omitempty"`
+	// Actions は操作の種類（archiveやsnooze、complete）ごとの、ログインせずに操作できるリンク
+	Actions map[string]string `json:"actions,omitempty"`
+}
+
+// actionLabel は操作のリンクの見出しを返す
+func actionLabel(f *locale.Formatter, action string) string {
+	switch action {
+	case actionlink.ActionComplete:
+		return f.Label("完了にする", "Complete")
+	case actionlink.ActionSnoozeDay:
+		return f.Label("1日後に再通知", "Snooze 1 day")
+	case actionlink.ActionDelete:
+		return f.Label("削除", "Delete")
+	default:
+		return action
+	}
+}
+
+// actionLinks は通知に含める操作のリンクを、見出しとURLの組でactionlink.QuickActionsの順に返す
+func actionLinks(f *locale.Formatter, n Notification) [][2]string {
+	var links [][2]string
+	for _, action := range actionlink.QuickActions {
+		if url, ok := n.Actions[action]; ok {
+			links = append(links, [2]string{actionLabel(f, action), url})
+		}
+	}
+	return links
+}
+
+// Notifier は通知を送信するインターフェース
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Router はNotificationのチャネルに応じて通知先を振り分けるNotifier。
+// 未設定のチャネルはfallbackで通知する。
+type Router struct {
+	notifiers map[string]Notifier
+	fallback  Notifier
+}
+
+// NewRouter はRouterの新しいインスタンスを生成する
+func NewRouter(fallback Notifier) *Router {
+	return &Router{
+		notifiers: make(map[string]Notifier),
+		fallback:  fallback,
+	}
+}
+
+// Register はチャネルに通知先を登録する
+func (r *Router) Register(channel string, n Notifier) {
+	r.notifiers[channel] = n
+}
+
+// Notify はチャネルに登録された通知先で通知する
+func (r *Router) Notify(ctx context.Context, n Notification) error {
+	if notifier, ok := r.notifiers[n.Channel]; ok {
+		return notifier.Notify(ctx, n)
+	}
+	slog.Warn("通知チャネルが設定されていないため代替チャネルで通知", "channel", n.Channel, "kind", n.Kind)
+	return r.fallback.Notify(ctx, n)
+}
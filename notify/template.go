@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/locale"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ErrInvalidPayload はテンプレートを適用した結果がJSONとして不正であることを表すエラー
+var ErrInvalidPayload = errors.New("テンプレートの出力がJSONではありません")
+
+// templateFuncs はペイロードのテンプレートで使える関数
+var templateFuncs = template.FuncMap{
+	// json は値をJSONとしてエンコードする。文字列を引用符とエスケープ付きで埋め込むために使う。
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// default は値が空の場合に代わりの値を返す
+	"default": func(def, v any) any {
+		if v == nil || v == "" || v == 0 || v == int64(0) {
+			return def
+		}
+		return v
+	},
+	// truncate は文字列を指定した文字数で切り詰める。Discordの2000文字などの上限に合わせるために使う。
+	"truncate": truncateRunes,
+	// rfc3339 は日時をRFC 3339形式の文字列にする。ゼロ値は空文字にする。
+	"rfc3339": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// PayloadTemplate は通知をWebhookの受信側が求める形のJSONに変換するGoのテンプレート。
+// テンプレートにはNotificationが渡され、{{.Title}}や{{json .Message}}のようにフィールドを参照する。
+type PayloadTemplate struct {
+	tmpl *template.Template
+}
+
+// ParsePayloadTemplate はテンプレートを解析する。
+// 解析に加えて見本の通知で実行し、出力がJSONにならないテンプレートはエラーにする。
+func ParsePayloadTemplate(src string) (*PayloadTemplate, error) {
+	tmpl, err := template.New("payload").Funcs(templateFuncs).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("テンプレートを解析できません: %w", err)
+	}
+	t := &PayloadTemplate{tmpl: tmpl}
+	if _, err := t.Execute(Notification{
+		Kind:     KindReminder,
+		TodoID:   1,
+		Title:    `見本の "通知"`,
+		Message:  "1行目\n2行目",
+		Channel:  ChannelWebhook,
+		RemindAt: time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Execute は通知にテンプレートを適用し、Webhookの本文を返す
+func (t *PayloadTemplate) Execute(n Notification) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, n); err != nil {
+		return nil, fmt.Errorf("テンプレートを実行できません: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		// 出力の全体はエラーメッセージに含めず、原因を探せる程度に先頭のみを含める
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPayload, truncateRunes(200, buf.String()))
+	}
+	return buf.Bytes(), nil
+}
+
+// ChatTemplate は通知をチャットに投稿する文章にするGoのテンプレート。
+// テンプレートにはNotificationのフィールドと、日時の書式化と見出しの言語を切り替える.Fが渡される。
+type ChatTemplate struct {
+	tmpl *template.Template
+}
+
+// chatTemplateData はチャットのテンプレートに渡すデータ
+type chatTemplateData struct {
+	Notification
+	// F は本文の言語とタイムゾーンの書式化。{{.F.DateTime .DueAt}}や{{.F.Label "期限" "Due"}}のように使う
+	F *locale.Formatter
+}
+
+// ParseChatTemplate はチャットのテンプレートを解析する。
+// 解析に加えて見本の通知で実行し、実行できないテンプレートや空の文章になるテンプレートはエラーにする。
+func ParseChatTemplate(src string) (*ChatTemplate, error) {
+	tmpl, err := template.New("chat").Funcs(templateFuncs).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("テンプレートを解析できません: %w", err)
+	}
+	t := &ChatTemplate{tmpl: tmpl}
+	now := time.Now()
+	if _, err := t.Execute(locale.New(locale.Japanese, time.UTC), Notification{
+		Kind:     KindTodoEvent,
+		TodoID:   1,
+		Title:    "見本のTodo",
+		Message:  "1行目\n2行目",
+		Event:    "todo.updated",
+		Project:  "見本のプロジェクト",
+		Assignee: "alice",
+		DueAt:    now,
+		Actions:  map[string]string{},
+	}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Execute は通知にテンプレートを適用し、投稿する文章を返す
+func (t *ChatTemplate) Execute(f *locale.Formatter, n Notification) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, chatTemplateData{Notification: n, F: f}); err != nil {
+		return "", fmt.Errorf("テンプレートを実行できません: %w", err)
+	}
+	text := strings.TrimSpace(buf.String())
+	if text == "" {
+		return "", errors.New("テンプレートの出力が空です")
+	}
+	return text, nil
+}
+
+// truncateRunes は文字列を先頭からn文字に切り詰める
+func truncateRunes(n int, s string) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// LookupChatTemplate はイベントの種類ごとのテンプレートを持つJSONオブジェクトから、eventのテンプレートを解析して返す。
+// eventのテンプレートがない場合はnilを返す。
+func LookupChatTemplate(templates, event string) (*ChatTemplate, error) {
+	var sources map[string]string
+	if err := json.Unmarshal([]byte(templates), &sources); err != nil {
+		return nil, fmt.Errorf("チャットのテンプレートを解析できません: %w", err)
+	}
+	src, ok := sources[event]
+	if !ok {
+		return nil, nil
+	}
+	return ParseChatTemplate(src)
+}
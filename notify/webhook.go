@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go-huma-test/secrets"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader はWebhookの本文のHMAC-SHA256署名を格納するヘッダー
+const SignatureHeader = "X-Todo-Signature"
+
+// WebhookNotifier は通知内容をJSONとして指定URLへPOSTするNotifier
+type WebhookNotifier struct {
+	url      string
+	client   *http.Client
+	secrets  *secrets.Manager
+	template *PayloadTemplate
+}
+
+// NewWebhookNotifier はWebhookNotifierの新しいインスタンスを生成する。
+// 署名鍵がsecretsに設定されている場合は、本文の署名をSignatureHeaderに付与する。
+// templateを指定した場合は、Notificationの代わりにテンプレートの出力を本文として送る。
+func NewWebhookNotifier(url string, timeout time.Duration, secrets *secrets.Manager, template *PayloadTemplate) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:      url,
+		client:   &http.Client{Timeout: timeout},
+		secrets:  secrets,
+		template: template,
+	}
+}
+
+// Sign は本文のHMAC-SHA256署名をSignatureHeaderの値の形式（sha256=<16進数>）で返す
+func Sign(key string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// payload は通知をWebhookの本文にする
+func (w *WebhookNotifier) payload(n Notification) ([]byte, error) {
+	if w.template != nil {
+		return w.template.Execute(n)
+	}
+	body, err := json.Marshal(n)
+	if err != nil {
+		return nil, fmt.Errorf("通知内容のエンコードに失敗: %w", err)
+	}
+	return body, nil
+}
+
+// Notify は通知内容をWebhookへ送信する。2xx以外の応答はエラーとして扱う。
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := w.payload(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Webhookリクエストの作成に失敗: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	key, ok, err := w.secrets.Lookup(ctx, secrets.WebhookSecret)
+	if err != nil {
+		return fmt.Errorf("Webhook署名鍵の取得に失敗: %w", err)
+	}
+	if ok {
+		req.Header.Set(SignatureHeader, Sign(key, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Webhookの送信に失敗: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhookがエラーを返しました: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+// Package ot はテキストの操作変換（Operational Transformation）を提供する。
+// 操作は文字列の先頭から順にretain（保持）、insert（挿入）、delete（削除）を並べたもので、
+// 同じ版の文字列に対して並行に作られた2つの操作をTransformで互いの後に適用できる形に変換する。
+// 文字数はUnicodeのコードポイント単位で数える。
+package ot
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrLengthMismatch は操作の長さが適用する文字列や並行する操作と一致しないことを表す
+var ErrLengthMismatch = errors.New("操作の長さが文字列と一致しません")
+
+// Component は操作の1要素を表す構造体。Retain、Insert、Deleteのいずれか1つだけを指定する。
+type Component struct {
+	Retain int    `json:"retain,omitempty" minimum:"0" doc:"変更せずに進める文字数"`
+	Insert string `json:"insert,omitempty" doc:"現在の位置に挿入する文字列"`
+	Delete int    `json:"delete,omitempty" minimum:"0" doc:"現在の位置から削除する文字数"`
+}
+
+// Operation は文字列全体に対する1つの操作を表す
+type Operation []Component
+
+// Validate は各要素にRetain、Insert、Deleteのいずれか1つだけが指定されていることを検証する
+func (o Operation) Validate() error {
+	for i, c := range o {
+		n := 0
+		if c.Retain != 0 {
+			n++
+		}
+		if c.Insert != "" {
+			n++
+		}
+		if c.Delete != 0 {
+			n++
+		}
+		if n != 1 || c.Retain < 0 || c.Delete < 0 {
+			return fmt.Errorf("%d番目の要素にはretain、insert、deleteのいずれか1つを正の値で指定してください", i)
+		}
+	}
+	return nil
+}
+
+// BaseLen は操作を適用する前の文字列の長さを返す
+func (o Operation) BaseLen() int {
+	n := 0
+	for _, c := range o {
+		n += c.Retain + c.Delete
+	}
+	return n
+}
+
+// TargetLen は操作を適用した後の文字列の長さを返す
+func (o Operation) TargetLen() int {
+	n := 0
+	for _, c := range o {
+		n += c.Retain + utf8.RuneCountInString(c.Insert)
+	}
+	return n
+}
+
+// Apply は文字列に操作を適用した結果を返す
+func (o Operation) Apply(s string) (string, error) {
+	runes := []rune(s)
+	if o.BaseLen() != len(runes) {
+		return "", ErrLengthMismatch
+	}
+	out := make([]rune, 0, o.TargetLen())
+	pos := 0
+	for _, c := range o {
+		switch {
+		case c.Retain > 0:
+			out = append(out, runes[pos:pos+c.Retain]...)
+			pos += c.Retain
+		case c.Insert != "":
+			out = append(out, []rune(c.Insert)...)
+		case c.Delete > 0:
+			pos += c.Delete
+		}
+	}
+	return string(out), nil
+}
+
+// Replace は長さbaseLenの文字列全体をsに置き換える操作を返す
+func Replace(baseLen int, s string) Operation {
+	var b builder
+	b.insert(s)
+	b.delete(baseLen)
+	return b.op
+}
+
+// builder は隣り合う同じ種類の要素をまとめながら操作を組み立てる
+type builder struct {
+	op Operation
+}
+
+func (b *builder) retain(n int) {
+	if n <= 0 {
+		return
+	}
+	if last := len(b.op) - 1; last >= 0 && b.op[last].Retain > 0 {
+		b.op[last].Retain += n
+		return
+	}
+	b.op = append(b.op, Component{Retain: n})
+}
+
+// insert は挿入を追加する。削除の直後の挿入は削除の前に置き、同じ結果の操作を1通りの表現にする。
+func (b *builder) insert(s string) {
+	if s == "" {
+		return
+	}
+	last := len(b.op) - 1
+	if last >= 0 && b.op[last].Delete > 0 {
+		if last > 0 && b.op[last-1].Insert != "" {
+			b.op[last-1].Insert += s
+			return
+		}
+		b.op = append(b.op[:last], Component{Insert: s}, b.op[last])
+		return
+	}
+	if last >= 0 && b.op[last].Insert != "" {
+		b.op[last].Insert += s
+		return
+	}
+	b.op = append(b.op, Component{Insert: s})
+}
+
+func (b *builder) delete(n int) {
+	if n <= 0 {
+		return
+	}
+	if last := len(b.op) - 1; last >= 0 && b.op[last].Delete > 0 {
+		b.op[last].Delete += n
+		return
+	}
+	b.op = append(b.op, Component{Delete: n})
+}
+
+// cursor は操作の要素を途中まで消費しながら読み進める
+type cursor struct {
+	op  Operation
+	i   int
+	cur Component
+	ok  bool
+}
+
+func newCursor(op Operation) *cursor {
+	c := &cursor{op: op}
+	c.next()
+	return c
+}
+
+func (c *cursor) next() {
+	if c.i >= len(c.op) {
+		c.ok = false
+		return
+	}
+	c.cur = c.op[c.i]
+	c.ok = true
+	c.i++
+}
+
+// Transform は同じ文字列に対して並行に作られた操作aとbを、それぞれ相手の後に適用できるよう変換する。
+// apply(apply(s, a), b')とapply(apply(s, b), a')は同じ結果になる。
+// 同じ位置への挿入はaを先に置く。
+func Transform(a, b Operation) (aPrime, bPrime Operation, err error) {
+	if a.BaseLen() != b.BaseLen() {
+		return nil, nil, ErrLengthMismatch
+	}
+	var ab, bb builder
+	ca, cb := newCursor(a), newCursor(b)
+	for ca.ok || cb.ok {
+		if ca.ok && ca.cur.Insert != "" {
+			ab.insert(ca.cur.Insert)
+			bb.retain(utf8.RuneCountInString(ca.cur.Insert))
+			ca.next()
+			continue
+		}
+		if cb.ok && cb.cur.Insert != "" {
+			ab.retain(utf8.RuneCountInString(cb.cur.Insert))
+			bb.insert(cb.cur.Insert)
+			cb.next()
+			continue
+		}
+		if !ca.ok || !cb.ok {
+			return nil, nil, ErrLengthMismatch
+		}
+
+		lenA := ca.cur.Retain + ca.cur.Delete
+		lenB := cb.cur.Retain + cb.cur.Delete
+		n := min(lenA, lenB)
+		switch {
+		case ca.cur.Retain > 0 && cb.cur.Retain > 0:
+			ab.retain(n)
+			bb.retain(n)
+		case ca.cur.Delete > 0 && cb.cur.Retain > 0:
+			ab.delete(n)
+		case ca.cur.Retain > 0 && cb.cur.Delete > 0:
+			bb.delete(n)
+		}
+		// 両方が削除する範囲はどちらの変換後の操作にも含めない
+
+		consume(ca, n)
+		consume(cb, n)
+	}
+	return ab.op, bb.op, nil
+}
+
+// consume は現在のretainまたはdeleteの要素をn文字分消費する
+func consume(c *cursor, n int) {
+	if c.cur.Retain > 0 {
+		c.cur.Retain -= n
+		if c.cur.Retain == 0 {
+			c.next()
+		}
+		return
+	}
+	c.cur.Delete -= n
+	if c.cur.Delete == 0 {
+		c.next()
+	}
+}
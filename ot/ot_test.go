@@ -0,0 +1,152 @@
+package ot
+
+import (
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		op   Operation
+		ok   bool
+	}{
+		{"valid", Operation{{Retain: 2}, {Insert: "x"}, {Delete: 1}}, true},
+		{"empty component", Operation{{}}, false},
+		{"two fields", Operation{{Retain: 1, Insert: "x"}}, false},
+		{"negative retain", Operation{{Retain: -1}}, false},
+		{"negative delete", Operation{{Delete: -1}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.op.Validate(); (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	// 長さはコードポイント単位で数える
+	op := Operation{{Retain: 2}, {Insert: "を買う"}, {Delete: 1}, {Retain: 1}}
+	if op.BaseLen() != 4 || op.TargetLen() != 6 {
+		t.Fatalf("BaseLen() = %d, TargetLen() = %d", op.BaseLen(), op.TargetLen())
+	}
+	got, err := op.Apply("牛乳🥛!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "牛乳を買う!" {
+		t.Errorf("Apply() = %q", got)
+	}
+	if _, err := op.Apply("牛乳"); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("Apply() on a shorter string = %v, want ErrLengthMismatch", err)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	op := Replace(3, "新しい説明")
+	if !reflect.DeepEqual(op, Operation{{Insert: "新しい説明"}, {Delete: 3}}) {
+		t.Fatalf("Replace() = %+v", op)
+	}
+	if got, err := op.Apply("古い!"); err != nil || got != "新しい説明" {
+		t.Errorf("Apply() = %q, %v", got, err)
+	}
+	if got := Replace(0, ""); len(got) != 0 {
+		t.Errorf("Replace(0, \"\") = %+v, want an empty operation", got)
+	}
+}
+
+// converge はaとbを互いに変換し、どちらの順に適用しても同じ文字列になることを確かめて結果を返す
+func converge(t *testing.T, s string, a, b Operation) string {
+	t.Helper()
+	aPrime, bPrime, err := Transform(a, b)
+	if err != nil {
+		t.Fatalf("Transform(%+v, %+v): %v", a, b, err)
+	}
+	sa, err := a.Apply(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb, err := b.Apply(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sab, err := bPrime.Apply(sa)
+	if err != nil {
+		t.Fatalf("b' %+v on %q: %v", bPrime, sa, err)
+	}
+	sba, err := aPrime.Apply(sb)
+	if err != nil {
+		t.Fatalf("a' %+v on %q: %v", aPrime, sb, err)
+	}
+	if sab != sba {
+		t.Fatalf("a then b' = %q, b then a' = %q (a %+v, b %+v)", sab, sba, a, b)
+	}
+	return sab
+}
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Operation
+		want string
+	}{
+		{"inserts at different positions", Operation{{Insert: "["}, {Retain: 5}}, Operation{{Retain: 5}, {Insert: "]"}}, "[hello]"},
+		// 同じ位置への挿入はaを先に置く
+		{"inserts at the same position", Operation{{Retain: 5}, {Insert: "A"}}, Operation{{Retain: 5}, {Insert: "B"}}, "helloAB"},
+		{"insert inside a deletion", Operation{{Retain: 2}, {Insert: "X"}, {Retain: 3}}, Operation{{Retain: 1}, {Delete: 3}, {Retain: 1}}, "hXo"},
+		{"overlapping deletions", Operation{{Delete: 3}, {Retain: 2}}, Operation{{Retain: 1}, {Delete: 4}}, ""},
+		{"same deletion", Operation{{Retain: 1}, {Delete: 2}, {Retain: 2}}, Operation{{Retain: 1}, {Delete: 2}, {Retain: 2}}, "hlo"},
+		{"replace against an edit", Replace(5, "bye"), Operation{{Retain: 5}, {Insert: "!"}}, "bye!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := converge(t, "hello", tt.a, tt.b); got != tt.want {
+				t.Errorf("result = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformLengthMismatch(t *testing.T) {
+	if _, _, err := Transform(Operation{{Retain: 3}}, Operation{{Retain: 4}}); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("Transform() = %v, want ErrLengthMismatch", err)
+	}
+}
+
+// randomOp は長さnの文字列に対する無作為な操作を返す
+func randomOp(r *rand.Rand, n int) Operation {
+	var b builder
+	for n > 0 {
+		k := 1 + r.IntN(n)
+		switch r.IntN(3) {
+		case 0:
+			b.retain(k)
+			n -= k
+		case 1:
+			b.delete(k)
+			n -= k
+		default:
+			b.insert(strings.Repeat(string(rune('a'+r.IntN(26))), 1+r.IntN(3)))
+		}
+	}
+	if r.IntN(2) == 0 {
+		b.insert("末尾")
+	}
+	return b.op
+}
+
+func TestTransformConvergesOnRandomOperations(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 500 {
+		s := strings.Repeat("あいうえお", 1+r.IntN(3))
+		n := utf8.RuneCountInString(s)
+		a, b := randomOp(r, n), randomOp(r, n)
+		converge(t, s, a, b)
+	}
+}
@@ -0,0 +1,47 @@
+// Package outbox はTodoの変更イベントを、変更と同じトランザクションでoutboxテーブルに記録する機能を提供する。
+// 記録したイベントはscheduler.OutboxRelayがWebhookの配信とブローカーへの送信を終えるまで保持するため、
+// コミットの直後にプロセスが終了してもイベントは失われず、再起動後に送られる。
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"time"
+)
+
+// Record はイベントをoutboxに記録する。qには変更と同じトランザクションのQueriesを渡す
+func Record(ctx context.Context, q *db.Queries, e event.Event) error {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	var completed int64
+	if e.Completed {
+		completed = 1
+	}
+	if err := q.CreateOutboxEvent(ctx, db.CreateOutboxEventParams{
+		Type:       string(e.Type),
+		TodoID:     e.TodoID,
+		OwnerID:    e.OwnerID,
+		Completed:  completed,
+		CommentID:  sql.NullInt64{Int64: e.CommentID, Valid: e.CommentID != 0},
+		OccurredAt: e.OccurredAt.UTC(),
+	}); err != nil {
+		return fmt.Errorf("イベントの記録に失敗: %w", err)
+	}
+	return nil
+}
+
+// ToEvent はoutboxの行をイベントに戻す
+func ToEvent(row db.Outbox) event.Event {
+	return event.Event{
+		Type:       event.Type(row.Type),
+		TodoID:     row.TodoID,
+		OwnerID:    row.OwnerID,
+		Completed:  row.Completed == 1,
+		CommentID:  row.CommentID.Int64,
+		OccurredAt: row.OccurredAt,
+	}
+}
@@ -0,0 +1,212 @@
+// Package presence は共有しているプロジェクト（リスト）を現在開いているユーザーを追跡する。
+// クライアントは開いている間ハートビートを送り続け、一定時間途絶えたユーザーは閉じたものとみなす。
+// 状態はプロセスのメモリ上に保持するため、複数のインスタンスで共有しない。
+package presence
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Viewer はプロジェクトを開いているユーザーを表す構造体
+type Viewer struct {
+	UserID int64
+	Name   string
+	// Since は開き始めた日時
+	Since time.Time
+	// LastSeen は最後にハートビートを受け取った日時
+	LastSeen time.Time
+}
+
+// subscription はプロジェクトの閲覧者の変化を受け取る購読者
+type subscription struct {
+	projectID int64
+	ch        chan []Viewer
+}
+
+// Tracker はプロジェクトごとの閲覧者を追跡する
+type Tracker struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	projects map[int64]map[int64]*Viewer
+	nextID   int
+	subs     map[int]subscription
+	closed   bool
+}
+
+// NewTracker はTrackerの新しいインスタンスを生成する。
+// ttlの間ハートビートがないユーザーは閲覧者から外す。
+func NewTracker(ttl time.Duration) *Tracker {
+	return &Tracker{
+		ttl:      ttl,
+		now:      time.Now,
+		projects: make(map[int64]map[int64]*Viewer),
+		subs:     make(map[int]subscription),
+	}
+}
+
+// TTL はハートビートが途絶えてから閲覧者から外すまでの時間を返す
+func (t *Tracker) TTL() time.Duration {
+	return t.ttl
+}
+
+// Heartbeat はユーザーがプロジェクトを開いていることを記録し、現在の閲覧者を返す。
+// 新たに開いたユーザーの場合は購読者に閲覧者の変化を配信する。
+func (t *Tracker) Heartbeat(projectID, userID int64, name string) []Viewer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	viewers, ok := t.projects[projectID]
+	if !ok {
+		viewers = make(map[int64]*Viewer)
+		t.projects[projectID] = viewers
+	}
+	v, ok := viewers[userID]
+	if ok && now.Sub(v.LastSeen) < t.ttl {
+		v.LastSeen = now
+		v.Name = name
+		return t.snapshot(projectID)
+	}
+	viewers[userID] = &Viewer{UserID: userID, Name: name, Since: now, LastSeen: now}
+	current := t.snapshot(projectID)
+	t.publish(projectID, current)
+	return current
+}
+
+// Leave はユーザーがプロジェクトを閉じたことを記録し、開いていた場合にtrueを返す
+func (t *Tracker) Leave(projectID, userID int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	viewers := t.projects[projectID]
+	if _, ok := viewers[userID]; !ok {
+		return false
+	}
+	delete(viewers, userID)
+	if len(viewers) == 0 {
+		delete(t.projects, projectID)
+	}
+	t.publish(projectID, t.snapshot(projectID))
+	return true
+}
+
+// Viewers はプロジェクトの現在の閲覧者を開き始めた順に返す
+func (t *Tracker) Viewers(projectID int64) []Viewer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.snapshot(projectID)
+}
+
+// snapshot はハートビートが途絶えていない閲覧者の複製を返す。t.muを保持して呼び出す。
+func (t *Tracker) snapshot(projectID int64) []Viewer {
+	now := t.now()
+	viewers := []Viewer{}
+	for _, v := range t.projects[projectID] {
+		if now.Sub(v.LastSeen) < t.ttl {
+			viewers = append(viewers, *v)
+		}
+	}
+	slices.SortFunc(viewers, func(a, b Viewer) int {
+		return cmp.Or(a.Since.Compare(b.Since), cmp.Compare(a.UserID, b.UserID))
+	})
+	return viewers
+}
+
+// publish はプロジェクトの購読者に閲覧者を配信する。t.muを保持して呼び出す。
+// 購読者が前の変化を受け取っていない場合は、最新の閲覧者で置き換える。
+func (t *Tracker) publish(projectID int64, viewers []Viewer) {
+	for _, s := range t.subs {
+		if s.projectID != projectID {
+			continue
+		}
+		select {
+		case <-s.ch:
+		default:
+		}
+		s.ch <- viewers
+	}
+}
+
+// Subscribe はプロジェクトの閲覧者が変化するたびに現在の閲覧者を受け取るチャネルと購読解除関数を返す。
+// Closeの後に購読した場合は閉じたチャネルを返す。
+func (t *Tracker) Subscribe(projectID int64) (<-chan []Viewer, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ch := make(chan []Viewer, 1)
+	if t.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	id := t.nextID
+	t.nextID++
+	t.subs[id] = subscription{projectID: projectID, ch: ch}
+
+	return ch, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, ok := t.subs[id]; ok {
+			delete(t.subs, id)
+			close(ch)
+		}
+	}
+}
+
+// Close はすべての購読者のチャネルを閉じる。サーバーの停止時に閲覧者の配信を終了させるために使う。
+func (t *Tracker) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	for id, s := range t.subs {
+		delete(t.subs, id)
+		close(s.ch)
+	}
+}
+
+// Run はctxがキャンセルされるまで、ハートビートが途絶えた閲覧者を一定間隔で外して購読者に配信する
+func (t *Tracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.ttl / 2)
+	defer ticker.Stop()
+
+	slog.Info("閲覧者の追跡を開始", "ttl", t.ttl)
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("閲覧者の追跡を停止")
+			return
+		case <-ticker.C:
+			t.prune()
+		}
+	}
+}
+
+// prune はハートビートが途絶えた閲覧者を外す
+func (t *Tracker) prune() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	for projectID, viewers := range t.projects {
+		removed := false
+		for userID, v := range viewers {
+			if now.Sub(v.LastSeen) >= t.ttl {
+				delete(viewers, userID)
+				removed = true
+			}
+		}
+		if len(viewers) == 0 {
+			delete(t.projects, projectID)
+		}
+		if removed {
+			t.publish(projectID, t.snapshot(projectID))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
+	"go-huma-test/confirm"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/handler"
+	"go-huma-test/model"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeleteProjectKeepsOtherUsersTodos(t *testing.T) {
+	sqlDB, err := initDB("sqlite:"+filepath.Join(t.TempDir(), "todos.db"), false, true)
+	if err != nil {
+		t.Fatalf("initDB: %v", err)
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+	for _, stmt := range []string{
+		"INSERT INTO users (email, name, password_hash, role) VALUES ('a@example.com', 'A', '', 'editor'), ('b@example.com', 'B', '', 'editor'), ('admin@example.com', 'Admin', '', 'admin')",
+		"INSERT INTO projects (name) VALUES ('共有'), ('Aのみ')",
+		"INSERT INTO todos (title, owner_id, project_id) VALUES ('Aの買い物', 1, 1), ('Bの買い物', 2, 1), ('Aの掃除', 1, 2)",
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	queries := db.New(sqlDB)
+	projects := handler.NewProjectHandler(queries, sqlDB, event.NewBus(), confirm.NewConfirmer(queries, time.Minute), nil, clock.Freeze(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)))
+	deleteProject := func(ctx context.Context, id int64) error {
+		t.Helper()
+		in := &model.DeleteProjectInput{ID: id, Mode: handler.ProjectDeleteModeDelete}
+		out, err := projects.DeleteProject(ctx, in)
+		if err != nil {
+			return err
+		}
+		if out.Status != http.StatusAccepted {
+			t.Fatalf("確認トークンなしのDeleteProject = %d, want 202", out.Status)
+		}
+		in.ConfirmationToken = out.Body.Confirmation.Token
+		_, err = projects.DeleteProject(ctx, in)
+		return err
+	}
+	countTodos := func() int64 {
+		t.Helper()
+		var n int64
+		if err := sqlDB.QueryRow("SELECT COUNT(*) FROM todos").Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// ユーザーAはユーザーBのTodoを含むプロジェクトを削除できない
+	alice := auth.WithRole(auth.WithUserID(context.Background(), 1), auth.RoleEditor)
+	wantStatus(t, deleteProject(alice, 1), http.StatusForbidden)
+	if n := countTodos(); n != 3 {
+		t.Fatalf("todos after rejected delete = %d, want 3", n)
+	}
+
+	// 自分のTodoだけのプロジェクトは削除できる
+	if err := deleteProject(alice, 2); err != nil {
+		t.Fatalf("自分のTodoだけのプロジェクトのDeleteProject: %v", err)
+	}
+	if n := countTodos(); n != 2 {
+		t.Fatalf("todos after deleting A's project = %d, want 2", n)
+	}
+
+	// adminは他のユーザーのTodoを含むプロジェクトも削除できる
+	admin := auth.WithRole(auth.WithUserID(context.Background(), 3), auth.RoleAdmin)
+	if err := deleteProject(admin, 1); err != nil {
+		t.Fatalf("adminのDeleteProject: %v", err)
+	}
+	if n := countTodos(); n != 0 {
+		t.Fatalf("todos after admin delete = %d, want 0", n)
+	}
+}
@@ -0,0 +1,203 @@
+// Package recurrence はiCalendar RRULE（RFC 5545）のサブセットを解析し、
+// 繰り返しTodoの次回発生日時を計算する機能を提供する。
+// 対応するパートは FREQ, INTERVAL, COUNT, UNTIL, BYDAY（WEEKLYのみ）である。
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency は繰り返しの頻度を表す
+type Frequency string
+
+const (
+	// Daily は毎日の繰り返しを表す
+	Daily Frequency = "DAILY"
+	// Weekly は毎週の繰り返しを表す
+	Weekly Frequency = "WEEKLY"
+	// Monthly は毎月の繰り返しを表す
+	Monthly Frequency = "MONTHLY"
+	// Yearly は毎年の繰り返しを表す
+	Yearly Frequency = "YEARLY"
+)
+
+// untilLayouts はUNTILに指定できる日時の書式
+var untilLayouts = []string{"20060102T150405Z", "20060102"}
+
+// weekdays はBYDAYの曜日表記とtime.Weekdayの対応
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Rule は解析済みの繰り返しルールを表す構造体
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	// Count は残りの発生回数。0の場合は回数制限なし
+	Count int
+	Until *time.Time
+	ByDay []time.Weekday
+}
+
+// Parse はRRULE文字列を解析する。先頭の "RRULE:" は省略できる。
+func Parse(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return Rule{}, errors.New("RRULEが空です")
+	}
+
+	r := Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("RRULEの書式が不正です: %s", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch f := Frequency(strings.ToUpper(value)); f {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = f
+			default:
+				return Rule{}, fmt.Errorf("未対応のFREQです: %s", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("INTERVALは1以上の整数で指定してください: %s", value)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("COUNTは1以上の整数で指定してください: %s", value)
+			}
+			r.Count = n
+		case "UNTIL":
+			var until time.Time
+			var err error
+			for _, layout := range untilLayouts {
+				if until, err = time.Parse(layout, value); err == nil {
+					break
+				}
+			}
+			if err != nil {
+				return Rule{}, fmt.Errorf("UNTILの書式が不正です: %s", value)
+			}
+			r.Until = &until
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				wd, ok := weekdays[strings.ToUpper(d)]
+				if !ok {
+					return Rule{}, fmt.Errorf("未対応のBYDAYです: %s", d)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		default:
+			return Rule{}, fmt.Errorf("未対応のRRULEパートです: %s", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return Rule{}, errors.New("FREQは必須です")
+	}
+	if len(r.ByDay) > 0 && r.Freq != Weekly {
+		return Rule{}, errors.New("BYDAYはFREQ=WEEKLYの場合のみ指定できます")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return Rule{}, errors.New("COUNTとUNTILは同時に指定できません")
+	}
+
+	return r, nil
+}
+
+// String はルールをRRULE文字列に変換する
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayouts[0]))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for name, wd := range weekdays {
+			if slices.Contains(r.ByDay, wd) {
+				days = append(days, name)
+			}
+		}
+		slices.SortFunc(days, func(a, b string) int { return int(weekdays[a]) - int(weekdays[b]) })
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next はfromの次の発生日時と、その発生に引き継ぐルールを返す。
+// 発生回数の上限や終了日時に達した場合はokにfalseを返す。
+func (r Rule) Next(from time.Time) (next time.Time, rest Rule, ok bool) {
+	if r.Count == 1 {
+		return time.Time{}, Rule{}, false
+	}
+
+	switch r.Freq {
+	case Daily:
+		next = from.AddDate(0, 0, r.Interval)
+	case Weekly:
+		next = r.nextWeekly(from)
+	case Monthly:
+		next = from.AddDate(0, r.Interval, 0)
+	case Yearly:
+		next = from.AddDate(r.Interval, 0, 0)
+	}
+
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, Rule{}, false
+	}
+
+	rest = r
+	if rest.Count > 0 {
+		rest.Count--
+	}
+	return next, rest, true
+}
+
+// nextWeekly はBYDAYを考慮した毎週の次回発生日時を返す
+func (r Rule) nextWeekly(from time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return from.AddDate(0, 0, 7*r.Interval)
+	}
+
+	// 同じ週の残りの曜日を優先し、なければINTERVAL週後の週の最初の曜日にする
+	for d := 1; d < 7; d++ {
+		candidate := from.AddDate(0, 0, d)
+		if candidate.Weekday() == time.Sunday {
+			break
+		}
+		if slices.Contains(r.ByDay, candidate.Weekday()) {
+			return candidate
+		}
+	}
+
+	weekStart := from.AddDate(0, 0, -int(from.Weekday())+7*r.Interval)
+	for d := 0; d < 7; d++ {
+		candidate := weekStart.AddDate(0, 0, d)
+		if slices.Contains(r.ByDay, candidate.Weekday()) {
+			return candidate
+		}
+	}
+	return from.AddDate(0, 0, 7*r.Interval)
+}
@@ -0,0 +1,109 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY", true},
+		{"RRULE:freq=weekly;interval=2;byday=fr,mo", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", true},
+		{"FREQ=MONTHLY;COUNT=3", "FREQ=MONTHLY;COUNT=3", true},
+		{"FREQ=YEARLY;UNTIL=20270101", "FREQ=YEARLY;UNTIL=20270101T000000Z", true},
+		{"FREQ=DAILY;UNTIL=20260315T090000Z", "FREQ=DAILY;UNTIL=20260315T090000Z", true},
+		{"", "", false},
+		{"FREQ", "", false},
+		{"FREQ=HOURLY", "", false},
+		{"INTERVAL=2", "", false},
+		{"FREQ=DAILY;INTERVAL=0", "", false},
+		{"FREQ=DAILY;COUNT=-1", "", false},
+		{"FREQ=DAILY;UNTIL=2026-03-15", "", false},
+		{"FREQ=WEEKLY;BYDAY=XX", "", false},
+		{"FREQ=DAILY;BYDAY=MO", "", false},
+		{"FREQ=DAILY;COUNT=2;UNTIL=20260315", "", false},
+		{"FREQ=DAILY;BYMONTH=1", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			r, err := Parse(tt.in)
+			if !tt.ok {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %v, want an error", tt.in, r)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2026年3月10日は火曜日
+	tue := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	thu := tue.AddDate(0, 0, 2)
+	tests := []struct {
+		rule string
+		from time.Time
+		want time.Time
+		rest string
+	}{
+		{"FREQ=DAILY;INTERVAL=3", tue, tue.AddDate(0, 0, 3), "FREQ=DAILY;INTERVAL=3"},
+		{"FREQ=WEEKLY", tue, tue.AddDate(0, 0, 7), "FREQ=WEEKLY"},
+		// 同じ週に残っている曜日を優先する
+		{"FREQ=WEEKLY;BYDAY=MO,TH", tue, thu, "FREQ=WEEKLY;BYDAY=MO,TH"},
+		// 残りがなければ次の週の最初の曜日にする
+		{"FREQ=WEEKLY;BYDAY=MO,TH", thu, time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC), "FREQ=WEEKLY;BYDAY=MO,TH"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", thu, time.Date(2026, 3, 23, 9, 0, 0, 0, time.UTC), "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{"FREQ=MONTHLY", tue, time.Date(2026, 4, 10, 9, 0, 0, 0, time.UTC), "FREQ=MONTHLY"},
+		{"FREQ=YEARLY;INTERVAL=2", tue, time.Date(2028, 3, 10, 9, 0, 0, 0, time.UTC), "FREQ=YEARLY;INTERVAL=2"},
+		// COUNTは発生ごとに1つ減らして引き継ぐ
+		{"FREQ=DAILY;COUNT=3", tue, tue.AddDate(0, 0, 1), "FREQ=DAILY;COUNT=2"},
+		{"FREQ=DAILY;INTERVAL=3;UNTIL=20260315T000000Z", tue, tue.AddDate(0, 0, 3), "FREQ=DAILY;INTERVAL=3;UNTIL=20260315T000000Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			next, rest, ok := r.Next(tt.from)
+			if !ok {
+				t.Fatalf("Next(%v) ended the recurrence", tt.from)
+			}
+			if !next.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, next, tt.want)
+			}
+			if rest.String() != tt.rest {
+				t.Errorf("rest = %q, want %q", rest.String(), tt.rest)
+			}
+		})
+	}
+}
+
+func TestNextEnds(t *testing.T) {
+	from := time.Date(2026, 3, 13, 9, 0, 0, 0, time.UTC)
+	for _, rule := range []string{
+		// 最後の発生
+		"FREQ=DAILY;COUNT=1",
+		// 次の発生がUNTILを過ぎる
+		"FREQ=DAILY;INTERVAL=3;UNTIL=20260315T000000Z",
+	} {
+		r, err := Parse(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next, _, ok := r.Next(from); ok {
+			t.Errorf("%s: Next(%v) = %v, want the recurrence to end", rule, from, next)
+		}
+	}
+}
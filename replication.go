@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go-huma-test/model"
+	"go-huma-test/replication"
+	"go-huma-test/secrets"
+	"os"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+)
+
+// snapshotResult はreplicationサブコマンドが出力するスナップショット1件を表す構造体
+type snapshotResult struct {
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// newSnapshotResult はスナップショットを出力する形に変換する
+func newSnapshotResult(s replication.Snapshot) snapshotResult {
+	return snapshotResult{Key: s.Key, Size: s.Size, CreatedAt: s.CreatedAt}
+}
+
+// newReplicationCommand はreplication-urlに複製したスナップショットを一覧、取得するreplicationサブコマンドを生成する。
+// 結果はJSONで標準出力に書き出し、失敗した場合は終了コード1で終了する。
+func newReplicationCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replication",
+		Short: "List or fetch the database snapshots replicated to replication-url",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the snapshots at replication-url, newest first",
+		Args:  cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, o *model.Options) {
+			snapshots, err := replication.List(cmd.Context(), openReplicationTarget(o))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			results := make([]snapshotResult, 0, len(snapshots))
+			for _, s := range snapshots {
+				results = append(results, newSnapshotResult(s))
+			}
+			writeJSON(map[string]any{"snapshots": results})
+		}),
+	})
+
+	var key string
+	pull := &cobra.Command{
+		Use:   "pull <file>",
+		Short: "Download and decompress a snapshot into a database file that backup restore accepts",
+		Long: "Downloads the newest snapshot at replication-url, or the one given with --key, and writes it decompressed to <file>. " +
+			"Restore it with backup restore <file>, or start a new server with database-url pointing to <file>.",
+		Args: cobra.ExactArgs(1),
+		Run: humacli.WithOptions(func(cmd *cobra.Command, args []string, o *model.Options) {
+			ctx := cmd.Context()
+			target := openReplicationTarget(o)
+
+			snapshots, err := replication.List(ctx, target)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			var snapshot *replication.Snapshot
+			for i, s := range snapshots {
+				if key == "" || s.Key == key {
+					snapshot = &snapshots[i]
+					break
+				}
+			}
+			if snapshot == nil {
+				err := replication.ErrNoSnapshot
+				if key != "" {
+					err = errors.New("スナップショットが見つかりません: " + key)
+				}
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+
+			if err := replication.Download(ctx, target, *snapshot, args[0]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			writeJSON(map[string]any{"snapshot": newSnapshotResult(*snapshot), "path": args[0]})
+		}),
+	}
+	pull.Flags().StringVar(&key, "key", "", "Key of the snapshot to fetch, as printed by replication list. Defaults to the newest")
+	cmd.AddCommand(pull)
+	return cmd
+}
+
+// openReplicationTarget はreplication-urlの複製先を開く。開けない場合は終了コード1で終了する。
+func openReplicationTarget(o *model.Options) replication.Target {
+	if o.ReplicationURL == "" {
+		fmt.Fprintln(os.Stderr, "replication-urlを指定してください")
+		os.Exit(1)
+	}
+	provider, err := secrets.ParseProviders(o.SecretProviders, secrets.VaultConfig{
+		Addr:  o.VaultAddr,
+		Token: os.Getenv("VAULT_TOKEN"),
+		Path:  o.VaultPath,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	target, err := replication.OpenTarget(o.ReplicationURL, replication.Config{
+		Endpoint: o.ReplicationEndpoint,
+		Region:   o.ReplicationRegion,
+		Secrets:  secrets.NewManager(provider, o.SecretTTL),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return target
+}
@@ -0,0 +1,421 @@
+// Package replication は障害に備えて、データベースのスナップショットを一定間隔でS3互換のオブジェクトストレージなど別の場所に複製する機能を提供する。
+// スナップショットはVACUUM INTOで書き出した一貫した時点のデータベースをgzipで圧縮したもので、そのままbackup restoreで復元できる。
+// 複製先はstorage.BlobStoreとstorage.Listerを満たせば差し替えられる。
+package replication
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/secrets"
+	"go-huma-test/storage"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// スナップショットのキーは作成日時の順に並ぶよう、接頭辞とUTCの作成日時と拡張子で構成する
+const (
+	keyPrefix  = "todos-"
+	keySuffix  = ".db.gz"
+	timeLayout = "20060102T150405.000Z"
+)
+
+// 複製先のURLのスキーム
+const (
+	SchemeS3   = "s3"
+	SchemeFile = "file"
+)
+
+// requestTimeout は複製先へのリクエスト1件あたりのタイムアウト。スナップショットの送信を含むため長めにする
+const requestTimeout = 10 * time.Minute
+
+// ErrNoSnapshot は複製先にスナップショットがないことを表すエラー
+var ErrNoSnapshot = errors.New("複製先にスナップショットがありません")
+
+// Store はスナップショットを保存し、列挙できる複製先のインターフェース
+type Store interface {
+	storage.BlobStore
+	storage.Lister
+}
+
+// Target は複製先のストアと、スナップショットのキーに付ける接頭辞を表す構造体
+type Target struct {
+	URL    string
+	Store  Store
+	Prefix string
+}
+
+// Config はS3互換のストレージに接続する設定を表す構造体
+type Config struct {
+	Endpoint string
+	Region   string
+	Secrets  *secrets.Manager
+}
+
+// OpenTarget はs3://bucket/prefixまたはfile:///dirの形式の複製先を開く
+func OpenTarget(rawURL string, config Config) (Target, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Target{}, fmt.Errorf("複製先のURLが不正です: %w", err)
+	}
+	switch u.Scheme {
+	case SchemeS3:
+		if u.Host == "" {
+			return Target{}, fmt.Errorf("複製先のURLにバケットがありません: %s", rawURL)
+		}
+		store, err := storage.NewS3Store(storage.S3Config{
+			Endpoint: config.Endpoint,
+			Region:   config.Region,
+			Bucket:   u.Host,
+			Secrets:  config.Secrets,
+			Timeout:  requestTimeout,
+		})
+		if err != nil {
+			return Target{}, err
+		}
+		prefix := strings.Trim(u.Path, "/")
+		if prefix != "" {
+			prefix += "/"
+		}
+		return Target{URL: rawURL, Store: store, Prefix: prefix + keyPrefix}, nil
+	case SchemeFile:
+		if u.Path == "" {
+			return Target{}, fmt.Errorf("複製先のURLにディレクトリがありません: %s", rawURL)
+		}
+		store, err := storage.NewLocalStore(u.Path)
+		if err != nil {
+			return Target{}, err
+		}
+		return Target{URL: rawURL, Store: store, Prefix: keyPrefix}, nil
+	default:
+		return Target{}, fmt.Errorf("複製先のURLはs3://bucket/prefixまたはfile:///dirの形式で指定してください: %s", rawURL)
+	}
+}
+
+// Snapshot は複製先のスナップショット1件を表す構造体
+type Snapshot struct {
+	Key       string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Status は複製の状態を表す構造体
+type Status struct {
+	Target   string
+	Interval time.Duration
+	// LastSnapshot は複製先にある最新のスナップショット
+	LastSnapshot *Snapshot
+	// LastAttemptAt は最後にスナップショットの作成を試みた日時
+	LastAttemptAt time.Time
+	// LastSuccessAt は最後に複製先が最新の内容になったことを確認した日時。変更がなく送らなかった場合も含む
+	LastSuccessAt time.Time
+	LastError     string
+	// ConsecutiveFailures は最後に成功してから続けて失敗した回数
+	ConsecutiveFailures int
+	NextAt              time.Time
+}
+
+// Replicator は一定間隔でデータベースのスナップショットを作成して複製先に送り、保持する件数を超えた古いものを削除する
+type Replicator struct {
+	db       *sql.DB
+	target   Target
+	interval time.Duration
+	retain   int
+
+	mu       sync.Mutex
+	status   Status
+	lastHash []byte
+}
+
+// NewReplicator はReplicatorの新しいインスタンスを生成する。retainが0の場合は古いスナップショットを削除しない。
+func NewReplicator(db *sql.DB, target Target, interval time.Duration, retain int) *Replicator {
+	return &Replicator{
+		db:       db,
+		target:   target,
+		interval: interval,
+		retain:   retain,
+		status:   Status{Target: target.URL, Interval: interval},
+	}
+}
+
+// Status は複製の状態を返す
+func (r *Replicator) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// Run はctxがキャンセルされるまで一定間隔でスナップショットを複製する。
+// 複製先の最新のスナップショットから間隔が経過していれば起動後すぐに複製し、再起動を繰り返しても間隔より頻繁には送らない。
+func (r *Replicator) Run(ctx context.Context) {
+	slog.Info("データベースの複製を開始", "target", r.target.URL, "interval", r.interval)
+
+	next := time.Now()
+	if latest, err := Latest(ctx, r.target); err == nil {
+		r.mu.Lock()
+		r.status.LastSnapshot = &latest
+		r.mu.Unlock()
+		next = latest.CreatedAt.Add(r.interval)
+	} else if !errors.Is(err, ErrNoSnapshot) {
+		slog.Warn("複製先のスナップショットの一覧の取得に失敗", "err", err)
+	}
+
+	for {
+		r.mu.Lock()
+		r.status.NextAt = next
+		r.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			slog.Info("データベースの複製を停止")
+			return
+		case <-timer.C:
+		}
+
+		snapshot, sent, err := r.Replicate(ctx)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				continue
+			}
+			slog.Warn("データベースの複製に失敗", "err", err)
+		case sent:
+			slog.Info("データベースのスナップショットを複製", "key", snapshot.Key, "size", snapshot.Size)
+		default:
+			slog.Debug("前回のスナップショットから変更がないため複製しません")
+		}
+		next = time.Now().Add(r.interval)
+	}
+}
+
+// Replicate はスナップショットを作成して複製先に送る。前回送った内容から変更がない場合は送らずにsentにfalseを返す。
+func (r *Replicator) Replicate(ctx context.Context) (snapshot Snapshot, sent bool, err error) {
+	attemptAt := time.Now()
+	defer func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.status.LastAttemptAt = attemptAt
+		if err != nil {
+			r.status.LastError = err.Error()
+			r.status.ConsecutiveFailures++
+			return
+		}
+		r.status.LastSuccessAt = time.Now()
+		r.status.LastError = ""
+		r.status.ConsecutiveFailures = 0
+		if sent {
+			r.status.LastSnapshot = &snapshot
+		}
+	}()
+
+	dir, err := os.MkdirTemp("", "todo-replication-")
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("一時ディレクトリの作成に失敗: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	// VACUUM INTOは読み取りのトランザクションで一貫した時点の内容を書き出すため、書き込みを止めずに実行できる
+	createdAt := time.Now().UTC().Truncate(time.Millisecond)
+	dbFile := filepath.Join(dir, "snapshot.db")
+	if _, err := r.db.ExecContext(ctx, "VACUUM INTO ?", dbFile); err != nil {
+		return Snapshot{}, false, fmt.Errorf("スナップショットの書き出しに失敗: %w", err)
+	}
+
+	hash, err := fileHash(dbFile)
+	if err != nil {
+		return Snapshot{}, false, err
+	}
+	r.mu.Lock()
+	unchanged := slices.Equal(hash, r.lastHash)
+	r.mu.Unlock()
+	if unchanged {
+		return Snapshot{}, false, nil
+	}
+
+	gzFile := dbFile + ".gz"
+	if err := compress(gzFile, dbFile); err != nil {
+		return Snapshot{}, false, err
+	}
+	f, err := os.Open(gzFile)
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("圧縮したスナップショットの読み込みに失敗: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	key := r.target.Prefix + createdAt.Format(timeLayout) + keySuffix
+	size, err := r.target.Store.Put(ctx, key, f)
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("スナップショットの送信に失敗: %w", err)
+	}
+
+	r.mu.Lock()
+	r.lastHash = hash
+	r.mu.Unlock()
+
+	// 送信に成功していれば次回は変更がない限り送らないため、古いスナップショットの削除の失敗は記録するだけにする
+	if err := r.prune(ctx); err != nil {
+		slog.Warn("古いスナップショットの削除に失敗", "err", err)
+	}
+	return Snapshot{Key: key, Size: size, CreatedAt: createdAt}, true, nil
+}
+
+// prune は新しい順にretain件を超えたスナップショットを削除する
+func (r *Replicator) prune(ctx context.Context) error {
+	if r.retain <= 0 {
+		return nil
+	}
+	snapshots, err := List(ctx, r.target)
+	if err != nil {
+		return err
+	}
+	for _, s := range snapshots[min(r.retain, len(snapshots)):] {
+		if err := r.target.Store.Delete(ctx, s.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List は複製先のスナップショットを新しい順に返す
+func List(ctx context.Context, target Target) ([]Snapshot, error) {
+	objects, err := target.Store.List(ctx, target.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("複製先のスナップショットの一覧の取得に失敗: %w", err)
+	}
+	var snapshots []Snapshot
+	for _, o := range objects {
+		createdAt, ok := parseKey(o.Key, target.Prefix)
+		if !ok {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{Key: o.Key, Size: o.Size, CreatedAt: createdAt})
+	}
+	slices.SortFunc(snapshots, func(a, b Snapshot) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// Latest は複製先の最新のスナップショットを返す。ない場合はErrNoSnapshotを返す。
+func Latest(ctx context.Context, target Target) (Snapshot, error) {
+	snapshots, err := List(ctx, target)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if len(snapshots) == 0 {
+		return Snapshot{}, ErrNoSnapshot
+	}
+	return snapshots[0], nil
+}
+
+// Download はスナップショットを展開してpathにSQLiteのデータベースファイルとして書き出す。
+// 書き出し途中のファイルを残さないよう、一時ファイルに書き出してから名前を変える。
+func Download(ctx context.Context, target Target, snapshot Snapshot, path string) error {
+	rc, err := target.Store.Open(ctx, snapshot.Key)
+	if err != nil {
+		return fmt.Errorf("スナップショットの取得に失敗: %w", err)
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+	zr, err := gzip.NewReader(rc)
+	if err != nil {
+		return fmt.Errorf("スナップショットの展開に失敗: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return fmt.Errorf("一時ファイルの作成に失敗: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := io.Copy(tmp, zr); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("スナップショットの展開に失敗: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("スナップショットの書き込みに失敗: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("スナップショットの保存に失敗: %w", err)
+	}
+	return nil
+}
+
+// parseKey はスナップショットのキーから作成日時を返す。スナップショットのキーでない場合はfalseを返す。
+func parseKey(key, prefix string) (time.Time, bool) {
+	ts, ok := strings.CutPrefix(key, prefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	ts, ok = strings.CutSuffix(ts, keySuffix)
+	if !ok {
+		return time.Time{}, false
+	}
+	createdAt, err := time.Parse(timeLayout, ts)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
+
+// fileHash はファイルの内容のSHA-256を返す
+func fileHash(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("スナップショットの読み込みに失敗: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("スナップショットの読み込みに失敗: %w", err)
+	}
+	return h.Sum(nil), nil
+}
+
+// compress はsrcをgzipで圧縮してdstに書き出す
+func compress(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("スナップショットの読み込みに失敗: %w", err)
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("スナップショットの圧縮に失敗: %w", err)
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("スナップショットの圧縮に失敗: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("スナップショットの圧縮に失敗: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("スナップショットの圧縮に失敗: %w", err)
+	}
+	return nil
+}
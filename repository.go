@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"go-huma-test/auth"
+	"go-huma-test/handler"
+	"go-huma-test/middleware"
+	"go-huma-test/migrate"
+	"go-huma-test/model"
+	"go-huma-test/repository"
+	"go-huma-test/secrets"
+	"log/slog"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+	"github.com/danielgtaylor/huma/v2/humacli"
+)
+
+//go:embed schema/postgres/migrations/*.sql
+var postgresMigrationFiles embed.FS
+
+// postgresMigrationsDir はpostgresMigrationFilesの中でPostgreSQLの移行のファイルを置くディレクトリ
+const postgresMigrationsDir = "schema/postgres/migrations"
+
+// repositoryOpenTimeout は起動時に保存先へ接続して移行を適用するまでの制限
+const repositoryOpenTimeout = 30 * time.Second
+
+// isPostgresURL はdatabase-urlがPostgreSQLのURL（postgres://またはpostgresql://）かを返す
+func isPostgresURL(databaseURL string) bool {
+	scheme, _, ok := strings.Cut(databaseURL, "://")
+	if !ok {
+		return false
+	}
+	scheme = strings.ToLower(scheme)
+	return scheme == "postgres" || scheme == "postgresql"
+}
+
+// usesRepository はdatabase-urlの保存先をTodoRepositoryで扱うかを返す。
+// SQLiteはすべての機能を持つサーバーで扱い、PostgreSQLはTodoの基本的な操作のみのサーバー（runRepositoryServer）で扱う。
+func usesRepository(databaseURL string) bool {
+	return isPostgresURL(databaseURL)
+}
+
+// openRepository はdatabase-urlの保存先のTodoRepositoryを開く。PostgreSQLの場合は未適用の移行を適用する
+func openRepository(ctx context.Context, databaseURL string) (repository.TodoRepository, error) {
+	if !isPostgresURL(databaseURL) {
+		return nil, fmt.Errorf("database-urlのスキームに対応していません: %s", databaseURL)
+	}
+	migrations, err := migrate.Load(postgresMigrationFiles, postgresMigrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := repository.OpenPostgres(ctx, databaseURL, migrations)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("PostgreSQLに接続しました")
+	return repo, nil
+}
+
+// registerRepositoryOperations はTodoRepositoryのTodoの操作を、すべての機能を持つサーバーと同じ操作IDとパスで登録する
+func registerRepositoryOperations(api huma.API, todos *handler.RepositoryHandler) {
+	huma.Register(api, huma.Operation{
+		OperationID: "list-todos",
+		Method:      http.MethodGet,
+		Path:        "/todos",
+		Summary:     "Todo一覧取得",
+		Description: "認証したユーザーのTodoを作成日時の新しい順に取得します。completedで完了状態を絞り込めます。",
+		Tags:        []string{"todos"},
+	}, todos.ListTodos)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-todo",
+		Method:      http.MethodGet,
+		Path:        "/todos/{id}",
+		Summary:     "Todo取得",
+		Description: "指定したIDのTodoを取得します。",
+		Tags:        []string{"todos"},
+	}, todos.GetTodo)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-todo",
+		Method:        http.MethodPost,
+		Path:          "/todos",
+		Summary:       "Todo作成",
+		Description:   "新しいTodoを作成します。",
+		Tags:          []string{"todos"},
+		DefaultStatus: http.StatusCreated,
+	}, todos.CreateTodo)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-todo",
+		Method:      http.MethodPut,
+		Path:        "/todos/{id}",
+		Summary:     "Todo更新",
+		Description: "指定したIDのTodoの内容を置き換えます。",
+		Tags:        []string{"todos"},
+	}, todos.UpdateTodo)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-todo",
+		Method:      http.MethodDelete,
+		Path:        "/todos/{id}",
+		Summary:     "Todo削除",
+		Description: "指定したIDのTodoを削除します。存在しないIDの削除も成功として扱います。",
+		Tags:        []string{"todos"},
+	}, todos.DeleteTodo)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "toggle-todo",
+		Method:      http.MethodPost,
+		Path:        "/todos/{id}/toggle",
+		Summary:     "Todo完了状態切り替え",
+		Description: "指定したIDのTodoの完了状態を切り替えます。",
+		Tags:        []string{"todos"},
+	}, todos.ToggleTodo)
+}
+
+// repositoryServerOptions はTodoの基本的な操作のみを提供するサーバーが使う起動オプション。
+// これ以外の起動オプションはSQLiteのスキーマに依存する機能の設定のため、このサーバーでは使えない。
+var repositoryServerOptions = []string{
+	"port", "host", "database-url", "db", "log-level", "auth-providers",
+	"secret-providers", "secret-ttl", "vault-addr", "vault-path",
+	"jwks-url", "jwks-refresh", "jwt-issuer", "jwt-audience", "jwt-leeway",
+	"readiness-timeout", "shutdown-timeout",
+}
+
+// defaultOptions はすべての起動オプションを既定値にしたOptionsを返す
+func defaultOptions() (model.Options, error) {
+	var o model.Options
+	v := reflect.ValueOf(&o).Elem()
+	for _, opt := range configOptions() {
+		if err := setConfigValue(v.Field(opt.index), opt.field.Tag.Get("default")); err != nil {
+			return o, fmt.Errorf("%sの既定値が不正です: %w", opt.name, err)
+		}
+	}
+	return o, nil
+}
+
+// unsupportedRepositoryOptions は既定値から変えた起動オプションのうち、Todoの基本的な操作のみを提供するサーバーで使えないもののフラグ名を返す。
+// 環境変数で指定した値も既定値と比べるため、フラグで指定した場合と同じく見つかる。
+func unsupportedRepositoryOptions(o *model.Options) ([]string, error) {
+	defaults, err := defaultOptions()
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(o).Elem()
+	dv := reflect.ValueOf(&defaults).Elem()
+	var names []string
+	for _, opt := range configOptions() {
+		if slices.Contains(repositoryServerOptions, opt.name) {
+			continue
+		}
+		if !reflect.DeepEqual(v.Field(opt.index).Interface(), dv.Field(opt.index).Interface()) {
+			names = append(names, opt.name)
+		}
+	}
+	return names, nil
+}
+
+// runRepositoryServer はPostgreSQLに保存したTodoの一覧、取得、作成、更新、トグル、削除のみを提供するサーバーを起動する。
+// すべての機能を持つサーバーはSQLiteのdb.Queriesを直接使うため、プロジェクト、タグ、ユーザー、共有、Webhook、アウトボックスなどの機能はこのサーバーにはない。
+// 機能が黙って欠けることのないよう、認証はstaticとjwtのみを受け付け、それ以外の機能の起動オプションを指定した場合は起動しない。
+func runRepositoryServer(h humacli.Hooks, o *model.Options) {
+	unsupported, err := unsupportedRepositoryOptions(o)
+	if err != nil {
+		slog.Error("起動オプションを確認できません", "err", err)
+		os.Exit(1)
+	}
+	if len(unsupported) > 0 {
+		slog.Error("PostgreSQLのデータベースではTodoの基本的な操作のみを提供するため、この起動オプションは使えません。使う場合はsqlite:のdatabase-urlを指定してください", "options", unsupported)
+		os.Exit(1)
+	}
+	authProviders, err := auth.ParseProviders(o.AuthProviders)
+	if err != nil {
+		slog.Error("auth-providersの指定が不正です", "err", err)
+		os.Exit(1)
+	}
+	// APIキーとOIDCはユーザーをSQLiteに保存するため使えない
+	if i := slices.IndexFunc(authProviders, func(p string) bool { return p != auth.ProviderStatic && p != auth.ProviderJWT }); i >= 0 {
+		slog.Error("PostgreSQLのデータベースではauth-providersにstaticとjwtのみを指定してください", "auth_provider", authProviders[i])
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), repositoryOpenTimeout)
+	repo, err := openRepository(ctx, o.DatabaseURL)
+	cancel()
+	if err != nil {
+		slog.Error("データベース初期化に失敗", "err", err)
+		os.Exit(1)
+	}
+	slog.Warn("PostgreSQLのデータベースではTodoの基本的な操作のみを提供します。プロジェクトやユーザーなどの機能にはsqlite:のdatabase-urlを指定してください")
+
+	secretProvider, err := secrets.ParseProviders(o.SecretProviders, secrets.VaultConfig{
+		Addr:  o.VaultAddr,
+		Token: os.Getenv("VAULT_TOKEN"),
+		Path:  o.VaultPath,
+	})
+	if err != nil {
+		slog.Error("秘密情報プロバイダーの設定が不正です", "err", err)
+		os.Exit(1)
+	}
+	secretManager := secrets.NewManager(secretProvider, o.SecretTTL)
+	var jwks *auth.JWKS
+	if o.JWKSURL != "" {
+		jwks = auth.NewJWKS(o.JWKSURL, o.JWKSRefresh)
+	} else if key, _, err := secretManager.Lookup(context.Background(), secrets.JWTSigningKey); slices.Contains(authProviders, auth.ProviderJWT) && (err != nil || key == "") {
+		// 検証できる鍵がなければJWTのリクエストがすべて401になるため、起動しない
+		slog.Error("JWTの検証鍵がありません。jwks-urlか秘密情報jwt_signing_keyを設定してください", "err", err)
+		os.Exit(1)
+	}
+
+	var authenticators auth.Stack
+	for _, p := range authProviders {
+		switch p {
+		case auth.ProviderStatic:
+			authenticators = append(authenticators, auth.NewStaticTokenAuthenticator(secretManager))
+		case auth.ProviderJWT:
+			authenticators = append(authenticators, auth.NewJWTAuthenticator(auth.NewVerifier(auth.Config{
+				Secrets:  secretManager,
+				JWKS:     jwks,
+				Issuer:   o.JWTIssuer,
+				Audience: o.JWTAudience,
+				Leeway:   o.JWTLeeway,
+			})))
+		}
+	}
+
+	mux := http.NewServeMux()
+	config := huma.DefaultConfig("Todo API", apiVersion)
+	config.Info.Description = "Todoの基本的な操作のみを提供するTodo API。"
+	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		middleware.BearerSecurityScheme: middleware.BearerSecuritySchemeDef(authProviders),
+	}
+	config.Security = []map[string][]string{{middleware.BearerSecurityScheme: {}}}
+	api := humago.New(mux, config)
+	api.UseMiddleware(middleware.Auth(api, authenticators))
+
+	todos := handler.NewRepositoryHandler(repo, o.ReadinessTimeout)
+	mux.HandleFunc("GET /healthz", todos.Liveness)
+	mux.HandleFunc("GET /readyz", todos.Readiness)
+	registerRepositoryOperations(api, todos)
+
+	srv := &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", o.Host, o.Port),
+		Handler:           middleware.AccessLog(middleware.AccessLogConfig{}, mux),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      serverWriteTimeout,
+		IdleTimeout:       60 * time.Second,
+	}
+
+	h.OnStart(func() {
+		fmt.Printf("🚀 Todo API Server starting on http://%s\n", srv.Addr)
+		fmt.Printf("📚 API Documentation: http://%s/docs\n", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("サーバー起動に失敗", "err", err)
+			os.Exit(1)
+		}
+	})
+
+	h.OnStop(func() {
+		slog.Info("サーバーのシャットダウン開始...")
+		ctx, cancel := context.WithTimeout(context.Background(), o.ShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Warn("時間内に終わらなかったリクエストを打ち切ります", "shutdown_timeout", o.ShutdownTimeout, "err", err)
+			srv.Close()
+		}
+		if err := repo.Close(); err != nil {
+			slog.Warn("データベースを閉じられません", "err", err)
+		}
+		slog.Info("サーバーのシャットダウン完了")
+	})
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"go-huma-test/migrate"
+	"os"
+	"testing"
+)
+
+// postgresURLEnv はPostgreSQLの結合テストに使うデータベースのURLを指定する環境変数。
+// 指定したデータベースのtodosテーブルはテストの前に空にする
+const postgresURLEnv = "TODO_TEST_POSTGRES_URL"
+
+func TestPostgres(t *testing.T) {
+	databaseURL := os.Getenv(postgresURLEnv)
+	if databaseURL == "" {
+		t.Skipf("%sにPostgreSQLのURLを指定すると実行します", postgresURLEnv)
+	}
+	ctx := context.Background()
+	migrations, err := migrate.Load(os.DirFS(".."), "schema/postgres/migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := OpenPostgres(ctx, databaseURL, migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+	// 移行は2回目以降の起動では何もしない
+	if err := migratePostgres(ctx, repo.pool, migrations); err != nil {
+		t.Fatalf("migratePostgres: %v", err)
+	}
+	if _, err := repo.pool.Exec(ctx, "TRUNCATE todos RESTART IDENTITY"); err != nil {
+		t.Fatal(err)
+	}
+	testTodoRepository(t, repo)
+}
@@ -0,0 +1,76 @@
+// Package repository はTodoの保存先をデータベースの種類から切り離すリポジトリを提供する。
+// SQLiteとPostgreSQL（pgx）の実装があり、PostgreSQLのdatabase-urlを指定するとTodoの基本的な操作のみを提供するサーバーがPostgreSQLの実装を使う。
+// リポジトリはTodoの基本的な項目のみを扱い、プロジェクトやサブタスクなどSQLiteのスキーマに依存する機能は含めない。
+// すべての機能を持つサーバーはSQLiteのdb.Queriesを直接使う。SQLiteの実装は、両方の実装が同じ振る舞いになることを共通のテストで確かめるために使う。
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrNotFound は指定されたIDのTodoが存在しないか、所有者が異なることを表すエラー
+var ErrNotFound = errors.New("Todoが見つかりません")
+
+// Todo はリポジトリに保存するTodoを表す構造体
+type Todo struct {
+	ID          int64
+	OwnerID     sql.NullInt64
+	Title       string
+	Description sql.NullString
+	Completed   bool
+	Priority    int64
+	DueAt       sql.NullTime
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ListTodosParams はTodoの一覧の取得条件を表す構造体
+type ListTodosParams struct {
+	// OwnerID は所有者のユーザーID。無効な場合は所有者のいないTodoを返す
+	OwnerID   sql.NullInt64
+	Completed bool
+}
+
+// CreateTodoParams は作成するTodoの内容を表す構造体
+type CreateTodoParams struct {
+	OwnerID     sql.NullInt64
+	Title       string
+	Description sql.NullString
+	Priority    int64
+	DueAt       sql.NullTime
+}
+
+// UpdateTodoParams は更新するTodoと更新後の内容を表す構造体
+type UpdateTodoParams struct {
+	ID          int64
+	OwnerID     sql.NullInt64
+	Title       string
+	Description sql.NullString
+	Completed   bool
+	Priority    int64
+	DueAt       sql.NullTime
+}
+
+// TodoRepository はTodoを保存するリポジトリのインターフェース。
+// IDを指定する操作は所有者が一致するTodoのみを対象にし、一致しない場合はErrNotFoundを返す。
+type TodoRepository interface {
+	// ListTodos は所有者のTodoのうち、完了状態が一致するものを作成日時の新しい順に返す
+	ListTodos(ctx context.Context, arg ListTodosParams) ([]Todo, error)
+	// GetTodo はTodoを返す
+	GetTodo(ctx context.Context, id int64, ownerID sql.NullInt64) (Todo, error)
+	// CreateTodo は未完了のTodoを作成し、作成したTodoを返す
+	CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error)
+	// UpdateTodo はTodoの内容を置き換え、更新したTodoを返す
+	UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, error)
+	// ToggleTodo はTodoの完了状態を反転し、更新したTodoを返す
+	ToggleTodo(ctx context.Context, id int64, ownerID sql.NullInt64) (Todo, error)
+	// DeleteTodo はTodoを削除する
+	DeleteTodo(ctx context.Context, id int64, ownerID sql.NullInt64) error
+	// Ping は保存先に接続できることを確認する
+	Ping(ctx context.Context) error
+	// Close は保存先との接続を閉じる
+	Close() error
+}
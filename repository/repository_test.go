@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// todoIDs はTodoのIDを昇順に並べて返す
+func todoIDs(todos []Todo) []int64 {
+	ids := make([]int64, len(todos))
+	for i, t := range todos {
+		ids[i] = t.ID
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// testTodoRepository はTodoRepositoryの実装が満たすべき振る舞いを確認する。repoは空の保存先にする
+func testTodoRepository(t *testing.T, repo TodoRepository) {
+	t.Helper()
+	ctx := context.Background()
+	alice := sql.NullInt64{Int64: 1, Valid: true}
+	bob := sql.NullInt64{Int64: 2, Valid: true}
+	nobody := sql.NullInt64{}
+	due := time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	create := func(owner sql.NullInt64, title string) Todo {
+		t.Helper()
+		todo, err := repo.CreateTodo(ctx, CreateTodoParams{OwnerID: owner, Title: title})
+		if err != nil {
+			t.Fatalf("CreateTodo(%q): %v", title, err)
+		}
+		return todo
+	}
+	wantNotFound := func(name string, err error) {
+		t.Helper()
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("%s: err = %v, want ErrNotFound", name, err)
+		}
+	}
+	list := func(owner sql.NullInt64, completed bool) []int64 {
+		t.Helper()
+		todos, err := repo.ListTodos(ctx, ListTodosParams{OwnerID: owner, Completed: completed})
+		if err != nil {
+			t.Fatalf("ListTodos: %v", err)
+		}
+		return todoIDs(todos)
+	}
+
+	if err := repo.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	first, err := repo.CreateTodo(ctx, CreateTodoParams{
+		OwnerID:     alice,
+		Title:       "買い物",
+		Description: sql.NullString{String: "牛乳を買う", Valid: true},
+		Priority:    2,
+		DueAt:       sql.NullTime{Time: due, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("CreateTodo: %v", err)
+	}
+	if first.ID == 0 || first.OwnerID != alice || first.Title != "買い物" || first.Description.String != "牛乳を買う" ||
+		first.Completed || first.Priority != 2 || !first.DueAt.Time.Equal(due) || first.CreatedAt.IsZero() {
+		t.Fatalf("CreateTodo = %+v", first)
+	}
+	second := create(alice, "掃除")
+	others := create(bob, "洗濯")
+	shared := create(nobody, "共有")
+
+	got, err := repo.GetTodo(ctx, first.ID, alice)
+	if err != nil || got.ID != first.ID || got.Title != first.Title || !got.DueAt.Time.Equal(due) {
+		t.Fatalf("GetTodo = %+v, %v", got, err)
+	}
+	_, err = repo.GetTodo(ctx, first.ID, bob)
+	wantNotFound("他のユーザーのTodoのGetTodo", err)
+	_, err = repo.GetTodo(ctx, shared.ID, alice)
+	wantNotFound("所有者のいないTodoをユーザーとしてGetTodo", err)
+	if got, err := repo.GetTodo(ctx, shared.ID, nobody); err != nil || got.Title != "共有" {
+		t.Fatalf("所有者のいないTodoのGetTodo = %+v, %v", got, err)
+	}
+	_, err = repo.GetTodo(ctx, others.ID+100, bob)
+	wantNotFound("存在しないTodoのGetTodo", err)
+
+	if ids := list(alice, false); !slices.Equal(ids, []int64{first.ID, second.ID}) {
+		t.Fatalf("aliceの未完了のTodo = %v, want %v", ids, []int64{first.ID, second.ID})
+	}
+	if ids := list(nobody, false); !slices.Equal(ids, []int64{shared.ID}) {
+		t.Fatalf("所有者のいない未完了のTodo = %v, want %v", ids, []int64{shared.ID})
+	}
+
+	toggled, err := repo.ToggleTodo(ctx, first.ID, alice)
+	if err != nil || !toggled.Completed {
+		t.Fatalf("ToggleTodo = %+v, %v", toggled, err)
+	}
+	_, err = repo.ToggleTodo(ctx, first.ID, bob)
+	wantNotFound("他のユーザーのTodoのToggleTodo", err)
+	if ids := list(alice, true); !slices.Equal(ids, []int64{first.ID}) {
+		t.Fatalf("aliceの完了したTodo = %v, want %v", ids, []int64{first.ID})
+	}
+	if ids := list(alice, false); !slices.Equal(ids, []int64{second.ID}) {
+		t.Fatalf("トグル後のaliceの未完了のTodo = %v, want %v", ids, []int64{second.ID})
+	}
+
+	updated, err := repo.UpdateTodo(ctx, UpdateTodoParams{
+		ID:        second.ID,
+		OwnerID:   alice,
+		Title:     "大掃除",
+		Completed: true,
+		Priority:  3,
+	})
+	if err != nil || updated.Title != "大掃除" || updated.Description.Valid || !updated.Completed || updated.Priority != 3 || updated.DueAt.Valid {
+		t.Fatalf("UpdateTodo = %+v, %v", updated, err)
+	}
+	_, err = repo.UpdateTodo(ctx, UpdateTodoParams{ID: others.ID, OwnerID: alice, Title: "横取り"})
+	wantNotFound("他のユーザーのTodoのUpdateTodo", err)
+	if got, err := repo.GetTodo(ctx, others.ID, bob); err != nil || got.Title != "洗濯" {
+		t.Fatalf("他のユーザーが更新できないTodo = %+v, %v", got, err)
+	}
+
+	wantNotFound("他のユーザーのTodoのDeleteTodo", repo.DeleteTodo(ctx, first.ID, bob))
+	if err := repo.DeleteTodo(ctx, first.ID, alice); err != nil {
+		t.Fatalf("DeleteTodo: %v", err)
+	}
+	_, err = repo.GetTodo(ctx, first.ID, alice)
+	wantNotFound("削除したTodoのGetTodo", err)
+	wantNotFound("削除したTodoのDeleteTodo", repo.DeleteTodo(ctx, first.ID, alice))
+	if ids := list(alice, true); !slices.Equal(ids, []int64{second.ID}) {
+		t.Fatalf("削除後のaliceの完了したTodo = %v, want %v", ids, []int64{second.ID})
+	}
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-huma-test/db"
+)
+
+// SQLite はSQLiteのtodosテーブルにTodoを保存するリポジトリ。
+// マイグレーション済みのデータベースを使い、クエリはdbパッケージのものを使う。
+// 共有されたTodoは含めず、所有者が一致するTodoのみを対象にする。
+type SQLite struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+// NewSQLite はSQLiteを生成する。sqlDBはマイグレーション済みのデータベース
+func NewSQLite(sqlDB *sql.DB, queries *db.Queries) *SQLite {
+	return &SQLite{db: sqlDB, queries: queries}
+}
+
+// fromSQLite はdbパッケージのTodoをリポジトリのTodoに変換する
+func fromSQLite(t db.Todo) Todo {
+	return Todo{
+		ID:          t.ID,
+		OwnerID:     t.OwnerID,
+		Title:       t.Title,
+		Description: t.Description,
+		Completed:   t.Completed != 0,
+		Priority:    t.Priority,
+		DueAt:       t.DueAt,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+// boolToInt64 は真偽値をSQLiteのINTEGERの値に変換する
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// get は所有者が一致するTodoを返す
+func (r *SQLite) get(ctx context.Context, q *db.Queries, id int64, ownerID sql.NullInt64) (db.Todo, error) {
+	t, err := q.GetTodoByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && t.OwnerID != ownerID) {
+		return db.Todo{}, ErrNotFound
+	}
+	return t, err
+}
+
+// inTx はfnをトランザクションの中で実行し、fnがエラーを返さなければコミットする
+func (r *SQLite) inTx(ctx context.Context, fn func(qtx *db.Queries) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := fn(r.queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListTodos は所有者のTodoのうち、完了状態が一致するものを作成日時の新しい順に返す。
+// アーカイブしたTodoとアーカイブしたプロジェクトのTodoは含めない
+func (r *SQLite) ListTodos(ctx context.Context, arg ListTodosParams) ([]Todo, error) {
+	rows, err := r.queries.ListTodosByStatus(ctx, db.ListTodosByStatusParams{
+		Completed: boolToInt64(arg.Completed),
+		UserID:    arg.OwnerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var todos []Todo
+	for _, t := range rows {
+		// 共有されたTodoは他の保存先にない概念のため除く
+		if t.OwnerID == arg.OwnerID {
+			todos = append(todos, fromSQLite(t))
+		}
+	}
+	return todos, nil
+}
+
+// GetTodo はTodoを返す
+func (r *SQLite) GetTodo(ctx context.Context, id int64, ownerID sql.NullInt64) (Todo, error) {
+	t, err := r.get(ctx, r.queries, id, ownerID)
+	if err != nil {
+		return Todo{}, err
+	}
+	return fromSQLite(t), nil
+}
+
+// CreateTodo は未完了のTodoを作成し、作成したTodoを返す
+func (r *SQLite) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error) {
+	t, err := r.queries.CreateTodo(ctx, db.CreateTodoParams{
+		Title:       arg.Title,
+		Description: arg.Description,
+		DueAt:       arg.DueAt,
+		OwnerID:     arg.OwnerID,
+		Priority:    arg.Priority,
+	})
+	if err != nil {
+		return Todo{}, err
+	}
+	return fromSQLite(t), nil
+}
+
+// UpdateTodo はTodoの内容を置き換え、更新したTodoを返す。
+// リポジトリで扱わない位置情報やプロジェクトなどの項目は変更しない
+func (r *SQLite) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, error) {
+	var updated db.Todo
+	err := r.inTx(ctx, func(qtx *db.Queries) error {
+		current, err := r.get(ctx, qtx, arg.ID, arg.OwnerID)
+		if err != nil {
+			return err
+		}
+		updated, err = qtx.UpdateTodo(ctx, db.UpdateTodoParams{
+			Title:           arg.Title,
+			Description:     arg.Description,
+			Completed:       boolToInt64(arg.Completed),
+			Latitude:        current.Latitude,
+			Longitude:       current.Longitude,
+			PlaceName:       current.PlaceName,
+			ProjectID:       current.ProjectID,
+			DueAt:           arg.DueAt,
+			Recurrence:      current.Recurrence,
+			Assignee:        current.Assignee,
+			Metadata:        current.Metadata,
+			Priority:        arg.Priority,
+			EscalationLevel: current.EscalationLevel,
+			Translations:    current.Translations,
+			ID:              arg.ID,
+		})
+		return err
+	})
+	if err != nil {
+		return Todo{}, err
+	}
+	return fromSQLite(updated), nil
+}
+
+// ToggleTodo はTodoの完了状態を反転し、更新したTodoを返す
+func (r *SQLite) ToggleTodo(ctx context.Context, id int64, ownerID sql.NullInt64) (Todo, error) {
+	var toggled db.Todo
+	err := r.inTx(ctx, func(qtx *db.Queries) error {
+		if _, err := r.get(ctx, qtx, id, ownerID); err != nil {
+			return err
+		}
+		var err error
+		toggled, err = qtx.ToggleTodoCompleted(ctx, id)
+		return err
+	})
+	if err != nil {
+		return Todo{}, err
+	}
+	return fromSQLite(toggled), nil
+}
+
+// DeleteTodo はTodoを削除する
+func (r *SQLite) DeleteTodo(ctx context.Context, id int64, ownerID sql.NullInt64) error {
+	return r.inTx(ctx, func(qtx *db.Queries) error {
+		if _, err := r.get(ctx, qtx, id, ownerID); err != nil {
+			return err
+		}
+		return qtx.DeleteTodo(ctx, id)
+	})
+}
+
+// Ping はデータベースに接続できることを確認する
+func (r *SQLite) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Close はデータベースを閉じる
+func (r *SQLite) Close() error {
+	return r.db.Close()
+}
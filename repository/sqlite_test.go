@@ -0,0 +1,36 @@
+//go:build cgo
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"go-huma-test/db"
+	"go-huma-test/migrate"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSQLite(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "todos.db")+"?_foreign_keys=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := migrate.Load(os.DirFS(".."), "schema/migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrate.New(sqlDB, migrations).Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if _, err := sqlDB.Exec("INSERT INTO users (email, name, password_hash) VALUES ('a@example.com', 'A', ''), ('b@example.com', 'B', '')"); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLite(sqlDB, db.New(sqlDB))
+	t.Cleanup(func() { _ = repo.Close() })
+	testTodoRepository(t, repo)
+}
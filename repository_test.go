@@ -0,0 +1,34 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestUnsupportedRepositoryOptions(t *testing.T) {
+	o, err := defaultOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := unsupportedRepositoryOptions(&o); err != nil || len(got) != 0 {
+		t.Fatalf("既定値のunsupportedRepositoryOptions = %v, %v, want none", got, err)
+	}
+
+	// PostgreSQLのサーバーが使う起動オプションは変えてもよい
+	o.DatabaseURL = "postgres://localhost/todo"
+	o.Port = 9000
+	o.AuthProviders = "static,jwt"
+	o.ShutdownTimeout = time.Second
+	if got, err := unsupportedRepositoryOptions(&o); err != nil || len(got) != 0 {
+		t.Fatalf("unsupportedRepositoryOptions = %v, %v, want none", got, err)
+	}
+
+	o.WebUI = false
+	o.BackupDir = "/var/backups/todo"
+	o.RateLimit = 0
+	want := []string{"rate-limit", "backup-dir", "web-ui"}
+	if got, err := unsupportedRepositoryOptions(&o); err != nil || !slices.Equal(got, want) {
+		t.Fatalf("unsupportedRepositoryOptions = %v, %v, want %v", got, err, want)
+	}
+}
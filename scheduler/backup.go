@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+	"go-huma-test/backup"
+	"log/slog"
+	"time"
+)
+
+// BackupScheduler は一定間隔でデータベースのバックアップを作成するスケジューラー
+type BackupScheduler struct {
+	manager  *backup.Manager
+	interval time.Duration
+}
+
+// NewBackupScheduler はBackupSchedulerの新しいインスタンスを生成する
+func NewBackupScheduler(manager *backup.Manager, interval time.Duration) *BackupScheduler {
+	return &BackupScheduler{
+		manager:  manager,
+		interval: interval,
+	}
+}
+
+// Run はctxがキャンセルされるまでスケジューラーを実行する。
+// 起動直後ではなく最初の間隔が経過してからバックアップを作成し、再起動を繰り返してもバックアップが増えすぎないようにする。
+func (s *BackupScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	slog.Info("バックアップスケジューラーを開始", "interval", s.interval, "dir", s.manager.Dir())
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("バックアップスケジューラーを停止")
+			return
+		case <-ticker.C:
+			info, err := s.manager.Create(ctx)
+			if err != nil {
+				slog.Warn("バックアップの作成に失敗", "err", err)
+				continue
+			}
+			slog.Info("バックアップを作成", "name", info.Name, "size", info.Size)
+		}
+	}
+}
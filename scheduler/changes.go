@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"log/slog"
+	"time"
+)
+
+// ChangeWatcher はTodoの変更を検知するたびにinvalidateを呼び出し、応答のキャッシュなどを破棄させる。
+// 同じプロセスの変更は変更イベントで、MCPサーバーやインポートなど他のプロセスの変更はoutboxに記録されたイベントで検知する。
+// outboxは送信先に送れない間も記録されるため、中継が止まっていても変更を検知できる。
+type ChangeWatcher struct {
+	queries    *db.Queries
+	bus        *event.Bus
+	interval   time.Duration
+	invalidate func()
+
+	// latest は最後に確認したoutboxのイベントのID
+	latest int64
+}
+
+// NewChangeWatcher はChangeWatcherの新しいインスタンスを生成する。
+// 他のプロセスの変更はintervalごとにoutboxを確認して検知する。
+func NewChangeWatcher(queries *db.Queries, bus *event.Bus, interval time.Duration, invalidate func()) *ChangeWatcher {
+	return &ChangeWatcher{
+		queries:    queries,
+		bus:        bus,
+		interval:   interval,
+		invalidate: invalidate,
+	}
+}
+
+// Run はctxがキャンセルされるまで変更を検知する
+func (w *ChangeWatcher) Run(ctx context.Context) {
+	events, unsubscribe := w.bus.Subscribe(64)
+	defer unsubscribe()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.latest, _ = w.latestEventID(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-events:
+			if !ok {
+				// シャットダウンでバスを閉じた後はoutboxの確認だけを続ける
+				events = nil
+				continue
+			}
+			// 破棄より後に記録された変更を見逃さないよう、破棄する前にIDを確認する
+			if latest, ok := w.latestEventID(ctx); ok {
+				w.latest = latest
+			}
+			w.invalidate()
+		case <-ticker.C:
+			// 送信済みのイベントの削除でIDが戻ることもあるため、増えたときだけでなく変わったときに破棄する
+			if latest, ok := w.latestEventID(ctx); ok && latest != w.latest {
+				w.latest = latest
+				w.invalidate()
+			}
+		}
+	}
+}
+
+// latestEventID はoutboxの最新のイベントのIDを返す。取得できない場合はfalseを返す
+func (w *ChangeWatcher) latestEventID(ctx context.Context) (int64, bool) {
+	id, err := w.queries.GetLatestOutboxEventID(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("outboxの最新のイベントの取得に失敗", "err", err)
+		}
+		return 0, false
+	}
+	return id, true
+}
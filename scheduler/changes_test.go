@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestChangeWatcherInvalidates(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if _, err := sqlDB.Exec("CREATE TABLE outbox (id INTEGER PRIMARY KEY AUTOINCREMENT, type TEXT NOT NULL)"); err != nil {
+		t.Fatal(err)
+	}
+
+	bus := event.NewBus()
+	var invalidations atomic.Int32
+	w := NewChangeWatcher(db.New(sqlDB), bus, 10*time.Millisecond, func() { invalidations.Add(1) })
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	waitFor := func(want int32) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for invalidations.Load() < want {
+			if time.Now().After(deadline) {
+				t.Fatalf("invalidations = %d, want %d", invalidations.Load(), want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// 購読を始めるまで待ってから、同じプロセスの変更を通知する
+	time.Sleep(20 * time.Millisecond)
+	if n := invalidations.Load(); n != 0 {
+		t.Fatalf("invalidations before any change = %d", n)
+	}
+	bus.Publish(event.Event{Type: event.TodoUpdated, TodoID: 1})
+	waitFor(1)
+
+	// 他のプロセスがoutboxに記録した変更
+	if _, err := sqlDB.Exec("INSERT INTO outbox (type) VALUES ('todo.updated')"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(2)
+
+	// 変更がなければ破棄しない
+	time.Sleep(50 * time.Millisecond)
+	if n := invalidations.Load(); n != 2 {
+		t.Fatalf("invalidations without changes = %d, want 2", n)
+	}
+}
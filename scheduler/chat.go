@@ -0,0 +1,175 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/notify"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// chatOverdueInterval は期限を過ぎたTodoを探してチャットに投稿する間隔
+const chatOverdueInterval = time.Minute
+
+// chatOverdueWindow は期限切れを投稿するTodoの期限の範囲。停止中や購読前に期限を過ぎた古いTodoをまとめて投稿しないよう、
+// 直近に期限を過ぎたTodoだけを投稿する
+const chatOverdueWindow = 24 * time.Hour
+
+// ChatForwarder はTodoの変更イベントと期限切れを、Todoが属するプロジェクトに設定されたDiscord、SlackやTeamsのチャットへ投稿する。
+// 送信先ごとに設定されたイベントの種類だけを投稿し、削除されたTodoは内容を取得できないため投稿しない。
+// 1回の変更で投稿するのは1件で、完了や担当者の設定を購読している送信先には更新の代わりにそのイベントを投稿する。
+type ChatForwarder struct {
+	queries *db.Queries
+	bus     *event.Bus
+	chats   map[string]*notify.ChatNotifier
+	clock   clock.Clock
+}
+
+// NewChatForwarder はChatForwarderの新しいインスタンスを生成する。chatsはチャット名（discordなど）ごとの投稿先。
+func NewChatForwarder(queries *db.Queries, bus *event.Bus, chats map[string]*notify.ChatNotifier, clock clock.Clock) *ChatForwarder {
+	return &ChatForwarder{
+		queries: queries,
+		bus:     bus,
+		chats:   chats,
+		clock:   clock,
+	}
+}
+
+// Run はctxがキャンセルされるまでイベントと期限切れを投稿する
+func (f *ChatForwarder) Run(ctx context.Context) {
+	events, unsubscribe := f.bus.Subscribe(256)
+	defer unsubscribe()
+	ticker := time.NewTicker(chatOverdueInterval)
+	defer ticker.Stop()
+
+	slog.Info("チャットへのイベント投稿を開始")
+	f.sweepOverdue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("チャットへのイベント投稿を停止")
+			return
+		case <-ticker.C:
+			f.sweepOverdue(ctx)
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if e.Type != event.TodoDeleted {
+				f.forward(ctx, e)
+			}
+		}
+	}
+}
+
+// chatEventTypes はイベントを投稿するときの種類の候補を、優先する順に返す
+func chatEventTypes(e event.Event) []string {
+	var types []string
+	if e.Completed {
+		types = append(types, string(event.TodoCompleted))
+	}
+	if e.Assigned {
+		types = append(types, string(event.TodoAssigned))
+	}
+	return append(types, string(e.Type))
+}
+
+// forward はイベントを購読している送信先へ投稿する
+func (f *ChatForwarder) forward(ctx context.Context, e event.Event) {
+	deliveries, err := f.queries.ListTodoChatDeliveries(ctx, e.TodoID)
+	if err != nil {
+		slog.Warn("チャットの送信先の取得に失敗", "todo_id", e.TodoID, "err", err)
+		return
+	}
+
+	types := chatEventTypes(e)
+	for _, d := range deliveries {
+		var subscribed []string
+		if err := json.Unmarshal([]byte(d.Events), &subscribed); err != nil {
+			slog.Warn("チャットの購読イベントを解析できません", "todo_id", e.TodoID, "provider", d.Provider, "err", err)
+			continue
+		}
+		i := slices.IndexFunc(types, func(t string) bool { return slices.Contains(subscribed, t) })
+		if i < 0 {
+			continue
+		}
+		n := notify.Notification{
+			Kind:     notify.KindTodoEvent,
+			TodoID:   e.TodoID,
+			Title:    d.Title,
+			Message:  d.Description.String,
+			Channel:  d.Provider,
+			Event:    types[i],
+			Project:  d.ProjectName,
+			Assignee: d.Assignee.String,
+			DueAt:    d.DueAt.Time,
+		}
+		if err := f.post(ctx, d.Provider, d.WebhookUrl, d.Templates, n); err != nil {
+			slog.Warn("チャットへのイベント投稿に失敗", "todo_id", e.TodoID, "provider", d.Provider, "type", types[i], "err", err)
+		}
+	}
+}
+
+// sweepOverdue は直近に期限を過ぎた未完了のTodoを、期限切れを購読している送信先へ投稿する。
+// 投稿に失敗しても同じ期限の期限切れは再送せず、期限が変わった場合は改めて投稿する。
+func (f *ChatForwarder) sweepOverdue(ctx context.Context) {
+	now := f.clock.Now().UTC()
+	rows, err := f.queries.ListChatOverdueDeliveries(ctx, db.ListChatOverdueDeliveriesParams{
+		Since: sql.NullTime{Time: now.Add(-chatOverdueWindow), Valid: true},
+		Now:   sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.Warn("期限を過ぎたTodoの取得に失敗", "err", err)
+		}
+		return
+	}
+
+	for i, r := range rows {
+		n := notify.Notification{
+			Kind:     notify.KindTodoEvent,
+			TodoID:   r.TodoID,
+			Title:    r.Title,
+			Message:  r.Description.String,
+			Channel:  r.Provider,
+			Event:    string(event.TodoOverdue),
+			Project:  r.ProjectName,
+			Assignee: r.Assignee.String,
+			DueAt:    r.DueAt.Time,
+		}
+		if err := f.post(ctx, r.Provider, r.WebhookUrl, r.Templates, n); err != nil {
+			slog.Warn("チャットへの期限切れの投稿に失敗", "todo_id", r.TodoID, "provider", r.Provider, "err", err)
+		}
+		// 送信先の順に並んでいるため、Todoの最後の送信先に投稿した後に記録する
+		if i+1 < len(rows) && rows[i+1].TodoID == r.TodoID {
+			continue
+		}
+		if err := f.queries.RecordChatOverduePost(ctx, db.RecordChatOverduePostParams{
+			TodoID:   r.TodoID,
+			DueAt:    r.DueAt.Time,
+			PostedAt: now,
+		}); err != nil {
+			slog.Warn("期限切れの投稿の記録に失敗", "todo_id", r.TodoID, "err", err)
+		}
+	}
+}
+
+// post は送信先にイベントのテンプレートがあれば適用して投稿する。テンプレートを解析できない場合は既定の形式で投稿する
+func (f *ChatForwarder) post(ctx context.Context, provider, url, templates string, n notify.Notification) error {
+	chat, ok := f.chats[provider]
+	if !ok {
+		return nil
+	}
+	tmpl, err := notify.LookupChatTemplate(templates, n.Event)
+	if err != nil {
+		slog.Warn("チャットのテンプレートを使えないため既定の形式で投稿", "todo_id", n.TodoID, "provider", provider, "event", n.Event, "err", err)
+		tmpl = nil
+	}
+	return chat.Post(ctx, url, n, tmpl)
+}
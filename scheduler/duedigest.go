@@ -0,0 +1,220 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/actionlink"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/locale"
+	"go-huma-test/notify"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// maxDueDigestItems は1通のまとめに含めるTodoの最大件数
+const maxDueDigestItems = 50
+
+// dueDigestCheckInterval は送信時刻を過ぎたかと、まとめを送っていない所有者がいるかを確認する間隔
+const dueDigestCheckInterval = time.Minute
+
+// dueDigestRetention は送信の記録を残す期間
+const dueDigestRetention = 30 * 24 * time.Hour
+
+// ParseDigestTime は"08:30"のような時刻を解析し、0時からの経過時間を返す
+func ParseDigestTime(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("時刻はHH:MM形式で指定してください: %s", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// DueDigestScheduler は期限が今日までの未完了のTodoを所有者ごとにまとめ、毎日決まった時刻に通知するスケジューラー。
+// 日付と時刻はformatterのタイムゾーンで数える。所有者ごとに送った日付をデータベースに記録し、
+// 送信時刻に停止していた場合も、その日のうちに再起動すればまだ送っていない所有者に送る。
+type DueDigestScheduler struct {
+	queries   *db.Queries
+	notifier  notify.Notifier
+	links     *actionlink.Signer
+	channel   string
+	formatter *locale.Formatter
+	at        time.Duration
+	clock     clock.Clock
+}
+
+// NewDueDigestScheduler はDueDigestSchedulerの新しいインスタンスを生成する。
+// clockの時刻で毎日0時からatが経った後にchannelで通知する。linksを指定した場合は、Todoを1回だけ完了にできるリンクを含める。
+func NewDueDigestScheduler(queries *db.Queries, notifier notify.Notifier, links *actionlink.Signer, channel string, formatter *locale.Formatter, at time.Duration, clock clock.Clock) *DueDigestScheduler {
+	return &DueDigestScheduler{
+		queries:   queries,
+		notifier:  notifier,
+		links:     links,
+		channel:   channel,
+		formatter: formatter,
+		at:        at,
+		clock:     clock,
+	}
+}
+
+// Run はctxがキャンセルされるまでスケジューラーを実行する
+func (s *DueDigestScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(dueDigestCheckInterval)
+	defer ticker.Stop()
+
+	slog.Info("期限が近いTodoのまとめの通知を開始", "at", s.at, "timezone", s.formatter.Location().String(), "channel", s.channel)
+	var lastPrune string
+	for {
+		if date := s.sweep(ctx); date != "" && date != lastPrune {
+			s.prune(ctx)
+			lastPrune = date
+		}
+		select {
+		case <-ctx.Done():
+			slog.Info("期限が近いTodoのまとめの通知を停止")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep は送信時刻を過ぎていれば、今日のまとめをまだ送っていない所有者に通知し、今日の日付を返す。
+// 送信時刻の前は何もせず空文字を返す。
+func (s *DueDigestScheduler) sweep(ctx context.Context) string {
+	now := s.clock.Now().In(s.formatter.Location())
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if now.Before(dayStart.Add(s.at)) {
+		return ""
+	}
+	date := dayStart.Format(time.DateOnly)
+
+	todos, err := s.queries.ListDueDigestTodos(ctx, db.ListDueDigestTodosParams{
+		DayEnd:     sql.NullTime{Time: dayStart.AddDate(0, 0, 1).UTC(), Valid: true},
+		DigestDate: date,
+	})
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.Warn("期限が近いTodoの取得に失敗", "err", err)
+		}
+		return date
+	}
+
+	projects := make(map[int64]string)
+	// Todoは所有者の順に並んでいる
+	for start := 0; start < len(todos); {
+		end := start + 1
+		for end < len(todos) && todos[end].OwnerID == todos[start].OwnerID {
+			end++
+		}
+		owner := todos[start].OwnerID.Int64
+		if err := s.send(ctx, owner, todos[start:end], projects, date, now); err != nil {
+			slog.Warn("期限が近いTodoのまとめの通知に失敗", "owner_id", owner, "channel", s.channel, "err", err)
+		}
+		start = end
+	}
+	return date
+}
+
+// send は所有者のTodoを1通にまとめて通知し、その日の送信として記録する
+func (s *DueDigestScheduler) send(ctx context.Context, owner int64, todos []db.Todo, projects map[int64]string, date string, now time.Time) error {
+	user, err := s.queries.GetUser(ctx, owner)
+	if err != nil {
+		return fmt.Errorf("Todoの所有者の取得に失敗: %w", err)
+	}
+
+	overdue := 0
+	items := make([]notify.DigestItem, 0, min(len(todos), maxDueDigestItems))
+	for i, t := range todos {
+		if t.DueAt.Time.Before(now) {
+			overdue++
+		}
+		if i >= maxDueDigestItems {
+			continue
+		}
+		item := notify.DigestItem{
+			TodoID:    t.ID,
+			Title:     t.Title,
+			Project:   projectName(ctx, s.queries, t.ProjectID, projects),
+			UpdatedAt: t.UpdatedAt,
+			DueAt:     t.DueAt.Time,
+			Overdue:   t.DueAt.Time.Before(now),
+		}
+		if s.links != nil {
+			// リンクを作れない場合もまとめは送るため、警告を記録してリンクのない項目にする
+			link, err := s.links.QuickActionLink(ctx, actionlink.ActionComplete, t.ID, owner, s.channel)
+			if err != nil {
+				slog.Warn("まとめの完了のリンクの作成に失敗", "todo_id", t.ID, "err", err)
+			} else {
+				item.Actions = map[string]string{actionlink.ActionComplete: link}
+			}
+		}
+		items = append(items, item)
+	}
+
+	f := s.formatter
+	if err := s.notifier.Notify(ctx, notify.Notification{
+		Kind: notify.KindDueDigest,
+		Title: fmt.Sprintf(f.Label("%[1]sまでが期限のTodoが%[2]d件あります（期限切れ%[3]d件）", "%[2]d todos due by %[1]s (%[3]d overdue)"),
+			f.Date(now), len(todos), overdue),
+		Message:   s.message(items, len(todos)),
+		Channel:   s.channel,
+		Recipient: user.Email,
+		Items:     items,
+		Total:     len(todos),
+	}); err != nil {
+		return err
+	}
+
+	if err := s.queries.CreateDueDigest(ctx, db.CreateDueDigestParams{
+		OwnerID:    owner,
+		DigestDate: date,
+		TodoCount:  int64(len(todos)),
+		SentAt:     now.UTC(),
+	}); err != nil {
+		return fmt.Errorf("送信の記録に失敗: %w", err)
+	}
+	slog.Info("期限が近いTodoのまとめを通知", "owner_id", owner, "date", date, "count", len(todos), "overdue", overdue)
+	return nil
+}
+
+// message はTodoを期限の順に並べ、期限切れの印と完了のリンクを添えた本文を返す
+func (s *DueDigestScheduler) message(items []notify.DigestItem, total int) string {
+	f := s.formatter
+	var b strings.Builder
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "- %s %s", f.DateTime(item.DueAt), item.Title)
+		if item.Project != "" {
+			fmt.Fprintf(&b, " [%s]", item.Project)
+		}
+		if item.Overdue {
+			fmt.Fprintf(&b, " (%s)", f.Label("期限切れ", "overdue"))
+		}
+		if link, ok := item.Actions[actionlink.ActionComplete]; ok {
+			fmt.Fprintf(&b, "\n  %s: %s", f.Label("完了にする", "Complete"), link)
+		}
+	}
+	if total > len(items) {
+		fmt.Fprintf(&b, "\n\n"+f.Label("ほか%d件", "and %d more"), total-len(items))
+	}
+	return b.String()
+}
+
+// prune は保持期間を過ぎた送信の記録を削除する
+func (s *DueDigestScheduler) prune(ctx context.Context) {
+	n, err := s.queries.PruneDueDigests(ctx, s.clock.Now().Add(-dueDigestRetention).UTC())
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.Warn("期限が近いTodoのまとめの送信の記録の削除に失敗", "err", err)
+		}
+		return
+	}
+	if n > 0 {
+		slog.Info("期限が近いTodoのまとめの送信の記録を削除", "count", n)
+	}
+}
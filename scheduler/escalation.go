@@ -0,0 +1,217 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/locale"
+	"go-huma-test/notify"
+	"go-huma-test/outbox"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// MaxPriority はTodoの優先度の最大値（高）
+const MaxPriority = 3
+
+// ParseEscalationThresholds は"24h,72h"のようにカンマ区切りで指定された、優先度を引き上げる期限超過時間を解析する。
+// 各時間は正の値で、短い順に並べる必要がある。空文字列の場合は空のスライスを返す。
+func ParseEscalationThresholds(s string) ([]time.Duration, error) {
+	var thresholds []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, fmt.Errorf("時間を解析できません: %s", part)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("正の時間を指定してください: %s", part)
+		}
+		if len(thresholds) > 0 && d <= thresholds[len(thresholds)-1] {
+			return nil, fmt.Errorf("短い順に指定してください: %s", part)
+		}
+		thresholds = append(thresholds, d)
+	}
+	return thresholds, nil
+}
+
+// EscalationScheduler は期限を過ぎた未完了のTodoの優先度を、超過時間の閾値に応じて一定間隔で引き上げるスケジューラー。
+// 閾値を1つ超えるごとに優先度を1段階上げ、アクティビティログに記録して所有者に通知する。
+type EscalationScheduler struct {
+	queries    *db.Queries
+	db         *sql.DB
+	bus        *event.Bus
+	notifier   notify.Notifier
+	channel    string
+	formatter  *locale.Formatter
+	thresholds []time.Duration
+	interval   time.Duration
+	clock      clock.Clock
+}
+
+// NewEscalationScheduler はEscalationSchedulerの新しいインスタンスを生成する。
+// 通知はchannelで送信し、本文の日時はformatterの言語とタイムゾーンで書式化する。
+func NewEscalationScheduler(queries *db.Queries, db *sql.DB, bus *event.Bus, notifier notify.Notifier, channel string, formatter *locale.Formatter, thresholds []time.Duration, interval time.Duration, clock clock.Clock) *EscalationScheduler {
+	return &EscalationScheduler{
+		queries:    queries,
+		db:         db,
+		bus:        bus,
+		notifier:   notifier,
+		channel:    channel,
+		formatter:  formatter,
+		thresholds: thresholds,
+		interval:   interval,
+		clock:      clock,
+	}
+}
+
+// Run はctxがキャンセルされるまでスケジューラーを実行する
+func (s *EscalationScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	slog.Info("優先度引き上げスケジューラーを開始", "interval", s.interval, "thresholds", s.thresholds)
+	s.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("優先度引き上げスケジューラーを停止")
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// level は期限の超過時間から到達した引き上げの段階を返す
+func (s *EscalationScheduler) level(overdue time.Duration) int64 {
+	var level int64
+	for _, t := range s.thresholds {
+		if overdue < t {
+			break
+		}
+		level++
+	}
+	return level
+}
+
+// sweep は期限を過ぎ、次の段階に達したTodoの優先度を引き上げる
+func (s *EscalationScheduler) sweep(ctx context.Context) {
+	now := s.clock.Now().UTC()
+	todos, err := s.queries.ListOverdueTodos(ctx, db.ListOverdueTodosParams{
+		Now:      sql.NullTime{Time: now, Valid: true},
+		MaxLevel: int64(len(s.thresholds)),
+	})
+	if err != nil {
+		slog.Warn("期限切れのTodoの取得に失敗", "err", err)
+		return
+	}
+
+	for _, t := range todos {
+		level := s.level(now.Sub(t.DueAt.Time))
+		if level <= t.EscalationLevel {
+			continue
+		}
+		if err := s.escalate(ctx, t, level); err != nil {
+			slog.Warn("優先度の引き上げに失敗", "todo_id", t.ID, "err", err)
+		}
+	}
+}
+
+// escalate はTodoの優先度を到達した段階の分だけ引き上げ、所有者に通知する。
+// 通知に失敗しても引き上げは取り消さず、同じ段階の通知は再送しない。
+func (s *EscalationScheduler) escalate(ctx context.Context, t db.Todo, level int64) error {
+	priority := min(t.Priority+level-t.EscalationLevel, MaxPriority)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("トランザクション開始に失敗: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := s.queries.WithTx(tx)
+
+	escalated, err := qtx.EscalateTodo(ctx, db.EscalateTodoParams{
+		Priority:        priority,
+		EscalationLevel: level,
+		ID:              t.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// 走査後に完了したか、他の処理で既に引き上げ済み
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("優先度の更新に失敗: %w", err)
+	}
+	if err := activity.Record(ctx, qtx, activity.ActionEscalate, &t, &escalated); err != nil {
+		return err
+	}
+	e := event.Event{Type: event.TodoUpdated, TodoID: t.ID}
+	if err := outbox.Record(ctx, qtx, e); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+	}
+
+	slog.Info("期限切れのTodoの優先度を引き上げ", "todo_id", t.ID, "level", level, "from", t.Priority, "to", priority)
+	s.bus.Publish(e)
+
+	s.notify(ctx, escalated)
+	return nil
+}
+
+// notify は優先度を引き上げたことをTodoの所有者に通知する。
+// 所有者がいない場合はチャネルに設定された宛先に送る。
+func (s *EscalationScheduler) notify(ctx context.Context, t db.Todo) {
+	var recipient string
+	if t.OwnerID.Valid {
+		owner, err := s.queries.GetUser(ctx, t.OwnerID.Int64)
+		if err != nil {
+			slog.Warn("Todoの所有者の取得に失敗", "todo_id", t.ID, "owner_id", t.OwnerID.Int64, "err", err)
+		} else {
+			recipient = owner.Email
+		}
+	}
+
+	f := s.formatter
+	message := fmt.Sprintf(f.Label("期限（%s、%s）を過ぎたため、優先度を%sに引き上げました", "Priority was raised to %[3]s because the due date (%[1]s, %[2]s) has passed"),
+		f.DateTime(t.DueAt.Time), f.Relative(t.DueAt.Time), priorityLabel(f, t.Priority))
+	if err := s.notifier.Notify(ctx, notify.Notification{
+		Kind:      notify.KindEscalation,
+		TodoID:    t.ID,
+		Title:     t.Title,
+		Message:   message,
+		Channel:   s.channel,
+		Recipient: recipient,
+	}); err != nil {
+		slog.Warn("優先度の引き上げの通知に失敗", "todo_id", t.ID, "channel", s.channel, "err", err)
+	}
+}
+
+// priorityLabel は優先度を言語に合わせた名前で返す
+func priorityLabel(f *locale.Formatter, priority int64) string {
+	switch priority {
+	case 1:
+		return f.Label("低", "low")
+	case 2:
+		return f.Label("中", "medium")
+	case MaxPriority:
+		return f.Label("高", "high")
+	default:
+		return f.Label("なし", "none")
+	}
+}
@@ -0,0 +1,264 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// autoVacuumIncremental はPRAGMA auto_vacuumがincrementalの場合の値
+const autoVacuumIncremental = 2
+
+// MaintenanceConfig はデータベースの保守を実行する間隔を表す構造体。間隔が0の処理は実行しない。
+type MaintenanceConfig struct {
+	CheckpointInterval time.Duration
+	AnalyzeInterval    time.Duration
+	VacuumInterval     time.Duration
+	// VacuumPages は1回のincremental vacuumで解放するページ数の上限。0の場合は空きページをすべて解放する
+	VacuumPages int
+}
+
+// CheckpointMetrics はWALのチェックポイントの実行結果を表す構造体
+type CheckpointMetrics struct {
+	At                 time.Time `json:"at"`
+	DurationMS         float64   `json:"duration_ms"`
+	Busy               bool      `json:"busy"`
+	WALFrames          int       `json:"wal_frames"`
+	CheckpointedFrames int       `json:"checkpointed_frames"`
+	WALBytesBefore     int64     `json:"wal_bytes_before"`
+	WALBytesAfter      int64     `json:"wal_bytes_after"`
+}
+
+// TaskMetrics はANALYZEやincremental vacuumの実行結果を表す構造体
+type TaskMetrics struct {
+	At         time.Time `json:"at"`
+	DurationMS float64   `json:"duration_ms"`
+	// FreedPages はincremental vacuumで解放したページ数
+	FreedPages int64 `json:"freed_pages,omitempty"`
+}
+
+// MaintenanceMetrics はデータベースの保守の状況を表す構造体。/debug/varsに公開する。
+type MaintenanceMetrics struct {
+	WALBytes                  int64              `json:"wal_bytes"`
+	Checkpoints               int64              `json:"checkpoints"`
+	CheckpointsBusy           int64              `json:"checkpoints_busy"`
+	CheckpointDurationTotalMS float64            `json:"checkpoint_duration_total_ms"`
+	CheckpointDurationMaxMS   float64            `json:"checkpoint_duration_max_ms"`
+	LastCheckpoint            *CheckpointMetrics `json:"last_checkpoint,omitempty"`
+	LastAnalyze               *TaskMetrics       `json:"last_analyze,omitempty"`
+	LastVacuum                *TaskMetrics       `json:"last_vacuum,omitempty"`
+	FreelistPages             int64              `json:"freelist_pages"`
+	Errors                    int64              `json:"errors"`
+}
+
+// MaintenanceScheduler はWALのチェックポイント、ANALYZE、incremental vacuumをそれぞれの間隔で実行するスケジューラー
+type MaintenanceScheduler struct {
+	db      *sql.DB
+	walPath string
+	config  MaintenanceConfig
+
+	mu      sync.Mutex
+	metrics MaintenanceMetrics
+}
+
+// NewMaintenanceScheduler はMaintenanceSchedulerの新しいインスタンスを生成する。
+// walPathはWALのファイルのパスで、インメモリのデータベースなどWALのファイルがない場合は空にする。
+func NewMaintenanceScheduler(db *sql.DB, walPath string, config MaintenanceConfig) *MaintenanceScheduler {
+	return &MaintenanceScheduler{
+		db:      db,
+		walPath: walPath,
+		config:  config,
+	}
+}
+
+// Metrics は保守の状況を返す。WALのサイズは呼び出した時点の値にする。
+func (s *MaintenanceScheduler) Metrics() MaintenanceMetrics {
+	size := s.walSize()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.metrics
+	m.WALBytes = size
+	return m
+}
+
+// Run はctxがキャンセルされるまでスケジューラーを実行する
+func (s *MaintenanceScheduler) Run(ctx context.Context) {
+	checkpoints := newOptionalTicker(s.config.CheckpointInterval)
+	defer checkpoints.Stop()
+	analyzes := newOptionalTicker(s.config.AnalyzeInterval)
+	defer analyzes.Stop()
+	vacuums := newOptionalTicker(s.config.VacuumInterval)
+	defer vacuums.Stop()
+
+	slog.Info("データベースの保守スケジューラーを開始",
+		"checkpoint_interval", s.config.CheckpointInterval,
+		"analyze_interval", s.config.AnalyzeInterval,
+		"vacuum_interval", s.config.VacuumInterval)
+	if s.config.VacuumInterval > 0 {
+		var mode int
+		if err := s.db.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&mode); err == nil && mode != autoVacuumIncremental {
+			slog.Warn("auto_vacuumがincrementalではないため、incremental vacuumは空きページを解放しません。PRAGMA auto_vacuum = INCREMENTALの後にVACUUMを一度実行してください")
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("データベースの保守スケジューラーを停止")
+			return
+		case <-checkpoints.C:
+			if _, err := s.Checkpoint(ctx); err != nil {
+				slog.Warn("WALのチェックポイントに失敗", "err", err)
+			}
+		case <-analyzes.C:
+			if err := s.analyze(ctx); err != nil {
+				slog.Warn("ANALYZEに失敗", "err", err)
+			}
+		case <-vacuums.C:
+			if err := s.vacuum(ctx); err != nil {
+				slog.Warn("incremental vacuumに失敗", "err", err)
+			}
+		}
+	}
+}
+
+// Checkpoint はWALの内容をデータベースのファイルに書き戻し、WALのファイルを空にする。
+// 読み取り中のコネクションがありWALを空にできなかった場合は、結果のBusyがtrueになる。
+func (s *MaintenanceScheduler) Checkpoint(ctx context.Context) (CheckpointMetrics, error) {
+	result := CheckpointMetrics{At: time.Now(), WALBytesBefore: s.walSize()}
+	var busy int
+	err := s.db.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &result.WALFrames, &result.CheckpointedFrames)
+	if err != nil {
+		s.recordError()
+		return CheckpointMetrics{}, fmt.Errorf("WALのチェックポイントに失敗: %w", err)
+	}
+	result.DurationMS = durationMS(time.Since(result.At))
+	result.Busy = busy != 0
+	result.WALBytesAfter = s.walSize()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics.Checkpoints++
+	if result.Busy {
+		s.metrics.CheckpointsBusy++
+	}
+	s.metrics.CheckpointDurationTotalMS += result.DurationMS
+	s.metrics.CheckpointDurationMaxMS = max(s.metrics.CheckpointDurationMaxMS, result.DurationMS)
+	s.metrics.LastCheckpoint = &result
+	slog.Debug("WALのチェックポイントを実行", "busy", result.Busy, "wal_frames", result.WALFrames, "checkpointed_frames", result.CheckpointedFrames, "duration_ms", result.DurationMS)
+	return result, nil
+}
+
+// analyze はクエリプランナーが使う統計情報を更新する
+func (s *MaintenanceScheduler) analyze(ctx context.Context) error {
+	start := time.Now()
+	if _, err := s.db.ExecContext(ctx, "ANALYZE"); err != nil {
+		s.recordError()
+		return err
+	}
+	result := TaskMetrics{At: start, DurationMS: durationMS(time.Since(start))}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics.LastAnalyze = &result
+	slog.Info("ANALYZEを実行", "duration_ms", result.DurationMS)
+	return nil
+}
+
+// vacuum はincremental vacuumで空きページを解放し、データベースのファイルを縮める
+func (s *MaintenanceScheduler) vacuum(ctx context.Context) error {
+	start := time.Now()
+	before, err := s.freelistCount(ctx)
+	if err != nil {
+		s.recordError()
+		return err
+	}
+	// incremental_vacuumは解放したページごとに行を返すため、すべて読み切るまで実行が続く
+	// PRAGMAの引数はパラメータにできないため、整数を埋め込む
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("PRAGMA incremental_vacuum(%d)", s.config.VacuumPages))
+	if err != nil {
+		s.recordError()
+		return err
+	}
+	for rows.Next() {
+		// 返された行は使わない
+	}
+	err = errors.Join(rows.Err(), rows.Close())
+	if err != nil {
+		s.recordError()
+		return err
+	}
+	after, err := s.freelistCount(ctx)
+	if err != nil {
+		s.recordError()
+		return err
+	}
+	result := TaskMetrics{At: start, DurationMS: durationMS(time.Since(start)), FreedPages: before - after}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics.LastVacuum = &result
+	s.metrics.FreelistPages = after
+	if result.FreedPages > 0 {
+		slog.Info("incremental vacuumで空きページを解放", "freed_pages", result.FreedPages, "duration_ms", result.DurationMS)
+	}
+	return nil
+}
+
+// freelistCount は使われていないページ数を返す
+func (s *MaintenanceScheduler) freelistCount(ctx context.Context) (int64, error) {
+	var n int64
+	err := s.db.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&n)
+	return n, err
+}
+
+// walSize はWALのファイルのサイズを返す。ファイルがない場合は0を返す。
+func (s *MaintenanceScheduler) walSize() int64 {
+	if s.walPath == "" {
+		return 0
+	}
+	info, err := os.Stat(s.walPath)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// recordError は保守の失敗を数える
+func (s *MaintenanceScheduler) recordError() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics.Errors++
+}
+
+// durationMS は所要時間をミリ秒にする
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// optionalTicker は間隔が0の場合に発火しないTicker
+type optionalTicker struct {
+	*time.Ticker
+	C <-chan time.Time
+}
+
+// newOptionalTicker はintervalごとに発火するTickerを生成する。intervalが0の場合は発火しない。
+func newOptionalTicker(interval time.Duration) optionalTicker {
+	if interval <= 0 {
+		return optionalTicker{}
+	}
+	t := time.NewTicker(interval)
+	return optionalTicker{Ticker: t, C: t.C}
+}
+
+// Stop はTickerを停止する
+func (t optionalTicker) Stop() {
+	if t.Ticker != nil {
+		t.Ticker.Stop()
+	}
+}
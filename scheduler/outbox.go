@@ -0,0 +1,200 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/outbox"
+	"log/slog"
+	"time"
+)
+
+// outboxBatchSize は1回に取得して送るoutboxのイベントの最大件数
+const outboxBatchSize = 100
+
+// maxOutboxBackoff は送信先への送信に失敗した後、次に送るまでの待ち時間の上限
+const maxOutboxBackoff = time.Minute
+
+// outboxPruneInterval は送信済みのイベントを削除する間隔
+const outboxPruneInterval = time.Hour
+
+// OutboxConfig はoutboxのイベントの中継の設定を表す構造体
+type OutboxConfig struct {
+	// Interval は未送信のイベントを確認する間隔。変更イベントを受け取ったときはすぐに確認する
+	Interval time.Duration
+	// SinkTimeout は1つの送信先への1回の送信で応答を待つ時間
+	SinkTimeout time.Duration
+	// Retention は送信済みのイベントを残す期間
+	Retention time.Duration
+}
+
+// OutboxRelay は変更と同じトランザクションでoutboxに記録したイベントを、記録した順にイベントの送信先に送り、
+// Webhookの配信として記録する。すべての送信先が受け取った後に送信済みにするため、少なくとも1回は送られる。
+// 送信済みにする前にプロセスが終了した場合は再起動後に同じイベントを再送するため、受信側はメッセージのidで重複を除く。
+type OutboxRelay struct {
+	db       *sql.DB
+	queries  *db.Queries
+	bus      *event.Bus
+	sinks    []event.Sink
+	webhooks *WebhookDispatcher
+	config   OutboxConfig
+	clock    clock.Clock
+
+	// backoff は送信に失敗した後の待ち時間。成功すると0に戻す
+	backoff time.Duration
+	// retryAt は送信に失敗した後、次に送る時刻
+	retryAt time.Time
+}
+
+// NewOutboxRelay はOutboxRelayの新しいインスタンスを生成する。sinksは終了時に閉じる。送信済みの日時と保持期間はclockで数える
+func NewOutboxRelay(sqlDB *sql.DB, queries *db.Queries, bus *event.Bus, sinks []event.Sink, webhooks *WebhookDispatcher, config OutboxConfig, clock clock.Clock) *OutboxRelay {
+	return &OutboxRelay{
+		db:       sqlDB,
+		queries:  queries,
+		bus:      bus,
+		sinks:    sinks,
+		webhooks: webhooks,
+		config:   config,
+		clock:    clock,
+	}
+}
+
+// Run はctxがキャンセルされるまでoutboxのイベントを中継する
+func (r *OutboxRelay) Run(ctx context.Context) {
+	// 変更イベントは未送信のイベントを確認するきっかけにだけ使う
+	events, unsubscribe := r.bus.Subscribe(1)
+	defer unsubscribe()
+	defer r.closeSinks()
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	slog.Info("outboxの中継を開始", "interval", r.config.Interval, "sinks", len(r.sinks))
+
+	var lastPrune time.Time
+	for {
+		if time.Now().After(r.retryAt) {
+			r.relayPending(ctx)
+		}
+		if time.Since(lastPrune) >= outboxPruneInterval {
+			r.prune(ctx)
+			lastPrune = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			slog.Info("outboxの中継を停止")
+			return
+		case <-ticker.C:
+		case _, ok := <-events:
+			if !ok {
+				// シャットダウンでバスを閉じた後は一定間隔の確認だけを続ける
+				events = nil
+			}
+		}
+	}
+}
+
+// relayPending は未送信のイベントを記録した順に送る。送信に失敗したイベントがあれば、順序を保つためそこで止める
+func (r *OutboxRelay) relayPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		rows, err := r.queries.ListPendingOutboxEvents(ctx, outboxBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("未送信のoutboxのイベントの取得に失敗", "err", err)
+			}
+			return
+		}
+		for _, row := range rows {
+			if err := r.relay(ctx, row); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				r.backoff = min(max(r.backoff*2, r.config.Interval), maxOutboxBackoff)
+				r.retryAt = time.Now().Add(r.backoff)
+				slog.Warn("outboxのイベントの送信に失敗", "outbox_id", row.ID, "type", row.Type, "todo_id", row.TodoID, "attempts", row.Attempts+1, "retry_in", r.backoff, "err", err)
+				if err := r.queries.RecordOutboxFailure(ctx, db.RecordOutboxFailureParams{
+					LastError: sql.NullString{String: err.Error(), Valid: true},
+					ID:        row.ID,
+				}); err != nil {
+					slog.Warn("outboxの送信の失敗の記録に失敗", "outbox_id", row.ID, "err", err)
+				}
+				return
+			}
+			r.backoff = 0
+		}
+		if len(rows) < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// relay は1件のイベントをすべての送信先に送り、Webhookの配信の記録と同じトランザクションで送信済みにする
+func (r *OutboxRelay) relay(ctx context.Context, row db.Outbox) error {
+	e := outbox.ToEvent(row)
+	for _, sink := range r.sinks {
+		for _, m := range event.Messages(row.ID, e) {
+			sendCtx, cancel := context.WithTimeout(ctx, r.config.SinkTimeout)
+			err := sink.Send(sendCtx, m)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("%s: %w", sink.Name(), err)
+			}
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("トランザクション開始に失敗: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := r.queries.WithTx(tx)
+
+	queued, err := r.webhooks.Enqueue(ctx, qtx, e)
+	if err != nil {
+		return err
+	}
+	if err := qtx.MarkOutboxEventPublished(ctx, db.MarkOutboxEventPublishedParams{
+		PublishedAt: sql.NullTime{Time: r.clock.Now().UTC(), Valid: true},
+		ID:          row.ID,
+	}); err != nil {
+		return fmt.Errorf("送信済みの記録に失敗: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+	}
+
+	if queued {
+		r.webhooks.Wake()
+	}
+	return nil
+}
+
+// prune は保持期間を過ぎた送信済みのイベントを削除する
+func (r *OutboxRelay) prune(ctx context.Context) {
+	n, err := r.queries.PruneOutbox(ctx, sql.NullTime{Time: r.clock.Now().Add(-r.config.Retention).UTC(), Valid: true})
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.Warn("送信済みのoutboxのイベントの削除に失敗", "err", err)
+		}
+		return
+	}
+	if n > 0 {
+		slog.Info("送信済みのoutboxのイベントを削除", "count", n)
+	}
+}
+
+// closeSinks は送信先との接続を閉じる
+func (r *OutboxRelay) closeSinks() {
+	for _, sink := range r.sinks {
+		if err := sink.Close(); err != nil {
+			slog.Warn("イベントの送信先を閉じられませんでした", "sink", sink.Name(), "err", err)
+		}
+	}
+}
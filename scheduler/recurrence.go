@@ -0,0 +1,185 @@
+// Package scheduler はサーバーと並行して動作するバックグラウンドジョブを提供する。
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/activity"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/outbox"
+	"go-huma-test/recurrence"
+	"log/slog"
+	"time"
+)
+
+// RecurrenceScheduler は繰り返しTodoが完了した際に次回のTodoを生成するスケジューラー。
+// 変更イベントを契機に処理し、取りこぼしに備えて一定間隔で未処理のTodoも走査する。
+type RecurrenceScheduler struct {
+	queries  *db.Queries
+	db       *sql.DB
+	bus      *event.Bus
+	interval time.Duration
+}
+
+// NewRecurrenceScheduler はRecurrenceSchedulerの新しいインスタンスを生成する
+func NewRecurrenceScheduler(queries *db.Queries, db *sql.DB, bus *event.Bus, interval time.Duration) *RecurrenceScheduler {
+	return &RecurrenceScheduler{
+		queries:  queries,
+		db:       db,
+		bus:      bus,
+		interval: interval,
+	}
+}
+
+// Run はctxがキャンセルされるまでスケジューラーを実行する
+func (s *RecurrenceScheduler) Run(ctx context.Context) {
+	events, unsubscribe := s.bus.Subscribe(64)
+	defer unsubscribe()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	slog.Info("繰り返しTodoスケジューラーを開始", "interval", s.interval)
+	s.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("繰り返しTodoスケジューラーを停止")
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if e.Type == event.TodoUpdated {
+				s.sweep(ctx)
+			}
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep は完了済みで次回のTodoが未生成の繰り返しTodoを処理する
+func (s *RecurrenceScheduler) sweep(ctx context.Context) {
+	todos, err := s.queries.ListPendingRecurrences(ctx)
+	if err != nil {
+		slog.Warn("繰り返しTodoの取得に失敗", "err", err)
+		return
+	}
+
+	for _, t := range todos {
+		if err := s.materialize(ctx, t); err != nil {
+			slog.Warn("次回のTodo生成に失敗", "todo_id", t.ID, "err", err)
+		}
+	}
+}
+
+// wipLimitReached はプロジェクトの未完了のTodoがWIP制限に達しているかを返す。プロジェクトがない場合や制限がない場合はfalse
+func wipLimitReached(ctx context.Context, q *db.Queries, projectID sql.NullInt64) (bool, db.Project, error) {
+	if !projectID.Valid {
+		return false, db.Project{}, nil
+	}
+	p, err := q.GetProject(ctx, projectID.Int64)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, db.Project{}, nil
+	}
+	if err != nil {
+		return false, db.Project{}, fmt.Errorf("プロジェクトの取得に失敗: %w", err)
+	}
+	return p.WipLimit.Valid && p.OpenCount >= p.WipLimit.Int64, p, nil
+}
+
+// materialize は完了した繰り返しTodoから次回のTodoを生成する。
+// 次回のTodoのプロジェクトがWIP制限に達している場合は生成せず、空きができた後の走査で生成する
+func (s *RecurrenceScheduler) materialize(ctx context.Context, t db.Todo) error {
+	rule, err := recurrence.Parse(t.Recurrence.String)
+	if err != nil {
+		return fmt.Errorf("繰り返しルールの解析に失敗: %w", err)
+	}
+
+	// 期限がない場合は完了日時を起点とする
+	base := t.UpdatedAt
+	if t.DueAt.Valid {
+		base = t.DueAt.Time
+	}
+	next, rest, ok := rule.Next(base)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("トランザクション開始に失敗: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := s.queries.WithTx(tx)
+
+	if !ok {
+		// 繰り返しが終了した場合はルールを外し、以降の走査対象から除く
+		if err := qtx.EndRecurrence(ctx, t.ID); err != nil {
+			return fmt.Errorf("繰り返し終了の記録に失敗: %w", err)
+		}
+		return tx.Commit()
+	}
+	// 完了で空いた枠を走査までに他のTodoが使った場合も制限を超えないよう、作成と同じトランザクションで確認する
+	reached, p, err := wipLimitReached(ctx, qtx, t.ProjectID)
+	if err != nil {
+		return err
+	}
+	if reached {
+		slog.Info("プロジェクトがWIP制限に達しているため、次回のTodoの生成を見送ります", "todo_id", t.ID, "project_id", p.ID, "open_count", p.OpenCount, "wip_limit", p.WipLimit.Int64)
+		return nil
+	}
+
+	created, err := qtx.CreateTodo(ctx, db.CreateTodoParams{
+		Title:        t.Title,
+		Description:  t.Description,
+		Completed:    0,
+		Latitude:     t.Latitude,
+		Longitude:    t.Longitude,
+		PlaceName:    t.PlaceName,
+		ProjectID:    t.ProjectID,
+		DueAt:        sql.NullTime{Time: next.UTC(), Valid: true},
+		Recurrence:   sql.NullString{String: rest.String(), Valid: true},
+		Assignee:     t.Assignee,
+		OwnerID:      t.OwnerID,
+		Metadata:     t.Metadata,
+		Priority:     t.Priority,
+		Translations: t.Translations,
+	})
+	if err != nil {
+		return fmt.Errorf("次回のTodo作成に失敗: %w", err)
+	}
+	if err := activity.Record(ctx, qtx, activity.ActionCreate, nil, &created); err != nil {
+		return err
+	}
+
+	n, err := qtx.SetNextTodoID(ctx, db.SetNextTodoIDParams{
+		NextTodoID: sql.NullInt64{Int64: created.ID, Valid: true},
+		ID:         t.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("次回のTodoの記録に失敗: %w", err)
+	}
+	if n == 0 {
+		// 他の処理で既に生成済み
+		return nil
+	}
+	e := event.Event{Type: event.TodoCreated, TodoID: created.ID}
+	if err := outbox.Record(ctx, qtx, e); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションのコミットに失敗: %w", err)
+	}
+
+	slog.Info("繰り返しTodoの次回を生成", "todo_id", t.ID, "next_todo_id", created.ID, "due_at", next)
+	s.bus.Publish(e)
+
+	return nil
+}
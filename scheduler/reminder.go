@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"go-huma-test/actionlink"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/notify"
+	"log/slog"
+	"time"
+)
+
+// ReminderScheduler は通知日時を過ぎたリマインダーを一定間隔で通知するスケジューラー。
+// Todoに所有者がいる場合は所有者に宛てて通知する
+type ReminderScheduler struct {
+	queries  *db.Queries
+	notifier notify.Notifier
+	links    *actionlink.Signer
+	interval time.Duration
+	clock    clock.Clock
+	// sent はリマインダーを送信済みにした後に呼び出す。nilの場合は呼び出さない
+	sent func()
+}
+
+// NewReminderScheduler はReminderSchedulerの新しいインスタンスを生成する。
+// linksを指定した場合は、完了や削除を1回だけ行えるリンクを通知に含める。
+// sentは送信済みにしたリマインダーがあった回ごとに呼び出し、応答のキャッシュの破棄などに用いる。
+func NewReminderScheduler(queries *db.Queries, notifier notify.Notifier, links *actionlink.Signer, interval time.Duration, clock clock.Clock, sent func()) *ReminderScheduler {
+	return &ReminderScheduler{
+		queries:  queries,
+		notifier: notifier,
+		links:    links,
+		interval: interval,
+		clock:    clock,
+		sent:     sent,
+	}
+}
+
+// Run はctxがキャンセルされるまでスケジューラーを実行する
+func (s *ReminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	slog.Info("リマインダースケジューラーを開始", "interval", s.interval)
+	s.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("リマインダースケジューラーを停止")
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep は未通知のリマインダーを通知する。
+// 通知に失敗したリマインダーは送信済みにせず、次回の実行で再送する。
+func (s *ReminderScheduler) sweep(ctx context.Context) {
+	reminders, err := s.queries.ListDueReminders(ctx, s.clock.Now().UTC())
+	if err != nil {
+		slog.Warn("通知対象のリマインダーの取得に失敗", "err", err)
+		return
+	}
+
+	marked := false
+	for _, r := range reminders {
+		if err := s.notifier.Notify(ctx, notify.Notification{
+			Kind:       notify.KindReminder,
+			ReminderID: r.ID,
+			TodoID:     r.TodoID,
+			Title:      r.Title,
+			Channel:    r.Channel,
+			RemindAt:   r.RemindAt,
+			Recipient:  r.OwnerEmail.String,
+			Actions:    s.actionLinks(ctx, r),
+		}); err != nil {
+			slog.Warn("リマインダーの通知に失敗", "reminder_id", r.ID, "channel", r.Channel, "err", err)
+			continue
+		}
+		if _, err := s.queries.MarkReminderSent(ctx, r.ID); err != nil {
+			slog.Warn("リマインダーの送信済み記録に失敗", "reminder_id", r.ID, "err", err)
+			continue
+		}
+		marked = true
+	}
+	if marked && s.sent != nil {
+		s.sent()
+	}
+}
+
+// actionLinks はリマインダーのTodoを操作するリンクを返す。
+// リンクを作れない場合も通知は送るため、警告を記録してリンクのない通知にする。
+func (s *ReminderScheduler) actionLinks(ctx context.Context, r db.ListDueRemindersRow) map[string]string {
+	if s.links == nil {
+		return nil
+	}
+	links := make(map[string]string, len(actionlink.QuickActions))
+	for _, action := range actionlink.QuickActions {
+		link, err := s.links.QuickActionLink(ctx, action, r.TodoID, r.OwnerID.Int64, r.Channel)
+		if err != nil {
+			slog.Warn("リマインダーの操作のリンクの作成に失敗", "reminder_id", r.ID, "action", action, "err", err)
+			return nil
+		}
+		links[action] = link
+	}
+	return links
+}
@@ -0,0 +1,284 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/locale"
+	"go-huma-test/migrate"
+	"go-huma-test/notify"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// recorder は受け取った通知を記録するNotifier
+type recorder struct {
+	sent []notify.Notification
+}
+
+func (r *recorder) Notify(_ context.Context, n notify.Notification) error {
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+// openTestDB は移行をすべて適用した一時的なデータベースを開き、ユーザー1（a@example.com）を作成する
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	sqlDB, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "todos.db")+"?_foreign_keys=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	migrations, err := migrate.Load(os.DirFS(".."), "schema/migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrate.New(sqlDB, migrations).Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if _, err := sqlDB.Exec("INSERT INTO users (email, name, password_hash) VALUES ('a@example.com', 'A', '')"); err != nil {
+		t.Fatal(err)
+	}
+	return sqlDB
+}
+
+func TestReminderSweepSendsDueReminders(t *testing.T) {
+	sqlDB := openTestDB(t)
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	if _, err := sqlDB.Exec("INSERT INTO todos (title, owner_id) VALUES ('牛乳を買う', 1)"); err != nil {
+		t.Fatal(err)
+	}
+	for _, at := range []time.Time{now.Add(-time.Minute), now, now.Add(time.Minute)} {
+		if _, err := sqlDB.Exec("INSERT INTO reminders (todo_id, remind_at, channel) VALUES (1, ?, 'log')", at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	n := &recorder{}
+	sent := 0
+	s := NewReminderScheduler(db.New(sqlDB), n, nil, time.Minute, clock.Freeze(now), func() { sent++ })
+	s.sweep(ctx)
+	if len(n.sent) != 2 || n.sent[0].ReminderID != 1 || n.sent[1].ReminderID != 2 {
+		t.Fatalf("notifications = %+v, want reminders 1 and 2", n.sent)
+	}
+	if got := n.sent[0]; got.Kind != notify.KindReminder || got.Title != "牛乳を買う" || got.Recipient != "a@example.com" || !got.RemindAt.Equal(now.Add(-time.Minute)) {
+		t.Errorf("notification = %+v", got)
+	}
+	if sent != 1 {
+		t.Errorf("sent called %d times, want 1", sent)
+	}
+
+	// 送信済みのリマインダーは再送せず、まだ通知日時になっていないものも送らない
+	s.sweep(ctx)
+	if len(n.sent) != 2 || sent != 1 {
+		t.Fatalf("after the second sweep: %d notifications, sent called %d times", len(n.sent), sent)
+	}
+
+	// 時刻が進めば残りのリマインダーを送る
+	NewReminderScheduler(db.New(sqlDB), n, nil, time.Minute, clock.Freeze(now.Add(time.Minute)), nil).sweep(ctx)
+	if len(n.sent) != 3 || n.sent[2].ReminderID != 3 {
+		t.Fatalf("notifications = %+v, want reminder 3 last", n.sent)
+	}
+}
+
+func TestDueDigestSweepSendsOncePerDay(t *testing.T) {
+	sqlDB := openTestDB(t)
+	jst := time.FixedZone("JST", 9*60*60)
+	for _, todo := range []struct {
+		title     string
+		due       time.Time
+		completed int
+	}{
+		{"昨日が期限", time.Date(2026, 3, 9, 18, 0, 0, 0, jst), 0},
+		{"今日が期限", time.Date(2026, 3, 10, 23, 59, 0, 0, jst), 0},
+		{"明日が期限", time.Date(2026, 3, 11, 0, 0, 0, 0, jst), 0},
+		{"完了済み", time.Date(2026, 3, 10, 12, 0, 0, 0, jst), 1},
+	} {
+		if _, err := sqlDB.Exec("INSERT INTO todos (title, due_at, completed, owner_id) VALUES (?, ?, ?, 1)",
+			todo.title, todo.due.UTC(), todo.completed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	n := &recorder{}
+	formatter := locale.New(locale.Japanese, jst)
+	scheduler := func(now time.Time) *DueDigestScheduler {
+		return NewDueDigestScheduler(db.New(sqlDB), n, nil, "log", formatter, 8*time.Hour, clock.Freeze(now))
+	}
+
+	// 送信時刻の前は何もしない
+	if date := scheduler(time.Date(2026, 3, 10, 7, 59, 0, 0, jst)).sweep(ctx); date != "" || len(n.sent) != 0 {
+		t.Fatalf("before 08:00: date %q, %d notifications", date, len(n.sent))
+	}
+
+	// 日付はformatterのタイムゾーンで数える（UTCではまだ3月9日）
+	at := time.Date(2026, 3, 10, 8, 0, 0, 0, jst)
+	if date := scheduler(at.UTC()).sweep(ctx); date != "2026-03-10" {
+		t.Fatalf("date = %q, want 2026-03-10", date)
+	}
+	if len(n.sent) != 1 {
+		t.Fatalf("%d notifications, want 1", len(n.sent))
+	}
+	got := n.sent[0]
+	if got.Kind != notify.KindDueDigest || got.Recipient != "a@example.com" || got.Total != 2 || len(got.Items) != 2 {
+		t.Fatalf("notification = %+v", got)
+	}
+	if got.Items[0].Title != "昨日が期限" || !got.Items[0].Overdue || got.Items[1].Title != "今日が期限" || got.Items[1].Overdue {
+		t.Errorf("items = %+v", got.Items)
+	}
+
+	// 同じ日には再送しない
+	scheduler(at.Add(4 * time.Hour)).sweep(ctx)
+	if len(n.sent) != 1 {
+		t.Fatalf("%d notifications after the second sweep, want 1", len(n.sent))
+	}
+
+	// 翌日は明日が期限だったTodoも含めて送る
+	if date := scheduler(at.AddDate(0, 0, 1)).sweep(ctx); date != "2026-03-11" {
+		t.Fatalf("date = %q, want 2026-03-11", date)
+	}
+	if len(n.sent) != 2 || n.sent[1].Total != 3 {
+		t.Fatalf("notifications = %+v, want a second digest with 3 todos", n.sent)
+	}
+}
+
+func TestRecurrenceSweepCreatesNextTodo(t *testing.T) {
+	sqlDB := openTestDB(t)
+	due := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2026, 3, 12, 15, 30, 0, 0, time.UTC)
+	for _, stmt := range []struct {
+		query string
+		args  []any
+	}{
+		// 期限がある場合は期限から次回を数える
+		{"INSERT INTO todos (title, completed, due_at, recurrence, owner_id, updated_at) VALUES ('週次の報告', 1, ?, 'FREQ=WEEKLY;COUNT=3', 1, ?)", []any{due, completedAt}},
+		// 期限がない場合は完了日時から数える
+		{"INSERT INTO todos (title, completed, recurrence, owner_id, updated_at) VALUES ('水やり', 1, 'FREQ=DAILY;INTERVAL=2', 1, ?)", []any{completedAt}},
+		// 繰り返しが終わる場合は次回を作らない
+		{"INSERT INTO todos (title, completed, due_at, recurrence, owner_id, updated_at) VALUES ('最終回', 1, ?, 'FREQ=DAILY;COUNT=1', 1, ?)", []any{due, completedAt}},
+	} {
+		if _, err := sqlDB.Exec(stmt.query, stmt.args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	queries := db.New(sqlDB)
+	bus := event.NewBus()
+	events, unsubscribe := bus.Subscribe(8)
+	defer unsubscribe()
+	NewRecurrenceScheduler(queries, sqlDB, bus, time.Minute).sweep(ctx)
+
+	tests := []struct {
+		id      int64
+		due     time.Time
+		rule    string
+		hasNext bool
+	}{
+		{1, due.AddDate(0, 0, 7), "FREQ=WEEKLY;COUNT=2", true},
+		{2, completedAt.AddDate(0, 0, 2), "FREQ=DAILY;INTERVAL=2", true},
+		{3, time.Time{}, "", false},
+	}
+	for _, tt := range tests {
+		todo, err := queries.GetTodoByID(ctx, tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tt.hasNext {
+			if todo.NextTodoID.Valid || todo.Recurrence.Valid {
+				t.Errorf("todo %d: next %v, recurrence %v; want the recurrence to end", tt.id, todo.NextTodoID, todo.Recurrence)
+			}
+			continue
+		}
+		if !todo.NextTodoID.Valid {
+			t.Fatalf("todo %d has no next todo", tt.id)
+		}
+		next, err := queries.GetTodoByID(ctx, todo.NextTodoID.Int64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next.Title != todo.Title || next.Completed != 0 || !next.DueAt.Time.Equal(tt.due) || next.Recurrence.String != tt.rule {
+			t.Errorf("next of todo %d = %+v, want due %v and rule %s", tt.id, next, tt.due, tt.rule)
+		}
+	}
+	for range 2 {
+		select {
+		case e := <-events:
+			if e.Type != event.TodoCreated {
+				t.Errorf("event = %+v", e)
+			}
+		default:
+			t.Fatal("missing todo.created event")
+		}
+	}
+
+	// 生成済みのTodoは再び処理しない
+	NewRecurrenceScheduler(queries, sqlDB, bus, time.Minute).sweep(ctx)
+	var count int64
+	if err := sqlDB.QueryRow("SELECT COUNT(*) FROM todos").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 {
+		t.Fatalf("todos = %d, want 5", count)
+	}
+}
+
+func TestRecurrenceSweepRespectsWIPLimit(t *testing.T) {
+	sqlDB := openTestDB(t)
+	for _, stmt := range []string{
+		"INSERT INTO projects (name, wip_limit) VALUES ('週次', 1)",
+		"INSERT INTO todos (title, completed, recurrence, owner_id, project_id) VALUES ('週次の報告', 1, 'FREQ=WEEKLY', 1, 1)",
+		// 完了で空いた枠を走査の前に別のTodoが使った
+		"INSERT INTO todos (title, completed, owner_id, project_id) VALUES ('割り込み', 0, 1, 1)",
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	ctx := context.Background()
+	queries := db.New(sqlDB)
+	scheduler := NewRecurrenceScheduler(queries, sqlDB, event.NewBus(), time.Minute)
+	openCount := func() int64 {
+		t.Helper()
+		p, err := queries.GetProject(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.OpenCount
+	}
+
+	scheduler.sweep(ctx)
+	todo, err := queries.GetTodoByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo.NextTodoID.Valid || openCount() != 1 {
+		t.Fatalf("next todo %v, open count %d; want no next todo while the project is at its WIP limit", todo.NextTodoID, openCount())
+	}
+
+	// 枠が空いた後の走査で生成する
+	if _, err := sqlDB.Exec("UPDATE todos SET completed = 1 WHERE id = 2"); err != nil {
+		t.Fatal(err)
+	}
+	scheduler.sweep(ctx)
+	if todo, err = queries.GetTodoByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !todo.NextTodoID.Valid {
+		t.Fatal("next todo was not created after the WIP limit freed up")
+	}
+	next, err := queries.GetTodoByID(ctx, todo.NextTodoID.Int64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.ProjectID != todo.ProjectID || openCount() != 1 {
+		t.Fatalf("next todo project %v, open count %d; want project 1 with 1 open todo", next.ProjectID, openCount())
+	}
+}
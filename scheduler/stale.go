@@ -0,0 +1,201 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/actionlink"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/locale"
+	"go-huma-test/notify"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// maxStaleDigestItems は1通の通知に含める放置されたTodoの最大件数
+const maxStaleDigestItems = 50
+
+// staleDigestCheckInterval は通知を送る所有者を確認する間隔の上限。
+// 通知の間隔が長くても、再起動した後の送信が大きく遅れないようにする。
+const staleDigestCheckInterval = time.Hour
+
+// StaleDigestScheduler は長く更新されていない未完了のTodoを所有者ごとにまとめ、見直しを促す通知を一定間隔で送るスケジューラー。
+// 通知にはTodoごとにアーカイブと通知の延期を行うリンクを含める。
+// 所有者ごとの前回の送信日時はデータベースに記録し、再起動しても送信の間隔を保つ。
+type StaleDigestScheduler struct {
+	queries    *db.Queries
+	notifier   notify.Notifier
+	links      *actionlink.Signer
+	channel    string
+	formatter  *locale.Formatter
+	staleAfter time.Duration
+	interval   time.Duration
+	clock      clock.Clock
+}
+
+// NewStaleDigestScheduler はStaleDigestSchedulerの新しいインスタンスを生成する。
+// staleAfterの間更新されていないTodoを、所有者ごとにintervalに1回までchannelで通知する。本文の日時はformatterで書式化する。
+func NewStaleDigestScheduler(queries *db.Queries, notifier notify.Notifier, links *actionlink.Signer, channel string, formatter *locale.Formatter, staleAfter, interval time.Duration, clock clock.Clock) *StaleDigestScheduler {
+	return &StaleDigestScheduler{
+		queries:    queries,
+		notifier:   notifier,
+		links:      links,
+		channel:    channel,
+		formatter:  formatter,
+		staleAfter: staleAfter,
+		interval:   interval,
+		clock:      clock,
+	}
+}
+
+// Run はctxがキャンセルされるまでスケジューラーを実行する
+func (s *StaleDigestScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(min(s.interval, staleDigestCheckInterval))
+	defer ticker.Stop()
+
+	slog.Info("放置されたTodoの通知を開始", "stale_after", s.staleAfter, "interval", s.interval)
+	s.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("放置されたTodoの通知を停止")
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep は放置されたTodoを所有者ごとにまとめ、前回の送信から間隔が空いた所有者に通知する
+func (s *StaleDigestScheduler) sweep(ctx context.Context) {
+	now := s.clock.Now().UTC()
+	todos, err := s.queries.ListStaleTodos(ctx, db.ListStaleTodosParams{
+		Before: now.Add(-s.staleAfter),
+		Now:    now,
+	})
+	if err != nil {
+		slog.Warn("放置されたTodoの取得に失敗", "err", err)
+		return
+	}
+
+	projects := make(map[int64]string)
+	// Todoは所有者の順に並んでいる
+	for start := 0; start < len(todos); {
+		end := start + 1
+		for end < len(todos) && todos[end].OwnerID == todos[start].OwnerID {
+			end++
+		}
+		owner := todos[start].OwnerID
+		if err := s.send(ctx, owner, todos[start:end], projects, now); err != nil {
+			slog.Warn("放置されたTodoの通知に失敗", "owner_id", owner.Int64, "channel", s.channel, "err", err)
+		}
+		start = end
+	}
+}
+
+// send は所有者の放置されたTodoを1通にまとめて通知し、送信を記録する。前回の送信から間隔が空いていない場合は送らない。
+func (s *StaleDigestScheduler) send(ctx context.Context, owner sql.NullInt64, todos []db.Todo, projects map[int64]string, now time.Time) error {
+	latest, err := s.queries.GetLatestStaleDigest(ctx, owner)
+	if err == nil && now.Sub(latest.SentAt) < s.interval {
+		return nil
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("前回の送信日時の取得に失敗: %w", err)
+	}
+
+	var recipient string
+	if owner.Valid {
+		user, err := s.queries.GetUser(ctx, owner.Int64)
+		if err != nil {
+			return fmt.Errorf("Todoの所有者の取得に失敗: %w", err)
+		}
+		recipient = user.Email
+	}
+
+	items := make([]notify.DigestItem, 0, min(len(todos), maxStaleDigestItems))
+	for _, t := range todos[:min(len(todos), maxStaleDigestItems)] {
+		item := notify.DigestItem{
+			TodoID:    t.ID,
+			Title:     t.Title,
+			Project:   projectName(ctx, s.queries, t.ProjectID, projects),
+			UpdatedAt: t.UpdatedAt,
+			Actions:   make(map[string]string),
+		}
+		for _, action := range []string{actionlink.ActionArchive, actionlink.ActionSnooze} {
+			link, err := s.links.Link(ctx, action, t.ID, owner.Int64)
+			if err != nil {
+				return fmt.Errorf("リンクの作成に失敗: %w", err)
+			}
+			item.Actions[action] = link
+		}
+		items = append(items, item)
+	}
+
+	f := s.formatter
+	if err := s.notifier.Notify(ctx, notify.Notification{
+		Kind:      notify.KindStaleDigest,
+		Title:     fmt.Sprintf(f.Label("しばらく更新されていないTodoが%d件あります", "%d todos have not been updated for a while"), len(todos)),
+		Message:   s.message(items, len(todos)),
+		Channel:   s.channel,
+		Recipient: recipient,
+		Items:     items,
+		Total:     len(todos),
+	}); err != nil {
+		return err
+	}
+
+	if err := s.queries.CreateStaleDigest(ctx, db.CreateStaleDigestParams{
+		OwnerID:   owner,
+		TodoCount: int64(len(todos)),
+		SentAt:    now,
+	}); err != nil {
+		return fmt.Errorf("送信の記録に失敗: %w", err)
+	}
+	slog.Info("放置されたTodoを通知", "owner_id", owner.Int64, "count", len(todos))
+	return nil
+}
+
+// projectName はプロジェクトの名前を返す。同じプロジェクトを何度も取得しないよう、取得した名前はprojectsに保持する。
+func projectName(ctx context.Context, queries *db.Queries, id sql.NullInt64, projects map[int64]string) string {
+	if !id.Valid {
+		return ""
+	}
+	if name, ok := projects[id.Int64]; ok {
+		return name
+	}
+	p, err := queries.GetProject(ctx, id.Int64)
+	if err != nil {
+		slog.Warn("プロジェクトの取得に失敗", "project_id", id.Int64, "err", err)
+		return ""
+	}
+	projects[id.Int64] = p.Name
+	return p.Name
+}
+
+// message はTodoをプロジェクトごとにまとめ、アーカイブと延期のリンクを添えた本文を返す
+func (s *StaleDigestScheduler) message(items []notify.DigestItem, total int) string {
+	f := s.formatter
+	var b strings.Builder
+	b.WriteString(f.Label("見直して、不要になったTodoはアーカイブしてください。", "Review them and archive the ones you no longer need."))
+	for i, item := range items {
+		if i == 0 || item.Project != items[i-1].Project {
+			project := item.Project
+			if project == "" {
+				project = f.Label("プロジェクトなし", "No project")
+			}
+			fmt.Fprintf(&b, "\n\n[%s]", project)
+		}
+		fmt.Fprintf(&b, "\n- %s (%s)", item.Title,
+			fmt.Sprintf(f.Label("%sに更新", "updated %s"), f.Relative(item.UpdatedAt)))
+		fmt.Fprintf(&b, "\n  %s: %s", f.Label("アーカイブ", "Archive"), item.Actions[actionlink.ActionArchive])
+		fmt.Fprintf(&b, "\n  %s: %s", f.Label("後で通知", "Snooze"), item.Actions[actionlink.ActionSnooze])
+	}
+	if total > len(items) {
+		fmt.Fprintf(&b, "\n\n"+f.Label("ほか%d件", "and %d more"), total-len(items))
+	}
+	return b.String()
+}
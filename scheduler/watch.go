@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/locale"
+	"go-huma-test/notify"
+	"log/slog"
+)
+
+// WatchNotifier はTodoの変更とコメントのイベントを、そのTodoをウォッチしているユーザーに通知する。
+// 作成のイベントはウォッチしているユーザーがいないため、削除のイベントはウォッチも一緒に削除されるため通知しない。
+// 共有を取り消されるなどしてTodoを閲覧できなくなったユーザーには、ウォッチが残っていても通知しない。
+type WatchNotifier struct {
+	queries   *db.Queries
+	bus       *event.Bus
+	notifier  notify.Notifier
+	channel   string
+	formatter *locale.Formatter
+}
+
+// NewWatchNotifier はWatchNotifierの新しいインスタンスを生成する。
+// 通知はchannelで送信し、本文はformatterの言語で書く。
+func NewWatchNotifier(queries *db.Queries, bus *event.Bus, notifier notify.Notifier, channel string, formatter *locale.Formatter) *WatchNotifier {
+	return &WatchNotifier{
+		queries:   queries,
+		bus:       bus,
+		notifier:  notifier,
+		channel:   channel,
+		formatter: formatter,
+	}
+}
+
+// Run はctxがキャンセルされるまでイベントを通知する
+func (w *WatchNotifier) Run(ctx context.Context) {
+	events, unsubscribe := w.bus.Subscribe(256)
+	defer unsubscribe()
+
+	slog.Info("ウォッチしているTodoの通知を開始", "channel", w.channel)
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("ウォッチしているTodoの通知を停止")
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if e.Type != event.TodoCreated && e.Type != event.TodoDeleted {
+				w.notify(ctx, e)
+			}
+		}
+	}
+}
+
+// notify はTodoをウォッチしているユーザーごとにイベントを通知する
+func (w *WatchNotifier) notify(ctx context.Context, e event.Event) {
+	watchers, err := w.queries.ListTodoWatchersToNotify(ctx, e.TodoID)
+	if err != nil {
+		slog.Warn("ウォッチしているユーザーの取得に失敗", "todo_id", e.TodoID, "err", err)
+		return
+	}
+	if len(watchers) == 0 {
+		return
+	}
+
+	t, err := w.queries.GetTodoByID(ctx, e.TodoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// イベントの後に削除された
+		return
+	}
+	if err != nil {
+		slog.Warn("通知するTodoの取得に失敗", "todo_id", e.TodoID, "err", err)
+		return
+	}
+
+	typ := e.Type
+	if e.Completed {
+		typ = event.TodoCompleted
+	}
+	message := w.message(ctx, e)
+	for _, watcher := range watchers {
+		if err := w.notifier.Notify(ctx, notify.Notification{
+			Kind:      notify.KindWatch,
+			TodoID:    t.ID,
+			Title:     t.Title,
+			Message:   message,
+			Channel:   w.channel,
+			Event:     string(typ),
+			Recipient: watcher.Email,
+		}); err != nil {
+			slog.Warn("ウォッチしているTodoの通知に失敗", "todo_id", t.ID, "user_id", watcher.ID, "channel", w.channel, "err", err)
+		}
+	}
+}
+
+// message はイベントの内容を通知の本文にする。コメントの場合は投稿者と本文を含める
+func (w *WatchNotifier) message(ctx context.Context, e event.Event) string {
+	f := w.formatter
+	switch {
+	case e.Type == event.TodoCommented:
+		c, err := w.queries.GetComment(ctx, db.GetCommentParams{ID: e.CommentID, TodoID: e.TodoID})
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				slog.Warn("通知するコメントの取得に失敗", "todo_id", e.TodoID, "comment_id", e.CommentID, "err", err)
+			}
+			return f.Label("コメントが投稿されました", "A comment was posted")
+		}
+		return fmt.Sprintf(f.Label("%sさんがコメントしました:\n%s", "%s commented:\n%s"), c.Author, c.Body)
+	case e.Completed:
+		return f.Label("Todoが完了しました", "The todo was completed")
+	case e.Type == event.TodoMoved:
+		return f.Label("Todoが移動されました", "The todo was moved")
+	default:
+		return f.Label("Todoが更新されました", "The todo was updated")
+	}
+}
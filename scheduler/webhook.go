@@ -0,0 +1,304 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/notify"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Webhookへの配信の状態
+const (
+	// WebhookPending は送信前か、再送を待っている配信
+	WebhookPending = "pending"
+	// WebhookSucceeded は2xxの応答を受け取った配信
+	WebhookSucceeded = "succeeded"
+	// WebhookFailed は送信の回数の上限まで失敗した配信
+	WebhookFailed = "failed"
+)
+
+// Webhookへのリクエストに付与するヘッダー
+const (
+	// WebhookEventHeader はイベントの種類を格納するヘッダー
+	WebhookEventHeader = "X-Todo-Event"
+	// WebhookDeliveryHeader は配信のIDを格納するヘッダー。再送でも同じ値のため、受信側で重複を除くのに使える
+	WebhookDeliveryHeader = "X-Todo-Delivery"
+)
+
+// webhookBatchSize は1回に取得して送信する配信の最大件数
+const webhookBatchSize = 50
+
+// maxWebhookBackoff は再送までの待ち時間の上限
+const maxWebhookBackoff = 6 * time.Hour
+
+// webhookPruneInterval は古い配信の記録を削除する間隔
+const webhookPruneInterval = time.Hour
+
+// maxWebhookErrorSize は配信の記録に残す応答の本文の最大バイト数
+const maxWebhookErrorSize = 512
+
+// WebhookConfig はWebhookの配信の設定を表す構造体
+type WebhookConfig struct {
+	// Interval は再送の時刻を過ぎた配信を確認する間隔
+	Interval time.Duration
+	// MaxAttempts は1件の配信を送信する回数の上限
+	MaxAttempts int
+	// Backoff は最初の再送までの待ち時間。再送のたびに倍にする
+	Backoff time.Duration
+	// Timeout は1回の送信で応答を待つ時間
+	Timeout time.Duration
+	// Retention は送信を終えた配信の記録を残す期間
+	Retention time.Duration
+	// AllowPrivate はループバックやプライベートのアドレスへの送信を許可するか
+	AllowPrivate bool
+}
+
+// webhookPayload はWebhookに送る本文を表す構造体
+type webhookPayload struct {
+	Event      string    `json:"event"`
+	TodoID     int64     `json:"todo_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Todo は変更後のTodo。削除の場合は含めない
+	Todo any `json:"todo,omitempty"`
+}
+
+// WebhookDispatcher はTodoの変更イベントを、Todoの所有者が登録したWebhookへの配信として記録し、非同期に送信する。
+// 配信の記録はOutboxRelayがoutboxのイベントごとにEnqueueを呼んで行う。
+// 送信に失敗した配信は待ち時間を倍にしながら再送し、回数の上限に達したらfailedにする。
+// 配信はデータベースに記録するため、再起動の前に送信できなかった配信も再起動後に送信する。
+type WebhookDispatcher struct {
+	queries *db.Queries
+	render  func(context.Context, db.Todo) any
+	client  *http.Client
+	config  WebhookConfig
+	// clock は保持期間を数える時計。再送の時刻は、再送が進むよう実際の時刻で決める
+	clock clock.Clock
+	// wake は新しい配信を記録したことを送信のループに知らせる
+	wake chan struct{}
+}
+
+// NewWebhookDispatcher はWebhookDispatcherの新しいインスタンスを生成する。
+// renderは本文に含めるTodoを、APIのレスポンスと同じ形式にする。
+func NewWebhookDispatcher(queries *db.Queries, render func(context.Context, db.Todo) any, config WebhookConfig, clock clock.Clock) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		queries: queries,
+		render:  render,
+		client:  notify.NewOutboundClient(config.Timeout, config.AllowPrivate),
+		config:  config,
+		clock:   clock,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Run はctxがキャンセルされるまで、記録した配信を送信する
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	slog.Info("Webhookの配信を開始", "interval", d.config.Interval, "max_attempts", d.config.MaxAttempts)
+	d.deliverLoop(ctx)
+	slog.Info("Webhookの配信を停止")
+}
+
+// Wake は新しい配信を記録したことを送信のループに知らせる。Enqueueのトランザクションをコミットした後に呼ぶ
+func (d *WebhookDispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliverLoop は新しい配信を記録したときと一定間隔ごとに、送信の時刻を過ぎた配信を送信する
+func (d *WebhookDispatcher) deliverLoop(ctx context.Context) {
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+
+	var lastPrune time.Time
+	for {
+		d.deliverDue(ctx)
+		if time.Since(lastPrune) >= webhookPruneInterval {
+			d.prune(ctx)
+			lastPrune = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// Enqueue はイベントを購読しているWebhookごとに配信を記録し、1件以上記録した場合にtrueを返す。
+// qにはoutboxのイベントを送信済みにするのと同じトランザクションのQueriesを渡す
+func (d *WebhookDispatcher) Enqueue(ctx context.Context, q *db.Queries, e event.Event) (bool, error) {
+	types := []event.Type{e.Type}
+	if e.Completed {
+		types = append(types, event.TodoCompleted)
+	}
+
+	owner := e.OwnerID
+	var todo any
+	if e.Type != event.TodoDeleted {
+		t, err := q.GetTodoByID(ctx, e.TodoID)
+		if errors.Is(err, sql.ErrNoRows) {
+			// 削除のイベントで配信する
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("Webhookに送るTodoの取得に失敗: %w", err)
+		}
+		owner = t.OwnerID
+		todo = d.render(ctx, t)
+	}
+
+	hooks, err := q.ListActiveWebhooksForOwner(ctx, owner)
+	if err != nil {
+		return false, fmt.Errorf("Webhookの取得に失敗: %w", err)
+	}
+
+	queued := false
+	for _, h := range hooks {
+		var subscribed []string
+		if err := json.Unmarshal([]byte(h.Events), &subscribed); err != nil {
+			slog.Warn("Webhookの購読イベントを解析できません", "webhook_id", h.ID, "err", err)
+			continue
+		}
+		for _, t := range types {
+			if !slices.Contains(subscribed, string(t)) {
+				continue
+			}
+			payload, err := json.Marshal(webhookPayload{Event: string(t), TodoID: e.TodoID, OccurredAt: e.OccurredAt.UTC(), Todo: todo})
+			if err != nil {
+				slog.Warn("Webhookの本文のエンコードに失敗", "webhook_id", h.ID, "todo_id", e.TodoID, "err", err)
+				continue
+			}
+			if err := q.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+				WebhookID:     h.ID,
+				Event:         string(t),
+				TodoID:        e.TodoID,
+				Payload:       string(payload),
+				NextAttemptAt: time.Now().UTC(),
+			}); err != nil {
+				return false, fmt.Errorf("Webhookの配信の記録に失敗: %w", err)
+			}
+			queued = true
+		}
+	}
+	return queued, nil
+}
+
+// deliverDue は送信の時刻を過ぎた配信を古い順に送信する
+func (d *WebhookDispatcher) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		rows, err := d.queries.ListDueWebhookDeliveries(ctx, db.ListDueWebhookDeliveriesParams{
+			Now:   time.Now().UTC(),
+			Limit: webhookBatchSize,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("送信するWebhookの配信の取得に失敗", "err", err)
+			}
+			return
+		}
+		for _, r := range rows {
+			d.deliver(ctx, r)
+		}
+		if len(rows) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// deliver は配信を1回送信し、結果を記録する
+func (d *WebhookDispatcher) deliver(ctx context.Context, r db.ListDueWebhookDeliveriesRow) {
+	status, err := d.send(ctx, r)
+	if ctx.Err() != nil {
+		// 停止による中断は失敗として数えず、再起動後に送り直す
+		return
+	}
+
+	now := time.Now().UTC()
+	params := db.FinishWebhookDeliveryParams{
+		ID:             r.ID,
+		Status:         WebhookSucceeded,
+		NextAttemptAt:  now,
+		ResponseStatus: sql.NullInt64{Int64: int64(status), Valid: status != 0},
+		DeliveredAt:    sql.NullTime{Time: now, Valid: true},
+	}
+	if err != nil {
+		attempts := r.Attempts + 1
+		params.LastError = sql.NullString{String: err.Error(), Valid: true}
+		params.DeliveredAt = sql.NullTime{}
+		// 送信先のアドレスは再送しても変わらないため、すぐにfailedにする
+		if attempts >= int64(d.config.MaxAttempts) || errors.Is(err, notify.ErrPrivateAddress) {
+			params.Status = WebhookFailed
+			slog.Warn("Webhookの配信が送信の回数の上限に達しました", "delivery_id", r.ID, "webhook_id", r.WebhookID, "attempts", attempts, "err", err)
+		} else {
+			params.Status = WebhookPending
+			params.NextAttemptAt = now.Add(webhookBackoff(d.config.Backoff, attempts))
+			slog.Info("Webhookの配信に失敗したため再送します", "delivery_id", r.ID, "webhook_id", r.WebhookID, "attempts", attempts, "next_attempt_at", params.NextAttemptAt, "err", err)
+		}
+	}
+	if err := d.queries.FinishWebhookDelivery(ctx, params); err != nil {
+		slog.Warn("Webhookの配信の結果の記録に失敗", "delivery_id", r.ID, "err", err)
+	}
+}
+
+// webhookBackoff はattempts回失敗した後の再送までの待ち時間を返す
+func webhookBackoff(base time.Duration, attempts int64) time.Duration {
+	wait := base
+	for i := int64(1); i < attempts && wait < maxWebhookBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxWebhookBackoff)
+}
+
+// send は配信の本文を署名してWebhookへPOSTし、応答のステータスコードを返す。2xx以外の応答はエラーとして扱う。
+func (d *WebhookDispatcher) send(ctx context.Context, r db.ListDueWebhookDeliveriesRow) (int, error) {
+	body := []byte(r.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("Webhookリクエストの作成に失敗: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, r.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(r.ID, 10))
+	req.Header.Set(notify.SignatureHeader, notify.Sign(r.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("Webhookの送信に失敗: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorSize))
+		return resp.StatusCode, fmt.Errorf("Webhookがエラーを返しました: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookErrorSize))
+	return resp.StatusCode, nil
+}
+
+// prune は保持期間を過ぎた、送信を終えた配信の記録を削除する
+func (d *WebhookDispatcher) prune(ctx context.Context) {
+	n, err := d.queries.PruneWebhookDeliveries(ctx, d.clock.Now().UTC().Add(-d.config.Retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("古いWebhookの配信の記録の削除に失敗", "err", err)
+		}
+		return
+	}
+	if n > 0 {
+		slog.Info("古いWebhookの配信の記録を削除", "deleted", n)
+	}
+}
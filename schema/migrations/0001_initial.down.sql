@@ -0,0 +1,23 @@
+-- 初期スキーマのすべてのテーブルを削除する。インデックスとトリガーはテーブルと一緒に削除される
+-- 外部キーで参照されるテーブルを後に削除するよう、作成と逆の順に削除する
+DROP TABLE IF EXISTS todo_description_ops;
+DROP TABLE IF EXISTS health_probe;
+DROP TABLE IF EXISTS project_chat_channels;
+DROP TABLE IF EXISTS usage_daily;
+DROP TABLE IF EXISTS import_jobs;
+DROP TABLE IF EXISTS confirmation_tokens;
+DROP TABLE IF EXISTS todo_shares;
+DROP TABLE IF EXISTS read_markers;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS activity_log;
+DROP TABLE IF EXISTS attachments;
+DROP TABLE IF EXISTS auth_events;
+DROP TABLE IF EXISTS comments;
+DROP TABLE IF EXISTS reminders;
+DROP TABLE IF EXISTS subtasks;
+DROP TABLE IF EXISTS saved_filters;
+DROP TABLE IF EXISTS todos;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,372 @@
+-- 初期スキーマ
+-- 移行の仕組みを導入する前に作成したデータベースにも適用できるよう、IF NOT EXISTSで作成する。
+-- そのようなデータベースの既存のテーブルには後から追加した列がないため、適用の前に列の追加とトリガーの削除を行う（legacy.go）
+
+-- Usersテーブル
+-- パスワードはPBKDF2でハッシュ化した値のみを保存する。OIDCで登録したユーザーはパスワードを持たず、空文字になる
+-- roleはviewer（読み取りのみ）、editor（Todoの変更）、admin（APIキーやユーザーの管理）のいずれか
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    name TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'editor' CHECK (role IN ('viewer', 'editor', 'admin')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 外部のOIDCプロバイダーのアカウントとユーザーの対応
+-- issuerとsubjectの組でプロバイダーのアカウントを一意に識別する
+CREATE TABLE IF NOT EXISTS user_identities (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (issuer, subject)
+);
+
+-- Projectsテーブル
+CREATE TABLE IF NOT EXISTS projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT,
+    archived_at DATETIME,
+    open_count INTEGER NOT NULL DEFAULT 0,
+    completed_count INTEGER NOT NULL DEFAULT 0,
+    metadata_schema TEXT CHECK (metadata_schema IS NULL OR json_valid(metadata_schema)), -- 所属するTodoのmetadataを検証するJSON Schema
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Todosテーブル
+CREATE TABLE IF NOT EXISTS todos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    description TEXT,
+    completed INTEGER NOT NULL DEFAULT 0,
+    latitude REAL CHECK (latitude BETWEEN -90 AND 90),
+    longitude REAL CHECK (longitude BETWEEN -180 AND 180),
+    place_name TEXT,
+    subtask_count INTEGER NOT NULL DEFAULT 0,
+    subtask_completed_count INTEGER NOT NULL DEFAULT 0,
+    project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+    due_at DATETIME,
+    recurrence TEXT,
+    next_todo_id INTEGER REFERENCES todos(id) ON DELETE SET NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    version INTEGER NOT NULL DEFAULT 1,
+    assignee TEXT,
+    owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    metadata TEXT CHECK (metadata IS NULL OR json_valid(metadata)), -- クライアントが自由に使うJSONオブジェクト
+    priority INTEGER NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 3), -- 0: なし, 1: 低, 2: 中, 3: 高
+    escalation_level INTEGER NOT NULL DEFAULT 0, -- 期限切れにより優先度を引き上げた段階
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- updated_atを自動更新するトリガー
+CREATE TRIGGER IF NOT EXISTS update_todos_updated_at
+    AFTER UPDATE ON todos
+    FOR EACH ROW
+BEGIN
+    UPDATE todos SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+CREATE INDEX IF NOT EXISTS idx_todos_project_id ON todos(project_id);
+CREATE INDEX IF NOT EXISTS idx_todos_assignee ON todos(assignee);
+CREATE INDEX IF NOT EXISTS idx_todos_owner_id ON todos(owner_id);
+
+-- updated_atを自動更新するトリガー（件数の更新では変更しない）
+CREATE TRIGGER IF NOT EXISTS update_projects_updated_at
+    AFTER UPDATE OF name, description, archived_at ON projects
+    FOR EACH ROW
+BEGIN
+    UPDATE projects SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+-- Todoの件数をプロジェクトに反映するトリガー
+CREATE TRIGGER IF NOT EXISTS todos_project_count_insert
+    AFTER INSERT ON todos
+    FOR EACH ROW
+    WHEN NEW.project_id IS NOT NULL
+BEGIN
+    UPDATE projects
+    SET open_count = open_count + 1 - NEW.completed,
+        completed_count = completed_count + NEW.completed
+    WHERE id = NEW.project_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS todos_project_count_update
+    AFTER UPDATE OF completed, project_id ON todos
+    FOR EACH ROW
+    WHEN OLD.completed IS NOT NEW.completed OR OLD.project_id IS NOT NEW.project_id
+BEGIN
+    UPDATE projects
+    SET open_count = open_count - 1 + OLD.completed,
+        completed_count = completed_count - OLD.completed
+    WHERE id = OLD.project_id;
+    UPDATE projects
+    SET open_count = open_count + 1 - NEW.completed,
+        completed_count = completed_count + NEW.completed
+    WHERE id = NEW.project_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS todos_project_count_delete
+    AFTER DELETE ON todos
+    FOR EACH ROW
+    WHEN OLD.project_id IS NOT NULL
+BEGIN
+    UPDATE projects
+    SET open_count = open_count - 1 + OLD.completed,
+        completed_count = completed_count - OLD.completed
+    WHERE id = OLD.project_id;
+END;
+
+-- 保存済みフィルター（スター付き検索）テーブル
+CREATE TABLE IF NOT EXISTS saved_filters (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    completed INTEGER NOT NULL DEFAULT 0,
+    near TEXT,
+    radius REAL NOT NULL DEFAULT 1000,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- サブタスク（チェックリスト項目）テーブル
+CREATE TABLE IF NOT EXISTS subtasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    completed INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_subtasks_todo_id ON subtasks(todo_id);
+
+-- サブタスクの件数をTodoに反映するトリガー
+CREATE TRIGGER IF NOT EXISTS subtasks_count_insert
+    AFTER INSERT ON subtasks
+    FOR EACH ROW
+BEGIN
+    UPDATE todos
+    SET subtask_count = subtask_count + 1,
+        subtask_completed_count = subtask_completed_count + NEW.completed,
+        version = version + 1
+    WHERE id = NEW.todo_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS subtasks_count_update
+    AFTER UPDATE OF completed ON subtasks
+    FOR EACH ROW
+BEGIN
+    UPDATE todos
+    SET subtask_completed_count = subtask_completed_count - OLD.completed + NEW.completed,
+        version = version + 1
+    WHERE id = NEW.todo_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS subtasks_count_delete
+    AFTER DELETE ON subtasks
+    FOR EACH ROW
+BEGIN
+    UPDATE todos
+    SET subtask_count = subtask_count - 1,
+        subtask_completed_count = subtask_completed_count - OLD.completed,
+        version = version + 1
+    WHERE id = OLD.todo_id;
+END;
+
+-- updated_atを自動更新するトリガー
+CREATE TRIGGER IF NOT EXISTS update_subtasks_updated_at
+    AFTER UPDATE ON subtasks
+    FOR EACH ROW
+BEGIN
+    UPDATE subtasks SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+-- リマインダーテーブル
+-- channelがdiscordとteamsの場合は、Todoが属するプロジェクトに設定されたチャットへ通知する
+CREATE TABLE IF NOT EXISTS reminders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    remind_at DATETIME NOT NULL,
+    channel TEXT NOT NULL CHECK (channel IN ('log', 'webhook', 'email', 'discord', 'teams')),
+    sent_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_todo_id ON reminders(todo_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_pending ON reminders(remind_at) WHERE sent_at IS NULL;
+
+-- コメントテーブル
+CREATE TABLE IF NOT EXISTS comments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    author TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_todo_id ON comments(todo_id, id);
+
+-- 認証イベント（監査ログ）テーブル
+CREATE TABLE IF NOT EXISTS auth_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subject TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    ip TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_events_subject ON auth_events(subject, id);
+
+-- 添付ファイルのメタデータテーブル（ファイル本体はBlobストアに保存する）
+CREATE TABLE IF NOT EXISTS attachments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_todo_id ON attachments(todo_id);
+
+-- Todoの変更履歴（アクティビティログ）テーブル
+-- Todoの削除後も履歴を残すため、todo_idには外部キー制約を設けない
+CREATE TABLE IF NOT EXISTS activity_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    diff TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_log_todo_id ON activity_log(todo_id, id);
+
+-- Idempotency-Keyと、そのキーで処理したリクエストの応答を保存するテーブル
+-- statusが0の行は処理中を表す
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    subject TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    headers TEXT NOT NULL DEFAULT '{}',
+    body BLOB,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (subject, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- 自動化クライアント向けのAPIキーテーブル
+-- キーそのものは保存せず、SHA-256ハッシュと表示用の先頭部分のみを保存する
+CREATE TABLE IF NOT EXISTS api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    created_by TEXT NOT NULL,
+    last_used_at DATETIME,
+    revoked_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 認証主体ごとに、サマリーの未読判定に使う既読位置を保存するテーブル
+CREATE TABLE IF NOT EXISTS read_markers (
+    subject TEXT PRIMARY KEY,
+    last_activity_id INTEGER NOT NULL DEFAULT 0,
+    last_comment_id INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Todoの共有テーブル
+-- todo_idを指定した行はそのTodoを、project_idを指定した行はshared_byがそのプロジェクトに所有するTodoをuser_idと共有する
+-- permissionはread（閲覧のみ）かwrite（変更も可能）
+CREATE TABLE IF NOT EXISTS todo_shares (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER REFERENCES todos(id) ON DELETE CASCADE,
+    project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    shared_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL CHECK (permission IN ('read', 'write')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((todo_id IS NULL) <> (project_id IS NULL)),
+    UNIQUE (todo_id, user_id),
+    UNIQUE (project_id, user_id, shared_by)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_shares_user_id ON todo_shares(user_id);
+
+-- 取り消せない管理操作を2段階で実行するための確認トークン
+-- トークンそのものは保存せず、SHA-256ハッシュのみを保存する。impactは発行時に提示した影響範囲のJSON
+CREATE TABLE IF NOT EXISTS confirmation_tokens (
+    token_hash TEXT PRIMARY KEY,
+    operation TEXT NOT NULL,
+    target TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    impact TEXT NOT NULL CHECK (json_valid(impact)),
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 外部のタスク管理サービスからのインポートを非同期に実行するジョブ
+-- resultはインポートしたプロジェクトの一覧のJSON。サーバーの停止で中断したジョブは起動時にfailedにする
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'failed')),
+    dry_run INTEGER NOT NULL DEFAULT 0,
+    actor TEXT NOT NULL,
+    result TEXT CHECK (result IS NULL OR json_valid(result)),
+    error TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at DATETIME
+);
+
+-- ユーザー（テナント）ごとの日次の利用量。課金や部門への費用配賦の基礎とする
+-- dayはUTCの日付（YYYY-MM-DD）。api_callsはその日の累計、storage_bytesとactive_todosはその日の最後の集計時点の値
+CREATE TABLE IF NOT EXISTS usage_daily (
+    day TEXT NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    api_calls INTEGER NOT NULL DEFAULT 0,
+    storage_bytes INTEGER NOT NULL DEFAULT 0,
+    active_todos INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (day, user_id)
+);
+
+-- プロジェクト（リスト）ごとにTodoの変更やリマインダーを投稿するチャットの送信先
+-- webhook_urlはDiscordのWebhookまたはTeamsの受信Webhook（ワークフロー）のURL。eventsは投稿するイベントの種類のJSON配列
+CREATE TABLE IF NOT EXISTS project_chat_channels (
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL CHECK (provider IN ('discord', 'teams')),
+    webhook_url TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(events)),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, provider)
+);
+
+-- 準備状態の確認（/readyz）でデータベースに書き込めることを確かめるための1行だけの表
+CREATE TABLE IF NOT EXISTS health_probe (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    checked_at DATETIME NOT NULL
+);
+
+-- Todoの説明の共同編集で適用した操作の履歴。revisionはTodoごとの連番
+-- 並行に編集したクライアントの操作を、基にした版より後の操作に対して変換するために使う。descriptionは適用後の説明
+CREATE TABLE IF NOT EXISTS todo_description_ops (
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    ops TEXT NOT NULL CHECK (json_valid(ops)),
+    description TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (todo_id, revision)
+);
@@ -0,0 +1,8 @@
+-- 操作（operationId）ごとの呼び出し回数と最終使用日時
+-- 廃止を検討する操作が実際に使われているかを確認するため、利用者を区別せずに累計する
+CREATE TABLE operation_usage (
+    operation_id TEXT PRIMARY KEY,
+    calls INTEGER NOT NULL DEFAULT 0,
+    last_used_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
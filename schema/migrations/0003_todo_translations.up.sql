@@ -0,0 +1,3 @@
+-- Todoのタイトルと説明の言語ごとの訳
+-- 言語タグをキー、{"title", "description"}を値とするJSONオブジェクトで、訳がない場合はNULL
+ALTER TABLE todos ADD COLUMN translations TEXT;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stale_digests;
+DROP TABLE IF EXISTS todo_snoozes;
+ALTER TABLE todos DROP COLUMN archived_at;
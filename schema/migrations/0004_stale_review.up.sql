@@ -0,0 +1,19 @@
+-- アーカイブした日時。アーカイブしたTodoは一覧に含めず、放置されたTodoの通知にも含めない
+ALTER TABLE todos ADD COLUMN archived_at DATETIME;
+
+-- 放置されたTodoの通知から外す期限。期限を過ぎても更新されていなければ再び通知に含める
+CREATE TABLE todo_snoozes (
+    todo_id INTEGER PRIMARY KEY REFERENCES todos(id) ON DELETE CASCADE,
+    snoozed_until DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 放置されたTodoの通知の送信履歴。所有者ごとに前回の送信から一定の間隔を空ける
+CREATE TABLE stale_digests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE, -- 所有者のいないTodoの通知はNULL
+    todo_count INTEGER NOT NULL,
+    sent_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_stale_digests_owner_id ON stale_digests(owner_id, sent_at);
@@ -0,0 +1,13 @@
+-- 通知のワンクリック操作のリンクの使用記録。リンクは1回だけ使えるため、トークンのIDを主キーにする。
+-- 削除したTodoの記録も残すため、todo_idは外部キーにしない
+CREATE TABLE action_link_uses (
+    token_id TEXT PRIMARY KEY,
+    action TEXT NOT NULL,
+    todo_id INTEGER NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL, -- 所有者のいないTodoのリンクはNULL
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    used_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_action_link_uses_todo_id ON action_link_uses(todo_id, used_at);
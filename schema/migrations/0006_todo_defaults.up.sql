@@ -0,0 +1,13 @@
+-- Todoの作成時にクライアントが省略した項目に使う既定値。project_idがNULLの行はすべてのTodoに使う全体の既定値で、
+-- プロジェクトの既定値で指定していない項目は全体の既定値を使う
+CREATE TABLE todo_defaults (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+    priority INTEGER CHECK (priority IS NULL OR priority BETWEEN 0 AND 3),
+    due_offset_seconds INTEGER CHECK (due_offset_seconds IS NULL OR due_offset_seconds > 0), -- 作成日時から期限までの秒数
+    metadata TEXT CHECK (metadata IS NULL OR json_valid(metadata)),
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 全体の既定値とプロジェクトごとの既定値をそれぞれ1行にする
+CREATE UNIQUE INDEX idx_todo_defaults_project_id ON todo_defaults(COALESCE(project_id, 0));
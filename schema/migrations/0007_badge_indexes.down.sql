@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_todos_open_assignee;
+DROP INDEX IF EXISTS idx_todos_open_due_at;
@@ -0,0 +1,3 @@
+-- バッジの件数は数秒ごとにポーリングされるため、未完了でアーカイブしていないTodoだけを所有者ごとに索引する
+CREATE INDEX idx_todos_open_due_at ON todos(owner_id, due_at) WHERE completed = 0 AND archived_at IS NULL;
+CREATE INDEX idx_todos_open_assignee ON todos(owner_id, assignee) WHERE completed = 0 AND archived_at IS NULL;
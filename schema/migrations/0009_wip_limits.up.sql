@@ -0,0 +1,2 @@
+-- プロジェクトの未完了のTodoの件数の上限（カンバンのWIP制限）。NULLは制限なし
+ALTER TABLE projects ADD COLUMN wip_limit INTEGER CHECK (wip_limit IS NULL OR wip_limit > 0);
@@ -0,0 +1,15 @@
+-- Todoの変更と同じトランザクションで記録するイベント。中継がWebhookの配信とブローカーへの送信を終えるとpublished_atを設定する
+CREATE TABLE outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,
+    todo_id INTEGER NOT NULL,
+    owner_id INTEGER, -- 削除のイベントのみ。削除されたTodoは後から所有者を取得できない
+    completed INTEGER NOT NULL DEFAULT 0,
+    occurred_at DATETIME NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0, -- 送信に失敗した回数
+    last_error TEXT,
+    published_at DATETIME
+);
+
+CREATE INDEX idx_outbox_pending ON outbox(id) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_published_at ON outbox(published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE outbox DROP COLUMN comment_id;
+DROP TABLE IF EXISTS todo_watchers;
@@ -0,0 +1,12 @@
+-- Todoをウォッチしているユーザー。ウォッチしたTodoの変更とコメントを通知する
+CREATE TABLE todo_watchers (
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (todo_id, user_id)
+);
+
+CREATE INDEX idx_todo_watchers_user_id ON todo_watchers(user_id);
+
+-- コメントのイベントで投稿されたコメント
+ALTER TABLE outbox ADD COLUMN comment_id INTEGER;
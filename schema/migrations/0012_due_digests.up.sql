@@ -0,0 +1,8 @@
+-- 期限が近いTodoのまとめのメールの送信履歴。ユーザーごとに1日1通だけ送る
+CREATE TABLE due_digests (
+    owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    digest_date TEXT NOT NULL, -- 送信したタイムゾーンでの日付（YYYY-MM-DD）
+    todo_count INTEGER NOT NULL,
+    sent_at DATETIME NOT NULL,
+    PRIMARY KEY (owner_id, digest_date)
+);
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS chat_overdue_posts;
+DELETE FROM project_chat_channels WHERE provider = 'slack';
+CREATE TABLE project_chat_channels_old (
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL CHECK (provider IN ('discord', 'teams')),
+    webhook_url TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(events)),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, provider)
+);
+INSERT INTO project_chat_channels_old (project_id, provider, webhook_url, events, created_at, updated_at)
+SELECT project_id, provider, webhook_url, events, created_at, updated_at FROM project_chat_channels;
+DROP TABLE project_chat_channels;
+ALTER TABLE project_chat_channels_old RENAME TO project_chat_channels;
@@ -0,0 +1,24 @@
+-- チャットの送信先にSlackを加え、イベントごとのメッセージのテンプレートを持たせる。
+-- providerのCHECK制約を変えるため、表を作り直す
+CREATE TABLE project_chat_channels_new (
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL CHECK (provider IN ('discord', 'slack', 'teams')),
+    webhook_url TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(events)),
+    -- イベントの種類ごとのメッセージのテンプレート（Goのtext/template）のJSONオブジェクト
+    templates TEXT NOT NULL DEFAULT '{}' CHECK (json_valid(templates)),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, provider)
+);
+INSERT INTO project_chat_channels_new (project_id, provider, webhook_url, events, created_at, updated_at)
+SELECT project_id, provider, webhook_url, events, created_at, updated_at FROM project_chat_channels;
+DROP TABLE project_chat_channels;
+ALTER TABLE project_chat_channels_new RENAME TO project_chat_channels;
+
+-- 期限切れをチャットに投稿したTodo。期限ごとに1回だけ投稿し、期限が変わった場合は改めて投稿する
+CREATE TABLE chat_overdue_posts (
+    todo_id INTEGER PRIMARY KEY REFERENCES todos(id) ON DELETE CASCADE,
+    due_at DATETIME NOT NULL,
+    posted_at DATETIME NOT NULL
+);
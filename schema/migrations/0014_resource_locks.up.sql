@@ -0,0 +1,9 @@
+-- 長い編集の間、他のユーザーによる変更を防ぐリソースのロック。期限を過ぎたロックは無いものとして扱い、次に取得したユーザーが上書きする
+CREATE TABLE resource_locks (
+    resource_type TEXT NOT NULL,
+    resource_id INTEGER NOT NULL,
+    holder_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    acquired_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (resource_type, resource_id)
+);
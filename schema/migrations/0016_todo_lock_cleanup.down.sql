@@ -0,0 +1 @@
+DROP TRIGGER IF EXISTS todos_delete_locks;
@@ -0,0 +1,11 @@
+-- Todoを削除したときにロックも削除する。resource_locksは種類の異なるリソースを持つため外部キーを張れない
+CREATE TRIGGER IF NOT EXISTS todos_delete_locks
+    AFTER DELETE ON todos
+    FOR EACH ROW
+BEGIN
+    DELETE FROM resource_locks WHERE resource_type = 'todo' AND resource_id = OLD.id;
+END;
+
+-- トリガーを追加する前に削除されたTodoのロック
+DELETE FROM resource_locks
+WHERE resource_type = 'todo' AND resource_id NOT IN (SELECT id FROM todos);
@@ -0,0 +1,19 @@
+-- Todoのタグ。タグはTodoの所有者ごとに名前で区別する
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 所有者のいないTodo（認証を使わない場合）のタグもNULLどうしで重複しないようにする
+CREATE UNIQUE INDEX idx_tags_owner_name ON tags(COALESCE(owner_id, 0), name);
+
+-- Todoに付けたタグ
+CREATE TABLE todo_tags (
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX idx_todo_tags_tag_id ON todo_tags(tag_id);
@@ -0,0 +1 @@
+ALTER TABLE todo_defaults DROP COLUMN tags;
@@ -0,0 +1,2 @@
+-- tagsを省略してTodoを作成した場合に付けるタグの名前のJSON配列。NULLは既定値なし
+ALTER TABLE todo_defaults ADD COLUMN tags TEXT CHECK (tags IS NULL OR json_valid(tags));
@@ -0,0 +1,2 @@
+-- PostgreSQLのスキーマのテーブルを削除する。インデックスはテーブルと一緒に削除される
+DROP TABLE IF EXISTS todos;
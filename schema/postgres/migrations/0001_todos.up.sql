@@ -0,0 +1,18 @@
+-- PostgreSQLのスキーマ
+-- PostgreSQLではリポジトリで扱うTodoの基本的な項目のみを保存する。ユーザーはトークンの主体で識別するため、usersテーブルは持たない
+
+-- Todosテーブル
+-- owner_idは認証したユーザーのID。認証を使わない場合はNULLになる
+CREATE TABLE IF NOT EXISTS todos (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id BIGINT,
+    title TEXT NOT NULL,
+    description TEXT,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    priority BIGINT NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 3), -- 0: なし, 1: 低, 2: 中, 3: 高
+    due_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_todos_owner_id ON todos(owner_id, completed, created_at DESC);
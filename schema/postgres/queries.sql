@@ -0,0 +1,40 @@
+-- PostgreSQLのクエリ
+-- 所有者はowner_idがNULLの場合も一致させるため、IS NOT DISTINCT FROMで比較する
+
+-- name: ListTodos :many
+SELECT id, owner_id, title, description, completed, priority, due_at, created_at, updated_at
+FROM todos
+WHERE owner_id IS NOT DISTINCT FROM sqlc.narg(owner_id)
+  AND completed = sqlc.arg(completed)
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetTodo :one
+SELECT id, owner_id, title, description, completed, priority, due_at, created_at, updated_at
+FROM todos
+WHERE id = sqlc.arg(id)
+  AND owner_id IS NOT DISTINCT FROM sqlc.narg(owner_id);
+
+-- name: CreateTodo :one
+INSERT INTO todos (owner_id, title, description, priority, due_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, owner_id, title, description, completed, priority, due_at, created_at, updated_at;
+
+-- name: UpdateTodo :one
+UPDATE todos
+SET title = sqlc.arg(title), description = sqlc.narg(description), completed = sqlc.arg(completed),
+    priority = sqlc.arg(priority), due_at = sqlc.narg(due_at), updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND owner_id IS NOT DISTINCT FROM sqlc.narg(owner_id)
+RETURNING id, owner_id, title, description, completed, priority, due_at, created_at, updated_at;
+
+-- name: ToggleTodo :one
+UPDATE todos
+SET completed = NOT completed, updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND owner_id IS NOT DISTINCT FROM sqlc.narg(owner_id)
+RETURNING id, owner_id, title, description, completed, priority, due_at, created_at, updated_at;
+
+-- name: DeleteTodo :execrows
+DELETE FROM todos
+WHERE id = sqlc.arg(id)
+  AND owner_id IS NOT DISTINCT FROM sqlc.narg(owner_id);
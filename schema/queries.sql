@@ -1,35 +1,1150 @@
 -- name: GetTodo :one
-SELECT id, title, description, completed, created_at, updated_at
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
 FROM todos
-WHERE id = ? LIMIT 1;
+WHERE id = sqlc.arg(id)
+  AND (owner_id IS sqlc.arg(user_id) OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = sqlc.arg(user_id)
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+          AND (CAST(sqlc.arg(for_write) AS INTEGER) = 0 OR s.permission = 'write')
+    ))
+LIMIT 1;
 
 -- name: ListTodos :many
-SELECT id, title, description, completed, created_at, updated_at
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
 FROM todos
+WHERE (owner_id IS sqlc.arg(user_id) OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = sqlc.arg(user_id)
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+  AND (CAST(sqlc.arg(include_archived_lists) AS INTEGER) = 1 OR project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND (CAST(sqlc.arg(include_archived_lists) AS INTEGER) = 1 OR archived_at IS NULL)
+  AND (sqlc.narg(metadata_path) IS NULL OR CAST(json_extract(metadata, sqlc.narg(metadata_path)) AS TEXT) = sqlc.narg(metadata_value))
 ORDER BY created_at DESC;
 
+-- name: ListTodosForExport :many
+-- エクスポートするTodoをIDの順にlimit件ずつ取得する。after_idに前回の最後のIDを渡して続きを取得する
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE id > sqlc.arg(after_id)
+  AND (owner_id IS sqlc.arg(user_id) OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = sqlc.arg(user_id)
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+  AND (sqlc.narg(completed) IS NULL OR completed = sqlc.narg(completed))
+  AND (sqlc.narg(project_id) IS NULL OR project_id = sqlc.narg(project_id))
+  AND (CAST(sqlc.arg(include_archived_lists) AS INTEGER) = 1 OR project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND (CAST(sqlc.arg(include_archived_lists) AS INTEGER) = 1 OR archived_at IS NULL)
+  AND (sqlc.narg(metadata_path) IS NULL OR CAST(json_extract(metadata, sqlc.narg(metadata_path)) AS TEXT) = sqlc.narg(metadata_value))
+ORDER BY id
+LIMIT sqlc.arg(limit);
+
 -- name: ListTodosByStatus :many
-SELECT id, title, description, completed, created_at, updated_at
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
 FROM todos
-WHERE completed = ?
+WHERE completed = sqlc.arg(completed)
+  AND (owner_id IS sqlc.arg(user_id) OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = sqlc.arg(user_id)
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+  AND (CAST(sqlc.arg(include_archived_lists) AS INTEGER) = 1 OR project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND (CAST(sqlc.arg(include_archived_lists) AS INTEGER) = 1 OR archived_at IS NULL)
+  AND (sqlc.narg(metadata_path) IS NULL OR CAST(json_extract(metadata, sqlc.narg(metadata_path)) AS TEXT) = sqlc.narg(metadata_value))
 ORDER BY created_at DESC;
 
+-- name: ListTodosNear :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE latitude IS NOT NULL AND longitude IS NOT NULL
+  AND (owner_id IS sqlc.arg(user_id) OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = sqlc.arg(user_id)
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+  AND (CAST(sqlc.narg(completed) AS INTEGER) IS NULL OR completed = sqlc.narg(completed))
+  AND haversine(latitude, longitude, CAST(sqlc.arg(lat) AS REAL), CAST(sqlc.arg(lng) AS REAL)) <= CAST(sqlc.arg(radius) AS REAL)
+  AND (CAST(sqlc.arg(include_archived_lists) AS INTEGER) = 1 OR project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND (CAST(sqlc.arg(include_archived_lists) AS INTEGER) = 1 OR archived_at IS NULL)
+  AND (sqlc.narg(metadata_path) IS NULL OR CAST(json_extract(metadata, sqlc.narg(metadata_path)) AS TEXT) = sqlc.narg(metadata_value))
+ORDER BY haversine(latitude, longitude, CAST(sqlc.arg(lat) AS REAL), CAST(sqlc.arg(lng) AS REAL)) ASC;
+
 -- name: CreateTodo :one
-INSERT INTO todos (title, description, completed)
-VALUES (?, ?, ?)
-RETURNING id, title, description, completed, created_at, updated_at;
+INSERT INTO todos (title, description, completed, latitude, longitude, place_name, project_id, due_at, recurrence, assignee, owner_id, metadata, priority, translations, position)
+VALUES (
+    sqlc.arg(title), sqlc.arg(description), sqlc.arg(completed), sqlc.arg(latitude), sqlc.arg(longitude),
+    sqlc.arg(place_name), sqlc.arg(project_id), sqlc.arg(due_at), sqlc.arg(recurrence), sqlc.arg(assignee), sqlc.arg(owner_id), sqlc.arg(metadata), sqlc.arg(priority), sqlc.arg(translations),
+    (SELECT COALESCE(MAX(position), 0) + 1 FROM todos WHERE project_id IS sqlc.arg(project_id))
+)
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at;
 
 -- name: UpdateTodo :one
 UPDATE todos
-SET title = ?, description = ?, completed = ?, updated_at = CURRENT_TIMESTAMP
+SET title = ?, description = ?, completed = ?, latitude = ?, longitude = ?, place_name = ?, project_id = ?, due_at = ?, recurrence = ?, assignee = ?, metadata = ?, priority = ?, escalation_level = ?, translations = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, title, description, completed, created_at, updated_at;
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at;
 
 -- name: DeleteTodo :exec
 DELETE FROM todos WHERE id = ?;
 
 -- name: ToggleTodoCompleted :one
 UPDATE todos
-SET completed = CASE WHEN completed = 0 THEN 1 ELSE 0 END, updated_at = CURRENT_TIMESTAMP
+SET completed = CASE WHEN completed = 0 THEN 1 ELSE 0 END, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at;
+
+-- name: GetSavedFilter :one
+SELECT id, name, completed, near, radius, created_at
+FROM saved_filters
+WHERE id = ? LIMIT 1;
+
+-- name: ListSavedFilters :many
+SELECT id, name, completed, near, radius, created_at
+FROM saved_filters
+ORDER BY id;
+
+-- name: CreateSavedFilter :one
+INSERT INTO saved_filters (name, completed, near, radius)
+VALUES (?, ?, ?, ?)
+RETURNING id, name, completed, near, radius, created_at;
+
+-- name: DeleteSavedFilter :execrows
+DELETE FROM saved_filters WHERE id = ?;
+
+-- name: ListSubtasks :many
+SELECT id, todo_id, title, completed, created_at, updated_at
+FROM subtasks
+WHERE todo_id = ?
+ORDER BY id;
+
+-- name: GetSubtask :one
+SELECT id, todo_id, title, completed, created_at, updated_at
+FROM subtasks
+WHERE id = ? AND todo_id = ? LIMIT 1;
+
+-- name: CreateSubtask :one
+INSERT INTO subtasks (todo_id, title, completed)
+VALUES (?, ?, ?)
+RETURNING id, todo_id, title, completed, created_at, updated_at;
+
+-- name: UpdateSubtask :one
+UPDATE subtasks
+SET title = ?, completed = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND todo_id = ?
+RETURNING id, todo_id, title, completed, created_at, updated_at;
+
+-- name: DeleteSubtask :execrows
+DELETE FROM subtasks WHERE id = ? AND todo_id = ?;
+
+-- name: ListOwnedTodoIDs :many
+-- DeleteTodosByIDsで削除されるTodoのID
+SELECT id FROM todos
+WHERE owner_id IS ? AND id IN (sqlc.slice(ids))
+ORDER BY id;
+
+-- name: DeleteTodosByIDs :many
+DELETE FROM todos
+WHERE owner_id IS ? AND id IN (sqlc.slice(ids))
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at;
+
+-- name: ListCompletedTodoIDs :many
+-- DeleteCompletedTodosで削除されるTodoのID
+SELECT id FROM todos
+WHERE completed = 1 AND owner_id IS ?
+ORDER BY id;
+
+-- name: DeleteCompletedTodos :many
+DELETE FROM todos
+WHERE completed = 1 AND owner_id IS ?
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at;
+
+-- name: GetProject :one
+SELECT id, name, description, archived_at, open_count, completed_count, metadata_schema, created_at, updated_at, wip_limit
+FROM projects
+WHERE id = ? LIMIT 1;
+
+-- name: ListProjects :many
+SELECT id, name, description, archived_at, open_count, completed_count, metadata_schema, created_at, updated_at, wip_limit
+FROM projects
+WHERE archived_at IS NULL OR CAST(sqlc.arg(include_archived) AS INTEGER) = 1
+ORDER BY id;
+
+-- name: CreateProject :one
+INSERT INTO projects (name, description, metadata_schema, wip_limit)
+VALUES (?, ?, ?, ?)
+RETURNING id, name, description, archived_at, open_count, completed_count, metadata_schema, created_at, updated_at, wip_limit;
+
+-- name: UpdateProject :one
+UPDATE projects
+SET name = ?, description = ?, metadata_schema = ?, wip_limit = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, name, description, archived_at, open_count, completed_count, metadata_schema, created_at, updated_at, wip_limit;
+
+-- name: ArchiveProject :execrows
+UPDATE projects
+SET archived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND archived_at IS NULL;
+
+-- name: UnarchiveProject :execrows
+UPDATE projects
+SET archived_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND archived_at IS NOT NULL;
+
+-- name: DeleteProject :execrows
+DELETE FROM projects WHERE id = ?;
+
+-- name: ListTodosByProject :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE project_id = ?
+ORDER BY position, created_at DESC, id DESC;
+
+-- name: ListVisibleTodosByProject :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE project_id = sqlc.arg(project_id)
+  AND (owner_id IS sqlc.arg(user_id) OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = sqlc.arg(user_id)
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+ORDER BY position, created_at DESC, id DESC;
+
+-- name: ListPendingRecurrences :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE completed = 1 AND recurrence IS NOT NULL AND next_todo_id IS NULL
+ORDER BY id;
+
+-- name: SetNextTodoID :execrows
+UPDATE todos
+SET next_todo_id = ?, version = version + 1
+WHERE id = ? AND next_todo_id IS NULL;
+
+-- name: EndRecurrence :exec
+UPDATE todos
+SET recurrence = NULL, version = version + 1
+WHERE id = ?;
+
+-- name: ListSubtasksByProject :many
+SELECT subtasks.id, subtasks.todo_id, subtasks.title, subtasks.completed, subtasks.created_at, subtasks.updated_at
+FROM subtasks
+JOIN todos ON todos.id = subtasks.todo_id
+WHERE todos.project_id = ?
+ORDER BY subtasks.id;
+
+-- name: ListReminders :many
+SELECT id, todo_id, remind_at, channel, sent_at, created_at
+FROM reminders
+WHERE todo_id = ?
+ORDER BY remind_at, id;
+
+-- name: CreateReminder :one
+INSERT INTO reminders (todo_id, remind_at, channel)
+VALUES (?, ?, ?)
+RETURNING id, todo_id, remind_at, channel, sent_at, created_at;
+
+-- name: DeleteReminder :execrows
+DELETE FROM reminders WHERE id = ? AND todo_id = ?;
+
+-- name: ListDueReminders :many
+SELECT reminders.id, reminders.todo_id, reminders.remind_at, reminders.channel, todos.title, todos.owner_id, users.email AS owner_email
+FROM reminders
+JOIN todos ON todos.id = reminders.todo_id
+LEFT JOIN users ON users.id = todos.owner_id
+WHERE reminders.sent_at IS NULL AND reminders.remind_at <= sqlc.arg(now)
+ORDER BY reminders.remind_at, reminders.id;
+
+-- name: MarkReminderSent :execrows
+UPDATE reminders SET sent_at = CURRENT_TIMESTAMP
+WHERE id = ? AND sent_at IS NULL;
+
+-- name: ListOverdueTodos :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE completed = 0 AND archived_at IS NULL AND due_at IS NOT NULL AND due_at < sqlc.arg(now) AND escalation_level < sqlc.arg(max_level)
+ORDER BY due_at, id;
+
+-- name: EscalateTodo :one
+UPDATE todos
+SET priority = sqlc.arg(priority), escalation_level = sqlc.arg(escalation_level), version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND completed = 0 AND escalation_level < sqlc.arg(escalation_level)
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at;
+
+-- name: ListComments :many
+SELECT id, todo_id, author, body, created_at
+FROM comments
+WHERE todo_id = sqlc.arg(todo_id) AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(limit);
+
+-- name: CreateComment :one
+INSERT INTO comments (todo_id, author, body, created_at)
+VALUES (sqlc.arg(todo_id), sqlc.arg(author), sqlc.arg(body), COALESCE(sqlc.narg(created_at), CURRENT_TIMESTAMP))
+RETURNING id, todo_id, author, body, created_at;
+
+-- name: DeleteComment :execrows
+DELETE FROM comments WHERE id = ? AND todo_id = ?;
+
+-- name: ListCommentsByProject :many
+SELECT comments.id, comments.todo_id, comments.author, comments.body, comments.created_at
+FROM comments
+JOIN todos ON todos.id = comments.todo_id
+WHERE todos.project_id = ?
+ORDER BY comments.id;
+
+-- name: CreateAuthEvent :one
+INSERT INTO auth_events (subject, event_type, ip, user_agent, created_at)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, subject, event_type, ip, user_agent, created_at;
+
+-- name: HasRecentAuthEvent :one
+SELECT EXISTS (
+    SELECT 1 FROM auth_events
+    WHERE subject = sqlc.arg(subject)
+      AND event_type = sqlc.arg(event_type)
+      AND ip = sqlc.arg(ip)
+      AND user_agent = sqlc.arg(user_agent)
+      AND created_at >= sqlc.arg(since)
+);
+
+-- name: GetAuthLocationStats :one
+SELECT COUNT(*) AS total, COUNT(CASE WHEN ip = sqlc.arg(ip) THEN 1 END) AS from_ip
+FROM auth_events
+WHERE subject = sqlc.arg(subject) AND event_type IN (sqlc.slice(event_types));
+
+-- name: ListAuthEvents :many
+SELECT id, subject, event_type, ip, user_agent, created_at
+FROM auth_events
+WHERE subject = ?
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: GetAttachment :one
+SELECT id, todo_id, filename, content_type, size, storage_key, created_at
+FROM attachments
+WHERE id = ? LIMIT 1;
+
+-- name: ListAttachments :many
+SELECT id, todo_id, filename, content_type, size, storage_key, created_at
+FROM attachments
+WHERE todo_id = ?
+ORDER BY id;
+
+-- name: CreateAttachment :one
+INSERT INTO attachments (todo_id, filename, content_type, size, storage_key)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, todo_id, filename, content_type, size, storage_key, created_at;
+
+-- name: CreateActivity :exec
+INSERT INTO activity_log (todo_id, actor, action, diff)
+VALUES (?, ?, ?, ?);
+
+-- name: ListTodoActivity :many
+SELECT id, todo_id, actor, action, diff, created_at
+FROM activity_log
+WHERE todo_id = sqlc.arg(todo_id) AND (sqlc.arg(before_id) = 0 OR id < sqlc.arg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: ListActivity :many
+SELECT id, todo_id, actor, action, diff, created_at
+FROM activity_log
+WHERE sqlc.arg(before_id) = 0 OR id < sqlc.arg(before_id)
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: GetIdempotencyKey :one
+SELECT subject, idempotency_key, request_hash, status, headers, body, created_at, expires_at
+FROM idempotency_keys
+WHERE subject = ? AND idempotency_key = ? AND expires_at > ?
+LIMIT 1;
+
+-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (subject, idempotency_key, request_hash, expires_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (subject, idempotency_key) DO NOTHING;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status = ?, headers = ?, body = ?
+WHERE subject = ? AND idempotency_key = ?;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE subject = ? AND idempotency_key = ?;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= ?;
+
+-- name: ListTodoIDsInProject :many
+SELECT id
+FROM todos
+WHERE project_id IS ?
+ORDER BY position, created_at DESC, id DESC;
+
+-- name: SetTodoPosition :execrows
+UPDATE todos
+SET position = sqlc.arg(position), version = version + 1
+WHERE id = sqlc.arg(id) AND position != sqlc.arg(position);
+
+-- name: MoveTodo :one
+UPDATE todos
+SET project_id = ?, position = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at;
+
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, prefix, key_hash, created_by)
+VALUES (?, ?, ?, ?)
+RETURNING id, name, prefix, key_hash, created_by, last_used_at, revoked_at, created_at;
+
+-- name: GetAPIKey :one
+SELECT id, name, prefix, key_hash, created_by, last_used_at, revoked_at, created_at
+FROM api_keys
+WHERE id = ?
+LIMIT 1;
+
+-- name: GetActiveAPIKeyByHash :one
+SELECT id, name, prefix, key_hash, created_by, last_used_at, revoked_at, created_at
+FROM api_keys
+WHERE key_hash = ? AND revoked_at IS NULL
+LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT id, name, prefix, key_hash, created_by, last_used_at, revoked_at, created_at
+FROM api_keys
+WHERE revoked_at IS NULL OR CAST(sqlc.arg(include_revoked) AS INTEGER) = 1
+ORDER BY id;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND revoked_at IS NULL;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = sqlc.arg(used_at)
+WHERE id = sqlc.arg(id) AND (last_used_at IS NULL OR last_used_at < sqlc.arg(stale_before));
+
+-- name: GetInboxSummary :one
+SELECT
+    (SELECT COUNT(*) FROM todos
+     WHERE completed = 0 AND due_at < sqlc.arg(now) AND owner_id IS sqlc.arg(owner_id) AND archived_at IS NULL
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS overdue_count,
+    (SELECT COUNT(*) FROM todos
+     WHERE completed = 0 AND due_at >= sqlc.arg(now) AND due_at < sqlc.arg(day_end) AND owner_id IS sqlc.arg(owner_id) AND archived_at IS NULL
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS due_today_count,
+    (SELECT COUNT(*) FROM todos
+     WHERE completed = 0 AND assignee = CAST(sqlc.arg(subject) AS TEXT) AND owner_id IS sqlc.arg(owner_id) AND archived_at IS NULL
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS assigned_count,
+    (SELECT COUNT(*) FROM comments
+     WHERE id > COALESCE((SELECT last_comment_id FROM read_markers WHERE subject = sqlc.arg(subject)), 0)
+       AND author != sqlc.arg(subject)
+       AND instr(body, '@' || sqlc.arg(subject)) > 0) AS mention_count,
+    (SELECT COUNT(*) FROM activity_log
+     WHERE id > COALESCE((SELECT last_activity_id FROM read_markers WHERE subject = sqlc.arg(subject)), 0)
+       AND actor != sqlc.arg(subject)) AS unread_activity_count;
+
+-- name: GetBadgeCounts :one
+SELECT
+    (SELECT COUNT(*) FROM todos
+     WHERE owner_id IS sqlc.arg(owner_id) AND completed = 0 AND archived_at IS NULL AND due_at < sqlc.arg(now)
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS overdue_count,
+    (SELECT COUNT(*) FROM todos
+     WHERE owner_id IS sqlc.arg(owner_id) AND completed = 0 AND archived_at IS NULL AND due_at >= sqlc.arg(now) AND due_at < sqlc.arg(day_end)
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS due_today_count,
+    (SELECT COUNT(*) FROM todos
+     WHERE owner_id IS sqlc.arg(owner_id) AND completed = 0 AND archived_at IS NULL AND assignee = CAST(sqlc.arg(subject) AS TEXT)
+       AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))) AS assigned_count,
+    (SELECT COUNT(*) FROM comments
+     WHERE id > COALESCE((SELECT last_comment_id FROM read_markers WHERE subject = sqlc.arg(subject)), 0)
+       AND author != sqlc.arg(subject)
+       AND todo_id IN (SELECT id FROM todos WHERE owner_id IS sqlc.arg(owner_id))) AS unread_comment_count;
+
+-- name: MarkInboxRead :exec
+INSERT INTO read_markers (subject, last_activity_id, last_comment_id)
+VALUES (
+    sqlc.arg(subject),
+    (SELECT COALESCE(MAX(id), 0) FROM activity_log),
+    (SELECT COALESCE(MAX(id), 0) FROM comments)
+)
+ON CONFLICT (subject) DO UPDATE
+SET last_activity_id = excluded.last_activity_id,
+    last_comment_id = excluded.last_comment_id,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: CreateUser :one
+INSERT INTO users (email, name, password_hash, role)
+VALUES (?, ?, ?, ?)
+RETURNING id, email, name, password_hash, role, created_at;
+
+-- name: GetUser :one
+SELECT id, email, name, password_hash, role, created_at
+FROM users
+WHERE id = ?
+LIMIT 1;
+
+-- name: GetUserByEmail :one
+SELECT id, email, name, password_hash, role, created_at
+FROM users
+WHERE email = ?
+LIMIT 1;
+
+-- name: ListSubtasksByTodoIDs :many
+SELECT id, todo_id, title, completed, created_at, updated_at
+FROM subtasks
+WHERE todo_id IN (sqlc.slice(todo_ids))
+ORDER BY todo_id, id;
+
+-- name: ListCommentsByTodoIDs :many
+SELECT id, todo_id, author, body, created_at
+FROM comments
+WHERE todo_id IN (sqlc.slice(todo_ids))
+ORDER BY todo_id, id;
+
+-- name: CountCommentsByTodoIDs :many
+SELECT todo_id, COUNT(*) AS count
+FROM comments
+WHERE todo_id IN (sqlc.slice(todo_ids))
+GROUP BY todo_id;
+
+-- name: ListRemindersByTodoIDs :many
+SELECT id, todo_id, remind_at, channel, sent_at, created_at
+FROM reminders
+WHERE todo_id IN (sqlc.slice(todo_ids))
+ORDER BY todo_id, remind_at, id;
+
+-- name: ListAttachmentsByTodoIDs :many
+SELECT id, todo_id, filename, content_type, size, storage_key, created_at
+FROM attachments
+WHERE todo_id IN (sqlc.slice(todo_ids))
+ORDER BY todo_id, id;
+
+-- name: ListProjectsByIDs :many
+SELECT id, name, description, archived_at, open_count, completed_count, metadata_schema, created_at, updated_at, wip_limit
+FROM projects
+WHERE id IN (sqlc.slice(ids))
+ORDER BY id;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: ListUsers :many
+SELECT id, email, name, password_hash, role, created_at
+FROM users
+ORDER BY id;
+
+-- name: UpdateUserRole :one
+UPDATE users SET role = ?
+WHERE id = ?
+RETURNING id, email, name, password_hash, role, created_at;
+
+-- name: CountUsersByRole :one
+SELECT COUNT(*) FROM users WHERE role = ?;
+
+-- name: ShareTodo :one
+INSERT INTO todo_shares (todo_id, user_id, shared_by, permission)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (todo_id, user_id) DO UPDATE SET permission = excluded.permission
+RETURNING id, todo_id, project_id, user_id, shared_by, permission, created_at;
+
+-- name: ShareProject :one
+INSERT INTO todo_shares (project_id, user_id, shared_by, permission)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (project_id, user_id, shared_by) DO UPDATE SET permission = excluded.permission
+RETURNING id, todo_id, project_id, user_id, shared_by, permission, created_at;
+
+-- name: ListTodoShares :many
+SELECT todo_shares.id, todo_shares.user_id, users.email, todo_shares.permission, todo_shares.created_at
+FROM todo_shares
+JOIN users ON users.id = todo_shares.user_id
+WHERE todo_shares.todo_id = ?
+ORDER BY todo_shares.id;
+
+-- name: ListProjectShares :many
+SELECT todo_shares.id, todo_shares.user_id, users.email, todo_shares.permission, todo_shares.created_at
+FROM todo_shares
+JOIN users ON users.id = todo_shares.user_id
+WHERE todo_shares.project_id = ? AND todo_shares.shared_by = ?
+ORDER BY todo_shares.id;
+
+-- name: DeleteTodoShare :execrows
+DELETE FROM todo_shares WHERE id = ? AND todo_id = ?;
+
+-- name: DeleteProjectShare :execrows
+DELETE FROM todo_shares WHERE id = ? AND project_id = ? AND shared_by = ?;
+
+-- name: GetUserByIdentity :one
+SELECT users.id, users.email, users.name, users.password_hash, users.role, users.created_at
+FROM users
+JOIN user_identities ON user_identities.user_id = users.id
+WHERE user_identities.issuer = ? AND user_identities.subject = ?
+LIMIT 1;
+
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (user_id, issuer, subject)
+VALUES (?, ?, ?);
+
+-- name: CreateConfirmationToken :exec
+INSERT INTO confirmation_tokens (token_hash, operation, target, actor, impact, expires_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ConsumeConfirmationToken :one
+DELETE FROM confirmation_tokens
+WHERE token_hash = sqlc.arg(token_hash) AND expires_at > sqlc.arg(now)
+RETURNING token_hash, operation, target, actor, impact, expires_at, created_at;
+
+-- name: DeleteExpiredConfirmationTokens :execrows
+DELETE FROM confirmation_tokens
+WHERE expires_at <= ?;
+
+-- name: ListTodosByOwner :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE owner_id = ?
+ORDER BY id;
+
+-- name: CountActiveAPIKeysByCreator :one
+SELECT COUNT(*) FROM api_keys WHERE created_by = ? AND revoked_at IS NULL;
+
+-- name: RevokeAPIKeysByCreator :execrows
+UPDATE api_keys
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE created_by = ? AND revoked_at IS NULL;
+
+-- name: DeleteUser :execrows
+DELETE FROM users WHERE id = ?;
+
+-- name: GetLatestActivity :one
+SELECT id, todo_id, actor, action, diff, created_at
+FROM activity_log
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetNextActivity :one
+SELECT id, todo_id, actor, action, diff, created_at
+FROM activity_log
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT 1;
+
+-- name: CreateImportJob :one
+INSERT INTO import_jobs (source, dry_run, actor)
+VALUES (?, ?, ?)
+RETURNING id, source, status, dry_run, actor, result, error, created_at, finished_at;
+
+-- name: FinishImportJob :one
+UPDATE import_jobs
+SET status = ?, result = ?, error = ?, finished_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, source, status, dry_run, actor, result, error, created_at, finished_at;
+
+-- name: GetImportJob :one
+SELECT id, source, status, dry_run, actor, result, error, created_at, finished_at
+FROM import_jobs
+WHERE id = ?;
+
+-- name: ListImportJobs :many
+SELECT id, source, status, dry_run, actor, result, error, created_at, finished_at
+FROM import_jobs
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: FailInterruptedImportJobs :execrows
+UPDATE import_jobs
+SET status = 'failed', error = ?, finished_at = CURRENT_TIMESTAMP
+WHERE status = 'running';
+
+-- name: AddUsageAPICalls :exec
+-- 削除されたユーザーの呼び出し回数は記録しない
+INSERT INTO usage_daily (day, user_id, api_calls)
+SELECT sqlc.arg(day), id, sqlc.arg(api_calls)
+FROM users
+WHERE id = sqlc.arg(user_id)
+ON CONFLICT (day, user_id) DO UPDATE
+SET api_calls = usage_daily.api_calls + excluded.api_calls, updated_at = CURRENT_TIMESTAMP;
+
+-- name: SnapshotUsage :execrows
+-- すべてのユーザーの添付ファイルの合計サイズと未完了のTodoの件数を、指定した日の利用量として記録する
+INSERT INTO usage_daily (day, user_id, storage_bytes, active_todos)
+SELECT sqlc.arg(day), u.id,
+       (SELECT COALESCE(SUM(a.size), 0) FROM attachments a JOIN todos t ON t.id = a.todo_id WHERE t.owner_id = u.id),
+       (SELECT COUNT(*) FROM todos t WHERE t.owner_id = u.id AND t.completed = 0)
+FROM users u
+WHERE true
+ON CONFLICT (day, user_id) DO UPDATE
+SET storage_bytes = excluded.storage_bytes, active_todos = excluded.active_todos, updated_at = CURRENT_TIMESTAMP;
+
+-- name: ListUsage :many
+SELECT d.day, d.user_id, u.email, d.api_calls, d.storage_bytes, d.active_todos
+FROM usage_daily d
+JOIN users u ON u.id = d.user_id
+WHERE d.day >= sqlc.arg(from_day) AND d.day <= sqlc.arg(to_day)
+  AND (sqlc.narg(user_id) IS NULL OR d.user_id = sqlc.narg(user_id))
+ORDER BY d.day, d.user_id;
+
+-- name: UpsertProjectChatChannel :one
+INSERT INTO project_chat_channels (project_id, provider, webhook_url, events, templates)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (project_id, provider) DO UPDATE
+SET webhook_url = excluded.webhook_url, events = excluded.events, templates = excluded.templates, updated_at = CURRENT_TIMESTAMP
+RETURNING project_id, provider, webhook_url, events, templates, created_at, updated_at;
+
+-- name: ListProjectChatChannels :many
+SELECT project_id, provider, webhook_url, events, templates, created_at, updated_at
+FROM project_chat_channels
+WHERE project_id = ?
+ORDER BY provider;
+
+-- name: GetProjectChatChannel :one
+SELECT project_id, provider, webhook_url, events, templates, created_at, updated_at
+FROM project_chat_channels
+WHERE project_id = ? AND provider = ?;
+
+-- name: DeleteProjectChatChannel :execrows
+DELETE FROM project_chat_channels
+WHERE project_id = ? AND provider = ?;
+
+-- name: GetChatChannelForTodo :one
+-- Todoが属するプロジェクトに設定されたチャットの送信先を取得する
+SELECT c.project_id, c.provider, c.webhook_url, c.events, c.templates, c.created_at, c.updated_at
+FROM project_chat_channels c
+JOIN todos t ON t.project_id = c.project_id
+WHERE t.id = sqlc.arg(todo_id) AND c.provider = sqlc.arg(provider);
+
+-- name: ListTodoChatDeliveries :many
+-- Todoが属するプロジェクトに設定されたチャットの送信先とTodoの内容を取得する
+SELECT c.provider, c.webhook_url, c.events, c.templates, t.title, t.description, t.assignee, t.due_at, p.name AS project_name
+FROM project_chat_channels c
+JOIN todos t ON t.project_id = c.project_id
+JOIN projects p ON p.id = c.project_id
+WHERE t.id = ?
+ORDER BY c.provider;
+
+-- name: ListChatOverdueDeliveries :many
+-- sinceからnowまでに期限を過ぎた未完了のTodoのうち、その期限の期限切れをまだ投稿していないものと、
+-- 期限切れを購読しているチャットの送信先を取得する
+SELECT t.id AS todo_id, t.title, t.description, t.assignee, t.due_at, p.name AS project_name,
+       c.provider, c.webhook_url, c.templates
+FROM todos t
+JOIN project_chat_channels c ON c.project_id = t.project_id
+JOIN projects p ON p.id = t.project_id
+LEFT JOIN chat_overdue_posts o ON o.todo_id = t.id AND o.due_at = t.due_at
+WHERE t.completed = 0 AND t.archived_at IS NULL AND t.due_at IS NOT NULL
+  AND t.due_at > sqlc.arg(since) AND t.due_at <= sqlc.arg(now)
+  AND o.todo_id IS NULL
+  AND EXISTS (SELECT 1 FROM json_each(c.events) WHERE json_each.value = 'todo.overdue')
+ORDER BY t.due_at, t.id, c.provider;
+
+-- name: RecordChatOverduePost :exec
+INSERT INTO chat_overdue_posts (todo_id, due_at, posted_at)
+VALUES (?, ?, ?)
+ON CONFLICT (todo_id) DO UPDATE SET due_at = excluded.due_at, posted_at = excluded.posted_at;
+
+-- name: TouchHealthProbe :exec
+INSERT INTO health_probe (id, checked_at)
+VALUES (1, CURRENT_TIMESTAMP)
+ON CONFLICT (id) DO UPDATE SET checked_at = excluded.checked_at;
+
+-- name: GetLatestDescriptionOp :one
+SELECT todo_id, revision, ops, description, actor, created_at
+FROM todo_description_ops
+WHERE todo_id = ?
+ORDER BY revision DESC
+LIMIT 1;
+
+-- name: ListDescriptionOps :many
+SELECT todo_id, revision, ops, description, actor, created_at
+FROM todo_description_ops
+WHERE todo_id = ? AND revision > ?
+ORDER BY revision;
+
+-- name: GetOldestDescriptionRevision :one
+SELECT CAST(COALESCE(MIN(revision), 0) AS INTEGER) AS revision
+FROM todo_description_ops
+WHERE todo_id = ?;
+
+-- name: CreateDescriptionOp :exec
+INSERT INTO todo_description_ops (todo_id, revision, ops, description, actor)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: PruneDescriptionOps :exec
+DELETE FROM todo_description_ops
+WHERE todo_id = ? AND revision <= ?;
+
+-- name: UpdateTodoDescription :one
+UPDATE todos
+SET description = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, title, description, completed, created_at, updated_at;
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at;
+
+-- name: AddOperationUsage :exec
+INSERT INTO operation_usage (operation_id, calls, last_used_at)
+VALUES (sqlc.arg(operation_id), sqlc.arg(calls), sqlc.arg(last_used_at))
+ON CONFLICT (operation_id) DO UPDATE
+SET calls = operation_usage.calls + excluded.calls,
+    last_used_at = MAX(operation_usage.last_used_at, excluded.last_used_at),
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: ListOperationUsage :many
+SELECT operation_id, calls, last_used_at, updated_at
+FROM operation_usage
+ORDER BY operation_id;
+
+-- name: ListStaleTodos :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE completed = 0 AND archived_at IS NULL AND updated_at < sqlc.arg(before)
+  AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND NOT EXISTS (SELECT 1 FROM todo_snoozes z WHERE z.todo_id = todos.id AND z.snoozed_until > sqlc.arg(now))
+ORDER BY owner_id, project_id, updated_at, id;
+
+-- name: ArchiveTodo :one
+UPDATE todos
+SET archived_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND archived_at IS NULL
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at;
+
+-- name: UnarchiveTodo :one
+UPDATE todos
+SET archived_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND archived_at IS NOT NULL
+RETURNING id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at;
+
+-- name: SnoozeTodo :exec
+INSERT INTO todo_snoozes (todo_id, snoozed_until)
+VALUES (sqlc.arg(todo_id), sqlc.arg(snoozed_until))
+ON CONFLICT (todo_id) DO UPDATE
+SET snoozed_until = excluded.snoozed_until, created_at = CURRENT_TIMESTAMP;
+
+-- name: GetLatestStaleDigest :one
+SELECT id, owner_id, todo_count, sent_at
+FROM stale_digests
+WHERE owner_id IS sqlc.arg(owner_id)
+ORDER BY sent_at DESC
+LIMIT 1;
+
+-- name: CreateStaleDigest :exec
+INSERT INTO stale_digests (owner_id, todo_count, sent_at)
+VALUES (?, ?, ?);
+
+-- name: UseActionLink :execrows
+INSERT INTO action_link_uses (token_id, action, todo_id, user_id, ip, user_agent)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (token_id) DO NOTHING;
+
+-- name: GetTodoDefaults :one
+SELECT id, project_id, priority, due_offset_seconds, metadata, updated_at, tags
+FROM todo_defaults
+WHERE project_id IS sqlc.arg(project_id);
+
+-- name: ListTodoDefaultsForProject :many
+SELECT id, project_id, priority, due_offset_seconds, metadata, updated_at, tags
+FROM todo_defaults
+WHERE project_id IS NULL OR project_id IS sqlc.arg(project_id);
+
+-- name: UpsertTodoDefaults :one
+INSERT INTO todo_defaults (project_id, priority, due_offset_seconds, metadata, tags)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (COALESCE(project_id, 0)) DO UPDATE
+SET priority = excluded.priority, due_offset_seconds = excluded.due_offset_seconds, metadata = excluded.metadata, tags = excluded.tags, updated_at = CURRENT_TIMESTAMP
+RETURNING id, project_id, priority, due_offset_seconds, metadata, updated_at, tags;
+
+-- name: DeleteTodoDefaults :execrows
+DELETE FROM todo_defaults
+WHERE project_id IS sqlc.arg(project_id);
+
+-- name: CreateWebhook :one
+INSERT INTO webhooks (owner_id, url, events, secret)
+VALUES (?, ?, ?, ?)
+RETURNING id, owner_id, url, events, secret, active, created_at, updated_at;
+
+-- name: GetWebhook :one
+SELECT id, owner_id, url, events, secret, active, created_at, updated_at
+FROM webhooks
+WHERE id = sqlc.arg(id) AND owner_id IS sqlc.arg(owner_id);
+
+-- name: ListWebhooks :many
+SELECT id, owner_id, url, events, secret, active, created_at, updated_at
+FROM webhooks
+WHERE owner_id IS sqlc.arg(owner_id)
+ORDER BY id;
+
+-- name: UpdateWebhook :one
+UPDATE webhooks
+SET url = sqlc.arg(url), events = sqlc.arg(events), active = sqlc.arg(active), updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND owner_id IS sqlc.arg(owner_id)
+RETURNING id, owner_id, url, events, secret, active, created_at, updated_at;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = sqlc.arg(id) AND owner_id IS sqlc.arg(owner_id);
+
+-- name: ListActiveWebhooksForOwner :many
+SELECT id, owner_id, url, events, secret, active, created_at, updated_at
+FROM webhooks
+WHERE owner_id IS sqlc.arg(owner_id) AND active = 1
+ORDER BY id;
+
+-- name: GetTodoByID :one
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE id = ?;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (webhook_id, event, todo_id, payload, next_attempt_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: ListDueWebhookDeliveries :many
+SELECT d.id, d.webhook_id, d.event, d.todo_id, d.payload, d.attempts, w.url, w.secret
+FROM webhook_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+WHERE d.status = 'pending' AND d.next_attempt_at <= sqlc.arg(now)
+ORDER BY d.next_attempt_at
+LIMIT sqlc.arg(limit);
+
+-- name: FinishWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = sqlc.arg(status), attempts = attempts + 1, next_attempt_at = sqlc.arg(next_attempt_at),
+    response_status = sqlc.arg(response_status), last_error = sqlc.arg(last_error), delivered_at = sqlc.arg(delivered_at)
+WHERE id = sqlc.arg(id);
+
+-- name: ListWebhookDeliveries :many
+SELECT id, webhook_id, event, todo_id, payload, status, attempts, next_attempt_at, response_status, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE webhook_id = sqlc.arg(webhook_id) AND (CAST(sqlc.arg(before_id) AS INTEGER) = 0 OR id < sqlc.arg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: PruneWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE status != 'pending' AND created_at < ?;
+
+-- name: ListAgendaTodos :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE owner_id IS sqlc.arg(owner_id) AND archived_at IS NULL AND due_at >= sqlc.arg(day_start) AND due_at < sqlc.arg(day_end)
+  AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+ORDER BY due_at, id;
+
+-- name: ListAgendaReminders :many
+SELECT reminders.id, reminders.todo_id, reminders.remind_at, reminders.channel, reminders.sent_at, todos.title, todos.completed
+FROM reminders
+JOIN todos ON todos.id = reminders.todo_id
+WHERE todos.owner_id IS sqlc.arg(owner_id) AND todos.archived_at IS NULL
+  AND reminders.remind_at >= sqlc.arg(day_start) AND reminders.remind_at < sqlc.arg(day_end)
+ORDER BY reminders.remind_at, reminders.id;
+
+-- name: ListPendingRecurringTodos :many
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE owner_id IS sqlc.arg(owner_id) AND completed = 0 AND archived_at IS NULL
+  AND recurrence IS NOT NULL AND recurrence != '' AND next_todo_id IS NULL AND due_at < sqlc.arg(day_end)
+  AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+ORDER BY due_at, id;
+
+-- name: CreateOutboxEvent :exec
+INSERT INTO outbox (type, todo_id, owner_id, completed, comment_id, occurred_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ListPendingOutboxEvents :many
+SELECT id, type, todo_id, owner_id, completed, occurred_at, attempts, last_error, published_at, comment_id
+FROM outbox
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT ?;
+
+-- name: GetLatestOutboxEventID :one
+SELECT CAST(COALESCE(MAX(id), 0) AS INTEGER) AS id
+FROM outbox;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox SET published_at = ? WHERE id = ?;
+
+-- name: RecordOutboxFailure :exec
+UPDATE outbox SET attempts = attempts + 1, last_error = ? WHERE id = ?;
+
+-- name: PruneOutbox :execrows
+DELETE FROM outbox
+WHERE published_at IS NOT NULL AND published_at < ?;
+
+-- name: WatchTodo :exec
+INSERT INTO todo_watchers (todo_id, user_id)
+VALUES (?, ?)
+ON CONFLICT (todo_id, user_id) DO NOTHING;
+
+-- name: UnwatchTodo :execrows
+DELETE FROM todo_watchers WHERE todo_id = ? AND user_id = ?;
+
+-- name: UnwatchTodos :execrows
+DELETE FROM todo_watchers
+WHERE user_id = sqlc.arg(user_id) AND todo_id IN (sqlc.slice(todo_ids));
+
+-- name: ListWatchersByTodoIDs :many
+SELECT todo_watchers.todo_id, todo_watchers.user_id, users.name, todo_watchers.created_at
+FROM todo_watchers
+JOIN users ON users.id = todo_watchers.user_id
+WHERE todo_watchers.todo_id IN (sqlc.slice(todo_ids))
+ORDER BY todo_watchers.todo_id, todo_watchers.created_at, todo_watchers.user_id;
+
+-- name: ListTodoWatchersToNotify :many
+-- 共有を取り消されるなどして、Todoを閲覧できなくなったユーザーには通知しない
+SELECT users.id, users.email
+FROM todo_watchers
+JOIN users ON users.id = todo_watchers.user_id
+JOIN todos ON todos.id = todo_watchers.todo_id
+WHERE todo_watchers.todo_id = ?
+  AND (todos.owner_id = todo_watchers.user_id OR EXISTS (
+        SELECT 1 FROM todo_shares s
+        WHERE s.user_id = todo_watchers.user_id
+          AND (s.todo_id = todos.id OR (s.project_id = todos.project_id AND s.shared_by = todos.owner_id))
+    ))
+ORDER BY users.id;
+
+-- name: GetComment :one
+SELECT id, todo_id, author, body, created_at
+FROM comments
+WHERE id = ? AND todo_id = ?;
+
+-- name: ListDueDigestTodos :many
+-- 期限が今日までの未完了のTodoを、その日のまとめをまだ送っていない所有者ごとに期限の順で返す
+SELECT id, title, description, completed, latitude, longitude, place_name, subtask_count, subtask_completed_count, project_id, due_at, recurrence, next_todo_id, position, version, assignee, owner_id, metadata, priority, escalation_level, created_at, updated_at, translations, archived_at
+FROM todos
+WHERE completed = 0 AND archived_at IS NULL AND owner_id IS NOT NULL AND due_at < sqlc.arg(day_end)
+  AND (project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))
+  AND NOT EXISTS (SELECT 1 FROM due_digests d WHERE d.owner_id = todos.owner_id AND d.digest_date = sqlc.arg(digest_date))
+ORDER BY owner_id, due_at, id;
+
+-- name: CreateDueDigest :exec
+INSERT INTO due_digests (owner_id, digest_date, todo_count, sent_at)
+VALUES (?, ?, ?, ?);
+
+-- name: PruneDueDigests :execrows
+DELETE FROM due_digests
+WHERE sent_at < ?;
+
+-- name: AcquireResourceLock :one
+-- 期限内のロックを他のユーザーが持っている場合は更新せず、行を返さない。同じユーザーが取得し直した場合は期限だけを延ばす
+INSERT INTO resource_locks (resource_type, resource_id, holder_id, acquired_at, expires_at)
+VALUES (sqlc.arg(resource_type), sqlc.arg(resource_id), sqlc.arg(holder_id), sqlc.arg(now), sqlc.arg(expires_at))
+ON CONFLICT (resource_type, resource_id) DO UPDATE SET
+    acquired_at = CASE
+        WHEN resource_locks.holder_id = excluded.holder_id AND resource_locks.expires_at > excluded.acquired_at THEN resource_locks.acquired_at
+        ELSE excluded.acquired_at
+    END,
+    holder_id = excluded.holder_id,
+    expires_at = excluded.expires_at
+WHERE resource_locks.holder_id = excluded.holder_id OR resource_locks.expires_at <= excluded.acquired_at
+RETURNING resource_type, resource_id, holder_id, acquired_at, expires_at;
+
+-- name: GetResourceLock :one
+SELECT l.resource_type, l.resource_id, l.holder_id, u.name AS holder_name, l.acquired_at, l.expires_at
+FROM resource_locks l
+JOIN users u ON u.id = l.holder_id
+WHERE l.resource_type = sqlc.arg(resource_type) AND l.resource_id = sqlc.arg(resource_id) AND l.expires_at > sqlc.arg(now);
+
+-- name: ListTodoLocks :many
+SELECT l.resource_id, l.holder_id, u.name AS holder_name, l.expires_at
+FROM resource_locks l
+JOIN users u ON u.id = l.holder_id
+WHERE l.resource_type = 'todo' AND l.resource_id IN (sqlc.slice(ids)) AND l.expires_at > sqlc.arg(now)
+ORDER BY l.resource_id;
+
+-- name: DeleteResourceLock :exec
+DELETE FROM resource_locks WHERE resource_type = ? AND resource_id = ?;
+
+-- name: PruneResourceLocks :execrows
+DELETE FROM resource_locks WHERE expires_at <= ?;
+
+-- name: CreateInvitation :one
+INSERT INTO invitations (email, role, token_hash, invited_by, expires_at)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, created_at;
+
+-- name: ListInvitations :many
+SELECT id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, created_at
+FROM invitations
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: GetInvitationByTokenHash :one
+SELECT id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, created_at
+FROM invitations
+WHERE token_hash = ?;
+
+-- name: GetPendingInvitationByEmail :one
+-- OIDCで確認済みのメールアドレスを、トークンなしで招待と照合する
+SELECT id, email, role, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, created_at
+FROM invitations
+WHERE email = sqlc.arg(email) AND accepted_at IS NULL AND expires_at > sqlc.arg(now)
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: AcceptInvitation :execrows
+UPDATE invitations
+SET accepted_at = sqlc.arg(accepted_at), accepted_user_id = sqlc.arg(user_id)
+WHERE id = sqlc.arg(id) AND accepted_at IS NULL;
+
+-- name: DeleteInvitation :execrows
+DELETE FROM invitations WHERE id = ?;
+
+-- name: ListTags :many
+-- 所有者のタグを、付けているTodoの件数とともに名前の順で返す
+SELECT t.id, t.owner_id, t.name, t.created_at, CAST(COUNT(tt.todo_id) AS INTEGER) AS todo_count
+FROM tags t
+LEFT JOIN todo_tags tt ON tt.tag_id = t.id
+WHERE t.owner_id IS ?
+GROUP BY t.id
+ORDER BY t.name;
+
+-- name: GetTag :one
+SELECT id, owner_id, name, created_at
+FROM tags
+WHERE id = ? AND owner_id IS ?;
+
+-- name: GetTagByName :one
+SELECT id, owner_id, name, created_at
+FROM tags
+WHERE owner_id IS ? AND name = ?;
+
+-- name: CreateTag :one
+INSERT INTO tags (owner_id, name)
+VALUES (?, ?)
+RETURNING id, owner_id, name, created_at;
+
+-- name: RenameTag :one
+UPDATE tags SET name = ?
+WHERE id = ?
+RETURNING id, owner_id, name, created_at;
+
+-- name: DeleteTag :execrows
+DELETE FROM tags WHERE id = ?;
+
+-- name: ListTagTodoIDs :many
+SELECT todo_id FROM todo_tags
+WHERE tag_id = ?
+ORDER BY todo_id;
+
+-- name: ReassignTodoTags :exec
+-- from_tag_idを付けたTodoにto_tag_idを付ける。既に付けている場合はそのままにする
+INSERT OR IGNORE INTO todo_tags (todo_id, tag_id)
+SELECT todo_id, sqlc.arg(to_tag_id) FROM todo_tags
+WHERE tag_id = sqlc.arg(from_tag_id);
+
+-- name: ListTodoTagNames :many
+SELECT tt.todo_id, t.name
+FROM todo_tags tt
+JOIN tags t ON t.id = tt.tag_id
+WHERE tt.todo_id IN (sqlc.slice(ids))
+ORDER BY tt.todo_id, t.name;
+
+-- name: AddTodoTag :exec
+INSERT OR IGNORE INTO todo_tags (todo_id, tag_id)
+VALUES (?, ?);
+
+-- name: DeleteTodoTags :exec
+DELETE FROM todo_tags WHERE todo_id = ?;
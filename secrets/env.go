@@ -0,0 +1,30 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// EnvPrefix は秘密情報を読み込む環境変数の接頭辞
+const EnvPrefix = "TODO_"
+
+// EnvProvider は環境変数から秘密情報を読み込むProvider。
+// 名前は大文字に変換し接頭辞を付けた環境変数名で参照する（smtp_password → TODO_SMTP_PASSWORD）。
+type EnvProvider struct {
+	prefix string
+}
+
+// NewEnvProvider はEnvProviderの新しいインスタンスを生成する
+func NewEnvProvider(prefix string) *EnvProvider {
+	return &EnvProvider{prefix: prefix}
+}
+
+// Get は環境変数から秘密情報を取得する
+func (p *EnvProvider) Get(_ context.Context, name string) (string, error) {
+	v, ok := os.LookupEnv(p.prefix + strings.ToUpper(name))
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
@@ -0,0 +1,40 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DockerSecretsDir はDocker secretsがマウントされるディレクトリ
+const DockerSecretsDir = "/run/secrets"
+
+// FileProvider はディレクトリ内の名前と同名のファイルから秘密情報を読み込むProvider。
+// Docker secretsやKubernetesのSecretボリュームのように1ファイル1値の形式に対応する。
+type FileProvider struct {
+	dir string
+}
+
+// NewFileProvider はFileProviderの新しいインスタンスを生成する
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+// Get はファイルから秘密情報を取得する。末尾の改行は取り除く。
+func (p *FileProvider) Get(_ context.Context, name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("不正な秘密情報の名前です: %s", name)
+	}
+	b, err := os.ReadFile(filepath.Join(p.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -0,0 +1,156 @@
+// Package secrets はSMTPの認証情報やWebhookの署名鍵などの秘密情報を、
+// 環境変数・ファイル・Docker secrets・Vaultといった差し替え可能なプロバイダーから読み込む機能を提供する。
+// Managerは読み込んだ値を一定時間だけキャッシュし、期限が切れると再読み込みすることでローテーションに追従する。
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 秘密情報の名前
+const (
+	// SMTPUsername はSMTP認証のユーザー名
+	SMTPUsername = "smtp_username"
+	// SMTPPassword はSMTP認証のパスワード
+	SMTPPassword = "smtp_password"
+	// WebhookSecret はWebhook通知の署名に用いる鍵
+	WebhookSecret = "webhook_secret"
+	// JWTSigningKey はJWTの署名に用いる鍵
+	JWTSigningKey = "jwt_signing_key"
+	// StaticTokens は固定のBearerトークンと認証主体の組（<認証主体>=<トークン>のカンマ区切り）
+	StaticTokens = "static_tokens"
+	// OIDCClientSecret はOIDCプロバイダーに登録したクライアントシークレット
+	OIDCClientSecret = "oidc_client_secret"
+	// GoogleClientSecret はGoogle Tasksのインポートでリフレッシュトークンを使うためのOAuthクライアントシークレット
+	GoogleClientSecret = "google_client_secret"
+	// S3AccessKeyID はデータベースを複製するS3互換のストレージのアクセスキーID
+	S3AccessKeyID = "s3_access_key_id"
+	// S3SecretAccessKey はS3AccessKeyIDのシークレットアクセスキー
+	S3SecretAccessKey = "s3_secret_access_key"
+	// S3SessionToken は一時的な認証情報を使う場合のセッショントークン。長期のアクセスキーでは不要
+	S3SessionToken = "s3_session_token"
+	// ActionLinkKey は通知に含めるワンクリック操作のリンクの署名に用いる鍵
+	ActionLinkKey = "action_link_key"
+)
+
+// ErrNotFound は指定された秘密情報がプロバイダーに存在しないことを表すエラー
+var ErrNotFound = errors.New("秘密情報が見つかりません")
+
+// Provider は名前を指定して秘密情報を取得するインターフェース
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// Chain は複数のプロバイダーを順に問い合わせ、最初に見つかった値を返すProvider
+type Chain []Provider
+
+// Get は各プロバイダーを順に問い合わせる。どのプロバイダーにも存在しない場合はErrNotFoundを返す。
+func (c Chain) Get(ctx context.Context, name string) (string, error) {
+	for _, p := range c {
+		v, err := p.Get(ctx, name)
+		if err == nil {
+			return v, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return "", err
+		}
+	}
+	return "", ErrNotFound
+}
+
+// VaultConfig はVaultプロバイダーの接続設定を表す構造体
+type VaultConfig struct {
+	Addr  string
+	Token string
+	Path  string
+}
+
+// ParseProviders はカンマ区切りのプロバイダー指定からChainを生成する。
+// 指定できるのは env, docker, file:<ディレクトリ>, vault である。
+func ParseProviders(spec string, vault VaultConfig) (Chain, error) {
+	var chain Chain
+	for _, s := range strings.Split(spec, ",") {
+		kind, arg, _ := strings.Cut(strings.TrimSpace(s), ":")
+		switch kind {
+		case "":
+			continue
+		case "env":
+			chain = append(chain, NewEnvProvider(EnvPrefix))
+		case "docker":
+			chain = append(chain, NewFileProvider(DockerSecretsDir))
+		case "file":
+			if arg == "" {
+				return nil, errors.New("fileプロバイダーにはディレクトリを指定してください（file:<ディレクトリ>）")
+			}
+			chain = append(chain, NewFileProvider(arg))
+		case "vault":
+			if vault.Addr == "" || vault.Path == "" {
+				return nil, errors.New("vaultプロバイダーにはVaultのアドレスとパスが必要です")
+			}
+			chain = append(chain, NewVaultProvider(vault.Addr, vault.Token, vault.Path))
+		default:
+			return nil, fmt.Errorf("未対応の秘密情報プロバイダーです: %s", kind)
+		}
+	}
+	return chain, nil
+}
+
+// cached はキャッシュした秘密情報を表す構造体
+type cached struct {
+	value     string
+	found     bool
+	fetchedAt time.Time
+}
+
+// Manager はプロバイダーから読み込んだ秘密情報をttlの間キャッシュするマネージャー
+type Manager struct {
+	provider Provider
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+// NewManager はManagerの新しいインスタンスを生成する
+func NewManager(provider Provider, ttl time.Duration) *Manager {
+	return &Manager{
+		provider: provider,
+		ttl:      ttl,
+		cache:    make(map[string]cached),
+	}
+}
+
+// Lookup は秘密情報を取得する。存在しない場合はokにfalseを返す。
+// プロバイダーへの問い合わせに失敗した場合は、以前に取得した値があればそれを返す。
+func (m *Manager) Lookup(ctx context.Context, name string) (value string, ok bool, err error) {
+	m.mu.Lock()
+	c, hit := m.cache[name]
+	m.mu.Unlock()
+	if hit && time.Since(c.fetchedAt) < m.ttl {
+		return c.value, c.found, nil
+	}
+
+	v, err := m.provider.Get(ctx, name)
+	switch {
+	case err == nil:
+		c = cached{value: v, found: true, fetchedAt: time.Now()}
+	case errors.Is(err, ErrNotFound):
+		c = cached{fetchedAt: time.Now()}
+	case hit:
+		slog.Warn("秘密情報の再読み込みに失敗したため以前の値を使用", "name", name, "err", err)
+		return c.value, c.found, nil
+	default:
+		return "", false, fmt.Errorf("秘密情報 %s の取得に失敗: %w", name, err)
+	}
+
+	m.mu.Lock()
+	m.cache[name] = c
+	m.mu.Unlock()
+	return c.value, c.found, nil
+}
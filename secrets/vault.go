@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultProvider はHashiCorp VaultのKVシークレットエンジン（v2）から秘密情報を読み込むProvider。
+// pathで指定したシークレットのキーを秘密情報の名前として参照する。
+type VaultProvider struct {
+	addr   string
+	token  string
+	path   string
+	client *http.Client
+}
+
+// NewVaultProvider はVaultProviderの新しいインスタンスを生成する。
+// pathは "secret/data/todo" のようにKV v2のデータパスを指定する。
+func NewVaultProvider(addr, token, path string) *VaultProvider {
+	return &VaultProvider{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		path:   strings.Trim(path, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// vaultKVResponse はKV v2の読み取りAPIのレスポンスを表す構造体
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]any `json:"data"`
+	} `json:"data"`
+}
+
+// Get はVaultから秘密情報を取得する
+func (p *VaultProvider) Get(ctx context.Context, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.addr+"/v1/"+p.path, nil)
+	if err != nil {
+		return "", fmt.Errorf("Vaultリクエストの作成に失敗: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Vaultへの問い合わせに失敗: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Vaultがエラーを返しました: %s", resp.Status)
+	}
+
+	var body vaultKVResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("Vaultのレスポンスの解析に失敗: %w", err)
+	}
+	v, ok := body.Data.Data[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("秘密情報 %s が文字列ではありません", name)
+	}
+	return s, nil
+}
@@ -0,0 +1,272 @@
+// Package slo はAPIの応答から可用性とレイテンシのSLO（サービスレベル目標）の達成状況を追跡する機能を提供する。
+// 応答の結果を1分ごとに集計してメモリに保持し、SLOの期間の達成率と残りのエラーバジェット、
+// 期間ごとのバーンレート（エラーバジェットを消費する速さ）と、Prometheusで一般的な複数期間のバーンレートのアラートの状態を求める。
+// 外部の監視の仕組みを用意しない小さな環境でも、SLOを確認できるようにする。記録は再起動で失われる。
+package slo
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// bucketWidth は応答の結果を集計する単位の時間
+const bucketWidth = time.Minute
+
+// 目標の名前
+const (
+	ObjectiveAvailability = "availability"
+	ObjectiveLatency      = "latency"
+)
+
+// アラートの重大度
+const (
+	SeverityPage   = "page"
+	SeverityTicket = "ticket"
+)
+
+// burnRateWindows はバーンレートを求める期間。SLOの期間より長いものは除き、SLOの期間全体を加える
+var burnRateWindows = []time.Duration{
+	5 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+	72 * time.Hour,
+}
+
+// alertRule は長い期間と短い期間のバーンレートがどちらも閾値以上のときに発火するアラートの条件
+type alertRule struct {
+	severity string
+	long     time.Duration
+	short    time.Duration
+	// budget は長い期間に消費すると発火するエラーバジェットの割合。閾値はSLOの期間から求める
+	budget float64
+}
+
+// alertRules は複数期間のバーンレートのアラートの条件。SLOの期間が30日の場合、閾値は順に14.4、6、3、1になる
+var alertRules = []alertRule{
+	{severity: SeverityPage, long: time.Hour, short: 5 * time.Minute, budget: 0.02},
+	{severity: SeverityPage, long: 6 * time.Hour, short: 30 * time.Minute, budget: 0.05},
+	{severity: SeverityTicket, long: 24 * time.Hour, short: 2 * time.Hour, budget: 0.1},
+	{severity: SeverityTicket, long: 72 * time.Hour, short: 6 * time.Hour, budget: 0.1},
+}
+
+// ParseTarget は"99.9"のような目標の百分率を解析する。空文字と0は目標を設定しないことを表し、0を返す
+func ParseTarget(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 || v >= 100 {
+		return 0, fmt.Errorf("目標は0以上100未満の百分率で指定してください: %s", s)
+	}
+	return v, nil
+}
+
+// Config はSLOの設定を表す構造体
+type Config struct {
+	// Availability は5xx以外で応答したリクエストの割合の目標（%）。0は追跡しない
+	Availability float64
+	// Latency は5xx以外で応答したリクエストのうち、LatencyThreshold以内に応答したものの割合の目標（%）。0は追跡しない
+	Latency float64
+	// LatencyThreshold はレイテンシの目標の応答時間
+	LatencyThreshold time.Duration
+	// Window は達成率とエラーバジェットを数えるSLOの期間
+	Window time.Duration
+}
+
+// bucket は1分間の応答の結果の集計
+type bucket struct {
+	// minute はUnix時間の分。minuteが異なる古い集計は捨てる
+	minute int64
+	total  int64
+	errors int64
+	// timed はレイテンシの目標の対象にしたリクエストの数
+	timed int64
+	slow  int64
+}
+
+// Tracker は応答の結果を1分ごとに集計し、SLOの期間の分だけ保持する
+type Tracker struct {
+	config Config
+	since  time.Time
+
+	mu      sync.Mutex
+	buckets []bucket
+}
+
+// NewTracker はTrackerの新しいインスタンスを生成する
+func NewTracker(config Config) *Tracker {
+	size := max(int(config.Window/bucketWidth), 1)
+	return &Tracker{
+		config:  config,
+		since:   time.Now(),
+		buckets: make([]bucket, size),
+	}
+}
+
+// Record は応答の結果を記録する。timedがfalseのリクエストはレイテンシの目標の対象にしない
+func (t *Tracker) Record(at time.Time, status int, elapsed time.Duration, timed bool) {
+	minute := at.Unix() / int64(bucketWidth/time.Second)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := &t.buckets[minute%int64(len(t.buckets))]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	b.total++
+	if status >= http.StatusInternalServerError {
+		b.errors++
+		return
+	}
+	if timed {
+		b.timed++
+		if elapsed > t.config.LatencyThreshold {
+			b.slow++
+		}
+	}
+}
+
+// Report はSLOの達成状況を表す構造体
+type Report struct {
+	Window time.Duration
+	// Since は記録を始めた日時。これより前の応答は数えていない
+	Since      time.Time
+	Objectives []ObjectiveReport
+}
+
+// ObjectiveReport は1つの目標の達成状況を表す構造体
+type ObjectiveReport struct {
+	Name   string
+	Target float64
+	// Threshold はレイテンシの目標の応答時間。可用性の目標では0
+	Threshold time.Duration
+	// Total と Bad はSLOの期間の対象のリクエストと、目標を満たさなかったリクエストの数
+	Total int64
+	Bad   int64
+	// Compliance はSLOの期間の目標を満たしたリクエストの割合（%）
+	Compliance float64
+	// BudgetRemaining はSLOの期間のエラーバジェットの残りの割合。使い切ると0、超えると負になる
+	BudgetRemaining float64
+	BurnRates       []BurnRate
+	Alerts          []Alert
+}
+
+// BurnRate は1つの期間のバーンレートを表す構造体。1はSLOの期間でちょうどエラーバジェットを使い切る速さ
+type BurnRate struct {
+	Window time.Duration
+	Total  int64
+	Bad    int64
+	Rate   float64
+}
+
+// Alert は複数期間のバーンレートのアラートの条件と状態を表す構造体
+type Alert struct {
+	Severity    string
+	LongWindow  time.Duration
+	ShortWindow time.Duration
+	Threshold   float64
+	Firing      bool
+}
+
+// counts は期間の対象のリクエストと、目標を満たさなかったリクエストの数
+type counts struct {
+	total int64
+	bad   int64
+}
+
+// Report はnowの時点のSLOの達成状況を返す
+func (t *Tracker) Report(now time.Time) Report {
+	windows := make([]time.Duration, 0, len(burnRateWindows)+1)
+	for _, w := range burnRateWindows {
+		if w < t.config.Window {
+			windows = append(windows, w)
+		}
+	}
+	windows = append(windows, t.config.Window)
+
+	availability := make(map[time.Duration]counts, len(windows))
+	latency := make(map[time.Duration]counts, len(windows))
+	current := now.Unix() / int64(bucketWidth/time.Second)
+
+	t.mu.Lock()
+	for _, b := range t.buckets {
+		if b.minute == 0 || b.minute > current {
+			continue
+		}
+		age := time.Duration(current-b.minute) * bucketWidth
+		for _, w := range windows {
+			if age >= w {
+				continue
+			}
+			a, l := availability[w], latency[w]
+			a.total += b.total
+			a.bad += b.errors
+			l.total += b.timed
+			l.bad += b.slow
+			availability[w], latency[w] = a, l
+		}
+	}
+	t.mu.Unlock()
+
+	report := Report{Window: t.config.Window, Since: t.since}
+	if t.config.Availability > 0 {
+		report.Objectives = append(report.Objectives, t.objective(ObjectiveAvailability, t.config.Availability, 0, windows, availability))
+	}
+	if t.config.Latency > 0 {
+		report.Objectives = append(report.Objectives, t.objective(ObjectiveLatency, t.config.Latency, t.config.LatencyThreshold, windows, latency))
+	}
+	return report
+}
+
+// objective は期間ごとの数から目標の達成状況を求める
+func (t *Tracker) objective(name string, target float64, threshold time.Duration, windows []time.Duration, byWindow map[time.Duration]counts) ObjectiveReport {
+	budget := 1 - target/100
+	all := byWindow[t.config.Window]
+	r := ObjectiveReport{
+		Name:            name,
+		Target:          target,
+		Threshold:       threshold,
+		Total:           all.total,
+		Bad:             all.bad,
+		Compliance:      100,
+		BudgetRemaining: 1,
+	}
+	if all.total > 0 {
+		r.Compliance = 100 * float64(all.total-all.bad) / float64(all.total)
+		r.BudgetRemaining = 1 - float64(all.bad)/(float64(all.total)*budget)
+	}
+
+	rates := make(map[time.Duration]float64, len(windows))
+	for _, w := range windows {
+		c := byWindow[w]
+		var rate float64
+		if c.total > 0 {
+			rate = float64(c.bad) / float64(c.total) / budget
+		}
+		rates[w] = rate
+		r.BurnRates = append(r.BurnRates, BurnRate{Window: w, Total: c.total, Bad: c.bad, Rate: rate})
+	}
+
+	for _, rule := range alertRules {
+		if !slices.Contains(windows, rule.long) || !slices.Contains(windows, rule.short) {
+			continue
+		}
+		threshold := rule.budget * float64(t.config.Window) / float64(rule.long)
+		r.Alerts = append(r.Alerts, Alert{
+			Severity:    rule.severity,
+			LongWindow:  rule.long,
+			ShortWindow: rule.short,
+			Threshold:   threshold,
+			Firing:      rates[rule.long] >= threshold && rates[rule.short] >= threshold,
+		})
+	}
+	return r
+}
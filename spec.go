@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-huma-test/apidoc"
+	"os"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/spf13/cobra"
+)
+
+// apiVersion はOpenAPIのドキュメントのinfo.version。
+// 互換性のない変更をする場合はメジャーバージョンを上げる。spec diffは上げずに互換性のない変更をした場合に失敗する
+const apiVersion = "1.0.0"
+
+// newSpecCommand はサーバーに登録した操作から生成したOpenAPIのドキュメントを書き出し、基準のドキュメントと比較するspecサブコマンドを生成する。
+// documentはサーバーの初期化で操作を登録した後のドキュメントを返す。
+// ドキュメントの生成ではデータベースに接続しないよう、インメモリのデータベースで初期化する。
+func newSpecCommand(document func() *huma.OpenAPI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "spec",
+		Short: "Export the generated OpenAPI document or compare it with a baseline",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "export",
+		Short: "Print the OpenAPI document generated from the registered operations, to be stored as a baseline",
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			writeJSON(document())
+		},
+	})
+
+	var current string
+	diff := &cobra.Command{
+		Use:   "diff <baseline>",
+		Short: "Compare the generated OpenAPI document with a baseline and list breaking and non-breaking changes",
+		Long: "Reads a baseline written by spec export or fetched from /openapi.json and compares it with the document generated by this build. " +
+			"Removed operations, parameters, properties and responses, newly required inputs, narrowed enums and type changes are breaking; " +
+			"removing what the baseline already marked deprecated is still breaking but flagged with was_deprecated. " +
+			"Exits with status 1 when there are breaking changes and the major version of info.version has not been raised.",
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			baseline, err := os.ReadFile(args[0])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			var doc []byte
+			if current != "" {
+				doc, err = os.ReadFile(current)
+			} else {
+				doc, err = json.Marshal(document())
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			report, err := apidoc.Diff(baseline, doc)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			writeJSON(report)
+			if report.Status != apidoc.DiffOK {
+				os.Exit(1)
+			}
+		},
+	}
+	diff.Flags().StringVar(&current, "current", "", "Compare with this OpenAPI JSON file instead of the document generated by this build")
+	cmd.AddCommand(diff)
+	return cmd
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-huma-test/model"
+	"go-huma-test/tracing"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriverName は独自SQL関数を登録し、SQLの実行をトレースするSQLiteドライバーの名前
+const sqliteDriverName = "sqlite3_todo"
+
+// sqliteScheme はSQLiteのデータベースファイルを指定するdatabase-urlのスキーム
+const sqliteScheme = "sqlite:"
+
+// memoryDatabase はインメモリのデータベースを表すパス。database-urlの"memory"と"sqlite::memory:"がこれになる。
+const memoryDatabase = ":memory:"
+
+// dbRetryAfter はデータベースが混み合っていて処理できなかったリクエストに、再試行まで待つよう返す時間
+const dbRetryAfter = time.Second
+
+// earthRadiusMeters は地球の平均半径（メートル）
+const earthRadiusMeters = 6371000.0
+
+func init() {
+	sql.Register(sqliteDriverName, tracing.WrapDriver(&sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			// haversine(lat1, lng1, lat2, lng2) を距離検索クエリから利用できるようにする
+			return conn.RegisterFunc("haversine", haversine, true)
+		},
+	}))
+}
+
+// dbUnavailable はSQLが実行時間の上限を超えたか、ロックの待ち時間を超えても書き込めなかったエラーかを返す。
+// いずれも時間をおいて再試行すれば成功する可能性があるため、500ではなくRetry-After付きの503で返す。
+func dbUnavailable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var se sqlite3.Error
+	if errors.As(err, &se) {
+		return se.Code == sqlite3.ErrBusy || se.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
+// newError はhuma.NewErrorに設定するエラーの生成関数。
+// データベースが混み合っていて処理できなかった500のエラーは、Retry-After付きの503にする。
+func newError(status int, msg string, errs ...error) huma.StatusError {
+	if status == http.StatusInternalServerError && slices.ContainsFunc(errs, dbUnavailable) {
+		return model.NewUnavailableError(msg+"。データベースが混み合っているため、Retry-Afterの秒数が経過してから再試行してください", dbRetryAfter, errs...)
+	}
+	return model.NewError(status, msg, errs...)
+}
+
+// haversine は2点間の大円距離をメートル単位で返す
+func haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
+// sqlitePath はdatabase-urlからSQLiteのデータベースファイルのパスを返す。
+// "sqlite:./todos.db"のような相対パス、"sqlite:///var/lib/todo/todos.db"のような絶対パス、スキームのないパスを受け付ける。
+// "memory"はファイルを作らないインメモリのデータベースになる。"C:\data\todos.db"のようなWindowsのドライブ文字はスキームとみなさない。
+// PostgreSQLのURLはTodoRepositoryで扱うため、SQLiteのファイルが必要な処理ではエラーにする。
+func sqlitePath(databaseURL string) (string, error) {
+	if databaseURL == "memory" {
+		return memoryDatabase, nil
+	}
+	scheme, rest, ok := strings.Cut(databaseURL, ":")
+	if !ok || strings.ContainsAny(scheme, "/.") || isDriveLetter(scheme) {
+		// スキームのない値はファイルのパスとみなす
+		if databaseURL == "" {
+			return "", errors.New("database-urlを指定してください")
+		}
+		return databaseURL, nil
+	}
+	switch strings.ToLower(scheme) + ":" {
+	case sqliteScheme:
+		// sqlite://path は sqlite:path と同じとみなし、sqlite:///abs は絶対パスになる
+		path := strings.TrimPrefix(rest, "//")
+		if path == "" {
+			return "", fmt.Errorf("database-urlにデータベースファイルのパスがありません: %s", databaseURL)
+		}
+		return path, nil
+	case "postgres:", "postgresql:":
+		return "", errors.New("PostgreSQLのデータベースはTodoの基本的な操作のみに対応しています。この機能にはsqlite:のURLを指定してください")
+	default:
+		return "", fmt.Errorf("database-urlのスキームに対応していません: %s", scheme)
+	}
+}
+
+// isDriveLetter はスキームとして切り出した値がWindowsのドライブ文字（C:など）かを返す
+func isDriveLetter(scheme string) bool {
+	return len(scheme) == 1 && ('a' <= scheme[0] && scheme[0] <= 'z' || 'A' <= scheme[0] && scheme[0] <= 'Z')
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestSQLitePath(t *testing.T) {
+	for _, tt := range []struct {
+		url, want string
+	}{
+		{"sqlite:./todos.db", "./todos.db"},
+		{"sqlite:///var/lib/todo/todos.db", "/var/lib/todo/todos.db"},
+		{"todos.db", "todos.db"},
+		{`C:\data\todos.db`, `C:\data\todos.db`},
+		{"d:/todos.db", "d:/todos.db"},
+		{"memory", memoryDatabase},
+	} {
+		if got, err := sqlitePath(tt.url); err != nil || got != tt.want {
+			t.Errorf("sqlitePath(%q) = %q, %v, want %q", tt.url, got, err, tt.want)
+		}
+	}
+	for _, url := range []string{"", "postgres://localhost/todo", "mysql://localhost/todo"} {
+		if _, err := sqlitePath(url); err == nil {
+			t.Errorf("sqlitePath(%q) succeeded, want error", url)
+		}
+	}
+}
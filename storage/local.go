@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// LocalStore はローカルディレクトリにデータを保存するBlobStore
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore はLocalStoreの新しいインスタンスを生成する。ディレクトリが存在しない場合は作成する。
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("保存先ディレクトリの作成に失敗: %w", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// path はキーに対応するファイルパスを返す。ディレクトリ外を指すキーは拒否する。
+func (s *LocalStore) path(key string) (string, error) {
+	if !filepath.IsLocal(key) {
+		return "", fmt.Errorf("不正なキーです: %s", key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
+
+// Put はrの内容をファイルに書き込む。書き込みに失敗した場合は途中のファイルを残さない。
+func (s *LocalStore) Put(_ context.Context, key string, r io.Reader) (int64, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("一時ファイルの作成に失敗: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	n, err := io.Copy(tmp, r)
+	if err != nil {
+		_ = tmp.Close()
+		return 0, fmt.Errorf("ファイルの書き込みに失敗: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("ファイルの書き込みに失敗: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return 0, fmt.Errorf("ファイルの保存に失敗: %w", err)
+	}
+	return n, nil
+}
+
+// Open はファイルを開く
+func (s *LocalStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete はファイルを削除する。存在しない場合は何もしない。
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("ファイルの削除に失敗: %w", err)
+	}
+	return nil
+}
+
+// List はprefixで始まる名前のファイルを名前の順に返す。書き込み途中の一時ファイルは含めない。
+func (s *LocalStore) List(_ context.Context, prefix string) ([]Object, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("保存先ディレクトリの読み込みに失敗: %w", err)
+	}
+	var objects []Object
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasPrefix(e.Name(), prefix) || strings.HasPrefix(e.Name(), ".upload-") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			// 一覧の取得後に削除されたものは含めない
+			continue
+		}
+		objects = append(objects, Object{Key: e.Name(), Size: info.Size(), ModifiedAt: info.ModTime()})
+	}
+	slices.SortFunc(objects, func(a, b Object) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return objects, nil
+}
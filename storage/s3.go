@@ -0,0 +1,362 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go-huma-test/secrets"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// S3の署名に用いる値
+const (
+	s3Service        = "s3"
+	s3Algorithm      = "AWS4-HMAC-SHA256"
+	s3UnsignedBody   = "UNSIGNED-PAYLOAD"
+	s3AmzDateLayout  = "20060102T150405Z"
+	s3DateLayout     = "20060102"
+	s3EmptyBodyHash  = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3MaxErrorLength = 1024
+)
+
+// S3Config はS3Storeの接続設定を表す構造体
+type S3Config struct {
+	// Endpoint はMinIOなどS3互換のストレージのURL。空の場合はRegionのAWS S3を使う
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Secrets はアクセスキーを読み込む。リクエストごとに読み込むため、ローテーションに追従する
+	Secrets *secrets.Manager
+	Timeout time.Duration
+}
+
+// S3Store はS3互換のオブジェクトストレージにデータを保存するBlobStore。
+// 互換のストレージでも使えるよう、バケットをパスに含めて署名バージョン4で署名したリクエストを送る。
+type S3Store struct {
+	endpoint *url.URL
+	region   string
+	bucket   string
+	secrets  *secrets.Manager
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Store はS3Storeの新しいインスタンスを生成する
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("バケットを指定してください")
+	}
+	if config.Region == "" {
+		return nil, errors.New("リージョンを指定してください")
+	}
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("エンドポイントはhttpまたはhttpsのURLで指定してください: %s", endpoint)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return &S3Store{
+		endpoint: u,
+		region:   config.Region,
+		bucket:   config.Bucket,
+		secrets:  config.Secrets,
+		client:   &http.Client{Timeout: config.Timeout},
+		now:      time.Now,
+	}, nil
+}
+
+// s3Error はS3が返すエラーの本文を表す構造体
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// s3ListResult はListObjectsV2の応答を表す構造体
+type s3ListResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+// Put はrの内容をkeyに保存する。S3は長さの分からない本文を受け付けないため、長さが分からない場合は一時ファイルに書き出してから送る。
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	size, ok := contentLength(r)
+	if !ok {
+		tmp, err := os.CreateTemp("", ".s3-upload-*")
+		if err != nil {
+			return 0, fmt.Errorf("一時ファイルの作成に失敗: %w", err)
+		}
+		defer func() {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}()
+		if size, err = io.Copy(tmp, r); err != nil {
+			return 0, fmt.Errorf("一時ファイルの書き込みに失敗: %w", err)
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("一時ファイルの読み込みに失敗: %w", err)
+		}
+		r = tmp
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, nil, io.NopCloser(r), size)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return size, nil
+}
+
+// Open はkeyに保存された内容を読み出す
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete はkeyに保存された内容を削除する。存在しない場合は何もしない。
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, 0)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// List はprefixで始まるキーの内容をキーの順に返す
+func (s *S3Store) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("一覧の応答を読み込めません: %w", err)
+		}
+		for _, c := range result.Contents {
+			objects = append(objects, Object{Key: c.Key, Size: c.Size, ModifiedAt: c.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	slices.SortFunc(objects, func(a, b Object) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return objects, nil
+}
+
+// do は署名したリクエストを送り、2xxの応答を返す。404はErrNotFound、それ以外はS3のエラーの内容を含むエラーにする。
+func (s *S3Store) do(ctx context.Context, method, key string, query url.Values, body io.ReadCloser, size int64) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = s.endpoint.Path + "/" + s.bucket
+	u.RawPath = s.endpoint.Path + "/" + s3Escape(s.bucket, false)
+	if key != "" {
+		u.Path += "/" + key
+		u.RawPath += "/" + s3Escape(key, false)
+	}
+	u.RawQuery = s3CanonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("リクエストの作成に失敗: %w", err)
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	if err := s.sign(ctx, req, body != nil); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("オブジェクトストレージへの接続に失敗: %w", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode == http.StatusNotFound && key != "" {
+		return nil, ErrNotFound
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, s3MaxErrorLength))
+	var e s3Error
+	if xml.Unmarshal(b, &e) == nil && e.Code != "" {
+		return nil, fmt.Errorf("オブジェクトストレージがエラーを返しました: %s: %s: %s", resp.Status, e.Code, e.Message)
+	}
+	return nil, fmt.Errorf("オブジェクトストレージがエラーを返しました: %s", resp.Status)
+}
+
+// sign はリクエストに署名バージョン4の署名を付ける。本文はストリームで送るため署名に含めない。
+func (s *S3Store) sign(ctx context.Context, req *http.Request, hasBody bool) error {
+	accessKey, ok, err := s.secrets.Lookup(ctx, secrets.S3AccessKeyID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("秘密情報 %s が設定されていません", secrets.S3AccessKeyID)
+	}
+	secretKey, ok, err := s.secrets.Lookup(ctx, secrets.S3SecretAccessKey)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("秘密情報 %s が設定されていません", secrets.S3SecretAccessKey)
+	}
+	sessionToken, _, err := s.secrets.Lookup(ctx, secrets.S3SessionToken)
+	if err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	payloadHash := s3EmptyBodyHash
+	if hasBody {
+		payloadHash = s3UnsignedBody
+	}
+	req.Header.Set("X-Amz-Date", now.Format(s3AmzDateLayout))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	scope := now.Format(s3DateLayout) + "/" + s.region + "/" + s3Service + "/aws4_request"
+	signedHeaders, canonicalHeaders := s3CanonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3AmzDateLayout),
+		scope,
+		hexSHA256(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), now.Format(s3DateLayout))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, accessKey, scope, signedHeaders, signature))
+	return nil
+}
+
+// s3CanonicalHeaders は署名に含めるヘッダーの名前の一覧と、正規化したヘッダーを返す
+func s3CanonicalHeaders(req *http.Request) (string, string) {
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || name == "range" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.Join(values, ",")
+		}
+	}
+	names := slices.Sorted(maps.Keys(headers))
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// s3CanonicalQuery はクエリを署名と同じ順序と符号化で組み立てる
+func s3CanonicalQuery(query url.Values) string {
+	var pairs []string
+	for _, k := range slices.Sorted(maps.Keys(query)) {
+		for _, v := range query[k] {
+			pairs = append(pairs, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape は署名で求められるとおり、英数字と-._~以外をパーセント符号化する。パスの場合は/を符号化しない。
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// contentLength はファイルやメモリ上のデータなど、読み出す前に長さが分かる場合にその長さを返す
+func contentLength(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Stat() (os.FileInfo, error) }:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		// 読み出し済みの位置から送る
+		if s, ok := r.(io.Seeker); ok {
+			if pos, err := s.Seek(0, io.SeekCurrent); err == nil {
+				return info.Size() - pos, true
+			}
+		}
+		return 0, false
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	}
+	return 0, false
+}
+
+// hexSHA256 はsのSHA-256を16進数で返す
+func hexSHA256(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 はkeyでsのHMAC-SHA256を求める
+func hmacSHA256(key []byte, s string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
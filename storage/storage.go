@@ -0,0 +1,46 @@
+// Package storage は添付ファイルやデータベースのスナップショットなどのバイナリデータを保存するBlobストアを提供する。
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound は指定されたキーのデータが存在しないことを表すエラー
+var ErrNotFound = errors.New("blobが見つかりません")
+
+// BlobStore はキーを指定してバイナリデータを保存・取得するストアのインターフェース
+type BlobStore interface {
+	// Put はrの内容をkeyに保存し、保存したバイト数を返す
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open はkeyに保存された内容を読み出す。存在しない場合はErrNotFoundを返す
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete はkeyに保存された内容を削除する
+	Delete(ctx context.Context, key string) error
+}
+
+// Object は保存された内容1件を表す構造体
+type Object struct {
+	Key        string
+	Size       int64
+	ModifiedAt time.Time
+}
+
+// Lister は保存された内容をキーの接頭辞で列挙できるストアのインターフェース
+type Lister interface {
+	// List はprefixで始まるキーの内容をキーの順に返す
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// NewKey は新しいデータを保存するためのランダムなキーを生成する
+func NewKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
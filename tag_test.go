@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go-huma-test/auth"
+	"go-huma-test/clock"
+	"go-huma-test/db"
+	"go-huma-test/event"
+	"go-huma-test/handler"
+	"go-huma-test/model"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBulkTagOperations(t *testing.T) {
+	sqlDB, err := initDB("sqlite:"+filepath.Join(t.TempDir(), "todos.db"), false, true)
+	if err != nil {
+		t.Fatalf("initDB: %v", err)
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	for _, stmt := range []string{
+		"INSERT INTO users (email, name, password_hash) VALUES ('a@example.com', 'A', ''), ('b@example.com', 'B', '')",
+		"INSERT INTO todos (title, owner_id) VALUES ('牛乳を買う', 1), ('卵を買う', 1), ('他のユーザーのTodo', 2)",
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	queries := db.New(sqlDB)
+	bus := event.NewBus()
+	events, unsubscribe := bus.Subscribe(64)
+	defer unsubscribe()
+	tags := handler.NewTagHandler(queries, sqlDB, bus, clock.Freeze(now))
+	ctx := auth.WithUserID(context.Background(), 1)
+
+	setTags := func(ctx context.Context, id int64, names ...string) []string {
+		t.Helper()
+		in := &model.SetTodoTagsInput{ID: id}
+		in.Body.Tags = names
+		out, err := tags.SetTodoTags(ctx, in)
+		if err != nil {
+			t.Fatalf("SetTodoTags(%d): %v", id, err)
+		}
+		return out.Body.Tags
+	}
+	tagID := func(name string) int64 {
+		t.Helper()
+		out, err := tags.ListTags(ctx, &model.ListTagsInput{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tag := range out.Body.Tags {
+			if tag.Name == name {
+				return tag.ID
+			}
+		}
+		t.Fatalf("tag %q not found in %+v", name, out.Body.Tags)
+		return 0
+	}
+	todoTags := func(id int64) []string {
+		t.Helper()
+		rows, err := queries.ListTodoTagNames(context.Background(), []int64{id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, r := range rows {
+			names = append(names, r.Name)
+		}
+		return names
+	}
+	drain := func() []int64 {
+		var ids []int64
+		for {
+			select {
+			case e := <-events:
+				if e.Type != event.TodoUpdated {
+					t.Errorf("event = %+v, want todo.updated", e)
+				}
+				ids = append(ids, e.TodoID)
+			default:
+				return ids
+			}
+		}
+	}
+
+	if got := setTags(ctx, 1, " 買い物 ", "急ぎ", "買い物"); !slices.Equal(got, []string{"急ぎ", "買い物"}) {
+		t.Fatalf("SetTodoTags = %v", got)
+	}
+	setTags(ctx, 2, "買い物", "食品")
+	// 他のユーザーの同じ名前のタグは別のタグにする
+	setTags(auth.WithUserID(context.Background(), 2), 3, "買い物")
+	if ids := drain(); !slices.Equal(ids, []int64{1, 2, 3}) {
+		t.Fatalf("events after SetTodoTags = %v", ids)
+	}
+
+	// 名前の変更はタグを付けたすべてのTodoに反映する
+	rename := &model.RenameTagInput{ID: tagID("買い物")}
+	rename.Body.Name = "買い出し"
+	out, err := tags.RenameTag(ctx, rename)
+	if err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	if !slices.Equal(out.Body.TodoIDs, []int64{1, 2}) || out.Body.Tag.Name != "買い出し" || out.Body.Tag.TodoCount != 2 {
+		t.Fatalf("RenameTag = %+v", out.Body)
+	}
+	if got := todoTags(1); !slices.Equal(got, []string{"急ぎ", "買い出し"}) {
+		t.Errorf("tags of todo 1 = %v", got)
+	}
+	if got := todoTags(3); !slices.Equal(got, []string{"買い物"}) {
+		t.Errorf("tags of the other user's todo = %v", got)
+	}
+	if ids := drain(); !slices.Equal(ids, []int64{1, 2}) {
+		t.Errorf("events after RenameTag = %v", ids)
+	}
+
+	// 既にある名前への変更は409
+	rename = &model.RenameTagInput{ID: tagID("食品")}
+	rename.Body.Name = "急ぎ"
+	_, err = tags.RenameTag(ctx, rename)
+	wantStatus(t, err, http.StatusConflict)
+
+	// dry_runでは何も変えない
+	merge := &model.MergeTagInput{ID: tagID("食品"), DryRun: true}
+	merge.Body.Into = tagID("急ぎ")
+	mout, err := tags.MergeTag(ctx, merge)
+	if err != nil {
+		t.Fatalf("MergeTag dry run: %v", err)
+	}
+	if !mout.Body.DryRun || !slices.Equal(mout.Body.TodoIDs, []int64{2}) || mout.Body.Tag.TodoCount != 2 {
+		t.Fatalf("MergeTag dry run = %+v", mout.Body)
+	}
+	if got := todoTags(2); !slices.Equal(got, []string{"買い出し", "食品"}) {
+		t.Fatalf("tags after dry run = %v", got)
+	}
+	if ids := drain(); len(ids) != 0 {
+		t.Fatalf("events after dry run = %v", ids)
+	}
+
+	// 他のユーザーがロックしているTodoが含まれる場合は何も変えない
+	if _, err := sqlDB.Exec("INSERT INTO resource_locks (resource_type, resource_id, holder_id, acquired_at, expires_at) VALUES (?, 2, 2, ?, ?)",
+		model.LockResourceTodo, now, now.Add(10*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	merge.DryRun = false
+	_, err = tags.MergeTag(ctx, merge)
+	wantStatus(t, err, http.StatusLocked)
+	if _, err := sqlDB.Exec("DELETE FROM resource_locks"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 統合したタグは削除し、既に統合先を付けているTodoは重複させない
+	setTags(ctx, 1, "急ぎ", "買い出し", "食品")
+	drain()
+	merge.ID = tagID("食品")
+	mout, err = tags.MergeTag(ctx, merge)
+	if err != nil {
+		t.Fatalf("MergeTag: %v", err)
+	}
+	if !slices.Equal(mout.Body.TodoIDs, []int64{1, 2}) || mout.Body.Tag.Name != "急ぎ" || mout.Body.Tag.TodoCount != 2 {
+		t.Fatalf("MergeTag = %+v", mout.Body)
+	}
+	if got := todoTags(1); !slices.Equal(got, []string{"急ぎ", "買い出し"}) {
+		t.Errorf("tags of todo 1 = %v", got)
+	}
+	if got := todoTags(2); !slices.Equal(got, []string{"急ぎ", "買い出し"}) {
+		t.Errorf("tags of todo 2 = %v", got)
+	}
+	drain()
+
+	// 付け替え先を指定した削除と、外すだけの削除
+	dout, err := tags.DeleteTag(ctx, &model.DeleteTagInput{ID: tagID("急ぎ"), ReassignTo: tagID("買い出し")})
+	if err != nil {
+		t.Fatalf("DeleteTag with reassignment: %v", err)
+	}
+	if !slices.Equal(dout.Body.TodoIDs, []int64{1, 2}) || dout.Body.Tag.Name != "買い出し" {
+		t.Fatalf("DeleteTag = %+v", dout.Body)
+	}
+	dout, err = tags.DeleteTag(ctx, &model.DeleteTagInput{ID: tagID("買い出し")})
+	if err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+	if !slices.Equal(dout.Body.TodoIDs, []int64{1, 2}) || dout.Body.Tag != nil {
+		t.Fatalf("DeleteTag = %+v", dout.Body)
+	}
+	if got := todoTags(1); len(got) != 0 {
+		t.Errorf("tags of todo 1 after delete = %v", got)
+	}
+	list, err := tags.ListTags(ctx, &model.ListTagsInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Body.Tags) != 0 {
+		t.Errorf("tags after delete = %+v", list.Body.Tags)
+	}
+
+	// expand=tagsでTodoのレスポンスにタグを含める
+	setTags(ctx, 2, "食品", "急ぎ")
+	todos := handler.NewTodoHandler(queries, sqlDB, bus, nil, clock.Freeze(now))
+	listed, err := todos.ListTodos(ctx, &model.ListTodosInput{ExpandParam: model.ExpandParam{Expand: []string{model.ExpandTags}}})
+	if err != nil {
+		t.Fatalf("ListTodos: %v", err)
+	}
+	for _, todo := range listed.Body.Todos {
+		want := map[int64][]string{1: {}, 2: {"急ぎ", "食品"}}[todo.ID]
+		if todo.Tags == nil || !slices.Equal(todo.Tags, want) {
+			t.Errorf("tags of todo %d = %#v, want %v", todo.ID, todo.Tags, want)
+		}
+	}
+	got, err := todos.GetTodo(ctx, &model.GetTodoInput{ID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Body.Tags != nil {
+		t.Errorf("tags without expand = %v", got.Body.Tags)
+	}
+	drain()
+
+	// 他のユーザーのタグは操作できない
+	_, err = tags.DeleteTag(ctx, &model.DeleteTagInput{ID: 3})
+	wantStatus(t, err, http.StatusNotFound)
+
+	// タグの変更はアクティビティに残る
+	var n int
+	if err := sqlDB.QueryRow("SELECT COUNT(*) FROM activity_log WHERE todo_id = 1 AND action = 'tag'").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	// 設定2回、名前の変更、統合、付け替えて削除、削除
+	if n != 6 {
+		t.Errorf("tag activities for todo 1 = %d, want 6", n)
+	}
+}
+
+func TestProjectBundleKeepsTags(t *testing.T) {
+	sqlDB, err := initDB("sqlite:"+filepath.Join(t.TempDir(), "todos.db"), false, true)
+	if err != nil {
+		t.Fatalf("initDB: %v", err)
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+	for _, stmt := range []string{
+		"INSERT INTO users (email, name, password_hash) VALUES ('a@example.com', 'A', ''), ('b@example.com', 'B', '')",
+		"INSERT INTO projects (name) VALUES ('買い物')",
+		"INSERT INTO todos (title, owner_id, project_id) VALUES ('牛乳を買う', 1, 1), ('卵を買う', 1, 1)",
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	queries := db.New(sqlDB)
+	clk := clock.Freeze(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	tags := handler.NewTagHandler(queries, sqlDB, event.NewBus(), clk)
+	projects := handler.NewProjectHandler(queries, sqlDB, event.NewBus(), nil, nil, clk)
+	ctx := auth.WithUserID(context.Background(), 1)
+	in := &model.SetTodoTagsInput{ID: 1}
+	in.Body.Tags = []string{"食品", "急ぎ"}
+	if _, err := tags.SetTodoTags(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+
+	exported, err := projects.ExportProject(ctx, &model.ExportProjectInput{ID: 1})
+	if err != nil {
+		t.Fatalf("ExportProject: %v", err)
+	}
+	bundle := exported.Body
+	tagged := slices.IndexFunc(bundle.Todos, func(t model.BundleTodo) bool { return t.Ref == 1 })
+	untagged := slices.IndexFunc(bundle.Todos, func(t model.BundleTodo) bool { return t.Ref == 2 })
+	if tagged < 0 || untagged < 0 || !slices.Equal(bundle.Todos[tagged].Tags, []string{"急ぎ", "食品"}) || bundle.Todos[untagged].Tags != nil {
+		t.Fatalf("exported todos = %+v", bundle.Todos)
+	}
+
+	// インポートしたユーザーのタグとして付け、ない名前のタグは作成する
+	other := auth.WithUserID(context.Background(), 2)
+	bundle.Todos[untagged].Tags = []string{" 急ぎ "}
+	_, todoIDs, err := projects.ImportBundle(other, bundle, false)
+	if err != nil {
+		t.Fatalf("ImportBundle: %v", err)
+	}
+	list, err := tags.ListTags(other, &model.ListTagsInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var counts []string
+	for _, tag := range list.Body.Tags {
+		counts = append(counts, fmt.Sprintf("%s:%d", tag.Name, tag.TodoCount))
+	}
+	if !slices.Equal(counts, []string{"急ぎ:2", "食品:1"}) {
+		t.Errorf("imported tags = %v", counts)
+	}
+	rows, err := queries.ListTodoTagNames(context.Background(), []int64{todoIDs[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Errorf("tags of the imported todo = %+v", rows)
+	}
+
+	bundle.Todos[tagged].Tags = []string{"急ぎ", " "}
+	_, _, err = projects.ImportBundle(other, bundle, false)
+	wantStatus(t, err, http.StatusUnprocessableEntity)
+}
+
+func TestDefaultTagsAreAppliedOnCreate(t *testing.T) {
+	sqlDB, err := initDB("sqlite:"+filepath.Join(t.TempDir(), "todos.db"), false, true)
+	if err != nil {
+		t.Fatalf("initDB: %v", err)
+	}
+	defer func() {
+		_ = sqlDB.Close()
+	}()
+	for _, stmt := range []string{
+		"INSERT INTO users (email, name, password_hash) VALUES ('a@example.com', 'A', '')",
+		"INSERT INTO projects (name) VALUES ('個人')",
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	queries := db.New(sqlDB)
+	defaults := handler.NewTodoDefaultsHandler(queries)
+	todos := handler.NewTodoHandler(queries, sqlDB, event.NewBus(), nil, clock.Freeze(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)))
+	ctx := auth.WithUserID(context.Background(), 1)
+
+	global := &model.UpdateTodoDefaultsInput{}
+	global.Body.Tags = []string{"仕事", " 仕事"}
+	out, err := defaults.UpdateGlobalTodoDefaults(ctx, global)
+	if err != nil {
+		t.Fatalf("UpdateGlobalTodoDefaults: %v", err)
+	}
+	if !slices.Equal(out.Body.Defaults.Tags, []string{"仕事"}) {
+		t.Errorf("default tags = %v", out.Body.Defaults.Tags)
+	}
+	// プロジェクトの空の配列は全体の既定値より優先する
+	project := &model.UpdateProjectTodoDefaultsInput{ID: 1}
+	project.Body.Tags = []string{}
+	out, err = defaults.UpdateProjectTodoDefaults(ctx, project)
+	if err != nil {
+		t.Fatalf("UpdateProjectTodoDefaults: %v", err)
+	}
+	if out.Body.Effective.Tags == nil || len(out.Body.Effective.Tags) != 0 {
+		t.Errorf("effective project tags = %#v", out.Body.Effective.Tags)
+	}
+
+	projectID := int64(1)
+	tests := []struct {
+		name      string
+		projectID *int64
+		tags      []string
+		want      []string
+	}{
+		{"global default", nil, nil, []string{"仕事"}},
+		{"project default", &projectID, nil, []string{}},
+		{"explicit", nil, []string{"急ぎ"}, []string{"急ぎ"}},
+		{"explicit empty", nil, []string{}, []string{}},
+	}
+	for _, tt := range tests {
+		in := &model.CreateTodoInput{}
+		in.Body.Title = tt.name
+		in.Body.ProjectID = tt.projectID
+		in.Body.Tags = tt.tags
+		created, err := todos.CreateTodo(ctx, in)
+		if err != nil {
+			t.Fatalf("%s: CreateTodo: %v", tt.name, err)
+		}
+		if !slices.Equal(created.Body.Tags, tt.want) {
+			t.Errorf("%s: tags in the response = %v, want %v", tt.name, created.Body.Tags, tt.want)
+		}
+		rows, err := queries.ListTodoTagNames(ctx, []int64{created.Body.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != len(tt.want) {
+			t.Errorf("%s: stored tags = %+v, want %v", tt.name, rows, tt.want)
+		}
+	}
+
+	global.Body.Tags = []string{""}
+	_, err = defaults.UpdateGlobalTodoDefaults(ctx, global)
+	wantStatus(t, err, http.StatusUnprocessableEntity)
+}
@@ -0,0 +1,103 @@
+-- Projectsテーブル
+CREATE TABLE IF NOT EXISTS projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT,
+    archived_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Todosテーブル
+CREATE TABLE IF NOT EXISTS todos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    description TEXT,
+    completed INTEGER NOT NULL DEFAULT 0,
+    latitude REAL CHECK (latitude BETWEEN -90 AND 90),
+    longitude REAL CHECK (longitude BETWEEN -180 AND 180),
+    place_name TEXT,
+    subtask_count INTEGER NOT NULL DEFAULT 0,
+    subtask_completed_count INTEGER NOT NULL DEFAULT 0,
+    project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- updated_atを自動更新するトリガー
+CREATE TRIGGER IF NOT EXISTS update_todos_updated_at
+    AFTER UPDATE ON todos
+    FOR EACH ROW
+BEGIN
+    UPDATE todos SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+CREATE INDEX IF NOT EXISTS idx_todos_project_id ON todos(project_id);
+
+-- updated_atを自動更新するトリガー
+CREATE TRIGGER IF NOT EXISTS update_projects_updated_at
+    AFTER UPDATE ON projects
+    FOR EACH ROW
+BEGIN
+    UPDATE projects SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+-- 保存済みフィルター（スター付き検索）テーブル
+CREATE TABLE IF NOT EXISTS saved_filters (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    completed INTEGER NOT NULL DEFAULT 0,
+    near TEXT,
+    radius REAL NOT NULL DEFAULT 1000,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- サブタスク（チェックリスト項目）テーブル
+CREATE TABLE IF NOT EXISTS subtasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    completed INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_subtasks_todo_id ON subtasks(todo_id);
+
+-- サブタスクの件数をTodoに反映するトリガー
+CREATE TRIGGER IF NOT EXISTS subtasks_count_insert
+    AFTER INSERT ON subtasks
+    FOR EACH ROW
+BEGIN
+    UPDATE todos
+    SET subtask_count = subtask_count + 1,
+        subtask_completed_count = subtask_completed_count + NEW.completed
+    WHERE id = NEW.todo_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS subtasks_count_update
+    AFTER UPDATE OF completed ON subtasks
+    FOR EACH ROW
+BEGIN
+    UPDATE todos
+    SET subtask_completed_count = subtask_completed_count - OLD.completed + NEW.completed
+    WHERE id = NEW.todo_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS subtasks_count_delete
+    AFTER DELETE ON subtasks
+    FOR EACH ROW
+BEGIN
+    UPDATE todos
+    SET subtask_count = subtask_count - 1,
+        subtask_completed_count = subtask_completed_count - OLD.completed
+    WHERE id = OLD.todo_id;
+END;
+
+-- updated_atを自動更新するトリガー
+CREATE TRIGGER IF NOT EXISTS update_subtasks_updated_at
+    AFTER UPDATE ON subtasks
+    FOR EACH ROW
+BEGIN
+    UPDATE subtasks SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"go-huma-test/middleware"
+	"go-huma-test/model"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// certCheckInterval は証明書のファイルが更新されたかを確認する間隔
+const certCheckInterval = time.Minute
+
+// tlsEnabled はサーバーがHTTPSで待ち受けるかを返す
+func tlsEnabled(o *model.Options) bool {
+	return o.TLSCert != "" || o.TLSKey != "" || o.AutocertDomains != ""
+}
+
+// checkTLS はTLSの起動オプションの組み合わせを検証し、証明書のファイルを指定した場合は読み込めることを確認する
+func checkTLS(o *model.Options) error {
+	if o.HSTSMaxAge < 0 {
+		return fmt.Errorf("hsts-max-ageに負の時間は指定できません: %s", o.HSTSMaxAge)
+	}
+	if o.HTTPRedirectPort != 0 {
+		if o.HTTPRedirectPort < 1 || o.HTTPRedirectPort > 65535 {
+			return fmt.Errorf("http-redirect-portは1から65535の範囲で指定してください: %d", o.HTTPRedirectPort)
+		}
+		if !tlsEnabled(o) {
+			return errors.New("http-redirect-portにはtls-certとtls-keyまたはautocert-domainsが必要です")
+		}
+		if o.HTTPRedirectPort == o.Port {
+			return fmt.Errorf("http-redirect-portにはportと異なるポートを指定してください: %d", o.HTTPRedirectPort)
+		}
+	}
+	if o.AutocertDomains != "" {
+		if o.TLSCert != "" || o.TLSKey != "" {
+			return errors.New("tls-cert、tls-keyとautocert-domainsは同時に指定できません")
+		}
+		return nil
+	}
+	if (o.TLSCert == "") != (o.TLSKey == "") {
+		return errors.New("HTTPSにはtls-certとtls-keyの両方が必要です")
+	}
+	if o.TLSCert != "" {
+		if _, err := tls.LoadX509KeyPair(o.TLSCert, o.TLSKey); err != nil {
+			return fmt.Errorf("証明書を読み込めません: %w", err)
+		}
+	}
+	return nil
+}
+
+// newTLSConfig は起動オプションからHTTPSの設定を作成する。
+// tls-certとtls-keyを指定した場合はファイルの証明書を使い、ファイルが更新されると読み込み直す。
+// autocert-domainsを指定した場合はACME（既定はLet's Encrypt）で証明書を取得し、期限の前に更新する。
+// 返したハンドラーは平文のHTTPのリスナーで使い、ACMEのHTTP-01チャレンジに応答してそれ以外をHTTPSへリダイレクトする。
+func newTLSConfig(o *model.Options) (*tls.Config, http.Handler, error) {
+	redirect := middleware.RedirectHTTPS(o.Port)
+	if o.AutocertDomains == "" {
+		certs, err := newCertFile(o.TLSCert, o.TLSKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}, redirect, nil
+	}
+
+	if err := os.MkdirAll(o.AutocertCacheDir, 0o700); err != nil {
+		return nil, nil, fmt.Errorf("証明書のキャッシュディレクトリを作成できません: %w", err)
+	}
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(splitList(o.AutocertDomains)...),
+		Cache:      autocert.DirCache(o.AutocertCacheDir),
+		Email:      o.AutocertEmail,
+	}
+	if o.AutocertDirectoryURL != "" {
+		m.Client = &acme.Client{DirectoryURL: o.AutocertDirectoryURL}
+	}
+	// TLSConfigはTLS-ALPN-01チャレンジにも応答するため、平文のHTTPのリスナーがなくても証明書を取得できる
+	cfg := m.TLSConfig()
+	cfg.MinVersion = tls.VersionTLS12
+	return cfg, m.HTTPHandler(redirect), nil
+}
+
+// certFile はファイルから読み込んだ証明書を保持し、ファイルが更新された場合に読み込み直す。
+// certbotなどの外部のツールが更新した証明書を再起動せずに使う。
+type certFile struct {
+	certPath, keyPath string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// newCertFile は証明書と秘密鍵のファイルを読み込む
+func newCertFile(certPath, keyPath string) (*certFile, error) {
+	c := &certFile{certPath: certPath, keyPath: keyPath}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// latestModTime は証明書と秘密鍵のファイルのうち新しい方の更新日時を返す
+func (c *certFile) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{c.certPath, c.keyPath} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// load は証明書と秘密鍵を読み込む。呼び出し側でmuをロックするか、公開前に呼び出す。
+func (c *certFile) load() error {
+	modTime, err := c.latestModTime()
+	if err != nil {
+		return fmt.Errorf("証明書のファイルを確認できません: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(c.certPath, c.keyPath)
+	if err != nil {
+		return fmt.Errorf("証明書を読み込めません: %w", err)
+	}
+	c.cert = &cert
+	c.modTime = modTime
+	c.checkedAt = time.Now()
+	return nil
+}
+
+// GetCertificate はtls.ConfigのGetCertificateとして証明書を返す。
+// certCheckIntervalごとにファイルの更新を確認し、読み込みに失敗した場合は前の証明書を使い続ける。
+func (c *certFile) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checkedAt) < certCheckInterval {
+		return c.cert, nil
+	}
+	c.checkedAt = time.Now()
+	modTime, err := c.latestModTime()
+	if err != nil || !modTime.After(c.modTime) {
+		return c.cert, nil
+	}
+	if err := c.load(); err != nil {
+		slog.Warn("更新された証明書の読み込みに失敗", "err", err)
+		return c.cert, nil
+	}
+	slog.Info("証明書を読み込み直しました", "cert", c.certPath)
+	return c.cert, nil
+}
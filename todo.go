@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-huma-test/client"
+	"go-huma-test/model"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/conditional"
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+)
+
+// todoサブコマンドの出力形式
+const (
+	todoOutputTable = "table"
+	todoOutputJSON  = "json"
+)
+
+// clientConfig はtodoサブコマンドが保存する接続先のサーバーとアクセストークンを表す構造体
+type clientConfig struct {
+	Server string `json:"server"`
+	Token  string `json:"token,omitempty"`
+}
+
+// defaultClientConfigPath はユーザーの設定ディレクトリの下の接続先の保存先を返す。設定ディレクトリがない場合は空文字を返す
+func defaultClientConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "todo-api", "client.json")
+}
+
+// loadClientConfig はpathから接続先を読み込む。ファイルがない場合は空の設定を返す
+func loadClientConfig(path string) (clientConfig, error) {
+	var cfg clientConfig
+	if path == "" {
+		return cfg, errors.New("設定ディレクトリがないため、--client-configで接続先の保存先を指定してください")
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("%sを読み込めません: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// saveClientConfig はpathに接続先を保存する。アクセストークンを含むため、本人だけが読めるようにする
+func saveClientConfig(path string, cfg clientConfig) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o600)
+}
+
+// todoCLI はtodoサブコマンドに共通のフラグを表す構造体
+type todoCLI struct {
+	configPath string
+	server     string
+	token      string
+	output     string
+}
+
+// config は保存した接続先にフラグの指定を重ねた接続先を返す。どちらにもサーバーがない場合はportのローカルのサーバーにする
+func (t *todoCLI) config(o *model.Options) (clientConfig, error) {
+	cfg, err := loadClientConfig(t.configPath)
+	if err != nil {
+		return cfg, err
+	}
+	if t.server != "" && t.server != cfg.Server {
+		// 別のサーバーに保存したトークンは送らない
+		cfg = clientConfig{Server: t.server}
+	}
+	cfg.Server = cmp.Or(cfg.Server, fmt.Sprintf("http://localhost:%d", o.Port))
+	cfg.Token = cmp.Or(t.token, cfg.Token)
+	return cfg, nil
+}
+
+// client は接続先のClientを返す。失敗した場合は終了コード1で終了する
+func (t *todoCLI) client(o *model.Options) *client.Client {
+	if t.output != todoOutputTable && t.output != todoOutputJSON {
+		fmt.Fprintf(os.Stderr, "--outputには%sか%sを指定してください\n", todoOutputTable, todoOutputJSON)
+		os.Exit(1)
+	}
+	cfg, err := t.config(o)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	c, err := client.New(client.Config{BaseURL: cfg.Server, Token: cfg.Token})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return c
+}
+
+// write はTodoを出力形式に従って標準出力に書き出す
+func (t *todoCLI) write(todos []model.TodoResponse) {
+	if t.output == todoOutputJSON {
+		writeJSON(map[string]any{"todos": todos})
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDONE\tPRIORITY\tDUE\tTITLE")
+	for _, todo := range todos {
+		done := ""
+		if todo.Completed {
+			done = "x"
+		}
+		due := "-"
+		if todo.DueAt != nil {
+			due = *todo.DueAt
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", todo.ID, done, todo.Priority, due, todo.Title)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// exitOnError はAPIの呼び出しのエラーを標準エラー出力に書き出して終了コード1で終了する。401の場合はログインを促す
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, err)
+	if client.IsStatus(err, 401) {
+		fmt.Fprintln(os.Stderr, "todo loginでログインするか、--tokenでアクセストークンを指定してください")
+	}
+	os.Exit(1)
+}
+
+// newTodoCommand は実行中のサーバーのAPIを呼び出してTodoを操作するtodoサブコマンドを生成する。
+// 接続先のサーバーとアクセストークンはtodo loginで保存し、--serverと--tokenで上書きできる。
+func newTodoCommand() *cobra.Command {
+	t := &todoCLI{}
+	cmd := &cobra.Command{
+		Use:   "todo",
+		Short: "Manage todos on a running server through its API",
+		Long: "Calls the API of a running server. The server URL and access token are saved by todo login " +
+			"and can be overridden with --server and --token.",
+	}
+	cmd.PersistentFlags().StringVar(&t.configPath, "client-config", defaultClientConfigPath(), "File that stores the server URL and access token")
+	cmd.PersistentFlags().StringVar(&t.server, "server", "", "Server URL, overriding the saved one (default http://localhost:<port>)")
+	cmd.PersistentFlags().StringVar(&t.token, "token", "", "Access token, overriding the saved one")
+	cmd.PersistentFlags().StringVarP(&t.output, "output", "o", todoOutputTable, "Output format: table or json")
+
+	cmd.AddCommand(newTodoLoginCommand(t), newTodoLogoutCommand(t), newTodoAddCommand(t), newTodoListCommand(t), newTodoDoneCommand(t))
+	return cmd
+}
+
+// newTodoLoginCommand は標準入力の1行目のパスワードでログインし、接続先とアクセストークンを保存するtodo loginを生成する
+func newTodoLoginCommand(t *todoCLI) *cobra.Command {
+	var email string
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Log in with --email, reading the password from the first line of stdin, and save the server URL and access token",
+		Long: "Logs in to the server and saves its URL and the access token to --client-config. " +
+			"With --token the given token is saved as is, without logging in.",
+		Args: cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, o *model.Options) {
+			cfg, err := t.config(o)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			if t.token == "" {
+				if email == "" {
+					fmt.Fprintln(os.Stderr, "--emailか--tokenを指定してください")
+					os.Exit(1)
+				}
+				password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+				if err != nil && !errors.Is(err, io.EOF) {
+					fmt.Fprintln(os.Stderr, "パスワードを読み込めません:", err)
+					os.Exit(1)
+				}
+				in := &model.LoginInput{}
+				in.Body.Email = email
+				in.Body.Password = strings.TrimRight(password, "\r\n")
+				c, err := client.New(client.Config{BaseURL: cfg.Server})
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+				out, err := c.Login(cmd.Context(), in)
+				exitOnError(err)
+				cfg.Token = out.Body.AccessToken
+			}
+
+			if err := saveClientConfig(t.configPath, cfg); err != nil {
+				fmt.Fprintln(os.Stderr, "接続先を保存できません:", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "%sの接続先を%sに保存しました\n", cfg.Server, t.configPath)
+		}),
+	}
+	cmd.Flags().StringVar(&email, "email", "", "Email address of the user to log in as")
+	return cmd
+}
+
+// newTodoLogoutCommand は保存したアクセストークンを削除するtodo logoutを生成する
+func newTodoLogoutCommand(t *todoCLI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the saved access token",
+		Args:  cobra.NoArgs,
+		Run: humacli.WithOptions(func(_ *cobra.Command, _ []string, _ *model.Options) {
+			cfg, err := loadClientConfig(t.configPath)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			if cfg.Token == "" {
+				return
+			}
+			cfg.Token = ""
+			if err := saveClientConfig(t.configPath, cfg); err != nil {
+				fmt.Fprintln(os.Stderr, "接続先を保存できません:", err)
+				os.Exit(1)
+			}
+		}),
+	}
+}
+
+// newTodoAddCommand は引数をタイトルとしてTodoを作成するtodo addを生成する
+func newTodoAddCommand(t *todoCLI) *cobra.Command {
+	var (
+		description string
+		projectID   int64
+		due         string
+		priority    int64
+	)
+	cmd := &cobra.Command{
+		Use:   "add <title>...",
+		Short: "Create a todo, joining the arguments into its title",
+		Args:  cobra.MinimumNArgs(1),
+		Run: humacli.WithOptions(func(cmd *cobra.Command, args []string, o *model.Options) {
+			in := &model.CreateTodoInput{}
+			in.Body.Title = strings.Join(args, " ")
+			if cmd.Flags().Changed("description") {
+				in.Body.Description = &description
+			}
+			if cmd.Flags().Changed("project") {
+				in.Body.ProjectID = &projectID
+			}
+			if cmd.Flags().Changed("due") {
+				dueAt, err := time.Parse(time.RFC3339, due)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "--dueにはRFC 3339の日時を指定してください:", err)
+					os.Exit(1)
+				}
+				in.Body.DueAt = &dueAt
+			}
+			if cmd.Flags().Changed("priority") {
+				in.Body.Priority = &priority
+			}
+
+			out, err := t.client(o).CreateTodo(cmd.Context(), in)
+			exitOnError(err)
+			t.write([]model.TodoResponse{out.Body})
+		}),
+	}
+	cmd.Flags().StringVar(&description, "description", "", "Description of the todo")
+	cmd.Flags().Int64Var(&projectID, "project", 0, "ID of the project to add the todo to")
+	cmd.Flags().StringVar(&due, "due", "", "Due date and time in RFC 3339, e.g. 2024-01-01T09:00:00Z")
+	cmd.Flags().Int64Var(&priority, "priority", 0, "Priority from 0 (none) to 3 (high)")
+	return cmd
+}
+
+// newTodoListCommand はTodoの一覧を表示するtodo listを生成する。既定では未完了のTodoのみを表示する
+func newTodoListCommand(t *todoCLI) *cobra.Command {
+	var completed, all bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List open todos, or completed ones with --completed",
+		Args:  cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, o *model.Options) {
+			if completed && all {
+				fmt.Fprintln(os.Stderr, "--completedと--allは同時に指定できません")
+				os.Exit(1)
+			}
+			// completed=falseでは完了状態で絞り込まないため、未完了のTodoはここで絞り込む
+			out, err := t.client(o).ListTodos(cmd.Context(), &model.ListTodosInput{Completed: completed})
+			exitOnError(err)
+			todos := make([]model.TodoResponse, 0, len(out.Body.Todos))
+			for _, todo := range out.Body.Todos {
+				if all || todo.Completed == completed {
+					todos = append(todos, todo)
+				}
+			}
+			t.write(todos)
+		}),
+	}
+	cmd.Flags().BoolVar(&completed, "completed", false, "List only completed todos")
+	cmd.Flags().BoolVar(&all, "all", false, "List both open and completed todos")
+	return cmd
+}
+
+// newTodoDoneCommand は指定したIDのTodoを完了にするtodo doneを生成する。完了済みのTodoは変更しない
+func newTodoDoneCommand(t *todoCLI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "done <id>...",
+		Short: "Mark todos as completed. Todos that are already completed are left as they are",
+		Args:  cobra.MinimumNArgs(1),
+		Run: humacli.WithOptions(func(cmd *cobra.Command, args []string, o *model.Options) {
+			ids := make([]int64, len(args))
+			for i, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "TodoのIDが不正です: %s\n", arg)
+					os.Exit(1)
+				}
+				ids[i] = id
+			}
+
+			c := t.client(o)
+			todos := make([]model.TodoResponse, 0, len(ids))
+			for _, id := range ids {
+				todo, err := completeTodo(cmd.Context(), c, id)
+				exitOnError(err)
+				todos = append(todos, todo)
+			}
+			t.write(todos)
+		}),
+	}
+}
+
+// completeTodo は未完了のTodoを完了にする。
+// 完了状態の切り替えは取得した後に他のクライアントが完了にしていると未完了に戻してしまうため、取得したバージョンをIf-Matchで指定する。
+func completeTodo(ctx context.Context, c *client.Client, id int64) (model.TodoResponse, error) {
+	got, err := c.GetTodo(ctx, &model.GetTodoInput{ID: id})
+	if err != nil {
+		return model.TodoResponse{}, err
+	}
+	if got.Body.Completed {
+		return got.Body, nil
+	}
+	out, err := c.ToggleTodo(ctx, &model.ToggleTodoInput{ID: id, Params: conditional.Params{IfMatch: []string{got.ETag}}})
+	if err != nil {
+		return model.TodoResponse{}, err
+	}
+	return out.Body, nil
+}
@@ -0,0 +1,298 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WrapDriver はSQLの実行ごとにスパンを作成するようにドライバーを包む。
+// スパンはdatabase/sqlに渡されたcontextの子になるため、db.Queriesの呼び出しはリクエストのスパンの下に記録される。
+// あわせてcontextの終了後も実行を続けるSQLを監視し、警告としてログに記録する。
+// WithQueryTimeoutでcontextに実行時間の上限を指定した場合は、SQLの実行ごとに上限を適用する。
+// ロックを取れずにトランザクションを開始できなかった場合は、SetBusyRetryの設定で再試行する。
+// 包んだドライバーの接続はドライバー固有の機能を使えるよう、Unwrapで元の接続を返す。
+func WrapDriver(d driver.Driver) driver.Driver {
+	return &tracedDriver{Driver: d}
+}
+
+// tracedDriver はスパンを作成する接続を開くドライバー
+type tracedDriver struct {
+	driver.Driver
+}
+
+func (d *tracedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{conn: c}, nil
+}
+
+// queryName はsqlcが生成したクエリの先頭の"-- name: GetTodo :one"からクエリ名を返す。
+// 名前のないクエリは先頭のキーワード（SELECTなど）を返す。
+func queryName(query string) string {
+	query = strings.TrimSpace(query)
+	if rest, ok := strings.CutPrefix(query, "-- name: "); ok {
+		if name, _, ok := strings.Cut(rest, " "); ok {
+			return name
+		}
+	}
+	if keyword, _, _ := strings.Cut(query, " "); keyword != "" {
+		return strings.ToUpper(keyword)
+	}
+	return "SQL"
+}
+
+// startSpan はSQLの実行のスパンを開始する
+func startSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	name := queryName(query)
+	return Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameSQLite,
+			semconv.DBOperationName(name),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+// endSpan はエラーを記録してスパンを終了する。driver.ErrSkipは別の方法で実行し直すためエラーとして扱わない。
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, driver.ErrSkip) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedConn はSQLの実行ごとにスパンを作成する接続
+type tracedConn struct {
+	conn driver.Conn
+}
+
+// Unwrap は包んでいる元の接続を返す
+func (c *tracedConn) Unwrap() driver.Conn {
+	return c.conn
+}
+
+func (c *tracedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		s   driver.Stmt
+		err error
+	)
+	if p, ok := c.conn.(driver.ConnPrepareContext); ok {
+		s, err = p.PrepareContext(ctx, query)
+	} else {
+		s, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{stmt: s, query: query}, nil
+}
+
+func (c *tracedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *tracedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	// 実行時間の上限はロックを待つ時間と再試行を含めた開始までに適用する
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	return beginWithRetry(ctx, func() (driver.Tx, error) {
+		if b, ok := c.conn.(driver.ConnBeginTx); ok {
+			return b.BeginTx(ctx, opts)
+		}
+		return c.conn.Begin()
+	})
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	ctx, span := startSpan(ctx, query)
+	watch := watchQuery(ctx, query)
+	res, err := e.ExecContext(ctx, query, args)
+	watch.finish(err)
+	if err == nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			span.SetAttributes(attribute.Int64("db.response.affected_rows", n))
+		}
+	}
+	endSpan(span, err)
+	return res, err
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, cancel := queryContext(ctx)
+	ctx, span := startSpan(ctx, query)
+	watch := watchQuery(ctx, query)
+	rows, err := q.QueryContext(ctx, query, args)
+	if err != nil {
+		watch.finish(err)
+		cancel()
+		endSpan(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span, watch: watch, cancel: cancel}, nil
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if p, ok := c.conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// tracedStmt は実行ごとにスパンを作成するプリペアドステートメント
+type tracedStmt struct {
+	stmt  driver.Stmt
+	query string
+}
+
+func (s *tracedStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *tracedStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *tracedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.stmt.Exec(args)
+}
+
+func (s *tracedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.stmt.Query(args)
+}
+
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := s.stmt.(driver.StmtExecContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+	ctx, span := startSpan(ctx, s.query)
+	watch := watchQuery(ctx, s.query)
+	res, err := e.ExecContext(ctx, args)
+	watch.finish(err)
+	if err == nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			span.SetAttributes(attribute.Int64("db.response.affected_rows", n))
+		}
+	}
+	endSpan(span, err)
+	return res, err
+}
+
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := s.stmt.(driver.StmtQueryContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, cancel := queryContext(ctx)
+	ctx, span := startSpan(ctx, s.query)
+	watch := watchQuery(ctx, s.query)
+	rows, err := q.QueryContext(ctx, args)
+	if err != nil {
+		watch.finish(err)
+		cancel()
+		endSpan(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span, watch: watch, cancel: cancel}, nil
+}
+
+// tracedRows は結果を読み終えて閉じたときにスパンを終了する結果セット。
+// SQLiteは行を読み進めるときにクエリを実行するため、読み終えるまでをクエリの時間とし、実行時間の上限も閉じるまで適用する。
+type tracedRows struct {
+	driver.Rows
+	span   trace.Span
+	watch  *queryWatch
+	cancel context.CancelFunc
+	count  int64
+	err    error
+}
+
+func (r *tracedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	switch {
+	case err == nil:
+		r.count++
+	case !errors.Is(err, io.EOF):
+		r.err = err
+	}
+	return err
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	r.watch.finish(r.err)
+	r.cancel()
+	r.span.SetAttributes(attribute.Int64("db.response.returned_rows", r.count))
+	endSpan(r.span, r.err)
+	return err
+}
+
+// 列の型の情報はdatabase/sqlのColumnTypesのため、元の結果セットが実装している場合に返す
+
+func (r *tracedRows) ColumnTypeScanType(index int) reflect.Type {
+	if t, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return t.ColumnTypeScanType(index)
+	}
+	return reflect.TypeFor[any]()
+}
+
+func (r *tracedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if t, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return t.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *tracedRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if t, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return t.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+func (r *tracedRows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if t, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return t.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *tracedRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if t, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return t.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
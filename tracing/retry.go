@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// DefaultBusyRetries はロックを取れずにトランザクションを開始できなかった場合に再試行する回数の既定値
+const DefaultBusyRetries = 3
+
+// DefaultBusyBackoff は再試行までの待ち時間の基準の既定値
+const DefaultBusyBackoff = 50 * time.Millisecond
+
+// maxBusyBackoff は再試行までの待ち時間の上限
+const maxBusyBackoff = 2 * time.Second
+
+var (
+	// busyRetries はトランザクションの開始を再試行する回数
+	busyRetries atomic.Int64
+	// busyBackoff は再試行までの待ち時間の基準（ナノ秒）
+	busyBackoff atomic.Int64
+
+	// busyMetrics はトランザクションの開始の再試行の状況
+	busyMetrics struct {
+		retries   atomic.Int64
+		recovered atomic.Int64
+		exhausted atomic.Int64
+	}
+)
+
+func init() {
+	busyRetries.Store(DefaultBusyRetries)
+	busyBackoff.Store(int64(DefaultBusyBackoff))
+}
+
+// SetBusyRetry はロックを取れずにトランザクションを開始できなかった場合に再試行する回数と、待ち時間の基準を設定する。
+// 待ち時間は再試行のたびに基準を倍にした時間を上限とするランダムな時間で、同時に失敗したリクエストが同時に再試行しないようにする。
+// retriesが0の場合は再試行しない。
+func SetBusyRetry(retries int, backoff time.Duration) {
+	busyRetries.Store(int64(retries))
+	busyBackoff.Store(int64(backoff))
+}
+
+// BusyRetryMetrics はトランザクションの開始の再試行の状況を表す構造体。/debug/varsに公開する。
+type BusyRetryMetrics struct {
+	// Retries は再試行した回数
+	Retries int64 `json:"retries"`
+	// Recovered は再試行によって開始できたトランザクションの数
+	Recovered int64 `json:"recovered"`
+	// Exhausted は再試行しても開始できずにエラーを返したトランザクションの数
+	Exhausted int64 `json:"exhausted"`
+}
+
+// BusyRetryStats はトランザクションの開始の再試行の状況を返す
+func BusyRetryStats() BusyRetryMetrics {
+	return BusyRetryMetrics{
+		Retries:   busyMetrics.retries.Load(),
+		Recovered: busyMetrics.recovered.Load(),
+		Exhausted: busyMetrics.exhausted.Load(),
+	}
+}
+
+// isBusy はほかの接続が書き込み中のためロックを取れなかったエラーかを返す
+func isBusy(err error) bool {
+	var se sqlite3.Error
+	return errors.As(err, &se) && (se.Code == sqlite3.ErrBusy || se.Code == sqlite3.ErrLocked)
+}
+
+// beginWithRetry はbeginでトランザクションを開始し、ロックを取れなかった場合は待ってから再試行する。
+// 書き込み用の接続はBEGIN IMMEDIATEで開始するため、ロックの競合は開始の時点で起き、トランザクションの中の処理をやり直す必要はない。
+// ctxが終了した場合は待つのをやめて最後のエラーを返す。
+func beginWithRetry(ctx context.Context, begin func() (driver.Tx, error)) (driver.Tx, error) {
+	tx, err := begin()
+	retries := int(busyRetries.Load())
+	backoff := time.Duration(busyBackoff.Load())
+	for attempt := 0; err != nil && isBusy(err) && attempt < retries; attempt++ {
+		wait := time.Duration(rand.Int64N(int64(min(backoff<<attempt, maxBusyBackoff)) + 1))
+		select {
+		case <-ctx.Done():
+			busyMetrics.exhausted.Add(1)
+			return nil, err
+		case <-time.After(wait):
+		}
+		busyMetrics.retries.Add(1)
+		slog.Debug("ロックを取れなかったためトランザクションの開始を再試行", "attempt", attempt+1, "wait", wait, "err", err)
+		if tx, err = begin(); err == nil {
+			busyMetrics.recovered.Add(1)
+			return tx, nil
+		}
+	}
+	if err != nil && isBusy(err) && retries > 0 {
+		busyMetrics.exhausted.Add(1)
+		slog.Warn("再試行してもロックを取れずにトランザクションを開始できませんでした", "retries", retries, "err", err)
+	}
+	return tx, err
+}
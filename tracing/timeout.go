@@ -0,0 +1,26 @@
+package tracing
+
+import (
+	"context"
+	"time"
+)
+
+// queryTimeoutKey はSQLの実行時間の上限をcontextに格納するキー
+type queryTimeoutKey struct{}
+
+// WithQueryTimeout はctxで実行するSQLそれぞれの実行時間の上限をdにしたcontextを返す。
+// 上限はWrapDriverで包んだドライバーがSQLの実行ごとに適用し、超えたSQLは中断してcontext.DeadlineExceededを返す。
+// トランザクションの中のSQLにも適用する。dが0以下の場合は上限をなくす。
+func WithQueryTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, d)
+}
+
+// queryContext はctxに実行時間の上限がある場合、上限で終了するcontextを返す。
+// 結果セットは読み終えるまでcontextが必要なため、cancelは結果セットを閉じたときに呼ぶ。
+func queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	d, _ := ctx.Value(queryTimeoutKey{}).(time.Duration)
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
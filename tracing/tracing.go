@@ -0,0 +1,92 @@
+// Package tracing はOpenTelemetryによる分散トレーシングを提供する。
+// HTTPの操作ごとのスパンはmiddleware.Tracingが、SQLの実行ごとのスパンはWrapDriverで包んだドライバーが作成し、
+// SetupでOTLP/HTTPのエクスポーターを設定した場合に送信する。設定しない場合のスパンは何もしない。
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName はこのアプリケーションが作成するスパンの計装ライブラリ名
+const instrumentationName = "go-huma-test"
+
+// tracesPath はOTLP/HTTPでトレースを送信するパス
+const tracesPath = "/v1/traces"
+
+// Tracer はこのアプリケーションのスパンを作成するTracerを返す。
+// Setupの前に呼び出しても、設定後に作成するスパンは設定したプロバイダーで記録される。
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Config はトレースのエクスポートの設定を表す構造体
+type Config struct {
+	// Endpoint はOTLP/HTTPのトレースの送信先URL（例: http://localhost:4318）。
+	// 空の場合はOTEL_EXPORTER_OTLP_ENDPOINTまたはOTEL_EXPORTER_OTLP_TRACES_ENDPOINTを使い、
+	// どちらも設定されていない場合はエクスポートしない。
+	Endpoint string
+	// ServiceName はservice.name属性。OTEL_SERVICE_NAMEが設定されている場合はそちらを優先する。
+	ServiceName string
+	// SampleRatio は親のスパンがないリクエストのうち記録する割合（0から1）
+	SampleRatio float64
+}
+
+// Enabled はエクスポート先が設定されているかを返す
+func (c Config) Enabled() bool {
+	return c.Endpoint != "" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup はOTLP/HTTPでトレースを送信するプロバイダーをグローバルに設定し、
+// W3C Trace ContextとBaggageを伝播するようにする。
+// 返した関数はサーバーの停止時に呼び出し、送信していないスパンを送信する。
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		u, err := url.Parse(cfg.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("OTLPのエンドポイントが不正です: %w", err)
+		}
+		// OTEL_EXPORTER_OTLP_ENDPOINTと同じく、パスのないURLにはトレースの送信先のパスを補う
+		if u.Path == "" || u.Path == "/" {
+			u.Path = tracesPath
+		}
+		opts = append(opts, otlptracehttp.WithEndpointURL(u.String()))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("OTLPエクスポーターの作成に失敗: %w", err)
+	}
+
+	// 環境変数（OTEL_SERVICE_NAME、OTEL_RESOURCE_ATTRIBUTES）の値を起動オプションより優先する
+	env, err := resource.New(ctx, resource.WithFromEnv())
+	if err != nil {
+		return nil, fmt.Errorf("環境変数のリソース属性を読み込めません: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName)))
+	if err == nil {
+		res, err = resource.Merge(res, env)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("リソースの作成に失敗: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
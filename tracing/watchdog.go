@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultOverrunThreshold はcontextの終了後にSQLが実行を続けてよい時間の既定値
+const DefaultOverrunThreshold = time.Second
+
+// overrunThreshold はcontextの終了後にSQLが実行を続けてよい時間（ナノ秒）
+var overrunThreshold atomic.Int64
+
+func init() {
+	overrunThreshold.Store(int64(DefaultOverrunThreshold))
+}
+
+// SetOverrunThreshold はcontextの終了後にSQLが実行を続けてよい時間を設定する。
+// クライアントの切断やタイムアウトの後もこの時間を超えて実行を続けたSQLは警告としてログに記録する。
+func SetOverrunThreshold(d time.Duration) {
+	overrunThreshold.Store(int64(d))
+}
+
+// queryWatch はcontextの終了後も実行を続けるSQLを検出する監視。
+// SQLiteのドライバーはcontextが終了すると実行中のSQLを中断するが、中断は次の命令の実行まで反映されないため、
+// 重い集計やソートでは終了後もしばらく実行が続くことがある。
+type queryWatch struct {
+	ctx   context.Context
+	name  string
+	start time.Time
+	stop  func() bool
+
+	mu       sync.Mutex
+	finished bool
+	doneAt   time.Time
+	timer    *time.Timer
+}
+
+// watchQuery はSQLの実行の監視を開始する。終了しないcontextの場合は監視しない。
+// contextが終了してもoverrunThresholdの間に終わらないSQLは、実行中のまま一度警告する。
+func watchQuery(ctx context.Context, query string) *queryWatch {
+	w := &queryWatch{ctx: ctx, name: queryName(query), start: time.Now()}
+	if ctx.Done() == nil {
+		return w
+	}
+	w.stop = context.AfterFunc(ctx, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.finished {
+			return
+		}
+		w.doneAt = time.Now()
+		w.timer = time.AfterFunc(time.Duration(overrunThreshold.Load()), func() {
+			slog.Warn("contextの終了後もSQLが実行中です",
+				"query", w.name,
+				"cause", context.Cause(ctx),
+				"elapsed", time.Since(w.start),
+			)
+		})
+	})
+	return w
+}
+
+// finish はSQLの実行の終了を記録する。contextの終了からoverrunThreshold以上経って終わった場合は警告する。
+func (w *queryWatch) finish(err error) {
+	if w.stop == nil || w.stop() {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.finished = true
+	if w.timer == nil {
+		return
+	}
+	w.timer.Stop()
+	if overrun := time.Since(w.doneAt); overrun >= time.Duration(overrunThreshold.Load()) {
+		slog.Warn("contextの終了後もSQLが実行を続けていました",
+			"query", w.name,
+			"cause", context.Cause(w.ctx),
+			"overrun", overrun,
+			"elapsed", time.Since(w.start),
+			"err", err,
+		)
+	}
+}
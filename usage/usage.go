@@ -0,0 +1,145 @@
+// Package usage はユーザー（テナント）ごとの利用量を日次で集計する機能を提供する。
+// APIの呼び出し回数はメモリ上で数えて定期的にデータベースへ加算し、
+// 添付ファイルの合計サイズと未完了のTodoの件数は同じ間隔でその日の値として記録する。
+// あわせて操作ごとの呼び出し回数と最終使用日時を累計し、使われていない操作を見つけられるようにする。
+package usage
+
+import (
+	"context"
+	"go-huma-test/db"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DayLayout は利用量を集計する日付（UTC）の形式
+const DayLayout = time.DateOnly
+
+// counterKey はAPIの呼び出し回数を数える日とユーザーの組
+type counterKey struct {
+	day    string
+	userID int64
+}
+
+// operationCount は操作の呼び出し回数と最後に呼び出された日時
+type operationCount struct {
+	calls    int64
+	lastUsed time.Time
+}
+
+// Meter はユーザーごとの利用量と操作ごとの呼び出し回数を集計する
+type Meter struct {
+	queries  *db.Queries
+	interval time.Duration
+	now      func() time.Time
+
+	mu         sync.Mutex
+	calls      map[counterKey]int64
+	operations map[string]operationCount
+}
+
+// NewMeter はMeterの新しいインスタンスを生成する。
+// intervalはAPIの呼び出し回数をデータベースに書き込み、保存容量とTodoの件数を集計する間隔。
+func NewMeter(queries *db.Queries, interval time.Duration) *Meter {
+	return &Meter{
+		queries:    queries,
+		interval:   interval,
+		now:        time.Now,
+		calls:      make(map[counterKey]int64),
+		operations: make(map[string]operationCount),
+	}
+}
+
+// RecordCall はユーザーのAPIの呼び出しを1回数える
+func (m *Meter) RecordCall(userID int64) {
+	key := counterKey{day: m.now().UTC().Format(DayLayout), userID: userID}
+	m.mu.Lock()
+	m.calls[key]++
+	m.mu.Unlock()
+}
+
+// RecordOperation は操作の呼び出しを1回数える
+func (m *Meter) RecordOperation(operationID string) {
+	now := m.now().UTC()
+	m.mu.Lock()
+	c := m.operations[operationID]
+	c.calls++
+	c.lastUsed = now
+	m.operations[operationID] = c
+	m.mu.Unlock()
+}
+
+// Run はctxがキャンセルされるまで一定間隔で利用量を集計する。
+// 集計の停止後に受け付けたリクエストの呼び出し回数は、サーバーの停止時にFlushで書き込む。
+func (m *Meter) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	slog.Info("利用量の集計を開始", "interval", m.interval)
+	m.Collect(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("利用量の集計を停止")
+			return
+		case <-ticker.C:
+			m.Collect(ctx)
+		}
+	}
+}
+
+// Collect はAPIの呼び出し回数を書き込み、当日の保存容量と未完了のTodoの件数を記録する
+func (m *Meter) Collect(ctx context.Context) {
+	m.Flush(ctx)
+
+	day := m.now().UTC().Format(DayLayout)
+	n, err := m.queries.SnapshotUsage(ctx, day)
+	if err != nil {
+		slog.Warn("利用量の集計に失敗", "day", day, "err", err)
+		return
+	}
+	slog.Debug("利用量を集計", "day", day, "users", n)
+}
+
+// Flush はメモリ上で数えたAPIの呼び出し回数と操作ごとの呼び出し回数をデータベースに加算する。
+// 書き込みに失敗した回数は次回に再び加算する。
+func (m *Meter) Flush(ctx context.Context) {
+	m.mu.Lock()
+	calls := m.calls
+	m.calls = make(map[counterKey]int64)
+	operations := m.operations
+	m.operations = make(map[string]operationCount)
+	m.mu.Unlock()
+
+	for key, n := range calls {
+		if err := m.queries.AddUsageAPICalls(ctx, db.AddUsageAPICallsParams{
+			Day:      key.day,
+			UserID:   key.userID,
+			ApiCalls: n,
+		}); err != nil {
+			slog.Warn("APIの呼び出し回数の記録に失敗", "day", key.day, "user_id", key.userID, "err", err)
+			m.mu.Lock()
+			m.calls[key] += n
+			m.mu.Unlock()
+		}
+	}
+
+	for id, c := range operations {
+		if err := m.queries.AddOperationUsage(ctx, db.AddOperationUsageParams{
+			OperationID: id,
+			Calls:       c.calls,
+			LastUsedAt:  c.lastUsed,
+		}); err != nil {
+			slog.Warn("操作の呼び出し回数の記録に失敗", "operation_id", id, "err", err)
+			m.mu.Lock()
+			retry := m.operations[id]
+			retry.calls += c.calls
+			if retry.lastUsed.Before(c.lastUsed) {
+				retry.lastUsed = c.lastUsed
+			}
+			m.operations[id] = retry
+			m.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,347 @@
+// Package websocket はRFC 6455のWebSocketのサーバー側の最小限の実装を提供する。
+// テキストとバイナリのメッセージ、ping/pong、closeを扱い、拡張（圧縮など）とサブプロトコルには対応しない。
+// HTTPの接続はhttp.ResponseControllerで乗っ取るため、Unwrapを実装したResponseWriterで包まれていてもよい。
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// acceptGUID はSec-WebSocket-Acceptの計算に使う、RFC 6455で定められた値
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Version は対応するWebSocketのプロトコルのバージョン
+const Version = "13"
+
+// writeWait はフレームの書き込みを待つ時間。受信しないクライアントが送信側を止め続けないようにする
+const writeWait = 10 * time.Second
+
+// メッセージとフレームの種類
+const (
+	continuationFrame = 0
+	// TextMessage はUTF-8のテキストのメッセージ
+	TextMessage = 1
+	// BinaryMessage はバイナリのメッセージ
+	BinaryMessage = 2
+	closeFrame    = 8
+	pingFrame     = 9
+	pongFrame     = 10
+)
+
+// クローズのステータスコード
+const (
+	// CloseNormal は正常な終了
+	CloseNormal = 1000
+	// CloseGoingAway はサーバーの停止などによる終了
+	CloseGoingAway = 1001
+	// CloseProtocolError はプロトコルに違反したフレームを受け取ったことによる終了
+	CloseProtocolError = 1002
+	// CloseUnsupportedData は扱えない種類のメッセージを受け取ったことによる終了
+	CloseUnsupportedData = 1003
+	// CloseNoStatus はステータスコードのないクローズを受け取ったことを表す。送信には使わない
+	CloseNoStatus = 1005
+	// CloseInvalidPayload はUTF-8でないテキストのメッセージを受け取ったことによる終了
+	CloseInvalidPayload = 1007
+	// ClosePolicyViolation はサーバーの方針に反するメッセージを受け取ったことによる終了
+	ClosePolicyViolation = 1008
+	// CloseMessageTooBig は上限を超える大きさのメッセージを受け取ったことによる終了
+	CloseMessageTooBig = 1009
+	// CloseInternalError はサーバーの内部エラーによる終了
+	CloseInternalError = 1011
+)
+
+// ErrNotWebSocket はリクエストがWebSocketのハンドシェイクでないことを表すエラー
+var ErrNotWebSocket = errors.New("WebSocketのハンドシェイクではありません")
+
+// ErrUnsupportedVersion はSec-WebSocket-Versionが対応していないバージョンであることを表すエラー
+var ErrUnsupportedVersion = errors.New("対応していないWebSocketのバージョンです")
+
+// CloseError は接続がクローズされたことを表すエラー。相手からのクローズと、こちらが送ったクローズのいずれか
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("WebSocketがクローズされました: %d", e.Code)
+	}
+	return fmt.Sprintf("WebSocketがクローズされました: %d %s", e.Code, e.Reason)
+}
+
+// Conn はWebSocketの1つの接続。ReadMessageは1つのゴルーチンから、書き込みは複数のゴルーチンから呼び出せる。
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	// MaxMessageSize は受け取るメッセージの最大バイト数。超えた場合はCloseMessageTooBigでクローズする
+	MaxMessageSize int64
+	// ReadTimeout はフレームを受け取るまで待つ時間。pongも含めて何も届かない場合はエラーにする。0は待ち続ける
+	ReadTimeout time.Duration
+
+	wmu       sync.Mutex
+	closeSent bool
+}
+
+// headerContains はカンマ区切りのヘッダーの値にtokenが含まれるかを大文字小文字を区別せずに返す
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for t := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckHandshake はリクエストがWebSocketのハンドシェイクとして正しいかを検証する。
+// ハンドシェイクでない場合はErrNotWebSocket、バージョンが異なる場合はErrUnsupportedVersionを返す。
+func CheckHandshake(r *http.Request) error {
+	if r.Method != http.MethodGet || !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		return ErrNotWebSocket
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != Version {
+		return ErrUnsupportedVersion
+	}
+	if key, err := base64.StdEncoding.DecodeString(r.Header.Get("Sec-WebSocket-Key")); err != nil || len(key) != 16 {
+		return ErrNotWebSocket
+	}
+	return nil
+}
+
+// acceptKey はSec-WebSocket-Keyに対するSec-WebSocket-Acceptの値を返す
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Upgrade はハンドシェイクに応答してHTTPの接続を乗っ取り、WebSocketの接続を返す。
+// 乗っ取った後はHTTPサーバーの読み書きのタイムアウトを外し、ReadTimeoutとwriteWaitで管理する。
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if err := CheckHandshake(r); err != nil {
+		return nil, err
+	}
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("接続の乗っ取りに失敗: %w", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	if brw.Reader.Buffered() > 0 {
+		_ = conn.Close()
+		return nil, errors.New("ハンドシェイクの応答前にデータを受け取りました")
+	}
+
+	_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if _, err := fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", acceptKey(r.Header.Get("Sec-WebSocket-Key"))); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("ハンドシェイクの応答に失敗: %w", err)
+	}
+	if err := brw.Flush(); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("ハンドシェイクの応答に失敗: %w", err)
+	}
+	return &Conn{conn: conn, br: brw.Reader, MaxMessageSize: 1 << 20}, nil
+}
+
+// fail はcodeでクローズを送り、CloseErrorを返す
+func (c *Conn) fail(code int, reason string) error {
+	_ = c.WriteClose(code, reason)
+	return &CloseError{Code: code, Reason: reason}
+}
+
+// readFrame はフレームを1つ読み、マスクを外したペイロードを返す
+func (c *Conn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	if c.ReadTimeout > 0 {
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.ReadTimeout))
+	}
+	var h [2]byte
+	if _, err := io.ReadFull(c.br, h[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = h[0]&0x80 != 0
+	opcode = int(h[0] & 0x0f)
+	if h[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "拡張には対応していません")
+	}
+	// クライアントから送るフレームは必ずマスクする
+	if h[1]&0x80 == 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "マスクされていないフレームです")
+	}
+
+	n := int64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = int64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+		if b[0]&0x80 != 0 {
+			return false, 0, nil, c.fail(CloseProtocolError, "フレームの長さが不正です")
+		}
+		n = int64(binary.BigEndian.Uint64(b[:]))
+	}
+	if opcode >= closeFrame && (!fin || n > 125) {
+		return false, 0, nil, c.fail(CloseProtocolError, "制御フレームが不正です")
+	}
+	if c.MaxMessageSize > 0 && n > c.MaxMessageSize {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// ReadMessage は分割されたフレームをまとめてメッセージを1つ読み、種類と内容を返す。
+// pingにはpongで応答し、クローズを受け取った場合はクローズを返してCloseErrorを返す。
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var (
+		messageType int
+		message     []byte
+	)
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch opcode {
+		case pingFrame:
+			if err := c.writeFrame(pongFrame, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case pongFrame:
+			continue
+		case closeFrame:
+			code, reason := CloseNoStatus, ""
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+				reason = string(payload[2:])
+			}
+			_ = c.WriteClose(CloseNormal, "")
+			return 0, nil, &CloseError{Code: code, Reason: reason}
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "分割されたメッセージの途中で新しいメッセージを受け取りました")
+			}
+			messageType = opcode
+			message = payload
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "続きのフレームに対応するメッセージがありません")
+			}
+			message = append(message, payload...)
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "不明な種類のフレームです")
+		}
+
+		if c.MaxMessageSize > 0 && int64(len(message)) > c.MaxMessageSize {
+			return 0, nil, c.fail(CloseMessageTooBig, "")
+		}
+		if !fin {
+			continue
+		}
+		if messageType == TextMessage && !utf8.Valid(message) {
+			return 0, nil, c.fail(CloseInvalidPayload, "")
+		}
+		return messageType, message, nil
+	}
+}
+
+// writeFrame はマスクしないフレームを1つ書き込む
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closeSent {
+		return net.ErrClosed
+	}
+	if opcode == closeFrame {
+		c.closeSent = true
+	}
+
+	header := make([]byte, 0, 10)
+	header = append(header, 0x80|byte(opcode))
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, byte(n))
+	case n <= 0xffff:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	bufs := net.Buffers{header, payload}
+	_, err := bufs.WriteTo(c.conn)
+	return err
+}
+
+// WriteMessage はメッセージを1つのフレームで送る
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	return c.writeFrame(messageType, data)
+}
+
+// WriteJSON はvをJSONにエンコードしてテキストのメッセージで送る
+func (c *Conn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(TextMessage, data)
+}
+
+// Ping はpingを送る。応答のpongはReadMessageが読み捨て、ReadTimeoutの待ち時間を延ばす
+func (c *Conn) Ping() error {
+	return c.writeFrame(pingFrame, nil)
+}
+
+// WriteClose はクローズを送る。2回目以降の呼び出しは何もしない
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	// 制御フレームのペイロードは125バイトまで。切り詰めて途中になった文字は除く
+	if len(reason) > 123 {
+		reason = strings.ToValidUTF8(reason[:123], "")
+	}
+	payload = append(payload, reason...)
+	err := c.writeFrame(closeFrame, payload)
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close は下位の接続を閉じる
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
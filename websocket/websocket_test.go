@@ -0,0 +1,280 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// RFC 6455 1.3の例
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("acceptKey() = %q", got)
+	}
+}
+
+func TestCheckHandshake(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		header map[string]string
+		want   error
+	}{
+		{"valid", http.MethodGet, map[string]string{}, nil},
+		{"connection list", http.MethodGet, map[string]string{"Connection": "keep-alive, Upgrade"}, nil},
+		{"post", http.MethodPost, map[string]string{}, ErrNotWebSocket},
+		{"no upgrade", http.MethodGet, map[string]string{"Upgrade": "h2c"}, ErrNotWebSocket},
+		{"old version", http.MethodGet, map[string]string{"Sec-WebSocket-Version": "8"}, ErrUnsupportedVersion},
+		{"short key", http.MethodGet, map[string]string{"Sec-WebSocket-Key": "c2hvcnQ="}, ErrNotWebSocket},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/ws", nil)
+			r.Header.Set("Connection", "Upgrade")
+			r.Header.Set("Upgrade", "websocket")
+			r.Header.Set("Sec-WebSocket-Version", Version)
+			r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			if err := CheckHandshake(r); !errors.Is(err, tt.want) {
+				t.Errorf("CheckHandshake() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// client はテスト用のWebSocketのクライアント側の接続
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// dial はハンドラーで待ち受けるサーバーを起動し、ハンドシェイクを済ませたクライアントを返す。
+// サーバー側の接続はhandleに渡し、handleが返ると閉じる。
+func dial(t *testing.T, handle func(*Conn)) *client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer func() { _ = c.Close() }()
+		handle(c)
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake response = %d %v", resp.StatusCode, resp.Header)
+	}
+	return &client{t: t, conn: conn, br: br}
+}
+
+// send はマスクしたフレームを送る。maskedがfalseの場合はマスクせずに送る
+func (c *client) send(fin bool, opcode int, payload []byte, masked bool) {
+	c.t.Helper()
+	b0 := byte(opcode)
+	if fin {
+		b0 |= 0x80
+	}
+	frame := []byte{b0}
+	var lenByte byte
+	switch n := len(payload); {
+	case n <= 125:
+		lenByte = byte(n)
+	default:
+		lenByte = 126
+	}
+	if masked {
+		frame = append(frame, 0x80|lenByte)
+	} else {
+		frame = append(frame, lenByte)
+	}
+	if lenByte == 126 {
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	}
+	data := append([]byte(nil), payload...)
+	if masked {
+		mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+		frame = append(frame, mask[:]...)
+		for i := range data {
+			data[i] ^= mask[i%4]
+		}
+	}
+	if _, err := c.conn.Write(append(frame, data...)); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// recv はサーバーからのフレームを1つ読む
+func (c *client) recv() (opcode int, payload []byte) {
+	c.t.Helper()
+	var h [2]byte
+	if _, err := io.ReadFull(c.br, h[:]); err != nil {
+		c.t.Fatal(err)
+	}
+	if h[0]&0x80 == 0 || h[1]&0x80 != 0 {
+		c.t.Fatalf("frame header = %08b %08b, want FIN and no mask", h[0], h[1])
+	}
+	n := int(h[1] & 0x7f)
+	if n == 126 {
+		var b [2]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			c.t.Fatal(err)
+		}
+		n = int(binary.BigEndian.Uint16(b[:]))
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		c.t.Fatal(err)
+	}
+	return int(h[0] & 0x0f), payload
+}
+
+// recvClose はクローズを読み、ステータスコードを返す
+func (c *client) recvClose() int {
+	c.t.Helper()
+	opcode, payload := c.recv()
+	if opcode != closeFrame || len(payload) < 2 {
+		c.t.Fatalf("frame = %d %q, want close", opcode, payload)
+	}
+	return int(binary.BigEndian.Uint16(payload))
+}
+
+// echo は受け取ったメッセージをそのまま送り返し、読み込みのエラーをerrsに送る
+func echo(errs chan<- error) func(*Conn) {
+	return func(c *Conn) {
+		c.MaxMessageSize = 200
+		for {
+			typ, msg, err := c.ReadMessage()
+			if err != nil {
+				errs <- err
+				return
+			}
+			if err := c.WriteMessage(typ, msg); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}
+}
+
+func TestEchoAndClose(t *testing.T) {
+	errs := make(chan error, 1)
+	c := dial(t, echo(errs))
+
+	c.send(true, TextMessage, []byte("こんにちは"), true)
+	if op, got := c.recv(); op != TextMessage || string(got) != "こんにちは" {
+		t.Fatalf("echo = %d %q", op, got)
+	}
+
+	// 分割されたメッセージの間に届いたpingには、その場でpongを返す
+	c.send(false, BinaryMessage, []byte{1, 2}, true)
+	c.send(true, pingFrame, []byte("p"), true)
+	c.send(true, continuationFrame, []byte{3}, true)
+	if op, got := c.recv(); op != pongFrame || string(got) != "p" {
+		t.Fatalf("pong = %d %q", op, got)
+	}
+	if op, got := c.recv(); op != BinaryMessage || string(got) != "\x01\x02\x03" {
+		t.Fatalf("echo = %d %v", op, got)
+	}
+
+	// 126バイト以上のメッセージは16ビットの長さで送る
+	long := strings.Repeat("a", 150)
+	c.send(true, TextMessage, []byte(long), true)
+	if _, got := c.recv(); string(got) != long {
+		t.Fatalf("echo of %d bytes = %d bytes", len(long), len(got))
+	}
+
+	c.send(true, closeFrame, binary.BigEndian.AppendUint16(nil, CloseGoingAway), true)
+	if code := c.recvClose(); code != CloseNormal {
+		t.Errorf("close code = %d, want %d", code, CloseNormal)
+	}
+	var ce *CloseError
+	if err := <-errs; !errors.As(err, &ce) || ce.Code != CloseGoingAway {
+		t.Errorf("ReadMessage() = %v, want CloseError %d", err, CloseGoingAway)
+	}
+}
+
+func TestProtocolErrorsClose(t *testing.T) {
+	tests := []struct {
+		name string
+		send func(*client)
+		code int
+	}{
+		{"unmasked frame", func(c *client) { c.send(true, TextMessage, []byte("x"), false) }, CloseProtocolError},
+		{"invalid utf-8", func(c *client) { c.send(true, TextMessage, []byte{0xff, 0xfe}, true) }, CloseInvalidPayload},
+		{"too big", func(c *client) { c.send(true, BinaryMessage, make([]byte, 201), true) }, CloseMessageTooBig},
+		{"too big when joined", func(c *client) {
+			c.send(false, BinaryMessage, make([]byte, 120), true)
+			c.send(true, continuationFrame, make([]byte, 120), true)
+		}, CloseMessageTooBig},
+		{"orphan continuation", func(c *client) { c.send(true, continuationFrame, []byte("x"), true) }, CloseProtocolError},
+		{"fragmented ping", func(c *client) { c.send(false, pingFrame, nil, true) }, CloseProtocolError},
+		{"unknown opcode", func(c *client) { c.send(true, 3, nil, true) }, CloseProtocolError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := make(chan error, 1)
+			c := dial(t, echo(errs))
+			tt.send(c)
+			if code := c.recvClose(); code != tt.code {
+				t.Errorf("close code = %d, want %d", code, tt.code)
+			}
+			var ce *CloseError
+			if err := <-errs; !errors.As(err, &ce) || ce.Code != tt.code {
+				t.Errorf("ReadMessage() = %v, want CloseError %d", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	done := make(chan error, 1)
+	c := dial(t, func(conn *Conn) {
+		if err := conn.WriteClose(CloseGoingAway, strings.Repeat("長", 60)); err != nil {
+			done <- err
+			return
+		}
+		// 2回目のクローズは何もせず、クローズの後のメッセージは送らない
+		if err := conn.WriteClose(CloseNormal, ""); err != nil {
+			done <- err
+			return
+		}
+		done <- conn.WriteJSON(map[string]string{"a": "b"})
+	})
+	opcode, payload := c.recv()
+	if opcode != closeFrame || len(payload) > 125 || binary.BigEndian.Uint16(payload) != CloseGoingAway {
+		t.Fatalf("close = %d, %d bytes", opcode, len(payload))
+	}
+	if reason := string(payload[2:]); !strings.HasPrefix(reason, "長") || strings.ToValidUTF8(reason, "") != reason {
+		t.Errorf("reason = %q, want a valid UTF-8 prefix", reason)
+	}
+	if err := <-done; !errors.Is(err, net.ErrClosed) {
+		t.Errorf("WriteJSON() after close = %v, want net.ErrClosed", err)
+	}
+}
@@ -0,0 +1,183 @@
+// Todoの一覧、作成、完了状態の切り替え、削除を同じオリジンのAPIで行う。
+// アクセストークンはlocalStorageに保存し、401が返ったらログインのフォームに戻す。
+"use strict";
+
+const tokenKey = "todo-api-token";
+
+const $ = (id) => document.getElementById(id);
+
+let filter = "open";
+
+// APIError はProblem Details（RFC 9457）のエラーのレスポンス
+class APIError extends Error {
+  constructor(status, problem) {
+    const details = (problem.errors || []).map((e) => e.message).filter(Boolean);
+    super([problem.detail || problem.title || `HTTP ${status}`, ...details].join(": "));
+    this.status = status;
+  }
+}
+
+// api はAPIを呼び出し、JSONのレスポンスを返す。2xx以外はAPIErrorを投げる
+async function api(method, path, { body, headers = {} } = {}) {
+  const token = localStorage.getItem(tokenKey);
+  const init = { method, headers: { Accept: "application/json", ...headers } };
+  if (token) {
+    init.headers.Authorization = `Bearer ${token}`;
+  }
+  if (body !== undefined) {
+    init.headers["Content-Type"] = "application/json";
+    init.body = JSON.stringify(body);
+  }
+
+  const res = await fetch(path, init);
+  const text = await res.text();
+  let data = null;
+  try {
+    data = text ? JSON.parse(text) : null;
+  } catch {
+    // プロキシが返したHTMLのエラーページなど、JSONでないレスポンスは本文をそのまま示す
+    data = { detail: text.slice(0, 200) };
+  }
+  if (!res.ok) {
+    throw new APIError(res.status, data || {});
+  }
+  return data;
+}
+
+// ifMatch はTodoのバージョンを更新と削除のIf-Matchヘッダーにする
+const ifMatch = (todo) => ({ "If-Match": `"${todo.version}"` });
+
+function showMessage(text) {
+  $("message").textContent = text;
+  $("message").hidden = !text;
+}
+
+function showLogin() {
+  localStorage.removeItem(tokenKey);
+  $("login").hidden = false;
+  $("todos").hidden = true;
+  $("logout").hidden = true;
+}
+
+// handleError はエラーを表示する。認証が切れた場合はログインのフォームに戻し、競合した場合は一覧を読み直す
+function handleError(err) {
+  if (err instanceof APIError && err.status === 401) {
+    showLogin();
+    showMessage("ログインしてください");
+    return;
+  }
+  showMessage(err.message);
+  if (err instanceof APIError && err.status === 412) {
+    load();
+  }
+}
+
+function render(todos) {
+  const list = $("list");
+  list.replaceChildren();
+  for (const todo of todos) {
+    const item = $("item").content.firstElementChild.cloneNode(true);
+    item.classList.toggle("completed", todo.completed);
+    item.querySelector(".title").textContent = todo.title;
+    if (todo.due_at) {
+      const due = new Date(todo.due_at);
+      item.querySelector(".due").textContent = due.toLocaleString();
+      item.querySelector(".due").classList.toggle("overdue", !todo.completed && due < new Date());
+    }
+
+    const checkbox = item.querySelector("input");
+    checkbox.checked = todo.completed;
+    checkbox.addEventListener("change", async () => {
+      try {
+        await api("POST", `/todos/${todo.id}/toggle`, { headers: ifMatch(todo) });
+        showMessage("");
+        await load();
+      } catch (err) {
+        checkbox.checked = todo.completed;
+        handleError(err);
+      }
+    });
+
+    item.querySelector(".delete").addEventListener("click", async () => {
+      if (!confirm(`「${todo.title}」を削除しますか？`)) {
+        return;
+      }
+      try {
+        await api("DELETE", `/todos/${todo.id}`, { headers: ifMatch(todo) });
+        showMessage("");
+        await load();
+      } catch (err) {
+        handleError(err);
+      }
+    });
+    list.append(item);
+  }
+  $("empty").hidden = todos.length > 0;
+}
+
+// load は選んだ完了状態のTodoを読み込んで表示する。completed=falseでは絞り込まれないため、未完了はここで絞り込む
+async function load() {
+  if (!localStorage.getItem(tokenKey)) {
+    showLogin();
+    return;
+  }
+  try {
+    const data = await api("GET", `/todos${filter === "completed" ? "?completed=true" : ""}`);
+    const todos = data.todos.filter((t) => filter !== "open" || !t.completed);
+    $("login").hidden = true;
+    $("todos").hidden = false;
+    $("logout").hidden = false;
+    render(todos);
+  } catch (err) {
+    handleError(err);
+  }
+}
+
+$("login").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const form = new FormData(e.target);
+  try {
+    const data = await api("POST", "/auth/login", {
+      body: { email: form.get("email"), password: form.get("password") },
+    });
+    localStorage.setItem(tokenKey, data.access_token);
+    e.target.reset();
+    showMessage("");
+    await load();
+  } catch (err) {
+    showMessage(err.message);
+  }
+});
+
+$("logout").addEventListener("click", () => {
+  showLogin();
+  showMessage("");
+});
+
+$("create").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const title = e.target.elements.title.value.trim();
+  if (!title) {
+    return;
+  }
+  try {
+    await api("POST", "/todos", { body: { title } });
+    e.target.reset();
+    showMessage("");
+    await load();
+  } catch (err) {
+    handleError(err);
+  }
+});
+
+for (const button of document.querySelectorAll("#filters button")) {
+  button.addEventListener("click", () => {
+    filter = button.dataset.filter;
+    for (const b of document.querySelectorAll("#filters button")) {
+      b.setAttribute("aria-pressed", String(b === button));
+    }
+    load();
+  });
+}
+
+load();
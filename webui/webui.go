@@ -0,0 +1,45 @@
+// Package webui はバイナリに埋め込んだTodoのWebアプリを提供する。
+// アプリはAPIと同じオリジンから配信し、ログインで得たアクセストークンでTodoの一覧、作成、完了状態の切り替え、削除のAPIを呼び出す。
+package webui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// AssetsPrefix はアプリのスクリプトとスタイルシートを配信するパスの接頭辞
+const AssetsPrefix = "/ui/"
+
+//go:embed static
+var static embed.FS
+
+// contentSecurityPolicy はアプリのページに付けるContent-Security-Policyヘッダー。
+// スクリプトとスタイルは同じオリジンのファイルだけを許可し、Todoのタイトルなどに紛れたスクリプトを実行させない
+const contentSecurityPolicy = "default-src 'self'; script-src 'self'; style-src 'self'; img-src 'self' data:; connect-src 'self'; frame-ancestors 'none'; base-uri 'none'; form-action 'self'"
+
+// Handler は/にアプリのページを、AssetsPrefixの下にスクリプトとスタイルシートを返すハンドラーを返す
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	assets := http.StripPrefix(AssetsPrefix, http.FileServerFS(files))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		// 埋め込んだファイルには更新日時がないため、バイナリを更新した後も古いファイルを使い続けないよう毎回検証させる
+		w.Header().Set("Cache-Control", "no-cache")
+		switch {
+		case r.URL.Path == "/":
+			http.ServeFileFS(w, r, files, "index.html")
+		case strings.HasSuffix(r.URL.Path, "/"):
+			// ディレクトリの一覧は返さない
+			http.NotFound(w, r)
+		default:
+			assets.ServeHTTP(w, r)
+		}
+	})
+}